@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"storemy/pkg/encryption"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"strings"
+	"testing"
+)
+
+func TestRun_EncryptedLog(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "wal.log")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	w, err := wal.NewWALWithCipher(vfs.OS, logPath, 4096, vfs.SyncOSync, encryption.NewCipher(encryption.NewStaticKeys(3, key)))
+	if err != nil {
+		t.Fatalf("NewWALWithCipher failed: %v", err)
+	}
+	tid := primitives.NewTransactionID()
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	keyFile := filepath.Join(dir, "wal.key")
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-key-file", keyFile, "-key-id", "3", logPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("waldump exited with %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"type=BEGIN", "type=COMMIT", "2 records"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the dump, got:\n%s", want, out)
+		}
+	}
+
+	// Without the key the first record cannot be opened
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{logPath}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit status 1 without a key, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "0 records") {
+		t.Errorf("expected no records without a key, got:\n%s", stdout.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"storemy/pkg/database"
+	"storemy/pkg/metrics"
 	"storemy/pkg/ui"
 	"time"
 
@@ -18,19 +19,25 @@ type Configuration struct {
 	DatabaseName string
 	LogPath      string
 	DataDir      string
+	ReadOnly     bool
+	MetricsAddr  string
 }
 
 func main() {
 	config := parseArguments()
 	showSplashScreen()
 
-	db, err := initializeDatabase(config)
+	engine, session, err := initializeDatabase(config)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer db.Close()
+	defer engine.Close()
 
-	if err := startInteractiveMode(db); err != nil {
+	if config.MetricsAddr != "" {
+		startMetricsServer(config.MetricsAddr)
+	}
+
+	if err := startInteractiveMode(session); err != nil {
 		log.Fatalf("Failed to start UI: %v", err)
 	}
 }
@@ -41,6 +48,8 @@ func parseArguments() Configuration {
 
 	flag.StringVar(&config.DatabaseName, "db", "mydb", "Database name")
 	flag.StringVar(&config.DataDir, "data", "./data", "Data directory path")
+	flag.BoolVar(&config.ReadOnly, "readonly", false, "Open an existing database in read-only mode")
+	flag.StringVar(&config.MetricsAddr, "metrics-addr", "", "Serve Prometheus metrics at http://<addr>/metrics (e.g. :9090)")
 
 	flag.Parse()
 
@@ -83,27 +92,59 @@ func showSplashScreen() {
 	time.Sleep(2 * time.Second)
 }
 
-// initializeDatabase creates and sets up the database database
-func initializeDatabase(config Configuration) (*database.Database, error) {
+// initializeDatabase creates the engine and opens the startup database in it.
+// The startup database keeps its WAL at config.LogPath; databases added with
+// CREATE DATABASE keep theirs in their own directory.
+func initializeDatabase(config Configuration) (*database.Engine, *database.Session, error) {
 	fmt.Printf("🔧 Initializing database '%s'...\n", config.DatabaseName)
 
-	fullPath := filepath.Join(config.DataDir, config.DatabaseName)
-	if err := os.MkdirAll(fullPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %v", err)
+	if !config.ReadOnly {
+		fullPath := filepath.Join(config.DataDir, config.DatabaseName)
+		if err := os.MkdirAll(fullPath, 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create data directory: %v", err)
+		}
+	}
+
+	opts := database.DefaultOptions()
+	opts.ReadOnly = config.ReadOnly
+
+	engine, err := database.NewEngine(config.DataDir, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := database.NewDatabaseWithOptions(config.DatabaseName, config.DataDir, config.LogPath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := engine.Attach(db); err != nil {
+		db.Close()
+		return nil, nil, err
 	}
 
-	db, err := database.NewDatabase(config.DatabaseName, config.DataDir, config.LogPath)
+	session, err := engine.NewSession(config.DatabaseName)
 	if err != nil {
-		return nil, err
+		engine.Close()
+		return nil, nil, err
 	}
 
 	fmt.Println("✅ Database initialized successfully!")
-	return db, nil
+	return engine, session, nil
+}
+
+// startMetricsServer exposes the metrics registry over HTTP in the background
+func startMetricsServer(addr string) {
+	fmt.Printf("📈 Serving metrics at http://%s/metrics\n", addr)
+	go func() {
+		if err := metrics.ListenAndServe(addr); err != nil {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
 }
 
 // startInteractiveMode launches the Bubble Tea UI
-func startInteractiveMode(db *database.Database) error {
-	model := ui.NewModel(db)
+func startInteractiveMode(session *database.Session) error {
+	model := ui.NewModel(session)
 
 	p := tea.NewProgram(
 		model,
@@ -0,0 +1,142 @@
+// Package audit defines the audit tables that record the row changes of
+// audited tables.
+//
+// Auditing is enabled per table with ALTER TABLE t ENABLE AUDIT, which
+// creates the table T_AUDIT and records the pair in the CATALOG_AUDITED_TABLES
+// system table. From then on the DML executor adds one row to T_AUDIT for
+// every row an INSERT, UPDATE, DELETE or COPY changes in T, inside the
+// statement's transaction, so an audit row exists exactly when its change
+// committed. Audit tables are ordinary tables and are queried with SELECT.
+//
+// OLD_ROW and NEW_ROW are string columns, so like any string value a row
+// whose JSON is longer than types.StringMaxSize is cut off at that size.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"time"
+)
+
+// TimeFormat is the layout of the AUDIT_TIME column: UTC with microseconds,
+// so audit rows sort by time as strings.
+const TimeFormat = "2006-01-02 15:04:05.000000"
+
+// Columns of an audit table, in order.
+var columns = []struct {
+	name      string
+	fieldType types.Type
+}{
+	{"AUDIT_TXN", types.IntType},     // ID of the transaction that made the change
+	{"AUDIT_TIME", types.StringType}, // When the row was changed, in TimeFormat
+	{"AUDIT_OP", types.StringType},   // INSERT, UPDATE or DELETE
+	{"OLD_ROW", types.StringType},    // Row before the change as a JSON object, empty for INSERT
+	{"NEW_ROW", types.StringType},    // Row after the change as a JSON object, empty for DELETE
+}
+
+// TableName returns the name of the audit table of a table.
+func TableName(tableName string) string {
+	return tableName + "_AUDIT"
+}
+
+// Schema returns the schema of the audit table of a table.
+func Schema(tableName string) (*schema.Schema, error) {
+	builder := schema.NewSchemaBuilder(primitives.InvalidFileID, TableName(tableName))
+	for _, c := range columns {
+		builder.AddColumn(c.name, c.fieldType)
+	}
+	return builder.Build()
+}
+
+// Matches reports whether td has the columns of an audit table, so that an
+// existing table can take audit rows.
+func Matches(td *tuple.TupleDescription) bool {
+	if int(td.NumFields()) != len(columns) {
+		return false
+	}
+	for i, c := range columns {
+		name, err := td.GetFieldName(primitives.ColumnID(i))
+		if err != nil || !strings.EqualFold(name, c.name) {
+			return false
+		}
+		fieldType, err := td.TypeAtIndex(primitives.ColumnID(i))
+		if err != nil || fieldType != c.fieldType {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRow builds the audit row of one change made by transaction tid at time
+// at. op is INSERT, UPDATE or DELETE; oldRow is nil for an INSERT and newRow
+// is nil for a DELETE.
+func NewRow(td *tuple.TupleDescription, tid *primitives.TransactionID, at time.Time, op string, oldRow, newRow *tuple.Tuple) (*tuple.Tuple, error) {
+	oldJSON, err := EncodeRow(oldRow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode old row: %w", err)
+	}
+	newJSON, err := EncodeRow(newRow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode new row: %w", err)
+	}
+
+	return tuple.NewBuilder(td).
+		AddInt(tid.ID()).
+		AddString(at.UTC().Format(TimeFormat)).
+		AddString(op).
+		AddString(oldJSON).
+		AddString(newJSON).
+		Build()
+}
+
+// EncodeRow serializes a row as a JSON object mapping its column names to
+// their values, in column order. Strings become JSON strings, booleans JSON
+// booleans, numbers JSON numbers and NULLs null. A nil row encodes as an
+// empty string.
+func EncodeRow(t *tuple.Tuple) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := primitives.ColumnID(0); i < t.TupleDesc.NumFields(); i++ {
+		name, err := t.TupleDesc.GetFieldName(i)
+		if err != nil {
+			return "", err
+		}
+		field, err := t.GetField(i)
+		if err != nil {
+			return "", err
+		}
+
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		sb.Write(key)
+		sb.WriteByte(':')
+		sb.WriteString(encodeField(field))
+	}
+	sb.WriteByte('}')
+	return sb.String(), nil
+}
+
+// encodeField returns the JSON value of a field.
+func encodeField(field types.Field) string {
+	if field == nil {
+		return "null"
+	}
+	switch field.Type() {
+	case types.StringType:
+		value, _ := json.Marshal(field.String())
+		return string(value)
+	default:
+		return field.String()
+	}
+}
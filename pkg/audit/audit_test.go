@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+	"time"
+)
+
+func TestEncodeRow(t *testing.T) {
+	td, err := tuple.NewTupleDesc(
+		[]types.Type{types.IntType, types.StringType, types.BoolType},
+		[]string{"ID", "NAME", "ACTIVE"},
+	)
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	row := tuple.NewBuilder(td).AddInt(7).AddString(`say "hi"`).AddBool(true).MustBuild()
+
+	got, err := EncodeRow(row)
+	if err != nil {
+		t.Fatalf("EncodeRow failed: %v", err)
+	}
+	if expected := `{"ID":7,"NAME":"say \"hi\"","ACTIVE":true}`; got != expected {
+		t.Errorf("EncodeRow = %s, expected %s", got, expected)
+	}
+
+	if got, _ := EncodeRow(nil); got != "" {
+		t.Errorf("EncodeRow(nil) = %q, expected empty", got)
+	}
+}
+
+func TestSchemaMatches(t *testing.T) {
+	sch, err := Schema("ORDERS")
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	if sch.TableName != "ORDERS_AUDIT" {
+		t.Errorf("audit table name = %s, expected ORDERS_AUDIT", sch.TableName)
+	}
+	if !Matches(sch.TupleDesc) {
+		t.Error("expected the audit schema to match")
+	}
+
+	other, err := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"AUDIT_TXN"})
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	if Matches(other) {
+		t.Error("expected a one-column table not to match")
+	}
+}
+
+func TestNewRow(t *testing.T) {
+	sch, err := Schema("T")
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	tid := primitives.NewTransactionID()
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	row, err := NewRow(sch.TupleDesc, tid, at, "DELETE", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRow failed: %v", err)
+	}
+	// Columns after AUDIT_TXN
+	expected := []string{"2024-05-01 12:30:00.000000", "DELETE", "", ""}
+	for i, want := range expected {
+		field, err := row.GetField(primitives.ColumnID(i + 1))
+		if err != nil {
+			t.Fatalf("GetField failed: %v", err)
+		}
+		if field.String() != want {
+			t.Errorf("column %d = %q, expected %q", i+1, field.String(), want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package bench
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"storemy/pkg/database"
+	"storemy/pkg/logging"
+)
+
+func newTestDB(tb testing.TB) *database.Database {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	opts := database.DefaultOptions()
+	opts.Logger = logging.NewNopLogger()
+	db, err := database.NewDatabaseWithOptions("benchdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), opts)
+	if err != nil {
+		tb.Fatalf("NewDatabase failed: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRun_CanonicalWorkloads(t *testing.T) {
+	for _, name := range []string{"kv", "join", "log"} {
+		for _, workload := range Workloads() {
+			t.Run(name+"/"+workload.Name, func(t *testing.T) {
+				dataset, err := DatasetByName(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				report, err := Run(newTestDB(t), Config{
+					Dataset:     dataset,
+					Workload:    workload,
+					Scale:       150,
+					Operations:  60,
+					Concurrency: 1,
+					Seed:        7,
+				})
+				if err != nil {
+					t.Fatalf("Run failed: %v", err)
+				}
+
+				if report.Operations != 60 {
+					t.Errorf("expected 60 operations, got %d", report.Operations)
+				}
+				if report.Errors != 0 {
+					t.Errorf("expected no errors, got %d: %v", report.Errors, report.ErrorSamples)
+				}
+				if report.Reads.Count+report.Writes.Count != report.Operations {
+					t.Errorf("reads (%d) + writes (%d) != operations (%d)",
+						report.Reads.Count, report.Writes.Count, report.Operations)
+				}
+				if report.Throughput <= 0 {
+					t.Errorf("expected positive throughput, got %f", report.Throughput)
+				}
+				if report.All.P50 > report.All.P99 || report.All.P99 > report.All.Max {
+					t.Errorf("percentiles out of order: %+v", report.All)
+				}
+			})
+		}
+	}
+}
+
+func TestRun_WritesAreVisible(t *testing.T) {
+	db := newTestDB(t)
+
+	report, err := Run(db, Config{
+		Dataset:     NewAppendLog(),
+		Workload:    Workload{Name: "append", ReadFraction: 0},
+		Scale:       20,
+		Operations:  30,
+		Concurrency: 1,
+		Seed:        1,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Errors != 0 {
+		t.Fatalf("expected no errors, got %d: %v", report.Errors, report.ErrorSamples)
+	}
+
+	result, err := db.ExecuteQuery("SELECT COUNT(seq) FROM bench_log")
+	if err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if got := result.Rows[0][0]; got != "50" {
+		t.Errorf("expected 50 log entries, got %s", got)
+	}
+}
+
+func TestRun_SkipSetupReusesDataset(t *testing.T) {
+	db := newTestDB(t)
+	cfg := Config{Dataset: NewKeyValue(), Workload: WriteHeavy, Scale: 10, Operations: 10, Concurrency: 1, Seed: 3}
+
+	if _, err := Run(db, cfg); err != nil {
+		t.Fatalf("first run failed: %v", err)
+	}
+
+	cfg.SkipSetup = true
+	report, err := Run(db, cfg)
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	if report.Errors != 0 {
+		t.Errorf("expected no errors, got %v", report.ErrorSamples)
+	}
+	if report.LoadTime != 0 {
+		t.Errorf("expected no load time when skipping setup, got %s", report.LoadTime)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no dataset", Config{Workload: Mixed, Concurrency: 1}},
+		{"bad read fraction", Config{Dataset: NewKeyValue(), Workload: Workload{ReadFraction: 1.5}, Concurrency: 1}},
+		{"negative operations", Config{Dataset: NewKeyValue(), Workload: Mixed, Operations: -1, Concurrency: 1}},
+		{"no workers", Config{Dataset: NewKeyValue(), Workload: Mixed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Run(nil, tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestByName(t *testing.T) {
+	if _, err := DatasetByName("nope"); err == nil {
+		t.Error("expected error for unknown dataset")
+	}
+	if w, err := WorkloadByName("READ-HEAVY"); err != nil || w != ReadHeavy {
+		t.Errorf("WorkloadByName(READ-HEAVY) = %v, %v", w, err)
+	}
+	if _, err := WorkloadByName("nope"); err == nil {
+		t.Error("expected error for unknown workload")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	lat := summarize(durations)
+	if lat.Count != 100 || lat.Min != time.Millisecond || lat.Max != 100*time.Millisecond {
+		t.Errorf("unexpected count/min/max: %+v", lat)
+	}
+	if lat.P50 != 50*time.Millisecond || lat.P95 != 95*time.Millisecond || lat.P99 != 99*time.Millisecond {
+		t.Errorf("unexpected percentiles: %+v", lat)
+	}
+	if lat.Mean != 50500*time.Microsecond {
+		t.Errorf("expected mean 50.5ms, got %s", lat.Mean)
+	}
+
+	if (summarize(nil) != Latency{}) {
+		t.Error("expected zero latency for no samples")
+	}
+}
+
+func TestReport_String(t *testing.T) {
+	report, err := Run(newTestDB(t), Config{
+		Dataset: NewKeyValue(), Workload: ReadHeavy, Scale: 10, Operations: 5, Concurrency: 1, Seed: 1,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	out := report.String()
+	for _, want := range []string{"dataset=kv", "workload=read-heavy", "throughput=", "p99", "reads", "writes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// runBenchmark loads dataset once and issues b.N statements of workload,
+// reporting the run's latency percentiles alongside the standard metrics.
+func runBenchmark(b *testing.B, dataset string, workload Workload) {
+	ds, err := DatasetByName(dataset)
+	if err != nil {
+		b.Fatal(err)
+	}
+	db := newTestDB(b)
+	if err := ds.Setup(db, 1000); err != nil {
+		b.Fatalf("setup failed: %v", err)
+	}
+
+	b.ResetTimer()
+	report, err := Run(db, Config{
+		Dataset:     ds,
+		Workload:    workload,
+		Operations:  b.N,
+		Concurrency: 4,
+		Seed:        1,
+		SkipSetup:   true,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(report.All.P50.Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(report.All.P99.Nanoseconds()), "p99-ns")
+	b.ReportMetric(float64(report.Errors), "errors")
+}
+
+func BenchmarkWorkloads(b *testing.B) {
+	for _, dataset := range []string{"kv", "join", "log"} {
+		for _, workload := range Workloads() {
+			b.Run(fmt.Sprintf("%s/%s", dataset, workload.Name), func(b *testing.B) {
+				runBenchmark(b, dataset, workload)
+			})
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Package bench provides a benchmark harness with canonical workloads.
+//
+// A Dataset creates a schema, loads it with generated data and produces the
+// read and write statements of its workload. Run drives a dataset against a
+// database with a configurable read/write mix and concurrency and returns a
+// Report with throughput and latency percentiles. The harness is used by the
+// package's Go benchmarks in CI and can be embedded by users who want to
+// compare database settings on a repeatable workload.
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+
+	"storemy/pkg/database"
+)
+
+// Dataset tables are created without a PRIMARY KEY: B+tree leaves hold up to
+// btree.MaxEntriesPerPage entries, which overflows a page for INT keys, so a
+// primary-key table cannot be loaded past roughly 150 rows.
+
+// loadBatchSize is the number of rows inserted per INSERT statement while
+// loading a dataset.
+const loadBatchSize = 100
+
+// Executor runs SQL statements. *database.Database satisfies it.
+type Executor interface {
+	ExecuteQuery(query string) (database.QueryResult, error)
+}
+
+// Dataset generates a schema with data and the statements that exercise it.
+//
+// ReadQuery and WriteQuery are called concurrently from the runner's workers,
+// each with its own random source, so implementations must be safe for
+// concurrent use.
+type Dataset interface {
+	// Name identifies the dataset in reports.
+	Name() string
+
+	// Setup creates the dataset's tables and loads scale rows into them.
+	Setup(db Executor, scale int) error
+
+	// ReadQuery returns a read-only statement.
+	ReadQuery(rng *rand.Rand) string
+
+	// WriteQuery returns a statement that modifies data.
+	WriteQuery(rng *rand.Rand) string
+}
+
+// DatasetByName returns a fresh dataset for one of the names "kv", "join"
+// or "log".
+func DatasetByName(name string) (Dataset, error) {
+	switch strings.ToLower(name) {
+	case "kv", "keyvalue", "key-value":
+		return NewKeyValue(), nil
+	case "join", "orders", "orders-users":
+		return NewOrdersUsers(), nil
+	case "log", "append-log", "appendlog":
+		return NewAppendLog(), nil
+	default:
+		return nil, fmt.Errorf("unknown dataset %q (want kv, join or log)", name)
+	}
+}
+
+// KeyValue is a single table of integer keys and text values accessed by
+// point lookups. Writes update an existing key or insert a new one with equal
+// probability.
+type KeyValue struct {
+	size atomic.Int64
+}
+
+// NewKeyValue creates the key-value dataset.
+func NewKeyValue() *KeyValue {
+	return &KeyValue{}
+}
+
+func (d *KeyValue) Name() string { return "kv" }
+
+func (d *KeyValue) Setup(db Executor, scale int) error {
+	if err := exec(db, "CREATE TABLE bench_kv (k INT, v TEXT)"); err != nil {
+		return err
+	}
+	err := loadRows(db, "INSERT INTO bench_kv (k, v) VALUES ", scale, func(i int) string {
+		return fmt.Sprintf("(%d, 'value-%d')", i, i)
+	})
+	if err != nil {
+		return err
+	}
+	d.size.Store(int64(scale))
+	return nil
+}
+
+func (d *KeyValue) ReadQuery(rng *rand.Rand) string {
+	return fmt.Sprintf("SELECT v FROM bench_kv WHERE k = %d", d.randomKey(rng))
+}
+
+func (d *KeyValue) WriteQuery(rng *rand.Rand) string {
+	if rng.Intn(2) == 0 {
+		k := d.randomKey(rng)
+		return fmt.Sprintf("UPDATE bench_kv SET v = 'updated-%d' WHERE k = %d", rng.Int63(), k)
+	}
+	k := d.size.Add(1) - 1
+	return fmt.Sprintf("INSERT INTO bench_kv (k, v) VALUES (%d, 'value-%d')", k, k)
+}
+
+func (d *KeyValue) randomKey(rng *rand.Rand) int64 {
+	return rng.Int63n(max(d.size.Load(), 1))
+}
+
+// OrdersUsers models a users table and an orders table referencing it. Reads
+// join the two tables for a slice of users; writes place new orders.
+type OrdersUsers struct {
+	users  int64
+	orders atomic.Int64
+}
+
+// ordersUsersAges is the number of distinct ages generated for users, which
+// bounds the selectivity of the join workload's filter.
+const ordersUsersAges = 50
+
+// NewOrdersUsers creates the orders/users join dataset.
+func NewOrdersUsers() *OrdersUsers {
+	return &OrdersUsers{}
+}
+
+func (d *OrdersUsers) Name() string { return "join" }
+
+// Setup loads scale users and scale orders, each order belonging to a random
+// user.
+func (d *OrdersUsers) Setup(db Executor, scale int) error {
+	if err := exec(db, "CREATE TABLE bench_users (id INT, name TEXT, age INT)"); err != nil {
+		return err
+	}
+	if err := exec(db, "CREATE TABLE bench_orders (id INT, user_id INT, amount INT)"); err != nil {
+		return err
+	}
+
+	err := loadRows(db, "INSERT INTO bench_users (id, name, age) VALUES ", scale, func(i int) string {
+		return fmt.Sprintf("(%d, 'user-%d', %d)", i, i, 18+i%ordersUsersAges)
+	})
+	if err != nil {
+		return err
+	}
+
+	users := max(scale, 1)
+	err = loadRows(db, "INSERT INTO bench_orders (id, user_id, amount) VALUES ", scale, func(i int) string {
+		return fmt.Sprintf("(%d, %d, %d)", i, (i*7919)%users, 1+(i*31)%1000)
+	})
+	if err != nil {
+		return err
+	}
+
+	d.users = int64(users)
+	d.orders.Store(int64(scale))
+	return nil
+}
+
+func (d *OrdersUsers) ReadQuery(rng *rand.Rand) string {
+	return fmt.Sprintf(
+		"SELECT u.name, o.amount FROM bench_users u JOIN bench_orders o ON u.id = o.user_id WHERE u.age = %d",
+		18+rng.Intn(ordersUsersAges),
+	)
+}
+
+func (d *OrdersUsers) WriteQuery(rng *rand.Rand) string {
+	id := d.orders.Add(1) - 1
+	return fmt.Sprintf(
+		"INSERT INTO bench_orders (id, user_id, amount) VALUES (%d, %d, %d)",
+		id, rng.Int63n(max(d.users, 1)), 1+rng.Intn(1000),
+	)
+}
+
+// AppendLog is an append-only event log. Writes append entries with a
+// monotonically increasing sequence number; reads fetch the most recent
+// entries.
+type AppendLog struct {
+	seq atomic.Int64
+}
+
+// appendLogTail is the number of recent entries fetched by a read.
+const appendLogTail = 10
+
+var appendLogLevels = []string{"DEBUG", "INFO", "INFO", "INFO", "WARN", "ERROR"}
+
+// NewAppendLog creates the append-only log dataset.
+func NewAppendLog() *AppendLog {
+	return &AppendLog{}
+}
+
+func (d *AppendLog) Name() string { return "log" }
+
+func (d *AppendLog) Setup(db Executor, scale int) error {
+	if err := exec(db, "CREATE TABLE bench_log (seq INT, level TEXT, message TEXT)"); err != nil {
+		return err
+	}
+	err := loadRows(db, "INSERT INTO bench_log (seq, level, message) VALUES ", scale, func(i int) string {
+		return fmt.Sprintf("(%d, '%s', 'event %d')", i, appendLogLevels[i%len(appendLogLevels)], i)
+	})
+	if err != nil {
+		return err
+	}
+	d.seq.Store(int64(scale))
+	return nil
+}
+
+func (d *AppendLog) ReadQuery(rng *rand.Rand) string {
+	return fmt.Sprintf("SELECT * FROM bench_log WHERE seq >= %d", d.seq.Load()-appendLogTail)
+}
+
+func (d *AppendLog) WriteQuery(rng *rand.Rand) string {
+	seq := d.seq.Add(1) - 1
+	level := appendLogLevels[rng.Intn(len(appendLogLevels))]
+	return fmt.Sprintf("INSERT INTO bench_log (seq, level, message) VALUES (%d, '%s', 'event %d')", seq, level, seq)
+}
+
+// maxQueryInError bounds how much of a failed setup statement is quoted in
+// the returned error; load statements can be very long.
+const maxQueryInError = 80
+
+// exec runs query and reports a failure as an error.
+func exec(db Executor, query string) error {
+	if _, err := db.ExecuteQuery(query); err != nil {
+		if len(query) > maxQueryInError {
+			query = query[:maxQueryInError] + "..."
+		}
+		return fmt.Errorf("bench setup %q: %w", query, err)
+	}
+	return nil
+}
+
+// loadRows inserts rows 0..n-1 in batches, rendering each row's VALUES tuple
+// with row.
+func loadRows(db Executor, prefix string, n int, row func(i int) string) error {
+	var sb strings.Builder
+	for start := 0; start < n; start += loadBatchSize {
+		end := min(start+loadBatchSize, n)
+
+		sb.Reset()
+		sb.WriteString(prefix)
+		for i := start; i < end; i++ {
+			if i > start {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(row(i))
+		}
+
+		if err := exec(db, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
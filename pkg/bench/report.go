@@ -0,0 +1,126 @@
+package bench
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Latency summarizes the latency distribution of a set of statements.
+type Latency struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean_ns"`
+	Min   time.Duration `json:"min_ns"`
+	Max   time.Duration `json:"max_ns"`
+	P50   time.Duration `json:"p50_ns"`
+	P95   time.Duration `json:"p95_ns"`
+	P99   time.Duration `json:"p99_ns"`
+}
+
+// Report holds the results of a benchmark run.
+type Report struct {
+	Dataset      string        `json:"dataset"`
+	Workload     string        `json:"workload"`
+	Scale        int           `json:"scale"`
+	Concurrency  int           `json:"concurrency"`
+	Operations   int           `json:"operations"`
+	Errors       int           `json:"errors"`
+	ErrorSamples []string      `json:"error_samples,omitempty"`
+	LoadTime     time.Duration `json:"load_time_ns"`
+	Elapsed      time.Duration `json:"elapsed_ns"`
+	Throughput   float64       `json:"ops_per_second"`
+	Reads        Latency       `json:"reads"`
+	Writes       Latency       `json:"writes"`
+	All          Latency       `json:"all"`
+}
+
+func newReport(cfg Config, perWorker [][]sample, elapsed time.Duration) *Report {
+	r := &Report{
+		Dataset:     cfg.Dataset.Name(),
+		Workload:    cfg.Workload.Name,
+		Scale:       cfg.Scale,
+		Concurrency: cfg.Concurrency,
+		Elapsed:     elapsed,
+	}
+
+	var reads, writes, all []time.Duration
+	for _, samples := range perWorker {
+		for _, s := range samples {
+			all = append(all, s.latency)
+			if s.read {
+				reads = append(reads, s.latency)
+			} else {
+				writes = append(writes, s.latency)
+			}
+			if s.err != nil {
+				r.Errors++
+				if len(r.ErrorSamples) < maxErrorSamples {
+					r.ErrorSamples = append(r.ErrorSamples, s.err.Error())
+				}
+			}
+		}
+	}
+
+	r.Operations = len(all)
+	r.Reads = summarize(reads)
+	r.Writes = summarize(writes)
+	r.All = summarize(all)
+	if elapsed > 0 {
+		r.Throughput = float64(r.Operations) / elapsed.Seconds()
+	}
+	return r
+}
+
+// summarize computes the latency distribution of durations, sorting it in
+// place.
+func summarize(durations []time.Duration) Latency {
+	if len(durations) == 0 {
+		return Latency{}
+	}
+	slices.Sort(durations)
+
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+
+	return Latency{
+		Count: len(durations),
+		Mean:  sum / time.Duration(len(durations)),
+		Min:   durations[0],
+		Max:   durations[len(durations)-1],
+		P50:   percentile(durations, 0.50),
+		P95:   percentile(durations, 0.95),
+		P99:   percentile(durations, 0.99),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[max(0, min(idx, len(sorted)-1))]
+}
+
+// String renders the report as a human-readable summary.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "dataset=%s workload=%s scale=%d concurrency=%d\n",
+		r.Dataset, r.Workload, r.Scale, r.Concurrency)
+	fmt.Fprintf(&sb, "operations=%d errors=%d load=%s elapsed=%s throughput=%.1f ops/s\n",
+		r.Operations, r.Errors, r.LoadTime.Round(time.Microsecond), r.Elapsed.Round(time.Microsecond), r.Throughput)
+	fmt.Fprintf(&sb, "%-6s %8s %12s %12s %12s %12s %12s\n", "", "count", "mean", "p50", "p95", "p99", "max")
+	for _, row := range []struct {
+		name string
+		lat  Latency
+	}{{"reads", r.Reads}, {"writes", r.Writes}, {"all", r.All}} {
+		fmt.Fprintf(&sb, "%-6s %8d %12s %12s %12s %12s %12s\n", row.name, row.lat.Count,
+			row.lat.Mean.Round(time.Microsecond), row.lat.P50.Round(time.Microsecond),
+			row.lat.P95.Round(time.Microsecond), row.lat.P99.Round(time.Microsecond),
+			row.lat.Max.Round(time.Microsecond))
+	}
+	for _, msg := range r.ErrorSamples {
+		fmt.Fprintf(&sb, "error: %s\n", msg)
+	}
+	return sb.String()
+}
@@ -0,0 +1,115 @@
+package bench
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxErrorSamples bounds the number of error messages kept in a report.
+const maxErrorSamples = 5
+
+// Config parameterizes a benchmark run.
+type Config struct {
+	Dataset     Dataset
+	Workload    Workload
+	Scale       int   // Rows loaded by Dataset.Setup
+	Operations  int   // Total statements issued after loading
+	Concurrency int   // Number of concurrent workers
+	Seed        int64 // Seed for the workers' random sources
+	SkipSetup   bool  // Reuse data loaded by an earlier run of the same Dataset value
+}
+
+// DefaultConfig returns a mixed key-value run sized for a quick evaluation.
+func DefaultConfig() Config {
+	return Config{
+		Dataset:     NewKeyValue(),
+		Workload:    Mixed,
+		Scale:       1000,
+		Operations:  1000,
+		Concurrency: 4,
+		Seed:        1,
+	}
+}
+
+func (c Config) validate() error {
+	switch {
+	case c.Dataset == nil:
+		return errors.New("bench: config has no dataset")
+	case c.Workload.ReadFraction < 0 || c.Workload.ReadFraction > 1:
+		return errors.New("bench: workload read fraction must be within [0, 1]")
+	case c.Scale < 0 || c.Operations < 0:
+		return errors.New("bench: scale and operations must not be negative")
+	case c.Concurrency < 1:
+		return errors.New("bench: concurrency must be at least 1")
+	}
+	return nil
+}
+
+// sample is the outcome of one statement.
+type sample struct {
+	latency time.Duration
+	read    bool
+	err     error
+}
+
+// Run loads cfg.Dataset into db unless cfg.SkipSetup is set, then issues
+// cfg.Operations statements from cfg.Concurrency workers and reports the
+// throughput and latencies observed. Statement failures are counted in the
+// report rather than aborting the run; only setup failures return an error.
+func Run(db Executor, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	var loadTime time.Duration
+	if !cfg.SkipSetup {
+		loadStart := time.Now()
+		if err := cfg.Dataset.Setup(db, cfg.Scale); err != nil {
+			return nil, err
+		}
+		loadTime = time.Since(loadStart)
+	}
+
+	var (
+		next    atomic.Int64
+		wg      sync.WaitGroup
+		samples = make([][]sample, cfg.Concurrency)
+	)
+
+	start := time.Now()
+	for w := range cfg.Concurrency {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(cfg.Seed + int64(w)))
+			for next.Add(1) <= int64(cfg.Operations) {
+				samples[w] = append(samples[w], runOne(db, cfg, rng))
+			}
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := newReport(cfg, samples, elapsed)
+	report.LoadTime = loadTime
+	return report, nil
+}
+
+// runOne issues a single read or write chosen according to the workload mix.
+func runOne(db Executor, cfg Config, rng *rand.Rand) sample {
+	read := rng.Float64() < cfg.Workload.ReadFraction
+
+	var query string
+	if read {
+		query = cfg.Dataset.ReadQuery(rng)
+	} else {
+		query = cfg.Dataset.WriteQuery(rng)
+	}
+
+	start := time.Now()
+	_, err := db.ExecuteQuery(query)
+	return sample{latency: time.Since(start), read: read, err: err}
+}
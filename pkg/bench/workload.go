@@ -0,0 +1,34 @@
+package bench
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Workload describes the mix of reads and writes issued by the runner.
+type Workload struct {
+	Name         string
+	ReadFraction float64 // Share of operations that are reads, in [0, 1]
+}
+
+// Canonical workloads.
+var (
+	ReadHeavy  = Workload{Name: "read-heavy", ReadFraction: 0.95}
+	WriteHeavy = Workload{Name: "write-heavy", ReadFraction: 0.05}
+	Mixed      = Workload{Name: "mixed", ReadFraction: 0.5}
+)
+
+// Workloads returns the canonical workloads.
+func Workloads() []Workload {
+	return []Workload{ReadHeavy, WriteHeavy, Mixed}
+}
+
+// WorkloadByName returns the canonical workload with the given name.
+func WorkloadByName(name string) (Workload, error) {
+	for _, w := range Workloads() {
+		if strings.EqualFold(w.Name, name) {
+			return w, nil
+		}
+	}
+	return Workload{}, fmt.Errorf("unknown workload %q (want read-heavy, write-heavy or mixed)", name)
+}
@@ -0,0 +1,105 @@
+// Package catalogerror defines the DBErrors returned by the system catalog,
+// shared by the catalog manager, its table cache and its I/O helpers.
+package catalogerror
+
+import (
+	"fmt"
+	dberror "storemy/pkg/error"
+)
+
+// Error codes of the DBErrors returned by the catalog
+const (
+	// ErrCodeTableNotFound indicates a table that is not in the catalog
+	ErrCodeTableNotFound = "CATALOG_TABLE_NOT_FOUND"
+
+	// ErrCodeTableExists indicates a table name that is already taken
+	ErrCodeTableExists = "CATALOG_TABLE_EXISTS"
+
+	// ErrCodeObjectNotFound indicates an index, trigger or other catalog
+	// object that does not exist
+	ErrCodeObjectNotFound = "CATALOG_OBJECT_NOT_FOUND"
+
+	// ErrCodeObjectExists indicates a catalog object that already exists
+	ErrCodeObjectExists = "CATALOG_OBJECT_EXISTS"
+
+	// ErrCodeColumnNotFound indicates a column the table does not have
+	ErrCodeColumnNotFound = "CATALOG_COLUMN_NOT_FOUND"
+
+	// ErrCodeInvalidDefinition indicates a definition the catalog rejects
+	ErrCodeInvalidDefinition = "CATALOG_INVALID_DEFINITION"
+
+	// ErrCodeCorrupt indicates catalog contents that are inconsistent
+	ErrCodeCorrupt = "CATALOG_CORRUPT"
+
+	// ErrCodeIO indicates reading or writing a catalog file failed
+	ErrCodeIO = "CATALOG_IO_ERROR"
+
+	// ErrCodeVersionTooNew indicates a catalog written by a newer release
+	ErrCodeVersionTooNew = "CATALOG_VERSION_TOO_NEW"
+)
+
+// newError creates a DBError of the catalog whose message is formatted from
+// format and args.
+func newError(category dberror.ErrorCategory, code, format string, args ...any) *dberror.DBError {
+	err := dberror.New(category, code, fmt.Sprintf(format, args...))
+	err.Component = "CatalogManager"
+	return err
+}
+
+// NewTableNotFound creates a DBError for a table that is not in the catalog.
+func NewTableNotFound(format string, args ...any) *dberror.DBError {
+	err := newError(dberror.ErrCategoryUser, ErrCodeTableNotFound, format, args...)
+	err.Hint = "Check the table name; SHOW TABLES lists the tables"
+	return err
+}
+
+// NewTableExists creates a DBError for a table name that is already taken.
+func NewTableExists(format string, args ...any) *dberror.DBError {
+	err := newError(dberror.ErrCategoryUser, ErrCodeTableExists, format, args...)
+	err.Hint = "Choose another name or drop the existing table first"
+	return err
+}
+
+// NewObjectNotFound creates a DBError for a catalog object that does not
+// exist.
+func NewObjectNotFound(format string, args ...any) *dberror.DBError {
+	return newError(dberror.ErrCategoryUser, ErrCodeObjectNotFound, format, args...)
+}
+
+// NewObjectExists creates a DBError for a catalog object that already exists.
+func NewObjectExists(format string, args ...any) *dberror.DBError {
+	return newError(dberror.ErrCategoryUser, ErrCodeObjectExists, format, args...)
+}
+
+// NewColumnNotFound creates a DBError for a column the table does not have.
+func NewColumnNotFound(format string, args ...any) *dberror.DBError {
+	return newError(dberror.ErrCategoryUser, ErrCodeColumnNotFound, format, args...)
+}
+
+// NewInvalidDefinition creates a DBError for a definition the catalog
+// rejects.
+func NewInvalidDefinition(format string, args ...any) *dberror.DBError {
+	return newError(dberror.ErrCategoryUser, ErrCodeInvalidDefinition, format, args...)
+}
+
+// NewCorrupt creates a DBError for catalog contents that are inconsistent.
+func NewCorrupt(format string, args ...any) *dberror.DBError {
+	err := newError(dberror.ErrCategoryData, ErrCodeCorrupt, format, args...)
+	err.Hint = "The catalog may be damaged; restore it from backup"
+	return err
+}
+
+// NewIOError creates a DBError for a catalog file that could not be read or
+// written because of cause.
+func NewIOError(cause error, format string, args ...any) *dberror.DBError {
+	err := newError(dberror.ErrCategorySystem, ErrCodeIO, format, args...)
+	err.Cause = cause
+	return err
+}
+
+// NewVersionTooNew creates a DBError for a catalog written by a newer release.
+func NewVersionTooNew(format string, args ...any) *dberror.DBError {
+	err := newError(dberror.ErrCategorySystem, ErrCodeVersionTooNew, format, args...)
+	err.Hint = "Open the database with the release that created it or a newer one"
+	return err
+}
@@ -2,6 +2,7 @@ package catalogio
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/tablecache"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/execution/scanner"
@@ -27,6 +28,7 @@ import (
 //   - IterateTable: Scan pages, apply MVCC, yield visible tuples
 //   - InsertRow: Write tuples to pages within transaction
 //   - DeleteRow: Mark tuples deleted (MVCC soft delete)
+//   - TableVersion: Track writes so that readers can cache table contents
 //
 // NOT responsible for:
 //   - Loading tables from CATALOG_TABLES (that's SystemCatalog's job)
@@ -35,9 +37,10 @@ import (
 //
 // This type can be used directly or embedded in higher-level catalog structures.
 type CatalogIO struct {
-	store  *memory.PageStore
-	cache  *tablecache.TableCache
-	tupMgr *table.TupleManager
+	store   *memory.PageStore
+	cache   *tablecache.TableCache
+	tupMgr  *table.TupleManager
+	changes *tableChanges
 }
 
 // NewCatalogIO creates a new CatalogIO instance with the given infrastructure components.
@@ -49,9 +52,10 @@ type CatalogIO struct {
 // Returns a fully initialized CatalogIO ready to perform I/O operations.
 func NewCatalogIO(store *memory.PageStore, cache *tablecache.TableCache) *CatalogIO {
 	return &CatalogIO{
-		store:  store,
-		cache:  cache,
-		tupMgr: table.NewTupleManager(store),
+		store:   store,
+		cache:   cache,
+		tupMgr:  table.NewTupleManager(store),
+		changes: newTableChanges(),
 	}
 }
 
@@ -77,7 +81,7 @@ func (cio *CatalogIO) IterateTable(tableID primitives.FileID, tx *transaction.Tr
 
 	heapFile, ok := file.(*heap.HeapFile)
 	if !ok {
-		return fmt.Errorf("table %d is not a heap file", tableID)
+		return catalogerror.NewCorrupt("table %d is not a heap file", tableID)
 	}
 
 	iter, err := scanner.NewSeqScan(tx, tableID, heapFile, cio.store)
@@ -112,6 +116,7 @@ func (cio *CatalogIO) InsertRow(tableID primitives.FileID, tx *transaction.Trans
 	if err != nil {
 		return fmt.Errorf("failed to get table file for ID %d: %w", tableID, err)
 	}
+	cio.changes.recordWrite(tableID, tx)
 	return cio.tupMgr.InsertTuple(tx, file, tup)
 }
 
@@ -138,9 +143,17 @@ func (cio *CatalogIO) DeleteRow(
 	if err != nil {
 		return fmt.Errorf("failed to get table file for ID %d: %w", tableID, err)
 	}
+	cio.changes.recordWrite(tableID, tx)
 	return cio.tupMgr.DeleteTuple(tx, file, tup)
 }
 
+// TableVersion implements ChangeTracker.TableVersion.
+// Every write to a catalog table goes through InsertRow or DeleteRow, which
+// record the writing transaction until it commits or aborts.
+func (cio *CatalogIO) TableVersion(tableID primitives.FileID, tx *transaction.TransactionContext) (uint64, bool) {
+	return cio.changes.version(tableID)
+}
+
 // GetCache returns the underlying TableCache.
 // This is useful when higher-level components need direct cache access.
 func (cio *CatalogIO) Cache() *tablecache.TableCache {
@@ -0,0 +1,61 @@
+package catalogio
+
+import (
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/primitives"
+	"sync"
+)
+
+// tableChanges tracks writes to catalog tables for CatalogIO.TableVersion.
+//
+// A write raises the version of its table and registers its transaction as
+// a writer of the table until the transaction commits or aborts. Writers
+// are dropped lazily, the next time the version of the table is asked for,
+// and dropping one raises the version again since the committed contents
+// changed.
+type tableChanges struct {
+	mutex    sync.Mutex
+	versions map[primitives.FileID]uint64
+	writers  map[primitives.FileID]map[*transaction.TransactionContext]struct{}
+}
+
+func newTableChanges() *tableChanges {
+	return &tableChanges{
+		versions: make(map[primitives.FileID]uint64),
+		writers:  make(map[primitives.FileID]map[*transaction.TransactionContext]struct{}),
+	}
+}
+
+// recordWrite notes that tx is about to write to table tableID. It must be
+// called before the write, so that a reader scanning the table concurrently
+// sees the version change.
+func (tc *tableChanges) recordWrite(tableID primitives.FileID, tx *transaction.TransactionContext) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	tc.versions[tableID]++
+	if tx == nil {
+		return
+	}
+	writers, ok := tc.writers[tableID]
+	if !ok {
+		writers = make(map[*transaction.TransactionContext]struct{})
+		tc.writers[tableID] = writers
+	}
+	writers[tx] = struct{}{}
+}
+
+// version implements ChangeTracker.TableVersion.
+func (tc *tableChanges) version(tableID primitives.FileID) (uint64, bool) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	writers := tc.writers[tableID]
+	for tx := range writers {
+		if !tx.IsActive() {
+			delete(writers, tx)
+			tc.versions[tableID]++
+		}
+	}
+	return tc.versions[tableID], len(writers) == 0
+}
@@ -59,3 +59,17 @@ type CatalogAccess interface {
 	CatalogReader
 	CatalogWriter
 }
+
+// ChangeTracker reports whether catalog tables changed, so that readers can
+// cache the committed contents of a table instead of scanning it per lookup.
+type ChangeTracker interface {
+	// TableVersion returns the version of the committed contents of a table.
+	// The version changes whenever the table is written. ok is false while
+	// any transaction, tx included, has uncommitted writes to the table: a
+	// cached copy can be neither used nor taken until they finish.
+	//
+	// Parameters:
+	//   - tableID: ID of the table
+	//   - tx: Transaction context of the reader
+	TableVersion(tableID primitives.FileID, tx *transaction.TransactionContext) (version uint64, ok bool)
+}
@@ -0,0 +1,76 @@
+package catalogmanager
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/primitives"
+)
+
+// AuditMetadata is a type alias for easier use
+type AuditMetadata = systemtable.AuditMetadata
+
+// EnableAudit records in CATALOG_AUDITED_TABLES that the row changes of a
+// table are audited to another table.
+// Returns an error if either table does not exist or the table is already audited.
+func (cm *CatalogManager) EnableAudit(tx TxContext, md AuditMetadata) error {
+	for _, id := range []primitives.FileID{md.TableID, md.AuditTableID} {
+		if _, err := cm.tableOps.GetTableMetadataByID(tx, id); err != nil {
+			return fmt.Errorf("table %d does not exist: %w", id, err)
+		}
+	}
+
+	existing, err := cm.GetAudit(tx, md.TableID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return catalogerror.NewObjectExists("table %d is already audited", md.TableID)
+	}
+	return cm.InsertRow(cm.SystemTabs.AuditedTablesTableID, tx, systemtable.AuditedTables.CreateTuple(md))
+}
+
+// GetAudit returns the audit record of a table, or nil if the table is not
+// audited.
+func (cm *CatalogManager) GetAudit(tx TxContext, tableID primitives.FileID) (*AuditMetadata, error) {
+	return cm.findAudit(tx, func(md *AuditMetadata) bool { return md.TableID == tableID })
+}
+
+// GetAuditedBy returns the audit record whose audit rows go to auditTableID,
+// or nil if the table is not an audit table.
+func (cm *CatalogManager) GetAuditedBy(tx TxContext, auditTableID primitives.FileID) (*AuditMetadata, error) {
+	return cm.findAudit(tx, func(md *AuditMetadata) bool { return md.AuditTableID == auditTableID })
+}
+
+// DisableAudit removes the audit record of a table. Its audit table and the
+// rows already in it are kept. Returns an error if the table is not audited.
+func (cm *CatalogManager) DisableAudit(tx TxContext, tableID primitives.FileID) error {
+	existing, err := cm.GetAudit(tx, tableID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return catalogerror.NewObjectNotFound("table %d is not audited", tableID)
+	}
+	return cm.DeleteTableFromSysTable(tx, tableID, cm.SystemTabs.AuditedTablesTableID)
+}
+
+// findAudit returns the first audit record match accepts, or nil if there is
+// none.
+func (cm *CatalogManager) findAudit(tx TxContext, match func(*AuditMetadata) bool) (*AuditMetadata, error) {
+	var found *AuditMetadata
+	err := cm.iterateTable(cm.SystemTabs.AuditedTablesTableID, tx, func(t Tuple) error {
+		md, err := systemtable.AuditedTables.Parse(t)
+		if err != nil {
+			return err
+		}
+		if found == nil && match(md) {
+			found = md
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audited tables: %w", err)
+	}
+	return found, nil
+}
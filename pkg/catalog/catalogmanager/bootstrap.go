@@ -0,0 +1,181 @@
+package catalogmanager
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/catalog/systemtable"
+)
+
+// CatalogVersion is the version of the system catalog written by this release.
+// Initialize upgrades the catalog of a data directory written by an older
+// release to this version, and refuses one written by a newer release.
+const CatalogVersion uint32 = 7
+
+// CatalogVersionFile is the file in the data directory that records the
+// version of its catalog.
+const CatalogVersionFile = "catalog.version"
+
+// catalogVersionMagic identifies a catalog version file.
+var catalogVersionMagic = [4]byte{'S', 'M', 'C', 'V'}
+
+// catalogUpgrade is one version in the history of the system catalog: the
+// system tables it introduced and the migration, if any, that converts the
+// rows of existing system tables to the layout of this version.
+//
+// Migrations run in the transaction passed to Initialize, after every system
+// table has been opened. A crash before the new version is recorded runs them
+// again on the next start, so they must be idempotent.
+type catalogUpgrade struct {
+	version     uint32
+	description string
+	tables      []systemtable.SystemTable
+	migrate     func(cm *CatalogManager, tx TxContext) error
+}
+
+// catalogUpgrades lists every catalog version in order. Adding a system table
+// or changing the layout of one means appending a version here and raising
+// CatalogVersion.
+var catalogUpgrades = []catalogUpgrade{
+	{
+		version:     1,
+		description: "base catalog",
+		tables: []systemtable.SystemTable{
+			systemtable.Tables, systemtable.Columns, systemtable.Stats,
+			systemtable.Indexes, systemtable.ColumnStats, systemtable.IndexStats,
+		},
+	},
+	{version: 2, description: "constraints", tables: []systemtable.SystemTable{systemtable.Constraints}},
+	{version: 3, description: "schema migrations", tables: []systemtable.SystemTable{systemtable.Migrations}},
+	{version: 4, description: "foreign tables", tables: []systemtable.SystemTable{systemtable.ForeignTables}},
+	{version: 5, description: "triggers", tables: []systemtable.SystemTable{systemtable.Triggers}},
+	{version: 6, description: "audited tables", tables: []systemtable.SystemTable{systemtable.AuditedTables}},
+	{version: 7, description: "cache hints", tables: []systemtable.SystemTable{systemtable.CacheHints}},
+}
+
+// SetReadOnly makes Initialize leave the catalog of an older data directory at
+// its version: no migration runs and no version is recorded.
+func (cm *CatalogManager) SetReadOnly(readOnly bool) {
+	cm.readOnly = readOnly
+}
+
+// Version returns the version of the catalog after Initialize. It is
+// CatalogVersion unless the catalog is read-only and was written by an older
+// release.
+func (cm *CatalogManager) Version() uint32 {
+	return cm.version
+}
+
+// storedCatalogVersion returns the version of the catalog in the data
+// directory, 0 for a new directory.
+//
+// Directories written before catalog versions were recorded are dated by the
+// system table files they hold: their version is the last one whose tables
+// are all present.
+func (cm *CatalogManager) storedCatalogVersion() (uint32, error) {
+	data, err := os.ReadFile(cm.catalogVersionPath())
+	if err == nil {
+		return decodeCatalogVersion(data)
+	}
+	if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read catalog version: %w", err)
+	}
+
+	var version uint32
+	for _, u := range catalogUpgrades {
+		for _, table := range u.tables {
+			if _, err := os.Stat(filepath.Join(cm.dataDir, table.FileName())); err != nil {
+				return version, nil
+			}
+		}
+		version = u.version
+	}
+	return version, nil
+}
+
+// upgrade brings a catalog from version from up to CatalogVersion. The system
+// tables of the newer versions were created empty when Initialize opened
+// them; upgrade runs the migrations of those versions in tx and commits it,
+// and only then records the new version.
+func (cm *CatalogManager) upgrade(tx TxContext, from uint32) error {
+	if from == CatalogVersion || cm.readOnly {
+		cm.version = from
+		return nil
+	}
+
+	for _, u := range catalogUpgrades {
+		if u.version <= from || u.migrate == nil {
+			continue
+		}
+		if err := u.migrate(cm, tx); err != nil {
+			return fmt.Errorf("failed to upgrade catalog to version %d (%s): %w", u.version, u.description, err)
+		}
+	}
+
+	if err := cm.store.CommitTransaction(tx); err != nil {
+		return fmt.Errorf("failed to commit catalog upgrade: %w", err)
+	}
+	if err := writeCatalogVersion(cm.catalogVersionPath(), CatalogVersion); err != nil {
+		return err
+	}
+
+	if from > 0 {
+		cm.logger.Info("catalog upgraded", "from_version", from, "to_version", CatalogVersion)
+	}
+	cm.version = CatalogVersion
+	return nil
+}
+
+func (cm *CatalogManager) catalogVersionPath() string {
+	return filepath.Join(cm.dataDir, CatalogVersionFile)
+}
+
+// Binary format of the catalog version file:
+// [Magic:4][Version:4][CRC32:4]
+func encodeCatalogVersion(version uint32) []byte {
+	data := make([]byte, 12)
+	copy(data[0:4], catalogVersionMagic[:])
+	binary.BigEndian.PutUint32(data[4:8], version)
+	binary.BigEndian.PutUint32(data[8:12], crc32.ChecksumIEEE(data[0:8]))
+	return data
+}
+
+func decodeCatalogVersion(data []byte) (uint32, error) {
+	if len(data) != 12 || [4]byte(data[0:4]) != catalogVersionMagic {
+		return 0, catalogerror.NewCorrupt("%s is not a catalog version file", CatalogVersionFile)
+	}
+	if crc32.ChecksumIEEE(data[0:8]) != binary.BigEndian.Uint32(data[8:12]) {
+		return 0, catalogerror.NewCorrupt("%s is corrupted: checksum mismatch", CatalogVersionFile)
+	}
+	return binary.BigEndian.Uint32(data[4:8]), nil
+}
+
+// writeCatalogVersion records version in path so that a crash leaves either
+// the old or the new version: it is written to a temporary file, synced and
+// renamed over path.
+func writeCatalogVersion(path string, version uint32) error {
+	tempPath := path + ".tmp"
+	f, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return catalogerror.NewIOError(err, "failed to write catalog version")
+	}
+
+	_, err = f.Write(encodeCatalogVersion(version))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return catalogerror.NewIOError(err, "failed to write catalog version")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package catalogmanager
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/memory"
+	"storemy/pkg/primitives"
+)
+
+// CacheHintMetadata is a type alias for easier use
+type CacheHintMetadata = systemtable.CacheHintMetadata
+
+// SetCacheHint records in CATALOG_CACHE_HINTS the eviction priority of a
+// table's pages and applies it to the buffer pool. memory.CacheNormal removes
+// the hint.
+// Returns an error if the table does not exist.
+func (cm *CatalogManager) SetCacheHint(tx TxContext, tableID primitives.FileID, priority memory.CachePriority) error {
+	if _, err := cm.tableOps.GetTableMetadataByID(tx, tableID); err != nil {
+		return fmt.Errorf("table %d does not exist: %w", tableID, err)
+	}
+
+	if err := cm.DeleteTableFromSysTable(tx, tableID, cm.SystemTabs.CacheHintsTableID); err != nil {
+		return err
+	}
+	if priority != memory.CacheNormal {
+		md := CacheHintMetadata{TableID: tableID, Priority: priority.String()}
+		if err := cm.InsertRow(cm.SystemTabs.CacheHintsTableID, tx, systemtable.CacheHints.CreateTuple(md)); err != nil {
+			return err
+		}
+	}
+
+	cm.store.SetCachePriority(tableID, priority)
+	return nil
+}
+
+// GetCacheHints returns the cache hints of every table that has one.
+func (cm *CatalogManager) GetCacheHints(tx TxContext) ([]*CacheHintMetadata, error) {
+	var hints []*CacheHintMetadata
+	err := cm.iterateTable(cm.SystemTabs.CacheHintsTableID, tx, func(t Tuple) error {
+		md, err := systemtable.CacheHints.Parse(t)
+		if err != nil {
+			return err
+		}
+		hints = append(hints, md)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache hints: %w", err)
+	}
+	return hints, nil
+}
+
+// applyCacheHints gives the buffer pool the eviction priority of every table
+// with a cache hint.
+func (cm *CatalogManager) applyCacheHints(tx TxContext) error {
+	hints, err := cm.GetCacheHints(tx)
+	if err != nil {
+		return err
+	}
+	for _, md := range hints {
+		priority, err := memory.ParseCachePriority(md.Priority)
+		if err != nil {
+			return fmt.Errorf("table %d: %w", md.TableID, err)
+		}
+		cm.store.SetCachePriority(md.TableID, priority)
+	}
+	return nil
+}
@@ -2,15 +2,18 @@ package catalogmanager
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/catalogio"
 	ops "storemy/pkg/catalog/operations"
 	"storemy/pkg/catalog/systemtable"
 	"storemy/pkg/catalog/tablecache"
+	"storemy/pkg/logging"
 	"storemy/pkg/memory"
-	"storemy/pkg/memory/wrappers/table"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/heap"
+	"storemy/pkg/vfs"
 	"sync"
 )
 
@@ -38,7 +41,6 @@ type CatalogManager struct {
 	io         *catalogio.CatalogIO
 	store      *memory.PageStore
 	tableCache *tablecache.TableCache
-	tupMgr     *table.TupleManager
 	dataDir    string
 	SystemTabs SystemTableIDs
 
@@ -47,13 +49,19 @@ type CatalogManager struct {
 	openFiles map[primitives.FileID]*heap.HeapFile
 
 	// Domain-specific operation handlers
-	indexOps       *ops.IndexOperations
-	colOps         *ops.ColumnOperations
-	statsOps       *ops.StatsOperations
-	tableOps       *ops.TableOperations
-	colStatsOps    *ops.ColStatsOperations
-	indexStatsOps  *ops.IndexStatsOperations
-	constraintOps  *ops.ConstraintOperations
+	indexOps      *ops.IndexOperations
+	colOps        *ops.ColumnOperations
+	statsOps      *ops.StatsOperations
+	tableOps      *ops.TableOperations
+	colStatsOps   *ops.ColStatsOperations
+	indexStatsOps *ops.IndexStatsOperations
+	constraintOps *ops.ConstraintOperations
+
+	// Catalog version, set by Initialize
+	version  uint32
+	readOnly bool
+
+	logger logging.ComponentLogger
 }
 
 // NewCatalogManager creates a new CatalogManager instance.
@@ -74,11 +82,19 @@ func NewCatalogManager(ps *memory.PageStore, dataDir string) *CatalogManager {
 		store:      ps,
 		tableCache: cache,
 		dataDir:    dataDir,
-		tupMgr:     table.NewTupleManager(ps),
 		openFiles:  make(map[primitives.FileID]*heap.HeapFile),
+		logger:     logging.ForComponent("catalog"),
 	}
 }
 
+// SetLogger replaces the logger used to report catalog failures that cannot be
+// returned to the caller, such as failed rollbacks and table files that fail
+// to close.
+func (cm *CatalogManager) SetLogger(logger logging.ComponentLogger) {
+	cm.logger = logger
+	cm.tableCache.SetLogger(logger)
+}
+
 // Initialize creates or loads all system catalog tables and registers them with the page store.
 //
 // This must be called before any other catalog operations.
@@ -92,24 +108,60 @@ func NewCatalogManager(ps *memory.PageStore, dataDir string) *CatalogManager {
 //   - CATALOG_COLUMN_STATISTICS: column-level statistics for selectivity estimation
 //   - CATALOG_INDEX_STATISTICS: index statistics for query optimization
 //   - CATALOG_CONSTRAINTS: constraint metadata (ID, name, type, columns, referenced table)
+//   - CATALOG_SCHEMA_MIGRATIONS: applied schema migrations (version, name, checksum, applied at)
+//   - CATALOG_FOREIGN_TABLES: foreign table definitions (name, format, location, columns)
+//   - CATALOG_TRIGGERS: trigger definitions (name, table, timing, event, function)
+//   - CATALOG_AUDITED_TABLES: audited tables and the tables their audit rows go to
+//   - CATALOG_CACHE_HINTS: buffer pool eviction priorities of tables
 //
 // The operation handlers are initialized after system tables are created.
+// A catalog written by an older release is then upgraded to CatalogVersion:
+// the system tables it lacks are created empty, the migrations of the newer
+// versions run, and the new version is recorded in CatalogVersionFile.
+// Initialize is idempotent: on an up-to-date catalog it only opens the tables.
+// In read-only mode nothing is written to the data directory: the system
+// tables an older catalog lacks are opened empty in memory instead.
 // The transaction is committed upon successful completion.
 //
 // Parameters:
 //   - ctx: Transaction context for all catalog initialization operations
 //
-// Returns error if any system table cannot be created, loaded, or registered.
+// Returns error if any system table cannot be created, loaded, or registered,
+// if the upgrade fails, or if the catalog was written by a newer release.
 func (cm *CatalogManager) Initialize(ctx TxContext) error {
 	defer cm.store.CommitTransaction(ctx)
 
+	version, err := cm.storedCatalogVersion()
+	if err != nil {
+		return err
+	}
+	if version > CatalogVersion {
+		return catalogerror.NewVersionTooNew("catalog version %d is newer than version %d supported by this release", version, CatalogVersion)
+	}
+
 	systemTables := systemtable.AllSystemTables
 
+	var missing vfs.FS // Holds the system tables a read-only catalog lacks
 	for _, table := range systemTables {
 		sch := table.Schema()
 
+		fsys := cm.store.FS()
 		path := primitives.Filepath(filepath.Join(cm.dataDir, table.FileName()))
-		f, err := heap.NewHeapFile(path, sch.TupleDesc)
+		_, err := os.Stat(string(path))
+		switch {
+		case cm.readOnly && os.IsNotExist(err):
+			if missing == nil {
+				missing = vfs.NewMemFS()
+				if err := missing.MkdirAll(cm.dataDir, 0755); err != nil {
+					return fmt.Errorf("failed to prepare missing system tables: %w", err)
+				}
+			}
+			fsys = missing
+			cm.logger.Info("opening missing system table empty in memory", "table", table.TableName(), "catalog_version", version)
+		case version > 0 && os.IsNotExist(err):
+			cm.logger.Info("creating missing system table", "table", table.TableName(), "catalog_version", version)
+		}
+		f, err := heap.NewHeapFileWithFS(fsys, path, sch.TupleDesc)
 		if err != nil {
 			return fmt.Errorf("failed to initialize %s: %w", table.TableName(), err)
 		}
@@ -126,7 +178,7 @@ func (cm *CatalogManager) Initialize(ctx TxContext) error {
 	}
 
 	cm.setupSysTables()
-	return nil
+	return cm.upgrade(ctx, version)
 }
 
 // setupSysTables initializes all domain-specific operation handlers with their respective system table IDs.
@@ -0,0 +1,245 @@
+package catalogmanager
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/logging"
+	"storemy/pkg/memory"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+// reopenTest opens a fresh catalog manager over the data directory of a
+// previous test environment, as a restart would.
+func reopenTest(t *testing.T, dir string) *testSetup {
+	w, err := wal.NewWAL(filepath.Join(dir, "test.wal"), 8192)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+
+	store := memory.NewPageStore(w)
+	catalogMgr := NewCatalogManager(store, dir)
+	catalogMgr.SetLogger(logging.NewNopLogger())
+	return &testSetup{
+		tempDir:    dir,
+		catalogMgr: catalogMgr,
+		txRegistry: transaction.NewTransactionRegistry(w),
+		store:      store,
+		wal:        w,
+		t:          t,
+	}
+}
+
+func TestCatalogUpgrades_CoverEverySystemTable(t *testing.T) {
+	introduced := make(map[string]uint32)
+	for i, u := range catalogUpgrades {
+		if u.version != uint32(i+1) {
+			t.Errorf("upgrade %d has version %d, want %d", i, u.version, i+1)
+		}
+		for _, table := range u.tables {
+			if v, ok := introduced[table.TableName()]; ok {
+				t.Errorf("%s introduced by both version %d and %d", table.TableName(), v, u.version)
+			}
+			introduced[table.TableName()] = u.version
+		}
+	}
+
+	if last := catalogUpgrades[len(catalogUpgrades)-1].version; last != CatalogVersion {
+		t.Errorf("last upgrade has version %d, but CatalogVersion is %d", last, CatalogVersion)
+	}
+	for _, table := range systemtable.AllSystemTables {
+		if _, ok := introduced[table.TableName()]; !ok {
+			t.Errorf("%s is not introduced by any catalog version", table.TableName())
+		}
+	}
+}
+
+func TestInitialize_RecordsCatalogVersion(t *testing.T) {
+	setup := setupTest(t)
+	if err := setup.catalogMgr.Initialize(setup.beginTx()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if v := setup.catalogMgr.Version(); v != CatalogVersion {
+		t.Errorf("Version() = %d, want %d", v, CatalogVersion)
+	}
+	setup.cleanup()
+
+	// Initializing again finds the recorded version and changes nothing
+	reopened := reopenTest(t, setup.tempDir)
+	defer reopened.cleanup()
+	stored, err := reopened.catalogMgr.storedCatalogVersion()
+	if err != nil || stored != CatalogVersion {
+		t.Fatalf("stored version = %d, %v; want %d", stored, err, CatalogVersion)
+	}
+	if err := reopened.catalogMgr.Initialize(reopened.beginTx()); err != nil {
+		t.Fatalf("second Initialize failed: %v", err)
+	}
+	if v := reopened.catalogMgr.Version(); v != CatalogVersion {
+		t.Errorf("Version() after reopening = %d, want %d", v, CatalogVersion)
+	}
+}
+
+func TestInitialize_UpgradesOlderCatalog(t *testing.T) {
+	setup := setupTest(t)
+	if err := setup.catalogMgr.Initialize(setup.beginTx()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	tx := setup.beginTx()
+	tableID, err := setup.catalogMgr.CreateTable(tx, createTestSchema("users", "id", []FieldMetadata{
+		{Name: "id", Type: types.IntType},
+		{Name: "name", Type: types.StringType},
+	}))
+	if err != nil {
+		t.Fatalf("CreateTable failed: %v", err)
+	}
+	setup.commitTx(tx)
+	setup.cleanup()
+
+	// Turn the directory into one written by a release with the base catalog only
+	os.Remove(filepath.Join(setup.tempDir, CatalogVersionFile))
+	for _, u := range catalogUpgrades[1:] {
+		for _, table := range u.tables {
+			if err := os.Remove(filepath.Join(setup.tempDir, table.FileName())); err != nil {
+				t.Fatalf("failed to remove %s: %v", table.FileName(), err)
+			}
+		}
+	}
+
+	reopened := reopenTest(t, setup.tempDir)
+	defer reopened.cleanup()
+	if stored, _ := reopened.catalogMgr.storedCatalogVersion(); stored != 1 {
+		t.Fatalf("stored version of the old directory = %d, want 1", stored)
+	}
+
+	if err := reopened.catalogMgr.Initialize(reopened.beginTx()); err != nil {
+		t.Fatalf("Initialize of the old directory failed: %v", err)
+	}
+	if v := reopened.catalogMgr.Version(); v != CatalogVersion {
+		t.Errorf("Version() after upgrade = %d, want %d", v, CatalogVersion)
+	}
+	for _, table := range systemtable.AllSystemTables {
+		if _, err := os.Stat(filepath.Join(setup.tempDir, table.FileName())); err != nil {
+			t.Errorf("%s was not created: %v", table.TableName(), err)
+		}
+	}
+
+	tx = reopened.beginTx()
+	if err := reopened.catalogMgr.LoadAllTables(tx); err != nil {
+		t.Fatalf("LoadAllTables failed: %v", err)
+	}
+	if id, err := reopened.catalogMgr.GetTableID(tx, "users"); err != nil || id != tableID {
+		t.Errorf("GetTableID(users) = %d, %v; want %d", id, err, tableID)
+	}
+	if _, err := reopened.catalogMgr.GetAppliedMigrations(tx); err != nil {
+		t.Errorf("new CATALOG_SCHEMA_MIGRATIONS is not usable: %v", err)
+	}
+}
+
+func TestInitialize_ReadOnlyKeepsOlderVersion(t *testing.T) {
+	setup := setupTest(t)
+	defer setup.cleanup()
+
+	// A directory holding only the base catalog
+	for _, table := range catalogUpgrades[0].tables {
+		if err := os.WriteFile(filepath.Join(setup.tempDir, table.FileName()), nil, 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", table.FileName(), err)
+		}
+	}
+
+	setup.catalogMgr.SetReadOnly(true)
+	if err := setup.catalogMgr.Initialize(setup.beginTx()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if v := setup.catalogMgr.Version(); v != 1 {
+		t.Errorf("Version() = %d, want 1", v)
+	}
+	if _, err := os.Stat(filepath.Join(setup.tempDir, CatalogVersionFile)); !os.IsNotExist(err) {
+		t.Errorf("read-only Initialize recorded a catalog version: %v", err)
+	}
+}
+
+func TestInitialize_ReadOnlyCreatesNoFiles(t *testing.T) {
+	setup := setupTest(t)
+	if err := setup.catalogMgr.Initialize(setup.beginTx()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	setup.cleanup()
+
+	// Turn the directory into one written by a release with catalog version 6
+	os.Remove(filepath.Join(setup.tempDir, CatalogVersionFile))
+	for _, table := range catalogUpgrades[6].tables {
+		if err := os.Remove(filepath.Join(setup.tempDir, table.FileName())); err != nil {
+			t.Fatalf("failed to remove %s: %v", table.FileName(), err)
+		}
+	}
+	before := dirNames(t, setup.tempDir)
+
+	reopened := reopenTest(t, setup.tempDir)
+	defer reopened.cleanup()
+	reopened.catalogMgr.SetReadOnly(true)
+	if err := reopened.catalogMgr.Initialize(reopened.beginTx()); err != nil {
+		t.Fatalf("read-only Initialize failed: %v", err)
+	}
+	if v := reopened.catalogMgr.Version(); v != 6 {
+		t.Errorf("Version() = %d, want 6", v)
+	}
+	if after := dirNames(t, setup.tempDir); !slices.Equal(after, before) {
+		t.Errorf("read-only Initialize changed the data directory from %v to %v", before, after)
+	}
+
+	// The missing table reads as empty
+	hints, err := reopened.catalogMgr.GetCacheHints(reopened.beginTx())
+	if err != nil || len(hints) != 0 {
+		t.Errorf("GetCacheHints = %v, %v; want no hints", hints, err)
+	}
+}
+
+// dirNames returns the sorted names of the files in dir.
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestInitialize_RejectsNewerCatalog(t *testing.T) {
+	setup := setupTest(t)
+	defer setup.cleanup()
+
+	path := filepath.Join(setup.tempDir, CatalogVersionFile)
+	if err := writeCatalogVersion(path, CatalogVersion+1); err != nil {
+		t.Fatalf("writeCatalogVersion failed: %v", err)
+	}
+
+	err := setup.catalogMgr.Initialize(setup.beginTx())
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected an error for a newer catalog, got %v", err)
+	}
+}
+
+func TestDecodeCatalogVersion_Corrupted(t *testing.T) {
+	data := encodeCatalogVersion(3)
+	if v, err := decodeCatalogVersion(data); err != nil || v != 3 {
+		t.Fatalf("decodeCatalogVersion = %d, %v; want 3", v, err)
+	}
+
+	data[5] ^= 0xFF
+	if _, err := decodeCatalogVersion(data); err == nil {
+		t.Error("expected a checksum error")
+	}
+	if _, err := decodeCatalogVersion([]byte("garbage")); err == nil {
+		t.Error("expected an error for a file that is not a catalog version file")
+	}
+}
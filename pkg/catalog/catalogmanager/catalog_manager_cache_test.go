@@ -0,0 +1,128 @@
+package catalogmanager
+
+import (
+	"storemy/pkg/types"
+	"testing"
+)
+
+func TestCatalogManager_ConstraintLookupsFollowTransactions(t *testing.T) {
+	setup := setupTest(t)
+	defer setup.cleanup()
+
+	if err := setup.catalogMgr.Initialize(setup.beginTx()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	tx := setup.beginTx()
+	tableID, err := setup.catalogMgr.CreateTable(tx, createTestSchema("accounts", "id", []FieldMetadata{
+		{Name: "id", Type: types.IntType},
+		{Name: "email", Type: types.StringType},
+	}))
+	if err != nil {
+		t.Fatalf("CreateTable failed: %v", err)
+	}
+	constraintID, err := setup.catalogMgr.CreateUniqueConstraint(tx, tableID, "uq_email", "email")
+	if err != nil {
+		t.Fatalf("CreateUniqueConstraint failed: %v", err)
+	}
+	setup.commitTx(tx)
+
+	enabledConstraints := func(tx TxContext) int {
+		t.Helper()
+		constraints, err := setup.catalogMgr.GetEnabledConstraintsForTable(tx, tableID)
+		if err != nil {
+			t.Fatalf("GetEnabledConstraintsForTable failed: %v", err)
+		}
+		return len(constraints)
+	}
+
+	reader := setup.beginTx()
+	if n := enabledConstraints(reader); n != 1 {
+		t.Fatalf("expected 1 enabled constraint, got %d", n)
+	}
+	// Served from the cached copy
+	if n := enabledConstraints(reader); n != 1 {
+		t.Fatalf("expected 1 enabled constraint on a repeated lookup, got %d", n)
+	}
+	setup.commitTx(reader)
+
+	// A transaction sees its own changes, and an abort throws them away
+	writer := setup.beginTx()
+	if err := setup.catalogMgr.DisableConstraint(writer, constraintID); err != nil {
+		t.Fatalf("DisableConstraint failed: %v", err)
+	}
+	if n := enabledConstraints(writer); n != 0 {
+		t.Errorf("expected the writer to see its disabled constraint, got %d enabled", n)
+	}
+	if err := setup.store.AbortTransaction(writer); err != nil {
+		t.Fatalf("AbortTransaction failed: %v", err)
+	}
+
+	reader = setup.beginTx()
+	if n := enabledConstraints(reader); n != 1 {
+		t.Errorf("expected the aborted change to be gone, got %d enabled", n)
+	}
+	setup.commitTx(reader)
+
+	// Committed changes are seen by later transactions
+	writer = setup.beginTx()
+	if err := setup.catalogMgr.DisableConstraint(writer, constraintID); err != nil {
+		t.Fatalf("DisableConstraint failed: %v", err)
+	}
+	setup.commitTx(writer)
+
+	reader = setup.beginTx()
+	if n := enabledConstraints(reader); n != 0 {
+		t.Errorf("expected the committed change to be seen, got %d enabled", n)
+	}
+	setup.commitTx(reader)
+}
+
+func TestConstraintValidator_CachesUntilConstraintsChange(t *testing.T) {
+	setup := setupTest(t)
+	defer setup.cleanup()
+
+	if err := setup.catalogMgr.Initialize(setup.beginTx()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	tx := setup.beginTx()
+	tableID, err := setup.catalogMgr.CreateTable(tx, createTestSchema("accounts", "id", []FieldMetadata{
+		{Name: "id", Type: types.IntType},
+		{Name: "email", Type: types.StringType},
+	}))
+	if err != nil {
+		t.Fatalf("CreateTable failed: %v", err)
+	}
+	setup.commitTx(tx)
+
+	tx = setup.beginTx()
+	defer setup.commitTx(tx)
+	validator := setup.catalogMgr.GetConstraintValidator(nil)
+
+	first, err := validator.GetEnabledConstraintsForTable(tx, tableID)
+	if err != nil || len(first) != 0 {
+		t.Fatalf("expected no constraints, got %v, %v", first, err)
+	}
+
+	constraintID, err := setup.catalogMgr.CreateNotNullConstraint(tx, tableID, "nn_email", "email")
+	if err != nil {
+		t.Fatalf("CreateNotNullConstraint failed: %v", err)
+	}
+	afterAdd, _ := validator.GetEnabledConstraintsForTable(tx, tableID)
+	if len(afterAdd) != 1 {
+		t.Fatalf("expected the new constraint to invalidate the cache, got %d constraints", len(afterAdd))
+	}
+
+	again, _ := validator.GetEnabledConstraintsForTable(tx, tableID)
+	if len(again) != 1 || again[0] != afterAdd[0] {
+		t.Error("expected a repeated lookup to reuse the cached constraints")
+	}
+
+	if err := setup.catalogMgr.DisableConstraint(tx, constraintID); err != nil {
+		t.Fatalf("DisableConstraint failed: %v", err)
+	}
+	if afterDisable, _ := validator.GetEnabledConstraintsForTable(tx, tableID); len(afterDisable) != 0 {
+		t.Errorf("expected the disabled constraint to invalidate the cache, got %d constraints", len(afterDisable))
+	}
+}
@@ -1,6 +1,8 @@
 package catalogmanager
 
 import (
+	"slices"
+	"storemy/pkg/primitives"
 	"storemy/pkg/storage/index"
 	"storemy/pkg/types"
 	"testing"
@@ -318,8 +320,9 @@ func TestCatalogManager_ValidateIntegrity_DetectsInconsistencies(t *testing.T) {
 	}
 }
 
-// TestCatalogManager_ListAllTables_MemoryVsDisk tests consistency between memory and disk listings
-func TestCatalogManager_ListAllTables_MemoryVsDisk(t *testing.T) {
+// TestCatalogManager_ListAllTables_Consistent tests that the listing follows
+// the disk catalog rather than whatever the cache happens to hold
+func TestCatalogManager_ListAllTables_Consistent(t *testing.T) {
 	setup := setupTest(t)
 	defer setup.cleanup()
 
@@ -330,47 +333,48 @@ func TestCatalogManager_ListAllTables_MemoryVsDisk(t *testing.T) {
 
 	// Create tables
 	fields := []FieldMetadata{{Name: "id", Type: types.IntType}}
-	for i := 0; i < 5; i++ {
+	var tableIDs []primitives.FileID
+	for i := 0; i < 3; i++ {
 		tableName := "consistency_table_" + string(rune('0'+i))
 		tableSchema := createTestSchema(tableName, "id", fields)
 
 		tx := setup.beginTx()
-		_, err := setup.catalogMgr.CreateTable(tx, tableSchema)
+		tableID, err := setup.catalogMgr.CreateTable(tx, tableSchema)
 		setup.commitTx(tx)
 		if err != nil {
 			t.Fatalf("CreateTable %d failed: %v", i, err)
 		}
+		tableIDs = append(tableIDs, tableID)
 	}
 
-	// Get memory listing
+	// Table 0 is dropped from the catalog but still cached
 	tx2 := setup.beginTx()
-	memoryTables, err := setup.catalogMgr.ListAllTables(tx2, false)
-	if err != nil {
-		t.Fatalf("ListAllTables (memory) failed: %v", err)
+	if err := setup.catalogMgr.DeleteCatalogEntry(tx2, tableIDs[0]); err != nil {
+		t.Fatalf("DeleteCatalogEntry failed: %v", err)
 	}
+	setup.commitTx(tx2)
+
+	// Tables 1 and 2 are committed but no longer loaded
+	setup.catalogMgr.ClearCache()
 
-	// Get disk listing
 	tx3 := setup.beginTx()
-	diskTables, err := setup.catalogMgr.ListAllTables(tx3, true)
+	tables, err := setup.catalogMgr.ListAllTables(tx3)
 	if err != nil {
-		t.Fatalf("ListAllTables (disk) failed: %v", err)
-	}
-
-	// Both should include the same user tables
-	memorySet := make(map[string]bool)
-	for _, name := range memoryTables {
-		memorySet[name] = true
-	}
-
-	diskSet := make(map[string]bool)
-	for _, name := range diskTables {
-		diskSet[name] = true
+		t.Fatalf("ListAllTables failed: %v", err)
 	}
+	setup.commitTx(tx3)
 
-	// Check that all disk tables exist in memory
-	for name := range diskSet {
-		if !memorySet[name] {
-			t.Errorf("Table '%s' exists on disk but not in memory", name)
+	if !slices.IsSorted(tables) {
+		t.Errorf("expected a sorted listing, got %v", tables)
+	}
+	for name, want := range map[string]bool{
+		"consistency_table_0": false,
+		"consistency_table_1": true,
+		"consistency_table_2": true,
+		"CATALOG_TABLES":      true,
+	} {
+		if got := slices.Contains(tables, name); got != want {
+			t.Errorf("table %s listed: %v, want %v (listing: %v)", name, got, want, tables)
 		}
 	}
 }
@@ -1072,7 +1072,7 @@ func TestCatalogManager_CreateDropMultipleTables(t *testing.T) {
 
 	// Verify all tables exist
 	tx2 := setup.beginTx()
-	allTables, err := setup.catalogMgr.ListAllTables(tx2, true)
+	allTables, err := setup.catalogMgr.ListAllTables(tx2)
 	if err != nil {
 		t.Fatalf("ListAllTables failed: %v", err)
 	}
@@ -97,7 +97,7 @@ func TestCatalogManager_ConcurrentTableCreation(t *testing.T) {
 
 	// Verify all tables were created
 	tx2 := setup.beginTx()
-	allTables, err := setup.catalogMgr.ListAllTables(tx2, true)
+	allTables, err := setup.catalogMgr.ListAllTables(tx2)
 	setup.commitTx(tx2)
 	if err != nil {
 		t.Fatalf("ListAllTables failed: %v", err)
@@ -377,7 +377,7 @@ func TestCatalogManager_StressTest_ManyTables(t *testing.T) {
 
 	// Verify all tables exist
 	tx2 := setup.beginTx()
-	allTables, err := setup.catalogMgr.ListAllTables(tx2, true)
+	allTables, err := setup.catalogMgr.ListAllTables(tx2)
 	if err != nil {
 		t.Fatalf("ListAllTables failed: %v", err)
 	}
@@ -5,8 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"storemy/pkg/catalog/schema"
+	"storemy/pkg/catalog/systemtable"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/log/wal"
+	"storemy/pkg/logging"
 	"storemy/pkg/memory"
 	"storemy/pkg/primitives"
 	"storemy/pkg/types"
@@ -39,10 +41,12 @@ func setupTest(t *testing.T) *testSetup {
 	if err != nil {
 		t.Fatalf("Failed to create WAL: %v", err)
 	}
+	wal.SetLogger(logging.NewNopLogger())
 
 	store := memory.NewPageStore(wal)
 	txRegistry := transaction.NewTransactionRegistry(wal)
 	catalogMgr := NewCatalogManager(store, tempDir)
+	catalogMgr.SetLogger(logging.NewNopLogger())
 
 	return &testSetup{
 		tempDir:    tempDir,
@@ -116,13 +120,9 @@ func TestCatalogManager_Initialize(t *testing.T) {
 
 	// Verify catalog tables exist in memory
 	tables := setup.catalogMgr.tableCache.GetAllTableNames()
-	expectedTables := map[string]bool{
-		"CATALOG_TABLES":            true,
-		"CATALOG_COLUMNS":           true,
-		"CATALOG_STATISTICS":        true,
-		"CATALOG_INDEXES":           true,
-		"CATALOG_COLUMN_STATISTICS": true,
-		"CATALOG_INDEX_STATISTICS":  true,
+	expectedTables := make(map[string]bool)
+	for _, table := range systemtable.AllSystemTables {
+		expectedTables[table.TableName()] = true
 	}
 
 	if len(tables) != len(expectedTables) {
@@ -443,24 +443,13 @@ func TestCatalogManager_ListTables(t *testing.T) {
 		}
 	}
 
-	// List tables from memory (refreshFromDisk = false)
 	tx2 := setup.beginTx()
-	memoryTables, err := setup.catalogMgr.ListAllTables(tx2, false)
+	diskTables, err := setup.catalogMgr.ListAllTables(tx2)
 	if err != nil {
 		t.Fatalf("ListAllTables failed: %v", err)
 	}
-	if len(memoryTables) < 3 {
-		t.Errorf("Expected at least 3 user tables in memory, got %d", len(memoryTables))
-	}
-
-	// List tables from disk (refreshFromDisk = true)
-	tx3 := setup.beginTx()
-	diskTables, err := setup.catalogMgr.ListAllTables(tx3, true)
-	if err != nil {
-		t.Fatalf("ListAllTablesFromDisk failed: %v", err)
-	}
 	if len(diskTables) < 3 {
-		t.Errorf("Expected at least 3 user tables on disk, got %d", len(diskTables))
+		t.Errorf("Expected at least 3 user tables, got %d", len(diskTables))
 	}
 
 	// Verify specific tables exist
@@ -0,0 +1,95 @@
+package catalogmanager
+
+import (
+	"fmt"
+	"sort"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/types"
+	"strings"
+)
+
+// ForeignTableMetadata is a type alias for easier use
+type ForeignTableMetadata = systemtable.ForeignTableMetadata
+
+// AddForeignTable records a foreign table definition in CATALOG_FOREIGN_TABLES.
+// Returns an error if a foreign table with the same name exists or if a field
+// does not fit in a catalog string.
+func (cm *CatalogManager) AddForeignTable(tx TxContext, md ForeignTableMetadata) error {
+	for name, value := range map[string]string{"location": md.Location, "columns": md.Columns} {
+		if len(value) > types.StringMaxSize {
+			return catalogerror.NewInvalidDefinition("foreign table %s: %s is %d bytes, the limit is %d", md.TableName, name, len(value), types.StringMaxSize)
+		}
+	}
+
+	existing, _, err := cm.findForeignTable(tx, md.TableName)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return catalogerror.NewTableExists("foreign table %s already exists", md.TableName)
+	}
+	return cm.InsertRow(cm.SystemTabs.ForeignTablesTableID, tx, systemtable.ForeignTables.CreateTuple(md))
+}
+
+// GetForeignTable returns the definition of a foreign table by case-insensitive
+// name, or nil if there is no such foreign table.
+func (cm *CatalogManager) GetForeignTable(tx TxContext, tableName string) (*ForeignTableMetadata, error) {
+	md, _, err := cm.findForeignTable(tx, tableName)
+	return md, err
+}
+
+// GetAllForeignTables returns every foreign table definition sorted by name.
+func (cm *CatalogManager) GetAllForeignTables(tx TxContext) ([]*ForeignTableMetadata, error) {
+	var tables []*ForeignTableMetadata
+	err := cm.iterateTable(cm.SystemTabs.ForeignTablesTableID, tx, func(t Tuple) error {
+		md, err := systemtable.ForeignTables.Parse(t)
+		if err != nil {
+			return err
+		}
+		tables = append(tables, md)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foreign tables: %w", err)
+	}
+
+	sort.Slice(tables, func(i, j int) bool { return tables[i].TableName < tables[j].TableName })
+	return tables, nil
+}
+
+// DropForeignTable removes a foreign table definition. The external file is
+// left untouched. Returns an error if there is no such foreign table.
+func (cm *CatalogManager) DropForeignTable(tx TxContext, tableName string) error {
+	md, tup, err := cm.findForeignTable(tx, tableName)
+	if err != nil {
+		return err
+	}
+	if md == nil {
+		return catalogerror.NewTableNotFound("foreign table %s does not exist", tableName)
+	}
+	return cm.DeleteRow(cm.SystemTabs.ForeignTablesTableID, tx, tup)
+}
+
+// findForeignTable returns the definition and catalog tuple of a foreign
+// table, or nils if there is none.
+func (cm *CatalogManager) findForeignTable(tx TxContext, tableName string) (*ForeignTableMetadata, Tuple, error) {
+	var (
+		found *ForeignTableMetadata
+		tup   Tuple
+	)
+	err := cm.iterateTable(cm.SystemTabs.ForeignTablesTableID, tx, func(t Tuple) error {
+		md, err := systemtable.ForeignTables.Parse(t)
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(md.TableName, tableName) {
+			found, tup = md, t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read foreign tables: %w", err)
+	}
+	return found, tup, nil
+}
@@ -2,6 +2,7 @@ package catalogmanager
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/operations"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
@@ -39,7 +40,8 @@ func (cm *CatalogManager) registerTable(tx TxContext, sch *schema.Schema, filepa
 }
 
 // DeleteCatalogEntry removes all catalog metadata for a table.
-// This includes entries in CATALOG_TABLES, CATALOG_COLUMNS, CATALOG_STATISTICS, and CATALOG_INDEXES.
+// This includes entries in CATALOG_TABLES, CATALOG_COLUMNS, CATALOG_STATISTICS, CATALOG_INDEXES,
+// CATALOG_TRIGGERS, CATALOG_AUDITED_TABLES and CATALOG_CACHE_HINTS.
 //
 // This is typically called as part of a DROP TABLE operation.
 // Note: This only removes catalog entries - the heap file must be deleted separately.
@@ -49,6 +51,9 @@ func (cm *CatalogManager) DeleteCatalogEntry(tx TxContext, tableID primitives.Fi
 		cm.SystemTabs.ColumnsTableID,
 		cm.SystemTabs.StatisticsTableID,
 		cm.SystemTabs.IndexesTableID,
+		cm.SystemTabs.TriggersTableID,
+		cm.SystemTabs.AuditedTablesTableID,
+		cm.SystemTabs.CacheHintsTableID,
 	}
 
 	for _, id := range sysTableIDs {
@@ -62,8 +67,7 @@ func (cm *CatalogManager) DeleteCatalogEntry(tx TxContext, tableID primitives.Fi
 // DeleteTableFromSysTable removes all entries for a specific table from a given system table.
 // Due to MVCC, there may be multiple versions of tuples for the same table - this deletes all.
 func (cm *CatalogManager) DeleteTableFromSysTable(tx TxContext, tableID, sysTableID primitives.FileID) error {
-	tableInfo, err := cm.tableCache.GetTableInfo(sysTableID)
-	if err != nil {
+	if _, err := cm.tableCache.GetTableInfo(sysTableID); err != nil {
 		return err
 	}
 
@@ -98,7 +102,7 @@ func (cm *CatalogManager) DeleteTableFromSysTable(tx TxContext, tableID, sysTabl
 
 	// Delete all matching tuples
 	for _, tup := range tuplesToDelete {
-		if err := cm.tupMgr.DeleteTuple(tx, tableInfo.File, tup); err != nil {
+		if err := cm.DeleteRow(sysTableID, tx, tup); err != nil {
 			return err
 		}
 	}
@@ -137,7 +141,7 @@ func (cm *CatalogManager) LoadTableSchema(tx TxContext, tableID primitives.FileI
 	}
 
 	if len(columns) == 0 {
-		return nil, fmt.Errorf("no columns found for table %d", tableID)
+		return nil, catalogerror.NewCorrupt("no columns found for table %d", tableID)
 	}
 
 	sch, err := schema.NewSchema(tableID, tm.TableName, columns)
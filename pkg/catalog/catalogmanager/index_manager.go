@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"slices"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/operations"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
@@ -40,7 +41,7 @@ type IndexCatalogOperation struct {
 // CatalogManager's lock is used for thread safety.
 func (ic *IndexCatalogOperation) DropIndex(name string) (*systemtable.IndexMetadata, error) {
 	if name == "" {
-		return nil, fmt.Errorf("index name cannot be empty")
+		return nil, catalogerror.NewInvalidDefinition("index name cannot be empty")
 	}
 
 	ic.cm.mu.Lock()
@@ -118,7 +119,7 @@ func (io *IndexCatalogOperation) CreateIndex(indexID primitives.FileID, indexNam
 	defer io.cm.mu.Unlock()
 
 	if exists, _ := io.indexOps.GetIndexByName(io.tx, indexName); exists != nil {
-		return "", fmt.Errorf("index %s already exists", indexName)
+		return "", catalogerror.NewObjectExists("index %s already exists", indexName)
 	}
 
 	tableID, err := io.getTableIDUnsafe(tableName)
@@ -141,19 +142,19 @@ func (io *IndexCatalogOperation) validateCreateIndexInput(
 	indexName, tableName, columnName string,
 ) error {
 	if indexID == 0 {
-		return fmt.Errorf("indexID cannot be zero")
+		return catalogerror.NewInvalidDefinition("indexID cannot be zero")
 	}
 	if indexName == "" {
-		return fmt.Errorf("index name cannot be empty")
+		return catalogerror.NewInvalidDefinition("index name cannot be empty")
 	}
 	if len(indexName) > 255 {
-		return fmt.Errorf("index name too long (max 255 characters)")
+		return catalogerror.NewInvalidDefinition("index name too long (max 255 characters)")
 	}
 	if tableName == "" {
-		return fmt.Errorf("table name cannot be empty")
+		return catalogerror.NewInvalidDefinition("table name cannot be empty")
 	}
 	if columnName == "" {
-		return fmt.Errorf("column name cannot be empty")
+		return catalogerror.NewInvalidDefinition("column name cannot be empty")
 	}
 	return nil
 }
@@ -201,7 +202,7 @@ func (io *IndexCatalogOperation) validateColumnExists(cols []schema.ColumnMetada
 	})
 
 	if !columnExists {
-		return fmt.Errorf("column %s does not exist in table %s", indexCol.columnName, indexCol.tableName)
+		return catalogerror.NewColumnNotFound("column %s does not exist in table %s", indexCol.columnName, indexCol.tableName)
 	}
 
 	return nil
@@ -210,13 +211,8 @@ func (io *IndexCatalogOperation) validateColumnExists(cols []schema.ColumnMetada
 // persistIndexMetadata writes index metadata to catalog.
 // Caller must hold cm.mu lock.
 func (io *IndexCatalogOperation) persistIndexMetadata(metadata systemtable.IndexMetadata) error {
-	indexesFile, err := io.cache.GetDbFile(io.indexTableID)
-	if err != nil {
-		return fmt.Errorf("failed to get indexes catalog file: %w", err)
-	}
-
 	tup := systemtable.Indexes.CreateTuple(metadata)
-	if err := io.cm.tupMgr.InsertTuple(io.tx, indexesFile, tup); err != nil {
+	if err := io.cm.InsertRow(io.indexTableID, io.tx, tup); err != nil {
 		return fmt.Errorf("failed to insert index tuple: %w", err)
 	}
 
@@ -317,13 +313,13 @@ type ValidationResult struct {
 //   - error: Validation failure with descriptive message
 func (io *IndexCatalogOperation) ValidateIndexCreation(indexName, tableName, columnName string) (*ValidationResult, error) {
 	if indexName == "" {
-		return nil, fmt.Errorf("index name cannot be empty")
+		return nil, catalogerror.NewInvalidDefinition("index name cannot be empty")
 	}
 	if tableName == "" {
-		return nil, fmt.Errorf("table name cannot be empty")
+		return nil, catalogerror.NewInvalidDefinition("table name cannot be empty")
 	}
 	if columnName == "" {
-		return nil, fmt.Errorf("column name cannot be empty")
+		return nil, catalogerror.NewInvalidDefinition("column name cannot be empty")
 	}
 
 	io.cm.mu.RLock()
@@ -331,13 +327,13 @@ func (io *IndexCatalogOperation) ValidateIndexCreation(indexName, tableName, col
 
 	// Check index name is unique
 	if exists, _ := io.indexOps.GetIndexByName(io.tx, indexName); exists != nil {
-		return nil, fmt.Errorf("index %s already exists", indexName)
+		return nil, catalogerror.NewObjectExists("index %s already exists", indexName)
 	}
 
 	// Validate table exists
 	tableID, err := io.getTableIDUnsafe(tableName)
 	if err != nil {
-		return nil, fmt.Errorf("table %s does not exist", tableName)
+		return nil, catalogerror.NewTableNotFound("table %s does not exist", tableName)
 	}
 
 	// Validate column exists and get metadata
@@ -359,7 +355,7 @@ func (io *IndexCatalogOperation) ValidateIndexCreation(indexName, tableName, col
 	}
 
 	if !found {
-		return nil, fmt.Errorf("column %s does not exist in table %s", columnName, tableName)
+		return nil, catalogerror.NewColumnNotFound("column %s does not exist in table %s", columnName, tableName)
 	}
 
 	return &ValidationResult{
@@ -389,7 +385,7 @@ func (io *IndexCatalogOperation) ValidateIndexCreation(indexName, tableName, col
 //   - error: Validation failure with descriptive message
 func (io *IndexCatalogOperation) ValidateIndexDeletion(indexName, tableName string) (*systemtable.IndexMetadata, error) {
 	if indexName == "" {
-		return nil, fmt.Errorf("index name cannot be empty")
+		return nil, catalogerror.NewInvalidDefinition("index name cannot be empty")
 	}
 
 	io.cm.mu.RLock()
@@ -398,7 +394,7 @@ func (io *IndexCatalogOperation) ValidateIndexDeletion(indexName, tableName stri
 	// Check index exists
 	metadata, err := io.indexOps.GetIndexByName(io.tx, indexName)
 	if err != nil {
-		return nil, fmt.Errorf("index %s does not exist", indexName)
+		return nil, catalogerror.NewObjectNotFound("index %s does not exist", indexName)
 	}
 
 	// Validate table ownership if specified
@@ -408,10 +404,9 @@ func (io *IndexCatalogOperation) ValidateIndexDeletion(indexName, tableName stri
 			return nil, fmt.Errorf("failed to verify table ownership: %w", err)
 		}
 		if tableMetadata.TableName != tableName {
-			return nil, fmt.Errorf("index %s does not belong to table %s", indexName, tableName)
+			return nil, catalogerror.NewObjectNotFound("index %s does not belong to table %s", indexName, tableName)
 		}
 	}
 
 	return metadata, nil
 }
-
@@ -0,0 +1,76 @@
+package catalogmanager
+
+import (
+	"fmt"
+	"sort"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/catalog/systemtable"
+)
+
+// MigrationRecord is a type alias for easier use
+type MigrationRecord = systemtable.MigrationRecord
+
+// GetAppliedMigrations returns every migration recorded in CATALOG_SCHEMA_MIGRATIONS,
+// sorted by version.
+func (cm *CatalogManager) GetAppliedMigrations(tx TxContext) ([]*MigrationRecord, error) {
+	var records []*MigrationRecord
+	err := cm.iterateTable(cm.SystemTabs.MigrationsTableID, tx, func(t Tuple) error {
+		rec, err := systemtable.Migrations.Parse(t)
+		if err != nil {
+			return err
+		}
+		records = append(records, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Version < records[j].Version })
+	return records, nil
+}
+
+// RecordMigration inserts a migration into CATALOG_SCHEMA_MIGRATIONS.
+// Returns an error if the version is already recorded.
+func (cm *CatalogManager) RecordMigration(tx TxContext, rec MigrationRecord) error {
+	existing, err := cm.findMigration(tx, rec.Version)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return catalogerror.NewObjectExists("migration %d is already recorded", rec.Version)
+	}
+	return cm.InsertRow(cm.SystemTabs.MigrationsTableID, tx, systemtable.Migrations.CreateTuple(rec))
+}
+
+// DeleteMigration removes a migration from CATALOG_SCHEMA_MIGRATIONS.
+// Returns an error if the version is not recorded.
+func (cm *CatalogManager) DeleteMigration(tx TxContext, version uint64) error {
+	existing, err := cm.findMigration(tx, version)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return catalogerror.NewObjectNotFound("migration %d is not recorded", version)
+	}
+	return cm.DeleteRow(cm.SystemTabs.MigrationsTableID, tx, existing)
+}
+
+// findMigration returns the catalog tuple recording version, or nil if there is none.
+func (cm *CatalogManager) findMigration(tx TxContext, version uint64) (Tuple, error) {
+	var found Tuple
+	err := cm.iterateTable(cm.SystemTabs.MigrationsTableID, tx, func(t Tuple) error {
+		rec, err := systemtable.Migrations.Parse(t)
+		if err != nil {
+			return err
+		}
+		if rec.Version == version {
+			found = t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	return found, nil
+}
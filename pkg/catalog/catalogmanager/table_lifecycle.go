@@ -2,9 +2,13 @@ package catalogmanager
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/log/record"
+	"storemy/pkg/memory"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/heap"
+	"storemy/pkg/tuple"
 )
 
 // CreateTable creates a new table in the database.
@@ -41,14 +45,14 @@ import (
 //	}
 func (cm *CatalogManager) CreateTable(tx TxContext, sch TableSchema) (primitives.FileID, error) {
 	if sch == nil {
-		return 0, fmt.Errorf("schema cannot be nil")
+		return 0, catalogerror.NewInvalidDefinition("schema cannot be nil")
 	}
 
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
 	if cm.TableExists(tx, sch.TableName) {
-		return 0, fmt.Errorf("table %s already exists", sch.TableName)
+		return 0, catalogerror.NewTableExists("table %s already exists", sch.TableName)
 	}
 
 	heapFile, err := cm.createTableFile(sch)
@@ -86,7 +90,7 @@ func (cm *CatalogManager) createTableFile(sch TableSchema) (*heap.HeapFile, erro
 	fileName := sch.TableName + ".dat"
 	fullPath := primitives.Filepath(cm.dataDir).Join(fileName)
 
-	heapFile, err := heap.NewHeapFile(fullPath, sch.TupleDesc)
+	heapFile, err := heap.NewHeapFileWithFS(cm.store.FS(), fullPath, sch.TupleDesc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create heap file: %w", err)
 	}
@@ -125,7 +129,7 @@ func (cm *CatalogManager) createTableFile(sch TableSchema) (*heap.HeapFile, erro
 func (cm *CatalogManager) addTableToCache(tx TxContext, file *heap.HeapFile, sch TableSchema) error {
 	if err := cm.tableCache.AddTable(file, sch); err != nil {
 		if deleteErr := cm.DeleteCatalogEntry(tx, sch.TableID); deleteErr != nil {
-			fmt.Printf("Warning: failed to rollback catalog entry after cache failure: %v\n", deleteErr)
+			cm.logger.Warn("failed to rollback catalog entry after cache failure", "table_id", sch.TableID, "error", deleteErr)
 		}
 		file.Close()
 		return fmt.Errorf("failed to add table to cache: %w", err)
@@ -144,15 +148,18 @@ func (cm *CatalogManager) addTableToCache(tx TxContext, file *heap.HeapFile, sch
 // DropTable permanently removes a table from the database.
 //
 // This operation removes both the in-memory representation and the catalog
-// metadata, but does NOT delete the physical heap file from disk. The physical
-// file should be manually deleted if needed.
+// metadata. The heap file is deleted once tx commits: the deletion is logged
+// to the WAL and deferred, so an abort keeps the file and a crash after the
+// commit leaves recovery to delete it.
 //
 // Steps performed:
-//  1. Looks up the table ID by name
+//  1. Looks up the table ID by name and locks the table exclusively, waiting
+//     for the statements using it to finish
 //  2. Removes the table from the in-memory cache (so queries immediately stop finding it)
 //  3. Closes and removes the open heap file handle
 //  4. Un-registers the file from the page store
 //  5. Deletes entries from CATALOG_TABLES and CATALOG_COLUMNS
+//  6. Logs the deletion of the heap file, performed when tx commits
 //
 // The operation is atomic - if cache removal fails, the table is not dropped.
 // If disk catalog deletion fails after cache removal, the operation attempts to
@@ -167,9 +174,9 @@ func (cm *CatalogManager) addTableToCache(tx TxContext, file *heap.HeapFile, sch
 // Returns:
 //   - error: nil on success, error if table not found or deletion fails
 func (cm *CatalogManager) DropTable(tx TxContext, tableName string) error {
-	tableID, err := cm.GetTableID(tx, tableName)
+	tableID, err := cm.LockTable(tx, tableName, true)
 	if err != nil {
-		return fmt.Errorf("table %s not found: %w", tableName, err)
+		return err
 	}
 
 	// Get table info before removing from cache (needed for potential rollback)
@@ -178,6 +185,11 @@ func (cm *CatalogManager) DropTable(tx TxContext, tableName string) error {
 		return fmt.Errorf("failed to get table info: %w", err)
 	}
 
+	tm, err := cm.GetTableMetadataByID(tx, tableID)
+	if err != nil {
+		return fmt.Errorf("failed to get table metadata: %w", err)
+	}
+
 	// Step 1: Remove from cache FIRST so queries immediately stop finding it
 	if err := cm.tableCache.RemoveTable(tableName); err != nil {
 		return fmt.Errorf("failed to remove table from cache: %w", err)
@@ -195,18 +207,27 @@ func (cm *CatalogManager) DropTable(tx TxContext, tableName string) error {
 		heapFile.Close()
 	}
 
-	// Step 3: Unregister from page store
+	// Step 3: Unregister from page store and drop the table's compiled schema
+	// and cache priority, which a new table at the same path must not inherit
 	cm.store.UnregisterDbFile(tableID)
+	cm.store.SetCachePriority(tableID, memory.CacheNormal)
+	tuple.Schemas.Invalidate(tableID)
 
 	// Step 4: Delete from disk catalog
 	if err := cm.DeleteCatalogEntry(tx, tableID); err != nil {
 		// Rollback: Try to re-add table to cache
 		if rollbackErr := cm.tableCache.AddTable(tableInfo.File, tableInfo.Schema); rollbackErr != nil {
-			fmt.Printf("CRITICAL: failed to rollback cache after disk deletion failure: %v (original error: %v)\n", rollbackErr, err)
+			cm.logger.Error("failed to rollback cache after disk deletion failure", "table_id", tableID, "error", rollbackErr, "original_error", err)
 		}
 		return fmt.Errorf("failed to delete catalog entry: %w", err)
 	}
 
+	// Step 5: Delete the heap file once the drop commits
+	deleteFile := record.FileOperation{Kind: record.FileOpDelete, Path: tm.FilePath}
+	if err := cm.store.LogFileOp(tx, deleteFile); err != nil {
+		return fmt.Errorf("failed to log heap file deletion: %w", err)
+	}
+
 	return nil
 }
 
@@ -284,7 +305,7 @@ func (cm *CatalogManager) loadFromDisk(tx TxContext, tableName string) (TableSch
 // Returns:
 //   - error: nil on success, error if file cannot be opened or registered
 func (cm *CatalogManager) openTable(filePath primitives.Filepath, sch TableSchema) error {
-	heapFile, err := heap.NewHeapFile(filePath, sch.TupleDesc)
+	heapFile, err := heap.NewHeapFileWithFS(cm.store.FS(), filePath, sch.TupleDesc)
 	if err != nil {
 		return fmt.Errorf("failed to open heap file: %w", err)
 	}
@@ -305,7 +326,8 @@ func (cm *CatalogManager) openTable(filePath primitives.Filepath, sch TableSchem
 // LoadAllTables loads all user tables from disk into memory during database startup.
 //
 // This reads CATALOG_TABLES, reconstructs schemas from CATALOG_COLUMNS,
-// opens heap files, and registers everything with the page store.
+// opens heap files, and registers everything with the page store, which
+// also takes on the eviction priorities of CATALOG_CACHE_HINTS.
 //
 // System tables (CATALOG_TABLES, CATALOG_COLUMNS) are not loaded by this
 // method as they are managed separately.
@@ -330,7 +352,7 @@ func (cm *CatalogManager) LoadAllTables(tx TxContext) error {
 		}
 	}
 
-	return nil
+	return cm.applyCacheHints(tx)
 }
 
 // RenameTable renames a table in both memory and disk catalog.
@@ -342,12 +364,15 @@ func (cm *CatalogManager) LoadAllTables(tx TxContext) error {
 // If any step fails, the in-memory rename is rolled back to maintain consistency.
 //
 // Steps performed:
-//  1. Validates new name is not already taken
+//  1. Validates new name is not already taken and locks the table
+//     exclusively, waiting for the statements using it to finish
 //  2. Renames in in-memory cache
 //  3. Updates CATALOG_TABLES entry (via UpdateBy operation)
 //  4. On failure, reverts the in-memory rename
 //
 // The physical heap file is NOT renamed - only the logical table name changes.
+// Its path determines the table ID that every page ID and catalog entry
+// refers to, so a file rename would change the table's identity.
 //
 // Parameters:
 //   - tx: Transaction context for catalog updates
@@ -365,7 +390,10 @@ func (cm *CatalogManager) LoadAllTables(tx TxContext) error {
 //	}
 func (cm *CatalogManager) RenameTable(tx TxContext, oldName, newName string) error {
 	if cm.TableExists(tx, newName) {
-		return fmt.Errorf("table %s already exists", newName)
+		return catalogerror.NewTableExists("table %s already exists", newName)
+	}
+	if _, err := cm.LockTable(tx, oldName, true); err != nil {
+		return err
 	}
 
 	if err := cm.tableCache.RenameTable(oldName, newName); err != nil {
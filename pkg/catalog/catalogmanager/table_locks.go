@@ -0,0 +1,41 @@
+package catalogmanager
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/primitives"
+)
+
+// LockTable takes a table-level lock on the table named tableName for tx and
+// returns its ID. Statements that read or write the table lock it shared;
+// DDL that renames, drops or changes the files of a table locks it
+// exclusively, which waits for the statements using it to finish and keeps
+// new ones out until tx ends. The lock is held until tx commits or aborts.
+//
+// The name is resolved again once the lock is granted: a table dropped or
+// renamed while tx waited is reported as not found rather than used under
+// its stale ID.
+func (cm *CatalogManager) LockTable(tx TxContext, tableName string, exclusive bool) (primitives.FileID, error) {
+	tableID, err := cm.GetTableID(tx, tableName)
+	if err != nil {
+		return 0, err
+	}
+	if err := cm.store.LockTable(tx, tableID, exclusive); err != nil {
+		return 0, fmt.Errorf("failed to lock table %s: %w", tableName, err)
+	}
+
+	current, err := cm.GetTableID(tx, tableName)
+	if err != nil || current != tableID {
+		return 0, catalogerror.NewTableNotFound("table %s not found", tableName)
+	}
+	return tableID, nil
+}
+
+// LockTableByID is LockTable for a table already resolved to its ID, such
+// as the table an index belongs to.
+func (cm *CatalogManager) LockTableByID(tx TxContext, tableID primitives.FileID, exclusive bool) error {
+	if err := cm.store.LockTable(tx, tableID, exclusive); err != nil {
+		return fmt.Errorf("failed to lock table %d: %w", tableID, err)
+	}
+	return nil
+}
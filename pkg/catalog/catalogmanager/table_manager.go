@@ -2,6 +2,7 @@ package catalogmanager
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/operations"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
@@ -83,14 +84,14 @@ func (cm *CatalogManager) NewTableOps(tx *transaction.TransactionContext) *Table
 //   - error: nil on success, error describing failure otherwise
 func (to *TableCatalogOperation) CreateTable(sch TableSchema) (primitives.FileID, error) {
 	if sch == nil {
-		return 0, fmt.Errorf("schema cannot be nil")
+		return 0, catalogerror.NewInvalidDefinition("schema cannot be nil")
 	}
 
 	to.mu.Lock()
 	defer to.mu.Unlock()
 
 	if to.TableExists(sch.TableName) {
-		return 0, fmt.Errorf("table %s already exists", sch.TableName)
+		return 0, catalogerror.NewTableExists("table %s already exists", sch.TableName)
 	}
 
 	heapFile, err := to.createTableFile(sch)
@@ -128,7 +129,7 @@ func (to *TableCatalogOperation) createTableFile(sch TableSchema) (*heap.HeapFil
 	fileName := sch.TableName + ".dat"
 	fullPath := primitives.Filepath(to.cm.dataDir).Join(fileName)
 
-	heapFile, err := heap.NewHeapFile(fullPath, sch.TupleDesc)
+	heapFile, err := heap.NewHeapFileWithFS(to.cm.store.FS(), fullPath, sch.TupleDesc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create heap file: %w", err)
 	}
@@ -194,7 +195,7 @@ func (to *TableCatalogOperation) registerTable(sch *schema.Schema, filepath prim
 func (to *TableCatalogOperation) addTableToCache(file *heap.HeapFile, sch TableSchema) error {
 	if err := to.cache.AddTable(file, sch); err != nil {
 		if deleteErr := to.cm.DeleteCatalogEntry(to.tx, sch.TableID); deleteErr != nil {
-			fmt.Printf("Warning: failed to rollback catalog entry after cache failure: %v\n", deleteErr)
+			to.cm.logger.Warn("failed to rollback catalog entry after cache failure", "table_id", sch.TableID, "error", deleteErr)
 		}
 		file.Close()
 		return fmt.Errorf("failed to add table to cache: %w", err)
@@ -235,9 +236,9 @@ func (to *TableCatalogOperation) addTableToCache(file *heap.HeapFile, sch TableS
 // Returns:
 //   - error: nil on success, error if table not found or deletion fails
 func (to *TableCatalogOperation) DropTable(tableName string) error {
-	tableID, err := to.GetTableID(tableName)
+	tableID, err := to.cm.LockTable(to.tx, tableName, true)
 	if err != nil {
-		return fmt.Errorf("table %s not found: %w", tableName, err)
+		return err
 	}
 
 	// Get table info before removing from cache (needed for potential rollback)
@@ -270,7 +271,7 @@ func (to *TableCatalogOperation) DropTable(tableName string) error {
 	if err := to.cm.DeleteCatalogEntry(to.tx, tableID); err != nil {
 		// Rollback: Try to re-add table to cache
 		if rollbackErr := to.cache.AddTable(tableInfo.File, tableInfo.Schema); rollbackErr != nil {
-			fmt.Printf("CRITICAL: failed to rollback cache after disk deletion failure: %v (original error: %v)\n", rollbackErr, err)
+			to.cm.logger.Error("failed to rollback cache after disk deletion failure", "table_id", tableID, "error", rollbackErr, "original_error", err)
 		}
 		return fmt.Errorf("failed to delete catalog entry: %w", err)
 	}
@@ -296,7 +297,10 @@ func (to *TableCatalogOperation) DropTable(tableName string) error {
 //   - error: nil on success, error if validation fails or rename cannot complete
 func (to *TableCatalogOperation) RenameTable(oldName, newName string) error {
 	if to.TableExists(newName) {
-		return fmt.Errorf("table %s already exists", newName)
+		return catalogerror.NewTableExists("table %s already exists", newName)
+	}
+	if _, err := to.cm.LockTable(to.tx, oldName, true); err != nil {
+		return err
 	}
 
 	if err := to.cache.RenameTable(oldName, newName); err != nil {
@@ -391,7 +395,7 @@ func (to *TableCatalogOperation) loadFromDisk(tableName string) (TableSchema, pr
 // Returns:
 //   - error: nil on success, error if file cannot be opened or registered
 func (to *TableCatalogOperation) openTable(filePath primitives.Filepath, sch TableSchema) error {
-	heapFile, err := heap.NewHeapFile(filePath, sch.TupleDesc)
+	heapFile, err := heap.NewHeapFileWithFS(to.cm.store.FS(), filePath, sch.TupleDesc)
 	if err != nil {
 		return fmt.Errorf("failed to open heap file: %w", err)
 	}
@@ -455,7 +459,7 @@ func (to *TableCatalogOperation) GetTableID(tableName string) (primitives.FileID
 		return md.TableID, nil
 	}
 
-	return 0, fmt.Errorf("table %s not found", tableName)
+	return 0, catalogerror.NewTableNotFound("table %s not found", tableName)
 }
 
 // GetTableName retrieves the table name for a given table ID.
@@ -477,7 +481,7 @@ func (to *TableCatalogOperation) GetTableName(tableID primitives.FileID) (string
 		return md.TableName, nil
 	}
 
-	return "", fmt.Errorf("table with ID %d not found", tableID)
+	return "", catalogerror.NewTableNotFound("table with ID %d not found", tableID)
 }
 
 // GetTableSchema retrieves the schema for a table.
@@ -520,31 +524,10 @@ func (to *TableCatalogOperation) TableExists(tableName string) bool {
 	return err == nil
 }
 
-// ListAllTables returns all table names.
-//
-// Parameters:
-//   - refreshFromDisk: If true, scans CATALOG_TABLES (includes unloaded tables).
-//     If false, returns tables from memory cache (faster).
-//
-// Returns:
-//   - []string: List of table names
-//   - error: Error if disk scan fails (only when refreshFromDisk=true)
-func (to *TableCatalogOperation) ListAllTables(refreshFromDisk bool) ([]string, error) {
-	if !refreshFromDisk {
-		return to.cache.GetAllTableNames(), nil
-	}
-
-	tables, err := to.tableOps.GetAllTables(to.tx)
-	if err != nil {
-		return nil, err
-	}
-
-	tableNames := make([]string, 0, len(tables))
-	for _, table := range tables {
-		tableNames = append(tableNames, table.TableName)
-	}
-
-	return tableNames, nil
+// ListAllTables returns the names of all tables visible to the operation's
+// transaction, sorted. See CatalogManager.ListAllTables.
+func (to *TableCatalogOperation) ListAllTables() ([]string, error) {
+	return to.cm.ListAllTables(to.tx)
 }
 
 // GetTableMetadataByID retrieves complete table metadata from CATALOG_TABLES by table ID.
@@ -606,7 +589,7 @@ func (to *TableCatalogOperation) LoadTableSchema(tableID primitives.FileID) (*sc
 	}
 
 	if len(columns) == 0 {
-		return nil, fmt.Errorf("no columns found for table %d", tableID)
+		return nil, catalogerror.NewCorrupt("no columns found for table %d", tableID)
 	}
 
 	sch, err := schema.NewSchema(tableID, tm.TableName, columns)
@@ -2,6 +2,8 @@ package catalogmanager
 
 import (
 	"fmt"
+	"slices"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
 	"storemy/pkg/primitives"
@@ -29,7 +31,7 @@ func (cm *CatalogManager) GetTableID(tx TxContext, tableName string) (primitives
 		return md.TableID, nil
 	}
 
-	return 0, fmt.Errorf("table %s not found", tableName)
+	return 0, catalogerror.NewTableNotFound("table %s not found", tableName)
 }
 
 // GetTableName retrieves the table name for a given table ID.
@@ -52,7 +54,7 @@ func (cm *CatalogManager) GetTableName(tx TxContext, tableID primitives.FileID)
 		return md.TableName, nil
 	}
 
-	return "", fmt.Errorf("table with ID %d not found", tableID)
+	return "", catalogerror.NewTableNotFound("table with ID %d not found", tableID)
 }
 
 // GetTableSchema retrieves the schema for a table.
@@ -117,30 +119,51 @@ func (cm *CatalogManager) TableExists(tx TxContext, tableName string) bool {
 	return err == nil
 }
 
-// ListAllTables returns all table names.
+// ListAllTables returns the names of all tables tx can see, sorted.
+//
+// The cache alone is not a consistent view: it holds tables other sessions
+// have created but not committed, keeps tables that are dropped until they
+// are evicted, and misses tables that were never loaded. So the user tables
+// are read from CATALOG_TABLES under tx, which sees committed tables and
+// the changes of tx itself. System tables are never recorded there and are
+// taken from the cache, which always holds them.
 //
 // Parameters:
 //   - tx: Transaction context for reading catalog
-//   - refreshFromDisk: If true, scans CATALOG_TABLES (slower but includes unloaded tables).
-//     If false, returns tables from memory cache (faster).
 //
 // Returns:
 //   - []string: List of table names
-//   - error: Error if disk scan fails (only when refreshFromDisk=true)
-func (cm *CatalogManager) ListAllTables(tx TxContext, refreshFromDisk bool) ([]string, error) {
-	if !refreshFromDisk {
-		return cm.tableCache.GetAllTableNames(), nil
+//   - error: Error if the catalog scan fails
+func (cm *CatalogManager) ListAllTables(tx TxContext) ([]string, error) {
+	var names []string
+	for _, name := range cm.tableCache.GetAllTableNames() {
+		if id, err := cm.tableCache.GetTableID(name); err == nil {
+			if _, err := cm.SystemTabs.GetSysTable(id); err == nil {
+				names = append(names, name)
+			}
+		}
 	}
 
 	tables, err := cm.GetAllTables(tx)
 	if err != nil {
 		return nil, err
 	}
+	names = append(names, functools.Map(tables,
+		func(t *systemtable.TableMetadata) string { return t.TableName })...)
 
-	tableNames := functools.Map(tables,
-		func(t *systemtable.TableMetadata) string { return t.TableName })
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
 
-	return tableNames, nil
+// CachedTableName returns the name of a table loaded in the cache, without
+// reading the catalog. It is meant for diagnostics that must not take locks;
+// use GetTableName everywhere else.
+func (cm *CatalogManager) CachedTableName(tableID primitives.FileID) (string, bool) {
+	info, err := cm.tableCache.GetTableInfo(tableID)
+	if err != nil {
+		return "", false
+	}
+	return info.Schema.TableName, true
 }
 
 // ValidateIntegrity checks consistency between memory and disk catalog.
@@ -161,7 +184,7 @@ func (cm *CatalogManager) ValidateIntegrity(tx TxContext) error {
 	names := cm.tableCache.GetAllTableNames()
 	for _, n := range names {
 		if _, err := cm.GetTableMetadataByName(tx, n); err != nil {
-			return fmt.Errorf("table %s exists in memory but not in disk catalog", n)
+			return catalogerror.NewCorrupt("table %s exists in memory but not in disk catalog", n)
 		}
 	}
 	return nil
@@ -188,6 +211,12 @@ func (cm *CatalogManager) ClearCache() {
 		"CATALOG_INDEXES":           true,
 		"CATALOG_COLUMN_STATISTICS": true,
 		"CATALOG_INDEX_STATISTICS":  true,
+		"CATALOG_CONSTRAINTS":       true,
+		"CATALOG_SCHEMA_MIGRATIONS": true,
+		"CATALOG_FOREIGN_TABLES":    true,
+		"CATALOG_TRIGGERS":          true,
+		"CATALOG_AUDITED_TABLES":    true,
+		"CATALOG_CACHE_HINTS":       true,
 	}
 
 	for _, name := range tableNames {
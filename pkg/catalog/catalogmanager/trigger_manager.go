@@ -0,0 +1,95 @@
+package catalogmanager
+
+import (
+	"fmt"
+	"sort"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/primitives"
+	"strings"
+)
+
+// TriggerMetadata is a type alias for easier use
+type TriggerMetadata = systemtable.TriggerMetadata
+
+// AddTrigger records a trigger definition in CATALOG_TRIGGERS.
+// Returns an error if a trigger with the same name exists or the table does not.
+func (cm *CatalogManager) AddTrigger(tx TxContext, md TriggerMetadata) error {
+	if _, err := cm.tableOps.GetTableMetadataByID(tx, md.TableID); err != nil {
+		return fmt.Errorf("table %d does not exist: %w", md.TableID, err)
+	}
+
+	existing, _, err := cm.findTrigger(tx, md.TriggerName)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return catalogerror.NewObjectExists("trigger %s already exists", md.TriggerName)
+	}
+	return cm.InsertRow(cm.SystemTabs.TriggersTableID, tx, systemtable.Triggers.CreateTuple(md))
+}
+
+// GetTrigger returns the definition of a trigger by case-insensitive name, or
+// nil if there is no such trigger.
+func (cm *CatalogManager) GetTrigger(tx TxContext, triggerName string) (*TriggerMetadata, error) {
+	md, _, err := cm.findTrigger(tx, triggerName)
+	return md, err
+}
+
+// GetTriggersForTable returns every trigger defined on a table, sorted by name.
+// Triggers with the same timing and event fire in this order.
+func (cm *CatalogManager) GetTriggersForTable(tx TxContext, tableID primitives.FileID) ([]*TriggerMetadata, error) {
+	var triggers []*TriggerMetadata
+	err := cm.iterateTable(cm.SystemTabs.TriggersTableID, tx, func(t Tuple) error {
+		md, err := systemtable.Triggers.Parse(t)
+		if err != nil {
+			return err
+		}
+		if md.TableID == tableID {
+			triggers = append(triggers, md)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read triggers: %w", err)
+	}
+
+	sort.Slice(triggers, func(i, j int) bool { return triggers[i].TriggerName < triggers[j].TriggerName })
+	return triggers, nil
+}
+
+// DropTrigger removes a trigger definition. Returns an error if there is no
+// such trigger.
+func (cm *CatalogManager) DropTrigger(tx TxContext, triggerName string) error {
+	md, tup, err := cm.findTrigger(tx, triggerName)
+	if err != nil {
+		return err
+	}
+	if md == nil {
+		return catalogerror.NewObjectNotFound("trigger %s does not exist", triggerName)
+	}
+	return cm.DeleteRow(cm.SystemTabs.TriggersTableID, tx, tup)
+}
+
+// findTrigger returns the definition and catalog tuple of a trigger, or nils
+// if there is none.
+func (cm *CatalogManager) findTrigger(tx TxContext, triggerName string) (*TriggerMetadata, Tuple, error) {
+	var (
+		found *TriggerMetadata
+		tup   Tuple
+	)
+	err := cm.iterateTable(cm.SystemTabs.TriggersTableID, tx, func(t Tuple) error {
+		md, err := systemtable.Triggers.Parse(t)
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(md.TriggerName, triggerName) {
+			found, tup = md, t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read triggers: %w", err)
+	}
+	return found, tup, nil
+}
@@ -1,7 +1,7 @@
 package catalogmanager
 
 import (
-	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/operations"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
@@ -36,11 +36,18 @@ type (
 //   - CATALOG_COLUMN_STATISTICS: column-level statistics
 //   - CATALOG_INDEX_STATISTICS: index statistics
 //   - CATALOG_CONSTRAINTS: constraint metadata
+//   - CATALOG_SCHEMA_MIGRATIONS: applied schema migrations
+//   - CATALOG_FOREIGN_TABLES: foreign table definitions
+//   - CATALOG_TRIGGERS: trigger definitions
+//   - CATALOG_AUDITED_TABLES: audited tables and their audit tables
+//   - CATALOG_CACHE_HINTS: buffer pool eviction priorities of tables
 type SystemTableIDs struct {
 	TablesTableID, StatisticsTableID        primitives.FileID
 	ColumnsTableID, ColumnStatisticsTableID primitives.FileID
 	IndexesTableID, IndexStatisticsTableID  primitives.FileID
-	ConstraintsTableID                      primitives.FileID
+	ConstraintsTableID, MigrationsTableID   primitives.FileID
+	ForeignTablesTableID, TriggersTableID   primitives.FileID
+	AuditedTablesTableID, CacheHintsTableID primitives.FileID
 }
 
 // GetSysTable returns the SystemTable interface for a given system table ID.
@@ -61,8 +68,18 @@ func (st *SystemTableIDs) GetSysTable(id primitives.FileID) (systemtable.SystemT
 		return systemtable.IndexStats, nil
 	case st.ConstraintsTableID:
 		return systemtable.Constraints, nil
+	case st.MigrationsTableID:
+		return systemtable.Migrations, nil
+	case st.ForeignTablesTableID:
+		return systemtable.ForeignTables, nil
+	case st.TriggersTableID:
+		return systemtable.Triggers, nil
+	case st.AuditedTablesTableID:
+		return systemtable.AuditedTables, nil
+	case st.CacheHintsTableID:
+		return systemtable.CacheHints, nil
 	default:
-		return nil, fmt.Errorf("unknown system table ID: %d", id)
+		return nil, catalogerror.NewObjectNotFound("unknown system table ID: %d", id)
 	}
 }
 
@@ -84,6 +101,16 @@ func (st *SystemTableIDs) SetSystemTableID(tableName string, tableID primitives.
 		st.IndexStatisticsTableID = tableID
 	case systemtable.Constraints.TableName():
 		st.ConstraintsTableID = tableID
+	case systemtable.Migrations.TableName():
+		st.MigrationsTableID = tableID
+	case systemtable.ForeignTables.TableName():
+		st.ForeignTablesTableID = tableID
+	case systemtable.Triggers.TableName():
+		st.TriggersTableID = tableID
+	case systemtable.AuditedTables.TableName():
+		st.AuditedTablesTableID = tableID
+	case systemtable.CacheHints.TableName():
+		st.CacheHintsTableID = tableID
 	}
 }
 
@@ -0,0 +1,61 @@
+package constraints
+
+import (
+	"storemy/pkg/catalog/operations"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/primitives"
+	"sync"
+)
+
+// constraintCache remembers the constraints a Validator looked up for each
+// table, so that a statement validating many rows reads the catalog once per
+// table instead of once per row.
+//
+// An entry is only reused by the transaction that loaded it, which may have
+// seen its own uncommitted constraint changes, and only while the version of
+// CATALOG_CONSTRAINTS is unchanged: any constraint DDL, by this transaction
+// or a committed one, invalidates it.
+type constraintCache struct {
+	mutex   sync.Mutex
+	entries map[primitives.FileID]cachedConstraints
+}
+
+type cachedConstraints struct {
+	tx          operations.TxContext
+	version     uint64
+	constraints []*systemtable.ConstraintMetadata
+}
+
+// get returns the cached constraints of table tableID, calling load on a
+// miss. Nothing is cached if CATALOG_CONSTRAINTS is not versioned.
+func (c *constraintCache) get(
+	tx operations.TxContext,
+	constraintOps *operations.ConstraintOperations,
+	tableID primitives.FileID,
+	load func() ([]*systemtable.ConstraintMetadata, error),
+) ([]*systemtable.ConstraintMetadata, error) {
+	version, ok := constraintOps.Version(tx)
+	if !ok {
+		return load()
+	}
+
+	c.mutex.Lock()
+	entry, hit := c.entries[tableID]
+	c.mutex.Unlock()
+	if hit && entry.tx == tx && entry.version == version {
+		return entry.constraints, nil
+	}
+
+	constraints, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[primitives.FileID]cachedConstraints)
+	}
+	c.entries[tableID] = cachedConstraints{tx: tx, version: version, constraints: constraints}
+	return constraints, nil
+}
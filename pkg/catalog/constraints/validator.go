@@ -2,14 +2,18 @@ package constraints
 
 import (
 	"fmt"
+	"slices"
 	"storemy/pkg/catalog/operations"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
 	dberror "storemy/pkg/error"
+	"storemy/pkg/execution/query"
+	"storemy/pkg/parser/parser"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
 	"strings"
+	"unicode"
 )
 
 // IndexSearcher defines the interface for searching indexes.
@@ -20,11 +24,18 @@ type IndexSearcher interface {
 }
 
 // Validator handles constraint validation for DML operations.
+//
+// A Validator caches the constraints of the tables it validates, keyed by
+// transaction and invalidated by constraint DDL, so it is meant to be
+// created once per statement and used for all of its rows.
 type Validator struct {
 	constraintOps *operations.ConstraintOperations
 	columnOps     *operations.ColumnOperations
 	indexOps      *operations.IndexOperations
 	indexSearcher IndexSearcher
+
+	enabled     constraintCache // Enabled constraints of each table
+	referencing constraintCache // Foreign keys referencing each table
 }
 
 // NewValidator creates a new constraint validator.
@@ -69,7 +80,7 @@ func NewValidator(
 // Returns a DBError if validation fails, nil otherwise.
 func (v *Validator) ValidateInsert(tx operations.TxContext, tableID primitives.FileID, tableName string, tup *tuple.Tuple, sch *schema.Schema) error {
 	// Get all enabled constraints for the table
-	constraints, err := v.constraintOps.GetEnabledConstraintsForTable(tx, tableID)
+	constraints, err := v.GetEnabledConstraintsForTable(tx, tableID)
 	if err != nil {
 		return dberror.Wrap(err, "CONSTRAINT_VALIDATION_ERROR", "ValidateInsert", "Validator")
 	}
@@ -116,7 +127,7 @@ func (v *Validator) ValidateInsert(tx operations.TxContext, tableID primitives.F
 // Returns a DBError if validation fails, nil otherwise.
 func (v *Validator) ValidateUpdate(tx operations.TxContext, tableID primitives.FileID, tableName string, oldTuple, newTuple *tuple.Tuple, sch *schema.Schema) error {
 	// Get all enabled constraints for the table
-	constraints, err := v.constraintOps.GetEnabledConstraintsForTable(tx, tableID)
+	constraints, err := v.GetEnabledConstraintsForTable(tx, tableID)
 	if err != nil {
 		return dberror.Wrap(err, "CONSTRAINT_VALIDATION_ERROR", "ValidateUpdate", "Validator")
 	}
@@ -165,7 +176,9 @@ func (v *Validator) ValidateUpdate(tx operations.TxContext, tableID primitives.F
 // Returns a DBError if validation fails, nil otherwise.
 func (v *Validator) ValidateDelete(tx operations.TxContext, tableID primitives.FileID, tableName string, tup *tuple.Tuple, sch *schema.Schema) error {
 	// Check if any foreign keys reference this table
-	referencingConstraints, err := v.constraintOps.GetForeignKeyConstraintsReferencingTable(tx, tableID)
+	referencingConstraints, err := v.referencing.get(tx, v.constraintOps, tableID, func() ([]*systemtable.ConstraintMetadata, error) {
+		return v.constraintOps.GetForeignKeyConstraintsReferencingTable(tx, tableID)
+	})
 	if err != nil {
 		return dberror.Wrap(err, "CONSTRAINT_VALIDATION_ERROR", "ValidateDelete", "Validator")
 	}
@@ -331,6 +344,7 @@ func (v *Validator) validateUnique(
 //   - column_name <op> value (e.g., "age >= 18", "price > 0")
 //   - column_name BETWEEN value1 AND value2 (e.g., "quantity BETWEEN 0 AND 100")
 //   - column_name IN (value1, value2, ...) (e.g., "status IN ('active', 'pending')")
+//   - CASE expressions (e.g., "CASE WHEN tier = 'gold' THEN discount <= 50 ELSE discount <= 10 END")
 //
 // Parameters:
 //   - constraint: The CHECK constraint metadata
@@ -418,8 +432,11 @@ func (v *Validator) GetConstraintsForTable(tx operations.TxContext, tableID prim
 }
 
 // GetEnabledConstraintsForTable is a convenience method to get enabled constraints for a table.
+// The result is cached until the constraints change; callers must not modify it.
 func (v *Validator) GetEnabledConstraintsForTable(tx operations.TxContext, tableID primitives.FileID) ([]*systemtable.ConstraintMetadata, error) {
-	return v.constraintOps.GetEnabledConstraintsForTable(tx, tableID)
+	return v.enabled.get(tx, v.constraintOps, tableID, func() ([]*systemtable.ConstraintMetadata, error) {
+		return v.constraintOps.GetEnabledConstraintsForTable(tx, tableID)
+	})
 }
 
 // evaluateCheckExpression evaluates a CHECK constraint expression against a tuple.
@@ -434,6 +451,7 @@ func (v *Validator) GetEnabledConstraintsForTable(tx operations.TxContext, table
 //   - column_name != value or column_name <> value
 //   - column_name BETWEEN value1 AND value2
 //   - column_name IN (value1, value2, ...)
+//   - any expression containing CASE, evaluated by the query expression evaluator
 //
 // Returns true if the constraint is satisfied, false otherwise.
 // Returns an error if the expression cannot be parsed or evaluated.
@@ -442,6 +460,10 @@ func evaluateCheckExpression(expression string, tup *tuple.Tuple, sch *schema.Sc
 	expr := strings.TrimSpace(expression)
 	exprLower := strings.ToLower(expr)
 
+	if slices.Contains(strings.FieldsFunc(exprLower, isNotWordChar), "case") {
+		return evaluateExpression(expr, tup)
+	}
+
 	// Try to parse BETWEEN expression: column BETWEEN value1 AND value2
 	if strings.Contains(exprLower, " between ") && strings.Contains(exprLower, " and ") {
 		return evaluateBetween(expr, tup, sch)
@@ -463,6 +485,41 @@ func evaluateCheckExpression(expression string, tup *tuple.Tuple, sch *schema.Sc
 	return false, fmt.Errorf("unsupported CHECK expression pattern: %s", expression)
 }
 
+// isNotWordChar reports whether r separates the words of an expression.
+func isNotWordChar(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// evaluateExpression parses expr with the SQL expression parser and evaluates
+// it against the tuple. As in SQL, a CHECK expression that evaluates to NULL
+// is satisfied; only FALSE violates the constraint.
+func evaluateExpression(expr string, tup *tuple.Tuple) (bool, error) {
+	parsed, err := parser.ParseExpression(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid CHECK expression %s: %w", expr, err)
+	}
+
+	bound, err := query.NewExpression(parsed, tup.TupleDesc)
+	if err != nil {
+		return false, fmt.Errorf("invalid CHECK expression %s: %w", expr, err)
+	}
+
+	result, err := bound.Eval(tup)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := result.(*types.BoolField)
+	switch {
+	case result == nil:
+		return true, nil
+	case !ok:
+		return false, fmt.Errorf("CHECK expression %s is not boolean", expr)
+	default:
+		return b.Value, nil
+	}
+}
+
 // evaluateComparison evaluates a simple comparison expression: column <op> value
 func evaluateComparison(expr string, op string, tup *tuple.Tuple, sch *schema.Schema) (bool, error) {
 	parts := strings.SplitN(expr, op, 2)
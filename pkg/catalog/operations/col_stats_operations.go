@@ -31,7 +31,7 @@ type ColStatsInfo struct {
 
 type ColStatsOperations struct {
 	fileGetter FileGetter
-	BaseOperations[*colStats]
+	*BaseOperations[*colStats]
 	colOps *ColumnOperations
 }
 
@@ -61,7 +61,7 @@ func NewColStatsOperations(
 
 	return &ColStatsOperations{
 		fileGetter:     fileGetter,
-		BaseOperations: *base,
+		BaseOperations: base,
 		colOps:         colOps,
 	}
 }
@@ -10,6 +10,9 @@ import (
 
 type colMetadata = schema.ColumnMetadata
 
+// columnsByTable is the secondary index over CATALOG_COLUMNS by table ID.
+const columnsByTable = "table_id"
+
 // AutoIncrementInfo represents auto-increment metadata for a column.
 // Contains the column name, its position in the tuple, and the next value to use.
 type AutoIncrementInfo struct {
@@ -42,6 +45,7 @@ func NewColumnOperations(access catalogio.CatalogAccess, colTableID primitives.F
 		},
 	)
 
+	base.AddIndex(columnsByTable, func(c *colMetadata) any { return c.TableID })
 	return &ColumnOperations{
 		BaseOperations: base,
 	}
@@ -61,15 +65,15 @@ func NewColumnOperations(access catalogio.CatalogAccess, colTableID primitives.F
 func (co *ColumnOperations) GetAutoIncrementColumn(tx TxContext, tableID primitives.FileID) (*AutoIncrementInfo, error) {
 	var result *AutoIncrementInfo
 
-	err := co.Iterate(tx, func(col *colMetadata) error {
-		if col.TableID != tableID {
-			return nil
-		}
+	cols, err := co.FindAllBy(tx, columnsByTable, tableID, func(col *colMetadata) bool {
+		return col.IsAutoInc
+	})
 
-		if !col.IsAutoInc {
-			return nil
-		}
+	if err != nil {
+		return nil, err
+	}
 
+	for _, col := range cols {
 		if result == nil || col.NextAutoValue > result.NextValue {
 			result = &AutoIncrementInfo{
 				ColumnName:  col.Name,
@@ -77,11 +81,6 @@ func (co *ColumnOperations) GetAutoIncrementColumn(tx TxContext, tableID primiti
 				NextValue:   col.NextAutoValue,
 			}
 		}
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
 	}
 
 	return result, nil
@@ -168,8 +167,8 @@ func (co *ColumnOperations) IncrementAutoIncrementValue(tx TxContext, tableID pr
 //   - Slice of ColumnMetadata for all columns in the table
 //   - error if catalog read or parsing fails
 func (co *ColumnOperations) LoadColumnMetadata(tx TxContext, tableID primitives.FileID) ([]colMetadata, error) {
-	columnPtrs, err := co.FindAll(tx, func(c *colMetadata) bool {
-		return c.TableID == tableID
+	columnPtrs, err := co.FindAllBy(tx, columnsByTable, tableID, func(c *colMetadata) bool {
+		return true
 	})
 
 	if err != nil {
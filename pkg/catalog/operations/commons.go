@@ -7,6 +7,7 @@ import (
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
+	"sync/atomic"
 )
 
 type (
@@ -24,12 +25,20 @@ var (
 // Type parameter T represents the parsed entity type (e.g., *systemtable.TableStatistics).
 //
 // This eliminates repetitive iterate-parse-process patterns across all operation structs.
+//
+// If the catalog access also implements catalogio.ChangeTracker, reads are
+// served from a cached copy of the table, with the secondary indexes added by
+// AddIndex, while no transaction has uncommitted writes to it.
 type BaseOperations[T any] struct {
 	reader  catalogio.CatalogReader
 	writer  catalogio.CatalogWriter
 	tableID primitives.FileID
 	parser  func(*tuple.Tuple) (T, error)
 	creator func(T) *tuple.Tuple
+
+	tracker  catalogio.ChangeTracker
+	indexes  map[string]func(T) any
+	snapshot atomic.Pointer[tableSnapshot]
 }
 
 // NewBaseOperations creates a new BaseOperations instance.
@@ -46,12 +55,14 @@ func NewBaseOperations[T any](
 	parser func(*tuple.Tuple) (T, error),
 	creator func(T) *tuple.Tuple,
 ) *BaseOperations[T] {
+	tracker, _ := access.(catalogio.ChangeTracker)
 	return &BaseOperations[T]{
 		reader:  access,
 		writer:  access,
 		tableID: tableID,
 		parser:  parser,
 		creator: creator,
+		tracker: tracker,
 	}
 }
 
@@ -64,6 +75,14 @@ func NewBaseOperations[T any](
 //
 // Returns error if iteration fails or if processFunc returns a non-ErrSuccess error.
 func (bo *BaseOperations[T]) Iterate(tx TxContext, processFunc func(T) error) error {
+	snap, err := bo.loadSnapshot(tx)
+	if err != nil {
+		return err
+	}
+	if snap != nil {
+		return bo.iterateSnapshot(snap, processFunc)
+	}
+
 	return bo.reader.IterateTable(bo.tableID, tx, func(tup *tuple.Tuple) error {
 		entity, err := bo.parser(tup)
 		if err != nil {
@@ -9,6 +9,12 @@ import (
 	"strings"
 )
 
+// Secondary indexes over CATALOG_CONSTRAINTS
+const (
+	constraintsByID    = "constraint_id"
+	constraintsByTable = "table_id"
+)
+
 // ConstraintOperations provides operations for managing constraint metadata in CATALOG_CONSTRAINTS.
 type ConstraintOperations struct {
 	*BaseOperations[*systemtable.ConstraintMetadata]
@@ -25,6 +31,8 @@ func NewConstraintOperations(access catalogio.CatalogAccess, tableID primitives.
 	baseOp := NewBaseOperations(access, tableID, systemtable.Constraints.Parse, func(cm *systemtable.ConstraintMetadata) *tuple.Tuple {
 		return systemtable.Constraints.CreateTuple(*cm)
 	})
+	baseOp.AddIndex(constraintsByID, func(cm *systemtable.ConstraintMetadata) any { return cm.ConstraintID })
+	baseOp.AddIndex(constraintsByTable, func(cm *systemtable.ConstraintMetadata) any { return cm.TableID })
 	return &ConstraintOperations{
 		BaseOperations: baseOp,
 	}
@@ -38,8 +46,8 @@ func NewConstraintOperations(access catalogio.CatalogAccess, tableID primitives.
 //
 // Returns the ConstraintMetadata or an error if not found.
 func (co *ConstraintOperations) GetConstraintByID(tx TxContext, constraintID primitives.FileID) (*systemtable.ConstraintMetadata, error) {
-	return co.FindOne(tx, func(cm *systemtable.ConstraintMetadata) bool {
-		return cm.ConstraintID == constraintID
+	return co.FindOneBy(tx, constraintsByID, constraintID, func(cm *systemtable.ConstraintMetadata) bool {
+		return true
 	})
 }
 
@@ -53,8 +61,8 @@ func (co *ConstraintOperations) GetConstraintByID(tx TxContext, constraintID pri
 //
 // Returns the ConstraintMetadata or an error if not found.
 func (co *ConstraintOperations) GetConstraintByName(tx TxContext, tableID primitives.FileID, constraintName string) (*systemtable.ConstraintMetadata, error) {
-	return co.FindOne(tx, func(cm *systemtable.ConstraintMetadata) bool {
-		return cm.TableID == tableID && strings.EqualFold(cm.ConstraintName, constraintName)
+	return co.FindOneBy(tx, constraintsByTable, tableID, func(cm *systemtable.ConstraintMetadata) bool {
+		return strings.EqualFold(cm.ConstraintName, constraintName)
 	})
 }
 
@@ -66,8 +74,8 @@ func (co *ConstraintOperations) GetConstraintByName(tx TxContext, tableID primit
 //
 // Returns a slice of ConstraintMetadata for all constraints on the table.
 func (co *ConstraintOperations) GetConstraintsForTable(tx TxContext, tableID primitives.FileID) ([]*systemtable.ConstraintMetadata, error) {
-	return co.FindAll(tx, func(cm *systemtable.ConstraintMetadata) bool {
-		return cm.TableID == tableID
+	return co.FindAllBy(tx, constraintsByTable, tableID, func(cm *systemtable.ConstraintMetadata) bool {
+		return true
 	})
 }
 
@@ -80,8 +88,8 @@ func (co *ConstraintOperations) GetConstraintsForTable(tx TxContext, tableID pri
 //
 // Returns a slice of ConstraintMetadata matching the specified type.
 func (co *ConstraintOperations) GetConstraintsByType(tx TxContext, tableID primitives.FileID, constraintType systemtable.ConstraintType) ([]*systemtable.ConstraintMetadata, error) {
-	return co.FindAll(tx, func(cm *systemtable.ConstraintMetadata) bool {
-		return cm.TableID == tableID && cm.ConstraintType == constraintType
+	return co.FindAllBy(tx, constraintsByTable, tableID, func(cm *systemtable.ConstraintMetadata) bool {
+		return cm.ConstraintType == constraintType
 	})
 }
 
@@ -109,8 +117,8 @@ func (co *ConstraintOperations) GetForeignKeyConstraintsReferencingTable(tx TxCo
 //
 // Returns a slice of enabled ConstraintMetadata for the table.
 func (co *ConstraintOperations) GetEnabledConstraintsForTable(tx TxContext, tableID primitives.FileID) ([]*systemtable.ConstraintMetadata, error) {
-	return co.FindAll(tx, func(cm *systemtable.ConstraintMetadata) bool {
-		return cm.TableID == tableID && cm.IsEnabled
+	return co.FindAllBy(tx, constraintsByTable, tableID, func(cm *systemtable.ConstraintMetadata) bool {
+		return cm.IsEnabled
 	})
 }
 
@@ -10,6 +10,13 @@ import (
 	"strings"
 )
 
+// Secondary indexes over CATALOG_INDEXES
+const (
+	indexesByID    = "index_id"
+	indexesByName  = "index_name"
+	indexesByTable = "table_id"
+)
+
 // IndexOperations handles all index-related catalog operations.
 // It depends only on the CatalogAccess interface, making it testable
 // and decoupled from the concrete SystemCatalog implementation.
@@ -32,6 +39,9 @@ func NewIndexOperations(access catalogio.CatalogAccess, indexTableID primitives.
 		},
 	)
 
+	base.AddIndex(indexesByID, func(im *systemtable.IndexMetadata) any { return im.IndexID })
+	base.AddIndex(indexesByName, func(im *systemtable.IndexMetadata) any { return strings.ToLower(im.IndexName) })
+	base.AddIndex(indexesByTable, func(im *systemtable.IndexMetadata) any { return im.TableID })
 	return &IndexOperations{
 		BaseOperations: base,
 	}
@@ -45,8 +55,8 @@ func NewIndexOperations(access catalogio.CatalogAccess, indexTableID primitives.
 //
 // Returns a slice of IndexMetadata for all indexes on the table, or an error if the catalog cannot be read.
 func (io *IndexOperations) GetIndexesByTable(tx *transaction.TransactionContext, tableID primitives.FileID) ([]*systemtable.IndexMetadata, error) {
-	return io.FindAll(tx, func(im *systemtable.IndexMetadata) bool {
-		return im.TableID == tableID
+	return io.FindAllBy(tx, indexesByTable, tableID, func(im *systemtable.IndexMetadata) bool {
+		return true
 	})
 }
 
@@ -59,7 +69,7 @@ func (io *IndexOperations) GetIndexesByTable(tx *transaction.TransactionContext,
 //
 // Returns IndexMetadata or an error if the index is not found.
 func (io *IndexOperations) GetIndexByName(tx *transaction.TransactionContext, indexName string) (*systemtable.IndexMetadata, error) {
-	return io.FindOne(tx, func(im *systemtable.IndexMetadata) bool {
+	return io.FindOneBy(tx, indexesByName, strings.ToLower(indexName), func(im *systemtable.IndexMetadata) bool {
 		return strings.EqualFold(im.IndexName, indexName)
 	})
 }
@@ -72,8 +82,8 @@ func (io *IndexOperations) GetIndexByName(tx *transaction.TransactionContext, in
 //
 // Returns IndexMetadata or an error if the index is not found.
 func (io *IndexOperations) GetIndexByID(tx *transaction.TransactionContext, indexID primitives.FileID) (*systemtable.IndexMetadata, error) {
-	return io.FindOne(tx, func(im *systemtable.IndexMetadata) bool {
-		return im.IndexID == indexID
+	return io.FindOneBy(tx, indexesByID, indexID, func(im *systemtable.IndexMetadata) bool {
+		return true
 	})
 }
 
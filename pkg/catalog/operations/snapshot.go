@@ -0,0 +1,174 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/tuple"
+)
+
+// tableSnapshot is a cached copy of the committed contents of a catalog
+// table, in scan order, with the secondary indexes of the BaseOperations that
+// took it. Tuples are kept rather than parsed entities so that callers can
+// change the entities they get back without corrupting the cache.
+type tableSnapshot struct {
+	version uint64
+	tuples  []*tuple.Tuple
+	// Index name -> key -> positions in tuples
+	indexes map[string]map[any][]int
+}
+
+// AddIndex registers a secondary index over the cached contents of the
+// table, for FindAllBy and FindOneBy. key extracts the index key of an
+// entity and must return a comparable value.
+//
+// Indexes are meant to be added by the constructor of an operations type,
+// before the BaseOperations is shared.
+func (bo *BaseOperations[T]) AddIndex(name string, key func(T) any) {
+	if bo.indexes == nil {
+		bo.indexes = make(map[string]func(T) any)
+	}
+	bo.indexes[name] = key
+	bo.snapshot.Store(nil)
+}
+
+// FindAllBy finds all entities whose key in the named index equals key and
+// that match the predicate, in table order.
+//
+// When the catalog access tracks changes, the lookup is served from a cached
+// copy of the table as long as no transaction has uncommitted writes to it;
+// otherwise it falls back to FindAll.
+//
+// Parameters:
+//   - tx: Transaction context
+//   - index: Name of an index registered with AddIndex
+//   - key: Index key to look up
+//   - predicate: Function that returns true for entities to include in results
+//
+// Returns slice of matching entities or error if the index does not exist or iteration fails.
+func (bo *BaseOperations[T]) FindAllBy(tx TxContext, index string, key any, predicate func(T) bool) ([]T, error) {
+	keyFunc, ok := bo.indexes[index]
+	if !ok {
+		return nil, fmt.Errorf("catalog table %d has no index %q", bo.tableID, index)
+	}
+
+	snap, err := bo.loadSnapshot(tx)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return bo.FindAll(tx, func(entity T) bool {
+			return keyFunc(entity) == key && predicate(entity)
+		})
+	}
+
+	var results []T
+	for _, pos := range snap.indexes[index][key] {
+		entity, err := bo.parser(snap.tuples[pos])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tuple: %w", err)
+		}
+		if predicate(entity) {
+			results = append(results, entity)
+		}
+	}
+	return results, nil
+}
+
+// FindOneBy finds the first entity whose key in the named index equals key
+// and that matches the predicate. See FindAllBy.
+//
+// Returns the matching entity or error if not found or iteration fails.
+func (bo *BaseOperations[T]) FindOneBy(tx TxContext, index string, key any, predicate func(T) bool) (T, error) {
+	var result T
+	results, err := bo.FindAllBy(tx, index, key, predicate)
+	if err != nil {
+		return result, err
+	}
+	if len(results) == 0 {
+		return result, fmt.Errorf("entity not found")
+	}
+	return results[0], nil
+}
+
+// loadSnapshot returns the cached copy of the table for tx, taking it if the
+// cached copy is stale. It returns nil if tx cannot use a cached copy: the
+// catalog access does not track changes, or some transaction has
+// uncommitted writes to the table.
+//
+// A copy taken while the table changed is returned to tx, which saw those
+// contents through its own scan, but not cached.
+func (bo *BaseOperations[T]) loadSnapshot(tx TxContext) (*tableSnapshot, error) {
+	if bo.tracker == nil {
+		return nil, nil
+	}
+	version, ok := bo.tracker.TableVersion(bo.tableID, tx)
+	if !ok {
+		return nil, nil
+	}
+	if snap := bo.snapshot.Load(); snap != nil && snap.version == version {
+		return snap, nil
+	}
+
+	snap := &tableSnapshot{
+		version: version,
+		indexes: make(map[string]map[any][]int, len(bo.indexes)),
+	}
+	for name := range bo.indexes {
+		snap.indexes[name] = make(map[any][]int)
+	}
+
+	err := bo.reader.IterateTable(bo.tableID, tx, func(tup *tuple.Tuple) error {
+		entity, err := bo.parser(tup)
+		if err != nil {
+			return fmt.Errorf("failed to parse tuple: %w", err)
+		}
+		pos := len(snap.tuples)
+		snap.tuples = append(snap.tuples, tup)
+		for name, keyFunc := range bo.indexes {
+			key := keyFunc(entity)
+			snap.indexes[name][key] = append(snap.indexes[name][key], pos)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if after, ok := bo.tracker.TableVersion(bo.tableID, tx); ok && after == version {
+		bo.snapshot.Store(snap)
+	}
+	return snap, nil
+}
+
+// iterateSnapshot applies processFunc to each entity of a cached copy of the
+// table, like Iterate.
+func (bo *BaseOperations[T]) iterateSnapshot(snap *tableSnapshot, processFunc func(T) error) error {
+	for _, tup := range snap.tuples {
+		entity, err := bo.parser(tup)
+		if err != nil {
+			return fmt.Errorf("failed to parse tuple: %w", err)
+		}
+
+		if err = processFunc(entity); err != nil {
+			if errors.Is(err, ErrSuccess) {
+				return err
+			}
+			return fmt.Errorf("failed to process entity: %w", err)
+		}
+	}
+	return nil
+}
+
+// Version returns the version of the table's contents, which changes
+// whenever the table is written, including by tx itself. It returns false if
+// the catalog access does not track changes.
+//
+// Callers can cache what they derive from the table for as long as the
+// version stays the same.
+func (bo *BaseOperations[T]) Version(tx TxContext) (uint64, bool) {
+	if bo.tracker == nil {
+		return 0, false
+	}
+	version, _ := bo.tracker.TableVersion(bo.tableID, tx)
+	return version, true
+}
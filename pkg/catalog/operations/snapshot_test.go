@@ -0,0 +1,113 @@
+package operations
+
+import (
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/primitives"
+	"testing"
+)
+
+// trackingCatalogAccess is a mockCatalogAccess that also tracks changes,
+// counting table scans to tell cached reads from uncached ones.
+type trackingCatalogAccess struct {
+	*mockCatalogAccess
+	version uint64
+	pending bool // A transaction has uncommitted writes
+	scans   int
+}
+
+func (m *trackingCatalogAccess) IterateTable(tableID primitives.FileID, tx TxContext, fn func(*Tuple) error) error {
+	m.scans++
+	return m.mockCatalogAccess.IterateTable(tableID, tx, fn)
+}
+
+func (m *trackingCatalogAccess) InsertRow(tableID primitives.FileID, tx TxContext, t *Tuple) error {
+	m.version++
+	return m.mockCatalogAccess.InsertRow(tableID, tx, t)
+}
+
+func (m *trackingCatalogAccess) TableVersion(tableID primitives.FileID, tx TxContext) (uint64, bool) {
+	return m.version, !m.pending
+}
+
+func TestFindAllBy_ServedFromSnapshot(t *testing.T) {
+	access := &trackingCatalogAccess{mockCatalogAccess: newMockCatalogAccess()}
+	constraintsTableID := primitives.FileID(100)
+	ops := NewConstraintOperations(access, constraintsTableID)
+
+	for i, tableID := range []primitives.FileID{1, 2, 1} {
+		if err := ops.Insert(nil, &systemtable.ConstraintMetadata{
+			ConstraintID:   primitives.FileID(10 + i),
+			ConstraintName: "c" + string(rune('a'+i)),
+			TableID:        tableID,
+			ConstraintType: systemtable.ConstraintTypeUnique,
+			ColumnNames:    "id",
+			IsEnabled:      i != 2,
+		}); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+
+	enabled, err := ops.GetEnabledConstraintsForTable(nil, 1)
+	if err != nil {
+		t.Fatalf("GetEnabledConstraintsForTable failed: %v", err)
+	}
+	if len(enabled) != 1 || enabled[0].ConstraintName != "ca" {
+		t.Fatalf("expected constraint ca, got %v", enabled)
+	}
+
+	// Lookups through any index reuse the snapshot taken by the first one
+	all, _ := ops.GetConstraintsForTable(nil, 1)
+	byID, _ := ops.GetConstraintByID(nil, 11)
+	byName, _ := ops.GetConstraintByName(nil, 1, "CC")
+	if len(all) != 2 || byID == nil || byID.TableID != 2 || byName == nil || byName.ConstraintID != 12 {
+		t.Fatalf("unexpected lookup results: %v, %v, %v", all, byID, byName)
+	}
+	if access.scans != 1 {
+		t.Errorf("expected 1 scan, got %d", access.scans)
+	}
+
+	// Entities handed out are copies
+	all[0].IsEnabled = false
+	if enabled, _ := ops.GetEnabledConstraintsForTable(nil, 1); len(enabled) != 1 {
+		t.Error("changing a returned entity changed the cached table")
+	}
+
+	// A write makes the snapshot stale
+	ops.Insert(nil, &systemtable.ConstraintMetadata{ConstraintID: 20, ConstraintName: "cd", TableID: 1, ColumnNames: "id", IsEnabled: true})
+	if enabled, _ := ops.GetEnabledConstraintsForTable(nil, 1); len(enabled) != 2 {
+		t.Errorf("expected 2 enabled constraints after the insert, got %d", len(enabled))
+	}
+	if access.scans != 2 {
+		t.Errorf("expected a rescan after the insert, got %d scans", access.scans)
+	}
+}
+
+func TestFindAllBy_BypassesSnapshotDuringWrites(t *testing.T) {
+	access := &trackingCatalogAccess{mockCatalogAccess: newMockCatalogAccess()}
+	ops := NewTableOperations(access, 100)
+	ops.Insert(nil, createTableTuple(10, "users", "/data/users.dat", "id"))
+
+	access.pending = true
+	for range 3 {
+		if _, err := ops.GetTableMetadataByName(nil, "USERS"); err != nil {
+			t.Fatalf("GetTableMetadataByName failed: %v", err)
+		}
+	}
+	if access.scans != 3 {
+		t.Errorf("expected every lookup to scan while a write is pending, got %d scans", access.scans)
+	}
+
+	access.pending = false
+	ops.GetTableMetadataByName(nil, "users")
+	ops.GetTableMetadataByID(nil, 10)
+	if access.scans != 4 {
+		t.Errorf("expected lookups to share one scan once writes finish, got %d scans", access.scans-3)
+	}
+}
+
+func TestFindAllBy_UnknownIndex(t *testing.T) {
+	ops := NewTableOperations(newMockCatalogAccess(), 100)
+	if _, err := ops.FindAllBy(nil, "no_such_index", 1, func(*systemtable.TableMetadata) bool { return true }); err == nil {
+		t.Error("expected an error for an unknown index")
+	}
+}
@@ -9,6 +9,12 @@ import (
 	"strings"
 )
 
+// Secondary indexes over CATALOG_TABLES
+const (
+	tablesByID   = "table_id"
+	tablesByName = "table_name"
+)
+
 type TableOperations struct {
 	*BaseOperations[*systemtable.TableMetadata]
 }
@@ -17,21 +23,25 @@ func NewTableOperations(access catalogio.CatalogAccess, tableID primitives.FileI
 	baseOp := NewBaseOperations(access, tableID, systemtable.Tables.Parse, func(t *systemtable.TableMetadata) *tuple.Tuple {
 		return systemtable.Tables.CreateTuple(*t)
 	})
+	baseOp.AddIndex(tablesByID, func(tm *systemtable.TableMetadata) any { return tm.TableID })
+	baseOp.AddIndex(tablesByName, func(tm *systemtable.TableMetadata) any { return strings.ToLower(tm.TableName) })
 	return &TableOperations{
 		BaseOperations: baseOp,
 	}
 }
 
-// findTableMetadata is a generic helper for searching CATALOG_TABLES with a custom predicate.
+// findTableMetadata is a generic helper for searching CATALOG_TABLES through one of its indexes.
 // Used by GetTableMetadataByID and GetTableMetadataByName to avoid code duplication.
 //
 // Parameters:
 //   - tid: Transaction ID for reading catalog
+//   - index: Name of the index to search
+//   - key: Index key of the desired table
 //   - pred: Predicate function that returns true when the desired table is found
 //
 // Returns the matching TableMetadata or an error if not found or if catalog access fails.
-func (to *TableOperations) findTableMetadata(tx TxContext, pred func(tm *systemtable.TableMetadata) bool) (*systemtable.TableMetadata, error) {
-	res, err := to.FindOne(tx, pred)
+func (to *TableOperations) findTableMetadata(tx TxContext, index string, key any, pred func(tm *systemtable.TableMetadata) bool) (*systemtable.TableMetadata, error) {
+	res, err := to.FindOneBy(tx, index, key, pred)
 
 	if err != nil {
 		return nil, fmt.Errorf("table not found in catalog: %w", err)
@@ -60,8 +70,8 @@ func (to *TableOperations) GetAllTables(tx TxContext) ([]*systemtable.TableMetad
 // Returns TableMetadata containing table name, file path, and primary key column,
 // or an error if the table is not found.
 func (to *TableOperations) GetTableMetadataByID(tx TxContext, tableID primitives.FileID) (*systemtable.TableMetadata, error) {
-	return to.findTableMetadata(tx, func(tm *systemtable.TableMetadata) bool {
-		return tm.TableID == tableID
+	return to.findTableMetadata(tx, tablesByID, tableID, func(tm *systemtable.TableMetadata) bool {
+		return true
 	})
 }
 
@@ -70,7 +80,7 @@ func (to *TableOperations) GetTableMetadataByID(tx TxContext, tableID primitives
 // Returns TableMetadata containing table ID, file path, and primary key column,
 // or an error if the table is not found.
 func (to *TableOperations) GetTableMetadataByName(tx TxContext, tableName string) (*systemtable.TableMetadata, error) {
-	return to.findTableMetadata(tx, func(tm *systemtable.TableMetadata) bool {
+	return to.findTableMetadata(tx, tablesByName, strings.ToLower(tableName), func(tm *systemtable.TableMetadata) bool {
 		return strings.EqualFold(tm.TableName, tableName)
 	})
 }
@@ -1,45 +1,117 @@
 package catalog
 
 import (
+	"sort"
 	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/clock"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 	"sync"
 	"time"
 )
 
+// AutoAnalyzeConfig controls when the background updater re-analyzes a table.
+// A table is due once the rows changed since its last analyze reach
+// MinChanges + Fraction * (rows at the last analyze), so small tables are not
+// re-analyzed on every write and large tables are not left stale for long.
+type AutoAnalyzeConfig struct {
+	Enabled    bool          // Whether the background updater runs at all
+	Interval   time.Duration // How often the updater checks the modification counters
+	Fraction   float64       // Fraction of the table that must change, e.g. 0.1 for 10%
+	MinChanges int           // Changes required regardless of table size
+}
+
+// DefaultAutoAnalyzeConfig returns the auto-analyze configuration used for new databases.
+func DefaultAutoAnalyzeConfig() AutoAnalyzeConfig {
+	return AutoAnalyzeConfig{
+		Enabled:    true,
+		Interval:   30 * time.Second,
+		Fraction:   0.1,
+		MinChanges: 50,
+	}
+}
+
+// TableActivity is a snapshot of the modification tracking for one table.
+type TableActivity struct {
+	TableID          primitives.FileID
+	TableName        string
+	Modifications    int       // Rows changed since the last analyze
+	Threshold        int       // Modifications at which the table is re-analyzed
+	LastAnalyzedRows uint64    // Cardinality recorded by the last analyze
+	LastAnalyze      time.Time // Zero if the table has not been analyzed since startup
+	AutoAnalyzeCount int64     // Analyzes run by the background updater
+}
+
 // StatisticsManager handles automatic statistics updates with intelligent caching
 type StatisticsManager struct {
 	catalog           *catalogmanager.CatalogManager
+	tableNames        map[primitives.FileID]string    // tableID -> name reported by the last modification
 	lastUpdate        map[primitives.FileID]time.Time // tableID -> last update time
 	modificationCount map[primitives.FileID]int       // tableID -> number of modifications since last stats update
+	analyzedRows      map[primitives.FileID]uint64    // tableID -> cardinality at last stats update
+	autoAnalyzeCount  map[primitives.FileID]int64     // tableID -> updates run by the background updater
 	mu                sync.RWMutex
 	updateThreshold   int            // Number of modifications before forcing stats update
+	updateFraction    float64        // Fraction of analyzed rows added to updateThreshold
 	updateInterval    time.Duration  // Minimum time between stats updates
 	stopChan          chan struct{}  // Channel to signal background worker to stop
 	wg                sync.WaitGroup // WaitGroup to track background worker
+	clock             clock.Clock    // Time source for update intervals and the background ticker
+	logger            logging.ComponentLogger
 	db                interface {
 		BeginTransaction() (*transaction.TransactionContext, error)
 		CommitTransaction(tx *transaction.TransactionContext) error
-	} // Database interface for creating and committing transactions
+		AbortTransaction(tx *transaction.TransactionContext) error
+	} // Database interface for creating and finishing transactions
 }
 
 // NewStatisticsManager creates a new statistics manager
 func NewStatisticsManager(catalog *catalogmanager.CatalogManager, db interface {
 	BeginTransaction() (*transaction.TransactionContext, error)
 	CommitTransaction(tx *transaction.TransactionContext) error
+	AbortTransaction(tx *transaction.TransactionContext) error
 }) *StatisticsManager {
+	cfg := DefaultAutoAnalyzeConfig()
 	return &StatisticsManager{
 		catalog:           catalog,
 		db:                db,
+		tableNames:        make(map[primitives.FileID]string),
 		lastUpdate:        make(map[primitives.FileID]time.Time),
 		modificationCount: make(map[primitives.FileID]int),
-		updateThreshold:   1000, // Update stats after 1000 modifications
+		analyzedRows:      make(map[primitives.FileID]uint64),
+		autoAnalyzeCount:  make(map[primitives.FileID]int64),
+		updateThreshold:   cfg.MinChanges,
+		updateFraction:    cfg.Fraction,
 		updateInterval:    5 * time.Minute,
 		stopChan:          make(chan struct{}),
+		clock:             clock.Real,
+		logger:            logging.ForComponent("statistics_manager"),
 	}
 }
 
+// SetClock replaces the time source used for update intervals and the
+// background updater. It must be called before StartBackgroundUpdater.
+func (sm *StatisticsManager) SetClock(c clock.Clock) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.clock = c
+}
+
+// SetLogger replaces the manager's logger. It must be called before StartBackgroundUpdater.
+func (sm *StatisticsManager) SetLogger(logger logging.ComponentLogger) {
+	sm.logger = logger
+}
+
+// SetAutoAnalyzeConfig applies the thresholds of cfg. Enabled and Interval are
+// read by the caller when deciding whether and how to start the background updater.
+func (sm *StatisticsManager) SetAutoAnalyzeConfig(cfg AutoAnalyzeConfig) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.updateThreshold = cfg.MinChanges
+	sm.updateFraction = cfg.Fraction
+}
+
 // RecordModification records a modification to a table (insert/delete/update)
 // This is called by the PageStore after successful modifications
 func (sm *StatisticsManager) RecordModification(tableID primitives.FileID) {
@@ -49,6 +121,32 @@ func (sm *StatisticsManager) RecordModification(tableID primitives.FileID) {
 	sm.modificationCount[tableID]++
 }
 
+// RecordModifications records count modified rows of a table at once.
+// It is called by the DML executor after every INSERT, UPDATE and DELETE.
+func (sm *StatisticsManager) RecordModifications(tableID primitives.FileID, tableName string, count int) {
+	if count <= 0 {
+		return
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.tableNames[tableID] = tableName
+	sm.modificationCount[tableID] += count
+}
+
+// Forget discards the tracking state of a table, e.g. after it was dropped.
+func (sm *StatisticsManager) Forget(tableID primitives.FileID) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	delete(sm.tableNames, tableID)
+	delete(sm.lastUpdate, tableID)
+	delete(sm.modificationCount, tableID)
+	delete(sm.analyzedRows, tableID)
+	delete(sm.autoAnalyzeCount, tableID)
+}
+
 // ShouldUpdateStatistics determines if statistics should be updated based on
 // modification count and time since last update
 func (sm *StatisticsManager) ShouldUpdateStatistics(tableID primitives.FileID) bool {
@@ -58,24 +156,37 @@ func (sm *StatisticsManager) ShouldUpdateStatistics(tableID primitives.FileID) b
 	modCount := sm.modificationCount[tableID]
 	lastUpdate, exists := sm.lastUpdate[tableID]
 
-	if modCount >= sm.updateThreshold {
+	if modCount >= sm.thresholdLocked(tableID) {
 		return true
 	}
 
-	if exists && time.Since(lastUpdate) >= sm.updateInterval {
+	if exists && sm.clock.Since(lastUpdate) >= sm.updateInterval {
 		return true
 	}
 
 	return !exists
 }
 
+// thresholdLocked returns the number of modifications at which the table is
+// due for a statistics update. Assumes the lock is held.
+func (sm *StatisticsManager) thresholdLocked(tableID primitives.FileID) int {
+	return sm.updateThreshold + int(sm.updateFraction*float64(sm.analyzedRows[tableID]))
+}
+
 // UpdateStatisticsIfNeeded updates statistics only if needed based on heuristics
 func (sm *StatisticsManager) UpdateStatisticsIfNeeded(tx *transaction.TransactionContext, tableID primitives.FileID) error {
 	if !sm.ShouldUpdateStatistics(tableID) {
 		return nil
 	}
+	return sm.ForceUpdate(tx, tableID)
+}
+
+// ForceUpdate forces an immediate statistics update regardless of heuristics
+func (sm *StatisticsManager) ForceUpdate(tx *transaction.TransactionContext, tableID primitives.FileID) error {
+	sm.mu.RLock()
+	pending := sm.modificationCount[tableID]
+	sm.mu.RUnlock()
 
-	// Update table-level statistics
 	if err := sm.catalog.UpdateTableStatistics(tx, tableID); err != nil {
 		return err
 	}
@@ -83,47 +194,38 @@ func (sm *StatisticsManager) UpdateStatisticsIfNeeded(tx *transaction.Transactio
 	// Update column-level statistics
 	if err := sm.catalog.UpdateColumnStatistics(tx, tableID); err != nil {
 		// Non-fatal: log but continue
-		// In production, you'd use proper logging
+		sm.logger.Warn("failed to update column statistics", "table_id", tableID, "error", err)
 	}
 
 	// Update index statistics
 	if err := sm.catalog.UpdateIndexStatistics(tx, tableID); err != nil {
 		// Non-fatal: log but continue
+		sm.logger.Warn("failed to update index statistics", "table_id", tableID, "error", err)
 	}
 
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.lastUpdate[tableID] = time.Now()
-	sm.modificationCount[tableID] = 0
-
+	sm.MarkAnalyzed(tx, tableID, pending)
 	return nil
 }
 
-// ForceUpdate forces an immediate statistics update regardless of heuristics
-func (sm *StatisticsManager) ForceUpdate(tx *transaction.TransactionContext, tableID primitives.FileID) error {
-	if err := sm.catalog.UpdateTableStatistics(tx, tableID); err != nil {
-		return err
-	}
-
-	// Update column-level statistics
-	if err := sm.catalog.UpdateColumnStatistics(tx, tableID); err != nil {
-		// Non-fatal: log but continue
-	}
-
-	// Update index statistics
-	if err := sm.catalog.UpdateIndexStatistics(tx, tableID); err != nil {
-		// Non-fatal: log but continue
+// MarkAnalyzed records that the statistics of a table were just refreshed.
+// pending is the modification count read before the refresh started; only
+// those modifications are cleared, so rows changed while the refresh ran
+// still count towards the next one.
+func (sm *StatisticsManager) MarkAnalyzed(tx *transaction.TransactionContext, tableID primitives.FileID, pending int) {
+	var rows uint64
+	if stats, err := sm.catalog.GetTableStatistics(tx, tableID); err == nil && stats != nil {
+		rows = stats.Cardinality
 	}
 
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.lastUpdate[tableID] = time.Now()
-	sm.modificationCount[tableID] = 0
-
-	return nil
+	sm.lastUpdate[tableID] = sm.clock.Now()
+	sm.analyzedRows[tableID] = rows
+	sm.modificationCount[tableID] = max(sm.modificationCount[tableID]-pending, 0)
 }
 
-// SetUpdateThreshold sets the modification threshold for automatic updates
+// SetUpdateThreshold sets the modification threshold for automatic updates.
+// The fraction of the table configured by SetAutoAnalyzeConfig is added on top.
 func (sm *StatisticsManager) SetUpdateThreshold(threshold int) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -144,6 +246,36 @@ func (sm *StatisticsManager) GetModificationCount(tableID primitives.FileID) int
 	return sm.modificationCount[tableID]
 }
 
+// Activity returns the tracking state of every table modified or analyzed
+// since startup, ordered by table ID.
+func (sm *StatisticsManager) Activity() []TableActivity {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	seen := make(map[primitives.FileID]bool)
+	for tableID := range sm.modificationCount {
+		seen[tableID] = true
+	}
+	for tableID := range sm.lastUpdate {
+		seen[tableID] = true
+	}
+
+	activity := make([]TableActivity, 0, len(seen))
+	for tableID := range seen {
+		activity = append(activity, TableActivity{
+			TableID:          tableID,
+			TableName:        sm.tableNames[tableID],
+			Modifications:    sm.modificationCount[tableID],
+			Threshold:        sm.thresholdLocked(tableID),
+			LastAnalyzedRows: sm.analyzedRows[tableID],
+			LastAnalyze:      sm.lastUpdate[tableID],
+			AutoAnalyzeCount: sm.autoAnalyzeCount[tableID],
+		})
+	}
+	sort.Slice(activity, func(i, j int) bool { return activity[i].TableID < activity[j].TableID })
+	return activity
+}
+
 // StartBackgroundUpdater starts a background goroutine that periodically updates statistics
 // for tables that have exceeded their update threshold or time interval
 func (sm *StatisticsManager) StartBackgroundUpdater(checkInterval time.Duration) {
@@ -154,28 +286,39 @@ func (sm *StatisticsManager) StartBackgroundUpdater(checkInterval time.Duration)
 // backgroundUpdateLoop is the main loop for the background statistics updater
 func (sm *StatisticsManager) backgroundUpdateLoop(checkInterval time.Duration) {
 	defer sm.wg.Done()
-	ticker := time.NewTicker(checkInterval)
+	sm.mu.RLock()
+	ticker := sm.clock.NewTicker(checkInterval)
+	sm.mu.RUnlock()
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			sm.processTableUpdates()
+		case <-ticker.C():
+			sm.RunAutoAnalyze()
 		case <-sm.stopChan:
 			return
 		}
 	}
 }
 
-// processTableUpdates checks all tables with modifications and updates stats if needed
-func (sm *StatisticsManager) processTableUpdates() {
+// RunAutoAnalyze performs one pass of the background updater immediately:
+// every table whose modifications reached its threshold is analyzed in its
+// own transaction. It returns the number of tables analyzed.
+func (sm *StatisticsManager) RunAutoAnalyze() int {
 	tablesToUpdate := sm.getTablesNeedingUpdate()
 
+	analyzed := 0
 	for _, tableID := range tablesToUpdate {
-		if err := sm.updateTableInSeparateTransaction(tableID); err != nil {
+		ok, err := sm.updateTableInSeparateTransaction(tableID)
+		if err != nil {
+			sm.logger.Warn("auto-analyze failed", "table_id", tableID, "error", err)
 			continue
 		}
+		if ok {
+			analyzed++
+		}
 	}
+	return analyzed
 }
 
 // getTablesNeedingUpdate returns a list of table IDs that need statistics updates
@@ -192,45 +335,58 @@ func (sm *StatisticsManager) getTablesNeedingUpdate() []primitives.FileID {
 	return tables
 }
 
-// shouldUpdateStatisticsLocked is the internal version that assumes lock is already held
+// shouldUpdateStatisticsLocked is the internal version that assumes lock is already held.
+// Unlike ShouldUpdateStatistics it never selects a table without pending modifications.
 func (sm *StatisticsManager) shouldUpdateStatisticsLocked(tableID primitives.FileID) bool {
 	modCount := sm.modificationCount[tableID]
+	if modCount == 0 {
+		return false
+	}
+
 	lastUpdate, exists := sm.lastUpdate[tableID]
 
-	if modCount >= sm.updateThreshold {
+	if modCount >= sm.thresholdLocked(tableID) {
 		return true
 	}
 
-	if exists && time.Since(lastUpdate) >= sm.updateInterval {
+	if exists && sm.clock.Since(lastUpdate) >= sm.updateInterval {
 		return true
 	}
 
-	return !exists && modCount > 0
+	return !exists
 }
 
-// updateTableInSeparateTransaction updates statistics for a table in its own transaction
-func (sm *StatisticsManager) updateTableInSeparateTransaction(tableID primitives.FileID) error {
+// updateTableInSeparateTransaction updates statistics for a table in its own transaction.
+// Tables that no longer exist are forgotten instead of being retried on every
+// tick; for those it reports false without an error.
+func (sm *StatisticsManager) updateTableInSeparateTransaction(tableID primitives.FileID) (bool, error) {
 	tx, err := sm.db.BeginTransaction()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	// Update statistics
-	err = sm.catalog.UpdateTableStatistics(tx, tableID)
+	tableName, err := sm.catalog.GetTableName(tx, tableID)
 	if err != nil {
-		return err
+		sm.db.AbortTransaction(tx)
+		sm.Forget(tableID)
+		return false, nil
+	}
+
+	if err := sm.ForceUpdate(tx, tableID); err != nil {
+		sm.db.AbortTransaction(tx)
+		return false, err
 	}
 
 	if err := sm.db.CommitTransaction(tx); err != nil {
-		return err
+		return false, err
 	}
 
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.lastUpdate[tableID] = time.Now()
-	sm.modificationCount[tableID] = 0
+	sm.autoAnalyzeCount[tableID]++
+	sm.mu.Unlock()
 
-	return nil
+	sm.logger.Info("auto-analyze completed", "table", tableName, "table_id", tableID)
+	return true, nil
 }
 
 // Stop gracefully stops the background updater
@@ -0,0 +1,81 @@
+package systemtable
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// AuditMetadata is the catalog record of an audited table.
+type AuditMetadata struct {
+	TableID      primitives.FileID // Table whose row changes are audited
+	AuditTableID primitives.FileID // Table the audit rows are written to
+}
+
+// AuditedTablesTable provides accessors and helpers for the
+// CATALOG_AUDITED_TABLES system table. Each row enables auditing for one table.
+type AuditedTablesTable struct {
+}
+
+// Schema returns the schema for the CATALOG_AUDITED_TABLES system table.
+// Schema layout:
+//
+//	(table_id INT PRIMARY KEY, audit_table_id INT)
+func (at *AuditedTablesTable) Schema() *schema.Schema {
+	sch, _ := schema.NewSchemaBuilder(InvalidTableID, at.TableName()).
+		AddPrimaryKey("table_id", types.Uint64Type).
+		AddColumn("audit_table_id", types.Uint64Type).
+		Build()
+	return sch
+}
+
+// TableName returns the canonical name of the system table.
+func (at *AuditedTablesTable) TableName() string {
+	return "CATALOG_AUDITED_TABLES"
+}
+
+// FileName returns the filename used to persist the CATALOG_AUDITED_TABLES heap.
+func (at *AuditedTablesTable) FileName() string {
+	return "catalog_audited_tables.dat"
+}
+
+// PrimaryKey returns the primary key field name in the schema.
+func (at *AuditedTablesTable) PrimaryKey() string {
+	return "table_id"
+}
+
+// TableIDIndex returns the index of the table_id field.
+func (at *AuditedTablesTable) TableIDIndex() int {
+	return 0
+}
+
+// CreateTuple constructs a catalog tuple for a given AuditMetadata.
+func (at *AuditedTablesTable) CreateTuple(m AuditMetadata) *tuple.Tuple {
+	return tuple.NewBuilder(tupleDesc(at)).
+		AddUint64(uint64(m.TableID)).
+		AddUint64(uint64(m.AuditTableID)).
+		MustBuild()
+}
+
+// Parse converts a catalog tuple into an AuditMetadata.
+// Returns an error if the tuple does not match the schema or a table ID is zero.
+func (at *AuditedTablesTable) Parse(t *tuple.Tuple) (*AuditMetadata, error) {
+	p := tuple.NewParser(t).ExpectFields(2)
+
+	m := &AuditMetadata{
+		TableID:      primitives.FileID(p.ReadUint64()),
+		AuditTableID: primitives.FileID(p.ReadUint64()),
+	}
+
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+
+	if m.TableID == InvalidTableID || m.AuditTableID == InvalidTableID {
+		return nil, fmt.Errorf("invalid audit record: table_id and audit_table_id cannot be zero")
+	}
+
+	return m, nil
+}
@@ -0,0 +1,83 @@
+package systemtable
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// CacheHintMetadata is the catalog record of a table's buffer pool hint.
+type CacheHintMetadata struct {
+	TableID  primitives.FileID // Table the hint applies to
+	Priority string            // Eviction priority of its pages (see memory.CachePriority)
+}
+
+// CacheHintsTable provides accessors and helpers for the CATALOG_CACHE_HINTS
+// system table. Each row gives the pages of one table an eviction priority
+// other than the default.
+type CacheHintsTable struct {
+}
+
+// Schema returns the schema for the CATALOG_CACHE_HINTS system table.
+// Schema layout:
+//
+//	(table_id INT PRIMARY KEY, priority STRING)
+func (ct *CacheHintsTable) Schema() *schema.Schema {
+	sch, _ := schema.NewSchemaBuilder(InvalidTableID, ct.TableName()).
+		AddPrimaryKey("table_id", types.Uint64Type).
+		AddColumn("priority", types.StringType).
+		Build()
+	return sch
+}
+
+// TableName returns the canonical name of the system table.
+func (ct *CacheHintsTable) TableName() string {
+	return "CATALOG_CACHE_HINTS"
+}
+
+// FileName returns the filename used to persist the CATALOG_CACHE_HINTS heap.
+func (ct *CacheHintsTable) FileName() string {
+	return "catalog_cache_hints.dat"
+}
+
+// PrimaryKey returns the primary key field name in the schema.
+func (ct *CacheHintsTable) PrimaryKey() string {
+	return "table_id"
+}
+
+// TableIDIndex returns the index of the table_id field.
+func (ct *CacheHintsTable) TableIDIndex() int {
+	return 0
+}
+
+// CreateTuple constructs a catalog tuple for a given CacheHintMetadata.
+func (ct *CacheHintsTable) CreateTuple(m CacheHintMetadata) *tuple.Tuple {
+	return tuple.NewBuilder(tupleDesc(ct)).
+		AddUint64(uint64(m.TableID)).
+		AddString(m.Priority).
+		MustBuild()
+}
+
+// Parse converts a catalog tuple into a CacheHintMetadata.
+// Returns an error if the tuple does not match the schema, the table ID is
+// zero or the priority is empty.
+func (ct *CacheHintsTable) Parse(t *tuple.Tuple) (*CacheHintMetadata, error) {
+	p := tuple.NewParser(t).ExpectFields(2)
+
+	m := &CacheHintMetadata{
+		TableID:  primitives.FileID(p.ReadUint64()),
+		Priority: p.ReadString(),
+	}
+
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+
+	if m.TableID == InvalidTableID || m.Priority == "" {
+		return nil, fmt.Errorf("invalid cache hint: table_id cannot be zero and priority cannot be empty")
+	}
+
+	return m, nil
+}
@@ -66,7 +66,7 @@ func (ct *ColumnsTable) TableIDIndex() int {
 // CreateTuple constructs a new catalog tuple from column metadata.
 // Auto-increment columns are initialized with next_auto_value=1.
 func (ct *ColumnsTable) CreateTuple(col schema.ColumnMetadata) *tuple.Tuple {
-	return tuple.NewBuilder(ct.Schema().TupleDesc).
+	return tuple.NewBuilder(tupleDesc(ct)).
 		AddUint64(uint64(col.TableID)).
 		AddString(col.Name).
 		AddInt(int64(col.FieldType)).
@@ -115,7 +115,7 @@ func (cst *ColumnStatsTable) Parse(t *tuple.Tuple) (*ColumnStatisticsRow, error)
 
 // CreateTuple creates a tuple for the column statistics table
 func (cst *ColumnStatsTable) CreateTuple(stats *ColumnStatisticsRow) *tuple.Tuple {
-	return tuple.NewBuilder(cst.Schema().TupleDesc).
+	return tuple.NewBuilder(tupleDesc(cst)).
 		AddUint64(uint64(stats.TableID)).
 		AddString(stats.ColumnName).
 		AddUint32(uint32(stats.ColumnIndex)).
@@ -3,6 +3,8 @@ package systemtable
 import (
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"sync"
 )
 
 const (
@@ -19,9 +21,27 @@ var (
 	ColumnStats     = &ColumnStatsTable{}
 	IndexStats      = &IndexStatsTable{}
 	Constraints     = &ConstraintsTable{}
-	AllSystemTables = []SystemTable{Tables, Columns, Stats, Indexes, ColumnStats, IndexStats, Constraints}
+	Migrations      = &MigrationsTable{}
+	ForeignTables   = &ForeignTablesTable{}
+	Triggers        = &TriggersTable{}
+	AuditedTables   = &AuditedTablesTable{}
+	CacheHints      = &CacheHintsTable{}
+	AllSystemTables = []SystemTable{Tables, Columns, Stats, Indexes, ColumnStats, IndexStats, Constraints, Migrations, ForeignTables, Triggers, AuditedTables, CacheHints}
 )
 
+// tupleDescs caches the tuple description of each system table by name, so
+// that building a catalog tuple does not rebuild the table's schema each time.
+var tupleDescs sync.Map
+
+// tupleDesc returns the tuple description of a system table's schema.
+func tupleDesc(st SystemTable) *tuple.TupleDescription {
+	if td, ok := tupleDescs.Load(st.TableName()); ok {
+		return td.(*tuple.TupleDescription)
+	}
+	td, _ := tupleDescs.LoadOrStore(st.TableName(), st.Schema().TupleDesc)
+	return td.(*tuple.TupleDescription)
+}
+
 // SystemTable defines the interface that all system catalog tables must implement.
 // System tables store metadata about the database schema and are managed internally
 // by the database engine, not directly accessible to users for modification.
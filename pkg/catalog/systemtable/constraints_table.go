@@ -138,7 +138,7 @@ func (ct *ConstraintsTable) GetNumFields() int {
 // CreateTuple constructs a catalog tuple for a given ConstraintMetadata.
 // Fields are populated in schema order.
 func (ct *ConstraintsTable) CreateTuple(cm ConstraintMetadata) *tuple.Tuple {
-	td := ct.Schema().TupleDesc
+	td := tupleDesc(ct)
 	return tuple.NewBuilder(td).
 		AddUint64(uint64(cm.ConstraintID)).
 		AddString(cm.ConstraintName).
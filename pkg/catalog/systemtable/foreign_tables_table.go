@@ -0,0 +1,99 @@
+package systemtable
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// ForeignTableMetadata is the catalog record of a foreign table: a table whose
+// rows are read from an external file each time it is scanned.
+type ForeignTableMetadata struct {
+	TableName string // Upper-case table name, unique across all tables
+	Format    string // File format (CSV or JSONL)
+	Location  string // File path as declared; relative paths are resolved against the data directory
+	Columns   string // Comma-separated "NAME TYPE" column definitions, in order
+	Header    bool   // CSV only: the first record holds column names
+	Delimiter string // CSV only: field separator
+}
+
+// ForeignTablesTable provides accessors and helpers for the CATALOG_FOREIGN_TABLES
+// system table. Each row defines one foreign table.
+type ForeignTablesTable struct {
+}
+
+// Schema returns the schema for the CATALOG_FOREIGN_TABLES system table.
+// Schema layout:
+//
+//	(table_name STRING PRIMARY KEY, format STRING, location STRING,
+//	 columns STRING, header BOOL, delimiter STRING)
+func (ft *ForeignTablesTable) Schema() *schema.Schema {
+	sch, _ := schema.NewSchemaBuilder(InvalidTableID, ft.TableName()).
+		AddPrimaryKey("table_name", types.StringType).
+		AddColumn("format", types.StringType).
+		AddColumn("location", types.StringType).
+		AddColumn("columns", types.StringType).
+		AddColumn("header", types.BoolType).
+		AddColumn("delimiter", types.StringType).
+		Build()
+	return sch
+}
+
+// TableName returns the canonical name of the system table.
+func (ft *ForeignTablesTable) TableName() string {
+	return "CATALOG_FOREIGN_TABLES"
+}
+
+// FileName returns the filename used to persist the CATALOG_FOREIGN_TABLES heap.
+func (ft *ForeignTablesTable) FileName() string {
+	return "catalog_foreign_tables.dat"
+}
+
+// PrimaryKey returns the primary key field name in the schema.
+func (ft *ForeignTablesTable) PrimaryKey() string {
+	return "table_name"
+}
+
+// TableIDIndex returns -1: foreign tables have no heap file and therefore no
+// table ID.
+func (ft *ForeignTablesTable) TableIDIndex() int {
+	return -1
+}
+
+// CreateTuple constructs a catalog tuple for a given ForeignTableMetadata.
+func (ft *ForeignTablesTable) CreateTuple(m ForeignTableMetadata) *tuple.Tuple {
+	return tuple.NewBuilder(tupleDesc(ft)).
+		AddString(m.TableName).
+		AddString(m.Format).
+		AddString(m.Location).
+		AddString(m.Columns).
+		AddBool(m.Header).
+		AddString(m.Delimiter).
+		MustBuild()
+}
+
+// Parse converts a catalog tuple into a ForeignTableMetadata.
+// Returns an error if the tuple does not match the schema or a required field is empty.
+func (ft *ForeignTablesTable) Parse(t *tuple.Tuple) (*ForeignTableMetadata, error) {
+	p := tuple.NewParser(t).ExpectFields(6)
+
+	m := &ForeignTableMetadata{
+		TableName: p.ReadString(),
+		Format:    p.ReadString(),
+		Location:  p.ReadString(),
+		Columns:   p.ReadString(),
+		Header:    p.ReadBool(),
+		Delimiter: p.ReadString(),
+	}
+
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+
+	if m.TableName == "" || m.Location == "" || m.Columns == "" {
+		return nil, fmt.Errorf("invalid foreign table record: table_name, location and columns are required")
+	}
+
+	return m, nil
+}
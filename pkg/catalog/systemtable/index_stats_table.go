@@ -157,7 +157,7 @@ func (ist *IndexStatsTable) Parse(t *tuple.Tuple) (*IndexStatisticsRow, error) {
 // CreateTuple creates a tuple for the index statistics table
 func (ist *IndexStatsTable) CreateTuple(stats *IndexStatisticsRow) *tuple.Tuple {
 	clusteringFactorInt := int64(stats.ClusteringFactor * 1000000.0)
-	return tuple.NewBuilder(ist.Schema().TupleDesc).
+	return tuple.NewBuilder(tupleDesc(ist)).
 		AddUint64(uint64(stats.IndexID)).
 		AddUint64(uint64(stats.TableID)).
 		AddString(stats.IndexName).
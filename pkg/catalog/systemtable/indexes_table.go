@@ -57,7 +57,7 @@ func (it *IndexesTable) GetNumFields() int {
 
 // CreateTuple creates a tuple from IndexMetadata
 func (it *IndexesTable) CreateTuple(im IndexMetadata) *tuple.Tuple {
-	return tuple.NewBuilder(it.Schema().TupleDesc).
+	return tuple.NewBuilder(tupleDesc(it)).
 		AddUint64(uint64(im.IndexID)).
 		AddString(im.IndexName).
 		AddUint64(uint64(im.TableID)).
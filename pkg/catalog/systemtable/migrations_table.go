@@ -0,0 +1,93 @@
+package systemtable
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"time"
+)
+
+// MigrationRecord is one applied schema migration as recorded in the catalog.
+type MigrationRecord struct {
+	Version   uint64    // Migration version, unique and increasing
+	Name      string    // Human-readable migration name
+	Checksum  string    // Hex digest of the migration's Up script when it was applied
+	AppliedAt time.Time // When the migration was committed
+}
+
+// MigrationsTable provides accessors and helpers for the CATALOG_SCHEMA_MIGRATIONS
+// system table. Each row records a migration that has been applied to the database.
+type MigrationsTable struct {
+}
+
+// Schema returns the schema for the CATALOG_SCHEMA_MIGRATIONS system table.
+// Schema layout:
+//
+//	(version INT PRIMARY KEY, name STRING, checksum STRING, applied_at INT)
+func (mt *MigrationsTable) Schema() *schema.Schema {
+	sch, _ := schema.NewSchemaBuilder(InvalidTableID, mt.TableName()).
+		AddPrimaryKey("version", types.Uint64Type).
+		AddColumn("name", types.StringType).
+		AddColumn("checksum", types.StringType).
+		AddColumn("applied_at", types.Int64Type).
+		Build()
+	return sch
+}
+
+// TableName returns the canonical name of the system table.
+func (mt *MigrationsTable) TableName() string {
+	return "CATALOG_SCHEMA_MIGRATIONS"
+}
+
+// FileName returns the filename used to persist the CATALOG_SCHEMA_MIGRATIONS heap.
+func (mt *MigrationsTable) FileName() string {
+	return "catalog_schema_migrations.dat"
+}
+
+// PrimaryKey returns the primary key field name in the schema.
+func (mt *MigrationsTable) PrimaryKey() string {
+	return "version"
+}
+
+// TableIDIndex returns -1: migrations belong to the database, not to a table,
+// so rows are never removed when a table is dropped.
+func (mt *MigrationsTable) TableIDIndex() int {
+	return -1
+}
+
+// CreateTuple constructs a catalog tuple for a given MigrationRecord.
+func (mt *MigrationsTable) CreateTuple(m MigrationRecord) *tuple.Tuple {
+	return tuple.NewBuilder(tupleDesc(mt)).
+		AddUint64(m.Version).
+		AddString(m.Name).
+		AddString(m.Checksum).
+		AddInt64(m.AppliedAt.Unix()).
+		MustBuild()
+}
+
+// Parse converts a catalog tuple into a MigrationRecord.
+// Returns an error if the tuple does not match the schema or the version is zero.
+func (mt *MigrationsTable) Parse(t *tuple.Tuple) (*MigrationRecord, error) {
+	p := tuple.NewParser(t).ExpectFields(4)
+
+	version := p.ReadUint64()
+	name := p.ReadString()
+	checksum := p.ReadString()
+	appliedAt := p.ReadTimestamp()
+
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		return nil, fmt.Errorf("invalid migration version: must be positive")
+	}
+
+	return &MigrationRecord{
+		Version:   version,
+		Name:      name,
+		Checksum:  checksum,
+		AppliedAt: appliedAt,
+	}, nil
+}
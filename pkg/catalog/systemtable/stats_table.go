@@ -111,7 +111,7 @@ func (st *StatsTable) Parse(t *tuple.Tuple) (*TableStatistics, error) {
 
 // createStatisticsTuple creates a tuple for the statistics table
 func (st *StatsTable) CreateTuple(stats *TableStatistics) *tuple.Tuple {
-	return tuple.NewBuilder(st.Schema().TupleDesc).
+	return tuple.NewBuilder(tupleDesc(st)).
 		AddUint64(uint64(stats.TableID)).
 		AddUint64(stats.Cardinality).
 		AddUint64(uint64(stats.PageCount)).
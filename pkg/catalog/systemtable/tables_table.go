@@ -64,7 +64,7 @@ func (tt *TablesTable) GetNumFields() int {
 // CreateTuple constructs a catalog tuple for a given TableMetadata.
 // Fields are populated in schema order: table_id, table_name, file_path, primary_key.
 func (tt *TablesTable) CreateTuple(tm TableMetadata) *tuple.Tuple {
-	td := tt.Schema().TupleDesc
+	td := tupleDesc(tt)
 	return tuple.NewBuilder(td).
 		AddUint64(uint64(tm.TableID)).
 		AddString(tm.TableName).
@@ -0,0 +1,98 @@
+package systemtable
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// TriggerMetadata is the catalog record of a row-level trigger.
+type TriggerMetadata struct {
+	TriggerName  string            // Upper-case trigger name, unique across the database
+	TableID      primitives.FileID // Table whose rows fire the trigger
+	Timing       string            // BEFORE or AFTER
+	Event        string            // INSERT, UPDATE or DELETE
+	FunctionName string            // Name of the registered trigger function to run
+}
+
+// TriggersTable provides accessors and helpers for the CATALOG_TRIGGERS system
+// table. Each row defines one trigger.
+type TriggersTable struct {
+}
+
+// Schema returns the schema for the CATALOG_TRIGGERS system table.
+// Schema layout:
+//
+//	(trigger_name STRING PRIMARY KEY, table_id INT, timing STRING,
+//	 event STRING, function_name STRING)
+func (tt *TriggersTable) Schema() *schema.Schema {
+	sch, _ := schema.NewSchemaBuilder(InvalidTableID, tt.TableName()).
+		AddPrimaryKey("trigger_name", types.StringType).
+		AddColumn("table_id", types.Uint64Type).
+		AddColumn("timing", types.StringType).
+		AddColumn("event", types.StringType).
+		AddColumn("function_name", types.StringType).
+		Build()
+	return sch
+}
+
+// TableName returns the canonical name of the system table.
+func (tt *TriggersTable) TableName() string {
+	return "CATALOG_TRIGGERS"
+}
+
+// FileName returns the filename used to persist the CATALOG_TRIGGERS heap.
+func (tt *TriggersTable) FileName() string {
+	return "catalog_triggers.dat"
+}
+
+// PrimaryKey returns the primary key field name in the schema.
+func (tt *TriggersTable) PrimaryKey() string {
+	return "trigger_name"
+}
+
+// TableIDIndex returns the index of the table_id field.
+func (tt *TriggersTable) TableIDIndex() int {
+	return 1
+}
+
+// CreateTuple constructs a catalog tuple for a given TriggerMetadata.
+func (tt *TriggersTable) CreateTuple(m TriggerMetadata) *tuple.Tuple {
+	return tuple.NewBuilder(tupleDesc(tt)).
+		AddString(m.TriggerName).
+		AddUint64(uint64(m.TableID)).
+		AddString(m.Timing).
+		AddString(m.Event).
+		AddString(m.FunctionName).
+		MustBuild()
+}
+
+// Parse converts a catalog tuple into a TriggerMetadata.
+// Returns an error if the tuple does not match the schema or a required field is empty.
+func (tt *TriggersTable) Parse(t *tuple.Tuple) (*TriggerMetadata, error) {
+	p := tuple.NewParser(t).ExpectFields(5)
+
+	m := &TriggerMetadata{
+		TriggerName:  p.ReadString(),
+		TableID:      primitives.FileID(p.ReadUint64()),
+		Timing:       p.ReadString(),
+		Event:        p.ReadString(),
+		FunctionName: p.ReadString(),
+	}
+
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+
+	if m.TriggerName == "" || m.FunctionName == "" {
+		return nil, fmt.Errorf("invalid trigger record: trigger_name and function_name are required")
+	}
+
+	if m.TableID == InvalidTableID {
+		return nil, fmt.Errorf("invalid trigger record: table_id cannot be zero")
+	}
+
+	return m, nil
+}
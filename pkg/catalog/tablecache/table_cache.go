@@ -2,11 +2,12 @@ package tablecache
 
 import (
 	"container/list"
-	"fmt"
 	"maps"
 	"slices"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"strings"
@@ -81,6 +82,7 @@ type TableCache struct {
 	maxSize     int
 	ttl         cacheTTLConfig
 	metrics     cacheMetrics
+	logger      logging.ComponentLogger
 	mutex       sync.RWMutex
 }
 
@@ -94,23 +96,32 @@ func NewTableCache() *TableCache {
 		lruList:     list.New(),
 		maxSize:     0, // Unlimited by default
 		ttl:         DefaultCacheTTL(),
+		logger:      logging.ForComponent("catalog"),
 	}
 }
 
+// SetLogger replaces the logger used to report files that fail to close
+// when tables leave the cache.
+func (tc *TableCache) SetLogger(logger logging.ComponentLogger) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.logger = logger
+}
+
 // addTable adds a new table to the cache with the specified database file and schema.
 // If a table with the same name or ID already exists, it will be replaced.
 // Implements LRU eviction if maxSize is configured and cache is full.
 func (tc *TableCache) AddTable(f page.DbFile, schema *schema.Schema) error {
 	if f == nil {
-		return fmt.Errorf("file cannot be nil")
+		return catalogerror.NewInvalidDefinition("file cannot be nil")
 	}
 	if schema == nil {
-		return fmt.Errorf("schema cannot be nil")
+		return catalogerror.NewInvalidDefinition("schema cannot be nil")
 	}
 
 	name := schema.TableName
 	if name == "" {
-		return fmt.Errorf("table name cannot be empty")
+		return catalogerror.NewInvalidDefinition("table name cannot be empty")
 	}
 
 	tc.mutex.Lock()
@@ -140,7 +151,7 @@ func (tc *TableCache) GetTableID(tableName string) (primitives.FileID, error) {
 	info, exists := tc.nameToTable[tableName]
 	if !exists {
 		tc.metrics.misses.Add(1)
-		return 0, fmt.Errorf("table '%s' not found", tableName)
+		return 0, catalogerror.NewTableNotFound("table '%s' not found", tableName)
 	}
 
 	tc.metrics.hits.Add(1)
@@ -158,7 +169,7 @@ func (tc *TableCache) GetDbFile(tableId primitives.FileID) (page.DbFile, error)
 	info, exists := tc.idToTable[tableId]
 	if !exists {
 		tc.metrics.misses.Add(1)
-		return nil, fmt.Errorf("table with ID %d not found", tableId)
+		return nil, catalogerror.NewTableNotFound("table with ID %d not found", tableId)
 	}
 
 	tc.metrics.hits.Add(1)
@@ -175,7 +186,7 @@ func (tc *TableCache) RemoveTable(name string) error {
 
 	info, exists := tc.nameToTable[name]
 	if !exists {
-		return fmt.Errorf("table '%s' not found", name)
+		return catalogerror.NewTableNotFound("table '%s' not found", name)
 	}
 
 	if info.lruElement != nil {
@@ -184,7 +195,7 @@ func (tc *TableCache) RemoveTable(name string) error {
 
 	if info.File != nil {
 		if err := info.File.Close(); err != nil {
-			fmt.Printf("Warning: failed to close file for table '%s': %v\n", name, err)
+			tc.logger.Warn("failed to close table file", "table", name, "error", err)
 		}
 	}
 
@@ -205,7 +216,7 @@ func (tc *TableCache) Clear() {
 		}
 
 		if err := info.File.Close(); err != nil {
-			fmt.Printf("Warning: failed to close file for table '%s': %v\n", info.Schema.TableName, err)
+			tc.logger.Warn("failed to close table file", "table", info.Schema.TableName, "error", err)
 		}
 	}
 
@@ -223,22 +234,22 @@ func (tc *TableCache) ValidateIntegrity() error {
 	defer tc.mutex.RUnlock()
 
 	if len(tc.nameToTable) != len(tc.idToTable) {
-		return fmt.Errorf("cache integrity violation: map size mismatch")
+		return catalogerror.NewCorrupt("cache integrity violation: map size mismatch")
 	}
 
 	for name, table := range tc.nameToTable {
 		if t, exists := tc.idToTable[table.GetFileID()]; !exists {
-			return fmt.Errorf("cache integrity violation: table %s missing from ID map", name)
+			return catalogerror.NewCorrupt("cache integrity violation: table %s missing from ID map", name)
 		} else if t != table {
-			return fmt.Errorf("cache integrity violation: table %s reference mismatch", name)
+			return catalogerror.NewCorrupt("cache integrity violation: table %s reference mismatch", name)
 		}
 	}
 
 	for id, table := range tc.idToTable {
 		if otherTable, exists := tc.nameToTable[table.Schema.TableName]; !exists {
-			return fmt.Errorf("cache integrity violation: table ID %d missing from name map", id)
+			return catalogerror.NewCorrupt("cache integrity violation: table ID %d missing from name map", id)
 		} else if otherTable != table {
-			return fmt.Errorf("cache integrity violation: table ID %d reference mismatch", id)
+			return catalogerror.NewCorrupt("cache integrity violation: table ID %d reference mismatch", id)
 		}
 	}
 
@@ -292,7 +303,7 @@ func (tc *TableCache) GetTableInfo(tableID primitives.FileID) (*TableInfo, error
 	info, exists := tc.idToTable[tableID]
 	if !exists {
 		tc.metrics.misses.Add(1)
-		return nil, fmt.Errorf("table with ID %d not found", tableID)
+		return nil, catalogerror.NewTableNotFound("table with ID %d not found", tableID)
 	}
 
 	tc.metrics.hits.Add(1)
@@ -304,10 +315,10 @@ func (tc *TableCache) GetTableInfo(tableID primitives.FileID) (*TableInfo, error
 // The operation maintains all other table metadata and file associations.
 func (tc *TableCache) RenameTable(oldName, newName string) error {
 	if oldName == "" || newName == "" {
-		return fmt.Errorf("table names cannot be empty")
+		return catalogerror.NewInvalidDefinition("table names cannot be empty")
 	}
 	if strings.TrimSpace(newName) != newName {
-		return fmt.Errorf("new table name cannot have leading or trailing whitespace")
+		return catalogerror.NewInvalidDefinition("new table name cannot have leading or trailing whitespace")
 	}
 
 	tc.mutex.Lock()
@@ -315,11 +326,11 @@ func (tc *TableCache) RenameTable(oldName, newName string) error {
 
 	info, exists := tc.nameToTable[oldName]
 	if !exists {
-		return fmt.Errorf("table '%s' not found", oldName)
+		return catalogerror.NewTableNotFound("table '%s' not found", oldName)
 	}
 
 	if _, exists := tc.nameToTable[newName]; exists {
-		return fmt.Errorf("table '%s' already exists", newName)
+		return catalogerror.NewTableExists("table '%s' already exists", newName)
 	}
 
 	info.Schema.TableName = newName
@@ -350,7 +361,7 @@ func (tc *TableCache) evictLRU() {
 	// Close the file if it exists
 	if info.File != nil {
 		if err := info.File.Close(); err != nil {
-			fmt.Printf("Warning: failed to close file during eviction for table '%s': %v\n", info.Schema.TableName, err)
+			tc.logger.Warn("failed to close table file during eviction", "table", info.Schema.TableName, "error", err)
 		}
 	}
 
@@ -403,7 +414,7 @@ func (tc *TableCache) SetCachedStatistics(tableID primitives.FileID, stats *Tabl
 
 	info, exists := tc.idToTable[tableID]
 	if !exists {
-		return fmt.Errorf("table with ID %d not found", tableID)
+		return catalogerror.NewTableNotFound("table with ID %d not found", tableID)
 	}
 
 	info.Stats = stats
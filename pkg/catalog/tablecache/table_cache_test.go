@@ -1,13 +1,18 @@
 package tablecache
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/iterator"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -268,6 +273,36 @@ func TestRemoveTable_NotFound(t *testing.T) {
 	}
 }
 
+// closeFailingFile is a mockDbFile whose Close fails
+type closeFailingFile struct {
+	*mockDbFile
+}
+
+func (f closeFailingFile) Close() error {
+	return errors.New("disk unplugged")
+}
+
+// TestRemoveTable_LogsCloseFailure tests that a file failing to close is
+// reported on the injected logger
+func TestRemoveTable_LogsCloseFailure(t *testing.T) {
+	var buf bytes.Buffer
+	cache := NewTableCache()
+	cache.SetLogger(logging.NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	file := closeFailingFile{newMockDbFile(1, []types.Type{types.IntType}, []string{"id"})}
+	if err := cache.AddTable(file, createTestSchema("users", 1, []string{"id"})); err != nil {
+		t.Fatalf("AddTable failed: %v", err)
+	}
+	if err := cache.RemoveTable("users"); err != nil {
+		t.Fatalf("RemoveTable failed: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "failed to close table file") || !strings.Contains(out, "table=users") {
+		t.Errorf("expected the close failure on the injected logger, got:\n%s", out)
+	}
+}
+
 // TestClear tests clearing all tables
 func TestClear(t *testing.T) {
 	cache := NewTableCache()
@@ -0,0 +1,44 @@
+// Package changefeed delivers row change events to systems outside the
+// database through sinks: a FileSink appends them as JSON lines to a file
+// it rotates by size, and a WebhookSink POSTs them in batches to an HTTP
+// endpoint, retrying with backoff.
+//
+// Events carry the same information as the rows of an audit table (see
+// package audit): the changing transaction, the time, the operation and the
+// row before and after the change as JSON objects.
+package changefeed
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operations of an Event.
+const (
+	OpInsert = "INSERT"
+	OpUpdate = "UPDATE"
+	OpDelete = "DELETE"
+)
+
+// Event is one row change.
+type Event struct {
+	Table string    `json:"table"`
+	TxnID int64     `json:"txn_id"`
+	Time  time.Time `json:"time"`
+	Op    string    `json:"op"` // OpInsert, OpUpdate or OpDelete
+
+	// Old is the row before the change as a JSON object, nil for an insert.
+	Old json.RawMessage `json:"old,omitempty"`
+
+	// New is the row after the change as a JSON object, nil for a delete.
+	New json.RawMessage `json:"new,omitempty"`
+}
+
+// Sink receives the events of a changefeed. Emit delivers events in order
+// and returns only once they are delivered, so a caller that retries a
+// failed Emit delivers each event at least once. Sinks are not safe for
+// concurrent use.
+type Sink interface {
+	Emit(events []Event) error
+	Close() error
+}
@@ -0,0 +1,163 @@
+package changefeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"storemy/pkg/vfs"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testEvents(n int) []Event {
+	events := make([]Event, n)
+	for i := range events {
+		events[i] = Event{
+			Table: "USERS",
+			TxnID: int64(i + 1),
+			Time:  time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			Op:    OpInsert,
+			New:   json.RawMessage(`{"ID":1}`),
+		}
+	}
+	return events
+}
+
+func TestFileSink_AppendsJSONLinesAndRotates(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	sink, err := NewFileSink(fsys, FileConfig{Path: "/feed.json", MaxBytes: 200, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	for range 6 {
+		if err := sink.Emit(testEvents(1)); err != nil {
+			t.Fatalf("Emit failed: %v", err)
+		}
+	}
+
+	data, err := fsys.ReadFile("/feed.json")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var event Event
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	if err := json.Unmarshal(line, &event); err != nil || event.Table != "USERS" || event.Old != nil {
+		t.Errorf("unexpected event %+v (%v)", event, err)
+	}
+	if len(data) > 200 {
+		t.Errorf("file not rotated, %d bytes", len(data))
+	}
+	for _, name := range []string{"/feed.json.1", "/feed.json.2"} {
+		if _, err := fsys.Stat(name); err != nil {
+			t.Errorf("expected rotated file %s: %v", name, err)
+		}
+	}
+	if _, err := fsys.Stat("/feed.json.3"); err == nil {
+		t.Error("expected rotated files past MaxFiles to be removed")
+	}
+}
+
+func TestFileSink_ReopenAppends(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	for range 2 {
+		sink, err := NewFileSink(fsys, FileConfig{Path: "/feed.json"})
+		if err != nil {
+			t.Fatalf("NewFileSink failed: %v", err)
+		}
+		if err := sink.Emit(testEvents(1)); err != nil {
+			t.Fatalf("Emit failed: %v", err)
+		}
+		sink.Close()
+	}
+	data, _ := fsys.ReadFile("/feed.json")
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("expected 2 lines, got %d", lines)
+	}
+}
+
+func TestWebhookSink_BatchesAndRetries(t *testing.T) {
+	var requests, received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("missing configured header")
+		}
+		// Every other request fails, so each batch is retried once
+		if requests.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+		if len(payload.Events) > 2 {
+			t.Errorf("batch of %d events, expected at most 2", len(payload.Events))
+		}
+		received.Add(int32(len(payload.Events)))
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{
+		URL:       server.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+		BatchSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewWebhookSink failed: %v", err)
+	}
+	defer sink.Close()
+	var slept []time.Duration
+	sink.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	if err := sink.Emit(testEvents(5)); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+	if received.Load() != 5 {
+		t.Errorf("received %d events, expected 5", received.Load())
+	}
+	if requests.Load() != 6 || len(slept) != 3 {
+		t.Errorf("got %d requests and %d retries, expected 6 and 3", requests.Load(), len(slept))
+	}
+}
+
+func TestWebhookSink_GivesUp(t *testing.T) {
+	var requests atomic.Int32
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: server.URL, MaxRetries: 3, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second})
+	if err != nil {
+		t.Fatalf("NewWebhookSink failed: %v", err)
+	}
+	var slept []time.Duration
+	sink.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	if err := sink.Emit(testEvents(1)); err == nil {
+		t.Fatal("expected Emit to fail")
+	}
+	if requests.Load() != 4 {
+		t.Errorf("got %d requests, expected 4", requests.Load())
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}; len(slept) != 3 || slept[0] != want[0] || slept[1] != want[1] || slept[2] != want[2] {
+		t.Errorf("backoff %v, expected %v", slept, want)
+	}
+
+	// Client errors are not retried
+	status = http.StatusBadRequest
+	requests.Store(0)
+	if err := sink.Emit(testEvents(1)); err == nil {
+		t.Fatal("expected Emit to fail")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("got %d requests, expected 1", requests.Load())
+	}
+}
@@ -0,0 +1,136 @@
+package changefeed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"storemy/pkg/vfs"
+)
+
+const (
+	// DefaultMaxFileBytes is the size a FileSink rotates its file at when
+	// FileConfig.MaxBytes is zero.
+	DefaultMaxFileBytes = 64 << 20
+
+	// DefaultMaxFiles is how many rotated files a FileSink keeps when
+	// FileConfig.MaxFiles is zero.
+	DefaultMaxFiles = 5
+)
+
+// FileConfig configures a FileSink.
+type FileConfig struct {
+	// Path is the file events are appended to. Rotated files are named
+	// Path.1 (the newest) to Path.MaxFiles (the oldest).
+	Path string
+
+	// MaxBytes is the size past which the file is rotated before the next
+	// write. Zero uses DefaultMaxFileBytes.
+	MaxBytes int64
+
+	// MaxFiles is how many rotated files are kept; older ones are removed.
+	// Zero uses DefaultMaxFiles.
+	MaxFiles int
+}
+
+// FileSink appends events to a file as JSON lines, one event per line.
+type FileSink struct {
+	fs     vfs.FS
+	config FileConfig
+	file   vfs.File
+	size   int64
+}
+
+// NewFileSink opens the file of config for appending, creating it if needed.
+func NewFileSink(fsys vfs.FS, config FileConfig) (*FileSink, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("changefeed file sink needs a path")
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultMaxFileBytes
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = DefaultMaxFiles
+	}
+
+	s := &FileSink{fs: fsys, config: config}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Emit appends events to the file and syncs it, rotating the file first if
+// it is past its maximum size. The events of one call are written to the
+// same file.
+func (s *FileSink) Emit(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for i := range events {
+		line, err := json.Marshal(&events[i])
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	if s.size > 0 && s.size+int64(len(data)) > s.config.MaxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.file.WriteAt(data, s.size); err != nil {
+		return fmt.Errorf("failed to write events to %s: %w", s.config.Path, err)
+	}
+	s.size += int64(len(data))
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	file, err := s.fs.OpenFile(s.config.Path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.config.Path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat %s: %w", s.config.Path, err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate renames the file to Path.1, shifting older rotated files up one
+// number and removing the one past MaxFiles, and starts a new file.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", s.config.Path, err)
+	}
+
+	oldest := s.rotatedName(s.config.MaxFiles)
+	if err := s.fs.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", oldest, err)
+	}
+	for n := s.config.MaxFiles - 1; n >= 1; n-- {
+		if err := s.fs.Rename(s.rotatedName(n), s.rotatedName(n+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate %s: %w", s.rotatedName(n), err)
+		}
+	}
+	if err := s.fs.Rename(s.config.Path, s.rotatedName(1)); err != nil {
+		return fmt.Errorf("failed to rotate %s: %w", s.config.Path, err)
+	}
+	return s.open()
+}
+
+func (s *FileSink) rotatedName(n int) string {
+	return fmt.Sprintf("%s.%d", s.config.Path, n)
+}
@@ -0,0 +1,153 @@
+package changefeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultWebhookBatchSize      = 100
+	DefaultWebhookMaxRetries     = 5
+	DefaultWebhookInitialBackoff = 100 * time.Millisecond
+	DefaultWebhookMaxBackoff     = 10 * time.Second
+	DefaultWebhookTimeout        = 30 * time.Second
+)
+
+// WebhookConfig configures a WebhookSink. Zero fields use the defaults above.
+type WebhookConfig struct {
+	// URL receives a POST per batch with the JSON body {"events": [...]}.
+	URL string
+
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string
+
+	// BatchSize is the most events sent in one request.
+	BatchSize int
+
+	// MaxRetries is how many times a failed request is retried. Network
+	// errors, 429 and 5xx responses are retried; other responses fail the
+	// Emit at once.
+	MaxRetries int
+
+	// InitialBackoff is the wait before the first retry; each retry waits
+	// twice as long as the one before, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Timeout bounds each request.
+	Timeout time.Duration
+}
+
+// WebhookSink POSTs events in batches to an HTTP endpoint. The endpoint
+// acknowledges a batch with a 2xx response; a batch retried after a timeout
+// may be received twice.
+type WebhookSink struct {
+	config WebhookConfig
+	client *http.Client
+	sleep  func(time.Duration)
+}
+
+// webhookPayload is the request body of a batch.
+type webhookPayload struct {
+	Events []Event `json:"events"`
+}
+
+// NewWebhookSink creates a sink posting to config.URL.
+func NewWebhookSink(config WebhookConfig) (*WebhookSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("changefeed webhook sink needs a URL")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultWebhookBatchSize
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = DefaultWebhookMaxRetries
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultWebhookInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultWebhookMaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+
+	return &WebhookSink{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		sleep:  time.Sleep,
+	}, nil
+}
+
+// Emit posts events in batches of at most BatchSize, in order. It stops at
+// the first batch that fails after its retries; the batches before it were
+// delivered.
+func (s *WebhookSink) Emit(events []Event) error {
+	for start := 0; start < len(events); start += s.config.BatchSize {
+		end := min(start+s.config.BatchSize, len(events))
+		if err := s.post(events[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections.
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// post sends one batch, retrying with exponential backoff.
+func (s *WebhookSink) post(batch []Event) error {
+	body, err := json.Marshal(webhookPayload{Events: batch})
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	backoff := s.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.send(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == s.config.MaxRetries {
+			return fmt.Errorf("failed to post %d events to %s after %d attempts: %w", len(batch), s.config.URL, attempt+1, err)
+		}
+		s.sleep(backoff)
+		backoff = min(2*backoff, s.config.MaxBackoff)
+	}
+}
+
+// send makes one request, reporting whether a failure is worth retrying.
+func (s *WebhookSink) send(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range s.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+}
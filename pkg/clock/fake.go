@@ -0,0 +1,130 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a virtual clock whose time only moves when Advance or Set is
+// called. Tickers created from it fire synchronously during Advance, so a
+// test controls exactly when periodic work becomes due.
+type Fake struct {
+	now     time.Time
+	tickers []*fakeTicker
+	mutex   sync.Mutex
+	cond    *sync.Cond
+}
+
+// NewFake creates a fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.cond = sync.NewCond(&f.mutex)
+	return f
+}
+
+// Now returns the clock's current time.
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+// Since returns the virtual time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTicker creates a ticker that fires every d of virtual time.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	t := &fakeTicker{
+		clock:  f,
+		period: d,
+		next:   f.now.Add(d),
+		c:      make(chan time.Time, 1),
+	}
+	f.tickers = append(f.tickers, t)
+	f.cond.Broadcast()
+	return t
+}
+
+// Advance moves the clock forward by d, firing every ticker that becomes due.
+// Like time.Ticker, a ticker whose previous tick has not been received drops
+// the new tick instead of blocking.
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.advanceTo(f.now.Add(d))
+}
+
+// Set moves the clock to t. Moving backwards does not fire tickers.
+func (f *Fake) Set(t time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if t.Before(f.now) {
+		f.now = t
+		return
+	}
+	f.advanceTo(t)
+}
+
+// BlockUntilTickers waits until at least n tickers are active. Tests use it
+// to make sure a background goroutine has created its tickers before
+// advancing the clock.
+func (f *Fake) BlockUntilTickers(n int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for len(f.tickers) < n {
+		f.cond.Wait()
+	}
+}
+
+// advanceTo fires due tickers in time order. The caller must hold f.mutex.
+func (f *Fake) advanceTo(end time.Time) {
+	for {
+		var due *fakeTicker
+		for _, t := range f.tickers {
+			if !t.next.After(end) && (due == nil || t.next.Before(due.next)) {
+				due = t
+			}
+		}
+		if due == nil {
+			break
+		}
+
+		f.now = due.next
+		due.next = due.next.Add(due.period)
+		select {
+		case due.c <- f.now:
+		default:
+		}
+	}
+	f.now = end
+}
+
+func (f *Fake) removeTicker(t *fakeTicker) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i, other := range f.tickers {
+		if other == t {
+			f.tickers = append(f.tickers[:i], f.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock  *Fake
+	period time.Duration
+	next   time.Time
+	c      chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+func (t *fakeTicker) Stop()               { t.clock.removeTicker(t) }
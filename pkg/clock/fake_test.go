@@ -0,0 +1,81 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake_AdvanceMovesTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	f.Advance(90 * time.Second)
+	if got := f.Since(start); got != 90*time.Second {
+		t.Errorf("expected 90s elapsed, got %v", got)
+	}
+
+	f.Set(start)
+	if !f.Now().Equal(start) {
+		t.Errorf("expected Set to move the clock back to %v, got %v", start, f.Now())
+	}
+}
+
+func TestFake_TickerFiresOnlyWhenDue(t *testing.T) {
+	f := NewFake(time.Unix(0, 0))
+	ticker := f.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	f.Advance(59 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker fired before its interval elapsed")
+	default:
+	}
+
+	f.Advance(time.Second)
+	select {
+	case tick := <-ticker.C():
+		if !tick.Equal(time.Unix(60, 0)) {
+			t.Errorf("expected tick at 60s, got %v", tick)
+		}
+	default:
+		t.Fatal("expected ticker to fire after one interval")
+	}
+}
+
+func TestFake_TickerDropsMissedTicks(t *testing.T) {
+	f := NewFake(time.Unix(0, 0))
+	ticker := f.NewTicker(time.Second)
+
+	f.Advance(10 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatal("expected missed ticks to be dropped")
+	default:
+	}
+
+	ticker.Stop()
+	f.Advance(10 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker must not fire")
+	default:
+	}
+}
+
+func TestFake_BlockUntilTickers(t *testing.T) {
+	f := NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		f.BlockUntilTickers(1)
+		close(done)
+	}()
+
+	f.NewTicker(time.Second)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("BlockUntilTickers did not return after a ticker was created")
+	}
+}
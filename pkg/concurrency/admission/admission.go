@@ -0,0 +1,158 @@
+// Package admission limits how many queries execute at once. Queries beyond
+// the limit wait in a queue for a free slot, so a burst of clients degrades
+// into queueing instead of thrashing the buffer pool and piling up lock
+// waits.
+package admission
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned when a query arrives while MaxQueued queries are already waiting.
+	ErrQueueFull = errors.New("admission queue is full")
+
+	// ErrQueueTimeout is returned when a query waited QueueTimeout without getting a slot.
+	ErrQueueTimeout = errors.New("timed out waiting for an execution slot")
+)
+
+// Config controls admission. A zero MaxConcurrent admits every query
+// immediately.
+type Config struct {
+	// MaxConcurrent is the number of queries allowed to execute at once.
+	MaxConcurrent int
+
+	// MaxQueued is the number of queries allowed to wait for a slot; further
+	// queries fail with ErrQueueFull. Zero places no limit on the queue.
+	MaxQueued int
+
+	// QueueTimeout bounds how long a query waits for a slot before failing
+	// with ErrQueueTimeout. Zero waits until a slot frees up.
+	QueueTimeout time.Duration
+}
+
+// Enabled reports whether the configuration limits concurrency.
+func (c Config) Enabled() bool {
+	return c.MaxConcurrent > 0
+}
+
+// Stats reports the state of a controller.
+type Stats struct {
+	Running  int   // Queries holding a slot
+	Queued   int   // Queries waiting for a slot
+	Admitted int64 // Queries admitted, immediately or after waiting
+	Rejected int64 // Queries rejected with ErrQueueFull
+	TimedOut int64 // Queries that failed with ErrQueueTimeout
+}
+
+// Controller hands out execution slots. It is safe for concurrent use.
+type Controller struct {
+	config Config
+	slots  chan struct{} // Buffered to MaxConcurrent; a send takes a slot
+
+	mutex sync.Mutex
+	stats Stats
+}
+
+// NewController creates a controller enforcing config.
+func NewController(config Config) *Controller {
+	c := &Controller{config: config}
+	if config.Enabled() {
+		c.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	return c
+}
+
+// Config returns the limits the controller enforces.
+func (c *Controller) Config() Config {
+	return c.config
+}
+
+// Acquire takes an execution slot, waiting in the queue if all slots are
+// taken. On success the caller must call the returned release function once
+// the query is done.
+func (c *Controller) Acquire() (release func(), err error) {
+	if !c.config.Enabled() {
+		return func() {}, nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		c.admit()
+		return c.release, nil
+	default:
+	}
+
+	if !c.enqueue() {
+		admissionRejected.Inc()
+		return nil, ErrQueueFull
+	}
+	defer c.dequeue()
+
+	var timeout <-chan time.Time
+	if c.config.QueueTimeout > 0 {
+		timer := time.NewTimer(c.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	start := time.Now()
+	select {
+	case c.slots <- struct{}{}:
+		admissionWaitSeconds.Observe(time.Since(start).Seconds())
+		c.admit()
+		return c.release, nil
+	case <-timeout:
+		c.mutex.Lock()
+		c.stats.TimedOut++
+		c.mutex.Unlock()
+		admissionTimeouts.Inc()
+		return nil, ErrQueueTimeout
+	}
+}
+
+// Stats returns a snapshot of the controller's state.
+func (c *Controller) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.stats
+}
+
+// enqueue adds a waiting query, or reports false if the queue is full.
+func (c *Controller) enqueue() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.config.MaxQueued > 0 && c.stats.Queued >= c.config.MaxQueued {
+		c.stats.Rejected++
+		return false
+	}
+	c.stats.Queued++
+	admissionQueueDepth.Inc()
+	return true
+}
+
+func (c *Controller) dequeue() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stats.Queued--
+	admissionQueueDepth.Dec()
+}
+
+func (c *Controller) admit() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stats.Running++
+	c.stats.Admitted++
+	admissionRunning.Inc()
+}
+
+func (c *Controller) release() {
+	c.mutex.Lock()
+	c.stats.Running--
+	c.mutex.Unlock()
+	admissionRunning.Dec()
+	<-c.slots
+}
@@ -0,0 +1,108 @@
+package admission
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestController_Disabled(t *testing.T) {
+	c := NewController(Config{})
+	for range 100 {
+		if _, err := c.Acquire(); err != nil {
+			t.Fatalf("expected a disabled controller to admit every query, got %v", err)
+		}
+	}
+}
+
+func TestController_LimitsConcurrency(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 2})
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := c.Acquire()
+			if err != nil {
+				t.Errorf("Acquire failed: %v", err)
+				return
+			}
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent queries, saw %d", peak)
+	}
+	stats := c.Stats()
+	if stats.Admitted != 10 || stats.Running != 0 || stats.Queued != 0 {
+		t.Errorf("unexpected stats after all queries finished: %+v", stats)
+	}
+}
+
+func TestController_QueueFull(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 1, MaxQueued: 1})
+
+	release, err := c.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	admitted := make(chan struct{})
+	go func() {
+		r, err := c.Acquire() // Waits in the only queue place
+		if err == nil {
+			r()
+		}
+		close(admitted)
+	}()
+	for c.Stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := c.Acquire(); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+
+	release()
+	<-admitted
+	if stats := c.Stats(); stats.Rejected != 1 || stats.Admitted != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestController_QueueTimeout(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 1, QueueTimeout: 10 * time.Millisecond})
+
+	release, err := c.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer release()
+
+	start := time.Now()
+	if _, err := c.Acquire(); !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("expected ErrQueueTimeout, got %v", err)
+	}
+	if waited := time.Since(start); waited < 10*time.Millisecond {
+		t.Errorf("expected to wait for the timeout, waited %s", waited)
+	}
+	if stats := c.Stats(); stats.TimedOut != 1 || stats.Queued != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,27 @@
+package admission
+
+import "storemy/pkg/metrics"
+
+var (
+	admissionRunning = metrics.NewGauge(
+		"storemy_admission_running_queries",
+		"Queries currently holding an execution slot",
+	)
+	admissionQueueDepth = metrics.NewGauge(
+		"storemy_admission_queue_depth",
+		"Queries waiting for an execution slot",
+	)
+	admissionRejected = metrics.NewCounter(
+		"storemy_admission_rejected_total",
+		"Queries rejected because the admission queue was full",
+	)
+	admissionTimeouts = metrics.NewCounter(
+		"storemy_admission_timeouts_total",
+		"Queries that gave up after waiting QueueTimeout for an execution slot",
+	)
+	admissionWaitSeconds = metrics.NewHistogram(
+		"storemy_admission_wait_seconds",
+		"Time queued queries waited for an execution slot before being admitted",
+		metrics.DefaultLatencyBuckets,
+	)
+)
@@ -0,0 +1,28 @@
+package lock
+
+import (
+	"fmt"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/primitives"
+)
+
+// newDeadlockError creates the DBError returned to tid when waiting would
+// close a cycle in the dependency graph. It is retryable: the other
+// transactions of the cycle go on once tid aborts.
+func newDeadlockError(tid *primitives.TransactionID) *dberror.DBError {
+	err := dberror.New(dberror.ErrCategoryConcurrency, dberror.ErrCodeDeadlock,
+		fmt.Sprintf("deadlock detected for transaction %d", tid.ID()))
+	err.Component = "LockManager"
+	err.Hint = "Abort the transaction and run it again"
+	return err
+}
+
+// newLockTimeoutError creates the DBError returned when a lock is not
+// granted in time, caused by cause if it is not nil. It is retryable.
+func newLockTimeoutError(cause error, format string, args ...any) *dberror.DBError {
+	err := dberror.New(dberror.ErrCategoryTransient, dberror.ErrCodeLockTimeout, fmt.Sprintf(format, args...))
+	err.Cause = cause
+	err.Component = "LockManager"
+	err.Hint = "Abort the transaction and run it again once the transactions holding the lock finish"
+	return err
+}
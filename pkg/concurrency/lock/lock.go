@@ -12,6 +12,13 @@ const (
 	ExclusiveLock
 )
 
+func (lt LockType) String() string {
+	if lt == ExclusiveLock {
+		return "EXCLUSIVE"
+	}
+	return "SHARED"
+}
+
 type Lock struct {
 	TID       *primitives.TransactionID
 	LockType  LockType
@@ -19,9 +26,20 @@ type Lock struct {
 }
 
 type LockRequest struct {
+	TID         *primitives.TransactionID
+	LockType    LockType
+	Chan        chan bool // Channel to signal when lock is granted
+	RequestTime time.Time // When the transaction started waiting
+}
+
+// LockInfo describes a single held or requested lock, as reported by
+// LockManager.Snapshot.
+type LockInfo struct {
 	TID      *primitives.TransactionID
+	PageID   primitives.PageID
 	LockType LockType
-	Chan     chan bool // Channel to signal when lock is granted
+	Granted  bool      // False if the transaction is still waiting for the lock
+	Since    time.Time // Grant time for held locks, request time for waiting ones
 }
 
 func NewLock(tid *primitives.TransactionID, lockType LockType) *Lock {
@@ -34,8 +52,9 @@ func NewLock(tid *primitives.TransactionID, lockType LockType) *Lock {
 
 func NewLockRequest(tid *primitives.TransactionID, lockType LockType) *LockRequest {
 	return &LockRequest{
-		TID:      tid,
-		LockType: lockType,
-		Chan:     make(chan bool, 1),
+		TID:         tid,
+		LockType:    lockType,
+		Chan:        make(chan bool, 1),
+		RequestTime: time.Now(),
 	}
 }
@@ -1,14 +1,60 @@
 package lock
 
 import (
+	"fmt"
 	"slices"
 	"storemy/pkg/primitives"
+	"strings"
 )
 
+// GrantPolicy decides whether a lock request compatible with the locks held
+// on a page may still have to wait for requests queued on it.
+type GrantPolicy int
+
+const (
+	// GrantReaderPreference grants every request compatible with the held
+	// locks, so shared locks are granted while a writer waits. It gives the
+	// most concurrency, but a steady stream of readers can starve writers.
+	GrantReaderPreference GrantPolicy = iota
+
+	// GrantFIFO grants requests in arrival order: a request waits while
+	// another transaction queued before it asked for a conflicting lock.
+	// Readers queued together are still granted together.
+	GrantFIFO
+
+	// GrantWriterPreference makes shared requests wait while any other
+	// transaction waits for an exclusive lock on the page, so writers are
+	// granted as soon as the current readers finish.
+	GrantWriterPreference
+)
+
+func (p GrantPolicy) String() string {
+	switch p {
+	case GrantFIFO:
+		return "FIFO"
+	case GrantWriterPreference:
+		return "WRITER_PREFERENCE"
+	default:
+		return "READER_PREFERENCE"
+	}
+}
+
+// ParseGrantPolicy parses the name of a grant policy as returned by
+// GrantPolicy.String, ignoring case.
+func ParseGrantPolicy(name string) (GrantPolicy, error) {
+	for _, p := range []GrantPolicy{GrantReaderPreference, GrantFIFO, GrantWriterPreference} {
+		if strings.EqualFold(name, p.String()) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown lock grant policy %q (expected READER_PREFERENCE, FIFO or WRITER_PREFERENCE)", name)
+}
+
 type LockGrantor struct {
 	lockTable *LockTable
 	waitQueue *WaitQueue
 	depGraph  *DependencyGraph
+	policy    GrantPolicy
 }
 
 // NewLockGrantor creates a new lock grantor.
@@ -23,7 +69,44 @@ func NewLockGrantor(l *LockTable, w *WaitQueue, d *DependencyGraph) *LockGrantor
 // CanGrantImmediately determines if a lock can be granted without waiting.
 // For exclusive locks, no other transaction can hold any lock on the page.
 // For shared locks, no other transaction can hold an exclusive lock on the page.
+// Under GrantFIFO and GrantWriterPreference the request must also not be
+// queued behind another one; see QueuedAhead.
 func (lg *LockGrantor) CanGrantImmediately(tid *primitives.TransactionID, pid primitives.PageID, lockType LockType) bool {
+	return lg.compatibleWithHolders(tid, pid, lockType) && len(lg.QueuedAhead(tid, pid, lockType)) == 0
+}
+
+// QueuedAhead returns the transactions whose queued requests on pid a
+// lockType request by tid has to wait for under the grant policy. A request
+// not in the queue yet counts as the last one.
+func (lg *LockGrantor) QueuedAhead(tid *primitives.TransactionID, pid primitives.PageID, lockType LockType) []*primitives.TransactionID {
+	var ahead []*primitives.TransactionID
+	switch lg.policy {
+	case GrantFIFO:
+		for _, req := range lg.waitQueue.GetRequests(pid) {
+			if req.TID == tid {
+				break
+			}
+			if lockType == ExclusiveLock || req.LockType == ExclusiveLock {
+				ahead = append(ahead, req.TID)
+			}
+		}
+
+	case GrantWriterPreference:
+		if lockType == ExclusiveLock {
+			return nil
+		}
+		for _, req := range lg.waitQueue.GetRequests(pid) {
+			if req.TID != tid && req.LockType == ExclusiveLock {
+				ahead = append(ahead, req.TID)
+			}
+		}
+	}
+	return ahead
+}
+
+// compatibleWithHolders reports whether a lockType request by tid is
+// compatible with the locks other transactions hold on pid.
+func (lg *LockGrantor) compatibleWithHolders(tid *primitives.TransactionID, pid primitives.PageID, lockType LockType) bool {
 	locks := lg.lockTable.GetPageLocks(pid)
 	if len(locks) == 0 {
 		return true
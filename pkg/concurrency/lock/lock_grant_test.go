@@ -522,6 +522,82 @@ func TestLockGrantorEdgeCases(t *testing.T) {
 	})
 }
 
+func TestCanGrantImmediately_GrantPolicies(t *testing.T) {
+	reader := primitives.NewTransactionID()
+	writer := primitives.NewTransactionID()
+	newReader := primitives.NewTransactionID()
+	pid := page.NewPageDescriptor(1, 1)
+
+	tests := []struct {
+		policy      GrantPolicy
+		grantShared bool
+	}{
+		{GrantReaderPreference, true},
+		{GrantFIFO, false},
+		{GrantWriterPreference, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			lg := setupLockGrantor()
+			lg.policy = tt.policy
+			lg.lockTable.AddLock(reader, pid, SharedLock)
+			lg.waitQueue.Add(writer, pid, ExclusiveLock)
+
+			if got := lg.CanGrantImmediately(newReader, pid, SharedLock); got != tt.grantShared {
+				t.Errorf("shared lock behind a waiting writer granted = %v, expected %v", got, tt.grantShared)
+			}
+			if got := len(lg.QueuedAhead(newReader, pid, SharedLock)) > 0; got == tt.grantShared {
+				t.Errorf("QueuedAhead reported a blocking writer = %v", got)
+			}
+		})
+	}
+}
+
+func TestQueuedAhead_FIFOOrder(t *testing.T) {
+	lg := setupLockGrantor()
+	lg.policy = GrantFIFO
+	r1 := primitives.NewTransactionID()
+	r2 := primitives.NewTransactionID()
+	w := primitives.NewTransactionID()
+	pid := page.NewPageDescriptor(1, 1)
+
+	lg.waitQueue.Add(r1, pid, SharedLock)
+	lg.waitQueue.Add(r2, pid, SharedLock)
+	lg.waitQueue.Add(w, pid, ExclusiveLock)
+
+	if ahead := lg.QueuedAhead(r2, pid, SharedLock); len(ahead) != 0 {
+		t.Errorf("readers queued together should not wait for each other, got %v", ahead)
+	}
+	if ahead := lg.QueuedAhead(w, pid, ExclusiveLock); len(ahead) != 2 {
+		t.Errorf("writer should wait for both readers queued before it, got %d", len(ahead))
+	}
+
+	// Under writer preference the writer goes first
+	lg.policy = GrantWriterPreference
+	if ahead := lg.QueuedAhead(w, pid, ExclusiveLock); len(ahead) != 0 {
+		t.Errorf("writer should not wait for queued readers, got %d", len(ahead))
+	}
+	if ahead := lg.QueuedAhead(r1, pid, SharedLock); len(ahead) != 1 || ahead[0] != w {
+		t.Errorf("reader should wait for the queued writer, got %v", ahead)
+	}
+}
+
+func TestParseGrantPolicy(t *testing.T) {
+	for _, p := range []GrantPolicy{GrantReaderPreference, GrantFIFO, GrantWriterPreference} {
+		parsed, err := ParseGrantPolicy(p.String())
+		if err != nil || parsed != p {
+			t.Errorf("ParseGrantPolicy(%q) = %v, %v", p.String(), parsed, err)
+		}
+	}
+	if p, err := ParseGrantPolicy("fifo"); err != nil || p != GrantFIFO {
+		t.Errorf("expected case-insensitive parsing, got %v, %v", p, err)
+	}
+	if _, err := ParseGrantPolicy("RANDOM"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
 // Helper function to create a LockGrantor with initialized components
 func setupLockGrantor() *LockGrantor {
 	lockTable := NewLockTable()
@@ -1,9 +1,12 @@
 package lock
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"storemy/pkg/primitives"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +19,12 @@ type LockManager struct {
 	waitQueue   *WaitQueue
 	lockTable   *LockTable
 	lockGrantor *LockGrantor
+	rangeLocks  map[primitives.FileID][]*RangeLock // Key ranges read by serializable transactions
+	tableLocks  map[primitives.FileID]*tableLock   // Table-level locks taken by DML and DDL
+	snapshots   snapshotGate                       // Writers and exported snapshots
+	trace       atomic.Pointer[LockTrace]
+
+	tableLockTimeout atomic.Int64 // Nanoseconds a table lock request may wait
 }
 
 // NewLockManager creates and initializes a new LockManager instance.
@@ -25,12 +34,17 @@ func NewLockManager() *LockManager {
 	waitQueue := NewWaitQueue()
 	depGraph := NewDependencyGraph()
 
-	return &LockManager{
+	lm := &LockManager{
 		depGraph:    depGraph,
 		waitQueue:   waitQueue,
 		lockTable:   lockTable,
 		lockGrantor: NewLockGrantor(lockTable, waitQueue, depGraph),
+		rangeLocks:  make(map[primitives.FileID][]*RangeLock),
+		tableLocks:  make(map[primitives.FileID]*tableLock),
+		snapshots:   newSnapshotGate(),
 	}
+	lm.tableLockTimeout.Store(int64(DefaultTableLockTimeout))
+	return lm
 }
 
 // LockPage attempts to acquire a lock on the specified page for the given primitives.
@@ -49,8 +63,17 @@ func NewLockManager() *LockManager {
 //   - Deadlock detected
 //   - Timeout waiting for lock (after 1000 attempts)
 func (lm *LockManager) LockPage(tid *primitives.TransactionID, pid primitives.PageID, exclusive bool) error {
+	_, err := lm.LockPageWait(tid, pid, exclusive)
+	return err
+}
+
+// LockPageWait is like LockPage but also reports how long the transaction had
+// to wait for a conflicting lock to be released. The duration is zero when the
+// lock was granted immediately. A transaction's first exclusive lock also
+// waits for exported snapshots to be released; see ExportSnapshot.
+func (lm *LockManager) LockPageWait(tid *primitives.TransactionID, pid primitives.PageID, exclusive bool) (time.Duration, error) {
 	if tid == nil {
-		return fmt.Errorf("transaction ID cannot be nil")
+		return 0, fmt.Errorf("transaction ID cannot be nil")
 	}
 
 	lockType := SharedLock
@@ -61,11 +84,19 @@ func (lm *LockManager) LockPage(tid *primitives.TransactionID, pid primitives.Pa
 	lm.mutex.RLock()
 	if lm.lockTable.HasSufficientLock(tid, pid, lockType) {
 		lm.mutex.RUnlock()
-		return nil
+		return 0, nil
 	}
 	lm.mutex.RUnlock()
 
-	return lm.attemptToAcquireLock(tid, pid, lockType)
+	if !exclusive {
+		return lm.attemptToAcquireLock(tid, pid, lockType)
+	}
+	gateWait, err := lm.enterWriteGate(tid)
+	if err != nil {
+		return 0, err
+	}
+	waited, err := lm.attemptToAcquireLock(tid, pid, lockType)
+	return gateWait + waited, err
 }
 
 // attemptToAcquireLock implements the main lock acquisition logic with retry and deadlock detection.
@@ -84,25 +115,46 @@ func (lm *LockManager) LockPage(tid *primitives.TransactionID, pid primitives.Pa
 //   - lockType: The type of lock requested
 //
 // Returns:
+//   - time.Duration: how long the transaction waited before the lock was granted
 //   - error: nil on success, error on deadlock or timeout
-func (lm *LockManager) attemptToAcquireLock(tid *primitives.TransactionID, pid primitives.PageID, lockType LockType) error {
+func (lm *LockManager) attemptToAcquireLock(tid *primitives.TransactionID, pid primitives.PageID, lockType LockType) (time.Duration, error) {
 	const maxRetryDelay = 50 * time.Millisecond
 	maxRetries := 100
 	retryDelay := time.Millisecond
 
+	trace := lm.trace.Load()
+	event := func(kind EventKind) LockEvent {
+		return LockEvent{TxID: tid.ID(), Kind: kind, PageID: pid, LockType: lockType}
+	}
+
+	var waitStart time.Time
+	granted := func(kind EventKind) (time.Duration, error) {
+		lockAcquisitions.Inc()
+		if waitStart.IsZero() {
+			trace.record(event(kind))
+			return 0, nil
+		}
+		waited := time.Since(waitStart)
+		lockWaitSeconds.Observe(waited.Seconds())
+		e := event(EventGranted)
+		e.Waited = waited
+		trace.record(e)
+		return waited, nil
+	}
+
 	for attempt := range maxRetries {
 		lm.mutex.Lock()
 
 		if lm.lockTable.HasSufficientLock(tid, pid, lockType) {
 			lm.mutex.Unlock()
-			return nil
+			return granted(EventAcquired)
 		}
 
 		if lockType == ExclusiveLock && lm.lockTable.HasLockType(tid, pid, SharedLock) {
 			if lm.lockGrantor.CanUpgradeLock(tid, pid) {
 				lm.lockTable.UpgradeLock(tid, pid)
 				lm.mutex.Unlock()
-				return nil
+				return granted(EventUpgraded)
 			}
 		}
 
@@ -110,25 +162,49 @@ func (lm *LockManager) attemptToAcquireLock(tid *primitives.TransactionID, pid p
 			lm.lockGrantor.GrantLock(tid, pid, lockType)
 			lm.depGraph.RemoveTransaction(tid)
 			lm.mutex.Unlock()
-			return nil
+			return granted(EventAcquired)
 		}
 
 		lm.waitQueue.Add(tid, pid, lockType)
 		lm.updateDependencies(tid, pid, lockType)
 
+		var holders []int64
+		if trace != nil {
+			holders = lm.conflictingHolders(tid, pid, lockType)
+		}
+
 		if lm.depGraph.HasCycle() {
 			lm.waitQueue.RemoveRequest(tid, pid)
 			lm.depGraph.RemoveTransaction(tid)
 			lm.mutex.Unlock()
-			return fmt.Errorf("deadlock detected for transaction %d", tid.ID())
+			lockDeadlocks.Inc()
+			e := event(EventDeadlock)
+			e.Holders = holders
+			trace.record(e)
+			return 0, newDeadlockError(tid)
 		}
 
 		lm.mutex.Unlock()
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+			lockWaits.Inc()
+			e := event(EventWaiting)
+			e.Holders = holders
+			trace.record(e)
+		}
 		currentDelay := lm.calculateRetryDelay(attempt, retryDelay, maxRetryDelay)
 		time.Sleep(currentDelay)
 	}
 
-	return fmt.Errorf("timeout waiting for lock on page %v", pid)
+	// Drop the request so it does not hold back the requests queued behind it
+	lm.mutex.Lock()
+	lm.waitQueue.RemoveRequest(tid, pid)
+	lm.depGraph.RemoveTransaction(tid)
+	lm.mutex.Unlock()
+
+	lockTimeouts.Inc()
+	trace.record(event(EventTimeout))
+	return 0, newLockTimeoutError(nil, "timeout waiting for lock on page %v", pid)
 }
 
 // updateDependencies updates the dependency graph based on lock conflicts.
@@ -137,6 +213,7 @@ func (lm *LockManager) attemptToAcquireLock(tid *primitives.TransactionID, pid p
 // Dependency rules:
 // - Exclusive lock request creates dependency on all lock holders
 // - Shared lock request creates dependency only on exclusive lock holders
+// - Any request creates dependency on the waiters the grant policy queues it behind
 func (lm *LockManager) updateDependencies(tid *primitives.TransactionID, pid primitives.PageID, lockType LockType) {
 	locks := lm.lockTable.GetPageLocks(pid)
 
@@ -149,6 +226,27 @@ func (lm *LockManager) updateDependencies(tid *primitives.TransactionID, pid pri
 			lm.depGraph.AddEdge(tid, lock.TID)
 		}
 	}
+
+	for _, waiter := range lm.lockGrantor.QueuedAhead(tid, pid, lockType) {
+		lm.depGraph.AddEdge(tid, waiter)
+	}
+}
+
+// conflictingHolders returns the IDs of the transactions whose locks on pid
+// conflict with a lockType request by tid, following the rules of
+// updateDependencies. The caller must hold lm.mutex.
+func (lm *LockManager) conflictingHolders(tid *primitives.TransactionID, pid primitives.PageID, lockType LockType) []int64 {
+	var holders []int64
+	for _, lock := range lm.lockTable.GetPageLocks(pid) {
+		if lock.TID == tid {
+			continue
+		}
+		if lockType == ExclusiveLock || lock.LockType == ExclusiveLock {
+			holders = append(holders, lock.TID.ID())
+		}
+	}
+	slices.Sort(holders)
+	return holders
 }
 
 // calculateRetryDelay computes the delay for the next retry attempt using exponential backoff.
@@ -171,10 +269,11 @@ func (lm *LockManager) UnlockPage(tid *primitives.TransactionID, pid primitives.
 	lm.lockTable.ReleaseLock(tid, pid)
 	lm.depGraph.RemoveTransaction(tid)
 	lm.processWaitQueue(pid)
+	lm.trace.Load().record(LockEvent{TxID: tid.ID(), Kind: EventReleased, PageID: pid, Count: 1})
 }
 
 // processWaitQueue processes pending lock requests for a page after a lock is released.
-// Grants locks to waiting transactions in FIFO order when possible.
+// Grants locks to waiting transactions in queue order when the grant policy allows.
 func (lm *LockManager) processWaitQueue(pid primitives.PageID) {
 	requests := lm.waitQueue.GetRequests(pid)
 	if len(requests) == 0 {
@@ -200,6 +299,21 @@ func (lm *LockManager) processWaitQueue(pid primitives.PageID) {
 	}
 }
 
+// SetGrantPolicy sets the policy that decides when queued lock requests are
+// granted. It applies to requests made from then on and to those waiting.
+func (lm *LockManager) SetGrantPolicy(policy GrantPolicy) {
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+	lm.lockGrantor.policy = policy
+}
+
+// GrantPolicy returns the policy that decides when queued lock requests are granted.
+func (lm *LockManager) GrantPolicy() GrantPolicy {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+	return lm.lockGrantor.policy
+}
+
 // IsPageLocked checks if any locks are currently held on a page.
 func (lm *LockManager) IsPageLocked(pid primitives.PageID) bool {
 	lm.mutex.RLock()
@@ -208,18 +322,123 @@ func (lm *LockManager) IsPageLocked(pid primitives.PageID) bool {
 	return lm.lockTable.IsPageLocked(pid)
 }
 
-// UnlockAllPages releases all locks held by a primitives.
-// This is typically called during transaction commit or abort.
+// UnlockAllPages releases all locks held by a primitives, including its key
+// range and table locks. This is typically called during transaction commit or abort.
 // Processes wait queues for all affected pages after releasing locks.
 func (lm *LockManager) UnlockAllPages(tid *primitives.TransactionID) {
 	lm.mutex.Lock()
 	defer lm.mutex.Unlock()
 
 	pagesToProcess := lm.lockTable.ReleaseAllLocks(tid)
+	lm.releaseRangeLocks(tid)
+	lm.releaseTableLocks(tid)
+	lm.releaseSnapshot(tid)
 	lm.depGraph.RemoveTransaction(tid)
 	lm.waitQueue.RemoveAllForTransaction(tid)
 
 	for _, pid := range pagesToProcess {
 		lm.processWaitQueue(pid)
 	}
+
+	if len(pagesToProcess) > 0 {
+		lm.trace.Load().record(LockEvent{TxID: tid.ID(), Kind: EventReleased, Count: len(pagesToProcess)})
+	}
+}
+
+// StartTrace starts recording lock events into a new trace keeping at most
+// capacity events, replacing any trace in progress, and returns it.
+func (lm *LockManager) StartTrace(capacity int) *LockTrace {
+	trace := NewLockTrace(capacity)
+	lm.trace.Store(trace)
+	return trace
+}
+
+// StopTrace stops recording lock events and returns the trace that was being
+// recorded, or nil if none was.
+func (lm *LockManager) StopTrace() *LockTrace {
+	return lm.trace.Swap(nil)
+}
+
+// Trace returns the trace being recorded, or nil if tracing is off.
+func (lm *LockManager) Trace() *LockTrace {
+	return lm.trace.Load()
+}
+
+// Snapshot returns every lock currently held or waited for, ordered by
+// transaction ID with held locks before waiting requests.
+func (lm *LockManager) Snapshot() []LockInfo {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+
+	var infos []LockInfo
+	for pid, locks := range lm.lockTable.GetPageLockTable() {
+		for _, l := range locks {
+			infos = append(infos, LockInfo{TID: l.TID, PageID: pid, LockType: l.LockType, Granted: true, Since: l.GrantTime})
+		}
+	}
+	for pid, requests := range lm.waitQueue.pageWaitQueue {
+		for _, r := range requests {
+			infos = append(infos, LockInfo{TID: r.TID, PageID: pid, LockType: r.LockType, Since: r.RequestTime})
+		}
+	}
+
+	slices.SortFunc(infos, func(a, b LockInfo) int {
+		if c := cmp.Compare(a.TID.ID(), b.TID.ID()); c != 0 {
+			return c
+		}
+		if a.Granted != b.Granted {
+			if a.Granted {
+				return -1
+			}
+			return 1
+		}
+		return a.Since.Compare(b.Since)
+	})
+	return infos
+}
+
+// WaitQueueInfo describes the requests waiting for the locks of one page, as
+// reported by LockManager.WaitQueues.
+type WaitQueueInfo struct {
+	PageID           primitives.PageID
+	Holders          int       // Transactions holding a lock on the page
+	WaitingShared    int       // Requests waiting for a shared lock
+	WaitingExclusive int       // Requests waiting for an exclusive lock
+	OldestRequest    time.Time // When the longest waiting request was made
+}
+
+// Waiting returns the length of the wait queue.
+func (q WaitQueueInfo) Waiting() int {
+	return q.WaitingShared + q.WaitingExclusive
+}
+
+// WaitQueues returns the wait queue of every page with requests waiting for
+// its locks, longest queue first.
+func (lm *LockManager) WaitQueues() []WaitQueueInfo {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+
+	infos := make([]WaitQueueInfo, 0, len(lm.waitQueue.pageWaitQueue))
+	for pid, requests := range lm.waitQueue.pageWaitQueue {
+		info := WaitQueueInfo{PageID: pid, Holders: len(lm.lockTable.pageLocks[pid])}
+		for _, r := range requests {
+			if r.LockType == ExclusiveLock {
+				info.WaitingExclusive++
+			} else {
+				info.WaitingShared++
+			}
+			if info.OldestRequest.IsZero() || r.RequestTime.Before(info.OldestRequest) {
+				info.OldestRequest = r.RequestTime
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	slices.SortFunc(infos, func(a, b WaitQueueInfo) int {
+		if c := cmp.Compare(b.Waiting(), a.Waiting()); c != 0 {
+			return c
+		}
+		return a.OldestRequest.Compare(b.OldestRequest)
+	})
+	return infos
 }
@@ -0,0 +1,27 @@
+package lock
+
+import "storemy/pkg/metrics"
+
+var (
+	lockAcquisitions = metrics.NewCounter(
+		"storemy_lock_acquisitions_total",
+		"Page lock requests that were granted",
+	)
+	lockWaits = metrics.NewCounter(
+		"storemy_lock_waits_total",
+		"Page lock requests that had to wait for a conflicting lock",
+	)
+	lockDeadlocks = metrics.NewCounter(
+		"storemy_lock_deadlocks_total",
+		"Lock requests aborted because they would cause a deadlock",
+	)
+	lockTimeouts = metrics.NewCounter(
+		"storemy_lock_timeouts_total",
+		"Lock requests that gave up after exhausting their retries",
+	)
+	lockWaitSeconds = metrics.NewHistogram(
+		"storemy_lock_wait_seconds",
+		"Time spent waiting for page locks that were eventually granted",
+		metrics.DefaultLatencyBuckets,
+	)
+)
@@ -0,0 +1,198 @@
+package lock
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"storemy/pkg/primitives"
+	"storemy/pkg/types"
+	"time"
+)
+
+// KeyRange is a closed range of index keys. A nil bound leaves that side of
+// the range open, so the zero KeyRange covers every key.
+type KeyRange struct {
+	Low  types.Field
+	High types.Field
+}
+
+// Contains reports whether key falls inside the range. Keys that cannot be
+// compared with a bound are treated as inside, erring on the side of
+// blocking an insert rather than letting a phantom through.
+func (r KeyRange) Contains(key types.Field) bool {
+	if r.Low != nil {
+		if ok, err := key.Compare(primitives.GreaterThanOrEqual, r.Low); err == nil && !ok {
+			return false
+		}
+	}
+	if r.High != nil {
+		if ok, err := key.Compare(primitives.LessThanOrEqual, r.High); err == nil && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// covers reports whether r contains every key of other.
+func (r KeyRange) covers(other KeyRange) bool {
+	if r.Low != nil && (other.Low == nil || !r.Contains(other.Low)) {
+		return false
+	}
+	if r.High != nil && (other.High == nil || !r.Contains(other.High)) {
+		return false
+	}
+	return true
+}
+
+func (r KeyRange) String() string {
+	low, high := "-inf", "+inf"
+	if r.Low != nil {
+		low = r.Low.String()
+	}
+	if r.High != nil {
+		high = r.High.String()
+	}
+	return fmt.Sprintf("[%s, %s]", low, high)
+}
+
+// RangeLock is a key range of an index read by a serializable transaction.
+// It protects the gaps between the keys the scan saw: page locks already
+// keep those keys from changing, and the range lock keeps other transactions
+// from inserting new keys into the range until the holder finishes.
+type RangeLock struct {
+	TID       *primitives.TransactionID
+	IndexID   primitives.FileID
+	Range     KeyRange
+	GrantTime time.Time
+}
+
+// LockKeyRange records that tid scanned r in the index indexID. Range locks
+// never conflict with each other, so it is granted immediately; inserts into
+// the range wait in LockKeyInsert until tid commits or aborts.
+func (lm *LockManager) LockKeyRange(tid *primitives.TransactionID, indexID primitives.FileID, r KeyRange) error {
+	if tid == nil {
+		return fmt.Errorf("transaction ID cannot be nil")
+	}
+
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	for _, held := range lm.rangeLocks[indexID] {
+		if held.TID == tid && held.Range.covers(r) {
+			return nil
+		}
+	}
+	lm.rangeLocks[indexID] = append(lm.rangeLocks[indexID], &RangeLock{
+		TID:       tid,
+		IndexID:   indexID,
+		Range:     r,
+		GrantTime: time.Now(),
+	})
+	return nil
+}
+
+// LockKeyInsert waits until no other transaction holds a range lock covering
+// key in the index indexID, so that tid may insert it. Nothing is held
+// afterwards: the inserted entry itself is protected by the page lock on the
+// leaf it lands in.
+//
+// Waiting follows the same rules as page locks: the wait is recorded in the
+// dependency graph, a cycle fails the request as a deadlock, and the request
+// times out after the same number of retries.
+//
+// Returns how long tid waited, zero when no range lock was in the way.
+func (lm *LockManager) LockKeyInsert(tid *primitives.TransactionID, indexID primitives.FileID, key types.Field) (time.Duration, error) {
+	if tid == nil {
+		return 0, fmt.Errorf("transaction ID cannot be nil")
+	}
+
+	const maxRetryDelay = 50 * time.Millisecond
+	maxRetries := 100
+	retryDelay := time.Millisecond
+
+	var waitStart time.Time
+	for attempt := range maxRetries {
+		lm.mutex.Lock()
+
+		holders := lm.rangeHolders(tid, indexID, key)
+		if len(holders) == 0 {
+			lm.depGraph.RemoveTransaction(tid)
+			lm.mutex.Unlock()
+			if waitStart.IsZero() {
+				return 0, nil
+			}
+			waited := time.Since(waitStart)
+			lockWaitSeconds.Observe(waited.Seconds())
+			return waited, nil
+		}
+
+		for _, holder := range holders {
+			lm.depGraph.AddEdge(tid, holder)
+		}
+		if lm.depGraph.HasCycle() {
+			lm.depGraph.RemoveTransaction(tid)
+			lm.mutex.Unlock()
+			lockDeadlocks.Inc()
+			return 0, newDeadlockError(tid)
+		}
+
+		lm.mutex.Unlock()
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+			lockWaits.Inc()
+		}
+		time.Sleep(lm.calculateRetryDelay(attempt, retryDelay, maxRetryDelay))
+	}
+
+	lm.mutex.Lock()
+	lm.depGraph.RemoveTransaction(tid)
+	lm.mutex.Unlock()
+	lockTimeouts.Inc()
+	return 0, newLockTimeoutError(nil, "timeout waiting for key range lock on %v in index %d", key, indexID)
+}
+
+// rangeHolders returns the transactions other than tid holding a range lock
+// that covers key. The caller must hold lm.mutex.
+func (lm *LockManager) rangeHolders(tid *primitives.TransactionID, indexID primitives.FileID, key types.Field) []*primitives.TransactionID {
+	var holders []*primitives.TransactionID
+	for _, held := range lm.rangeLocks[indexID] {
+		if held.TID != tid && held.Range.Contains(key) && !slices.Contains(holders, held.TID) {
+			holders = append(holders, held.TID)
+		}
+	}
+	return holders
+}
+
+// releaseRangeLocks drops every range lock held by tid. The caller must hold
+// lm.mutex.
+func (lm *LockManager) releaseRangeLocks(tid *primitives.TransactionID) {
+	for indexID, locks := range lm.rangeLocks {
+		locks = slices.DeleteFunc(locks, func(l *RangeLock) bool { return l.TID == tid })
+		if len(locks) == 0 {
+			delete(lm.rangeLocks, indexID)
+		} else {
+			lm.rangeLocks[indexID] = locks
+		}
+	}
+}
+
+// RangeLocks returns the range locks currently held, ordered by index and
+// grant time.
+func (lm *LockManager) RangeLocks() []RangeLock {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+
+	var locks []RangeLock
+	for _, held := range lm.rangeLocks {
+		for _, l := range held {
+			locks = append(locks, *l)
+		}
+	}
+	slices.SortFunc(locks, func(a, b RangeLock) int {
+		if c := cmp.Compare(a.IndexID, b.IndexID); c != 0 {
+			return c
+		}
+		return a.GrantTime.Compare(b.GrantTime)
+	})
+	return locks
+}
@@ -0,0 +1,132 @@
+package lock
+
+import (
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyRange_Contains(t *testing.T) {
+	tests := []struct {
+		name string
+		r    KeyRange
+		key  int64
+		want bool
+	}{
+		{"inside", KeyRange{types.NewIntField(10), types.NewIntField(20)}, 15, true},
+		{"low bound", KeyRange{types.NewIntField(10), types.NewIntField(20)}, 10, true},
+		{"high bound", KeyRange{types.NewIntField(10), types.NewIntField(20)}, 20, true},
+		{"below", KeyRange{types.NewIntField(10), types.NewIntField(20)}, 9, false},
+		{"above", KeyRange{types.NewIntField(10), types.NewIntField(20)}, 21, false},
+		{"open low", KeyRange{nil, types.NewIntField(20)}, -100, true},
+		{"open high", KeyRange{types.NewIntField(10), nil}, 1000, true},
+		{"point", KeyRange{types.NewIntField(7), types.NewIntField(7)}, 7, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Contains(types.NewIntField(tt.key)); got != tt.want {
+				t.Errorf("%v.Contains(%d) = %v, want %v", tt.r, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockKeyInsert_WaitsForRangeHolder(t *testing.T) {
+	lm := NewLockManager()
+	scanner := primitives.NewTransactionID()
+	inserter := primitives.NewTransactionID()
+	indexID := primitives.FileID(7)
+
+	r := KeyRange{Low: types.NewIntField(10), High: types.NewIntField(20)}
+	if err := lm.LockKeyRange(scanner, indexID, r); err != nil {
+		t.Fatalf("LockKeyRange failed: %v", err)
+	}
+
+	// Keys outside the range and other indexes are not affected
+	if waited, err := lm.LockKeyInsert(inserter, indexID, types.NewIntField(25)); err != nil || waited != 0 {
+		t.Fatalf("insert outside the range: waited %v, err %v", waited, err)
+	}
+	if waited, err := lm.LockKeyInsert(inserter, indexID+1, types.NewIntField(15)); err != nil || waited != 0 {
+		t.Fatalf("insert into another index: waited %v, err %v", waited, err)
+	}
+	// A transaction never waits for its own range
+	if waited, err := lm.LockKeyInsert(scanner, indexID, types.NewIntField(15)); err != nil || waited != 0 {
+		t.Fatalf("insert by the scanner: waited %v, err %v", waited, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := lm.LockKeyInsert(inserter, indexID, types.NewIntField(15))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("insert into a locked range returned early: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	lm.UnlockAllPages(scanner)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("insert after the scanner finished failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("insert still waiting after the range lock was released")
+	}
+
+	if locks := lm.RangeLocks(); len(locks) != 0 {
+		t.Errorf("expected no range locks after UnlockAllPages, got %v", locks)
+	}
+}
+
+func TestLockKeyRange_SkipsCoveredRanges(t *testing.T) {
+	lm := NewLockManager()
+	tid := primitives.NewTransactionID()
+
+	lm.LockKeyRange(tid, 1, KeyRange{Low: types.NewIntField(0), High: types.NewIntField(100)})
+	lm.LockKeyRange(tid, 1, KeyRange{Low: types.NewIntField(10), High: types.NewIntField(20)})
+	lm.LockKeyRange(tid, 1, KeyRange{Low: types.NewIntField(50), High: nil})
+
+	locks := lm.RangeLocks()
+	if len(locks) != 2 {
+		t.Fatalf("expected 2 range locks, got %d: %v", len(locks), locks)
+	}
+	if got := locks[1].Range.String(); got != "[50, +inf]" {
+		t.Errorf("second range = %s, want [50, +inf]", got)
+	}
+}
+
+func TestLockKeyInsert_Deadlock(t *testing.T) {
+	lm := NewLockManager()
+	t1 := primitives.NewTransactionID()
+	t2 := primitives.NewTransactionID()
+	pid := page.NewPageDescriptor(1, 1)
+
+	// T1 holds a range and waits for a page T2 has locked; T2 then tries to
+	// insert into T1's range
+	lm.LockKeyRange(t1, 1, KeyRange{})
+	if err := lm.LockPage(t2, pid, true); err != nil {
+		t.Fatalf("LockPage failed: %v", err)
+	}
+
+	t1Done := make(chan error, 1)
+	go func() { t1Done <- lm.LockPage(t1, pid, true) }()
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := lm.LockKeyInsert(t2, 1, types.NewIntField(1))
+	if err == nil || !strings.Contains(err.Error(), "deadlock") {
+		t.Fatalf("expected a deadlock error, got %v", err)
+	}
+
+	lm.UnlockAllPages(t2)
+	if err := <-t1Done; err != nil {
+		t.Fatalf("T1 should get the page once T2 aborts: %v", err)
+	}
+}
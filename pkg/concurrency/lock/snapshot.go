@@ -0,0 +1,274 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"storemy/pkg/primitives"
+	"time"
+)
+
+// ErrSnapshotNotFound is returned (wrapped) when a snapshot token does not
+// name a snapshot that can still be imported.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// ErrSnapshotTimeout is returned (wrapped) when a snapshot could not be
+// exported, or a write could not start while a snapshot was held, before the
+// table lock timeout passed.
+var ErrSnapshotTimeout = errors.New("timeout waiting for snapshot")
+
+// snapshotGate orders writers against exported snapshots. Locks are held
+// until commit, so a transaction that reads while no other transaction is
+// writing sees a state that stays consistent until it ends; the gate keeps
+// it that way across transactions. Writers pass it in writer mode before
+// their first exclusive page lock, transactions holding a snapshot in
+// snapshot mode. Either mode is shared with other holders of the same mode
+// and excludes the other one.
+type snapshotGate struct {
+	writers map[*primitives.TransactionID]struct{}
+	holders map[*primitives.TransactionID]string // Snapshot holders and the token of their snapshot
+	waiting []*primitives.TransactionID          // Exports waiting for writers, in arrival order
+	tokens  map[string]*primitives.TransactionID // Exported tokens and their exporters
+	nextSeq uint64
+}
+
+func newSnapshotGate() snapshotGate {
+	return snapshotGate{
+		writers: make(map[*primitives.TransactionID]struct{}),
+		holders: make(map[*primitives.TransactionID]string),
+		tokens:  make(map[string]*primitives.TransactionID),
+	}
+}
+
+// ExportSnapshot makes the state tid reads a snapshot other transactions can
+// share, and returns the token that names it. It waits until no other
+// transaction is writing, and from then until tid ends no transaction can
+// start writing, so every transaction importing the token reads the same
+// committed state as tid. Exporting again from tid returns the same token.
+//
+// tid must not have written anything: its changes are not committed, so
+// importers could not see them. The wait takes part in deadlock detection
+// and fails with ErrSnapshotTimeout after the table lock timeout. While it
+// waits, transactions that have not yet written queue behind it, so a
+// stream of short writes cannot starve the export.
+//
+// Returns the token and how long tid waited, zero when no writer was active.
+func (lm *LockManager) ExportSnapshot(tid *primitives.TransactionID) (string, time.Duration, error) {
+	if tid == nil {
+		return "", 0, fmt.Errorf("transaction ID cannot be nil")
+	}
+
+	const maxRetryDelay = 50 * time.Millisecond
+	retryDelay := time.Millisecond
+	deadline := time.Now().Add(time.Duration(lm.tableLockTimeout.Load()))
+
+	var waitStart time.Time
+	for attempt := 0; ; attempt++ {
+		lm.mutex.Lock()
+		gate := &lm.snapshots
+
+		if _, wrote := gate.writers[tid]; wrote {
+			lm.mutex.Unlock()
+			return "", 0, fmt.Errorf("transaction %d cannot export a snapshot after writing", tid.ID())
+		}
+		if token, ok := gate.holders[tid]; ok && gate.tokens[token] == tid {
+			lm.mutex.Unlock()
+			return token, 0, nil
+		}
+
+		writers := lm.snapshotConflicts(tid, false)
+		if len(writers) == 0 {
+			gate.nextSeq++
+			token := fmt.Sprintf("%08X-%08X", tid.ID(), gate.nextSeq)
+			gate.holders[tid] = token
+			gate.tokens[token] = tid
+			gate.waiting = slices.DeleteFunc(gate.waiting, func(w *primitives.TransactionID) bool { return w == tid })
+			lm.depGraph.RemoveTransaction(tid)
+			lm.mutex.Unlock()
+			lockAcquisitions.Inc()
+			if waitStart.IsZero() {
+				return token, 0, nil
+			}
+			waited := time.Since(waitStart)
+			lockWaitSeconds.Observe(waited.Seconds())
+			return token, waited, nil
+		}
+
+		if !slices.Contains(gate.waiting, tid) {
+			gate.waiting = append(gate.waiting, tid)
+		}
+		for _, writer := range writers {
+			lm.depGraph.AddEdge(tid, writer)
+		}
+		if lm.depGraph.HasCycle() {
+			lm.abandonSnapshot(tid)
+			lm.mutex.Unlock()
+			lockDeadlocks.Inc()
+			return "", 0, newDeadlockError(tid)
+		}
+		if !time.Now().Before(deadline) {
+			lm.abandonSnapshot(tid)
+			lm.mutex.Unlock()
+			lockTimeouts.Inc()
+			return "", 0, newLockTimeoutError(ErrSnapshotTimeout, "snapshot export not granted after %v", time.Since(waitStart).Round(time.Millisecond))
+		}
+
+		lm.mutex.Unlock()
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+			lockWaits.Inc()
+		}
+		time.Sleep(lm.calculateRetryDelay(attempt, retryDelay, maxRetryDelay))
+	}
+}
+
+// ImportSnapshot makes tid read the snapshot named by token, exported by a
+// transaction that is still running. Since no transaction writes while the
+// exporter holds its snapshot, tid already reads the same state; importing
+// keeps writers out until tid ends too, so it stays consistent after the
+// exporter finishes. A token can no longer be imported once its exporter has
+// ended, and tid must not have written anything.
+func (lm *LockManager) ImportSnapshot(tid *primitives.TransactionID, token string) error {
+	if tid == nil {
+		return fmt.Errorf("transaction ID cannot be nil")
+	}
+
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+	gate := &lm.snapshots
+
+	if _, ok := gate.tokens[token]; !ok {
+		return fmt.Errorf("%w: %q", ErrSnapshotNotFound, token)
+	}
+	if _, wrote := gate.writers[tid]; wrote {
+		return fmt.Errorf("transaction %d cannot import a snapshot after writing", tid.ID())
+	}
+	if held, ok := gate.holders[tid]; ok && held != token {
+		return fmt.Errorf("transaction %d already holds snapshot %s", tid.ID(), held)
+	}
+	gate.holders[tid] = token
+	return nil
+}
+
+// SnapshotOf returns the token of the snapshot tid holds, exported or
+// imported, and whether it holds one.
+func (lm *LockManager) SnapshotOf(tid *primitives.TransactionID) (string, bool) {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+	token, ok := lm.snapshots.holders[tid]
+	return token, ok
+}
+
+// enterWriteGate marks tid as a writer before it takes an exclusive page
+// lock, waiting until no other transaction holds or is exporting a
+// snapshot. It fails like ExportSnapshot does.
+func (lm *LockManager) enterWriteGate(tid *primitives.TransactionID) (time.Duration, error) {
+	lm.mutex.RLock()
+	_, entered := lm.snapshots.writers[tid]
+	lm.mutex.RUnlock()
+	if entered {
+		return 0, nil
+	}
+
+	const maxRetryDelay = 50 * time.Millisecond
+	retryDelay := time.Millisecond
+	deadline := time.Now().Add(time.Duration(lm.tableLockTimeout.Load()))
+
+	var waitStart time.Time
+	for attempt := 0; ; attempt++ {
+		lm.mutex.Lock()
+		gate := &lm.snapshots
+
+		if _, ok := gate.holders[tid]; ok {
+			lm.mutex.Unlock()
+			return 0, fmt.Errorf("transaction %d cannot write while holding a snapshot", tid.ID())
+		}
+
+		holders := lm.snapshotConflicts(tid, true)
+		if len(holders) == 0 {
+			gate.writers[tid] = struct{}{}
+			lm.depGraph.RemoveTransaction(tid)
+			lm.mutex.Unlock()
+			if waitStart.IsZero() {
+				return 0, nil
+			}
+			waited := time.Since(waitStart)
+			lockWaitSeconds.Observe(waited.Seconds())
+			return waited, nil
+		}
+
+		for _, holder := range holders {
+			lm.depGraph.AddEdge(tid, holder)
+		}
+		if lm.depGraph.HasCycle() {
+			lm.depGraph.RemoveTransaction(tid)
+			lm.mutex.Unlock()
+			lockDeadlocks.Inc()
+			return 0, newDeadlockError(tid)
+		}
+		if !time.Now().Before(deadline) {
+			lm.depGraph.RemoveTransaction(tid)
+			lm.mutex.Unlock()
+			lockTimeouts.Inc()
+			return 0, newLockTimeoutError(ErrSnapshotTimeout, "snapshot not released after %v", time.Since(waitStart).Round(time.Millisecond))
+		}
+
+		lm.mutex.Unlock()
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+			lockWaits.Inc()
+		}
+		time.Sleep(lm.calculateRetryDelay(attempt, retryDelay, maxRetryDelay))
+	}
+}
+
+// snapshotConflicts returns the transactions tid must wait for before it
+// can pass the gate as a writer, or as a snapshot holder when writer is
+// false. The caller must hold lm.mutex.
+func (lm *LockManager) snapshotConflicts(tid *primitives.TransactionID, writer bool) []*primitives.TransactionID {
+	gate := &lm.snapshots
+	var conflicts []*primitives.TransactionID
+	if !writer {
+		for w := range gate.writers {
+			if w != tid {
+				conflicts = append(conflicts, w)
+			}
+		}
+		return conflicts
+	}
+
+	for holder := range gate.holders {
+		if holder != tid {
+			conflicts = append(conflicts, holder)
+		}
+	}
+	// New writers let waiting exports go first
+	for _, waiter := range gate.waiting {
+		if waiter != tid && !slices.Contains(conflicts, waiter) {
+			conflicts = append(conflicts, waiter)
+		}
+	}
+	return conflicts
+}
+
+// abandonSnapshot withdraws an export by tid that failed. The caller must
+// hold lm.mutex.
+func (lm *LockManager) abandonSnapshot(tid *primitives.TransactionID) {
+	lm.depGraph.RemoveTransaction(tid)
+	lm.snapshots.waiting = slices.DeleteFunc(lm.snapshots.waiting, func(w *primitives.TransactionID) bool { return w == tid })
+}
+
+// releaseSnapshot takes tid out of the gate, in whichever mode it was, and
+// retires the snapshot it exported. Importers keep their snapshot. The
+// caller must hold lm.mutex.
+func (lm *LockManager) releaseSnapshot(tid *primitives.TransactionID) {
+	gate := &lm.snapshots
+	delete(gate.writers, tid)
+	delete(gate.holders, tid)
+	gate.waiting = slices.DeleteFunc(gate.waiting, func(w *primitives.TransactionID) bool { return w == tid })
+	for token, exporter := range gate.tokens {
+		if exporter == tid {
+			delete(gate.tokens, token)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package lock
+
+import (
+	"errors"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_ExportWaitsForWriters(t *testing.T) {
+	lm := NewLockManager()
+	writer, exporter, importer := primitives.NewTransactionID(), primitives.NewTransactionID(), primitives.NewTransactionID()
+	pid := page.NewPageDescriptor(1, 0)
+
+	if err := lm.LockPage(writer, pid, true); err != nil {
+		t.Fatalf("exclusive lock failed: %v", err)
+	}
+
+	type exported struct {
+		token string
+		err   error
+	}
+	done := make(chan exported, 1)
+	go func() {
+		token, _, err := lm.ExportSnapshot(exporter)
+		done <- exported{token, err}
+	}()
+
+	select {
+	case e := <-done:
+		t.Fatalf("snapshot exported while a writer is active: %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+	lm.UnlockAllPages(writer)
+
+	var token string
+	select {
+	case e := <-done:
+		if e.err != nil {
+			t.Fatalf("export after the writer finished failed: %v", e.err)
+		}
+		token = e.token
+	case <-time.After(2 * time.Second):
+		t.Fatal("snapshot not exported after the writer finished")
+	}
+
+	if again, _, err := lm.ExportSnapshot(exporter); err != nil || again != token {
+		t.Errorf("second export = %q, %v; expected %q", again, err, token)
+	}
+	if err := lm.ImportSnapshot(importer, token); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if got, ok := lm.SnapshotOf(importer); !ok || got != token {
+		t.Errorf("SnapshotOf(importer) = %q, %v", got, ok)
+	}
+
+	// The importer keeps writers out after the exporter ends, but the
+	// token can no longer be imported
+	lm.UnlockAllPages(exporter)
+	if err := lm.ImportSnapshot(primitives.NewTransactionID(), token); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("import after the exporter ended: expected ErrSnapshotNotFound, got %v", err)
+	}
+
+	lm.SetTableLockTimeout(100 * time.Millisecond)
+	late := primitives.NewTransactionID()
+	if err := lm.LockPage(late, page.NewPageDescriptor(1, 1), true); !errors.Is(err, ErrSnapshotTimeout) {
+		t.Errorf("write while a snapshot is held: expected ErrSnapshotTimeout, got %v", err)
+	}
+
+	lm.UnlockAllPages(importer)
+	if err := lm.LockPage(late, page.NewPageDescriptor(1, 1), true); err != nil {
+		t.Errorf("write after the snapshot was released failed: %v", err)
+	}
+}
+
+func TestSnapshot_WritersCannotShareSnapshots(t *testing.T) {
+	lm := NewLockManager()
+	writer, reader := primitives.NewTransactionID(), primitives.NewTransactionID()
+
+	if err := lm.LockPage(writer, page.NewPageDescriptor(1, 0), true); err != nil {
+		t.Fatalf("exclusive lock failed: %v", err)
+	}
+	if _, _, err := lm.ExportSnapshot(writer); err == nil {
+		t.Error("expected exporting after writing to fail")
+	}
+	lm.UnlockAllPages(writer)
+
+	token, _, err := lm.ExportSnapshot(reader)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if err := lm.LockPage(reader, page.NewPageDescriptor(1, 0), true); err == nil {
+		t.Error("expected writing while holding a snapshot to fail")
+	}
+	if err := lm.ImportSnapshot(reader, "00000000-00000000"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("expected ErrSnapshotNotFound for an unknown token, got %v", err)
+	}
+	if err := lm.ImportSnapshot(reader, token); err != nil {
+		t.Errorf("importing its own snapshot failed: %v", err)
+	}
+}
+
+func TestSnapshot_DeadlockWithPageLock(t *testing.T) {
+	lm := NewLockManager()
+	writer, exporter := primitives.NewTransactionID(), primitives.NewTransactionID()
+	pid := page.NewPageDescriptor(1, 0)
+
+	// The exporter read the page the writer is about to write
+	if err := lm.LockPage(exporter, pid, false); err != nil {
+		t.Fatalf("shared lock failed: %v", err)
+	}
+	if err := lm.LockPage(writer, page.NewPageDescriptor(1, 1), true); err != nil {
+		t.Fatalf("exclusive lock failed: %v", err)
+	}
+
+	// Whichever side fails aborts, letting the other one through
+	done := make(chan error, 1)
+	go func() {
+		err := lm.LockPage(writer, pid, true)
+		if err != nil {
+			lm.UnlockAllPages(writer)
+		}
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	_, _, exportErr := lm.ExportSnapshot(exporter)
+	if exportErr != nil {
+		lm.UnlockAllPages(exporter)
+	}
+	writeErr := <-done
+	if (exportErr == nil) == (writeErr == nil) {
+		t.Fatalf("expected exactly one side to fail: export %v, write %v", exportErr, writeErr)
+	}
+}
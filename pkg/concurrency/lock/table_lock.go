@@ -0,0 +1,208 @@
+package lock
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"storemy/pkg/primitives"
+	"time"
+)
+
+// DefaultTableLockTimeout is how long a table lock request waits for
+// conflicting transactions to finish before it fails.
+const DefaultTableLockTimeout = 5 * time.Second
+
+// ErrTableLockTimeout is returned (wrapped) when a table lock could not be
+// acquired before the table lock timeout passed.
+var ErrTableLockTimeout = errors.New("timeout waiting for table lock")
+
+// tableLock is the state of the table-level lock on one table.
+type tableLock struct {
+	holders map[*primitives.TransactionID]LockType
+	waiting []*primitives.TransactionID // Exclusive requests waiting, in arrival order
+}
+
+// TableLockInfo describes a table lock held by a transaction.
+type TableLockInfo struct {
+	TID      *primitives.TransactionID
+	TableID  primitives.FileID
+	LockType LockType
+}
+
+// SetTableLockTimeout sets how long LockTable waits before failing with
+// ErrTableLockTimeout. A non-positive d restores DefaultTableLockTimeout.
+func (lm *LockManager) SetTableLockTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTableLockTimeout
+	}
+	lm.tableLockTimeout.Store(int64(d))
+}
+
+// LockTable acquires a table-level lock on tableID for tid, held until the
+// transaction ends. Statements reading or writing a table take a shared
+// lock, so any number of them run together; DDL that changes or removes a
+// table or its files takes an exclusive lock, so it waits for the queries
+// using the table to finish and keeps new ones out until it commits.
+//
+// Table locks are independent of page locks, but waits for both share the
+// dependency graph, so a cycle through either fails the request as a
+// deadlock. While an exclusive request waits, shared requests from
+// transactions not already holding the table queue behind it, so DDL is not
+// starved by a stream of short queries. A request that is not granted within
+// the table lock timeout fails with ErrTableLockTimeout.
+//
+// Returns how long tid waited, zero when the lock was granted immediately.
+func (lm *LockManager) LockTable(tid *primitives.TransactionID, tableID primitives.FileID, exclusive bool) (time.Duration, error) {
+	if tid == nil {
+		return 0, fmt.Errorf("transaction ID cannot be nil")
+	}
+
+	lockType := SharedLock
+	if exclusive {
+		lockType = ExclusiveLock
+	}
+
+	const maxRetryDelay = 50 * time.Millisecond
+	retryDelay := time.Millisecond
+	deadline := time.Now().Add(time.Duration(lm.tableLockTimeout.Load()))
+
+	var waitStart time.Time
+	for attempt := 0; ; attempt++ {
+		lm.mutex.Lock()
+
+		holders := lm.tableLockConflicts(tid, tableID, lockType)
+		if len(holders) == 0 {
+			lm.grantTableLock(tid, tableID, lockType)
+			lm.depGraph.RemoveTransaction(tid)
+			lm.mutex.Unlock()
+			lockAcquisitions.Inc()
+			if waitStart.IsZero() {
+				return 0, nil
+			}
+			waited := time.Since(waitStart)
+			lockWaitSeconds.Observe(waited.Seconds())
+			return waited, nil
+		}
+
+		if exclusive {
+			lm.addTableWaiter(tid, tableID)
+		}
+		for _, holder := range holders {
+			lm.depGraph.AddEdge(tid, holder)
+		}
+		if lm.depGraph.HasCycle() {
+			lm.abandonTableLock(tid, tableID)
+			lm.mutex.Unlock()
+			lockDeadlocks.Inc()
+			return 0, newDeadlockError(tid)
+		}
+		if !time.Now().Before(deadline) {
+			lm.abandonTableLock(tid, tableID)
+			lm.mutex.Unlock()
+			lockTimeouts.Inc()
+			return 0, newLockTimeoutError(ErrTableLockTimeout, "lock on table %d not granted after %v", tableID, time.Since(waitStart).Round(time.Millisecond))
+		}
+
+		lm.mutex.Unlock()
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+			lockWaits.Inc()
+		}
+		time.Sleep(lm.calculateRetryDelay(attempt, retryDelay, maxRetryDelay))
+	}
+}
+
+// tableLockConflicts returns the transactions tid must wait for before it
+// can hold lockType on tableID. The caller must hold lm.mutex.
+func (lm *LockManager) tableLockConflicts(tid *primitives.TransactionID, tableID primitives.FileID, lockType LockType) []*primitives.TransactionID {
+	tl := lm.tableLocks[tableID]
+	if tl == nil {
+		return nil
+	}
+
+	var conflicts []*primitives.TransactionID
+	for holder, held := range tl.holders {
+		if holder != tid && (lockType == ExclusiveLock || held == ExclusiveLock) {
+			conflicts = append(conflicts, holder)
+		}
+	}
+
+	// Newcomers let waiting DDL go first
+	if _, holds := tl.holders[tid]; lockType == SharedLock && !holds {
+		for _, waiter := range tl.waiting {
+			if waiter != tid && !slices.Contains(conflicts, waiter) {
+				conflicts = append(conflicts, waiter)
+			}
+		}
+	}
+	return conflicts
+}
+
+// grantTableLock records that tid holds lockType on tableID, keeping an
+// exclusive lock it already holds. The caller must hold lm.mutex.
+func (lm *LockManager) grantTableLock(tid *primitives.TransactionID, tableID primitives.FileID, lockType LockType) {
+	tl := lm.tableLocks[tableID]
+	if tl == nil {
+		tl = &tableLock{holders: make(map[*primitives.TransactionID]LockType)}
+		lm.tableLocks[tableID] = tl
+	}
+	if held, ok := tl.holders[tid]; !ok || held == SharedLock {
+		tl.holders[tid] = lockType
+	}
+	tl.waiting = slices.DeleteFunc(tl.waiting, func(w *primitives.TransactionID) bool { return w == tid })
+}
+
+// addTableWaiter queues an exclusive request by tid on tableID. The caller
+// must hold lm.mutex.
+func (lm *LockManager) addTableWaiter(tid *primitives.TransactionID, tableID primitives.FileID) {
+	tl := lm.tableLocks[tableID]
+	if !slices.Contains(tl.waiting, tid) {
+		tl.waiting = append(tl.waiting, tid)
+	}
+}
+
+// abandonTableLock withdraws a request by tid on tableID that failed. The
+// caller must hold lm.mutex.
+func (lm *LockManager) abandonTableLock(tid *primitives.TransactionID, tableID primitives.FileID) {
+	lm.depGraph.RemoveTransaction(tid)
+	if tl := lm.tableLocks[tableID]; tl != nil {
+		tl.waiting = slices.DeleteFunc(tl.waiting, func(w *primitives.TransactionID) bool { return w == tid })
+		if len(tl.holders) == 0 && len(tl.waiting) == 0 {
+			delete(lm.tableLocks, tableID)
+		}
+	}
+}
+
+// releaseTableLocks drops every table lock held or requested by tid. The
+// caller must hold lm.mutex.
+func (lm *LockManager) releaseTableLocks(tid *primitives.TransactionID) {
+	for tableID, tl := range lm.tableLocks {
+		delete(tl.holders, tid)
+		tl.waiting = slices.DeleteFunc(tl.waiting, func(w *primitives.TransactionID) bool { return w == tid })
+		if len(tl.holders) == 0 && len(tl.waiting) == 0 {
+			delete(lm.tableLocks, tableID)
+		}
+	}
+}
+
+// TableLocks returns the table locks currently held, ordered by table and
+// transaction ID.
+func (lm *LockManager) TableLocks() []TableLockInfo {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+
+	var infos []TableLockInfo
+	for tableID, tl := range lm.tableLocks {
+		for tid, lockType := range tl.holders {
+			infos = append(infos, TableLockInfo{TID: tid, TableID: tableID, LockType: lockType})
+		}
+	}
+	slices.SortFunc(infos, func(a, b TableLockInfo) int {
+		if c := cmp.Compare(a.TableID, b.TableID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.TID.ID(), b.TID.ID())
+	})
+	return infos
+}
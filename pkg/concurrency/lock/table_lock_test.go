@@ -0,0 +1,165 @@
+package lock
+
+import (
+	"errors"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"testing"
+	"time"
+)
+
+func TestLockTable_ExclusiveWaitsForShared(t *testing.T) {
+	lm := NewLockManager()
+	reader1, reader2 := primitives.NewTransactionID(), primitives.NewTransactionID()
+	ddl := primitives.NewTransactionID()
+	tableID := primitives.FileID(3)
+
+	for _, tid := range []*primitives.TransactionID{reader1, reader2} {
+		if waited, err := lm.LockTable(tid, tableID, false); err != nil || waited != 0 {
+			t.Fatalf("shared lock: waited %v, err %v", waited, err)
+		}
+	}
+	// Other tables are not affected
+	if _, err := lm.LockTable(ddl, tableID+1, true); err != nil {
+		t.Fatalf("exclusive lock on another table failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := lm.LockTable(ddl, tableID, true)
+		done <- err
+	}()
+
+	for _, reader := range []*primitives.TransactionID{reader1, reader2} {
+		select {
+		case err := <-done:
+			t.Fatalf("exclusive lock granted while readers hold the table: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		lm.UnlockAllPages(reader)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("exclusive lock after the readers finished failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("exclusive lock not granted after the readers finished")
+	}
+
+	locks := lm.TableLocks()
+	if len(locks) != 2 || locks[0].TID != ddl || locks[0].LockType != ExclusiveLock {
+		t.Errorf("unexpected table locks %+v", locks)
+	}
+	lm.UnlockAllPages(ddl)
+	if locks := lm.TableLocks(); len(locks) != 0 {
+		t.Errorf("expected no table locks after commit, got %+v", locks)
+	}
+}
+
+func TestLockTable_NewReadersQueueBehindWaitingDDL(t *testing.T) {
+	lm := NewLockManager()
+	reader, ddl, late := primitives.NewTransactionID(), primitives.NewTransactionID(), primitives.NewTransactionID()
+	tableID := primitives.FileID(3)
+
+	if _, err := lm.LockTable(reader, tableID, false); err != nil {
+		t.Fatalf("shared lock failed: %v", err)
+	}
+	ddlDone := make(chan error, 1)
+	go func() {
+		_, err := lm.LockTable(ddl, tableID, true)
+		ddlDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	lateDone := make(chan error, 1)
+	go func() {
+		_, err := lm.LockTable(late, tableID, false)
+		lateDone <- err
+	}()
+
+	// The reader already holding the table is not held up
+	if waited, err := lm.LockTable(reader, tableID, false); err != nil || waited != 0 {
+		t.Fatalf("repeated shared lock: waited %v, err %v", waited, err)
+	}
+
+	lm.UnlockAllPages(reader)
+	if err := <-ddlDone; err != nil {
+		t.Fatalf("exclusive lock failed: %v", err)
+	}
+	select {
+	case err := <-lateDone:
+		t.Fatalf("late reader granted while DDL holds the table: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	lm.UnlockAllPages(ddl)
+	if err := <-lateDone; err != nil {
+		t.Fatalf("late reader failed: %v", err)
+	}
+}
+
+func TestLockTable_UpgradeAndTimeout(t *testing.T) {
+	lm := NewLockManager()
+	lm.SetTableLockTimeout(100 * time.Millisecond)
+	owner, other := primitives.NewTransactionID(), primitives.NewTransactionID()
+	tableID := primitives.FileID(3)
+
+	// The sole reader of a table upgrades without waiting
+	if _, err := lm.LockTable(owner, tableID, false); err != nil {
+		t.Fatalf("shared lock failed: %v", err)
+	}
+	if waited, err := lm.LockTable(owner, tableID, true); err != nil || waited != 0 {
+		t.Fatalf("upgrade: waited %v, err %v", waited, err)
+	}
+
+	start := time.Now()
+	_, err := lm.LockTable(other, tableID, false)
+	if !errors.Is(err, ErrTableLockTimeout) {
+		t.Fatalf("expected ErrTableLockTimeout, got %v", err)
+	}
+	if !dberror.IsRetryable(err) || dberror.SQLState(err) != dberror.SQLStateLockNotAvailable {
+		t.Errorf("lock timeout is not a retryable %s: %v", dberror.SQLStateLockNotAvailable, err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("timed out after %v, want about 100ms", elapsed)
+	}
+	if locks := lm.TableLocks(); len(locks) != 1 || locks[0].TID != owner {
+		t.Errorf("failed request left table locks behind: %+v", locks)
+	}
+}
+
+func TestLockTable_DeadlockWithPageLock(t *testing.T) {
+	lm := NewLockManager()
+	reader, ddl := primitives.NewTransactionID(), primitives.NewTransactionID()
+	tableID := primitives.FileID(3)
+	pid := page.NewPageDescriptor(tableID, 0)
+
+	if _, err := lm.LockTable(reader, tableID, false); err != nil {
+		t.Fatalf("shared lock failed: %v", err)
+	}
+	if err := lm.LockPage(ddl, pid, true); err != nil {
+		t.Fatalf("page lock failed: %v", err)
+	}
+
+	ddlDone := make(chan error, 1)
+	go func() {
+		_, err := lm.LockTable(ddl, tableID, true)
+		ddlDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	readerErr := lm.LockPage(reader, pid, false)
+	if readerErr == nil {
+		t.Fatal("expected the reader to be chosen as the deadlock victim")
+	}
+	if dberror.Code(readerErr) != dberror.ErrCodeDeadlock || !dberror.IsRetryable(readerErr) {
+		t.Errorf("expected a retryable %s, got %v", dberror.ErrCodeDeadlock, readerErr)
+	}
+	lm.UnlockAllPages(reader)
+	if err := <-ddlDone; err != nil {
+		t.Fatalf("exclusive lock after the victim aborted failed: %v", err)
+	}
+}
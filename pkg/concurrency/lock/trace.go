@@ -0,0 +1,240 @@
+package lock
+
+import (
+	"fmt"
+	"slices"
+	"storemy/pkg/primitives"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTraceCapacity is the number of lock events a trace keeps by default.
+const DefaultTraceCapacity = 1000
+
+// EventKind is what happened to a lock request in a LockEvent.
+type EventKind int
+
+const (
+	EventAcquired EventKind = iota // Granted without waiting
+	EventUpgraded                  // A shared lock was upgraded to exclusive
+	EventWaiting                   // The request conflicts with Holders and waits
+	EventGranted                   // Granted after waiting for Waited
+	EventDeadlock                  // Refused because waiting would deadlock
+	EventTimeout                   // Given up after waiting too long
+	EventReleased                  // Count locks were released
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventAcquired:
+		return "ACQUIRED"
+	case EventUpgraded:
+		return "UPGRADED"
+	case EventWaiting:
+		return "WAITING"
+	case EventGranted:
+		return "GRANTED"
+	case EventDeadlock:
+		return "DEADLOCK"
+	case EventTimeout:
+		return "TIMEOUT"
+	case EventReleased:
+		return "RELEASED"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// LockEvent is one step in the life of the locks of a transaction, as
+// recorded by a LockTrace.
+type LockEvent struct {
+	Seq      int64 // Order of the event in the trace, starting at 1
+	Time     time.Time
+	TxID     int64
+	Kind     EventKind
+	PageID   primitives.PageID // Nil for EventReleased events covering several pages
+	LockType LockType
+	Holders  []int64       // For EventWaiting and EventDeadlock: transactions holding conflicting locks
+	Waited   time.Duration // For EventGranted: time spent waiting
+	Count    int           // For EventReleased: number of locks released
+}
+
+// TableNamer returns the name of a table for display, or "" if unknown.
+type TableNamer func(primitives.FileID) string
+
+// String describes the event, e.g. "X lock on 3:0 WAITING for T1", where
+// 3:0 is page 0 of the table with ID 3.
+func (e LockEvent) String() string {
+	return e.Describe(nil)
+}
+
+// Describe is like String but names the table of the page with tableName,
+// e.g. "X lock on ACCOUNTS:0 WAITING for T1". A nil tableName, or one
+// returning "", shows table IDs.
+func (e LockEvent) Describe(tableName TableNamer) string {
+	page := pageName(e.PageID, tableName)
+	switch e.Kind {
+	case EventWaiting, EventDeadlock:
+		holders := make([]string, len(e.Holders))
+		for i, h := range e.Holders {
+			holders[i] = fmt.Sprintf("T%d", h)
+		}
+		verb := "WAITING for"
+		if e.Kind == EventDeadlock {
+			verb = "DEADLOCK with"
+		}
+		return fmt.Sprintf("%s lock on %s %s %s", e.LockType.short(), page, verb, strings.Join(holders, ", "))
+	case EventGranted:
+		return fmt.Sprintf("%s lock on %s GRANTED after %s", e.LockType.short(), page, e.Waited.Round(time.Microsecond))
+	case EventReleased:
+		if e.PageID != nil {
+			return fmt.Sprintf("lock on %s RELEASED", page)
+		}
+		return fmt.Sprintf("RELEASED %d lock(s)", e.Count)
+	default:
+		return fmt.Sprintf("%s lock on %s %s", e.LockType.short(), page, e.Kind)
+	}
+}
+
+// short returns the one-letter lock mode used in timelines.
+func (lt LockType) short() string {
+	if lt == ExclusiveLock {
+		return "X"
+	}
+	return "S"
+}
+
+// pageName renders a page as "table:pageNo", naming the table with
+// tableName if it knows it and by its ID otherwise.
+func pageName(pid primitives.PageID, tableName TableNamer) string {
+	if pid == nil {
+		return "-"
+	}
+	if tableName != nil {
+		if name := tableName(pid.FileID()); name != "" {
+			return fmt.Sprintf("%s:%d", name, pid.PageNo())
+		}
+	}
+	return fmt.Sprintf("%d:%d", pid.FileID(), pid.PageNo())
+}
+
+// LockTrace records lock acquisitions, waits, conflicts and releases in the
+// order they happen, so that the interplay of concurrent transactions can be
+// shown as a timeline. It is a teaching aid: it keeps the latest events in
+// memory and is only active while started with LockManager.StartTrace. It
+// is safe for concurrent use; its methods are no-ops on a nil trace.
+type LockTrace struct {
+	mutex    sync.Mutex
+	started  time.Time
+	events   []LockEvent
+	capacity int
+	seq      int64
+	dropped  int64
+}
+
+// NewLockTrace creates a trace keeping at most capacity events; older events
+// are dropped once it is full. A non-positive capacity uses
+// DefaultTraceCapacity.
+func NewLockTrace(capacity int) *LockTrace {
+	if capacity <= 0 {
+		capacity = DefaultTraceCapacity
+	}
+	return &LockTrace{started: time.Now(), capacity: capacity}
+}
+
+// record appends e, numbering and timestamping it.
+func (t *LockTrace) record(e LockEvent) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.seq++
+	e.Seq = t.seq
+	e.Time = time.Now()
+	if len(t.events) == t.capacity {
+		t.events = slices.Delete(t.events, 0, 1)
+		t.dropped++
+	}
+	t.events = append(t.events, e)
+}
+
+// Started returns when the trace was started.
+func (t *LockTrace) Started() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.started
+}
+
+// Events returns a copy of the recorded events, oldest first.
+func (t *LockTrace) Events() []LockEvent {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return slices.Clone(t.events)
+}
+
+// Dropped returns the number of events discarded because the trace was full.
+func (t *LockTrace) Dropped() int64 {
+	if t == nil {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.dropped
+}
+
+// timelineColumnWidth is the width of each transaction's column in Timeline.
+const timelineColumnWidth = 34
+
+// Timeline renders the recorded events with one column per transaction, in
+// the order the transactions first touched a lock, and one row per event.
+// Tables are named with tableName, which may be nil:
+//
+//	   TIME  T1                                T2
+//	0.000ms  S lock on USERS:0 ACQUIRED
+//	0.120ms                                    X lock on USERS:0 WAITING for T1
+//	3.410ms  RELEASED 1 lock(s)
+//	3.452ms                                    X lock on USERS:0 GRANTED after 3.332ms
+func (t *LockTrace) Timeline(tableName TableNamer) string {
+	events := t.Events()
+	if len(events) == 0 {
+		return "No lock events recorded\n"
+	}
+
+	var columns []int64
+	for _, e := range events {
+		if !slices.Contains(columns, e.TxID) {
+			columns = append(columns, e.TxID)
+		}
+	}
+
+	var sb strings.Builder
+	if dropped := t.Dropped(); dropped > 0 {
+		sb.WriteString(fmt.Sprintf("(%d earlier events dropped)\n", dropped))
+	}
+
+	header := fmt.Sprintf("%10s", "TIME")
+	for _, tx := range columns {
+		header += fmt.Sprintf("  %-*s", timelineColumnWidth, fmt.Sprintf("T%d", tx))
+	}
+	sb.WriteString(strings.TrimRight(header, " "))
+	sb.WriteString("\n")
+
+	for _, e := range events {
+		elapsed := e.Time.Sub(t.Started())
+		sb.WriteString(fmt.Sprintf("%8.3fms", float64(elapsed.Microseconds())/1000))
+		column := slices.Index(columns, e.TxID)
+		sb.WriteString(strings.Repeat(" ", column*(timelineColumnWidth+2)))
+		sb.WriteString("  ")
+		sb.WriteString(e.Describe(tableName))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,96 @@
+package lock
+
+import (
+	"fmt"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockTrace_RecordsConflictTimeline(t *testing.T) {
+	lm := NewLockManager()
+	trace := lm.StartTrace(0)
+
+	t1 := primitives.NewTransactionID()
+	t2 := primitives.NewTransactionID()
+	pid := page.NewPageDescriptor(5, 0)
+
+	if err := lm.LockPage(t1, pid, false); err != nil {
+		t.Fatalf("T1 shared lock: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- lm.LockPage(t2, pid, true) }()
+
+	// Release once T2 is queued behind T1
+	for len(trace.Events()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	lm.UnlockAllPages(t1)
+	if err := <-done; err != nil {
+		t.Fatalf("T2 exclusive lock: %v", err)
+	}
+	lm.UnlockAllPages(t2)
+
+	if stopped := lm.StopTrace(); stopped != trace {
+		t.Fatal("StopTrace did not return the running trace")
+	}
+
+	names := map[int64]string{t1.ID(): "T1", t2.ID(): "T2"}
+	var got []string
+	for _, e := range trace.Events() {
+		got = append(got, names[e.TxID]+" "+e.Kind.String())
+	}
+	want := []string{"T1 ACQUIRED", "T2 WAITING", "T1 RELEASED", "T2 GRANTED", "T2 RELEASED"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+
+	waiting := trace.Events()[1]
+	if len(waiting.Holders) != 1 || waiting.Holders[0] != t1.ID() {
+		t.Errorf("expected T2 to wait for T1, got holders %v", waiting.Holders)
+	}
+	if s := waiting.String(); s != fmt.Sprintf("X lock on 5:0 WAITING for T%d", t1.ID()) {
+		t.Errorf("unexpected description %q", s)
+	}
+
+	timeline := trace.Timeline(nil)
+	lines := strings.Split(strings.TrimSuffix(timeline, "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected a header and 5 rows, got:\n%s", timeline)
+	}
+	if !strings.Contains(lines[0], fmt.Sprintf("T%d", t1.ID())) || !strings.Contains(lines[0], fmt.Sprintf("T%d", t2.ID())) {
+		t.Errorf("expected a column per transaction, got header %q", lines[0])
+	}
+	if strings.Index(lines[2], "X lock") <= strings.Index(lines[1], "S lock") {
+		t.Errorf("expected T2's events in a column right of T1's:\n%s", timeline)
+	}
+}
+
+func TestLockTrace_DropsOldestWhenFull(t *testing.T) {
+	trace := NewLockTrace(2)
+	for i := range 3 {
+		trace.record(LockEvent{TxID: int64(i), Kind: EventAcquired})
+	}
+
+	events := trace.Events()
+	if len(events) != 2 || events[0].Seq != 2 || trace.Dropped() != 1 {
+		t.Fatalf("expected the oldest event to be dropped, got %+v (dropped %d)", events, trace.Dropped())
+	}
+	if !strings.HasPrefix(trace.Timeline(nil), "(1 earlier events dropped)") {
+		t.Errorf("expected the timeline to mention dropped events, got:\n%s", trace.Timeline(nil))
+	}
+}
+
+func TestLockTrace_NilIsNoOp(t *testing.T) {
+	var trace *LockTrace
+	trace.record(LockEvent{Kind: EventAcquired})
+	if len(trace.Events()) != 0 {
+		t.Error("nil trace returned events")
+	}
+	if trace.Timeline(nil) != "No lock events recorded\n" {
+		t.Errorf("unexpected timeline %q", trace.Timeline(nil))
+	}
+}
@@ -1,11 +1,16 @@
 package transaction
 
 import (
+	"errors"
 	"fmt"
 	"maps"
 	"slices"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/execution/tempfile"
+	"storemy/pkg/log/record"
 	"storemy/pkg/log/wal"
 	"storemy/pkg/primitives"
+	"storemy/pkg/tracing"
 	"sync"
 	"time"
 )
@@ -29,6 +34,26 @@ const (
 	ReadWrite
 )
 
+// IsolationLevel controls which anomalies a transaction is protected from.
+//
+// Every level uses strict two-phase page locking, so rows a transaction has
+// read cannot change under it. Serializable additionally locks the key ranges
+// its index scans cover, so another transaction cannot insert a phantom row
+// into a range it has read.
+type IsolationLevel int
+
+const (
+	RepeatableRead IsolationLevel = iota
+	Serializable
+)
+
+func (il IsolationLevel) String() string {
+	if il == Serializable {
+		return "SERIALIZABLE"
+	}
+	return "REPEATABLE READ"
+}
+
 func (ts TransactionStatus) String() string {
 	switch ts {
 	case TxActive:
@@ -46,6 +71,24 @@ func (ts TransactionStatus) String() string {
 	}
 }
 
+// ErrReadOnlyTransaction is returned when a read-only transaction tries to
+// write.
+var ErrReadOnlyTransaction = errors.New("cannot write in a read-only transaction")
+
+// FileOp is a file operation a transaction has logged and performs once it
+// commits.
+type FileOp struct {
+	LSN primitives.LSN // The FILE OP record
+	Op  record.FileOperation
+}
+
+// BulkLoad is an unlogged bulk load a transaction has announced in the WAL.
+// Its pages are zeroed again if the transaction does not commit.
+type BulkLoad struct {
+	LSN  primitives.LSN // The BULK LOAD record
+	Load record.BulkLoad
+}
+
 type TransactionStats struct {
 	PagesRead     int
 	PagesWritten  int
@@ -64,6 +107,8 @@ type TransactionContext struct {
 
 	// Lifecycle state
 	status    TransactionStatus
+	isolation IsolationLevel
+	readOnly  bool // Declared read-only at begin, never written to the WAL
 	startTime time.Time
 	endTime   time.Time
 	mutex     sync.RWMutex
@@ -83,6 +128,15 @@ type TransactionContext struct {
 	undoNextLSN primitives.LSN
 	// Has a BEGIN record been written to WAL?
 	begunInWAL bool
+	// COMMIT record, once the transaction has logged one
+	commitLSN    primitives.LSN
+	hasCommitLSN bool
+	// File deletions and renames deferred until commit
+	fileOps []FileOp
+	// Bulk loads to undo unless the transaction commits
+	bulkLoads []BulkLoad
+	// Savepoint of the running statement, or nil
+	statement *StatementSavepoint
 
 	// Deadlock detection
 	// Pages this transaction is currently waiting to acquire
@@ -94,6 +148,15 @@ type TransactionContext struct {
 	tuplesRead    int
 	tuplesWritten int
 	tuplesDeleted int
+
+	// Tracing: span tree for the query running in this transaction, or nil
+	trace *tracing.Trace
+
+	// Memory accounting for the query running in this transaction, or nil
+	memory *membudget.Tracker
+
+	// Temporary files of the query running in this transaction, or nil
+	tempFiles *tempfile.Query
 }
 
 func NewTransactionContext(tid *primitives.TransactionID) *TransactionContext {
@@ -134,6 +197,28 @@ func (tc *TransactionContext) SetStatus(status TransactionStatus) {
 	}
 }
 
+// IsReadOnly reports whether the transaction was begun read-only.
+func (tc *TransactionContext) IsReadOnly() bool {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.readOnly
+}
+
+// IsolationLevel returns the transaction's isolation level.
+func (tc *TransactionContext) IsolationLevel() IsolationLevel {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.isolation
+}
+
+// SetIsolationLevel changes the isolation level. It should be called before
+// the transaction reads anything; earlier scans keep the level they ran at.
+func (tc *TransactionContext) SetIsolationLevel(level IsolationLevel) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.isolation = level
+}
+
 // RecordPageAccess records that this transaction has accessed a page
 func (tc *TransactionContext) RecordPageAccess(pid primitives.PageID, perm Permissions) {
 	tc.mutex.Lock()
@@ -169,6 +254,36 @@ func (tc *TransactionContext) GetDirtyPages() []primitives.PageID {
 	return slices.Collect(maps.Keys(tc.dirtyPages))
 }
 
+// AddFileOp records a file operation logged at lsn, to perform at commit
+func (tc *TransactionContext) AddFileOp(lsn primitives.LSN, op record.FileOperation) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.fileOps = append(tc.fileOps, FileOp{LSN: lsn, Op: op})
+}
+
+// FileOps returns a copy of the deferred file operations, in the order they
+// were logged
+func (tc *TransactionContext) FileOps() []FileOp {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return slices.Clone(tc.fileOps)
+}
+
+// AddBulkLoad records a bulk load announced at lsn
+func (tc *TransactionContext) AddBulkLoad(lsn primitives.LSN, load record.BulkLoad) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.bulkLoads = append(tc.bulkLoads, BulkLoad{LSN: lsn, Load: load})
+}
+
+// BulkLoads returns a copy of the bulk loads, in the order they were
+// announced
+func (tc *TransactionContext) BulkLoads() []BulkLoad {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return slices.Clone(tc.bulkLoads)
+}
+
 // GetLockedPages returns a copy of all locked pages
 func (tc *TransactionContext) GetLockedPages() []primitives.PageID {
 	tc.mutex.RLock()
@@ -197,12 +312,19 @@ func (tc *TransactionContext) GetWaitingFor() []primitives.PageID {
 	return slices.Clone(tc.waitingFor)
 }
 
-// EnsureBegunInWAL ensures a BEGIN record has been written
+// EnsureBegunInWAL ensures a BEGIN record has been written.
+// Against a read-only WAL no record is written: such transactions can only read,
+// so there is nothing to recover or undo for them. A read-only transaction
+// never begins in the WAL; it gets ErrReadOnlyTransaction, since it only asks
+// to before writing.
 func (tc *TransactionContext) EnsureBegunInWAL(w *wal.WAL) error {
 	tc.mutex.Lock()
 	defer tc.mutex.Unlock()
 
-	if tc.begunInWAL {
+	if tc.readOnly {
+		return ErrReadOnlyTransaction
+	}
+	if tc.begunInWAL || w.IsReadOnly() {
 		return nil
 	}
 
@@ -242,6 +364,22 @@ func (tc *TransactionContext) GetFirstLSN() primitives.LSN {
 	return tc.firstLSN
 }
 
+// SetCommitLSN records the LSN of the transaction's COMMIT record
+func (tc *TransactionContext) SetCommitLSN(lsn primitives.LSN) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.commitLSN = lsn
+	tc.hasCommitLSN = true
+}
+
+// CommitLSN returns the LSN of the transaction's COMMIT record, and false if
+// none was logged (the transaction changed nothing, or has not committed)
+func (tc *TransactionContext) CommitLSN() (primitives.LSN, bool) {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.commitLSN, tc.hasCommitLSN
+}
+
 // Statistics methods
 
 // RecordTupleRead increments the tuples read counter
@@ -288,6 +426,13 @@ func (tc *TransactionContext) GetStatistics() TransactionStats {
 	}
 }
 
+// StartTime returns when the transaction began
+func (tc *TransactionContext) StartTime() time.Time {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.startTime
+}
+
 // Duration returns how long the transaction has been running
 func (tc *TransactionContext) Duration() time.Duration {
 	tc.mutex.RLock()
@@ -300,6 +445,62 @@ func (tc *TransactionContext) Duration() time.Duration {
 	return endTime.Sub(tc.startTime)
 }
 
+// SetTrace attaches the trace that records this transaction's work.
+func (tc *TransactionContext) SetTrace(t *tracing.Trace) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.trace = t
+}
+
+// Trace returns the attached trace, or nil if the transaction is not traced.
+// It is safe to call on a nil context.
+func (tc *TransactionContext) Trace() *tracing.Trace {
+	if tc == nil {
+		return nil
+	}
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.trace
+}
+
+// SetMemoryTracker attaches the tracker that accounts for the memory held
+// by the query running in this transaction.
+func (tc *TransactionContext) SetMemoryTracker(t *membudget.Tracker) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.memory = t
+}
+
+// MemoryTracker returns the attached memory tracker, or nil if the query's
+// memory is not accounted. It is safe to call on a nil context.
+func (tc *TransactionContext) MemoryTracker() *membudget.Tracker {
+	if tc == nil {
+		return nil
+	}
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.memory
+}
+
+// SetTempFiles attaches the handle through which the query running in this
+// transaction creates temporary files.
+func (tc *TransactionContext) SetTempFiles(q *tempfile.Query) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.tempFiles = q
+}
+
+// TempFiles returns the attached temporary file handle, or nil if the
+// query cannot spill to disk. It is safe to call on a nil context.
+func (tc *TransactionContext) TempFiles() *tempfile.Query {
+	if tc == nil {
+		return nil
+	}
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.tempFiles
+}
+
 // String returns a string representation of the transaction context
 func (tc *TransactionContext) String() string {
 	tc.mutex.RLock()
@@ -44,6 +44,24 @@ func (tr *TransactionRegistry) Begin() (*TransactionContext, error) {
 	return ctx, nil
 }
 
+// BeginReadOnly creates a read-only transaction context and registers it.
+// A read-only transaction only takes shared locks and writes nothing to the
+// WAL: no BEGIN and no COMMIT record. It never enters the WAL's table of
+// active transactions, so checkpoints do not list it and recovery has
+// nothing to undo for it. Requests to write, including lock upgrades, fail
+// with ErrReadOnlyTransaction.
+func (tr *TransactionRegistry) BeginReadOnly() (*TransactionContext, error) {
+	tid := primitives.NewTransactionID()
+	ctx := NewTransactionContext(tid)
+	ctx.readOnly = true
+
+	tr.mutex.Lock()
+	tr.contexts[tid] = ctx
+	tr.mutex.Unlock()
+
+	return ctx, nil
+}
+
 // Get retrieves a transaction context by ID
 func (tr *TransactionRegistry) Get(tid *primitives.TransactionID) (*TransactionContext, error) {
 	tr.mutex.RLock()
@@ -0,0 +1,138 @@
+package transaction
+
+import (
+	"maps"
+	"slices"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"sync"
+)
+
+// StatementSavepoint marks the start of a statement inside a transaction, so
+// that a failed statement can be rolled back without aborting the whole
+// transaction. It keeps a copy of every page the statement asked to write,
+// taken before the statement first did so, and the rows it reserved or
+// released against a quota.
+type StatementSavepoint struct {
+	// Last log record the transaction wrote before the statement, 0 if none
+	LSN primitives.LSN
+
+	mutex     sync.Mutex
+	pages     map[primitives.PageKey]page.Page // Page images at the savepoint
+	order     []primitives.PageKey             // Keys of pages, in the order they were saved
+	rows      map[primitives.FileID]int64      // Rows reserved (positive) or released (negative) per table
+	fileOps   int                              // Deferred file operations logged before the statement
+	bulkLoads int                              // Bulk loads announced before the statement
+	lost      bool                             // A page could not be copied, so the savepoint cannot be restored
+}
+
+// SavePage remembers pg as it is now, unless the savepoint already holds an
+// earlier image of it. It is called before the statement writes pg.
+func (sp *StatementSavepoint) SavePage(pg page.Page) {
+	key := primitives.KeyOf(pg.GetID())
+
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+	if _, saved := sp.pages[key]; saved {
+		return
+	}
+
+	cp := pg.Copy()
+	if cp == nil {
+		sp.lost = true
+		return
+	}
+	sp.pages[key] = cp
+	sp.order = append(sp.order, key)
+}
+
+// Pages returns the saved page images, in the order they were saved, and
+// whether every page the statement asked to write could be saved.
+func (sp *StatementSavepoint) Pages() ([]page.Page, bool) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	pages := make([]page.Page, 0, len(sp.order))
+	for _, key := range sp.order {
+		pages = append(pages, sp.pages[key])
+	}
+	return pages, !sp.lost
+}
+
+// AddRows records that the statement reserved n rows of table id, or
+// released them if n is negative.
+func (sp *StatementSavepoint) AddRows(id primitives.FileID, n int64) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+	if sp.rows == nil {
+		sp.rows = make(map[primitives.FileID]int64)
+	}
+	sp.rows[id] += n
+}
+
+// Rows returns the rows the statement reserved or released, per table.
+func (sp *StatementSavepoint) Rows() map[primitives.FileID]int64 {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+	return maps.Clone(sp.rows)
+}
+
+// BeginStatement sets a savepoint for the statement about to run, replacing
+// any earlier one. lsn is the last log record the transaction has written.
+func (tc *TransactionContext) BeginStatement(lsn primitives.LSN) *StatementSavepoint {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	tc.statement = &StatementSavepoint{
+		LSN:       lsn,
+		pages:     make(map[primitives.PageKey]page.Page),
+		fileOps:   len(tc.fileOps),
+		bulkLoads: len(tc.bulkLoads),
+	}
+	return tc.statement
+}
+
+// Statement returns the savepoint of the running statement, or nil if none
+// was set. It is safe to call on a nil context.
+func (tc *TransactionContext) Statement() *StatementSavepoint {
+	if tc == nil {
+		return nil
+	}
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	return tc.statement
+}
+
+// EndStatement releases the savepoint of the running statement.
+func (tc *TransactionContext) EndStatement() {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	tc.statement = nil
+}
+
+// DiscardFileOpsSince forgets the deferred file operations logged after sp
+// was set and returns them.
+func (tc *TransactionContext) DiscardFileOpsSince(sp *StatementSavepoint) []FileOp {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	if sp.fileOps >= len(tc.fileOps) {
+		return nil
+	}
+	discarded := slices.Clone(tc.fileOps[sp.fileOps:])
+	tc.fileOps = tc.fileOps[:sp.fileOps]
+	return discarded
+}
+
+// BulkLoadsSince returns the bulk loads announced after sp was set. They stay
+// recorded: their BULK LOAD records are in the log, so the transaction must
+// still end with a COMMIT or ABORT record for recovery to settle them.
+func (tc *TransactionContext) BulkLoadsSince(sp *StatementSavepoint) []BulkLoad {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+
+	if sp.bulkLoads >= len(tc.bulkLoads) {
+		return nil
+	}
+	return slices.Clone(tc.bulkLoads[sp.bulkLoads:])
+}
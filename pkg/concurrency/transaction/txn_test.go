@@ -523,6 +523,37 @@ func TestTransactionRegistry_Begin(t *testing.T) {
 	}
 }
 
+// TestTransactionRegistry_BeginReadOnly tests that a read-only transaction
+// writes nothing to the WAL and cannot begin in it later
+func TestTransactionRegistry_BeginReadOnly(t *testing.T) {
+	w, _ := createTestWAL(t)
+	defer w.Close()
+
+	registry := NewTransactionRegistry(w)
+	before := w.CurrentLSN()
+
+	ctx, err := registry.BeginReadOnly()
+	if err != nil {
+		t.Fatalf("Failed to begin read-only transaction: %v", err)
+	}
+	if !ctx.IsReadOnly() || !ctx.IsActive() {
+		t.Error("Expected an active read-only transaction")
+	}
+	if registry.Count() != 1 {
+		t.Errorf("Expected registry count to be 1, got %d", registry.Count())
+	}
+	if w.CurrentLSN() != before {
+		t.Errorf("Expected no WAL record, LSN moved from %d to %d", before, w.CurrentLSN())
+	}
+
+	if err := ctx.EnsureBegunInWAL(w); err != ErrReadOnlyTransaction {
+		t.Errorf("Expected ErrReadOnlyTransaction, got %v", err)
+	}
+	if w.CurrentLSN() != before {
+		t.Errorf("Expected no WAL record after EnsureBegunInWAL, LSN moved to %d", w.CurrentLSN())
+	}
+}
+
 // TestTransactionRegistry_Get tests retrieving a transaction
 func TestTransactionRegistry_Get(t *testing.T) {
 	wal, _ := createTestWAL(t)
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	dberror "storemy/pkg/error"
+)
+
+// Error codes for configuration and superblock failures
+const (
+	// ErrCodeUnknownSetting indicates the setting name does not exist
+	ErrCodeUnknownSetting = "UNKNOWN_SETTING"
+
+	// ErrCodeInvalidSetting indicates the value is not valid for the setting
+	ErrCodeInvalidSetting = "INVALID_SETTING"
+
+	// ErrCodeReadOnlySetting indicates the setting cannot be changed after creation
+	ErrCodeReadOnlySetting = "READ_ONLY_SETTING"
+
+	// ErrCodeCorruptSuperblock indicates the superblock failed validation on open
+	ErrCodeCorruptSuperblock = "CORRUPT_SUPERBLOCK"
+)
+
+// NewUnknownSettingError creates a DBError for a setting name that does not exist.
+func NewUnknownSettingError(name string) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryUser,
+		ErrCodeUnknownSetting,
+		fmt.Sprintf("unknown setting '%s'", name),
+	)
+	err.Hint = "Use SHOW PERSISTENT to list available settings"
+	err.Component = "Config"
+	return err
+}
+
+// NewInvalidSettingError creates a DBError for a value rejected by a setting.
+func NewInvalidSettingError(name, value string, cause error) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryUser,
+		ErrCodeInvalidSetting,
+		fmt.Sprintf("invalid value '%s' for setting '%s'", value, name),
+	)
+	err.Detail = cause.Error()
+	err.Component = "Config"
+	return err
+}
+
+// NewReadOnlySettingError creates a DBError for an attempt to change a fixed setting.
+func NewReadOnlySettingError(name string) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryUser,
+		ErrCodeReadOnlySetting,
+		fmt.Sprintf("setting '%s' cannot be changed", name),
+	)
+	err.Detail = fmt.Sprintf("'%s' is fixed when the database is created", name)
+	err.Component = "Config"
+	return err
+}
+
+// NewCorruptSuperblockError creates a DBError for a superblock that failed validation.
+func NewCorruptSuperblockError(path string, cause error) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryData,
+		ErrCodeCorruptSuperblock,
+		"superblock validation failed",
+	)
+	err.Detail = fmt.Sprintf("%s: %v", path, cause)
+	err.Hint = "The superblock may be corrupted or written by an incompatible version. Restore it from backup"
+	err.Component = "Config"
+	err.Operation = "Open"
+	return err
+}
@@ -0,0 +1,577 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"storemy/pkg/catalog"
+	"storemy/pkg/encryption"
+	"storemy/pkg/log/record"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/optimizer"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings holds the database-wide parameters persisted in the superblock.
+type Settings struct {
+	// PageSize is the on-disk page size the database was created with.
+	// It is fixed at creation time and must match the compiled page size.
+	PageSize int
+
+	// WALBufferSize is the size of the in-memory WAL write buffer in bytes.
+	WALBufferSize int
+
+	// WALDurability is whether commits wait for their commit record to be
+	// flushed (see wal.Durability).
+	WALDurability wal.Durability
+
+	// WALSyncInterval is how often the log is flushed under
+	// wal.DurabilityInterval (see wal.WAL.SetSyncInterval).
+	WALSyncInterval time.Duration
+
+	// WALCommitWindow is how long a synchronous commit waits for other
+	// commits to share its log force (see wal.WAL.SetCommitWindow).
+	WALCommitWindow time.Duration
+
+	// WALCompression is how UPDATE records compress their page images
+	// (see wal.WAL.SetCompression).
+	WALCompression record.Compression
+
+	// SyncPolicy is how the WAL and the page files make writes durable
+	// (see vfs.SyncPolicy).
+	SyncPolicy vfs.SyncPolicy
+
+	// DirectIO is whether the WAL and the page files bypass the OS page
+	// cache where the file system supports it (see vfs.ODirect).
+	DirectIO bool
+
+	// Checkpoint triggering behavior (see wal.CheckpointConfig)
+	CheckpointInterval        time.Duration
+	CheckpointMaxWALSize      int64
+	CheckpointMaxTransactions int64
+	CheckpointEnabled         bool
+
+	// Automatic statistics refresh behavior (see catalog.AutoAnalyzeConfig)
+	AutoAnalyzeEnabled    bool
+	AutoAnalyzeInterval   time.Duration
+	AutoAnalyzeFraction   float64
+	AutoAnalyzeMinChanges int64
+
+	// MaxRecursiveIterations is how many times a recursive query (WITH
+	// RECURSIVE) may evaluate its recursive term before it fails, which
+	// stops queries over cyclic data that never reach a fixed point.
+	MaxRecursiveIterations int64
+
+	// Unit costs of the optimizer's cost model (see optimizer.CostParameters)
+	SeqPageCost       float64
+	RandomPageCost    float64
+	CPUTupleCost      float64
+	CPUIndexTupleCost float64
+	CPUOperatorCost   float64
+
+	// TimeTravelRetention is how far into the past a query may read a table
+	// with AS OF. Zero disables time travel queries.
+	TimeTravelRetention time.Duration
+
+	// Encryption is whether the database is encrypted at rest. It is fixed
+	// when the database is created and cannot be changed with SET.
+	Encryption Encryption
+}
+
+// Encryption describes how a database is encrypted at rest.
+type Encryption struct {
+	// Enabled is whether pages and WAL records are encrypted.
+	Enabled bool
+
+	// KeyID is the key new data is encrypted with; data written before a
+	// key rotation may still need older keys.
+	KeyID uint32
+
+	// KeyCheck is a known value sealed with KeyID (see encryption.Cipher.KeyCheck),
+	// which tells a wrong key from a corrupt database on open.
+	KeyCheck [encryption.CheckSize]byte
+}
+
+// DefaultSettings returns the settings used when a database is created.
+func DefaultSettings() Settings {
+	cp := wal.DefaultCheckpointConfig()
+	aa := catalog.DefaultAutoAnalyzeConfig()
+	cost := optimizer.DefaultCostParameters()
+	return Settings{
+		PageSize:                  page.PageSize,
+		WALBufferSize:             8192,
+		WALDurability:             wal.DurabilitySync,
+		WALSyncInterval:           wal.DefaultSyncInterval,
+		SyncPolicy:                vfs.SyncOSync,
+		CheckpointInterval:        cp.Interval,
+		CheckpointMaxWALSize:      cp.MaxWALSize,
+		CheckpointMaxTransactions: cp.MaxTransactions,
+		CheckpointEnabled:         cp.Enabled,
+		AutoAnalyzeEnabled:        aa.Enabled,
+		AutoAnalyzeInterval:       aa.Interval,
+		AutoAnalyzeFraction:       aa.Fraction,
+		AutoAnalyzeMinChanges:     int64(aa.MinChanges),
+		MaxRecursiveIterations:    DefaultMaxRecursiveIterations,
+		SeqPageCost:               cost.SeqPageCost,
+		RandomPageCost:            cost.RandomPageCost,
+		CPUTupleCost:              cost.CPUTupleCost,
+		CPUIndexTupleCost:         cost.CPUIndexTupleCost,
+		CPUOperatorCost:           cost.CPUOperatorCost,
+		TimeTravelRetention:       DefaultTimeTravelRetention,
+	}
+}
+
+// CheckpointConfig converts the checkpoint settings into a wal.CheckpointConfig.
+func (s Settings) CheckpointConfig() wal.CheckpointConfig {
+	return wal.CheckpointConfig{
+		Interval:        s.CheckpointInterval,
+		MaxWALSize:      s.CheckpointMaxWALSize,
+		MaxTransactions: s.CheckpointMaxTransactions,
+		Enabled:         s.CheckpointEnabled,
+	}
+}
+
+// AutoAnalyzeConfig converts the auto-analyze settings into a catalog.AutoAnalyzeConfig.
+func (s Settings) AutoAnalyzeConfig() catalog.AutoAnalyzeConfig {
+	return catalog.AutoAnalyzeConfig{
+		Enabled:    s.AutoAnalyzeEnabled,
+		Interval:   s.AutoAnalyzeInterval,
+		Fraction:   s.AutoAnalyzeFraction,
+		MinChanges: int(s.AutoAnalyzeMinChanges),
+	}
+}
+
+// CostParameters converts the cost settings into optimizer.CostParameters.
+func (s Settings) CostParameters() optimizer.CostParameters {
+	return optimizer.CostParameters{
+		SeqPageCost:       s.SeqPageCost,
+		RandomPageCost:    s.RandomPageCost,
+		CPUTupleCost:      s.CPUTupleCost,
+		CPUIndexTupleCost: s.CPUIndexTupleCost,
+		CPUOperatorCost:   s.CPUOperatorCost,
+	}
+}
+
+// Validate checks that every setting is within its allowed range.
+func (s Settings) Validate() error {
+	if s.PageSize != page.PageSize {
+		return fmt.Errorf("page size %d does not match compiled page size %d", s.PageSize, page.PageSize)
+	}
+	if s.WALBufferSize < minWALBufferSize {
+		return fmt.Errorf("wal buffer size %d is below minimum %d", s.WALBufferSize, minWALBufferSize)
+	}
+	if s.WALDurability > wal.DurabilityNoSync {
+		return fmt.Errorf("unknown wal durability level %d", s.WALDurability)
+	}
+	if s.WALSyncInterval <= 0 || s.WALSyncInterval > wal.MaxSyncInterval {
+		return fmt.Errorf("wal sync interval must be greater than 0 and at most %s, got %s", wal.MaxSyncInterval, s.WALSyncInterval)
+	}
+	if s.WALCommitWindow < 0 || s.WALCommitWindow > wal.MaxCommitWindow {
+		return fmt.Errorf("wal commit window must be between 0 and %s, got %s", wal.MaxCommitWindow, s.WALCommitWindow)
+	}
+	if s.WALCompression > record.CompressionDeflate {
+		return fmt.Errorf("unknown wal compression %d", s.WALCompression)
+	}
+	if s.SyncPolicy > vfs.SyncFdatasync {
+		return fmt.Errorf("unknown sync policy %d", s.SyncPolicy)
+	}
+	if s.CheckpointInterval <= 0 {
+		return fmt.Errorf("checkpoint interval must be positive, got %s", s.CheckpointInterval)
+	}
+	if s.CheckpointMaxWALSize <= 0 {
+		return fmt.Errorf("checkpoint max wal size must be positive, got %d", s.CheckpointMaxWALSize)
+	}
+	if s.CheckpointMaxTransactions <= 0 {
+		return fmt.Errorf("checkpoint max transactions must be positive, got %d", s.CheckpointMaxTransactions)
+	}
+	if s.AutoAnalyzeInterval <= 0 {
+		return fmt.Errorf("auto-analyze interval must be positive, got %s", s.AutoAnalyzeInterval)
+	}
+	if s.AutoAnalyzeFraction < 0 || s.AutoAnalyzeFraction > maxAutoAnalyzeFraction {
+		return fmt.Errorf("auto-analyze fraction must be between 0 and %g, got %g", float64(maxAutoAnalyzeFraction), s.AutoAnalyzeFraction)
+	}
+	if s.AutoAnalyzeMinChanges < 1 {
+		return fmt.Errorf("auto-analyze min changes must be at least 1, got %d", s.AutoAnalyzeMinChanges)
+	}
+	if s.MaxRecursiveIterations < 1 {
+		return fmt.Errorf("max recursive iterations must be at least 1, got %d", s.MaxRecursiveIterations)
+	}
+	if s.TimeTravelRetention < 0 {
+		return fmt.Errorf("time travel retention must not be negative, got %s", s.TimeTravelRetention)
+	}
+	for name, def := range settingDefs {
+		if def.cost == nil {
+			continue
+		}
+		if v := *def.cost(&s); v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%s must be a non-negative number, got %g", name, v)
+		}
+	}
+	return nil
+}
+
+const (
+	minWALBufferSize       = 512
+	maxAutoAnalyzeFraction = 100
+
+	// DefaultMaxRecursiveIterations is the default MaxRecursiveIterations.
+	DefaultMaxRecursiveIterations = 1000
+
+	// DefaultTimeTravelRetention is the default TimeTravelRetention.
+	DefaultTimeTravelRetention = time.Hour
+)
+
+// Setting describes a single named setting and its current value.
+type Setting struct {
+	Name            string
+	Value           string
+	Description     string
+	ReadOnly        bool // Cannot be changed after the database is created
+	RequiresRestart bool // Takes effect the next time the database is opened
+}
+
+// settingDef binds a setting name to accessors on Settings.
+type settingDef struct {
+	description     string
+	readOnly        bool
+	requiresRestart bool
+	get             func(s *Settings) string
+	set             func(s *Settings, value string) error
+	cost            func(s *Settings) *float64 // Set for the cost model's unit costs
+}
+
+// costSetting defines a unit cost of the cost model, the field of Settings
+// that field returns.
+func costSetting(description string, field func(s *Settings) *float64) settingDef {
+	return settingDef{
+		description: description,
+		cost:        field,
+		get:         func(s *Settings) string { return strconv.FormatFloat(*field(s), 'g', -1, 64) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number value: %s", value)
+			}
+			*field(s) = v
+			return nil
+		},
+	}
+}
+
+var settingDefs = map[string]settingDef{
+	"page_size": {
+		description: "Size of a data page in bytes",
+		readOnly:    true,
+		get:         func(s *Settings) string { return strconv.Itoa(s.PageSize) },
+	},
+	"wal_buffer_size": {
+		description:     "Size of the WAL write buffer in bytes",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.Itoa(s.WALBufferSize) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid integer value: %s", value)
+			}
+			s.WALBufferSize = v
+			return nil
+		},
+	},
+	"wal_durability": {
+		description:     "Whether commits wait for the WAL to reach disk (sync), return once buffered (async), return once buffered with the WAL flushed every wal_sync_interval (interval) or write the WAL without syncing it (nosync)",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return s.WALDurability.String() },
+		set: func(s *Settings, value string) error {
+			v, err := wal.ParseDurability(value)
+			if err != nil {
+				return err
+			}
+			s.WALDurability = v
+			return nil
+		},
+	},
+	"wal_sync_interval": {
+		description:     "How often the WAL is flushed when wal_durability is interval (e.g. 100ms)",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return s.WALSyncInterval.String() },
+		set: func(s *Settings, value string) error {
+			v, err := time.ParseDuration(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid duration value: %s", value)
+			}
+			s.WALSyncInterval = v
+			return nil
+		},
+	},
+	"wal_commit_window": {
+		description:     "How long a synchronous commit waits for concurrent commits to share its WAL sync (e.g. 2ms); 0 syncs at once",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return s.WALCommitWindow.String() },
+		set: func(s *Settings, value string) error {
+			v, err := time.ParseDuration(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid duration value: %s", value)
+			}
+			s.WALCommitWindow = v
+			return nil
+		},
+	},
+	"wal_compression": {
+		description:     "How UPDATE records compress their page images in the WAL: not at all (none) or with DEFLATE (deflate)",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return s.WALCompression.String() },
+		set: func(s *Settings, value string) error {
+			v, err := record.ParseCompression(value)
+			if err != nil {
+				return err
+			}
+			s.WALCompression = v
+			return nil
+		},
+	},
+	"sync_policy": {
+		description:     "How the WAL and data files make writes durable: O_SYNC on every write (osync), or one fsync (fsync) or fdatasync (fdatasync) per batch",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return s.SyncPolicy.String() },
+		set: func(s *Settings, value string) error {
+			v, err := vfs.ParseSyncPolicy(value)
+			if err != nil {
+				return err
+			}
+			s.SyncPolicy = v
+			return nil
+		},
+	},
+	"direct_io": {
+		description:     "Whether the WAL and the table files bypass the OS page cache with O_DIRECT where the file system supports it",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.FormatBool(s.DirectIO) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseBool(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid boolean value: %s", value)
+			}
+			s.DirectIO = v
+			return nil
+		},
+	},
+
+	"checkpoint_interval": {
+		description:     "Time between automatic checkpoints (e.g. 30s, 10m)",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return s.CheckpointInterval.String() },
+		set: func(s *Settings, value string) error {
+			v, err := time.ParseDuration(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid duration value: %s", value)
+			}
+			s.CheckpointInterval = v
+			return nil
+		},
+	},
+	"checkpoint_max_wal_size": {
+		description:     "WAL size in bytes that triggers a checkpoint",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.FormatInt(s.CheckpointMaxWALSize, 10) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer value: %s", value)
+			}
+			s.CheckpointMaxWALSize = v
+			return nil
+		},
+	},
+	"checkpoint_max_transactions": {
+		description:     "Number of commits that triggers a checkpoint",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.FormatInt(s.CheckpointMaxTransactions, 10) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer value: %s", value)
+			}
+			s.CheckpointMaxTransactions = v
+			return nil
+		},
+	},
+	"checkpoint_enabled": {
+		description:     "Whether automatic checkpointing is enabled",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.FormatBool(s.CheckpointEnabled) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseBool(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid boolean value: %s", value)
+			}
+			s.CheckpointEnabled = v
+			return nil
+		},
+	},
+	"auto_analyze_enabled": {
+		description:     "Whether statistics are refreshed automatically in the background",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.FormatBool(s.AutoAnalyzeEnabled) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseBool(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid boolean value: %s", value)
+			}
+			s.AutoAnalyzeEnabled = v
+			return nil
+		},
+	},
+	"auto_analyze_interval": {
+		description:     "Time between checks for tables that need new statistics (e.g. 30s, 5m)",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return s.AutoAnalyzeInterval.String() },
+		set: func(s *Settings, value string) error {
+			v, err := time.ParseDuration(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid duration value: %s", value)
+			}
+			s.AutoAnalyzeInterval = v
+			return nil
+		},
+	},
+	"auto_analyze_fraction": {
+		description:     "Fraction of a table's rows that must change before it is re-analyzed",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.FormatFloat(s.AutoAnalyzeFraction, 'g', -1, 64) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number value: %s", value)
+			}
+			s.AutoAnalyzeFraction = v
+			return nil
+		},
+	},
+	"auto_analyze_min_changes": {
+		description:     "Changed rows required before a table is re-analyzed, added to the fraction",
+		requiresRestart: true,
+		get:             func(s *Settings) string { return strconv.FormatInt(s.AutoAnalyzeMinChanges, 10) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer value: %s", value)
+			}
+			s.AutoAnalyzeMinChanges = v
+			return nil
+		},
+	},
+	"max_recursive_iterations": {
+		description: "Times a WITH RECURSIVE query may evaluate its recursive term before it fails",
+		get:         func(s *Settings) string { return strconv.FormatInt(s.MaxRecursiveIterations, 10) },
+		set: func(s *Settings, value string) error {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer value: %s", value)
+			}
+			s.MaxRecursiveIterations = v
+			return nil
+		},
+	},
+	"time_travel_retention": {
+		description: "How far back SELECT ... AS OF may read a table's past state (e.g. 30m, 24h); 0 disables it",
+		get:         func(s *Settings) string { return s.TimeTravelRetention.String() },
+		set: func(s *Settings, value string) error {
+			v, err := time.ParseDuration(strings.ToLower(value))
+			if err != nil {
+				return fmt.Errorf("invalid duration value: %s", value)
+			}
+			s.TimeTravelRetention = v
+			return nil
+		},
+	},
+	"seq_page_cost": costSetting(
+		"Optimizer cost of reading a page as part of a sequential scan",
+		func(s *Settings) *float64 { return &s.SeqPageCost },
+	),
+	"random_page_cost": costSetting(
+		"Optimizer cost of reading a page at a random position, as index lookups do",
+		func(s *Settings) *float64 { return &s.RandomPageCost },
+	),
+	"cpu_tuple_cost": costSetting(
+		"Optimizer cost of processing a row",
+		func(s *Settings) *float64 { return &s.CPUTupleCost },
+	),
+	"cpu_index_tuple_cost": costSetting(
+		"Optimizer cost of processing an index entry",
+		func(s *Settings) *float64 { return &s.CPUIndexTupleCost },
+	),
+	"cpu_operator_cost": costSetting(
+		"Optimizer cost of evaluating a predicate on a row",
+		func(s *Settings) *float64 { return &s.CPUOperatorCost },
+	),
+}
+
+// lookupSetting finds a setting definition by case-insensitive name.
+func lookupSetting(name string) (string, settingDef, error) {
+	key := strings.ToLower(name)
+	def, ok := settingDefs[key]
+	if !ok {
+		return "", settingDef{}, NewUnknownSettingError(name)
+	}
+	return key, def, nil
+}
+
+// Get returns the current value of the named setting.
+func (s Settings) Get(name string) (Setting, error) {
+	key, def, err := lookupSetting(name)
+	if err != nil {
+		return Setting{}, err
+	}
+	return def.describe(key, &s), nil
+}
+
+// With returns a copy of s with the named setting changed to value.
+// The result is validated as a whole, so s itself is never modified.
+func (s Settings) With(name, value string) (Settings, error) {
+	key, def, err := lookupSetting(name)
+	if err != nil {
+		return s, err
+	}
+	if def.readOnly {
+		return s, NewReadOnlySettingError(key)
+	}
+
+	updated := s
+	if err := def.set(&updated, value); err != nil {
+		return s, NewInvalidSettingError(key, value, err)
+	}
+	if err := updated.Validate(); err != nil {
+		return s, NewInvalidSettingError(key, value, err)
+	}
+	return updated, nil
+}
+
+// All returns every setting sorted by name.
+func (s Settings) All() []Setting {
+	names := make([]string, 0, len(settingDefs))
+	for name := range settingDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	all := make([]Setting, 0, len(names))
+	for _, name := range names {
+		def := settingDefs[name]
+		all = append(all, def.describe(name, &s))
+	}
+	return all
+}
+
+func (def settingDef) describe(name string, s *Settings) Setting {
+	return Setting{
+		Name:            name,
+		Value:           def.get(s),
+		Description:     def.description,
+		ReadOnly:        def.readOnly,
+		RequiresRestart: def.requiresRestart,
+	}
+}
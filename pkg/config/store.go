@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrReadOnly is returned by Set when the store was opened read-only.
+var ErrReadOnly = errors.New("configuration store is opened in read-only mode")
+
+// Store owns the superblock of a database directory. Reads are served from
+// memory; every change is validated and written atomically before it becomes
+// visible, so the file on disk always holds a complete, valid superblock.
+type Store struct {
+	path     string
+	readOnly bool
+	created  bool
+	settings Settings
+	mutex    sync.RWMutex
+}
+
+// Open loads the superblock from dir, creating it with DefaultSettings if it does
+// not exist yet. With readOnly set nothing is ever written: a missing superblock
+// yields the default settings and Set returns ErrReadOnly.
+func Open(dir string, readOnly bool) (*Store, error) {
+	path := filepath.Join(dir, SuperblockFile)
+	store := &Store{
+		path:     path,
+		readOnly: readOnly,
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		settings, err := DecodeSuperblock(data)
+		if err != nil {
+			return nil, NewCorruptSuperblockError(path, err)
+		}
+		store.settings = settings
+
+	case os.IsNotExist(err):
+		store.settings = DefaultSettings()
+		if !readOnly {
+			if err := writeFileAtomic(path, EncodeSuperblock(store.settings)); err != nil {
+				return nil, fmt.Errorf("failed to create superblock: %w", err)
+			}
+			store.created = true
+		}
+
+	default:
+		return nil, fmt.Errorf("failed to read superblock: %w", err)
+	}
+
+	return store, nil
+}
+
+// Path returns the location of the superblock file.
+func (st *Store) Path() string {
+	return st.path
+}
+
+// Created reports whether Open created the superblock, i.e. whether the
+// database is new.
+func (st *Store) Created() bool {
+	return st.created
+}
+
+// Settings returns a snapshot of the current settings.
+func (st *Store) Settings() Settings {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+	return st.settings
+}
+
+// Get returns the current value of the named setting.
+func (st *Store) Get(name string) (Setting, error) {
+	return st.Settings().Get(name)
+}
+
+// All returns every setting sorted by name.
+func (st *Store) All() []Setting {
+	return st.Settings().All()
+}
+
+// Set validates and persists a new value for the named setting.
+// The in-memory settings only change once the superblock has been durably written.
+func (st *Store) Set(name, value string) (Setting, error) {
+	if st.readOnly {
+		return Setting{}, ErrReadOnly
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	updated, err := st.settings.With(name, value)
+	if err != nil {
+		return Setting{}, err
+	}
+
+	if err := writeFileAtomic(st.path, EncodeSuperblock(updated)); err != nil {
+		return Setting{}, fmt.Errorf("failed to write superblock: %w", err)
+	}
+
+	st.settings = updated
+	return updated.Get(name)
+}
+
+// SetEncryption persists how the database is encrypted at rest. Unlike the
+// settings changed with Set, it is only written when a database is created
+// and when its key is rotated.
+func (st *Store) SetEncryption(e Encryption) error {
+	if st.readOnly {
+		return ErrReadOnly
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	updated := st.settings
+	updated.Encryption = e
+	if err := writeFileAtomic(st.path, EncodeSuperblock(updated)); err != nil {
+		return fmt.Errorf("failed to write superblock: %w", err)
+	}
+
+	st.settings = updated
+	return nil
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"storemy/pkg/encryption"
+	"storemy/pkg/log/record"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/vfs"
+	"time"
+)
+
+const (
+	// SuperblockFile is the name of the superblock inside the database directory
+	SuperblockFile = "superblock.dat"
+
+	// SuperblockVersion is the current on-disk format version
+	SuperblockVersion uint16 = 1
+
+	superblockMagic = "SMSB"
+
+	// Header: Magic(4) + Version(2) + Reserved(2) + PayloadLen(4)
+	superblockHeaderSize = 12
+	superblockCRCSize    = 4
+
+	// Payload: PageSize(4) + WALBufferSize(4) + Interval(8) + MaxWALSize(8) + MaxTxns(8) + Enabled(1)
+	superblockBasePayloadSize = 33
+
+	// Auto-analyze extension: Enabled(1) + Interval(8) + Fraction(8) + MinChanges(8).
+	// Superblocks written before it existed end after the base payload and
+	// decode with the default auto-analyze settings.
+	superblockAutoAnalyzePayloadSize = superblockBasePayloadSize + 25
+
+	// Durability extension: WALDurability(1). Older superblocks decode with
+	// synchronous commits.
+	superblockDurabilityPayloadSize = superblockAutoAnalyzePayloadSize + 1
+
+	// Sync policy extension: SyncPolicy(1). Older superblocks decode with
+	// O_SYNC writes.
+	superblockSyncPolicyPayloadSize = superblockDurabilityPayloadSize + 1
+
+	// Recursion extension: MaxRecursiveIterations(8). Older superblocks
+	// decode with DefaultMaxRecursiveIterations.
+	superblockRecursionPayloadSize = superblockSyncPolicyPayloadSize + 8
+
+	// Cost extension: SeqPageCost(8) + RandomPageCost(8) + CPUTupleCost(8) +
+	// CPUIndexTupleCost(8) + CPUOperatorCost(8). Older superblocks decode with
+	// the default unit costs.
+	superblockCostPayloadSize = superblockRecursionPayloadSize + 40
+
+	// Time travel extension: TimeTravelRetention(8). Older superblocks decode
+	// with DefaultTimeTravelRetention.
+	superblockTimeTravelPayloadSize = superblockCostPayloadSize + 8
+
+	// Encryption extension: Enabled(1) + KeyID(4) + KeyCheck(encryption.CheckSize).
+	// Older superblocks decode as unencrypted.
+	superblockEncryptionPayloadSize = superblockTimeTravelPayloadSize + 5 + encryption.CheckSize
+
+	// Group commit extension: WALCommitWindow(8). Older superblocks decode
+	// without a commit window.
+	superblockCommitWindowPayloadSize = superblockEncryptionPayloadSize + 8
+
+	// WAL compression extension: WALCompression(1). Older superblocks decode
+	// without compression.
+	superblockCompressionPayloadSize = superblockCommitWindowPayloadSize + 1
+
+	// Sync interval extension: WALSyncInterval(8). Older superblocks decode
+	// with wal.DefaultSyncInterval.
+	superblockSyncIntervalPayloadSize = superblockCompressionPayloadSize + 8
+
+	// Direct I/O extension: DirectIO(1). Older superblocks decode going
+	// through the OS page cache.
+	superblockPayloadSize = superblockSyncIntervalPayloadSize + 1
+)
+
+// EncodeSuperblock serializes settings into the superblock format:
+//
+//	[Magic:4][Version:2][Reserved:2][PayloadLen:4][Payload][CRC32:4]
+//
+// The CRC covers everything before it. All integers are big-endian.
+func EncodeSuperblock(s Settings) []byte {
+	buf := new(bytes.Buffer)
+	buf.Grow(superblockHeaderSize + superblockPayloadSize + superblockCRCSize)
+
+	buf.WriteString(superblockMagic)
+	binary.Write(buf, binary.BigEndian, SuperblockVersion)
+	binary.Write(buf, binary.BigEndian, uint16(0))
+	binary.Write(buf, binary.BigEndian, uint32(superblockPayloadSize))
+
+	binary.Write(buf, binary.BigEndian, uint32(s.PageSize))
+	binary.Write(buf, binary.BigEndian, uint32(s.WALBufferSize))
+	binary.Write(buf, binary.BigEndian, int64(s.CheckpointInterval))
+	binary.Write(buf, binary.BigEndian, s.CheckpointMaxWALSize)
+	binary.Write(buf, binary.BigEndian, s.CheckpointMaxTransactions)
+	var enabled uint8
+	if s.CheckpointEnabled {
+		enabled = 1
+	}
+	buf.WriteByte(enabled)
+
+	var autoAnalyze uint8
+	if s.AutoAnalyzeEnabled {
+		autoAnalyze = 1
+	}
+	buf.WriteByte(autoAnalyze)
+	binary.Write(buf, binary.BigEndian, int64(s.AutoAnalyzeInterval))
+	binary.Write(buf, binary.BigEndian, math.Float64bits(s.AutoAnalyzeFraction))
+	binary.Write(buf, binary.BigEndian, s.AutoAnalyzeMinChanges)
+
+	buf.WriteByte(uint8(s.WALDurability))
+	buf.WriteByte(uint8(s.SyncPolicy))
+	binary.Write(buf, binary.BigEndian, s.MaxRecursiveIterations)
+	for _, cost := range []float64{s.SeqPageCost, s.RandomPageCost, s.CPUTupleCost, s.CPUIndexTupleCost, s.CPUOperatorCost} {
+		binary.Write(buf, binary.BigEndian, math.Float64bits(cost))
+	}
+	binary.Write(buf, binary.BigEndian, int64(s.TimeTravelRetention))
+
+	var encrypted uint8
+	if s.Encryption.Enabled {
+		encrypted = 1
+	}
+	buf.WriteByte(encrypted)
+	binary.Write(buf, binary.BigEndian, s.Encryption.KeyID)
+	buf.Write(s.Encryption.KeyCheck[:])
+	binary.Write(buf, binary.BigEndian, int64(s.WALCommitWindow))
+	buf.WriteByte(uint8(s.WALCompression))
+	binary.Write(buf, binary.BigEndian, int64(s.WALSyncInterval))
+	var directIO uint8
+	if s.DirectIO {
+		directIO = 1
+	}
+	buf.WriteByte(directIO)
+
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes()
+}
+
+// DecodeSuperblock parses and validates a superblock produced by EncodeSuperblock.
+// It rejects data with a bad magic number, unsupported version, checksum mismatch,
+// or settings that fail validation (including a page size that differs from the
+// compiled page size).
+func DecodeSuperblock(data []byte) (Settings, error) {
+	if len(data) < superblockHeaderSize+superblockCRCSize {
+		return Settings{}, fmt.Errorf("superblock too short: %d bytes", len(data))
+	}
+	if string(data[0:4]) != superblockMagic {
+		return Settings{}, errors.New("invalid superblock magic")
+	}
+
+	version := binary.BigEndian.Uint16(data[4:6])
+	if version != SuperblockVersion {
+		return Settings{}, fmt.Errorf("unsupported superblock version %d (expected %d)", version, SuperblockVersion)
+	}
+
+	payloadLen := int(binary.BigEndian.Uint32(data[8:12]))
+	if payloadLen < superblockBasePayloadSize || len(data) != superblockHeaderSize+payloadLen+superblockCRCSize {
+		return Settings{}, fmt.Errorf("invalid superblock payload length %d", payloadLen)
+	}
+
+	crcOffset := superblockHeaderSize + payloadLen
+	expected := binary.BigEndian.Uint32(data[crcOffset:])
+	if actual := crc32.ChecksumIEEE(data[:crcOffset]); actual != expected {
+		return Settings{}, fmt.Errorf("superblock checksum mismatch: expected %08x, got %08x", expected, actual)
+	}
+
+	p := data[superblockHeaderSize:crcOffset]
+	s := DefaultSettings()
+	s.PageSize = int(binary.BigEndian.Uint32(p[0:4]))
+	s.WALBufferSize = int(binary.BigEndian.Uint32(p[4:8]))
+	s.CheckpointInterval = time.Duration(binary.BigEndian.Uint64(p[8:16]))
+	s.CheckpointMaxWALSize = int64(binary.BigEndian.Uint64(p[16:24]))
+	s.CheckpointMaxTransactions = int64(binary.BigEndian.Uint64(p[24:32]))
+	s.CheckpointEnabled = p[32] != 0
+
+	if payloadLen >= superblockAutoAnalyzePayloadSize {
+		s.AutoAnalyzeEnabled = p[33] != 0
+		s.AutoAnalyzeInterval = time.Duration(binary.BigEndian.Uint64(p[34:42]))
+		s.AutoAnalyzeFraction = math.Float64frombits(binary.BigEndian.Uint64(p[42:50]))
+		s.AutoAnalyzeMinChanges = int64(binary.BigEndian.Uint64(p[50:58]))
+	}
+	if payloadLen >= superblockDurabilityPayloadSize {
+		s.WALDurability = wal.Durability(p[58])
+	}
+	if payloadLen >= superblockSyncPolicyPayloadSize {
+		s.SyncPolicy = vfs.SyncPolicy(p[59])
+	}
+	if payloadLen >= superblockRecursionPayloadSize {
+		s.MaxRecursiveIterations = int64(binary.BigEndian.Uint64(p[60:68]))
+	}
+	if payloadLen >= superblockCostPayloadSize {
+		costs := []*float64{&s.SeqPageCost, &s.RandomPageCost, &s.CPUTupleCost, &s.CPUIndexTupleCost, &s.CPUOperatorCost}
+		for i, cost := range costs {
+			*cost = math.Float64frombits(binary.BigEndian.Uint64(p[68+8*i:]))
+		}
+	}
+	if payloadLen >= superblockTimeTravelPayloadSize {
+		s.TimeTravelRetention = time.Duration(binary.BigEndian.Uint64(p[108:116]))
+	}
+	if payloadLen >= superblockEncryptionPayloadSize {
+		s.Encryption.Enabled = p[116] != 0
+		s.Encryption.KeyID = binary.BigEndian.Uint32(p[117:121])
+		copy(s.Encryption.KeyCheck[:], p[121:superblockEncryptionPayloadSize])
+	}
+	if payloadLen >= superblockCommitWindowPayloadSize {
+		s.WALCommitWindow = time.Duration(binary.BigEndian.Uint64(p[superblockEncryptionPayloadSize:]))
+	}
+	if payloadLen >= superblockCompressionPayloadSize {
+		s.WALCompression = record.Compression(p[superblockCommitWindowPayloadSize])
+	}
+	if payloadLen >= superblockSyncIntervalPayloadSize {
+		s.WALSyncInterval = time.Duration(binary.BigEndian.Uint64(p[superblockCompressionPayloadSize:]))
+	}
+	if payloadLen >= superblockPayloadSize {
+		s.DirectIO = p[superblockSyncIntervalPayloadSize] != 0
+	}
+
+	if err := s.Validate(); err != nil {
+		return Settings{}, err
+	}
+	return s, nil
+}
+
+// writeFileAtomic replaces path with data so that a crash leaves either the old
+// or the new contents, never a partial file. The data is written to a temporary
+// file and synced, renamed over path, and the directory is synced so the rename
+// itself is durable.
+func writeFileAtomic(path string, data []byte) error {
+	tempPath := path + ".tmp"
+
+	f, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir fsyncs a directory so that entries created or renamed in it are durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory for sync: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+package config
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"storemy/pkg/encryption"
+	"storemy/pkg/log/record"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/vfs"
+	"testing"
+	"time"
+)
+
+func TestSuperblock_EncodeDecodeRoundTrip(t *testing.T) {
+	s := DefaultSettings()
+	s.WALBufferSize = 16384
+	s.CheckpointInterval = 30 * time.Second
+	s.CheckpointEnabled = false
+	s.WALDurability = wal.DurabilityInterval
+	s.WALSyncInterval = 250 * time.Millisecond
+	s.WALCommitWindow = 2 * time.Millisecond
+	s.WALCompression = record.CompressionDeflate
+	s.SyncPolicy = vfs.SyncFdatasync
+	s.DirectIO = true
+	s.RandomPageCost = 0.25
+	s.CPUOperatorCost = 0.0025
+	s.TimeTravelRetention = 24 * time.Hour
+	s.Encryption = Encryption{Enabled: true, KeyID: 3, KeyCheck: [encryption.CheckSize]byte{1, 2, 3}}
+
+	decoded, err := DecodeSuperblock(EncodeSuperblock(s))
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded != s {
+		t.Errorf("round trip mismatch: got %+v, want %+v", decoded, s)
+	}
+}
+
+func TestSuperblock_DecodeRejectsCorruption(t *testing.T) {
+	valid := EncodeSuperblock(DefaultSettings())
+
+	tests := []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"bad magic", func(b []byte) []byte { b[0] = 'X'; return b }},
+		{"bad version", func(b []byte) []byte { b[5] = 99; return b }},
+		{"flipped payload bit", func(b []byte) []byte { b[superblockHeaderSize+5] ^= 0x01; return b }},
+		{"truncated", func(b []byte) []byte { return b[:len(b)-3] }},
+		{"empty", func(b []byte) []byte { return nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.mutate(append([]byte(nil), valid...))
+			if _, err := DecodeSuperblock(data); err == nil {
+				t.Error("expected decode error, got none")
+			}
+		})
+	}
+}
+
+func TestSuperblock_DecodeBasePayloadUsesAutoAnalyzeDefaults(t *testing.T) {
+	s := DefaultSettings()
+	s.CheckpointMaxTransactions = 42
+	s.AutoAnalyzeFraction = 0.5
+
+	// Rebuild the superblock as it was written before the auto-analyze fields existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockBasePayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], superblockBasePayloadSize)
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.CheckpointMaxTransactions != 42 {
+		t.Errorf("expected base settings to be decoded, got %+v", decoded)
+	}
+	if decoded.AutoAnalyzeConfig() != DefaultSettings().AutoAnalyzeConfig() {
+		t.Errorf("expected default auto-analyze settings, got %+v", decoded.AutoAnalyzeConfig())
+	}
+}
+
+func TestSuperblock_DecodeWithoutDurabilityUsesSyncCommits(t *testing.T) {
+	s := DefaultSettings()
+	s.AutoAnalyzeFraction = 0.5
+	s.WALDurability = wal.DurabilityAsync
+
+	// Rebuild the superblock as it was written before the durability field existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockAutoAnalyzePayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], superblockAutoAnalyzePayloadSize)
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.AutoAnalyzeFraction != 0.5 {
+		t.Errorf("expected auto-analyze settings to be decoded, got %+v", decoded)
+	}
+	if decoded.WALDurability != wal.DurabilitySync {
+		t.Errorf("expected sync durability, got %s", decoded.WALDurability)
+	}
+}
+
+func TestSuperblock_DecodeWithoutSyncPolicyUsesOSync(t *testing.T) {
+	s := DefaultSettings()
+	s.WALDurability = wal.DurabilityAsync
+	s.SyncPolicy = vfs.SyncFsync
+
+	// Rebuild the superblock as it was written before the sync policy field existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockDurabilityPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], superblockDurabilityPayloadSize)
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.WALDurability != wal.DurabilityAsync {
+		t.Errorf("expected durability to be decoded, got %+v", decoded)
+	}
+	if decoded.SyncPolicy != vfs.SyncOSync {
+		t.Errorf("expected osync policy, got %s", decoded.SyncPolicy)
+	}
+}
+
+func TestSuperblock_DecodeWithoutRecursionLimitUsesDefault(t *testing.T) {
+	s := DefaultSettings()
+	s.SyncPolicy = vfs.SyncFsync
+	s.MaxRecursiveIterations = 50
+
+	// Rebuild the superblock as it was written before the recursion limit existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockSyncPolicyPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], superblockSyncPolicyPayloadSize)
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.SyncPolicy != vfs.SyncFsync {
+		t.Errorf("expected sync policy to be decoded, got %+v", decoded)
+	}
+	if decoded.MaxRecursiveIterations != DefaultMaxRecursiveIterations {
+		t.Errorf("expected %d recursive iterations, got %d", DefaultMaxRecursiveIterations, decoded.MaxRecursiveIterations)
+	}
+}
+
+func TestSuperblock_DecodeWithoutCostsUsesDefaults(t *testing.T) {
+	s := DefaultSettings()
+	s.MaxRecursiveIterations = 50
+	s.SeqPageCost = 2
+
+	// Rebuild the superblock as it was written before the cost settings existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockRecursionPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], superblockRecursionPayloadSize)
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.MaxRecursiveIterations != 50 {
+		t.Errorf("expected the recursion limit to be decoded, got %+v", decoded)
+	}
+	if decoded.CostParameters() != DefaultSettings().CostParameters() {
+		t.Errorf("expected default cost parameters, got %+v", decoded.CostParameters())
+	}
+}
+
+func TestSuperblock_DecodeWithoutRetentionUsesDefault(t *testing.T) {
+	s := DefaultSettings()
+	s.SeqPageCost = 2
+	s.TimeTravelRetention = 0
+
+	// Rebuild the superblock as it was written before time travel existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockCostPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], superblockCostPayloadSize)
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.SeqPageCost != 2 {
+		t.Errorf("expected the cost settings to be decoded, got %+v", decoded)
+	}
+	if decoded.TimeTravelRetention != DefaultTimeTravelRetention {
+		t.Errorf("expected retention %s, got %s", DefaultTimeTravelRetention, decoded.TimeTravelRetention)
+	}
+}
+
+func TestSuperblock_DecodeWithoutEncryptionIsUnencrypted(t *testing.T) {
+	s := DefaultSettings()
+	s.TimeTravelRetention = 2 * time.Hour
+	s.Encryption = Encryption{Enabled: true, KeyID: 3}
+
+	// Rebuild the superblock as it was written before encryption existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockTimeTravelPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], superblockTimeTravelPayloadSize)
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.TimeTravelRetention != 2*time.Hour {
+		t.Errorf("expected the retention to be decoded, got %s", decoded.TimeTravelRetention)
+	}
+	if decoded.Encryption != (Encryption{}) {
+		t.Errorf("expected an unencrypted database, got %+v", decoded.Encryption)
+	}
+}
+
+func TestSuperblock_DecodeWithoutCommitWindowSyncsAtOnce(t *testing.T) {
+	s := DefaultSettings()
+	s.Encryption = Encryption{Enabled: true, KeyID: 3}
+	s.WALCommitWindow = 5 * time.Millisecond
+
+	// Rebuild the superblock as it was written before group commit existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockEncryptionPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], uint32(superblockEncryptionPayloadSize))
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.Encryption.KeyID != 3 {
+		t.Errorf("expected the encryption settings to be decoded, got %+v", decoded.Encryption)
+	}
+	if decoded.WALCommitWindow != 0 {
+		t.Errorf("expected no commit window, got %s", decoded.WALCommitWindow)
+	}
+}
+
+func TestSuperblock_DecodeWithoutCompressionStoresImagesAsIs(t *testing.T) {
+	s := DefaultSettings()
+	s.WALCommitWindow = 5 * time.Millisecond
+	s.WALCompression = record.CompressionDeflate
+
+	// Rebuild the superblock as it was written before WAL compression existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockCommitWindowPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], uint32(superblockCommitWindowPayloadSize))
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.WALCommitWindow != 5*time.Millisecond {
+		t.Errorf("expected the commit window to be decoded, got %s", decoded.WALCommitWindow)
+	}
+	if decoded.WALCompression != record.CompressionNone {
+		t.Errorf("expected no compression, got %s", decoded.WALCompression)
+	}
+}
+
+func TestSuperblock_DecodeWithoutSyncIntervalUsesDefault(t *testing.T) {
+	s := DefaultSettings()
+	s.WALCompression = record.CompressionDeflate
+	s.WALSyncInterval = 10 * time.Millisecond
+
+	// Rebuild the superblock as it was written before the sync interval existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockCompressionPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], uint32(superblockCompressionPayloadSize))
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.WALCompression != record.CompressionDeflate {
+		t.Errorf("expected the compression to be decoded, got %s", decoded.WALCompression)
+	}
+	if decoded.WALSyncInterval != wal.DefaultSyncInterval {
+		t.Errorf("expected the default sync interval, got %s", decoded.WALSyncInterval)
+	}
+}
+
+func TestSuperblock_DecodeWithoutDirectIOUsesPageCache(t *testing.T) {
+	s := DefaultSettings()
+	s.WALSyncInterval = 10 * time.Millisecond
+	s.DirectIO = true
+
+	// Rebuild the superblock as it was written before direct I/O existed.
+	full := EncodeSuperblock(s)
+	legacy := append([]byte(nil), full[:superblockHeaderSize+superblockSyncIntervalPayloadSize]...)
+	binary.BigEndian.PutUint32(legacy[8:12], uint32(superblockSyncIntervalPayloadSize))
+	legacy = binary.BigEndian.AppendUint32(legacy, crc32.ChecksumIEEE(legacy))
+
+	decoded, err := DecodeSuperblock(legacy)
+	if err != nil {
+		t.Fatalf("DecodeSuperblock failed: %v", err)
+	}
+	if decoded.WALSyncInterval != 10*time.Millisecond {
+		t.Errorf("expected the sync interval to be decoded, got %s", decoded.WALSyncInterval)
+	}
+	if decoded.DirectIO {
+		t.Error("expected direct I/O to be off")
+	}
+}
+
+func TestSuperblock_DecodeRejectsPageSizeMismatch(t *testing.T) {
+	s := DefaultSettings()
+	s.PageSize = s.PageSize * 2
+
+	if _, err := DecodeSuperblock(EncodeSuperblock(s)); err == nil {
+		t.Error("expected error for page size that differs from compiled page size")
+	}
+}
+
+func TestStore_OpenCreatesSuperblock(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := Open(dir, false)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if store.Settings() != DefaultSettings() {
+		t.Errorf("expected default settings, got %+v", store.Settings())
+	}
+	if _, err := os.Stat(filepath.Join(dir, SuperblockFile)); err != nil {
+		t.Errorf("expected superblock file to be created: %v", err)
+	}
+}
+
+func TestStore_SetPersistsAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := Open(dir, false)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	s, err := store.Set("CHECKPOINT_INTERVAL", "5M")
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if s.Value != "5m0s" {
+		t.Errorf("expected value 5m0s, got %s", s.Value)
+	}
+
+	reopened, err := Open(dir, false)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if got := reopened.Settings().CheckpointInterval; got != 5*time.Minute {
+		t.Errorf("expected persisted interval 5m, got %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SuperblockFile+".tmp")); !os.IsNotExist(err) {
+		t.Error("temporary superblock file should not remain after Set")
+	}
+}
+
+func TestStore_SetRejectsInvalidValues(t *testing.T) {
+	store, err := Open(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"page_size", "8192"},
+		{"no_such_setting", "1"},
+		{"wal_buffer_size", "abc"},
+		{"wal_buffer_size", "16"},
+		{"checkpoint_interval", "-1s"},
+		{"checkpoint_enabled", "maybe"},
+		{"wal_durability", "eventually"},
+		{"wal_commit_window", "-1ms"},
+		{"wal_commit_window", "1m"},
+		{"wal_compression", "snappy"},
+		{"wal_sync_interval", "0s"},
+		{"wal_sync_interval", "2m"},
+		{"sync_policy", "sometimes"},
+		{"direct_io", "sometimes"},
+		{"auto_analyze_interval", "0s"},
+		{"auto_analyze_fraction", "-0.5"},
+		{"auto_analyze_fraction", "half"},
+		{"auto_analyze_min_changes", "0"},
+		{"random_page_cost", "-1"},
+		{"cpu_tuple_cost", "NaN"},
+		{"seq_page_cost", "cheap"},
+	}
+
+	for _, tt := range tests {
+		if _, err := store.Set(tt.name, tt.value); err == nil {
+			t.Errorf("expected Set(%s, %s) to fail", tt.name, tt.value)
+		}
+	}
+
+	if store.Settings() != DefaultSettings() {
+		t.Errorf("rejected changes must not modify settings, got %+v", store.Settings())
+	}
+}
+
+func TestStore_OpenCorruptSuperblock(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, SuperblockFile), []byte("garbage"), 0644); err != nil {
+		t.Fatalf("failed to write corrupt superblock: %v", err)
+	}
+
+	if _, err := Open(dir, false); err == nil {
+		t.Fatal("expected error opening corrupt superblock")
+	}
+}
+
+func TestStore_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := Open(dir, true)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SuperblockFile)); !os.IsNotExist(err) {
+		t.Error("read-only open must not create the superblock")
+	}
+	if _, err := store.Set("wal_buffer_size", "16384"); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
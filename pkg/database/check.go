@@ -0,0 +1,47 @@
+package database
+
+import (
+	dberror "storemy/pkg/error"
+	"storemy/pkg/fsck"
+)
+
+// Check verifies the consistency of the database: catalog entries against
+// files on disk, the structure of every heap page, every index against its
+// table and the rows against their constraints. Problems are reported as
+// findings in the returned report; an error means the check could not run.
+//
+// The check reads committed data from disk and is exact on an idle database.
+func (db *Database) Check() (*fsck.Report, error) {
+	log := db.logger
+
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	if db.closing {
+		return nil, newClosedError("Check")
+	}
+
+	tx, err := db.txRegistry.Begin()
+	if err != nil {
+		dbErr := dberror.Wrap(err, "TX_BEGIN_FAILED", "Check", "TransactionRegistry")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = "Failed to begin transaction for consistency check"
+		return nil, dbErr
+	}
+	defer db.pageStore.AbortTransaction(tx)
+
+	report, err := fsck.NewChecker(db.dbCtx, tx).Run()
+	if err != nil {
+		dbErr := dberror.Wrap(err, "CHECK_FAILED", "Check", "Checker")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = "Failed to read the catalog for the consistency check"
+		dbErr.Hint = "The catalog tables may be corrupted. Consider restoring from backup"
+		log.Error("consistency check failed", "error", err)
+		return nil, dbErr
+	}
+
+	log.Info("consistency check completed",
+		"errors", report.Count(fsck.SeverityError),
+		"warnings", report.Count(fsck.SeverityWarning),
+		"duration_ms", report.Duration.Milliseconds())
+	return report, nil
+}
@@ -1,21 +1,34 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"storemy/pkg/catalog"
 	"storemy/pkg/catalog/catalogmanager"
 	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/concurrency/admission"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/config"
+	"storemy/pkg/encryption"
 	dberror "storemy/pkg/error"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/execution/tempfile"
 	"storemy/pkg/log/wal"
 	"storemy/pkg/logging"
 	"storemy/pkg/memory"
 	"storemy/pkg/parser/parser"
 	"storemy/pkg/parser/statements"
 	"storemy/pkg/planner"
+	"storemy/pkg/recovery"
 	"storemy/pkg/registry"
+	"storemy/pkg/resultcache"
+	"storemy/pkg/stmtstats"
+	"storemy/pkg/storage/accounting"
+	"storemy/pkg/sysview"
+	"storemy/pkg/tracing"
+	"storemy/pkg/vfs"
 	"sync"
 	"time"
 )
@@ -32,12 +45,39 @@ type Database struct {
 	walInstance  *wal.WAL
 	txRegistry   *transaction.TransactionRegistry
 	statsManager *catalog.StatisticsManager
+	settings     *config.Store
+	checkpointer *wal.CheckpointDaemon
+	sessions     *sysview.SessionTracker
+	statements   *stmtstats.Collector
+	resultCache  *resultcache.Cache
+	admission    *admission.Controller
+	memBudget    *membudget.Budget
+	tempFiles    *tempfile.Manager
+	exporter     tracing.Exporter
+	cipher       *encryption.Cipher // Nil unless encrypted at rest
+	dbCtx        *registry.DatabaseContext
+
+	name            string
+	dataDir         string
+	walPath         string
+	readOnly        bool
+	isolation       transaction.IsolationLevel // Level new transactions start at
+	shutdownTimeout time.Duration
+	retry           RetryPolicy // Retries of the implicit transactions of ExecuteQuery
+
+	mutex        sync.RWMutex // Held exclusively only to set closing
+	closing      bool
+	shutdownOnce sync.Once
+	shutdownErr  error
+	migrationMu  sync.Mutex // Serializes ApplyMigrations and RollbackMigrations
+	stats        *DatabaseStats
+	logger       logging.ComponentLogger // Tagged with the component and database name
+	recoveryLog  logging.ComponentLogger // For the dry runs of ExplainRecovery
+}
 
-	name    string
-	dataDir string
-
-	mutex sync.RWMutex
-	stats *DatabaseStats
+// txLogger returns the database logger tagged with the ID of tx.
+func (db *Database) txLogger(tx *transaction.TransactionContext) logging.ComponentLogger {
+	return db.logger.With("tx_id", int(tx.ID.ID()))
 }
 
 // DatabaseStats tracks performance metrics
@@ -56,6 +96,11 @@ type QueryResult struct {
 	RowsAffected int
 	Message      string
 	Error        error
+
+	// Buffered reports that the query's commit was acknowledged before its
+	// commit record reached the WAL file, which only happens under async WAL
+	// durability; a crash may still lose it.
+	Buffered bool
 }
 
 // DatabaseInfo contains database metadata
@@ -69,51 +114,145 @@ type DatabaseInfo struct {
 }
 
 func NewDatabase(name, dataDir, logDir string) (*Database, error) {
-	log := logging.WithComponent("database").With("database", name)
-	log.Info("initializing database", "data_dir", dataDir, "log_dir", logDir)
+	return NewDatabaseWithOptions(name, dataDir, logDir, DefaultOptions())
+}
 
-	fullPath := filepath.Join(dataDir, name)
-	if err := os.MkdirAll(fullPath, 0755); err != nil {
-		dbErr := dberror.Wrap(err, "DIR_CREATE_FAILED", "NewDatabase", "Database")
-		dbErr.Detail = fmt.Sprintf("Failed to create directory: %s", fullPath)
-		dbErr.Hint = "Check that the parent directory exists and you have write permissions"
-		log.Error("failed to create database directory", "error", err, "path", fullPath)
-		return nil, dbErr
-	}
+// NewDatabaseWithOptions opens (or creates) a database using the given options.
+// With opts.ReadOnly set, the database directory and WAL must already exist;
+// recovery replays the WAL in redo-only mode and the statistics updater is not started.
+func NewDatabaseWithOptions(name, dataDir, logDir string, opts Options) (*Database, error) {
+	log := opts.componentLogger("database").With("database", name)
+	log.Info("initializing database", "data_dir", dataDir, "log_dir", logDir, "read_only", opts.ReadOnly)
 
-	walInstance, err := wal.NewWAL(logDir, 8192)
+	fullPath := filepath.Join(dataDir, name)
+	walInstance, settings, cipher, err := openStorage(fullPath, logDir, opts)
 	if err != nil {
-		dbErr := dberror.Wrap(err, "WAL_INIT_FAILED", "NewDatabase", "WAL")
-		dbErr.Detail = fmt.Sprintf("Failed to initialize Write-Ahead Log at: %s", logDir)
-		dbErr.Hint = "Ensure the log directory exists and has sufficient disk space"
-		log.Error("WAL initialization failed", "error", err, "log_dir", logDir)
-		return nil, dbErr
+		return nil, err
 	}
 	log.Debug("WAL initialized", "log_dir", logDir)
 
+	walInstance.SetLogger(opts.componentLogger("wal"))
+
 	pageStore := memory.NewPageStore(walInstance)
+	if cipher != nil {
+		pageStore.SetFS(encryption.NewFS(vfs.OS, cipher))
+	}
+	pageStore.SetSyncPolicy(settings.Settings().SyncPolicy)
+	pageStore.SetDirectIO(settings.Settings().DirectIO)
+	pageStore.SetTableLockTimeout(opts.TableLockTimeout)
+	pageStore.SetLockGrantPolicy(opts.LockGrantPolicy)
+	if !opts.ReadOnly {
+		if err := openDoubleWrite(pageStore, fullPath, opts.DoubleWrite, log); err != nil {
+			walInstance.Close()
+			return nil, err
+		}
+	}
 	catalogMgr := catalogmanager.NewCatalogManager(pageStore, fullPath)
+	catalogMgr.SetLogger(opts.componentLogger("catalog"))
+	catalogMgr.SetReadOnly(opts.ReadOnly)
+
+	if opts.ReadOnly {
+		rm := recovery.NewRecoveryManager(walInstance, logDir, pageStore)
+		rm.SetLogger(opts.componentLogger("recovery"))
+		// A backup or standby log may end in a record still being copied
+		rm.SetStopAtCorruptRecord(true)
+		if err := rm.RecoverRedoOnly(); err != nil {
+			walInstance.Close()
+			dbErr := dberror.Wrap(err, "RECOVERY_FAILED", "NewDatabase", "RecoveryManager")
+			dbErr.Category = dberror.ErrCategoryData
+			dbErr.Detail = "Redo-only recovery failed while opening the database read-only"
+			log.Error("redo-only recovery failed", "error", err)
+			return nil, dbErr
+		}
+	}
+
+	var tempFiles *tempfile.Manager
+	if config, ok := opts.tempFilesConfig(fullPath); ok {
+		tempFiles, err = tempfile.New(config)
+		if err != nil {
+			walInstance.Close()
+			dbErr := dberror.Wrap(err, "TEMP_DIR_FAILED", "NewDatabase", "TempFiles")
+			dbErr.Category = dberror.ErrCategorySystem
+			dbErr.Detail = fmt.Sprintf("Failed to prepare the temporary file directory: %s", config.Dir)
+			log.Error("failed to prepare temporary file directory", "error", err, "path", config.Dir)
+			return nil, dbErr
+		}
+		if swept := tempFiles.Stats().Swept; swept > 0 {
+			log.Info("removed leftover temporary files", "count", swept, "path", config.Dir)
+		}
+	}
 
 	ctx := registry.NewDatabaseContext(pageStore, catalogMgr, walInstance, fullPath)
+	ctx.SetSettings(settings)
+	if opts.Quota.Enabled() && !opts.ReadOnly {
+		ctx.TupleManager().SetQuota(accounting.NewQuotaTracker(opts.Quota, catalogMgr, pageStore))
+	}
 
 	db := &Database{
-		catalogMgr: catalogMgr,
-		pageStore:  pageStore,
-		txRegistry: ctx.TransactionRegistry(),
-		walInstance:        walInstance,
-		name:       name,
-		dataDir:    fullPath,
-		stats:      &DatabaseStats{},
+		catalogMgr:      catalogMgr,
+		pageStore:       pageStore,
+		txRegistry:      ctx.TransactionRegistry(),
+		walInstance:     walInstance,
+		settings:        settings,
+		name:            name,
+		dataDir:         fullPath,
+		walPath:         logDir,
+		readOnly:        opts.ReadOnly,
+		isolation:       opts.IsolationLevel,
+		shutdownTimeout: opts.shutdownTimeout(),
+		retry:           opts.StatementRetry,
+		stats:           &DatabaseStats{},
+		sessions:        sysview.NewSessionTracker(),
+		statements:      stmtstats.NewCollector(stmtstats.DefaultMaxEntries),
+		resultCache:     resultcache.New(opts.ResultCache),
+		admission:       admission.NewController(opts.Admission),
+		memBudget:       opts.MemoryBudget,
+		tempFiles:       tempFiles,
+		exporter:        opts.TraceExporter,
+		cipher:          cipher,
+		dbCtx:           ctx,
+		logger:          log,
+		recoveryLog:     opts.componentLogger("recovery"),
 	}
 
+	db.checkpointer = wal.NewCheckpointDaemon(walInstance, settings.Settings().CheckpointConfig())
+	db.checkpointer.SetLogger(opts.componentLogger("checkpoint_daemon"))
+
+	autoAnalyze := settings.Settings().AutoAnalyzeConfig()
 	statsManager := catalog.NewStatisticsManager(catalogMgr, db)
+	statsManager.SetAutoAnalyzeConfig(autoAnalyze)
+	statsManager.SetLogger(opts.componentLogger("statistics_manager"))
 	db.statsManager = statsManager
+	ctx.AddModificationRecorder(statsManager)
+	ctx.AddModificationRecorder(db.resultCache)
+
+	if err := db.registerSystemViews(ctx); err != nil {
+		walInstance.Close()
+		dbErr := dberror.Wrap(err, "SYSTEM_VIEWS_FAILED", "NewDatabase", "Database")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = "Failed to register system views"
+		log.Error("failed to register system views", "error", err)
+		return nil, dbErr
+	}
 
 	queryPlanner := planner.NewQueryPlanner(ctx)
+	queryPlanner.SetLogger(opts.componentLogger("query_planner"))
 	db.queryPlanner = queryPlanner
+	ctx.Triggers().SetExecutor(db.runTriggerStatement)
+
+	if !opts.ReadOnly {
+		if autoAnalyze.Enabled {
+			statsManager.StartBackgroundUpdater(autoAnalyze.Interval)
+			log.Info("statistics background updater started",
+				"interval", autoAnalyze.Interval,
+				"fraction", autoAnalyze.Fraction,
+				"min_changes", autoAnalyze.MinChanges)
+		}
 
-	statsManager.StartBackgroundUpdater(30 * time.Second)
-	log.Info("statistics background updater started", "interval_seconds", 30)
+		if err := db.checkpointer.Start(); err != nil {
+			log.Warn("failed to start checkpoint daemon", "error", err)
+		}
+	}
 
 	if err := db.loadExistingTables(); err != nil {
 		dbErr := dberror.Wrap(err, "TABLE_LOAD_FAILED", "NewDatabase", "CatalogManager")
@@ -123,31 +262,173 @@ func NewDatabase(name, dataDir, logDir string) (*Database, error) {
 		return nil, dbErr
 	}
 
+	// Opening commits catalog changes no standby could have acknowledged yet,
+	// so commits only start waiting for standbys once the database is open
+	if !opts.ReadOnly {
+		if err := walInstance.SetReplication(opts.Replication); err != nil {
+			log.Warn("failed to enable synchronous replication", "error", err)
+		}
+		if err := walInstance.SetDiskReserve(opts.WALDiskReserve); err != nil {
+			log.Warn("failed to monitor WAL disk space", "error", err)
+		}
+	}
+
 	log.Info("database initialized successfully")
 	return db, nil
 }
 
 func (db *Database) ExecuteQuery(query string) (QueryResult, error) {
+	return db.ExecuteQueryWithArgs(query)
+}
+
+// ExecuteQueryWithArgs executes a parameterized query. The query refers to
+// its arguments with bind parameters, either numbered ($1, $2, ...) or
+// anonymous (?, numbered in order of appearance): args[0] is the value of $1,
+// args[1] of $2, and so on. Arguments are Go values (integers, floats, bools,
+// strings, []byte or nil for NULL) or types.Field values, and are converted
+// to the type of the column each parameter is compared with or assigned to.
+//
+// Because argument values are never parsed as SQL, they cannot change the
+// meaning of the query:
+//
+//	db.ExecuteQueryWithArgs("SELECT id FROM users WHERE name = $1", name)
+//	db.ExecuteQueryWithArgs("INSERT INTO users (id, name) VALUES (?, ?)", 7, name)
+//
+// Unlike string literals in the query text, string arguments keep their case.
+func (db *Database) ExecuteQueryWithArgs(query string, args ...any) (QueryResult, error) {
+	return db.executeQuery(query, args, nil)
+}
+
+// ExecuteQueryStepByStep executes query like ExecuteQuery and reports every
+// call into its execution operators to observer as it happens: each operator
+// opened, each row pulled through it, each page a scan has read, and so on.
+// The observer runs on the executing goroutine, so a client that wants to
+// show the execution live can pace it by taking its time to return. The
+// result cache is bypassed, as a cached result has no execution to observe.
+func (db *Database) ExecuteQueryStepByStep(query string, observer tracing.StepObserver) (QueryResult, error) {
+	return db.executeQuery(query, nil, observer)
+}
+
+// executeQuery runs query with args in a transaction of its own and commits
+// it. A non-nil steps observer receives the operator steps of the execution.
+func (db *Database) executeQuery(query string, args []any, steps tracing.StepObserver) (QueryResult, error) {
 	var err error
 	var res QueryResult
 
-	log := logging.WithComponent("database").With("database", db.name)
+	log := db.logger
 	log.Info("executing query", "query_length", len(query))
 	startTime := time.Now()
 
-	tx, err := db.txRegistry.Begin()
+	cacheKey, cacheable := db.resultCacheKey(query, args)
+	cacheable = cacheable && steps == nil
+	if cacheable {
+		if cached, ok := db.cachedResult(cacheKey); ok {
+			elapsed := db.recordQuery(query, cached, startTime)
+			log.Info("query answered from the result cache", "duration_ms", elapsed.Milliseconds())
+			return cached, nil
+		}
+	}
+	cacheVersion := db.resultCache.Version()
+
+	release, err := db.admit("ExecuteQuery")
+	if err != nil {
+		log.Warn("query rejected by admission control", "error", err)
+		return res, err
+	}
+	defer release()
+
+	for attempt := 1; ; attempt++ {
+		var retryable bool
+		res, retryable, err = db.runImplicitTransaction(query, args, steps, startTime, cacheable, cacheKey, cacheVersion)
+		if err == nil || !retryable || attempt >= db.retry.MaxAttempts {
+			return res, err
+		}
+		backoff := db.retry.backoff(attempt)
+		statementRetries.Inc()
+		log.Warn("retrying statement after a transaction conflict", "attempt", attempt, "backoff_ms", backoff.Milliseconds(), "error", err)
+		time.Sleep(backoff)
+	}
+}
+
+// runImplicitTransaction runs query with args in a transaction of its own
+// and commits it, or aborts it on failure. retryable reports whether the
+// statement failed with an error it may not hit if run again in a new
+// transaction, such as a deadlock; failures to begin or commit are never
+// retried.
+func (db *Database) runImplicitTransaction(query string, args []any, steps tracing.StepObserver, startTime time.Time, cacheable bool, cacheKey string, cacheVersion uint64) (res QueryResult, retryable bool, err error) {
+	log := db.logger
+
+	tx, err := db.begin("ExecuteQuery")
+	if isClosedError(err) {
+		log.Warn("query rejected, database is closed")
+		return res, false, err
+	}
 	if err != nil {
 		dbErr := dberror.Wrap(err, "TX_BEGIN_FAILED", "ExecuteQuery", "TransactionRegistry")
 		dbErr.Category = dberror.ErrCategoryTransient
 		dbErr.Detail = "Failed to start a new transaction"
 		dbErr.Hint = "Try again. If the problem persists, check system resources"
 		log.Error("transaction begin failed", "error", err)
-		return res, dbErr
+		return res, false, dbErr
 	}
+	// Registered before cleanupTransaction so the trace also covers the abort.
+	defer db.finishTrace(tx)
 	defer db.cleanupTransaction(tx, &err)
 
-	txLog := logging.WithTx(int(tx.ID.ID())).With("component", "database")
+	sessionID := db.sessions.Start(query, tx.ID.ID())
+	defer db.sessions.End(sessionID)
+
+	var result QueryResult
+	var trace *tracing.Trace
+	result, trace, err = db.runStatement(tx, query, args, steps, startTime)
+	if err != nil {
+		return QueryResult{}, dberror.IsRetryable(err), err
+	}
+
+	txLog := db.txLogger(tx)
+	if cacheable {
+		db.cacheResult(tx, cacheKey, query, cacheVersion, result)
+	}
+
+	commitSpan := trace.StartSpan("commit")
+	err = db.pageStore.CommitTransaction(tx)
+	commitSpan.End()
+	if err != nil {
+		db.recordError()
+		dbErr := dberror.Wrap(err, "COMMIT_FAILED", "ExecuteQuery", "PageStore")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = "Failed to commit transaction changes to disk"
+		dbErr.Hint = "This may be a temporary issue. Retry the operation"
+		txLog.Error("commit failed", "error", err)
+		return QueryResult{}, false, dbErr
+	}
+	result.Buffered = !db.pageStore.IsCommitDurable(tx)
+
+	elapsed := db.recordQuery(query, result, startTime)
+	txLog.Info("query completed successfully", "duration_ms", elapsed.Milliseconds(), "rows_affected", result.RowsAffected)
+	return result, false, nil
+}
+
+// runStatement parses, binds args to, plans and executes query within tx
+// without committing it, reporting the operator steps to a non-nil steps
+// observer. Failures are counted in the database statistics and returned as
+// DBErrors; the caller decides whether to commit or abort tx. A panic while
+// the statement runs is returned as a QUERY_PANIC error (see
+// recoverStatement), after which tx must be aborted.
+func (db *Database) runStatement(tx *transaction.TransactionContext, query string, args []any, steps tracing.StepObserver, startTime time.Time) (result QueryResult, trace *tracing.Trace, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = QueryResult{}, db.recoverStatement(tx, query, r)
+		}
+	}()
+	return db.execStatement(tx, query, args, steps, startTime)
+}
 
+// execStatement is runStatement without the recovery of panics.
+func (db *Database) execStatement(tx *transaction.TransactionContext, query string, args []any, steps tracing.StepObserver, startTime time.Time) (QueryResult, *tracing.Trace, error) {
+	txLog := db.txLogger(tx)
+
+	parseStart := time.Now()
 	stmt, err := parser.ParseStatement(query)
 	if err != nil {
 		db.recordError()
@@ -156,11 +437,57 @@ func (db *Database) ExecuteQuery(query string) (QueryResult, error) {
 		dbErr.Detail = fmt.Sprintf("Invalid SQL syntax in query: %s", query)
 		dbErr.Hint = "Check your SQL syntax. Common issues: missing semicolon, typos in keywords, unmatched quotes"
 		txLog.Error("parse error", "error", err)
-		return QueryResult{}, dbErr
+		return QueryResult{}, nil, dbErr
+	}
+	trace := db.startTrace(tx, stmt, steps, startTime, parseStart)
+
+	if isEngineStatement(stmt) {
+		db.recordError()
+		return QueryResult{}, trace, newEngineStatementError(stmt)
+	}
+
+	if db.readOnly && !isReadOnlyStatement(stmt) {
+		db.recordError()
+		txLog.Warn("write rejected in read-only mode", "statement_type", stmt.GetType().String())
+		return QueryResult{}, trace, newReadOnlyError(stmt.GetType().String())
+	}
+
+	if db.walInstance.DiskSpaceLow() && !isReadOnlyStatement(stmt) {
+		db.recordError()
+		txLog.Warn("write rejected while the WAL disk is below its reserve", "statement_type", stmt.GetType().String())
+		return QueryResult{}, trace, newDiskSpaceLowError(stmt.GetType().String(), nil)
+	}
+
+	if tx.IsReadOnly() && !isReadOnlyStatement(stmt) {
+		db.recordError()
+		txLog.Warn("write rejected in read-only transaction", "statement_type", stmt.GetType().String())
+		return QueryResult{}, trace, newReadOnlyTransactionError(stmt.GetType().String())
+	}
+
+	tracker := db.memBudget.NewTracker()
+	tx.SetMemoryTracker(tracker)
+	temp := db.tempFiles.NewQuery()
+	tx.SetTempFiles(temp)
+	defer func() {
+		tx.SetMemoryTracker(nil)
+		tracker.Close()
+		tx.SetTempFiles(nil)
+		temp.Close()
+	}()
+
+	if err := db.queryPlanner.Bind(stmt, args, tx); err != nil {
+		db.recordError()
+		dbErr := dberror.Wrap(err, "BIND_ERROR", "ExecuteQuery", "QueryPlanner")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Detail = "Failed to bind query parameters"
+		dbErr.Hint = "Pass one argument per parameter ($1, $2, ... or ?), convertible to the type of the column it is used with"
+		txLog.Error("parameter binding failed", "error", err)
+		return QueryResult{}, trace, dbErr
 	}
 
-	var plan planner.Plan
-	plan, err = db.queryPlanner.Plan(stmt, tx)
+	planSpan := trace.StartSpan("plan")
+	plan, err := db.queryPlanner.Plan(stmt, tx)
+	planSpan.End()
 	if err != nil {
 		db.recordError()
 		dbErr := dberror.Wrap(err, "PLAN_ERROR", "ExecuteQuery", "QueryPlanner")
@@ -168,39 +495,269 @@ func (db *Database) ExecuteQuery(query string) (QueryResult, error) {
 		dbErr.Detail = "Failed to create query execution plan"
 		dbErr.Hint = "Verify that all referenced tables and columns exist"
 		txLog.Error("planning failed", "error", err)
-		return QueryResult{}, dbErr
+		return QueryResult{}, trace, dbErr
 	}
 
-	var result QueryResult
-	result, err = db.executePlan(plan, stmt)
+	execSpan := trace.StartSpan("execute")
+	result, err := db.executePlan(plan, stmt)
+	execSpan.End()
+	if errors.Is(err, membudget.ErrOutOfMemoryBudget) {
+		db.recordError()
+		txLog.Warn("query exceeded its memory budget", "error", err)
+		return QueryResult{}, trace, newOutOfMemoryBudgetError(err)
+	}
+	if errors.Is(err, tempfile.ErrTempQuotaExceeded) {
+		db.recordError()
+		txLog.Warn("query exceeded its temporary file quota", "error", err)
+		return QueryResult{}, trace, newTempQuotaError(err)
+	}
+	if errors.Is(err, wal.ErrDiskSpaceLow) {
+		db.recordError()
+		txLog.Warn("write rejected while the WAL disk is below its reserve", "error", err)
+		return QueryResult{}, trace, newDiskSpaceLowError(stmt.GetType().String(), err)
+	}
+	if errors.Is(err, accounting.ErrQuotaExceeded) {
+		db.recordError()
+		txLog.Warn("write exceeded the storage quota", "error", err)
+		return QueryResult{}, trace, newQuotaError(err)
+	}
 	if err != nil {
 		db.recordError()
 		dbErr := dberror.Wrap(err, "EXEC_ERROR", "ExecuteQuery", "Executor")
 		dbErr.Detail = "Failed to execute query plan"
 		txLog.Error("execution failed", "error", err)
-		return QueryResult{}, dbErr
+		return QueryResult{}, trace, dbErr
 	}
 
-	err = db.pageStore.CommitTransaction(tx)
-	if err != nil {
-		db.recordError()
-		dbErr := dberror.Wrap(err, "COMMIT_FAILED", "ExecuteQuery", "PageStore")
-		dbErr.Category = dberror.ErrCategoryTransient
-		dbErr.Detail = "Failed to commit transaction changes to disk"
-		dbErr.Hint = "This may be a temporary issue. Retry the operation"
-		txLog.Error("commit failed", "error", err)
-		return QueryResult{}, dbErr
+	if stmt.GetType().IsDDL() {
+		// A schema change can change the result of any query
+		db.resultCache.InvalidateAll()
 	}
 
-	elapsed := time.Since(startTime).Milliseconds()
+	return result, trace, nil
+}
+
+// recordQuery updates the query metrics and statement statistics for a
+// successful query and returns its elapsed time.
+func (db *Database) recordQuery(query string, result QueryResult, startTime time.Time) time.Duration {
+	elapsed := time.Since(startTime)
+	queryDuration.Observe(elapsed.Seconds())
+	rowsReturned.Add(int64(len(result.Rows)))
+	rowsAffected.Add(int64(result.RowsAffected))
+	db.statements.Record(query, elapsed, int64(len(result.Rows)+result.RowsAffected))
 	db.recordSuccess()
-	txLog.Info("query completed successfully", "duration_ms", elapsed, "rows_affected", result.RowsAffected)
-	return result, nil
+	return elapsed
+}
+
+// openDoubleWrite restores the pages of a batch a crash left in the
+// double-write buffer of the database, before anything reads them, and then
+// turns the buffer on if enabled is set.
+func openDoubleWrite(pageStore *memory.PageStore, fullPath string, enabled bool, log logging.ComponentLogger) error {
+	path := filepath.Join(fullPath, memory.DoubleWriteFile)
+	restored, err := pageStore.RecoverDoubleWrite(path)
+	if err != nil {
+		dbErr := dberror.Wrap(err, "DOUBLE_WRITE_RECOVERY_FAILED", "NewDatabase", "PageStore")
+		dbErr.Category = dberror.ErrCategoryData
+		dbErr.Detail = fmt.Sprintf("Failed to restore pages from the double-write buffer: %s", path)
+		dbErr.Hint = "Check the file system health; the pages in the buffer may be torn until it is restored"
+		log.Error("double-write recovery failed", "error", err, "path", path)
+		return dbErr
+	}
+	if restored > 0 {
+		log.Warn("restored pages from the double-write buffer", "count", restored, "path", path)
+	}
+
+	if !enabled {
+		return nil
+	}
+	if err := pageStore.EnableDoubleWrite(path); err != nil {
+		dbErr := dberror.Wrap(err, "DOUBLE_WRITE_FAILED", "NewDatabase", "PageStore")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = fmt.Sprintf("Failed to create the double-write buffer: %s", path)
+		log.Error("failed to enable double-write", "error", err, "path", path)
+		return dbErr
+	}
+	return nil
+}
+
+// openStorage prepares the database directory, loads the superblock and opens the WAL.
+// In read-only mode nothing is created: the directory and WAL file must already exist,
+// and a missing superblock falls back to the default settings. The returned cipher is
+// nil unless the database is encrypted at rest.
+func openStorage(fullPath, logDir string, opts Options) (*wal.WAL, *config.Store, *encryption.Cipher, error) {
+	log := opts.componentLogger("database")
+
+	if opts.ReadOnly {
+		if _, err := os.Stat(fullPath); err != nil {
+			dbErr := dberror.Wrap(err, "DB_NOT_FOUND", "NewDatabase", "Database")
+			dbErr.Category = dberror.ErrCategoryUser
+			dbErr.Detail = fmt.Sprintf("Database directory does not exist: %s", fullPath)
+			dbErr.Hint = "A read-only database must point at an existing data directory"
+			log.Error("database directory missing for read-only open", "error", err, "path", fullPath)
+			return nil, nil, nil, dbErr
+		}
+	} else if err := os.MkdirAll(fullPath, 0755); err != nil {
+		dbErr := dberror.Wrap(err, "DIR_CREATE_FAILED", "NewDatabase", "Database")
+		dbErr.Detail = fmt.Sprintf("Failed to create directory: %s", fullPath)
+		dbErr.Hint = "Check that the parent directory exists and you have write permissions"
+		log.Error("failed to create database directory", "error", err, "path", fullPath)
+		return nil, nil, nil, dbErr
+	}
+
+	settings, err := config.Open(fullPath, opts.ReadOnly)
+	if err != nil {
+		if dbErr, ok := err.(*dberror.DBError); ok {
+			log.Error("superblock validation failed", "error", err, "path", fullPath)
+			return nil, nil, nil, dbErr
+		}
+		dbErr := dberror.Wrap(err, "SUPERBLOCK_OPEN_FAILED", "NewDatabase", "Config")
+		dbErr.Detail = fmt.Sprintf("Failed to open superblock in: %s", fullPath)
+		log.Error("failed to open superblock", "error", err, "path", fullPath)
+		return nil, nil, nil, dbErr
+	}
+	log.Debug("superblock loaded", "path", settings.Path())
+
+	cipher, err := openCipher(settings, opts)
+	if err != nil {
+		log.Error("failed to open encryption keys", "error", err, "path", fullPath)
+		return nil, nil, nil, err
+	}
+
+	if opts.ReadOnly {
+		walInstance, err := wal.OpenReadOnlyWithCipher(vfs.OS, logDir, cipher)
+		if err != nil {
+			dbErr := dberror.Wrap(err, "WAL_INIT_FAILED", "NewDatabase", "WAL")
+			dbErr.Detail = fmt.Sprintf("Failed to open Write-Ahead Log read-only at: %s", logDir)
+			dbErr.Hint = "A read-only database requires an existing WAL file"
+			log.Error("read-only WAL open failed", "error", err, "log_dir", logDir)
+			return nil, nil, nil, dbErr
+		}
+		return walInstance, settings, cipher, nil
+	}
+
+	walInstance, err := wal.NewWALWithCipher(vfs.OS, logDir, settings.Settings().WALBufferSize, settings.Settings().SyncPolicy, cipher)
+	if err != nil {
+		dbErr := dberror.Wrap(err, "WAL_INIT_FAILED", "NewDatabase", "WAL")
+		dbErr.Detail = fmt.Sprintf("Failed to initialize Write-Ahead Log at: %s", logDir)
+		dbErr.Hint = "Ensure the log directory exists and has sufficient disk space"
+		log.Error("WAL initialization failed", "error", err, "log_dir", logDir)
+		return nil, nil, nil, dbErr
+	}
+	if err := walInstance.SetDirectIO(settings.Settings().DirectIO); err != nil {
+		walInstance.Close()
+		return nil, nil, nil, dberror.Wrap(err, "WAL_INIT_FAILED", "NewDatabase", "WAL")
+	}
+	walInstance.SetCompression(settings.Settings().WALCompression)
+	if err := walInstance.SetSyncInterval(settings.Settings().WALSyncInterval); err != nil {
+		walInstance.Close()
+		return nil, nil, nil, dberror.Wrap(err, "WAL_INIT_FAILED", "NewDatabase", "WAL")
+	}
+	if err := walInstance.SetDurability(settings.Settings().WALDurability); err != nil {
+		walInstance.Close()
+		return nil, nil, nil, dberror.Wrap(err, "WAL_INIT_FAILED", "NewDatabase", "WAL")
+	}
+	if err := walInstance.SetCommitWindow(settings.Settings().WALCommitWindow); err != nil {
+		walInstance.Close()
+		return nil, nil, nil, dberror.Wrap(err, "WAL_INIT_FAILED", "NewDatabase", "WAL")
+	}
+	if err := opts.Replication.Validate(); err != nil {
+		walInstance.Close()
+		dbErr := dberror.Wrap(err, "INVALID_REPLICATION_CONFIG", "NewDatabase", "WAL")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Detail = "Options.Replication cannot be satisfied"
+		dbErr.Hint = "Name each standby once and set a quorum no larger than the number of standbys"
+		log.Error("invalid replication configuration", "error", err)
+		return nil, nil, nil, dbErr
+	}
+	if err := opts.WALDiskReserve.Validate(); err != nil {
+		walInstance.Close()
+		dbErr := dberror.Wrap(err, "INVALID_DISK_RESERVE", "NewDatabase", "WAL")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Detail = "Options.WALDiskReserve is invalid"
+		log.Error("invalid WAL disk reserve", "error", err)
+		return nil, nil, nil, dbErr
+	}
+	if err := walInstance.SetBufferPolicy(opts.WALBuffer); err != nil {
+		walInstance.Close()
+		dbErr := dberror.Wrap(err, "INVALID_WAL_BUFFER", "NewDatabase", "WAL")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Detail = "Options.WALBuffer is invalid"
+		dbErr.Hint = "Set a maximum size of zero or between wal_buffer_size and wal.MaxBufferSize"
+		log.Error("invalid WAL buffer policy", "error", err)
+		return nil, nil, nil, dbErr
+	}
+	return walInstance, settings, cipher, nil
+}
+
+// registerSystemViews adds the database-level system views (SYS_SESSIONS,
+// SYS_CHECKPOINTER, SYS_STATEMENTS, SYS_AUTO_ANALYZE, SYS_RESULT_CACHE,
+// SYS_CARDINALITY_FEEDBACK and SYS_TABLE_STORAGE) to the views the context
+// already exposes.
+func (db *Database) registerSystemViews(ctx *registry.DatabaseContext) error {
+	sessionsView, err := sysview.NewSessionsView(db.sessions)
+	if err != nil {
+		return err
+	}
+	if err := ctx.SystemViews().Register(sessionsView); err != nil {
+		return err
+	}
+
+	checkpointerView, err := sysview.NewCheckpointerView(db.checkpointer)
+	if err != nil {
+		return err
+	}
+	if err := ctx.SystemViews().Register(checkpointerView); err != nil {
+		return err
+	}
+
+	statementsView, err := sysview.NewStatementsView(db.statements)
+	if err != nil {
+		return err
+	}
+	if err := ctx.SystemViews().Register(statementsView); err != nil {
+		return err
+	}
+
+	autoAnalyzeView, err := sysview.NewAutoAnalyzeView(db.statsManager)
+	if err != nil {
+		return err
+	}
+	if err := ctx.SystemViews().Register(autoAnalyzeView); err != nil {
+		return err
+	}
+
+	resultCacheView, err := sysview.NewResultCacheView(db.resultCache)
+	if err != nil {
+		return err
+	}
+	if err := ctx.SystemViews().Register(resultCacheView); err != nil {
+		return err
+	}
+
+	feedbackView, err := sysview.NewFeedbackView(ctx.CardinalityFeedback())
+	if err != nil {
+		return err
+	}
+	if err := ctx.SystemViews().Register(feedbackView); err != nil {
+		return err
+	}
+
+	tableStorageView, err := sysview.NewTableStorageView(db.StorageReport)
+	if err != nil {
+		return err
+	}
+	return ctx.SystemViews().Register(tableStorageView)
+}
+
+// ResetStatementStatistics discards the per-fingerprint statistics shown in
+// SYS_STATEMENTS.
+func (db *Database) ResetStatementStatistics() {
+	db.statements.Reset()
 }
 
 // loadExistingTables loads table metadata from disk
 func (db *Database) loadExistingTables() error {
-	log := logging.WithComponent("database").With("database", db.name)
+	log := db.logger
 	log.Info("loading existing tables", "data_dir", db.dataDir)
 
 	tx, err := db.txRegistry.Begin()
@@ -212,7 +769,13 @@ func (db *Database) loadExistingTables() error {
 		return dbErr
 	}
 
-	if err := db.catalogMgr.Initialize(tx); err != nil {
+	defer func() {
+		if err != nil {
+			db.pageStore.AbortTransaction(tx)
+		}
+	}()
+
+	if err = db.catalogMgr.Initialize(tx); err != nil {
 		dbErr := dberror.Wrap(err, "CATALOG_INIT_FAILED", "loadExistingTables", "CatalogManager")
 		dbErr.Category = dberror.ErrCategorySystem
 		dbErr.Detail = "Failed to initialize system catalog tables"
@@ -220,6 +783,13 @@ func (db *Database) loadExistingTables() error {
 		log.Error("catalog initialization failed", "error", err)
 		return dbErr
 	}
+	if err = db.pageStore.CommitTransaction(tx); err != nil {
+		dbErr := dberror.Wrap(err, "CATALOG_INIT_FAILED", "loadExistingTables", "PageStore")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = "Failed to commit system catalog initialization"
+		log.Error("catalog initialization commit failed", "error", err)
+		return dbErr
+	}
 	log.Info("catalog initialized successfully")
 
 	catalogTablesPath := filepath.Join(db.dataDir, CatalogTablesFile)
@@ -239,7 +809,13 @@ func (db *Database) loadExistingTables() error {
 		return dbErr
 	}
 
-	if err := db.catalogMgr.LoadAllTables(tx2); err != nil {
+	defer func() {
+		if err != nil {
+			db.pageStore.AbortTransaction(tx2)
+		}
+	}()
+
+	if err = db.catalogMgr.LoadAllTables(tx2); err != nil {
 		dbErr := dberror.Wrap(err, "TABLE_LOAD_FAILED", "loadExistingTables", "CatalogManager")
 		dbErr.Category = dberror.ErrCategoryData
 		dbErr.Detail = fmt.Sprintf("Failed to load tables from catalog file: %s", catalogTablesPath)
@@ -247,6 +823,13 @@ func (db *Database) loadExistingTables() error {
 		log.Error("failed to load tables from catalog", "error", err, "path", catalogTablesPath)
 		return dbErr
 	}
+	if err = db.pageStore.CommitTransaction(tx2); err != nil {
+		dbErr := dberror.Wrap(err, "TABLE_LOAD_FAILED", "loadExistingTables", "PageStore")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = "Failed to commit the transaction that loaded the tables"
+		log.Error("table load commit failed", "error", err)
+		return dbErr
+	}
 	log.Info("tables loaded successfully")
 
 	return nil
@@ -257,6 +840,7 @@ func (db *Database) recordError() {
 	db.stats.mutex.Lock()
 	db.stats.ErrorCount++
 	db.stats.mutex.Unlock()
+	queryErrors.Inc()
 }
 
 // recordSuccess updates success statistics
@@ -264,6 +848,7 @@ func (db *Database) recordSuccess() {
 	db.stats.mutex.Lock()
 	db.stats.QueriesExecuted++
 	db.stats.mutex.Unlock()
+	queriesTotal.Inc()
 }
 
 func (db *Database) executePlan(plan planner.Plan, stmt statements.Statement) (QueryResult, error) {
@@ -279,10 +864,13 @@ func (db *Database) executePlan(plan planner.Plan, stmt statements.Statement) (Q
 func (db *Database) GetTables() []string {
 	db.mutex.RLock()
 	defer db.mutex.RUnlock()
+	if db.closing {
+		return nil
+	}
 
 	tx, _ := db.txRegistry.Begin()
 	defer db.pageStore.CommitTransaction(tx)
-	names, _ := db.catalogMgr.ListAllTables(tx, true)
+	names, _ := db.catalogMgr.ListAllTables(tx)
 	return names
 }
 
@@ -312,7 +900,7 @@ func (db *Database) GetStatistics() DatabaseInfo {
 }
 
 func (db *Database) cleanupTransaction(tx *transaction.TransactionContext, err *error) {
-	log := logging.WithTx(int(tx.ID.ID())).With("component", "database")
+	log := db.txLogger(tx)
 
 	if *err != nil {
 		log.Debug("aborting transaction due to error")
@@ -326,8 +914,13 @@ func (db *Database) cleanupTransaction(tx *transaction.TransactionContext, err *
 }
 
 // UpdateTableStatistics manually triggers a statistics update for a table
-// This is useful for forcing an update after bulk operations
+// This is useful for forcing an update after bulk operations. It also resets
+// the table's modification counter, postponing the next auto-analyze.
 func (db *Database) UpdateTableStatistics(tableName string) error {
+	if db.readOnly {
+		return newReadOnlyError("UpdateTableStatistics")
+	}
+
 	tx, err := db.txRegistry.Begin()
 	if err != nil {
 		dbErr := dberror.Wrap(err, "TX_BEGIN_FAILED", "UpdateTableStatistics", "TransactionRegistry")
@@ -346,7 +939,7 @@ func (db *Database) UpdateTableStatistics(tableName string) error {
 		return dbErr
 	}
 
-	if err := db.catalogMgr.UpdateTableStatistics(tx, tableID); err != nil {
+	if err := db.statsManager.ForceUpdate(tx, tableID); err != nil {
 		dbErr := dberror.Wrap(err, "STATS_UPDATE_FAILED", "UpdateTableStatistics", "CatalogManager")
 		dbErr.Category = dberror.ErrCategorySystem
 		dbErr.Detail = fmt.Sprintf("Failed to update statistics for table '%s'", tableName)
@@ -388,13 +981,16 @@ func (db *Database) GetTableStatistics(tableName string) (*systemtable.TableStat
 		return nil, dbErr
 	}
 
+	if err = db.pageStore.CommitTransaction(tx); err != nil {
+		return nil, err
+	}
 	return stats, nil
 }
 
 // BeginTransaction starts a new transaction
 func (db *Database) BeginTransaction() (*transaction.TransactionContext, error) {
-	log := logging.WithComponent("database").With("database", db.name)
-	tx, err := db.txRegistry.Begin()
+	log := db.logger
+	tx, err := db.begin("BeginTransaction")
 	if err != nil {
 		log.Error("failed to begin transaction", "error", err)
 		return nil, err
@@ -403,9 +999,24 @@ func (db *Database) BeginTransaction() (*transaction.TransactionContext, error)
 	return tx, nil
 }
 
+// BeginReadOnlyTransaction starts a transaction that can only read. It takes
+// shared locks only and writes nothing to the WAL, which makes it cheaper
+// than BeginTransaction for long scans; checkpoints do not list it as
+// active. Statements that would write fail with a READ_ONLY_VIOLATION error.
+func (db *Database) BeginReadOnlyTransaction() (*transaction.TransactionContext, error) {
+	log := db.logger
+	tx, err := db.beginWith("BeginReadOnlyTransaction", db.txRegistry.BeginReadOnly)
+	if err != nil {
+		log.Error("failed to begin read-only transaction", "error", err)
+		return nil, err
+	}
+	log.Info("read-only transaction started", "tx_id", tx.ID.ID())
+	return tx, nil
+}
+
 // CommitTransaction commits a transaction
 func (db *Database) CommitTransaction(tx *transaction.TransactionContext) error {
-	log := logging.WithTx(int(tx.ID.ID())).With("component", "database")
+	log := db.txLogger(tx)
 	log.Info("committing transaction")
 
 	err := db.pageStore.CommitTransaction(tx)
@@ -418,40 +1029,79 @@ func (db *Database) CommitTransaction(tx *transaction.TransactionContext) error
 	return nil
 }
 
-func (db *Database) Close() error {
-	log := logging.WithComponent("database").With("database", db.name)
-	log.Info("closing database")
-
-	db.mutex.Lock()
-	defer db.mutex.Unlock()
+// AbortTransaction rolls back a transaction
+func (db *Database) AbortTransaction(tx *transaction.TransactionContext) error {
+	log := db.txLogger(tx)
+	log.Info("aborting transaction")
 
-	if db.statsManager != nil {
-		log.Debug("stopping statistics manager")
-		db.statsManager.Stop()
+	if err := db.pageStore.AbortTransaction(tx); err != nil {
+		log.Error("abort failed", "error", err)
+		return err
 	}
 
-	log.Debug("flushing all pages")
-	if err := db.pageStore.FlushAllPages(); err != nil {
-		dbErr := dberror.Wrap(err, "PAGE_FLUSH_FAILED", "Close", "PageStore")
-		dbErr.Category = dberror.ErrCategorySystem
-		dbErr.Detail = "Failed to flush dirty pages to disk during shutdown"
-		dbErr.Hint = "Check disk space and file system health. Some data may not be persisted"
-		log.Error("failed to flush pages", "error", err)
-		return dbErr
+	log.Info("transaction aborted")
+	return nil
+}
+
+// ExecuteInTransaction runs query with args inside tx, a transaction started
+// with BeginTransaction, without committing it. Each statement is atomic: if
+// it fails, the changes it made so far are rolled back and tx carries on with
+// the changes of its earlier statements, ready for the next statement,
+// CommitTransaction or AbortTransaction. Should the failed statement not
+// roll back on its own, tx is aborted and a STATEMENT_ROLLBACK_FAILED error
+// is returned. A statement that panics fails with a QUERY_PANIC error and
+// aborts tx too, since it may have stopped anywhere.
+func (db *Database) ExecuteInTransaction(tx *transaction.TransactionContext, query string, args ...any) (QueryResult, error) {
+	if tx == nil || !tx.IsActive() {
+		db.recordError()
+		dbErr := dberror.New(dberror.ErrCategoryUser, "TX_NOT_ACTIVE", "transaction is not active")
+		dbErr.Detail = "Statements can only run in a transaction that has not committed or aborted"
+		dbErr.Hint = "Start a new transaction with BeginTransaction"
+		return QueryResult{}, dbErr
 	}
 
-	log.Debug("closing WAL")
-	if err := db.walInstance.Close(); err != nil {
-		dbErr := dberror.Wrap(err, "WAL_CLOSE_FAILED", "Close", "WAL")
-		dbErr.Category = dberror.ErrCategorySystem
-		dbErr.Detail = "Failed to close Write-Ahead Log during shutdown"
-		log.Error("failed to close WAL", "error", err)
-		return dbErr
+	release, err := db.admit("ExecuteInTransaction")
+	if err != nil {
+		return QueryResult{}, err
 	}
+	defer release()
 
-	log.Debug("clearing catalog cache")
-	db.catalogMgr.ClearCache()
+	start := time.Now()
+	sessionID := db.sessions.Start(query, tx.ID.ID())
+	defer db.sessions.End(sessionID)
+	defer func() {
+		db.finishTrace(tx)
+		tx.SetTrace(nil)
+	}()
 
-	log.Info("database closed successfully")
-	return nil
+	db.pageStore.BeginStatement(tx)
+	result, _, err := db.runStatement(tx, query, args, nil, start)
+	if IsQueryPanicError(err) {
+		// The statement stopped anywhere, so its changes cannot be trusted
+		// to roll back alone
+		if abortErr := db.pageStore.AbortTransaction(tx); abortErr != nil {
+			db.txLogger(tx).Error("failed to abort transaction", "error", abortErr)
+		}
+		return QueryResult{}, err
+	}
+	if err != nil {
+		db.dbCtx.TupleManager().RollbackStatement(tx)
+		if rollbackErr := db.pageStore.RollbackStatement(tx); rollbackErr != nil {
+			txLog := db.txLogger(tx)
+			txLog.Error("statement rollback failed, aborting transaction", "error", rollbackErr)
+			if abortErr := db.pageStore.AbortTransaction(tx); abortErr != nil {
+				txLog.Error("failed to abort transaction", "error", abortErr)
+			}
+			dbErr := dberror.Wrap(rollbackErr, "STATEMENT_ROLLBACK_FAILED", "ExecuteInTransaction", "PageStore")
+			dbErr.Category = dberror.ErrCategoryTransient
+			dbErr.Detail = fmt.Sprintf("The statement failed (%v) and its changes could not be undone alone, so the transaction was aborted", err)
+			dbErr.Hint = "Start a new transaction and run its statements again"
+			return QueryResult{}, dbErr
+		}
+		return QueryResult{}, err
+	}
+	db.pageStore.EndStatement(tx)
+
+	db.recordQuery(query, result, start)
+	return result, nil
 }
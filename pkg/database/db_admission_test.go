@@ -0,0 +1,39 @@
+package database
+
+import (
+	"path/filepath"
+	"storemy/pkg/concurrency/admission"
+	"testing"
+	"time"
+)
+
+func TestAdmission_RejectsWhenSaturated(t *testing.T) {
+	tempDir := t.TempDir()
+	opts := testOptions()
+	opts.Admission = admission.Config{MaxConcurrent: 1, QueueTimeout: 10 * time.Millisecond}
+
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	defer db.Close()
+	mustExec(t, db, "CREATE TABLE users (id INT)")
+
+	// Hold the only slot, as a long-running query would
+	release, err := db.admit("test")
+	if err != nil {
+		t.Fatalf("admit failed: %v", err)
+	}
+
+	_, err = db.ExecuteQuery("SELECT id FROM users")
+	if !IsAdmissionError(err) {
+		t.Fatalf("expected an ADMISSION_REJECTED error, got %v", err)
+	}
+
+	release()
+	mustExec(t, db, "SELECT id FROM users")
+
+	if stats := db.admission.Stats(); stats.TimedOut != 1 || stats.Running != 0 {
+		t.Errorf("unexpected admission stats %+v", stats)
+	}
+}
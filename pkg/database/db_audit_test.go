@@ -0,0 +1,98 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAudit_RecordsRowChanges(t *testing.T) {
+	db := setupTableLockDB(t, time.Second)
+	mustExec(t, db,
+		"ALTER TABLE users ENABLE AUDIT",
+		"INSERT INTO users VALUES (2, 'bob')",
+		"UPDATE users SET name = 'robert' WHERE id = 2",
+		"DELETE FROM users WHERE id = 1",
+	)
+
+	result, err := db.ExecuteQuery("SELECT AUDIT_TIME, AUDIT_OP, OLD_ROW, NEW_ROW FROM users_audit")
+	if err != nil {
+		t.Fatalf("SELECT FROM USERS_AUDIT failed: %v", err)
+	}
+
+	expected := [][]string{
+		{"INSERT", "", `{"ID":2,"NAME":"BOB"}`},
+		{"UPDATE", `{"ID":2,"NAME":"BOB"}`, `{"ID":2,"NAME":"ROBERT"}`},
+		{"DELETE", `{"ID":1,"NAME":"ALICE"}`, ""},
+	}
+	if len(result.Rows) != len(expected) {
+		t.Fatalf("expected %d audit rows, got %v", len(expected), result.Rows)
+	}
+	for i, want := range expected {
+		row := result.Rows[i]
+		if row[0] == "" {
+			t.Errorf("audit row %d has no time", i)
+		}
+		for j, value := range want {
+			if row[j+1] != value {
+				t.Errorf("audit row %d column %d = %q, expected %q", i, j+1, row[j+1], value)
+			}
+		}
+	}
+}
+
+func TestAudit_RolledBackChangesAreNotAudited(t *testing.T) {
+	db := setupTableLockDB(t, time.Second)
+	mustExec(t, db, "ALTER TABLE users ENABLE AUDIT")
+
+	if _, err := db.ExecuteQuery("INSERT INTO users VALUES (2, 'bob'), (3)"); err == nil {
+		t.Fatal("expected the INSERT to fail")
+	}
+	if got := sortedColumn(t, db, "SELECT AUDIT_OP FROM users_audit"); len(got) != 0 {
+		t.Errorf("expected no audit rows, got %v", got)
+	}
+}
+
+func TestAudit_ResultCacheSeesAuditRows(t *testing.T) {
+	db := setupResultCacheDB(t)
+	mustExec(t, db, "ALTER TABLE users ENABLE AUDIT")
+
+	query := "SELECT AUDIT_OP FROM users_audit"
+	if got := sortedColumn(t, db, query); len(got) != 0 {
+		t.Fatalf("expected no audit rows, got %v", got)
+	}
+
+	// The audit row invalidates the cached result over the audit table
+	mustExec(t, db, "INSERT INTO users VALUES (3, 'carol')")
+	if got := sortedColumn(t, db, query); len(got) != 1 || got[0] != "INSERT" {
+		t.Errorf("expected one INSERT audit row, got %v", got)
+	}
+	mustExec(t, db, "DELETE FROM users WHERE id = 3")
+	if got := sortedColumn(t, db, query); len(got) != 2 {
+		t.Errorf("expected two audit rows, got %v", got)
+	}
+}
+
+func TestAudit_DisableKeepsAuditTable(t *testing.T) {
+	db := setupTableLockDB(t, time.Second)
+	mustExec(t, db, "ALTER TABLE users ENABLE AUDIT", "INSERT INTO users VALUES (2, 'bob')")
+
+	if _, err := db.ExecuteQuery("DROP TABLE users_audit"); err == nil || !strings.Contains(err.Error(), "audit table") {
+		t.Errorf("expected dropping the audit table to fail, got %v", err)
+	}
+	if _, err := db.ExecuteQuery("ALTER TABLE users_audit ENABLE AUDIT"); err == nil {
+		t.Error("expected auditing an audit table to fail")
+	}
+
+	mustExec(t, db, "ALTER TABLE users DISABLE AUDIT", "INSERT INTO users VALUES (3, 'carol')")
+	if got := sortedColumn(t, db, "SELECT AUDIT_OP FROM users_audit"); len(got) != 1 {
+		t.Errorf("expected 1 audit row after DISABLE AUDIT, got %v", got)
+	}
+
+	// Enabling again reuses the audit table and its rows
+	mustExec(t, db, "ALTER TABLE users ENABLE AUDIT", "DELETE FROM users WHERE id = 3")
+	if got := sortedColumn(t, db, "SELECT AUDIT_OP FROM users_audit"); len(got) != 2 {
+		t.Errorf("expected 2 audit rows, got %v", got)
+	}
+	mustExec(t, db, "ALTER TABLE users DISABLE AUDIT", "DROP TABLE users_audit")
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"storemy/pkg/catalog"
+	"testing"
+)
+
+func insertRows(t *testing.T, db *Database, table string, from, to int) {
+	t.Helper()
+	for i := from; i <= to; i++ {
+		mustExec(t, db, fmt.Sprintf("INSERT INTO %s (id) VALUES (%d)", table, i))
+	}
+}
+
+func TestAutoAnalyze_RefreshesAfterFractionChanged(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.statsManager.SetAutoAnalyzeConfig(catalog.AutoAnalyzeConfig{Fraction: 0.5, MinChanges: 2})
+
+	mustExec(t, db, "CREATE TABLE readings (id INT)")
+	insertRows(t, db, "readings", 1, 4)
+	if err := db.UpdateTableStatistics("READINGS"); err != nil {
+		t.Fatalf("UpdateTableStatistics failed: %v", err)
+	}
+
+	// 4 analyzed rows: the table is due after 2 + 0.5*4 = 4 changes.
+	insertRows(t, db, "readings", 5, 6)
+	mustExec(t, db, "DELETE FROM readings WHERE id = 1")
+	if n := db.statsManager.RunAutoAnalyze(); n != 0 {
+		t.Fatalf("expected no table to be due after 3 changes, analyzed %d", n)
+	}
+
+	result, err := db.ExecuteQuery("SELECT table_name, modifications, threshold FROM sys_auto_analyze")
+	if err != nil {
+		t.Fatalf("SELECT from SYS_AUTO_ANALYZE failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0] != "READINGS" || result.Rows[0][1] != "3" || result.Rows[0][2] != "4" {
+		t.Errorf("unexpected SYS_AUTO_ANALYZE rows: %v", result.Rows)
+	}
+
+	mustExec(t, db, "UPDATE readings SET id = 10 WHERE id = 2")
+	if n := db.statsManager.RunAutoAnalyze(); n != 1 {
+		t.Fatalf("expected the table to be analyzed once 4 rows changed, analyzed %d", n)
+	}
+
+	stats, err := db.GetTableStatistics("READINGS")
+	if err != nil {
+		t.Fatalf("GetTableStatistics failed: %v", err)
+	}
+	if stats.Cardinality != 5 {
+		t.Errorf("expected refreshed cardinality 5, got %d", stats.Cardinality)
+	}
+
+	activity := db.statsManager.Activity()
+	if len(activity) != 1 {
+		t.Fatalf("expected activity for one table, got %+v", activity)
+	}
+	if a := activity[0]; a.Modifications != 0 || a.Threshold != 4 || a.AutoAnalyzeCount != 1 || a.LastAnalyze.IsZero() {
+		t.Errorf("unexpected activity after auto-analyze: %+v", a)
+	}
+}
+
+func TestAutoAnalyze_ForgetsDroppedTables(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db, "CREATE TABLE scratch (id INT)")
+	insertRows(t, db, "scratch", 1, 3)
+	mustExec(t, db, "DROP TABLE scratch")
+
+	if n := db.statsManager.RunAutoAnalyze(); n != 0 {
+		t.Errorf("expected the dropped table not to be analyzed, analyzed %d", n)
+	}
+	if activity := db.statsManager.Activity(); len(activity) != 0 {
+		t.Errorf("expected the dropped table to be forgotten, got %+v", activity)
+	}
+}
+
+func TestAutoAnalyze_SettingsPersist(t *testing.T) {
+	dataDir := t.TempDir()
+	logDir := filepath.Join(dataDir, "wal.log")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	for name, value := range map[string]string{
+		"auto_analyze_fraction":    "0.25",
+		"auto_analyze_min_changes": "7",
+	} {
+		if _, err := db.settings.Set(name, value); err != nil {
+			t.Fatalf("Set(%s) failed: %v", name, err)
+		}
+	}
+	db.Close()
+
+	db, err = NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer db.Close()
+
+	mustExec(t, db, "CREATE TABLE t (id INT)")
+	insertRows(t, db, "t", 1, 1)
+	activity := db.statsManager.Activity()
+	if len(activity) != 1 || activity[0].Threshold != 7 {
+		t.Errorf("expected a threshold of 7 from the persisted settings, got %+v", activity)
+	}
+}
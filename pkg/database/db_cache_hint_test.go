@@ -0,0 +1,66 @@
+package database
+
+import (
+	"path/filepath"
+	"storemy/pkg/memory"
+	"storemy/pkg/primitives"
+	"testing"
+)
+
+func cachePriority(t *testing.T, db *Database, table string) (primitives.FileID, memory.CachePriority) {
+	t.Helper()
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.CommitTransaction(tx)
+
+	tableID, err := db.catalogMgr.GetTableID(tx, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tableID, db.pageStore.CachePriority(tableID)
+}
+
+func TestCacheHint_PersistsAcrossReopen(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir, logDir := filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	mustExec(t, db,
+		"CREATE TABLE regions (id INT, name STRING)",
+		"CREATE TABLE orders (id INT, region INT)",
+		"ALTER TABLE regions SET CACHE PINNED",
+		"ALTER TABLE orders SET CACHE HIGH",
+		"ALTER TABLE orders SET CACHE NORMAL",
+	)
+	if _, got := cachePriority(t, db, "REGIONS"); got != memory.CachePinned {
+		t.Errorf("REGIONS priority = %s, expected pinned", got)
+	}
+	if _, err := db.ExecuteQuery("ALTER TABLE missing SET CACHE HIGH"); err == nil {
+		t.Error("expected an error for a missing table")
+	}
+	db.Close()
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if _, got := cachePriority(t, reopened, "REGIONS"); got != memory.CachePinned {
+		t.Errorf("REGIONS priority after reopen = %s, expected pinned", got)
+	}
+	if _, got := cachePriority(t, reopened, "ORDERS"); got != memory.CacheNormal {
+		t.Errorf("ORDERS priority after reopen = %s, expected normal", got)
+	}
+
+	// A table created again, at the same path, does not inherit the hint
+	mustExec(t, reopened, "DROP TABLE regions", "CREATE TABLE regions (id INT)")
+	if _, got := cachePriority(t, reopened, "REGIONS"); got != memory.CacheNormal {
+		t.Errorf("recreated REGIONS priority = %s, expected normal", got)
+	}
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// estimatedRows returns the "Estimated Rows" line of an EXPLAIN output.
+func estimatedRows(t *testing.T, db *Database, query string) string {
+	t.Helper()
+	result, err := db.ExecuteQuery(query)
+	if err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+	for line := range strings.SplitSeq(result.Rows[0][0], "\n") {
+		if strings.HasPrefix(line, "Estimated Rows:") {
+			return line
+		}
+	}
+	t.Fatalf("no estimated rows in:\n%s", result.Rows[0][0])
+	return ""
+}
+
+func TestCardinalityFeedback_CorrectsRecurringPredicates(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Skewed data: 190 of the 200 orders are open
+	mustExec(t, db, "CREATE TABLE orders (id INT, status STRING)")
+	values := make([]string, 0, 200)
+	for i := range 200 {
+		status := "open"
+		if i%20 == 0 {
+			status = "closed"
+		}
+		values = append(values, fmt.Sprintf("(%d, '%s')", i, status))
+	}
+	mustExec(t, db, "INSERT INTO orders VALUES "+strings.Join(values, ", "))
+
+	open := "SELECT * FROM orders WHERE status = 'open'"
+	closed := "SELECT * FROM orders WHERE status = 'closed'"
+	closedBefore := estimatedRows(t, db, "EXPLAIN "+closed)
+
+	if _, err := db.ExecuteQuery("EXPLAIN ANALYZE " + open); err != nil {
+		t.Fatalf("EXPLAIN ANALYZE failed: %v", err)
+	}
+
+	if got := estimatedRows(t, db, "EXPLAIN "+open); got != "Estimated Rows: 190" {
+		t.Errorf("expected the estimate to be corrected to the observed rows, got %q", got)
+	}
+	if got := estimatedRows(t, db, "EXPLAIN "+closed); got != closedBefore {
+		t.Errorf("feedback for another literal changed the estimate from %q to %q", closedBefore, got)
+	}
+
+	result, err := db.ExecuteQuery("SELECT table_name, predicates, actual_rows, samples FROM SYS_CARDINALITY_FEEDBACK")
+	if err != nil {
+		t.Fatalf("querying SYS_CARDINALITY_FEEDBACK failed: %v", err)
+	}
+	if len(result.Rows) != 1 {
+		t.Fatalf("expected one feedback entry, got %v", result.Rows)
+	}
+	row := result.Rows[0]
+	if row[0] != "ORDERS" || row[1] != "ORDERS.STATUS = OPEN" || row[2] != "190" || row[3] != "1" {
+		t.Errorf("unexpected feedback entry %v", row)
+	}
+}
+
+func TestCardinalityFeedback_SkipsLimitedScans(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE items (id INT)",
+		"INSERT INTO items VALUES (1), (2), (3), (4)")
+
+	if _, err := db.ExecuteQuery("EXPLAIN ANALYZE SELECT * FROM items WHERE id > 0 LIMIT 1"); err != nil {
+		t.Fatalf("EXPLAIN ANALYZE failed: %v", err)
+	}
+	if entries := db.dbCtx.CardinalityFeedback().Snapshot(); len(entries) != 0 {
+		t.Errorf("a scan stopped by LIMIT must not be recorded, got %+v", entries)
+	}
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func setupCaseDB(t *testing.T) *Database {
+	t.Helper()
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	mustExec(t, db,
+		"CREATE TABLE people (id INT, name STRING, age INT)",
+		"INSERT INTO people (id, name, age) VALUES (1, 'alice', 70)",
+		"INSERT INTO people (id, name, age) VALUES (2, 'bob', 12)",
+		"INSERT INTO people (id, name, age) VALUES (3, 'carol', 40)",
+		"INSERT INTO people (id, name, age) VALUES (4, 'dave', 17)",
+	)
+	return db
+}
+
+func TestCase_SelectList(t *testing.T) {
+	db := setupCaseDB(t)
+
+	result, err := db.ExecuteQuery("SELECT name, CASE WHEN age < 18 THEN 'minor' WHEN age < 65 THEN 'adult' ELSE 'senior' END AS bracket FROM people ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.Columns, []string{"NAME", "BRACKET"}) {
+		t.Errorf("expected columns [NAME BRACKET], got %v", result.Columns)
+	}
+
+	expected := [][]string{{"ALICE", "SENIOR"}, {"BOB", "MINOR"}, {"CAROL", "ADULT"}, {"DAVE", "MINOR"}}
+	if len(result.Rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %v", len(expected), result.Rows)
+	}
+	for i, row := range result.Rows {
+		if !slices.Equal(row, expected[i]) {
+			t.Errorf("row %d: got %v, expected %v", i, row, expected[i])
+		}
+	}
+
+	got := selectNames(t, db, "SELECT CASE id WHEN 1 THEN 'first' END AS label FROM people WHERE id <= 2")
+	if !slices.Equal(got, []string{"FIRST", "NULL"}) {
+		t.Errorf("expected [FIRST NULL] from a simple CASE without ELSE, got %v", got)
+	}
+}
+
+func TestCase_WhereAndOrderBy(t *testing.T) {
+	db := setupCaseDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT name FROM people WHERE CASE WHEN age < 18 THEN 'minor' ELSE 'adult' END = 'minor'", []string{"BOB", "DAVE"}},
+		{"SELECT name FROM people WHERE CASE WHEN age > 60 OR age < 13 THEN TRUE ELSE FALSE END", []string{"ALICE", "BOB"}},
+		{"SELECT name FROM people WHERE id = CASE WHEN age >= 65 THEN 1 ELSE 0 END", []string{"ALICE"}},
+	}
+	for _, tt := range tests {
+		if got := selectNames(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+
+	// Minors first, then everyone else.
+	result, err := db.ExecuteQuery("SELECT name, age FROM people ORDER BY CASE WHEN age < 18 THEN 0 ELSE 1 END")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, row := range result.Rows {
+		got = append(got, row[0])
+	}
+	if len(got) != 4 || !slices.Contains(got[:2], "BOB") || !slices.Contains(got[:2], "DAVE") {
+		t.Errorf("expected minors first, got %v", got)
+	}
+}
+
+func TestCase_UpdateAndDelete(t *testing.T) {
+	db := setupCaseDB(t)
+
+	mustExec(t, db,
+		"UPDATE people SET id = 0 WHERE CASE WHEN age < 18 THEN 'minor' ELSE 'adult' END = 'minor'",
+		"DELETE FROM people WHERE CASE WHEN age >= 65 THEN TRUE ELSE FALSE END",
+	)
+
+	if got := selectNames(t, db, "SELECT name FROM people WHERE id = 0"); !slices.Equal(got, []string{"BOB", "DAVE"}) {
+		t.Errorf("expected UPDATE ... WHERE CASE to change BOB and DAVE, got %v", got)
+	}
+	if n := countRows(t, db, "people"); n != 3 {
+		t.Errorf("expected DELETE ... WHERE CASE to remove 1 row, got %d rows", n)
+	}
+}
+
+func TestCase_CheckConstraint(t *testing.T) {
+	db := setupCaseDB(t)
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tableID, err := db.catalogMgr.GetTableID(tx, "PEOPLE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.catalogMgr.CreateCheckConstraint(tx, tableID, "ck_minor_ids", "ID,AGE",
+		"CASE WHEN age < 18 THEN id >= 100 ELSE TRUE END"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	mustExec(t, db,
+		"INSERT INTO people (id, name, age) VALUES (100, 'erin', 10)",
+		"INSERT INTO people (id, name, age) VALUES (5, 'frank', 30)",
+	)
+	if _, err := db.ExecuteQuery("INSERT INTO people (id, name, age) VALUES (6, 'gina', 9)"); err == nil {
+		t.Error("expected an INSERT violating the CASE CHECK constraint to fail")
+	}
+	if n := countRows(t, db, "people"); n != 6 {
+		t.Errorf("expected 6 rows, got %d", n)
+	}
+}
+
+func TestCase_AggregatesRejected(t *testing.T) {
+	db := setupCaseDB(t)
+
+	if _, err := db.ExecuteQuery("SELECT COUNT(id), CASE WHEN age < 18 THEN 'minor' END FROM people"); err == nil {
+		t.Error("expected CASE combined with an aggregate to fail")
+	}
+}
@@ -0,0 +1,207 @@
+package database
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"storemy/pkg/fsck"
+	"storemy/pkg/types"
+)
+
+func setupCheckDB(t *testing.T) *Database {
+	t.Helper()
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	for _, q := range []string{
+		"CREATE TABLE users (id INT PRIMARY KEY, name TEXT, age INT)",
+		"CREATE TABLE orders (id INT PRIMARY KEY, user_id INT, amount INT)",
+		"CREATE INDEX idx_age ON users (age) USING HASH",
+		// One row per statement: multi-row inserts currently leave all but the
+		// last key out of B+tree indexes, which the checker would report.
+		"INSERT INTO users (id, name, age) VALUES (1, 'alice', 30)",
+		"INSERT INTO users (id, name, age) VALUES (2, 'bob', 25)",
+		"INSERT INTO users (id, name, age) VALUES (3, 'carol', 30)",
+		"INSERT INTO orders (id, user_id, amount) VALUES (1, 1, 10)",
+		"INSERT INTO orders (id, user_id, amount) VALUES (2, 3, 20)",
+		"INSERT INTO orders (id, user_id, amount) VALUES (3, 9, 30)",
+	} {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	return db
+}
+
+func runCheck(t *testing.T, db *Database) *fsck.Report {
+	t.Helper()
+	report, err := db.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	return report
+}
+
+func requireFinding(t *testing.T, report *fsck.Report, sev fsck.Severity, check, substr string) {
+	t.Helper()
+	for _, f := range report.Filter(check) {
+		if f.Severity == sev && strings.Contains(f.Message, substr) {
+			return
+		}
+	}
+	t.Errorf("expected %s %s finding containing %q, got:\n%s", sev, check, substr, report)
+}
+
+func tableFilePath(t *testing.T, db *Database, table string) string {
+	t.Helper()
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.CommitTransaction(tx)
+
+	md, err := db.catalogMgr.GetTableMetadataByName(tx, table)
+	if err != nil {
+		t.Fatalf("table %s: %v", table, err)
+	}
+	return string(md.FilePath)
+}
+
+func TestCheck_CleanDatabase(t *testing.T) {
+	db := setupCheckDB(t)
+	report := runCheck(t, db)
+
+	if !report.OK() || len(report.Findings) != 0 {
+		t.Fatalf("expected a clean report, got:\n%s", report)
+	}
+	if report.TablesChecked != 2 {
+		t.Errorf("expected 2 tables checked, got %d", report.TablesChecked)
+	}
+	if report.IndexesChecked != 3 {
+		t.Errorf("expected 3 indexes checked, got %d", report.IndexesChecked)
+	}
+	if report.ConstraintsChecked != 2 {
+		t.Errorf("expected 2 primary keys checked, got %d", report.ConstraintsChecked)
+	}
+	if report.TuplesChecked < 6 {
+		t.Errorf("expected at least 6 tuples checked, got %d", report.TuplesChecked)
+	}
+	if !strings.HasPrefix(report.String(), "OK:") {
+		t.Errorf("unexpected summary: %s", report)
+	}
+}
+
+func TestCheck_MissingAndOrphanFiles(t *testing.T) {
+	db := setupCheckDB(t)
+
+	if err := os.Remove(tableFilePath(t, db, "ORDERS")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(db.dataDir, "GHOST.dat"), make([]byte, 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := runCheck(t, db)
+	if report.OK() {
+		t.Fatalf("expected errors, got:\n%s", report)
+	}
+	requireFinding(t, report, fsck.SeverityError, fsck.CheckCatalogFiles, "heap file")
+	requireFinding(t, report, fsck.SeverityWarning, fsck.CheckCatalogFiles, "not referenced by the catalog")
+}
+
+func TestCheck_CorruptHeapPage(t *testing.T) {
+	db := setupCheckDB(t)
+	path := tableFilePath(t, db, "USERS")
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Point the first slot into the slot pointer array.
+	var ptr [2]byte
+	binary.LittleEndian.PutUint16(ptr[:], 2)
+	if _, err := f.WriteAt(ptr[:], 0); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	report := runCheck(t, db)
+	requireFinding(t, report, fsck.SeverityError, fsck.CheckHeapPages, "slot pointer array")
+	if len(report.Filter(fsck.CheckIndexes)) != 0 {
+		t.Errorf("index findings should be suppressed for unreadable pages:\n%s", report)
+	}
+}
+
+func TestCheck_IndexOutOfSync(t *testing.T) {
+	db := setupCheckDB(t)
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tableID, err := db.catalogMgr.GetTableID(tx, "USERS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes, err := db.dbCtx.IndexManager().NewLoader(tx).LoadIndexes(tableID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iwm := range indexes {
+		if iwm.Metadata().IndexName != "IDX_AGE" {
+			continue
+		}
+		key := types.NewIntField(25)
+		rids, err := iwm.Index().Search(key)
+		if err != nil || len(rids) != 1 {
+			t.Fatalf("expected one entry for age 25, got %v, %v", rids, err)
+		}
+		if err := iwm.Index().Delete(key, rids[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := iwm.Index().Insert(types.NewIntField(30), rids[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	report := runCheck(t, db)
+	requireFinding(t, report, fsck.SeverityError, fsck.CheckIndexes, "has no index entry")
+	requireFinding(t, report, fsck.SeverityError, fsck.CheckIndexes, "holds a different key")
+}
+
+func TestCheck_ConstraintViolations(t *testing.T) {
+	db := setupCheckDB(t)
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	usersID, _ := db.catalogMgr.GetTableID(tx, "USERS")
+	ordersID, _ := db.catalogMgr.GetTableID(tx, "ORDERS")
+	if _, err := db.catalogMgr.CreateUniqueConstraint(tx, usersID, "uq_users_age", "AGE"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.catalogMgr.CreateForeignKeyConstraint(tx, ordersID, "fk_orders_user", "USER_ID", usersID, "ID", "RESTRICT", "RESTRICT"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.catalogMgr.CreateCheckConstraint(tx, ordersID, "ck_amount", "AMOUNT", "AMOUNT > 0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	report := runCheck(t, db)
+	requireFinding(t, report, fsck.SeverityError, fsck.CheckConstraints, "share key (30)")
+	requireFinding(t, report, fsck.SeverityError, fsck.CheckConstraints, "references (9)")
+	requireFinding(t, report, fsck.SeverityInfo, fsck.CheckConstraints, "not evaluated")
+	if got := report.Count(fsck.SeverityError); got != 2 {
+		t.Errorf("expected exactly 2 errors, got %d:\n%s", got, report)
+	}
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checksumOf returns the row count and checksum CHECKSUM TABLE reports for
+// table.
+func checksumOf(t *testing.T, db *Database, table string) (string, string) {
+	t.Helper()
+	result, err := db.ExecuteQuery("CHECKSUM TABLE " + table)
+	if err != nil {
+		t.Fatalf("CHECKSUM TABLE %s failed: %v", table, err)
+	}
+	if len(result.Rows) != 1 {
+		t.Fatalf("expected one row for %s, got %v", table, result.Rows)
+	}
+	return result.Rows[0][1], result.Rows[0][2]
+}
+
+func TestChecksumTable_IgnoresRowOrder(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	mustExec(t, db,
+		"CREATE TABLE a (id INT, name STRING)",
+		"CREATE TABLE b (id INT, name STRING)",
+		"INSERT INTO a (id, name) VALUES (1, 'ada')",
+		"INSERT INTO a (id, name) VALUES (2, 'alan')",
+		"INSERT INTO a (id, name) VALUES (2, 'alan')",
+		"INSERT INTO b (id, name) VALUES (2, 'alan')",
+		"INSERT INTO b (id, name) VALUES (1, 'ada')",
+		"INSERT INTO b (id, name) VALUES (2, 'alan')",
+	)
+
+	rowsA, sumA := checksumOf(t, db, "a")
+	rowsB, sumB := checksumOf(t, db, "b")
+	if rowsA != "3" || rowsB != "3" {
+		t.Errorf("expected 3 rows each, got %s and %s", rowsA, rowsB)
+	}
+	if sumA != sumB {
+		t.Errorf("expected equal checksums for the same rows, got %s and %s", sumA, sumB)
+	}
+
+	// A duplicate counts, and so does a changed value
+	mustExec(t, db, "DELETE FROM b WHERE id = 2", "INSERT INTO b (id, name) VALUES (2, 'alan')")
+	if _, sum := checksumOf(t, db, "b"); sum == sumA {
+		t.Error("expected removing a duplicate row to change the checksum")
+	}
+	mustExec(t, db,
+		"INSERT INTO b (id, name) VALUES (2, 'alan')",
+		"DELETE FROM b WHERE id = 1",
+		"INSERT INTO b (id, name) VALUES (1, 'grace')",
+	)
+	if _, sum := checksumOf(t, db, "b"); sum == sumA {
+		t.Error("expected a changed value to change the checksum")
+	}
+}
+
+func TestChecksumTable_SeveralTables(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	mustExec(t, db,
+		"CREATE TABLE a (id INT)",
+		"CREATE TABLE b (id INT)",
+		"INSERT INTO a (id) VALUES (1)",
+	)
+
+	result, err := db.ExecuteQuery("CHECKSUM TABLE a, b")
+	if err != nil {
+		t.Fatalf("CHECKSUM TABLE failed: %v", err)
+	}
+	if strings.Join(result.Columns, ",") != "table,rows,checksum" {
+		t.Errorf("unexpected columns %v", result.Columns)
+	}
+	if len(result.Rows) != 2 || result.Rows[0][0] != "A" || result.Rows[1][0] != "B" || result.Rows[1][1] != "0" {
+		t.Errorf("unexpected rows %v", result.Rows)
+	}
+
+	if _, err := db.ExecuteQuery("CHECKSUM TABLE missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected an error for a missing table, got %v", err)
+	}
+}
+
+func TestChecksumTable_SurvivesReopen(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	mustExec(t, db,
+		"CREATE TABLE t (id INT, name STRING)",
+		"INSERT INTO t (id, name) VALUES (1, 'ada')",
+		"INSERT INTO t (id, name) VALUES (2, 'grace')",
+		"DELETE FROM t WHERE id = 1",
+		"INSERT INTO t (id, name) VALUES (3, 'alan')",
+	)
+	_, before := checksumOf(t, db, "t")
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if _, after := checksumOf(t, reopened, "t"); after != before {
+		t.Errorf("expected checksum %s after reopen, got %s", before, after)
+	}
+}
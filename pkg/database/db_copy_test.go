@@ -0,0 +1,121 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"storemy/pkg/storage/page"
+	"strings"
+	"testing"
+)
+
+func writeCopyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestCopy_BulkLoadsEmptyTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ExecuteQuery("CREATE TABLE users (id INT, name STRING)"); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+
+	var rows strings.Builder
+	rows.WriteString("id;name\n")
+	for i := range 300 {
+		rows.WriteString(strings.Repeat("1", i%5+1) + ";user\n")
+	}
+	path := writeCopyFile(t, rows.String())
+
+	startLSN := db.walInstance.CurrentLSN()
+	result, err := db.ExecuteQuery("COPY users FROM '" + path + "' OPTIONS (header 'true', delimiter ';')")
+	if err != nil {
+		t.Fatalf("COPY failed: %v", err)
+	}
+	if result.RowsAffected != 300 || result.Message != "300 row(s) copied" {
+		t.Errorf("unexpected result: %d rows, %q", result.RowsAffected, result.Message)
+	}
+	if logged := db.walInstance.CurrentLSN() - startLSN; logged >= page.PageSize {
+		t.Errorf("expected a bulk load to log less than a page, logged %d bytes", logged)
+	}
+	if n := countRows(t, db, "users"); n != 300 {
+		t.Errorf("expected 300 rows, got %d", n)
+	}
+
+	// The table is no longer empty, so the rows are inserted one by one
+	if _, err := db.ExecuteQuery("COPY users FROM '" + path + "' OPTIONS (header 'true', delimiter ';')"); err != nil {
+		t.Fatalf("second COPY failed: %v", err)
+	}
+	if n := countRows(t, db, "users"); n != 600 {
+		t.Errorf("expected 600 rows, got %d", n)
+	}
+}
+
+func TestCopy_FailedLoadLeavesTableEmpty(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ExecuteQuery("CREATE TABLE users (id INT, name STRING)"); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+
+	path := writeCopyFile(t, "1,alice\n2,bob\nthree,carol\n")
+	if _, err := db.ExecuteQuery("COPY users FROM '" + path + "'"); err == nil {
+		t.Fatal("expected COPY of a malformed file to fail")
+	}
+	if n := countRows(t, db, "users"); n != 0 {
+		t.Errorf("expected no rows after a failed COPY, got %d", n)
+	}
+
+	if _, err := db.ExecuteQuery("COPY missing FROM '" + path + "'"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected COPY into a missing table to fail, got %v", err)
+	}
+}
+
+func TestCopy_GenerateSeriesIsDeterministic(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, table := range []string{"a", "b"} {
+		if _, err := db.ExecuteQuery("CREATE TABLE " + table + " (id INT, name STRING, age INT, price FLOAT)"); err != nil {
+			t.Fatalf("CREATE TABLE failed: %v", err)
+		}
+		result, err := db.ExecuteQuery("COPY " + table + " FROM GENERATE_SERIES(1, 500) OPTIONS (seed '7')")
+		if err != nil {
+			t.Fatalf("COPY failed: %v", err)
+		}
+		if result.RowsAffected != 500 {
+			t.Errorf("expected 500 rows copied, got %d", result.RowsAffected)
+		}
+	}
+
+	a, err := db.ExecuteQuery("SELECT * FROM a")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	b, err := db.ExecuteQuery("SELECT * FROM b")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(a.Rows) != 500 || len(b.Rows) != 500 {
+		t.Fatalf("expected 500 rows in each table, got %d and %d", len(a.Rows), len(b.Rows))
+	}
+	for i := range a.Rows {
+		if strings.Join(a.Rows[i], "|") != strings.Join(b.Rows[i], "|") {
+			t.Fatalf("row %d differs: %v vs %v", i, a.Rows[i], b.Rows[i])
+		}
+	}
+
+	young, err := db.ExecuteQuery("SELECT * FROM a WHERE a.age < 18")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(young.Rows) != 0 {
+		t.Errorf("expected generated ages of at least 18, got %d younger rows", len(young.Rows))
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setupGraphDB creates edges 1 -> 2 -> 3 -> 1, a cycle, and 2 -> 4.
+func setupGraphDB(t *testing.T) *Database {
+	t.Helper()
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	mustExec(t, db,
+		"CREATE TABLE edges (src INT, dst INT)",
+		"INSERT INTO edges (src, dst) VALUES (1, 2)",
+		"INSERT INTO edges (src, dst) VALUES (2, 3)",
+		"INSERT INTO edges (src, dst) VALUES (3, 1)",
+		"INSERT INTO edges (src, dst) VALUES (2, 4)",
+	)
+	return db
+}
+
+func TestCTE_Queries(t *testing.T) {
+	db := setupGraphDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"WITH late AS (SELECT src, dst FROM edges WHERE src > 1) SELECT dst FROM late", []string{"1", "3", "4"}},
+		{"WITH late AS (SELECT src, dst FROM edges WHERE src > 1) SELECT * FROM late WHERE dst = 4", []string{"2/4"}},
+		{"WITH a AS (SELECT dst FROM edges WHERE src = 2), b(n) AS (SELECT dst FROM a) SELECT n FROM b", []string{"3", "4"}},
+		{"WITH hub(node) AS (SELECT src FROM edges WHERE dst = 3) SELECT e.dst FROM edges e JOIN hub h ON e.src = h.node", []string{"3", "4"}},
+		{"WITH ends AS (SELECT dst FROM edges WHERE src = 2 UNION SELECT dst FROM edges WHERE src = 3) SELECT dst FROM ends", []string{"1", "3", "4"}},
+		{
+			// 1 reaches every node, itself through the cycle; UNION stops there
+			"WITH RECURSIVE reach(node) AS (SELECT dst FROM edges WHERE src = 1 UNION SELECT e.dst FROM edges e JOIN reach r ON e.src = r.node) SELECT node FROM reach",
+			[]string{"1", "2", "3", "4"},
+		},
+		{
+			"WITH RECURSIVE reach(node) AS (SELECT dst FROM edges WHERE src = 4 UNION SELECT e.dst FROM edges e JOIN reach r ON e.src = r.node) SELECT node FROM reach",
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestCTE_RecursionLimit(t *testing.T) {
+	db := setupGraphDB(t)
+
+	// UNION ALL keeps going around the cycle
+	query := "WITH RECURSIVE walk(node) AS (SELECT dst FROM edges WHERE src = 1 UNION ALL SELECT e.dst FROM edges e JOIN walk w ON e.src = w.node) SELECT node FROM walk"
+	mustExec(t, db, "SET PERSISTENT max_recursive_iterations = 5")
+
+	_, err := db.ExecuteQuery(query)
+	if err == nil || !strings.Contains(err.Error(), "max_recursive_iterations") {
+		t.Fatalf("expected the recursion limit to be reached, got %v", err)
+	}
+}
+
+func TestCTE_InvalidQueries(t *testing.T) {
+	db := setupGraphDB(t)
+
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"WITH pairs(a) AS (SELECT src, dst FROM edges) SELECT a FROM pairs", "2 columns but 1 names"},
+		{"WITH RECURSIVE r(n) AS (SELECT e.dst FROM edges e JOIN r ON e.src = r.n) SELECT n FROM r", "anchor UNION"},
+		{"WITH RECURSIVE r(n) AS (SELECT src FROM edges UNION SELECT e.src FROM edges e JOIN r ON e.src = r.n JOIN r r2 ON e.dst = r2.n) SELECT n FROM r", "exactly once"},
+		{"WITH x AS (SELECT src FROM edges), x AS (SELECT dst FROM edges) SELECT src FROM x", "more than once"},
+	}
+
+	for _, tt := range tests {
+		_, err := db.ExecuteQuery(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
+
+func TestCTE_Explain(t *testing.T) {
+	db := setupGraphDB(t)
+
+	result, err := db.ExecuteQuery("EXPLAIN WITH RECURSIVE reach(node) AS (SELECT dst FROM edges WHERE src = 1 UNION SELECT e.dst FROM edges e JOIN reach r ON e.src = r.node) SELECT node FROM reach")
+	if err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	if len(result.Rows) == 0 || !strings.Contains(result.Rows[0][0], "REACH") {
+		t.Errorf("expected the plan to scan REACH, got %v", result.Rows)
+	}
+}
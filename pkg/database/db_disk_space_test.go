@@ -0,0 +1,67 @@
+package database
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/log/wal"
+	"testing"
+)
+
+func TestDiskReserve_RefusesWritesWhileLow(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+	mustExec(t, db, "CREATE TABLE users (id INT)", "INSERT INTO users VALUES (1)")
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(tx, "INSERT INTO users VALUES (2)"); err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+
+	// No disk has this much room, so the reserve is never met
+	if err := db.walInstance.SetDiskReserve(wal.DiskReserve{MinFreeBytes: math.MaxInt64}); err != nil {
+		t.Fatalf("SetDiskReserve failed: %v", err)
+	}
+
+	_, err = db.ExecuteQuery("INSERT INTO users VALUES (3)")
+	if !IsDiskSpaceLowError(err) {
+		t.Fatalf("expected a DISK_SPACE_LOW error, got %v", err)
+	}
+	var dbErr *dberror.DBError
+	if !errors.As(err, &dbErr) || dbErr.Code != ErrCodeDiskSpaceLow {
+		t.Errorf("expected code %s, got %v", ErrCodeDiskSpaceLow, err)
+	}
+
+	// The running transaction still commits, and reads still work
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	if n := countRows(t, db, "users"); n != 2 {
+		t.Errorf("users has %d rows, want 2", n)
+	}
+
+	if err := db.walInstance.SetDiskReserve(wal.DiskReserve{}); err != nil {
+		t.Fatalf("SetDiskReserve failed: %v", err)
+	}
+	mustExec(t, db, "INSERT INTO users VALUES (3)")
+	if n := countRows(t, db, "users"); n != 3 {
+		t.Errorf("users has %d rows, want 3", n)
+	}
+}
+
+func TestDiskReserve_InvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+	opts := testOptions()
+	opts.WALDiskReserve = wal.DiskReserve{MinFreeBytes: -1}
+	if _, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), opts); err == nil {
+		t.Fatal("expected a negative disk reserve to be rejected")
+	}
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// orderedRows runs query and returns its rows as "col1/col2" strings in
+// result order.
+func orderedRows(t *testing.T, db *Database, query string) []string {
+	t.Helper()
+	result, err := db.ExecuteQuery(query)
+	if err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+
+	rows := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		rows = append(rows, strings.Join(row, "/"))
+	}
+	return rows
+}
+
+func TestDistinctOn(t *testing.T) {
+	db := setupGroupDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			"SELECT DISTINCT ON (dept) dept, id FROM staff ORDER BY salary DESC",
+			[]string{"ENG/3", "OPS/5", "HR/6"},
+		},
+		{
+			"SELECT DISTINCT ON (dept) id FROM staff ORDER BY age",
+			[]string{"4", "1", "6"},
+		},
+		{
+			"SELECT DISTINCT ON (band) CASE WHEN age < 30 THEN 'junior' ELSE 'senior' END AS band, id FROM staff ORDER BY salary",
+			[]string{"JUNIOR/4", "SENIOR/6"},
+		},
+		{
+			"SELECT DISTINCT ON (dept, salary > 100) dept, id FROM staff ORDER BY id",
+			[]string{"ENG/1", "ENG/2", "OPS/4", "OPS/5", "HR/6"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := orderedRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestDistinct_MultipleColumns(t *testing.T) {
+	db := setupGroupDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			"SELECT DISTINCT dept FROM staff",
+			[]string{"ENG", "HR", "OPS"},
+		},
+		{
+			"SELECT DISTINCT dept, CASE WHEN age < 30 THEN 'junior' ELSE 'senior' END AS band FROM staff",
+			[]string{"ENG/JUNIOR", "ENG/SENIOR", "HR/SENIOR", "OPS/JUNIOR", "OPS/SENIOR"},
+		},
+		{
+			"SELECT DISTINCT dept, CASE WHEN age > 60 THEN 'retiring' END AS plan FROM staff",
+			[]string{"ENG/NULL", "ENG/RETIRING", "HR/NULL", "OPS/NULL"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestDistinctOn_Errors(t *testing.T) {
+	db := setupGroupDB(t)
+
+	queries := []string{
+		"SELECT DISTINCT ON (dept) dept, COUNT(id) FROM staff GROUP BY dept",
+		"SELECT DISTINCT ON (missing) dept FROM staff",
+	}
+	for _, query := range queries {
+		if _, err := db.ExecuteQuery(query); err == nil {
+			t.Errorf("expected %s to fail", query)
+		}
+	}
+}
+
+func TestDistinctOn_Explain(t *testing.T) {
+	db := setupGroupDB(t)
+
+	result, err := db.ExecuteQuery("EXPLAIN SELECT DISTINCT ON (dept) dept, id FROM staff ORDER BY salary DESC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plan strings.Builder
+	for _, row := range result.Rows {
+		plan.WriteString(strings.Join(row, " ") + "\n")
+	}
+	if !strings.Contains(plan.String(), "Distinct ON (DEPT)") {
+		t.Errorf("expected EXPLAIN output to contain DISTINCT ON keys, got:\n%s", plan.String())
+	}
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"storemy/pkg/memory"
+	"testing"
+)
+
+func TestDoubleWrite_Lifecycle(t *testing.T) {
+	dir := t.TempDir()
+	dataDir, logDir := filepath.Join(dir, "data"), filepath.Join(dir, "logs")
+	opts := testOptions()
+	opts.DoubleWrite = true
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	mustExec(t, db, "CREATE TABLE users (id INT)", "INSERT INTO users VALUES (1)", "INSERT INTO users VALUES (2)")
+
+	// Between writes the buffer is empty
+	buffer := filepath.Join(dataDir, "testdb", memory.DoubleWriteFile)
+	info, err := os.Stat(buffer)
+	if err != nil {
+		t.Fatalf("double-write buffer missing while the database is open: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("double-write buffer holds %d bytes after the commits, want 0", info.Size())
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(buffer); !os.IsNotExist(err) {
+		t.Errorf("double-write buffer left behind by a clean shutdown: %v", err)
+	}
+
+	// A torn batch left behind is ignored, and the data is intact
+	if err := os.WriteFile(buffer, []byte("SMDW torn"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	db, err = NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	defer db.Close()
+	if n := countRows(t, db, "users"); n != 2 {
+		t.Errorf("users has %d rows after reopening, want 2", n)
+	}
+	if _, err := os.Stat(buffer); !os.IsNotExist(err) {
+		t.Errorf("double-write buffer not removed when the database was opened: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"storemy/pkg/encryption"
+	"testing"
+)
+
+func encryptionKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+// openEncryptedDB opens the database in dir with keys, failing the test on error.
+func openEncryptedDB(t *testing.T, dir string, keys encryption.KeyProvider) *Database {
+	t.Helper()
+	opts := testOptions()
+	opts.KeyProvider = keys
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	return db
+}
+
+// filesContaining returns the files under dir whose contents include text.
+func filesContaining(t *testing.T, dir, text string) []string {
+	t.Helper()
+	var found []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(data, []byte(text)) {
+			found = append(found, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDir failed: %v", err)
+	}
+	return found
+}
+
+func TestEncryption_DataIsEncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	keys := encryption.NewStaticKeys(1, encryptionKey(1))
+
+	db := openEncryptedDB(t, dir, keys)
+	if !db.IsEncrypted() {
+		t.Fatal("expected the database to be encrypted")
+	}
+	mustExec(t, db,
+		"CREATE TABLE secrets (id INT, value STRING)",
+		"CREATE INDEX idx_secrets_id ON secrets (id)",
+		"INSERT INTO secrets (id, value) VALUES (1, 'topsecretvalue')",
+		"INSERT INTO secrets (id, value) VALUES (2, 'anothersecret')",
+	)
+
+	if found := filesContaining(t, dir, "TOPSECRETVALUE"); len(found) > 0 {
+		t.Errorf("plaintext found in %v", found)
+	}
+	db.Close()
+
+	if found := filesContaining(t, dir, "TOPSECRETVALUE"); len(found) > 0 {
+		t.Errorf("plaintext found after close in %v", found)
+	}
+
+	db = openEncryptedDB(t, dir, keys)
+	defer db.Close()
+	got := sortedColumn(t, db, "SELECT value FROM secrets WHERE id >= 1")
+	if want := []string{"ANOTHERSECRET", "TOPSECRETVALUE"}; !slices.Equal(got, want) {
+		t.Errorf("after reopen got %v, expected %v", got, want)
+	}
+}
+
+func TestEncryption_OpenRequiresTheKey(t *testing.T) {
+	dir := t.TempDir()
+	db := openEncryptedDB(t, dir, encryption.NewStaticKeys(1, encryptionKey(1)))
+	mustExec(t, db, "CREATE TABLE secrets (id INT)")
+	db.Close()
+
+	dataDir, logDir := filepath.Join(dir, "data"), filepath.Join(dir, "logs")
+	if _, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions()); !IsEncryptionError(err) {
+		t.Errorf("expected an encryption error without a key, got %v", err)
+	}
+
+	opts := testOptions()
+	opts.KeyProvider = encryption.NewStaticKeys(1, encryptionKey(2))
+	if _, err := NewDatabaseWithOptions("testdb", dataDir, logDir, opts); !IsEncryptionError(err) {
+		t.Errorf("expected an encryption error with a wrong key, got %v", err)
+	}
+}
+
+func TestEncryption_CannotEncryptExistingDatabase(t *testing.T) {
+	dir := t.TempDir()
+	dataDir, logDir := filepath.Join(dir, "data"), filepath.Join(dir, "logs")
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	db.Close()
+
+	opts := testOptions()
+	opts.KeyProvider = encryption.NewStaticKeys(1, encryptionKey(1))
+	if _, err := NewDatabaseWithOptions("testdb", dataDir, logDir, opts); !IsEncryptionError(err) {
+		t.Errorf("expected an encryption error, got %v", err)
+	}
+}
+
+func TestEncryption_RotateKey(t *testing.T) {
+	dir := t.TempDir()
+	keys := encryption.NewStaticKeys(1, encryptionKey(1))
+	db := openEncryptedDB(t, dir, keys)
+	mustExec(t, db,
+		"CREATE TABLE secrets (id INT, value STRING)",
+		"INSERT INTO secrets (id, value) VALUES (1, 'one')",
+	)
+	if err := db.pageStore.FlushAllPages(); err != nil {
+		t.Fatalf("FlushAllPages failed: %v", err)
+	}
+
+	keys.Rotate(2, encryptionKey(2))
+	rekeyed, err := db.RotateEncryptionKey()
+	if err != nil {
+		t.Fatalf("RotateEncryptionKey failed: %v", err)
+	}
+	if rekeyed == 0 {
+		t.Error("expected pages to be re-encrypted")
+	}
+	if id := db.Settings().Encryption.KeyID; id != 2 {
+		t.Errorf("superblock key ID = %d, expected 2", id)
+	}
+	mustExec(t, db, "INSERT INTO secrets (id, value) VALUES (2, 'two')")
+	db.Close()
+
+	// The superblock now checks the new key, so the old one alone is rejected
+	opts := testOptions()
+	opts.KeyProvider = encryption.NewStaticKeys(1, encryptionKey(1))
+	if _, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), opts); !IsEncryptionError(err) {
+		t.Errorf("expected key 1 to be rejected after rotation, got %v", err)
+	}
+
+	db = openEncryptedDB(t, dir, keys)
+	defer db.Close()
+	got := sortedColumn(t, db, "SELECT value FROM secrets WHERE id >= 1")
+	if want := []string{"ONE", "TWO"}; !slices.Equal(got, want) {
+		t.Errorf("after rotation got %v, expected %v", got, want)
+	}
+}
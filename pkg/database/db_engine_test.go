@@ -0,0 +1,151 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func setupTestEngine(t *testing.T) (*Engine, string) {
+	t.Helper()
+	dataDir := filepath.Join(t.TempDir(), "data")
+
+	engine, err := NewEngine(dataDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	t.Cleanup(func() { engine.Close() })
+	return engine, dataDir
+}
+
+func mustExecSession(t *testing.T, s *Session, query string) QueryResult {
+	t.Helper()
+	result, err := s.ExecuteQuery(query)
+	if err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+	return result
+}
+
+func TestEngine_CreateDatabaseAndUse(t *testing.T) {
+	engine, dataDir := setupTestEngine(t)
+	if _, err := engine.CreateDatabase("main", false); err != nil {
+		t.Fatalf("CreateDatabase failed: %v", err)
+	}
+
+	session, err := engine.NewSession("main")
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	mustExecSession(t, session, "CREATE TABLE items (id INT, name STRING)")
+	mustExecSession(t, session, "INSERT INTO items VALUES (1, 'main')")
+
+	mustExecSession(t, session, "CREATE DATABASE shop")
+	mustExecSession(t, session, "USE shop")
+	if session.Database().name != "SHOP" {
+		t.Fatalf("expected current database SHOP, got %s", session.Database().name)
+	}
+
+	// Each database has its own catalog, so the same table name can be reused
+	mustExecSession(t, session, "CREATE TABLE items (id INT, name STRING)")
+	mustExecSession(t, session, "INSERT INTO items VALUES (2, 'shop')")
+	mustExecSession(t, session, "INSERT INTO items VALUES (3, 'shop')")
+
+	if result := mustExecSession(t, session, "SELECT id FROM items"); len(result.Rows) != 2 {
+		t.Errorf("expected 2 rows in SHOP.items, got %v", result.Rows)
+	}
+
+	mustExecSession(t, session, "USE main")
+	if result := mustExecSession(t, session, "SELECT id FROM items"); len(result.Rows) != 1 || result.Rows[0][0] != "1" {
+		t.Errorf("expected only row 1 in main.items, got %v", result.Rows)
+	}
+
+	for _, name := range []string{"main", "SHOP"} {
+		if _, err := os.Stat(filepath.Join(dataDir, name, DatabaseWALFile)); err != nil {
+			t.Errorf("expected %s to have its own WAL: %v", name, err)
+		}
+	}
+
+	names, err := engine.ListDatabases()
+	if err != nil {
+		t.Fatalf("ListDatabases failed: %v", err)
+	}
+	if !slices.Equal(names, []string{"SHOP", "main"}) {
+		t.Errorf("expected databases [SHOP main], got %v", names)
+	}
+}
+
+func TestEngine_Errors(t *testing.T) {
+	engine, _ := setupTestEngine(t)
+	session := func() *Session {
+		if _, err := engine.CreateDatabase("main", true); err != nil {
+			t.Fatalf("CreateDatabase failed: %v", err)
+		}
+		s, err := engine.NewSession("main")
+		if err != nil {
+			t.Fatalf("NewSession failed: %v", err)
+		}
+		return s
+	}()
+
+	if _, err := session.ExecuteQuery("USE missing"); !isDatabaseNotFoundError(err) {
+		t.Errorf("expected DATABASE_NOT_FOUND for USE of a missing database, got %v", err)
+	}
+	if session.Database().name != "main" {
+		t.Errorf("failed USE changed the current database to %s", session.Database().name)
+	}
+
+	if _, err := session.ExecuteQuery("CREATE DATABASE MAIN"); err == nil {
+		t.Error("expected CREATE DATABASE of an existing database to fail")
+	}
+	if _, err := session.ExecuteQuery("CREATE DATABASE IF NOT EXISTS main"); err != nil {
+		t.Errorf("CREATE DATABASE IF NOT EXISTS failed: %v", err)
+	}
+
+	for _, name := range []string{"", "../escape", "a/b", "1st"} {
+		if _, err := engine.CreateDatabase(name, false); err == nil {
+			t.Errorf("expected invalid database name %q to be rejected", name)
+		}
+	}
+
+	// A database on its own cannot switch databases
+	if _, err := session.Database().ExecuteQuery("USE main"); err == nil {
+		t.Error("expected USE sent directly to a database to fail")
+	}
+}
+
+func TestEngine_Reopen(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+
+	engine, err := NewEngine(dataDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if _, err := engine.CreateDatabase("shop", false); err != nil {
+		t.Fatalf("CreateDatabase failed: %v", err)
+	}
+	session, err := engine.NewSession("shop")
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	mustExecSession(t, session, "CREATE TABLE items (id INT)")
+	mustExecSession(t, session, "INSERT INTO items VALUES (7)")
+	if err := engine.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	engine, err = NewEngine(dataDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewEngine failed on reopen: %v", err)
+	}
+	defer engine.Close()
+
+	session, err = engine.NewSession("SHOP")
+	if err != nil {
+		t.Fatalf("NewSession failed on reopen: %v", err)
+	}
+	if result := mustExecSession(t, session, "SELECT id FROM items"); len(result.Rows) != 1 || result.Rows[0][0] != "7" {
+		t.Errorf("expected row 7 after reopening, got %v", result.Rows)
+	}
+}
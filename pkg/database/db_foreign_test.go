@@ -0,0 +1,155 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func createForeignVisits(t *testing.T, db *Database, path string) {
+	t.Helper()
+	content := "visit_id,user_id,page\n1,1,home\n2,2,search\n3,1,checkout\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	query := "CREATE FOREIGN TABLE visits (visit_id INT, user_id INT, page STRING) " +
+		"OPTIONS (format 'csv', location '" + path + "', header 'true')"
+	if _, err := db.ExecuteQuery(query); err != nil {
+		t.Fatalf("CREATE FOREIGN TABLE failed: %v", err)
+	}
+}
+
+func TestForeignTable_Select(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	createForeignVisits(t, db, filepath.Join(t.TempDir(), "Visits.csv"))
+
+	if n := countRows(t, db, "visits"); n != 3 {
+		t.Errorf("expected 3 rows, got %d", n)
+	}
+
+	result, err := db.ExecuteQuery("SELECT page FROM visits WHERE user_id = 1")
+	if err != nil {
+		t.Fatalf("filtered SELECT failed: %v", err)
+	}
+	var pages []string
+	for _, row := range result.Rows {
+		pages = append(pages, row[0])
+	}
+	slices.Sort(pages)
+	if !slices.Equal(pages, []string{"checkout", "home"}) {
+		t.Errorf("pages = %v, want [checkout home]", pages)
+	}
+}
+
+func TestForeignTable_JoinWithStoredTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	createForeignVisits(t, db, filepath.Join(t.TempDir(), "visits.csv"))
+	for _, q := range []string{
+		"CREATE TABLE users (id INT, name STRING)",
+		"INSERT INTO users (id, name) VALUES (1, 'alice')",
+		"INSERT INTO users (id, name) VALUES (2, 'bob')",
+	} {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("%s failed: %v", q, err)
+		}
+	}
+
+	result, err := db.ExecuteQuery("SELECT users.name, visits.page FROM users JOIN visits ON users.id = visits.user_id")
+	if err != nil {
+		t.Fatalf("JOIN failed: %v", err)
+	}
+	if len(result.Rows) != 3 {
+		t.Errorf("expected 3 joined rows, got %d: %v", len(result.Rows), result.Rows)
+	}
+}
+
+func TestForeignTable_ReadOnlyAndNameConflicts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "visits.csv")
+	createForeignVisits(t, db, path)
+
+	if _, err := db.ExecuteQuery("INSERT INTO visits (visit_id, user_id, page) VALUES (4, 3, 'home')"); err == nil ||
+		!strings.Contains(err.Error(), "foreign table") {
+		t.Errorf("expected INSERT into a foreign table to fail, got %v", err)
+	}
+
+	if _, err := db.ExecuteQuery("CREATE TABLE visits (id INT)"); err == nil {
+		t.Error("expected CREATE TABLE with a foreign table's name to fail")
+	}
+
+	query := "CREATE FOREIGN TABLE visits (id INT) OPTIONS (format 'csv', location '" + path + "')"
+	if _, err := db.ExecuteQuery(query); err == nil {
+		t.Error("expected a duplicate CREATE FOREIGN TABLE to fail")
+	}
+	if _, err := db.ExecuteQuery(strings.Replace(query, "TABLE visits", "TABLE IF NOT EXISTS visits", 1)); err != nil {
+		t.Errorf("expected IF NOT EXISTS to succeed, got %v", err)
+	}
+}
+
+func TestForeignTable_DropKeepsFile(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "visits.csv")
+	createForeignVisits(t, db, path)
+
+	if _, err := db.ExecuteQuery("DROP TABLE visits"); err != nil {
+		t.Fatalf("DROP TABLE failed: %v", err)
+	}
+	if _, err := db.ExecuteQuery("SELECT * FROM visits"); err == nil {
+		t.Error("expected SELECT from a dropped foreign table to fail")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the external file to remain: %v", err)
+	}
+}
+
+func TestForeignTable_BadFileReportsLine(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "visits.csv")
+	createForeignVisits(t, db, path)
+	if err := os.WriteFile(path, []byte("visit_id,user_id,page\n1,x,home\n"), 0o644); err != nil {
+		t.Fatalf("failed to rewrite file: %v", err)
+	}
+
+	_, err := db.ExecuteQuery("SELECT * FROM visits")
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error naming line 2, got %v", err)
+	}
+}
+
+func TestForeignTable_SurvivesReopen(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	createForeignVisits(t, db, filepath.Join(tempDir, "visits.csv"))
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if n := countRows(t, reopened, "visits"); n != 3 {
+		t.Errorf("expected 3 rows after reopen, got %d", n)
+	}
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func setupGroupDB(t *testing.T) *Database {
+	t.Helper()
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	mustExec(t, db,
+		"CREATE TABLE staff (id INT, dept STRING, age INT, salary INT)",
+		"INSERT INTO staff (id, dept, age, salary) VALUES (1, 'eng', 25, 100)",
+		"INSERT INTO staff (id, dept, age, salary) VALUES (2, 'eng', 40, 200)",
+		"INSERT INTO staff (id, dept, age, salary) VALUES (3, 'eng', 70, 300)",
+		"INSERT INTO staff (id, dept, age, salary) VALUES (4, 'ops', 16, 50)",
+		"INSERT INTO staff (id, dept, age, salary) VALUES (5, 'ops', 35, 150)",
+		"INSERT INTO staff (id, dept, age, salary) VALUES (6, 'hr', 50, 120)",
+	)
+	return db
+}
+
+// groupRows runs query and returns its rows as "col1/col2" strings, sorted.
+func groupRows(t *testing.T, db *Database, query string) []string {
+	t.Helper()
+	result, err := db.ExecuteQuery(query)
+	if err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+
+	rows := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		rows = append(rows, strings.Join(row, "/"))
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+func TestGroupBy_Expressions(t *testing.T) {
+	db := setupGroupDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			"SELECT COUNT(id) FROM staff GROUP BY CASE WHEN age < 30 THEN 'junior' ELSE 'senior' END",
+			[]string{"JUNIOR/2", "SENIOR/4"},
+		},
+		{
+			"SELECT CASE WHEN age < 30 THEN 'junior' ELSE 'senior' END AS band, SUM(salary) FROM staff GROUP BY band",
+			[]string{"JUNIOR/150", "SENIOR/770"},
+		},
+		{
+			"SELECT COUNT(id) FROM staff GROUP BY CASE WHEN age > 60 THEN 'retiring' END",
+			[]string{"NULL/5", "RETIRING/1"},
+		},
+		{
+			"SELECT COUNT(*) FROM staff GROUP BY salary >= 150",
+			[]string{"false/3", "true/3"},
+		},
+		{
+			"SELECT dept, COUNT(id) FROM staff GROUP BY dept",
+			[]string{"ENG/3", "HR/1", "OPS/2"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestGroupBy_Having(t *testing.T) {
+	db := setupGroupDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY dept HAVING COUNT(id) > 1", []string{"ENG/3", "OPS/2"}},
+		{"SELECT dept, SUM(salary) FROM staff GROUP BY dept HAVING SUM(salary) >= 200 AND dept <> 'eng'", []string{"OPS/200"}},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY staff.dept HAVING staff.dept = 'hr'", []string{"HR/1"}},
+		{
+			"SELECT CASE WHEN age < 30 THEN 'junior' ELSE 'senior' END AS band, COUNT(id) FROM staff GROUP BY band HAVING band = 'junior'",
+			[]string{"JUNIOR/2"},
+		},
+		{
+			"SELECT dept, MAX(age) FROM staff GROUP BY dept HAVING CASE WHEN MAX(age) > 60 THEN TRUE ELSE FALSE END",
+			[]string{"ENG/70"},
+		},
+		{"SELECT COUNT(id) FROM staff HAVING COUNT(id) > 10", nil},
+		{"SELECT COUNT(id) FROM staff HAVING COUNT(id) > 5", []string{"6"}},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestGroupBy_HavingErrors(t *testing.T) {
+	db := setupGroupDB(t)
+
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"SELECT dept FROM staff HAVING dept = 'eng'", "HAVING requires an aggregate"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY dept HAVING age > 30", "must appear in GROUP BY"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY dept HAVING SUM(salary) > 30", "must be the aggregate function of the SELECT list"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY dept HAVING COUNT(id)", "boolean"},
+		{"SELECT CASE WHEN age < 30 THEN 'junior' END, COUNT(id) FROM staff GROUP BY dept", "must appear in GROUP BY"},
+		{"SELECT COUNT(id) FROM staff WHERE CASE WHEN COUNT(id) > 1 THEN TRUE END", "not allowed"},
+	}
+
+	for _, tt := range tests {
+		_, err := db.ExecuteQuery(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error", tt.query)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
+
+func TestGroupBy_ExplainHaving(t *testing.T) {
+	db := setupGroupDB(t)
+
+	result, err := db.ExecuteQuery("EXPLAIN SELECT dept, COUNT(id) FROM staff GROUP BY dept HAVING COUNT(id) > 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plan strings.Builder
+	for _, row := range result.Rows {
+		plan.WriteString(strings.Join(row, " ") + "\n")
+	}
+	for _, want := range []string{"Filter on COUNT(ID) > 1", "GROUP BY DEPT"} {
+		if !strings.Contains(plan.String(), want) {
+			t.Errorf("expected EXPLAIN output to contain %q, got:\n%s", want, plan.String())
+		}
+	}
+}
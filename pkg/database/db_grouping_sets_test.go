@@ -0,0 +1,81 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupingSets_Queries(t *testing.T) {
+	db := setupGroupDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT dept, SUM(salary) FROM staff GROUP BY ROLLUP (dept)", []string{"ENG/600", "HR/120", "NULL/920", "OPS/200"}},
+		{"SELECT dept, SUM(salary) FROM staff GROUP BY CUBE (dept)", []string{"ENG/600", "HR/120", "NULL/920", "OPS/200"}},
+		{"SELECT dept, MAX(age) FROM staff GROUP BY GROUPING SETS (dept, (), ())", []string{"ENG/70", "HR/50", "NULL/70", "NULL/70", "OPS/35"}},
+		{"SELECT COUNT(id) FROM staff GROUP BY GROUPING SETS (())", []string{"6"}},
+		{
+			"SELECT dept, COUNT(id), GROUPING(dept) FROM staff GROUP BY ROLLUP (dept)",
+			[]string{"ENG/3/0", "HR/1/0", "NULL/6/1", "OPS/2/0"},
+		},
+		{"SELECT dept, COUNT(id), GROUPING(dept) FROM staff GROUP BY dept", []string{"ENG/3/0", "HR/1/0", "OPS/2/0"}},
+		{
+			"SELECT CASE WHEN age < 30 THEN 'junior' ELSE 'senior' END AS band, COUNT(id) FROM staff GROUP BY ROLLUP (band)",
+			[]string{"JUNIOR/2", "NULL/6", "SENIOR/4"},
+		},
+		{
+			"SELECT dept, COUNT(id), GROUPING(dept) AS total FROM staff GROUP BY ROLLUP (dept) HAVING GROUPING(dept) = 1",
+			[]string{"NULL/6/1"},
+		},
+		// Without rows, only the empty set has a row
+		{"SELECT dept, COUNT(id) FROM staff WHERE age > 100 GROUP BY ROLLUP (dept)", []string{"NULL/0"}},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestGroupingSets_Errors(t *testing.T) {
+	db := setupGroupDB(t)
+
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY ROLLUP (dept, age)", "single key"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY GROUPING SETS ((dept), (age))", "single key"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY ROLLUP (COUNT(id))", "not allowed in GROUP BY"},
+		{"SELECT dept, COUNT(id), GROUPING(age) FROM staff GROUP BY ROLLUP (dept)", "must be the GROUP BY key"},
+		{"SELECT COUNT(id), GROUPING(dept) FROM staff", "must be the GROUP BY key"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY ROLLUP (dept) HAVING GROUPING(dept) = 1", "GROUPING call of the SELECT list"},
+	}
+
+	for _, tt := range tests {
+		_, err := db.ExecuteQuery(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
+
+func TestGroupingSets_Explain(t *testing.T) {
+	db := setupGroupDB(t)
+
+	result, err := db.ExecuteQuery("EXPLAIN SELECT dept, COUNT(id) FROM staff GROUP BY ROLLUP (dept)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) == 0 || !strings.Contains(result.Rows[0][0], "GROUP BY GROUPING SETS ((DEPT), ())") {
+		t.Errorf("expected the plan to show the grouping sets, got %v", result.Rows)
+	}
+}
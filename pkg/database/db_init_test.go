@@ -3,6 +3,8 @@ package database
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"storemy/pkg/catalog/systemtable"
 	"testing"
 )
 
@@ -17,7 +19,7 @@ func setupTestDBInit(t *testing.T, name string) (*Database, func()) {
 	dataDir := filepath.Join(tempDir, "data")
 	logDir := filepath.Join(tempDir, "logs")
 
-	db, err := NewDatabase(name, dataDir, logDir)
+	db, err := NewDatabaseWithOptions(name, dataDir, logDir, testOptions())
 	if err != nil {
 		os.RemoveAll(tempDir)
 		t.Fatalf("NewDatabase failed: %v", err)
@@ -73,7 +75,7 @@ func TestNewDatabase_DirectoryCreation(t *testing.T) {
 	dataDir := filepath.Join(tempDir, "data")
 	logDir := filepath.Join(tempDir, "logs")
 
-	db, err := NewDatabase("mydb", dataDir, logDir)
+	db, err := NewDatabaseWithOptions("mydb", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase failed: %v", err)
 	}
@@ -120,13 +122,13 @@ func TestNewDatabase_MultipleDatabases(t *testing.T) {
 	dataDir := filepath.Join(tempDir, "data")
 	logDir := filepath.Join(tempDir, "logs")
 
-	db1, err := NewDatabase("db1", dataDir, logDir)
+	db1, err := NewDatabaseWithOptions("db1", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase db1 failed: %v", err)
 	}
 	defer db1.Close()
 
-	db2, err := NewDatabase("db2", dataDir, logDir)
+	db2, err := NewDatabaseWithOptions("db2", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase db2 failed: %v", err)
 	}
@@ -146,26 +148,21 @@ func TestDatabase_GetTables_Empty(t *testing.T) {
 	db, cleanup := setupTestDBInit(t, "emptydb")
 	defer cleanup()
 
-	tables := db.GetTables()
-	// Should have exactly 3 catalog tables (CATALOG_TABLES and CATALOG_COLUMNS)
-	if len(tables) != 3 {
-		t.Errorf("expected 3 catalog tables, got %d", len(tables))
-	}
+	expectSystemTables(t, db.GetTables())
+}
 
-	// Verify they are the catalog tables
-	hasCatalogTables := false
-	hasCatalogColumns := false
-	for _, table := range tables {
-		if table == "CATALOG_TABLES" {
-			hasCatalogTables = true
-		}
-		if table == "CATALOG_COLUMNS" {
-			hasCatalogColumns = true
-		}
+// expectSystemTables checks that tables are exactly the system catalog tables.
+func expectSystemTables(t *testing.T, tables []string) {
+	t.Helper()
+	var expected []string
+	for _, table := range systemtable.AllSystemTables {
+		expected = append(expected, table.TableName())
 	}
+	slices.Sort(expected)
 
-	if !hasCatalogTables || !hasCatalogColumns {
-		t.Error("expected CATALOG_TABLES and CATALOG_COLUMNS to exist")
+	got := slices.Sorted(slices.Values(tables))
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected the system tables %v, got %v", expected, got)
 	}
 }
 
@@ -180,7 +177,7 @@ func TestDatabase_Close(t *testing.T) {
 	dataDir := filepath.Join(tempDir, "data")
 	logDir := filepath.Join(tempDir, "logs")
 
-	db, err := NewDatabase("closedb", dataDir, logDir)
+	db, err := NewDatabaseWithOptions("closedb", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase failed: %v", err)
 	}
@@ -202,11 +199,7 @@ func TestDatabase_LoadExistingTables_NoCatalogFile(t *testing.T) {
 	db, cleanup := setupTestDBInit(t, "nocatalog")
 	defer cleanup()
 
-	tables := db.GetTables()
-	// Should have exactly 3 catalog tables (CATALOG_TABLES and CATALOG_COLUMNS)
-	if len(tables) != 3 {
-		t.Errorf("expected 2 catalog tables, got %d", len(tables))
-	}
+	expectSystemTables(t, db.GetTables())
 }
 
 // TestDatabase_RecordError tests error recording
@@ -308,7 +301,7 @@ func TestDatabase_Name(t *testing.T) {
 	tests := []string{"db1", "test_database", "my-db", "db_123"}
 
 	for _, name := range tests {
-		db, err := NewDatabase(name, dataDir, logDir)
+		db, err := NewDatabaseWithOptions(name, dataDir, logDir, testOptions())
 		if err != nil {
 			t.Fatalf("NewDatabase failed for %s: %v", name, err)
 		}
@@ -403,7 +396,7 @@ func TestDatabase_DataDirPermissions(t *testing.T) {
 	dataDir := filepath.Join(tempDir, "data")
 	logDir := filepath.Join(tempDir, "logs")
 
-	db, err := NewDatabase("permdb", dataDir, logDir)
+	db, err := NewDatabaseWithOptions("permdb", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase failed: %v", err)
 	}
@@ -137,7 +137,7 @@ func TestIntegration_DatabasePersistence(t *testing.T) {
 	logDir := filepath.Join(tempDir, "logs")
 
 	// Create first database instance
-	db1, err := NewDatabase("persistdb", dataDir, logDir)
+	db1, err := NewDatabaseWithOptions("persistdb", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase failed: %v", err)
 	}
@@ -160,7 +160,7 @@ func TestIntegration_DatabasePersistence(t *testing.T) {
 	}
 
 	// Create second database instance (reload)
-	db2, err := NewDatabase("persistdb", dataDir, logDir)
+	db2, err := NewDatabaseWithOptions("persistdb", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase (reload) failed: %v", err)
 	}
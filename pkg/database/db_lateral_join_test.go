@@ -0,0 +1,99 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCrossJoin(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	got := groupRows(t, db, "SELECT name, oid FROM customers CROSS JOIN orders")
+	expected := []string{
+		"ADA/10", "ADA/11", "ADA/12",
+		"ALAN/10", "ALAN/11", "ALAN/12",
+		"GRACE/10", "GRACE/11", "GRACE/12",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestLateralJoin(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			"SELECT * FROM customers c, LATERAL (SELECT oid, total FROM orders o WHERE o.customer_id = c.id) x",
+			[]string{"1/ADA/10/100", "1/ADA/11/50"},
+		},
+		{
+			"SELECT * FROM customers c CROSS JOIN LATERAL (SELECT oid, total FROM orders o WHERE o.customer_id = c.id ORDER BY total LIMIT 1) cheapest",
+			[]string{"1/ADA/11/50"},
+		},
+		{
+			"SELECT * FROM customers c LEFT JOIN LATERAL (SELECT customer_id, total FROM orders o WHERE o.customer_id = c.id) x ON c.id = x.customer_id",
+			[]string{"1/ADA/1/100", "1/ADA/1/50", "2/ALAN/NULL/NULL", "3/GRACE/NULL/NULL"},
+		},
+		{
+			"SELECT * FROM customers c JOIN (SELECT customer_id, total FROM orders WHERE total > 60) big ON c.id = big.customer_id",
+			[]string{"1/ADA/1/100"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestLateralJoin_InvalidReferences(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{
+			"SELECT * FROM customers c CROSS JOIN (SELECT * FROM orders o WHERE o.customer_id = c.id) x",
+			"requires LATERAL",
+		},
+		{
+			"SELECT * FROM customers c, LATERAL (SELECT * FROM orders o WHERE o.customer_id = z.id) x",
+			"no table Z",
+		},
+		{
+			"SELECT * FROM customers c, LATERAL (SELECT * FROM orders o WHERE o.customer_id = c.missing) x",
+			"unknown column C.MISSING",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := db.ExecuteQuery(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
+
+func TestLateralJoin_Explain(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	result, err := db.ExecuteQuery("EXPLAIN SELECT * FROM customers c, LATERAL (SELECT oid FROM orders o WHERE o.customer_id = c.id) x")
+	if err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	out := result.Rows[0][0]
+	if !strings.Contains(out, "cross JOIN [LATERAL subquery X]") || !strings.Contains(out, "using nested loop") {
+		t.Errorf("expected lateral join annotation, got:\n%s", out)
+	}
+}
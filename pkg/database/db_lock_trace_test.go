@@ -0,0 +1,38 @@
+package database
+
+import (
+	"storemy/pkg/concurrency/lock"
+	"strings"
+	"testing"
+)
+
+func TestLockTrace_RecordsStatementLocks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE accounts (id INT, balance INT)",
+		"INSERT INTO accounts VALUES (1, 100)")
+
+	if db.LockTrace() != nil {
+		t.Fatal("expected lock tracing to be off by default")
+	}
+	db.StartLockTrace(0)
+	mustExec(t, db, "UPDATE accounts SET balance = 50 WHERE id = 1")
+	trace := db.StopLockTrace()
+
+	if trace == nil || db.LockTrace() != nil {
+		t.Fatal("expected StopLockTrace to return the trace and turn tracing off")
+	}
+
+	kinds := map[lock.EventKind]int{}
+	for _, e := range trace.Events() {
+		kinds[e.Kind]++
+	}
+	if kinds[lock.EventAcquired] == 0 || kinds[lock.EventReleased] == 0 {
+		t.Fatalf("expected the update to acquire and release locks, got:\n%s", trace.Timeline(nil))
+	}
+	if timeline := db.LockTimeline(trace); !strings.Contains(timeline, "X lock on ACCOUNTS:0") {
+		t.Errorf("expected an exclusive lock on the table's page in the timeline:\n%s", timeline)
+	}
+}
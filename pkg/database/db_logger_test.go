@@ -0,0 +1,31 @@
+package database
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"storemy/pkg/logging"
+	"strings"
+	"testing"
+)
+
+func TestOptions_InjectedLogger(t *testing.T) {
+	_, logDir, cleanup := setupReadOnlyDB(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	opts := testOptions()
+	opts.ReadOnly = true
+	opts.Logger = logging.NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(filepath.Dir(logDir), "data"), logDir, opts)
+	if err != nil {
+		t.Fatalf("read-only open failed: %v", err)
+	}
+	defer db.Close()
+
+	out := buf.String()
+	if !strings.Contains(out, "component=recovery") || !strings.Contains(out, "redo-only recovery completed") {
+		t.Errorf("expected recovery output on injected logger, got:\n%s", out)
+	}
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"storemy/pkg/execution/membudget"
+	"strings"
+	"testing"
+)
+
+// setupMemoryBudgetDB opens a database whose queries may hold at most
+// queryBytes, with a 200-row users table and a 200-row orders table.
+func setupMemoryBudgetDB(t *testing.T, queryBytes int64) (*Database, *membudget.Budget) {
+	t.Helper()
+	tempDir := t.TempDir()
+	budget := membudget.New(membudget.Config{QueryBytes: queryBytes})
+	opts := testOptions()
+	opts.MemoryBudget = budget
+
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mustExec(t, db, "CREATE TABLE users (id INT, name STRING)", "CREATE TABLE orders (id INT, user_id INT)")
+	users := make([]string, 0, 200)
+	orders := make([]string, 0, 200)
+	for i := range 200 {
+		users = append(users, fmt.Sprintf("(%d, 'user number %d')", i, i))
+		orders = append(orders, fmt.Sprintf("(%d, %d)", i, i))
+	}
+	mustExec(t, db,
+		"INSERT INTO users VALUES "+strings.Join(users, ", "),
+		"INSERT INTO orders VALUES "+strings.Join(orders, ", "))
+	return db, budget
+}
+
+func TestMemoryBudget_RejectsLargeQueries(t *testing.T) {
+	db, budget := setupMemoryBudgetDB(t, 4096)
+
+	queries := []string{
+		"SELECT id, name FROM users",
+		"SELECT name FROM users ORDER BY name LIMIT 1",
+		"SELECT DISTINCT name FROM users",
+		"SELECT name, COUNT(id) FROM users GROUP BY name",
+		"SELECT COUNT(users.id) FROM users JOIN orders ON users.id = orders.user_id",
+		"SELECT name FROM users UNION SELECT name FROM users",
+	}
+	for _, query := range queries {
+		_, err := db.ExecuteQuery(query)
+		if !IsOutOfMemoryBudgetError(err) {
+			t.Errorf("%s: expected an OUT_OF_MEMORY_BUDGET error, got %v", query, err)
+		}
+		if used := budget.Stats().Used; used != 0 {
+			t.Errorf("%s: %d bytes still reserved after the query failed", query, used)
+		}
+	}
+
+	// Queries within the budget are unaffected
+	if got := selectNames(t, db, "SELECT name FROM users WHERE id < 3 ORDER BY name"); len(got) != 3 {
+		t.Errorf("expected 3 rows, got %v", got)
+	}
+	if used := budget.Stats().Used; used != 0 {
+		t.Errorf("%d bytes still reserved after a successful query", used)
+	}
+}
+
+func TestMemoryBudget_ErrorIsTyped(t *testing.T) {
+	db, _ := setupMemoryBudgetDB(t, 1024)
+
+	_, err := db.ExecuteQuery("SELECT id FROM users ORDER BY id")
+	if err == nil {
+		t.Fatal("expected the query to exceed its memory budget")
+	}
+	if !strings.Contains(err.Error(), ErrCodeOutOfMemoryBudget) {
+		t.Errorf("expected error code %s in %q", ErrCodeOutOfMemoryBudget, err)
+	}
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"storemy/pkg/metrics"
+	"testing"
+)
+
+func metricValue(t *testing.T, name string) int64 {
+	t.Helper()
+
+	m, ok := metrics.DefaultRegistry.Get(name)
+	if !ok {
+		t.Fatalf("metric %s is not registered", name)
+	}
+	switch v := m.(type) {
+	case *metrics.Counter:
+		return v.Value()
+	case *metrics.Histogram:
+		return v.Count()
+	default:
+		t.Fatalf("unexpected metric type %T for %s", m, name)
+		return 0
+	}
+}
+
+func TestMetrics_PopulatedByQueries(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	names := []string{
+		"storemy_queries_total",
+		"storemy_query_duration_seconds",
+		"storemy_executor_rows_returned_total",
+		"storemy_executor_rows_affected_total",
+		"storemy_wal_bytes_written_total",
+		"storemy_wal_records_written_total",
+		"storemy_lock_acquisitions_total",
+	}
+	before := make(map[string]int64, len(names))
+	for _, name := range names {
+		before[name] = metricValue(t, name)
+	}
+
+	queries := []string{
+		"CREATE TABLE users (id INT, name STRING)",
+		"INSERT INTO users (id, name) VALUES (1, 'alice')",
+		"SELECT * FROM users",
+	}
+	for _, q := range queries {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("query %q failed: %v", q, err)
+		}
+	}
+
+	for _, name := range names {
+		if after := metricValue(t, name); after <= before[name] {
+			t.Errorf("expected %s to increase, stayed at %d", name, after)
+		}
+	}
+
+	errorsBefore := metricValue(t, "storemy_query_errors_total")
+	db.ExecuteQuery("SELEC broken")
+	if metricValue(t, "storemy_query_errors_total") != errorsBefore+1 {
+		t.Error("expected storemy_query_errors_total to increase after a failed query")
+	}
+}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var testMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create accounts",
+		Up:      "CREATE TABLE accounts (id INT, owner STRING);\nINSERT INTO accounts (id, owner) VALUES (1, 'alice');",
+		Down:    "DROP TABLE accounts;",
+	},
+	{
+		Version: 2,
+		Name:    "add bob",
+		Up:      "INSERT INTO accounts (id, owner) VALUES (2, 'bob');",
+		Down:    "DELETE FROM accounts WHERE id = 2;",
+	},
+}
+
+func versions(ms []Migration) []uint64 {
+	out := make([]uint64, len(ms))
+	for i, m := range ms {
+		out[i] = m.Version
+	}
+	return out
+}
+
+func TestApplyMigrations(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Pass the migrations out of order: they are applied by version.
+	applied, err := db.ApplyMigrations([]Migration{testMigrations[1], testMigrations[0]})
+	if err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	if got := versions(applied); !slices.Equal(got, []uint64{1, 2}) {
+		t.Errorf("applied versions = %v, want [1 2]", got)
+	}
+	if n := countRows(t, db, "accounts"); n != 2 {
+		t.Errorf("expected 2 rows, got %d", n)
+	}
+
+	applied, err = db.ApplyMigrations(testMigrations)
+	if err != nil {
+		t.Fatalf("second ApplyMigrations failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected nothing to apply, got %v", versions(applied))
+	}
+
+	statuses, err := db.MigrationStatus(testMigrations)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.Modified || s.Unknown || s.AppliedAt.IsZero() {
+			t.Errorf("unexpected status: %+v", s)
+		}
+	}
+}
+
+func TestApplyMigrations_FailureLeavesNoRecord(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	broken := slices.Clone(testMigrations)
+	broken[1].Up = "INSERT INTO accounts (id, owner) VALUES (2, 'bob');\nINSERT INTO missing_table (id) VALUES (3);"
+
+	applied, err := db.ApplyMigrations(broken)
+	requireErrorCode(t, err, "MIGRATION_FAILED")
+	if got := versions(applied); !slices.Equal(got, []uint64{1}) {
+		t.Errorf("applied versions = %v, want [1]", got)
+	}
+	if n := countRows(t, db, "accounts"); n != 1 {
+		t.Errorf("expected the failed migration's insert to be rolled back, found %d rows", n)
+	}
+
+	statuses, err := db.MigrationStatus(broken)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("expected only version 1 to be applied, got %+v", statuses)
+	}
+
+	if _, err := db.ApplyMigrations(testMigrations); err != nil {
+		t.Fatalf("ApplyMigrations after fix failed: %v", err)
+	}
+	if n := countRows(t, db, "accounts"); n != 2 {
+		t.Errorf("expected 2 rows, got %d", n)
+	}
+}
+
+func TestApplyMigrations_RejectsChangedAndOutOfOrder(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ApplyMigrations(testMigrations); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+
+	edited := slices.Clone(testMigrations)
+	edited[1].Up = "INSERT INTO accounts (id, owner) VALUES (2, 'robert');"
+	_, err := db.ApplyMigrations(edited)
+	requireErrorCode(t, err, "MIGRATION_CHECKSUM_MISMATCH")
+
+	statuses, err := db.MigrationStatus(edited)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if !statuses[1].Modified {
+		t.Errorf("expected version 2 to be reported as modified: %+v", statuses[1])
+	}
+
+	duplicate := append(slices.Clone(testMigrations), Migration{Version: 1, Name: "duplicate"})
+	_, err = db.ApplyMigrations(duplicate)
+	requireErrorCode(t, err, "INVALID_MIGRATION")
+	_, err = db.ApplyMigrations([]Migration{{Version: 0, Name: "zero"}})
+	requireErrorCode(t, err, "INVALID_MIGRATION")
+
+	statuses, err = db.MigrationStatus([]Migration{testMigrations[1], {Version: 3, Name: "pending"}})
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if len(statuses) != 3 || !statuses[0].Unknown || statuses[2].Applied {
+		t.Errorf("unexpected statuses: %+v", statuses)
+	}
+
+	fourth := append(slices.Clone(testMigrations), Migration{
+		Version: 4, Name: "add dave", Up: "INSERT INTO accounts (id, owner) VALUES (4, 'dave');",
+	})
+	if _, err := db.ApplyMigrations(fourth); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	third := append(fourth, Migration{
+		Version: 3, Name: "add carol", Up: "INSERT INTO accounts (id, owner) VALUES (3, 'carol');",
+	})
+	_, err = db.ApplyMigrations(third)
+	requireErrorCode(t, err, "MIGRATION_OUT_OF_ORDER")
+	if n := countRows(t, db, "accounts"); n != 3 {
+		t.Errorf("expected 3 rows, got %d", n)
+	}
+}
+
+func TestRollbackMigrations(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ApplyMigrations(testMigrations); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+
+	irreversible := slices.Clone(testMigrations)
+	irreversible[1].Down = ""
+	_, err := db.RollbackMigrations(irreversible, 1)
+	requireErrorCode(t, err, "MIGRATION_IRREVERSIBLE")
+
+	rolledBack, err := db.RollbackMigrations(testMigrations, 1)
+	if err != nil {
+		t.Fatalf("RollbackMigrations failed: %v", err)
+	}
+	if got := versions(rolledBack); !slices.Equal(got, []uint64{2}) {
+		t.Errorf("rolled back versions = %v, want [2]", got)
+	}
+	if n := countRows(t, db, "accounts"); n != 1 {
+		t.Errorf("expected 1 row after rolling back version 2, got %d", n)
+	}
+
+	statuses, err := db.MigrationStatus(testMigrations)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("expected only version 1 to be applied, got %+v", statuses)
+	}
+
+	applied, err := db.ApplyMigrations(testMigrations)
+	if err != nil {
+		t.Fatalf("reapplying failed: %v", err)
+	}
+	if got := versions(applied); !slices.Equal(got, []uint64{2}) {
+		t.Errorf("reapplied versions = %v, want [2]", got)
+	}
+}
+
+func TestMigrations_SurviveReopen(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	if _, err := db.ApplyMigrations(testMigrations[:1]); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	applied, err := reopened.ApplyMigrations(testMigrations)
+	if err != nil {
+		t.Fatalf("ApplyMigrations after reopen failed: %v", err)
+	}
+	if got := versions(applied); !slices.Equal(got, []uint64{2}) {
+		t.Errorf("applied versions after reopen = %v, want [2]", got)
+	}
+}
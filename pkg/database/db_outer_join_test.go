@@ -0,0 +1,153 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func setupOuterJoinDB(t *testing.T) *Database {
+	t.Helper()
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	mustExec(t, db,
+		"CREATE TABLE customers (id INT, name STRING)",
+		"CREATE TABLE orders (oid INT, customer_id INT, total INT)",
+		"INSERT INTO customers (id, name) VALUES (1, 'ada')",
+		"INSERT INTO customers (id, name) VALUES (2, 'alan')",
+		"INSERT INTO customers (id, name) VALUES (3, 'grace')",
+		"INSERT INTO orders (oid, customer_id, total) VALUES (10, 1, 100)",
+		"INSERT INTO orders (oid, customer_id, total) VALUES (11, 1, 50)",
+		"INSERT INTO orders (oid, customer_id, total) VALUES (12, 4, 70)",
+	)
+	return db
+}
+
+func TestOuterJoin_NullExtendedRows(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			"SELECT * FROM customers INNER JOIN orders ON customers.id = orders.customer_id",
+			[]string{"1/ADA/10/1/100", "1/ADA/11/1/50"},
+		},
+		{
+			"SELECT * FROM customers LEFT JOIN orders ON customers.id = orders.customer_id",
+			[]string{"1/ADA/10/1/100", "1/ADA/11/1/50", "2/ALAN/NULL/NULL/NULL", "3/GRACE/NULL/NULL/NULL"},
+		},
+		{
+			"SELECT * FROM customers RIGHT OUTER JOIN orders ON customers.id = orders.customer_id",
+			[]string{"1/ADA/10/1/100", "1/ADA/11/1/50", "NULL/NULL/12/4/70"},
+		},
+		{
+			"SELECT * FROM customers FULL OUTER JOIN orders ON customers.id = orders.customer_id",
+			[]string{"1/ADA/10/1/100", "1/ADA/11/1/50", "2/ALAN/NULL/NULL/NULL", "3/GRACE/NULL/NULL/NULL", "NULL/NULL/12/4/70"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestOuterJoin_WhereAppliesAfterJoin(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	// A WHERE on the preserved side of a RIGHT join must not drop the
+	// null-extended rows of the other side before the join runs.
+	got := groupRows(t, db, "SELECT * FROM customers RIGHT JOIN orders ON customers.id = orders.customer_id WHERE total > 60")
+	expected := []string{"1/ADA/10/1/100", "NULL/NULL/12/4/70"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	got = groupRows(t, db, "SELECT * FROM customers LEFT JOIN orders ON customers.id = orders.customer_id WHERE id > 1")
+	expected = []string{"2/ALAN/NULL/NULL/NULL", "3/GRACE/NULL/NULL/NULL"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestOuterJoin_Explain(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	result, err := db.ExecuteQuery("EXPLAIN SELECT * FROM customers LEFT JOIN orders ON customers.id = orders.customer_id")
+	if err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	if !strings.Contains(result.Rows[0][0], "left outer JOIN") || !strings.Contains(result.Rows[0][0], "NULL-extends right") {
+		t.Errorf("expected outer join annotation, got:\n%s", result.Rows[0][0])
+	}
+}
+
+func TestOuterJoin_NullExtendedColumns(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			"SELECT name, total FROM customers LEFT JOIN orders ON customers.id = orders.customer_id",
+			[]string{"ADA/100", "ADA/50", "ALAN/NULL", "GRACE/NULL"},
+		},
+		{
+			"SELECT name, oid FROM customers RIGHT JOIN orders ON customers.id = orders.customer_id",
+			[]string{"ADA/10", "ADA/11", "NULL/12"},
+		},
+		{
+			"SELECT name FROM customers LEFT JOIN orders ON customers.id = orders.customer_id WHERE total > 60",
+			[]string{"ADA"},
+		},
+		{
+			"SELECT oid FROM customers RIGHT JOIN orders ON customers.id = orders.customer_id WHERE name = 'ada'",
+			[]string{"10", "11"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestOuterJoin_OrderByNullExtendedSide(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	// NULL sorts after every value, so it comes last ascending and first
+	// descending
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			"SELECT name, total FROM customers LEFT JOIN orders ON customers.id = orders.customer_id ORDER BY total",
+			[]string{"ADA/50", "ADA/100", "ALAN/NULL", "GRACE/NULL"},
+		},
+		{
+			"SELECT name, oid FROM customers RIGHT JOIN orders ON customers.id = orders.customer_id ORDER BY name DESC",
+			[]string{"NULL/12", "ADA/10", "ADA/11"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := orderedRows(t, db, tt.query)
+		if len(got) != len(tt.expected) {
+			t.Fatalf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+		// Rows with equal sort keys may come in either order
+		for i := range got {
+			if strings.Split(got[i], "/")[0] != strings.Split(tt.expected[i], "/")[0] {
+				t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/tracing"
+	"testing"
+)
+
+func TestPanic_FailsOnlyTheQuery(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+	mustExec(t, db, "CREATE TABLE users (id INT, name VARCHAR)", "INSERT INTO users VALUES (1, 'a')", "INSERT INTO users VALUES (2, 'b')")
+
+	// An operator panics in the middle of a scan holding its locks
+	panicking := func(tracing.StepEvent) { panic("operator bug") }
+	before := queryPanics.Value()
+	_, err = db.executeQuery("SELECT * FROM users", nil, panicking)
+	if !IsQueryPanicError(err) {
+		t.Fatalf("expected a QUERY_PANIC error, got %v", err)
+	}
+	var dbErr *dberror.DBError
+	if !errors.As(err, &dbErr) || dbErr.Code != ErrCodeQueryPanic || dberror.SQLState(err) != dberror.SQLStateInternalError {
+		t.Errorf("expected code %s and SQLSTATE %s, got %v", ErrCodeQueryPanic, dberror.SQLStateInternalError, err)
+	}
+	if n := queryPanics.Value() - before; n != 1 {
+		t.Errorf("%d panics counted, want 1", n)
+	}
+
+	// The transaction was aborted, so its locks are free, and the process
+	// still serves queries
+	mustExec(t, db, "UPDATE users SET name = 'y'")
+	if n := countRows(t, db, "users"); n != 2 {
+		t.Errorf("users has %d rows, want 2", n)
+	}
+}
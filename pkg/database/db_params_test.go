@@ -0,0 +1,137 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func setupParamsDB(t *testing.T) *Database {
+	t.Helper()
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+
+	mustExec(t, db, "CREATE TABLE users (id INT, name STRING, age INT)")
+	rows := []struct {
+		id   int
+		name string
+		age  int
+	}{
+		{1, "alice", 30},
+		{2, "Bob", 17},
+		{3, "carol's", 65},
+	}
+	for _, r := range rows {
+		if _, err := db.ExecuteQueryWithArgs("INSERT INTO users (id, name, age) VALUES ($1, $2, $3)", r.id, r.name, r.age); err != nil {
+			t.Fatalf("parameterized INSERT failed: %v", err)
+		}
+	}
+	return db
+}
+
+// paramRows runs a parameterized query and returns its rows as "col1/col2"
+// strings, sorted.
+func paramRows(t *testing.T, db *Database, query string, args ...any) []string {
+	t.Helper()
+	result, err := db.ExecuteQueryWithArgs(query, args...)
+	if err != nil {
+		t.Fatalf("%s %v failed: %v", query, args, err)
+	}
+
+	rows := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		rows = append(rows, strings.Join(row, "/"))
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+func TestParams_Select(t *testing.T) {
+	db := setupParamsDB(t)
+
+	tests := []struct {
+		query    string
+		args     []any
+		expected []string
+	}{
+		{"SELECT name FROM users WHERE id = $1", []any{2}, []string{"Bob"}},
+		{"SELECT name FROM users WHERE name = ?", []any{"carol's"}, []string{"carol's"}},
+		{"SELECT name FROM users WHERE age >= $1", []any{"30"}, []string{"alice", "carol's"}},
+		{"SELECT name FROM users WHERE name LIKE $1", []any{"a%"}, []string{"alice"}},
+		{"SELECT id FROM users WHERE CASE WHEN age < $1 THEN $2 ELSE 'x' END = $2", []any{18, "minor"}, []string{"2"}},
+		{"SELECT name, CASE WHEN age > $1 THEN $2 ELSE $3 END AS band FROM users WHERE id = $4", []any{20, "old", "young", 1}, []string{"alice/old"}},
+		{"SELECT name FROM users WHERE name = $1", []any{"alice' OR '1'='1"}, []string{}},
+		{"SELECT name FROM users WHERE id = $1", []any{nil}, []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := paramRows(t, db, tt.query, tt.args...); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s %v = %v, expected %v", tt.query, tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestParams_UpdateAndDelete(t *testing.T) {
+	db := setupParamsDB(t)
+
+	result, err := db.ExecuteQueryWithArgs("UPDATE users SET age = $1, name = $2 WHERE id = $3", 18, "Robert", 2)
+	if err != nil {
+		t.Fatalf("parameterized UPDATE failed: %v", err)
+	}
+	if result.RowsAffected != 1 {
+		t.Errorf("expected 1 row updated, got %d", result.RowsAffected)
+	}
+	if got := paramRows(t, db, "SELECT name, age FROM users WHERE id = ?", 2); !slices.Equal(got, []string{"Robert/18"}) {
+		t.Errorf("expected updated row Robert/18, got %v", got)
+	}
+
+	if _, err := db.ExecuteQueryWithArgs("DELETE FROM users WHERE age > ?", 20); err != nil {
+		t.Fatalf("parameterized DELETE failed: %v", err)
+	}
+	if got := paramRows(t, db, "SELECT name FROM users"); !slices.Equal(got, []string{"Robert"}) {
+		t.Errorf("expected only Robert to remain, got %v", got)
+	}
+}
+
+func TestParams_Errors(t *testing.T) {
+	db := setupParamsDB(t)
+
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+	}{
+		{"missing argument", "SELECT name FROM users WHERE id = $2", []any{1}},
+		{"no arguments", "SELECT name FROM users WHERE id = ?", nil},
+		{"extra argument", "SELECT name FROM users WHERE id = $1", []any{1, 2}},
+		{"arguments without parameters", "SELECT name FROM users", []any{1}},
+		{"type mismatch", "SELECT name FROM users WHERE id = $1", []any{"1 OR 1=1"}},
+		{"mixed styles", "SELECT name FROM users WHERE id = $1 AND age = ?", []any{1, 2}},
+		{"parameter zero", "SELECT name FROM users WHERE id = $0", []any{1}},
+		{"NULL in SET", "UPDATE users SET age = $1 WHERE id = 1", []any{nil}},
+		{"NULL in VALUES", "INSERT INTO users VALUES (?, ?, ?)", []any{4, nil, 40}},
+	}
+
+	for _, tt := range tests {
+		if _, err := db.ExecuteQueryWithArgs(tt.query, tt.args...); err == nil {
+			t.Errorf("%s: expected %s %v to fail", tt.name, tt.query, tt.args)
+		}
+	}
+
+	// Failed statements must not have changed anything
+	if got := paramRows(t, db, "SELECT id FROM users"); len(got) != 3 {
+		t.Errorf("expected 3 rows, got %v", got)
+	}
+}
+
+func TestParams_Explain(t *testing.T) {
+	db := setupParamsDB(t)
+
+	result, err := db.ExecuteQueryWithArgs("EXPLAIN SELECT name FROM users WHERE age > $1", 20)
+	if err != nil {
+		t.Fatalf("EXPLAIN of a parameterized query failed: %v", err)
+	}
+	if len(result.Rows) == 0 {
+		t.Error("expected EXPLAIN output")
+	}
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func selectNames(t *testing.T, db *Database, query string) []string {
+	t.Helper()
+	result, err := db.ExecuteQuery(query)
+	if err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+
+	names := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		names = append(names, row[0])
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestPatternPredicates(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE people (id INT, name STRING)",
+		"INSERT INTO people (id, name) VALUES (1, 'alice')",
+		"INSERT INTO people (id, name) VALUES (2, 'albert')",
+		"INSERT INTO people (id, name) VALUES (3, 'bob')",
+		"INSERT INTO people (id, name) VALUES (4, 'al_x')",
+		"INSERT INTO people (id, name) VALUES (5, 'carol7')",
+	)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT name FROM people WHERE name LIKE 'al%'", []string{"ALBERT", "ALICE", "AL_X"}},
+		{"SELECT name FROM people WHERE name LIKE '%o%'", []string{"BOB", "CAROL7"}},
+		{"SELECT name FROM people WHERE name LIKE '_ob'", []string{"BOB"}},
+		{`SELECT name FROM people WHERE name LIKE 'al\_%'`, []string{"AL_X"}},
+		{"SELECT name FROM people WHERE name ILIKE 'BO%'", []string{"BOB"}},
+		{`SELECT name FROM people WHERE name REGEXP '\d$'`, []string{"CAROL7"}},
+		{"SELECT name FROM people WHERE name REGEXP '^al(i|b)'", []string{"ALBERT", "ALICE"}},
+	}
+
+	for _, tt := range tests {
+		if got := selectNames(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+
+	mustExec(t, db,
+		"UPDATE people SET id = 0 WHERE name LIKE 'al%'",
+		"DELETE FROM people WHERE name REGEXP '^B'",
+	)
+	if got := selectNames(t, db, "SELECT name FROM people WHERE id = 0"); len(got) != 3 {
+		t.Errorf("expected UPDATE ... LIKE to change 3 rows, got %v", got)
+	}
+	if n := countRows(t, db, "people"); n != 4 {
+		t.Errorf("expected DELETE ... REGEXP to remove 1 row, got %d rows", n)
+	}
+
+	if _, err := db.ExecuteQuery("SELECT name FROM people WHERE id LIKE '1%'"); err == nil {
+		t.Error("expected LIKE on an INT column to fail")
+	}
+}
+
+func TestPatternPredicates_PrefixIndexScan(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE words (id INT, word STRING)",
+		"CREATE INDEX idx_word ON words(word) USING BTREE",
+	)
+	words := []string{"apple", "apply", "apricot", "banana", "ap", "aq", "zap"}
+	for i, w := range words {
+		mustExec(t, db, fmt.Sprintf("INSERT INTO words (id, word) VALUES (%d, '%s')", i, w))
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT word FROM words WHERE word LIKE 'ap%'", []string{"AP", "APPLE", "APPLY", "APRICOT"}},
+		{"SELECT word FROM words WHERE word LIKE 'appl_'", []string{"APPLE", "APPLY"}},
+		{"SELECT word FROM words WHERE word LIKE 'ap'", []string{"AP"}},
+		{"SELECT word FROM words WHERE word LIKE '%ap%'", []string{"AP", "APPLE", "APPLY", "APRICOT", "ZAP"}},
+	}
+
+	for _, tt := range tests {
+		if got := selectNames(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
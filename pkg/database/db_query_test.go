@@ -3,10 +3,19 @@ package database
 import (
 	"os"
 	"path/filepath"
+	"storemy/pkg/logging"
 	"strings"
 	"testing"
 )
 
+// testOptions returns the default options with logging discarded, so tests
+// that open a database do not flood the output.
+func testOptions() Options {
+	opts := DefaultOptions()
+	opts.Logger = logging.NewNopLogger()
+	return opts
+}
+
 // setupTestDB creates a test database for query testing
 func setupTestDB(t *testing.T) (*Database, func()) {
 	t.Helper()
@@ -19,7 +28,7 @@ func setupTestDB(t *testing.T) (*Database, func()) {
 	dataDir := filepath.Join(tempDir, "data")
 	logDir := filepath.Join(tempDir, "logs")
 
-	db, err := NewDatabase("testdb", dataDir, logDir)
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("NewDatabase failed: %v", err)
 	}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/storage/accounting"
+	"storemy/pkg/storage/page"
+	"strings"
+	"testing"
+)
+
+// openQuotaDB opens the database in dir with quota.
+func openQuotaDB(t *testing.T, dir string, quota accounting.Quota) *Database {
+	t.Helper()
+	opts := testOptions()
+	opts.Quota = quota
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	return db
+}
+
+func expectQuotaError(t *testing.T, err error, kind string) {
+	t.Helper()
+	if !IsQuotaExceededError(err) {
+		t.Fatalf("expected a QUOTA_EXCEEDED error, got %v", err)
+	}
+	var dbErr *dberror.DBError
+	if !errors.As(err, &dbErr) || dbErr.Code != ErrCodeQuotaExceeded {
+		t.Errorf("expected code %s, got %v", ErrCodeQuotaExceeded, err)
+	}
+	var quotaErr *accounting.QuotaError
+	if !errors.As(err, &quotaErr) || quotaErr.Kind != kind {
+		t.Errorf("expected a %s quota error, got %v", kind, err)
+	}
+}
+
+func TestQuota_MaxTableRows(t *testing.T) {
+	dir := t.TempDir()
+	db := openQuotaDB(t, dir, accounting.Quota{MaxTableRows: 5})
+
+	mustExec(t, db,
+		"CREATE TABLE users (id INT)",
+		"CREATE TABLE orders (id INT)",
+		"INSERT INTO users VALUES (1), (2), (3), (4)",
+	)
+	_, err := db.ExecuteQuery("INSERT INTO users VALUES (5), (6)")
+	expectQuotaError(t, err, accounting.RowQuota)
+	if n := countRows(t, db, "users"); n != 4 {
+		t.Errorf("users has %d rows after the refused insert, want 4", n)
+	}
+
+	mustExec(t, db,
+		"INSERT INTO users VALUES (5)",
+		"INSERT INTO orders VALUES (1), (2), (3), (4), (5)",
+	)
+	_, err = db.ExecuteQuery("INSERT INTO users VALUES (6)")
+	expectQuotaError(t, err, accounting.RowQuota)
+
+	// Deleted rows make room
+	mustExec(t, db, "DELETE FROM users WHERE id < 3", "INSERT INTO users VALUES (6), (7)")
+	if n := countRows(t, db, "users"); n != 5 {
+		t.Errorf("users has %d rows, want 5", n)
+	}
+
+	// The rows are counted again when the database is reopened
+	db.Close()
+	db = openQuotaDB(t, dir, accounting.Quota{MaxTableRows: 5})
+	defer db.Close()
+	_, err = db.ExecuteQuery("INSERT INTO users VALUES (8)")
+	expectQuotaError(t, err, accounting.RowQuota)
+}
+
+func TestQuota_UncommittedRows(t *testing.T) {
+	db := openQuotaDB(t, t.TempDir(), accounting.Quota{MaxTableRows: 5})
+	defer db.Close()
+	mustExec(t, db, "CREATE TABLE users (id INT)", "INSERT INTO users VALUES (1), (2)")
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(tx, "INSERT INTO users VALUES (3), (4), (5)"); err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+
+	// The rows of the open transaction are reserved
+	_, err = db.ExecuteQuery("INSERT INTO users VALUES (6)")
+	expectQuotaError(t, err, accounting.RowQuota)
+
+	// and freed when it aborts
+	if err := db.AbortTransaction(tx); err != nil {
+		t.Fatalf("AbortTransaction failed: %v", err)
+	}
+	mustExec(t, db, "INSERT INTO users VALUES (6), (7), (8)")
+	if n := countRows(t, db, "users"); n != 5 {
+		t.Errorf("users has %d rows, want 5", n)
+	}
+}
+
+func TestQuota_RolledBackStatement(t *testing.T) {
+	db := openQuotaDB(t, t.TempDir(), accounting.Quota{MaxTableRows: 2})
+	defer db.Close()
+	mustExec(t, db, "CREATE TABLE t (id INT, name VARCHAR)")
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	_, err = db.ExecuteInTransaction(tx, "INSERT INTO t VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+	expectQuotaError(t, err, accounting.RowQuota)
+
+	result, err := db.ExecuteInTransaction(tx, "SELECT * FROM t")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(result.Rows) != 0 {
+		t.Fatalf("t has %d rows after the statement was rolled back, want 0", len(result.Rows))
+	}
+
+	// The rolled back statement gave its rows back
+	for _, query := range []string{"INSERT INTO t VALUES (1, 'a')", "INSERT INTO t VALUES (2, 'b')"} {
+		if _, err := db.ExecuteInTransaction(tx, query); err != nil {
+			t.Fatalf("%s failed: %v", query, err)
+		}
+	}
+	_, err = db.ExecuteInTransaction(tx, "INSERT INTO t VALUES (3, 'c')")
+	expectQuotaError(t, err, accounting.RowQuota)
+
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	if n := countRows(t, db, "t"); n != 2 {
+		t.Errorf("t has %d rows, want 2", n)
+	}
+}
+
+func TestQuota_MaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	db := openQuotaDB(t, dir, accounting.Quota{})
+	mustExec(t, db, "CREATE TABLE notes (id INT, body STRING)", "INSERT INTO notes VALUES (0, 'first')")
+	size, err := db.pageStore.FileBytes()
+	if err != nil {
+		t.Fatalf("FileBytes failed: %v", err)
+	}
+	db.Close()
+
+	limit := size + 3*int64(page.PageSize)
+	db = openQuotaDB(t, dir, accounting.Quota{MaxBytes: limit})
+	defer db.Close()
+
+	body := strings.Repeat("x", 100)
+	inserted := 0
+	for i := 1; i < 1000; i++ {
+		_, err = db.ExecuteQuery(fmt.Sprintf("INSERT INTO notes VALUES (%d, '%s')", i, body))
+		if err != nil {
+			break
+		}
+		inserted++
+	}
+	expectQuotaError(t, err, accounting.SizeQuota)
+	if inserted == 0 {
+		t.Error("expected inserts to succeed until the quota was reached")
+	}
+
+	size, err = db.pageStore.FileBytes()
+	if err != nil {
+		t.Fatalf("FileBytes failed: %v", err)
+	}
+	if size > limit {
+		t.Errorf("database grew to %d bytes, past its quota of %d", size, limit)
+	}
+
+	// Rows still fit in the free space of the existing pages
+	mustExec(t, db, "DELETE FROM notes WHERE id < 5", "INSERT INTO notes VALUES (1000, 'again')")
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupReadOnlyDB creates a database with one populated table, closes it,
+// and reopens the same directory in read-only mode.
+func setupReadOnlyDB(t *testing.T) (*Database, string, func()) {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "db_readonly_test_*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		os.RemoveAll(tempDir)
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+
+	setup := []string{
+		"CREATE TABLE users (id INT, name STRING)",
+		"INSERT INTO users (id, name) VALUES (1, 'alice')",
+		"INSERT INTO users (id, name) VALUES (2, 'bob')",
+	}
+	for _, q := range setup {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			db.Close()
+			os.RemoveAll(tempDir)
+			t.Fatalf("setup query %q failed: %v", q, err)
+		}
+	}
+	db.Close()
+
+	opts := testOptions()
+	opts.ReadOnly = true
+	roDB, err := NewDatabaseWithOptions("testdb", dataDir, logDir, opts)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		t.Fatalf("read-only open failed: %v", err)
+	}
+
+	cleanup := func() {
+		roDB.Close()
+		os.RemoveAll(tempDir)
+	}
+
+	return roDB, logDir, cleanup
+}
+
+func TestReadOnly_SelectAllowed(t *testing.T) {
+	db, _, cleanup := setupReadOnlyDB(t)
+	defer cleanup()
+
+	if !db.IsReadOnly() {
+		t.Fatal("expected database to report read-only mode")
+	}
+
+	result, err := db.ExecuteQuery("SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("SELECT failed in read-only mode: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Errorf("expected 2 rows, got %d", len(result.Rows))
+	}
+}
+
+func TestReadOnly_WritesRejected(t *testing.T) {
+	db, _, cleanup := setupReadOnlyDB(t)
+	defer cleanup()
+
+	queries := []string{
+		"INSERT INTO users (id, name) VALUES (3, 'carol')",
+		"UPDATE users SET name = 'x' WHERE id = 1",
+		"DELETE FROM users WHERE id = 1",
+		"CREATE TABLE other (id INT)",
+		"DROP TABLE users",
+		"CREATE INDEX idx_users_id ON users (id)",
+		"EXPLAIN ANALYZE INSERT INTO users (id, name) VALUES (4, 'dave')",
+	}
+
+	for _, q := range queries {
+		_, err := db.ExecuteQuery(q)
+		if err == nil {
+			t.Errorf("expected %q to be rejected", q)
+			continue
+		}
+		if !IsReadOnlyError(err) {
+			t.Errorf("expected read-only error for %q, got: %v", q, err)
+		}
+	}
+
+	if err := db.UpdateTableStatistics("users"); !IsReadOnlyError(err) {
+		t.Errorf("expected read-only error from UpdateTableStatistics, got: %v", err)
+	}
+
+	result, err := db.ExecuteQuery("SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Errorf("expected data to be unchanged (2 rows), got %d", len(result.Rows))
+	}
+}
+
+func TestReadOnly_NoWALWrites(t *testing.T) {
+	db, logDir, cleanup := setupReadOnlyDB(t)
+	defer cleanup()
+
+	before, err := os.Stat(logDir)
+	if err != nil {
+		t.Fatalf("failed to stat WAL: %v", err)
+	}
+
+	for range 3 {
+		if _, err := db.ExecuteQuery("SELECT * FROM users"); err != nil {
+			t.Fatalf("SELECT failed: %v", err)
+		}
+	}
+	db.ExecuteQuery("INSERT INTO users (id, name) VALUES (3, 'carol')")
+
+	after, err := os.Stat(logDir)
+	if err != nil {
+		t.Fatalf("failed to stat WAL: %v", err)
+	}
+	if after.Size() != before.Size() {
+		t.Errorf("expected WAL size to stay %d, got %d", before.Size(), after.Size())
+	}
+}
+
+func TestReadOnly_MissingDatabase(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "db_readonly_test_*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	opts := testOptions()
+	opts.ReadOnly = true
+	_, err = NewDatabaseWithOptions("missing", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err == nil {
+		t.Fatal("expected error opening a non-existent database read-only")
+	}
+
+	if _, statErr := os.Stat(filepath.Join(tempDir, "data", "missing")); !os.IsNotExist(statErr) {
+		t.Error("read-only open must not create the database directory")
+	}
+}
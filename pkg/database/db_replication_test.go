@@ -0,0 +1,81 @@
+package database
+
+import (
+	"path/filepath"
+	"storemy/pkg/log/wal"
+	"testing"
+	"time"
+)
+
+func openReplicatedDB(t *testing.T, config wal.ReplicationConfig) (*Database, error) {
+	t.Helper()
+	tempDir := t.TempDir()
+	opts := testOptions()
+	opts.Replication = config
+	return NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+}
+
+func TestReplication_CommitWaitsForStandby(t *testing.T) {
+	db, err := openReplicatedDB(t, wal.ReplicationConfig{
+		Standbys: []string{"standby1", "standby2"},
+		Quorum:   1,
+		Timeout:  time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	defer db.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecuteQuery("CREATE TABLE users (id INT)")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("statement committed before a standby acknowledged it: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Stands in for the log shipper reporting that standby1 caught up
+	if err := db.AcknowledgeStandby("standby1", wal.AckReceived, db.walInstance.FlushedLSN()); err != nil {
+		t.Fatalf("AcknowledgeStandby failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("CREATE TABLE failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("statement did not commit after the acknowledgment")
+	}
+
+	result, err := db.ExecuteQuery("SELECT * FROM sys_replication")
+	if err != nil {
+		t.Fatalf("SELECT from sys_replication failed: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("expected 2 standbys, got %d", len(result.Rows))
+	}
+	standby := columnIndex(t, result, "STANDBY")
+	lag := columnIndex(t, result, "LAG_BYTES")
+	for _, row := range result.Rows {
+		if row[standby] == "standby2" && row[lag] == "0" {
+			t.Error("expected standby2 to lag behind")
+		}
+		if got := row[columnIndex(t, result, "DEMOTED")]; got != "false" {
+			t.Errorf("DEMOTED = %s, expected false", got)
+		}
+	}
+
+	if err := db.AcknowledgeStandby("standby3", wal.AckReceived, 1); err == nil {
+		t.Error("expected an acknowledgment from an unknown standby to fail")
+	}
+}
+
+func TestReplication_InvalidConfigRejected(t *testing.T) {
+	if _, err := openReplicatedDB(t, wal.ReplicationConfig{Standbys: []string{"standby1"}, Quorum: 2}); err == nil {
+		t.Fatal("expected a quorum larger than the standbys to be rejected")
+	}
+}
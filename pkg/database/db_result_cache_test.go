@@ -0,0 +1,109 @@
+package database
+
+import (
+	"path/filepath"
+	"slices"
+	"storemy/pkg/resultcache"
+	"testing"
+)
+
+func setupResultCacheDB(t *testing.T) *Database {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	opts := testOptions()
+	opts.ResultCache = resultcache.Config{MaxEntries: 100}
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mustExec(t, db, "CREATE TABLE users (id INT, name STRING)")
+	mustExec(t, db, "CREATE TABLE orders (id INT, user_id INT)")
+	mustExec(t, db, "INSERT INTO users VALUES (1, 'alice')")
+	mustExec(t, db, "INSERT INTO users VALUES (2, 'bob')")
+	mustExec(t, db, "INSERT INTO orders VALUES (10, 1)")
+	return db
+}
+
+func TestResultCache_HitsAndInvalidation(t *testing.T) {
+	db := setupResultCacheDB(t)
+	query := "SELECT name FROM users"
+
+	first := selectNames(t, db, query)
+	if got := selectNames(t, db, query); !slices.Equal(got, first) {
+		t.Fatalf("cached result %v differs from %v", got, first)
+	}
+	if stats := db.resultCache.Stats(); stats.Hits != 1 || stats.Stores != 1 {
+		t.Fatalf("expected one store and one hit, got %+v", stats)
+	}
+
+	// A write to another table keeps the entry
+	mustExec(t, db, "INSERT INTO orders VALUES (11, 2)")
+	selectNames(t, db, query)
+	if hits := db.resultCache.Stats().Hits; hits != 2 {
+		t.Errorf("expected a write to ORDERS not to invalidate a query on USERS, got %d hits", hits)
+	}
+
+	mustExec(t, db, "INSERT INTO users VALUES (3, 'carol')")
+	if got := selectNames(t, db, query); !slices.Equal(got, []string{"ALICE", "BOB", "CAROL"}) {
+		t.Errorf("expected the new row after an INSERT, got %v", got)
+	}
+
+	mustExec(t, db, "UPDATE users SET name = 'dave' WHERE id = 3")
+	if got := selectNames(t, db, query); !slices.Equal(got, []string{"ALICE", "BOB", "DAVE"}) {
+		t.Errorf("expected the updated row after an UPDATE, got %v", got)
+	}
+
+	mustExec(t, db, "DELETE FROM users WHERE id = 3")
+	if got := selectNames(t, db, query); !slices.Equal(got, []string{"ALICE", "BOB"}) {
+		t.Errorf("expected the row gone after a DELETE, got %v", got)
+	}
+}
+
+func TestResultCache_KeyIncludesArguments(t *testing.T) {
+	db := setupResultCacheDB(t)
+
+	query := "SELECT name FROM users WHERE id = $1"
+	if got := paramRows(t, db, query, 1); !slices.Equal(got, []string{"ALICE"}) {
+		t.Fatalf("expected ALICE, got %v", got)
+	}
+	if got := paramRows(t, db, query, 2); !slices.Equal(got, []string{"BOB"}) {
+		t.Fatalf("expected BOB for a different argument, got %v", got)
+	}
+}
+
+func TestResultCache_DDLInvalidatesEverything(t *testing.T) {
+	db := setupResultCacheDB(t)
+
+	selectNames(t, db, "SELECT name FROM users")
+	mustExec(t, db, "DROP TABLE users")
+	if _, err := db.ExecuteQuery("SELECT name FROM users"); err == nil {
+		t.Error("expected a SELECT from a dropped table to fail instead of hitting the cache")
+	}
+}
+
+func TestResultCache_SkipsSystemViews(t *testing.T) {
+	db := setupResultCacheDB(t)
+
+	for range 2 {
+		mustExec(t, db, "SELECT * FROM sys_result_cache")
+	}
+	if stores := db.resultCache.Stats().Stores; stores != 0 {
+		t.Errorf("expected system view results not to be cached, got %d stores", stores)
+	}
+}
+
+func TestResultCache_DisabledByDefault(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db, "CREATE TABLE users (id INT)")
+	for range 2 {
+		mustExec(t, db, "SELECT id FROM users")
+	}
+	if stats := db.resultCache.Stats(); stats.Stores != 0 || stats.Hits != 0 {
+		t.Errorf("expected no caching without ResultCache options, got %+v", stats)
+	}
+}
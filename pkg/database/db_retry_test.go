@@ -0,0 +1,115 @@
+package database
+
+import (
+	"path/filepath"
+	dberror "storemy/pkg/error"
+	"testing"
+	"time"
+)
+
+func setupRetryDB(t *testing.T, retry RetryPolicy) *Database {
+	t.Helper()
+	tempDir := t.TempDir()
+	opts := testOptions()
+	opts.TableLockTimeout = 50 * time.Millisecond
+	opts.StatementRetry = retry
+
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mustExec(t, db, "CREATE TABLE users (id INT, name STRING)", "INSERT INTO users VALUES (1, 'alice')")
+	return db
+}
+
+func TestStatementRetry_LockTimeoutIsRetryable(t *testing.T) {
+	db := setupRetryDB(t, RetryPolicy{})
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	defer db.CommitTransaction(tx)
+	if _, err := db.ExecuteInTransaction(tx, "SELECT id FROM users"); err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+
+	_, err = db.ExecuteQuery("DROP TABLE users")
+	if !dberror.IsRetryable(err) {
+		t.Fatalf("expected a retryable lock timeout, got %v", err)
+	}
+	if state := dberror.SQLState(err); state != dberror.SQLStateLockNotAvailable {
+		t.Errorf("SQLState = %s, want %s", state, dberror.SQLStateLockNotAvailable)
+	}
+}
+
+func TestStatementRetry_SucceedsOnceTheConflictEnds(t *testing.T) {
+	db := setupRetryDB(t, RetryPolicy{MaxAttempts: 20, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(tx, "SELECT id FROM users"); err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+
+	retries := metricValue(t, "storemy_statement_retries_total")
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecuteQuery("DROP TABLE users")
+		done <- err
+	}()
+
+	// Hold the table past several lock timeouts before letting DROP through
+	time.Sleep(200 * time.Millisecond)
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("DROP TABLE failed despite retries: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("DROP TABLE did not finish after the reader committed")
+	}
+	if got := metricValue(t, "storemy_statement_retries_total"); got <= retries {
+		t.Errorf("statement retries = %d, want more than %d", got, retries)
+	}
+}
+
+func TestStatementRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	db := setupRetryDB(t, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	defer db.CommitTransaction(tx)
+	if _, err := db.ExecuteInTransaction(tx, "SELECT id FROM users"); err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+
+	retries := metricValue(t, "storemy_statement_retries_total")
+	if _, err := db.ExecuteQuery("DROP TABLE users"); !dberror.IsRetryable(err) {
+		t.Fatalf("expected the lock timeout of the last attempt, got %v", err)
+	}
+	if got := metricValue(t, "storemy_statement_retries_total") - retries; got != 2 {
+		t.Errorf("statement retried %d times, want 2", got)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 35 * time.Millisecond}
+	for retry, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 35 * time.Millisecond, 8: 35 * time.Millisecond} {
+		for range 20 {
+			if got := p.backoff(retry); got < want/2 || got > want {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", retry, got, want/2, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/plan"
+	"strings"
+	"testing"
+)
+
+// materializeRootRule materializes the output of every projection.
+type materializeRootRule struct{}
+
+func (materializeRootRule) Name() string { return "materialize_root" }
+
+func (materializeRootRule) Match(node plan.PlanNode) bool {
+	_, ok := node.(*plan.ProjectNode)
+	return ok
+}
+
+func (materializeRootRule) Apply(_ *transaction.TransactionContext, node plan.PlanNode) (plan.PlanNode, error) {
+	return &plan.MaterializeNode{
+		BasePlanNode: plan.BasePlanNode{Children: []plan.PlanNode{node}},
+		Child:        node,
+	}, nil
+}
+
+func TestRewriteRules_RegisteredRuleShapesExplain(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db, "CREATE TABLE users (id INT, name STRING)")
+
+	explain := func() string {
+		t.Helper()
+		result, err := db.ExecuteQuery("EXPLAIN SELECT users.id FROM users")
+		if err != nil {
+			t.Fatalf("EXPLAIN failed: %v", err)
+		}
+		return result.Rows[0][0]
+	}
+
+	if strings.Contains(explain(), "Materialize") {
+		t.Fatal("expected no Materialize node before the rule is registered")
+	}
+
+	if err := db.RegisterRewriteRule(materializeRootRule{}); err != nil {
+		t.Fatalf("RegisterRewriteRule failed: %v", err)
+	}
+	if err := db.RegisterRewriteRule(materializeRootRule{}); err == nil {
+		t.Error("expected an error registering the rule twice")
+	}
+	if out := explain(); !strings.Contains(out, "Materialize") {
+		t.Errorf("expected the registered rule to add a Materialize node:\n%s", out)
+	}
+
+	db.UnregisterRewriteRule("materialize_root")
+	if strings.Contains(explain(), "Materialize") {
+		t.Error("expected no Materialize node after the rule is unregistered")
+	}
+}
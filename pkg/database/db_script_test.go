@@ -0,0 +1,168 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	dberror "storemy/pkg/error"
+)
+
+const migrationScript = `
+-- create and fill the accounts table
+CREATE TABLE accounts (id INT, owner STRING);
+INSERT INTO accounts (id, owner) VALUES (1, 'alice');
+INSERT INTO missing_table (id) VALUES (2);
+INSERT INTO accounts (id, owner) VALUES (3, 'carol; with a semicolon');
+`
+
+func countRows(t *testing.T, db *Database, table string) int {
+	t.Helper()
+	result, err := db.ExecuteQuery("SELECT * FROM " + table)
+	if err != nil {
+		t.Fatalf("SELECT from %s failed: %v", table, err)
+	}
+	return len(result.Rows)
+}
+
+func statuses(r *ScriptResult) []StatementStatus {
+	out := make([]StatementStatus, len(r.Statements))
+	for i, s := range r.Statements {
+		out[i] = s.Status
+	}
+	return out
+}
+
+func TestExecuteScript_StopOnError(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	result, err := db.ExecuteScript(migrationScript, ScriptStopOnError)
+	var dbErr *dberror.DBError
+	if !errors.As(err, &dbErr) || dbErr.Code != "SCRIPT_FAILED" {
+		t.Fatalf("expected SCRIPT_FAILED, got %v", err)
+	}
+	if !strings.Contains(dbErr.Message, "statement 3 (line 5)") {
+		t.Errorf("error should name the failing statement, got %q", dbErr.Message)
+	}
+
+	want := []StatementStatus{StatementSucceeded, StatementSucceeded, StatementFailed, StatementSkipped}
+	if got := statuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if result.Succeeded != 2 || result.Failed != 1 || result.Skipped != 1 {
+		t.Errorf("unexpected counts: %s", result.Summary())
+	}
+	if n := countRows(t, db, "accounts"); n != 1 {
+		t.Errorf("expected 1 committed row, got %d", n)
+	}
+}
+
+func TestExecuteScript_ContinueOnError(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	result, err := db.ExecuteScript(migrationScript, ScriptContinueOnError)
+	if err == nil {
+		t.Fatal("expected an error for the failed statement")
+	}
+
+	want := []StatementStatus{StatementSucceeded, StatementSucceeded, StatementFailed, StatementSucceeded}
+	if got := statuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if n := countRows(t, db, "accounts"); n != 2 {
+		t.Errorf("expected 2 committed rows, got %d", n)
+	}
+}
+
+func TestExecuteScript_RollbackAll(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Seed a row so the script's inserts land on an existing page: pages the
+	// heap allocates mid-transaction are written straight to disk and survive
+	// an abort.
+	for _, q := range []string{
+		"CREATE TABLE accounts (id INT, owner STRING)",
+		"INSERT INTO accounts (id, owner) VALUES (0, 'seed')",
+	} {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+
+	script := `
+INSERT INTO accounts (id, owner) VALUES (1, 'alice');
+INSERT INTO accounts (id, owner) VALUES (2, 'bob');
+INSERT INTO missing_table (id) VALUES (3);`
+
+	result, err := db.ExecuteScript(script, ScriptRollbackAll)
+	if err == nil {
+		t.Fatal("expected an error for the failed statement")
+	}
+	if !result.RolledBack {
+		t.Error("expected the script to be rolled back")
+	}
+	want := []StatementStatus{StatementRolledBack, StatementRolledBack, StatementFailed}
+	if got := statuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if n := countRows(t, db, "accounts"); n != 1 {
+		t.Errorf("expected the inserts to be rolled back, found %d rows", n)
+	}
+
+	result, err = db.ExecuteScript(strings.TrimSuffix(script, "INSERT INTO missing_table (id) VALUES (3);"), ScriptRollbackAll)
+	if err != nil {
+		t.Fatalf("script failed: %v", err)
+	}
+	if result.RolledBack || result.Succeeded != 2 {
+		t.Errorf("unexpected result: %s", result.Summary())
+	}
+	if n := countRows(t, db, "accounts"); n != 3 {
+		t.Errorf("expected 3 committed rows, got %d", n)
+	}
+}
+
+func TestExecuteScript_SummaryTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	result, err := db.ExecuteScript("CREATE TABLE t (id INT);\nINSERT INTO t (id) VALUES (1);", ScriptStopOnError)
+	if err != nil {
+		t.Fatalf("script failed: %v", err)
+	}
+
+	table := result.QueryResult()
+	if !table.Success || len(table.Rows) != 2 || len(table.Columns) != 7 {
+		t.Fatalf("unexpected summary table: %+v", table)
+	}
+	if table.Rows[1][1] != "2" || table.Rows[1][2] != "OK" || table.Rows[1][3] != "1" {
+		t.Errorf("unexpected row for the INSERT: %v", table.Rows[1])
+	}
+	if !strings.HasPrefix(table.Message, "2 statements: 2 succeeded, 0 failed, 0 skipped") {
+		t.Errorf("unexpected summary: %s", table.Message)
+	}
+}
+
+func TestExecuteScript_SplitError(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ExecuteScript("SELECT 'unterminated", ScriptStopOnError); err == nil {
+		t.Fatal("expected an error for an unterminated string")
+	}
+}
+
+func TestParseScriptErrorPolicy(t *testing.T) {
+	for _, p := range []ScriptErrorPolicy{ScriptStopOnError, ScriptContinueOnError, ScriptRollbackAll} {
+		got, err := ParseScriptErrorPolicy(strings.ToUpper(p.String()))
+		if err != nil || got != p {
+			t.Errorf("ParseScriptErrorPolicy(%q) = %v, %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParseScriptErrorPolicy("ignore"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSemiJoin_ExistsAndIn(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT name FROM customers c WHERE EXISTS (SELECT * FROM orders o WHERE o.customer_id = c.id)", []string{"ADA"}},
+		{"SELECT name FROM customers c WHERE NOT EXISTS (SELECT * FROM orders o WHERE o.customer_id = c.id)", []string{"ALAN", "GRACE"}},
+		{"SELECT name FROM customers c WHERE EXISTS (SELECT oid FROM orders o WHERE o.customer_id = c.id AND total < 60)", []string{"ADA"}},
+		{"SELECT name FROM customers WHERE EXISTS (SELECT * FROM orders WHERE total > 500)", []string{}},
+		{"SELECT name FROM customers WHERE id IN (SELECT customer_id FROM orders)", []string{"ADA"}},
+		{"SELECT name FROM customers WHERE id NOT IN (SELECT customer_id FROM orders)", []string{"ALAN", "GRACE"}},
+		{"SELECT name FROM customers WHERE id > 1 AND id NOT IN (SELECT customer_id FROM orders)", []string{"ALAN", "GRACE"}},
+		{"SELECT oid FROM orders o WHERE customer_id IN (SELECT id FROM customers c WHERE c.id = o.customer_id)", []string{"10", "11"}},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestSemiJoin_NotInWithNull(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	// Order 11 yields NULL, so no id is known to differ from every value
+	query := "SELECT name FROM customers WHERE id NOT IN (SELECT CASE WHEN total > 60 THEN customer_id END AS cid FROM orders)"
+	if got := groupRows(t, db, query); len(got) != 0 {
+		t.Errorf("%s = %v, expected no rows", query, got)
+	}
+
+	// IN ignores the NULL: a match is still a match
+	query = "SELECT name FROM customers WHERE id IN (SELECT CASE WHEN total > 60 THEN customer_id END AS cid FROM orders)"
+	if got := groupRows(t, db, query); !slices.Equal(got, []string{"ADA"}) {
+		t.Errorf("%s = %v, expected [ADA]", query, got)
+	}
+}
+
+func TestSemiJoin_NestedFallback(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT name FROM customers c WHERE EXISTS (SELECT * FROM orders o WHERE o.customer_id > c.id)", []string{"ADA", "ALAN", "GRACE"}},
+		{"SELECT name FROM customers c WHERE NOT EXISTS (SELECT * FROM orders o WHERE o.customer_id < c.id)", []string{"ADA"}},
+		{"SELECT name FROM customers c WHERE EXISTS (SELECT * FROM orders o WHERE o.customer_id = c.id LIMIT 1)", []string{"ADA"}},
+		{"SELECT oid FROM orders o WHERE total IN (SELECT MAX(total) FROM orders p WHERE p.customer_id = o.customer_id)", []string{"10", "12"}},
+		{"SELECT oid FROM orders o WHERE total NOT IN (SELECT MAX(total) FROM orders p WHERE p.customer_id = o.customer_id)", []string{"11"}},
+	}
+
+	for _, tt := range tests {
+		if got := groupRows(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestSemiJoin_InvalidSubqueries(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"SELECT name FROM customers c WHERE EXISTS (SELECT * FROM orders o WHERE o.customer_id = z.id)", "no table Z"},
+		{"SELECT name FROM customers c WHERE EXISTS (SELECT * FROM orders o WHERE o.customer_id > z.id)", "no table Z"},
+		{"SELECT name FROM customers c WHERE id IN (SELECT oid, total FROM orders o WHERE o.total > c.id)", "exactly one column"},
+	}
+
+	for _, tt := range tests {
+		_, err := db.ExecuteQuery(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
+
+func TestSemiJoin_Explain(t *testing.T) {
+	db := setupOuterJoinDB(t)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"EXPLAIN SELECT name FROM customers c WHERE EXISTS (SELECT * FROM orders o WHERE o.customer_id = c.id)", "semi JOIN for EXISTS (subquery) using hash"},
+		{"EXPLAIN SELECT name FROM customers WHERE id NOT IN (SELECT customer_id FROM orders)", "anti JOIN for ID NOT IN (subquery) using hash"},
+		{"EXPLAIN SELECT name FROM customers c WHERE EXISTS (SELECT * FROM orders o WHERE o.customer_id > c.id)", "semi JOIN for EXISTS (subquery) using nested loop"},
+	}
+
+	for _, tt := range tests {
+		result, err := db.ExecuteQuery(tt.query)
+		if err != nil {
+			t.Fatalf("%s failed: %v", tt.query, err)
+		}
+		if plan := result.Rows[0][0]; !strings.Contains(plan, tt.expected) {
+			t.Errorf("%s: expected plan to contain %q, got:\n%s", tt.query, tt.expected, plan)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"storemy/pkg/config"
+	"storemy/pkg/log/wal"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSettings_SuperblockCreatedOnOpen(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	if _, err := os.Stat(filepath.Join(db.dataDir, config.SuperblockFile)); err != nil {
+		t.Fatalf("expected superblock to be created: %v", err)
+	}
+	if db.Settings() != config.DefaultSettings() {
+		t.Errorf("expected default settings, got %+v", db.Settings())
+	}
+}
+
+func TestSettings_ShowPersistent(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	result, err := db.ExecuteQuery("SHOW PERSISTENT")
+	if err != nil {
+		t.Fatalf("SHOW PERSISTENT failed: %v", err)
+	}
+	if len(result.Rows) != len(config.DefaultSettings().All()) {
+		t.Errorf("expected %d settings, got %d", len(config.DefaultSettings().All()), len(result.Rows))
+	}
+
+	result, err = db.ExecuteQuery("SHOW PERSISTENT page_size")
+	if err != nil {
+		t.Fatalf("SHOW PERSISTENT page_size failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0] != "page_size" {
+		t.Errorf("expected single page_size row, got %v", result.Rows)
+	}
+
+	if _, err := db.ExecuteQuery("SHOW PERSISTENT no_such_setting"); err == nil {
+		t.Error("expected error for unknown setting")
+	}
+}
+
+func TestSettings_SetPersistentSurvivesReopen(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+
+	if _, err := db.ExecuteQuery("SET PERSISTENT checkpoint_interval = '90s'"); err != nil {
+		db.Close()
+		t.Fatalf("SET PERSISTENT failed: %v", err)
+	}
+	if _, err := db.ExecuteQuery("SET PERSISTENT page_size = 8192"); err == nil {
+		t.Error("expected page_size to be rejected")
+	}
+	db.Close()
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if got := reopened.Settings().CheckpointInterval; got != 90*time.Second {
+		t.Errorf("expected checkpoint interval 90s after reopen, got %s", got)
+	}
+}
+
+func TestSettings_SetPersistentRejectedReadOnly(t *testing.T) {
+	db, _, cleanup := setupReadOnlyDB(t)
+	defer cleanup()
+
+	if _, err := db.ExecuteQuery("SHOW PERSISTENT"); err != nil {
+		t.Errorf("SHOW PERSISTENT should be allowed read-only: %v", err)
+	}
+
+	_, err := db.ExecuteQuery("SET PERSISTENT checkpoint_enabled = false")
+	if !IsReadOnlyError(err) {
+		t.Errorf("expected read-only error, got: %v", err)
+	}
+}
+
+func TestSettings_AsyncDurabilityBuffersCommits(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	if _, err := db.ExecuteQuery("CREATE TABLE t (id INT)"); err != nil {
+		db.Close()
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+	result, err := db.ExecuteQuery("INSERT INTO t VALUES (1)")
+	if err != nil {
+		db.Close()
+		t.Fatalf("INSERT failed: %v", err)
+	}
+	if result.Buffered {
+		t.Error("commit reported as buffered under sync durability")
+	}
+	if _, err := db.ExecuteQuery("SET PERSISTENT wal_durability = 'async'"); err != nil {
+		db.Close()
+		t.Fatalf("SET PERSISTENT failed: %v", err)
+	}
+	db.Close()
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	result, err = reopened.ExecuteQuery("INSERT INTO t VALUES (2)")
+	if err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+	if !result.Buffered {
+		t.Error("expected the commit to be acknowledged while buffered under async durability")
+	}
+}
+
+func TestSettings_SetWALDurability(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	mustExec(t, db, "CREATE TABLE t (id INT)")
+	result, err := db.ExecuteQuery("SET wal_durability = async")
+	if err != nil {
+		t.Fatalf("SET wal_durability failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "set to async") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+
+	result, err = db.ExecuteQuery("INSERT INTO t VALUES (1)")
+	if err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+	if !result.Buffered {
+		t.Error("expected the commit to be acknowledged while buffered after SET wal_durability = async")
+	}
+
+	mustExec(t, db, "SET wal_sync_interval = '50ms'")
+	mustExec(t, db, "SET wal_durability = interval")
+	if stats := db.walInstance.Stats(); stats.Durability != wal.DurabilityInterval || stats.SyncInterval != 50*time.Millisecond {
+		t.Errorf("WAL stats report %s every %s, want interval every 50ms", stats.Durability, stats.SyncInterval)
+	}
+
+	if _, err := db.ExecuteQuery("SET wal_durability = eventually"); err == nil {
+		t.Error("expected an unknown durability level to be rejected")
+	}
+	if _, err := db.ExecuteQuery("SET wal_sync_interval = '0s'"); err == nil {
+		t.Error("expected a zero sync interval to be rejected")
+	}
+
+	// SET lasts until the database is reopened
+	if got := db.Settings().WALDurability; got != wal.DurabilitySync {
+		t.Errorf("expected the persistent durability to stay sync, got %s", got)
+	}
+}
+
+func TestSettings_DirectIO(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	mustExec(t, db, "CREATE TABLE t (id INT, name VARCHAR)")
+	mustExec(t, db, "INSERT INTO t VALUES (1, 'before')")
+	mustExec(t, db, "SET PERSISTENT direct_io = true")
+	db.Close()
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if !reopened.pageStore.DirectIO() {
+		t.Error("expected the page files to be set to direct I/O")
+	}
+	for i := 2; i <= 20; i++ {
+		mustExec(t, reopened, fmt.Sprintf("INSERT INTO t VALUES (%d, 'after')", i))
+	}
+	result, err := reopened.ExecuteQuery("SELECT COUNT(*) FROM t")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if result.Rows[0][0] != "20" {
+		t.Errorf("expected 20 rows, got %v", result.Rows)
+	}
+}
+
+func TestSettings_CostParametersAffectExplain(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	mustExec(t, db,
+		"CREATE TABLE t (id INT)",
+		"INSERT INTO t VALUES (1)",
+		"INSERT INTO t VALUES (2)",
+	)
+
+	totalCost := func() string {
+		t.Helper()
+		result, err := db.ExecuteQuery("EXPLAIN SELECT id FROM t WHERE id > 1")
+		if err != nil {
+			t.Fatalf("EXPLAIN failed: %v", err)
+		}
+		_, cost, found := strings.Cut(result.Rows[0][0], "Total Cost: ")
+		if !found {
+			t.Fatalf("expected a total cost in %s", result.Rows[0][0])
+		}
+		return strings.Fields(cost)[0]
+	}
+
+	before := totalCost()
+	mustExec(t, db,
+		"SET PERSISTENT random_page_cost = 4",
+		"SET PERSISTENT seq_page_cost = 0.5",
+		"SET PERSISTENT cpu_tuple_cost = 0.1",
+	)
+	if after := totalCost(); after == before {
+		t.Errorf("expected the cost settings to change the plan cost %s", before)
+	}
+
+	if db.Settings().CostParameters().RandomPageCost != 4 {
+		t.Errorf("expected random_page_cost 4, got %+v", db.Settings().CostParameters())
+	}
+}
+
+func TestSettings_SetBufferPoolSize(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	mustExec(t, db, "CREATE TABLE items (id INT, name VARCHAR)")
+	for i := range 50 {
+		mustExec(t, db, fmt.Sprintf("INSERT INTO items VALUES (%d, 'item')", i))
+	}
+
+	result, err := db.ExecuteQuery("SET buffer_pool_size = 20")
+	if err != nil {
+		t.Fatalf("SET buffer_pool_size failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "set to 20 pages") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+
+	result, err = db.ExecuteQuery("SELECT CAPACITY, CACHED_PAGES FROM SYS_BUFFER_POOL")
+	if err != nil {
+		t.Fatalf("SYS_BUFFER_POOL failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0] != "20" {
+		t.Fatalf("expected capacity 20, got %v", result.Rows)
+	}
+
+	// Queries keep working in the smaller pool
+	result, err = db.ExecuteQuery("SELECT COUNT(*) FROM items")
+	if err != nil {
+		t.Fatalf("SELECT after shrinking failed: %v", err)
+	}
+	if result.Rows[0][0] != "50" {
+		t.Errorf("expected 50 rows, got %v", result.Rows)
+	}
+
+	if _, err := db.ExecuteQuery("SET buffer_pool_size = 2"); err == nil {
+		t.Error("expected a size below the minimum to be rejected")
+	}
+	if _, err := db.ExecuteQuery("SET no_such_setting = 1"); err == nil {
+		t.Error("expected an unknown setting to be rejected")
+	}
+
+	res, err := db.SetBufferPoolSize(500)
+	if err != nil || res.OldCapacity != 20 || db.pageStore.Capacity() != 500 {
+		t.Errorf("SetBufferPoolSize(500) = %+v, %v", res, err)
+	}
+	if _, err := db.SetBufferPoolSize(0); err == nil || !strings.Contains(err.Error(), "INVALID_BUFFER_POOL_SIZE") {
+		t.Errorf("expected INVALID_BUFFER_POOL_SIZE, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/logging"
+	"testing"
+	"time"
+)
+
+func requireErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var dbErr *dberror.DBError
+	if !errors.As(err, &dbErr) || dbErr.Code != code {
+		t.Fatalf("expected %s error, got %v", code, err)
+	}
+}
+
+func TestShutdown_RejectsNewWork(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ExecuteQuery("CREATE TABLE t (id INT)"); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	_, err := db.ExecuteQuery("SELECT * FROM t")
+	requireErrorCode(t, err, ErrCodeDatabaseClosed)
+
+	_, err = db.BeginTransaction()
+	requireErrorCode(t, err, ErrCodeDatabaseClosed)
+
+	_, err = db.Check()
+	requireErrorCode(t, err, ErrCodeDatabaseClosed)
+
+	if err := db.Close(); err != nil {
+		t.Errorf("second Close should return the first result, got %v", err)
+	}
+}
+
+func TestShutdown_WaitsForActiveTransaction(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- db.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown returned while a transaction was active: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Shutdown failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not return after the transaction committed")
+	}
+}
+
+func TestShutdown_AbortsAfterDeadline(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = db.Shutdown(ctx)
+	requireErrorCode(t, err, "SHUTDOWN_TIMEOUT")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	if got := tx.GetStatus(); got != transaction.TxAborted {
+		t.Errorf("expected the straggling transaction to be aborted, got %s", got)
+	}
+}
+
+func TestShutdown_DataSurvivesReopen(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "data")
+	logDir := filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, Options{ShutdownTimeout: time.Second, Logger: logging.NewNopLogger()})
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	for _, q := range []string{
+		"CREATE TABLE users (id INT, name STRING)",
+		"INSERT INTO users (id, name) VALUES (1, 'alice')",
+		"INSERT INTO users (id, name) VALUES (2, 'bob')",
+	} {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if n := len(db.txRegistry.GetActive()); n != 0 {
+		t.Errorf("%d transactions still active after Close", n)
+	}
+
+	reopened, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	result, err := reopened.ExecuteQuery("SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Errorf("expected 2 rows after reopen, got %d", len(result.Rows))
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "testdb", "USERS.dat")); err != nil {
+		t.Errorf("heap file missing after shutdown: %v", err)
+	}
+}
+
+func TestShutdown_DoesNotWaitOnFailedAbort(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	mustExec(t, db, "CREATE TABLE users (id INT)")
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(tx, "INSERT INTO users VALUES (1)"); err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+
+	// With its heap file closed the commit fails after COMMIT is logged,
+	// and the abort that follows cannot log ABORT
+	tableID, err := db.catalogMgr.GetTableID(tx, "users")
+	if err != nil {
+		t.Fatalf("GetTableID failed: %v", err)
+	}
+	file, err := db.catalogMgr.GetTableFile(tableID)
+	if err != nil {
+		t.Fatalf("GetTableFile failed: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("closing the heap file failed: %v", err)
+	}
+	if err := db.CommitTransaction(tx); err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+	if err := db.AbortTransaction(tx); err == nil {
+		t.Fatal("expected the abort to fail")
+	}
+	if got := tx.GetStatus(); got != transaction.TxAborted {
+		t.Errorf("expected the failed transaction to end aborted, got %s", got)
+	}
+
+	start := time.Now()
+	err = db.Close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Close took %v waiting on the failed transaction", elapsed)
+	}
+	var dbErr *dberror.DBError
+	if errors.As(err, &dbErr) && dbErr.Code == "SHUTDOWN_TIMEOUT" {
+		t.Errorf("Close timed out on the failed transaction: %v", err)
+	}
+}
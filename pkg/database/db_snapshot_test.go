@@ -0,0 +1,128 @@
+package database
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/lock"
+	"storemy/pkg/concurrency/transaction"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_ExportAndImport(t *testing.T) {
+	db := setupTableLockDB(t, 5*time.Second)
+	mustExec(t, db, "CREATE TABLE orders (id INT, total INT)", "INSERT INTO orders VALUES (1, 100)")
+
+	exporter, err := db.BeginReadOnlyTransaction()
+	if err != nil {
+		t.Fatalf("BeginReadOnlyTransaction failed: %v", err)
+	}
+	token, err := db.ExportSnapshot(exporter)
+	if err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+
+	// A writer started after the export waits for the snapshot
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecuteQuery("INSERT INTO users VALUES (2, 'bob')")
+		done <- err
+	}()
+
+	workers := make([]*transaction.TransactionContext, 2)
+	for i := range workers {
+		tx, err := db.BeginReadOnlyTransaction()
+		if err != nil {
+			t.Fatalf("BeginReadOnlyTransaction failed: %v", err)
+		}
+		if i == 0 {
+			_, err = db.ExecuteInTransaction(tx, fmt.Sprintf("SET TRANSACTION SNAPSHOT '%s'", token))
+		} else {
+			err = db.ImportSnapshot(tx, token)
+		}
+		if err != nil {
+			t.Fatalf("worker %d: import failed: %v", i, err)
+		}
+		workers[i] = tx
+	}
+
+	// The exporter may finish first; the workers keep their snapshot
+	if err := db.CommitTransaction(exporter); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	for i, tx := range workers {
+		for _, query := range []string{"SELECT id FROM users", "SELECT id FROM orders"} {
+			result, err := db.ExecuteInTransaction(tx, query)
+			if err != nil || len(result.Rows) != 1 {
+				t.Errorf("worker %d: %s returned %d rows, err %v; expected 1", i, query, len(result.Rows), err)
+			}
+		}
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("INSERT finished while the snapshot was held: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	for _, tx := range workers {
+		if err := db.CommitTransaction(tx); err != nil {
+			t.Fatalf("CommitTransaction failed: %v", err)
+		}
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("INSERT failed after the snapshot was released: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("INSERT did not finish after the snapshot was released")
+	}
+}
+
+func TestSnapshot_WriteTimesOut(t *testing.T) {
+	db := setupTableLockDB(t, 100*time.Millisecond)
+
+	tx, err := db.BeginReadOnlyTransaction()
+	if err != nil {
+		t.Fatalf("BeginReadOnlyTransaction failed: %v", err)
+	}
+	defer db.CommitTransaction(tx)
+	if _, err := db.ExportSnapshot(tx); err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+
+	_, err = db.ExecuteQuery("INSERT INTO users VALUES (2, 'bob')")
+	if err == nil || !strings.Contains(err.Error(), lock.ErrSnapshotTimeout.Error()) {
+		t.Errorf("expected a snapshot timeout, got %v", err)
+	}
+}
+
+func TestSnapshot_InvalidImports(t *testing.T) {
+	db := setupTableLockDB(t, 100*time.Millisecond)
+
+	exporter, err := db.BeginReadOnlyTransaction()
+	if err != nil {
+		t.Fatalf("BeginReadOnlyTransaction failed: %v", err)
+	}
+	token, err := db.ExportSnapshot(exporter)
+	if err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+	if err := db.CommitTransaction(exporter); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	tx, err := db.BeginReadOnlyTransaction()
+	if err != nil {
+		t.Fatalf("BeginReadOnlyTransaction failed: %v", err)
+	}
+	defer db.CommitTransaction(tx)
+
+	for _, tok := range []string{token, "NOT-A-SNAPSHOT"} {
+		_, err := db.ExecuteInTransaction(tx, fmt.Sprintf("SET TRANSACTION SNAPSHOT '%s'", tok))
+		if err == nil || !strings.Contains(err.Error(), "snapshot not found") {
+			t.Errorf("import of %s: expected snapshot not found, got %v", tok, err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"path/filepath"
+	dberror "storemy/pkg/error"
+	"testing"
+)
+
+func TestExecuteQuery_SQLState(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+	mustExec(t, db, "CREATE TABLE users (id INT)")
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELEC 1", dberror.SQLStateSyntaxError},
+		{"SELECT * FROM missing", dberror.SQLStateUndefinedTable},
+		{"INSERT INTO missing VALUES (1)", dberror.SQLStateUndefinedTable},
+		{"DROP TABLE missing", dberror.SQLStateUndefinedTable},
+		{"CREATE TABLE users (id INT)", dberror.SQLStateDuplicateTable},
+		{"SELECT * FROM users", dberror.SQLStateSuccessful},
+	}
+	for _, tt := range tests {
+		_, err := db.ExecuteQuery(tt.query)
+		if got := dberror.SQLState(err); got != tt.want {
+			t.Errorf("%s: SQLSTATE %s, want %s (error: %v)", tt.query, got, tt.want, err)
+		}
+	}
+}
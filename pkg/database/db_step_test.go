@@ -0,0 +1,68 @@
+package database
+
+import (
+	"storemy/pkg/tracing"
+	"strings"
+	"testing"
+)
+
+func TestExecuteQueryStepByStep_ReportsOperatorSteps(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE users (id INT, name STRING)",
+		"INSERT INTO users VALUES (3, 'carol'), (1, 'alice'), (2, 'bob')")
+
+	var steps []tracing.StepEvent
+	result, err := db.ExecuteQueryStepByStep("SELECT name FROM users WHERE id > 1 ORDER BY name", func(e tracing.StepEvent) {
+		steps = append(steps, e)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteQueryStepByStep failed: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %v", result.Rows)
+	}
+
+	var lines []string
+	for _, step := range steps {
+		lines = append(lines, step.String())
+	}
+	log := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"Scan USERS opened",
+		"Scan USERS returned row 1 from page 0",
+		"Scan USERS read page 0, returned 2 rows",
+		"Sort finished after 2 rows",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("expected step %q in:\n%s", want, log)
+		}
+	}
+
+	if steps[0].Kind != tracing.StepOpen || steps[len(steps)-1].Kind != tracing.StepClose {
+		t.Errorf("expected the steps to start with an open and end with a close, got:\n%s", log)
+	}
+}
+
+func TestExplainAnalyzeEducational_StepsThroughExecution(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE users (id INT, name STRING)",
+		"INSERT INTO users VALUES (1, 'alice'), (2, 'bob')")
+
+	result, err := db.ExecuteQuery("EXPLAIN ANALYZE FORMAT EDUCATIONAL SELECT * FROM users WHERE id > 1")
+	if err != nil {
+		t.Fatalf("EXPLAIN ANALYZE FORMAT EDUCATIONAL failed: %v", err)
+	}
+
+	out := result.Rows[0][0]
+	for _, want := range []string{"EDUCATIONAL QUERY PLAN EXPLANATION", "Actual Rows: 1", "STEP-THROUGH EXECUTION", "Scan USERS read page 0, returned 1 row"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"path/filepath"
+	"storemy/pkg/concurrency/lock"
+	"storemy/pkg/sysview"
+	"strings"
+	"testing"
+	"time"
+)
+
+func columnIndex(t *testing.T, result QueryResult, name string) int {
+	t.Helper()
+	for i, col := range result.Columns {
+		if strings.EqualFold(col, name) {
+			return i
+		}
+	}
+	t.Fatalf("column %s not found in %v", name, result.Columns)
+	return -1
+}
+
+func TestSystemViews_AllQueryable(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	views := []string{
+		sysview.SessionsView,
+		sysview.TransactionsView,
+		sysview.LocksView,
+		sysview.LockQueuesView,
+		sysview.BufferPoolView,
+		sysview.WALView,
+		sysview.ReplicationView,
+		sysview.CheckpointerView,
+		sysview.StatementsView,
+		sysview.AutoAnalyzeView,
+		sysview.ResultCacheView,
+		sysview.TableStorageView,
+	}
+	for _, name := range views {
+		if _, err := db.ExecuteQuery("SELECT * FROM " + strings.ToLower(name)); err != nil {
+			t.Errorf("SELECT from %s failed: %v", name, err)
+		}
+	}
+}
+
+func TestSystemViews_SessionsAndTransactionsShowCurrentQuery(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	result, err := db.ExecuteQuery("SELECT * FROM sys_sessions")
+	if err != nil {
+		t.Fatalf("SELECT from sys_sessions failed: %v", err)
+	}
+	if len(result.Rows) != 1 {
+		t.Fatalf("expected only the current session, got %v", result.Rows)
+	}
+	sessionTx := result.Rows[0][columnIndex(t, result, "TX_ID")]
+
+	result, err = db.ExecuteQuery("SELECT * FROM sys_transactions")
+	if err != nil {
+		t.Fatalf("SELECT from sys_transactions failed: %v", err)
+	}
+	if len(result.Rows) == 0 {
+		t.Fatal("expected the current transaction to be listed")
+	}
+	if sessionTx == result.Rows[0][columnIndex(t, result, "TX_ID")] {
+		t.Error("expected a new transaction for the second query")
+	}
+
+	if len(db.sessions.Snapshot()) != 0 {
+		t.Error("expected sessions to be removed once queries finish")
+	}
+}
+
+func TestSystemViews_WALPositionAdvances(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	before, err := db.ExecuteQuery("SELECT current_lsn FROM sys_wal")
+	if err != nil {
+		t.Fatalf("SELECT from sys_wal failed: %v", err)
+	}
+
+	if _, err := db.ExecuteQuery("CREATE TABLE items (id INT, name VARCHAR)"); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+	if _, err := db.ExecuteQuery("INSERT INTO items VALUES (1, 'a')"); err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+
+	after, err := db.ExecuteQuery("SELECT current_lsn FROM sys_wal")
+	if err != nil {
+		t.Fatalf("SELECT from sys_wal failed: %v", err)
+	}
+	if before.Rows[0][0] == after.Rows[0][0] {
+		t.Errorf("expected WAL position to advance, stayed at %s", after.Rows[0][0])
+	}
+}
+
+func TestSystemViews_Filter(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	result, err := db.ExecuteQuery("SELECT * FROM sys_checkpointer WHERE running = true")
+	if err != nil {
+		t.Fatalf("filtered SELECT from sys_checkpointer failed: %v", err)
+	}
+	if len(result.Rows) != 1 {
+		t.Errorf("expected the checkpoint daemon to be running, got %v", result.Rows)
+	}
+
+	result, err = db.ExecuteQuery("SELECT * FROM sys_locks WHERE granted = false")
+	if err != nil {
+		t.Fatalf("filtered SELECT from sys_locks failed: %v", err)
+	}
+	if len(result.Rows) != 0 {
+		t.Errorf("expected no lock waits, got %v", result.Rows)
+	}
+}
+
+func TestSystemViews_StatementsAggregatesByFingerprint(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	queries := []string{
+		"CREATE TABLE items (id INT, name VARCHAR)",
+		"INSERT INTO items VALUES (1, 'a')",
+		"INSERT INTO items VALUES (2, 'b')",
+		"INSERT INTO items VALUES (3, 'c')",
+	}
+	for _, q := range queries {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("%s failed: %v", q, err)
+		}
+	}
+
+	result, err := db.ExecuteQuery("SELECT * FROM sys_statements")
+	if err != nil {
+		t.Fatalf("SELECT from sys_statements failed: %v", err)
+	}
+
+	queryCol := columnIndex(t, result, "QUERY")
+	callsCol := columnIndex(t, result, "CALLS")
+	rowsCol := columnIndex(t, result, "ROWS")
+	found := false
+	for _, row := range result.Rows {
+		if row[queryCol] == "INSERT INTO ITEMS VALUES (?, ?)" {
+			found = true
+			if row[callsCol] != "3" || row[rowsCol] != "3" {
+				t.Errorf("expected 3 calls and 3 rows, got calls=%s rows=%s", row[callsCol], row[rowsCol])
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected normalized INSERT fingerprint, got %v", result.Rows)
+	}
+
+	db.ResetStatementStatistics()
+	result, err = db.ExecuteQuery("SELECT * FROM sys_statements")
+	if err != nil {
+		t.Fatalf("SELECT from sys_statements failed: %v", err)
+	}
+	if len(result.Rows) != 0 {
+		t.Errorf("expected no statistics after reset, got %v", result.Rows)
+	}
+}
+
+func TestSystemViews_LockQueuesShowWaitingWriter(t *testing.T) {
+	tempDir := t.TempDir()
+	opts := testOptions()
+	opts.LockGrantPolicy = lock.GrantWriterPreference
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	defer db.Close()
+	mustExec(t, db, "CREATE TABLE users (id INT, name STRING)", "INSERT INTO users VALUES (1, 'alice')")
+
+	holder, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(holder, "UPDATE users SET name = 'carol' WHERE id = 1"); err != nil {
+		t.Fatalf("UPDATE failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecuteQuery("UPDATE users SET name = 'bob' WHERE id = 1")
+		done <- err
+	}()
+
+	var row []string
+	var result QueryResult
+	deadline := time.Now().Add(2 * time.Second)
+	for row == nil && time.Now().Before(deadline) {
+		result, err = db.ExecuteQuery("SELECT * FROM sys_lock_queues")
+		if err != nil {
+			t.Fatalf("SELECT from sys_lock_queues failed: %v", err)
+		}
+		if len(result.Rows) > 0 {
+			row = result.Rows[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if row == nil {
+		db.AbortTransaction(holder)
+		t.Fatal("expected the waiting UPDATE to be listed")
+	}
+	if got := row[columnIndex(t, result, "WAITING_EXCLUSIVE")]; got != "1" {
+		t.Errorf("WAITING_EXCLUSIVE = %s, expected 1", got)
+	}
+	if got := row[columnIndex(t, result, "POLICY")]; got != "WRITER_PREFERENCE" {
+		t.Errorf("POLICY = %s, expected WRITER_PREFERENCE", got)
+	}
+
+	if err := db.CommitTransaction(holder); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("UPDATE failed: %v", err)
+	}
+}
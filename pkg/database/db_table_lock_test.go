@@ -0,0 +1,94 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupTableLockDB(t *testing.T, timeout time.Duration) *Database {
+	t.Helper()
+	tempDir := t.TempDir()
+	opts := testOptions()
+	opts.TableLockTimeout = timeout
+
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mustExec(t, db, "CREATE TABLE users (id INT, name STRING)", "INSERT INTO users VALUES (1, 'alice')")
+	return db
+}
+
+func TestTableLock_DropWaitsForRunningTransaction(t *testing.T) {
+	db := setupTableLockDB(t, 5*time.Second)
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(tx, "SELECT id FROM users"); err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecuteQuery("DROP TABLE users")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("DROP TABLE finished while a transaction was reading the table: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The reader still sees the table until it finishes
+	result, err := db.ExecuteInTransaction(tx, "SELECT id FROM users")
+	if err != nil || len(result.Rows) != 1 {
+		t.Fatalf("second SELECT: %d rows, err %v", len(result.Rows), err)
+	}
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("DROP TABLE failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("DROP TABLE did not finish after the reader committed")
+	}
+	if _, err := db.ExecuteQuery("SELECT id FROM users"); err == nil {
+		t.Error("expected the table to be gone")
+	}
+}
+
+func TestTableLock_DropTimesOut(t *testing.T) {
+	db := setupTableLockDB(t, 100*time.Millisecond)
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(tx, "INSERT INTO users VALUES (2, 'bob')"); err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+
+	_, err = db.ExecuteQuery("DROP TABLE users")
+	if err == nil || !strings.Contains(err.Error(), "timeout waiting for table lock") {
+		t.Fatalf("expected a table lock timeout, got %v", err)
+	}
+
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	result, err := db.ExecuteQuery("SELECT id FROM users")
+	if err != nil || len(result.Rows) != 2 {
+		t.Fatalf("expected the table to survive with 2 rows: %d rows, err %v", len(result.Rows), err)
+	}
+}
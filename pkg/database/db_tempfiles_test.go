@@ -0,0 +1,58 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTempFiles_SweptOnOpenAndRemovedOnClose(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir, logDir := filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs")
+
+	db, err := NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	if db.tempFiles == nil || db.tempFiles.Config().Dir != filepath.Join(dataDir, "testdb", TempDirName) {
+		t.Fatalf("expected temporary files in the tmp directory of the database")
+	}
+
+	// A file a query still holds when the process dies is left behind
+	f, err := db.tempFiles.NewQuery().Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := f.Write([]byte("sort run")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	leftover := f.Name() + "-crash"
+	if err := os.Link(f.Name(), leftover); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+
+	db.Close()
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("expected Close to remove the files of running queries, stat returned %v", err)
+	}
+
+	db, err = NewDatabaseWithOptions("testdb", dataDir, logDir, testOptions())
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	defer db.Close()
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Errorf("expected the leftover file to be swept on open, stat returned %v", err)
+	}
+	if db.tempFiles.Stats().Swept != 1 {
+		t.Errorf("expected 1 swept file, got %d", db.tempFiles.Stats().Swept)
+	}
+
+	mustExec(t, db, "CREATE TABLE users (id INT)", "INSERT INTO users VALUES (1)")
+	if _, err := db.ExecuteQuery("SELECT id FROM users"); err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if stats := db.tempFiles.Stats(); stats.Files != 0 || stats.Used != 0 {
+		t.Errorf("expected no temporary files after the query, got %+v", stats)
+	}
+}
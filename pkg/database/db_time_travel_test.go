@@ -0,0 +1,93 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// currentLSN returns the CURRENT_LSN column of SYS_WAL.
+func currentLSN(t *testing.T, db *Database) string {
+	t.Helper()
+	result, err := db.ExecuteQuery("SELECT CURRENT_LSN FROM SYS_WAL")
+	if err != nil || len(result.Rows) != 1 {
+		t.Fatalf("SELECT FROM SYS_WAL failed: %v", err)
+	}
+	return result.Rows[0][0]
+}
+
+// sortedColumn returns the first column of the rows of query, sorted.
+func sortedColumn(t *testing.T, db *Database, query string) []string {
+	t.Helper()
+	result, err := db.ExecuteQuery(query)
+	if err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+	var values []string
+	for _, row := range result.Rows {
+		values = append(values, row[0])
+	}
+	slices.Sort(values)
+	return values
+}
+
+func TestTimeTravel_AsOfLSN(t *testing.T) {
+	db := setupTableLockDB(t, time.Second)
+	mustExec(t, db, "INSERT INTO users VALUES (2, 'bob')")
+	lsn := currentLSN(t, db)
+
+	mustExec(t, db,
+		"INSERT INTO users VALUES (3, 'carol')",
+		"UPDATE users SET id = 20 WHERE id = 2",
+		"DELETE FROM users WHERE id = 1",
+	)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{fmt.Sprintf("SELECT id FROM users AS OF LSN %s", lsn), []string{"1", "2"}},
+		{fmt.Sprintf("SELECT id FROM users AS OF LSN %s WHERE id > 1", lsn), []string{"2"}},
+		{fmt.Sprintf("SELECT name FROM users u AS OF LSN %s WHERE u.id = 1", lsn), []string{"ALICE"}},
+		{"SELECT id FROM users", []string{"20", "3"}},
+	}
+	for _, tt := range tests {
+		if got := sortedColumn(t, db, tt.query); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s returned %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestTimeTravel_AsOfTimestamp(t *testing.T) {
+	db := setupTableLockDB(t, time.Second)
+	before := time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+	query := fmt.Sprintf("SELECT id FROM users AS OF TIMESTAMP '%s'", before)
+	if got := sortedColumn(t, db, query); len(got) != 0 {
+		t.Errorf("%s returned %v, expected no rows", query, got)
+	}
+
+	now := time.Now().Add(time.Minute).Format(time.DateTime)
+	query = fmt.Sprintf("SELECT id FROM users AS OF TIMESTAMP '%s'", now)
+	if got := sortedColumn(t, db, query); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("%s returned %v, expected [1]", query, got)
+	}
+}
+
+func TestTimeTravel_Retention(t *testing.T) {
+	db := setupTableLockDB(t, time.Second)
+
+	old := time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	_, err := db.ExecuteQuery(fmt.Sprintf("SELECT id FROM users AS OF TIMESTAMP '%s'", old))
+	if err == nil || !strings.Contains(err.Error(), "retention") {
+		t.Errorf("expected a retention error, got %v", err)
+	}
+
+	mustExec(t, db, "SET PERSISTENT time_travel_retention = '0s'")
+	_, err = db.ExecuteQuery(fmt.Sprintf("SELECT id FROM users AS OF LSN %s", currentLSN(t, db)))
+	if err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("expected AS OF to be disabled, got %v", err)
+	}
+}
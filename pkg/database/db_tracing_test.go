@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"storemy/pkg/tracing"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingExporter keeps every exported span in memory.
+type recordingExporter struct {
+	mutex  sync.Mutex
+	traces [][]tracing.SpanData
+}
+
+func (e *recordingExporter) ExportSpans(ctx context.Context, spans []tracing.SpanData) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.traces = append(e.traces, spans)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func createTracingTestTable(t *testing.T, db *Database) {
+	t.Helper()
+	if _, err := db.ExecuteQuery("CREATE TABLE users (id INT, name VARCHAR)"); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+	for _, q := range []string{
+		"INSERT INTO users VALUES (1, 'alice')",
+		"INSERT INTO users VALUES (2, 'bob')",
+		"INSERT INTO users VALUES (3, 'carol')",
+	} {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("INSERT failed: %v", err)
+		}
+	}
+}
+
+func TestExplainAnalyze_ReportsActualRows(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+	createTracingTestTable(t, db)
+
+	result, err := db.ExecuteQuery("EXPLAIN ANALYZE SELECT * FROM users WHERE id > 1")
+	if err != nil {
+		t.Fatalf("EXPLAIN ANALYZE failed: %v", err)
+	}
+
+	out := result.Rows[0][0]
+	if !strings.Contains(out, "Actual Rows: 2") || !strings.Contains(out, "Execution Time:") {
+		t.Errorf("expected execution statistics, got:\n%s", out)
+	}
+	if strings.Contains(out, "Trace:") {
+		t.Errorf("trace must only be included with VERBOSE, got:\n%s", out)
+	}
+}
+
+func TestExplainAnalyzeVerbose_IncludesSpanTree(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+	createTracingTestTable(t, db)
+
+	result, err := db.ExecuteQuery("EXPLAIN ANALYZE VERBOSE SELECT * FROM users WHERE id > 1 ORDER BY id")
+	if err != nil {
+		t.Fatalf("EXPLAIN ANALYZE VERBOSE failed: %v", err)
+	}
+
+	out := strings.ToUpper(result.Rows[0][0])
+	for _, want := range []string{"TRACE:", "-> PARSE", "-> PLAN", "-> EXECUTE", "-> SORT", "-> SCAN USERS", "ROWS=2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in trace output, got:\n%s", want, result.Rows[0][0])
+		}
+	}
+}
+
+func TestTraceExporter_ReceivesQuerySpans(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "db_tracing_test_*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	exporter := &recordingExporter{}
+	opts := testOptions()
+	opts.TraceExporter = exporter
+
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	defer db.Close()
+	createTracingTestTable(t, db)
+
+	before := len(exporter.traces)
+	if _, err := db.ExecuteQuery("SELECT name FROM users WHERE id = 2"); err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(exporter.traces) != before+1 {
+		t.Fatalf("expected one exported trace for the query, got %d", len(exporter.traces)-before)
+	}
+
+	spans := exporter.traces[len(exporter.traces)-1]
+	if spans[0].Name != "query" || spans[0].ParentSpanID.IsValid() {
+		t.Fatalf("expected root span first, got %+v", spans[0])
+	}
+
+	names := make(map[string]bool)
+	for _, s := range spans {
+		names[strings.ToUpper(s.Name)] = true
+		if s.TraceID != spans[0].TraceID {
+			t.Errorf("span %s has a different trace ID", s.Name)
+		}
+		if s.EndTime.Before(s.StartTime) {
+			t.Errorf("span %s ends before it starts", s.Name)
+		}
+	}
+	for _, want := range []string{"PARSE", "PLAN", "EXECUTE", "COMMIT", "PROJECT", "SCAN USERS"} {
+		if !names[want] {
+			t.Errorf("expected span %q, got %v", want, names)
+		}
+	}
+}
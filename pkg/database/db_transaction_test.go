@@ -1,6 +1,12 @@
 package database
 
 import (
+	"errors"
+	"maps"
+	"path/filepath"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/trigger"
+	"storemy/pkg/types"
 	"sync"
 	"testing"
 )
@@ -593,3 +599,166 @@ func TestTransaction_ConcurrentReadWrite(t *testing.T) {
 		t.Error("expected successful query after concurrent operations")
 	}
 }
+
+// TestTransaction_IsolationLevelOption tests that new transactions start at
+// the isolation level given in Options
+func TestTransaction_IsolationLevelOption(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if got := tx.IsolationLevel(); got != transaction.RepeatableRead {
+		t.Errorf("default isolation level = %v, want REPEATABLE READ", got)
+	}
+	db.CommitTransaction(tx)
+	cleanup()
+
+	tempDir := t.TempDir()
+	opts := testOptions()
+	opts.IsolationLevel = transaction.Serializable
+	db, err = NewDatabaseWithOptions("testdb", filepath.Join(tempDir, "data"), filepath.Join(tempDir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	defer db.Close()
+
+	tx, err = db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	defer db.CommitTransaction(tx)
+	if got := tx.IsolationLevel(); got != transaction.Serializable {
+		t.Errorf("isolation level = %v, want SERIALIZABLE", got)
+	}
+}
+
+// TestTransaction_StatementAtomicity tests that a failed statement inside an
+// explicit transaction undoes only its own changes
+func TestTransaction_StatementAtomicity(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.RegisterTriggerFunc("reject_third", func(ctx *trigger.Context) error {
+		id, err := ctx.OldValue("id")
+		if err != nil {
+			return err
+		}
+		if id.(*types.IntField).Value == 3 {
+			return errors.New("account 3 is frozen")
+		}
+		return nil
+	})
+	mustExec(t, db,
+		"CREATE TABLE accounts (id INT, balance INT)",
+		"INSERT INTO accounts (id, balance) VALUES (1, 100)",
+		"INSERT INTO accounts (id, balance) VALUES (2, 200)",
+		"INSERT INTO accounts (id, balance) VALUES (3, 300)",
+		"CREATE TRIGGER frozen BEFORE UPDATE ON accounts EXECUTE FUNCTION reject_third",
+	)
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := db.ExecuteInTransaction(tx, "INSERT INTO accounts (id, balance) VALUES (4, 400)"); err != nil {
+		t.Fatalf("INSERT failed: %v", err)
+	}
+
+	// Accounts 1 and 2 are updated before the trigger rejects account 3
+	_, err = db.ExecuteInTransaction(tx, "UPDATE accounts SET balance = 0")
+	requireErrorCode(t, err, "TRIGGER_FAILED")
+	if !tx.IsActive() {
+		t.Fatal("expected the transaction to stay active after a failed statement")
+	}
+
+	balances := func(result QueryResult) map[string]string {
+		got := make(map[string]string)
+		for _, row := range result.Rows {
+			got[row[0]] = row[1]
+		}
+		return got
+	}
+	want := map[string]string{"1": "100", "2": "200", "3": "300", "4": "400"}
+
+	result, err := db.ExecuteInTransaction(tx, "SELECT id, balance FROM accounts")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if got := balances(result); !maps.Equal(got, want) {
+		t.Errorf("expected the failed UPDATE to be undone, got %v", got)
+	}
+
+	if _, err := db.ExecuteInTransaction(tx, "UPDATE accounts SET balance = 150 WHERE id = 1"); err != nil {
+		t.Fatalf("UPDATE after the failed statement failed: %v", err)
+	}
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	result, err = db.ExecuteQuery("SELECT id, balance FROM accounts")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	want["1"] = "150"
+	if got := balances(result); !maps.Equal(got, want) {
+		t.Errorf("expected %v after commit, got %v", want, got)
+	}
+}
+
+// TestTransaction_ExecuteInFinishedTransaction tests that statements are
+// rejected once the transaction has ended
+func TestTransaction_ExecuteInFinishedTransaction(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	_, err = db.ExecuteInTransaction(tx, "CREATE TABLE late (id INT)")
+	requireErrorCode(t, err, "TX_NOT_ACTIVE")
+}
+
+// TestTransaction_ReadOnly tests that a read-only transaction reads without
+// logging and rejects writes
+func TestTransaction_ReadOnly(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE accounts (id INT, balance INT)",
+		"INSERT INTO accounts (id, balance) VALUES (1, 100)",
+	)
+
+	tx, err := db.BeginReadOnlyTransaction()
+	if err != nil {
+		t.Fatalf("BeginReadOnlyTransaction failed: %v", err)
+	}
+	before := db.walInstance.CurrentLSN()
+
+	result, err := db.ExecuteInTransaction(tx, "SELECT id, balance FROM accounts")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][1] != "100" {
+		t.Errorf("expected one account with balance 100, got %v", result.Rows)
+	}
+
+	_, err = db.ExecuteInTransaction(tx, "UPDATE accounts SET balance = 0")
+	requireErrorCode(t, err, ErrCodeReadOnly)
+	if !tx.IsActive() {
+		t.Fatal("expected the transaction to stay active after a rejected write")
+	}
+
+	if err := db.CommitTransaction(tx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	if after := db.walInstance.CurrentLSN(); after != before {
+		t.Errorf("expected no WAL records, LSN moved from %d to %d", before, after)
+	}
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/trigger"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+func mustExec(t *testing.T, db *Database, queries ...string) {
+	t.Helper()
+	for _, q := range queries {
+		if _, err := db.ExecuteQuery(q); err != nil {
+			t.Fatalf("%s failed: %v", q, err)
+		}
+	}
+}
+
+func TestTriggers_AfterInsertAudits(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	err := db.RegisterTriggerFunc("audit", func(ctx *trigger.Context) error {
+		id, err := ctx.NewValue("id")
+		if err != nil {
+			return err
+		}
+		return ctx.Exec(fmt.Sprintf("INSERT INTO audit (order_id, action) VALUES (%s, '%s')", id, ctx.Event))
+	})
+	if err != nil {
+		t.Fatalf("RegisterTriggerFunc failed: %v", err)
+	}
+
+	mustExec(t, db,
+		"CREATE TABLE orders (id INT, item STRING)",
+		"CREATE TABLE audit (order_id INT, action STRING)",
+		"CREATE TRIGGER audit_orders AFTER INSERT ON orders EXECUTE FUNCTION audit",
+		"INSERT INTO orders (id, item) VALUES (1, 'book')",
+		"INSERT INTO orders (id, item) VALUES (2, 'pen')",
+	)
+
+	result, err := db.ExecuteQuery("SELECT order_id, action FROM audit")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0][1] != "INSERT" {
+		t.Errorf("expected 2 INSERT audit rows, got %v", result.Rows)
+	}
+
+	mustExec(t, db,
+		"DROP TRIGGER audit_orders",
+		"INSERT INTO orders (id, item) VALUES (3, 'ink')",
+	)
+	if n := countRows(t, db, "audit"); n != 2 {
+		t.Errorf("expected no audit row after DROP TRIGGER, got %d rows", n)
+	}
+}
+
+func TestTriggers_BeforeModifiesAndSkips(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.RegisterTriggerFunc("normalize", func(ctx *trigger.Context) error {
+		name, err := ctx.NewValue("name")
+		if err != nil {
+			return err
+		}
+		return ctx.SetNew("name", types.NewStringField(strings.ToUpper(name.String()), types.StringMaxSize))
+	})
+	db.RegisterTriggerFunc("protect_admin", func(ctx *trigger.Context) error {
+		name, err := ctx.OldValue("name")
+		if err != nil {
+			return err
+		}
+		if name.String() == "ADMIN" {
+			return trigger.ErrSkipRow
+		}
+		return nil
+	})
+
+	mustExec(t, db,
+		"CREATE TABLE users (id INT, name STRING)",
+		"CREATE TRIGGER normalize_insert BEFORE INSERT ON users EXECUTE FUNCTION normalize",
+		"CREATE TRIGGER normalize_update BEFORE UPDATE ON users EXECUTE FUNCTION normalize",
+		"CREATE TRIGGER protect BEFORE DELETE ON users EXECUTE FUNCTION protect_admin",
+		"INSERT INTO users (id, name) VALUES (1, 'admin')",
+		"INSERT INTO users (id, name) VALUES (2, 'bob')",
+		"UPDATE users SET name = 'robert' WHERE id = 2",
+	)
+
+	result, err := db.ExecuteQuery("SELECT name FROM users WHERE id = 2")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0] != "ROBERT" {
+		t.Errorf("expected BEFORE UPDATE to normalize the name, got %v", result.Rows)
+	}
+
+	result, err = db.ExecuteQuery("DELETE FROM users")
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	if result.RowsAffected != 1 {
+		t.Errorf("expected the skipped row not to be counted, got %d", result.RowsAffected)
+	}
+	if n := countRows(t, db, "users"); n != 1 {
+		t.Errorf("expected the admin row to survive, got %d rows", n)
+	}
+}
+
+func TestTriggers_FailureRollsBackStatement(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var seen []string
+	db.RegisterTriggerFunc("check_balance", func(ctx *trigger.Context) error {
+		oldBalance, err := ctx.OldValue("balance")
+		if err != nil {
+			return err
+		}
+		newBalance, err := ctx.NewValue("balance")
+		if err != nil {
+			return err
+		}
+		seen = append(seen, oldBalance.String()+"->"+newBalance.String())
+		if newBalance.(*types.IntField).Value > 1000 {
+			return errors.New("balance exceeds the account limit")
+		}
+		return nil
+	})
+	db.RegisterTriggerFunc("log_change", func(ctx *trigger.Context) error {
+		return ctx.Exec("INSERT INTO changes (note) VALUES ('changed')")
+	})
+
+	mustExec(t, db,
+		"CREATE TABLE accounts (id INT, balance INT)",
+		"CREATE TABLE changes (note STRING)",
+		"INSERT INTO accounts (id, balance) VALUES (1, 100)",
+		"INSERT INTO changes (note) VALUES ('seed')",
+		"CREATE TRIGGER a_log AFTER UPDATE ON accounts EXECUTE FUNCTION log_change",
+		"CREATE TRIGGER b_check BEFORE UPDATE ON accounts EXECUTE FUNCTION check_balance",
+		"UPDATE accounts SET balance = 50 WHERE id = 1",
+	)
+
+	_, err := db.ExecuteQuery("UPDATE accounts SET balance = 5000 WHERE id = 1")
+	requireErrorCode(t, err, "TRIGGER_FAILED")
+	if !strings.Contains(err.Error(), "balance exceeds the account limit") {
+		t.Errorf("expected the trigger's message, got %v", err)
+	}
+
+	if strings.Join(seen, ",") != "100->50,50->5000" {
+		t.Errorf("unexpected OLD/NEW values: %v", seen)
+	}
+
+	result, err := db.ExecuteQuery("SELECT balance FROM accounts")
+	if err != nil {
+		t.Fatalf("SELECT failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0] != "50" {
+		t.Errorf("expected balance to stay 50, got %v", result.Rows)
+	}
+	if n := countRows(t, db, "changes"); n != 2 {
+		t.Errorf("expected only the committed update to be logged, got %d rows", n)
+	}
+}
+
+func TestTriggers_RecursionIsBounded(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.RegisterTriggerFunc("echo", func(ctx *trigger.Context) error {
+		return ctx.Exec("INSERT INTO events (id) VALUES (0)")
+	})
+	mustExec(t, db,
+		"CREATE TABLE events (id INT)",
+		"INSERT INTO events (id) VALUES (1)",
+		"CREATE TRIGGER echo_events AFTER INSERT ON events EXECUTE FUNCTION echo",
+	)
+
+	_, err := db.ExecuteQuery("INSERT INTO events (id) VALUES (2)")
+	requireErrorCode(t, err, "TRIGGER_DEPTH_EXCEEDED")
+	if n := countRows(t, db, "events"); n != 1 {
+		t.Errorf("expected the recursive inserts to be rolled back, got %d rows", n)
+	}
+}
+
+func TestTriggers_DDLValidation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.RegisterTriggerFunc("noop", func(*trigger.Context) error { return nil })
+	mustExec(t, db, "CREATE TABLE items (id INT)")
+
+	failing := []string{
+		"CREATE TRIGGER t1 AFTER INSERT ON missing EXECUTE FUNCTION noop",
+		"CREATE TRIGGER t1 AFTER INSERT ON items EXECUTE FUNCTION unknown",
+		"DROP TRIGGER t1",
+	}
+	for _, q := range failing {
+		if _, err := db.ExecuteQuery(q); err == nil {
+			t.Errorf("expected %q to fail", q)
+		}
+	}
+
+	mustExec(t, db,
+		"CREATE TRIGGER t1 AFTER INSERT ON items EXECUTE FUNCTION noop",
+		"CREATE TRIGGER IF NOT EXISTS t1 AFTER DELETE ON items EXECUTE FUNCTION noop",
+		"DROP TRIGGER IF EXISTS t2",
+	)
+	if _, err := db.ExecuteQuery("CREATE TRIGGER t1 AFTER DELETE ON items EXECUTE FUNCTION noop"); err == nil {
+		t.Error("expected duplicate trigger name to fail")
+	}
+
+	// Dropping the table drops its triggers, so the name can be reused.
+	mustExec(t, db,
+		"DROP TABLE items",
+		"CREATE TABLE items (id INT)",
+		"CREATE TRIGGER t1 AFTER INSERT ON items EXECUTE FUNCTION noop",
+	)
+
+	// A trigger whose function is gone fails the statement that fires it.
+	db.UnregisterTriggerFunc("noop")
+	_, err := db.ExecuteQuery("INSERT INTO items (id) VALUES (1)")
+	requireErrorCode(t, err, "TRIGGER_FUNCTION_NOT_FOUND")
+}
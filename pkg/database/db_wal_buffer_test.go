@@ -0,0 +1,35 @@
+package database
+
+import (
+	"path/filepath"
+	"storemy/pkg/log/wal"
+	"testing"
+)
+
+func TestWALBuffer_Options(t *testing.T) {
+	dir := t.TempDir()
+	opts := testOptions()
+	opts.WALBuffer = wal.BufferPolicy{MaxSize: 1 << 20, Backpressure: wal.BackpressureReject}
+	db, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), opts)
+	if err != nil {
+		t.Fatalf("NewDatabaseWithOptions failed: %v", err)
+	}
+	defer db.Close()
+
+	if got := db.walInstance.BufferPolicy(); got != opts.WALBuffer {
+		t.Errorf("WAL buffer policy = %+v, want %+v", got, opts.WALBuffer)
+	}
+	mustExec(t, db, "CREATE TABLE users (id INT)", "INSERT INTO users VALUES (1)")
+	if n := countRows(t, db, "users"); n != 1 {
+		t.Errorf("users has %d rows, want 1", n)
+	}
+}
+
+func TestWALBuffer_InvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+	opts := testOptions()
+	opts.WALBuffer = wal.BufferPolicy{MaxSize: 1024} // Below the default wal_buffer_size
+	if _, err := NewDatabaseWithOptions("testdb", filepath.Join(dir, "data"), filepath.Join(dir, "logs"), opts); err == nil {
+		t.Fatal("expected a WAL buffer smaller than its initial size to be rejected")
+	}
+}
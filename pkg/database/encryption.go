@@ -0,0 +1,135 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/config"
+	"storemy/pkg/encryption"
+	dberror "storemy/pkg/error"
+)
+
+// ErrCodeEncryption indicates a database could not be opened or rekeyed
+// because of its encryption keys: a key is missing or wrong, or the
+// database was created with a different encryption setting.
+const ErrCodeEncryption = "ENCRYPTION_KEY_INVALID"
+
+// IsEncryptionError reports whether err was caused by the encryption keys
+// a database was opened with.
+func IsEncryptionError(err error) bool {
+	var dbErr *dberror.DBError
+	return errors.As(err, &dbErr) && dbErr.Code == ErrCodeEncryption
+}
+
+// newEncryptionError creates the DBError returned for a problem with the
+// encryption keys of a database.
+func newEncryptionError(err error, operation, detail, hint string) *dberror.DBError {
+	dbErr := dberror.Wrap(err, ErrCodeEncryption, operation, "Encryption")
+	dbErr.Category = dberror.ErrCategoryUser
+	dbErr.Detail = detail
+	dbErr.Hint = hint
+	return dbErr
+}
+
+// openCipher returns the cipher a database is encrypted with, or nil if it
+// is not encrypted. Encryption is enabled when a database is created with
+// Options.KeyProvider; from then on the database can only be opened with a
+// provider holding its keys, which is checked against the superblock before
+// any page or log record is read.
+func openCipher(settings *config.Store, opts Options) (*encryption.Cipher, error) {
+	enc := settings.Settings().Encryption
+	switch {
+	case !enc.Enabled && opts.KeyProvider == nil:
+		return nil, nil
+
+	case !enc.Enabled:
+		if !settings.Created() {
+			return nil, newEncryptionError(
+				errors.New("database was created without encryption"), "NewDatabase",
+				"An existing unencrypted database cannot be opened with a key provider",
+				"Open the database without Options.KeyProvider, or copy its data into a new encrypted database")
+		}
+		c := encryption.NewCipher(opts.KeyProvider)
+		if err := recordEncryptionKey(settings, c); err != nil {
+			return nil, err
+		}
+		return c, nil
+
+	case opts.KeyProvider == nil:
+		return nil, newEncryptionError(
+			errors.New("database is encrypted"), "NewDatabase",
+			"The database is encrypted at rest and no key provider was given",
+			"Open the database with Options.KeyProvider set")
+	}
+
+	c := encryption.NewCipher(opts.KeyProvider)
+	if err := c.VerifyKeyCheck(enc.KeyCheck); err != nil {
+		return nil, newEncryptionError(err, "NewDatabase",
+			fmt.Sprintf("The key provider does not hold key %d the database was last encrypted with", enc.KeyID),
+			"Open the database with the key provider it was created or last rotated with")
+	}
+
+	// A provider that was rotated while the database was closed already
+	// encrypts with its new key; record it so the key is checked next time
+	if !opts.ReadOnly && c.CurrentKeyID() != enc.KeyID {
+		if err := recordEncryptionKey(settings, c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// recordEncryptionKey stores the current key of c and its key check in the
+// superblock.
+func recordEncryptionKey(settings *config.Store, c *encryption.Cipher) error {
+	id := c.CurrentKeyID()
+	check, err := c.KeyCheck(id)
+	if err != nil {
+		return newEncryptionError(err, "NewDatabase",
+			fmt.Sprintf("The key provider does not return its current key %d", id),
+			"Check the configuration of the key provider")
+	}
+
+	if err := settings.SetEncryption(config.Encryption{Enabled: true, KeyID: id, KeyCheck: check}); err != nil {
+		dbErr := dberror.Wrap(err, "SUPERBLOCK_WRITE_FAILED", "NewDatabase", "Config")
+		dbErr.Detail = "Failed to record the encryption key in the superblock"
+		return dbErr
+	}
+	return nil
+}
+
+// IsEncrypted reports whether the database is encrypted at rest.
+func (db *Database) IsEncrypted() bool {
+	return db.cipher != nil
+}
+
+// RotateEncryptionKey finishes a key rotation: once the key provider has
+// made a new key current, it records the key in the superblock and
+// re-encrypts with it every page still encrypted with an older key, and
+// returns how many pages it re-encrypted. New pages and log records use the
+// new key as soon as the provider returns it; the old keys stay needed for
+// the log records written before, until a checkpoint truncates the WAL.
+func (db *Database) RotateEncryptionKey() (int, error) {
+	if db.readOnly {
+		return 0, newReadOnlyError("RotateEncryptionKey")
+	}
+	if db.cipher == nil {
+		return 0, newEncryptionError(
+			errors.New("database is not encrypted"), "RotateEncryptionKey",
+			"Only a database created with Options.KeyProvider can rotate its key",
+			"")
+	}
+
+	if err := recordEncryptionKey(db.settings, db.cipher); err != nil {
+		return 0, err
+	}
+
+	rekeyed, err := db.pageStore.RekeyFiles()
+	if err != nil {
+		dbErr := dberror.Wrap(err, "REKEY_FAILED", "RotateEncryptionKey", "PageStore")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = fmt.Sprintf("Failed after re-encrypting %d pages", rekeyed)
+		dbErr.Hint = "Keep the old keys available and retry the rotation"
+		return rekeyed, dbErr
+	}
+	return rekeyed, nil
+}
@@ -0,0 +1,390 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"storemy/pkg/config"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/parser/parser"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/tracing"
+	"strings"
+	"sync"
+)
+
+const (
+	// DatabaseWALFile is the name of the write-ahead log inside the directory
+	// of a database opened by an Engine.
+	DatabaseWALFile = "wal.log"
+
+	// ErrCodeDatabaseNotFound indicates USE or a lookup named a database the engine does not have
+	ErrCodeDatabaseNotFound = "DATABASE_NOT_FOUND"
+
+	// ErrCodeDatabaseExists indicates CREATE DATABASE named a database that already exists
+	ErrCodeDatabaseExists = "DATABASE_EXISTS"
+)
+
+// databaseNamePattern matches the names accepted for databases: the same
+// identifiers the SQL lexer accepts, so a name can never escape the engine's
+// data directory.
+var databaseNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Engine hosts several databases in one process. Each database lives in its
+// own subdirectory of the engine's data directory with its own catalog,
+// buffer pool and write-ahead log (DatabaseWALFile), so a transaction only
+// ever touches one database.
+//
+// Databases are opened on first use and stay open until the engine is
+// closed. Names are case-insensitive: the SQL lexer upper-cases identifiers,
+// so USE mydb finds a database created as "mydb".
+type Engine struct {
+	dataDir string
+	opts    Options
+
+	mutex     sync.Mutex // Guards databases and closed
+	databases map[string]*Database
+	closed    bool
+}
+
+// NewEngine returns an engine serving the databases under dataDir, creating
+// the directory unless opts.ReadOnly is set. Every database the engine opens
+// uses opts.
+func NewEngine(dataDir string, opts Options) (*Engine, error) {
+	if !opts.ReadOnly {
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			dbErr := dberror.Wrap(err, "DIR_CREATE_FAILED", "NewEngine", "Engine")
+			dbErr.Detail = fmt.Sprintf("Failed to create directory: %s", dataDir)
+			dbErr.Hint = "Check that the parent directory exists and you have write permissions"
+			return nil, dbErr
+		}
+	}
+
+	return &Engine{
+		dataDir:   dataDir,
+		opts:      opts,
+		databases: make(map[string]*Database),
+	}, nil
+}
+
+// Attach adds a database opened by the caller to the engine, which closes it
+// along with the others. This is how a database whose WAL lives outside its
+// directory joins an engine.
+func (e *Engine) Attach(db *Database) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return newClosedError("Attach")
+	}
+	if _, ok := e.lookupLocked(db.name); ok {
+		return newDatabaseExistsError(db.name)
+	}
+	e.databases[db.name] = db
+	return nil
+}
+
+// CreateDatabase creates and opens a new database. If the database already
+// exists, it is returned when ifNotExists is set and is an error otherwise.
+func (e *Engine) CreateDatabase(name string, ifNotExists bool) (*Database, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return nil, newClosedError("CREATE DATABASE")
+	}
+	if e.opts.ReadOnly {
+		return nil, newReadOnlyError("CREATE DATABASE")
+	}
+	if err := validateDatabaseName(name); err != nil {
+		return nil, err
+	}
+
+	db, err := e.databaseLocked(name)
+	if err == nil {
+		if ifNotExists {
+			return db, nil
+		}
+		return nil, newDatabaseExistsError(name)
+	}
+	if !isDatabaseNotFoundError(err) {
+		return nil, err
+	}
+
+	return e.openLocked(name)
+}
+
+// Database returns the named database, opening it if needed.
+func (e *Engine) Database(name string) (*Database, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return nil, newClosedError("USE")
+	}
+	return e.databaseLocked(name)
+}
+
+// ListDatabases returns the names of the engine's databases, open or not, sorted.
+func (e *Engine) ListDatabases() ([]string, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	names := make([]string, 0, len(e.databases))
+	for name := range e.databases {
+		names = append(names, name)
+	}
+
+	onDisk, err := e.databasesOnDisk()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range onDisk {
+		if _, ok := e.lookupLocked(name); !ok {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// Close closes every open database. It returns the first error, but always
+// tries to close all of them.
+func (e *Engine) Close() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	var firstErr error
+	for _, db := range e.databases {
+		if err := db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// NewSession returns a session whose current database is the named one.
+func (e *Engine) NewSession(name string) (*Session, error) {
+	db, err := e.Database(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{engine: e, current: db}, nil
+}
+
+// databaseLocked returns the named database, opening it if its directory
+// exists. The caller must hold e.mutex.
+func (e *Engine) databaseLocked(name string) (*Database, error) {
+	if db, ok := e.lookupLocked(name); ok {
+		return db, nil
+	}
+
+	onDisk, err := e.databasesOnDisk()
+	if err != nil {
+		return nil, err
+	}
+	for _, dirName := range onDisk {
+		if strings.EqualFold(dirName, name) {
+			return e.openLocked(dirName)
+		}
+	}
+	return nil, newDatabaseNotFoundError(name)
+}
+
+// lookupLocked returns the open database with the given name, ignoring case.
+// The caller must hold e.mutex.
+func (e *Engine) lookupLocked(name string) (*Database, bool) {
+	for dbName, db := range e.databases {
+		if strings.EqualFold(dbName, name) {
+			return db, true
+		}
+	}
+	return nil, false
+}
+
+// openLocked opens (or creates) the database in the named subdirectory and
+// registers it. The caller must hold e.mutex.
+func (e *Engine) openLocked(name string) (*Database, error) {
+	walPath := filepath.Join(e.dataDir, name, DatabaseWALFile)
+	if !e.opts.ReadOnly {
+		if err := os.MkdirAll(filepath.Dir(walPath), 0755); err != nil {
+			dbErr := dberror.Wrap(err, "DIR_CREATE_FAILED", "CreateDatabase", "Engine")
+			dbErr.Detail = fmt.Sprintf("Failed to create directory: %s", filepath.Dir(walPath))
+			return nil, dbErr
+		}
+	}
+
+	db, err := NewDatabaseWithOptions(name, e.dataDir, walPath, e.opts)
+	if err != nil {
+		return nil, err
+	}
+	e.databases[name] = db
+	e.opts.componentLogger("engine").Info("database opened", "database", name)
+	return db, nil
+}
+
+// databasesOnDisk returns the names of the subdirectories of the data
+// directory that hold a database, recognized by its superblock.
+func (e *Engine) databasesOnDisk() ([]string, error) {
+	entries, err := os.ReadDir(e.dataDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		dbErr := dberror.Wrap(err, "DIR_READ_FAILED", "ListDatabases", "Engine")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = fmt.Sprintf("Failed to read data directory: %s", e.dataDir)
+		return nil, dbErr
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(e.dataDir, entry.Name(), config.SuperblockFile)); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Session runs statements against an engine on behalf of one client. It
+// tracks the client's current database, which USE changes; every other
+// statement runs against the current database.
+//
+// A session is meant to be used by one goroutine at a time.
+type Session struct {
+	engine  *Engine
+	current *Database
+}
+
+// Database returns the session's current database.
+func (s *Session) Database() *Database {
+	return s.current
+}
+
+// ExecuteQuery executes query against the current database, or against the
+// engine for CREATE DATABASE and USE.
+func (s *Session) ExecuteQuery(query string) (QueryResult, error) {
+	return s.ExecuteQueryWithArgs(query)
+}
+
+// ExecuteQueryWithArgs executes a parameterized query against the current
+// database. See Database.ExecuteQueryWithArgs.
+func (s *Session) ExecuteQueryWithArgs(query string, args ...any) (QueryResult, error) {
+	stmt, err := parser.ParseStatement(query)
+	if err != nil || !isEngineStatement(stmt) {
+		// Parse errors are reported (and counted) by the database
+		return s.current.ExecuteQueryWithArgs(query, args...)
+	}
+
+	if len(args) > 0 {
+		dbErr := dberror.New(dberror.ErrCategoryUser, "BIND_ERROR", fmt.Sprintf("got %d arguments, but the statement has 0 parameters", len(args)))
+		dbErr.Detail = "Failed to bind query parameters"
+		return QueryResult{}, dbErr
+	}
+
+	switch stmt := stmt.(type) {
+	case *statements.CreateDatabaseStatement:
+		if _, err := s.engine.CreateDatabase(stmt.DatabaseName, stmt.IfNotExists); err != nil {
+			return QueryResult{}, err
+		}
+		return QueryResult{Success: true, Message: fmt.Sprintf("Database %s created", stmt.DatabaseName)}, nil
+	case *statements.UseStatement:
+		db, err := s.engine.Database(stmt.DatabaseName)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		s.current = db
+		return QueryResult{Success: true, Message: fmt.Sprintf("Using database %s", db.name)}, nil
+	}
+	return QueryResult{}, fmt.Errorf("unsupported engine statement: %T", stmt)
+}
+
+// ExecuteQueryStepByStep executes query against the current database,
+// reporting its operator steps to observer. See
+// Database.ExecuteQueryStepByStep; CREATE DATABASE and USE run no operators
+// and are executed as by ExecuteQuery.
+func (s *Session) ExecuteQueryStepByStep(query string, observer tracing.StepObserver) (QueryResult, error) {
+	stmt, err := parser.ParseStatement(query)
+	if err == nil && isEngineStatement(stmt) {
+		return s.ExecuteQuery(query)
+	}
+	return s.current.ExecuteQueryStepByStep(query, observer)
+}
+
+// ExecuteScript runs a script against the current database. See
+// Database.ExecuteScript; CREATE DATABASE and USE are not supported in scripts.
+func (s *Session) ExecuteScript(script string, policy ScriptErrorPolicy) (*ScriptResult, error) {
+	return s.current.ExecuteScript(script, policy)
+}
+
+// GetStatistics returns the statistics of the current database.
+func (s *Session) GetStatistics() DatabaseInfo {
+	return s.current.GetStatistics()
+}
+
+// isEngineStatement reports whether stmt is run by the engine rather than by
+// a single database.
+func isEngineStatement(stmt statements.Statement) bool {
+	switch stmt.(type) {
+	case *statements.CreateDatabaseStatement, *statements.UseStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+// newEngineStatementError reports CREATE DATABASE or USE sent directly to a
+// database instead of through an engine session.
+func newEngineStatementError(stmt statements.Statement) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryUser,
+		"ENGINE_STATEMENT",
+		fmt.Sprintf("%s must be run through an engine session", stmt.GetType()),
+	)
+	err.Detail = "A database cannot create or switch to other databases"
+	err.Hint = "Open the database with NewEngine and run the statement through a Session"
+	err.Operation = "ExecuteQuery"
+	err.Component = "Database"
+	return err
+}
+
+func validateDatabaseName(name string) error {
+	if databaseNamePattern.MatchString(name) {
+		return nil
+	}
+	err := dberror.New(dberror.ErrCategoryUser, "INVALID_DATABASE_NAME", fmt.Sprintf("invalid database name: %q", name))
+	err.Hint = "Database names start with a letter or underscore and contain only letters, digits and underscores"
+	err.Component = "Engine"
+	return err
+}
+
+func newDatabaseNotFoundError(name string) *dberror.DBError {
+	err := dberror.New(dberror.ErrCategoryUser, ErrCodeDatabaseNotFound, fmt.Sprintf("database %s does not exist", name))
+	err.Hint = "Create it with CREATE DATABASE"
+	err.Component = "Engine"
+	return err
+}
+
+func newDatabaseExistsError(name string) *dberror.DBError {
+	err := dberror.New(dberror.ErrCategoryUser, ErrCodeDatabaseExists, fmt.Sprintf("database %s already exists", name))
+	err.Hint = "Use CREATE DATABASE IF NOT EXISTS to ignore existing databases"
+	err.Component = "Engine"
+	return err
+}
+
+func isDatabaseNotFoundError(err error) bool {
+	var dbErr *dberror.DBError
+	return errors.As(err, &dbErr) && dbErr.Code == ErrCodeDatabaseNotFound
+}
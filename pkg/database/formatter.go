@@ -8,17 +8,19 @@ import (
 
 func formatResult(rawResult any, stmt statements.Statement) (QueryResult, error) {
 	switch stmt.GetType() {
-	case statements.Select:
+	case statements.Select, statements.ShowPersistent, statements.ChecksumTable:
 		if queryResult, ok := rawResult.(*planner.SelectQueryResult); ok {
 			return formatSelect(queryResult), nil
 		}
 
-	case statements.Insert, statements.Update, statements.Delete:
+	case statements.Insert, statements.Update, statements.Delete, statements.Copy:
 		if dmlResult, ok := rawResult.(*planner.DMLResult); ok {
 			return formatDML(dmlResult, stmt.GetType()), nil
 		}
 
-	case statements.CreateTable, statements.DropTable:
+	case statements.CreateTable, statements.CreateForeignTable, statements.DropTable, statements.SetPersistent,
+		statements.CreateTrigger, statements.DropTrigger, statements.SetTransactionSnapshot, statements.AlterTableAudit, statements.Set,
+		statements.AlterTableCache:
 		if ddlResult, ok := rawResult.(*planner.DDLResult); ok {
 			return formatDDL(ddlResult), nil
 		}
@@ -87,6 +89,8 @@ func formatDML(result *planner.DMLResult, stmtType statements.StatementType) Que
 		action = "updated"
 	case statements.Delete:
 		action = "deleted"
+	case statements.Copy:
+		action = "copied"
 	}
 
 	return QueryResult{
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"storemy/pkg/concurrency/lock"
+	"storemy/pkg/primitives"
+)
+
+// StartLockTrace starts recording which transactions acquire, wait for and
+// release which page locks, in the order it happens, keeping at most capacity
+// events (lock.DefaultTraceCapacity if not positive). A trace already running
+// is discarded. The trace is meant for teaching and debugging how concurrent
+// transactions interact; render it with LockTimeline.
+func (db *Database) StartLockTrace(capacity int) *lock.LockTrace {
+	return db.pageStore.StartLockTrace(capacity)
+}
+
+// StopLockTrace stops recording lock events and returns the trace recorded
+// since StartLockTrace, or nil if none was running.
+func (db *Database) StopLockTrace() *lock.LockTrace {
+	return db.pageStore.StopLockTrace()
+}
+
+// LockTrace returns the lock trace being recorded, or nil if lock tracing is
+// off.
+func (db *Database) LockTrace() *lock.LockTrace {
+	return db.pageStore.LockTrace()
+}
+
+// LockTimeline renders trace as a timeline with one column per transaction,
+// naming the tables of locked pages. See lock.LockTrace.Timeline.
+func (db *Database) LockTimeline(trace *lock.LockTrace) string {
+	// Cached names only: reading the catalog would take locks
+	return trace.Timeline(func(id primitives.FileID) string {
+		name, _ := db.catalogMgr.CachedTableName(id)
+		return name
+	})
+}
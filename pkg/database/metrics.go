@@ -0,0 +1,35 @@
+package database
+
+import "storemy/pkg/metrics"
+
+var (
+	queriesTotal = metrics.NewCounter(
+		"storemy_queries_total",
+		"Queries executed successfully",
+	)
+	queryErrors = metrics.NewCounter(
+		"storemy_query_errors_total",
+		"Queries that failed during parsing, planning, execution or commit",
+	)
+	queryPanics = metrics.NewCounter(
+		"storemy_query_panics_total",
+		"Statements that panicked and failed with a QUERY_PANIC error instead of ending the process",
+	)
+	queryDuration = metrics.NewHistogram(
+		"storemy_query_duration_seconds",
+		"End-to-end latency of successful queries, including commit",
+		metrics.DefaultLatencyBuckets,
+	)
+	rowsReturned = metrics.NewCounter(
+		"storemy_executor_rows_returned_total",
+		"Rows returned to clients by queries",
+	)
+	rowsAffected = metrics.NewCounter(
+		"storemy_executor_rows_affected_total",
+		"Rows inserted, updated or deleted by DML statements",
+	)
+	statementRetries = metrics.NewCounter(
+		"storemy_statement_retries_total",
+		"Statements run again after their implicit transaction hit a deadlock, serialization failure or lock timeout",
+	)
+)
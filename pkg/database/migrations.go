@@ -0,0 +1,349 @@
+package database
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/parser/parser"
+	"time"
+)
+
+// Migration is one versioned step of schema evolution. Its scripts may hold
+// several DDL and DML statements separated by semicolons.
+type Migration struct {
+	Version uint64 // Positive and unique; migrations are applied in increasing order
+	Name    string
+	Up      string // Script applying the change
+	Down    string // Script undoing Up; empty if the migration cannot be rolled back
+}
+
+// Checksum returns the hex SHA-256 digest of the Up script. It is recorded
+// when the migration is applied so later edits to an applied migration are
+// detected.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationStatus describes one migration as seen by MigrationStatus.
+type MigrationStatus struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time // Zero unless Applied
+	Modified  bool      // Applied, but the Up script has changed since
+	Unknown   bool      // Recorded in the database but not in the given migrations
+}
+
+// ApplyMigrations applies every migration that is not yet recorded in the
+// CATALOG_SCHEMA_MIGRATIONS system table, in version order, and returns the
+// ones it applied.
+//
+// Each migration runs in its own transaction together with the row recording
+// it, so a failed migration leaves no record and no page changes; files
+// created by its DDL stay on disk. Migrations applied before the failure stay
+// committed.
+//
+// Nothing runs if the list is invalid, if an applied migration's Up script has
+// changed, or if a pending migration is older than the newest applied one.
+func (db *Database) ApplyMigrations(migrations []Migration) ([]Migration, error) {
+	const op = "ApplyMigrations"
+	if db.readOnly {
+		return nil, newReadOnlyError(op)
+	}
+	log := db.logger
+
+	db.migrationMu.Lock()
+	defer db.migrationMu.Unlock()
+
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	records, err := db.appliedMigrations(op)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint64]*catalogmanager.MigrationRecord, len(records))
+	var latest uint64
+	for _, rec := range records {
+		applied[rec.Version] = rec
+		latest = max(latest, rec.Version)
+	}
+
+	var pending []Migration
+	for _, m := range sorted {
+		rec, ok := applied[m.Version]
+		switch {
+		case ok && rec.Checksum != m.Checksum():
+			err := newMigrationError(dberror.ErrCategoryUser, "MIGRATION_CHECKSUM_MISMATCH", op,
+				fmt.Sprintf("migration %d (%s) has changed since it was applied", m.Version, m.Name))
+			err.Hint = "Add a new migration instead of editing one that has been applied"
+			return nil, err
+		case ok:
+		case m.Version < latest:
+			err := newMigrationError(dberror.ErrCategoryUser, "MIGRATION_OUT_OF_ORDER", op,
+				fmt.Sprintf("migration %d (%s) is older than applied migration %d", m.Version, m.Name, latest))
+			err.Hint = "Give the migration a version above every applied one"
+			return nil, err
+		default:
+			pending = append(pending, m)
+		}
+	}
+
+	steps := make([][]parser.ScriptStatement, len(pending))
+	for i, m := range pending {
+		if steps[i], err = splitMigration(op, m, m.Up); err != nil {
+			return nil, err
+		}
+	}
+
+	var done []Migration
+	for i, m := range pending {
+		start := time.Now()
+		err := db.runMigration(op, m, steps[i], func(tx *transaction.TransactionContext) error {
+			return db.catalogMgr.RecordMigration(tx, catalogmanager.MigrationRecord{
+				Version:   m.Version,
+				Name:      m.Name,
+				Checksum:  m.Checksum(),
+				AppliedAt: time.Now(),
+			})
+		})
+		if err != nil {
+			log.Error("migration failed", "version", m.Version, "name", m.Name, "error", err)
+			return done, err
+		}
+		log.Info("migration applied", "version", m.Version, "name", m.Name, "duration_ms", time.Since(start).Milliseconds())
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// RollbackMigrations undoes the newest steps applied migrations by running
+// their Down scripts, newest first, and returns the ones it rolled back. The
+// Down scripts are taken from migrations.
+//
+// Each rollback runs in its own transaction together with the removal of the
+// migration's record. Nothing runs if one of the migrations to roll back is
+// missing from the list or has no Down script.
+func (db *Database) RollbackMigrations(migrations []Migration, steps int) ([]Migration, error) {
+	const op = "RollbackMigrations"
+	if db.readOnly {
+		return nil, newReadOnlyError(op)
+	}
+	log := db.logger
+
+	db.migrationMu.Lock()
+	defer db.migrationMu.Unlock()
+
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	records, err := db.appliedMigrations(op)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]Migration, len(sorted))
+	for _, m := range sorted {
+		byVersion[m.Version] = m
+	}
+
+	var targets []Migration
+	var scripts [][]parser.ScriptStatement
+	for i := len(records) - 1; i >= 0 && len(targets) < steps; i-- {
+		rec := records[i]
+		m, ok := byVersion[rec.Version]
+		if !ok || m.Down == "" {
+			err := newMigrationError(dberror.ErrCategoryUser, "MIGRATION_IRREVERSIBLE", op,
+				fmt.Sprintf("migration %d (%s) has no Down script", rec.Version, rec.Name))
+			err.Hint = "Pass the migration with a Down script, or roll back fewer steps"
+			return nil, err
+		}
+		stmts, err := splitMigration(op, m, m.Down)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, m)
+		scripts = append(scripts, stmts)
+	}
+
+	var done []Migration
+	for i, m := range targets {
+		err := db.runMigration(op, m, scripts[i], func(tx *transaction.TransactionContext) error {
+			return db.catalogMgr.DeleteMigration(tx, m.Version)
+		})
+		if err != nil {
+			log.Error("migration rollback failed", "version", m.Version, "name", m.Name, "error", err)
+			return done, err
+		}
+		log.Info("migration rolled back", "version", m.Version, "name", m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// MigrationStatus compares migrations with the versions recorded in the
+// database and returns one entry per version, in version order. Versions
+// recorded in the database but missing from migrations are marked Unknown.
+func (db *Database) MigrationStatus(migrations []Migration) ([]MigrationStatus, error) {
+	const op = "MigrationStatus"
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	records, err := db.appliedMigrations(op)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint64]*catalogmanager.MigrationRecord, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+
+	statuses := make([]MigrationStatus, 0, len(sorted)+len(records))
+	for _, m := range sorted {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if rec, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = rec.AppliedAt
+			s.Modified = rec.Checksum != m.Checksum()
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for _, rec := range records {
+		if _, ok := applied[rec.Version]; ok {
+			statuses = append(statuses, MigrationStatus{
+				Version: rec.Version, Name: rec.Name, Applied: true, AppliedAt: rec.AppliedAt, Unknown: true,
+			})
+		}
+	}
+
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return statuses, nil
+}
+
+// runMigration runs one migration script in a new transaction, calls record
+// to update the migrations table in the same transaction and commits.
+func (db *Database) runMigration(op string, m Migration, stmts []parser.ScriptStatement, record func(*transaction.TransactionContext) error) (err error) {
+	tx, err := db.begin(op)
+	if isClosedError(err) {
+		return err
+	}
+	if err != nil {
+		dbErr := dberror.Wrap(err, "TX_BEGIN_FAILED", op, "TransactionRegistry")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = fmt.Sprintf("Failed to start the transaction for migration %d", m.Version)
+		return dbErr
+	}
+	defer db.cleanupTransaction(tx, &err)
+
+	for i, stmt := range stmts {
+		s := StatementResult{Index: i + 1, Line: stmt.Line, SQL: stmt.SQL}
+		if stmtErr := db.runScriptStatement(tx, &s); stmtErr != nil {
+			category := dberror.ErrCategorySystem
+			if cause, ok := stmtErr.(*dberror.DBError); ok {
+				category = cause.Category
+			}
+			dbErr := newMigrationError(category, "MIGRATION_FAILED", op,
+				fmt.Sprintf("migration %d (%s): statement %d (line %d) failed", m.Version, m.Name, s.Index, s.Line))
+			dbErr.Detail = firstLine(s.SQL)
+			dbErr.Cause = stmtErr
+			return dbErr
+		}
+	}
+
+	if err = record(tx); err != nil {
+		dbErr := dberror.Wrap(err, "MIGRATION_RECORD_FAILED", op, "CatalogManager")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = fmt.Sprintf("Failed to update CATALOG_SCHEMA_MIGRATIONS for migration %d", m.Version)
+		return dbErr
+	}
+
+	if err = db.pageStore.CommitTransaction(tx); err != nil {
+		db.recordError()
+		dbErr := dberror.Wrap(err, "COMMIT_FAILED", op, "PageStore")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = fmt.Sprintf("Failed to commit migration %d", m.Version)
+		return dbErr
+	}
+	return nil
+}
+
+// appliedMigrations reads the migrations table in a short transaction.
+func (db *Database) appliedMigrations(op string) (records []*catalogmanager.MigrationRecord, err error) {
+	tx, err := db.begin(op)
+	if isClosedError(err) {
+		return nil, err
+	}
+	if err != nil {
+		dbErr := dberror.Wrap(err, "TX_BEGIN_FAILED", op, "TransactionRegistry")
+		dbErr.Category = dberror.ErrCategoryTransient
+		return nil, dbErr
+	}
+	defer func() {
+		if err != nil {
+			db.pageStore.AbortTransaction(tx)
+		}
+	}()
+
+	if records, err = db.catalogMgr.GetAppliedMigrations(tx); err != nil {
+		dbErr := dberror.Wrap(err, "CATALOG_READ_FAILED", op, "CatalogManager")
+		dbErr.Category = dberror.ErrCategorySystem
+		return nil, dbErr
+	}
+	if err = db.pageStore.CommitTransaction(tx); err != nil {
+		return nil, dberror.Wrap(err, "COMMIT_FAILED", op, "PageStore")
+	}
+	return records, nil
+}
+
+// sortMigrations returns a copy of migrations in version order, rejecting
+// zero and duplicate versions.
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	for i, m := range sorted {
+		if m.Version == 0 {
+			return nil, newMigrationError(dberror.ErrCategoryUser, "INVALID_MIGRATION", "ValidateMigrations",
+				fmt.Sprintf("migration %q has version 0; versions must be positive", m.Name))
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, newMigrationError(dberror.ErrCategoryUser, "INVALID_MIGRATION", "ValidateMigrations",
+				fmt.Sprintf("version %d is used by both %q and %q", m.Version, sorted[i-1].Name, m.Name))
+		}
+	}
+	return sorted, nil
+}
+
+// splitMigration splits one of m's scripts, reporting errors against m.
+func splitMigration(op string, m Migration, script string) ([]parser.ScriptStatement, error) {
+	stmts, err := parser.SplitScript(script)
+	if err != nil {
+		dbErr := newMigrationError(dberror.ErrCategoryUser, "INVALID_MIGRATION", op,
+			fmt.Sprintf("migration %d (%s) cannot be split into statements", m.Version, m.Name))
+		dbErr.Cause = err
+		return nil, dbErr
+	}
+	return stmts, nil
+}
+
+func newMigrationError(category dberror.ErrorCategory, code, op, message string) *dberror.DBError {
+	err := dberror.New(category, code, message)
+	err.Operation = op
+	err.Component = "Database"
+	return err
+}
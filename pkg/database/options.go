@@ -0,0 +1,432 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"storemy/pkg/concurrency/admission"
+	"storemy/pkg/concurrency/lock"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/config"
+	"storemy/pkg/encryption"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/execution/tempfile"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/logging"
+	"storemy/pkg/memory"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/primitives"
+	"storemy/pkg/resultcache"
+	"storemy/pkg/storage/accounting"
+	"storemy/pkg/tracing"
+	"time"
+)
+
+const (
+	// ErrCodeReadOnly indicates a write was attempted against a database opened read-only
+	ErrCodeReadOnly = "READ_ONLY_VIOLATION"
+
+	// TempDirName is the directory of a database that holds the temporary
+	// files of its queries, unless Options.TempFiles.Dir names another.
+	TempDirName = "tmp"
+)
+
+// Options controls how a database is opened.
+type Options struct {
+	// ReadOnly opens an existing database without modifying it (e.g. a backup or
+	// standby directory). Recovery runs in redo-only mode, DML/DDL statements are
+	// rejected with a READ_ONLY_VIOLATION error, and nothing is written to the WAL.
+	// The WAL is replayed once, when the database is opened, so it is a snapshot of
+	// the directory as of the open. It does not keep applying WAL shipped from a
+	// primary: hot standby reads are not supported yet (see the replication section
+	// of DEVELOPMENT_ROADMAP.md).
+	ReadOnly bool
+
+	// Logger receives log output from the WAL, recovery manager and catalog,
+	// each tagged with its component name. Nil uses the global logging package.
+	Logger logging.ComponentLogger
+
+	// TraceExporter receives the span tree of every query (parse, plan, each
+	// execution operator, commit, lock and WAL waits) once its transaction
+	// completes. Nil disables tracing except for EXPLAIN ANALYZE VERBOSE. The
+	// caller owns the exporter and is responsible for shutting it down.
+	TraceExporter tracing.Exporter
+
+	// ResultCache enables the query result cache when MaxEntries is positive.
+	// The results of SELECT statements run with ExecuteQuery or
+	// ExecuteQueryWithArgs are cached by query text and arguments, and reused
+	// until a table they read is written or any DDL statement runs. Hit
+	// statistics are shown in SYS_RESULT_CACHE.
+	ResultCache resultcache.Config
+
+	// Admission limits how many queries execute at once (see
+	// admission.Config). Queries beyond MaxConcurrent wait for a slot and fail
+	// with an ADMISSION_REJECTED error if the queue is full or QueueTimeout
+	// passes. Results served from the result cache skip admission, and a
+	// script run with ScriptRollbackAll takes a single slot. The zero value
+	// admits every query.
+	Admission admission.Config
+
+	// MemoryBudget limits the memory queries hold while sorting, hashing,
+	// aggregating and materializing results (see membudget.Config). A query
+	// that would exceed its per-query limit, or push all queries past the
+	// global limit, fails with an OUT_OF_MEMORY_BUDGET error instead of
+	// exhausting the process. Databases opened with the same budget, such as
+	// those of an Engine, share its global limit. Nil disables accounting.
+	MemoryBudget *membudget.Budget
+
+	// TempFiles configures the temporary files queries spill to (see
+	// tempfile.Config). An empty Dir uses the tmp directory of the database,
+	// or disables temporary files for a read-only database. Files left there
+	// by a crash are removed when the database is opened, and a query that
+	// would write more than the quotas allow fails with a
+	// TEMP_QUOTA_EXCEEDED error.
+	TempFiles tempfile.Config
+
+	// IsolationLevel is the isolation level new transactions start at. The
+	// zero value, transaction.RepeatableRead, locks the pages a transaction
+	// reads and writes until it ends; transaction.Serializable also locks the
+	// key ranges its index scans cover, so concurrent inserts cannot add
+	// phantom rows to them. Transactions from BeginTransaction can change
+	// their own level with SetIsolationLevel.
+	IsolationLevel transaction.IsolationLevel
+
+	// TableLockTimeout bounds how long a statement waits for a table lock:
+	// DDL such as DROP TABLE waits for the queries using the table to
+	// finish, and queries wait for DDL in progress on a table they use. Zero
+	// uses lock.DefaultTableLockTimeout.
+	TableLockTimeout time.Duration
+
+	// LockGrantPolicy decides when a page lock request compatible with the
+	// locks held on the page still waits for requests queued before it. The
+	// zero value, lock.GrantReaderPreference, grants shared locks while
+	// writers wait, which can starve writers under heavy reads;
+	// lock.GrantFIFO grants in arrival order and lock.GrantWriterPreference
+	// lets waiting writers go first. SYS_LOCK_QUEUES shows the queues.
+	LockGrantPolicy lock.GrantPolicy
+
+	// ShutdownTimeout bounds how long Close waits for active transactions to
+	// finish before aborting them. Zero uses DefaultShutdownTimeout; callers
+	// needing a per-call deadline use Shutdown directly.
+	ShutdownTimeout time.Duration
+
+	// KeyProvider encrypts the database at rest: its pages and WAL records
+	// are sealed with AES-GCM using the provider's current key (see package
+	// encryption). It must be set when the database is created and whenever
+	// it is opened afterwards, since a database cannot switch between
+	// encrypted and unencrypted. After the provider rotates to a new key,
+	// RotateEncryptionKey re-encrypts the existing pages with it.
+	KeyProvider encryption.KeyProvider
+
+	// Replication makes commits synchronous with standbys: a commit returns
+	// once Replication.Quorum of the named standbys acknowledged its WAL
+	// record at Replication.Level, reported by the log shipping process
+	// through AcknowledgeStandby. When a commit times out, commits stop
+	// waiting until the standbys catch up. The zero value disables it;
+	// SYS_REPLICATION shows the standbys and their lag.
+	Replication wal.ReplicationConfig
+
+	// WALDiskReserve keeps free space on the disk of the WAL for the
+	// transactions already running (see wal.DiskReserve). While less is
+	// free, the database only reads: statements that write fail with a
+	// DISK_SPACE_LOW error, while running transactions can still commit or
+	// abort. Writes resume by themselves once a check finds the space back.
+	// The zero value does not monitor the disk.
+	WALDiskReserve wal.DiskReserve
+
+	// WALBuffer lets the WAL buffer grow from the wal_buffer_size setting up
+	// to WALBuffer.MaxSize under bursts of records, and decides what a
+	// statement does once the buffer is full at that size (see
+	// wal.BufferPolicy): wait for the buffer to be flushed, or fail with a
+	// WAL_BACKPRESSURE error the caller can retry after backing off. The
+	// stalls and refusals are counted in the WAL's stats. The zero value
+	// keeps the buffer at its initial size and waits.
+	WALBuffer wal.BufferPolicy
+
+	// Quota limits how much the database may grow (see accounting.Quota):
+	// the total size of its page files and the rows of each table. An
+	// insert, UPDATE moving a row to a new page, or COPY that would exceed
+	// it fails with a QUOTA_EXCEEDED error; deleting rows makes room again
+	// once the delete commits. The zero value sets no limits.
+	Quota accounting.Quota
+
+	// StatementRetry runs a statement executed with ExecuteQuery again, in a
+	// new transaction, when its transaction fails with a retryable error
+	// (see dberror.IsRetryable): a deadlock, serialization failure or lock
+	// timeout. Only the implicit transaction of a single statement is
+	// retried; statements of an explicit transaction fail and leave the
+	// retry to the caller. The zero value does not retry.
+	StatementRetry RetryPolicy
+
+	// DoubleWrite protects the pages of the database from being torn by a
+	// crash in the middle of a write: pages flushed at commit, checkpoint or
+	// shutdown are first written and synced to the double-write buffer
+	// (memory.DoubleWriteFile in the database directory), and only then to
+	// their files. It costs two more syncs per commit that writes pages. A
+	// buffer left behind by a crash is restored whenever the database is
+	// opened, whether DoubleWrite is set or not, unless it is opened
+	// read-only.
+	DoubleWrite bool
+}
+
+// DefaultOptions returns the options used by NewDatabase.
+func DefaultOptions() Options {
+	return Options{
+		ReadOnly: false,
+	}
+}
+
+// shutdownTimeout returns the configured ShutdownTimeout or the default.
+func (o Options) shutdownTimeout() time.Duration {
+	if o.ShutdownTimeout <= 0 {
+		return DefaultShutdownTimeout
+	}
+	return o.ShutdownTimeout
+}
+
+// tempFilesConfig returns the temporary file configuration for a database
+// in dataDir, with ok false if temporary files are disabled.
+func (o Options) tempFilesConfig(dataDir string) (tempfile.Config, bool) {
+	config := o.TempFiles
+	if config.Dir == "" {
+		if o.ReadOnly {
+			return config, false
+		}
+		config.Dir = filepath.Join(dataDir, TempDirName)
+	}
+	return config, true
+}
+
+// componentLogger returns the logger for the named storage component.
+func (o Options) componentLogger(component string) logging.ComponentLogger {
+	if o.Logger == nil {
+		return logging.ForComponent(component)
+	}
+	return o.Logger.With("component", component)
+}
+
+// IsReadOnly reports whether the database was opened in read-only mode.
+func (db *Database) IsReadOnly() bool {
+	return db.readOnly
+}
+
+// Settings returns the persistent settings loaded from the database superblock.
+func (db *Database) Settings() config.Settings {
+	return db.settings.Settings()
+}
+
+// ErrCodeAdmissionRejected indicates a query was turned away by admission
+// control because the database was at its concurrent query limit.
+const ErrCodeAdmissionRejected = "ADMISSION_REJECTED"
+
+// admit takes an execution slot for operation from the admission controller.
+// The caller must call the returned release function when done.
+func (db *Database) admit(operation string) (func(), error) {
+	release, err := db.admission.Acquire()
+	if err == nil {
+		return release, nil
+	}
+
+	db.recordError()
+	config := db.admission.Config()
+	dbErr := dberror.Wrap(err, ErrCodeAdmissionRejected, operation, "Admission")
+	dbErr.Category = dberror.ErrCategoryTransient
+	dbErr.Detail = fmt.Sprintf("The database is running its maximum of %d concurrent queries", config.MaxConcurrent)
+	dbErr.Hint = "Retry the query later, or raise Options.Admission.MaxConcurrent"
+	return nil, dbErr
+}
+
+// IsAdmissionError reports whether err was caused by admission control
+// rejecting a query.
+func IsAdmissionError(err error) bool {
+	var dbErr *dberror.DBError
+	return errors.As(err, &dbErr) && dbErr.Code == ErrCodeAdmissionRejected
+}
+
+// ErrCodeOutOfMemoryBudget indicates a query was stopped because it needed
+// more memory than Options.MemoryBudget allows.
+const ErrCodeOutOfMemoryBudget = "OUT_OF_MEMORY_BUDGET"
+
+// newOutOfMemoryBudgetError converts a membudget.ErrOutOfMemoryBudget raised
+// during execution into the DBError returned to the caller.
+func newOutOfMemoryBudgetError(err error) *dberror.DBError {
+	dbErr := dberror.Wrap(err, ErrCodeOutOfMemoryBudget, "ExecuteQuery", "Executor")
+	dbErr.Category = dberror.ErrCategoryUser
+	dbErr.Detail = "The query needed more memory than its budget allows"
+	dbErr.Hint = "Add a WHERE or LIMIT clause, or raise the limits of Options.MemoryBudget"
+
+	var limitErr *membudget.LimitError
+	if errors.As(err, &limitErr) && limitErr.Scope == membudget.GlobalScope {
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = "Running queries together hold all the memory the database allows"
+		dbErr.Hint = "Retry the query once other queries finish, or raise Options.MemoryBudget.GlobalBytes"
+	}
+	return dbErr
+}
+
+// ErrCodeTempQuotaExceeded indicates a query was stopped because it needed
+// more temporary disk space than its quota allows.
+const ErrCodeTempQuotaExceeded = "TEMP_QUOTA_EXCEEDED"
+
+// newTempQuotaError converts a tempfile.ErrTempQuotaExceeded raised during
+// execution into a database error.
+func newTempQuotaError(err error) *dberror.DBError {
+	dbErr := dberror.Wrap(err, ErrCodeTempQuotaExceeded, "ExecuteQuery", "Executor")
+	dbErr.Category = dberror.ErrCategoryUser
+	dbErr.Detail = "The query needed more temporary disk space than its quota allows"
+	dbErr.Hint = "Add a WHERE or LIMIT clause, or raise Options.TempFiles.QueryBytes"
+
+	var quotaErr *tempfile.QuotaError
+	if errors.As(err, &quotaErr) && quotaErr.Scope == tempfile.GlobalScope {
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = "Running queries together hold all the temporary disk space the database allows"
+		dbErr.Hint = "Retry the query once other queries finish, or raise Options.TempFiles.GlobalBytes"
+	}
+	return dbErr
+}
+
+// IsTempQuotaError reports whether err was caused by a query exceeding its
+// temporary file quota.
+func IsTempQuotaError(err error) bool {
+	return errors.Is(err, tempfile.ErrTempQuotaExceeded)
+}
+
+// ErrCodeQuotaExceeded indicates a write was refused because it would take
+// the database past its size quota or a table past its row quota.
+const ErrCodeQuotaExceeded = "QUOTA_EXCEEDED"
+
+// newQuotaError converts an accounting.ErrQuotaExceeded raised during
+// execution into a database error.
+func newQuotaError(err error) *dberror.DBError {
+	dbErr := dberror.Wrap(err, ErrCodeQuotaExceeded, "ExecuteQuery", "Executor")
+	dbErr.Category = dberror.ErrCategoryUser
+	dbErr.Detail = "The table has reached its row quota"
+	dbErr.Hint = "Delete rows from the table, or raise Options.Quota.MaxTableRows"
+
+	var quotaErr *accounting.QuotaError
+	if errors.As(err, &quotaErr) && quotaErr.Kind == accounting.SizeQuota {
+		dbErr.Detail = "The database has reached its size quota"
+		dbErr.Hint = "Delete rows to free space in the existing pages, or raise Options.Quota.MaxBytes"
+	}
+	return dbErr
+}
+
+// IsQuotaExceededError reports whether err was caused by a write exceeding
+// the size or row quota of the database.
+func IsQuotaExceededError(err error) bool {
+	return errors.Is(err, accounting.ErrQuotaExceeded)
+}
+
+// IsOutOfMemoryBudgetError reports whether err was caused by a query
+// exceeding its memory budget.
+func IsOutOfMemoryBudgetError(err error) bool {
+	return errors.Is(err, membudget.ErrOutOfMemoryBudget)
+}
+
+// IsReadOnlyError reports whether err was caused by a write against a read-only
+// database or in a read-only transaction.
+func IsReadOnlyError(err error) bool {
+	var dbErr *dberror.DBError
+	return errors.As(err, &dbErr) && dbErr.Code == ErrCodeReadOnly
+}
+
+// newReadOnlyError creates the DBError returned when a write is attempted in read-only mode.
+func newReadOnlyError(operation string) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryUser,
+		ErrCodeReadOnly,
+		"cannot execute write operation in a read-only database",
+	)
+	err.Detail = fmt.Sprintf("%s is not allowed because the database was opened read-only", operation)
+	err.Hint = "Reopen the database without the read-only option to modify it"
+	err.Operation = operation
+	err.Component = "Database"
+	return err
+}
+
+// ErrCodeDiskSpaceLow indicates a write was refused because the disk of the
+// WAL has less free space than Options.WALDiskReserve keeps.
+const ErrCodeDiskSpaceLow = "DISK_SPACE_LOW"
+
+// newDiskSpaceLowError creates the DBError returned when a write is
+// attempted while the WAL disk is below its reserve. cause is the error
+// that refused it, or nil if the statement was refused before it ran.
+func newDiskSpaceLowError(operation string, cause error) *dberror.DBError {
+	if cause == nil {
+		cause = wal.ErrDiskSpaceLow
+	}
+	err := dberror.Wrap(cause, ErrCodeDiskSpaceLow, operation, "Database")
+	err.Category = dberror.ErrCategoryTransient
+	err.Detail = fmt.Sprintf("%s is not allowed because the disk of the WAL is almost full; the database only reads until space is freed", operation)
+	err.Hint = "Free space on the disk of the WAL; writes resume automatically"
+	return err
+}
+
+// IsDiskSpaceLowError reports whether err was caused by a write refused
+// while the disk of the WAL was below its reserve.
+func IsDiskSpaceLowError(err error) bool {
+	return errors.Is(err, wal.ErrDiskSpaceLow)
+}
+
+// newReadOnlyTransactionError creates the DBError returned when a write is
+// attempted in a transaction begun with BeginReadOnlyTransaction.
+func newReadOnlyTransactionError(operation string) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryUser,
+		ErrCodeReadOnly,
+		"cannot execute write operation in a read-only transaction",
+	)
+	err.Detail = fmt.Sprintf("%s is not allowed because the transaction was begun read-only", operation)
+	err.Hint = "Run the statement in a transaction started with BeginTransaction"
+	err.Operation = operation
+	err.Component = "Database"
+	return err
+}
+
+// isReadOnlyStatement reports whether stmt can run without modifying the database.
+// EXPLAIN is allowed unless it is EXPLAIN ANALYZE of a statement that would write,
+// since ANALYZE actually executes the underlying statement.
+func isReadOnlyStatement(stmt statements.Statement) bool {
+	switch s := stmt.(type) {
+	case *statements.SelectStatement, *statements.ShowIndexesStatement, *statements.ShowPersistentStatement,
+		*statements.ChecksumTableStatement, *statements.SetTransactionSnapshotStatement, *statements.SetStatement:
+		return true
+	case *statements.ExplainStatement:
+		if !s.Options.Analyze {
+			return true
+		}
+		return isReadOnlyStatement(s.Statement)
+	default:
+		return false
+	}
+}
+
+// SetBufferPoolSize changes how many pages the buffer pool holds at most,
+// without reopening the database, like SET buffer_pool_size. Shrinking
+// evicts clean pages at once; dirty and locked pages over the new size are
+// evicted once their transactions finish (see memory.PageStore.SetCapacity).
+// The size goes back to the default when the database is reopened.
+func (db *Database) SetBufferPoolSize(pages int) (memory.ResizeResult, error) {
+	res, err := db.pageStore.SetCapacity(pages)
+	if err != nil {
+		dbErr := dberror.Wrap(err, "INVALID_BUFFER_POOL_SIZE", "SetBufferPoolSize", "PageStore")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Hint = fmt.Sprintf("Use a size of at least %d pages", memory.MinPageCount)
+		return res, dbErr
+	}
+	return res, nil
+}
+
+// AcknowledgeStandby records that the named standby of Options.Replication
+// has received, or with wal.AckApplied also applied, every WAL record before
+// lsn. Commits waiting for the standby return once enough have acknowledged.
+func (db *Database) AcknowledgeStandby(name string, level wal.AckLevel, lsn primitives.LSN) error {
+	if err := db.walInstance.AcknowledgeStandby(name, level, lsn); err != nil {
+		dbErr := dberror.Wrap(err, "UNKNOWN_STANDBY", "AcknowledgeStandby", "WAL")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Hint = "Only standbys named in Options.Replication can acknowledge commits"
+		return dbErr
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+)
+
+// ErrCodeQueryPanic indicates a statement panicked while it ran
+const ErrCodeQueryPanic = "QUERY_PANIC"
+
+// ErrQueryPanic is the cause of the error a statement that panicked fails
+// with, so that callers can tell it apart with errors.Is.
+var ErrQueryPanic = errors.New("statement panicked")
+
+// recoverStatement turns a panic raised while a statement of tx ran into a
+// QUERY_PANIC error, so that a bug in one operator fails its query instead
+// of the process. The stack of the panic is logged. The transaction may be
+// left in any state, so the caller must abort it rather than roll back the
+// statement alone.
+//
+// Only the goroutine running the statement is covered: a panic in a
+// goroutine an operator starts still ends the process.
+func (db *Database) recoverStatement(tx *transaction.TransactionContext, query string, recovered any) *dberror.DBError {
+	db.recordError()
+	queryPanics.Inc()
+	txLog := db.txLogger(tx)
+	txLog.Error("statement panicked", "panic", fmt.Sprint(recovered), "query", query, "stack", string(debug.Stack()))
+
+	dbErr := dberror.New(dberror.ErrCategorySystem, ErrCodeQueryPanic, fmt.Sprintf("internal error: %v", recovered))
+	dbErr.Cause = ErrQueryPanic
+	dbErr.Operation = "ExecuteQuery"
+	dbErr.Component = "Executor"
+	dbErr.Detail = "The statement hit an internal error and its transaction was aborted"
+	dbErr.Hint = "This is a bug; the stack trace is in the server log. Other queries are not affected"
+	return dbErr
+}
+
+// IsQueryPanicError reports whether err is the error of a statement that
+// panicked.
+func IsQueryPanicError(err error) bool {
+	return errors.Is(err, ErrQueryPanic)
+}
@@ -0,0 +1,13 @@
+package database
+
+import "storemy/pkg/recovery"
+
+// ExplainRecovery narrates what ARIES recovery would do if the database
+// crashed now: which transactions analysis finds to be winners and losers,
+// and for every WAL record whether redo replays it and undo rolls it back,
+// and why. It is a dry run that changes no page and writes no log record.
+func (db *Database) ExplainRecovery() (*recovery.Explanation, error) {
+	rm := recovery.NewRecoveryManager(db.walInstance, db.walPath, db.pageStore)
+	rm.SetLogger(db.recoveryLog)
+	return rm.Explain()
+}
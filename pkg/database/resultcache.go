@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/parser"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/plan"
+	"strings"
+	"unsafe"
+)
+
+// resultCacheKey returns the key under which the result of query run with
+// args is cached, and false if the query cannot be cached: caching is
+// disabled or the query is not a SELECT. The key is the exact query text, as
+// two texts with the same fingerprint differ in their literals.
+func (db *Database) resultCacheKey(query string, args []any) (string, bool) {
+	if !db.resultCache.Enabled() {
+		return "", false
+	}
+	if lexer.NewLexer(query).NextToken().Type != lexer.SELECT {
+		return "", false
+	}
+	if len(args) == 0 {
+		return query, true
+	}
+	return fmt.Sprintf("%s\x00%#v", query, args), true
+}
+
+// cachedResult returns a copy of the cached result for key, unless the
+// database is shutting down.
+func (db *Database) cachedResult(key string) (QueryResult, bool) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	if db.closing {
+		return QueryResult{}, false
+	}
+
+	cached, ok := db.resultCache.Get(key)
+	if !ok {
+		return QueryResult{}, false
+	}
+	return cloneResult(cached.(QueryResult)), true
+}
+
+// cacheResult stores the result of a query that started at version, if every
+// table it read is a stored user table. System views, foreign tables and the
+// catalog tables change without going through INSERT, UPDATE or DELETE, so
+// results over them are never cached.
+func (db *Database) cacheResult(tx *transaction.TransactionContext, key, query string, version uint64, result QueryResult) {
+	stmt, err := parser.ParseStatement(query)
+	if err != nil {
+		return
+	}
+	selectStmt, ok := stmt.(*statements.SelectStatement)
+	if !ok {
+		return
+	}
+
+	tables := selectTables(selectStmt.Plan, nil)
+	for _, table := range tables {
+		if _, ok := db.dbCtx.SystemViews().Lookup(table); ok {
+			return
+		}
+		if strings.HasPrefix(strings.ToUpper(table), "CATALOG_") || !db.catalogMgr.TableExists(tx, table) {
+			return
+		}
+	}
+
+	result = cloneResult(result)
+	db.resultCache.Put(key, tables, version, result, resultSize(result))
+}
+
+// selectTables appends the names of the tables p reads to tables.
+func selectTables(p *plan.SelectPlan, tables []string) []string {
+	if p.IsSetOperation() {
+		tables = selectTables(p.LeftPlan(), tables)
+		return selectTables(p.RightPlan(), tables)
+	}
+
+	for _, scan := range p.Tables() {
+		tables = append(tables, scan.TableName)
+	}
+	for _, join := range p.Joins() {
+		tables = append(tables, join.RightTable.TableName)
+	}
+	return tables
+}
+
+// cloneResult copies the rows of result, so neither the cache nor its callers
+// see the other's changes.
+func cloneResult(result QueryResult) QueryResult {
+	rows := make([][]string, len(result.Rows))
+	for i, row := range result.Rows {
+		rows[i] = append([]string(nil), row...)
+	}
+	result.Rows = rows
+	result.Columns = append([]string(nil), result.Columns...)
+	return result
+}
+
+// resultSize estimates the memory held by result, counted against the
+// cache's MaxBytes.
+func resultSize(result QueryResult) int64 {
+	const stringHeader = int64(unsafe.Sizeof(""))
+	const sliceHeader = int64(unsafe.Sizeof([]string(nil)))
+
+	size := int64(unsafe.Sizeof(result)) + int64(len(result.Message))
+	for _, column := range result.Columns {
+		size += stringHeader + int64(len(column))
+	}
+	for _, row := range result.Rows {
+		size += sliceHeader
+		for _, value := range row {
+			size += stringHeader + int64(len(value))
+		}
+	}
+	return size
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultRetryBackoff is how long the first retry of a statement waits
+	// when RetryPolicy.InitialBackoff is zero.
+	DefaultRetryBackoff = 10 * time.Millisecond
+
+	// DefaultMaxRetryBackoff caps the wait between retries of a statement
+	// when RetryPolicy.MaxBackoff is zero.
+	DefaultMaxRetryBackoff = time.Second
+)
+
+// RetryPolicy controls the automatic retry of statements whose implicit
+// transaction failed with a retryable error. The failed transaction is
+// aborted, releasing its locks, before the statement waits and runs again.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a statement runs at most, the first
+	// time included. Statements are not retried below 2.
+	MaxAttempts int
+
+	// InitialBackoff is how long the first retry waits, zero for
+	// DefaultRetryBackoff. Each retry waits twice as long as the one
+	// before, up to MaxBackoff, less a random jitter of up to half the wait
+	// so that the transactions of a deadlock do not retry in step.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between retries, zero for
+	// DefaultMaxRetryBackoff.
+	MaxBackoff time.Duration
+}
+
+// Enabled reports whether p retries statements.
+func (p RetryPolicy) Enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// backoff returns how long to wait before the given retry, 1 for the first.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	wait := p.InitialBackoff
+	if wait <= 0 {
+		wait = DefaultRetryBackoff
+	}
+	limit := p.MaxBackoff
+	if limit <= 0 {
+		limit = DefaultMaxRetryBackoff
+	}
+
+	for i := 1; i < retry && wait < limit; i++ {
+		wait *= 2
+	}
+	wait = min(wait, limit)
+	return wait - rand.N(wait/2+1)
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	dberror "storemy/pkg/error"
+	"storemy/pkg/optimizer"
+)
+
+// RegisterRewriteRule adds rule to the query optimizer, which applies it
+// after its built-in rules and any rule registered before it. Rules are not
+// persisted: after reopening the database, register them again.
+func (db *Database) RegisterRewriteRule(rule optimizer.RewriteRule) error {
+	if err := db.dbCtx.RewriteRules().Register(rule); err != nil {
+		dbErr := dberror.Wrap(err, "INVALID_REWRITE_RULE", "RegisterRewriteRule", "Optimizer")
+		dbErr.Category = dberror.ErrCategoryUser
+		return dbErr
+	}
+	return nil
+}
+
+// UnregisterRewriteRule removes the rewrite rule with the given name.
+func (db *Database) UnregisterRewriteRule(name string) {
+	db.dbCtx.RewriteRules().Unregister(name)
+}
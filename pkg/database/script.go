@@ -0,0 +1,326 @@
+package database
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/parser/parser"
+	"strings"
+	"time"
+)
+
+// ScriptErrorPolicy decides what ExecuteScript does when a statement fails.
+type ScriptErrorPolicy int
+
+const (
+	// ScriptStopOnError runs each statement in its own transaction and stops at
+	// the first failure. Statements that already succeeded stay committed.
+	ScriptStopOnError ScriptErrorPolicy = iota
+
+	// ScriptContinueOnError runs each statement in its own transaction and
+	// keeps going after failures.
+	ScriptContinueOnError
+
+	// ScriptRollbackAll runs the whole script in one transaction, which is
+	// committed only if every statement succeeds and aborted otherwise. The
+	// abort undoes page changes only: files created by DDL stay on disk.
+	ScriptRollbackAll
+)
+
+// String returns the policy name accepted by ParseScriptErrorPolicy.
+func (p ScriptErrorPolicy) String() string {
+	switch p {
+	case ScriptStopOnError:
+		return "stop"
+	case ScriptContinueOnError:
+		return "continue"
+	case ScriptRollbackAll:
+		return "rollback"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseScriptErrorPolicy parses a policy name: stop, continue or rollback.
+func ParseScriptErrorPolicy(name string) (ScriptErrorPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "stop":
+		return ScriptStopOnError, nil
+	case "continue":
+		return ScriptContinueOnError, nil
+	case "rollback", "rollback-all":
+		return ScriptRollbackAll, nil
+	default:
+		return 0, fmt.Errorf("unknown script error policy %q (expected stop, continue or rollback)", name)
+	}
+}
+
+// StatementStatus is the outcome of one statement of a script.
+type StatementStatus int
+
+const (
+	StatementSucceeded StatementStatus = iota
+	StatementFailed
+	StatementSkipped    // Not run because an earlier statement failed
+	StatementRolledBack // Succeeded, but the script's transaction was rolled back
+)
+
+func (s StatementStatus) String() string {
+	switch s {
+	case StatementSucceeded:
+		return "OK"
+	case StatementFailed:
+		return "FAILED"
+	case StatementSkipped:
+		return "SKIPPED"
+	case StatementRolledBack:
+		return "ROLLED BACK"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// StatementResult records how one statement of a script ran.
+type StatementResult struct {
+	Index    int // 1-based position in the script
+	Line     int // 1-based line on which the statement starts
+	SQL      string
+	Status   StatementStatus
+	Result   QueryResult
+	Err      error
+	Duration time.Duration
+}
+
+// ScriptResult summarizes a script run.
+type ScriptResult struct {
+	Policy     ScriptErrorPolicy
+	Statements []StatementResult
+	Succeeded  int
+	Failed     int
+	Skipped    int
+	RolledBack bool // True if the rollback policy aborted the script's transaction
+	Duration   time.Duration
+}
+
+// Summary returns a one-line description of the run.
+func (r *ScriptResult) Summary() string {
+	summary := fmt.Sprintf("%d statements: %d succeeded, %d failed, %d skipped in %v",
+		len(r.Statements), r.Succeeded, r.Failed, r.Skipped, r.Duration.Round(time.Microsecond))
+	if r.RolledBack {
+		summary += "; all changes rolled back"
+	}
+	return summary
+}
+
+// QueryResult renders the run as a table with one row per statement.
+func (r *ScriptResult) QueryResult() QueryResult {
+	rows := make([][]string, len(r.Statements))
+	for i, s := range r.Statements {
+		detail := s.Result.Message
+		if s.Err != nil {
+			detail = s.Err.Error()
+		}
+		rows[i] = []string{
+			fmt.Sprintf("%d", s.Index),
+			fmt.Sprintf("%d", s.Line),
+			s.Status.String(),
+			fmt.Sprintf("%d", len(s.Result.Rows)+s.Result.RowsAffected),
+			s.Duration.Round(time.Microsecond).String(),
+			firstLine(s.SQL),
+			detail,
+		}
+	}
+	return QueryResult{
+		Success: r.Failed == 0,
+		Columns: []string{"#", "LINE", "STATUS", "ROWS", "TIME", "STATEMENT", "DETAIL"},
+		Rows:    rows,
+		Message: r.Summary(),
+	}
+}
+
+// ExecuteScript splits script into statements and runs them in order, applying
+// policy when a statement fails. The returned ScriptResult describes every
+// statement, including the ones skipped after a failure.
+//
+// The error is non-nil if the script could not be split or if any statement
+// failed; a SCRIPT_FAILED error names the first failing statement and wraps
+// its error.
+func (db *Database) ExecuteScript(script string, policy ScriptErrorPolicy) (*ScriptResult, error) {
+	log := db.logger
+	start := time.Now()
+
+	parsed, err := parser.SplitScript(script)
+	if err != nil {
+		dbErr := dberror.Wrap(err, "PARSE_ERROR", "ExecuteScript", "Parser")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Detail = "Failed to split the script into statements"
+		return nil, dbErr
+	}
+
+	result := &ScriptResult{Policy: policy, Statements: make([]StatementResult, len(parsed))}
+	for i, p := range parsed {
+		result.Statements[i] = StatementResult{Index: i + 1, Line: p.Line, SQL: p.SQL, Status: StatementSkipped}
+	}
+	log.Info("executing script", "statements", len(parsed), "policy", policy.String())
+
+	if policy == ScriptRollbackAll {
+		err = db.runScriptInTransaction(result)
+	} else {
+		db.runScriptStatements(result, policy == ScriptContinueOnError)
+	}
+
+	for _, s := range result.Statements {
+		switch s.Status {
+		case StatementSucceeded:
+			result.Succeeded++
+		case StatementFailed:
+			result.Failed++
+		case StatementSkipped:
+			result.Skipped++
+		}
+	}
+	result.Duration = time.Since(start)
+	log.Info("script finished", "succeeded", result.Succeeded, "failed", result.Failed,
+		"skipped", result.Skipped, "rolled_back", result.RolledBack, "duration_ms", result.Duration.Milliseconds())
+
+	if err != nil {
+		return result, err
+	}
+	if first := result.firstFailure(); first != nil {
+		return result, newScriptError(first)
+	}
+	return result, nil
+}
+
+// runScriptStatements runs each statement through ExecuteQuery.
+func (db *Database) runScriptStatements(result *ScriptResult, continueOnError bool) {
+	for i := range result.Statements {
+		s := &result.Statements[i]
+		start := time.Now()
+		s.Result, s.Err = db.ExecuteQuery(s.SQL)
+		s.Duration = time.Since(start)
+
+		if s.Err == nil {
+			s.Status = StatementSucceeded
+			continue
+		}
+		s.Status = StatementFailed
+		if !continueOnError || isClosedError(s.Err) {
+			return
+		}
+	}
+}
+
+// runScriptInTransaction runs every statement in a single transaction,
+// aborting it at the first failure and committing it otherwise. The whole
+// script takes a single admission slot. The returned error is only set for
+// failures outside a statement (admission, begin or commit).
+func (db *Database) runScriptInTransaction(result *ScriptResult) (err error) {
+	release, err := db.admit("ExecuteScript")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tx, err := db.begin("ExecuteScript")
+	if isClosedError(err) {
+		return err
+	}
+	if err != nil {
+		dbErr := dberror.Wrap(err, "TX_BEGIN_FAILED", "ExecuteScript", "TransactionRegistry")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = "Failed to start the script transaction"
+		return dbErr
+	}
+	defer db.cleanupTransaction(tx, &err)
+
+	for i := range result.Statements {
+		s := &result.Statements[i]
+		if s.Err = db.runScriptStatement(tx, s); s.Err != nil {
+			s.Status = StatementFailed
+			db.rollBackScript(tx, result)
+			return nil
+		}
+		s.Status = StatementSucceeded
+	}
+
+	if err = db.pageStore.CommitTransaction(tx); err != nil {
+		db.recordError()
+		result.markRolledBack()
+		dbErr := dberror.Wrap(err, "COMMIT_FAILED", "ExecuteScript", "PageStore")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = "Failed to commit the script transaction; no statement took effect"
+		return dbErr
+	}
+	return nil
+}
+
+// runScriptStatement runs one statement inside the script transaction, with
+// the same session tracking, tracing and statistics as ExecuteQuery.
+func (db *Database) runScriptStatement(tx *transaction.TransactionContext, s *StatementResult) error {
+	start := time.Now()
+	sessionID := db.sessions.Start(s.SQL, tx.ID.ID())
+	defer db.sessions.End(sessionID)
+	defer func() {
+		db.finishTrace(tx)
+		tx.SetTrace(nil)
+	}()
+
+	res, _, err := db.runStatement(tx, s.SQL, nil, nil, start)
+	s.Duration = time.Since(start)
+	if err != nil {
+		return err
+	}
+	s.Result = res
+	db.recordQuery(s.SQL, res, start)
+	return nil
+}
+
+// rollBackScript aborts the script transaction after a failed statement.
+func (db *Database) rollBackScript(tx *transaction.TransactionContext, result *ScriptResult) {
+	if err := db.pageStore.AbortTransaction(tx); err != nil {
+		db.txLogger(tx).Error("failed to roll back script", "error", err)
+	}
+	result.markRolledBack()
+}
+
+func (r *ScriptResult) markRolledBack() {
+	r.RolledBack = true
+	for i := range r.Statements {
+		if r.Statements[i].Status == StatementSucceeded {
+			r.Statements[i].Status = StatementRolledBack
+		}
+	}
+}
+
+func (r *ScriptResult) firstFailure() *StatementResult {
+	for i := range r.Statements {
+		if r.Statements[i].Status == StatementFailed {
+			return &r.Statements[i]
+		}
+	}
+	return nil
+}
+
+// newScriptError reports the first failed statement of a script. Its
+// category is taken from the statement's error.
+func newScriptError(s *StatementResult) *dberror.DBError {
+	category := dberror.ErrCategorySystem
+	if cause, ok := s.Err.(*dberror.DBError); ok {
+		category = cause.Category
+	}
+	err := dberror.New(category, "SCRIPT_FAILED", fmt.Sprintf("statement %d (line %d) failed", s.Index, s.Line))
+	err.Detail = firstLine(s.SQL)
+	err.Operation = "ExecuteScript"
+	err.Component = "Database"
+	err.Cause = s.Err
+	return err
+}
+
+// firstLine returns the first line of sql, marking truncation with "...".
+func firstLine(sql string) string {
+	if i := strings.IndexByte(sql, '\n'); i >= 0 {
+		return strings.TrimSpace(sql[:i]) + " ..."
+	}
+	return sql
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+	"time"
+)
+
+const (
+	// ErrCodeDatabaseClosed indicates a query or transaction was started after shutdown began
+	ErrCodeDatabaseClosed = "DATABASE_CLOSED"
+
+	// DefaultShutdownTimeout is how long Close waits for active transactions
+	// before aborting them.
+	DefaultShutdownTimeout = 10 * time.Second
+
+	// drainPollInterval is how often Shutdown re-checks for active transactions.
+	drainPollInterval = 5 * time.Millisecond
+)
+
+// Close shuts the database down, giving active transactions up to the
+// configured ShutdownTimeout to finish. See Shutdown.
+func (db *Database) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), db.shutdownTimeout)
+	defer cancel()
+	return db.Shutdown(ctx)
+}
+
+// Shutdown closes the database in dependency order:
+//  1. Reject new queries and transactions with a DATABASE_CLOSED error
+//  2. Stop the statistics updater and the checkpoint daemon
+//  3. Wait for active transactions to commit or abort; once ctx is done,
+//     abort the ones still running
+//  4. Flush the buffer pool
+//  5. Close the index and heap files, then the WAL
+//
+// If ctx ends before the transactions drain, shutdown still completes and the
+// returned error wraps ctx.Err(). Only the first call does any work; later
+// calls wait for it and return its result.
+func (db *Database) Shutdown(ctx context.Context) error {
+	db.shutdownOnce.Do(func() {
+		db.shutdownErr = db.shutdown(ctx)
+	})
+	return db.shutdownErr
+}
+
+func (db *Database) shutdown(ctx context.Context) error {
+	log := db.logger
+	log.Info("closing database")
+	start := time.Now()
+
+	db.mutex.Lock()
+	db.closing = true
+	db.mutex.Unlock()
+
+	if db.statsManager != nil {
+		log.Debug("stopping statistics manager")
+		db.statsManager.Stop()
+	}
+
+	if db.checkpointer != nil {
+		log.Debug("stopping checkpoint daemon")
+		db.checkpointer.Stop()
+	}
+
+	aborted := db.drainTransactions(ctx)
+	db.tempFiles.Close()
+
+	log.Debug("flushing all pages")
+	if err := db.pageStore.FlushAllPages(); err != nil {
+		dbErr := dberror.Wrap(err, "PAGE_FLUSH_FAILED", "Close", "PageStore")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = "Failed to flush dirty pages to disk during shutdown"
+		dbErr.Hint = "Check disk space and file system health. Some data may not be persisted"
+		log.Error("failed to flush pages", "error", err)
+		return dbErr
+	}
+	if err := db.pageStore.DisableDoubleWrite(); err != nil {
+		log.Warn("failed to remove double-write buffer", "error", err)
+	}
+
+	log.Debug("closing index and heap files")
+	if err := db.dbCtx.IndexManager().Close(); err != nil {
+		log.Warn("failed to close index files", "error", err)
+	}
+	db.catalogMgr.ClearCacheCompletely()
+
+	log.Debug("closing WAL")
+	if err := db.walInstance.Close(); err != nil {
+		dbErr := dberror.Wrap(err, "WAL_CLOSE_FAILED", "Close", "WAL")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = "Failed to close Write-Ahead Log during shutdown"
+		log.Error("failed to close WAL", "error", err)
+		return dbErr
+	}
+
+	if aborted > 0 {
+		dbErr := dberror.Wrap(ctx.Err(), "SHUTDOWN_TIMEOUT", "Close", "Database")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = fmt.Sprintf("%d active transaction(s) were aborted when the shutdown deadline passed", aborted)
+		dbErr.Hint = "Allow more time for shutdown, or finish transactions before closing the database"
+		log.Warn("database closed after aborting transactions", "aborted", aborted, "duration_ms", time.Since(start).Milliseconds())
+		return dbErr
+	}
+
+	log.Info("database closed successfully", "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// drainTransactions waits until no transaction is active. If ctx ends first,
+// the remaining transactions are aborted and their number is returned.
+func (db *Database) drainTransactions(ctx context.Context) int {
+	log := db.logger
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		active := db.txRegistry.GetActive()
+		if len(active) == 0 {
+			return 0
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Warn("shutdown deadline reached, aborting active transactions", "count", len(active))
+			for _, tx := range active {
+				if err := db.pageStore.AbortTransaction(tx); err != nil {
+					log.Error("failed to abort transaction", "tx_id", tx.ID.ID(), "error", err)
+				}
+			}
+			return len(active)
+		}
+	}
+}
+
+// begin starts a transaction at the database's isolation level unless
+// shutdown has begun. The read lock is held while the transaction registers
+// so Shutdown either rejects it or waits for it.
+func (db *Database) begin(op string) (*transaction.TransactionContext, error) {
+	if db.walInstance.DiskSpaceLow() {
+		// The WAL refuses to begin transactions, so only reads can run
+		return db.beginWith(op, db.txRegistry.BeginReadOnly)
+	}
+	return db.beginWith(op, db.txRegistry.Begin)
+}
+
+// beginWith starts a transaction through start unless the database is
+// closing.
+func (db *Database) beginWith(op string, start func() (*transaction.TransactionContext, error)) (*transaction.TransactionContext, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	if db.closing {
+		return nil, newClosedError(op)
+	}
+	tx, err := start()
+	if err != nil {
+		return nil, err
+	}
+	tx.SetIsolationLevel(db.isolation)
+	return tx, nil
+}
+
+func newClosedError(operation string) *dberror.DBError {
+	err := dberror.New(
+		dberror.ErrCategoryUser,
+		ErrCodeDatabaseClosed,
+		"database is closed",
+	)
+	err.Detail = fmt.Sprintf("%s is not allowed because the database has been shut down", operation)
+	err.Hint = "Open the database again to run further statements"
+	err.Operation = operation
+	err.Component = "Database"
+	return err
+}
+
+// isClosedError reports whether err was returned because the database is closed.
+func isClosedError(err error) bool {
+	dbErr, ok := err.(*dberror.DBError)
+	return ok && dbErr.Code == ErrCodeDatabaseClosed
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"storemy/pkg/concurrency/transaction"
+)
+
+// ExportSnapshot shares the state tx reads with other transactions and
+// returns a token naming it. Other transactions, typically read-only ones
+// begun by parallel dump workers, import it with ImportSnapshot or
+// SET TRANSACTION SNAPSHOT 'token', and then all read the same committed
+// state of every table.
+//
+// Locks are held until commit rather than versioned, so the export waits
+// for transactions already writing to finish, and from then until tx and
+// every importer have ended, statements that would write wait for the
+// snapshot (failing after the table lock timeout). tx must not have written
+// anything. The token can be imported until tx ends.
+func (db *Database) ExportSnapshot(tx *transaction.TransactionContext) (string, error) {
+	log := db.txLogger(tx)
+	token, err := db.pageStore.ExportSnapshot(tx)
+	if err != nil {
+		log.Error("snapshot export failed", "error", err)
+		return "", err
+	}
+	log.Info("snapshot exported", "snapshot", token)
+	return token, nil
+}
+
+// ImportSnapshot makes tx read the snapshot exported under token by a
+// transaction that is still running. It is the same as executing
+// SET TRANSACTION SNAPSHOT 'token' in tx.
+func (db *Database) ImportSnapshot(tx *transaction.TransactionContext, token string) error {
+	log := db.txLogger(tx)
+	if err := db.pageStore.ImportSnapshot(tx, token); err != nil {
+		log.Error("snapshot import failed", "error", err)
+		return err
+	}
+	log.Info("snapshot imported", "snapshot", token)
+	return nil
+}
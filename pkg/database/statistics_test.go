@@ -19,7 +19,7 @@ func TestDatabase_StatisticsAutoTracking(t *testing.T) {
 	logDir := filepath.Join(tempDir, "wal.log")
 
 	// Create database - statistics tracking should be automatic
-	db, err := NewDatabase(dbName, tempDir, logDir)
+	db, err := NewDatabaseWithOptions(dbName, tempDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -68,6 +68,7 @@ func TestDatabase_StatisticsAutoTracking(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to begin transaction: %v", err)
 	}
+	defer db.CommitTransaction(tx)
 	tableID, err := db.catalogMgr.GetTableID(tx, "USERS")
 	if err != nil {
 		t.Fatalf("Failed to get table ID for 'USERS': %v", err)
@@ -121,7 +122,7 @@ func TestDatabase_StatisticsAfterDelete(t *testing.T) {
 	dbName := "test_delete_stats"
 	logDir := filepath.Join(tempDir, "wal.log")
 
-	db, err := NewDatabase(dbName, tempDir, logDir)
+	db, err := NewDatabaseWithOptions(dbName, tempDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -213,7 +214,7 @@ func TestDatabase_StatisticsMultipleTables(t *testing.T) {
 	dbName := "test_multi_stats"
 	logDir := filepath.Join(tempDir, "wal.log")
 
-	db, err := NewDatabase(dbName, tempDir, logDir)
+	db, err := NewDatabaseWithOptions(dbName, tempDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -268,7 +269,7 @@ func TestDatabase_StatisticsThresholdBehavior(t *testing.T) {
 	dbName := "test_threshold"
 	logDir := filepath.Join(tempDir, "wal.log")
 
-	db, err := NewDatabase(dbName, tempDir, logDir)
+	db, err := NewDatabaseWithOptions(dbName, tempDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -285,6 +286,7 @@ func TestDatabase_StatisticsThresholdBehavior(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to begin transaction: %v", err)
 	}
+	defer db.CommitTransaction(tx)
 	tableID, err := db.catalogMgr.GetTableID(tx, toUpperTable("threshold_test"))
 	if err != nil {
 		t.Fatalf("Failed to get table ID: %v", err)
@@ -326,7 +328,7 @@ func TestDatabase_StatisticsNotFound(t *testing.T) {
 	dbName := "test_notfound"
 	logDir := filepath.Join(tempDir, "wal.log")
 
-	db, err := NewDatabase(dbName, tempDir, logDir)
+	db, err := NewDatabaseWithOptions(dbName, tempDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -355,7 +357,7 @@ func TestDatabase_StatisticsAfterBulkInsert(t *testing.T) {
 	dbName := "test_bulk"
 	logDir := filepath.Join(tempDir, "wal.log")
 
-	db, err := NewDatabase(dbName, tempDir, logDir)
+	db, err := NewDatabaseWithOptions(dbName, tempDir, logDir, testOptions())
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	dberror "storemy/pkg/error"
+	"storemy/pkg/storage/accounting"
+)
+
+// StorageReport returns the storage used by every table, largest first: the
+// size of its heap file and indexes, its live and dead tuples and the free
+// space of its pages. SYS_TABLE_STORAGE shows the same report.
+//
+// Like Check, it reads committed data from disk.
+func (db *Database) StorageReport() ([]accounting.TableUsage, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	if db.closing {
+		return nil, newClosedError("StorageReport")
+	}
+
+	tx, err := db.txRegistry.Begin()
+	if err != nil {
+		dbErr := dberror.Wrap(err, "TX_BEGIN_FAILED", "StorageReport", "TransactionRegistry")
+		dbErr.Category = dberror.ErrCategoryTransient
+		dbErr.Detail = "Failed to begin transaction for the storage report"
+		return nil, dbErr
+	}
+	defer db.pageStore.AbortTransaction(tx)
+
+	usages, err := accounting.Collect(db.catalogMgr, db.pageStore.FS(), tx)
+	if err != nil {
+		dbErr := dberror.Wrap(err, "STORAGE_REPORT_FAILED", "StorageReport", "Accounting")
+		dbErr.Category = dberror.ErrCategorySystem
+		dbErr.Detail = "Failed to measure the storage of the tables"
+		return nil, dbErr
+	}
+	return usages, nil
+}
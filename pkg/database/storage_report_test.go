@@ -0,0 +1,50 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStorageReport_CountsLiveAndDeadTuples(t *testing.T) {
+	db, cleanup := setupTestDBInit(t, "testdb")
+	defer cleanup()
+
+	mustExec(t, db,
+		"CREATE TABLE users (id INT, name STRING)",
+		"CREATE TABLE empty (id INT)",
+		"CREATE INDEX idx_users_id ON users (id)",
+	)
+	for i := range 10 {
+		mustExec(t, db, fmt.Sprintf("INSERT INTO users (id, name) VALUES (%d, 'user')", i))
+	}
+	mustExec(t, db, "DELETE FROM users WHERE id < 3")
+
+	usages, err := db.StorageReport()
+	if err != nil {
+		t.Fatalf("StorageReport failed: %v", err)
+	}
+	if len(usages) != 2 || usages[0].Table != "USERS" {
+		t.Fatalf("expected USERS first of 2 tables, got %+v", usages)
+	}
+	users := usages[0]
+	if users.LiveTuples != 7 {
+		t.Errorf("LiveTuples = %d, expected 7", users.LiveTuples)
+	}
+	if users.DeadTuples < 1 || users.DeadTuples > 3 {
+		t.Errorf("DeadTuples = %d, expected between 1 and 3", users.DeadTuples)
+	}
+	if users.HeapPages == 0 || users.HeapBytes == 0 || users.FreeBytes == 0 {
+		t.Errorf("expected heap pages and free space, got %+v", users)
+	}
+	if users.Indexes != 1 || users.IndexBytes == 0 {
+		t.Errorf("expected one non-empty index, got %d of %d bytes", users.Indexes, users.IndexBytes)
+	}
+
+	result, err := db.ExecuteQuery("SELECT TABLE_NAME, LIVE_TUPLES FROM sys_table_storage WHERE TABLE_NAME = 'users'")
+	if err != nil {
+		t.Fatalf("SELECT from sys_table_storage failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][1] != "7" {
+		t.Errorf("unexpected rows %v", result.Rows)
+	}
+}
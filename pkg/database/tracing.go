@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/tracing"
+	"time"
+)
+
+// startTrace attaches a trace to tx if the query should be traced: always when
+// a trace exporter is configured, for EXPLAIN ANALYZE VERBOSE so that the
+// span tree can be returned to the client, and when a steps observer watches
+// the execution operator by operator. Parsing happens before this decision
+// is made, so it is recorded after the fact from parseStart. Returns nil when
+// the query is not traced; the nil trace is safe to use.
+func (db *Database) startTrace(tx *transaction.TransactionContext, stmt statements.Statement, steps tracing.StepObserver, start, parseStart time.Time) *tracing.Trace {
+	if db.exporter == nil && !isExplainVerbose(stmt) && steps == nil {
+		return nil
+	}
+
+	trace := tracing.NewTrace("query", start)
+	trace.SetDetailed(true)
+	trace.SetStepObserver(steps)
+	trace.Root().SetAttributes(tracing.Attr("statement_type", stmt.GetType().String()))
+	trace.Record("parse", parseStart, time.Now())
+	tx.SetTrace(trace)
+	return trace
+}
+
+// finishTrace ends the trace attached to tx, if any, and hands its spans to the
+// configured exporter. Export failures are logged and never fail the query.
+func (db *Database) finishTrace(tx *transaction.TransactionContext) {
+	trace := tx.Trace()
+	if trace == nil {
+		return
+	}
+
+	trace.Root().End()
+	if db.exporter == nil {
+		return
+	}
+
+	if err := db.exporter.ExportSpans(context.Background(), trace.Spans()); err != nil {
+		db.txLogger(tx).Warn("failed to export query trace", "trace_id", trace.ID().String(), "error", err)
+	}
+}
+
+// isExplainVerbose reports whether stmt is an EXPLAIN ANALYZE VERBOSE statement.
+func isExplainVerbose(stmt statements.Statement) bool {
+	explain, ok := stmt.(*statements.ExplainStatement)
+	return ok && explain.Options.Analyze && explain.Options.Verbose
+}
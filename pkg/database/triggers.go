@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/parser/parser"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/trigger"
+)
+
+// RegisterTriggerFunc makes fn available to triggers under a case-insensitive
+// name. A trigger can only be created for a registered function, and
+// functions are not persisted: after reopening the database, register them
+// again before running statements that fire their triggers.
+func (db *Database) RegisterTriggerFunc(name string, fn trigger.Func) error {
+	if err := db.dbCtx.Triggers().Register(name, fn); err != nil {
+		dbErr := dberror.Wrap(err, "INVALID_TRIGGER_FUNCTION", "RegisterTriggerFunc", "TriggerRegistry")
+		dbErr.Category = dberror.ErrCategoryUser
+		return dbErr
+	}
+	return nil
+}
+
+// UnregisterTriggerFunc removes a trigger function. Triggers that call it
+// fail until it is registered again.
+func (db *Database) UnregisterTriggerFunc(name string) {
+	db.dbCtx.Triggers().Unregister(name)
+}
+
+// runTriggerStatement executes a statement issued by a trigger function
+// inside the triggering statement's transaction. Only INSERT, UPDATE and
+// DELETE are allowed; triggers on the tables they change fire in turn.
+func (db *Database) runTriggerStatement(tx *transaction.TransactionContext, sql string) error {
+	stmt, err := parser.ParseStatement(sql)
+	if err != nil {
+		dbErr := dberror.Wrap(err, "PARSE_ERROR", "runTriggerStatement", "Parser")
+		dbErr.Category = dberror.ErrCategoryUser
+		dbErr.Detail = fmt.Sprintf("Invalid SQL syntax in trigger statement: %s", sql)
+		return dbErr
+	}
+
+	switch stmt.GetType() {
+	case statements.Insert, statements.Update, statements.Delete:
+	default:
+		return fmt.Errorf("triggers can only run INSERT, UPDATE or DELETE, got %s", stmt.GetType())
+	}
+
+	plan, err := db.queryPlanner.Plan(stmt, tx)
+	if err != nil {
+		return err
+	}
+	_, err = plan.Execute()
+	return err
+}
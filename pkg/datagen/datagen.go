@@ -0,0 +1,263 @@
+// Package datagen generates deterministic sample rows for experimenting with
+// indexes and query plans. Rows are numbered by a series, like the SQL
+// GENERATE_SERIES function, and every value is derived from the row number,
+// the column and a seed, so the same series and seed always produce the same
+// table, in any order and on any machine:
+//
+//	COPY users FROM GENERATE_SERIES(1, 1000000) OPTIONS (seed '42');
+//
+// Values follow the column's name where it suggests a kind of data, in the
+// manner of faker libraries: an ID column numbers the rows, NAME, EMAIL,
+// CITY and COUNTRY columns get plausible strings, AGE a human age, and a
+// column ending in _ID a key in the range of the series, ready to join on.
+// Other columns get uniformly distributed values of their type, drawn from a
+// range small enough that equality predicates match a few rows.
+package datagen
+
+import (
+	"fmt"
+	"math"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+)
+
+// DefaultSeed is the seed used when none is given.
+const DefaultSeed = 1
+
+// Series is the range of row numbers start, start+step, ... up to and
+// including stop, as generated by GENERATE_SERIES.
+type Series struct {
+	Start int64
+	Stop  int64
+	Step  int64
+}
+
+// Validate checks that the series has a non-zero step.
+func (s Series) Validate() error {
+	if s.Step == 0 {
+		return fmt.Errorf("GENERATE_SERIES step cannot be zero")
+	}
+	return nil
+}
+
+// Len returns the number of row numbers in the series, zero if start lies
+// beyond stop in the direction of step.
+func (s Series) Len() int64 {
+	if s.Step == 0 || (s.Step > 0 && s.Start > s.Stop) || (s.Step < 0 && s.Start < s.Stop) {
+		return 0
+	}
+	span := s.Stop - s.Start
+	if s.Step < 0 {
+		return -span/(-s.Step) + 1
+	}
+	return span/s.Step + 1
+}
+
+func (s Series) String() string {
+	if s.Step == 1 {
+		return fmt.Sprintf("GENERATE_SERIES(%d, %d)", s.Start, s.Stop)
+	}
+	return fmt.Sprintf("GENERATE_SERIES(%d, %d, %d)", s.Start, s.Stop, s.Step)
+}
+
+// column generates the values of one column from a row number and a random
+// number drawn for the row and column.
+type column func(n int64, r uint64) types.Field
+
+// Generator produces the rows of a table with a given schema.
+type Generator struct {
+	td      *tuple.TupleDescription
+	seed    uint64
+	columns []column
+}
+
+// New returns a generator of rows for td. Rows of the series s are keys for
+// the columns ending in _ID.
+func New(td *tuple.TupleDescription, s Series, seed int64) (*Generator, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{td: td, seed: uint64(seed), columns: make([]column, td.NumFields())}
+	low, high := min(s.Start, s.Stop), max(s.Start, s.Stop)
+	for i := range g.columns {
+		name, _ := td.GetFieldName(primitives.ColumnID(i))
+		col, err := columnFor(strings.ToUpper(name), td.Types[i], low, high)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", name, err)
+		}
+		g.columns[i] = col
+	}
+	return g, nil
+}
+
+// Row returns the row numbered n.
+func (g *Generator) Row(n int64) (*tuple.Tuple, error) {
+	t := tuple.NewTuple(g.td)
+	for i, col := range g.columns {
+		r := mix(g.seed ^ mix(uint64(n)^mix(uint64(i)+0x9e3779b97f4a7c15)))
+		if err := t.SetField(primitives.ColumnID(i), col(n, r)); err != nil {
+			return nil, fmt.Errorf("failed to set field: %v", err)
+		}
+	}
+	return t, nil
+}
+
+// Reader returns the rows of s in order.
+func (g *Generator) Reader(s Series) *Reader {
+	return &Reader{gen: g, series: s, remaining: s.Len(), next: s.Start}
+}
+
+// Reader streams the rows of a series. It satisfies foreign.Reader, so
+// generated rows can be loaded wherever a file's rows can.
+type Reader struct {
+	gen       *Generator
+	series    Series
+	remaining int64
+	next      int64
+}
+
+// Next returns the next row, or nil once the series is exhausted.
+func (r *Reader) Next() (*tuple.Tuple, error) {
+	if r.remaining == 0 {
+		return nil, nil
+	}
+	t, err := r.gen.Row(r.next)
+	if err != nil {
+		return nil, err
+	}
+	r.remaining--
+	r.next += r.series.Step
+	return t, nil
+}
+
+// Close does nothing; it is there to satisfy foreign.Reader.
+func (r *Reader) Close() error {
+	return nil
+}
+
+// mix is the SplitMix64 finalizer, a fast bijective hash of x.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+var (
+	firstNames = []string{"Ada", "Alan", "Barbara", "Charles", "Dennis", "Donald", "Edsger", "Frances", "Grace", "John", "Ken", "Leslie", "Linus", "Margaret", "Niklaus", "Radia", "Shafi", "Tim", "Tony", "Whitfield"}
+	lastNames  = []string{"Backus", "Codd", "Dijkstra", "Gray", "Hamilton", "Hopper", "Kay", "Knuth", "Lamport", "Liskov", "Lovelace", "McCarthy", "Perlman", "Ritchie", "Stonebraker", "Thompson", "Turing", "Wirth"}
+	cities     = []string{"Amsterdam", "Bangalore", "Berlin", "Boston", "Buenos Aires", "Cairo", "Lagos", "Lisbon", "London", "Madrid", "Mumbai", "Nairobi", "Osaka", "Paris", "Seoul", "Sydney", "Tokyo", "Toronto"}
+	countries  = []string{"Argentina", "Australia", "Brazil", "Canada", "Egypt", "France", "Germany", "India", "Japan", "Kenya", "Netherlands", "Nigeria", "Portugal", "South Korea", "Spain", "United Kingdom", "United States"}
+	words      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa", "quebec", "romeo", "sierra", "tango"}
+)
+
+// pick returns an element of list chosen by r.
+func pick(list []string, r uint64) string {
+	return list[r%uint64(len(list))]
+}
+
+// columnFor chooses how a column named name of type t is generated. Keys of
+// columns ending in _ID fall in [low, high].
+func columnFor(name string, t types.Type, low, high int64) (column, error) {
+	switch t {
+	case types.StringType:
+		return stringColumn(name), nil
+	case types.BoolType:
+		return func(_ int64, r uint64) types.Field { return types.NewBoolField(r&1 == 1) }, nil
+	case types.FloatType:
+		return floatColumn(name), nil
+	case types.IntType, types.Int32Type, types.Int64Type, types.Uint32Type, types.Uint64Type:
+		ints := intColumn(name, low, high)
+		return func(n int64, r uint64) types.Field { return intField(t, ints(n, r)) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported column type %s", t)
+	}
+}
+
+// intColumn returns the integer values of a column.
+func intColumn(name string, low, high int64) func(n int64, r uint64) int64 {
+	switch {
+	case name == "ID":
+		return func(n int64, _ uint64) int64 { return n }
+	case strings.HasSuffix(name, "_ID"):
+		span := uint64(high-low) + 1
+		return func(_ int64, r uint64) int64 { return low + int64(r%span) }
+	case strings.Contains(name, "AGE"):
+		return func(_ int64, r uint64) int64 { return 18 + int64(r%73) }
+	case strings.Contains(name, "YEAR"):
+		return func(_ int64, r uint64) int64 { return 1970 + int64(r%56) }
+	default:
+		return func(_ int64, r uint64) int64 { return int64(r % 10000) }
+	}
+}
+
+// intField converts v to a field of integer type t, wrapping values that do
+// not fit.
+func intField(t types.Type, v int64) types.Field {
+	switch t {
+	case types.Int32Type:
+		return types.NewInt32Field(int32(v))
+	case types.Int64Type:
+		return types.NewInt64Field(v)
+	case types.Uint32Type:
+		return types.NewUint32Field(uint32(v))
+	case types.Uint64Type:
+		return types.NewUint64Field(uint64(v))
+	default:
+		return types.NewIntField(v)
+	}
+}
+
+// floatColumn returns the generator of a FLOAT column: prices and amounts
+// with two decimals, scores between 0 and 1, and otherwise values in
+// [0, 1000).
+func floatColumn(name string) column {
+	switch {
+	case strings.Contains(name, "PRICE") || strings.Contains(name, "AMOUNT") || strings.Contains(name, "SALARY"):
+		return func(_ int64, r uint64) types.Field {
+			return types.NewFloat64Field(float64(r%1000000) / 100)
+		}
+	case strings.Contains(name, "SCORE") || strings.Contains(name, "RATIO"):
+		return func(_ int64, r uint64) types.Field {
+			return types.NewFloat64Field(float64(r>>11) / (1 << 53))
+		}
+	default:
+		return func(_ int64, r uint64) types.Field {
+			return types.NewFloat64Field(math.Round(float64(r>>11)/(1<<53)*100000) / 100)
+		}
+	}
+}
+
+// stringColumn returns the generator of a STRING column.
+func stringColumn(name string) column {
+	str := func(s string) types.Field { return types.NewStringField(s, types.StringMaxSize) }
+
+	switch {
+	case strings.Contains(name, "EMAIL"):
+		return func(n int64, r uint64) types.Field {
+			first, last := pick(firstNames, r), pick(lastNames, r>>16)
+			return str(strings.ToLower(fmt.Sprintf("%s.%s%d@example.com", first, last, n)))
+		}
+	case strings.Contains(name, "FIRST"):
+		return func(_ int64, r uint64) types.Field { return str(pick(firstNames, r)) }
+	case strings.Contains(name, "LAST"):
+		return func(_ int64, r uint64) types.Field { return str(pick(lastNames, r)) }
+	case strings.Contains(name, "NAME"):
+		return func(_ int64, r uint64) types.Field {
+			return str(pick(firstNames, r) + " " + pick(lastNames, r>>16))
+		}
+	case strings.Contains(name, "CITY"):
+		return func(_ int64, r uint64) types.Field { return str(pick(cities, r)) }
+	case strings.Contains(name, "COUNTRY"):
+		return func(_ int64, r uint64) types.Field { return str(pick(countries, r)) }
+	default:
+		return func(_ int64, r uint64) types.Field {
+			return str(fmt.Sprintf("%s-%d", pick(words, r), (r>>16)%1000))
+		}
+	}
+}
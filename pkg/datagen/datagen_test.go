@@ -0,0 +1,126 @@
+package datagen
+
+import (
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+func newUsersDesc(t *testing.T) *tuple.TupleDescription {
+	t.Helper()
+	td, err := tuple.NewTupleDesc(
+		[]types.Type{types.IntType, types.StringType, types.StringType, types.IntType, types.IntType, types.FloatType, types.BoolType},
+		[]string{"id", "name", "email", "age", "dept_id", "price", "active"},
+	)
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	return td
+}
+
+func readAll(t *testing.T, r *Reader) []*tuple.Tuple {
+	t.Helper()
+	var rows []*tuple.Tuple
+	for {
+		row, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if row == nil {
+			return rows
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestSeries_Len(t *testing.T) {
+	tests := []struct {
+		series Series
+		want   int64
+	}{
+		{Series{1, 10, 1}, 10},
+		{Series{1, 10, 3}, 4},
+		{Series{10, 1, 1}, 0},
+		{Series{10, 1, -2}, 5},
+		{Series{5, 5, 1}, 1},
+		{Series{1, 10, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.series.Len(); got != tt.want {
+			t.Errorf("%s: Len() = %d, want %d", tt.series, got, tt.want)
+		}
+	}
+}
+
+func TestGenerator_IsDeterministic(t *testing.T) {
+	td := newUsersDesc(t)
+	s := Series{Start: 1, Stop: 200, Step: 1}
+
+	g1, err := New(td, s, 42)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	g2, _ := New(td, s, 42)
+	other, _ := New(td, s, 43)
+
+	rows1, rows2 := readAll(t, g1.Reader(s)), readAll(t, g2.Reader(s))
+	if len(rows1) != 200 {
+		t.Fatalf("expected 200 rows, got %d", len(rows1))
+	}
+
+	differs := false
+	for i := range rows1 {
+		if rows1[i].String() != rows2[i].String() {
+			t.Fatalf("row %d differs between generators with the same seed", i)
+		}
+		row, _ := g1.Row(int64(i + 1))
+		if row.String() != rows1[i].String() {
+			t.Fatalf("Row(%d) differs from the row read in order", i+1)
+		}
+		if o, _ := other.Row(int64(i + 1)); o.String() != rows1[i].String() {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("expected a different seed to produce different rows")
+	}
+}
+
+func TestGenerator_FollowsColumnNames(t *testing.T) {
+	td := newUsersDesc(t)
+	s := Series{Start: 100, Stop: 199, Step: 1}
+	g, err := New(td, s, DefaultSeed)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for i, row := range readAll(t, g.Reader(s)) {
+		id, _ := row.GetField(0)
+		if got := id.(*types.IntField).Value; got != int64(100+i) {
+			t.Errorf("row %d: id %d, want %d", i, got, 100+i)
+		}
+		name, _ := row.GetField(1)
+		if !strings.Contains(name.(*types.StringField).Value, " ") {
+			t.Errorf("row %d: expected a full name, got %q", i, name)
+		}
+		email, _ := row.GetField(2)
+		if !strings.HasSuffix(email.(*types.StringField).Value, "@example.com") {
+			t.Errorf("row %d: expected an email address, got %q", i, email)
+		}
+		age, _ := row.GetField(3)
+		if v := age.(*types.IntField).Value; v < 18 || v > 90 {
+			t.Errorf("row %d: age %d out of range", i, v)
+		}
+		dept, _ := row.GetField(4)
+		if v := dept.(*types.IntField).Value; v < 100 || v > 199 {
+			t.Errorf("row %d: dept_id %d outside the series", i, v)
+		}
+	}
+}
+
+func TestNew_RejectsZeroStep(t *testing.T) {
+	if _, err := New(newUsersDesc(t), Series{Start: 1, Stop: 10}, DefaultSeed); err == nil {
+		t.Error("expected an error for a zero step")
+	}
+}
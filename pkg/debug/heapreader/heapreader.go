@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"storemy/pkg/catalog/catalogmanager"
-	"storemy/pkg/catalog/systemtable"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/debug/ui"
 	"storemy/pkg/memory"
@@ -31,12 +30,6 @@ var heapKeys = heapKeyMap{
 	NavigationKeyMap: ui.NavigationKeys,
 }
 
-var (
-	statsTable  = systemtable.Stats.TableName()
-	colsTable   = systemtable.Columns.TableName()
-	tablesTable = systemtable.Tables.TableName()
-)
-
 type tableInfo struct {
 	tableID    primitives.FileID
 	tableName  string
@@ -102,11 +95,7 @@ func initializeHeapReader(dataDir string) tea.Cmd {
 
 		// Collect all user tables (non-catalog tables
 		var tables []tableInfo
-		catalogTableID, _ := cat.GetTableID(tx2, tablesTable)
-		columnsTableID, _ := cat.GetTableID(tx2, colsTable)
-		statsTableID, _ := cat.GetTableID(tx2, statsTable)
-
-		allTableNames, _ := cat.ListAllTables(tx2, true)
+		allTableNames, _ := cat.ListAllTables(tx2)
 		for _, name := range allTableNames {
 			tableID, err := cat.GetTableID(tx2, name)
 			if err != nil {
@@ -114,7 +103,7 @@ func initializeHeapReader(dataDir string) tea.Cmd {
 			}
 
 			// Skip catalog tables
-			if tableID == catalogTableID || tableID == columnsTableID || tableID == statsTableID {
+			if _, err := cat.SystemTabs.GetSysTable(tableID); err == nil {
 				continue
 			}
 
@@ -241,6 +241,22 @@ func (m model) colorizeRecordType(recordType record.LogRecordType) string {
 		color = lipgloss.Color(ui.MutedColor.Dark)
 		icon = "↶"
 		name = "CLR      "
+	case record.FileOpRecord:
+		color = lipgloss.Color(ui.SecondaryColor.Dark)
+		icon = "▤"
+		name = "FILE OP  "
+	case record.FileOpDoneRecord:
+		color = lipgloss.Color(ui.SecondaryColor.Dark)
+		icon = "▣"
+		name = "FILE DONE"
+	case record.BulkLoadRecord:
+		color = lipgloss.Color(ui.PrimaryColor.Dark)
+		icon = "⇣"
+		name = "BULK LOAD"
+	case record.BulkLoadBarrierRecord:
+		color = lipgloss.Color(ui.PrimaryColor.Dark)
+		icon = "▬"
+		name = "BULK SYNC"
 	default:
 		color = lipgloss.Color(ui.MutedColor.Dark)
 		icon = "?"
@@ -297,6 +313,22 @@ func (m model) renderDetailView() string {
 			b.WriteString(m.renderKeyValue("  File ID", fmt.Sprintf("%d", re.PageID.FileID())))
 			b.WriteString(m.renderKeyValue("  Page Number", fmt.Sprintf("%d", re.PageID.PageNo())))
 		}
+
+	case record.FileOpRecord:
+		b.WriteString(ui.LabelStyle.Render("File Operation:") + "\n")
+		b.WriteString(m.renderKeyValue("  Kind", re.FileOp.Kind.String()))
+		b.WriteString(m.renderKeyValue("  Path", re.FileOp.Path.String()))
+		if re.FileOp.NewPath != "" {
+			b.WriteString(m.renderKeyValue("  New Path", re.FileOp.NewPath.String()))
+		}
+
+	case record.BulkLoadRecord, record.BulkLoadBarrierRecord:
+		b.WriteString(ui.LabelStyle.Render("Bulk Load:") + "\n")
+		b.WriteString(m.renderKeyValue("  Path", re.BulkLoad.Path.String()))
+		b.WriteString(m.renderKeyValue("  Start Page", fmt.Sprintf("%d", re.BulkLoad.StartPage)))
+		if re.Type == record.BulkLoadBarrierRecord {
+			b.WriteString(m.renderKeyValue("  End Page", fmt.Sprintf("%d", re.BulkLoad.EndPage)))
+		}
 	}
 
 	return ui.DetailStyle.Render(b.String())
@@ -0,0 +1,219 @@
+// Package encryption implements transparent encryption at rest: page files
+// are encrypted page by page through FS, and the WAL seals each record with
+// Cipher, both with AES-GCM.
+//
+// Keys come from a KeyProvider and are identified by a key ID. Everything
+// sealed records the ID of the key it was sealed with, so rotating to a new
+// key only changes the key used for new writes: data sealed with an older key
+// stays readable as long as the provider still returns it, and is re-sealed
+// with the current key when it is next written or rekeyed.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	KeyIDSize = 4  // ID of the key the data was sealed with (uint32)
+	NonceSize = 12 // Random GCM nonce, new for every seal
+	TagSize   = 16 // GCM authentication tag
+
+	// Overhead is how many bytes sealing adds to its plaintext:
+	//
+	//	[KeyID:4][Nonce:12][Ciphertext][Tag:16]
+	Overhead = KeyIDSize + NonceSize + TagSize
+
+	// CheckSize is the size of a key check (see Cipher.KeyCheck).
+	CheckSize = Overhead + len(keyCheckText)
+
+	keyCheckText = "STOREMY KEYCHECK"
+)
+
+// ErrWrongKey is returned when sealed data fails authentication: it was
+// sealed with a different key than the provider returned for its key ID, or
+// it was modified.
+var ErrWrongKey = errors.New("decryption failed: wrong key or corrupted data")
+
+// KeyProvider supplies the keys data is encrypted with. Implementations
+// must be safe for concurrent use.
+type KeyProvider interface {
+	// CurrentKeyID returns the ID of the key new data is sealed with.
+	CurrentKeyID() uint32
+
+	// Key returns the AES key with the given ID, 16, 24 or 32 bytes long.
+	// Keys that sealed data still on disk or in the WAL must stay available.
+	Key(id uint32) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider that holds its keys in memory.
+type StaticKeys struct {
+	mutex   sync.RWMutex
+	keys    map[uint32][]byte
+	current uint32
+}
+
+// NewStaticKeys returns a provider holding key under id, which is also the
+// current key.
+func NewStaticKeys(id uint32, key []byte) *StaticKeys {
+	return &StaticKeys{
+		keys:    map[uint32][]byte{id: key},
+		current: id,
+	}
+}
+
+// Rotate adds key under id and makes it the current key. The previous keys
+// are kept to read the data they sealed.
+func (s *StaticKeys) Rotate(id uint32, key []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys[id] = key
+	s.current = id
+}
+
+// CurrentKeyID returns the ID of the key passed to the last Rotate, or to
+// NewStaticKeys if it was never called.
+func (s *StaticKeys) CurrentKeyID() uint32 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.current
+}
+
+// Key returns the key with the given ID.
+func (s *StaticKeys) Key(id uint32) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %d not found", id)
+	}
+	return key, nil
+}
+
+// Cipher seals and opens data with AES-GCM using the keys of a KeyProvider.
+// It is safe for concurrent use.
+type Cipher struct {
+	provider KeyProvider
+	mutex    sync.RWMutex
+	aeads    map[uint32]cipher.AEAD // One per key ID, built on first use
+}
+
+// NewCipher returns a Cipher over the keys of provider.
+func NewCipher(provider KeyProvider) *Cipher {
+	return &Cipher{
+		provider: provider,
+		aeads:    make(map[uint32]cipher.AEAD),
+	}
+}
+
+// CurrentKeyID returns the ID of the key Seal uses.
+func (c *Cipher) CurrentKeyID() uint32 {
+	return c.provider.CurrentKeyID()
+}
+
+// Seal encrypts plaintext with the current key and a random nonce, appends
+// the result to dst and returns it. ad is authenticated but not encrypted;
+// the same ad must be passed to Open.
+func (c *Cipher) Seal(dst, plaintext, ad []byte) ([]byte, error) {
+	return c.sealWith(c.provider.CurrentKeyID(), dst, plaintext, ad)
+}
+
+func (c *Cipher) sealWith(id uint32, dst, plaintext, ad []byte) ([]byte, error) {
+	aead, err := c.aead(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var header [KeyIDSize + NonceSize]byte
+	binary.BigEndian.PutUint32(header[:KeyIDSize], id)
+	nonce := header[KeyIDSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	dst = append(dst, header[:]...)
+	return aead.Seal(dst, nonce, plaintext, ad), nil
+}
+
+// Open decrypts data produced by Seal, appends the plaintext to dst and
+// returns it. It returns ErrWrongKey if sealed does not authenticate.
+func (c *Cipher) Open(dst, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < Overhead {
+		return nil, fmt.Errorf("sealed data too short: %d bytes, minimum %d", len(sealed), Overhead)
+	}
+
+	aead, err := c.aead(KeyID(sealed))
+	if err != nil {
+		return nil, err
+	}
+	nonce := sealed[KeyIDSize : KeyIDSize+NonceSize]
+	plaintext, err := aead.Open(dst, nonce, sealed[KeyIDSize+NonceSize:], ad)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plaintext, nil
+}
+
+// KeyID returns the ID of the key sealed was sealed with.
+func KeyID(sealed []byte) uint32 {
+	return binary.BigEndian.Uint32(sealed[:KeyIDSize])
+}
+
+// KeyCheck seals a known text with the key id. Stored next to the data, it
+// lets VerifyKeyCheck tell a wrong key apart from corrupted data before
+// anything else is read.
+func (c *Cipher) KeyCheck(id uint32) ([CheckSize]byte, error) {
+	var check [CheckSize]byte
+	sealed, err := c.sealWith(id, check[:0], []byte(keyCheckText), nil)
+	if err != nil {
+		return check, err
+	}
+	copy(check[:], sealed)
+	return check, nil
+}
+
+// VerifyKeyCheck reports whether the provider's key for the ID in check is
+// the key that produced check.
+func (c *Cipher) VerifyKeyCheck(check [CheckSize]byte) error {
+	plaintext, err := c.Open(nil, check[:], nil)
+	if err != nil {
+		return fmt.Errorf("key %d does not match the database: %w", KeyID(check[:]), err)
+	}
+	if string(plaintext) != keyCheckText {
+		return fmt.Errorf("key %d does not match the database: %w", KeyID(check[:]), ErrWrongKey)
+	}
+	return nil
+}
+
+// aead returns the AES-GCM instance of the key id.
+func (c *Cipher) aead(id uint32) (cipher.AEAD, error) {
+	c.mutex.RLock()
+	aead, ok := c.aeads[id]
+	c.mutex.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	key, err := c.provider.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key %d: %w", id, err)
+	}
+	aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM for key %d: %w", id, err)
+	}
+
+	c.mutex.Lock()
+	c.aeads[id] = aead
+	c.mutex.Unlock()
+	return aead, nil
+}
@@ -0,0 +1,97 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	c := NewCipher(NewStaticKeys(1, testKey(1)))
+	plaintext := []byte("hello, world")
+
+	sealed, err := c.Seal(nil, plaintext, []byte("ad"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if len(sealed) != len(plaintext)+Overhead {
+		t.Errorf("sealed length = %d, expected %d", len(sealed), len(plaintext)+Overhead)
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Error("sealed data contains the plaintext")
+	}
+	if KeyID(sealed) != 1 {
+		t.Errorf("KeyID = %d, expected 1", KeyID(sealed))
+	}
+
+	again, err := c.Seal(nil, plaintext, []byte("ad"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if bytes.Equal(sealed, again) {
+		t.Error("expected a new nonce for every seal")
+	}
+
+	opened, err := c.Open(nil, sealed, []byte("ad"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open = %q, expected %q", opened, plaintext)
+	}
+
+	if _, err := c.Open(nil, sealed, []byte("other")); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey for other additional data, got %v", err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := c.Open(nil, sealed, []byte("ad")); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey for modified data, got %v", err)
+	}
+}
+
+func TestCipher_Rotation(t *testing.T) {
+	keys := NewStaticKeys(1, testKey(1))
+	c := NewCipher(keys)
+
+	old, err := c.Seal(nil, []byte("old"), nil)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	keys.Rotate(2, testKey(2))
+	sealed, err := c.Seal(nil, []byte("new"), nil)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if KeyID(sealed) != 2 {
+		t.Errorf("KeyID after rotation = %d, expected 2", KeyID(sealed))
+	}
+	if opened, err := c.Open(nil, old, nil); err != nil || string(opened) != "old" {
+		t.Errorf("Open of data sealed before rotation = %q, %v", opened, err)
+	}
+}
+
+func TestCipher_KeyCheck(t *testing.T) {
+	c := NewCipher(NewStaticKeys(7, testKey(1)))
+	check, err := c.KeyCheck(7)
+	if err != nil {
+		t.Fatalf("KeyCheck failed: %v", err)
+	}
+	if err := c.VerifyKeyCheck(check); err != nil {
+		t.Errorf("VerifyKeyCheck failed: %v", err)
+	}
+
+	wrong := NewCipher(NewStaticKeys(7, testKey(2)))
+	if err := wrong.VerifyKeyCheck(check); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey, got %v", err)
+	}
+
+	missing := NewCipher(NewStaticKeys(8, testKey(1)))
+	if err := missing.VerifyKeyCheck(check); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
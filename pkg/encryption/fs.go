@@ -0,0 +1,347 @@
+package encryption
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+	"sync"
+)
+
+// BlockSize is the plaintext size of the blocks files are encrypted in. It
+// is the page size, so every page of a page file is sealed on its own.
+const BlockSize = page.PageSize
+
+// slotSize is the on-disk size of a sealed block.
+const slotSize = BlockSize + Overhead
+
+// FS is a vfs.FS whose files are encrypted at rest. Every file is split into
+// blocks of BlockSize bytes, each sealed separately with a new nonce every
+// time it is written, so a page write re-encrypts only that page. A block's
+// number is authenticated with it, so blocks cannot be swapped within a file.
+//
+// Offsets and sizes seen through FS are those of the plaintext. Only the last
+// block of a file may be shorter than BlockSize; a block that was never
+// written, such as a hole left by writing past the end of a file, reads as
+// zeros.
+type FS struct {
+	base   vfs.FS
+	cipher *Cipher
+}
+
+// NewFS returns a file system that encrypts the files it stores on base with
+// cipher.
+func NewFS(base vfs.FS, cipher *Cipher) *FS {
+	return &FS{base: base, cipher: cipher}
+}
+
+// OpenFile opens an encrypted file.
+func (e *FS) OpenFile(name string, flag int, perm fs.FileMode) (vfs.File, error) {
+	file, err := e.base.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &File{file: file, cipher: e.cipher}, nil
+}
+
+// ReadFile returns the plaintext of the named file.
+func (e *FS) ReadFile(name string) ([]byte, error) {
+	f, err := vfs.Open(e, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, info.Size())
+	if _, err := f.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+// WriteFile replaces the named file with data, encrypted.
+func (e *FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := e.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Rename renames a file; its contents do not depend on its name.
+func (e *FS) Rename(oldpath, newpath string) error {
+	return e.base.Rename(oldpath, newpath)
+}
+
+// Remove removes a file.
+func (e *FS) Remove(name string) error {
+	return e.base.Remove(name)
+}
+
+// Stat returns the metadata of a file, with the size of its plaintext.
+func (e *FS) Stat(name string) (fs.FileInfo, error) {
+	info, err := e.base.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return plainInfo{info}, nil
+}
+
+// MkdirAll creates a directory and its parents.
+func (e *FS) MkdirAll(path string, perm fs.FileMode) error {
+	return e.base.MkdirAll(path, perm)
+}
+
+// plainInfo reports the plaintext size of an encrypted file.
+type plainInfo struct {
+	fs.FileInfo
+}
+
+func (i plainInfo) Size() int64 {
+	if i.IsDir() {
+		return i.FileInfo.Size()
+	}
+	return plainSize(i.FileInfo.Size())
+}
+
+// plainSize returns the plaintext size of a file of size bytes on disk.
+func plainSize(size int64) int64 {
+	blocks, rest := size/slotSize, size%slotSize
+	return blocks*BlockSize + max(0, rest-Overhead)
+}
+
+// File is an open file of FS.
+type File struct {
+	file   vfs.File
+	cipher *Cipher
+	mutex  sync.Mutex // Serializes writes, which may read a block first
+}
+
+// Name returns the path the file was opened with.
+func (f *File) Name() string {
+	return f.file.Name()
+}
+
+// Stat returns the file's metadata, with the size of its plaintext.
+func (f *File) Stat() (fs.FileInfo, error) {
+	info, err := f.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return plainInfo{info}, nil
+}
+
+// Sync makes all previous writes durable.
+func (f *File) Sync() error {
+	return f.file.Sync()
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	return f.file.Close()
+}
+
+// ReadAt reads len(p) plaintext bytes starting at off. Like os.File.ReadAt
+// it returns io.EOF when fewer bytes are left.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		block, err := f.readBlock(pos / BlockSize)
+		if err != nil {
+			return n, err
+		}
+		in := int(pos % BlockSize)
+		if in >= len(block) {
+			return n, io.EOF
+		}
+		n += copy(p[n:], block[in:])
+	}
+	return n, nil
+}
+
+// WriteAt writes p as plaintext starting at off, re-sealing every block it
+// touches. A block only partly covered is read and decrypted first.
+func (f *File) WriteAt(p []byte, off int64) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// A write past the end pads a short last block to a whole one, so that
+	// only the last block of the file is ever short
+	size, err := f.size()
+	if err != nil {
+		return 0, err
+	}
+	if last := size / BlockSize; off/BlockSize > last && size%BlockSize != 0 {
+		block, err := f.readBlock(last)
+		if err != nil {
+			return 0, err
+		}
+		if err := f.writeBlock(last, append(block, make([]byte, BlockSize-len(block))...)); err != nil {
+			return 0, err
+		}
+	}
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		blockNo, in := pos/BlockSize, int(pos%BlockSize)
+		chunk := min(BlockSize-in, len(p)-n)
+
+		var block []byte
+		if in == 0 && chunk == BlockSize {
+			block = p[n : n+chunk]
+		} else {
+			if block, err = f.readBlock(blockNo); err != nil {
+				return n, err
+			}
+			if len(block) < in+chunk {
+				block = append(block, make([]byte, in+chunk-len(block))...)
+			}
+			copy(block[in:], p[n:n+chunk])
+		}
+
+		if err := f.writeBlock(blockNo, block); err != nil {
+			return n, err
+		}
+		n += chunk
+	}
+	return n, nil
+}
+
+// Truncate changes the plaintext size of the file.
+func (f *File) Truncate(size int64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	blockNo, rest := size/BlockSize, int(size%BlockSize)
+	if rest == 0 {
+		return f.file.Truncate(blockNo * slotSize)
+	}
+
+	block, err := f.readBlock(blockNo)
+	if err != nil {
+		return err
+	}
+	if len(block) < rest {
+		block = append(block, make([]byte, rest-len(block))...)
+	}
+	if err := f.file.Truncate(blockNo * slotSize); err != nil {
+		return err
+	}
+	return f.writeBlock(blockNo, block[:rest])
+}
+
+// Rekey re-seals with the current key every block sealed with another one,
+// and returns how many it re-sealed.
+func (f *File) Rekey() (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	current := f.cipher.CurrentKeyID()
+	rekeyed := 0
+	for blockNo := int64(0); blockNo*slotSize < info.Size(); blockNo++ {
+		sealed, err := f.readSealed(blockNo)
+		if err != nil {
+			return rekeyed, err
+		}
+		if sealed == nil || KeyID(sealed) == current {
+			continue
+		}
+
+		block, err := f.open(blockNo, sealed)
+		if err != nil {
+			return rekeyed, err
+		}
+		if err := f.writeBlock(blockNo, block); err != nil {
+			return rekeyed, err
+		}
+		rekeyed++
+	}
+	return rekeyed, nil
+}
+
+// size returns the plaintext size of the file.
+func (f *File) size() (int64, error) {
+	info, err := f.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return plainSize(info.Size()), nil
+}
+
+// readSealed returns the sealed bytes of a block as stored, or nil if the
+// block is past the end of the file or was never written.
+func (f *File) readSealed(blockNo int64) ([]byte, error) {
+	sealed := make([]byte, slotSize)
+	n, err := f.file.ReadAt(sealed, blockNo*slotSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	sealed = sealed[:n]
+
+	for _, b := range sealed {
+		if b != 0 {
+			return sealed, nil
+		}
+	}
+	return nil, nil
+}
+
+// readBlock returns the plaintext of a block: empty past the end of the
+// file, and zeros for a block that was never written.
+func (f *File) readBlock(blockNo int64) ([]byte, error) {
+	sealed, err := f.readSealed(blockNo)
+	if err != nil {
+		return nil, err
+	}
+	if sealed == nil {
+		size, err := f.size()
+		if err != nil {
+			return nil, err
+		}
+		return make([]byte, min(BlockSize, max(0, size-blockNo*BlockSize))), nil
+	}
+	return f.open(blockNo, sealed)
+}
+
+func (f *File) open(blockNo int64, sealed []byte) ([]byte, error) {
+	block, err := f.cipher.Open(nil, sealed, blockAD(blockNo))
+	if err != nil {
+		return nil, fmt.Errorf("block %d of %s: %w", blockNo, f.file.Name(), err)
+	}
+	return block, nil
+}
+
+// writeBlock seals a block's plaintext with the current key and writes it.
+func (f *File) writeBlock(blockNo int64, block []byte) error {
+	sealed, err := f.cipher.Seal(make([]byte, 0, len(block)+Overhead), block, blockAD(blockNo))
+	if err != nil {
+		return err
+	}
+	_, err = f.file.WriteAt(sealed, blockNo*slotSize)
+	return err
+}
+
+// blockAD is the additional data a block is sealed with: its block number.
+func blockAD(blockNo int64) []byte {
+	var ad [8]byte
+	binary.BigEndian.PutUint64(ad[:], uint64(blockNo))
+	return ad[:]
+}
@@ -0,0 +1,163 @@
+package encryption
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"storemy/pkg/vfs"
+	"testing"
+)
+
+func setupFS(t *testing.T) (*FS, *vfs.MemFS, *StaticKeys) {
+	t.Helper()
+	mem := vfs.NewMemFS()
+	keys := NewStaticKeys(1, testKey(1))
+	return NewFS(mem, NewCipher(keys)), mem, keys
+}
+
+func openTestFile(t *testing.T, fsys vfs.FS, name string) vfs.File {
+	t.Helper()
+	f, err := fsys.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestFS_PagesAreEncrypted(t *testing.T) {
+	efs, mem, _ := setupFS(t)
+	f := openTestFile(t, efs, "/table.dat")
+
+	pageData := bytes.Repeat([]byte("secret!!"), BlockSize/8)
+	for pageNo := int64(0); pageNo < 3; pageNo++ {
+		if _, err := f.WriteAt(pageData, pageNo*BlockSize); err != nil {
+			t.Fatalf("WriteAt failed: %v", err)
+		}
+	}
+
+	raw, err := mem.ReadFile("/table.dat")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(raw) != 3*slotSize {
+		t.Errorf("file size on disk = %d, expected %d", len(raw), 3*slotSize)
+	}
+	if bytes.Contains(raw, []byte("secret!!")) {
+		t.Error("file on disk contains plaintext")
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size() != 3*BlockSize {
+		t.Errorf("Stat size = %d, expected %d", info.Size(), 3*BlockSize)
+	}
+
+	got := make([]byte, BlockSize)
+	if _, err := f.ReadAt(got, BlockSize); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	if !bytes.Equal(got, pageData) {
+		t.Error("page read back differs from page written")
+	}
+	if _, err := f.ReadAt(got, 3*BlockSize); err != io.EOF {
+		t.Errorf("expected io.EOF past the end, got %v", err)
+	}
+}
+
+func TestFS_PartialWritesAndHoles(t *testing.T) {
+	efs, _, _ := setupFS(t)
+	f := openTestFile(t, efs, "/log.dat")
+
+	if _, err := f.WriteAt([]byte("hello"), 0); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+	if _, err := f.WriteAt([]byte(" world"), 5); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+	// Spans a block boundary and leaves block 1 unwritten
+	if _, err := f.WriteAt([]byte("xyz"), 3*BlockSize-1); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+
+	data, err := efs.ReadFile("/log.dat")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(data) != 3*BlockSize+2 {
+		t.Fatalf("plaintext size = %d, expected %d", len(data), 3*BlockSize+2)
+	}
+	if string(data[:11]) != "hello world" || string(data[3*BlockSize-1:]) != "xyz" {
+		t.Errorf("unexpected contents %q ... %q", data[:11], data[3*BlockSize-1:])
+	}
+	if !bytes.Equal(data[11:3*BlockSize-1], make([]byte, 3*BlockSize-12)) {
+		t.Error("expected zeros between the writes")
+	}
+
+	if err := f.Truncate(7); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	if data, _ := efs.ReadFile("/log.dat"); string(data) != "hello w" {
+		t.Errorf("after Truncate: %q", data)
+	}
+}
+
+func TestFS_SwappedBlocksFail(t *testing.T) {
+	efs, mem, _ := setupFS(t)
+	f := openTestFile(t, efs, "/table.dat")
+	for i := byte(0); i < 2; i++ {
+		if _, err := f.WriteAt(bytes.Repeat([]byte{i + 1}, BlockSize), int64(i)*BlockSize); err != nil {
+			t.Fatalf("WriteAt failed: %v", err)
+		}
+	}
+
+	raw, _ := mem.ReadFile("/table.dat")
+	swapped := append(append([]byte{}, raw[slotSize:]...), raw[:slotSize]...)
+	if err := mem.WriteFile("/table.dat", swapped, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if _, err := efs.ReadFile("/table.dat"); err == nil {
+		t.Error("expected reading swapped blocks to fail")
+	}
+}
+
+func TestFile_Rekey(t *testing.T) {
+	efs, mem, keys := setupFS(t)
+	f := openTestFile(t, efs, "/table.dat")
+	pageData := bytes.Repeat([]byte{7}, BlockSize)
+	for pageNo := int64(0); pageNo < 2; pageNo++ {
+		if _, err := f.WriteAt(pageData, pageNo*BlockSize); err != nil {
+			t.Fatalf("WriteAt failed: %v", err)
+		}
+	}
+
+	keys.Rotate(2, testKey(2))
+	if _, err := f.WriteAt(pageData, 0); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+
+	rekeyed, err := f.(*File).Rekey()
+	if err != nil {
+		t.Fatalf("Rekey failed: %v", err)
+	}
+	if rekeyed != 1 {
+		t.Errorf("Rekey re-sealed %d blocks, expected 1", rekeyed)
+	}
+
+	raw, _ := mem.ReadFile("/table.dat")
+	for blockNo := 0; blockNo < 2; blockNo++ {
+		if id := KeyID(raw[blockNo*slotSize:]); id != 2 {
+			t.Errorf("block %d sealed with key %d, expected 2", blockNo, id)
+		}
+	}
+
+	// Only the new key is needed from now on
+	only := NewFS(mem, NewCipher(NewStaticKeys(2, testKey(2))))
+	data, err := only.ReadFile("/table.dat")
+	if err != nil || !bytes.Equal(data[:BlockSize], pageData) {
+		t.Errorf("ReadFile with the new key only: %v", err)
+	}
+}
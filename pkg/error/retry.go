@@ -0,0 +1,45 @@
+package error
+
+import "errors"
+
+// Codes of the conflicts between transactions that IsRetryable reports, for
+// the packages that raise them
+const (
+	// ErrCodeDeadlock indicates a transaction was chosen to break a deadlock
+	ErrCodeDeadlock = "DEADLOCK_DETECTED"
+
+	// ErrCodeSerializationFailure indicates a transaction could not be
+	// serialized with the transactions running alongside it
+	ErrCodeSerializationFailure = "SERIALIZATION_FAILURE"
+
+	// ErrCodeLockTimeout indicates a lock was not granted before its timeout
+	ErrCodeLockTimeout = "LOCK_TIMEOUT"
+)
+
+// retryableCodes are the codes of the errors a transaction may succeed
+// after if it is run again from the start.
+var retryableCodes = map[string]bool{
+	ErrCodeDeadlock:             true,
+	ErrCodeSerializationFailure: true,
+	ErrCodeLockTimeout:          true,
+}
+
+// Retryable reports whether the transaction that failed with e may succeed
+// if it is aborted and run again: e is a deadlock, serialization failure or
+// lock timeout, or another conflict in ErrCategoryConcurrency. Such errors
+// depend on the transactions running alongside, not on the statement.
+func (e *DBError) Retryable() bool {
+	return retryableCodes[e.Code] || e.Category == ErrCategoryConcurrency
+}
+
+// IsRetryable reports whether any DBError in err's chain is Retryable.
+// Errors wrapping a conflict, such as the EXEC_ERROR of a statement that hit
+// a deadlock, are retryable too.
+func IsRetryable(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if dbErr, ok := err.(*DBError); ok && dbErr.Retryable() {
+			return true
+		}
+	}
+	return false
+}
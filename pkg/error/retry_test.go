@@ -0,0 +1,37 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRetryable(t *testing.T) {
+	deadlock := New(ErrCategoryConcurrency, ErrCodeDeadlock, "deadlock detected for transaction 7")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("boom"), false},
+		{"deadlock", deadlock, true},
+		{"lock timeout", New(ErrCategoryTransient, ErrCodeLockTimeout, "lock not granted"), true},
+		{"serialization failure", New(ErrCategoryConcurrency, ErrCodeSerializationFailure, "conflict"), true},
+		{"other conflict", New(ErrCategoryConcurrency, "SOMETHING_NEW", "conflict"), true},
+		{"wrapped conflict", Wrap(fmt.Errorf("scan: %w", deadlock), "EXEC_ERROR", "ExecuteQuery", "Executor"), true},
+		{"user error", New(ErrCategoryUser, "UNIQUE_VIOLATION", "duplicate key"), false},
+		{"transient resource error", New(ErrCategoryTransient, "ADMISSION_REJECTED", "too many queries"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := SQLState(deadlock); got != SQLStateDeadlockDetected {
+		t.Errorf("SQLState() of a deadlock = %s, want %s", got, SQLStateDeadlockDetected)
+	}
+}
@@ -0,0 +1,225 @@
+package error
+
+import "errors"
+
+// SQLSTATE codes returned by SQLState. The first two characters are the
+// class of the error, which is what drivers and clients usually branch on;
+// the codes follow PostgreSQL where it has an equivalent.
+const (
+	SQLStateSuccessful = "00000"
+
+	// Class 22: data exception
+	SQLStateDataException     = "22000"
+	SQLStateNumericOutOfRange = "22003"
+	SQLStateInvalidParameter  = "22023"
+
+	// Class 23: integrity constraint violation
+	SQLStateIntegrityViolation  = "23000"
+	SQLStateNotNullViolation    = "23502"
+	SQLStateForeignKeyViolation = "23503"
+	SQLStateUniqueViolation     = "23505"
+	SQLStateCheckViolation      = "23514"
+
+	// Class 25: invalid transaction state
+	SQLStateInvalidTransactionState = "25000"
+	SQLStateReadOnlyTransaction     = "25006"
+	SQLStateNoActiveTransaction     = "25P01"
+
+	// Class 28: invalid authorization specification
+	SQLStateInvalidAuthorization = "28000"
+
+	// Class 3D: invalid catalog name
+	SQLStateInvalidCatalogName = "3D000"
+
+	// Class 40: transaction rollback
+	SQLStateTransactionRollback  = "40000"
+	SQLStateSerializationFailure = "40001"
+	SQLStateDeadlockDetected     = "40P01"
+
+	// Class 42: syntax error or access rule violation
+	SQLStateSyntaxOrAccessRule = "42000"
+	SQLStateSyntaxError        = "42601"
+	SQLStateInvalidName        = "42602"
+	SQLStateUndefinedColumn    = "42703"
+	SQLStateUndefinedObject    = "42704"
+	SQLStateDuplicateObject    = "42710"
+	SQLStateUndefinedTable     = "42P01"
+	SQLStateUndefinedParameter = "42P02"
+	SQLStateDuplicateDatabase  = "42P04"
+	SQLStateDuplicateTable     = "42P07"
+	SQLStateInvalidDefinition  = "42P17"
+
+	// Class 53: insufficient resources
+	SQLStateInsufficientResources = "53000"
+	SQLStateDiskFull              = "53100"
+	SQLStateOutOfMemory           = "53200"
+	SQLStateTooManyConnections    = "53300"
+	SQLStateLimitExceeded         = "53400"
+
+	// Class 54: program limit exceeded
+	SQLStateProgramLimitExceeded = "54000"
+
+	// Class 55: object not in prerequisite state
+	SQLStateNotInPrerequisiteState = "55000"
+	SQLStateLockNotAvailable       = "55P03"
+	SQLStateCantChangeParameter    = "55P02"
+
+	// Class 57: operator intervention
+	SQLStateOperatorIntervention = "57000"
+	SQLStateAdminShutdown        = "57P01"
+
+	// Class 58: system error
+	SQLStateSystemError = "58000"
+	SQLStateIOError     = "58030"
+
+	// Class XX: internal error
+	SQLStateInternalError = "XX000"
+	SQLStateDataCorrupted = "XX001"
+)
+
+// codeStates maps the stable error codes of the engine to their SQLSTATE.
+// Codes that only name the step that failed, such as EXEC_ERROR or
+// COMMIT_FAILED, are left out so that the code of the error that caused
+// them decides; codes found nowhere in a chain fall back to the SQLSTATE of
+// their category.
+var codeStates = map[string]string{
+	// Parsing, planning and statements
+	"PARSE_ERROR":          SQLStateSyntaxError,
+	"BIND_ERROR":           SQLStateUndefinedParameter,
+	"TABLE_NOT_FOUND":      SQLStateUndefinedTable,
+	"INVALID_MIGRATION":    SQLStateInvalidDefinition,
+	"INVALID_REWRITE_RULE": SQLStateInvalidDefinition,
+
+	// Transactions
+	"TX_NOT_ACTIVE":             SQLStateNoActiveTransaction,
+	"READ_ONLY_VIOLATION":       SQLStateReadOnlyTransaction,
+	ErrCodeDeadlock:             SQLStateDeadlockDetected,
+	ErrCodeSerializationFailure: SQLStateSerializationFailure,
+	ErrCodeLockTimeout:          SQLStateLockNotAvailable,
+
+	// Constraints
+	"NOT_NULL_VIOLATION":          SQLStateNotNullViolation,
+	"UNIQUE_VIOLATION":            SQLStateUniqueViolation,
+	"PRIMARY_KEY_VIOLATION":       SQLStateUniqueViolation,
+	"FOREIGN_KEY_VIOLATION":       SQLStateForeignKeyViolation,
+	"CHECK_VIOLATION":             SQLStateCheckViolation,
+	"CONSTRAINT_VALIDATION_ERROR": SQLStateIntegrityViolation,
+	"CONSTRAINT_NOT_FOUND":        SQLStateUndefinedObject,
+	"CONSTRAINT_EXISTS":           SQLStateDuplicateObject,
+	"INVALID_CONSTRAINT":          SQLStateInvalidDefinition,
+
+	// Resources and limits
+	"QUOTA_EXCEEDED":         SQLStateLimitExceeded,
+	"TEMP_QUOTA_EXCEEDED":    SQLStateLimitExceeded,
+	"OUT_OF_MEMORY_BUDGET":   SQLStateOutOfMemory,
+	"ADMISSION_REJECTED":     SQLStateTooManyConnections,
+	"DISK_SPACE_LOW":         SQLStateDiskFull,
+	"TRIGGER_DEPTH_EXCEEDED": SQLStateProgramLimitExceeded,
+	"QUERY_PANIC":            SQLStateInternalError,
+
+	// Databases and settings
+	"DATABASE_CLOSED":        SQLStateAdminShutdown,
+	"SHUTDOWN_TIMEOUT":       SQLStateAdminShutdown,
+	"DATABASE_NOT_FOUND":     SQLStateInvalidCatalogName,
+	"DB_NOT_FOUND":           SQLStateInvalidCatalogName,
+	"DATABASE_EXISTS":        SQLStateDuplicateDatabase,
+	"INVALID_DATABASE_NAME":  SQLStateInvalidName,
+	"UNKNOWN_SETTING":        SQLStateUndefinedObject,
+	"INVALID_SETTING":        SQLStateInvalidParameter,
+	"READ_ONLY_SETTING":      SQLStateCantChangeParameter,
+	"CORRUPT_SUPERBLOCK":     SQLStateDataCorrupted,
+	"ENCRYPTION_KEY_INVALID": SQLStateInvalidAuthorization,
+
+	// WAL
+	"WAL_IO_ERROR":          SQLStateIOError,
+	"WAL_CORRUPT":           SQLStateDataCorrupted,
+	"WAL_READ_ONLY":         SQLStateReadOnlyTransaction,
+	"WAL_TX_NOT_FOUND":      SQLStateInternalError,
+	"WAL_LSN_OUT_OF_RANGE":  SQLStateNumericOutOfRange,
+	"WAL_KEY_MISSING":       SQLStateInvalidAuthorization,
+	"UNKNOWN_STANDBY":       SQLStateUndefinedObject,
+	"INVALID_WAL_CONFIG":    SQLStateInvalidParameter,
+	"WAL_CHECKPOINT_FAILED": SQLStateSystemError,
+	"WAL_ARCHIVE_FAILED":    SQLStateIOError,
+
+	// Recovery
+	"RECOVERY_FAILED":  SQLStateSystemError,
+	"RECOVERY_CORRUPT": SQLStateDataCorrupted,
+
+	// Catalog
+	"CATALOG_IO_ERROR":           SQLStateIOError,
+	"CATALOG_CORRUPT":            SQLStateDataCorrupted,
+	"CATALOG_VERSION_TOO_NEW":    SQLStateNotInPrerequisiteState,
+	"CATALOG_TABLE_NOT_FOUND":    SQLStateUndefinedTable,
+	"CATALOG_TABLE_EXISTS":       SQLStateDuplicateTable,
+	"CATALOG_OBJECT_NOT_FOUND":   SQLStateUndefinedObject,
+	"CATALOG_OBJECT_EXISTS":      SQLStateDuplicateObject,
+	"CATALOG_COLUMN_NOT_FOUND":   SQLStateUndefinedColumn,
+	"CATALOG_INVALID_DEFINITION": SQLStateInvalidDefinition,
+
+	// Heap
+	"HEAP_IO_ERROR":        SQLStateIOError,
+	"HEAP_PAGE_CORRUPT":    SQLStateDataCorrupted,
+	"HEAP_PAGE_FULL":       SQLStateInsufficientResources,
+	"HEAP_TUPLE_INVALID":   SQLStateDataException,
+	"HEAP_TUPLE_NOT_FOUND": SQLStateUndefinedObject,
+	"HEAP_INVALID_STATE":   SQLStateInternalError,
+}
+
+// categoryStates is the SQLSTATE of an error whose code has none.
+var categoryStates = map[ErrorCategory]string{
+	ErrCategoryUser:        SQLStateSyntaxOrAccessRule,
+	ErrCategoryTransient:   SQLStateInsufficientResources,
+	ErrCategorySystem:      SQLStateSystemError,
+	ErrCategoryData:        SQLStateDataCorrupted,
+	ErrCategoryConcurrency: SQLStateSerializationFailure,
+}
+
+// SQLState returns the SQLSTATE of e: the one of its code if it has one,
+// otherwise the one of the first error in its chain whose code has one, and
+// otherwise the one of its category.
+func (e *DBError) SQLState() string {
+	for err := error(e); err != nil; err = errors.Unwrap(err) {
+		if dbErr, ok := err.(*DBError); ok {
+			if state, ok := codeStates[dbErr.Code]; ok {
+				return state
+			}
+		}
+	}
+	if state, ok := categoryStates[e.Category]; ok {
+		return state
+	}
+	return SQLStateInternalError
+}
+
+// SQLState returns the SQLSTATE of err, so that drivers and clients can
+// branch on its class: SQLStateSuccessful for nil, the SQLSTATE of the first
+// DBError in its chain, or SQLStateInternalError if it has none.
+func SQLState(err error) string {
+	if err == nil {
+		return SQLStateSuccessful
+	}
+	var dbErr *DBError
+	if errors.As(err, &dbErr) {
+		return dbErr.SQLState()
+	}
+	return SQLStateInternalError
+}
+
+// SQLStateClass returns the class of a SQLSTATE, its first two characters.
+func SQLStateClass(state string) string {
+	if len(state) < 2 {
+		return ""
+	}
+	return state[:2]
+}
+
+// Code returns the code of the first DBError in err's chain, or "" if there
+// is none.
+func Code(err error) string {
+	var dbErr *DBError
+	if errors.As(err, &dbErr) {
+		return dbErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSQLState(t *testing.T) {
+	notFound := New(ErrCategoryUser, "CATALOG_TABLE_NOT_FOUND", "table users not found")
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, SQLStateSuccessful},
+		{"plain error", errors.New("boom"), SQLStateInternalError},
+		{"known code", New(ErrCategoryUser, "UNIQUE_VIOLATION", "duplicate key"), SQLStateUniqueViolation},
+		{"wrapped by fmt", fmt.Errorf("insert failed: %w", notFound), SQLStateUndefinedTable},
+		{"step code defers to its cause", Wrap(fmt.Errorf("plan: %w", notFound), "EXEC_ERROR", "ExecuteQuery", "Executor"), SQLStateUndefinedTable},
+		{"unknown code falls back to category", New(ErrCategoryConcurrency, "SOMETHING_NEW", "conflict"), SQLStateSerializationFailure},
+		{"step code without cause", Wrap(errors.New("boom"), "EXEC_ERROR", "ExecuteQuery", "Executor"), SQLStateSystemError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SQLState(tt.err); got != tt.want {
+				t.Errorf("SQLState() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLStateClassAndCode(t *testing.T) {
+	if got := SQLStateClass(SQLStateUniqueViolation); got != "23" {
+		t.Errorf("SQLStateClass(%s) = %s, want 23", SQLStateUniqueViolation, got)
+	}
+	if got := SQLStateClass(""); got != "" {
+		t.Errorf("SQLStateClass(\"\") = %q, want empty", got)
+	}
+
+	err := fmt.Errorf("context: %w", New(ErrCategoryUser, "PARSE_ERROR", "bad syntax"))
+	if got := Code(err); got != "PARSE_ERROR" {
+		t.Errorf("Code() = %q, want PARSE_ERROR", got)
+	}
+	if got := Code(errors.New("boom")); got != "" {
+		t.Errorf("Code() of a plain error = %q, want empty", got)
+	}
+}
@@ -40,4 +40,7 @@ type Aggregator interface {
 	// InitializeDefault initializes the default group for non-grouped aggregates
 	// This is used when there are no input tuples to ensure COUNT(*) returns 0
 	InitializeDefault() error
+
+	// NumGroups returns the number of groups built so far
+	NumGroups() int
 }
@@ -0,0 +1,279 @@
+package aggregation
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// GroupingSetsOperator computes an aggregate for several grouping sets, as
+// GROUP BY GROUPING SETS, ROLLUP and CUBE need. Each grouping set either
+// groups by the grouping field or is the empty set, which aggregates all
+// rows into one group, as an aggregate without GROUP BY does.
+//
+// The input is read once, however many sets there are: every tuple is merged
+// into one aggregator per set, and the results of the sets are output in
+// order once the input is exhausted.
+//
+// Output tuples have the schema of an AggregateOperator grouping by the
+// grouping field, (group, aggregate), with a NULL group for the rows of the
+// empty sets; without a grouping field, every set must be empty and the
+// schema is (aggregate). If requested, a GROUPING column follows: 1 for the
+// rows of the empty sets, whose group was aggregated away, and 0 otherwise.
+//
+// Complexity:
+//   - Time: O(input rows × sets)
+//   - Space: O(groups of all sets)
+type GroupingSetsOperator struct {
+	base           *iterator.BaseIterator
+	source         iterator.DbIterator
+	aggregateField primitives.ColumnID
+	groupByField   primitives.ColumnID
+	sets           []bool // Whether each set groups by groupByField
+	aggregators    []Aggregator
+	withGrouping   bool
+	tupleDesc      *tuple.TupleDescription
+
+	results []*tuple.Tuple // Output rows, computed by Open
+	pos     int
+	memory  membudget.Account // Memory held by the groups of all sets
+}
+
+// NewGroupingSetsOperator creates an operator computing op over
+// aggregateField for each grouping set of sets, which is true for a set
+// grouping by groupByField and false for the empty set. groupByField may be
+// NoGrouping if every set is empty. If withGrouping is set, the output has a
+// GROUPING column.
+func NewGroupingSetsOperator(source iterator.DbIterator, aggregateField, groupByField primitives.ColumnID, op AggregateOp, sets []bool, withGrouping bool) (*GroupingSetsOperator, error) {
+	if err := validateInputs(source, aggregateField, groupByField); err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("at least one grouping set is required")
+	}
+
+	sourceDesc := source.GetTupleDesc()
+	var gbFieldType types.Type
+	if groupByField != NoGrouping {
+		gbFieldType = sourceDesc.Types[groupByField]
+	}
+
+	g := &GroupingSetsOperator{
+		source:         source,
+		aggregateField: aggregateField,
+		groupByField:   groupByField,
+		sets:           sets,
+		withGrouping:   withGrouping,
+	}
+
+	for _, grouped := range sets {
+		setField := NoGrouping
+		if grouped {
+			if groupByField == NoGrouping {
+				return nil, fmt.Errorf("a grouping set cannot group without a grouping field")
+			}
+			setField = groupByField
+		}
+		aggregator, err := createAggregator(sourceDesc.Types[aggregateField], gbFieldType, setField, aggregateField, op)
+		if err != nil {
+			return nil, err
+		}
+		g.aggregators = append(g.aggregators, aggregator)
+	}
+
+	var err error
+	g.tupleDesc, err = g.createTupleDesc(sourceDesc.Types[aggregateField], gbFieldType, op)
+	if err != nil {
+		return nil, err
+	}
+
+	g.base = iterator.NewBaseIterator(g.readNext)
+	return g, nil
+}
+
+// createTupleDesc returns the output schema: that of an aggregator grouping
+// by the grouping field, if any, followed by the GROUPING column if wanted.
+func (g *GroupingSetsOperator) createTupleDesc(aggFieldType, gbFieldType types.Type, op AggregateOp) (*tuple.TupleDescription, error) {
+	aggregator, err := createAggregator(aggFieldType, gbFieldType, g.groupByField, g.aggregateField, op)
+	if err != nil {
+		return nil, err
+	}
+	desc := aggregator.GetTupleDesc()
+	if !g.withGrouping {
+		return desc, nil
+	}
+
+	fieldTypes := append(append([]types.Type{}, desc.Types...), types.IntType)
+	names := append(append([]string{}, desc.FieldNames...), "GROUPING")
+	return tuple.NewTupleDesc(fieldTypes, names)
+}
+
+// SetMemoryTracker accounts the groups of all sets against the query's
+// memory budget, so too many distinct keys fail with
+// membudget.ErrOutOfMemoryBudget.
+func (g *GroupingSetsOperator) SetMemoryTracker(t *membudget.Tracker) {
+	g.memory.SetTracker(t)
+}
+
+// Open reads the whole input into the aggregators of the sets and computes
+// the output rows.
+func (g *GroupingSetsOperator) Open() error {
+	if err := g.source.Open(); err != nil {
+		return fmt.Errorf("failed to open source iterator: %w", err)
+	}
+
+	tupleCount := 0
+	err := iterator.ForEach(g.source, func(t *tuple.Tuple) error {
+		tupleCount++
+		for i, aggregator := range g.aggregators {
+			groups := aggregator.NumGroups()
+			if err := aggregator.Merge(t); err != nil {
+				return fmt.Errorf("error merging tuple: %v", err)
+			}
+			if aggregator.NumGroups() > groups {
+				if err := g.reserveGroup(t, g.sets[i]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	g.results = nil
+	for i, aggregator := range g.aggregators {
+		// Like an aggregate without GROUP BY, an empty set has a row even
+		// without input, e.g. a COUNT of 0
+		if tupleCount == 0 && !g.sets[i] {
+			if err := aggregator.InitializeDefault(); err != nil {
+				return fmt.Errorf("failed to initialize default group: %v", err)
+			}
+		}
+		if err := g.collectResults(aggregator, g.sets[i]); err != nil {
+			return err
+		}
+	}
+
+	g.pos = 0
+	g.base.MarkOpened()
+	return nil
+}
+
+// reserveGroup reserves memory for the group t just started in a set.
+func (g *GroupingSetsOperator) reserveGroup(t *tuple.Tuple, grouped bool) error {
+	size := int64(groupStateSize)
+	if grouped {
+		key, _ := t.GetField(g.groupByField)
+		size += membudget.FieldSize(key)
+	}
+
+	if err := g.memory.Reserve(size); err != nil {
+		return fmt.Errorf("cannot add aggregation group: %w", err)
+	}
+	return nil
+}
+
+// collectResults appends the rows of one set's aggregator to the output,
+// converted to the output schema.
+func (g *GroupingSetsOperator) collectResults(aggregator Aggregator, grouped bool) error {
+	it := aggregator.Iterator()
+	if err := it.Open(); err != nil {
+		return fmt.Errorf("failed to open aggregate iterator: %v", err)
+	}
+	defer it.Close()
+
+	return iterator.ForEach(it, func(row *tuple.Tuple) error {
+		result, err := g.outputTuple(row, grouped)
+		if err != nil {
+			return err
+		}
+		g.results = append(g.results, result)
+		return nil
+	})
+}
+
+// outputTuple converts a row of a set's aggregator, (group, aggregate) or
+// (aggregate), to the output schema.
+func (g *GroupingSetsOperator) outputTuple(row *tuple.Tuple, grouped bool) (*tuple.Tuple, error) {
+	result := tuple.NewTuple(g.tupleDesc)
+	aggIndex := primitives.ColumnID(0)
+	if g.groupByField != NoGrouping {
+		aggIndex = 1
+	}
+
+	// The row of an empty set leaves the group NULL
+	if grouped {
+		if err := copyField(result, 0, row, 0); err != nil {
+			return nil, err
+		}
+	}
+	if err := copyField(result, aggIndex, row, row.TupleDesc.NumFields()-1); err != nil {
+		return nil, err
+	}
+
+	if g.withGrouping {
+		grouping := int64(1)
+		if grouped {
+			grouping = 0
+		}
+		if err := result.SetField(aggIndex+1, types.NewIntField(grouping)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// copyField sets field i of dst to field j of src, leaving it NULL if that is.
+func copyField(dst *tuple.Tuple, i primitives.ColumnID, src *tuple.Tuple, j primitives.ColumnID) error {
+	field, err := src.GetField(j)
+	if err != nil || field == nil {
+		return err
+	}
+	return dst.SetField(i, field)
+}
+
+// readNext returns the next output row.
+func (g *GroupingSetsOperator) readNext() (*tuple.Tuple, error) {
+	if g.pos >= len(g.results) {
+		return nil, nil
+	}
+	result := g.results[g.pos]
+	g.pos++
+	return result, nil
+}
+
+// Rewind restarts the output without aggregating again.
+func (g *GroupingSetsOperator) Rewind() error {
+	g.pos = 0
+	g.base.ClearCache()
+	return nil
+}
+
+// Close closes the input and releases the groups.
+func (g *GroupingSetsOperator) Close() error {
+	errs := []error{g.source.Close(), g.base.Close()}
+	g.results = nil
+	g.memory.ReleaseAll()
+	return errors.Join(errs...)
+}
+
+// GetTupleDesc returns the output schema.
+func (g *GroupingSetsOperator) GetTupleDesc() *tuple.TupleDescription {
+	return g.tupleDesc
+}
+
+// HasNext checks if more tuples are available.
+func (g *GroupingSetsOperator) HasNext() (bool, error) {
+	return g.base.HasNext()
+}
+
+// Next returns the next tuple.
+func (g *GroupingSetsOperator) Next() (*tuple.Tuple, error) {
+	return g.base.Next()
+}
@@ -0,0 +1,112 @@
+package aggregation
+
+import (
+	"slices"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"strings"
+	"testing"
+)
+
+// groupingSetsRows runs a GroupingSetsOperator and returns its rows as
+// "field/field/..." strings, with NULL for NULL fields, sorted.
+func groupingSetsRows(t *testing.T, g *GroupingSetsOperator) []string {
+	t.Helper()
+	if err := g.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer g.Close()
+
+	var rows []string
+	for {
+		hasNext, err := g.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			break
+		}
+		row, err := g.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		rows = append(rows, formatRow(row))
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+func formatRow(row *tuple.Tuple) string {
+	fields := make([]string, row.TupleDesc.NumFields())
+	for i := range fields {
+		field, _ := row.GetField(primitives.ColumnID(i))
+		if field == nil {
+			fields[i] = "NULL"
+		} else {
+			fields[i] = field.String()
+		}
+	}
+	return strings.Join(fields, "/")
+}
+
+func TestGroupingSetsOperator_Rollup(t *testing.T) {
+	td := createTestTupleDesc()
+
+	tests := []struct {
+		name         string
+		sets         []bool
+		withGrouping bool
+		expected     []string
+	}{
+		{"rollup", []bool{true, false}, false, []string{"A/30", "B/45", "C/30", "NULL/105"}},
+		{"with GROUPING", []bool{true, false}, true, []string{"A/30/0", "B/45/0", "C/30/0", "NULL/105/1"}},
+		{"repeated empty set", []bool{false, false}, false, []string{"NULL/105", "NULL/105"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newMockIterator(createTestTuples(), td)
+			g, err := NewGroupingSetsOperator(source, 1, 0, Sum, tt.sets, tt.withGrouping)
+			if err != nil {
+				t.Fatalf("NewGroupingSetsOperator failed: %v", err)
+			}
+			if got := groupingSetsRows(t, g); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGroupingSetsOperator_EmptyInput(t *testing.T) {
+	td := createTestTupleDesc()
+
+	// Only the empty set has a row without input
+	g, err := NewGroupingSetsOperator(newMockIterator(nil, td), 1, 0, Count, []bool{true, false}, true)
+	if err != nil {
+		t.Fatalf("NewGroupingSetsOperator failed: %v", err)
+	}
+	if got := groupingSetsRows(t, g); !slices.Equal(got, []string{"NULL/0/1"}) {
+		t.Errorf("expected [NULL/0/1], got %v", got)
+	}
+
+	// Without a grouping field, the rows have no group
+	g, err = NewGroupingSetsOperator(newMockIterator(createTestTuples(), td), 1, NoGrouping, Count, []bool{false}, false)
+	if err != nil {
+		t.Fatalf("NewGroupingSetsOperator failed: %v", err)
+	}
+	if got := groupingSetsRows(t, g); !slices.Equal(got, []string{"6"}) {
+		t.Errorf("expected [6], got %v", got)
+	}
+}
+
+func TestGroupingSetsOperator_InvalidSets(t *testing.T) {
+	td := createTestTupleDesc()
+	source := newMockIterator(createTestTuples(), td)
+
+	if _, err := NewGroupingSetsOperator(source, 1, 0, Sum, nil, false); err == nil {
+		t.Error("expected an error without grouping sets")
+	}
+	if _, err := NewGroupingSetsOperator(source, 1, NoGrouping, Sum, []bool{true}, false); err == nil {
+		t.Error("expected an error for a set grouping without a grouping field")
+	}
+}
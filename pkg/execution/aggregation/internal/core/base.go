@@ -87,6 +87,13 @@ func (ba *BaseAggregator) GetGroups() []string {
 	return slices.Collect(maps.Keys(ba.groups))
 }
 
+// NumGroups returns the number of groups that have been processed.
+func (ba *BaseAggregator) NumGroups() int {
+	ba.mutex.RLock()
+	defer ba.mutex.RUnlock()
+	return len(ba.groups)
+}
+
 // GetAggregateValue retrieves the computed aggregate value for a specific group.
 //
 // Parameters:
@@ -175,7 +182,8 @@ func (ba *BaseAggregator) Merge(tup *tuple.Tuple) error {
 
 // extractGroupKey extracts the grouping key from a tuple.
 // For non-grouped aggregates, returns the constant "NO_GROUPING".
-// For grouped aggregates, returns the string representation of the grouping field.
+// For grouped aggregates, returns the string representation of the grouping field,
+// or nullGroupKey if it is NULL, so that all NULLs form a single group.
 func (ba *BaseAggregator) extractGroupKey(tup *tuple.Tuple) (string, error) {
 	if ba.GbField == NoGrouping {
 		return "NO_GROUPING", nil
@@ -185,6 +193,9 @@ func (ba *BaseAggregator) extractGroupKey(tup *tuple.Tuple) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to get grouping field: %v", err)
 	}
+	if groupField == nil {
+		return nullGroupKey, nil
+	}
 
 	return groupField.String(), nil
 }
@@ -148,7 +148,18 @@ func (i *AggregatorIterator) buildResultTuple(groupKey string) (*tuple.Tuple, er
 		return t, nil
 	}
 
-	groupField := types.NewStringField(groupKey, len(groupKey))
+	if groupKey == nullGroupKey {
+		t := tuple.NewTuple(agg.GetTupleDesc())
+		if err := t.SetField(1, aggValue); err != nil {
+			return nil, fmt.Errorf("failed to build grouped tuple: %w", err)
+		}
+		return t, nil
+	}
+
+	groupField, err := groupFieldFromKey(agg.GetTupleDesc().Types[0], groupKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build grouped tuple: %w", err)
+	}
 	t, err := builder.AddField(groupField).AddField(aggValue).Build()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build grouped tuple: %w", err)
@@ -156,6 +167,15 @@ func (i *AggregatorIterator) buildResultTuple(groupKey string) (*tuple.Tuple, er
 	return t, nil
 }
 
+// groupFieldFromKey converts a group key back into a field of the grouping
+// field's type.
+func groupFieldFromKey(gbFieldType types.Type, groupKey string) (types.Field, error) {
+	if gbFieldType == types.StringType {
+		return types.NewStringField(groupKey, len(groupKey)), nil
+	}
+	return types.CreateFieldFromConstant(gbFieldType, groupKey)
+}
+
 // HasNext checks if there are more tuples available.
 //
 // Returns true if there are more tuples to iterate over in the snapshot.
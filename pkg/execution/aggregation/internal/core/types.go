@@ -11,6 +11,10 @@ import (
 const (
 	// NoGrouping indicates that no grouping field is used in aggregation
 	NoGrouping primitives.ColumnID = math.MaxUint32
+
+	// nullGroupKey is the group key of tuples whose grouping field is NULL.
+	// It cannot collide with the string representation of a field value.
+	nullGroupKey = "\x00NULL"
 )
 
 // AggregateOp represents the type of aggregation operation to perform
@@ -1,9 +1,11 @@
 package aggregation
 
 import (
+	"errors"
 	"fmt"
 	"storemy/pkg/execution/aggregation/internal/calculators"
 	"storemy/pkg/execution/aggregation/internal/core"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/iterator"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
@@ -25,6 +27,18 @@ type AggregateOperator struct {
 	opened                       bool
 	nextTuple                    *tuple.Tuple
 	hasNextCalled                bool
+	memory                       membudget.Account // Memory held by the groups
+}
+
+// groupStateSize estimates the bytes each group holds besides its key: the
+// entries in the group maps and the calculator's running state.
+const groupStateSize = 128
+
+// SetMemoryTracker accounts the groups built while aggregating against the
+// query's memory budget, so a GROUP BY over too many distinct keys fails with
+// membudget.ErrOutOfMemoryBudget.
+func (agg *AggregateOperator) SetMemoryTracker(t *membudget.Tracker) {
+	agg.memory.SetTracker(t)
 }
 
 // NewAggregateOperator creates a new aggregate operator with the specified configuration.
@@ -78,6 +92,7 @@ func (agg *AggregateOperator) Close() error {
 	agg.opened = false
 	agg.nextTuple = nil
 	agg.hasNextCalled = false
+	agg.memory.ReleaseAll()
 
 	return nil
 }
@@ -120,17 +135,24 @@ func (agg *AggregateOperator) Open() error {
 	}
 
 	if err := agg.source.Open(); err != nil {
-		return fmt.Errorf("failed to open source iterator: %v", err)
+		return fmt.Errorf("failed to open source iterator: %w", err)
 	}
 
 	tupleCount := 0
-	iterator.ForEach(agg.source, func(t *tuple.Tuple) error {
+	err := iterator.ForEach(agg.source, func(t *tuple.Tuple) error {
 		tupleCount++
+		groups := agg.aggregator.NumGroups()
 		if err := agg.aggregator.Merge(t); err != nil {
 			return fmt.Errorf("error merging tuple: %v", err)
 		}
+		if agg.aggregator.NumGroups() > groups {
+			return agg.reserveGroup(t)
+		}
 		return nil
 	})
+	if errors.Is(err, membudget.ErrOutOfMemoryBudget) {
+		return err
+	}
 
 	// For non-grouped aggregates (e.g., COUNT(*) with no GROUP BY),
 	// we need to return a single row even if there are no input tuples.
@@ -152,6 +174,20 @@ func (agg *AggregateOperator) Open() error {
 	return nil
 }
 
+// reserveGroup reserves memory for the group t just started.
+func (agg *AggregateOperator) reserveGroup(t *tuple.Tuple) error {
+	size := int64(groupStateSize)
+	if agg.groupByField != NoGrouping {
+		key, _ := t.GetField(agg.groupByField)
+		size += membudget.FieldSize(key)
+	}
+
+	if err := agg.memory.Reserve(size); err != nil {
+		return fmt.Errorf("cannot add aggregation group: %w", err)
+	}
+	return nil
+}
+
 // HasNext checks if there are more aggregate result tuples available.
 // Uses internal caching to support the hasNext/next pattern efficiently.
 func (agg *AggregateOperator) HasNext() (bool, error) {
@@ -163,7 +199,7 @@ func (agg *AggregateOperator) HasNext() (bool, error) {
 		var err error
 		agg.nextTuple, err = agg.readNext()
 		if err != nil {
-			return false, fmt.Errorf("error reading next tuple: %v", err)
+			return false, fmt.Errorf("error reading next tuple: %w", err)
 		}
 		agg.hasNextCalled = true
 	}
@@ -974,6 +974,70 @@ func TestAggregateOperator_Aggregation_WithGrouping(t *testing.T) {
 	}
 }
 
+func TestAggregateOperator_Aggregation_TypedAndNullGroups(t *testing.T) {
+	td, _ := tuple.NewTupleDesc(
+		[]types.Type{types.BoolType, types.IntType},
+		[]string{"group", "value"},
+	)
+
+	var tuples []*tuple.Tuple
+	for _, data := range []struct {
+		group types.Field
+		value int64
+	}{
+		{types.NewBoolField(true), 1},
+		{types.NewBoolField(false), 2},
+		{nil, 4},
+		{types.NewBoolField(true), 8},
+		{nil, 16},
+	} {
+		tup := tuple.NewTuple(td)
+		if data.group != nil {
+			tup.SetField(0, data.group)
+		}
+		tup.SetField(1, types.NewIntField(data.value))
+		tuples = append(tuples, tup)
+	}
+
+	op, err := NewAggregateOperator(newMockIterator(tuples, td), 1, 0, Sum)
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	if err := op.Open(); err != nil {
+		t.Fatalf("Failed to open operator: %v", err)
+	}
+	defer op.Close()
+
+	results := make(map[string]int64)
+	err = iterator.ForEach(op, func(result *tuple.Tuple) error {
+		groupField, _ := result.GetField(0)
+		valueField, _ := result.GetField(1)
+
+		key := "NULL"
+		if groupField != nil {
+			if groupField.Type() != types.BoolType {
+				t.Errorf("expected a BOOL group field, got %s", groupField.Type())
+			}
+			key = groupField.String()
+		}
+		results[key] = valueField.(*types.IntField).Value
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to iterate: %v", err)
+	}
+
+	expected := map[string]int64{"true": 9, "false": 2, "NULL": 20}
+	if len(results) != len(expected) {
+		t.Fatalf("expected groups %v, got %v", expected, results)
+	}
+	for group, sum := range expected {
+		if results[group] != sum {
+			t.Errorf("group %s: expected sum %d, got %d", group, sum, results[group])
+		}
+	}
+}
+
 func TestAggregateOperator_Aggregation_NoGrouping(t *testing.T) {
 	td := createTestTupleDesc()
 	tuples := createTestTuples()
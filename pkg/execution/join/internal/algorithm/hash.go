@@ -184,20 +184,13 @@ func (hj *HashJoin) setupMatches(leftTuple *tuple.Tuple, matches []*tuple.Tuple)
 // into the in-memory hashTable keyed by the join key.
 func (h *HashJoin) buildHashTable() error {
 	rf := h.Predicate().GetRightField()
-	rightTuples, err := iterator.Collect(h.RightChild())
-
-	if err != nil {
-		return err
-	}
-
-	for _, rightTuple := range rightTuples {
+	return iterator.ForEach(h.RightChild(), func(rightTuple *tuple.Tuple) error {
 		if err := h.addToHashTable(rightTuple, rf); err != nil {
 			// Skip right tuples that cannot produce a valid join key
-			continue
+			return nil
 		}
-	}
-
-	return nil
+		return h.ReserveTuple(rightTuple)
+	})
 }
 
 // addToHashTable inserts a single right tuple into the hash table under the
@@ -128,10 +128,16 @@ func (nl *NestedLoopJoin) Initialize() error {
 func (nl *NestedLoopJoin) loadNextBlock() error {
 	nl.blockIndex = 0
 
+	nl.ReleaseTuples()
 	tuples, err := iterator.Take(nl.LeftChild(), nl.blockSize)
 	if err != nil {
 		return err
 	}
+	for _, t := range tuples {
+		if err := nl.ReserveTuple(t); err != nil {
+			return err
+		}
+	}
 	nl.leftBlock = tuples
 	return nil
 }
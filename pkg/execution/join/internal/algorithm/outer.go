@@ -0,0 +1,218 @@
+package algorithm
+
+import (
+	"fmt"
+	"storemy/pkg/execution/join/internal/common"
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+)
+
+// OuterJoin implements LEFT, RIGHT and FULL outer joins.
+//
+// The right child is buffered in memory, grouped by join key when the
+// predicate is an equality, and the left child is streamed against it:
+//   - Each left tuple is combined with every right tuple it matches. A left
+//     tuple with no match is combined with an all-NULL right tuple if the
+//     join preserves the left input.
+//   - Once the left child is exhausted, each right tuple that matched no
+//     left tuple is combined with an all-NULL left tuple if the join
+//     preserves the right input.
+//
+// A NULL join key never matches, so rows with NULL keys are kept by the
+// preserved side and padded, as SQL requires.
+//
+// Complexity:
+//   - Time: O(|Left| + |Right|) for equality predicates, O(|Left| * |Right|)
+//     otherwise.
+//   - Space: O(|Right|) for the buffered right input.
+type OuterJoin struct {
+	common.BaseJoin
+	joinType   common.JoinType
+	right      []*tuple.Tuple
+	buckets    map[primitives.HashCode][]int // Right tuple positions by join key; nil for non-equality predicates
+	matched    []bool                        // Whether each right tuple matched a left tuple
+	leftNulls  *tuple.Tuple
+	rightNulls *tuple.Tuple
+	leftDone   bool
+	nextRight  int // Next right tuple to check for a match once the left input is exhausted
+}
+
+// NewOuterJoin creates an outer join of the given type. The returned
+// operator is not initialized; call Initialize() before Next().
+func NewOuterJoin(left, right iterator.DbIterator, pred common.JoinPredicate, stats *common.JoinStatistics, joinType common.JoinType) *OuterJoin {
+	return &OuterJoin{
+		BaseJoin: common.NewBaseJoin(left, right, pred, stats),
+		joinType: joinType,
+	}
+}
+
+// Initialize buffers the right child and prepares the all-NULL padding
+// tuples. It is idempotent.
+func (oj *OuterJoin) Initialize() error {
+	if oj.IsInitialized() {
+		return nil
+	}
+
+	if oj.Predicate().GetOP() == primitives.Equals {
+		oj.buckets = make(map[primitives.HashCode][]int)
+	}
+
+	for {
+		hasNext, err := oj.RightChild().HasNext()
+		if err != nil {
+			return err
+		}
+		if !hasNext {
+			break
+		}
+
+		t, err := oj.RightChild().Next()
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			continue
+		}
+		if err := oj.ReserveTuple(t); err != nil {
+			return err
+		}
+
+		if oj.buckets != nil {
+			if key, err := common.ExtractJoinKey(t, oj.Predicate().GetRightField()); err == nil {
+				oj.buckets[key] = append(oj.buckets[key], len(oj.right))
+			}
+		}
+		oj.right = append(oj.right, t)
+	}
+
+	oj.matched = make([]bool, len(oj.right))
+	oj.leftNulls = tuple.NewTuple(oj.LeftChild().GetTupleDesc())
+	oj.rightNulls = tuple.NewTuple(oj.RightChild().GetTupleDesc())
+	oj.SetInitialized()
+	return nil
+}
+
+// Next returns the next joined or NULL-padded tuple, or nil when the join
+// is exhausted.
+func (oj *OuterJoin) Next() (*tuple.Tuple, error) {
+	if !oj.IsInitialized() {
+		return nil, fmt.Errorf("outer join not initialized")
+	}
+
+	if match := oj.GetMatchFromBuffer(); match != nil {
+		return match, nil
+	}
+
+	for !oj.leftDone {
+		hasNext, err := oj.LeftChild().HasNext()
+		if err != nil {
+			return nil, err
+		}
+		if !hasNext {
+			oj.leftDone = true
+			break
+		}
+
+		left, err := oj.LeftChild().Next()
+		if err != nil {
+			return nil, err
+		}
+		if left == nil {
+			continue
+		}
+
+		oj.MatchBuffer().StartNew()
+		if err := oj.probe(left); err != nil {
+			return nil, err
+		}
+		if result := oj.MatchBuffer().GetFirstAndAdvance(); result != nil {
+			return result, nil
+		}
+	}
+
+	if !oj.joinType.PreservesRight() {
+		return nil, nil
+	}
+	for oj.nextRight < len(oj.right) {
+		i := oj.nextRight
+		oj.nextRight++
+		if !oj.matched[i] {
+			return tuple.CombineTuples(oj.leftNulls, oj.right[i])
+		}
+	}
+	return nil, nil
+}
+
+// probe buffers the combinations of left with the right tuples it matches,
+// or with the all-NULL right tuple if it matches none and the join
+// preserves the left input.
+func (oj *OuterJoin) probe(left *tuple.Tuple) error {
+	found := false
+	check := func(i int) error {
+		ok, err := oj.Predicate().Filter(left, oj.right[i])
+		if err != nil || !ok {
+			return err
+		}
+		found = true
+		oj.matched[i] = true
+		return common.CombineAndBuffer(oj.MatchBuffer(), left, oj.right[i])
+	}
+
+	if oj.buckets != nil {
+		if key, err := common.ExtractJoinKey(left, oj.Predicate().GetLeftField()); err == nil {
+			for _, i := range oj.buckets[key] {
+				if err := check(i); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		for i := range oj.right {
+			if err := check(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !found && oj.joinType.PreservesLeft() {
+		return common.CombineAndBuffer(oj.MatchBuffer(), left, oj.rightNulls)
+	}
+	return nil
+}
+
+// Reset rewinds the left child and forgets which right tuples matched. The
+// buffered right input is kept.
+func (oj *OuterJoin) Reset() error {
+	oj.ResetCommon()
+	oj.leftDone = false
+	oj.nextRight = 0
+	clear(oj.matched)
+	return oj.LeftChild().Rewind()
+}
+
+// Close releases the buffered right input.
+func (oj *OuterJoin) Close() error {
+	oj.right = nil
+	oj.buckets = nil
+	oj.matched = nil
+	return oj.BaseJoin.Close()
+}
+
+// EstimateCost returns the cost of reading both inputs, plus comparing
+// every pair of tuples when the predicate is not an equality.
+func (oj *OuterJoin) EstimateCost() float64 {
+	stats := oj.Stats()
+	if stats == nil {
+		return common.DefaultHighCost
+	}
+	if oj.Predicate().GetOP() == primitives.Equals {
+		return 3 * float64(stats.LeftSize+stats.RightSize)
+	}
+	return float64(stats.LeftSize + stats.LeftSize*stats.RightSize)
+}
+
+// SupportsPredicateType reports that any predicate can be used.
+func (oj *OuterJoin) SupportsPredicateType(predicate common.JoinPredicate) bool {
+	return true
+}
@@ -0,0 +1,171 @@
+package algorithm
+
+import (
+	"slices"
+	"storemy/pkg/execution/join/internal/common"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// OUTER JOIN TESTS
+// ============================================================================
+
+// outerJoinInputs returns users (id, name) and orders (user_id, item), where
+// user 3 has no order, the order of user 9 has no user and both inputs have
+// a row with a NULL key.
+func outerJoinInputs() (*mockIterator, *mockIterator) {
+	usersDesc := createTestTupleDesc([]types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+	ordersDesc := createTestTupleDesc([]types.Type{types.IntType, types.StringType}, []string{"user_id", "item"})
+
+	users := newMockIterator([]*tuple.Tuple{
+		outerTuple(usersDesc, []any{1, "ada"}),
+		outerTuple(usersDesc, []any{2, "alan"}),
+		outerTuple(usersDesc, []any{3, "grace"}),
+		outerTuple(usersDesc, []any{nil, "nobody"}),
+	}, usersDesc)
+	orders := newMockIterator([]*tuple.Tuple{
+		outerTuple(ordersDesc, []any{1, "book"}),
+		outerTuple(ordersDesc, []any{1, "pen"}),
+		outerTuple(ordersDesc, []any{2, "lamp"}),
+		outerTuple(ordersDesc, []any{9, "orphan"}),
+		outerTuple(ordersDesc, []any{nil, "lost"}),
+	}, ordersDesc)
+	return users, orders
+}
+
+// outerTuple builds a tuple of values, leaving the fields of nil values
+// unset, which is how NULL is stored.
+func outerTuple(desc *tuple.TupleDescription, values []any) *tuple.Tuple {
+	t := tuple.NewTuple(desc)
+	for i, v := range values {
+		switch v := v.(type) {
+		case int:
+			t.SetField(primitives.ColumnID(i), types.NewIntField(int64(v)))
+		case string:
+			t.SetField(primitives.ColumnID(i), types.NewStringField(v, types.StringMaxSize))
+		}
+	}
+	return t
+}
+
+// formatOuterRow renders a joined row with NULL for missing fields.
+func formatOuterRow(t *tuple.Tuple) string {
+	parts := make([]string, t.TupleDesc.NumFields())
+	for i := range parts {
+		field, _ := t.GetField(primitives.ColumnID(i))
+		if field == nil {
+			parts[i] = "NULL"
+		} else {
+			parts[i] = field.String()
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func runOuterJoin(t *testing.T, joinType common.JoinType, op primitives.Predicate) []string {
+	t.Helper()
+	users, orders := outerJoinInputs()
+	users.Open()
+	orders.Open()
+
+	pred, _ := common.NewJoinPredicate(0, 0, op)
+	oj := NewOuterJoin(users, orders, pred, common.DefaultJoinStatistics(), joinType)
+	if err := oj.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer oj.Close()
+
+	var rows []string
+	for {
+		row, err := oj.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if row == nil {
+			break
+		}
+		rows = append(rows, formatOuterRow(row))
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+func TestOuterJoin_NullExtendsUnmatchedRows(t *testing.T) {
+	matches := []string{"1,ada,1,book", "1,ada,1,pen", "2,alan,2,lamp"}
+	leftOnly := []string{"3,grace,NULL,NULL", "NULL,nobody,NULL,NULL"}
+	rightOnly := []string{"NULL,NULL,9,orphan", "NULL,NULL,NULL,lost"}
+
+	tests := []struct {
+		name     string
+		joinType common.JoinType
+		want     []string
+	}{
+		{"left", common.LeftOuterJoin, slices.Concat(matches, leftOnly)},
+		{"right", common.RightOuterJoin, slices.Concat(matches, rightOnly)},
+		{"full", common.FullOuterJoin, slices.Concat(matches, leftOnly, rightOnly)},
+		{"inner", common.InnerJoin, matches},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if got := runOuterJoin(t, tt.joinType, primitives.Equals); !slices.Equal(got, want) {
+				t.Errorf("got rows\n%v\nwant\n%v", got, want)
+			}
+		})
+	}
+}
+
+func TestOuterJoin_NonEqualityPredicate(t *testing.T) {
+	// Users whose id is greater than an order's user_id
+	got := runOuterJoin(t, common.LeftOuterJoin, primitives.GreaterThan)
+	want := []string{
+		"2,alan,1,book", "2,alan,1,pen",
+		"3,grace,1,book", "3,grace,1,pen", "3,grace,2,lamp",
+		"1,ada,NULL,NULL", "NULL,nobody,NULL,NULL",
+	}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("got rows\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestOuterJoin_ResetForgetsMatches(t *testing.T) {
+	users, orders := outerJoinInputs()
+	users.Open()
+	orders.Open()
+
+	pred, _ := common.NewJoinPredicate(0, 0, primitives.Equals)
+	oj := NewOuterJoin(users, orders, pred, common.DefaultJoinStatistics(), common.FullOuterJoin)
+	if err := oj.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer oj.Close()
+
+	count := func() int {
+		n := 0
+		for {
+			row, err := oj.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if row == nil {
+				return n
+			}
+			n++
+		}
+	}
+
+	first := count()
+	if err := oj.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if second := count(); second != first || first != 7 {
+		t.Errorf("expected 7 rows before and after Reset, got %d and %d", first, second)
+	}
+}
@@ -49,13 +49,13 @@ func (s *SortMergeJoin) Initialize() error {
 	}
 
 	var err error
-	leftSorted, err := loadAndSort(s.LeftChild(), s.Predicate().GetLeftField())
+	leftSorted, err := s.loadAndSort(s.LeftChild(), s.Predicate().GetLeftField())
 	if err != nil {
 		return err
 	}
 	s.leftIterator = iterator.NewSliceIterator(leftSorted)
 
-	rightSorted, err := loadAndSort(s.RightChild(), s.Predicate().GetRightField())
+	rightSorted, err := s.loadAndSort(s.RightChild(), s.Predicate().GetRightField())
 	if err != nil {
 		return err
 	}
@@ -258,9 +258,9 @@ func (s *SortMergeJoin) processEqualKeys() error {
 }
 
 // loadAndSort loads all tuples from an iterator and sorts by field index.
-func loadAndSort(iter iterator.DbIterator, fieldIndex primitives.ColumnID) ([]*tuple.Tuple, error) {
+func (s *SortMergeJoin) loadAndSort(iter iterator.DbIterator, fieldIndex primitives.ColumnID) ([]*tuple.Tuple, error) {
 	tuples, err := iterator.Map(iter, func(t *tuple.Tuple) (*tuple.Tuple, error) {
-		return t, nil
+		return t, s.ReserveTuple(t)
 	})
 	if err != nil {
 		return nil, err
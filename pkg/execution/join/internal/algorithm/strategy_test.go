@@ -2,6 +2,7 @@ package algorithm
 
 import (
 	"storemy/pkg/execution/join/internal/common"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
@@ -34,6 +35,8 @@ func (m *mockJoinAlgorithm) EstimateCost() float64 {
 	return m.cost
 }
 
+func (m *mockJoinAlgorithm) SetMemoryTracker(t *membudget.Tracker) {}
+
 func (m *mockJoinAlgorithm) SupportsPredicateType(pred common.JoinPredicate) bool {
 	return m.supportsType
 }
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"fmt"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/iterator"
 	"storemy/pkg/tuple"
 )
@@ -14,6 +16,7 @@ type BaseJoin struct {
 	StatsField       *JoinStatistics
 	MatchBufferField *JoinMatchBuffer
 	Initialized      bool
+	memory           membudget.Account // Memory held by buffered input tuples
 }
 
 // NewBaseJoin creates a new base join with common initialization.
@@ -32,6 +35,22 @@ func NewBaseJoin(left, right iterator.DbIterator, pred JoinPredicate, stats *Joi
 func (bj *BaseJoin) Close() error {
 	bj.MatchBufferField.Reset()
 	bj.Initialized = false
+	bj.memory.ReleaseAll()
+	return nil
+}
+
+// SetMemoryTracker accounts the tuples the join buffers against the query's
+// memory budget.
+func (bj *BaseJoin) SetMemoryTracker(t *membudget.Tracker) {
+	bj.memory.SetTracker(t)
+}
+
+// ReserveTuple reserves memory for an input tuple the join keeps, failing
+// with membudget.ErrOutOfMemoryBudget when the query's budget is exhausted.
+func (bj *BaseJoin) ReserveTuple(t *tuple.Tuple) error {
+	if err := bj.memory.ReserveTuple(t); err != nil {
+		return fmt.Errorf("cannot buffer join input: %w", err)
+	}
 	return nil
 }
 
@@ -45,6 +64,12 @@ func (bj *BaseJoin) SetInitialized() {
 	bj.Initialized = true
 }
 
+// ReleaseTuples returns the memory reserved for buffered input tuples, once
+// the join has dropped them.
+func (bj *BaseJoin) ReleaseTuples() {
+	bj.memory.ReleaseAll()
+}
+
 // GetMatchFromBuffer returns next match if available, nil otherwise.
 func (bj *BaseJoin) GetMatchFromBuffer() *tuple.Tuple {
 	if bj.MatchBufferField.HasNext() {
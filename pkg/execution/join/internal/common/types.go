@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
 )
@@ -10,6 +11,42 @@ const (
 	DefaultHighCost = 1e6 // Arbitrary high cost for unsupported scenarios
 )
 
+// JoinType is the kind of join, which decides what happens to rows of one
+// input that match no row of the other.
+type JoinType int
+
+const (
+	InnerJoin      JoinType = iota // Unmatched rows are dropped
+	LeftOuterJoin                  // Unmatched left rows are kept, with NULL right columns
+	RightOuterJoin                 // Unmatched right rows are kept, with NULL left columns
+	FullOuterJoin                  // Unmatched rows of both inputs are kept
+)
+
+func (t JoinType) String() string {
+	switch t {
+	case InnerJoin:
+		return "INNER"
+	case LeftOuterJoin:
+		return "LEFT OUTER"
+	case RightOuterJoin:
+		return "RIGHT OUTER"
+	case FullOuterJoin:
+		return "FULL OUTER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// PreservesLeft reports whether unmatched left rows are kept.
+func (t JoinType) PreservesLeft() bool {
+	return t == LeftOuterJoin || t == FullOuterJoin
+}
+
+// PreservesRight reports whether unmatched right rows are kept.
+func (t JoinType) PreservesRight() bool {
+	return t == RightOuterJoin || t == FullOuterJoin
+}
+
 // JoinAlgorithm defines the interface for all join implementations
 type JoinAlgorithm interface {
 	// Initialize prepares the join algorithm for execution
@@ -29,6 +66,9 @@ type JoinAlgorithm interface {
 
 	// SupportsPredicateType checks if this algorithm can handle the given predicate
 	SupportsPredicateType(predicate JoinPredicate) bool
+
+	// SetMemoryTracker accounts the tuples the algorithm buffers against a query's memory budget
+	SetMemoryTracker(t *membudget.Tracker)
 }
 
 // JoinStatistics holds statistics about input relations for cost estimation
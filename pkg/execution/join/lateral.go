@@ -0,0 +1,220 @@
+package join
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/execution/join/internal/common"
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+)
+
+// RightInput builds the right input of a LateralJoin for one left tuple. The
+// returned iterator is not open yet; a nil iterator is an empty input.
+type RightInput func(left *tuple.Tuple) (iterator.DbIterator, error)
+
+// LateralJoin is a nested-loop join whose right input is built anew for each
+// left tuple, so it can depend on that tuple's values. It executes CROSS
+// JOINs, which combine every left tuple with every right tuple, and joins with
+// LATERAL subqueries, whose conditions refer to the columns of the left input.
+//
+// The join is inner unless SetJoinType makes it a LEFT outer join, which
+// combines a left tuple with no matching right tuple with an all-NULL one.
+// The right input cannot be preserved, since it differs from one left tuple
+// to the next.
+//
+// Complexity:
+//   - Time: O(|Left| * |Right|), plus building the right input |Left| times.
+//   - Space: O(1); nothing is buffered.
+type LateralJoin struct {
+	base       *iterator.BaseIterator
+	leftChild  iterator.DbIterator
+	right      RightInput
+	predicate  common.JoinPredicate // nil when every pair of tuples matches
+	joinType   common.JoinType
+	tupleDesc  *tuple.TupleDescription
+	rightNulls *tuple.Tuple
+
+	current   *tuple.Tuple        // Left tuple being joined, nil between left tuples
+	rightIter iterator.DbIterator // Right input of current; nil if it is empty
+	matched   bool                // Whether current matched a right tuple
+}
+
+// NewLateralJoin creates a join of leftChild with the right inputs built by
+// right, whose tuples are described by rightDesc. Without a condition set by
+// SetCondition it is a cross join.
+func NewLateralJoin(leftChild iterator.DbIterator, rightDesc *tuple.TupleDescription, right RightInput) (*LateralJoin, error) {
+	if leftChild == nil {
+		return nil, fmt.Errorf("left child operator cannot be nil")
+	}
+	if right == nil || rightDesc == nil {
+		return nil, fmt.Errorf("right input and its tuple descriptor cannot be nil")
+	}
+
+	j := &LateralJoin{
+		leftChild:  leftChild,
+		right:      right,
+		tupleDesc:  tuple.Combine(leftChild.GetTupleDesc(), rightDesc),
+		rightNulls: tuple.NewTuple(rightDesc),
+	}
+	j.base = iterator.NewBaseIterator(j.readNext)
+	return j, nil
+}
+
+// SetCondition restricts the join to pairs of tuples whose field1 (of the
+// left tuple) and field2 (of the right tuple) satisfy op. It must be called
+// before Open.
+func (j *LateralJoin) SetCondition(field1, field2 primitives.ColumnID, op primitives.Predicate) error {
+	predicate, err := common.NewJoinPredicate(field1, field2, op)
+	if err != nil {
+		return fmt.Errorf("invalid join predicate: %w", err)
+	}
+	j.predicate = predicate
+	return nil
+}
+
+// SetJoinType makes the join a LEFT outer join, or inner again. Other join
+// types are rejected. It must be called before Open.
+func (j *LateralJoin) SetJoinType(t JoinType) error {
+	if t != InnerJoin && t != LeftOuterJoin {
+		return fmt.Errorf("a lateral join can only be INNER or LEFT, not %s", t)
+	}
+	j.joinType = t
+	return nil
+}
+
+// Open opens the left child. Right inputs are opened as they are built.
+func (j *LateralJoin) Open() error {
+	if err := j.leftChild.Open(); err != nil {
+		return fmt.Errorf("failed to open left child: %w", err)
+	}
+	j.base.MarkOpened()
+	return nil
+}
+
+// readNext returns the next joined tuple, moving to the next left tuple and
+// building its right input whenever the current one is exhausted.
+func (j *LateralJoin) readNext() (*tuple.Tuple, error) {
+	for {
+		if j.current != nil {
+			t, err := j.nextMatch()
+			if err != nil || t != nil {
+				return t, err
+			}
+
+			left, matched := j.current, j.matched
+			j.current = nil
+			if err := j.closeRight(); err != nil {
+				return nil, err
+			}
+			if !matched && j.joinType == LeftOuterJoin {
+				return tuple.CombineTuples(left, j.rightNulls)
+			}
+		}
+
+		hasNext, err := j.leftChild.HasNext()
+		if err != nil || !hasNext {
+			return nil, err
+		}
+		left, err := j.leftChild.Next()
+		if err != nil {
+			return nil, err
+		}
+		if err := j.openRight(left); err != nil {
+			return nil, err
+		}
+		j.current, j.matched = left, false
+	}
+}
+
+// openRight builds and opens the right input for left.
+func (j *LateralJoin) openRight(left *tuple.Tuple) error {
+	it, err := j.right(left)
+	if err != nil {
+		return fmt.Errorf("failed to build right input: %w", err)
+	}
+	if it != nil {
+		if err := it.Open(); err != nil {
+			return fmt.Errorf("failed to open right input: %w", err)
+		}
+	}
+	j.rightIter = it
+	return nil
+}
+
+// nextMatch returns the combination of the current left tuple with its next
+// matching right tuple, or nil once its right input is exhausted.
+func (j *LateralJoin) nextMatch() (*tuple.Tuple, error) {
+	if j.rightIter == nil {
+		return nil, nil
+	}
+
+	for {
+		hasNext, err := j.rightIter.HasNext()
+		if err != nil || !hasNext {
+			return nil, err
+		}
+		right, err := j.rightIter.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if j.predicate != nil {
+			ok, err := j.predicate.Filter(j.current, right)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		j.matched = true
+		return tuple.CombineTuples(j.current, right)
+	}
+}
+
+// closeRight closes the right input of the current left tuple, if any.
+func (j *LateralJoin) closeRight() error {
+	if j.rightIter == nil {
+		return nil
+	}
+	err := j.rightIter.Close()
+	j.rightIter = nil
+	return err
+}
+
+// Rewind restarts the join from the first left tuple.
+func (j *LateralJoin) Rewind() error {
+	if err := j.closeRight(); err != nil {
+		return err
+	}
+	j.current = nil
+	if err := j.leftChild.Rewind(); err != nil {
+		return fmt.Errorf("failed to rewind left child: %w", err)
+	}
+	j.base.ClearCache()
+	return nil
+}
+
+// Close closes the left child and the right input in use.
+func (j *LateralJoin) Close() error {
+	errs := []error{j.closeRight(), j.leftChild.Close(), j.base.Close()}
+	j.current = nil
+	return errors.Join(errs...)
+}
+
+// GetTupleDesc returns the schema of the joined tuples: the left input's
+// fields followed by the right input's.
+func (j *LateralJoin) GetTupleDesc() *tuple.TupleDescription {
+	return j.tupleDesc
+}
+
+// HasNext checks if more joined tuples are available.
+func (j *LateralJoin) HasNext() (bool, error) {
+	return j.base.HasNext()
+}
+
+// Next returns the next joined tuple.
+func (j *LateralJoin) Next() (*tuple.Tuple, error) {
+	return j.base.Next()
+}
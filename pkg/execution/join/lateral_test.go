@@ -0,0 +1,125 @@
+package join
+
+import (
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+// lateralRows drains j and returns each joined tuple's fields as strings,
+// "NULL" for a NULL field.
+func lateralRows(t *testing.T, j *LateralJoin) [][]string {
+	t.Helper()
+	if err := j.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer j.Close()
+
+	var rows [][]string
+	for {
+		hasNext, err := j.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			return rows
+		}
+		tup, err := j.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+
+		row := make([]string, tup.TupleDesc.NumFields())
+		for i := range row {
+			field, _ := tup.GetField(primitives.ColumnID(i))
+			row[i] = "NULL"
+			if field != nil {
+				row[i] = field.String()
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// lateralInputs returns a left input of ids 1..3 and a right input that,
+// for a left tuple with id n, yields the multiples n*1 .. n*(3-n) (none for 3).
+func lateralInputs() (*mockIterator, *tuple.TupleDescription, RightInput) {
+	leftDesc := createTestTupleDesc([]types.Type{types.IntType}, []string{"id"})
+	left := newMockIterator([]*tuple.Tuple{
+		createJoinTestTuple(leftDesc, []interface{}{1}),
+		createJoinTestTuple(leftDesc, []interface{}{2}),
+		createJoinTestTuple(leftDesc, []interface{}{3}),
+	}, leftDesc)
+
+	rightDesc := createTestTupleDesc([]types.Type{types.IntType}, []string{"multiple"})
+	right := func(l *tuple.Tuple) (iterator.DbIterator, error) {
+		field, _ := l.GetField(0)
+		n := field.(*types.IntField).Value
+		var tuples []*tuple.Tuple
+		for k := int64(1); k <= 3-n; k++ {
+			tuples = append(tuples, createJoinTestTuple(rightDesc, []interface{}{n * k}))
+		}
+		return newMockIterator(tuples, rightDesc), nil
+	}
+	return left, rightDesc, right
+}
+
+func TestLateralJoin_RightInputDependsOnLeft(t *testing.T) {
+	left, rightDesc, right := lateralInputs()
+	j, err := NewLateralJoin(left, rightDesc, right)
+	if err != nil {
+		t.Fatalf("NewLateralJoin failed: %v", err)
+	}
+
+	got := lateralRows(t, j)
+	expected := [][]string{{"1", "1"}, {"1", "2"}, {"2", "2"}}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i][0] != expected[i][0] || got[i][1] != expected[i][1] {
+			t.Errorf("row %d: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestLateralJoin_LeftKeepsUnmatchedRows(t *testing.T) {
+	left, rightDesc, right := lateralInputs()
+	j, err := NewLateralJoin(left, rightDesc, right)
+	if err != nil {
+		t.Fatalf("NewLateralJoin failed: %v", err)
+	}
+	if err := j.SetJoinType(LeftOuterJoin); err != nil {
+		t.Fatalf("SetJoinType failed: %v", err)
+	}
+	if err := j.SetCondition(0, 0, primitives.Equals); err != nil {
+		t.Fatalf("SetCondition failed: %v", err)
+	}
+
+	// Only 1*1 and 2*2 equal their left id; 3 has no right input at all
+	got := lateralRows(t, j)
+	expected := [][]string{{"1", "1"}, {"2", "2"}, {"3", "NULL"}}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i][0] != expected[i][0] || got[i][1] != expected[i][1] {
+			t.Errorf("row %d: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestLateralJoin_RejectsPreservedRight(t *testing.T) {
+	left, rightDesc, right := lateralInputs()
+	j, err := NewLateralJoin(left, rightDesc, right)
+	if err != nil {
+		t.Fatalf("NewLateralJoin failed: %v", err)
+	}
+	for _, jt := range []JoinType{RightOuterJoin, FullOuterJoin} {
+		if err := j.SetJoinType(jt); err == nil {
+			t.Errorf("expected %s to be rejected", jt)
+		}
+	}
+}
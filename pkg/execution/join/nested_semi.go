@@ -0,0 +1,201 @@
+package join
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// NestedSemiJoin filters its left input like a HashSemiJoin, but builds its
+// right input anew for each left tuple, so the right input can depend on
+// that tuple's values. It evaluates the correlated EXISTS and IN subqueries
+// that cannot be decorrelated into a hash semi-join, such as those comparing
+// with the enclosing query by inequality or computing an aggregate.
+//
+// Without a key, a left tuple is kept if its right input has any tuple (or,
+// for the anti kinds, none). With a key, the left field is compared for
+// equality with the first field of each right tuple, as IN does.
+//
+// Complexity:
+//   - Time: O(|Left| * |Right|), plus building the right input |Left| times.
+//   - Space: O(1); nothing is buffered.
+type NestedSemiJoin struct {
+	base      *iterator.BaseIterator
+	leftChild iterator.DbIterator
+	right     RightInput
+	key       *primitives.ColumnID // The IN field of the left tuples; nil for EXISTS
+	kind      SemiJoinKind
+}
+
+// NewNestedSemiJoin creates a join keeping the tuples of leftChild for which
+// the right input built by right matches (or, for the anti kinds, does not
+// match). key is the left field compared with the right tuples' first field,
+// or nil to only test whether the right input has tuples; NullAwareAnti
+// needs a key.
+func NewNestedSemiJoin(leftChild iterator.DbIterator, right RightInput, key *primitives.ColumnID, kind SemiJoinKind) (*NestedSemiJoin, error) {
+	if leftChild == nil {
+		return nil, fmt.Errorf("left child operator cannot be nil")
+	}
+	if right == nil {
+		return nil, fmt.Errorf("right input cannot be nil")
+	}
+	if kind == NullAwareAnti && key == nil {
+		return nil, fmt.Errorf("null-aware anti-join needs a key")
+	}
+
+	j := &NestedSemiJoin{
+		leftChild: leftChild,
+		right:     right,
+		key:       key,
+		kind:      kind,
+	}
+	j.base = iterator.NewBaseIterator(j.readNext)
+	return j, nil
+}
+
+// Open opens the left child. Right inputs are opened as they are built.
+func (j *NestedSemiJoin) Open() error {
+	if err := j.leftChild.Open(); err != nil {
+		return fmt.Errorf("failed to open left child: %w", err)
+	}
+	j.base.MarkOpened()
+	return nil
+}
+
+// readNext returns the next left tuple the join keeps.
+func (j *NestedSemiJoin) readNext() (*tuple.Tuple, error) {
+	for {
+		hasNext, err := j.leftChild.HasNext()
+		if err != nil || !hasNext {
+			return nil, err
+		}
+		t, err := j.leftChild.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		keep, err := j.keeps(t)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			return t, nil
+		}
+	}
+}
+
+// keeps builds the right input for left and decides whether left is output.
+func (j *NestedSemiJoin) keeps(left *tuple.Tuple) (bool, error) {
+	var key types.Field
+	if j.key != nil {
+		field, err := left.GetField(*j.key)
+		if err != nil {
+			return false, err
+		}
+		key = field
+	}
+
+	it, err := j.right(left)
+	if err != nil {
+		return false, fmt.Errorf("failed to build right input: %w", err)
+	}
+	if it == nil {
+		return j.kind != Semi, nil // No right tuple matches
+	}
+	if err := it.Open(); err != nil {
+		return false, fmt.Errorf("failed to open right input: %w", err)
+	}
+
+	matched, sawNull, empty, err := j.scan(it, key)
+	if closeErr := it.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch j.kind {
+	case Semi:
+		return matched, nil
+	case Anti:
+		return !matched, nil
+	default:
+		if empty {
+			return true, nil
+		}
+		return key != nil && !matched && !sawNull, nil
+	}
+}
+
+// scan reads the right input until the outcome for key is known, reporting
+// whether a right tuple matched, whether a right key was NULL and whether
+// the input was empty. Without a key, any right tuple matches.
+func (j *NestedSemiJoin) scan(it iterator.DbIterator, key types.Field) (matched, sawNull, empty bool, err error) {
+	empty = true
+	for {
+		hasNext, err := it.HasNext()
+		if err != nil || !hasNext {
+			return false, sawNull, empty, err
+		}
+		t, err := it.Next()
+		if err != nil {
+			return false, sawNull, empty, err
+		}
+		empty = false
+
+		if j.key == nil {
+			return true, false, false, nil
+		}
+		if key == nil {
+			return false, false, false, nil // NULL [NOT] IN (...) is never true
+		}
+
+		field, err := t.GetField(0)
+		if err != nil {
+			return false, sawNull, false, err
+		}
+		if field == nil {
+			sawNull = true
+			continue
+		}
+		equal, err := key.Compare(primitives.Equals, field)
+		if err != nil {
+			return false, sawNull, false, err
+		}
+		if equal {
+			return true, sawNull, false, nil
+		}
+	}
+}
+
+// Rewind restarts the join from the first left tuple.
+func (j *NestedSemiJoin) Rewind() error {
+	if err := j.leftChild.Rewind(); err != nil {
+		return fmt.Errorf("failed to rewind left child: %w", err)
+	}
+	j.base.ClearCache()
+	return nil
+}
+
+// Close closes the left child.
+func (j *NestedSemiJoin) Close() error {
+	return errors.Join(j.leftChild.Close(), j.base.Close())
+}
+
+// GetTupleDesc returns the schema of the left input, which the join outputs.
+func (j *NestedSemiJoin) GetTupleDesc() *tuple.TupleDescription {
+	return j.leftChild.GetTupleDesc()
+}
+
+// HasNext checks if more tuples are available.
+func (j *NestedSemiJoin) HasNext() (bool, error) {
+	return j.base.HasNext()
+}
+
+// Next returns the next tuple.
+func (j *NestedSemiJoin) Next() (*tuple.Tuple, error) {
+	return j.base.Next()
+}
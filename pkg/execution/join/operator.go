@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"storemy/pkg/execution/join/internal/algorithm"
 	"storemy/pkg/execution/join/internal/common"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/iterator"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
@@ -22,6 +23,8 @@ type JoinOperator struct {
 	tupleDesc  *tuple.TupleDescription
 	algorithm  common.JoinAlgorithm
 	strategy   *algorithm.JoinStrategy
+	memory     *membudget.Tracker
+	joinType   common.JoinType
 
 	initialized bool
 	mutex       sync.RWMutex
@@ -54,6 +57,25 @@ func NewJoinOperator(field1, field2 primitives.ColumnID, op primitives.Predicate
 	return j, nil
 }
 
+// SetMemoryTracker accounts the tuples buffered by the selected join
+// algorithm (the hash table of a hash join, the sorted inputs of a
+// sort-merge join) against the query's memory budget. It must be called
+// before Open.
+func (j *JoinOperator) SetMemoryTracker(t *membudget.Tracker) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.memory = t
+}
+
+// SetJoinType makes the join an outer join that keeps unmatched rows of the
+// inputs t preserves, padding the other input's columns with NULLs. Joins
+// are inner by default. It must be called before Open.
+func (j *JoinOperator) SetJoinType(t JoinType) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.joinType = t
+}
+
 // Open initializes the join operator and selects the optimal join algorithm.
 //
 // This method:
@@ -110,15 +132,22 @@ func (j *JoinOperator) getDefaultStatistics() *common.JoinStatistics {
 	return common.DefaultJoinStatistics()
 }
 
-// selectAndInitializeAlgorithm chooses and sets up the optimal join algorithm
+// selectAndInitializeAlgorithm chooses and sets up the optimal join
+// algorithm. Outer joins always use the outer join algorithm, the only one
+// that tracks unmatched rows.
 func (j *JoinOperator) selectAndInitializeAlgorithm(stats *common.JoinStatistics) error {
-	j.strategy = algorithm.NewJoinStrategy(j.leftChild, j.rightChild, j.predicate, stats)
-
-	alg, err := j.strategy.SelectBestAlgorithm(j.predicate)
-	if err != nil {
-		return fmt.Errorf("failed to select join algorithm: %w", err)
+	if j.joinType != common.InnerJoin {
+		j.algorithm = algorithm.NewOuterJoin(j.leftChild, j.rightChild, j.predicate, stats, j.joinType)
+	} else {
+		j.strategy = algorithm.NewJoinStrategy(j.leftChild, j.rightChild, j.predicate, stats)
+
+		alg, err := j.strategy.SelectBestAlgorithm(j.predicate)
+		if err != nil {
+			return fmt.Errorf("failed to select join algorithm: %w", err)
+		}
+		j.algorithm = alg
 	}
-	j.algorithm = alg
+	j.algorithm.SetMemoryTracker(j.memory)
 
 	if err := j.algorithm.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize join algorithm: %w", err)
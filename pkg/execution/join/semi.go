@@ -0,0 +1,344 @@
+package join
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// SemiJoinKind selects which left tuples a HashSemiJoin keeps.
+type SemiJoinKind int
+
+const (
+	// Semi keeps the left tuples whose key matches a right tuple's: EXISTS
+	// and IN.
+	Semi SemiJoinKind = iota
+	// Anti keeps the left tuples whose key matches no right tuple's: NOT
+	// EXISTS.
+	Anti
+	// NullAwareAnti keeps the left tuples whose first key differs from the
+	// first key of every right tuple matching on the other keys: NOT IN. A
+	// comparison with NULL is unknown rather than false, so a NULL on either
+	// side drops the tuple, unless no right tuple matches on the other keys.
+	NullAwareAnti
+)
+
+func (k SemiJoinKind) String() string {
+	switch k {
+	case Semi:
+		return "SEMI"
+	case Anti:
+		return "ANTI"
+	case NullAwareAnti:
+		return "NULL-AWARE ANTI"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// HashSemiJoin filters its left input by whether each tuple's key has a
+// match in the right input, which is read once into a hash table of keys.
+// It outputs left tuples unchanged, each at most once however many right
+// tuples match, which is what EXISTS and IN subqueries need and what an
+// inner join followed by DISTINCT can only approximate.
+//
+// Keys are compared for equality, and a NULL in a key never matches.
+//
+// Complexity:
+//   - Time: O(|Left| + |Right|)
+//   - Space: O(distinct right keys)
+type HashSemiJoin struct {
+	base       *iterator.BaseIterator
+	leftChild  iterator.DbIterator
+	rightChild iterator.DbIterator
+	leftKeys   []primitives.ColumnID
+	rightKeys  []primitives.ColumnID
+	kind       SemiJoinKind
+	memory     membudget.Account
+
+	built      bool
+	keys       keySet // Right keys without NULLs
+	groups     keySet // NullAwareAnti: right keys but the first, without NULLs
+	nullGroups keySet // NullAwareAnti: the groups in which the first key is NULL
+}
+
+// NewHashSemiJoin creates a join keeping the tuples of leftChild whose
+// fields leftKeys match (or, for the anti kinds, do not match) the fields
+// rightKeys of a tuple of rightChild. Without keys, a semi-join keeps every
+// left tuple if the right input has any tuple, and an anti-join keeps them
+// if it has none. NullAwareAnti needs at least one key.
+func NewHashSemiJoin(leftChild, rightChild iterator.DbIterator, leftKeys, rightKeys []primitives.ColumnID, kind SemiJoinKind) (*HashSemiJoin, error) {
+	if leftChild == nil || rightChild == nil {
+		return nil, fmt.Errorf("child operators cannot be nil")
+	}
+	if len(leftKeys) != len(rightKeys) {
+		return nil, fmt.Errorf("semi-join needs as many left keys as right keys, got %d and %d", len(leftKeys), len(rightKeys))
+	}
+	if kind == NullAwareAnti && len(leftKeys) == 0 {
+		return nil, fmt.Errorf("null-aware anti-join needs a key")
+	}
+
+	j := &HashSemiJoin{
+		leftChild:  leftChild,
+		rightChild: rightChild,
+		leftKeys:   leftKeys,
+		rightKeys:  rightKeys,
+		kind:       kind,
+	}
+	j.base = iterator.NewBaseIterator(j.readNext)
+	return j, nil
+}
+
+// SetMemoryTracker accounts the hash table of right keys against the
+// query's memory budget. It must be called before Open.
+func (j *HashSemiJoin) SetMemoryTracker(t *membudget.Tracker) {
+	j.memory.SetTracker(t)
+}
+
+// Open opens both inputs. The right input is read on the first call to
+// HasNext or Next.
+func (j *HashSemiJoin) Open() error {
+	if err := j.leftChild.Open(); err != nil {
+		return fmt.Errorf("failed to open left child: %w", err)
+	}
+	if err := j.rightChild.Open(); err != nil {
+		return fmt.Errorf("failed to open right child: %w", err)
+	}
+	j.base.MarkOpened()
+	return nil
+}
+
+// build reads the right input into the hash tables.
+func (j *HashSemiJoin) build() error {
+	j.keys, j.groups, j.nullGroups = keySet{}, keySet{}, keySet{}
+
+	for {
+		hasNext, err := j.rightChild.HasNext()
+		if err != nil {
+			return err
+		}
+		if !hasNext {
+			break
+		}
+		t, err := j.rightChild.Next()
+		if err != nil {
+			return err
+		}
+
+		key, err := keyOf(t, j.rightKeys)
+		if err != nil {
+			return err
+		}
+		added, err := j.addKey(key)
+		if err != nil {
+			return err
+		}
+		if added {
+			if err := j.memory.ReserveTuple(t); err != nil {
+				return fmt.Errorf("cannot buffer semi-join keys: %w", err)
+			}
+		}
+	}
+
+	j.built = true
+	return nil
+}
+
+// addKey records a right key, reporting whether the tables grew.
+func (j *HashSemiJoin) addKey(key []types.Field) (bool, error) {
+	if j.kind != NullAwareAnti {
+		if hasNull(key) {
+			return false, nil
+		}
+		return j.keys.add(key)
+	}
+
+	group := key[1:]
+	if hasNull(group) {
+		return false, nil
+	}
+	added, err := j.groups.add(group)
+	if err != nil {
+		return false, err
+	}
+	if key[0] == nil {
+		nullAdded, err := j.nullGroups.add(group)
+		return added || nullAdded, err
+	}
+	keyAdded, err := j.keys.add(key)
+	return added || keyAdded, err
+}
+
+// readNext returns the next left tuple the join keeps.
+func (j *HashSemiJoin) readNext() (*tuple.Tuple, error) {
+	if !j.built {
+		if err := j.build(); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		hasNext, err := j.leftChild.HasNext()
+		if err != nil || !hasNext {
+			return nil, err
+		}
+		t, err := j.leftChild.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := keyOf(t, j.leftKeys)
+		if err != nil {
+			return nil, err
+		}
+		keep, err := j.keeps(key)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			return t, nil
+		}
+	}
+}
+
+// keeps decides whether the left tuple with key is output.
+func (j *HashSemiJoin) keeps(key []types.Field) (bool, error) {
+	switch j.kind {
+	case Semi, Anti:
+		matched := false
+		if !hasNull(key) {
+			var err error
+			if matched, err = j.keys.contains(key); err != nil {
+				return false, err
+			}
+		}
+		return matched == (j.kind == Semi), nil
+
+	default:
+		group := key[1:]
+		if hasNull(group) {
+			return true, nil // No right tuple shares a NULL
+		}
+		if inGroup, err := j.groups.contains(group); err != nil || !inGroup {
+			return !inGroup, err
+		}
+		if key[0] == nil {
+			return false, nil
+		}
+		if matched, err := j.keys.contains(key); err != nil || matched {
+			return false, err
+		}
+		hasNullKey, err := j.nullGroups.contains(group)
+		return !hasNullKey, err
+	}
+}
+
+// Rewind restarts the left input; the right keys are kept.
+func (j *HashSemiJoin) Rewind() error {
+	if err := j.leftChild.Rewind(); err != nil {
+		return fmt.Errorf("failed to rewind left child: %w", err)
+	}
+	j.base.ClearCache()
+	return nil
+}
+
+// Close closes both inputs and drops the right keys.
+func (j *HashSemiJoin) Close() error {
+	errs := []error{j.leftChild.Close(), j.rightChild.Close(), j.base.Close()}
+	j.keys, j.groups, j.nullGroups = nil, nil, nil
+	j.built = false
+	j.memory.ReleaseAll()
+	return errors.Join(errs...)
+}
+
+// GetTupleDesc returns the schema of the left input, which the join outputs.
+func (j *HashSemiJoin) GetTupleDesc() *tuple.TupleDescription {
+	return j.leftChild.GetTupleDesc()
+}
+
+// HasNext checks if more tuples are available.
+func (j *HashSemiJoin) HasNext() (bool, error) {
+	return j.base.HasNext()
+}
+
+// Next returns the next tuple.
+func (j *HashSemiJoin) Next() (*tuple.Tuple, error) {
+	return j.base.Next()
+}
+
+// keyOf returns the fields of t at columns; NULL fields are nil.
+func keyOf(t *tuple.Tuple, columns []primitives.ColumnID) ([]types.Field, error) {
+	key := make([]types.Field, len(columns))
+	for i, col := range columns {
+		field, err := t.GetField(col)
+		if err != nil {
+			return nil, err
+		}
+		key[i] = field
+	}
+	return key, nil
+}
+
+// hasNull reports whether any field of key is NULL.
+func hasNull(key []types.Field) bool {
+	for _, field := range key {
+		if field == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// keySet is a hash set of keys without NULLs.
+type keySet map[primitives.HashCode][][]types.Field
+
+// hashKey combines the hashes of key's fields.
+func hashKey(key []types.Field) (primitives.HashCode, error) {
+	var h primitives.HashCode
+	for _, field := range key {
+		fh, err := field.Hash()
+		if err != nil {
+			return 0, err
+		}
+		h = h*31 + fh
+	}
+	return h, nil
+}
+
+// add inserts key, reporting whether it was new.
+func (s keySet) add(key []types.Field) (bool, error) {
+	found, err := s.contains(key)
+	if err != nil || found {
+		return false, err
+	}
+	h, _ := hashKey(key)
+	s[h] = append(s[h], key)
+	return true, nil
+}
+
+// contains reports whether the set has a key equal to key.
+func (s keySet) contains(key []types.Field) (bool, error) {
+	h, err := hashKey(key)
+	if err != nil {
+		return false, err
+	}
+
+next:
+	for _, candidate := range s[h] {
+		for i, field := range key {
+			equal, err := field.Compare(primitives.Equals, candidate[i])
+			if err != nil {
+				return false, err
+			}
+			if !equal {
+				continue next
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,169 @@
+package join
+
+import (
+	"slices"
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+// semiInput returns an iterator over one-column INT tuples; nil values are
+// NULL.
+func semiInput(values ...interface{}) *mockIterator {
+	td := createTestTupleDesc([]types.Type{types.IntType}, []string{"v"})
+	tuples := make([]*tuple.Tuple, len(values))
+	for i, v := range values {
+		tuples[i] = tuple.NewTuple(td)
+		if v != nil {
+			tuples[i].SetField(0, types.NewIntField(int64(v.(int))))
+		}
+	}
+	return newMockIterator(tuples, td)
+}
+
+// semiRows drains j and returns the first field of each tuple, "NULL" for
+// a NULL field.
+func semiRows(t *testing.T, j iterator.DbIterator) []string {
+	t.Helper()
+	if err := j.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer j.Close()
+
+	var rows []string
+	for {
+		hasNext, err := j.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			return rows
+		}
+		tup, err := j.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		field, _ := tup.GetField(0)
+		if field == nil {
+			rows = append(rows, "NULL")
+		} else {
+			rows = append(rows, field.String())
+		}
+	}
+}
+
+func TestHashSemiJoin_Kinds(t *testing.T) {
+	key := []primitives.ColumnID{0}
+	left := []interface{}{1, 2, 3, nil}
+
+	tests := []struct {
+		name     string
+		kind     SemiJoinKind
+		right    []interface{}
+		expected []string
+	}{
+		{"Semi keeps each match once", Semi, []interface{}{1, 1, 3, nil}, []string{"1", "3"}},
+		{"Anti keeps NULL keys", Anti, []interface{}{1, 1, 3, nil}, []string{"2", "NULL"}},
+		{"NOT IN drops NULL keys", NullAwareAnti, []interface{}{1, 3}, []string{"2"}},
+		{"NOT IN with a NULL keeps nothing", NullAwareAnti, []interface{}{1, nil}, nil},
+		{"NOT IN an empty set keeps everything", NullAwareAnti, nil, []string{"1", "2", "3", "NULL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := NewHashSemiJoin(semiInput(left...), semiInput(tt.right...), key, key, tt.kind)
+			if err != nil {
+				t.Fatalf("NewHashSemiJoin failed: %v", err)
+			}
+			if got := semiRows(t, j); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHashSemiJoin_NullAwareAntiPerGroup(t *testing.T) {
+	td := createTestTupleDesc([]types.Type{types.IntType, types.IntType}, []string{"v", "grp"})
+	pairs := func(values ...[2]interface{}) *mockIterator {
+		tuples := make([]*tuple.Tuple, len(values))
+		for i, v := range values {
+			tuples[i] = tuple.NewTuple(td)
+			for c, x := range v {
+				if x != nil {
+					tuples[i].SetField(primitives.ColumnID(c), types.NewIntField(int64(x.(int))))
+				}
+			}
+		}
+		return newMockIterator(tuples, td)
+	}
+
+	// Group 1 holds {5}, group 2 holds {5, NULL}, group 3 is empty
+	left := pairs([2]interface{}{5, 1}, [2]interface{}{6, 1}, [2]interface{}{6, 2}, [2]interface{}{7, 3}, [2]interface{}{nil, 3})
+	right := pairs([2]interface{}{5, 1}, [2]interface{}{5, 2}, [2]interface{}{nil, 2})
+
+	keys := []primitives.ColumnID{0, 1}
+	j, err := NewHashSemiJoin(left, right, keys, keys, NullAwareAnti)
+	if err != nil {
+		t.Fatalf("NewHashSemiJoin failed: %v", err)
+	}
+	if got, expected := semiRows(t, j), []string{"6", "7", "NULL"}; !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestHashSemiJoin_InvalidKeys(t *testing.T) {
+	if _, err := NewHashSemiJoin(semiInput(), semiInput(), []primitives.ColumnID{0}, nil, Semi); err == nil {
+		t.Error("expected mismatched keys to be rejected")
+	}
+	if _, err := NewHashSemiJoin(semiInput(), semiInput(), nil, nil, NullAwareAnti); err == nil {
+		t.Error("expected a keyless null-aware anti-join to be rejected")
+	}
+}
+
+func TestNestedSemiJoin_Kinds(t *testing.T) {
+	key := primitives.ColumnID(0)
+	left := []interface{}{1, 2, 3, nil}
+
+	// The right input of v holds the values below v, and a NULL below 3
+	right := func(row *tuple.Tuple) (iterator.DbIterator, error) {
+		field, _ := row.GetField(0)
+		if field == nil {
+			return nil, nil
+		}
+		v := int(field.(*types.IntField).Value)
+		var values []interface{}
+		for i := 1; i < v; i++ {
+			values = append(values, i)
+		}
+		if v == 3 {
+			values = append(values, nil)
+		}
+		return semiInput(values...), nil
+	}
+
+	tests := []struct {
+		name     string
+		key      *primitives.ColumnID
+		kind     SemiJoinKind
+		expected []string
+	}{
+		{"EXISTS keeps non-empty inputs", nil, Semi, []string{"2", "3"}},
+		{"NOT EXISTS keeps empty inputs", nil, Anti, []string{"1", "NULL"}},
+		{"IN finds no value below itself", &key, Semi, nil},
+		{"NOT IN drops values beside a NULL", &key, NullAwareAnti, []string{"1", "2", "NULL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := NewNestedSemiJoin(semiInput(left...), right, tt.key, tt.kind)
+			if err != nil {
+				t.Fatalf("NewNestedSemiJoin failed: %v", err)
+			}
+			if got := semiRows(t, j); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
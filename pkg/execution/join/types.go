@@ -18,6 +18,17 @@ type JoinStatistics = common.JoinStatistics
 // Re-export from internal for public API
 type JoinPredicate = common.JoinPredicate
 
+// JoinType is the kind of join: inner, or outer keeping unmatched rows.
+// Re-export from internal for public API
+type JoinType = common.JoinType
+
+const (
+	InnerJoin      = common.InnerJoin
+	LeftOuterJoin  = common.LeftOuterJoin
+	RightOuterJoin = common.RightOuterJoin
+	FullOuterJoin  = common.FullOuterJoin
+)
+
 // NewJoinPredicate creates a new join predicate
 func NewJoinPredicate(field1, field2 primitives.ColumnID, op primitives.Predicate) (JoinPredicate, error) {
 	return common.NewJoinPredicate(field1, field2, op)
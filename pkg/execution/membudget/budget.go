@@ -0,0 +1,300 @@
+// Package membudget accounts for the memory that query execution holds and
+// enforces a limit on it. Blocking operators (sort, hash join, aggregation,
+// DISTINCT and set operations) and result materialization reserve an
+// estimate of every tuple they keep, and fail with ErrOutOfMemoryBudget once
+// a reservation would exceed the per-query or the global budget, instead of
+// letting a single large query take the process down.
+//
+// A Budget holds the global limit shared by all queries. Each query gets its
+// own Tracker from it; operators reserve through an Account, which remembers
+// what its operator holds and returns it when the operator is closed.
+package membudget
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"sync"
+	"unsafe"
+)
+
+// ErrOutOfMemoryBudget is returned (wrapped in a *LimitError) when a query
+// tries to hold more memory than its budget allows.
+var ErrOutOfMemoryBudget = errors.New("out of memory budget")
+
+// Scopes of a memory limit, reported by LimitError.
+const (
+	QueryScope  = "query"
+	GlobalScope = "global"
+)
+
+// LimitError describes a reservation that was refused. It matches
+// ErrOutOfMemoryBudget with errors.Is.
+type LimitError struct {
+	Scope     string // QueryScope or GlobalScope
+	Requested int64  // Bytes the refused reservation asked for
+	Used      int64  // Bytes already reserved in Scope
+	Limit     int64  // Limit of Scope in bytes
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s memory limit of %d bytes exceeded (%d bytes in use, %d requested)",
+		ErrOutOfMemoryBudget, e.Scope, e.Limit, e.Used, e.Requested)
+}
+
+// Is reports whether target is ErrOutOfMemoryBudget.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrOutOfMemoryBudget
+}
+
+// Config limits execution memory. Zero limits are unlimited; with both zero
+// nothing is accounted.
+type Config struct {
+	// QueryBytes limits the memory a single query may hold.
+	QueryBytes int64
+
+	// GlobalBytes limits the memory all running queries may hold together.
+	GlobalBytes int64
+}
+
+// Enabled reports whether the configuration limits anything.
+func (c Config) Enabled() bool {
+	return c.QueryBytes > 0 || c.GlobalBytes > 0
+}
+
+// Stats reports the state of a budget.
+type Stats struct {
+	Used     int64 // Bytes currently reserved by all queries
+	Peak     int64 // Highest Used seen
+	Rejected int64 // Reservations refused by either limit
+}
+
+// Budget is the global memory budget. It is safe for concurrent use.
+type Budget struct {
+	config Config
+
+	mutex sync.Mutex
+	stats Stats
+}
+
+// New creates a budget enforcing config.
+func New(config Config) *Budget {
+	return &Budget{config: config}
+}
+
+// Config returns the limits the budget was created with.
+func (b *Budget) Config() Config {
+	return b.config
+}
+
+// NewTracker returns the tracker for a new query, or nil when the budget
+// limits nothing. A nil tracker accepts every reservation.
+func (b *Budget) NewTracker() *Tracker {
+	if b == nil || !b.config.Enabled() {
+		return nil
+	}
+	return &Tracker{budget: b, limit: b.config.QueryBytes}
+}
+
+// Stats returns a snapshot of the budget statistics.
+func (b *Budget) Stats() Stats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.stats
+}
+
+// reserve takes n bytes from the global budget.
+func (b *Budget) reserve(n int64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.config.GlobalBytes > 0 && b.stats.Used+n > b.config.GlobalBytes {
+		b.stats.Rejected++
+		memoryRejected.Inc()
+		return &LimitError{Scope: GlobalScope, Requested: n, Used: b.stats.Used, Limit: b.config.GlobalBytes}
+	}
+	b.stats.Used += n
+	b.stats.Peak = max(b.stats.Peak, b.stats.Used)
+	memoryReserved.Set(b.stats.Used)
+	return nil
+}
+
+// release returns n bytes to the global budget.
+func (b *Budget) release(n int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.stats.Used -= n
+	memoryReserved.Set(b.stats.Used)
+}
+
+// rejectQuery counts a reservation refused by a query limit.
+func (b *Budget) rejectQuery() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.stats.Rejected++
+	memoryRejected.Inc()
+}
+
+// Tracker accounts for the memory held by one query. Its methods are safe
+// to call on a nil tracker, which accepts every reservation.
+type Tracker struct {
+	budget *Budget
+	limit  int64 // Per-query limit; zero is unlimited
+
+	mutex sync.Mutex
+	used  int64
+	peak  int64
+}
+
+// Reserve takes n bytes for the query. It fails with a *LimitError if the
+// query or the global budget would exceed its limit.
+func (t *Tracker) Reserve(n int64) error {
+	if t == nil || n <= 0 {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.limit > 0 && t.used+n > t.limit {
+		t.budget.rejectQuery()
+		return &LimitError{Scope: QueryScope, Requested: n, Used: t.used, Limit: t.limit}
+	}
+	if err := t.budget.reserve(n); err != nil {
+		return err
+	}
+	t.used += n
+	t.peak = max(t.peak, t.used)
+	return nil
+}
+
+// Release returns n bytes reserved by the query.
+func (t *Tracker) Release(n int64) {
+	if t == nil || n <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	n = min(n, t.used)
+	t.used -= n
+	t.budget.release(n)
+}
+
+// Close returns everything the query still holds to the global budget. It
+// is called once the query has finished, whether or not its operators
+// released their memory.
+func (t *Tracker) Close() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.used > 0 {
+		t.budget.release(t.used)
+		t.used = 0
+	}
+	memoryQueryPeak.Observe(float64(t.peak))
+}
+
+// Used returns the bytes the query currently holds.
+func (t *Tracker) Used() int64 {
+	if t == nil {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.used
+}
+
+// Peak returns the most bytes the query has held at once.
+func (t *Tracker) Peak() int64 {
+	if t == nil {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.peak
+}
+
+// Account is the memory held by one operator. The zero value, with no
+// tracker, accepts every reservation, so operators work unchanged when
+// their query is not accounted.
+type Account struct {
+	tracker  *Tracker
+	reserved int64
+}
+
+// SetTracker attaches the tracker of the operator's query.
+func (a *Account) SetTracker(t *Tracker) {
+	a.tracker = t
+}
+
+// Reserve takes n bytes for the operator.
+func (a *Account) Reserve(n int64) error {
+	if a.tracker == nil {
+		return nil
+	}
+	if err := a.tracker.Reserve(n); err != nil {
+		return err
+	}
+	a.reserved += n
+	return nil
+}
+
+// ReserveTuple takes the estimated size of t, which the operator keeps.
+func (a *Account) ReserveTuple(t *tuple.Tuple) error {
+	if a.tracker == nil {
+		return nil
+	}
+	return a.Reserve(TupleSize(t))
+}
+
+// ReleaseAll returns everything the operator holds, e.g. when it is closed
+// or rewound and drops its tuples.
+func (a *Account) ReleaseAll() {
+	a.tracker.Release(a.reserved)
+	a.reserved = 0
+}
+
+const (
+	tupleOverhead  = int64(unsafe.Sizeof(tuple.Tuple{})) + int64(unsafe.Sizeof((*tuple.Tuple)(nil)))
+	fieldOverhead  = int64(unsafe.Sizeof(types.Field(nil))) // Interface slot in the field slice
+	stringOverhead = int64(unsafe.Sizeof(types.StringField{}))
+)
+
+// TupleSize estimates the bytes a materialized tuple occupies: the tuple
+// itself, its field slice and the values. Strings count their actual length.
+func TupleSize(t *tuple.Tuple) int64 {
+	if t == nil {
+		return 0
+	}
+
+	size := tupleOverhead
+	for i := range t.NumFields() {
+		field, err := t.GetField(i)
+		if err != nil {
+			field = nil
+		}
+		size += fieldOverhead + FieldSize(field)
+	}
+	return size
+}
+
+// FieldSize estimates the bytes a field value occupies, not counting the
+// interface that refers to it. NULL occupies nothing.
+func FieldSize(f types.Field) int64 {
+	if f == nil {
+		return 0
+	}
+	if s, ok := f.(*types.StringField); ok {
+		return stringOverhead + int64(len(s.Value))
+	}
+	return int64(f.Type().Size())
+}
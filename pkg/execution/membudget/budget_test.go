@@ -0,0 +1,131 @@
+package membudget
+
+import (
+	"errors"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+func TestTracker_QueryLimit(t *testing.T) {
+	budget := New(Config{QueryBytes: 100})
+	tracker := budget.NewTracker()
+
+	if err := tracker.Reserve(60); err != nil {
+		t.Fatalf("Reserve(60) failed: %v", err)
+	}
+	err := tracker.Reserve(60)
+	if !errors.Is(err, ErrOutOfMemoryBudget) {
+		t.Fatalf("expected ErrOutOfMemoryBudget, got %v", err)
+	}
+
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Scope != QueryScope || limitErr.Used != 60 || limitErr.Limit != 100 {
+		t.Errorf("unexpected limit error %+v", limitErr)
+	}
+
+	tracker.Release(60)
+	if err := tracker.Reserve(100); err != nil {
+		t.Errorf("Reserve(100) after release failed: %v", err)
+	}
+	if tracker.Peak() != 100 {
+		t.Errorf("expected peak 100, got %d", tracker.Peak())
+	}
+
+	tracker.Close()
+	if stats := budget.Stats(); stats.Used != 0 || stats.Rejected != 1 {
+		t.Errorf("unexpected stats after close %+v", stats)
+	}
+}
+
+func TestTracker_GlobalLimit(t *testing.T) {
+	budget := New(Config{GlobalBytes: 100})
+	first, second := budget.NewTracker(), budget.NewTracker()
+
+	if err := first.Reserve(80); err != nil {
+		t.Fatalf("first Reserve failed: %v", err)
+	}
+
+	var limitErr *LimitError
+	err := second.Reserve(40)
+	if !errors.As(err, &limitErr) || limitErr.Scope != GlobalScope {
+		t.Fatalf("expected a global limit error, got %v", err)
+	}
+	if second.Used() != 0 {
+		t.Errorf("refused reservation counted as used: %d", second.Used())
+	}
+
+	first.Close()
+	if err := second.Reserve(40); err != nil {
+		t.Errorf("Reserve after the first query finished failed: %v", err)
+	}
+}
+
+func TestTracker_NilAcceptsEverything(t *testing.T) {
+	if New(Config{}).NewTracker() != nil {
+		t.Fatal("expected no tracker for an unlimited budget")
+	}
+
+	var tracker *Tracker
+	if err := tracker.Reserve(1 << 40); err != nil {
+		t.Errorf("nil tracker refused a reservation: %v", err)
+	}
+	tracker.Release(10)
+	tracker.Close()
+
+	var account Account
+	if err := account.Reserve(1 << 40); err != nil {
+		t.Errorf("untracked account refused a reservation: %v", err)
+	}
+	account.ReleaseAll()
+}
+
+func TestAccount_ReleaseAll(t *testing.T) {
+	budget := New(Config{QueryBytes: 1000})
+	tracker := budget.NewTracker()
+	defer tracker.Close()
+
+	var sortMemory, hashMemory Account
+	sortMemory.SetTracker(tracker)
+	hashMemory.SetTracker(tracker)
+
+	if err := sortMemory.Reserve(300); err != nil {
+		t.Fatal(err)
+	}
+	if err := hashMemory.Reserve(200); err != nil {
+		t.Fatal(err)
+	}
+
+	sortMemory.ReleaseAll()
+	if tracker.Used() != 200 {
+		t.Errorf("expected 200 bytes in use, got %d", tracker.Used())
+	}
+	sortMemory.ReleaseAll()
+	if tracker.Used() != 200 {
+		t.Errorf("releasing twice changed usage to %d", tracker.Used())
+	}
+}
+
+func TestTupleSize(t *testing.T) {
+	td, err := tuple.NewTupleDesc([]types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short := tuple.NewTuple(td)
+	_ = short.SetField(0, types.NewIntField(1))
+	_ = short.SetField(1, types.NewStringField("a", types.StringMaxSize))
+
+	long := tuple.NewTuple(td)
+	_ = long.SetField(0, types.NewIntField(1))
+	_ = long.SetField(1, types.NewStringField("abcdefghij", types.StringMaxSize))
+
+	if diff := TupleSize(long) - TupleSize(short); diff != 9 {
+		t.Errorf("expected strings to count their length, sizes differ by %d", diff)
+	}
+
+	null := tuple.NewTuple(td)
+	if TupleSize(null) >= TupleSize(short) {
+		t.Errorf("expected NULL fields to be smaller than values")
+	}
+}
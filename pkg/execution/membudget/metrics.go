@@ -0,0 +1,19 @@
+package membudget
+
+import "storemy/pkg/metrics"
+
+var (
+	memoryReserved = metrics.NewGauge(
+		"storemy_query_memory_reserved_bytes",
+		"Bytes of execution memory currently reserved by running queries",
+	)
+	memoryRejected = metrics.NewCounter(
+		"storemy_query_memory_rejected_total",
+		"Memory reservations refused because a query or the global budget was exhausted",
+	)
+	memoryQueryPeak = metrics.NewHistogram(
+		"storemy_query_memory_peak_bytes",
+		"Most execution memory held at once by a finished query",
+		[]float64{1 << 10, 1 << 14, 1 << 17, 1 << 20, 1 << 23, 1 << 26, 1 << 30},
+	)
+)
@@ -0,0 +1,142 @@
+package query
+
+import (
+	"fmt"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/execution/setops"
+	"storemy/pkg/iterator"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// DistinctOn implements SELECT DISTINCT ON (expr, ...): of each group of input
+// tuples whose key expressions evaluate to the same values, only the first is
+// returned. Placed above a Sort, it returns the first row of every group in
+// ORDER BY order.
+//
+// Keys are deduplicated with a hash-based setops.TupleSet, so the operator
+// streams its input and keeps one key tuple per group in memory. NULL keys
+// are equal to each other, as in DISTINCT.
+type DistinctOn struct {
+	*iterator.UnaryOperator
+	keys    []*Expression
+	keyDesc *tuple.TupleDescription
+	seen    *setops.TupleSet
+	opened  bool
+	memory  membudget.Account // Memory held by the seen keys
+}
+
+// NewDistinctOn creates a DistinctOn operator that keeps the first tuple of
+// source for each distinct combination of keys.
+func NewDistinctOn(keys []*Expression, source iterator.DbIterator) (*DistinctOn, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("DISTINCT ON requires at least one key expression")
+	}
+
+	keyTypes := make([]types.Type, len(keys))
+	keyNames := make([]string, len(keys))
+	for i, key := range keys {
+		keyTypes[i] = key.Type()
+		if keyTypes[i] == nullType {
+			keyTypes[i] = types.StringType
+		}
+		keyNames[i] = key.String()
+	}
+
+	keyDesc, err := tuple.NewTupleDesc(keyTypes, keyNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key tuple desc: %v", err)
+	}
+
+	d := &DistinctOn{
+		keys:    keys,
+		keyDesc: keyDesc,
+		seen:    setops.NewTupleSet(false),
+	}
+
+	unaryOp, err := iterator.NewUnaryOperator(source, d.readNext)
+	if err != nil {
+		return nil, err
+	}
+	d.UnaryOperator = unaryOp
+
+	return d, nil
+}
+
+// readNext returns the next source tuple whose key has not been seen yet.
+func (d *DistinctOn) readNext() (*tuple.Tuple, error) {
+	for {
+		t, err := d.FetchNext()
+		if err != nil || t == nil {
+			return t, err
+		}
+
+		key, err := d.keyOf(t)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.seen.Add(key) {
+			if err := d.memory.ReserveTuple(key); err != nil {
+				return nil, fmt.Errorf("cannot remember DISTINCT ON key: %w", err)
+			}
+			return t, nil
+		}
+	}
+}
+
+// SetMemoryTracker accounts the seen keys against the query's memory budget.
+func (d *DistinctOn) SetMemoryTracker(t *membudget.Tracker) {
+	d.memory.SetTracker(t)
+}
+
+// keyOf evaluates the key expressions against t. NULL keys are left unset.
+func (d *DistinctOn) keyOf(t *tuple.Tuple) (*tuple.Tuple, error) {
+	key := tuple.NewTuple(d.keyDesc)
+	for i, expr := range d.keys {
+		field, err := expr.Eval(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate %s: %v", expr, err)
+		}
+		if field == nil {
+			continue
+		}
+
+		if err := key.SetField(primitives.ColumnID(i), field); err != nil {
+			return nil, fmt.Errorf("failed to set key field %d: %v", i, err)
+		}
+	}
+	return key, nil
+}
+
+// Open opens the source and forgets the keys seen by a previous run.
+func (d *DistinctOn) Open() error {
+	if err := d.UnaryOperator.Open(); err != nil {
+		return fmt.Errorf("failed to open child operator: %w", err)
+	}
+
+	d.seen.Clear()
+	d.memory.ReleaseAll()
+	d.opened = true
+	return nil
+}
+
+// Close releases the seen keys and closes the source.
+func (d *DistinctOn) Close() error {
+	d.opened = false
+	d.seen.Clear()
+	d.memory.ReleaseAll()
+	return d.UnaryOperator.Close()
+}
+
+// Rewind restarts the operator from the first source tuple.
+func (d *DistinctOn) Rewind() error {
+	if !d.opened {
+		return fmt.Errorf("distinct on operator not opened")
+	}
+
+	d.seen.Clear()
+	d.memory.ReleaseAll()
+	return d.UnaryOperator.Rewind()
+}
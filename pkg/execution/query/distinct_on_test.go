@@ -0,0 +1,143 @@
+package query
+
+import (
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"testing"
+)
+
+func drainIDs(t *testing.T, it interface {
+	HasNext() (bool, error)
+	Next() (*tuple.Tuple, error)
+}) []string {
+	t.Helper()
+	var ids []string
+	for {
+		hasNext, err := it.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			return ids
+		}
+		tup, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		field, err := tup.GetField(0)
+		if err != nil {
+			t.Fatalf("GetField failed: %v", err)
+		}
+		ids = append(ids, field.String())
+	}
+}
+
+func distinctOnTestTuples(td *tuple.TupleDescription) []*tuple.Tuple {
+	return []*tuple.Tuple{
+		createProjectTestTuple(td, 1, "alice", 30, "a@x"),
+		createProjectTestTuple(td, 2, "bob", 30, "b@x"),
+		createProjectTestTuple(td, 3, "alice", 40, "c@x"),
+		createProjectTestTuple(td, 4, "carol", 10, "d@x"),
+		createProjectTestTuple(td, 5, "alice", 30, "e@x"),
+	}
+}
+
+func TestDistinctOn_FirstRowPerKey(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	child := newMockChildIterator(distinctOnTestTuples(td), td)
+
+	d, err := NewDistinctOn([]*Expression{mustBind(t, col("name"), td)}, child)
+	if err != nil {
+		t.Fatalf("NewDistinctOn failed: %v", err)
+	}
+	if err := d.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer d.Close()
+
+	ids := drainIDs(t, d)
+	expected := []string{"1", "2", "4"}
+	if len(ids) != len(expected) {
+		t.Fatalf("expected ids %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("expected ids %v, got %v", expected, ids)
+			break
+		}
+	}
+
+	if err := d.Rewind(); err != nil {
+		t.Fatalf("Rewind failed: %v", err)
+	}
+	if again := drainIDs(t, d); len(again) != len(expected) {
+		t.Errorf("expected %d tuples after Rewind, got %v", len(expected), again)
+	}
+}
+
+func TestDistinctOn_MultipleKeys(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	child := newMockChildIterator(distinctOnTestTuples(td), td)
+
+	keys := []*Expression{mustBind(t, col("name"), td), mustBind(t, col("age"), td)}
+	d, err := NewDistinctOn(keys, child)
+	if err != nil {
+		t.Fatalf("NewDistinctOn failed: %v", err)
+	}
+	if err := d.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer d.Close()
+
+	if ids := drainIDs(t, d); len(ids) != 4 {
+		t.Errorf("expected 4 distinct (name, age) pairs, got %v", ids)
+	}
+}
+
+func TestDistinctOn_NullKeysAreEqual(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	child := newMockChildIterator(distinctOnTestTuples(td), td)
+
+	// CASE WHEN age < 18 THEN 'minor' END is NULL for every adult.
+	minor := &plan.CaseExpr{
+		Whens: []plan.WhenClause{
+			{Cond: cmp(col("age"), primitives.LessThan, num(18)), Result: str("minor")},
+		},
+	}
+	d, err := NewDistinctOn([]*Expression{mustBind(t, minor, td)}, child)
+	if err != nil {
+		t.Fatalf("NewDistinctOn failed: %v", err)
+	}
+	if err := d.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer d.Close()
+
+	ids := drainIDs(t, d)
+	if len(ids) != 2 || ids[0] != "1" || ids[1] != "4" {
+		t.Errorf("expected ids [1 4], got %v", ids)
+	}
+}
+
+func TestNewDistinctOn_NoKeys(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	child := newMockChildIterator(nil, td)
+
+	if _, err := NewDistinctOn(nil, child); err == nil {
+		t.Error("expected error for DISTINCT ON without keys")
+	}
+}
+
+func TestDistinctOn_RewindBeforeOpen(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	child := newMockChildIterator(nil, td)
+
+	d, err := NewDistinctOn([]*Expression{mustBind(t, col("name"), td)}, child)
+	if err != nil {
+		t.Fatalf("NewDistinctOn failed: %v", err)
+	}
+	if err := d.Rewind(); err == nil {
+		t.Error("expected error rewinding an unopened operator")
+	}
+}
@@ -0,0 +1,460 @@
+package query
+
+import (
+	"fmt"
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+)
+
+// nullType is the result type of a NULL literal. It is compatible with every
+// other type, since NULL can stand in for a value of any type.
+const nullType = types.InvalidType
+
+// Expression is a plan.Expr bound to the schema of the tuples it is evaluated
+// against. Binding resolves column references to field indices and checks the
+// types of every node once, so that evaluating a row cannot fail on a missing
+// column or a type mismatch.
+//
+// Evaluation follows SQL NULL semantics: a comparison with NULL is NULL, AND
+// and OR use three-valued logic and a CASE branch is only taken when its
+// condition is true. NULL is represented by a nil types.Field.
+type Expression struct {
+	expr plan.Expr
+	root evalNode
+}
+
+// evalNode is a bound node of an expression tree.
+type evalNode interface {
+	eval(t *tuple.Tuple) (types.Field, error)
+	resultType() types.Type
+}
+
+// NewExpression binds expr to the tuple schema td.
+// Returns an error if a referenced column does not exist or the operand types
+// of a node are incompatible.
+func NewExpression(expr plan.Expr, td *tuple.TupleDescription) (*Expression, error) {
+	if expr == nil {
+		return nil, fmt.Errorf("expression cannot be nil")
+	}
+	if td == nil {
+		return nil, fmt.Errorf("tuple descriptor cannot be nil")
+	}
+
+	root, err := bindExpr(expr, td)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{expr: expr, root: root}, nil
+}
+
+// Type returns the type of the values the expression produces.
+// An expression that can only produce NULL has type types.InvalidType.
+func (e *Expression) Type() types.Type {
+	return e.root.resultType()
+}
+
+// Eval evaluates the expression against t. A nil field means NULL.
+func (e *Expression) Eval(t *tuple.Tuple) (types.Field, error) {
+	return e.root.eval(t)
+}
+
+// Filter evaluates a boolean expression against t and reports whether it is
+// true. NULL counts as false, as in a SQL WHERE clause.
+func (e *Expression) Filter(t *tuple.Tuple) (bool, error) {
+	v, err := e.root.eval(t)
+	if err != nil {
+		return false, err
+	}
+	return isTrue(v), nil
+}
+
+func (e *Expression) String() string {
+	return e.expr.String()
+}
+
+// bindExpr converts a plan expression into its bound form.
+func bindExpr(expr plan.Expr, td *tuple.TupleDescription) (evalNode, error) {
+	switch e := expr.(type) {
+	case *plan.ColumnExpr:
+		return bindColumn(e, td)
+	case *plan.LiteralExpr:
+		return newLiteralNode(e.Value), nil
+	case *plan.CompareExpr:
+		return bindCompare(e, td)
+	case *plan.LogicalExpr:
+		return bindLogical(e, td)
+	case *plan.CaseExpr:
+		return bindCase(e, td)
+	case *plan.AggregateExpr:
+		return nil, fmt.Errorf("aggregate function %s is not allowed here", e)
+	case *plan.GroupingExpr:
+		return nil, fmt.Errorf("%s is only allowed in the SELECT list and HAVING clause of an aggregate query", e)
+	case *plan.ParamExpr:
+		if !e.Bound {
+			return nil, fmt.Errorf("no value bound to parameter %s", e)
+		}
+		return newLiteralNode(e.Value), nil
+	default:
+		return nil, fmt.Errorf("unsupported expression %s", expr)
+	}
+}
+
+type columnNode struct {
+	index primitives.ColumnID
+	typ   types.Type
+}
+
+func bindColumn(e *plan.ColumnExpr, td *tuple.TupleDescription) (evalNode, error) {
+	name := e.Name
+	if dot := strings.LastIndex(name, "."); dot != -1 {
+		name = name[dot+1:]
+	}
+
+	idx, err := td.FindFieldIndex(name)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := td.TypeAtIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+	return &columnNode{index: idx, typ: typ}, nil
+}
+
+func (n *columnNode) eval(t *tuple.Tuple) (types.Field, error) {
+	return t.GetField(n.index)
+}
+
+func (n *columnNode) resultType() types.Type {
+	return n.typ
+}
+
+type literalNode struct {
+	value types.Field
+}
+
+func newLiteralNode(value types.Field) *literalNode {
+	return &literalNode{value: value}
+}
+
+func (n *literalNode) eval(*tuple.Tuple) (types.Field, error) {
+	return n.value, nil
+}
+
+func (n *literalNode) resultType() types.Type {
+	if n.value == nil {
+		return nullType
+	}
+	return n.value.Type()
+}
+
+type compareNode struct {
+	left, right evalNode
+	op          primitives.Predicate
+}
+
+func bindCompare(e *plan.CompareExpr, td *tuple.TupleDescription) (evalNode, error) {
+	left, err := bindExpr(e.Left, td)
+	if err != nil {
+		return nil, err
+	}
+	right, err := bindExpr(e.Right, td)
+	if err != nil {
+		return nil, err
+	}
+
+	left, right, err = unify(left, right)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compare %s: %v", e, err)
+	}
+
+	if e.Op.IsPatternMatch() {
+		for _, n := range []evalNode{left, right} {
+			if typ := n.resultType(); typ != types.StringType && typ != nullType {
+				return nil, fmt.Errorf("%s requires string operands, got %s", e.Op, typ)
+			}
+		}
+	}
+
+	return &compareNode{left: left, right: right, op: e.Op}, nil
+}
+
+func (n *compareNode) eval(t *tuple.Tuple) (types.Field, error) {
+	l, err := n.left.eval(t)
+	if err != nil || l == nil {
+		return nil, err
+	}
+	r, err := n.right.eval(t)
+	if err != nil || r == nil {
+		return nil, err
+	}
+
+	result, err := l.Compare(n.op, r)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewBoolField(result), nil
+}
+
+func (n *compareNode) resultType() types.Type {
+	return types.BoolType
+}
+
+type logicalNode struct {
+	left, right evalNode
+	op          plan.LogicalOp
+}
+
+func bindLogical(e *plan.LogicalExpr, td *tuple.TupleDescription) (evalNode, error) {
+	left, err := bindBoolean(e.Left, td)
+	if err != nil {
+		return nil, err
+	}
+	right, err := bindBoolean(e.Right, td)
+	if err != nil {
+		return nil, err
+	}
+	return &logicalNode{left: left, right: right, op: e.Op}, nil
+}
+
+// eval applies three-valued logic: false AND NULL is false, true OR NULL is
+// true, and every other combination involving NULL is NULL.
+func (n *logicalNode) eval(t *tuple.Tuple) (types.Field, error) {
+	l, err := n.left.eval(t)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// decisive is the operand value that settles the result on its own.
+	decisive := n.op == plan.OrOp
+	if (l != nil && isTrue(l) == decisive) || (r != nil && isTrue(r) == decisive) {
+		return types.NewBoolField(decisive), nil
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+	return types.NewBoolField(!decisive), nil
+}
+
+func (n *logicalNode) resultType() types.Type {
+	return types.BoolType
+}
+
+type whenNode struct {
+	cond, result evalNode
+}
+
+type caseNode struct {
+	operand evalNode // nil for a searched CASE
+	whens   []whenNode
+	orElse  evalNode // nil when there is no ELSE branch
+	typ     types.Type
+}
+
+func bindCase(e *plan.CaseExpr, td *tuple.TupleDescription) (evalNode, error) {
+	if len(e.Whens) == 0 {
+		return nil, fmt.Errorf("CASE requires at least one WHEN clause")
+	}
+
+	n := &caseNode{whens: make([]whenNode, len(e.Whens))}
+	if e.Operand != nil {
+		operand, err := bindExpr(e.Operand, td)
+		if err != nil {
+			return nil, err
+		}
+		n.operand = operand
+	}
+
+	results := make([]evalNode, 0, len(e.Whens)+1)
+	for i, w := range e.Whens {
+		cond, err := n.bindWhenCondition(w.Cond, td)
+		if err != nil {
+			return nil, err
+		}
+		result, err := bindExpr(w.Result, td)
+		if err != nil {
+			return nil, err
+		}
+		n.whens[i] = whenNode{cond: cond}
+		results = append(results, result)
+	}
+	if e.Else != nil {
+		orElse, err := bindExpr(e.Else, td)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, orElse)
+	}
+
+	typ, err := commonType(results)
+	if err != nil {
+		return nil, fmt.Errorf("CASE branches have incompatible types: %v", err)
+	}
+	for i := range results {
+		if results[i], err = coerce(results[i], typ); err != nil {
+			return nil, fmt.Errorf("CASE branches have incompatible types: %v", err)
+		}
+	}
+
+	for i := range n.whens {
+		n.whens[i].result = results[i]
+	}
+	if e.Else != nil {
+		n.orElse = results[len(results)-1]
+	}
+	n.typ = typ
+	return n, nil
+}
+
+// bindWhenCondition binds the condition of a WHEN clause. In a searched CASE
+// it must be boolean; in a simple CASE it must be comparable with the operand.
+func (n *caseNode) bindWhenCondition(cond plan.Expr, td *tuple.TupleDescription) (evalNode, error) {
+	if n.operand == nil {
+		return bindBoolean(cond, td)
+	}
+
+	value, err := bindExpr(cond, td)
+	if err != nil {
+		return nil, err
+	}
+	operand, value, err := unify(n.operand, value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compare CASE operand with %s: %v", cond, err)
+	}
+	n.operand = operand
+	return value, nil
+}
+
+func (n *caseNode) eval(t *tuple.Tuple) (types.Field, error) {
+	var operand types.Field
+	if n.operand != nil {
+		var err error
+		if operand, err = n.operand.eval(t); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, w := range n.whens {
+		matched, err := n.matches(w, operand, t)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			return w.result.eval(t)
+		}
+	}
+
+	if n.orElse == nil {
+		return nil, nil
+	}
+	return n.orElse.eval(t)
+}
+
+// matches reports whether the WHEN branch w is taken for t.
+func (n *caseNode) matches(w whenNode, operand types.Field, t *tuple.Tuple) (bool, error) {
+	cond, err := w.cond.eval(t)
+	if err != nil {
+		return false, err
+	}
+
+	if n.operand == nil {
+		return isTrue(cond), nil
+	}
+	if operand == nil || cond == nil {
+		return false, nil
+	}
+	return operand.Compare(primitives.Equals, cond)
+}
+
+func (n *caseNode) resultType() types.Type {
+	return n.typ
+}
+
+// bindBoolean binds an expression that must produce a boolean value.
+func bindBoolean(expr plan.Expr, td *tuple.TupleDescription) (evalNode, error) {
+	n, err := bindExpr(expr, td)
+	if err != nil {
+		return nil, err
+	}
+	if typ := n.resultType(); typ != types.BoolType && typ != nullType {
+		return nil, fmt.Errorf("expected a boolean condition, %s is %s", expr, typ)
+	}
+	return n, nil
+}
+
+// unify makes the result types of two nodes match, converting a literal to
+// the type of the other side where that is lossless (e.g. 5 compared with a
+// FLOAT column).
+func unify(a, b evalNode) (evalNode, evalNode, error) {
+	typ, err := commonType([]evalNode{a, b})
+	if err != nil {
+		return nil, nil, err
+	}
+	if a, err = coerce(a, typ); err != nil {
+		return nil, nil, err
+	}
+	if b, err = coerce(b, typ); err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+// commonType picks the type a set of nodes is converted to: the type of the
+// first node that is not a literal, or of the first non-NULL literal if all
+// of them are literals.
+func commonType(nodes []evalNode) (types.Type, error) {
+	typ := nullType
+	for _, n := range nodes {
+		if _, isLiteral := n.(*literalNode); !isLiteral && n.resultType() != nullType {
+			return n.resultType(), nil
+		}
+		if typ == nullType {
+			typ = n.resultType()
+		}
+	}
+	return typ, nil
+}
+
+// coerce returns n converted to typ. Only numeric literals can be converted;
+// any other mismatch is an error.
+func coerce(n evalNode, typ types.Type) (evalNode, error) {
+	from := n.resultType()
+	if from == typ || from == nullType || typ == nullType {
+		return n, nil
+	}
+
+	lit, isLiteral := n.(*literalNode)
+	if !isLiteral || !isNumeric(from) || !isNumeric(typ) {
+		return nil, fmt.Errorf("type mismatch: %s and %s", from, typ)
+	}
+
+	value, err := types.CreateFieldFromConstant(typ, lit.value.String())
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %s to %s: %v", lit.value, typ, err)
+	}
+	return newLiteralNode(value), nil
+}
+
+func isNumeric(t types.Type) bool {
+	switch t {
+	case types.IntType, types.Int32Type, types.Int64Type,
+		types.Uint32Type, types.Uint64Type, types.FloatType:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTrue reports whether v is the boolean TRUE. NULL and non-boolean values are not.
+func isTrue(v types.Field) bool {
+	b, ok := v.(*types.BoolField)
+	return ok && b.Value
+}
@@ -0,0 +1,261 @@
+package query
+
+import (
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+func col(name string) plan.Expr {
+	return &plan.ColumnExpr{Name: name}
+}
+
+func lit(value types.Field) plan.Expr {
+	return &plan.LiteralExpr{Value: value}
+}
+
+func str(s string) plan.Expr {
+	return lit(types.NewStringField(s, types.StringMaxSize))
+}
+
+func num(n int64) plan.Expr {
+	return lit(types.NewIntField(n))
+}
+
+func cmp(left plan.Expr, op primitives.Predicate, right plan.Expr) plan.Expr {
+	return &plan.CompareExpr{Left: left, Op: op, Right: right}
+}
+
+// ageBracket is CASE WHEN age < 18 THEN 'minor' WHEN age < 65 THEN 'adult' ELSE 'senior' END.
+func ageBracket() *plan.CaseExpr {
+	return &plan.CaseExpr{
+		Whens: []plan.WhenClause{
+			{Cond: cmp(col("age"), primitives.LessThan, num(18)), Result: str("minor")},
+			{Cond: cmp(col("age"), primitives.LessThan, num(65)), Result: str("adult")},
+		},
+		Else: str("senior"),
+	}
+}
+
+func mustBind(t *testing.T, expr plan.Expr, td *tuple.TupleDescription) *Expression {
+	t.Helper()
+	bound, err := NewExpression(expr, td)
+	if err != nil {
+		t.Fatalf("NewExpression(%s) failed: %v", expr, err)
+	}
+	return bound
+}
+
+func evalString(t *testing.T, e *Expression, tup *tuple.Tuple) string {
+	t.Helper()
+	v, err := e.Eval(tup)
+	if err != nil {
+		t.Fatalf("Eval(%s) failed: %v", e, err)
+	}
+	if v == nil {
+		return "NULL"
+	}
+	return v.String()
+}
+
+func TestExpression_SearchedCase(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	e := mustBind(t, ageBracket(), td)
+
+	if e.Type() != types.StringType {
+		t.Fatalf("expected STRING result type, got %s", e.Type())
+	}
+
+	tests := []struct {
+		age      int64
+		expected string
+	}{
+		{10, "minor"},
+		{18, "adult"},
+		{64, "adult"},
+		{65, "senior"},
+	}
+	for _, tt := range tests {
+		tup := createProjectTestTuple(td, 1, "x", tt.age, "x@example.com")
+		if got := evalString(t, e, tup); got != tt.expected {
+			t.Errorf("age %d: got %s, expected %s", tt.age, got, tt.expected)
+		}
+	}
+}
+
+func TestExpression_SimpleCase(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	e := mustBind(t, &plan.CaseExpr{
+		Operand: col("name"),
+		Whens: []plan.WhenClause{
+			{Cond: str("alice"), Result: num(1)},
+			{Cond: str("bob"), Result: num(2)},
+		},
+	}, td)
+
+	tests := map[string]string{"alice": "1", "bob": "2", "carol": "NULL"}
+	for name, expected := range tests {
+		tup := createProjectTestTuple(td, 1, name, 30, "x@example.com")
+		if got := evalString(t, e, tup); got != expected {
+			t.Errorf("name %s: got %s, expected %s", name, got, expected)
+		}
+	}
+}
+
+func TestExpression_NullSemantics(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	tup := tuple.NewTuple(td) // every field is NULL
+
+	e := mustBind(t, &plan.CaseExpr{
+		Whens: []plan.WhenClause{{Cond: cmp(col("age"), primitives.GreaterThan, num(0)), Result: str("positive")}},
+		Else:  str("unknown"),
+	}, td)
+	if got := evalString(t, e, tup); got != "unknown" {
+		t.Errorf("expected a NULL condition to fall through to ELSE, got %s", got)
+	}
+
+	falseAndNull := &plan.LogicalExpr{Op: plan.AndOp, Left: lit(types.NewBoolField(false)), Right: cmp(col("age"), primitives.Equals, num(1))}
+	if got := evalString(t, mustBind(t, falseAndNull, td), tup); got != "false" {
+		t.Errorf("FALSE AND NULL = %s, expected false", got)
+	}
+
+	trueOrNull := &plan.LogicalExpr{Op: plan.OrOp, Left: lit(types.NewBoolField(true)), Right: cmp(col("age"), primitives.Equals, num(1))}
+	if got := evalString(t, mustBind(t, trueOrNull, td), tup); got != "true" {
+		t.Errorf("TRUE OR NULL = %s, expected true", got)
+	}
+
+	trueAndNull := &plan.LogicalExpr{Op: plan.AndOp, Left: lit(types.NewBoolField(true)), Right: cmp(col("age"), primitives.Equals, num(1))}
+	if got := evalString(t, mustBind(t, trueAndNull, td), tup); got != "NULL" {
+		t.Errorf("TRUE AND NULL = %s, expected NULL", got)
+	}
+}
+
+func TestExpression_BindErrors(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+
+	tests := []struct {
+		name string
+		expr plan.Expr
+	}{
+		{"unknown column", cmp(col("missing"), primitives.Equals, num(1))},
+		{"type mismatch", cmp(col("age"), primitives.Equals, str("x"))},
+		{"non-boolean WHEN", &plan.CaseExpr{Whens: []plan.WhenClause{{Cond: col("age"), Result: num(1)}}}},
+		{"mixed branch types", &plan.CaseExpr{
+			Whens: []plan.WhenClause{{Cond: cmp(col("age"), primitives.LessThan, num(18)), Result: str("minor")}},
+			Else:  col("age"),
+		}},
+		{"pattern on INT", cmp(col("age"), primitives.Like, str("1%"))},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewExpression(tt.expr, td); err == nil {
+			t.Errorf("%s: expected NewExpression(%s) to fail", tt.name, tt.expr)
+		}
+	}
+}
+
+func TestExpression_CoercesNumericLiterals(t *testing.T) {
+	td, err := tuple.NewTupleDesc([]types.Type{types.FloatType}, []string{"price"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tup := tuple.NewTuple(td)
+	if err := tup.SetField(0, types.NewFloat64Field(9.5)); err != nil {
+		t.Fatal(err)
+	}
+
+	e := mustBind(t, cmp(col("price"), primitives.LessThan, num(10)), td)
+	if got := evalString(t, e, tup); got != "true" {
+		t.Errorf("9.5 < 10 = %s, expected true", got)
+	}
+}
+
+func TestExpressionProjectFilterAndSort(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	tuples := []*tuple.Tuple{
+		createProjectTestTuple(td, 1, "alice", 70, "a@example.com"),
+		createProjectTestTuple(td, 2, "bob", 12, "b@example.com"),
+		createProjectTestTuple(td, 3, "carol", 40, "c@example.com"),
+	}
+
+	// WHERE CASE ... END != 'minor'
+	cond := mustBind(t, cmp(ageBracket(), primitives.NotEqual, str("minor")), td)
+	filter, err := NewExpressionFilter(cond, newMockChildIterator(tuples, td))
+	if err != nil {
+		t.Fatalf("NewExpressionFilter failed: %v", err)
+	}
+
+	// ORDER BY CASE WHEN age >= 65 THEN 0 ELSE 1 END
+	key := mustBind(t, &plan.CaseExpr{
+		Whens: []plan.WhenClause{{Cond: cmp(col("age"), primitives.GreaterThanOrEqual, num(65)), Result: num(0)}},
+		Else:  num(1),
+	}, td)
+	sorted, err := NewSortByExpression(filter, key, false)
+	if err != nil {
+		t.Fatalf("NewSortByExpression failed: %v", err)
+	}
+
+	// SELECT name, CASE ... END
+	exprs := []*Expression{mustBind(t, col("name"), td), mustBind(t, ageBracket(), td)}
+	project, err := NewExpressionProject(exprs, []string{"name", "bracket"}, sorted)
+	if err != nil {
+		t.Fatalf("NewExpressionProject failed: %v", err)
+	}
+
+	if err := project.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer project.Close()
+
+	var got []string
+	for {
+		hasNext, err := project.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			break
+		}
+		row, err := project.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		got = append(got, evalString(t, mustBind(t, col("name"), project.GetTupleDesc()), row)+
+			"/"+evalString(t, mustBind(t, col("bracket"), project.GetTupleDesc()), row))
+	}
+
+	expected := []string{"carol/adult", "alice/senior"}
+	if len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestNewExpressionFilter_RequiresBoolean(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	child := newMockChildIterator(nil, td)
+
+	if _, err := NewExpressionFilter(mustBind(t, ageBracket(), td), child); err == nil {
+		t.Error("expected a STRING expression to be rejected as a filter")
+	}
+}
+
+func TestExpression_Parameters(t *testing.T) {
+	td := mustCreateProjectTupleDesc()
+	param := &plan.ParamExpr{Index: 1}
+	expr := cmp(col("age"), primitives.GreaterThan, param)
+
+	if _, err := NewExpression(expr, td); err == nil {
+		t.Fatal("expected an unbound parameter to fail")
+	}
+
+	param.Bind(types.NewIntField(18))
+	e := mustBind(t, expr, td)
+	if got := evalString(t, e, createProjectTestTuple(td, 1, "a", 30, "a@x")); got != "true" {
+		t.Errorf("expected 30 > $1 to be true, got %s", got)
+	}
+	if got := evalString(t, e, createProjectTestTuple(td, 2, "b", 10, "b@x")); got != "false" {
+		t.Errorf("expected 10 > $1 to be false, got %s", got)
+	}
+}
@@ -4,13 +4,21 @@ import (
 	"fmt"
 	"storemy/pkg/iterator"
 	"storemy/pkg/tuple"
+	"storemy/pkg/types"
 )
 
 // Filter represents a filtering operator that applies a predicate to each tuple
 // from its source operator, only returning tuples that satisfy the predicate condition.
 type Filter struct {
 	*iterator.UnaryOperator
-	predicate *Predicate
+	predicate tupleFilter
+}
+
+// tupleFilter decides whether a tuple passes a Filter. It is implemented by
+// Predicate for simple column comparisons and by Expression for arbitrary
+// boolean expressions.
+type tupleFilter interface {
+	Filter(t *tuple.Tuple) (bool, error)
 }
 
 // NewFilter creates a new Filter operator with the specified predicate and source iterator.
@@ -20,7 +28,22 @@ func NewFilter(predicate *Predicate, source iterator.DbIterator) (*Filter, error
 	if predicate == nil {
 		return nil, fmt.Errorf("predicate cannot be nil")
 	}
+	return newFilter(predicate, source)
+}
+
+// NewExpressionFilter creates a Filter operator that passes only the tuples
+// for which the boolean expression evaluates to true.
+func NewExpressionFilter(expr *Expression, source iterator.DbIterator) (*Filter, error) {
+	if expr == nil {
+		return nil, fmt.Errorf("expression cannot be nil")
+	}
+	if expr.Type() != types.BoolType {
+		return nil, fmt.Errorf("filter expression %s must be boolean, got %s", expr, expr.Type())
+	}
+	return newFilter(expr, source)
+}
 
+func newFilter(predicate tupleFilter, source iterator.DbIterator) (*Filter, error) {
 	f := &Filter{
 		predicate: predicate,
 	}
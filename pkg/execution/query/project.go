@@ -13,10 +13,14 @@ import (
 // exactly which columns should appear in the result set.
 //
 // Conceptually: SELECT col1, col3, col5 FROM table
+//
+// A Project created by NewExpressionProject computes each output field from an
+// Expression instead, e.g. SELECT name, CASE WHEN age > 30 THEN 'senior' END FROM table.
 type Project struct {
 	*iterator.UnaryOperator
 	projectedCols  []primitives.ColumnID
 	projectedTypes []types.Type
+	exprs          []*Expression
 	tupleDesc      *tuple.TupleDescription
 }
 
@@ -52,6 +56,50 @@ func NewProject(projectedCols []primitives.ColumnID, projectedTypes []types.Type
 	return p, nil
 }
 
+// NewExpressionProject creates a Project operator whose output fields are
+// computed by evaluating exprs against each input tuple. names gives the
+// output column names. An expression that can only produce NULL is output as
+// a STRING column.
+func NewExpressionProject(exprs []*Expression, names []string, source iterator.DbIterator) (*Project, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source operator cannot be nil")
+	}
+	if len(exprs) == 0 {
+		return nil, fmt.Errorf("must project at least one field")
+	}
+	if len(exprs) != len(names) {
+		return nil, fmt.Errorf("expression list length (%d) must match names list length (%d)",
+			len(exprs), len(names))
+	}
+
+	fieldTypes := make([]types.Type, len(exprs))
+	for i, expr := range exprs {
+		fieldTypes[i] = expr.Type()
+		if fieldTypes[i] == nullType {
+			fieldTypes[i] = types.StringType
+		}
+	}
+
+	tupleDesc, err := tuple.NewTupleDesc(fieldTypes, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output tuple desc: %v", err)
+	}
+
+	p := &Project{
+		projectedTypes: fieldTypes,
+		exprs:          exprs,
+		tupleDesc:      tupleDesc,
+	}
+
+	unaryOp, err := iterator.NewUnaryOperator(source, p.readNext)
+	if err != nil {
+		return nil, err
+	}
+	p.UnaryOperator = unaryOp
+
+	return p, nil
+}
+
 // validateProjectInputs performs basic validation of constructor parameters
 func validateProjectInputs(projectedCols []primitives.ColumnID, projectedTypes []types.Type, source iterator.DbIterator) error {
 	if source == nil {
@@ -89,12 +137,19 @@ func (p *Project) readNext() (*tuple.Tuple, error) {
 		return t, err
 	}
 
+	if p.exprs != nil {
+		return p.computeTuple(t)
+	}
+
 	projectedTuple := tuple.NewTuple(p.tupleDesc)
 	for i, fieldIndex := range p.projectedCols {
 		field, err := t.GetField(fieldIndex)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get field %d from source tuple: %v", fieldIndex, err)
 		}
+		if field == nil {
+			continue // NULL stays unset
+		}
 
 		if err := projectedTuple.SetField(primitives.ColumnID(i), field); err != nil {
 			return nil, fmt.Errorf("failed to set field %d in projected tuple: %v", i, err)
@@ -105,6 +160,28 @@ func (p *Project) readNext() (*tuple.Tuple, error) {
 	return projectedTuple, nil
 }
 
+// computeTuple creates the output tuple of an expression projection.
+// Fields whose expression evaluates to NULL are left unset.
+func (p *Project) computeTuple(t *tuple.Tuple) (*tuple.Tuple, error) {
+	computed := tuple.NewTuple(p.tupleDesc)
+	for i, expr := range p.exprs {
+		field, err := expr.Eval(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate %s: %v", expr, err)
+		}
+		if field == nil {
+			continue
+		}
+
+		if err := computed.SetField(primitives.ColumnID(i), field); err != nil {
+			return nil, fmt.Errorf("failed to set field %d in projected tuple: %v", i, err)
+		}
+	}
+
+	computed.RecordID = t.RecordID
+	return computed, nil
+}
+
 // validateAndExtractFieldNames validates field indices and extracts corresponding field names
 func validateAndExtractFieldNames(cols []primitives.ColumnID, types []types.Type,
 	td *tuple.TupleDescription) ([]string, error) {
@@ -3,9 +3,11 @@ package query
 import (
 	"fmt"
 	"sort"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/iterator"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
+	"storemy/pkg/types"
 )
 
 // Sort operator orders tuples by a specified field in ascending or descending order.
@@ -25,9 +27,11 @@ type Sort struct {
 	child        iterator.DbIterator
 	sorted       *iterator.SliceIterator[*tuple.Tuple]
 	sortField    primitives.ColumnID // Index of field to sort by
+	sortExpr     *Expression         // Expression to sort by instead of sortField (optional)
 	ascending    bool                // Sort direction: true = ASC, false = DESC
 	opened       bool
-	materialized bool // Flag to track if tuples have been materialized
+	materialized bool              // Flag to track if tuples have been materialized
+	memory       membudget.Account // Memory held by the materialized tuples
 }
 
 // NewSort creates a new Sort operator that orders tuples by the specified field.
@@ -65,6 +69,35 @@ func NewSort(child iterator.DbIterator, sortField primitives.ColumnID, ascending
 	return s, nil
 }
 
+// NewSortByExpression creates a new Sort operator that orders tuples by the
+// value of an expression, e.g. ORDER BY CASE WHEN status = 'urgent' THEN 0 ELSE 1 END.
+// The expression is evaluated once per tuple. Tuples for which it is NULL sort
+// after all others in ascending order and before them in descending order.
+func NewSortByExpression(child iterator.DbIterator, sortExpr *Expression, ascending bool) (*Sort, error) {
+	if child == nil {
+		return nil, fmt.Errorf("child operator cannot be nil")
+	}
+	if sortExpr == nil {
+		return nil, fmt.Errorf("sort expression cannot be nil")
+	}
+
+	s := &Sort{
+		child:     child,
+		sortExpr:  sortExpr,
+		ascending: ascending,
+	}
+
+	s.base = iterator.NewBaseIterator(s.readNext)
+	return s, nil
+}
+
+// SetMemoryTracker accounts the materialized tuples against the query's
+// memory budget, so sorting fails with membudget.ErrOutOfMemoryBudget
+// instead of growing without bound.
+func (s *Sort) SetMemoryTracker(t *membudget.Tracker) {
+	s.memory.SetTracker(t)
+}
+
 // materializeTuples reads all tuples from source and sorts them.
 // This is called once during Open() to prepare the sorted data.
 func (s *Sort) materializeTuples() error {
@@ -91,6 +124,9 @@ func (s *Sort) materializeTuples() error {
 			break
 		}
 
+		if err := s.memory.ReserveTuple(t); err != nil {
+			return fmt.Errorf("cannot materialize tuples to sort: %w", err)
+		}
 		tuples = append(tuples, t)
 	}
 
@@ -105,6 +141,10 @@ func (s *Sort) materializeTuples() error {
 
 // sortTuples sorts the slice of tuples by the sort field using comparison.
 func (s *Sort) sortTuples(tuples []*tuple.Tuple) error {
+	if s.sortExpr != nil {
+		return s.sortByExpression(tuples)
+	}
+
 	var sortErr error
 
 	sort.Slice(tuples, func(i, j int) bool {
@@ -124,8 +164,56 @@ func (s *Sort) sortTuples(tuples []*tuple.Tuple) error {
 	return sortErr
 }
 
+// sortByExpression sorts tuples by the value of the sort expression.
+// Keys are computed up front so that each tuple is evaluated only once.
+func (s *Sort) sortByExpression(tuples []*tuple.Tuple) error {
+	type keyedTuple struct {
+		key types.Field
+		t   *tuple.Tuple
+	}
+
+	keyed := make([]keyedTuple, len(tuples))
+	for i, t := range tuples {
+		key, err := s.sortExpr.Eval(t)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate sort expression: %w", err)
+		}
+		keyed[i] = keyedTuple{key: key, t: t}
+	}
+
+	var sortErr error
+	sort.SliceStable(keyed, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+
+		a, b := keyed[i].key, keyed[j].key
+		if !s.ascending {
+			a, b = b, a
+		}
+
+		// NULL is treated as larger than any value.
+		if a == nil || b == nil {
+			return a != nil
+		}
+
+		lessThan, err := a.Compare(primitives.LessThan, b)
+		if err != nil {
+			sortErr = fmt.Errorf("failed to compare fields: %w", err)
+			return false
+		}
+		return lessThan
+	})
+
+	for i := range keyed {
+		tuples[i] = keyed[i].t
+	}
+	return sortErr
+}
+
 // compare compares two tuples at indices i and j based on the sort field.
-// It extracts the sort field from both tuples and performs a compares them
+// It extracts the sort field from both tuples and performs a compares them.
+// As with sort expressions, NULL is treated as larger than any value.
 //
 // Parameters:
 //   - tuples: slice of tuples to compare
@@ -137,23 +225,22 @@ func (s *Sort) sortTuples(tuples []*tuple.Tuple) error {
 //   - error: any error encountered during field retrieval or comparison
 func (s *Sort) compare(tuples []*tuple.Tuple, i, j int) (bool, error) {
 	field1, err := tuples[i].GetField(s.sortField)
-	if err != nil || field1 == nil {
+	if err != nil {
 		return false, fmt.Errorf("failed to get sort field from tuple %d: %w", i, err)
 	}
 
 	field2, err := tuples[j].GetField(s.sortField)
-	if err != nil || field2 == nil {
+	if err != nil {
 		return false, fmt.Errorf("failed to get sort field from tuple %d: %w", j, err)
 	}
 
-	var lessThan bool
-	if s.ascending {
-		lessThan, err = field1.Compare(primitives.LessThan, field2)
-	} else {
-		lessThan, err = field2.Compare(primitives.LessThan, field1)
+	if !s.ascending {
+		field1, field2 = field2, field1
 	}
-
-	return lessThan, err
+	if field1 == nil || field2 == nil {
+		return field1 != nil, nil
+	}
+	return field1.Compare(primitives.LessThan, field2)
 }
 
 // readNext returns the next tuple from the sorted slice.
@@ -182,6 +269,7 @@ func (s *Sort) Open() error {
 
 	s.opened = true
 	s.materialized = false
+	s.memory.ReleaseAll()
 
 	s.base.MarkOpened()
 	return nil
@@ -195,6 +283,7 @@ func (s *Sort) Close() error {
 	if s.sorted != nil {
 		s.sorted = iterator.NewSliceIterator([]*tuple.Tuple{nil})
 	}
+	s.memory.ReleaseAll()
 
 	if err := s.child.Close(); err != nil {
 		return fmt.Errorf("failed to close source operator: %w", err)
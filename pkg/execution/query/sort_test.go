@@ -1,6 +1,8 @@
 package query
 
 import (
+	"errors"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
 	"testing"
@@ -683,3 +685,36 @@ func collectResults(t *testing.T, iter *Sort) []*tuple.Tuple {
 	}
 	return results
 }
+
+func TestSort_MemoryBudget(t *testing.T) {
+	td := mustCreateSortTupleDesc()
+	tuples := []*tuple.Tuple{
+		createSortTestTuple(td, 3, "three"),
+		createSortTestTuple(td, 1, "one"),
+		createSortTestTuple(td, 2, "two"),
+	}
+
+	budget := membudget.New(membudget.Config{QueryBytes: 2 * membudget.TupleSize(tuples[0])})
+	tracker := budget.NewTracker()
+	defer tracker.Close()
+
+	sort, err := NewSort(newMockChildIterator(tuples, td), 0, true)
+	if err != nil {
+		t.Fatalf("NewSort failed: %v", err)
+	}
+	sort.SetMemoryTracker(tracker)
+
+	if err := sort.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := sort.HasNext(); !errors.Is(err, membudget.ErrOutOfMemoryBudget) {
+		t.Fatalf("expected ErrOutOfMemoryBudget, got %v", err)
+	}
+
+	if err := sort.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if tracker.Used() != 0 {
+		t.Errorf("expected Close to release the sorted tuples, %d bytes still reserved", tracker.Used())
+	}
+}
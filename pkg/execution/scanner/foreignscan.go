@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"fmt"
+	"storemy/pkg/iterator"
+	"storemy/pkg/tuple"
+)
+
+// RowStream yields the rows of an external source one at a time.
+type RowStream interface {
+	// Next returns the next row, or nil once the stream is exhausted.
+	Next() (*tuple.Tuple, error)
+
+	// Close releases the stream's resources.
+	Close() error
+}
+
+// StreamOpener opens a new stream positioned at the first row.
+type StreamOpener func() (RowStream, error)
+
+// ForeignScan iterates over the rows of a foreign table. Rows are parsed from
+// the external file as they are requested rather than loaded up front, so
+// memory use does not grow with the file. Like ViewScan it reads no pages and
+// takes no locks. Rewind reopens the stream and reads the file again.
+type ForeignScan struct {
+	base      *iterator.BaseIterator
+	tupleDesc *tuple.TupleDescription
+	open      StreamOpener
+	stream    RowStream
+}
+
+// NewForeignScan creates a scan over the streams returned by open, whose rows
+// must all conform to td.
+func NewForeignScan(td *tuple.TupleDescription, open StreamOpener) (*ForeignScan, error) {
+	if td == nil {
+		return nil, fmt.Errorf("tuple description cannot be nil")
+	}
+	if open == nil {
+		return nil, fmt.Errorf("stream opener cannot be nil")
+	}
+
+	fs := &ForeignScan{
+		tupleDesc: td,
+		open:      open,
+	}
+	fs.base = iterator.NewBaseIterator(fs.readNext)
+	return fs, nil
+}
+
+// Open opens the underlying stream. Calling Open on an already opened scan
+// keeps the current stream.
+func (fs *ForeignScan) Open() error {
+	if fs.stream != nil {
+		return nil
+	}
+
+	stream, err := fs.open()
+	if err != nil {
+		return err
+	}
+	fs.stream = stream
+	fs.base.MarkOpened()
+	return nil
+}
+
+// Close closes the underlying stream.
+func (fs *ForeignScan) Close() error {
+	var err error
+	if fs.stream != nil {
+		err = fs.stream.Close()
+		fs.stream = nil
+	}
+	if baseErr := fs.base.Close(); err == nil {
+		err = baseErr
+	}
+	return err
+}
+
+// GetTupleDesc returns the schema of the foreign table.
+func (fs *ForeignScan) GetTupleDesc() *tuple.TupleDescription {
+	return fs.tupleDesc
+}
+
+// HasNext checks if there are more rows in the stream.
+func (fs *ForeignScan) HasNext() (bool, error) {
+	return fs.base.HasNext()
+}
+
+// Next returns the next row of the stream.
+func (fs *ForeignScan) Next() (*tuple.Tuple, error) {
+	return fs.base.Next()
+}
+
+// Rewind restarts iteration from the first row by reopening the stream.
+func (fs *ForeignScan) Rewind() error {
+	if fs.stream == nil {
+		return fmt.Errorf("foreign scan not opened")
+	}
+
+	stream, err := fs.open()
+	if err != nil {
+		return err
+	}
+	fs.stream.Close()
+	fs.stream = stream
+	fs.base.ClearCache()
+	return nil
+}
+
+func (fs *ForeignScan) readNext() (*tuple.Tuple, error) {
+	return fs.stream.Next()
+}
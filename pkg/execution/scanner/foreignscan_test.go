@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+type sliceStream struct {
+	rows   []*tuple.Tuple
+	pos    int
+	closed bool
+}
+
+func (s *sliceStream) Next() (*tuple.Tuple, error) {
+	if s.pos >= len(s.rows) {
+		return nil, nil
+	}
+	s.pos++
+	return s.rows[s.pos-1], nil
+}
+
+func (s *sliceStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestForeignScan_StreamsAndRewinds(t *testing.T) {
+	td, _ := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"N"})
+	var streams []*sliceStream
+	open := func() (RowStream, error) {
+		s := &sliceStream{}
+		for i := 0; i < 3; i++ {
+			s.rows = append(s.rows, tuple.NewBuilder(td).AddInt(int64(i)).MustBuild())
+		}
+		streams = append(streams, s)
+		return s, nil
+	}
+
+	scan, err := NewForeignScan(td, open)
+	if err != nil {
+		t.Fatalf("NewForeignScan failed: %v", err)
+	}
+
+	if _, err := scan.HasNext(); err == nil {
+		t.Error("expected HasNext before Open to fail")
+	}
+
+	count := func() int {
+		n := 0
+		for {
+			hasNext, err := scan.HasNext()
+			if err != nil {
+				t.Fatalf("HasNext failed: %v", err)
+			}
+			if !hasNext {
+				return n
+			}
+			if _, err := scan.Next(); err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			n++
+		}
+	}
+
+	if err := scan.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if len(streams) != 1 {
+		t.Fatalf("expected Open to open one stream, got %d", len(streams))
+	}
+
+	if _, err := scan.Next(); err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if streams[0].pos != 1 {
+		t.Errorf("expected rows to be read on demand, stream is at %d", streams[0].pos)
+	}
+	if got := count(); got != 2 {
+		t.Errorf("expected 2 remaining rows, got %d", got)
+	}
+
+	if err := scan.Rewind(); err != nil {
+		t.Fatalf("Rewind failed: %v", err)
+	}
+	if !streams[0].closed || len(streams) != 2 {
+		t.Error("expected Rewind to close the old stream and open a new one")
+	}
+	if got := count(); got != 3 {
+		t.Errorf("expected 3 rows after rewind, got %d", got)
+	}
+
+	if err := scan.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !streams[1].closed {
+		t.Error("expected Close to close the stream")
+	}
+}
@@ -208,7 +208,8 @@ func (is *IndexScan) readNext() (*tuple.Tuple, error) {
 }
 
 // fetchTupleByRID retrieves a tuple from the heap file using its RecordID.
-// This method handles page fetching and tuple extraction.
+// This method handles page fetching and tuple extraction, following the
+// forward pointer left in the slot when the tuple was moved to another page.
 //
 // Returns:
 //   - The tuple at the given RID, or nil if the tuple has been deleted
@@ -225,6 +226,10 @@ func (is *IndexScan) fetchTupleByRID(rid RecID) (*tuple.Tuple, error) {
 		return nil, fmt.Errorf("expected HeapPage, got %T", page)
 	}
 
+	if target, ok := hp.Forward(rid.TupleNum); ok {
+		return is.fetchTupleByRID(target)
+	}
+
 	tup, err := hp.GetTupleAt(rid.TupleNum)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tuple at slot %d: %w", rid.TupleNum, err)
@@ -163,6 +163,159 @@ func BenchmarkSequentialScan(b *testing.B) {
 	}
 }
 
+// BenchmarkSequentialScanReuseTuples compares the allocations of the standard
+// sequential scan with those of tuple reuse mode, which decodes every row into
+// one tuple read straight from the heap file
+func BenchmarkSequentialScanReuseTuples(b *testing.B) {
+	sizes := []int{1000, 10000}
+
+	for _, size := range sizes {
+		setup := setupScanBenchmark(b, size)
+
+		for _, reuse := range []bool{false, true} {
+			b.Run(fmt.Sprintf("tuples_%d/reuse_%t", size, reuse), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					tx := transaction.NewTransactionContext(primitives.NewTransactionID())
+					seqScan, err := NewSeqScan(tx, setup.heapFile.GetID(), setup.heapFile, setup.store)
+					if err != nil {
+						b.Fatalf("Failed to create sequential scan: %v", err)
+					}
+					seqScan.SetReuseTuples(reuse)
+					if err := seqScan.Open(); err != nil {
+						b.Fatalf("Failed to open sequential scan: %v", err)
+					}
+
+					for {
+						hasNext, err := seqScan.HasNext()
+						if err != nil {
+							b.Fatalf("HasNext failed: %v", err)
+						}
+						if !hasNext {
+							break
+						}
+						if _, err := seqScan.Next(); err != nil {
+							b.Fatalf("Next failed: %v", err)
+						}
+					}
+
+					seqScan.Close()
+				}
+			})
+		}
+
+		setup.cleanup()
+	}
+}
+
+// setupCategoryBenchmark writes numPages full pages of (id, category) tuples
+// whose category takes one of numCategories values
+func setupCategoryBenchmark(b *testing.B, numPages, numCategories int) *benchmarkSetup {
+	tempDir := b.TempDir()
+
+	td, err := tuple.NewTupleDesc([]types.Type{types.IntType, types.StringType}, []string{"id", "category"})
+	if err != nil {
+		b.Fatalf("Failed to create tuple description: %v", err)
+	}
+	heapFile, err := heap.NewHeapFile(primitives.Filepath(filepath.Join(tempDir, "category.dat")), td)
+	if err != nil {
+		b.Fatalf("Failed to create heap file: %v", err)
+	}
+	wal, err := wal.NewWAL(filepath.Join(tempDir, "bench.wal"), 4096)
+	if err != nil {
+		b.Fatalf("Failed to create WAL: %v", err)
+	}
+
+	id := int64(0)
+	for pageNo := range numPages {
+		hp, err := heap.NewEmptyHeapPage(page.NewPageDescriptor(heapFile.GetID(), primitives.PageNumber(pageNo)), td)
+		if err != nil {
+			b.Fatalf("Failed to create page: %v", err)
+		}
+		for hp.GetNumEmptySlots() > 0 {
+			tup := tuple.NewTuple(td)
+			tup.SetField(0, types.NewIntField(id))
+			tup.SetField(1, types.NewStringField(fmt.Sprintf("category_%d", id%int64(numCategories)), types.StringMaxSize))
+			if err := hp.AddTuple(tup); err != nil {
+				break
+			}
+			id++
+		}
+		if err := heapFile.WritePage(hp); err != nil {
+			b.Fatalf("Failed to write page: %v", err)
+		}
+	}
+
+	return &benchmarkSetup{
+		heapFile: heapFile,
+		wal:      wal,
+		store:    memory.NewPageStore(wal),
+		td:       td,
+		tempDir:  tempDir,
+	}
+}
+
+// BenchmarkSequentialScanInternStrings counts the rows per category of a
+// table, as a GROUP BY on a low-cardinality column would, with and without
+// interning the category values. Every scan starts on a cold buffer pool.
+func BenchmarkSequentialScanInternStrings(b *testing.B) {
+	setup := setupCategoryBenchmark(b, 500, 8)
+	defer setup.cleanup()
+
+	modes := []struct {
+		name          string
+		reuse, intern bool
+	}{
+		{"default", false, false},
+		{"intern", false, true},
+		{"reuse_intern", true, true},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				setup.store.Close()
+				setup.store = memory.NewPageStore(setup.wal)
+				b.StartTimer()
+
+				tx := transaction.NewTransactionContext(primitives.NewTransactionID())
+				seqScan, err := NewSeqScan(tx, setup.heapFile.GetID(), setup.heapFile, setup.store)
+				if err != nil {
+					b.Fatalf("Failed to create sequential scan: %v", err)
+				}
+				seqScan.SetReuseTuples(mode.reuse)
+				seqScan.SetInternStrings(mode.intern)
+				if err := seqScan.Open(); err != nil {
+					b.Fatalf("Failed to open sequential scan: %v", err)
+				}
+
+				groups := make(map[string]int)
+				for {
+					hasNext, err := seqScan.HasNext()
+					if err != nil {
+						b.Fatalf("HasNext failed: %v", err)
+					}
+					if !hasNext {
+						break
+					}
+					tup, err := seqScan.Next()
+					if err != nil {
+						b.Fatalf("Next failed: %v", err)
+					}
+					category, _ := tup.GetField(1)
+					groups[category.String()]++
+				}
+
+				seqScan.Close()
+			}
+		})
+	}
+}
+
 // BenchmarkParallelScan benchmarks the parallel sequential scan
 func BenchmarkParallelScan(b *testing.B) {
 	sizes := []int{100, 1000, 5000, 10000}
@@ -9,6 +9,7 @@ import (
 	"storemy/pkg/storage/heap"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
+	"storemy/pkg/types"
 )
 
 // SequentialScan implements a sequential scan operator that iterates through all tuples in a table.
@@ -27,7 +28,14 @@ type SequentialScan struct {
 	store           *memory.PageStore
 	tupIter         *tuple.Iterator
 	prefetchEnabled bool
-	prefetchDone    chan struct{} // Signals when prefetch is complete
+	prefetchDone    chan struct{}    // Signals when prefetch is complete
+	ring            *memory.ScanRing // Pages read past the buffer pool by a large scan
+
+	reuseTuples   bool                   // See SetReuseTuples
+	internStrings bool                   // See SetInternStrings
+	filters       []heap.ScanFilter      // See SetFilters
+	columns       []primitives.ColumnID  // See SetColumns
+	fileIter      *heap.HeapFileIterator // Reads committed pages in either mode
 }
 
 // NewSeqScan creates a new SequentialScan operator for the specified table within a transaction context.
@@ -70,10 +78,76 @@ func (ss *SequentialScan) Close() error {
 	if ss.prefetchDone != nil {
 		<-ss.prefetchDone
 	}
+	if ss.fileIter != nil {
+		ss.fileIter.Close()
+		ss.fileIter = nil
+	}
 	ss.dbFile = nil
 	return ss.base.Close()
 }
 
+// SetReuseTuples switches the scan to tuple reuse mode, an opt-in fast path
+// for large scans whose consumer does not keep the tuples it reads, such as
+// an aggregate. Instead of going through the buffer pool, where every cached
+// page holds a decoded tuple per row, the scan reads each page from disk and
+// decodes its rows one at a time into a single tuple it returns from every
+// Next (see heap.HeapFileIterator.ReuseTuple), so the scan allocates almost
+// nothing per row and does not evict other pages from the buffer pool.
+//
+// The consumer must copy any tuple it keeps before the next call to HasNext
+// or Next. The scan sees committed data only and takes no page locks, so the
+// mode is only correct for reads that need no more isolation than that and
+// whose transaction has not written the table. It must be set before Open.
+func (ss *SequentialScan) SetReuseTuples(enabled bool) {
+	ss.reuseTuples = enabled
+}
+
+// SetInternStrings makes the scan intern the string values it reads in a
+// dictionary of its own (see types.StringDict), so that rows repeating a
+// value share one string. On low-cardinality columns this saves an
+// allocation per row and lets a group-by above the scan key its groups
+// with the shared values. The dictionary stops growing after
+// types.DefaultStringDictLimit distinct values.
+//
+// Interning happens as rows are decoded, so like tuple reuse mode, with
+// which it can be combined, the scan reads committed pages from disk and
+// has the same restrictions. It must be set before Open.
+func (ss *SequentialScan) SetInternStrings(enabled bool) {
+	ss.internStrings = enabled
+}
+
+// SetFilters makes the scan return only the rows passing every filter,
+// evaluating them as it reads each page instead of leaving them to a Filter
+// operator above it. The planner pushes simple WHERE comparisons down this
+// way. Reading committed pages from disk, in tuple reuse mode or interning
+// strings, a row failing a filter is skipped after decoding just the
+// filtered fields (see heap.HeapFileIterator.SetFilters). It must be set
+// before Open.
+func (ss *SequentialScan) SetFilters(filters ...heap.ScanFilter) {
+	ss.filters = filters
+}
+
+// Filters returns the filters the scan evaluates, see SetFilters.
+func (ss *SequentialScan) Filters() []heap.ScanFilter {
+	return ss.filters
+}
+
+// SetColumns tells the scan that its consumer, such as the projection of a
+// SELECT list, only uses the given fields of each row. Reading committed
+// pages from disk, in tuple reuse mode or interning strings, the scan then
+// decodes just those fields and leaves the others nil (see
+// heap.HeapFileIterator.SetColumns). Rows read through the buffer pool are
+// already decoded and are returned whole. It must be set before Open.
+func (ss *SequentialScan) SetColumns(columns ...primitives.ColumnID) {
+	ss.columns = columns
+}
+
+// Columns returns the fields the consumer of the scan uses, see SetColumns.
+// None means all of them.
+func (ss *SequentialScan) Columns() []primitives.ColumnID {
+	return ss.columns
+}
+
 // GetTupleDesc returns the tuple description (schema) for tuples produced by this scan.
 // The schema describes the structure, field names, and types of tuples in the target table.
 func (ss *SequentialScan) GetTupleDesc() *tuple.TupleDescription {
@@ -108,7 +182,7 @@ func (ss *SequentialScan) prefetchNextPage(nextPageNum primitives.PageNumber) {
 		defer close(ss.prefetchDone)
 
 		nextPID := page.NewPageDescriptor(ss.tableID, nextPageNum)
-		_, _ = ss.store.GetPage(ss.tx, ss.dbFile, nextPID, transaction.ReadOnly)
+		_, _ = ss.getPage(nextPID)
 		// Ignore errors in prefetch - the main read will handle them
 	}()
 }
@@ -119,11 +193,36 @@ func (ss *SequentialScan) prefetchNextPage(nextPageNum primitives.PageNumber) {
 //
 // The implementation includes page prefetching: when moving to a new page,
 // it asynchronously prefetches the next page in the background to reduce I/O wait time.
+//
+// A scan of a table larger than a quarter of the buffer pool (see
+// memory.PageStore.IsLargeScan) reads the pages missing from the pool
+// through a scan ring rather than caching them, so that it does not flush
+// the pages of other statements out of the pool.
 func (ss *SequentialScan) readNext() (*tuple.Tuple, error) {
 	if ss.dbFile == nil {
 		return nil, fmt.Errorf("database file not initialized")
 	}
+	if ss.reuseTuples || ss.internStrings {
+		return ss.readNextFromFile()
+	}
 
+	for {
+		t, err := ss.readNextFromPool()
+		if err != nil || t == nil || len(ss.filters) == 0 {
+			return t, err
+		}
+		ok, err := heap.MatchAll(ss.filters, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter tuple: %v", err)
+		}
+		if ok {
+			return t, nil
+		}
+	}
+}
+
+// readNextFromPool reads the next tuple through the buffer pool.
+func (ss *SequentialScan) readNextFromPool() (*tuple.Tuple, error) {
 	numPages, err := ss.dbFile.NumPages()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get number of pages: %v", err)
@@ -139,6 +238,10 @@ func (ss *SequentialScan) readNext() (*tuple.Tuple, error) {
 		}
 	}
 
+	if ss.currentPage < 0 && ss.ring == nil && ss.store.IsLargeScan(int(numPages)) {
+		ss.ring = memory.NewScanRing(memory.DefaultScanRingSize)
+	}
+
 	for {
 		ss.currentPage++
 		if ss.currentPage >= int64(numPages) {
@@ -148,7 +251,7 @@ func (ss *SequentialScan) readNext() (*tuple.Tuple, error) {
 		<-ss.prefetchDone
 
 		pid := page.NewPageDescriptor(ss.tableID, primitives.PageNumber(ss.currentPage))
-		page, err := ss.store.GetPage(ss.tx, ss.dbFile, pid, transaction.ReadOnly)
+		page, err := ss.getPage(pid)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get page %d: %v", pid, err)
 		}
@@ -176,6 +279,15 @@ func (ss *SequentialScan) readNext() (*tuple.Tuple, error) {
 	}
 }
 
+// getPage reads a page of the table for the scan, through the scan ring if
+// the scan is large.
+func (ss *SequentialScan) getPage(pid *page.PageDescriptor) (page.Page, error) {
+	if ss.ring != nil {
+		return ss.store.GetScanPage(ss.tx, ss.dbFile, pid, ss.ring)
+	}
+	return ss.store.GetPage(ss.tx, ss.dbFile, pid, transaction.ReadOnly)
+}
+
 // Rewind resets the SequentialScan operator to the beginning of the table.
 // This allows the scan to be re-executed from the start, which is useful
 // for operations that need to scan the table multiple times.
@@ -189,8 +301,38 @@ func (ss *SequentialScan) Rewind() error {
 	ss.currentPage = -1
 	ss.tupIter = nil
 	ss.base.ClearCache()
+	if ss.fileIter != nil {
+		if err := ss.fileIter.Rewind(); err != nil {
+			return err
+		}
+	}
 
 	ss.prefetchDone = make(chan struct{})
 	close(ss.prefetchDone)
 	return nil
 }
+
+// readNextFromFile reads the next tuple straight from the heap file, in tuple
+// reuse mode or interning strings.
+func (ss *SequentialScan) readNextFromFile() (*tuple.Tuple, error) {
+	if ss.fileIter == nil {
+		ss.fileIter = heap.NewHeapFileIterator(ss.dbFile, ss.tx.ID)
+		if ss.reuseTuples {
+			ss.fileIter.ReuseTuple(tuple.NewTuple(ss.tupleDesc))
+		}
+		if ss.internStrings {
+			ss.fileIter.InternStrings(types.NewStringDict(types.DefaultStringDictLimit))
+		}
+		ss.fileIter.SetFilters(ss.filters)
+		ss.fileIter.SetColumns(ss.columns)
+		if err := ss.fileIter.Open(); err != nil {
+			return nil, fmt.Errorf("failed to open file iterator: %v", err)
+		}
+	}
+
+	hasNext, err := ss.fileIter.HasNext()
+	if err != nil || !hasNext {
+		return nil, err
+	}
+	return ss.fileIter.Next()
+}
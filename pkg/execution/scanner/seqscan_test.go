@@ -13,6 +13,7 @@ import (
 	"sync"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 // testSetup holds common test resources
@@ -342,3 +343,288 @@ func TestSeqScanPrefetchingConcurrency(t *testing.T) {
 		}
 	}
 }
+
+// TestSeqScanReuseTuples verifies that tuple reuse mode returns the same rows
+// through one tuple, and again after Rewind
+func TestSeqScanReuseTuples(t *testing.T) {
+	setup := setupSeqScanTest(t, []types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+	defer setup.cleanup()
+
+	totalTuples := 35
+	setup.insertTuples(t, totalTuples, func(i int, td *tuple.TupleDescription) *tuple.Tuple {
+		tup := tuple.NewTuple(td)
+		tup.SetField(0, types.NewIntField(int64(i)))
+		tup.SetField(1, types.NewStringField("tuple", 128))
+		return tup
+	})
+
+	seqScan, err := NewSeqScan(setup.tx, setup.heapFile.GetID(), setup.heapFile, setup.store)
+	if err != nil {
+		t.Fatalf("Failed to create sequential scan: %v", err)
+	}
+	seqScan.SetReuseTuples(true)
+	if err := seqScan.Open(); err != nil {
+		t.Fatalf("Failed to open sequential scan: %v", err)
+	}
+	defer seqScan.Close()
+
+	for pass := 0; pass < 2; pass++ {
+		var first *tuple.Tuple
+		sum := int64(0)
+		count := 0
+		for {
+			hasNext, err := seqScan.HasNext()
+			if err != nil {
+				t.Fatalf("HasNext failed: %v", err)
+			}
+			if !hasNext {
+				break
+			}
+			tup, err := seqScan.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if first == nil {
+				first = tup
+			} else if tup != first {
+				t.Fatal("Expected every row in the same tuple")
+			}
+			field, _ := tup.GetField(0)
+			sum += field.(*types.IntField).Value
+			count++
+		}
+
+		if count != totalTuples || sum != int64(totalTuples*(totalTuples-1)/2) {
+			t.Errorf("Pass %d: got %d tuples with id sum %d", pass, count, sum)
+		}
+		if err := seqScan.Rewind(); err != nil {
+			t.Fatalf("Rewind failed: %v", err)
+		}
+	}
+}
+
+// TestSeqScanFilters verifies that a scan with filters returns only the rows
+// passing them, whether it reads through the buffer pool or from disk
+func TestSeqScanFilters(t *testing.T) {
+	setup := setupSeqScanTest(t, []types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+	defer setup.cleanup()
+
+	setup.insertTuples(t, 35, func(i int, td *tuple.TupleDescription) *tuple.Tuple {
+		tup := tuple.NewTuple(td)
+		tup.SetField(0, types.NewIntField(int64(i)))
+		tup.SetField(1, types.NewStringField("tuple", 128))
+		return tup
+	})
+
+	for _, reuse := range []bool{false, true} {
+		seqScan, err := NewSeqScan(setup.tx, setup.heapFile.GetID(), setup.heapFile, setup.store)
+		if err != nil {
+			t.Fatalf("Failed to create sequential scan: %v", err)
+		}
+		seqScan.SetReuseTuples(reuse)
+		seqScan.SetFilters(heap.ScanFilter{Field: 0, Op: primitives.LessThan, Value: types.NewIntField(12)})
+		if err := seqScan.Open(); err != nil {
+			t.Fatalf("Failed to open sequential scan: %v", err)
+		}
+
+		count := 0
+		for {
+			hasNext, err := seqScan.HasNext()
+			if err != nil {
+				t.Fatalf("HasNext failed: %v", err)
+			}
+			if !hasNext {
+				break
+			}
+			tup, err := seqScan.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if field, _ := tup.GetField(0); field.(*types.IntField).Value >= 12 {
+				t.Errorf("reuse=%v: row %s fails the filter", reuse, tup)
+			}
+			count++
+		}
+		seqScan.Close()
+
+		if count != 12 {
+			t.Errorf("reuse=%v: got %d rows, want 12", reuse, count)
+		}
+	}
+}
+
+// TestSeqScanColumns verifies that a scan reading pages from disk decodes
+// only the columns its consumer uses, and that one reading through the
+// buffer pool returns whole rows
+func TestSeqScanColumns(t *testing.T) {
+	setup := setupSeqScanTest(t, []types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+	defer setup.cleanup()
+
+	setup.insertTuples(t, 10, func(i int, td *tuple.TupleDescription) *tuple.Tuple {
+		tup := tuple.NewTuple(td)
+		tup.SetField(0, types.NewIntField(int64(i)))
+		tup.SetField(1, types.NewStringField("tuple", 128))
+		return tup
+	})
+
+	for _, reuse := range []bool{false, true} {
+		seqScan, err := NewSeqScan(setup.tx, setup.heapFile.GetID(), setup.heapFile, setup.store)
+		if err != nil {
+			t.Fatalf("Failed to create sequential scan: %v", err)
+		}
+		seqScan.SetReuseTuples(reuse)
+		seqScan.SetColumns(0)
+		if err := seqScan.Open(); err != nil {
+			t.Fatalf("Failed to open sequential scan: %v", err)
+		}
+
+		count := 0
+		for {
+			hasNext, err := seqScan.HasNext()
+			if err != nil {
+				t.Fatalf("HasNext failed: %v", err)
+			}
+			if !hasNext {
+				break
+			}
+			tup, err := seqScan.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if id, _ := tup.GetField(0); id == nil {
+				t.Errorf("reuse=%v: id not decoded", reuse)
+			}
+			if name, _ := tup.GetField(1); (name == nil) != reuse {
+				t.Errorf("reuse=%v: name = %v", reuse, name)
+			}
+			count++
+		}
+		seqScan.Close()
+
+		if count != 10 {
+			t.Errorf("reuse=%v: got %d rows, want 10", reuse, count)
+		}
+	}
+}
+
+// TestSeqScanInternStrings verifies that rows repeating a string value share
+// one string when the scan interns strings
+func TestSeqScanInternStrings(t *testing.T) {
+	setup := setupSeqScanTest(t, []types.Type{types.IntType, types.StringType}, []string{"id", "color"})
+	defer setup.cleanup()
+
+	colors := []string{"red", "green", "blue"}
+	totalTuples := 30
+	setup.insertTuples(t, totalTuples, func(i int, td *tuple.TupleDescription) *tuple.Tuple {
+		tup := tuple.NewTuple(td)
+		tup.SetField(0, types.NewIntField(int64(i)))
+		tup.SetField(1, types.NewStringField(colors[i%len(colors)], 128))
+		return tup
+	})
+
+	seqScan, err := NewSeqScan(setup.tx, setup.heapFile.GetID(), setup.heapFile, setup.store)
+	if err != nil {
+		t.Fatalf("Failed to create sequential scan: %v", err)
+	}
+	seqScan.SetInternStrings(true)
+	if err := seqScan.Open(); err != nil {
+		t.Fatalf("Failed to open sequential scan: %v", err)
+	}
+	defer seqScan.Close()
+
+	values := make(map[string]*byte)
+	count := 0
+	for {
+		hasNext, err := seqScan.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			break
+		}
+		tup, err := seqScan.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		field, _ := tup.GetField(1)
+		color := field.(*types.StringField).Value
+		if data, ok := values[color]; ok && data != unsafe.StringData(color) {
+			t.Errorf("Expected every %s row to share one string", color)
+		}
+		values[color] = unsafe.StringData(color)
+		count++
+	}
+
+	if count != totalTuples || len(values) != len(colors) {
+		t.Errorf("Got %d tuples with %d colors, want %d and %d", count, len(values), totalTuples, len(colors))
+	}
+}
+
+// TestSeqScanLargeScanBypassesBufferPool verifies that a scan of a table
+// larger than a quarter of the buffer pool reads it through a scan ring,
+// leaving the pool as it was
+func TestSeqScanLargeScanBypassesBufferPool(t *testing.T) {
+	setup := setupSeqScanTest(t, []types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+	defer setup.cleanup()
+
+	totalTuples := 200
+	setup.insertTuples(t, totalTuples, func(i int, td *tuple.TupleDescription) *tuple.Tuple {
+		tup := tuple.NewTuple(td)
+		tup.SetField(0, types.NewIntField(int64(i)))
+		tup.SetField(1, types.NewStringField("tuple", 128))
+		return tup
+	})
+
+	scan := func(store *memory.PageStore) int {
+		t.Helper()
+		tx := transaction.NewTransactionContext(primitives.NewTransactionID())
+		defer store.CommitTransaction(tx)
+
+		seqScan, err := NewSeqScan(tx, setup.heapFile.GetID(), setup.heapFile, store)
+		if err != nil {
+			t.Fatalf("Failed to create sequential scan: %v", err)
+		}
+		if err := seqScan.Open(); err != nil {
+			t.Fatalf("Failed to open sequential scan: %v", err)
+		}
+		defer seqScan.Close()
+
+		count := 0
+		for {
+			hasNext, err := seqScan.HasNext()
+			if err != nil {
+				t.Fatalf("HasNext failed: %v", err)
+			}
+			if !hasNext {
+				return count
+			}
+			if _, err := seqScan.Next(); err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			count++
+		}
+	}
+
+	// 20 pages are more than a quarter of a 16 page pool
+	small := memory.NewPageStore(setup.wal)
+	if _, err := small.SetCapacity(memory.MinPageCount); err != nil {
+		t.Fatalf("SetCapacity failed: %v", err)
+	}
+	before := small.Stats().ScanReads
+	if count := scan(small); count != totalTuples {
+		t.Errorf("Large scan returned %d tuples, expected %d", count, totalTuples)
+	}
+	if stats := small.Stats(); stats.CachedPages != 0 || stats.ScanReads-before != 20 {
+		t.Errorf("Large scan left %d pages cached and read %d through its ring, expected 0 and 20",
+			stats.CachedPages, stats.ScanReads-before)
+	}
+
+	// The same table is a small scan for the default pool, which caches it
+	large := memory.NewPageStore(setup.wal)
+	if count := scan(large); count != totalTuples {
+		t.Errorf("Small scan returned %d tuples, expected %d", count, totalTuples)
+	}
+	if cached := large.Stats().CachedPages; cached != 20 {
+		t.Errorf("Small scan left %d pages cached, expected 20", cached)
+	}
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"fmt"
+	"storemy/pkg/iterator"
+	"storemy/pkg/tuple"
+)
+
+// RowSource produces the rows of a virtual table.
+type RowSource func() ([]*tuple.Tuple, error)
+
+// ViewScan iterates over the rows of a virtual table such as a system view.
+// Unlike SequentialScan it reads no pages and takes no locks: the rows are
+// produced by a RowSource when the scan is opened, and Rewind replays that
+// same snapshot so that every pass over the scan sees consistent data.
+type ViewScan struct {
+	base      *iterator.BaseIterator
+	tupleDesc *tuple.TupleDescription
+	source    RowSource
+	rows      []*tuple.Tuple
+	pos       int
+	opened    bool
+}
+
+// NewViewScan creates a scan over the rows returned by source, all of which
+// must conform to td.
+func NewViewScan(td *tuple.TupleDescription, source RowSource) (*ViewScan, error) {
+	if td == nil {
+		return nil, fmt.Errorf("tuple description cannot be nil")
+	}
+	if source == nil {
+		return nil, fmt.Errorf("row source cannot be nil")
+	}
+
+	vs := &ViewScan{
+		tupleDesc: td,
+		source:    source,
+	}
+	vs.base = iterator.NewBaseIterator(vs.readNext)
+	return vs, nil
+}
+
+// Open takes a snapshot of the view's rows. Calling Open on an already
+// opened scan keeps the existing snapshot.
+func (vs *ViewScan) Open() error {
+	if vs.opened {
+		return nil
+	}
+
+	rows, err := vs.source()
+	if err != nil {
+		return fmt.Errorf("failed to read view rows: %v", err)
+	}
+	vs.rows = rows
+	vs.pos = 0
+	vs.opened = true
+	vs.base.MarkOpened()
+	return nil
+}
+
+// Close releases the row snapshot.
+func (vs *ViewScan) Close() error {
+	vs.rows = nil
+	vs.opened = false
+	return vs.base.Close()
+}
+
+// GetTupleDesc returns the schema of the view.
+func (vs *ViewScan) GetTupleDesc() *tuple.TupleDescription {
+	return vs.tupleDesc
+}
+
+// HasNext checks if there are more rows in the snapshot.
+func (vs *ViewScan) HasNext() (bool, error) {
+	return vs.base.HasNext()
+}
+
+// Next returns the next row of the snapshot.
+func (vs *ViewScan) Next() (*tuple.Tuple, error) {
+	return vs.base.Next()
+}
+
+// Rewind restarts iteration from the first row of the same snapshot.
+func (vs *ViewScan) Rewind() error {
+	if !vs.opened {
+		return fmt.Errorf("view scan not opened")
+	}
+
+	vs.pos = 0
+	vs.base.ClearCache()
+	return nil
+}
+
+func (vs *ViewScan) readNext() (*tuple.Tuple, error) {
+	if vs.pos >= len(vs.rows) {
+		return nil, nil
+	}
+
+	t := vs.rows[vs.pos]
+	vs.pos++
+	return t, nil
+}
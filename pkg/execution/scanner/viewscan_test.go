@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+func TestViewScan_SnapshotPerOpen(t *testing.T) {
+	td, _ := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"N"})
+	calls := 0
+	source := func() ([]*tuple.Tuple, error) {
+		calls++
+		rows := make([]*tuple.Tuple, 0, calls)
+		for i := 0; i < calls; i++ {
+			rows = append(rows, tuple.NewBuilder(td).AddInt(int64(i)).MustBuild())
+		}
+		return rows, nil
+	}
+
+	scan, err := NewViewScan(td, source)
+	if err != nil {
+		t.Fatalf("NewViewScan failed: %v", err)
+	}
+
+	count := func() int {
+		n := 0
+		for {
+			hasNext, err := scan.HasNext()
+			if err != nil {
+				t.Fatalf("HasNext failed: %v", err)
+			}
+			if !hasNext {
+				return n
+			}
+			if _, err := scan.Next(); err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			n++
+		}
+	}
+
+	if err := scan.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got := count(); got != 1 {
+		t.Errorf("expected 1 row, got %d", got)
+	}
+
+	if err := scan.Rewind(); err != nil {
+		t.Fatalf("Rewind failed: %v", err)
+	}
+	if got := count(); got != 1 {
+		t.Errorf("expected rewind to replay the same snapshot, got %d rows", got)
+	}
+
+	scan.Close()
+	if err := scan.Open(); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if got := count(); got != 2 {
+		t.Errorf("expected reopen to take a new snapshot with 2 rows, got %d", got)
+	}
+}
@@ -2,6 +2,7 @@ package setops
 
 import (
 	"fmt"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/iterator"
 	"storemy/pkg/tuple"
 )
@@ -30,6 +31,7 @@ type Distinct struct {
 	*iterator.UnaryOperator
 	seen   *TupleSet // Tracks unique tuples already emitted
 	opened bool
+	memory membudget.Account // Memory held by the seen tuples
 }
 
 // NewDistinct creates a new Distinct operator that removes duplicates from input.
@@ -58,6 +60,11 @@ func NewDistinct(child iterator.DbIterator) (*Distinct, error) {
 	return d, nil
 }
 
+// SetMemoryTracker accounts the seen tuples against the query's memory budget.
+func (d *Distinct) SetMemoryTracker(t *membudget.Tracker) {
+	d.memory.SetTracker(t)
+}
+
 // readNext implements the core distinct logic.
 // Called by BaseIterator to fetch the next unique tuple.
 //
@@ -79,6 +86,9 @@ func (d *Distinct) readNext() (*tuple.Tuple, error) {
 		}
 
 		if d.seen.Add(t) {
+			if err := d.memory.ReserveTuple(t); err != nil {
+				return nil, fmt.Errorf("cannot remember distinct tuple: %w", err)
+			}
 			return t, nil
 		}
 	}
@@ -94,6 +104,7 @@ func (d *Distinct) Open() error {
 	}
 
 	d.seen.Clear()
+	d.memory.ReleaseAll()
 	d.opened = true
 
 	return nil
@@ -103,6 +114,7 @@ func (d *Distinct) Open() error {
 func (d *Distinct) Close() error {
 	d.opened = false
 	d.seen.Clear()
+	d.memory.ReleaseAll()
 
 	return d.UnaryOperator.Close()
 }
@@ -115,5 +127,6 @@ func (d *Distinct) Rewind() error {
 	}
 
 	d.seen.Clear()
+	d.memory.ReleaseAll()
 	return d.UnaryOperator.Rewind()
 }
@@ -74,6 +74,9 @@ func (ex *Except) readNext() (*tuple.Tuple, error) {
 			}
 
 			ex.tracker.MarkSeen(t)
+			if err := ex.remember(t); err != nil {
+				return nil, err
+			}
 			return t, nil
 		}
 	}
@@ -0,0 +1,215 @@
+package setops
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/iterator"
+	"storemy/pkg/tuple"
+)
+
+// ErrRecursionLimit is returned (wrapped) when a RecursiveUnion evaluates its
+// recursive term more times than its iteration limit allows.
+var ErrRecursionLimit = errors.New("recursion limit exceeded")
+
+// RecursiveStep builds the recursive term of a RecursiveUnion over working,
+// the rows the previous evaluation produced. The returned iterator is not
+// open yet.
+type RecursiveStep func(working []*tuple.Tuple) (iterator.DbIterator, error)
+
+// RecursiveUnion evaluates a recursive query, anchor UNION [ALL] recursive
+// term, as WITH RECURSIVE needs. It outputs the rows of the anchor, then
+// evaluates the recursive term over them, outputs its rows, evaluates it
+// again over those, and so on until an evaluation produces no rows.
+//
+// The rows one evaluation reads, the working table, are the rows the
+// previous one output: only the new rows, never all rows so far. For UNION,
+// a row already output is dropped rather than output and fed back again, so
+// the recursion stops on cyclic data; UNION ALL keeps every row and only
+// stops on cyclic data at the iteration limit.
+//
+// Complexity:
+//   - Time: O(total rows) plus building the recursive term once per iteration
+//   - Space: O(largest working table) for UNION ALL, O(total rows) for UNION
+type RecursiveUnion struct {
+	base          *iterator.BaseIterator
+	anchor        iterator.DbIterator
+	step          RecursiveStep
+	unionAll      bool
+	maxIterations int64
+
+	seen      *TupleSet           // Rows output so far, for UNION
+	current   iterator.DbIterator // Input being read: the anchor, then each evaluation
+	iteration int64               // Evaluations of the recursive term so far
+	working   []*tuple.Tuple      // Rows the current input produced so far
+
+	// memory holds the rows of working, and for UNION every row output.
+	// For UNION ALL, readMemory holds the rows the current evaluation reads,
+	// which are released once it is exhausted.
+	memory, readMemory membudget.Account
+}
+
+// NewRecursiveUnion creates a recursive union of anchor and the recursive
+// term built by step, which may be evaluated at most maxIterations times.
+func NewRecursiveUnion(anchor iterator.DbIterator, step RecursiveStep, unionAll bool, maxIterations int64) (*RecursiveUnion, error) {
+	if anchor == nil || step == nil {
+		return nil, fmt.Errorf("anchor and recursive step cannot be nil")
+	}
+	if maxIterations < 1 {
+		return nil, fmt.Errorf("iteration limit must be at least 1, got %d", maxIterations)
+	}
+
+	r := &RecursiveUnion{
+		anchor:        anchor,
+		step:          step,
+		unionAll:      unionAll,
+		maxIterations: maxIterations,
+	}
+	r.base = iterator.NewBaseIterator(r.readNext)
+	return r, nil
+}
+
+// SetMemoryTracker accounts the rows the union buffers against the query's
+// memory budget. It must be called before Open.
+func (r *RecursiveUnion) SetMemoryTracker(t *membudget.Tracker) {
+	r.memory.SetTracker(t)
+	r.readMemory.SetTracker(t)
+}
+
+// Open opens the anchor.
+func (r *RecursiveUnion) Open() error {
+	if err := r.anchor.Open(); err != nil {
+		return fmt.Errorf("failed to open anchor: %w", err)
+	}
+	r.reset()
+	r.base.MarkOpened()
+	return nil
+}
+
+// reset starts the union over from the anchor.
+func (r *RecursiveUnion) reset() {
+	r.seen = NewTupleSet(false)
+	r.current = r.anchor
+	r.iteration = 0
+	r.working = nil
+	r.memory.ReleaseAll()
+	r.readMemory.ReleaseAll()
+}
+
+// readNext returns the next row of the current input that the union keeps,
+// evaluating the recursive term again once the input is exhausted.
+func (r *RecursiveUnion) readNext() (*tuple.Tuple, error) {
+	for r.current != nil {
+		hasNext, err := r.current.HasNext()
+		if err != nil {
+			return nil, err
+		}
+		if !hasNext {
+			if err := r.nextIteration(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		t, err := r.current.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !r.unionAll && !r.seen.Add(t) {
+			continue
+		}
+		if err := r.memory.ReserveTuple(t); err != nil {
+			return nil, fmt.Errorf("cannot buffer recursive query rows: %w", err)
+		}
+		r.working = append(r.working, t)
+		return t, nil
+	}
+	return nil, nil
+}
+
+// nextIteration closes the exhausted input and, unless it produced no rows,
+// evaluates the recursive term over the rows it did.
+func (r *RecursiveUnion) nextIteration() error {
+	if r.current != r.anchor {
+		if err := r.current.Close(); err != nil {
+			return err
+		}
+	}
+	r.current = nil
+
+	working := r.working
+	r.working = nil
+	if r.unionAll {
+		r.readMemory.ReleaseAll()
+		r.memory, r.readMemory = r.readMemory, r.memory
+	}
+	if len(working) == 0 {
+		return nil
+	}
+	if r.iteration == r.maxIterations {
+		return fmt.Errorf("%w: the recursive term was evaluated %d times without reaching a fixed point", ErrRecursionLimit, r.maxIterations)
+	}
+	r.iteration++
+
+	next, err := r.step(working)
+	if err != nil {
+		return fmt.Errorf("failed to build recursive term: %w", err)
+	}
+	if err := validateSchemaCompatibility(r.anchor.GetTupleDesc(), next.GetTupleDesc()); err != nil {
+		next.Close()
+		return fmt.Errorf("recursive term does not match anchor: %w", err)
+	}
+	if err := next.Open(); err != nil {
+		return fmt.Errorf("failed to open recursive term: %w", err)
+	}
+	r.current = next
+	return nil
+}
+
+// Rewind restarts the union from the anchor.
+func (r *RecursiveUnion) Rewind() error {
+	if err := r.closeStep(); err != nil {
+		return err
+	}
+	if err := r.anchor.Rewind(); err != nil {
+		return fmt.Errorf("failed to rewind anchor: %w", err)
+	}
+	r.reset()
+	r.base.ClearCache()
+	return nil
+}
+
+// closeStep closes the recursive term being read, if any.
+func (r *RecursiveUnion) closeStep() error {
+	if r.current == nil || r.current == r.anchor {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
+
+// Close closes the anchor and the recursive term being read.
+func (r *RecursiveUnion) Close() error {
+	errs := []error{r.closeStep(), r.anchor.Close(), r.base.Close()}
+	r.seen, r.current, r.working = nil, nil, nil
+	r.memory.ReleaseAll()
+	r.readMemory.ReleaseAll()
+	return errors.Join(errs...)
+}
+
+// GetTupleDesc returns the schema of the anchor, which every evaluation of
+// the recursive term must match.
+func (r *RecursiveUnion) GetTupleDesc() *tuple.TupleDescription {
+	return r.anchor.GetTupleDesc()
+}
+
+// HasNext checks if more tuples are available.
+func (r *RecursiveUnion) HasNext() (bool, error) {
+	return r.base.HasNext()
+}
+
+// Next returns the next tuple.
+func (r *RecursiveUnion) Next() (*tuple.Tuple, error) {
+	return r.base.Next()
+}
@@ -0,0 +1,103 @@
+package setops
+
+import (
+	"errors"
+	"slices"
+	"storemy/pkg/iterator"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+// edgeStep returns a recursive step following edges: for each working row
+// (node), it yields a row for every node the edges lead to from it.
+func edgeStep(desc *tuple.TupleDescription, edges map[int64][]int) RecursiveStep {
+	return func(working []*tuple.Tuple) (iterator.DbIterator, error) {
+		var next []*tuple.Tuple
+		for _, row := range working {
+			field, _ := row.GetField(0)
+			for _, to := range edges[field.(*types.IntField).Value] {
+				next = append(next, createSetOpTestTuple(desc, to))
+			}
+		}
+		return newMockSetOpIterator(next, desc), nil
+	}
+}
+
+// nodeValues returns the node of each row.
+func nodeValues(rows []*tuple.Tuple) []int64 {
+	values := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		field, _ := row.GetField(0)
+		values = append(values, field.(*types.IntField).Value)
+	}
+	return values
+}
+
+func TestRecursiveUnion_FollowsEdgesToFixedPoint(t *testing.T) {
+	desc, _ := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"node"})
+	anchor := newMockSetOpIterator([]*tuple.Tuple{createSetOpTestTuple(desc, 1)}, desc)
+
+	// 1 -> 2 -> 3 and 1 -> 3: UNION ALL reaches 3 twice, UNION once
+	edges := map[int64][]int{1: {2, 3}, 2: {3}}
+
+	for _, tt := range []struct {
+		unionAll bool
+		expected []int64
+	}{
+		{false, []int64{1, 2, 3}},
+		{true, []int64{1, 2, 3, 3}},
+	} {
+		r, err := NewRecursiveUnion(anchor, edgeStep(desc, edges), tt.unionAll, 10)
+		if err != nil {
+			t.Fatalf("NewRecursiveUnion failed: %v", err)
+		}
+		results, err := collectTuples(r)
+		if err != nil {
+			t.Fatalf("unionAll=%v: %v", tt.unionAll, err)
+		}
+		if got := nodeValues(results); !slices.Equal(got, tt.expected) {
+			t.Errorf("unionAll=%v: expected %v, got %v", tt.unionAll, tt.expected, got)
+		}
+	}
+}
+
+func TestRecursiveUnion_Cycle(t *testing.T) {
+	desc, _ := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"node"})
+	anchor := newMockSetOpIterator([]*tuple.Tuple{createSetOpTestTuple(desc, 1)}, desc)
+	edges := map[int64][]int{1: {2}, 2: {1}}
+
+	// UNION drops the rows seen before, so the cycle ends the recursion
+	r, _ := NewRecursiveUnion(anchor, edgeStep(desc, edges), false, 10)
+	results, err := collectTuples(r)
+	if err != nil {
+		t.Fatalf("UNION over a cycle failed: %v", err)
+	}
+	if got := nodeValues(results); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("expected [1 2], got %v", got)
+	}
+
+	// UNION ALL goes around the cycle until the limit
+	r, _ = NewRecursiveUnion(anchor, edgeStep(desc, edges), true, 10)
+	if _, err := collectTuples(r); !errors.Is(err, ErrRecursionLimit) {
+		t.Errorf("expected ErrRecursionLimit, got %v", err)
+	}
+}
+
+func TestRecursiveUnion_StepSchemaMismatch(t *testing.T) {
+	desc, _ := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"node"})
+	other, _ := tuple.NewTupleDesc([]types.Type{types.StringType}, []string{"name"})
+	anchor := newMockSetOpIterator([]*tuple.Tuple{createSetOpTestTuple(desc, 1)}, desc)
+
+	step := func([]*tuple.Tuple) (iterator.DbIterator, error) {
+		return newMockSetOpIterator(nil, other), nil
+	}
+	r, _ := NewRecursiveUnion(anchor, step, false, 10)
+	if _, err := collectTuples(r); err == nil {
+		t.Error("expected an error for a recursive term of another type")
+	}
+
+	if _, err := NewRecursiveUnion(anchor, step, false, 0); err == nil {
+		t.Error("expected an error for an iteration limit of 0")
+	}
+}
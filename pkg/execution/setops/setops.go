@@ -2,6 +2,7 @@ package setops
 
 import (
 	"fmt"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/iterator"
 	"storemy/pkg/primitives"
 	"storemy/pkg/tuple"
@@ -23,8 +24,9 @@ type SetOp struct {
 	preserveAll bool // true for ALL variants (UNION ALL, INTERSECT ALL, etc.)
 
 	tracker     *TupleSetTracker
-	leftDone    bool // Track if left child exhausted (UNION)
-	initialized bool // Track if right hash set is built
+	leftDone    bool              // Track if left child exhausted (UNION)
+	initialized bool              // Track if right hash set is built
+	memory      membudget.Account // Memory held by the tuple sets
 }
 
 // NewSetOperationBase creates a new base for set operations with common validation.
@@ -79,6 +81,9 @@ func (s *SetOp) buildRightHashSet() error {
 			break
 		}
 
+		if err := s.remember(rt); err != nil {
+			return err
+		}
 		s.tracker.AddToRight(rt)
 	}
 
@@ -86,6 +91,20 @@ func (s *SetOp) buildRightHashSet() error {
 	return nil
 }
 
+// SetMemoryTracker accounts the tuples held in the operator's hash sets
+// against the query's memory budget.
+func (s *SetOp) SetMemoryTracker(t *membudget.Tracker) {
+	s.memory.SetTracker(t)
+}
+
+// remember reserves memory for a tuple about to be kept in a hash set.
+func (s *SetOp) remember(t *tuple.Tuple) error {
+	if err := s.memory.ReserveTuple(t); err != nil {
+		return fmt.Errorf("cannot hold tuple for set operation: %w", err)
+	}
+	return nil
+}
+
 // GetTupleDesc returns the schema of the result.
 func (s *SetOp) GetTupleDesc() *tuple.TupleDescription {
 	return s.GetLeftChild().GetTupleDesc()
@@ -96,12 +115,14 @@ func (s *SetOp) Rewind() error {
 	s.initialized = false
 	s.leftDone = false
 	s.tracker.Clear()
+	s.memory.ReleaseAll()
 
 	return s.BinaryOperator.Rewind()
 }
 
 // Close releases resources by closing both child operators.
 func (s *SetOp) Close() error {
+	s.memory.ReleaseAll()
 	return s.BinaryOperator.Close()
 }
 
@@ -114,6 +135,7 @@ func (s *SetOp) Open() error {
 	s.initialized = false
 	s.leftDone = false
 	s.tracker = NewTupleSetTracker(s.preserveAll)
+	s.memory.ReleaseAll()
 
 	return nil
 }
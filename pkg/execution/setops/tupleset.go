@@ -25,12 +25,15 @@ func NewTupleSet(preserveAll bool) *TupleSet {
 }
 
 // hashTuple computes a hash for a tuple based on all its fields.
+// NULL fields hash to 0.
 func hashTuple(t *tuple.Tuple) primitives.HashCode {
 	var hash primitives.HashCode = 0
 	var i primitives.ColumnID
 	for i = 0; i < t.TupleDesc.NumFields(); i++ {
-		field, _ := t.GetField(i)
-		fieldHash, _ := field.Hash()
+		var fieldHash primitives.HashCode
+		if field, _ := t.GetField(i); field != nil {
+			fieldHash, _ = field.Hash()
+		}
 		hash = hash*31 + fieldHash
 	}
 	return hash
@@ -38,6 +41,7 @@ func hashTuple(t *tuple.Tuple) primitives.HashCode {
 
 // tuplesEqual compares two tuples for equality by comparing all fields.
 // This is used for collision detection when two tuples have the same hash.
+// Like SQL's DISTINCT, it treats two NULL fields as equal.
 func tuplesEqual(t1, t2 *tuple.Tuple) bool {
 	if t1.TupleDesc.NumFields() != t2.TupleDesc.NumFields() {
 		return false
@@ -48,6 +52,12 @@ func tuplesEqual(t1, t2 *tuple.Tuple) bool {
 		f1, _ := t1.GetField(i)
 		f2, _ := t2.GetField(i)
 
+		if f1 == nil || f2 == nil {
+			if f1 != f2 {
+				return false
+			}
+			continue
+		}
 		if !f1.Equals(f2) {
 			return false
 		}
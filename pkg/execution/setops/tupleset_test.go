@@ -156,6 +156,38 @@ func TestTupleEqualityFunction(t *testing.T) {
 	}
 }
 
+// TestTupleSetNullFields tests that NULL fields compare equal to each other
+// but not to non-NULL values
+func TestTupleSetNullFields(t *testing.T) {
+	desc, _ := tuple.NewTupleDesc(
+		[]types.Type{types.IntType, types.StringType},
+		[]string{"id", "name"},
+	)
+
+	ts := NewTupleSet(false)
+
+	null1 := createSetOpTestTuple(desc, 1) // name left NULL
+	null2 := createSetOpTestTuple(desc, 1)
+	named := createSetOpTestTuple(desc, 1, "Alice")
+
+	if !tuplesEqual(null1, null2) {
+		t.Error("Tuples with NULL in the same column should be equal")
+	}
+	if tuplesEqual(null1, named) {
+		t.Error("NULL should not equal a non-NULL value")
+	}
+
+	if !ts.Add(null1) {
+		t.Error("First NULL tuple should be added")
+	}
+	if ts.Add(null2) {
+		t.Error("Second NULL tuple should be a duplicate")
+	}
+	if !ts.Add(named) {
+		t.Error("Non-NULL tuple should be added")
+	}
+}
+
 // TestFindTupleInList tests the findTupleInList helper function
 func TestFindTupleInList(t *testing.T) {
 	desc, _ := tuple.NewTupleDesc(
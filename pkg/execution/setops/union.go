@@ -76,6 +76,9 @@ func (u *Union) readNext() (*tuple.Tuple, error) {
 			if !u.tracker.MarkSeen(t) {
 				continue
 			}
+			if err := u.remember(t); err != nil {
+				return nil, err
+			}
 		}
 		return t, nil
 	}
@@ -90,6 +93,9 @@ func (u *Union) readNext() (*tuple.Tuple, error) {
 			if !u.tracker.MarkSeen(t) {
 				continue
 			}
+			if err := u.remember(t); err != nil {
+				return nil, err
+			}
 		}
 		return t, nil
 	}
@@ -0,0 +1,445 @@
+// Package tempfile manages the temporary files queries write when their
+// intermediate state does not fit in memory: sort runs, hash join and
+// aggregation partitions, and staged input of COPY. Files live in a spill
+// directory, count against a per-query and a global disk quota, and are
+// removed when they are closed or when their query finishes, whether it
+// completed, failed or was cancelled.
+//
+// A Manager owns the spill directory. Each query gets its own Query from it
+// and creates files through it. Files left behind by a crash are removed
+// when the next Manager is created for the directory.
+package tempfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrTempQuotaExceeded is returned (wrapped in a *QuotaError) when a query
+// tries to write more temporary data than its quota allows.
+var ErrTempQuotaExceeded = errors.New("temporary file quota exceeded")
+
+// ErrNoSpillDir is returned when a query without a temporary file manager
+// tries to create a temporary file.
+var ErrNoSpillDir = errors.New("temporary files are not enabled")
+
+// Scopes of a disk quota, reported by QuotaError.
+const (
+	QueryScope  = "query"
+	GlobalScope = "global"
+)
+
+// filePrefix starts the name of every temporary file, so that the startup
+// sweep only removes files a Manager created.
+const filePrefix = "storemy-tmp-"
+
+// QuotaError describes a write that was refused. It matches
+// ErrTempQuotaExceeded with errors.Is.
+type QuotaError struct {
+	Scope     string // QueryScope or GlobalScope
+	Requested int64  // Bytes the refused write asked for
+	Used      int64  // Bytes already written in Scope
+	Limit     int64  // Quota of Scope in bytes
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s quota of %d bytes exceeded (%d bytes in use, %d requested)",
+		ErrTempQuotaExceeded, e.Scope, e.Limit, e.Used, e.Requested)
+}
+
+// Is reports whether target is ErrTempQuotaExceeded.
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrTempQuotaExceeded
+}
+
+// Config configures temporary files. Zero quotas are unlimited.
+type Config struct {
+	// Dir is the spill directory. It is created if missing.
+	Dir string
+
+	// QueryBytes limits the temporary data a single query may hold on disk.
+	QueryBytes int64
+
+	// GlobalBytes limits the temporary data all running queries may hold
+	// on disk together.
+	GlobalBytes int64
+}
+
+// Stats reports the state of a manager.
+type Stats struct {
+	Files    int   // Temporary files currently open
+	Used     int64 // Bytes currently held by all queries
+	Peak     int64 // Highest Used seen
+	Rejected int64 // Writes refused by either quota
+	Swept    int   // Leftover files removed when the manager was created
+}
+
+// Manager allocates temporary files in the spill directory and enforces
+// the global quota. It is safe for concurrent use.
+type Manager struct {
+	config Config
+
+	mutex   sync.Mutex
+	stats   Stats
+	nextID  uint64
+	queries map[*Query]struct{}
+}
+
+// New creates a manager for config.Dir, creating the directory and removing
+// the temporary files a previous process left in it.
+func New(config Config) (*Manager, error) {
+	if config.Dir == "" {
+		return nil, errors.New("tempfile: spill directory not set")
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory %s: %w", config.Dir, err)
+	}
+
+	m := &Manager{config: config, queries: make(map[*Query]struct{})}
+	swept, err := m.sweep()
+	if err != nil {
+		return nil, err
+	}
+	m.stats.Swept = swept
+	tempFilesSwept.Add(int64(swept))
+	return m, nil
+}
+
+// Config returns the configuration the manager was created with.
+func (m *Manager) Config() Config {
+	return m.config
+}
+
+// Stats returns a snapshot of the manager statistics.
+func (m *Manager) Stats() Stats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stats
+}
+
+// NewQuery returns the handle through which a new query creates temporary
+// files, or nil for a nil manager. A nil Query fails to create files with
+// ErrNoSpillDir.
+func (m *Manager) NewQuery() *Query {
+	if m == nil {
+		return nil
+	}
+
+	q := &Query{manager: m, limit: m.config.QueryBytes, files: make(map[*File]struct{})}
+	m.mutex.Lock()
+	m.queries[q] = struct{}{}
+	m.mutex.Unlock()
+	return q
+}
+
+// Close removes the files of every query still running, for a database
+// shutting down.
+func (m *Manager) Close() {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	queries := make([]*Query, 0, len(m.queries))
+	for q := range m.queries {
+		queries = append(queries, q)
+	}
+	m.mutex.Unlock()
+
+	for _, q := range queries {
+		q.Close()
+	}
+}
+
+// sweep removes the temporary files in the spill directory. Only the
+// process that owns the directory creates files in it, so at startup every
+// one of them was left behind by a crash.
+func (m *Manager) sweep() (int, error) {
+	entries, err := os.ReadDir(m.config.Dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read spill directory %s: %w", m.config.Dir, err)
+	}
+
+	swept := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), filePrefix) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.config.Dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return swept, fmt.Errorf("failed to remove leftover temporary file %s: %w", entry.Name(), err)
+		}
+		swept++
+	}
+	return swept, nil
+}
+
+// create opens a new, empty temporary file.
+func (m *Manager) create() (*os.File, error) {
+	m.mutex.Lock()
+	m.nextID++
+	name := fmt.Sprintf("%s%d-%d", filePrefix, os.Getpid(), m.nextID)
+	m.mutex.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(m.config.Dir, name), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	m.mutex.Lock()
+	m.stats.Files++
+	tempFilesOpen.Set(int64(m.stats.Files))
+	m.mutex.Unlock()
+	return f, nil
+}
+
+// removed accounts for a temporary file that was deleted.
+func (m *Manager) removed() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.stats.Files--
+	tempFilesOpen.Set(int64(m.stats.Files))
+}
+
+// reserve takes n bytes from the global quota.
+func (m *Manager) reserve(n int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.config.GlobalBytes > 0 && m.stats.Used+n > m.config.GlobalBytes {
+		m.stats.Rejected++
+		tempRejected.Inc()
+		return &QuotaError{Scope: GlobalScope, Requested: n, Used: m.stats.Used, Limit: m.config.GlobalBytes}
+	}
+	m.stats.Used += n
+	m.stats.Peak = max(m.stats.Peak, m.stats.Used)
+	tempBytesUsed.Set(m.stats.Used)
+	return nil
+}
+
+// release returns n bytes to the global quota.
+func (m *Manager) release(n int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.stats.Used -= n
+	tempBytesUsed.Set(m.stats.Used)
+}
+
+// rejectQuery counts a write refused by a query quota.
+func (m *Manager) rejectQuery() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.stats.Rejected++
+	tempRejected.Inc()
+}
+
+// forget drops q from the running queries.
+func (m *Manager) forget(q *Query) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.queries, q)
+}
+
+// Query owns the temporary files of one query and enforces its quota.
+// Closing it removes every file the query has not closed itself, so a query
+// that fails or is cancelled midway leaves nothing behind.
+type Query struct {
+	manager *Manager
+	limit   int64 // Per-query quota; zero is unlimited
+
+	mutex  sync.Mutex
+	files  map[*File]struct{}
+	used   int64
+	peak   int64
+	closed bool
+}
+
+// Create returns a new, empty temporary file owned by the query.
+func (q *Query) Create() (*File, error) {
+	if q == nil {
+		return nil, ErrNoSpillDir
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return nil, errors.New("tempfile: query is closed")
+	}
+	f, err := q.manager.create()
+	if err != nil {
+		return nil, err
+	}
+	file := &File{query: q, file: f}
+	q.files[file] = struct{}{}
+	return file, nil
+}
+
+// Close removes the files the query still holds and returns their bytes to
+// the global quota. It is called once the query has finished and is safe
+// to call more than once.
+func (q *Query) Close() {
+	if q == nil {
+		return
+	}
+
+	q.mutex.Lock()
+	files := make([]*File, 0, len(q.files))
+	for f := range q.files {
+		files = append(files, f)
+	}
+	q.closed = true
+	q.mutex.Unlock()
+
+	for _, f := range files {
+		f.Close()
+	}
+
+	q.mutex.Lock()
+	peak := q.peak
+	q.mutex.Unlock()
+
+	q.manager.forget(q)
+	tempQueryPeak.Observe(float64(peak))
+}
+
+// Used returns the bytes of temporary data the query holds.
+func (q *Query) Used() int64 {
+	if q == nil {
+		return 0
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.used
+}
+
+// Peak returns the most bytes of temporary data the query has held at once.
+func (q *Query) Peak() int64 {
+	if q == nil {
+		return 0
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.peak
+}
+
+// reserve takes n bytes for the query. It fails with a *QuotaError if the
+// query or the global quota would be exceeded.
+func (q *Query) reserve(n int64) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.limit > 0 && q.used+n > q.limit {
+		q.manager.rejectQuery()
+		return &QuotaError{Scope: QueryScope, Requested: n, Used: q.used, Limit: q.limit}
+	}
+	if err := q.manager.reserve(n); err != nil {
+		return err
+	}
+	q.used += n
+	q.peak = max(q.peak, q.used)
+	return nil
+}
+
+// release returns n bytes reserved by the query.
+func (q *Query) release(n int64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	n = min(n, q.used)
+	q.used -= n
+	q.manager.release(n)
+}
+
+// remove forgets f, which was removed from disk.
+func (q *Query) remove(f *File) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.files, f)
+}
+
+// File is a temporary file. Data is appended with Write and read back with
+// ReadAt or Reader; every byte written counts against the quotas of its
+// query until the file is closed, which removes it.
+type File struct {
+	query *Query
+	file  *os.File
+
+	mutex  sync.Mutex
+	size   int64
+	closed bool
+}
+
+// Write appends p to the file. Nothing is written if it would exceed a
+// quota.
+func (f *File) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if err := f.query.reserve(int64(len(p))); err != nil {
+		return 0, err
+	}
+
+	n, err := f.file.WriteAt(p, f.size)
+	f.size += int64(n)
+	f.query.release(int64(len(p) - n))
+	return n, err
+}
+
+// ReadAt reads len(p) bytes of the file starting at off.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	return f.file.ReadAt(p, off)
+}
+
+// Reader returns a reader of everything written so far.
+func (f *File) Reader() io.Reader {
+	return io.NewSectionReader(f.file, 0, f.Size())
+}
+
+// Size returns the number of bytes written.
+func (f *File) Size() int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.size
+}
+
+// Name returns the path of the file.
+func (f *File) Name() string {
+	return f.file.Name()
+}
+
+// Close closes and removes the file and returns its bytes to the quotas.
+// It is safe to call more than once.
+func (f *File) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	closeErr := f.file.Close()
+	removeErr := os.Remove(f.file.Name())
+	f.query.release(f.size)
+	f.query.remove(f)
+	f.query.manager.removed()
+
+	if closeErr != nil {
+		return fmt.Errorf("failed to close temporary file: %w", closeErr)
+	}
+	if removeErr != nil && !os.IsNotExist(removeErr) {
+		return fmt.Errorf("failed to remove temporary file: %w", removeErr)
+	}
+	return nil
+}
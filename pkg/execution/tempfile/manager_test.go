@@ -0,0 +1,149 @@
+package tempfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuery_WriteReadAndClose(t *testing.T) {
+	m, err := New(Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	q := m.NewQuery()
+
+	f, err := q.Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for _, chunk := range []string{"sort ", "run"} {
+		if _, err := f.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	data, err := io.ReadAll(f.Reader())
+	if err != nil || string(data) != "sort run" {
+		t.Fatalf("read back %q (err %v), want %q", data, err, "sort run")
+	}
+	if q.Used() != 8 || m.Stats().Used != 8 || m.Stats().Files != 1 {
+		t.Errorf("unexpected usage: query %d, stats %+v", q.Used(), m.Stats())
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("expected the closed file to be removed, stat returned %v", err)
+	}
+	if q.Used() != 0 || q.Peak() != 8 {
+		t.Errorf("expected used 0 and peak 8, got %d and %d", q.Used(), q.Peak())
+	}
+	q.Close()
+	if stats := m.Stats(); stats.Used != 0 || stats.Files != 0 {
+		t.Errorf("unexpected stats after close %+v", stats)
+	}
+}
+
+func TestQuery_Quotas(t *testing.T) {
+	m, err := New(Config{Dir: t.TempDir(), QueryBytes: 10, GlobalBytes: 15})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	first, second := m.NewQuery(), m.NewQuery()
+
+	f, _ := first.Create()
+	if _, err := f.Write(make([]byte, 8)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var quotaErr *QuotaError
+	_, err = f.Write(make([]byte, 4))
+	if !errors.Is(err, ErrTempQuotaExceeded) || !errors.As(err, &quotaErr) || quotaErr.Scope != QueryScope {
+		t.Fatalf("expected a query quota error, got %v", err)
+	}
+	if f.Size() != 8 {
+		t.Errorf("refused write changed the file size to %d", f.Size())
+	}
+
+	g, _ := second.Create()
+	_, err = g.Write(make([]byte, 8))
+	if !errors.As(err, &quotaErr) || quotaErr.Scope != GlobalScope || quotaErr.Used != 8 {
+		t.Fatalf("expected a global quota error, got %v", err)
+	}
+
+	first.Close()
+	if _, err := g.Write(make([]byte, 8)); err != nil {
+		t.Errorf("Write after the first query finished failed: %v", err)
+	}
+	second.Close()
+	if stats := m.Stats(); stats.Used != 0 || stats.Rejected != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestQuery_CloseRemovesUnclosedFiles(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	q := m.NewQuery()
+	for range 3 {
+		f, err := q.Create()
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		f.Write([]byte("partition"))
+	}
+
+	q.Close()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected an empty spill directory, found %d entries", len(entries))
+	}
+	if _, err := q.Create(); err == nil {
+		t.Error("expected Create on a closed query to fail")
+	}
+}
+
+func TestNew_SweepsLeftoverFiles(t *testing.T) {
+	dir := t.TempDir()
+	first, err := New(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	f, _ := first.NewQuery().Create()
+	f.Write([]byte("left behind by a crash"))
+
+	other := filepath.Join(dir, "keep.txt")
+	if err := os.WriteFile(other, nil, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	second, err := New(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if second.Stats().Swept != 1 {
+		t.Errorf("expected 1 swept file, got %d", second.Stats().Swept)
+	}
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("expected the leftover file to be removed, stat returned %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("expected files the manager did not create to be kept: %v", err)
+	}
+}
+
+func TestQuery_Nil(t *testing.T) {
+	var m *Manager
+	q := m.NewQuery()
+	if _, err := q.Create(); !errors.Is(err, ErrNoSpillDir) {
+		t.Errorf("expected ErrNoSpillDir, got %v", err)
+	}
+	q.Close()
+	m.Close()
+}
@@ -0,0 +1,27 @@
+package tempfile
+
+import "storemy/pkg/metrics"
+
+var (
+	tempBytesUsed = metrics.NewGauge(
+		"storemy_query_temp_bytes",
+		"Bytes of temporary files currently held by running queries",
+	)
+	tempFilesOpen = metrics.NewGauge(
+		"storemy_query_temp_files",
+		"Temporary files currently open",
+	)
+	tempRejected = metrics.NewCounter(
+		"storemy_query_temp_rejected_total",
+		"Temporary file writes refused because a query or the global quota was exhausted",
+	)
+	tempFilesSwept = metrics.NewCounter(
+		"storemy_query_temp_files_swept_total",
+		"Leftover temporary files removed at startup",
+	)
+	tempQueryPeak = metrics.NewHistogram(
+		"storemy_query_temp_peak_bytes",
+		"Most temporary data held at once by a finished query",
+		[]float64{1 << 14, 1 << 17, 1 << 20, 1 << 23, 1 << 26, 1 << 30, 1 << 33},
+	)
+)
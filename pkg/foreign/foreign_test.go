@@ -0,0 +1,175 @@
+package foreign
+
+import (
+	"os"
+	"path/filepath"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+var testColumns = []Column{
+	{Name: "id", Type: types.IntType},
+	{Name: "name", Type: types.StringType},
+	{Name: "score", Type: types.FloatType},
+	{Name: "active", Type: types.BoolType},
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func readAll(t *testing.T, table *Table, dir string) ([]*tuple.Tuple, error) {
+	t.Helper()
+	r, err := table.Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer r.Close()
+
+	var rows []*tuple.Tuple
+	for {
+		row, err := r.Next()
+		if err != nil || row == nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func fieldString(t *testing.T, row *tuple.Tuple, i int) string {
+	t.Helper()
+	f, err := row.GetField(primitives.ColumnID(i))
+	if err != nil {
+		t.Fatalf("GetField(%d) failed: %v", i, err)
+	}
+	return f.String()
+}
+
+func TestNewTable_Options(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]string
+		errMsg  string
+	}{
+		{"Missing format", map[string]string{"location": "a.csv"}, "unsupported foreign table format"},
+		{"Unknown format", map[string]string{"format": "xml", "location": "a.xml"}, "unsupported foreign table format"},
+		{"Missing location", map[string]string{"format": "csv"}, "option LOCATION is required"},
+		{"Unknown option", map[string]string{"format": "csv", "location": "a.csv", "encoding": "utf8"}, "unknown foreign table option ENCODING"},
+		{"Header on JSONL", map[string]string{"format": "jsonl", "location": "a.jsonl", "header": "true"}, "only applies to csv"},
+		{"Bad header", map[string]string{"format": "csv", "location": "a.csv", "header": "yes please"}, "must be true or false"},
+		{"Bad delimiter", map[string]string{"format": "csv", "location": "a.csv", "delimiter": "::"}, "single character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTable("t", testColumns, tt.options)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("NewTable() error = %v, want it to contain %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	if _, err := NewTable("t", []Column{{Name: "n", Type: types.Uint64Type}}, map[string]string{"format": "csv", "location": "a.csv"}); err == nil {
+		t.Error("expected an error for an unsupported column type")
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "people.csv", "id;name;score;active\n1;alice;9.5;true\n2;\"bob; jr\";7;false\n")
+
+	table, err := NewTable("people", testColumns, map[string]string{
+		"format": "CSV", "location": "people.csv", "header": "true", "delimiter": ";",
+	})
+	if err != nil {
+		t.Fatalf("NewTable failed: %v", err)
+	}
+
+	rows, err := readAll(t, table, dir)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if got := fieldString(t, rows[1], 1); got != "bob; jr" {
+		t.Errorf("expected quoted field with delimiter, got %q", got)
+	}
+	if got := fieldString(t, rows[0], 3); got != "true" {
+		t.Errorf("expected active=true, got %q", got)
+	}
+}
+
+func TestCSVReader_BadValueNamesLine(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "people.csv", "1,alice,9.5,true\n2,bob,lots,false\n")
+
+	table, err := NewTable("people", testColumns, map[string]string{"format": "csv", "location": "people.csv"})
+	if err != nil {
+		t.Fatalf("NewTable failed: %v", err)
+	}
+
+	rows, err := readAll(t, table, dir)
+	if len(rows) != 1 || err == nil {
+		t.Fatalf("expected one row before the error, got %d rows and error %v", len(rows), err)
+	}
+	if !strings.Contains(err.Error(), "line 2: column SCORE: invalid FLOAT value") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestJSONLReader(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "people.jsonl", `{"ID": 1, "Name": "alice", "score": 9.5, "active": true}
+
+{"id": 2, "name": "bob", "score": 7, "active": false, "extra": [1, 2]}
+{"id": 3, "name": "carol", "active": true}
+`)
+
+	table, err := NewTable("people", testColumns, map[string]string{"format": "jsonl", "location": filepath.Join(dir, "people.jsonl")})
+	if err != nil {
+		t.Fatalf("NewTable failed: %v", err)
+	}
+
+	rows, err := readAll(t, table, "/nonexistent")
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows before the error, got %d", len(rows))
+	}
+	if got := fieldString(t, rows[0], 1); got != "alice" {
+		t.Errorf("expected keys to match case-insensitively, got %q", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "line 4: missing value for column SCORE") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	table, err := NewTable("people", testColumns, map[string]string{
+		"format": "csv", "location": "data/People.csv", "header": "true", "delimiter": "|",
+	})
+	if err != nil {
+		t.Fatalf("NewTable failed: %v", err)
+	}
+
+	md := table.Metadata()
+	if md.Columns != "ID INT,NAME STRING,SCORE FLOAT,ACTIVE BOOLEAN" {
+		t.Errorf("unexpected column encoding %q", md.Columns)
+	}
+
+	restored, err := FromMetadata(&md)
+	if err != nil {
+		t.Fatalf("FromMetadata failed: %v", err)
+	}
+	if restored.Location != "data/People.csv" || !restored.Header || restored.Delimiter != '|' {
+		t.Errorf("options not restored: %+v", restored)
+	}
+	if !restored.TupleDesc.Equals(table.TupleDesc) {
+		t.Errorf("schema not restored: %v", restored.TupleDesc)
+	}
+}
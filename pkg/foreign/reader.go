@@ -0,0 +1,217 @@
+package foreign
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strconv"
+	"strings"
+)
+
+// maxJSONLineSize bounds the length of one JSONL record.
+const maxJSONLineSize = 1 << 20
+
+// Reader streams the rows of a foreign table's file, parsing one record at a
+// time.
+type Reader interface {
+	// Next returns the next row, or nil once the file is exhausted. Errors
+	// name the file and line of the offending record.
+	Next() (*tuple.Tuple, error)
+
+	// Close releases the underlying file.
+	Close() error
+}
+
+// Open opens the table's file for reading. Relative locations are resolved
+// against baseDir.
+func (t *Table) Open(baseDir string) (Reader, error) {
+	path := t.Path(baseDir)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file for foreign table %s: %w", t.Name, err)
+	}
+
+	switch t.Format {
+	case FormatCSV:
+		return newCSVReader(t, path, f)
+	case FormatJSONL:
+		return newJSONLReader(t, path, f), nil
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported foreign table format %q", t.Format)
+	}
+}
+
+type csvReader struct {
+	table *Table
+	path  string
+	file  *os.File
+	csv   *csv.Reader
+}
+
+func newCSVReader(t *Table, path string, f *os.File) (*csvReader, error) {
+	r := csv.NewReader(bufio.NewReader(f))
+	r.Comma = t.Delimiter
+	r.FieldsPerRecord = len(t.Columns)
+	r.ReuseRecord = true
+
+	cr := &csvReader{table: t, path: path, file: f, csv: r}
+	if t.Header {
+		if _, err := r.Read(); err != nil && err != io.EOF {
+			f.Close()
+			return nil, cr.wrap(err)
+		}
+	}
+	return cr, nil
+}
+
+func (r *csvReader) Next() (*tuple.Tuple, error) {
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.wrap(err)
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	return buildRow(r.table, r.path, line, func(i int) (string, bool) {
+		return record[i], true
+	})
+}
+
+func (r *csvReader) Close() error {
+	return r.file.Close()
+}
+
+// wrap adds the file name to errors from encoding/csv, which already carry
+// the line number.
+func (r *csvReader) wrap(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%s: %w", r.path, err)
+	}
+	return fmt.Errorf("failed to read %s: %w", r.path, err)
+}
+
+type jsonlReader struct {
+	table   *Table
+	path    string
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLReader(t *Table, path string, f *os.File) *jsonlReader {
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxJSONLineSize)
+	return &jsonlReader{table: t, path: path, file: f, scanner: scanner}
+}
+
+func (r *jsonlReader) Next() (*tuple.Tuple, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var object map[string]any
+		if err := dec.Decode(&object); err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid JSON object: %v", r.path, r.line, err)
+		}
+
+		values := make(map[string]any, len(object))
+		for k, v := range object {
+			values[strings.ToUpper(k)] = v
+		}
+
+		return buildRow(r.table, r.path, r.line, func(i int) (string, bool) {
+			return jsonText(values[r.table.Columns[i].Name])
+		})
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s after line %d: %w", r.path, r.line, err)
+	}
+	return nil, nil
+}
+
+func (r *jsonlReader) Close() error {
+	return r.file.Close()
+}
+
+// jsonText returns the text of a scalar JSON value, or false if the value is
+// missing, null or not a scalar.
+func jsonText(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// buildRow converts the text of each column into a tuple. value returns the
+// text of column i, or false if the record has no value for it.
+func buildRow(t *Table, path string, line int, value func(i int) (string, bool)) (*tuple.Tuple, error) {
+	row := tuple.NewTuple(t.TupleDesc)
+	for i, col := range t.Columns {
+		text, ok := value(i)
+		if !ok {
+			return nil, fmt.Errorf("%s line %d: missing value for column %s", path, line, col.Name)
+		}
+
+		field, err := parseField(col.Type, text)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: column %s: %v", path, line, col.Name, err)
+		}
+		if err := row.SetField(primitives.ColumnID(i), field); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// parseField converts text to a field of type t. Unlike
+// types.CreateFieldFromConstant it rejects text that is not a valid value.
+func parseField(t types.Type, text string) (types.Field, error) {
+	switch t {
+	case types.IntType:
+		v, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INT value %q", text)
+		}
+		return types.NewIntField(v), nil
+	case types.FloatType:
+		v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FLOAT value %q", text)
+		}
+		return types.NewFloat64Field(v), nil
+	case types.BoolType:
+		v, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid BOOLEAN value %q", text)
+		}
+		return types.NewBoolField(v), nil
+	case types.StringType:
+		return types.NewStringField(text, types.StringMaxSize), nil
+	default:
+		return nil, fmt.Errorf("unsupported column type %s", t)
+	}
+}
@@ -0,0 +1,230 @@
+// Package foreign implements foreign tables: tables whose rows live in an
+// external CSV or JSONL file instead of a heap file. The file is parsed on the
+// fly, using the declared columns, every time the table is scanned, so foreign
+// tables can be queried and joined with stored tables without importing them:
+//
+//	CREATE FOREIGN TABLE visits (user_id INT, page STRING)
+//	OPTIONS (format 'csv', location 'visits.csv', header 'true');
+//
+// Foreign tables are read-only. Table and column names are upper case,
+// matching how the lexer normalizes identifiers.
+package foreign
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strconv"
+	"strings"
+)
+
+// Format is the file format of a foreign table.
+type Format string
+
+const (
+	FormatCSV   Format = "CSV"   // Comma-separated values, one row per record
+	FormatJSONL Format = "JSONL" // One JSON object per line, keyed by column name
+)
+
+// ParseFormat parses a format name case-insensitively.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToUpper(strings.TrimSpace(name))); f {
+	case FormatCSV, FormatJSONL:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported foreign table format %q (expected csv or jsonl)", name)
+	}
+}
+
+// Option names accepted by NewTable.
+const (
+	OptionFormat    = "FORMAT"    // csv or jsonl; required
+	OptionLocation  = "LOCATION"  // Path of the file; required
+	OptionHeader    = "HEADER"    // CSV only: 'true' if the first record holds column names
+	OptionDelimiter = "DELIMITER" // CSV only: single-character field separator, ',' by default
+)
+
+// Column is one declared column of a foreign table.
+type Column struct {
+	Name string
+	Type types.Type
+}
+
+// Table is the definition of a foreign table.
+type Table struct {
+	Name      string
+	Format    Format
+	Location  string // As declared; relative paths are resolved by Open
+	Columns   []Column
+	Header    bool
+	Delimiter rune
+	TupleDesc *tuple.TupleDescription
+}
+
+// NewTable validates a foreign table definition. Option names are matched
+// case-insensitively; unknown options and options that do not apply to the
+// format are rejected.
+func NewTable(name string, columns []Column, options map[string]string) (*Table, error) {
+	t := &Table{
+		Name:      strings.ToUpper(name),
+		Columns:   slices.Clone(columns),
+		Delimiter: ',',
+	}
+	for i := range t.Columns {
+		t.Columns[i].Name = strings.ToUpper(t.Columns[i].Name)
+	}
+
+	opts := make(map[string]string, len(options))
+	for k, v := range options {
+		opts[strings.ToUpper(k)] = v
+	}
+
+	format, err := ParseFormat(opts[OptionFormat])
+	if err != nil {
+		return nil, err
+	}
+	t.Format = format
+
+	if t.Location = strings.TrimSpace(opts[OptionLocation]); t.Location == "" {
+		return nil, fmt.Errorf("option %s is required", OptionLocation)
+	}
+
+	for k, v := range opts {
+		switch k {
+		case OptionFormat, OptionLocation:
+		case OptionHeader:
+			if format != FormatCSV {
+				return nil, fmt.Errorf("option %s only applies to csv files", k)
+			}
+			if t.Header, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("option %s must be true or false, got %q", k, v)
+			}
+		case OptionDelimiter:
+			if format != FormatCSV {
+				return nil, fmt.Errorf("option %s only applies to csv files", k)
+			}
+			r := []rune(v)
+			if len(r) != 1 || r[0] == '"' || r[0] == '\r' || r[0] == '\n' {
+				return nil, fmt.Errorf("option %s must be a single character other than a quote or newline, got %q", k, v)
+			}
+			t.Delimiter = r[0]
+		default:
+			return nil, fmt.Errorf("unknown foreign table option %s", k)
+		}
+	}
+
+	if err := t.buildTupleDesc(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Table) buildTupleDesc() error {
+	if len(t.Columns) == 0 {
+		return fmt.Errorf("foreign table %s must have at least one column", t.Name)
+	}
+
+	fieldTypes := make([]types.Type, len(t.Columns))
+	fieldNames := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		if _, err := typeName(col.Type); err != nil {
+			return fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		fieldTypes[i] = col.Type
+		fieldNames[i] = col.Name
+	}
+
+	td, err := tuple.NewTupleDesc(fieldTypes, fieldNames)
+	if err != nil {
+		return fmt.Errorf("invalid schema for foreign table %s: %v", t.Name, err)
+	}
+	t.TupleDesc = td
+	return nil
+}
+
+// Path returns the file the table reads. A relative Location is resolved
+// against baseDir, the database's data directory.
+func (t *Table) Path(baseDir string) string {
+	if filepath.IsAbs(t.Location) {
+		return t.Location
+	}
+	return filepath.Join(baseDir, t.Location)
+}
+
+// Metadata returns the catalog representation of the table.
+func (t *Table) Metadata() systemtable.ForeignTableMetadata {
+	cols := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		name, _ := typeName(col.Type)
+		cols[i] = col.Name + " " + name
+	}
+
+	return systemtable.ForeignTableMetadata{
+		TableName: t.Name,
+		Format:    string(t.Format),
+		Location:  t.Location,
+		Columns:   strings.Join(cols, ","),
+		Header:    t.Header,
+		Delimiter: string(t.Delimiter),
+	}
+}
+
+// FromMetadata rebuilds a table from its catalog representation.
+func FromMetadata(md *systemtable.ForeignTableMetadata) (*Table, error) {
+	var columns []Column
+	for _, def := range strings.Split(md.Columns, ",") {
+		name, typ, ok := strings.Cut(strings.TrimSpace(def), " ")
+		if !ok {
+			return nil, fmt.Errorf("foreign table %s: malformed column definition %q", md.TableName, def)
+		}
+		t, err := parseTypeName(typ)
+		if err != nil {
+			return nil, fmt.Errorf("foreign table %s: column %s: %w", md.TableName, name, err)
+		}
+		columns = append(columns, Column{Name: name, Type: t})
+	}
+
+	options := map[string]string{
+		OptionFormat:   md.Format,
+		OptionLocation: md.Location,
+	}
+	if Format(md.Format) == FormatCSV {
+		options[OptionHeader] = strconv.FormatBool(md.Header)
+		options[OptionDelimiter] = md.Delimiter
+	}
+	return NewTable(md.TableName, columns, options)
+}
+
+// typeName returns the SQL name of a column type supported by foreign tables.
+func typeName(t types.Type) (string, error) {
+	switch t {
+	case types.IntType:
+		return "INT", nil
+	case types.StringType:
+		return "STRING", nil
+	case types.BoolType:
+		return "BOOLEAN", nil
+	case types.FloatType:
+		return "FLOAT", nil
+	default:
+		return "", fmt.Errorf("type %s is not supported in foreign tables", t)
+	}
+}
+
+func parseTypeName(name string) (types.Type, error) {
+	switch name {
+	case "INT":
+		return types.IntType, nil
+	case "STRING":
+		return types.StringType, nil
+	case "BOOLEAN":
+		return types.BoolType, nil
+	case "FLOAT":
+		return types.FloatType, nil
+	default:
+		return 0, fmt.Errorf("unknown column type %q", name)
+	}
+}
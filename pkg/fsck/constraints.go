@@ -0,0 +1,180 @@
+package fsck
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"strings"
+)
+
+// checkConstraints verifies the constraints that only involve the table
+// itself: the schema's primary key and the enabled PRIMARY KEY, UNIQUE and
+// NOT NULL constraints in CATALOG_CONSTRAINTS. Foreign keys are collected
+// for checkForeignKeys, which runs once every table has been read.
+func (c *Checker) checkConstraints(tm *systemtable.TableMetadata, data *tableData) {
+	for _, col := range data.schema.Columns {
+		if col.IsPrimary {
+			c.report.ConstraintsChecked++
+			object := fmt.Sprintf("%s PRIMARY KEY (%s)", tm.TableName, col.Name)
+			c.checkUnique(object, data, []primitives.ColumnID{col.Position}, true)
+		}
+	}
+
+	constraints, err := c.catalog.GetEnabledConstraintsForTable(c.tx, tm.TableID)
+	if err != nil {
+		c.report.add(SeverityError, CheckConstraints, tm.TableName, "cannot load constraints: %v", err)
+		return
+	}
+
+	for _, cm := range constraints {
+		c.report.ConstraintsChecked++
+		object := cm.ConstraintName
+
+		if cm.ConstraintType == systemtable.ConstraintTypeForeignKey {
+			c.foreignKeys = append(c.foreignKeys, cm)
+			continue
+		}
+		if cm.ConstraintType == systemtable.ConstraintTypeCheck {
+			c.report.add(SeverityInfo, CheckConstraints, object, "CHECK (%s) is not evaluated by the checker", cm.CheckExpression)
+			continue
+		}
+
+		cols, err := resolveColumns(data.schema, cm.ColumnNames)
+		if err != nil {
+			c.report.add(SeverityError, CheckConstraints, object, "%v", err)
+			continue
+		}
+
+		switch cm.ConstraintType {
+		case systemtable.ConstraintTypePrimaryKey:
+			c.checkUnique(object, data, cols, true)
+		case systemtable.ConstraintTypeUnique:
+			c.checkUnique(object, data, cols, false)
+		case systemtable.ConstraintTypeNotNull:
+			c.checkNotNull(object, data, cols)
+		default:
+			c.report.add(SeverityWarning, CheckConstraints, object, "unknown constraint type %d", cm.ConstraintType)
+		}
+	}
+}
+
+// checkUnique reports rows sharing a key over cols. Rows with a NULL key
+// column are skipped, or reported when notNull is set.
+func (c *Checker) checkUnique(object string, data *tableData, cols []primitives.ColumnID, notNull bool) {
+	nulls := c.limiter(SeverityError, CheckConstraints, object)
+	dups := c.limiter(SeverityError, CheckConstraints, object)
+
+	seen := make(map[string]*tuple.TupleRecordID, len(data.tuples))
+	for _, t := range data.tuples {
+		key, ok := compositeKey(t, cols)
+		if !ok {
+			if notNull {
+				nulls.add("row at %s has a NULL key", location(t.RecordID))
+			}
+			continue
+		}
+		if first, dup := seen[key]; dup {
+			dups.add("rows at %s and %s share key (%s)", location(first), location(t.RecordID), key)
+			continue
+		}
+		seen[key] = t.RecordID
+	}
+
+	nulls.flush()
+	dups.flush()
+}
+
+func (c *Checker) checkNotNull(object string, data *tableData, cols []primitives.ColumnID) {
+	nulls := c.limiter(SeverityError, CheckConstraints, object)
+	for _, t := range data.tuples {
+		for _, col := range cols {
+			if f, err := t.GetField(col); err != nil || f == nil {
+				nulls.add("row at %s is NULL in column %d", location(t.RecordID), col)
+			}
+		}
+	}
+	nulls.flush()
+}
+
+// checkForeignKeys verifies that every non-NULL foreign key value exists in
+// the referenced table.
+func (c *Checker) checkForeignKeys() {
+	for _, fk := range c.foreignKeys {
+		object := fk.ConstraintName
+		child, parent := c.tables[fk.TableID], c.tables[fk.ReferencedTableID]
+		if child == nil {
+			continue
+		}
+		if parent == nil {
+			c.report.add(SeverityError, CheckConstraints, object, "references table %d, which could not be read", fk.ReferencedTableID)
+			continue
+		}
+
+		childCols, err := resolveColumns(child.schema, fk.ColumnNames)
+		if err != nil {
+			c.report.add(SeverityError, CheckConstraints, object, "%v", err)
+			continue
+		}
+		parentCols, err := resolveColumns(parent.schema, fk.ReferencedColumns)
+		if err != nil {
+			c.report.add(SeverityError, CheckConstraints, object, "referenced %v", err)
+			continue
+		}
+		if len(childCols) != len(parentCols) {
+			c.report.add(SeverityError, CheckConstraints, object, "has %d columns but references %d", len(childCols), len(parentCols))
+			continue
+		}
+		if !parent.complete {
+			c.report.add(SeverityInfo, CheckConstraints, object, "skipped: %s has unreadable pages", parent.name)
+			continue
+		}
+
+		keys := make(map[string]bool, len(parent.tuples))
+		for _, t := range parent.tuples {
+			if key, ok := compositeKey(t, parentCols); ok {
+				keys[key] = true
+			}
+		}
+
+		orphans := c.limiter(SeverityError, CheckConstraints, object)
+		for _, t := range child.tuples {
+			if key, ok := compositeKey(t, childCols); ok && !keys[key] {
+				orphans.add("row at %s references (%s), which does not exist in %s", location(t.RecordID), key, parent.name)
+			}
+		}
+		orphans.flush()
+	}
+}
+
+// resolveColumns maps a comma-separated column list to column positions.
+func resolveColumns(sch *schema.Schema, list string) ([]primitives.ColumnID, error) {
+	names := splitColumns(list)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("constraint has no columns")
+	}
+	cols := make([]primitives.ColumnID, 0, len(names))
+	for _, name := range names {
+		idx, err := sch.GetFieldIndex(name)
+		if err != nil {
+			return nil, fmt.Errorf("column %s does not exist in %s", name, sch.TableName)
+		}
+		cols = append(cols, idx)
+	}
+	return cols, nil
+}
+
+// compositeKey renders the values of cols as a single comparable key. ok is
+// false if any of them is NULL.
+func compositeKey(t *tuple.Tuple, cols []primitives.ColumnID) (key string, ok bool) {
+	parts := make([]string, len(cols))
+	for i, col := range cols {
+		f, err := t.GetField(col)
+		if err != nil || f == nil {
+			return "", false
+		}
+		parts[i] = f.String()
+	}
+	return strings.Join(parts, ", "), true
+}
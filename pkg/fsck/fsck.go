@@ -0,0 +1,310 @@
+// Package fsck verifies the consistency of a database's files and catalog.
+//
+// The checker reads every table straight from disk and reports, with a
+// severity for each finding:
+//   - catalog entries without a file and files without a catalog entry
+//   - heap pages whose slot directory or tuples cannot be decoded
+//   - rows missing from an index and index entries pointing at the wrong row
+//   - rows violating PRIMARY KEY, UNIQUE, NOT NULL and FOREIGN KEY constraints
+//
+// Pages are read from disk rather than through the buffer pool, so the check
+// is exact for committed data on an idle database. Statements running
+// concurrently may make their in-flight changes show up as findings.
+package fsck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/config"
+	"storemy/pkg/indexmanager"
+	"storemy/pkg/primitives"
+	"storemy/pkg/registry"
+	"storemy/pkg/storage/heap"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"strings"
+	"time"
+)
+
+// maxFindingsPerObject caps the findings of one kind reported for a single
+// table, index or constraint; the remainder is summarized in one finding.
+const maxFindingsPerObject = 20
+
+// Checker runs the consistency checks against one database.
+type Checker struct {
+	catalog *catalogmanager.CatalogManager
+	indexes *indexmanager.IndexManager
+	dataDir string
+	tx      *transaction.TransactionContext
+
+	report      *Report
+	tables      map[primitives.FileID]*tableData
+	foreignKeys []*systemtable.ConstraintMetadata
+}
+
+// tableData is a table's decoded contents, kept for the foreign key pass.
+type tableData struct {
+	name     string
+	schema   *schema.Schema
+	tuples   []*tuple.Tuple
+	complete bool // False if some pages could not be decoded
+}
+
+// NewChecker creates a checker reading the catalog and indexes of ctx within
+// tx. The transaction is only read from; the caller commits or aborts it.
+func NewChecker(ctx *registry.DatabaseContext, tx *transaction.TransactionContext) *Checker {
+	return &Checker{
+		catalog: ctx.CatalogManager(),
+		indexes: ctx.IndexManager(),
+		dataDir: ctx.DataDir(),
+		tx:      tx,
+	}
+}
+
+// Run performs all checks and returns the report. An error is returned only
+// if the catalog itself cannot be read; every other problem is a finding.
+func (c *Checker) Run() (*Report, error) {
+	start := time.Now()
+	c.report = &Report{}
+	c.tables = make(map[primitives.FileID]*tableData)
+	c.foreignKeys = nil
+
+	tables, err := c.catalog.GetAllTables(c.tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table catalog: %w", err)
+	}
+	indexes, err := c.catalog.NewIndexOps(c.tx).GetAllIndexes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index catalog: %w", err)
+	}
+
+	c.checkFiles(tables, indexes)
+	c.checkSystemTables()
+
+	for _, tm := range tables {
+		if c.fileMissing(tm.FilePath) {
+			continue
+		}
+		c.checkTable(tm, indexes)
+	}
+	c.checkForeignKeys()
+
+	c.report.Duration = time.Since(start)
+	return c.report, nil
+}
+
+// checkFiles cross-checks the catalog against the data directory: every
+// table and index must have its file, and every data file must belong to a
+// table, an index or the system catalog.
+func (c *Checker) checkFiles(tables []*systemtable.TableMetadata, indexes []*systemtable.IndexMetadata) {
+	referenced := map[string]bool{
+		absPath(filepath.Join(c.dataDir, config.SuperblockFile)): true,
+	}
+	for _, st := range systemtable.AllSystemTables {
+		referenced[absPath(filepath.Join(c.dataDir, st.FileName()))] = true
+	}
+
+	tableIDs := make(map[primitives.FileID]bool, len(tables))
+	for _, tm := range tables {
+		tableIDs[tm.TableID] = true
+		referenced[absPath(string(tm.FilePath))] = true
+		if c.fileMissing(tm.FilePath) {
+			c.report.add(SeverityError, CheckCatalogFiles, tm.TableName, "heap file %s is missing", tm.FilePath)
+		}
+	}
+
+	for _, im := range indexes {
+		referenced[absPath(string(im.FilePath))] = true
+		if !tableIDs[im.TableID] {
+			c.report.add(SeverityError, CheckCatalogFiles, im.IndexName, "index belongs to table %d, which is not in the catalog", im.TableID)
+		}
+		if c.fileMissing(im.FilePath) {
+			c.report.add(SeverityError, CheckCatalogFiles, im.IndexName, "index file %s is missing", im.FilePath)
+		}
+	}
+
+	entries, err := os.ReadDir(c.dataDir)
+	if err != nil {
+		c.report.add(SeverityError, CheckCatalogFiles, c.dataDir, "cannot list data directory: %v", err)
+		return
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".dat" && ext != ".idx") {
+			continue
+		}
+		path := filepath.Join(c.dataDir, e.Name())
+		if !referenced[absPath(path)] {
+			c.report.add(SeverityWarning, CheckCatalogFiles, e.Name(), "file is not referenced by the catalog")
+		}
+	}
+}
+
+// checkSystemTables verifies the heap pages of the catalog's own tables.
+func (c *Checker) checkSystemTables() {
+	for _, st := range systemtable.AllSystemTables {
+		id, err := c.catalog.GetTableID(c.tx, st.TableName())
+		if err != nil {
+			c.report.add(SeverityError, CheckCatalogFiles, st.TableName(), "system table is not loaded: %v", err)
+			continue
+		}
+		hf, err := c.heapFile(id)
+		if err != nil {
+			c.report.add(SeverityError, CheckCatalogFiles, st.TableName(), "%v", err)
+			continue
+		}
+		c.scanHeap(st.TableName(), hf)
+	}
+}
+
+// checkTable verifies one user table: its pages, its indexes and the
+// constraints that can be checked on the table alone.
+func (c *Checker) checkTable(tm *systemtable.TableMetadata, indexes []*systemtable.IndexMetadata) {
+	sch, err := c.catalog.GetTableSchema(c.tx, tm.TableID)
+	if err != nil {
+		c.report.add(SeverityError, CheckCatalogFiles, tm.TableName, "cannot load schema: %v", err)
+		return
+	}
+	hf, err := c.heapFile(tm.TableID)
+	if err != nil {
+		c.report.add(SeverityError, CheckCatalogFiles, tm.TableName, "%v", err)
+		return
+	}
+
+	c.report.TablesChecked++
+	tuples, complete := c.scanHeap(tm.TableName, hf)
+	data := &tableData{name: tm.TableName, schema: sch, tuples: tuples, complete: complete}
+	c.tables[tm.TableID] = data
+
+	c.checkIndexes(tm, data, indexes)
+	c.checkConstraints(tm, data)
+}
+
+// heapFile returns the open heap file of a table.
+func (c *Checker) heapFile(tableID primitives.FileID) (*heap.HeapFile, error) {
+	file, err := c.catalog.GetTableFile(tableID)
+	if err != nil {
+		return nil, fmt.Errorf("table is not loaded: %v", err)
+	}
+	hf, ok := file.(*heap.HeapFile)
+	if !ok {
+		return nil, fmt.Errorf("table file is %T, not a heap file", file)
+	}
+	return hf, nil
+}
+
+// scanHeap verifies every page of hf and returns the tuples of the pages that
+// decoded cleanly. complete is false if any page had to be skipped.
+func (c *Checker) scanHeap(name string, hf *heap.HeapFile) (tuples []*tuple.Tuple, complete bool) {
+	info, err := hf.GetFile().Stat()
+	if err != nil {
+		c.report.add(SeverityError, CheckHeapPages, name, "cannot stat heap file: %v", err)
+		return nil, false
+	}
+
+	size := info.Size()
+	if size%int64(page.PageSize) != 0 {
+		c.report.add(SeverityWarning, CheckHeapPages, name,
+			"file size %d is not a multiple of the page size; the trailing %d bytes are ignored",
+			size, size%int64(page.PageSize))
+	}
+
+	complete = true
+	td := hf.GetTupleDesc()
+	pages := primitives.PageNumber(size / int64(page.PageSize))
+	for pageNo := primitives.PageNumber(0); pageNo < pages; pageNo++ {
+		c.report.PagesChecked++
+
+		data, err := hf.ReadPageData(pageNo)
+		if err != nil {
+			c.report.add(SeverityError, CheckHeapPages, name, "page %d: cannot read: %v", pageNo, err)
+			complete = false
+			continue
+		}
+
+		if errs := heap.VerifyPageData(data, td); len(errs) > 0 {
+			for _, e := range errs {
+				c.report.add(SeverityError, CheckHeapPages, name, "page %d: %v", pageNo, e)
+			}
+			complete = false
+			continue
+		}
+
+		hp, err := heap.NewHeapPage(page.NewPageDescriptor(hf.GetID(), pageNo), data, td)
+		if err != nil {
+			c.report.add(SeverityError, CheckHeapPages, name, "page %d: %v", pageNo, err)
+			complete = false
+			continue
+		}
+		pageTuples := hp.GetTuples()
+		c.report.TuplesChecked += len(pageTuples)
+		tuples = append(tuples, pageTuples...)
+	}
+
+	return tuples, complete
+}
+
+func (c *Checker) fileMissing(path primitives.Filepath) bool {
+	_, err := os.Stat(string(path))
+	return os.IsNotExist(err)
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// limiter reports findings of one kind for one object, collapsing everything
+// past maxFindingsPerObject into a single summary finding.
+type limiter struct {
+	report   *Report
+	severity Severity
+	check    string
+	object   string
+	count    int
+}
+
+func (c *Checker) limiter(sev Severity, check, object string) *limiter {
+	return &limiter{report: c.report, severity: sev, check: check, object: object}
+}
+
+func (l *limiter) add(format string, args ...any) {
+	l.count++
+	if l.count <= maxFindingsPerObject {
+		l.report.add(l.severity, l.check, l.object, format, args...)
+	}
+}
+
+func (l *limiter) flush() {
+	if extra := l.count - maxFindingsPerObject; extra > 0 {
+		l.report.add(l.severity, l.check, l.object, "%d more similar findings omitted", extra)
+	}
+}
+
+// ridKey identifies a record ID for set membership.
+func ridKey(rid *tuple.TupleRecordID) string {
+	return fmt.Sprintf("%d/%d/%d", rid.PageID.FileID(), rid.PageID.PageNo(), rid.TupleNum)
+}
+
+// location describes where a row lives for use in messages.
+func location(rid *tuple.TupleRecordID) string {
+	return fmt.Sprintf("page %d slot %d", rid.PageID.PageNo(), rid.TupleNum)
+}
+
+// splitColumns parses a comma-separated constraint column list.
+func splitColumns(list string) []string {
+	var cols []string
+	for _, col := range strings.Split(list, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
@@ -0,0 +1,147 @@
+package fsck
+
+import (
+	"math"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/indexmanager"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+)
+
+// checkIndexes verifies that every index of a table agrees with its heap:
+// each row must be reachable through its key, and each index entry must point
+// at a row holding that key.
+func (c *Checker) checkIndexes(tm *systemtable.TableMetadata, data *tableData, catalogIndexes []*systemtable.IndexMetadata) {
+	opened, err := c.indexes.NewLoader(c.tx).LoadIndexes(tm.TableID)
+	if err != nil {
+		c.report.add(SeverityError, CheckIndexes, tm.TableName, "cannot load indexes: %v", err)
+		return
+	}
+
+	loaded := make(map[string]bool, len(opened))
+	for _, iwm := range opened {
+		loaded[iwm.Metadata().IndexName] = true
+	}
+	for _, im := range catalogIndexes {
+		if im.TableID == tm.TableID && !loaded[im.IndexName] && !c.fileMissing(im.FilePath) {
+			c.report.add(SeverityError, CheckIndexes, im.IndexName, "index could not be opened")
+		}
+	}
+
+	for _, iwm := range opened {
+		c.report.IndexesChecked++
+		c.checkIndex(iwm, data)
+	}
+}
+
+func (c *Checker) checkIndex(iwm *indexmanager.IndexWithMetadata, data *tableData) {
+	meta := iwm.Metadata()
+	idx := iwm.Index()
+	name := meta.IndexName
+
+	type keyRows struct {
+		key  types.Field
+		rids []*tuple.TupleRecordID
+	}
+
+	// Group the heap's rows by key so each key is searched once.
+	byKey := make(map[string]*keyRows)
+	heapRows := make(map[string]bool, len(data.tuples))
+	for _, t := range data.tuples {
+		heapRows[ridKey(t.RecordID)] = true
+		key, err := t.GetField(meta.ColumnIndex)
+		if err != nil || key == nil {
+			continue
+		}
+		k := key.String()
+		if byKey[k] == nil {
+			byKey[k] = &keyRows{key: key}
+		}
+		byKey[k].rids = append(byKey[k].rids, t.RecordID)
+	}
+
+	missing := c.limiter(SeverityError, CheckIndexes, name)
+	wrong := c.limiter(SeverityError, CheckIndexes, name)
+	dangling := c.limiter(SeverityError, CheckIndexes, name)
+	reported := make(map[string]bool)
+
+	for k, kr := range byKey {
+		entries, err := idx.Search(kr.key)
+		if err != nil {
+			c.report.add(SeverityError, CheckIndexes, name, "search for key %s failed: %v", k, err)
+			continue
+		}
+
+		inIndex := make(map[string]bool, len(entries))
+		for _, rid := range entries {
+			inIndex[ridKey(rid)] = true
+		}
+		inHeap := make(map[string]bool, len(kr.rids))
+		for _, rid := range kr.rids {
+			inHeap[ridKey(rid)] = true
+			if !inIndex[ridKey(rid)] {
+				missing.add("row at %s with key %s has no index entry", location(rid), k)
+			}
+		}
+
+		for _, rid := range entries {
+			rk := ridKey(rid)
+			switch {
+			case inHeap[rk]:
+			case heapRows[rk]:
+				wrong.add("entry for key %s points to %s, which holds a different key", k, location(rid))
+				reported[rk] = true
+			case data.complete:
+				dangling.add("entry for key %s points to %s, which holds no row", k, location(rid))
+				reported[rk] = true
+			}
+		}
+	}
+
+	// Entries whose key no longer occurs in the table are only reachable by
+	// scanning the whole key domain.
+	if lo, hi, ok := keyDomain(meta.KeyType); ok && data.complete {
+		entries, err := idx.RangeSearch(lo, hi)
+		if err != nil {
+			c.report.add(SeverityError, CheckIndexes, name, "full index scan failed: %v", err)
+		}
+		for _, rid := range entries {
+			if rk := ridKey(rid); !heapRows[rk] && !reported[rk] {
+				dangling.add("entry points to %s, which holds no row", location(rid))
+				reported[rk] = true
+			}
+		}
+	}
+
+	missing.flush()
+	wrong.flush()
+	dangling.flush()
+}
+
+// keyDomain returns the smallest and largest key of a type, so a range search
+// between them visits every entry. ok is false for types without usable
+// bounds.
+func keyDomain(t types.Type) (lo, hi types.Field, ok bool) {
+	switch t {
+	case types.IntType:
+		return types.NewIntField(math.MinInt64), types.NewIntField(math.MaxInt64), true
+	case types.Int32Type:
+		return types.NewInt32Field(math.MinInt32), types.NewInt32Field(math.MaxInt32), true
+	case types.Int64Type:
+		return types.NewInt64Field(math.MinInt64), types.NewInt64Field(math.MaxInt64), true
+	case types.Uint32Type:
+		return types.NewUint32Field(0), types.NewUint32Field(math.MaxUint32), true
+	case types.Uint64Type:
+		return types.NewUint64Field(0), types.NewUint64Field(math.MaxUint64), true
+	case types.FloatType:
+		return types.NewFloat64Field(math.Inf(-1)), types.NewFloat64Field(math.Inf(1)), true
+	case types.BoolType:
+		return types.NewBoolField(false), types.NewBoolField(true), true
+	case types.StringType:
+		return types.NewStringField("", types.StringMaxSize),
+			types.NewStringField(strings.Repeat("\xff", types.StringMaxSize), types.StringMaxSize), true
+	default:
+		return nil, nil, false
+	}
+}
@@ -0,0 +1,125 @@
+package fsck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity ranks a finding by how much it threatens correctness.
+type Severity int
+
+const (
+	// SeverityInfo marks something the checker noticed but could not or did
+	// not need to verify.
+	SeverityInfo Severity = iota
+
+	// SeverityWarning marks a problem that wastes space or hints at an
+	// interrupted operation but does not make query results wrong.
+	SeverityWarning
+
+	// SeverityError marks corruption: data that is unreadable, missing or
+	// inconsistent with the catalog, an index or a constraint.
+	SeverityError
+)
+
+// String returns the severity name used in reports.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarning:
+		return "WARNING"
+	case SeverityError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Names of the individual checks, used in Finding.Check.
+const (
+	CheckCatalogFiles = "catalog_files"
+	CheckHeapPages    = "heap_pages"
+	CheckIndexes      = "index_heap"
+	CheckConstraints  = "constraints"
+)
+
+// Finding is a single problem reported by the checker.
+type Finding struct {
+	Severity Severity
+	Check    string // One of the Check* constants
+	Object   string // Table, index, constraint or file the finding is about
+	Message  string
+}
+
+// String renders the finding on a single line.
+func (f Finding) String() string {
+	return fmt.Sprintf("%-7s %-13s %s: %s", f.Severity, f.Check, f.Object, f.Message)
+}
+
+// Report is the result of a consistency check.
+type Report struct {
+	Findings []Finding
+
+	TablesChecked      int
+	IndexesChecked     int
+	ConstraintsChecked int
+	PagesChecked       int
+	TuplesChecked      int
+	Duration           time.Duration
+}
+
+func (r *Report) add(sev Severity, check, object, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{
+		Severity: sev,
+		Check:    check,
+		Object:   object,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Count returns the number of findings with the given severity.
+func (r *Report) Count(sev Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
+
+// OK reports whether the check found no errors. Warnings and informational
+// findings do not make a database inconsistent.
+func (r *Report) OK() bool {
+	return r.Count(SeverityError) == 0
+}
+
+// Filter returns the findings produced by the named check.
+func (r *Report) Filter(check string) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Check == check {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// String renders a summary line followed by one line per finding.
+func (r *Report) String() string {
+	var sb strings.Builder
+	status := "OK"
+	if !r.OK() {
+		status = "INCONSISTENT"
+	}
+	fmt.Fprintf(&sb, "%s: %d tables, %d indexes, %d constraints, %d pages, %d tuples checked in %s; %d errors, %d warnings\n",
+		status, r.TablesChecked, r.IndexesChecked, r.ConstraintsChecked, r.PagesChecked, r.TuplesChecked,
+		r.Duration.Round(time.Millisecond), r.Count(SeverityError), r.Count(SeverityWarning))
+	for _, f := range r.Findings {
+		sb.WriteString(f.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -7,10 +7,12 @@ import (
 	"storemy/pkg/storage/index/btree"
 	"storemy/pkg/storage/index/hash"
 	"storemy/pkg/types"
+	"storemy/pkg/vfs"
 )
 
 type IndexFileOps struct {
-	f primitives.Filepath
+	f    primitives.Filepath
+	fsys vfs.FS
 }
 
 // CreatePhysicalIndex creates the physical index file on disk and returns its actual file ID.
@@ -36,7 +38,7 @@ func (i *IndexFileOps) CreatePhysicalIndex(keyType types.Type, indexType index.I
 
 	switch indexType {
 	case index.HashIndex:
-		hashFile, err := hash.NewHashFile(i.f, keyType, hash.DefaultBuckets)
+		hashFile, err := hash.NewHashFileWithFS(i.fsys, i.f, keyType, hash.DefaultBuckets)
 		if err != nil {
 			return 0, fmt.Errorf("failed to create hash index file: %v", err)
 		}
@@ -47,7 +49,7 @@ func (i *IndexFileOps) CreatePhysicalIndex(keyType types.Type, indexType index.I
 		return indexID, nil
 
 	case index.BTreeIndex:
-		btreeFile, err := btree.NewBTreeFile(i.f, keyType)
+		btreeFile, err := btree.NewBTreeFileWithFS(i.fsys, i.f, keyType)
 		if err != nil {
 			return 0, fmt.Errorf("failed to create btree index file: %v", err)
 		}
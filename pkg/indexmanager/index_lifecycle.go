@@ -124,7 +124,7 @@ func (im *IndexManager) PopulateIndex(
 
 	switch indexType {
 	case index.HashIndex:
-		hashFile, err := hash.NewHashFile(filePath, keyType, hash.DefaultBuckets)
+		hashFile, err := hash.NewHashFileWithFS(im.pageStore.FS(), filePath, keyType, hash.DefaultBuckets)
 		if err != nil {
 			return fmt.Errorf("failed to open hash index: %v", err)
 		}
@@ -137,7 +137,7 @@ func (im *IndexManager) PopulateIndex(
 		}
 
 	case index.BTreeIndex:
-		btreeFile, err := btree.NewBTreeFile(filePath, keyType)
+		btreeFile, err := btree.NewBTreeFileWithFS(im.pageStore.FS(), filePath, keyType)
 		if err != nil {
 			return fmt.Errorf("failed to open btree index: %v", err)
 		}
@@ -198,7 +198,7 @@ func (il *IndexLoader) openIndex(m *IndexMetadata) (index.Index, error) {
 //   - A BTree index wrapper ready for use
 //   - An error if the file cannot be opened or initialized
 func (il *IndexLoader) openBTreeIndex(m *IndexMetadata) (*btreeindex.BTree, error) {
-	file, err := btree.NewBTreeFile(m.FilePath, m.KeyType)
+	file, err := btree.NewBTreeFileWithFS(il.store.FS(), m.FilePath, m.KeyType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open BTree file: %v", err)
 	}
@@ -218,7 +218,7 @@ func (il *IndexLoader) openBTreeIndex(m *IndexMetadata) (*btreeindex.BTree, erro
 //   - A HashIndex wrapper ready for use
 //   - An error if the file cannot be opened or initialized
 func (il *IndexLoader) openHashIndex(m *IndexMetadata) (*hashindex.HashIndex, error) {
-	file, err := hash.NewHashFile(m.FilePath, m.KeyType, hash.DefaultBuckets)
+	file, err := hash.NewHashFileWithFS(il.store.FS(), m.FilePath, m.KeyType, hash.DefaultBuckets)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open hash file: %v", err)
 	}
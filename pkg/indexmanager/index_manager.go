@@ -41,6 +41,16 @@ type IndexWithMetadata struct {
 	metadata *IndexMetadata // Resolved metadata for this index
 }
 
+// Index returns the opened index.
+func (iwm *IndexWithMetadata) Index() index.Index {
+	return iwm.index
+}
+
+// Metadata returns the resolved metadata of the index.
+func (iwm *IndexWithMetadata) Metadata() *IndexMetadata {
+	return iwm.metadata
+}
+
 // IndexManager manages all indexes in the database.
 // It handles:
 //   - Loading index metadata from catalog
@@ -128,7 +138,8 @@ func (im *IndexManager) NewLoader(tx *transaction.TransactionContext) *IndexLoad
 
 func (im *IndexManager) NewFileOps(filePath primitives.Filepath) *IndexFileOps {
 	return &IndexFileOps{
-		f: filePath,
+		f:    filePath,
+		fsys: im.pageStore.FS(),
 	}
 }
 
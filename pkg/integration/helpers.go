@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"storemy/pkg/database"
+	"storemy/pkg/logging"
 	"testing"
 )
 
@@ -27,7 +28,9 @@ func SetupTestDB(t *testing.T) *TestDatabase {
 	dataDir := filepath.Join(tempDir, "data")
 	logDir := filepath.Join(tempDir, "logs")
 
-	db, err := database.NewDatabase("testdb", dataDir, logDir)
+	opts := database.DefaultOptions()
+	opts.Logger = logging.NewNopLogger()
+	db, err := database.NewDatabaseWithOptions("testdb", dataDir, logDir, opts)
 	if err != nil {
 		os.RemoveAll(tempDir)
 		t.Fatalf("failed to create database: %v", err)
@@ -0,0 +1,6 @@
+//go:build !storemy_assert
+
+package invariant
+
+// buildEnabled leaves assertions to the runtime switch.
+const buildEnabled = false
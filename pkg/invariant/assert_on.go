@@ -0,0 +1,6 @@
+//go:build storemy_assert
+
+package invariant
+
+// buildEnabled forces assertions on in builds with the storemy_assert tag.
+const buildEnabled = true
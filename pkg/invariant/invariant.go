@@ -0,0 +1,68 @@
+// Package invariant holds the debug assertions of the storage and recovery
+// layers: checks of internal invariants (page latching discipline, the WAL
+// rule, the layout of heap pages, the undo chain) that are too expensive to
+// run in production but catch corruption where it happens rather than when
+// a later read trips over it.
+//
+// Assertions are on when the binary is built with the storemy_assert build
+// tag, and otherwise follow a runtime switch. The switch starts on in test
+// binaries and when the STOREMY_ASSERTIONS environment variable is set, and
+// SetEnabled flips it. Call sites guard checks with Enabled, so a production
+// build pays one atomic load per check:
+//
+//	if invariant.Enabled() && offset < headerSize {
+//		invariant.Failf("slot %d: offset %d inside the slot array", i, offset)
+//	}
+package invariant
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+// enabled is the runtime switch, consulted when the build tag is not set.
+var enabled atomic.Bool
+
+func init() {
+	enabled.Store(testing.Testing() || os.Getenv("STOREMY_ASSERTIONS") != "")
+}
+
+// Enabled reports whether assertions are on.
+func Enabled() bool {
+	return buildEnabled || enabled.Load()
+}
+
+// SetEnabled turns assertions on or off at run time and returns the function
+// that restores the previous setting. In a build with the storemy_assert tag
+// assertions stay on regardless.
+func SetEnabled(on bool) (restore func()) {
+	previous := enabled.Swap(on)
+	return func() { enabled.Store(previous) }
+}
+
+// Violation is the value an assertion panics with.
+type Violation struct {
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return "invariant violated: " + v.Message
+}
+
+// Failf reports a violated invariant by panicking with a *Violation whose
+// message is formatted from format and args. Callers check Enabled first.
+func Failf(format string, args ...any) {
+	violations.Inc()
+	panic(&Violation{Message: fmt.Sprintf(format, args...)})
+}
+
+// Check calls Failf if assertions are on and cond is false. The arguments
+// are evaluated either way, so checks whose condition is costly to compute
+// test Enabled themselves.
+func Check(cond bool, format string, args ...any) {
+	if !cond && Enabled() {
+		Failf(format, args...)
+	}
+}
@@ -0,0 +1,39 @@
+package invariant
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck_PanicsWithViolationWhenEnabled(t *testing.T) {
+	if !Enabled() {
+		t.Fatal("expected assertions to be on in tests")
+	}
+
+	defer func() {
+		var v *Violation
+		err, _ := recover().(error)
+		if !errors.As(err, &v) || v.Message != "slot 3 out of bounds" {
+			t.Errorf("expected a Violation for slot 3, got %v", err)
+		}
+	}()
+	Check(false, "slot %d out of bounds", 3)
+	t.Error("Check did not panic")
+}
+
+func TestSetEnabled_TurnsChecksOff(t *testing.T) {
+	if buildEnabled {
+		t.Skip("assertions are forced on by the storemy_assert build tag")
+	}
+
+	restore := SetEnabled(false)
+	if Enabled() {
+		t.Error("expected assertions to be off after SetEnabled(false)")
+	}
+	Check(false, "ignored while off")
+
+	restore()
+	if !Enabled() {
+		t.Error("expected restore to turn assertions back on")
+	}
+}
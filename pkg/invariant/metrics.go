@@ -0,0 +1,8 @@
+package invariant
+
+import "storemy/pkg/metrics"
+
+var violations = metrics.NewCounter(
+	"storemy_invariant_violations_total",
+	"Internal invariants found violated while assertions were on",
+)
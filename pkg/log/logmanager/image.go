@@ -0,0 +1,83 @@
+package logmanager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// slotSize is the size of the slot number at the start of a tuple image.
+const slotSize = 2
+
+// EncodeTupleImage serializes a tuple into a before or after image.
+// The page of the tuple is recorded in the log record itself, so the image
+// only carries the slot number followed by the tuple's fields:
+//
+//	[Slot:2][Field0][Field1]...[FieldN]
+func EncodeTupleImage(t *tuple.Tuple) ([]byte, error) {
+	if t == nil {
+		return nil, fmt.Errorf("tuple cannot be nil")
+	}
+	if t.RecordID == nil {
+		return nil, fmt.Errorf("tuple has no RecordID")
+	}
+	return encodeImage(t.RecordID.TupleNum, t)
+}
+
+// encodeImage serializes t as a tuple image for the given slot.
+func encodeImage(slot primitives.SlotID, t *tuple.Tuple) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, uint16(slot)); err != nil {
+		return nil, fmt.Errorf("failed to write slot: %w", err)
+	}
+
+	for i := primitives.ColumnID(0); i < t.TupleDesc.NumFields(); i++ {
+		field, err := t.GetField(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get field %d: %w", i, err)
+		}
+		if field == nil {
+			return nil, fmt.Errorf("field %d is not set", i)
+		}
+		if err := field.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("failed to serialize field %d: %w", i, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeTupleImage rebuilds the tuple stored in an image written by
+// EncodeTupleImage. The tuple's RecordID combines pageID, the page of the
+// log record, with the slot in the image.
+func DecodeTupleImage(pageID primitives.PageID, image []byte, td *tuple.TupleDescription) (*tuple.Tuple, error) {
+	if len(image) < slotSize {
+		return nil, fmt.Errorf("tuple image too short: %d bytes", len(image))
+	}
+
+	reader := bytes.NewReader(image[slotSize:])
+	t := tuple.NewTuple(td)
+	for i := primitives.ColumnID(0); i < td.NumFields(); i++ {
+		fieldType, err := td.TypeAtIndex(i)
+		if err != nil {
+			return nil, err
+		}
+
+		field, err := types.ParseField(reader, fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse field %d: %w", i, err)
+		}
+		if err := t.SetField(i, field); err != nil {
+			return nil, err
+		}
+	}
+	if reader.Len() != 0 {
+		return nil, fmt.Errorf("tuple image has %d trailing bytes", reader.Len())
+	}
+
+	slot := primitives.SlotID(binary.BigEndian.Uint16(image))
+	t.RecordID = tuple.NewTupleRecordID(pageID, slot)
+	return t, nil
+}
@@ -0,0 +1,78 @@
+// Package logmanager logs tuple changes to the write-ahead log.
+//
+// The WAL itself only stores opaque before and after images. LogManager sits
+// on top of it for the DML layer: callers hand it the tuples they change, and
+// it derives the page from each tuple's RecordID and serializes the images
+// itself, so an undo image can never describe a different tuple or page than
+// the change it belongs to.
+package logmanager
+
+import (
+	"fmt"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+)
+
+// LogManager writes tuple-level INSERT, DELETE and UPDATE records to a WAL.
+// Each record's PageID is the page of the tuple's RecordID, and its images
+// are tuple images (see EncodeTupleImage) rather than whole pages.
+type LogManager struct {
+	wal *wal.WAL
+}
+
+// NewLogManager creates a LogManager writing to w.
+func NewLogManager(w *wal.WAL) *LogManager {
+	return &LogManager{wal: w}
+}
+
+// LogInsert logs the insertion of t, which must already have the RecordID
+// of the slot it was stored in. The record's after image is t.
+func (lm *LogManager) LogInsert(tid *primitives.TransactionID, t *tuple.Tuple) (primitives.LSN, error) {
+	image, err := EncodeTupleImage(t)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode inserted tuple: %w", err)
+	}
+	return lm.wal.LogInsert(tid, t.RecordID.PageID, image)
+}
+
+// LogDelete logs the deletion of t, which must still have its RecordID. The
+// record's before image is t, the version UNDO restores.
+func (lm *LogManager) LogDelete(tid *primitives.TransactionID, t *tuple.Tuple) (primitives.LSN, error) {
+	image, err := EncodeTupleImage(t)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode deleted tuple: %w", err)
+	}
+	return lm.wal.LogDelete(tid, t.RecordID.PageID, image)
+}
+
+// LogUpdate logs the replacement of oldTuple by newTuple in the same slot.
+// newTuple takes oldTuple's RecordID if it has none yet, so the update can be
+// logged before the page is changed; a newTuple with a different RecordID is
+// rejected, since its images would describe two different rows.
+func (lm *LogManager) LogUpdate(tid *primitives.TransactionID, oldTuple, newTuple *tuple.Tuple) (primitives.LSN, error) {
+	if oldTuple == nil || newTuple == nil {
+		return 0, fmt.Errorf("old and new tuples cannot be nil")
+	}
+	if oldTuple.RecordID == nil {
+		return 0, fmt.Errorf("old tuple has no RecordID")
+	}
+	if newTuple.RecordID != nil && !newTuple.RecordID.Equals(oldTuple.RecordID) {
+		return 0, fmt.Errorf("update must keep the RecordID: old %v, new %v", oldTuple.RecordID, newTuple.RecordID)
+	}
+	if !oldTuple.TupleDesc.Equals(newTuple.TupleDesc) {
+		return 0, fmt.Errorf("old and new tuples have different schemas")
+	}
+
+	before, err := EncodeTupleImage(oldTuple)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode old tuple: %w", err)
+	}
+
+	// The new version is logged for the old slot, where it is about to live
+	after, err := encodeImage(oldTuple.RecordID.TupleNum, newTuple)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode new tuple: %w", err)
+	}
+	return lm.wal.LogUpdate(tid, oldTuple.RecordID.PageID, before, after)
+}
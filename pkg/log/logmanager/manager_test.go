@@ -0,0 +1,163 @@
+package logmanager
+
+import (
+	"path/filepath"
+	"storemy/pkg/log/record"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+func newTestTupleDesc(t *testing.T) *tuple.TupleDescription {
+	t.Helper()
+	td, err := tuple.NewTupleDesc([]types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	return td
+}
+
+func newTestTuple(td *tuple.TupleDescription, id int64, name string, rid *tuple.TupleRecordID) *tuple.Tuple {
+	t := tuple.NewTuple(td)
+	t.SetField(0, types.NewIntField(id))
+	t.SetField(1, types.NewStringField(name, types.StringMaxSize))
+	t.RecordID = rid
+	return t
+}
+
+func TestLogManager_RecordsTupleImages(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "test.wal")
+	w, err := wal.NewWAL(logPath, 4096)
+	if err != nil {
+		t.Fatalf("NewWAL failed: %v", err)
+	}
+
+	lm := NewLogManager(w)
+	td := newTestTupleDesc(t)
+	tid := primitives.NewTransactionID()
+	rid := tuple.NewTupleRecordID(page.NewPageDescriptor(3, 5), 7)
+
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	inserted := newTestTuple(td, 1, "alice", rid)
+	if _, err := lm.LogInsert(tid, inserted); err != nil {
+		t.Fatalf("LogInsert failed: %v", err)
+	}
+	// The new version has no RecordID yet and takes the old one's
+	updated := newTestTuple(td, 1, "bob", nil)
+	if _, err := lm.LogUpdate(tid, inserted, updated); err != nil {
+		t.Fatalf("LogUpdate failed: %v", err)
+	}
+	updated.RecordID = rid
+	if _, err := lm.LogDelete(tid, updated); err != nil {
+		t.Fatalf("LogDelete failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reader, err := wal.NewLogReader(logPath)
+	if err != nil {
+		t.Fatalf("NewLogReader failed: %v", err)
+	}
+	defer reader.Close()
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+
+	decode := func(rec *record.LogRecord, image []byte) string {
+		t.Helper()
+		if image == nil {
+			return ""
+		}
+		tup, err := DecodeTupleImage(rec.PageID, image, td)
+		if err != nil {
+			t.Fatalf("DecodeTupleImage failed: %v", err)
+		}
+		if !tup.RecordID.Equals(rid) {
+			t.Errorf("decoded RecordID = %v, want %v", tup.RecordID, rid)
+		}
+		name, _ := tup.GetField(1)
+		return name.String()
+	}
+
+	want := []struct {
+		typ           record.LogRecordType
+		before, after string
+	}{
+		{record.BeginRecord, "", ""},
+		{record.InsertRecord, "", "alice"},
+		{record.UpdateRecord, "alice", "bob"},
+		{record.DeleteRecord, "bob", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %d", len(want), len(records))
+	}
+	for i, exp := range want {
+		rec := records[i]
+		if rec.Type != exp.typ {
+			t.Errorf("record %d: type %v, want %v", i, rec.Type, exp.typ)
+			continue
+		}
+		if before := decode(rec, rec.BeforeImage); before != exp.before {
+			t.Errorf("record %d: before image %q, want %q", i, before, exp.before)
+		}
+		if after := decode(rec, rec.AfterImage); after != exp.after {
+			t.Errorf("record %d: after image %q, want %q", i, after, exp.after)
+		}
+	}
+}
+
+func TestLogManager_RejectsMismatchedTuples(t *testing.T) {
+	w, err := wal.NewWAL(filepath.Join(t.TempDir(), "test.wal"), 4096)
+	if err != nil {
+		t.Fatalf("NewWAL failed: %v", err)
+	}
+	defer w.Close()
+
+	lm := NewLogManager(w)
+	td := newTestTupleDesc(t)
+	tid := primitives.NewTransactionID()
+	w.LogBegin(tid)
+
+	pid := page.NewPageDescriptor(3, 5)
+	old := newTestTuple(td, 1, "a", tuple.NewTupleRecordID(pid, 0))
+
+	tests := []struct {
+		name    string
+		log     func() error
+		wantErr string
+	}{
+		{"insert without RecordID", func() error {
+			_, err := lm.LogInsert(tid, newTestTuple(td, 1, "a", nil))
+			return err
+		}, "no RecordID"},
+		{"delete nil tuple", func() error {
+			_, err := lm.LogDelete(tid, nil)
+			return err
+		}, "cannot be nil"},
+		{"update moving the row", func() error {
+			_, err := lm.LogUpdate(tid, old, newTestTuple(td, 1, "b", tuple.NewTupleRecordID(pid, 1)))
+			return err
+		}, "must keep the RecordID"},
+		{"update without old RecordID", func() error {
+			_, err := lm.LogUpdate(tid, newTestTuple(td, 1, "a", nil), newTestTuple(td, 1, "b", nil))
+			return err
+		}, "no RecordID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.log()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package record
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+)
+
+// PageRangeSize is the size of the StartPage and EndPage fields of a bulk
+// load record.
+const PageRangeSize = 16
+
+// BulkLoad describes pages a transaction wrote to a heap file without
+// logging them. The pages from StartPage up to EndPage were appended to the
+// file; nothing before StartPage was touched.
+//
+// A load is announced by a BulkLoadRecord, whose EndPage is 0 since the
+// load has not written anything yet, and which is forced before the first
+// page reaches the file. Once every page is written and the file is synced,
+// a BulkLoadBarrierRecord carries the new relation size in EndPage: from
+// then on the pages are as durable as a committed page write. If the
+// transaction does not commit, the load is undone by zeroing its pages,
+// which turns them back into the empty pages the file was extended with.
+type BulkLoad struct {
+	Path      primitives.Filepath
+	StartPage primitives.PageNumber
+	EndPage   primitives.PageNumber // Relation size after the load, 0 until the barrier
+}
+
+func (b BulkLoad) String() string {
+	if b.EndPage == 0 {
+		return fmt.Sprintf("%s from page %d", b.Path, b.StartPage)
+	}
+	return fmt.Sprintf("%s pages %d-%d", b.Path, b.StartPage, b.EndPage)
+}
+
+// Undo zeroes the pages of the load on fsys and syncs the file. Without a
+// barrier the end of the load is unknown, and every page from StartPage to
+// the end of the file is zeroed: no other transaction can extend a file
+// while it is being loaded. Undo is idempotent, so that recovery can repeat
+// it after a crash.
+func (b BulkLoad) Undo(fsys vfs.FS) error {
+	f, err := fsys.OpenFile(string(b.Path), os.O_RDWR, 0644)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", b.Path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", b.Path, err)
+	}
+	end := primitives.PageNumber((info.Size() + page.PageSize - 1) / page.PageSize)
+	if b.EndPage != 0 && b.EndPage < end {
+		end = b.EndPage
+	}
+
+	zero := make([]byte, page.PageSize)
+	for pageNo := b.StartPage; pageNo < end; pageNo++ {
+		if _, err := f.WriteAt(zero, int64(pageNo)*page.PageSize); err != nil {
+			return fmt.Errorf("failed to zero page %d of %s: %w", pageNo, b.Path, err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", b.Path, err)
+	}
+	return nil
+}
+
+// NewBulkLoadRecord returns the record announcing a bulk load by tid.
+func NewBulkLoadRecord(tid *primitives.TransactionID, load BulkLoad, prevLSN LSN) *LogRecord {
+	rec := NewLogRecord(BulkLoadRecord, tid, nil, nil, nil, prevLSN)
+	rec.BulkLoad = load
+	return rec
+}
+
+// NewBulkLoadBarrierRecord returns the record saying that the pages of a
+// bulk load by tid are durable.
+func NewBulkLoadBarrierRecord(tid *primitives.TransactionID, load BulkLoad, prevLSN LSN) *LogRecord {
+	rec := NewLogRecord(BulkLoadBarrierRecord, tid, nil, nil, nil, prevLSN)
+	rec.BulkLoad = load
+	return rec
+}
+
+func (b BulkLoad) serializedSize() int {
+	return ImageLengthSize + len(b.Path) + PageRangeSize
+}
+
+// appendBulkLoad serializes b as [PathLen:4][Path][StartPage:8][EndPage:8].
+func appendBulkLoad(dst []byte, b BulkLoad) []byte {
+	dst = appendImage(dst, []byte(b.Path))
+	dst = binary.BigEndian.AppendUint64(dst, uint64(b.StartPage))
+	return binary.BigEndian.AppendUint64(dst, uint64(b.EndPage))
+}
+
+// deserializeBulkLoad deserializes the payload of a bulk load record.
+func deserializeBulkLoad(buf *bytes.Reader, record *LogRecord) error {
+	path, err := deserializeImage(buf)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize path: %w", err)
+	}
+	record.BulkLoad.Path = primitives.Filepath(path)
+
+	var start, end uint64
+	if err := binary.Read(buf, binary.BigEndian, &start); err != nil {
+		return fmt.Errorf("failed to read start page: %w", err)
+	}
+	if err := binary.Read(buf, binary.BigEndian, &end); err != nil {
+		return fmt.Errorf("failed to read end page: %w", err)
+	}
+	record.BulkLoad.StartPage = primitives.PageNumber(start)
+	record.BulkLoad.EndPage = primitives.PageNumber(end)
+	return nil
+}
@@ -0,0 +1,80 @@
+package record
+
+import (
+	"bytes"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+	"testing"
+)
+
+func TestBulkLoadRecord_RoundTrip(t *testing.T) {
+	tid := primitives.NewTransactionIDFromValue(7)
+	load := BulkLoad{Path: "/data/users.dat", StartPage: 3, EndPage: 10}
+
+	for _, rec := range []*LogRecord{NewBulkLoadRecord(tid, BulkLoad{Path: load.Path, StartPage: 3}, 42), NewBulkLoadBarrierRecord(tid, load, 42)} {
+		data, err := SerializeLogRecord(rec)
+		if err != nil {
+			t.Fatalf("SerializeLogRecord failed: %v", err)
+		}
+		if len(data) != rec.SerializedSize() {
+			t.Errorf("serialized %d bytes, SerializedSize says %d", len(data), rec.SerializedSize())
+		}
+
+		got, err := DeserializeLogRecord(data)
+		if err != nil {
+			t.Fatalf("DeserializeLogRecord failed: %v", err)
+		}
+		if got.Type != rec.Type || got.PrevLSN != 42 || got.TID.ID() != 7 {
+			t.Errorf("header mismatch: type %v, PrevLSN %d, TID %d", got.Type, got.PrevLSN, got.TID.ID())
+		}
+		if got.BulkLoad != rec.BulkLoad {
+			t.Errorf("bulk load mismatch: got %s, want %s", got.BulkLoad, rec.BulkLoad)
+		}
+	}
+}
+
+func TestBulkLoad_Undo(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	full := bytes.Repeat([]byte{0xAB}, 5*page.PageSize)
+	if err := fsys.WriteFile("/t.dat", full, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	pageBytes := func(pageNo int) []byte {
+		data, err := fsys.ReadFile("/t.dat")
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		return data[pageNo*page.PageSize : (pageNo+1)*page.PageSize]
+	}
+	zero := make([]byte, page.PageSize)
+
+	// With a barrier only the loaded range is zeroed
+	if err := (BulkLoad{Path: "/t.dat", StartPage: 1, EndPage: 3}).Undo(fsys); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	for pageNo, want := range map[int]bool{0: false, 1: true, 2: true, 3: false, 4: false} {
+		if got := bytes.Equal(pageBytes(pageNo), zero); got != want {
+			t.Errorf("page %d: zeroed %v, want %v", pageNo, got, want)
+		}
+	}
+
+	// Without one, everything from the start page is
+	load := BulkLoad{Path: "/t.dat", StartPage: 3}
+	for range 2 {
+		if err := load.Undo(fsys); err != nil {
+			t.Fatalf("Undo failed: %v", err)
+		}
+	}
+	if !bytes.Equal(pageBytes(4), zero) || bytes.Equal(pageBytes(0), zero) {
+		t.Error("expected pages 3 and 4 zeroed and page 0 kept")
+	}
+	if info, err := fsys.Stat("/t.dat"); err != nil || info.Size() != int64(len(full)) {
+		t.Errorf("expected the file to keep its size, got %v (err %v)", info, err)
+	}
+
+	if err := (BulkLoad{Path: "/missing.dat"}).Undo(fsys); err != nil {
+		t.Errorf("expected undoing a load of a missing file to succeed, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package record
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"storemy/pkg/primitives"
 	"time"
@@ -20,21 +21,30 @@ type CheckpointRecord struct {
 
 	// Dirty pages at checkpoint time
 	// Maps page ID -> first LSN that dirtied the page
-	DirtyPages map[primitives.HashCode]primitives.LSN
+	DirtyPages map[primitives.PageKey]primitives.LSN
+
+	// Dirty pages of a checkpoint written in the legacy format, which kept
+	// only a hash of each page ID. The pages cannot be recovered from the
+	// hashes, so recovery rebuilds them from the log starting at the
+	// earliest of these LSNs.
+	LegacyDirtyPages map[primitives.HashCode]primitives.LSN
+
+	// Highest transaction ID allocated at checkpoint time, so that IDs keep
+	// growing after a restart even once the WAL holding them is truncated
+	MaxTID int64
 }
 
 // NewCheckpointRecord creates a new checkpoint record
-func NewCheckpointRecord(activeTxns map[*primitives.TransactionID]*TransactionLogInfo, dirtyPages map[primitives.PageID]primitives.LSN) *CheckpointRecord {
+func NewCheckpointRecord(activeTxns map[*primitives.TransactionID]*TransactionLogInfo, dirtyPages map[primitives.PageKey]primitives.LSN) *CheckpointRecord {
 	// Convert activeTxns map to use int64 keys for serialization
 	txnMap := make(map[int64]*TransactionLogInfo)
 	for tid, info := range activeTxns {
 		txnMap[tid.ID()] = info
 	}
 
-	// Convert dirtyPages to use HashCode keys
-	pageMap := make(map[primitives.HashCode]primitives.LSN)
-	for pageID, lsn := range dirtyPages {
-		pageMap[pageID.HashCode()] = lsn
+	pageMap := make(map[primitives.PageKey]primitives.LSN, len(dirtyPages))
+	for key, lsn := range dirtyPages {
+		pageMap[key] = lsn
 	}
 
 	return &CheckpointRecord{
@@ -44,21 +54,35 @@ func NewCheckpointRecord(activeTxns map[*primitives.TransactionID]*TransactionLo
 	}
 }
 
+// Checkpoint format versions. Version 1 checkpoints have no magic number
+// and key dirty pages by a hash of the page ID; they start with the
+// checkpoint LSN, whose high bytes are never the magic number.
+const (
+	checkpointMagic                = "SMCK"
+	CheckpointVersion       uint16 = 2
+	legacyCheckpointVersion        = 1
+)
+
 // SerializeCheckpoint serializes a checkpoint record to bytes
 //
 // Binary format:
-// [Size:4][LSN:8][Timestamp:8][NumTxns:4][TxnData...][NumPages:4][PageData...]
+// [Size:4][Magic:4][Version:2][LSN:8][Timestamp:8][NumTxns:4][TxnData...][NumPages:4][PageData...][MaxTID:8]
 //
 // TxnData format (repeated NumTxns times):
 // [TID:8][FirstLSN:8][LastLSN:8][UndoNextLSN:8]
 //
 // PageData format (repeated NumPages times):
-// [PageHash:8][FirstDirtyLSN:8]
+// [FileID:8][PageNo:8][FirstDirtyLSN:8]
+//
+// Legacy dirty pages are not written: a checkpoint read in the legacy format
+// is never written back.
 func SerializeCheckpoint(cp *CheckpointRecord) ([]byte, error) {
 	var buf bytes.Buffer
+	buf.WriteString(checkpointMagic)
 
 	// Write header
 	writes := []any{
+		CheckpointVersion,
 		uint64(cp.LSN),
 		uint64(cp.Timestamp.Unix()),
 	}
@@ -94,9 +118,10 @@ func SerializeCheckpoint(cp *CheckpointRecord) ([]byte, error) {
 		return nil, fmt.Errorf("failed to write page count: %w", err)
 	}
 
-	for pageHash, lsn := range cp.DirtyPages {
+	for key, lsn := range cp.DirtyPages {
 		pageWrites := []any{
-			uint64(pageHash),
+			uint64(key.FileID),
+			uint64(key.PageNo),
 			uint64(lsn),
 		}
 
@@ -107,6 +132,10 @@ func SerializeCheckpoint(cp *CheckpointRecord) ([]byte, error) {
 		}
 	}
 
+	if err := binary.Write(&buf, binary.BigEndian, uint64(cp.MaxTID)); err != nil {
+		return nil, fmt.Errorf("failed to write max transaction ID: %w", err)
+	}
+
 	// Prepend size
 	data := buf.Bytes()
 	result := make([]byte, 4+len(data))
@@ -116,22 +145,57 @@ func SerializeCheckpoint(cp *CheckpointRecord) ([]byte, error) {
 	return result, nil
 }
 
-// DeserializeCheckpoint deserializes a checkpoint record from bytes
+// ErrCorruptCheckpoint is returned for serialized data that is not a valid
+// checkpoint: data cut short, a count of entries larger than the data holds,
+// or an unknown format version.
+var ErrCorruptCheckpoint = errors.New("corrupt checkpoint")
+
+// txnEntrySize, pageEntrySize and legacyPageEntrySize are the sizes of the
+// serialized active transactions and dirty pages of a checkpoint.
+const (
+	txnEntrySize        = 32
+	pageEntrySize       = 24
+	legacyPageEntrySize = 16
+)
+
+// DeserializeCheckpoint deserializes a checkpoint record from bytes. Every
+// error it returns wraps ErrCorruptCheckpoint.
 func DeserializeCheckpoint(data []byte) (*CheckpointRecord, error) {
+	cp, err := deserializeCheckpoint(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptCheckpoint, err)
+	}
+	return cp, nil
+}
+
+func deserializeCheckpoint(data []byte) (*CheckpointRecord, error) {
 	if len(data) < 4 {
 		return nil, fmt.Errorf("checkpoint data too short")
 	}
 
 	// Read size
 	size := binary.BigEndian.Uint32(data[0:4])
+	if size < 4 {
+		return nil, fmt.Errorf("invalid checkpoint size %d", size)
+	}
 	if uint32(len(data)) < size {
 		return nil, fmt.Errorf("checkpoint data truncated: expected %d, got %d", size, len(data))
 	}
 
-	buf := bytes.NewReader(data[4:])
+	version := uint16(legacyCheckpointVersion)
+	body := data[4:size]
+	if len(body) >= 6 && string(body[:4]) == checkpointMagic {
+		version = binary.BigEndian.Uint16(body[4:6])
+		body = body[6:]
+	}
+	if version != CheckpointVersion && version != legacyCheckpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", version)
+	}
+
+	buf := bytes.NewReader(body)
 	cp := &CheckpointRecord{
 		ActiveTxns: make(map[int64]*TransactionLogInfo),
-		DirtyPages: make(map[primitives.HashCode]primitives.LSN),
+		DirtyPages: make(map[primitives.PageKey]primitives.LSN),
 	}
 
 	// Read header
@@ -151,6 +215,9 @@ func DeserializeCheckpoint(data []byte) (*CheckpointRecord, error) {
 	if err := binary.Read(buf, binary.BigEndian, &numTxns); err != nil {
 		return nil, fmt.Errorf("failed to read transaction count: %w", err)
 	}
+	if uint64(numTxns)*txnEntrySize > uint64(buf.Len()) {
+		return nil, fmt.Errorf("transaction count %d exceeds the %d bytes left", numTxns, buf.Len())
+	}
 
 	for i := uint32(0); i < numTxns; i++ {
 		var tid, firstLSN, lastLSN, undoNextLSN uint64
@@ -181,17 +248,49 @@ func DeserializeCheckpoint(data []byte) (*CheckpointRecord, error) {
 		return nil, fmt.Errorf("failed to read page count: %w", err)
 	}
 
+	entrySize := uint64(pageEntrySize)
+	if version == legacyCheckpointVersion {
+		cp.LegacyDirtyPages = make(map[primitives.HashCode]primitives.LSN)
+		entrySize = legacyPageEntrySize
+	}
+	if uint64(numPages)*entrySize > uint64(buf.Len()) {
+		return nil, fmt.Errorf("page count %d exceeds the %d bytes left", numPages, buf.Len())
+	}
+
 	for i := uint32(0); i < numPages; i++ {
-		var pageHash, lsn uint64
+		if version == legacyCheckpointVersion {
+			var pageHash, lsn uint64
+			if err := binary.Read(buf, binary.BigEndian, &pageHash); err != nil {
+				return nil, fmt.Errorf("failed to read page hash: %w", err)
+			}
+			if err := binary.Read(buf, binary.BigEndian, &lsn); err != nil {
+				return nil, fmt.Errorf("failed to read page LSN: %w", err)
+			}
+			cp.LegacyDirtyPages[primitives.HashCode(pageHash)] = primitives.LSN(lsn)
+			continue
+		}
 
-		if err := binary.Read(buf, binary.BigEndian, &pageHash); err != nil {
-			return nil, fmt.Errorf("failed to read page hash: %w", err)
+		var fileID, pageNo, lsn uint64
+		if err := binary.Read(buf, binary.BigEndian, &fileID); err != nil {
+			return nil, fmt.Errorf("failed to read page file ID: %w", err)
+		}
+		if err := binary.Read(buf, binary.BigEndian, &pageNo); err != nil {
+			return nil, fmt.Errorf("failed to read page number: %w", err)
 		}
 		if err := binary.Read(buf, binary.BigEndian, &lsn); err != nil {
 			return nil, fmt.Errorf("failed to read page LSN: %w", err)
 		}
+		key := primitives.PageKey{FileID: primitives.FileID(fileID), PageNo: primitives.PageNumber(pageNo)}
+		cp.DirtyPages[key] = primitives.LSN(lsn)
+	}
 
-		cp.DirtyPages[primitives.HashCode(pageHash)] = primitives.LSN(lsn)
+	// Checkpoints written before MaxTID was added end here
+	if buf.Len() >= 8 {
+		var maxTID uint64
+		if err := binary.Read(buf, binary.BigEndian, &maxTID); err != nil {
+			return nil, fmt.Errorf("failed to read max transaction ID: %w", err)
+		}
+		cp.MaxTID = int64(maxTID)
 	}
 
 	return cp, nil
@@ -199,14 +298,32 @@ func DeserializeCheckpoint(data []byte) (*CheckpointRecord, error) {
 
 // Size returns the serialized size of the checkpoint record
 func (cp *CheckpointRecord) Size() int {
-	// Size field (4) + LSN (8) + Timestamp (8) + NumTxns (4) + NumPages (4)
-	baseSize := 4 + 8 + 8 + 4 + 4
+	// Size field (4) + Magic (4) + Version (2) + LSN (8) + Timestamp (8) + NumTxns (4) + NumPages (4) + MaxTID (8)
+	baseSize := 4 + 4 + 2 + 8 + 8 + 4 + 4 + 8
 
 	// Each transaction: TID (8) + FirstLSN (8) + LastLSN (8) + UndoNextLSN (8) = 32 bytes
 	txnSize := len(cp.ActiveTxns) * 32
 
-	// Each page: PageHash (8) + LSN (8) = 16 bytes
-	pageSize := len(cp.DirtyPages) * 16
+	// Each page: FileID (8) + PageNo (8) + LSN (8) = 24 bytes
+	pageSize := len(cp.DirtyPages) * 24
 
 	return baseSize + txnSize + pageSize
 }
+
+// MinDirtyLSN returns the earliest LSN that dirtied a page still dirty at
+// checkpoint time, including the pages of a legacy checkpoint, and false if
+// no page was dirty. Redo must start no later than this LSN.
+func (cp *CheckpointRecord) MinDirtyLSN() (primitives.LSN, bool) {
+	minLSN, found := primitives.LSN(0), false
+	for _, lsn := range cp.DirtyPages {
+		if !found || lsn < minLSN {
+			minLSN, found = lsn, true
+		}
+	}
+	for _, lsn := range cp.LegacyDirtyPages {
+		if !found || lsn < minLSN {
+			minLSN, found = lsn, true
+		}
+	}
+	return minLSN, found
+}
@@ -0,0 +1,185 @@
+package record
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Compression is the algorithm the page images of a record are compressed
+// with in the log.
+type Compression uint8
+
+const (
+	// CompressionNone stores page images as they are.
+	CompressionNone Compression = iota
+
+	// CompressionDeflate compresses page images with DEFLATE at its fastest
+	// level, which shrinks the mostly empty or repetitive pages of a table
+	// several times over.
+	CompressionDeflate
+)
+
+const (
+	// compressedImageFlag is set in the length prefix of a compressed image,
+	// which is followed by [Compression:1][RawLength:4] and the compressed
+	// bytes. Images are far below 2 GB, so the bit is free in plain ones.
+	compressedImageFlag = 1 << 31
+
+	compressedImageHeaderSize = 5 // Compression (byte) + raw length (uint32)
+
+	// minCompressedImageSize is the smallest image worth compressing.
+	minCompressedImageSize = 64
+
+	// maxDeflateRatio bounds how many times larger than its compressed form
+	// a deflate stream can expand, so a corrupt raw length is caught before
+	// a buffer is allocated for it.
+	maxDeflateRatio = 1032
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionDeflate:
+		return "deflate"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseCompression parses a compression name as returned by
+// Compression.String, ignoring case.
+func ParseCompression(s string) (Compression, error) {
+	switch strings.ToLower(s) {
+	case "none":
+		return CompressionNone, nil
+	case "deflate":
+		return CompressionDeflate, nil
+	default:
+		return 0, fmt.Errorf("unknown compression %q (expected none or deflate)", s)
+	}
+}
+
+// compressedImages holds the compressed forms of a record's images that
+// Serialize writes in their place.
+type compressedImages struct {
+	compression   Compression
+	before, after []byte // nil for an image that does not shrink
+}
+
+// CompressImages makes the record serialize its BeforeImage and AfterImage
+// compressed with c. An image that compression does not make smaller is
+// written as it is. The images themselves are left untouched, and
+// DeserializeLogRecord restores them, so compression is invisible to
+// everything but the size of the log.
+func (l *LogRecord) CompressImages(c Compression) {
+	if c == CompressionNone {
+		l.compressed = nil
+		return
+	}
+
+	packed := &compressedImages{compression: c}
+	packed.before = compressImage(c, l.BeforeImage)
+	packed.after = compressImage(c, l.AfterImage)
+	if packed.before == nil && packed.after == nil {
+		packed = nil
+	}
+	l.compressed = packed
+}
+
+// ImageBytesSaved returns how many bytes compressing the images of the
+// record saves in the log, 0 if CompressImages was not called.
+func (l *LogRecord) ImageBytesSaved() int {
+	before, after, _ := l.packedImages()
+	return imageSize(l.BeforeImage, nil) - imageSize(l.BeforeImage, before) +
+		imageSize(l.AfterImage, nil) - imageSize(l.AfterImage, after)
+}
+
+var deflateWriters = sync.Pool{
+	New: func() any {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	},
+}
+
+// compressImage returns image compressed with c, or nil if that does not
+// make it smaller.
+func compressImage(c Compression, image []byte) []byte {
+	if c != CompressionDeflate || len(image) < minCompressedImageSize {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	w := deflateWriters.Get().(*flate.Writer)
+	defer deflateWriters.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(image); err != nil {
+		return nil
+	}
+	if err := w.Close(); err != nil {
+		return nil
+	}
+	if buf.Len()+compressedImageHeaderSize >= len(image) {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// decompressImage restores an image of rawLength bytes compressed with c.
+func decompressImage(c Compression, data []byte, rawLength uint32) ([]byte, error) {
+	if c != CompressionDeflate {
+		return nil, fmt.Errorf("unknown image compression %d", c)
+	}
+	if uint64(rawLength) > uint64(len(data))*maxDeflateRatio {
+		return nil, fmt.Errorf("compressed image of %d bytes cannot expand to %d bytes", len(data), rawLength)
+	}
+
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+
+	image := make([]byte, rawLength)
+	if _, err := io.ReadFull(r, image); err != nil {
+		return nil, fmt.Errorf("failed to decompress image: %w", err)
+	}
+	if n, _ := r.Read(make([]byte, 1)); n != 0 {
+		return nil, fmt.Errorf("compressed image is longer than %d bytes", rawLength)
+	}
+	return image, nil
+}
+
+// imageSize returns the serialized size of an image, compressed to packed
+// unless packed is nil.
+func imageSize(image, packed []byte) int {
+	if packed != nil {
+		return ImageLengthSize + compressedImageHeaderSize + len(packed)
+	}
+	return ImageLengthSize + len(image)
+}
+
+// appendPackedImage serializes image as appendImage does, or in the
+// compressed form packed unless it is nil:
+//
+//	[length|compressedImageFlag:4][Compression:1][RawLength:4][packed]
+func appendPackedImage(dst, image, packed []byte, c Compression) []byte {
+	if packed == nil {
+		return appendImage(dst, image)
+	}
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(packed))|compressedImageFlag)
+	dst = append(dst, byte(c))
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(image)))
+	return append(dst, packed...)
+}
+
+// packedImages returns the compressed forms of the images, nil for those
+// serialized as they are.
+func (l *LogRecord) packedImages() (before, after []byte, c Compression) {
+	if l.compressed == nil {
+		return nil, nil, CompressionNone
+	}
+	return l.compressed.before, l.compressed.after, l.compressed.compression
+}
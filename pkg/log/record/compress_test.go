@@ -0,0 +1,83 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"storemy/pkg/primitives"
+	"testing"
+)
+
+func TestCompressImages_RoundTrip(t *testing.T) {
+	before := make([]byte, 4096)
+	copy(before, "a mostly empty page")
+	after := bytes.Repeat([]byte("row "), 1024)
+
+	rec := NewLogRecord(UpdateRecord, primitives.NewTransactionID(), &MockPageID{tableID: 1, pageNo: 2}, before, after, 7)
+	plain := rec.SerializedSize()
+	rec.CompressImages(CompressionDeflate)
+
+	data, err := rec.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if len(data) != rec.SerializedSize() {
+		t.Errorf("serialized %d bytes, SerializedSize says %d", len(data), rec.SerializedSize())
+	}
+	if saved := plain - len(data); saved != rec.ImageBytesSaved() || saved < 4096 {
+		t.Errorf("compression saved %d bytes (ImageBytesSaved %d), expected most of both images", saved, rec.ImageBytesSaved())
+	}
+
+	decoded, err := DeserializeLogRecord(data)
+	if err != nil {
+		t.Fatalf("DeserializeLogRecord failed: %v", err)
+	}
+	if !bytes.Equal(decoded.BeforeImage, before) || !bytes.Equal(decoded.AfterImage, after) {
+		t.Error("decompressed images differ from the originals")
+	}
+}
+
+func TestCompressImages_KeepsIncompressibleImages(t *testing.T) {
+	noise := make([]byte, 512)
+	rand.New(rand.NewSource(1)).Read(noise)
+
+	rec := NewLogRecord(UpdateRecord, primitives.NewTransactionID(), &MockPageID{tableID: 1, pageNo: 2}, noise, []byte("short"), 7)
+	plain, err := rec.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	rec.CompressImages(CompressionDeflate)
+	data, err := rec.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !bytes.Equal(data, plain) || rec.ImageBytesSaved() != 0 {
+		t.Error("expected images that do not shrink to be written as they are")
+	}
+}
+
+func TestDeserializeLogRecord_UnknownImageCompression(t *testing.T) {
+	rec := NewLogRecord(UpdateRecord, primitives.NewTransactionID(), &MockPageID{tableID: 1, pageNo: 2},
+		nil, make([]byte, 1024), 7)
+	rec.compressed = &compressedImages{compression: Compression(9), after: compressImage(CompressionDeflate, rec.AfterImage)}
+
+	data, err := rec.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if _, err := DeserializeLogRecord(data); !errors.Is(err, ErrCorruptRecord) {
+		t.Errorf("expected ErrCorruptRecord for an unknown compression, got %v", err)
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range []Compression{CompressionNone, CompressionDeflate} {
+		got, err := ParseCompression(c.String())
+		if err != nil || got != c {
+			t.Errorf("ParseCompression(%q) = %s, %v", c.String(), got, err)
+		}
+	}
+	if _, err := ParseCompression("snappy"); err == nil {
+		t.Error("expected an error for an unsupported compression")
+	}
+}
@@ -0,0 +1,43 @@
+package record
+
+import "sync"
+
+// maxPooledEncoderSize caps the buffer an Encoder keeps when it goes back to
+// the pool, so that one huge record does not pin its buffer forever.
+const maxPooledEncoderSize = 1 << 20
+
+var encoderPool = sync.Pool{
+	New: func() any { return &Encoder{} },
+}
+
+// Encoder serializes log records into a buffer it reuses from one record to
+// the next. Encoders are pooled: get one with NewEncoder and hand it back
+// with Release once the encoded bytes are no longer needed.
+//
+// An Encoder is not safe for concurrent use.
+type Encoder struct {
+	buf []byte
+}
+
+// NewEncoder returns an Encoder from the pool.
+func NewEncoder() *Encoder {
+	return encoderPool.Get().(*Encoder)
+}
+
+// Encode serializes rec. The returned slice is owned by the encoder and is
+// valid only until the next call to Encode or Release.
+func (e *Encoder) Encode(rec *LogRecord) []byte {
+	if size := rec.SerializedSize(); cap(e.buf) < size {
+		e.buf = make([]byte, 0, size)
+	}
+	e.buf = rec.AppendTo(e.buf[:0])
+	return e.buf
+}
+
+// Release returns the encoder to the pool. It must not be used afterwards.
+func (e *Encoder) Release() {
+	if cap(e.buf) > maxPooledEncoderSize {
+		e.buf = nil
+	}
+	encoderPool.Put(e)
+}
@@ -0,0 +1,129 @@
+package record
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+)
+
+// FileOpKind is the file system change announced by a FileOpRecord.
+type FileOpKind uint8
+
+const (
+	FileOpDelete FileOpKind = iota + 1
+	FileOpRename
+)
+
+// FileOpKindSize is the size of the kind byte of a FileOpRecord.
+const FileOpKindSize = 1
+
+func (k FileOpKind) String() string {
+	switch k {
+	case FileOpDelete:
+		return "DELETE"
+	case FileOpRename:
+		return "RENAME"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// FileOperation is a deletion or rename of a data file made on behalf of a
+// transaction. Such changes cannot be undone from page images, so they are
+// deferred: the transaction logs a FileOpRecord, performs the operation only
+// once its COMMIT is durable, and then logs a FileOpDoneRecord. Recovery
+// finishes the operations of committed transactions that have no DONE
+// record, and drops those of transactions that did not commit.
+type FileOperation struct {
+	Kind    FileOpKind
+	Path    primitives.Filepath
+	NewPath primitives.Filepath // Target of a rename, empty for a delete
+}
+
+func (op FileOperation) String() string {
+	if op.Kind == FileOpRename {
+		return fmt.Sprintf("%s %s -> %s", op.Kind, op.Path, op.NewPath)
+	}
+	return fmt.Sprintf("%s %s", op.Kind, op.Path)
+}
+
+// Apply performs op on fsys. It is idempotent, so that recovery can repeat
+// an operation that may or may not have happened before a crash: deleting a
+// missing file succeeds, and so does renaming a file that is already at its
+// new path.
+func (op FileOperation) Apply(fsys vfs.FS) error {
+	switch op.Kind {
+	case FileOpDelete:
+		if err := fsys.Remove(string(op.Path)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete %s: %w", op.Path, err)
+		}
+		return nil
+
+	case FileOpRename:
+		if _, err := fsys.Stat(string(op.Path)); os.IsNotExist(err) {
+			if _, err := fsys.Stat(string(op.NewPath)); err == nil {
+				return nil
+			}
+			return fmt.Errorf("failed to rename %s: neither it nor %s exists", op.Path, op.NewPath)
+		}
+		if err := fsys.Rename(string(op.Path), string(op.NewPath)); err != nil {
+			return fmt.Errorf("failed to rename %s to %s: %w", op.Path, op.NewPath, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown file operation %d", op.Kind)
+	}
+}
+
+// NewFileOpRecord returns the record announcing op for tid.
+func NewFileOpRecord(tid *primitives.TransactionID, op FileOperation, prevLSN LSN) *LogRecord {
+	rec := NewLogRecord(FileOpRecord, tid, nil, nil, nil, prevLSN)
+	rec.FileOp = op
+	return rec
+}
+
+// NewFileOpDoneRecord returns the record saying that the operation announced
+// at intentLSN by tid has been performed or dropped.
+func NewFileOpDoneRecord(tid *primitives.TransactionID, intentLSN LSN) *LogRecord {
+	return NewLogRecord(FileOpDoneRecord, tid, nil, nil, nil, intentLSN)
+}
+
+func (op FileOperation) serializedSize() int {
+	return FileOpKindSize + ImageLengthSize + len(op.Path) + ImageLengthSize + len(op.NewPath)
+}
+
+// appendFileOp serializes op as [Kind:1][PathLen:4][Path][NewPathLen:4][NewPath].
+func appendFileOp(dst []byte, op FileOperation) []byte {
+	dst = append(dst, byte(op.Kind))
+	dst = appendImage(dst, []byte(op.Path))
+	return appendImage(dst, []byte(op.NewPath))
+}
+
+// deserializeFileOp deserializes the payload of a FileOpRecord.
+func deserializeFileOp(buf *bytes.Reader, record *LogRecord) error {
+	kind, err := buf.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read file operation kind: %w", err)
+	}
+	record.FileOp.Kind = FileOpKind(kind)
+	if record.FileOp.Kind != FileOpDelete && record.FileOp.Kind != FileOpRename {
+		return fmt.Errorf("unknown file operation kind %d", kind)
+	}
+
+	path, err := deserializeImage(buf)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize path: %w", err)
+	}
+	record.FileOp.Path = primitives.Filepath(path)
+
+	newPath, err := deserializeImage(buf)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize new path: %w", err)
+	}
+	record.FileOp.NewPath = primitives.Filepath(newPath)
+
+	return nil
+}
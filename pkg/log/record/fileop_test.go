@@ -0,0 +1,82 @@
+package record
+
+import (
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"testing"
+)
+
+func TestFileOpRecord_RoundTrip(t *testing.T) {
+	tid := primitives.NewTransactionIDFromValue(7)
+	ops := []FileOperation{
+		{Kind: FileOpDelete, Path: "/data/users.dat"},
+		{Kind: FileOpRename, Path: "/data/old.dat", NewPath: "/data/new.dat"},
+	}
+
+	for _, op := range ops {
+		rec := NewFileOpRecord(tid, op, 42)
+		data, err := SerializeLogRecord(rec)
+		if err != nil {
+			t.Fatalf("SerializeLogRecord failed: %v", err)
+		}
+		if len(data) != rec.SerializedSize() {
+			t.Errorf("%s: serialized %d bytes, SerializedSize says %d", op, len(data), rec.SerializedSize())
+		}
+
+		got, err := DeserializeLogRecord(data)
+		if err != nil {
+			t.Fatalf("DeserializeLogRecord failed: %v", err)
+		}
+		if got.Type != FileOpRecord || got.PrevLSN != 42 || got.TID.ID() != 7 {
+			t.Errorf("%s: header mismatch: type %v, PrevLSN %d, TID %d", op, got.Type, got.PrevLSN, got.TID.ID())
+		}
+		if got.FileOp != op {
+			t.Errorf("file operation mismatch: got %s, want %s", got.FileOp, op)
+		}
+	}
+
+	done, err := SerializeLogRecord(NewFileOpDoneRecord(tid, 42))
+	if err != nil {
+		t.Fatalf("SerializeLogRecord failed: %v", err)
+	}
+	got, err := DeserializeLogRecord(done)
+	if err != nil {
+		t.Fatalf("DeserializeLogRecord failed: %v", err)
+	}
+	if got.Type != FileOpDoneRecord || got.PrevLSN != 42 {
+		t.Errorf("expected a DONE record for LSN 42, got type %v with PrevLSN %d", got.Type, got.PrevLSN)
+	}
+}
+
+func TestFileOperation_ApplyIsIdempotent(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	if err := fsys.WriteFile("/a.dat", []byte("a"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := fsys.WriteFile("/b.dat", []byte("b"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	del := FileOperation{Kind: FileOpDelete, Path: "/a.dat"}
+	rename := FileOperation{Kind: FileOpRename, Path: "/b.dat", NewPath: "/c.dat"}
+	for i := 0; i < 2; i++ {
+		if err := del.Apply(fsys); err != nil {
+			t.Errorf("delete, pass %d: %v", i+1, err)
+		}
+		if err := rename.Apply(fsys); err != nil {
+			t.Errorf("rename, pass %d: %v", i+1, err)
+		}
+	}
+
+	if _, err := fsys.Stat("/a.dat"); err == nil {
+		t.Error("expected /a.dat to be deleted")
+	}
+	if data, err := fsys.ReadFile("/c.dat"); err != nil || string(data) != "b" {
+		t.Errorf("expected /b.dat to be renamed to /c.dat, got %q (err %v)", data, err)
+	}
+
+	missing := FileOperation{Kind: FileOpRename, Path: "/x.dat", NewPath: "/y.dat"}
+	if err := missing.Apply(fsys); err == nil {
+		t.Error("expected renaming a file that exists under neither name to fail")
+	}
+}
@@ -1,7 +1,6 @@
 package record
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"storemy/pkg/primitives"
@@ -26,8 +25,54 @@ const (
 	CheckpointEnd
 
 	CLRRecord
+
+	FileOpRecord
+	FileOpDoneRecord
+
+	BulkLoadRecord
+	BulkLoadBarrierRecord
 )
 
+// EncryptedRecord is the type byte of a record written by an encrypted WAL.
+// The record's real type and contents follow it, sealed; the WAL opens them
+// before the record is deserialized.
+const EncryptedRecord LogRecordType = 0xFF
+
+func (t LogRecordType) String() string {
+	switch t {
+	case BeginRecord:
+		return "BEGIN"
+	case CommitRecord:
+		return "COMMIT"
+	case AbortRecord:
+		return "ABORT"
+	case UpdateRecord:
+		return "UPDATE"
+	case InsertRecord:
+		return "INSERT"
+	case DeleteRecord:
+		return "DELETE"
+	case CheckpointBegin:
+		return "CHECKPOINT_BEGIN"
+	case CheckpointEnd:
+		return "CHECKPOINT_END"
+	case CLRRecord:
+		return "CLR"
+	case FileOpRecord:
+		return "FILE_OP"
+	case FileOpDoneRecord:
+		return "FILE_OP_DONE"
+	case BulkLoadRecord:
+		return "BULK_LOAD"
+	case BulkLoadBarrierRecord:
+		return "BULK_LOAD_BARRIER"
+	case EncryptedRecord:
+		return "ENCRYPTED"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", uint8(t))
+	}
+}
+
 // LogRecord represents a single entry in the WAL
 type LogRecord struct {
 	LSN     LSN // Unique identifier for this record
@@ -39,13 +84,21 @@ type LogRecord struct {
 	BeforeImage []byte            // Page state before modification (for UNDO)
 	AfterImage  []byte            // Page state after modification (for REDO)
 
-	UndoNextLSN LSN // Next record to undo (for CLR records)
+	UndoNextLSN LSN           // Next record to undo (for CLR records)
+	FileOp      FileOperation // File change announced by a FileOpRecord
+	BulkLoad    BulkLoad      // Unlogged pages of a bulk load record
 	Timestamp   time.Time
+
+	compressed *compressedImages // Images as serialized, see CompressImages
 }
 
 // TransactionLogInfo tracks logging information for a transaction
 type TransactionLogInfo struct {
 	FirstLSN, LastLSN, UndoNextLSN LSN
+
+	// Records and bytes the transaction has logged, including its BEGIN.
+	// They are not saved in checkpoints.
+	Records, Bytes int64
 }
 
 func NewLogRecord(logType LogRecordType, tid *primitives.TransactionID, pageId primitives.PageID, beforeImage, afterImage []byte, prevLSN LSN) *LogRecord {
@@ -65,114 +118,105 @@ func NewLogRecord(logType LogRecordType, tid *primitives.TransactionID, pageId p
 //
 // Binary format structure:
 //
-//	[Size:4][Type:1][TID:8][PrevLSN:8][Timestamp:8][Type-specific data]
+//	[Size:4][Type:1][TID:8][PrevLSN:8][Timestamp:8][Type-specific data][CRC32C:4]
 //
 // Type-specific data varies based on record type:
 //   - UpdateRecord/InsertRecord/DeleteRecord: PageID + BeforeImage + AfterImage
 //   - CLRRecord: PageID + UndoNextLSN + AfterImage
+//     (images are compressed after CompressImages, see appendPackedImage)
 //   - BeginRecord/CommitRecord/AbortRecord: No additional data
 //   - CheckpointBegin/CheckpointEnd: No additional data (checkpoint records handled separately)
+//   - FileOpRecord: Kind + Path + NewPath
+//   - FileOpDoneRecord: No additional data, PrevLSN is the FileOpRecord it completes
+//   - BulkLoadRecord/BulkLoadBarrierRecord: Path + StartPage + EndPage
 //
 // The Size field at the start includes the entire record length for efficient log scanning.
+// The CRC32C checksum at the end covers every byte before it, Size included,
+// so that a torn or bit-rotted record is detected instead of replayed.
 // PrevLSN creates a linked list of records per transaction, crucial for ARIES rollback.
 //
 // Returns serialized byte slice, or error if serialization fails.
 func (l *LogRecord) Serialize() ([]byte, error) {
-	var buf bytes.Buffer
+	return l.AppendTo(make([]byte, 0, l.SerializedSize())), nil
+}
 
+// SerializedSize returns the number of bytes Serialize produces for l,
+// including the Size field.
+func (l *LogRecord) SerializedSize() int {
+	size := RecordSize + TypeSize + TIDSize + PrevLSNSize + TimestampSize + ChecksumSize
+
+	before, after, _ := l.packedImages()
+	switch l.Type {
+	case UpdateRecord, InsertRecord, DeleteRecord:
+		size += l.pageIDSize() + imageSize(l.BeforeImage, before) + imageSize(l.AfterImage, after)
+	case CLRRecord:
+		size += l.pageIDSize() + UndoNextLSNSize + imageSize(l.AfterImage, after)
+	case FileOpRecord:
+		size += l.FileOp.serializedSize()
+	case BulkLoadRecord, BulkLoadBarrierRecord:
+		size += l.BulkLoad.serializedSize()
+	}
+	return size
+}
+
+// AppendTo appends the serialized form of l to dst and returns the extended
+// slice. When dst has SerializedSize() bytes of spare capacity, the record
+// is encoded in place without allocating, which lets the WAL writer
+// serialize records straight into its buffer.
+func (l *LogRecord) AppendTo(dst []byte) []byte {
 	tidVal := uint64(0)
 	if l.TID != nil {
 		tidVal = uint64(l.TID.ID())
 	}
 
-	writes := []any{
-		byte(l.Type),
-		tidVal,
-		uint64(l.PrevLSN),
-		uint64(l.Timestamp.Unix()),
-	}
-
-	for _, v := range writes {
-		if err := binary.Write(&buf, binary.BigEndian, v); err != nil {
-			return nil, fmt.Errorf("failed to write base field: %w", err)
-		}
-	}
+	start := len(dst)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(l.SerializedSize()))
+	dst = append(dst, byte(l.Type))
+	dst = binary.BigEndian.AppendUint64(dst, tidVal)
+	dst = binary.BigEndian.AppendUint64(dst, uint64(l.PrevLSN))
+	dst = binary.BigEndian.AppendUint64(dst, uint64(l.Timestamp.Unix()))
 
+	before, after, compression := l.packedImages()
 	switch l.Type {
 	case UpdateRecord, InsertRecord, DeleteRecord:
-		if err := l.serializeDataModification(&buf); err != nil {
-			return nil, err
-		}
+		dst = l.appendPageID(dst)
+		dst = appendPackedImage(dst, l.BeforeImage, before, compression)
+		dst = appendPackedImage(dst, l.AfterImage, after, compression)
 	case CLRRecord:
-		if err := l.serializeCLR(&buf); err != nil {
-			return nil, err
-		}
+		dst = l.appendPageID(dst)
+		dst = binary.BigEndian.AppendUint64(dst, uint64(l.UndoNextLSN))
+		dst = appendPackedImage(dst, l.AfterImage, after, compression)
+	case FileOpRecord:
+		dst = appendFileOp(dst, l.FileOp)
+	case BulkLoadRecord, BulkLoadBarrierRecord:
+		dst = appendBulkLoad(dst, l.BulkLoad)
 	}
-
-	data := buf.Bytes()
-	result := make([]byte, RecordSize+len(data))
-	binary.BigEndian.PutUint32(result, uint32(len(result)))
-	copy(result[RecordSize:], data)
-
-	return result, nil
+	return binary.BigEndian.AppendUint32(dst, checksum(dst[start:]))
 }
 
-// serializeDataModification serializes data modification records (Insert, Update, Delete).
-// These records contain a PageID, BeforeImage (for updates/deletes), and AfterImage.
-func (l *LogRecord) serializeDataModification(buf *bytes.Buffer) error {
-	if err := l.serializePageID(buf); err != nil {
-		return err
-	}
-	if err := l.serializeImage(buf, l.BeforeImage); err != nil {
-		return err
-	}
-	return l.serializeImage(buf, l.AfterImage)
-}
-
-// serializeCLR serializes Compensation Log Records (CLR).
-// CLRs are used during transaction rollback and contain PageID, UndoNextLSN, and AfterImage.
-func (l *LogRecord) serializeCLR(buf *bytes.Buffer) error {
-	if err := l.serializePageID(buf); err != nil {
-		return err
-	}
-	if err := binary.Write(buf, binary.BigEndian, uint64(l.UndoNextLSN)); err != nil {
-		return fmt.Errorf("failed to write UndoNextLSN: %w", err)
+// pageIDSize returns the size of the serialized PageID, which is omitted
+// when the record has none.
+func (l *LogRecord) pageIDSize() int {
+	if l.PageID == nil {
+		return 0
 	}
-	return l.serializeImage(buf, l.AfterImage)
+	return PageIDSize
 }
 
-// serializePageID serializes a PageID as two uint32 values (FileID and PageNo).
+// appendPageID serializes a PageID as two uint32 values (FileID and PageNo).
 // This must match the format expected by deserializePageID in serialize.go.
-func (l *LogRecord) serializePageID(buf *bytes.Buffer) error {
+func (l *LogRecord) appendPageID(dst []byte) []byte {
 	if l.PageID == nil {
-		return nil
-	}
-	if err := binary.Write(buf, binary.BigEndian, uint32(l.PageID.FileID())); err != nil {
-		return fmt.Errorf("failed to write PageID tableID: %w", err)
+		return dst
 	}
-	if err := binary.Write(buf, binary.BigEndian, uint32(l.PageID.PageNo())); err != nil {
-		return fmt.Errorf("failed to write PageID pageNo: %w", err)
-	}
-	return nil
+	dst = binary.BigEndian.AppendUint32(dst, uint32(l.PageID.FileID()))
+	return binary.BigEndian.AppendUint32(dst, uint32(l.PageID.PageNo()))
 }
 
-// serializeImage serializes a byte slice image (BeforeImage or AfterImage).
+// appendImage serializes a byte slice image (BeforeImage or AfterImage).
 // The format is: [length:4][data:length] where length is uint32.
 // If the image is nil, only a zero length is written.
-func (l *LogRecord) serializeImage(buf *bytes.Buffer, image []byte) error {
-	length := uint32(0)
-	if image != nil {
-		length = uint32(len(image))
-	}
-
-	if err := binary.Write(buf, binary.BigEndian, length); err != nil {
-		return fmt.Errorf("failed to write image length: %w", err)
-	}
-
-	if image != nil {
-		if _, err := buf.Write(image); err != nil {
-			return fmt.Errorf("failed to write image data: %w", err)
-		}
-	}
-	return nil
+func appendImage(dst []byte, image []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(image)))
+	return append(dst, image...)
 }
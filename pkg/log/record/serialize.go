@@ -3,7 +3,9 @@ package record
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
@@ -16,8 +18,27 @@ const (
 	TIDSize       = 8 // Transaction ID field (uint64)
 	PrevLSNSize   = 8 // Previous LSN field (uint64)
 	TimestampSize = 8 // Timestamp field (uint64, Unix timestamp)
+
+	PageIDSize      = 8 // PageID of data and CLR records (uint32 FileID + uint32 PageNo)
+	UndoNextLSNSize = 8 // UndoNextLSN of CLR records (uint64)
+	ImageLengthSize = 4 // Length prefix of a before or after image (uint32)
+
+	ChecksumSize = 4 // CRC32C of every byte of the record before it (uint32)
 )
 
+// ErrCorruptRecord is returned for serialized data that is not a valid log
+// record: a checksum that does not match, a record cut short, or fields
+// that cannot be decoded.
+var ErrCorruptRecord = errors.New("corrupt log record")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksum computes the CRC32C stored at the end of a record over data, the
+// record up to its checksum.
+func checksum(data []byte) uint32 {
+	return crc32.Checksum(data, crcTable)
+}
+
 // SerializeLogRecord converts a LogRecord struct into a compact binary representation.
 // The serialization format uses big-endian byte ordering for cross-platform compatibility.
 func SerializeLogRecord(record *LogRecord) ([]byte, error) {
@@ -25,12 +46,21 @@ func SerializeLogRecord(record *LogRecord) ([]byte, error) {
 }
 
 // DeserializeLogRecord converts a binary representation back into a LogRecord struct.
-// It validates the input data and ensures proper error handling for corrupted or invalid records.
+// It verifies the record's checksum before decoding it, and every error it
+// returns for corrupted or invalid data wraps ErrCorruptRecord.
 //
 // Binary format structure (must match SerializeLogRecord):
 //
-//	[Size:4][Type:1][TID:8][PrevLSN:8][Timestamp:8][Type-specific data]
+//	[Size:4][Type:1][TID:8][PrevLSN:8][Timestamp:8][Type-specific data][CRC32C:4]
 func DeserializeLogRecord(data []byte) (*LogRecord, error) {
+	record, err := deserializeLogRecord(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptRecord, err)
+	}
+	return record, nil
+}
+
+func deserializeLogRecord(data []byte) (*LogRecord, error) {
 	if len(data) < RecordSize {
 		return nil, fmt.Errorf("invalid record: data too short (%d bytes, minimum %d required)", len(data), RecordSize)
 	}
@@ -40,12 +70,17 @@ func DeserializeLogRecord(data []byte) (*LogRecord, error) {
 		return nil, fmt.Errorf("size mismatch: header indicates %d bytes, actual %d bytes", recordSize, len(data))
 	}
 
-	minSize := RecordSize + TypeSize + TIDSize + PrevLSNSize + TimestampSize
+	minSize := RecordSize + TypeSize + TIDSize + PrevLSNSize + TimestampSize + ChecksumSize
 	if len(data) < minSize {
 		return nil, fmt.Errorf("invalid record: data too short (%d bytes, minimum %d required)", len(data), minSize)
 	}
 
-	buf := bytes.NewReader(data[RecordSize:])
+	end := len(data) - ChecksumSize
+	if stored, computed := binary.BigEndian.Uint32(data[end:]), checksum(data[:end]); stored != computed {
+		return nil, fmt.Errorf("checksum mismatch: stored %08x, computed %08x", stored, computed)
+	}
+
+	buf := bytes.NewReader(data[RecordSize:end])
 	record := &LogRecord{}
 
 	var recordType byte
@@ -83,7 +118,15 @@ func DeserializeLogRecord(data []byte) (*LogRecord, error) {
 		if err := deserializeCLR(buf, record); err != nil {
 			return nil, fmt.Errorf("failed to deserialize CLR record: %w", err)
 		}
-	case BeginRecord, CommitRecord, AbortRecord, CheckpointBegin, CheckpointEnd:
+	case FileOpRecord:
+		if err := deserializeFileOp(buf, record); err != nil {
+			return nil, fmt.Errorf("failed to deserialize file operation record: %w", err)
+		}
+	case BulkLoadRecord, BulkLoadBarrierRecord:
+		if err := deserializeBulkLoad(buf, record); err != nil {
+			return nil, fmt.Errorf("failed to deserialize bulk load record: %w", err)
+		}
+	case BeginRecord, CommitRecord, AbortRecord, CheckpointBegin, CheckpointEnd, FileOpDoneRecord:
 	default:
 		return nil, fmt.Errorf("unknown record type: %d", record.Type)
 	}
@@ -160,20 +203,38 @@ func deserializePageID(buf *bytes.Reader) (primitives.PageID, error) {
 }
 
 // deserializeImage deserializes a byte slice image (BeforeImage or AfterImage).
-// The format is: [length:4][data:length] where length is uint32.
+// The format is: [length:4][data:length] where length is uint32, or the
+// compressed form written by appendPackedImage, which it decompresses.
 func deserializeImage(buf *bytes.Reader) ([]byte, error) {
 	var length uint32
 	if err := binary.Read(buf, binary.BigEndian, &length); err != nil {
 		return nil, fmt.Errorf("failed to read image length: %w", err)
 	}
 
+	compressed := length&compressedImageFlag != 0
+	length &^= compressedImageFlag
+	var compression Compression
+	var rawLength uint32
+	if compressed {
+		if err := binary.Read(buf, binary.BigEndian, &compression); err != nil {
+			return nil, fmt.Errorf("failed to read image compression: %w", err)
+		}
+		if err := binary.Read(buf, binary.BigEndian, &rawLength); err != nil {
+			return nil, fmt.Errorf("failed to read image raw length: %w", err)
+		}
+	}
+
 	if length == 0 {
 		return nil, nil
 	}
 
 	const maxImageSize = 100 * 1024 * 1024 // 100MB limit
-	if length > maxImageSize {
-		return nil, fmt.Errorf("image size too large: %d bytes (max %d)", length, maxImageSize)
+	if length > maxImageSize || rawLength > maxImageSize {
+		return nil, fmt.Errorf("image size too large: %d bytes (max %d)", max(length, rawLength), maxImageSize)
+	}
+	// Checked before allocating, so a torn length cannot demand a large buffer
+	if int64(length) > int64(buf.Len()) {
+		return nil, fmt.Errorf("incomplete image data: expected %d bytes, %d left in the record", length, buf.Len())
 	}
 
 	image := make([]byte, length)
@@ -185,5 +246,8 @@ func deserializeImage(buf *bytes.Reader) ([]byte, error) {
 		return nil, fmt.Errorf("incomplete image data: expected %d bytes, got %d", length, n)
 	}
 
+	if compressed {
+		return decompressImage(compression, image, rawLength)
+	}
 	return image, nil
 }
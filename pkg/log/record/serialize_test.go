@@ -3,6 +3,7 @@ package record
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"storemy/pkg/primitives"
 	"testing"
@@ -339,13 +340,9 @@ func TestSerializeLogRecord_AbortRecord(t *testing.T) {
 }
 
 func TestSerializeImage_NonNilImage(t *testing.T) {
-	var buf bytes.Buffer
 	image := []byte("test image")
-	l := LogRecord{}
 
-	l.serializeImage(&buf, image)
-
-	data := buf.Bytes()
+	data := appendImage(nil, image)
 
 	// First 4 bytes should be the length
 	length := binary.BigEndian.Uint32(data[0:4])
@@ -360,12 +357,7 @@ func TestSerializeImage_NonNilImage(t *testing.T) {
 }
 
 func TestSerializeImage_NilImage(t *testing.T) {
-	var buf bytes.Buffer
-
-	l := LogRecord{}
-	l.serializeImage(&buf, nil)
-
-	data := buf.Bytes()
+	data := appendImage(nil, nil)
 
 	// Should write 4 bytes with value 0
 	if len(data) != 4 {
@@ -379,20 +371,21 @@ func TestSerializeImage_NilImage(t *testing.T) {
 }
 
 func TestSerializeDataModification(t *testing.T) {
-	var buf bytes.Buffer
 	pageID := &MockPageID{tableID: 7, pageNo: 700}
 	beforeImage := []byte("before")
 	afterImage := []byte("after")
 
 	record := &LogRecord{
+		Type:        UpdateRecord,
 		PageID:      pageID,
 		BeforeImage: beforeImage,
 		AfterImage:  afterImage,
 	}
 
-	record.serializeDataModification(&buf)
-
-	data := buf.Bytes()
+	data := record.AppendTo(nil)
+	if len(data) != record.SerializedSize() {
+		t.Errorf("AppendTo wrote %d bytes, SerializedSize is %d", len(data), record.SerializedSize())
+	}
 
 	// Should contain pageID, before image length + data, after image length + data
 	if len(data) == 0 {
@@ -401,19 +394,20 @@ func TestSerializeDataModification(t *testing.T) {
 }
 
 func TestSerializeCLR(t *testing.T) {
-	var buf bytes.Buffer
 	pageID := &MockPageID{tableID: 8, pageNo: 800}
 	afterImage := []byte("compensated")
 
 	record := &LogRecord{
+		Type:        CLRRecord,
 		PageID:      pageID,
 		UndoNextLSN: 100,
 		AfterImage:  afterImage,
 	}
 
-	record.serializeCLR(&buf)
-
-	data := buf.Bytes()
+	data := record.AppendTo(nil)
+	if len(data) != record.SerializedSize() {
+		t.Errorf("AppendTo wrote %d bytes, SerializedSize is %d", len(data), record.SerializedSize())
+	}
 
 	// Should contain pageID, UndoNextLSN, and after image
 	if len(data) == 0 {
@@ -956,3 +950,50 @@ func TestDeserializeImage_IncompleteData(t *testing.T) {
 		t.Error("Expected error for incomplete image data, got nil")
 	}
 }
+
+func TestEncoder_ReusesBufferAcrossRecords(t *testing.T) {
+	large := NewLogRecord(UpdateRecord, primitives.NewTransactionID(), &MockPageID{tableID: 1, pageNo: 2},
+		make([]byte, 256), []byte("after"), 7)
+	small := NewLogRecord(CommitRecord, primitives.NewTransactionID(), nil, nil, nil, 9)
+
+	enc := NewEncoder()
+	defer enc.Release()
+
+	for _, rec := range []*LogRecord{large, small, large} {
+		want, err := rec.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		got := enc.Encode(rec)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("Encode(%v) differs from Serialize", rec.Type)
+		}
+		if len(got) != rec.SerializedSize() {
+			t.Errorf("encoded %d bytes, SerializedSize is %d", len(got), rec.SerializedSize())
+		}
+	}
+
+	before := cap(enc.buf)
+	enc.Encode(small)
+	if cap(enc.buf) != before {
+		t.Errorf("encoding a smaller record reallocated the buffer: cap %d -> %d", before, cap(enc.buf))
+	}
+}
+
+func TestDeserializeLogRecord_ChecksumMismatch(t *testing.T) {
+	rec := NewLogRecord(UpdateRecord, primitives.NewTransactionID(), &MockPageID{tableID: 1, pageNo: 2},
+		[]byte("before"), []byte("after"), 7)
+	data, err := rec.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if _, err := DeserializeLogRecord(data); err != nil {
+		t.Fatalf("DeserializeLogRecord failed on an intact record: %v", err)
+	}
+
+	// Flip one bit of the after image, which still decodes
+	data[len(data)-ChecksumSize-1] ^= 0x01
+	if _, err := DeserializeLogRecord(data); !errors.Is(err, ErrCorruptRecord) {
+		t.Errorf("expected ErrCorruptRecord for a flipped bit, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package wal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"storemy/pkg/vfs"
+)
+
+// archiveCopyChunk is how much of the log file is copied at a time.
+const archiveCopyChunk = 1 << 20
+
+// ArchiveFunc archives the log file at path, e.g. by uploading it to offsite
+// storage. The file must not be modified, and is only valid until the
+// function returns.
+type ArchiveFunc func(path string) error
+
+// ArchiveConfig configures WAL archiving. Truncation is the only time
+// records leave the log, so before the log is truncated its file, with every
+// record truncation is about to drop, is copied to Dir and handed to Func.
+// Each archive holds the log as it was before one truncation, and later
+// archives start with the records earlier ones kept. Point-in-time recovery
+// replays the archives in order.
+type ArchiveConfig struct {
+	// Dir receives a copy of the log file before each truncation, named
+	// after the log file with a sequence number appended, such as
+	// wal.log.0000000000000001. Empty copies nothing.
+	Dir string
+
+	// Func is called with the archived copy in Dir, or with the log file
+	// itself if Dir is empty. Nil calls nothing.
+	Func ArchiveFunc
+}
+
+// Enabled reports whether the log is archived.
+func (c ArchiveConfig) Enabled() bool {
+	return c.Dir != "" || c.Func != nil
+}
+
+// archiver is the archiving state of a WAL, guarded by its mutex.
+type archiver struct {
+	config ArchiveConfig
+	next   uint64 // Sequence number of the next archive in Dir, 0 until looked up
+}
+
+// SetArchive enables WAL archiving with config, or disables it with the
+// zero value. Dir is created if it does not exist.
+func (w *WAL) SetArchive(config ArchiveConfig) error {
+	if w.readOnly && config.Enabled() {
+		return newReadOnlyError("SetArchive")
+	}
+	if config.Dir != "" {
+		if err := w.fs.MkdirAll(config.Dir, 0755); err != nil {
+			return newArchiveError(err, "failed to create archive directory")
+		}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if config.Dir != w.archive.config.Dir {
+		w.archive.next = 0
+	}
+	w.archive.config = config
+	return nil
+}
+
+// Archive returns the archiving configuration.
+func (w *WAL) Archive() ArchiveConfig {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.archive.config
+}
+
+// archiveLog archives the log file at path, which holds exactly the flushed
+// records. The caller holds w.mutex.
+func (w *WAL) archiveLog(path string) error {
+	config := w.archive.config
+	if !config.Enabled() {
+		return nil
+	}
+
+	if config.Dir != "" {
+		archived, err := w.copyToArchive(path, config.Dir)
+		if err != nil {
+			walArchiveFailures.Inc()
+			return newArchiveError(err, "failed to copy WAL to archive")
+		}
+		path = archived
+	}
+	if config.Func != nil {
+		if err := config.Func(path); err != nil {
+			walArchiveFailures.Inc()
+			return newArchiveError(err, "WAL archive function failed")
+		}
+	}
+
+	walArchivedFiles.Inc()
+	w.logger.Info("archived WAL", "path", path, "size", int64(w.writer.FlushedLSN()))
+	return nil
+}
+
+// copyToArchive copies the log file at path into dir under the next free
+// sequence number and returns the path of the copy. The copy is written to a
+// temporary file and renamed into place once synced, so a crash never
+// leaves a partial archive under an archive name.
+func (w *WAL) copyToArchive(path, dir string) (string, error) {
+	dst, err := w.nextArchivePath(path, dir)
+	if err != nil {
+		return "", err
+	}
+	tmp := dst + ".tmp"
+
+	src, err := w.fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	out, err := w.fs.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	if err := copyLogFile(out, src, int64(w.writer.FlushedLSN())); err != nil {
+		out.Close()
+		w.fs.Remove(tmp)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		w.fs.Remove(tmp)
+		return "", err
+	}
+	if err := w.fs.Rename(tmp, dst); err != nil {
+		w.fs.Remove(tmp)
+		return "", err
+	}
+
+	w.archive.next++
+	return dst, nil
+}
+
+// nextArchivePath returns the path of the next archive of the log file at
+// path in dir. The first call after the directory changes skips the
+// sequence numbers already taken by earlier archives.
+func (w *WAL) nextArchivePath(path, dir string) (string, error) {
+	if w.archive.next == 0 {
+		w.archive.next = 1
+	}
+	base := filepath.Base(path)
+	for {
+		dst := filepath.Join(dir, fmt.Sprintf("%s.%016d", base, w.archive.next))
+		if _, err := w.fs.Stat(dst); os.IsNotExist(err) {
+			return dst, nil
+		} else if err != nil {
+			return "", err
+		}
+		w.archive.next++
+	}
+}
+
+// copyLogFile copies the first size bytes of src to dst and syncs dst.
+func copyLogFile(dst, src vfs.File, size int64) error {
+	buf := make([]byte, min(size, archiveCopyChunk))
+	for off := int64(0); off < size; {
+		chunk := buf[:min(int64(len(buf)), size-off)]
+		if n, err := src.ReadAt(chunk, off); err != nil && (err != io.EOF || n < len(chunk)) {
+			return newArchiveError(err, "failed to read WAL at %d", off)
+		}
+		if _, err := dst.WriteAt(chunk, off); err != nil {
+			return newArchiveError(err, "failed to write archive at %d", off)
+		}
+		off += int64(len(chunk))
+	}
+	return dst.Sync()
+}
@@ -0,0 +1,129 @@
+package wal
+
+import (
+	"bytes"
+	"errors"
+	"storemy/pkg/logging"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"testing"
+)
+
+func logTransactions(t *testing.T, w *WAL, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		tid := primitives.NewTransactionIDFromValue(int64(i))
+		if _, err := w.LogBegin(tid); err != nil {
+			t.Fatalf("LogBegin failed: %v", err)
+		}
+		if _, err := w.LogCommit(tid); err != nil {
+			t.Fatalf("LogCommit failed: %v", err)
+		}
+	}
+}
+
+func TestWAL_ArchiveBeforeTruncation(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	w, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	var archived []string
+	err = w.SetArchive(ArchiveConfig{Dir: "/archive", Func: func(path string) error {
+		archived = append(archived, path)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("SetArchive failed: %v", err)
+	}
+
+	logTransactions(t, w, 1, 10)
+	before, err := fsys.ReadFile("/wal.log")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	truncateLSN := w.Stats().FlushedLSN / 2
+	if err := w.performTruncation(truncateLSN); err != nil {
+		t.Fatalf("performTruncation failed: %v", err)
+	}
+
+	if len(archived) != 1 || archived[0] != "/archive/wal.log.0000000000000001" {
+		t.Fatalf("archive function called with %v, want the first archive", archived)
+	}
+	copied, err := fsys.ReadFile(archived[0])
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !bytes.Equal(copied, before) {
+		t.Errorf("archive holds %d bytes, want the %d bytes of the log before truncation", len(copied), len(before))
+	}
+
+	logTransactions(t, w, 10, 15)
+	if err := w.performTruncation(w.Stats().FlushedLSN / 2); err != nil {
+		t.Fatalf("performTruncation failed: %v", err)
+	}
+	if len(archived) != 2 || archived[1] != "/archive/wal.log.0000000000000002" {
+		t.Fatalf("archive function called with %v, want a second archive", archived)
+	}
+}
+
+func TestWAL_ArchiveFailureKeepsLog(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	w, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	errArchive := errors.New("archive unavailable")
+	if err := w.SetArchive(ArchiveConfig{Func: func(string) error { return errArchive }}); err != nil {
+		t.Fatalf("SetArchive failed: %v", err)
+	}
+
+	logTransactions(t, w, 1, 5)
+	size := w.Stats().FileSize
+	if err := w.performTruncation(w.Stats().FlushedLSN / 2); !errors.Is(err, errArchive) {
+		t.Fatalf("performTruncation error = %v, want the archive error", err)
+	}
+	if got := w.Stats().FileSize; got != size {
+		t.Errorf("FileSize = %d after a failed archive, want %d", got, size)
+	}
+
+	// The log is still writable.
+	logTransactions(t, w, 5, 6)
+}
+
+func TestWAL_ArchiveSkipsTakenNames(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	if err := fsys.MkdirAll("/archive", 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := fsys.WriteFile("/archive/wal.log.0000000000000001", []byte("old"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	w, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+	if err := w.SetArchive(ArchiveConfig{Dir: "/archive"}); err != nil {
+		t.Fatalf("SetArchive failed: %v", err)
+	}
+
+	logTransactions(t, w, 1, 5)
+	if err := w.performTruncation(w.Stats().FlushedLSN / 2); err != nil {
+		t.Fatalf("performTruncation failed: %v", err)
+	}
+	if _, err := fsys.Stat("/archive/wal.log.0000000000000002"); err != nil {
+		t.Errorf("expected the archive after the existing one: %v", err)
+	}
+	if old, _ := fsys.ReadFile("/archive/wal.log.0000000000000001"); string(old) != "old" {
+		t.Error("existing archive overwritten")
+	}
+}
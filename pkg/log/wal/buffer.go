@@ -0,0 +1,139 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrWALBackpressure is returned by LogBegin and the logging of changes
+// when the log buffer is full at its largest size and the WAL is set to
+// BackpressureReject, instead of waiting for the buffer to be flushed.
+var ErrWALBackpressure = errors.New("WAL buffer is full")
+
+// MaxBufferSize is the largest size BufferPolicy.MaxSize accepts.
+const MaxBufferSize = 64 << 20
+
+// shrinkAfterFlushes is how many flushes in a row must find a grown buffer at
+// most a quarter full before it is halved.
+const shrinkAfterFlushes = 16
+
+// BackpressureMode decides what an append does when the log buffer is full
+// and cannot grow: the flush writing it out has fallen behind the appends.
+type BackpressureMode int
+
+const (
+	// BackpressureBlock flushes the buffer in the append, which waits for the
+	// write and sync of the whole buffer. The wait is counted as a stall.
+	BackpressureBlock BackpressureMode = iota
+
+	// BackpressureReject fails LogBegin and the logging of inserts, updates
+	// and deletes with ErrWALBackpressure, and flushes the buffer in the
+	// background, so the caller can back off and retry. Commits, aborts and
+	// the records undoing a transaction are never rejected, so a transaction
+	// can always end; they wait for the flush like BackpressureBlock.
+	BackpressureReject
+)
+
+func (m BackpressureMode) String() string {
+	switch m {
+	case BackpressureBlock:
+		return "block"
+	case BackpressureReject:
+		return "reject"
+	default:
+		return fmt.Sprintf("BackpressureMode(%d)", int(m))
+	}
+}
+
+// BufferPolicy sets how the log buffer adapts to the rate of appends. The
+// buffer starts at the size passed to NewWAL. An append that finds it full
+// doubles it, up to MaxSize, instead of flushing it, which absorbs bursts of
+// records without a write and sync in the append; a grown buffer is halved
+// again, down to its initial size, once shrinkAfterFlushes flushes in a row
+// found it at most a quarter full. Once the buffer is full at MaxSize,
+// Backpressure decides what the append does.
+//
+// A larger buffer holds more records that are not yet durable, which under
+// DurabilityAsync and DurabilityInterval are lost in a crash.
+type BufferPolicy struct {
+	// MaxSize is the largest the buffer grows to, in bytes. Zero keeps the
+	// buffer at its initial size.
+	MaxSize int
+
+	// Backpressure decides what an append does when the buffer is full at
+	// MaxSize. The zero value blocks.
+	Backpressure BackpressureMode
+}
+
+// Validate checks that MaxSize is in range and Backpressure is known.
+func (p BufferPolicy) Validate() error {
+	if p.MaxSize < 0 || p.MaxSize > MaxBufferSize {
+		return newConfigError("WAL buffer max size must be between 0 and %d, got %d", MaxBufferSize, p.MaxSize)
+	}
+	if p.Backpressure != BackpressureBlock && p.Backpressure != BackpressureReject {
+		return newConfigError("unknown WAL backpressure mode %v", p.Backpressure)
+	}
+	return nil
+}
+
+// SetBufferPolicy sets how the log buffer grows and what appends do once it
+// is full at its largest size. MaxSize must be zero or at least the initial
+// size of the buffer. A buffer already larger than a new MaxSize shrinks
+// back as it empties.
+func (w *WAL) SetBufferPolicy(policy BufferPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if policy.MaxSize != 0 && policy.MaxSize < w.writer.initialSize {
+		return newConfigError("WAL buffer max size %d is below its initial size %d", policy.MaxSize, w.writer.initialSize)
+	}
+	w.bufferPolicy = policy
+	w.writer.maxSize = policy.MaxSize
+	return nil
+}
+
+// BufferPolicy returns how the log buffer grows and what appends do once it
+// is full.
+func (w *WAL) BufferPolicy() BufferPolicy {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.bufferPolicy
+}
+
+// BufferStats reports how the log buffer kept up with the appends.
+type BufferStats struct {
+	Size       int           // Current size of the buffer in bytes
+	Growths    int64         // Times the buffer grew instead of being flushed
+	Stalls     int64         // Appends that had to flush the full buffer themselves
+	StallTime  time.Duration // Time those appends spent flushing
+	Rejections int64         // Appends failed with ErrWALBackpressure
+}
+
+// admit checks, under BackpressureReject, that rec fits in the buffer
+// without flushing it. If it does not, it starts flushing the buffer in the
+// background and returns ErrWALBackpressure. The size of an encrypted record
+// is that of its plaintext, so a record close to the limit may still flush
+// the buffer in the append. Must be called with w.mutex held.
+func (w *WAL) admit(size int, operation string) error {
+	if w.bufferPolicy.Backpressure != BackpressureReject || !w.writer.wouldStall(size) {
+		return nil
+	}
+	w.writer.rejections++
+	walBackpressureRejections.Inc()
+
+	if w.draining.CompareAndSwap(false, true) {
+		w.drains.Add(1)
+		go func() {
+			defer w.drains.Done()
+			defer w.draining.Store(false)
+			if err := w.flushBuffered(); err != nil {
+				w.logger.Error("background WAL flush failed", "error", err)
+			}
+		}()
+	}
+	return newBackpressureError(operation)
+}
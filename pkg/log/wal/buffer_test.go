@@ -0,0 +1,172 @@
+package wal
+
+import (
+	"errors"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"testing"
+	"time"
+)
+
+func TestLogWriter_GrowsAndShrinks(t *testing.T) {
+	w := NewLogWriter(discardWriterAt{}, 512, 0, 0)
+	w.maxSize = 4096
+	data := make([]byte, 100)
+
+	// Appends past the initial size grow the buffer instead of flushing it
+	for range 20 {
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if stats := w.bufferStats(); stats.Size != 2048 || stats.Stalls != 0 || w.FlushedLSN() != 0 {
+		t.Fatalf("after 2000 bytes: %+v, flushed %d; want a 2048-byte buffer and no flush", stats, w.FlushedLSN())
+	}
+
+	// Full at its largest size, the buffer is flushed in the append
+	for range 21 {
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if stats := w.bufferStats(); stats.Size != 4096 || stats.Stalls != 1 {
+		t.Fatalf("after 4100 bytes: %+v; want a 4096-byte buffer and one stall", stats)
+	}
+
+	// A record larger than the buffer but within its largest size fits
+	if _, err := w.Write(make([]byte, 3000)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// Flushes that find it nearly empty shrink it back to its initial size
+	for range 4 * shrinkAfterFlushes {
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if err := w.flush(); err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+	}
+	if size := w.bufferStats().Size; size != 512 {
+		t.Errorf("buffer is %d bytes after light use, want 512", size)
+	}
+}
+
+func TestWAL_BackpressureReject(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 512)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	defer w.Close()
+	if err := w.SetBufferPolicy(BufferPolicy{MaxSize: 1024, Backpressure: BackpressureReject}); err != nil {
+		t.Fatalf("SetBufferPolicy failed: %v", err)
+	}
+
+	tid := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	pid := &mockPageID{tableID: 1, pageNo: 0}
+
+	// Fill the buffer up to its largest size
+	var rejected error
+	for range 20 {
+		if _, rejected = w.LogInsert(tid, pid, make([]byte, 100)); rejected != nil {
+			break
+		}
+	}
+	if !errors.Is(rejected, ErrWALBackpressure) {
+		t.Fatalf("expected ErrWALBackpressure once the buffer was full, got %v", rejected)
+	}
+	if stats := w.Stats(); stats.Buffer.Size != 1024 || stats.Buffer.Rejections != 1 {
+		t.Errorf("Stats().Buffer = %+v, want a 1024-byte buffer and one rejection", stats.Buffer)
+	}
+
+	// The buffer is flushed in the background, after which appends go through
+	deadline := time.Now().Add(2 * time.Second)
+	for w.Stats().BufferedBytes != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("buffer was not flushed in the background")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := w.LogInsert(tid, pid, make([]byte, 100)); err != nil {
+		t.Fatalf("LogInsert after the flush failed: %v", err)
+	}
+
+	// A commit is never refused, even when the buffer is full
+	for {
+		if _, err := w.LogInsert(tid, pid, make([]byte, 100)); err != nil {
+			break
+		}
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit with a full buffer failed: %v", err)
+	}
+}
+
+func TestWAL_BackpressureBlockCountsStalls(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 512)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	defer w.Close()
+
+	tid := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	for range 10 {
+		if _, err := w.LogInsert(tid, &mockPageID{tableID: 1, pageNo: 0}, make([]byte, 100)); err != nil {
+			t.Fatalf("LogInsert failed: %v", err)
+		}
+	}
+	if stats := w.Stats().Buffer; stats.Stalls == 0 || stats.Rejections != 0 || stats.Size != 512 {
+		t.Errorf("Stats().Buffer = %+v, want stalls from a fixed 512-byte buffer", stats)
+	}
+}
+
+func TestWAL_SetBufferPolicyValidates(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	defer w.Close()
+
+	for _, policy := range []BufferPolicy{
+		{MaxSize: -1},
+		{MaxSize: MaxBufferSize + 1},
+		{MaxSize: 2048},
+		{Backpressure: BackpressureMode(7)},
+	} {
+		if err := w.SetBufferPolicy(policy); err == nil {
+			t.Errorf("SetBufferPolicy(%+v) succeeded, want an error", policy)
+		}
+	}
+	if err := w.SetBufferPolicy(BufferPolicy{MaxSize: 1 << 20}); err != nil {
+		t.Fatalf("SetBufferPolicy failed: %v", err)
+	}
+	if got := w.BufferPolicy(); got.MaxSize != 1<<20 || got.Backpressure != BackpressureBlock {
+		t.Errorf("BufferPolicy() = %+v", got)
+	}
+}
+
+func TestWAL_BufferPolicySurvivesWriterReset(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 512)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	defer w.Close()
+	if err := w.SetBufferPolicy(BufferPolicy{MaxSize: 4096}); err != nil {
+		t.Fatalf("SetBufferPolicy failed: %v", err)
+	}
+
+	w.mutex.Lock()
+	w.writer.grow(2048)
+	w.resetWriter(w.file, w.writer.FlushedLSN())
+	w.mutex.Unlock()
+
+	if got := w.writer; got.initialSize != 512 || got.maxSize != 4096 || got.growths != 1 {
+		t.Errorf("after a writer reset: initial %d, max %d, growths %d; want 512, 4096, 1", got.initialSize, got.maxSize, got.growths)
+	}
+}
@@ -0,0 +1,46 @@
+package wal
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+// LogBulkLoad logs that tid is about to write the pages of load to a heap
+// file without logging them, and forces the record to disk. It must be
+// durable before the first page is written, so that recovery knows which
+// pages to undo should tid not commit.
+func (w *WAL) LogBulkLoad(tid *primitives.TransactionID, load record.BulkLoad) (primitives.LSN, error) {
+	lsn, err := w.logBulkLoadRecord(record.NewBulkLoadRecord, tid, load)
+	if err != nil {
+		return 0, err
+	}
+	if err := w.WaitForDurability(lsn); err != nil {
+		return 0, newIOError(err, "failed to force bulk load record to disk")
+	}
+	return lsn, nil
+}
+
+// LogBulkLoadBarrier logs that the pages of load, whose EndPage is the new
+// relation size, have been synced to the heap file. It is not forced: the
+// COMMIT of tid forces it along.
+func (w *WAL) LogBulkLoadBarrier(tid *primitives.TransactionID, load record.BulkLoad) (primitives.LSN, error) {
+	return w.logBulkLoadRecord(record.NewBulkLoadBarrierRecord, tid, load)
+}
+
+func (w *WAL) logBulkLoadRecord(newRecord func(*primitives.TransactionID, record.BulkLoad, primitives.LSN) *record.LogRecord, tid *primitives.TransactionID, load record.BulkLoad) (primitives.LSN, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	txnInfo, err := w.getTransactionInfo(tid)
+	if err != nil {
+		return 0, err
+	}
+
+	lsn, err := w.writeRecord(newRecord(tid, load, txnInfo.LastLSN))
+	if err != nil {
+		return 0, err
+	}
+
+	txnInfo.LastLSN = lsn
+	return lsn, nil
+}
@@ -0,0 +1,70 @@
+package wal
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"testing"
+)
+
+func TestBulkLoad_LogsStartDurablyAndBarrierInChain(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+	load := record.BulkLoad{Path: "/t.dat", StartPage: 2}
+
+	beginLSN, err := w.LogBegin(tid)
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	startLSN, err := w.LogBulkLoad(tid, load)
+	if err != nil {
+		t.Fatalf("LogBulkLoad failed: %v", err)
+	}
+	if !w.IsDurable(startLSN) {
+		t.Error("expected the bulk load record to be forced before any page is written")
+	}
+
+	load.EndPage = 9
+	barrierLSN, err := w.LogBulkLoadBarrier(tid, load)
+	if err != nil {
+		t.Fatalf("LogBulkLoadBarrier failed: %v", err)
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+
+	reader, err := NewLogReaderWithFS(w.fs, w.file.Name())
+	if err != nil {
+		t.Fatalf("NewLogReaderWithFS failed: %v", err)
+	}
+	defer reader.Close()
+
+	want := map[primitives.LSN]primitives.LSN{startLSN: beginLSN, barrierLSN: startLSN}
+	for {
+		rec, err := reader.ReadNext()
+		if err != nil {
+			break
+		}
+		prev, ok := want[rec.LSN]
+		if !ok {
+			continue
+		}
+		if rec.PrevLSN != prev {
+			t.Errorf("record at %d: PrevLSN %d, want %d", rec.LSN, rec.PrevLSN, prev)
+		}
+		if rec.LSN == barrierLSN && rec.BulkLoad != load {
+			t.Errorf("barrier carries %s, want %s", rec.BulkLoad, load)
+		}
+		delete(want, rec.LSN)
+	}
+	if len(want) != 0 {
+		t.Errorf("bulk load records not found at %v", want)
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("expected a clean log, got issues: %v", report.Issues)
+	}
+}
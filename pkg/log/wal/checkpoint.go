@@ -1,7 +1,6 @@
 package wal
 
 import (
-	"fmt"
 	"os"
 	"storemy/pkg/log/record"
 	"storemy/pkg/primitives"
@@ -23,12 +22,12 @@ var globalCheckpointState = &checkpointState{}
 //
 // Returns the LSN of the checkpoint end record
 func (w *WAL) WriteCheckpoint() (primitives.LSN, error) {
-	fmt.Println("Starting fuzzy checkpoint...")
+	w.logger.Info("starting fuzzy checkpoint")
 
 	// Phase 1: Write CheckpointBegin record
 	beginLSN, err := w.writeCheckpointBegin()
 	if err != nil {
-		return 0, fmt.Errorf("failed to write checkpoint begin: %w", err)
+		return 0, newCheckpointError(err, "failed to write checkpoint begin")
 	}
 
 	// Phase 2: Capture snapshot of active transactions and dirty pages (with lock)
@@ -44,19 +43,23 @@ func (w *WAL) WriteCheckpoint() (primitives.LSN, error) {
 		}
 	}
 
-	dirtyPages := make(map[primitives.PageID]primitives.LSN)
-	for pageID, lsn := range w.dirtyPages {
-		dirtyPages[pageID] = lsn
+	dirtyPages := make(map[primitives.PageKey]primitives.LSN, len(w.dirtyPages))
+	for key, lsn := range w.dirtyPages {
+		dirtyPages[key] = lsn
 	}
 	w.mutex.RUnlock()
 
 	// Phase 3: Create and serialize checkpoint record
 	checkpointRec := record.NewCheckpointRecord(activeTxns, dirtyPages)
 	checkpointRec.LSN = beginLSN
+	checkpointRec.MaxTID = primitives.LastTransactionID()
+	for tid := range activeTxns {
+		checkpointRec.MaxTID = max(checkpointRec.MaxTID, tid.ID())
+	}
 
 	checkpointData, err := record.SerializeCheckpoint(checkpointRec)
 	if err != nil {
-		return 0, fmt.Errorf("failed to serialize checkpoint: %w", err)
+		return 0, newCheckpointError(err, "failed to serialize checkpoint")
 	}
 
 	// Phase 4: Write checkpoint data to a separate checkpoint file
@@ -64,26 +67,26 @@ func (w *WAL) WriteCheckpoint() (primitives.LSN, error) {
 	// and makes recovery faster (no need to scan entire WAL to find checkpoint)
 	checkpointPath := w.getCheckpointPath()
 	if err := w.writeCheckpointFile(checkpointPath, checkpointData); err != nil {
-		return 0, fmt.Errorf("failed to write checkpoint file: %w", err)
+		return 0, newCheckpointError(err, "failed to write checkpoint file")
 	}
 
 	// Phase 5: Write CheckpointEnd record (this completes the checkpoint)
 	endLSN, err := w.writeCheckpointEnd(beginLSN)
 	if err != nil {
-		return 0, fmt.Errorf("failed to write checkpoint end: %w", err)
+		return 0, newCheckpointError(err, "failed to write checkpoint end")
 	}
 
 	// Phase 6: Force checkpoint records to disk
 	if err := w.Force(endLSN); err != nil {
-		return 0, fmt.Errorf("failed to force checkpoint to disk: %w", err)
+		return 0, newCheckpointError(err, "failed to force checkpoint to disk")
 	}
 
 	// Update global checkpoint state
 	globalCheckpointState.lastCheckpointLSN.Store(endLSN)
 	globalCheckpointState.checkpointFile = checkpointPath
 
-	fmt.Printf("Checkpoint completed: LSN=%d, ActiveTxns=%d, DirtyPages=%d, Size=%d bytes\n",
-		endLSN, len(activeTxns), len(dirtyPages), len(checkpointData))
+	w.logger.Info("checkpoint completed",
+		"lsn", endLSN, "active_txns", len(activeTxns), "dirty_pages", len(dirtyPages), "size_bytes", len(checkpointData))
 
 	return endLSN, nil
 }
@@ -95,18 +98,18 @@ func (w *WAL) GetLastCheckpoint() (*record.CheckpointRecord, error) {
 	checkpointPath := w.getCheckpointPath()
 
 	// Try to read checkpoint file
-	data, err := os.ReadFile(checkpointPath)
+	data, err := w.fs.ReadFile(checkpointPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil // No checkpoint exists
 		}
-		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+		return nil, newCheckpointError(err, "failed to read checkpoint file")
 	}
 
 	// Deserialize checkpoint
 	checkpoint, err := record.DeserializeCheckpoint(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to deserialize checkpoint: %w", err)
+		return nil, newCheckpointError(err, "failed to deserialize checkpoint")
 	}
 
 	return checkpoint, nil
@@ -120,7 +123,7 @@ func (w *WAL) writeCheckpointBegin() (primitives.LSN, error) {
 	rec := record.NewLogRecord(record.CheckpointBegin, nil, nil, nil, nil, 0)
 	lsn, err := w.writeRecord(rec)
 	if err != nil {
-		return 0, fmt.Errorf("failed to write checkpoint begin record: %w", err)
+		return 0, newCheckpointError(err, "failed to write checkpoint begin record")
 	}
 
 	return lsn, nil
@@ -135,7 +138,7 @@ func (w *WAL) writeCheckpointEnd(beginLSN primitives.LSN) (primitives.LSN, error
 	rec := record.NewLogRecord(record.CheckpointEnd, nil, nil, nil, nil, beginLSN)
 	lsn, err := w.writeRecord(rec)
 	if err != nil {
-		return 0, fmt.Errorf("failed to write checkpoint end record: %w", err)
+		return 0, newCheckpointError(err, "failed to write checkpoint end record")
 	}
 
 	return lsn, nil
@@ -146,14 +149,14 @@ func (w *WAL) writeCheckpointFile(path string, data []byte) error {
 	// Write to a temporary file first, then atomically rename
 	tempPath := path + ".tmp"
 
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temporary checkpoint file: %w", err)
+	if err := w.fs.WriteFile(tempPath, data, 0644); err != nil {
+		return newCheckpointError(err, "failed to write temporary checkpoint file")
 	}
 
 	// Atomically rename to final path
-	if err := os.Rename(tempPath, path); err != nil {
-		os.Remove(tempPath) // Clean up temp file on error
-		return fmt.Errorf("failed to rename checkpoint file: %w", err)
+	if err := w.fs.Rename(tempPath, path); err != nil {
+		w.fs.Remove(tempPath) // Clean up temp file on error
+		return newCheckpointError(err, "failed to rename checkpoint file")
 	}
 
 	return nil
@@ -189,14 +192,13 @@ type CheckpointStats struct {
 // based on WAL size or time since last checkpoint
 func (w *WAL) ShouldCheckpoint(maxWALSize int64, maxInterval time.Duration) bool {
 	// Check WAL size
-	info, err := w.file.Stat()
-	if err == nil && info.Size() >= maxWALSize {
+	if w.Stats().FileSize >= maxWALSize {
 		return true
 	}
 
 	// Check time since last checkpoint
 	checkpointPath := w.getCheckpointPath()
-	info, err = os.Stat(checkpointPath)
+	info, err := w.fs.Stat(checkpointPath)
 	if err != nil {
 		// No checkpoint exists yet
 		return true
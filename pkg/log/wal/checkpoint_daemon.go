@@ -1,7 +1,8 @@
 package wal
 
 import (
-	"fmt"
+	"storemy/pkg/clock"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 	"sync"
 	"sync/atomic"
@@ -10,14 +11,16 @@ import (
 
 // CheckpointDaemon manages automatic checkpoint triggering
 type CheckpointDaemon struct {
-	wal           *WAL
-	config        CheckpointConfig
-	stopChan      chan struct{}
-	wg            sync.WaitGroup
-	running       atomic.Bool
+	wal            *WAL
+	config         CheckpointConfig
+	stopChan       chan struct{}
+	wg             sync.WaitGroup
+	running        atomic.Bool
 	lastCheckpoint atomic.Value // stores time.Time
-	stats         CheckpointDaemonStats
-	statsMutex    sync.RWMutex
+	stats          CheckpointDaemonStats
+	statsMutex     sync.RWMutex
+	logger         logging.ComponentLogger
+	clock          clock.Clock
 }
 
 // CheckpointConfig configures checkpoint triggering behavior
@@ -47,13 +50,13 @@ func DefaultCheckpointConfig() CheckpointConfig {
 
 // CheckpointDaemonStats tracks daemon statistics
 type CheckpointDaemonStats struct {
-	TotalCheckpoints     int64
-	TimeBasedTriggers    int64
-	SizeBasedTriggers    int64
-	ManualTriggers       int64
-	FailedCheckpoints    int64
-	LastCheckpointTime   time.Time
-	LastCheckpointLSN    primitives.LSN
+	TotalCheckpoints       int64
+	TimeBasedTriggers      int64
+	SizeBasedTriggers      int64
+	ManualTriggers         int64
+	FailedCheckpoints      int64
+	LastCheckpointTime     time.Time
+	LastCheckpointLSN      primitives.LSN
 	LastCheckpointDuration time.Duration
 }
 
@@ -63,24 +66,38 @@ func NewCheckpointDaemon(wal *WAL, config CheckpointConfig) *CheckpointDaemon {
 		wal:      wal,
 		config:   config,
 		stopChan: make(chan struct{}),
+		logger:   logging.ForComponent("checkpoint_daemon"),
+		clock:    clock.Real,
 	}
-	daemon.lastCheckpoint.Store(time.Now())
+	daemon.lastCheckpoint.Store(daemon.clock.Now())
 	return daemon
 }
 
+// SetClock replaces the clock that drives the time- and size-based triggers,
+// so tests can advance time deterministically. It must be called before Start.
+func (cd *CheckpointDaemon) SetClock(c clock.Clock) {
+	cd.clock = c
+	cd.lastCheckpoint.Store(c.Now())
+}
+
+// SetLogger replaces the daemon's logger. It must be called before Start.
+func (cd *CheckpointDaemon) SetLogger(logger logging.ComponentLogger) {
+	cd.logger = logger
+}
+
 // Start begins the checkpoint daemon
 func (cd *CheckpointDaemon) Start() error {
 	if !cd.config.Enabled {
-		fmt.Println("Checkpoint daemon disabled")
+		cd.logger.Info("checkpoint daemon disabled")
 		return nil
 	}
 
 	if !cd.running.CompareAndSwap(false, true) {
-		return fmt.Errorf("checkpoint daemon already running")
+		return newCheckpointError(nil, "checkpoint daemon already running")
 	}
 
-	fmt.Printf("Starting checkpoint daemon (interval=%v, maxWALSize=%d bytes)\n",
-		cd.config.Interval, cd.config.MaxWALSize)
+	cd.logger.Info("starting checkpoint daemon",
+		"interval", cd.config.Interval, "max_wal_size", cd.config.MaxWALSize)
 
 	cd.wg.Add(1)
 	go cd.run()
@@ -94,11 +111,11 @@ func (cd *CheckpointDaemon) Stop() error {
 		return nil
 	}
 
-	fmt.Println("Stopping checkpoint daemon...")
+	cd.logger.Info("stopping checkpoint daemon")
 	close(cd.stopChan)
 	cd.wg.Wait()
 	cd.running.Store(false)
-	fmt.Println("Checkpoint daemon stopped")
+	cd.logger.Info("checkpoint daemon stopped")
 
 	return nil
 }
@@ -107,11 +124,11 @@ func (cd *CheckpointDaemon) Stop() error {
 func (cd *CheckpointDaemon) run() {
 	defer cd.wg.Done()
 
-	ticker := time.NewTicker(cd.config.Interval)
+	ticker := cd.clock.NewTicker(cd.config.Interval)
 	defer ticker.Stop()
 
 	// Also check more frequently for size-based triggers
-	checkTicker := time.NewTicker(30 * time.Second)
+	checkTicker := cd.clock.NewTicker(30 * time.Second)
 	defer checkTicker.Stop()
 
 	for {
@@ -119,7 +136,7 @@ func (cd *CheckpointDaemon) run() {
 		case <-cd.stopChan:
 			return
 
-		case <-ticker.C:
+		case <-ticker.C():
 			// Time-based trigger
 			if cd.shouldCheckpointByTime() {
 				cd.triggerCheckpoint("time-based")
@@ -128,7 +145,7 @@ func (cd *CheckpointDaemon) run() {
 				cd.statsMutex.Unlock()
 			}
 
-		case <-checkTicker.C:
+		case <-checkTicker.C():
 			// Check size-based trigger
 			if cd.shouldCheckpointBySize() {
 				cd.triggerCheckpoint("size-based")
@@ -143,7 +160,7 @@ func (cd *CheckpointDaemon) run() {
 // shouldCheckpointByTime checks if enough time has passed since last checkpoint
 func (cd *CheckpointDaemon) shouldCheckpointByTime() bool {
 	lastCheckpoint := cd.lastCheckpoint.Load().(time.Time)
-	return time.Since(lastCheckpoint) >= cd.config.Interval
+	return cd.clock.Since(lastCheckpoint) >= cd.config.Interval
 }
 
 // shouldCheckpointBySize checks if WAL has grown too large
@@ -157,17 +174,17 @@ func (cd *CheckpointDaemon) shouldCheckpointBySize() bool {
 
 // triggerCheckpoint performs a checkpoint
 func (cd *CheckpointDaemon) triggerCheckpoint(reason string) {
-	fmt.Printf("Triggering checkpoint (reason: %s)...\n", reason)
-	startTime := time.Now()
+	cd.logger.Info("triggering checkpoint", "reason", reason)
+	startTime := cd.clock.Now()
 
 	lsn, err := cd.wal.WriteCheckpoint()
-	duration := time.Since(startTime)
+	duration := cd.clock.Since(startTime)
 
 	cd.statsMutex.Lock()
 	defer cd.statsMutex.Unlock()
 
 	if err != nil {
-		fmt.Printf("Checkpoint failed: %v\n", err)
+		cd.logger.Error("checkpoint failed", "reason", reason, "error", err)
 		cd.stats.FailedCheckpoints++
 		return
 	}
@@ -179,24 +196,24 @@ func (cd *CheckpointDaemon) triggerCheckpoint(reason string) {
 	cd.stats.LastCheckpointDuration = duration
 	cd.lastCheckpoint.Store(startTime)
 
-	fmt.Printf("Checkpoint completed in %v (LSN=%d)\n", duration, lsn)
+	cd.logger.Info("checkpoint completed", "reason", reason, "duration", duration, "lsn", lsn)
 }
 
 // TriggerManualCheckpoint manually triggers a checkpoint
 // This is useful for testing or administrative operations
 func (cd *CheckpointDaemon) TriggerManualCheckpoint() (primitives.LSN, error) {
-	fmt.Println("Manual checkpoint triggered")
+	cd.logger.Info("manual checkpoint triggered")
 
-	startTime := time.Now()
+	startTime := cd.clock.Now()
 	lsn, err := cd.wal.WriteCheckpoint()
-	duration := time.Since(startTime)
+	duration := cd.clock.Since(startTime)
 
 	cd.statsMutex.Lock()
 	defer cd.statsMutex.Unlock()
 
 	if err != nil {
 		cd.stats.FailedCheckpoints++
-		return 0, fmt.Errorf("manual checkpoint failed: %w", err)
+		return 0, newCheckpointError(err, "manual checkpoint failed")
 	}
 
 	cd.stats.TotalCheckpoints++
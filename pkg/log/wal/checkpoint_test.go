@@ -1,8 +1,10 @@
 package wal
 
 import (
+	"encoding/binary"
 	"os"
 	"storemy/pkg/log/record"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"testing"
@@ -25,15 +27,16 @@ func TestCheckpointRecordSerialization(t *testing.T) {
 		},
 	}
 
-	dirtyPages := map[primitives.PageID]primitives.LSN{
-		page.NewPageDescriptor(primitives.FileID(1), primitives.PageNumber(0)): 100,
-		page.NewPageDescriptor(primitives.FileID(1), primitives.PageNumber(1)): 200,
-		page.NewPageDescriptor(primitives.FileID(2), primitives.PageNumber(0)): 300,
+	dirtyPages := map[primitives.PageKey]primitives.LSN{
+		primitives.KeyOf(page.NewPageDescriptor(primitives.FileID(1), primitives.PageNumber(0))): 100,
+		primitives.KeyOf(page.NewPageDescriptor(primitives.FileID(1), primitives.PageNumber(1))): 200,
+		primitives.KeyOf(page.NewPageDescriptor(primitives.FileID(2), primitives.PageNumber(0))): 300,
 	}
 
 	// Create checkpoint record
 	cp := record.NewCheckpointRecord(activeTxns, dirtyPages)
 	cp.LSN = 500
+	cp.MaxTID = 42
 
 	// Serialize
 	data, err := record.SerializeCheckpoint(cp)
@@ -52,6 +55,14 @@ func TestCheckpointRecordSerialization(t *testing.T) {
 		t.Errorf("LSN mismatch: expected %d, got %d", cp.LSN, cp2.LSN)
 	}
 
+	if cp2.MaxTID != cp.MaxTID {
+		t.Errorf("MaxTID mismatch: expected %d, got %d", cp.MaxTID, cp2.MaxTID)
+	}
+
+	if len(data) != cp.Size() {
+		t.Errorf("Size mismatch: expected %d, got %d", cp.Size(), len(data))
+	}
+
 	if len(cp2.ActiveTxns) != len(cp.ActiveTxns) {
 		t.Errorf("ActiveTxns count mismatch: expected %d, got %d", len(cp.ActiveTxns), len(cp2.ActiveTxns))
 	}
@@ -73,18 +84,85 @@ func TestCheckpointRecordSerialization(t *testing.T) {
 	}
 
 	// Verify dirty pages
-	for pageHash, lsn := range cp.DirtyPages {
-		lsn2, exists := cp2.DirtyPages[pageHash]
+	for key, lsn := range cp.DirtyPages {
+		lsn2, exists := cp2.DirtyPages[key]
 		if !exists {
-			t.Errorf("Page %d missing in deserialized checkpoint", pageHash)
+			t.Errorf("Page %v missing in deserialized checkpoint", key)
 			continue
 		}
 		if lsn2 != lsn {
-			t.Errorf("Page %d LSN mismatch: expected %d, got %d", pageHash, lsn, lsn2)
+			t.Errorf("Page %v LSN mismatch: expected %d, got %d", key, lsn, lsn2)
 		}
 	}
 }
 
+// TestCheckpointRecordLegacyFormat tests reading a checkpoint written before
+// dirty pages were keyed by page, when they were keyed by page hash
+func TestCheckpointRecordLegacyFormat(t *testing.T) {
+	// [Size:4][LSN:8][Timestamp:8][NumTxns:4][NumPages:4]{[PageHash:8][LSN:8]}[MaxTID:8]
+	var body []byte
+	body = binary.BigEndian.AppendUint64(body, 500)
+	body = binary.BigEndian.AppendUint64(body, uint64(time.Now().Unix()))
+	body = binary.BigEndian.AppendUint32(body, 0)
+	body = binary.BigEndian.AppendUint32(body, 2)
+	body = binary.BigEndian.AppendUint64(body, 0xdeadbeef)
+	body = binary.BigEndian.AppendUint64(body, 300)
+	body = binary.BigEndian.AppendUint64(body, 0xfeedface)
+	body = binary.BigEndian.AppendUint64(body, 120)
+	body = binary.BigEndian.AppendUint64(body, 7)
+
+	data := binary.BigEndian.AppendUint32(nil, uint32(4+len(body)))
+	data = append(data, body...)
+
+	cp, err := record.DeserializeCheckpoint(data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize legacy checkpoint: %v", err)
+	}
+
+	if cp.LSN != 500 || cp.MaxTID != 7 {
+		t.Errorf("Header mismatch: got LSN %d, MaxTID %d", cp.LSN, cp.MaxTID)
+	}
+	if len(cp.DirtyPages) != 0 {
+		t.Errorf("Expected no keyed dirty pages, got %d", len(cp.DirtyPages))
+	}
+	if len(cp.LegacyDirtyPages) != 2 {
+		t.Errorf("Expected 2 legacy dirty pages, got %d", len(cp.LegacyDirtyPages))
+	}
+
+	minLSN, ok := cp.MinDirtyLSN()
+	if !ok || minLSN != 120 {
+		t.Errorf("Expected min dirty LSN 120, got %d (found=%v)", minLSN, ok)
+	}
+}
+
+// TestCheckpointRecordDistinctPageKeys tests that dirty pages round-trip by
+// file and page number, including page numbers wider than 32 bits
+func TestCheckpointRecordDistinctPageKeys(t *testing.T) {
+	a := page.NewPageDescriptor(primitives.FileID(1), primitives.PageNumber(1<<32))
+	b := page.NewPageDescriptor(primitives.FileID(1), primitives.PageNumber(0))
+
+	dirtyPages := map[primitives.PageKey]primitives.LSN{
+		primitives.KeyOf(a): 100,
+		primitives.KeyOf(b): 200,
+	}
+	if len(dirtyPages) != 2 {
+		t.Fatalf("Expected 2 distinct page keys, got %d", len(dirtyPages))
+	}
+
+	data, err := record.SerializeCheckpoint(record.NewCheckpointRecord(nil, dirtyPages))
+	if err != nil {
+		t.Fatalf("Failed to serialize checkpoint: %v", err)
+	}
+	cp, err := record.DeserializeCheckpoint(data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize checkpoint: %v", err)
+	}
+
+	if cp.DirtyPages[primitives.KeyOf(a)] != 100 || cp.DirtyPages[primitives.KeyOf(b)] != 200 {
+		t.Errorf("Dirty page LSNs mismatch: %v", cp.DirtyPages)
+	}
+}
+
 // TestWriteCheckpoint tests writing a checkpoint to WAL
 func TestWriteCheckpoint(t *testing.T) {
 	// Create temporary WAL
@@ -99,6 +177,7 @@ func TestWriteCheckpoint(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create WAL: %v", err)
 	}
+	wal.SetLogger(logging.NewNopLogger())
 	defer wal.Close()
 	defer os.Remove(tmpFile.Name() + ".checkpoint")
 
@@ -170,6 +249,7 @@ func TestCheckpointDaemon(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create WAL: %v", err)
 	}
+	wal.SetLogger(logging.NewNopLogger())
 	defer wal.Close()
 	defer os.Remove(tmpFile.Name() + ".checkpoint")
 
@@ -182,6 +262,7 @@ func TestCheckpointDaemon(t *testing.T) {
 	}
 
 	daemon := NewCheckpointDaemon(wal, config)
+	daemon.SetLogger(logging.NewNopLogger())
 
 	// Start daemon
 	if err := daemon.Start(); err != nil {
@@ -229,6 +310,7 @@ func TestManualCheckpoint(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create WAL: %v", err)
 	}
+	wal.SetLogger(logging.NewNopLogger())
 	defer wal.Close()
 	defer os.Remove(tmpFile.Name() + ".checkpoint")
 
@@ -241,6 +323,7 @@ func TestManualCheckpoint(t *testing.T) {
 	}
 
 	daemon := NewCheckpointDaemon(wal, config)
+	daemon.SetLogger(logging.NewNopLogger())
 
 	// Trigger manual checkpoint
 	lsn, err := daemon.TriggerManualCheckpoint()
@@ -285,6 +368,7 @@ func TestNoCheckpoint(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create WAL: %v", err)
 	}
+	wal.SetLogger(logging.NewNopLogger())
 	defer wal.Close()
 
 	// Try to get checkpoint (should return nil, no error)
@@ -312,6 +396,7 @@ func TestCheckpointWithCommittedTransaction(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create WAL: %v", err)
 	}
+	wal.SetLogger(logging.NewNopLogger())
 	defer wal.Close()
 	defer os.Remove(tmpFile.Name() + ".checkpoint")
 
@@ -0,0 +1,19 @@
+package wal
+
+import "storemy/pkg/log/record"
+
+// SetCompression sets how later UPDATE records compress their before and
+// after images (see record.LogRecord.CompressImages). Records already in
+// the log stay readable whatever the setting, so it can change at any time.
+func (w *WAL) SetCompression(c record.Compression) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.compression = c
+}
+
+// Compression returns how UPDATE records compress their page images.
+func (w *WAL) Compression() record.Compression {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.compression
+}
@@ -0,0 +1,56 @@
+package wal
+
+import (
+	"bytes"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"testing"
+)
+
+func TestLogUpdate_CompressesImages(t *testing.T) {
+	before := make([]byte, page.PageSize)
+	after := bytes.Repeat([]byte("tuple"), page.PageSize/5)
+
+	logUpdate := func(c record.Compression) (*WAL, primitives.LSN) {
+		t.Helper()
+		wal, _, cleanup := createTestWAL(t)
+		t.Cleanup(cleanup)
+		wal.SetCompression(c)
+
+		tid := primitives.NewTransactionID()
+		if _, err := wal.LogBegin(tid); err != nil {
+			t.Fatalf("LogBegin failed: %v", err)
+		}
+		lsn, err := wal.LogUpdate(tid, page.NewPageDescriptor(1, 0), before, after)
+		if err != nil {
+			t.Fatalf("LogUpdate failed: %v", err)
+		}
+		if err := wal.Force(lsn); err != nil {
+			t.Fatalf("Force failed: %v", err)
+		}
+		return wal, wal.CurrentLSN() - lsn
+	}
+
+	_, plainSize := logUpdate(record.CompressionNone)
+	wal, compressedSize := logUpdate(record.CompressionDeflate)
+	if compressedSize*4 > plainSize {
+		t.Errorf("compressed UPDATE record takes %d bytes, expected far less than %d", compressedSize, plainSize)
+	}
+
+	reader, err := wal.OpenLogReader()
+	if err != nil {
+		t.Fatalf("OpenLogReader failed: %v", err)
+	}
+	defer reader.Close()
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(records) != 2 || records[1].Type != record.UpdateRecord {
+		t.Fatalf("expected BEGIN and UPDATE records, got %d records", len(records))
+	}
+	if !bytes.Equal(records[1].BeforeImage, before) || !bytes.Equal(records[1].AfterImage, after) {
+		t.Error("images read back differ from the ones logged")
+	}
+}
@@ -0,0 +1,78 @@
+package wal
+
+import (
+	"os"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+)
+
+// SetDirectIO reopens the log file with or without direct I/O (see
+// vfs.ODirect). The log is written once and read back only by recovery and
+// log readers, so caching it in the OS page cache mostly evicts pages that
+// are worth more. Buffered records are flushed first. Where the file system
+// does not support direct I/O the file keeps using the page cache, which
+// Stats reports.
+func (w *WAL) SetDirectIO(enabled bool) error {
+	if w.readOnly {
+		return newReadOnlyError("SetDirectIO")
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if enabled == w.directIO {
+		return nil
+	}
+	if err := w.writer.flush(); err != nil {
+		return newIOError(err, "failed to flush WAL")
+	}
+
+	w.directIO = enabled
+	file, err := w.fs.OpenFile(w.file.Name(), w.openFlag(), 0644)
+	if err != nil {
+		w.directIO = !enabled
+		return newIOError(err, "failed to reopen WAL file")
+	}
+	w.file.Close()
+	w.indexMu.Lock()
+	w.file = file
+	w.indexMu.Unlock()
+	w.resetWriter(file, w.writer.FlushedLSN())
+	return nil
+}
+
+// DirectIO reports whether the log file is written with direct I/O, which
+// is false after SetDirectIO(true) on a file system without it.
+func (w *WAL) DirectIO() bool {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return vfs.IsDirect(w.file)
+}
+
+// openFlag returns the flags to reopen the log file with.
+func (w *WAL) openFlag() int {
+	flag := os.O_RDWR | w.syncPolicy.OpenFlag()
+	if w.directIO {
+		flag |= vfs.ODirect
+	}
+	return flag
+}
+
+// resetWriter replaces the writer with one appending to file at lsn, keeping
+// the buffer sizes and counters, cipher and durability of the old one. The
+// new writer extends w.index. The caller holds w.mutex and has flushed the
+// old writer.
+func (w *WAL) resetWriter(file vfs.File, lsn primitives.LSN) {
+	old := w.writer
+	w.writer = NewLogWriter(file, old.bufferSize, lsn, lsn)
+	w.writer.initialSize = old.initialSize
+	w.writer.maxSize = old.maxSize
+	w.writer.growths, w.writer.stalls = old.growths, old.stalls
+	w.writer.stallTime, w.writer.rejections = old.stallTime, old.rejections
+	w.writer.sync = func() error { return w.syncPolicy.Sync(file) }
+	w.writer.cipher = old.cipher
+	w.writer.noSync = old.noSync
+	w.writer.onFlush = old.onFlush
+	w.writer.unsynced = old.unsynced || old.noSync
+	w.writer.index = w.index
+}
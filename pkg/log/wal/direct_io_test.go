@@ -0,0 +1,68 @@
+package wal
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+	"testing"
+)
+
+func TestWAL_DirectIOKeepsRecordsReadable(t *testing.T) {
+	wal, logPath, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	// Records buffered before the switch are flushed through the old handle
+	tid := primitives.NewTransactionID()
+	if _, err := wal.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if err := wal.SetDirectIO(true); err != nil {
+		t.Fatalf("SetDirectIO failed: %v", err)
+	}
+	if wal.FlushedLSN() != wal.CurrentLSN() {
+		t.Errorf("SetDirectIO left records buffered: flushed %d of %d", wal.FlushedLSN(), wal.CurrentLSN())
+	}
+
+	// Unaligned records straddling blocks
+	for i := range 10 {
+		image := make([]byte, page.PageSize/3+i)
+		if _, err := wal.LogInsert(tid, page.NewPageDescriptor(1, primitives.PageNumber(i)), image); err != nil {
+			t.Fatalf("LogInsert failed: %v", err)
+		}
+	}
+	if _, err := wal.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if got, want := wal.Stats().DirectIO, vfs.IsDirect(wal.file); got != want || got != wal.DirectIO() {
+		t.Errorf("Stats().DirectIO = %v, want %v", got, want)
+	}
+
+	info, err := wal.file.Stat()
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if primitives.LSN(info.Size()) != wal.FlushedLSN() {
+		t.Errorf("log file is %d bytes, want the flushed LSN %d", info.Size(), wal.FlushedLSN())
+	}
+
+	reader, err := NewLogReader(logPath)
+	if err != nil {
+		t.Fatalf("NewLogReader failed: %v", err)
+	}
+	defer reader.Close()
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(records) != 12 || records[11].Type != record.CommitRecord {
+		t.Fatalf("expected BEGIN, 10 INSERTs and COMMIT, got %d records", len(records))
+	}
+
+	if err := wal.SetDirectIO(false); err != nil {
+		t.Fatalf("SetDirectIO failed: %v", err)
+	}
+	if wal.Stats().DirectIO {
+		t.Error("expected direct I/O to be off")
+	}
+}
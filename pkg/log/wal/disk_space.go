@@ -0,0 +1,185 @@
+package wal
+
+import (
+	"errors"
+	"path/filepath"
+	"storemy/pkg/vfs"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDiskSpaceLow is returned by LogBegin while the file system holding the
+// log has less free space than the reserve set with SetDiskReserve.
+var ErrDiskSpaceLow = errors.New("WAL disk space is below its reserve")
+
+// DefaultDiskCheckInterval is how often the free space of the log's file
+// system is checked when DiskReserve.CheckInterval is zero.
+const DefaultDiskCheckInterval = 5 * time.Second
+
+// DiskReserve keeps free space on the file system of the log for the
+// transactions already running. Once less than MinFreeBytes are free, no
+// transaction begins: LogBegin fails with ErrDiskSpaceLow, while the
+// transactions that began still log their changes, commit and abort into the
+// reserve. Transactions begin again as soon as a check finds the space back.
+type DiskReserve struct {
+	// MinFreeBytes is the free space below which transactions stop
+	// beginning. Zero disables the monitor.
+	MinFreeBytes int64
+
+	// CheckInterval is how often the free space is checked. Zero uses
+	// DefaultDiskCheckInterval.
+	CheckInterval time.Duration
+}
+
+// Enabled reports whether the free space is monitored.
+func (r DiskReserve) Enabled() bool {
+	return r.MinFreeBytes > 0
+}
+
+// Validate checks that the reserve and interval are not negative.
+func (r DiskReserve) Validate() error {
+	if r.MinFreeBytes < 0 {
+		return newConfigError("disk reserve must not be negative, got %d", r.MinFreeBytes)
+	}
+	if r.CheckInterval < 0 {
+		return newConfigError("disk check interval must not be negative, got %v", r.CheckInterval)
+	}
+	return nil
+}
+
+func (r DiskReserve) interval() time.Duration {
+	if r.CheckInterval <= 0 {
+		return DefaultDiskCheckInterval
+	}
+	return r.CheckInterval
+}
+
+// diskMonitor watches the free space of the file system holding the log.
+// Like intervalSync, its goroutine runs only while a reserve is set.
+type diskMonitor struct {
+	mu      sync.Mutex
+	reserve DiskReserve
+	closed  bool          // The WAL is closed, the monitor must not start again
+	stop    chan struct{} // Closed to stop the running monitor, nil if none
+	done    chan struct{} // Closed once the running monitor has returned
+
+	freeSpace func(dir string) (int64, error) // vfs.FreeSpace, replaced by tests
+	low       atomic.Bool                     // Less than the reserve was free at the last check
+}
+
+func newDiskMonitor() *diskMonitor {
+	return &diskMonitor{freeSpace: vfs.FreeSpace}
+}
+
+// SetDiskReserve sets the free space kept for running transactions on the
+// file system of the log, checks it at once and then keeps checking it in
+// the background; the zero value stops monitoring. A read-only WAL, which
+// begins no transactions, is never monitored. It fails with
+// vfs.ErrFreeSpaceUnsupported where the free space cannot be read.
+func (w *WAL) SetDiskReserve(reserve DiskReserve) error {
+	if err := reserve.Validate(); err != nil {
+		return err
+	}
+
+	m := w.disk
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopMonitor()
+	m.reserve = reserve
+	if m.closed || w.readOnly || !reserve.Enabled() {
+		m.low.Store(false)
+		return nil
+	}
+
+	w.mutex.RLock()
+	dir := filepath.Dir(w.file.Name())
+	w.mutex.RUnlock()
+	if err := w.checkDiskSpace(dir, reserve); errors.Is(err, vfs.ErrFreeSpaceUnsupported) {
+		m.reserve = DiskReserve{}
+		return err
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go w.runDiskMonitor(dir, reserve, m.stop, m.done)
+	return nil
+}
+
+// DiskReserve returns the free space kept for running transactions.
+func (w *WAL) DiskReserve() DiskReserve {
+	w.disk.mu.Lock()
+	defer w.disk.mu.Unlock()
+	return w.disk.reserve
+}
+
+// DiskSpaceLow reports whether the last check found less free space than
+// the reserve, so that no transaction begins.
+func (w *WAL) DiskSpaceLow() bool {
+	return w.disk.low.Load()
+}
+
+// closeDiskMonitor stops the monitor for good.
+func (w *WAL) closeDiskMonitor() {
+	m := w.disk
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.stopMonitor()
+}
+
+// stopMonitor stops the running monitor and waits for it to return. It must
+// be called with m.mu held.
+func (m *diskMonitor) stopMonitor() {
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	<-m.done
+	m.stop, m.done = nil, nil
+}
+
+// runDiskMonitor checks the free space of dir every interval until stop is
+// closed.
+func (w *WAL) runDiskMonitor(dir string, reserve DiskReserve, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(reserve.interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.checkDiskSpace(dir, reserve)
+		}
+	}
+}
+
+// checkDiskSpace compares the free space of dir with the reserve. A check
+// that fails is logged and leaves the state as it was.
+func (w *WAL) checkDiskSpace(dir string, reserve DiskReserve) error {
+	free, err := w.disk.freeSpace(dir)
+	if err != nil {
+		w.logger.Warn("failed to check WAL disk space", "dir", dir, "error", err)
+		return err
+	}
+	walDiskFreeBytes.Set(free)
+	w.setDiskSpaceLow(free < reserve.MinFreeBytes, free)
+	return nil
+}
+
+// setDiskSpaceLow records whether less than the reserve is free, logging
+// the transitions.
+func (w *WAL) setDiskSpaceLow(low bool, free int64) {
+	if w.disk.low.Swap(low) == low {
+		return
+	}
+	if low {
+		w.logger.Error("WAL disk space below reserve, refusing new transactions", "free_bytes", free)
+	} else {
+		w.logger.Info("WAL disk space back above reserve, accepting transactions", "free_bytes", free)
+	}
+}
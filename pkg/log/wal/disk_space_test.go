@@ -0,0 +1,92 @@
+package wal
+
+import (
+	"errors"
+	"storemy/pkg/logging"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWAL_DiskReserve(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	var free atomic.Int64
+	free.Store(1 << 20)
+	w.disk.freeSpace = func(string) (int64, error) { return free.Load(), nil }
+
+	reserve := DiskReserve{MinFreeBytes: 1 << 10, CheckInterval: time.Millisecond}
+	if err := w.SetDiskReserve(reserve); err != nil {
+		t.Fatalf("SetDiskReserve failed: %v", err)
+	}
+	if w.DiskSpaceLow() {
+		t.Fatal("disk space reported low with room to spare")
+	}
+
+	// A transaction that began before the disk filled up still commits
+	running := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(running); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	free.Store(100)
+	waitFor(t, w.DiskSpaceLow, "disk space to be reported low")
+
+	if _, err := w.LogBegin(primitives.NewTransactionIDFromValue(2)); !errors.Is(err, ErrDiskSpaceLow) {
+		t.Fatalf("LogBegin with the disk below its reserve returned %v, want ErrDiskSpaceLow", err)
+	}
+	if _, err := w.LogCommit(running); err != nil {
+		t.Fatalf("LogCommit of a running transaction failed: %v", err)
+	}
+
+	// Transactions begin again once the space is back
+	free.Store(1 << 20)
+	waitFor(t, func() bool { return !w.DiskSpaceLow() }, "disk space to be reported back")
+	logTransactions(t, w, 3, 4)
+
+	// Removing the reserve stops the refusals at once
+	free.Store(100)
+	waitFor(t, w.DiskSpaceLow, "disk space to be reported low")
+	if err := w.SetDiskReserve(DiskReserve{}); err != nil {
+		t.Fatalf("SetDiskReserve failed: %v", err)
+	}
+	logTransactions(t, w, 4, 5)
+}
+
+func TestWAL_DiskReserveValidate(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	for _, reserve := range []DiskReserve{{MinFreeBytes: -1}, {MinFreeBytes: 1, CheckInterval: -time.Second}} {
+		if err := w.SetDiskReserve(reserve); err == nil {
+			t.Errorf("SetDiskReserve(%+v) succeeded", reserve)
+		}
+	}
+
+	w.disk.freeSpace = func(string) (int64, error) { return 0, vfs.ErrFreeSpaceUnsupported }
+	if err := w.SetDiskReserve(DiskReserve{MinFreeBytes: 1}); !errors.Is(err, vfs.ErrFreeSpaceUnsupported) {
+		t.Errorf("SetDiskReserve without free space support returned %v", err)
+	}
+}
+
+// waitFor polls cond until it holds, failing after a few seconds.
+func waitFor(t *testing.T, cond func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,132 @@
+package wal
+
+import (
+	"storemy/pkg/primitives"
+	"strings"
+	"time"
+)
+
+// Durability controls whether LogCommit waits for the commit record to reach
+// the log file before it returns, and whether the log is synced at all.
+type Durability uint8
+
+const (
+	// DurabilitySync makes LogCommit return only once the commit record is
+	// flushed, so every acknowledged commit survives a crash.
+	DurabilitySync Durability = iota
+
+	// DurabilityAsync lets LogCommit return as soon as the commit record is
+	// buffered. The record reaches the log file with the next flush (a later
+	// synchronous commit, a page write, a full buffer or Close); a crash
+	// before then loses the transaction as if it had never committed.
+	DurabilityAsync
+
+	// DurabilityInterval lets LogCommit return as soon as the commit record
+	// is buffered, like DurabilityAsync, and flushes the log in the
+	// background every sync interval (see SetSyncInterval). A crash loses
+	// at most the commits of the last interval.
+	DurabilityInterval
+
+	// DurabilityNoSync makes LogCommit write the commit record to the log
+	// file but never syncs the log, leaving it to the operating system to
+	// reach the disk. Commits survive a crash of the database process but
+	// not of the machine. It is meant for tests and bulk loads that can be
+	// redone; under vfs.SyncOSync every write is still synchronous, so it
+	// pays off with fsync or fdatasync.
+	DurabilityNoSync
+)
+
+const (
+	// DefaultSyncInterval is the sync interval of a new WAL.
+	DefaultSyncInterval = time.Second
+
+	// MaxSyncInterval is the longest sync interval SetSyncInterval accepts.
+	MaxSyncInterval = time.Minute
+)
+
+func (d Durability) String() string {
+	switch d {
+	case DurabilitySync:
+		return "sync"
+	case DurabilityAsync:
+		return "async"
+	case DurabilityInterval:
+		return "interval"
+	case DurabilityNoSync:
+		return "nosync"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseDurability parses a durability level name as returned by
+// Durability.String, ignoring case.
+func ParseDurability(s string) (Durability, error) {
+	switch strings.ToLower(s) {
+	case "sync":
+		return DurabilitySync, nil
+	case "async":
+		return DurabilityAsync, nil
+	case "interval":
+		return DurabilityInterval, nil
+	case "nosync":
+		return DurabilityNoSync, nil
+	default:
+		return 0, newConfigError("unknown durability level %q (expected sync, async, interval or nosync)", s)
+	}
+}
+
+// SetDurability sets the durability level used by later commits. It can be
+// changed at any time: leaving DurabilityNoSync first syncs what was written
+// without a sync, so every record below the flushed LSN is durable again, and
+// the background flushes of DurabilityInterval start and stop with it.
+func (w *WAL) SetDurability(d Durability) error {
+	if d > DurabilityNoSync {
+		return newConfigError("unknown durability level %d", d)
+	}
+
+	w.mutex.Lock()
+	if w.durability == DurabilityNoSync && d != DurabilityNoSync {
+		if err := w.writer.syncUnsynced(); err != nil {
+			w.mutex.Unlock()
+			return newIOError(err, "failed to sync WAL")
+		}
+	}
+	w.durability = d
+	w.writer.noSync = d == DurabilityNoSync
+	w.mutex.Unlock()
+
+	w.restartIntervalSync()
+	return nil
+}
+
+// Durability returns the durability level used by commits.
+func (w *WAL) Durability() Durability {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.durability
+}
+
+// IsDurable reports whether the record at lsn has reached the log file.
+// LSNs are the offsets where records start, so that is once the flushed LSN
+// is past it.
+func (w *WAL) IsDurable(lsn primitives.LSN) bool {
+	return w.FlushedLSN() > lsn
+}
+
+// WaitForDurability returns once the record at lsn has reached the log file,
+// forcing the log if the record is still buffered. A caller holding the LSN
+// of a commit acknowledged under DurabilityAsync uses it to learn that the
+// commit is durable.
+func (w *WAL) WaitForDurability(lsn primitives.LSN) error {
+	if w.IsDurable(lsn) {
+		return nil
+	}
+	if err := w.Force(lsn); err != nil {
+		return err
+	}
+	if !w.IsDurable(lsn) {
+		return newLSNOutOfRangeError(lsn)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package wal
+
+import (
+	"encoding/binary"
+	"storemy/pkg/encryption"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+// sealedRecordSize is the size of a record of size bytes once sealed.
+func sealedRecordSize(size int) int {
+	return size + record.TypeSize + encryption.Overhead
+}
+
+// sealRecord encrypts a serialized record that will be written at lsn:
+//
+//	[Size:4][EncryptedRecord:1][Sealed Type, TID, ... of the record]
+//
+// The size stays readable so the log can be scanned, and the LSN is
+// authenticated with the record, so a record cannot be moved within the log.
+func sealRecord(c *encryption.Cipher, data []byte, lsn primitives.LSN) ([]byte, error) {
+	size := sealedRecordSize(len(data))
+	sealed := make([]byte, record.RecordSize+record.TypeSize, size)
+	binary.BigEndian.PutUint32(sealed, uint32(size))
+	sealed[record.RecordSize] = byte(record.EncryptedRecord)
+
+	sealed, err := c.Seal(sealed, data[record.RecordSize:], lsnAD(lsn))
+	if err != nil {
+		return nil, newIOError(err, "failed to encrypt log record")
+	}
+	return sealed, nil
+}
+
+// openRecord decrypts a record sealed by sealRecord at lsn and returns it
+// serialized as it was before sealing.
+func openRecord(c *encryption.Cipher, data []byte, lsn primitives.LSN) ([]byte, error) {
+	if c == nil {
+		return nil, newWALError(dberror.ErrCategorySystem, ErrCodeKeyMissing, nil, "record at LSN %d is encrypted and no key provider was given", lsn)
+	}
+
+	header := record.RecordSize + record.TypeSize
+	size := len(data) - record.TypeSize - encryption.Overhead
+	if size < record.RecordSize {
+		return nil, newCorruptError(nil, "encrypted record at LSN %d is too short", lsn)
+	}
+
+	opened := make([]byte, record.RecordSize, size)
+	binary.BigEndian.PutUint32(opened, uint32(size))
+	opened, err := c.Open(opened, data[header:], lsnAD(lsn))
+	if err != nil {
+		return nil, newCorruptError(err, "failed to decrypt record at LSN %d", lsn)
+	}
+	return opened, nil
+}
+
+// isSealed reports whether serialized record data was written by sealRecord.
+func isSealed(data []byte) bool {
+	return len(data) > record.RecordSize && record.LogRecordType(data[record.RecordSize]) == record.EncryptedRecord
+}
+
+func lsnAD(lsn primitives.LSN) []byte {
+	var ad [8]byte
+	binary.BigEndian.PutUint64(ad[:], uint64(lsn))
+	return ad[:]
+}
@@ -0,0 +1,86 @@
+package wal
+
+import (
+	"bytes"
+	"storemy/pkg/encryption"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"strings"
+	"testing"
+)
+
+func TestWAL_EncryptedRecords(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	keys := encryption.NewStaticKeys(1, bytes.Repeat([]byte{1}, 32))
+	c := encryption.NewCipher(keys)
+
+	// A record written before encryption was enabled stays readable
+	plain, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	first := primitives.NewTransactionID()
+	if _, err := plain.LogBegin(first); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if err := plain.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	w, err := NewWALWithCipher(fsys, "/wal.log", 4096, vfs.SyncOSync, c)
+	if err != nil {
+		t.Fatalf("NewWALWithCipher failed: %v", err)
+	}
+	tid := primitives.NewTransactionID()
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	image := []byte("very secret tuple data")
+	if _, err := w.LogInsert(tid, &mockPageID{tableID: 1, pageNo: 2}, image); err != nil {
+		t.Fatalf("LogInsert failed: %v", err)
+	}
+	keys.Rotate(2, bytes.Repeat([]byte{2}, 32))
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+
+	raw, err := fsys.ReadFile("/wal.log")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if bytes.Contains(raw, image) {
+		t.Error("WAL file contains the plaintext image")
+	}
+
+	reader, err := w.OpenLogReader()
+	if err != nil {
+		t.Fatalf("OpenLogReader failed: %v", err)
+	}
+	defer reader.Close()
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	types := []record.LogRecordType{record.BeginRecord, record.BeginRecord, record.InsertRecord, record.CommitRecord}
+	if len(records) != len(types) {
+		t.Fatalf("read %d records, expected %d", len(records), len(types))
+	}
+	for i, rec := range records {
+		if rec.Type != types[i] {
+			t.Errorf("record %d has type %v, expected %v", i, rec.Type, types[i])
+		}
+	}
+	if !bytes.Equal(records[2].AfterImage, image) {
+		t.Errorf("after image = %q, expected %q", records[2].AfterImage, image)
+	}
+
+	noKey, err := NewLogReaderWithFS(fsys, "/wal.log")
+	if err != nil {
+		t.Fatalf("NewLogReaderWithFS failed: %v", err)
+	}
+	defer noKey.Close()
+	if _, err := noKey.ReadAll(); err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Errorf("expected reading without a key to fail, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/primitives"
+)
+
+// Error codes of the DBErrors returned by the WAL
+const (
+	// ErrCodeIO indicates reading, writing or syncing a log file failed
+	ErrCodeIO = "WAL_IO_ERROR"
+
+	// ErrCodeCorrupt indicates a log record or checkpoint failed its checks
+	ErrCodeCorrupt = "WAL_CORRUPT"
+
+	// ErrCodeReadOnly indicates a write to a WAL opened read-only
+	ErrCodeReadOnly = "WAL_READ_ONLY"
+
+	// ErrCodeDiskSpaceLow indicates a transaction was refused because the
+	// disk of the log is below its reserve
+	ErrCodeDiskSpaceLow = "DISK_SPACE_LOW"
+
+	// ErrCodeBackpressure indicates an append was refused because the log
+	// buffer was full and the WAL is set to BackpressureReject
+	ErrCodeBackpressure = "WAL_BACKPRESSURE"
+
+	// ErrCodeTxNotFound indicates a record was logged for a transaction that
+	// is not active
+	ErrCodeTxNotFound = "WAL_TX_NOT_FOUND"
+
+	// ErrCodeLSNOutOfRange indicates an LSN beyond the end of the log
+	ErrCodeLSNOutOfRange = "WAL_LSN_OUT_OF_RANGE"
+
+	// ErrCodeKeyMissing indicates an encrypted record was read without a key
+	ErrCodeKeyMissing = "WAL_KEY_MISSING"
+
+	// ErrCodeUnknownStandby indicates an acknowledgment from a standby that
+	// is not configured
+	ErrCodeUnknownStandby = "UNKNOWN_STANDBY"
+
+	// ErrCodeInvalidConfig indicates a WAL setting is out of range
+	ErrCodeInvalidConfig = "INVALID_WAL_CONFIG"
+
+	// ErrCodeCheckpoint indicates writing or reading a checkpoint failed
+	ErrCodeCheckpoint = "WAL_CHECKPOINT_FAILED"
+
+	// ErrCodeArchive indicates archiving the log before truncation failed
+	ErrCodeArchive = "WAL_ARCHIVE_FAILED"
+)
+
+// newWALError creates a DBError of the WAL whose message is formatted from
+// format and args, caused by cause if it is not nil.
+func newWALError(category dberror.ErrorCategory, code string, cause error, format string, args ...any) *dberror.DBError {
+	err := dberror.New(category, code, fmt.Sprintf(format, args...))
+	err.Cause = cause
+	err.Component = "WAL"
+	return err
+}
+
+// newIOError creates a DBError for a failed read, write or sync of the log.
+func newIOError(cause error, format string, args ...any) *dberror.DBError {
+	err := newWALError(dberror.ErrCategorySystem, ErrCodeIO, cause, format, args...)
+	err.Hint = "Check the disk and permissions of the WAL directory"
+	return err
+}
+
+// newCorruptError creates a DBError for a record that failed its checks,
+// caused by cause if it is not nil. It matches ErrCorruptRecord with
+// errors.Is.
+func newCorruptError(cause error, format string, args ...any) *dberror.DBError {
+	if cause == nil {
+		cause = ErrCorruptRecord
+	} else if !errors.Is(cause, ErrCorruptRecord) {
+		cause = fmt.Errorf("%w: %w", ErrCorruptRecord, cause)
+	}
+	err := newWALError(dberror.ErrCategoryData, ErrCodeCorrupt, cause, format, args...)
+	err.Hint = "The log may have been torn by a crash; Validate can truncate it at the first damaged record"
+	return err
+}
+
+// newReadOnlyError creates the DBError returned by operation on a WAL
+// opened read-only. It matches ErrReadOnly with errors.Is.
+func newReadOnlyError(operation string) *dberror.DBError {
+	err := newWALError(dberror.ErrCategoryUser, ErrCodeReadOnly, ErrReadOnly, "%s is not allowed on a read-only WAL", operation)
+	err.Operation = operation
+	return err
+}
+
+// newDiskSpaceLowError creates the DBError returned while the disk of the
+// log is below its reserve. It matches ErrDiskSpaceLow with errors.Is.
+func newDiskSpaceLowError(operation string) *dberror.DBError {
+	err := newWALError(dberror.ErrCategoryTransient, ErrCodeDiskSpaceLow, ErrDiskSpaceLow, "%s refused", operation)
+	err.Hint = "Free space on the disk of the WAL; transactions begin again automatically"
+	err.Operation = operation
+	return err
+}
+
+// newBackpressureError creates the DBError returned when operation is
+// refused because the log buffer is full. It matches ErrWALBackpressure with
+// errors.Is.
+func newBackpressureError(operation string) *dberror.DBError {
+	err := newWALError(dberror.ErrCategoryTransient, ErrCodeBackpressure, ErrWALBackpressure, "%s refused while the WAL buffer is flushed", operation)
+	err.Hint = "Retry after a short backoff, or raise the maximum size of the WAL buffer"
+	err.Operation = operation
+	return err
+}
+
+// newTxNotFoundError creates a DBError for a record logged for a transaction
+// that is not active.
+func newTxNotFoundError(tid *primitives.TransactionID) *dberror.DBError {
+	return newWALError(dberror.ErrCategorySystem, ErrCodeTxNotFound, nil, "transaction %v not found in active transactions", tid)
+}
+
+// newLSNOutOfRangeError creates a DBError for an LSN beyond the end of the log.
+func newLSNOutOfRangeError(lsn primitives.LSN) *dberror.DBError {
+	return newWALError(dberror.ErrCategoryUser, ErrCodeLSNOutOfRange, nil, "LSN %d is beyond the end of the log", lsn)
+}
+
+// newConfigError creates a DBError for a WAL setting that is out of range.
+func newConfigError(format string, args ...any) *dberror.DBError {
+	return newWALError(dberror.ErrCategoryUser, ErrCodeInvalidConfig, nil, format, args...)
+}
+
+// newCheckpointError creates a DBError for a checkpoint that could not be
+// written or read.
+func newCheckpointError(cause error, format string, args ...any) *dberror.DBError {
+	return newWALError(dberror.ErrCategorySystem, ErrCodeCheckpoint, cause, format, args...)
+}
+
+// newArchiveError creates a DBError for a log that could not be archived.
+func newArchiveError(cause error, format string, args ...any) *dberror.DBError {
+	err := newWALError(dberror.ErrCategorySystem, ErrCodeArchive, cause, format, args...)
+	err.Hint = "The log is kept until it can be archived; check the archive destination"
+	return err
+}
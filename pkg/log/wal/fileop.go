@@ -0,0 +1,84 @@
+package wal
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+// LogFileOp logs that tid will perform op once it commits. The operation
+// must not touch the file system before then: call FinishFileOp after the
+// transaction's COMMIT is durable, and nothing at all if it aborts.
+func (w *WAL) LogFileOp(tid *primitives.TransactionID, op record.FileOperation) (primitives.LSN, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	txnInfo, err := w.getTransactionInfo(tid)
+	if err != nil {
+		return 0, err
+	}
+
+	lsn, err := w.writeRecord(record.NewFileOpRecord(tid, op, txnInfo.LastLSN))
+	if err != nil {
+		return 0, err
+	}
+
+	txnInfo.LastLSN = lsn
+	w.pendingFileOps[lsn] = tid
+	return lsn, nil
+}
+
+// FinishFileOp performs the operation tid logged at intentLSN on the WAL's
+// file system, which holds the data files too, and logs that it is done.
+func (w *WAL) FinishFileOp(tid *primitives.TransactionID, intentLSN primitives.LSN, op record.FileOperation) error {
+	if w.readOnly {
+		return newReadOnlyError("FinishFileOp")
+	}
+	if err := op.Apply(w.fs); err != nil {
+		return err
+	}
+	if _, err := w.LogFileOpDone(tid, intentLSN); err != nil {
+		return newIOError(err, "failed to log completion of %s", op)
+	}
+	return nil
+}
+
+// LogFileOpDone logs that the operation tid logged at intentLSN has been
+// performed, or dropped because tid did not commit. The record is not
+// forced: if it is lost, recovery repeats the operation, which is harmless.
+func (w *WAL) LogFileOpDone(tid *primitives.TransactionID, intentLSN primitives.LSN) (primitives.LSN, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	lsn, err := w.writeRecord(record.NewFileOpDoneRecord(tid, intentLSN))
+	if err != nil {
+		return 0, err
+	}
+
+	delete(w.pendingFileOps, intentLSN)
+	return lsn, nil
+}
+
+// dropFileOps forgets the pending file operations of tid, which aborted. The
+// caller holds w.mutex.
+func (w *WAL) dropFileOps(tid *primitives.TransactionID) {
+	for lsn, owner := range w.pendingFileOps {
+		if owner == tid {
+			delete(w.pendingFileOps, lsn)
+		}
+	}
+}
+
+// oldestPendingFileOp returns the LSN of the oldest file operation announced
+// but not yet done, which truncation must keep for recovery to finish it.
+func (w *WAL) oldestPendingFileOp() (primitives.LSN, bool) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	oldest, found := primitives.LSN(0), false
+	for lsn := range w.pendingFileOps {
+		if !found || lsn < oldest {
+			oldest, found = lsn, true
+		}
+	}
+	return oldest, found
+}
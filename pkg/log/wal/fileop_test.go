@@ -0,0 +1,122 @@
+package wal
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"testing"
+)
+
+func TestFileOp_FinishAfterCommit(t *testing.T) {
+	w, fsys := newValidateTestWAL(t)
+	if err := fsys.WriteFile("/t.dat", []byte("heap"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	tid := primitives.NewTransactionIDFromValue(1)
+	op := record.FileOperation{Kind: record.FileOpDelete, Path: "/t.dat"}
+
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	intentLSN, err := w.LogFileOp(tid, op)
+	if err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+	if _, err := fsys.Stat("/t.dat"); err != nil {
+		t.Fatalf("expected the file to survive until commit, got %v", err)
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if oldest, ok := w.oldestPendingFileOp(); !ok || oldest != intentLSN {
+		t.Fatalf("expected the operation at %d to be pending, got %d (%v)", intentLSN, oldest, ok)
+	}
+
+	if err := w.FinishFileOp(tid, intentLSN, op); err != nil {
+		t.Fatalf("FinishFileOp failed: %v", err)
+	}
+	if _, err := fsys.Stat("/t.dat"); err == nil {
+		t.Error("expected the file to be deleted")
+	}
+	if _, ok := w.oldestPendingFileOp(); ok {
+		t.Error("expected no pending file operation after it finished")
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.OK() || report.Records != 4 {
+		t.Errorf("expected 4 clean records, got %d records and issues %v", report.Records, report.Issues)
+	}
+}
+
+func TestFileOp_AbortDropsOperation(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogFileOp(tid, record.FileOperation{Kind: record.FileOpDelete, Path: "/t.dat"}); err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+	if _, err := w.LogAbort(tid); err != nil {
+		t.Fatalf("LogAbort failed: %v", err)
+	}
+
+	if _, ok := w.oldestPendingFileOp(); ok {
+		t.Error("expected the aborted transaction's file operation to be dropped")
+	}
+}
+
+func TestFileOp_RequiresActiveTransaction(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+
+	if _, err := w.LogFileOp(tid, record.FileOperation{Kind: record.FileOpDelete, Path: "/t.dat"}); err == nil {
+		t.Error("expected LogFileOp to fail for a transaction that has not begun")
+	}
+}
+
+func TestFileOp_DoneForUnknownOperation(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+
+	beginLSN, err := w.LogBegin(tid)
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	doneLSN, err := w.LogFileOpDone(tid, beginLSN)
+	if err != nil {
+		t.Fatalf("LogFileOpDone failed: %v", err)
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Kind != IssueBrokenPrevLSN || report.Issues[0].LSN != doneLSN {
+		t.Errorf("expected a broken PrevLSN at %d, got %v", doneLSN, report.Issues)
+	}
+}
+
+func TestFileOp_TruncationKeepsPendingOperation(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	intentLSN, err := w.LogFileOp(tid, record.FileOperation{Kind: record.FileOpDelete, Path: "/t.dat"})
+	if err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+
+	checkpoint := &record.CheckpointRecord{LSN: intentLSN + 1<<20}
+	if got := w.calculateTruncationPoint(checkpoint); got > intentLSN {
+		t.Errorf("expected truncation to stop at or before %d, got %d", intentLSN, got)
+	}
+}
@@ -0,0 +1,71 @@
+package wal
+
+import (
+	"storemy/pkg/primitives"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLogCommit_GroupCommitSharesSyncs(t *testing.T) {
+	wal, _, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	if err := wal.SetCommitWindow(20 * time.Millisecond); err != nil {
+		t.Fatalf("SetCommitWindow failed: %v", err)
+	}
+	var syncs atomic.Int32
+	syncLog := wal.writer.sync
+	wal.writer.sync = func() error {
+		syncs.Add(1)
+		return syncLog()
+	}
+
+	const committers = 8
+	tids := make([]*primitives.TransactionID, committers)
+	for i := range tids {
+		tids[i] = primitives.NewTransactionID()
+		if _, err := wal.LogBegin(tids[i]); err != nil {
+			t.Fatalf("LogBegin failed: %v", err)
+		}
+	}
+
+	lsns := make([]primitives.LSN, committers)
+	var wg sync.WaitGroup
+	for i, tid := range tids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lsn, err := wal.LogCommit(tid)
+			if err != nil {
+				t.Errorf("LogCommit failed: %v", err)
+			}
+			lsns[i] = lsn
+		}()
+	}
+	wg.Wait()
+
+	for _, lsn := range lsns {
+		if !wal.IsDurable(lsn) {
+			t.Errorf("commit at %d not durable, flushed to %d", lsn, wal.FlushedLSN())
+		}
+	}
+	if n := syncs.Load(); n >= committers {
+		t.Errorf("%d commits took %d syncs, expected them to share", committers, n)
+	}
+}
+
+func TestSetCommitWindow_RejectsOutOfRange(t *testing.T) {
+	wal, _, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	for _, window := range []time.Duration{-time.Millisecond, MaxCommitWindow + 1} {
+		if err := wal.SetCommitWindow(window); err == nil {
+			t.Errorf("expected SetCommitWindow(%s) to fail", window)
+		}
+	}
+	if wal.CommitWindow() != 0 {
+		t.Errorf("expected the window to stay 0, got %s", wal.CommitWindow())
+	}
+}
@@ -0,0 +1,45 @@
+package wal
+
+import (
+	"io"
+	"storemy/pkg/log/record"
+)
+
+// ReadHistory calls fn for every record in the log, in LSN order, up to the
+// end of the log at the time of the call. Buffered records are flushed
+// first, so the records of every transaction that has committed are read.
+// Records written while the log is read are not passed to fn.
+//
+// The log is read without holding the WAL lock, so transactions keep logging
+// while it runs. It stops at the first error fn returns.
+func (w *WAL) ReadHistory(fn func(*record.LogRecord) error) error {
+	w.mutex.Lock()
+	if !w.readOnly {
+		if err := w.writer.flush(); err != nil {
+			w.mutex.Unlock()
+			return newIOError(err, "failed to flush WAL before reading history")
+		}
+	}
+	end := int64(w.writer.FlushedLSN())
+	w.mutex.Unlock()
+
+	reader, err := w.OpenLogReader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for reader.offset < end {
+		rec, err := reader.ReadNext()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return newIOError(err, "failed to read WAL at LSN %d", reader.offset)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package wal
+
+import (
+	"sync"
+	"time"
+)
+
+// intervalSync is the background flusher of DurabilityInterval. It runs
+// only while the WAL uses that level, and is restarted whenever the level or
+// the interval changes.
+type intervalSync struct {
+	mu       sync.Mutex
+	interval time.Duration
+	closed   bool          // The WAL is closed, the flusher must not start again
+	stop     chan struct{} // Closed to stop the running flusher, nil if none
+	done     chan struct{} // Closed once the running flusher has returned
+}
+
+func newIntervalSync() *intervalSync {
+	return &intervalSync{interval: DefaultSyncInterval}
+}
+
+// SetSyncInterval sets how often the log is flushed under DurabilityInterval.
+// A shorter interval loses fewer commits in a crash at the cost of more
+// syncs. The interval is kept when the WAL switches to another level.
+func (w *WAL) SetSyncInterval(interval time.Duration) error {
+	if interval <= 0 || interval > MaxSyncInterval {
+		return newConfigError("sync interval must be greater than 0 and at most %s, got %s", MaxSyncInterval, interval)
+	}
+
+	w.syncer.mu.Lock()
+	w.syncer.interval = interval
+	w.syncer.mu.Unlock()
+
+	w.restartIntervalSync()
+	return nil
+}
+
+// SyncInterval returns how often the log is flushed under DurabilityInterval.
+func (w *WAL) SyncInterval() time.Duration {
+	w.syncer.mu.Lock()
+	defer w.syncer.mu.Unlock()
+	return w.syncer.interval
+}
+
+// restartIntervalSync stops the background flusher and starts it again with
+// the current interval if the WAL uses DurabilityInterval. It must not be
+// called with w.mutex held, since the flusher takes it.
+func (w *WAL) restartIntervalSync() {
+	s := w.syncer
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopFlusher()
+	if s.closed || w.readOnly || w.Durability() != DurabilityInterval {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go w.runIntervalSync(s.interval, s.stop, s.done)
+}
+
+// closeIntervalSync stops the background flusher for good.
+func (w *WAL) closeIntervalSync() {
+	s := w.syncer
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.stopFlusher()
+}
+
+// stopFlusher stops the running flusher and waits for it to return. It must
+// be called with s.mu held.
+func (s *intervalSync) stopFlusher() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop, s.done = nil, nil
+}
+
+// runIntervalSync flushes the buffered records every interval until stop is
+// closed.
+func (w *WAL) runIntervalSync(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := w.flushBuffered(); err != nil {
+				w.logger.Error("interval WAL flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// flushBuffered flushes the buffered records, if there are any.
+func (w *WAL) flushBuffered() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.writer.CurrentLSN() == w.writer.FlushedLSN() {
+		return nil
+	}
+	walForces.Inc()
+	return w.writer.flush()
+}
@@ -0,0 +1,53 @@
+package wal
+
+import (
+	"sort"
+	"sync"
+)
+
+// lsnIndexInterval is how many records apart the boundaries kept by an
+// lsnIndex are, bounding the headers Seek reads past the nearest one.
+const lsnIndexInterval = 64
+
+// lsnIndex is a sparse index of the record boundaries of a log file. It
+// keeps the offset of every lsnIndexInterval-th record, so it grows by 8
+// bytes per 64 records, and finds the boundary nearest an LSN by binary
+// search.
+//
+// A WAL keeps one index of its log file: the writer extends it as records
+// are appended, and the readers opened with OpenLogReader share it and
+// extend it over the records they read or seek past, so the boundaries of a
+// log that was already on disk when the WAL was opened are walked once. A
+// reader over any other file builds an index of its own.
+type lsnIndex struct {
+	mu      sync.Mutex
+	offsets []int64 // Offsets of records 0, lsnIndexInterval, 2*lsnIndexInterval, ...
+	count   int     // Records indexed so far
+	end     int64   // Offset just past the last indexed record
+}
+
+// add indexes the record of length recLen at off, if it is the record right
+// after the last indexed one.
+func (idx *lsnIndex) add(off int64, recLen uint32) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if off != idx.end {
+		return
+	}
+	if idx.count%lsnIndexInterval == 0 {
+		idx.offsets = append(idx.offsets, off)
+	}
+	idx.count++
+	idx.end = off + int64(recLen)
+}
+
+// nearest returns the last known record boundary at or before off.
+func (idx *lsnIndex) nearest(off int64) int64 {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if off >= idx.end {
+		return idx.end
+	}
+	i := sort.Search(len(idx.offsets), func(i int) bool { return idx.offsets[i] > off })
+	return idx.offsets[i-1]
+}
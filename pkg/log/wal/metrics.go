@@ -0,0 +1,77 @@
+package wal
+
+import "storemy/pkg/metrics"
+
+var (
+	walBytesWritten = metrics.NewCounter(
+		"storemy_wal_bytes_written_total",
+		"Bytes of serialized log records appended to the WAL",
+	)
+	walRecordsWritten = metrics.NewCounter(
+		"storemy_wal_records_written_total",
+		"Log records appended to the WAL",
+	)
+	walImageBytesSaved = metrics.NewCounter(
+		"storemy_wal_image_bytes_saved_total",
+		"Bytes compressing the page images of UPDATE records kept out of the WAL",
+	)
+	walFlushes = metrics.NewCounter(
+		"storemy_wal_flushes_total",
+		"Writes of buffered WAL data to the log file",
+	)
+	walForces = metrics.NewCounter(
+		"storemy_wal_forces_total",
+		"Force requests made to guarantee log durability (e.g. at commit)",
+	)
+	walBufferGrowths = metrics.NewCounter(
+		"storemy_wal_buffer_growths_total",
+		"Times the WAL buffer grew instead of being flushed when an append found it full",
+	)
+	walBufferStalls = metrics.NewCounter(
+		"storemy_wal_buffer_stalls_total",
+		"Appends that had to flush the full WAL buffer themselves",
+	)
+	walBufferStallSeconds = metrics.NewHistogram(
+		"storemy_wal_buffer_stall_seconds",
+		"Time appends spent flushing the full WAL buffer",
+		metrics.DefaultLatencyBuckets,
+	)
+	walBackpressureRejections = metrics.NewCounter(
+		"storemy_wal_backpressure_rejections_total",
+		"Appends refused with ErrWALBackpressure because the WAL buffer was full",
+	)
+	walGroupCommitSize = metrics.NewHistogram(
+		"storemy_wal_group_commit_size",
+		"Commits made durable by a single group commit log force",
+		[]float64{1, 2, 4, 8, 16, 32, 64, 128},
+	)
+	walArchivedFiles = metrics.NewCounter(
+		"storemy_wal_archived_files_total",
+		"Copies of the WAL archived before truncation",
+	)
+	walArchiveFailures = metrics.NewCounter(
+		"storemy_wal_archive_failures_total",
+		"WAL archive attempts that failed, leaving the WAL untruncated",
+	)
+	walDiskFreeBytes = metrics.NewGauge(
+		"storemy_wal_disk_free_bytes",
+		"Free space of the file system holding the WAL at the last check",
+	)
+	walSubscriptions = metrics.NewGauge(
+		"storemy_wal_subscriptions",
+		"Consumers following the records flushed to the WAL",
+	)
+	replicationWaitSeconds = metrics.NewHistogram(
+		"storemy_wal_replication_wait_seconds",
+		"Time commits waited for a quorum of standbys to acknowledge them",
+		metrics.DefaultLatencyBuckets,
+	)
+	replicationDemotions = metrics.NewCounter(
+		"storemy_wal_replication_demotions_total",
+		"Times synchronous replication was demoted to asynchronous after a commit timed out",
+	)
+	replicationLagBytes = metrics.NewGauge(
+		"storemy_wal_replication_lag_bytes",
+		"Flushed WAL bytes not yet acknowledged by the standby furthest behind",
+	)
+)
@@ -2,39 +2,55 @@ package wal
 
 import (
 	"encoding/binary"
-	"fmt"
+	"errors"
 	"io"
-	"os"
+	"storemy/pkg/encryption"
 	"storemy/pkg/log/record"
 	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
 )
 
 const (
 	MaxLogRecordSize = 10 * 1024 * 1024 // 10 MB max record size
 )
 
+// ErrCorruptRecord is returned by ReadNext for a record that is torn, fails
+// its checksum or cannot be decoded. The reader cannot tell where the next
+// record starts, so the log is unreadable from that record on.
+var ErrCorruptRecord = record.ErrCorruptRecord
+
 // LogReader reads and deserializes log records from a WAL file
-// It provides sequential access to all records in the log
+// It provides sequential access to all records in the log, starting at the
+// beginning or at the LSN given to Seek
 type LogReader struct {
-	file   *os.File
+	file   vfs.File
 	offset int64
+	cipher *encryption.Cipher // Opens encrypted records, nil if none are expected
+	index  *lsnIndex          // Record boundaries seen so far, for Seek
 }
 
 // NewLogReader creates a new log reader for the specified file
 func NewLogReader(logPath string) (*LogReader, error) {
-	file, err := os.Open(logPath)
+	return NewLogReaderWithFS(vfs.OS, logPath)
+}
+
+// NewLogReaderWithFS creates a log reader for a WAL file stored on fsys.
+func NewLogReaderWithFS(fsys vfs.FS, logPath string) (*LogReader, error) {
+	file, err := vfs.Open(fsys, logPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return nil, newIOError(err, "failed to open log file")
 	}
 
 	return &LogReader{
 		file:   file,
 		offset: 0,
+		index:  &lsnIndex{},
 	}, nil
 }
 
 // ReadNext reads the next log record from the file
-// Returns nil when EOF is reached
+// Returns io.EOF when the end of the file is reached, and an error wrapping
+// ErrCorruptRecord when the next record is not valid
 func (lr *LogReader) ReadNext() (*record.LogRecord, error) {
 	recLen, err := readHeader(lr.file, lr.offset)
 	if err != nil {
@@ -43,23 +59,63 @@ func (lr *LogReader) ReadNext() (*record.LogRecord, error) {
 
 	recordBuf, err := readRecordBytes(lr.file, int64(recLen-record.RecordSize), lr.offset+record.RecordSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read record bytes at offset %d: %w", lr.offset, err)
+		return nil, newIOError(err, "failed to read record bytes at offset %d", lr.offset)
 	}
 
 	fullRecord := make([]byte, recLen)
 	binary.BigEndian.PutUint32(fullRecord[0:record.RecordSize], recLen)
 	copy(fullRecord[record.RecordSize:], recordBuf)
 
+	if isSealed(fullRecord) {
+		if fullRecord, err = openRecord(lr.cipher, fullRecord, primitives.LSN(lr.offset)); err != nil {
+			return nil, err
+		}
+	}
+
 	rec, err := record.DeserializeLogRecord(fullRecord)
 	if err != nil {
-		return nil, fmt.Errorf("failed to deserialize record at offset %d: %w", lr.offset, err)
+		return nil, newCorruptError(err, "failed to deserialize record at offset %d", lr.offset)
 	}
 
 	rec.LSN = primitives.LSN(lr.offset)
+	lr.index.add(lr.offset, recLen)
 	lr.offset += int64(recLen)
 	return rec, nil
 }
 
+// Seek positions the reader so that ReadNext returns the record at lsn, or
+// the first record after it if lsn falls inside a record. Seeking past the
+// last record leaves the reader at the end of the log, and seeking past a
+// corrupt record stops at it so that ReadNext reports it.
+//
+// LSNs are byte offsets into the log file, but a record can only be read
+// from its start, which only the lengths of the records before it tell.
+// Seek finds the nearest record boundary it has indexed at or before lsn and
+// walks the record headers from there, indexing the boundaries it passes,
+// so seeking back and forth reads few headers and no record bodies.
+func (lr *LogReader) Seek(lsn primitives.LSN) error {
+	off := lr.index.nearest(int64(lsn))
+	for off < int64(lsn) {
+		recLen, err := readHeader(lr.file, off)
+		if err == io.EOF || errors.Is(err, ErrCorruptRecord) {
+			break
+		}
+		if err != nil {
+			return newIOError(err, "failed to seek to LSN %d", lsn)
+		}
+		lr.index.add(off, recLen)
+		off += int64(recLen)
+	}
+	lr.offset = off
+	return nil
+}
+
+// SetCipher sets the cipher that opens the records of an encrypted log.
+// Without one, reading an encrypted record fails.
+func (lr *LogReader) SetCipher(c *encryption.Cipher) {
+	lr.cipher = c
+}
+
 // ReadAll reads all log records from the file
 func (lr *LogReader) ReadAll() ([]*record.LogRecord, error) {
 	var records []*record.LogRecord
@@ -78,7 +134,8 @@ func (lr *LogReader) ReadAll() ([]*record.LogRecord, error) {
 	return records, nil
 }
 
-// Reset resets the reader to the beginning of the file
+// Reset resets the reader to the beginning of the file, keeping the record
+// boundaries indexed for Seek
 func (lr *LogReader) Reset() error {
 	lr.offset = 0
 	return nil
@@ -101,7 +158,7 @@ func (lr *LogReader) GetFileSize() (int64, error) {
 	return stat.Size(), nil
 }
 
-func readHeader(file *os.File, offset int64) (uint32, error) {
+func readHeader(file io.ReaderAt, offset int64) (uint32, error) {
 	sizeBuf := make([]byte, record.RecordSize)
 	n, err := file.ReadAt(sizeBuf, offset)
 	if err == io.EOF || n == 0 {
@@ -109,25 +166,25 @@ func readHeader(file *os.File, offset int64) (uint32, error) {
 	}
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to read record size: %w", err)
+		return 0, newIOError(err, "failed to read record size")
 	}
 
 	recordSize := binary.BigEndian.Uint32(sizeBuf)
-	if recordSize == 0 || recordSize > MaxLogRecordSize { // Sanity check: max 10MB per record
-		return 0, fmt.Errorf("invalid record size: %d at offset %d", recordSize, offset)
+	if recordSize < record.RecordSize || recordSize > MaxLogRecordSize { // Sanity check: max 10MB per record
+		return 0, newCorruptError(nil, "invalid record size: %d at offset %d", recordSize, offset)
 	}
 
 	return recordSize, nil
 }
 
-func readRecordBytes(file *os.File, size, offset int64) ([]byte, error) {
+func readRecordBytes(file io.ReaderAt, size, offset int64) ([]byte, error) {
 	recordBuf := make([]byte, size)
 	n, err := file.ReadAt(recordBuf, offset)
 	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("failed to read record data: %w", err)
+		return nil, newIOError(err, "failed to read record data")
 	}
 	if n != int(size) {
-		return nil, fmt.Errorf("incomplete record: expected %d bytes, got %d", size, n)
+		return nil, newCorruptError(nil, "incomplete record: expected %d bytes, got %d", size, n)
 	}
 
 	return recordBuf, nil
@@ -5,8 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"storemy/pkg/log/record"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
 	"testing"
 )
 
@@ -708,3 +710,133 @@ func TestLogReader_LSNAssignment(t *testing.T) {
 		}
 	}
 }
+
+// TestLogReader_Seek tests seeking to record boundaries, into records and
+// past the end of the log, back and forth across the sparse index
+func TestLogReader_Seek(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "seek.log")
+	file, err := os.Create(logPath)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var lsns []primitives.LSN
+	offset := int64(0)
+	for i := 0; i < 3*lsnIndexInterval+5; i++ {
+		tid := primitives.NewTransactionIDFromValue(int64(i + 1))
+		serialized, err := record.SerializeLogRecord(record.NewLogRecord(record.BeginRecord, tid, nil, nil, nil, FirstLSN))
+		if err != nil {
+			t.Fatalf("failed to serialize record: %v", err)
+		}
+		if _, err := file.Write(serialized); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+		lsns = append(lsns, primitives.LSN(offset))
+		offset += int64(len(serialized))
+	}
+	file.Close()
+
+	reader, err := NewLogReader(logPath)
+	if err != nil {
+		t.Fatalf("NewLogReader failed: %v", err)
+	}
+	defer reader.Close()
+
+	for _, i := range []int{150, 3, lsnIndexInterval, len(lsns) - 1, 0, 2*lsnIndexInterval + 1} {
+		if err := reader.Seek(lsns[i]); err != nil {
+			t.Fatalf("Seek(%d) failed: %v", lsns[i], err)
+		}
+		rec, err := reader.ReadNext()
+		if err != nil {
+			t.Fatalf("ReadNext after Seek(%d) failed: %v", lsns[i], err)
+		}
+		if rec.LSN != lsns[i] || rec.TID.ID() != int64(i+1) {
+			t.Errorf("Seek(%d) read record at %d of transaction %d, want record %d", lsns[i], rec.LSN, rec.TID.ID(), i)
+		}
+	}
+
+	// Inside a record: the next record
+	if err := reader.Seek(lsns[10] + 1); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	if rec, err := reader.ReadNext(); err != nil || rec.LSN != lsns[11] {
+		t.Errorf("Seek into record 10 read %v, %v, want record 11", rec, err)
+	}
+
+	// Past the end: end of log
+	if err := reader.Seek(primitives.LSN(offset + 100)); err != nil {
+		t.Fatalf("Seek past the end failed: %v", err)
+	}
+	if _, err := reader.ReadNext(); err != io.EOF {
+		t.Errorf("ReadNext after seeking past the end = %v, want io.EOF", err)
+	}
+	if got := len(reader.index.offsets); got != 4 {
+		t.Errorf("index holds %d boundaries, want 4", got)
+	}
+}
+
+// TestWAL_ReadersShareLSNIndex tests that the records appended to a WAL are
+// indexed as they are written, that its readers seek through that index, and
+// that truncation rebuilds it for the new file
+func TestWAL_ReadersShareLSNIndex(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	tid := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	var lsns []primitives.LSN
+	for i := range 3 * lsnIndexInterval {
+		lsn, err := w.LogInsert(tid, &mockPageID{tableID: 1, pageNo: primitives.PageNumber(i)}, []byte("tuple"))
+		if err != nil {
+			t.Fatalf("LogInsert failed: %v", err)
+		}
+		lsns = append(lsns, lsn)
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if got := w.index.count; got != len(lsns)+2 {
+		t.Fatalf("index holds %d records after the appends, want %d", got, len(lsns)+2)
+	}
+
+	reader, err := w.OpenLogReader()
+	if err != nil {
+		t.Fatalf("OpenLogReader failed: %v", err)
+	}
+	defer reader.Close()
+	if reader.index != w.index {
+		t.Fatal("expected the reader to share the WAL's index")
+	}
+	for _, i := range []int{len(lsns) - 1, 0, lsnIndexInterval + 7} {
+		if err := reader.Seek(lsns[i]); err != nil {
+			t.Fatalf("Seek(%d) failed: %v", lsns[i], err)
+		}
+		rec, err := reader.ReadNext()
+		if err != nil || rec.LSN != lsns[i] {
+			t.Errorf("Seek(%d) read %v, %v", lsns[i], rec, err)
+		}
+	}
+
+	cut := lsns[lsnIndexInterval]
+	if err := w.performTruncation(cut); err != nil {
+		t.Fatalf("performTruncation failed: %v", err)
+	}
+	reader, err = w.OpenLogReader()
+	if err != nil {
+		t.Fatalf("OpenLogReader failed: %v", err)
+	}
+	defer reader.Close()
+	last := lsns[len(lsns)-1] - cut
+	if err := reader.Seek(last); err != nil {
+		t.Fatalf("Seek(%d) failed: %v", last, err)
+	}
+	if rec, err := reader.ReadNext(); err != nil || rec.LSN != last || rec.Type != record.InsertRecord {
+		t.Errorf("Seek(%d) after truncation read %v, %v", last, rec, err)
+	}
+}
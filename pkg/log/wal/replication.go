@@ -0,0 +1,319 @@
+package wal
+
+import (
+	"slices"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/primitives"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReplicationTimeout is how long a commit waits for its standbys when
+// ReplicationConfig.Timeout is zero.
+const DefaultReplicationTimeout = 10 * time.Second
+
+// AckLevel is how far a standby must have gotten with a commit record for
+// its acknowledgment to count toward the quorum.
+type AckLevel uint8
+
+const (
+	// AckReceived counts a standby once it has stored the record.
+	AckReceived AckLevel = iota
+
+	// AckApplied counts a standby once it has applied the record, so a read
+	// on the standby sees the commit.
+	AckApplied
+)
+
+func (l AckLevel) String() string {
+	switch l {
+	case AckReceived:
+		return "received"
+	case AckApplied:
+		return "applied"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseAckLevel parses an acknowledgment level name as returned by
+// AckLevel.String, ignoring case.
+func ParseAckLevel(s string) (AckLevel, error) {
+	switch strings.ToLower(s) {
+	case "received":
+		return AckReceived, nil
+	case "applied":
+		return AckApplied, nil
+	default:
+		return 0, newConfigError("unknown acknowledgment level %q (expected received or applied)", s)
+	}
+}
+
+// ReplicationConfig configures synchronous replication. The WAL does not
+// ship records itself: whatever copies the log to the standbys reports their
+// progress with AcknowledgeStandby, and commits wait for it.
+type ReplicationConfig struct {
+	// Standbys names the standbys that count toward the quorum.
+	Standbys []string
+
+	// Quorum is how many of the standbys must acknowledge a commit record
+	// before LogCommit returns. Zero disables synchronous replication.
+	Quorum int
+
+	// Level is the acknowledgment level that counts (see AckLevel).
+	Level AckLevel
+
+	// Timeout bounds how long a commit waits for the quorum. When it passes,
+	// replication is demoted to asynchronous: the commit returns, and later
+	// commits stop waiting until the quorum acknowledges the commit that
+	// timed out. Zero uses DefaultReplicationTimeout.
+	Timeout time.Duration
+}
+
+// Enabled reports whether commits wait for standbys.
+func (c ReplicationConfig) Enabled() bool {
+	return c.Quorum > 0
+}
+
+// Validate checks that the quorum can be reached by the configured standbys.
+func (c ReplicationConfig) Validate() error {
+	if c.Quorum < 0 {
+		return newConfigError("replication quorum must not be negative, got %d", c.Quorum)
+	}
+	if c.Quorum > len(c.Standbys) {
+		return newConfigError("replication quorum %d exceeds the %d configured standbys", c.Quorum, len(c.Standbys))
+	}
+	if c.Level != AckReceived && c.Level != AckApplied {
+		return newConfigError("unknown acknowledgment level %d", c.Level)
+	}
+	if c.Timeout < 0 {
+		return newConfigError("replication timeout must not be negative, got %v", c.Timeout)
+	}
+	for i, name := range c.Standbys {
+		if name == "" {
+			return newConfigError("standby %d has no name", i)
+		}
+		if slices.Contains(c.Standbys[:i], name) {
+			return newConfigError("standby %s is listed twice", name)
+		}
+	}
+	return nil
+}
+
+func (c ReplicationConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultReplicationTimeout
+	}
+	return c.Timeout
+}
+
+// StandbyStatus is the replication progress of one standby.
+type StandbyStatus struct {
+	Name        string
+	ReceivedLSN primitives.LSN // Records before this LSN are stored on the standby
+	AppliedLSN  primitives.LSN // Records before this LSN are applied on the standby
+	LagBytes    int64          // Flushed log the standby has not acknowledged at the configured level
+	LastAck     time.Time      // Zero if the standby never acknowledged
+}
+
+// ReplicationStats is a snapshot of synchronous replication.
+type ReplicationStats struct {
+	Config   ReplicationConfig
+	Demoted  bool // Commits do not wait because the quorum timed out
+	Standbys []StandbyStatus
+}
+
+// replication is the state of synchronous replication of a WAL. It has its
+// own lock, so standbys acknowledge without contending with transactions
+// logging.
+type replication struct {
+	mutex     sync.Mutex
+	config    ReplicationConfig
+	standbys  map[string]*StandbyStatus
+	demoted   bool
+	demotedAt primitives.LSN // Commit whose timeout demoted replication
+	changed   chan struct{}  // Closed and replaced whenever a commit may stop waiting
+}
+
+// SetReplication configures synchronous replication for later commits. The
+// progress of standbys already known is kept.
+func (w *WAL) SetReplication(config ReplicationConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	config.Standbys = slices.Clone(config.Standbys)
+
+	r := &w.replication
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	standbys := make(map[string]*StandbyStatus, len(config.Standbys))
+	for _, name := range config.Standbys {
+		if s, ok := r.standbys[name]; ok {
+			standbys[name] = s
+		} else {
+			standbys[name] = &StandbyStatus{Name: name}
+		}
+	}
+	r.config = config
+	r.standbys = standbys
+	r.demoted = false
+	r.notify()
+	return nil
+}
+
+// Replication returns the synchronous replication configuration.
+func (w *WAL) Replication() ReplicationConfig {
+	r := &w.replication
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.config
+}
+
+// AcknowledgeStandby records that the named standby has every record before
+// lsn at level. Applied records are also received. Acknowledgments never
+// move a standby backwards, so they may arrive out of order.
+func (w *WAL) AcknowledgeStandby(name string, level AckLevel, lsn primitives.LSN) error {
+	r := &w.replication
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s, ok := r.standbys[name]
+	if !ok {
+		return newWALError(dberror.ErrCategoryUser, ErrCodeUnknownStandby, nil, "unknown standby %q", name)
+	}
+
+	s.ReceivedLSN = max(s.ReceivedLSN, lsn)
+	if level == AckApplied {
+		s.AppliedLSN = max(s.AppliedLSN, lsn)
+	}
+	s.LastAck = time.Now()
+
+	if r.demoted && r.quorumReached(r.demotedAt) {
+		r.demoted = false
+		w.logger.Info("synchronous replication restored", "lsn", r.demotedAt)
+	}
+	r.notify()
+	w.updateReplicationLag()
+	return nil
+}
+
+// ReplicationStats returns the progress of every configured standby.
+func (w *WAL) ReplicationStats() ReplicationStats {
+	flushed := w.FlushedLSN()
+
+	r := &w.replication
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stats := ReplicationStats{Config: r.config, Demoted: r.demoted}
+	for _, name := range r.config.Standbys {
+		s := *r.standbys[name]
+		s.LagBytes = lagBytes(flushed, r.position(&s))
+		stats.Standbys = append(stats.Standbys, s)
+	}
+	return stats
+}
+
+// waitForReplication returns once a quorum of standbys acknowledged the
+// record at lsn, or once the replication timeout passed, demoting
+// replication to asynchronous. It returns at once when replication is
+// disabled or demoted.
+func (w *WAL) waitForReplication(lsn primitives.LSN) {
+	r := &w.replication
+	r.mutex.Lock()
+	if !r.config.Enabled() || r.demoted {
+		r.mutex.Unlock()
+		return
+	}
+	timeout := r.config.timeout()
+	r.mutex.Unlock()
+
+	start := time.Now()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		r.mutex.Lock()
+		if !r.config.Enabled() || r.demoted || r.quorumReached(lsn) {
+			r.mutex.Unlock()
+			replicationWaitSeconds.Observe(time.Since(start).Seconds())
+			return
+		}
+		changed := r.changedChan()
+		r.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			r.mutex.Lock()
+			if r.config.Enabled() && !r.demoted && !r.quorumReached(lsn) {
+				r.demoted = true
+				r.demotedAt = lsn
+				r.notify()
+				replicationDemotions.Inc()
+				w.logger.Warn("standbys did not acknowledge commit in time, replication demoted to asynchronous",
+					"lsn", lsn, "quorum", r.config.Quorum, "timeout", timeout)
+			}
+			r.mutex.Unlock()
+			return
+		}
+	}
+}
+
+// quorumReached reports whether enough standbys acknowledged the record at
+// lsn. The caller must hold r.mutex.
+func (r *replication) quorumReached(lsn primitives.LSN) bool {
+	acked := 0
+	for _, s := range r.standbys {
+		if r.position(s) > lsn {
+			acked++
+		}
+	}
+	return acked >= r.config.Quorum
+}
+
+// position returns how far s got at the configured level.
+func (r *replication) position(s *StandbyStatus) primitives.LSN {
+	if r.config.Level == AckApplied {
+		return s.AppliedLSN
+	}
+	return s.ReceivedLSN
+}
+
+// changedChan returns the channel closed by the next notify. The caller must
+// hold r.mutex.
+func (r *replication) changedChan() chan struct{} {
+	if r.changed == nil {
+		r.changed = make(chan struct{})
+	}
+	return r.changed
+}
+
+// notify wakes every commit waiting for standbys. The caller must hold r.mutex.
+func (r *replication) notify() {
+	if r.changed != nil {
+		close(r.changed)
+		r.changed = nil
+	}
+}
+
+// updateReplicationLag sets the lag gauge to the lag of the standby furthest
+// behind. The caller must hold w.replication.mutex.
+func (w *WAL) updateReplicationLag() {
+	r := &w.replication
+	flushed := w.FlushedLSN()
+	var lag int64
+	for _, s := range r.standbys {
+		lag = max(lag, lagBytes(flushed, r.position(s)))
+	}
+	replicationLagBytes.Set(lag)
+}
+
+func lagBytes(flushed, acked primitives.LSN) int64 {
+	if acked >= flushed {
+		return 0
+	}
+	return int64(flushed - acked)
+}
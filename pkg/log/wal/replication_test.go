@@ -0,0 +1,189 @@
+package wal
+
+import (
+	"storemy/pkg/logging"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"testing"
+	"time"
+)
+
+func newReplicatedWAL(t *testing.T, config ReplicationConfig) *WAL {
+	t.Helper()
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	t.Cleanup(func() { w.Close() })
+	if err := w.SetReplication(config); err != nil {
+		t.Fatalf("SetReplication failed: %v", err)
+	}
+	return w
+}
+
+// commitAsync commits a new transaction in the background and sends the
+// commit LSN once LogCommit returns.
+func commitAsync(t *testing.T, w *WAL) <-chan primitives.LSN {
+	t.Helper()
+	tid := primitives.NewTransactionID()
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	done := make(chan primitives.LSN, 1)
+	go func() {
+		lsn, err := w.LogCommit(tid)
+		if err != nil {
+			t.Errorf("LogCommit failed: %v", err)
+		}
+		done <- lsn
+	}()
+	return done
+}
+
+func expectWaiting(t *testing.T, done <-chan primitives.LSN) {
+	t.Helper()
+	select {
+	case <-done:
+		t.Fatal("commit returned before the quorum acknowledged it")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func expectCommitted(t *testing.T, done <-chan primitives.LSN) primitives.LSN {
+	t.Helper()
+	select {
+	case lsn := <-done:
+		return lsn
+	case <-time.After(5 * time.Second):
+		t.Fatal("commit did not return")
+		return 0
+	}
+}
+
+func TestReplication_CommitWaitsForQuorum(t *testing.T) {
+	w := newReplicatedWAL(t, ReplicationConfig{
+		Standbys: []string{"a", "b", "c"},
+		Quorum:   2,
+		Timeout:  time.Minute,
+	})
+
+	done := commitAsync(t, w)
+	expectWaiting(t, done)
+
+	flushed := w.FlushedLSN()
+	if err := w.AcknowledgeStandby("a", AckReceived, flushed); err != nil {
+		t.Fatalf("AcknowledgeStandby failed: %v", err)
+	}
+	expectWaiting(t, done)
+
+	if err := w.AcknowledgeStandby("c", AckApplied, flushed); err != nil {
+		t.Fatalf("AcknowledgeStandby failed: %v", err)
+	}
+	lsn := expectCommitted(t, done)
+	if lsn >= flushed {
+		t.Errorf("commit LSN %d not below acknowledged LSN %d", lsn, flushed)
+	}
+
+	stats := w.ReplicationStats()
+	if stats.Demoted {
+		t.Error("replication should not be demoted")
+	}
+	if len(stats.Standbys) != 3 || stats.Standbys[1].LagBytes != int64(flushed) {
+		t.Errorf("unexpected standby stats %+v", stats.Standbys)
+	}
+}
+
+func TestReplication_AppliedLevelIgnoresReceivedAcks(t *testing.T) {
+	w := newReplicatedWAL(t, ReplicationConfig{
+		Standbys: []string{"a"},
+		Quorum:   1,
+		Level:    AckApplied,
+		Timeout:  time.Minute,
+	})
+
+	done := commitAsync(t, w)
+	expectWaiting(t, done)
+
+	if err := w.AcknowledgeStandby("a", AckReceived, w.FlushedLSN()); err != nil {
+		t.Fatalf("AcknowledgeStandby failed: %v", err)
+	}
+	expectWaiting(t, done)
+
+	if err := w.AcknowledgeStandby("a", AckApplied, w.FlushedLSN()); err != nil {
+		t.Fatalf("AcknowledgeStandby failed: %v", err)
+	}
+	expectCommitted(t, done)
+}
+
+func TestReplication_TimeoutDemotesToAsync(t *testing.T) {
+	w := newReplicatedWAL(t, ReplicationConfig{
+		Standbys: []string{"a"},
+		Quorum:   1,
+		Timeout:  20 * time.Millisecond,
+	})
+
+	before := replicationDemotions.Value()
+	expectCommitted(t, commitAsync(t, w))
+	if !w.ReplicationStats().Demoted {
+		t.Fatal("expected replication to be demoted after the timeout")
+	}
+	if replicationDemotions.Value() != before+1 {
+		t.Error("expected the demotion to be counted")
+	}
+
+	// Demoted, commits return without waiting for the timeout
+	start := time.Now()
+	expectCommitted(t, commitAsync(t, w))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("demoted commit took %v", elapsed)
+	}
+
+	// Catching up with the commit that timed out restores synchronous commits
+	if err := w.AcknowledgeStandby("a", AckReceived, w.FlushedLSN()); err != nil {
+		t.Fatalf("AcknowledgeStandby failed: %v", err)
+	}
+	if w.ReplicationStats().Demoted {
+		t.Fatal("expected replication to be restored")
+	}
+	w.SetReplication(ReplicationConfig{Standbys: []string{"a"}, Quorum: 1, Timeout: time.Minute})
+	done := commitAsync(t, w)
+	expectWaiting(t, done)
+	w.AcknowledgeStandby("a", AckReceived, w.FlushedLSN())
+	expectCommitted(t, done)
+}
+
+func TestReplication_InvalidConfig(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	for _, config := range []ReplicationConfig{
+		{Standbys: []string{"a"}, Quorum: 2},
+		{Standbys: []string{"a", "a"}, Quorum: 1},
+		{Standbys: []string{""}, Quorum: 1},
+		{Quorum: -1},
+	} {
+		if err := w.SetReplication(config); err == nil {
+			t.Errorf("expected %+v to be rejected", config)
+		}
+	}
+	if err := w.AcknowledgeStandby("unknown", AckReceived, 1); err == nil {
+		t.Error("expected an acknowledgment from an unknown standby to fail")
+	}
+}
+
+func TestParseAckLevel(t *testing.T) {
+	for _, level := range []AckLevel{AckReceived, AckApplied} {
+		got, err := ParseAckLevel(level.String())
+		if err != nil || got != level {
+			t.Errorf("ParseAckLevel(%q) = %v, %v", level.String(), got, err)
+		}
+	}
+	if _, err := ParseAckLevel("flushed"); err == nil {
+		t.Error("expected an unknown level to fail")
+	}
+}
@@ -0,0 +1,89 @@
+package wal
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"time"
+)
+
+// Stats is a snapshot of the state of a WAL, for checkpoint policies and
+// monitoring views.
+type Stats struct {
+	CurrentLSN         primitives.LSN // LSN of the next record, including buffered records
+	FlushedLSN         primitives.LSN // LSN up to which records have reached the log file
+	BufferedBytes      int64          // Bytes of records not yet written to the log file
+	Buffer             BufferStats    // How the log buffer kept up with the appends
+	BufferPolicy       BufferPolicy
+	FileSize           int64          // Size of the log file
+	LastCheckpointLSN  primitives.LSN // LSN of the last checkpoint, 0 if none
+	ActiveTransactions int            // Transactions that began and have not committed
+	DirtyPages         int            // Entries in the dirty page table
+	Durability         Durability
+	SyncInterval       time.Duration // How often the log is flushed under DurabilityInterval
+	Compression        record.Compression
+	SyncPolicy         vfs.SyncPolicy
+	DirectIO           bool // The log file bypasses the OS page cache
+	ReadOnly           bool
+}
+
+// TransactionStats is the log usage of an active transaction.
+type TransactionStats struct {
+	Records  int64          // Records the transaction logged, including its BEGIN
+	Bytes    int64          // Bytes those records take in the log
+	FirstLSN primitives.LSN // LSN of its BEGIN
+	LastLSN  primitives.LSN // LSN of the last record it logged
+}
+
+// GetTransactionStats returns the log usage of transaction tid so far, for
+// enforcing per-transaction quotas and finding transactions that log far
+// more than expected. Records are counted as they are appended, whether or
+// not they were flushed, at their size in the log file, so compressed and
+// encrypted records count as written. It fails for a transaction that is not
+// active, including one that has committed.
+func (w *WAL) GetTransactionStats(tid *primitives.TransactionID) (TransactionStats, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	txnInfo, err := w.getTransactionInfo(tid)
+	if err != nil {
+		return TransactionStats{}, err
+	}
+	return TransactionStats{
+		Records:  txnInfo.Records,
+		Bytes:    txnInfo.Bytes,
+		FirstLSN: txnInfo.FirstLSN,
+		LastLSN:  txnInfo.LastLSN,
+	}, nil
+}
+
+// Stats returns a consistent snapshot of the WAL's positions and tables.
+//
+// The log file holds exactly the flushed records, since LSNs are byte
+// offsets into it, so FileSize is derived from FlushedLSN rather than read
+// from the file system.
+func (w *WAL) Stats() Stats {
+	interval := w.SyncInterval()
+
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	current, flushed := w.writer.CurrentLSN(), w.writer.FlushedLSN()
+	return Stats{
+		CurrentLSN:         current,
+		FlushedLSN:         flushed,
+		BufferedBytes:      int64(current - flushed),
+		Buffer:             w.writer.bufferStats(),
+		BufferPolicy:       w.bufferPolicy,
+		FileSize:           int64(flushed),
+		LastCheckpointLSN:  w.GetCheckpointStats().LastCheckpointLSN,
+		ActiveTransactions: len(w.activeTxns),
+		DirtyPages:         len(w.dirtyPages),
+		Durability:         w.durability,
+		SyncInterval:       interval,
+		Compression:        w.compression,
+		SyncPolicy:         w.syncPolicy,
+		DirectIO:           vfs.IsDirect(w.file),
+		ReadOnly:           w.readOnly,
+	}
+}
@@ -0,0 +1,233 @@
+package wal
+
+import (
+	"fmt"
+	"io"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"sync"
+)
+
+const (
+	// subscriptionBuffer is how many records the channel of a subscription
+	// holds before the subscription waits for its consumer.
+	subscriptionBuffer = 64
+
+	// subscriptionBatch is how many records a subscription reads from the
+	// log at a time, holding off appends while it does.
+	subscriptionBatch = 256
+)
+
+// subscriptions are the consumers of the records the WAL flushes, see
+// Subscribe. They have their own lock, so that flushing only wakes them.
+type subscriptions struct {
+	mu      sync.Mutex
+	closed  bool          // The WAL is closed, no subscription starts
+	waiting bool          // A subscription took flushed since it was last replaced
+	flushed chan struct{} // Closed and replaced whenever the log is flushed or truncated
+	done    chan struct{} // Closed when the WAL is closed
+	wg      sync.WaitGroup
+
+	truncated primitives.LSN // Bytes truncation removed from the front of the log, guarded by w.mutex
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		flushed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// subscription is the state of one consumer, owned by its goroutine.
+type subscription struct {
+	from      primitives.LSN // Records before this LSN are not delivered
+	reader    *LogReader     // Positioned at the next record to deliver, nil until opened
+	truncated primitives.LSN // subscriptions.truncated when reader was opened
+	records   chan *record.LogRecord
+	cancel    chan struct{}
+	done      chan struct{}
+}
+
+// Subscribe delivers the records of the log from fromLSN on, as they are
+// flushed, on the returned channel, so that consumers such as replicas and
+// caches follow the log without polling its file. Records already in the
+// log are delivered first, then each record once a flush writes it to the
+// log file; records still buffered are not delivered, so consumers never see
+// a record a crash could lose before it was written. If fromLSN falls inside
+// a record, delivery starts with the next one.
+//
+// Each subscription reads the log on its own and waits for its consumer, so
+// a slow consumer only falls behind. The channel is closed once cancel is
+// called, the WAL is closed, or the subscription cannot go on: when its
+// records are truncated before it delivered them, or the log cannot be read.
+// The latter are logged. Truncation moves the LSNs of the records it keeps,
+// so the records delivered after it carry the new LSNs. cancel may be called
+// more than once, and returns once the channel is closed.
+func (w *WAL) Subscribe(fromLSN primitives.LSN) (<-chan *record.LogRecord, func()) {
+	s := &subscription{
+		from:    fromLSN,
+		records: make(chan *record.LogRecord, subscriptionBuffer),
+		cancel:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { close(s.cancel) })
+		<-s.done
+	}
+
+	subs := w.subs
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	if subs.closed {
+		close(s.records)
+		close(s.done)
+		return s.records, cancel
+	}
+	subs.wg.Add(1)
+	walSubscriptions.Inc()
+	go w.runSubscription(s)
+	return s.records, cancel
+}
+
+// runSubscription delivers the records of s until it is cancelled, the WAL
+// is closed or the log cannot be read.
+func (w *WAL) runSubscription(s *subscription) {
+	defer w.subs.wg.Done()
+	defer walSubscriptions.Dec()
+	defer close(s.done)
+	defer close(s.records)
+	defer func() {
+		if s.reader != nil {
+			s.reader.Close()
+		}
+	}()
+
+	for {
+		// Taken before reading, so that a flush during the read still wakes us
+		flushed := w.subs.wait()
+
+		batch, err := w.readSubscription(s)
+		if err != nil {
+			w.logger.Error("WAL subscription stopped", "error", err)
+			return
+		}
+		for _, rec := range batch {
+			select {
+			case s.records <- rec:
+			case <-s.cancel:
+				return
+			case <-w.subs.done:
+				return
+			}
+		}
+		if len(batch) > 0 {
+			continue
+		}
+
+		select {
+		case <-flushed:
+		case <-s.cancel:
+			return
+		case <-w.subs.done:
+			return
+		}
+	}
+}
+
+// readSubscription reads the next flushed records of s, at most
+// subscriptionBatch of them. It holds w.mutex shared, so the log is neither
+// flushed nor truncated while it reads.
+func (w *WAL) readSubscription(s *subscription) ([]*record.LogRecord, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	if err := w.openSubscription(s); err != nil {
+		return nil, err
+	}
+
+	var batch []*record.LogRecord
+	flushedLSN := w.writer.FlushedLSN()
+	for len(batch) < subscriptionBatch && primitives.LSN(s.reader.offset) < flushedLSN {
+		rec, err := s.reader.ReadNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, newIOError(err, "failed to read WAL at LSN %d", s.reader.offset)
+		}
+		if rec.LSN >= s.from {
+			batch = append(batch, rec)
+		}
+	}
+	return batch, nil
+}
+
+// openSubscription opens the reader of s, or reopens it on the new log file
+// if the log was truncated since, moving its position to the new LSNs. The
+// caller holds w.mutex.
+func (w *WAL) openSubscription(s *subscription) error {
+	var next primitives.LSN
+	if s.reader != nil {
+		shift := w.subs.truncated - s.truncated
+		if shift == 0 {
+			return nil
+		}
+		next = primitives.LSN(s.reader.offset)
+		if next < shift {
+			return fmt.Errorf("records from LSN %d were truncated before they were delivered", next)
+		}
+		next -= shift
+		s.from -= min(s.from, shift)
+		s.reader.Close()
+		s.reader = nil
+	} else {
+		next = s.from
+	}
+
+	reader, err := w.OpenLogReader()
+	if err != nil {
+		return err
+	}
+	if err := reader.Seek(next); err != nil {
+		reader.Close()
+		return err
+	}
+	s.reader = reader
+	s.truncated = w.subs.truncated
+	return nil
+}
+
+// wait returns a channel closed at the next flush or truncation of the log.
+func (subs *subscriptions) wait() <-chan struct{} {
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	subs.waiting = true
+	return subs.flushed
+}
+
+// notify wakes the subscriptions waiting for the log to be flushed.
+func (subs *subscriptions) notify() {
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	if !subs.waiting {
+		return
+	}
+	subs.waiting = false
+	close(subs.flushed)
+	subs.flushed = make(chan struct{})
+}
+
+// closeSubscriptions ends every subscription and waits for them to close
+// their channels. It must not be called with w.mutex held, since the
+// subscriptions take it.
+func (w *WAL) closeSubscriptions() {
+	subs := w.subs
+	subs.mu.Lock()
+	if !subs.closed {
+		subs.closed = true
+		close(subs.done)
+	}
+	subs.mu.Unlock()
+	subs.wg.Wait()
+}
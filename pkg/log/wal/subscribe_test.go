@@ -0,0 +1,155 @@
+package wal
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/logging"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"testing"
+	"time"
+)
+
+// receive reads n records from ch, failing if they do not arrive in time.
+func receive(t *testing.T, ch <-chan *record.LogRecord, n int) []*record.LogRecord {
+	t.Helper()
+	var recs []*record.LogRecord
+	timeout := time.After(5 * time.Second)
+	for len(recs) < n {
+		select {
+		case rec, ok := <-ch:
+			if !ok {
+				t.Fatalf("subscription closed after %d of %d records", len(recs), n)
+			}
+			recs = append(recs, rec)
+		case <-timeout:
+			t.Fatalf("received %d of %d records", len(recs), n)
+		}
+	}
+	return recs
+}
+
+// expectClosed fails unless ch is closed without delivering more records.
+func expectClosed(t *testing.T, ch <-chan *record.LogRecord) {
+	t.Helper()
+	select {
+	case rec, ok := <-ch:
+		if ok {
+			t.Fatalf("unexpected record at LSN %d", rec.LSN)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
+func TestWAL_Subscribe(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	logTransactions(t, w, 1, 3)
+	ch, cancel := w.Subscribe(0)
+	defer cancel()
+
+	// The records already in the log come first
+	recs := receive(t, ch, 4)
+	for i, want := range []record.LogRecordType{record.BeginRecord, record.CommitRecord, record.BeginRecord, record.CommitRecord} {
+		if recs[i].Type != want {
+			t.Errorf("record %d is %v, want %v", i, recs[i].Type, want)
+		}
+	}
+
+	// A record still buffered is not delivered until it is flushed
+	tid := primitives.NewTransactionIDFromValue(3)
+	beginLSN, err := w.LogBegin(tid)
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	select {
+	case rec := <-ch:
+		t.Fatalf("record at LSN %d delivered before it was flushed", rec.LSN)
+	case <-time.After(50 * time.Millisecond):
+	}
+	commitLSN, err := w.LogCommit(tid)
+	if err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	recs = receive(t, ch, 2)
+	if recs[0].LSN != beginLSN || recs[1].LSN != commitLSN {
+		t.Errorf("received LSNs %d and %d, want %d and %d", recs[0].LSN, recs[1].LSN, beginLSN, commitLSN)
+	}
+
+	cancel()
+	expectClosed(t, ch)
+}
+
+func TestWAL_SubscribeFromLSN(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	defer w.Close()
+
+	logTransactions(t, w, 1, 3)
+	second, err := w.LogBegin(primitives.NewTransactionIDFromValue(3))
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if err := w.Force(second); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+
+	// Starting inside a record skips to the next one
+	ch, cancel := w.Subscribe(second - 1)
+	defer cancel()
+	if rec := receive(t, ch, 1)[0]; rec.LSN != second {
+		t.Errorf("first record at LSN %d, want %d", rec.LSN, second)
+	}
+
+	// Starting past the end delivers the records written from there on
+	future := w.Stats().FlushedLSN + 1000
+	later, cancelLater := w.Subscribe(future)
+	defer cancelLater()
+	logTransactions(t, w, 4, 40)
+	for _, rec := range receive(t, later, 1) {
+		if rec.LSN < future {
+			t.Errorf("record at LSN %d delivered to a subscription from %d", rec.LSN, future)
+		}
+	}
+}
+
+func TestWAL_SubscribeFollowsTruncation(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+
+	logTransactions(t, w, 1, 10)
+	ch, cancel := w.Subscribe(0)
+	defer cancel()
+	receive(t, ch, 18)
+
+	if err := w.performTruncation(w.Stats().FlushedLSN / 2); err != nil {
+		t.Fatalf("performTruncation failed: %v", err)
+	}
+	end := w.Stats().FlushedLSN
+
+	logTransactions(t, w, 10, 11)
+	recs := receive(t, ch, 2)
+	if recs[0].LSN != end || recs[0].Type != record.BeginRecord {
+		t.Errorf("first record after truncation is %v at LSN %d, want BEGIN at %d", recs[0].Type, recs[0].LSN, end)
+	}
+
+	// Closing the WAL ends the subscription
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	expectClosed(t, ch)
+
+	closed, _ := w.Subscribe(0)
+	expectClosed(t, closed)
+}
@@ -1,11 +1,11 @@
 package wal
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"storemy/pkg/log/record"
 	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
 )
 
 // TruncateConfig configures WAL truncation behavior
@@ -24,8 +24,8 @@ type TruncateConfig struct {
 func DefaultTruncateConfig() TruncateConfig {
 	return TruncateConfig{
 		Enabled:                 true,
-		MinWALSizeForTruncation: 5 * 1024 * 1024,  // 5MB
-		MinRetainedSize:         1 * 1024 * 1024,  // 1MB
+		MinWALSizeForTruncation: 5 * 1024 * 1024, // 5MB
+		MinRetainedSize:         1 * 1024 * 1024, // 1MB
 	}
 }
 
@@ -44,12 +44,7 @@ func (w *WAL) TruncateWAL(checkpoint *record.CheckpointRecord, config TruncateCo
 	}
 
 	// Check current WAL size
-	info, err := w.file.Stat()
-	if err != nil {
-		return 0, fmt.Errorf("failed to stat WAL file: %w", err)
-	}
-
-	currentSize := info.Size()
+	currentSize := w.Stats().FileSize
 	if currentSize < config.MinWALSizeForTruncation {
 		// WAL is too small, skip truncation
 		return 0, nil
@@ -74,12 +69,12 @@ func (w *WAL) TruncateWAL(checkpoint *record.CheckpointRecord, config TruncateCo
 		return 0, nil
 	}
 
-	fmt.Printf("Truncating WAL: current size=%d, truncate LSN=%d, will save=%d bytes\n",
-		currentSize, truncateLSN, bytesToTruncate)
+	w.logger.Info("truncating WAL",
+		"current_size", currentSize, "truncate_lsn", truncateLSN, "bytes_saved", bytesToTruncate)
 
 	// Perform the actual truncation
 	if err := w.performTruncation(truncateLSN); err != nil {
-		return 0, fmt.Errorf("failed to truncate WAL: %w", err)
+		return 0, newIOError(err, "failed to truncate WAL")
 	}
 
 	return bytesToTruncate, nil
@@ -98,10 +93,13 @@ func (w *WAL) calculateTruncationPoint(checkpoint *record.CheckpointRecord) prim
 	}
 
 	// Can't truncate before any page became dirty
-	for _, dirtyLSN := range checkpoint.DirtyPages {
-		if dirtyLSN < minLSN {
-			minLSN = dirtyLSN
-		}
+	if dirtyLSN, ok := checkpoint.MinDirtyLSN(); ok && dirtyLSN < minLSN {
+		minLSN = dirtyLSN
+	}
+
+	// Can't truncate before a file operation recovery may still have to finish
+	if fileOpLSN, ok := w.oldestPendingFileOp(); ok && fileOpLSN < minLSN {
+		minLSN = fileOpLSN
 	}
 
 	// Safety margin: keep at least some records before the calculated point
@@ -123,30 +121,39 @@ func (w *WAL) performTruncation(truncateLSN primitives.LSN) error {
 
 	// Step 1: Flush any pending writes
 	if err := w.writer.Close(); err != nil {
-		return fmt.Errorf("failed to flush WAL before truncation: %w", err)
+		return newIOError(err, "failed to flush WAL before truncation")
+	}
+
+	oldEnd := w.writer.FlushedLSN()
+
+	// Archive the records about to be dropped. Nothing has changed yet, so
+	// a failed archive leaves the WAL as it was
+	if err := w.archiveLog(w.file.Name()); err != nil {
+		return err
 	}
 
 	// Step 2: Create a new temporary WAL file
 	newWALPath := w.file.Name() + ".truncate.tmp"
-	newFile, err := os.OpenFile(newWALPath, os.O_CREATE|os.O_RDWR|os.O_SYNC, 0644)
+	newFile, err := w.fs.OpenFile(newWALPath, os.O_CREATE|w.openFlag(), 0644)
 	if err != nil {
-		return fmt.Errorf("failed to create temporary WAL: %w", err)
+		return newIOError(err, "failed to create temporary WAL")
 	}
 
 	// Step 3: Copy records from truncateLSN onwards to the new file
 	oldPath := w.file.Name()
-	copiedBytes, err := w.copyWALRecords(oldPath, newFile, truncateLSN)
+	index := &lsnIndex{}
+	copiedBytes, err := w.copyWALRecords(oldPath, newFile, truncateLSN, index)
 	if err != nil {
 		newFile.Close()
-		os.Remove(newWALPath)
-		return fmt.Errorf("failed to copy WAL records: %w", err)
+		w.fs.Remove(newWALPath)
+		return newIOError(err, "failed to copy WAL records")
 	}
 
 	// Step 4: Close the old WAL file
 	if err := w.file.Close(); err != nil {
 		newFile.Close()
-		os.Remove(newWALPath)
-		return fmt.Errorf("failed to close old WAL: %w", err)
+		w.fs.Remove(newWALPath)
+		return newIOError(err, "failed to close old WAL")
 	}
 
 	// Step 5: Atomically replace old WAL with new WAL
@@ -154,64 +161,84 @@ func (w *WAL) performTruncation(truncateLSN primitives.LSN) error {
 	backupPath := oldPath + ".old"
 
 	// Rename old WAL to backup
-	if err := os.Rename(oldWALPath, backupPath); err != nil {
+	if err := w.fs.Rename(oldWALPath, backupPath); err != nil {
 		newFile.Close()
-		return fmt.Errorf("failed to backup old WAL: %w", err)
+		return newIOError(err, "failed to backup old WAL")
 	}
 
-	// Rename new WAL to active WAL
+	// Rename new WAL to active WAL. Readers are not opened on it until its
+	// index is in place
 	newFile.Close()
-	if err := os.Rename(newWALPath, oldWALPath); err != nil {
+	w.indexMu.Lock()
+	if err := w.fs.Rename(newWALPath, oldWALPath); err != nil {
 		// Try to restore backup
-		os.Rename(backupPath, oldWALPath)
-		return fmt.Errorf("failed to activate new WAL: %w", err)
+		w.fs.Rename(backupPath, oldWALPath)
+		w.indexMu.Unlock()
+		return newIOError(err, "failed to activate new WAL")
 	}
 
 	// Step 6: Reopen the new WAL file
-	file, err := os.OpenFile(oldWALPath, os.O_RDWR|os.O_SYNC, 0644)
+	file, err := w.fs.OpenFile(oldWALPath, w.openFlag(), 0644)
 	if err != nil {
-		return fmt.Errorf("failed to reopen WAL: %w", err)
+		w.indexMu.Unlock()
+		return newIOError(err, "failed to reopen WAL")
 	}
 
 	// Step 7: Recreate the writer with adjusted LSNs
 	// LSNs in the new file start from 0, but we need to continue from where we were
 	w.file = file
-	w.writer = NewLogWriter(file, w.writer.bufferSize, primitives.LSN(copiedBytes), primitives.LSN(copiedBytes))
+	w.index = index
+	w.indexMu.Unlock()
+	w.resetWriter(file, primitives.LSN(copiedBytes))
+	w.subs.truncated += oldEnd - primitives.LSN(copiedBytes)
+	w.subs.notify()
 
 	// Step 8: Update dirty page table LSNs (subtract truncateLSN)
-	newDirtyPages := make(map[primitives.PageID]primitives.LSN)
-	for pageID, lsn := range w.dirtyPages {
+	newDirtyPages := make(map[primitives.PageKey]primitives.LSN)
+	for key, lsn := range w.dirtyPages {
 		if lsn >= truncateLSN {
-			newDirtyPages[pageID] = lsn - truncateLSN
+			newDirtyPages[key] = lsn - truncateLSN
 		}
 	}
 	w.dirtyPages = newDirtyPages
 
+	newFileOps := make(map[primitives.LSN]*primitives.TransactionID, len(w.pendingFileOps))
+	for lsn, tid := range w.pendingFileOps {
+		if lsn >= truncateLSN {
+			newFileOps[lsn-truncateLSN] = tid
+		}
+	}
+	w.pendingFileOps = newFileOps
+
 	// Step 9: Clean up backup file
-	os.Remove(backupPath)
+	w.fs.Remove(backupPath)
 
-	fmt.Printf("WAL truncation completed: new size=%d bytes\n", copiedBytes)
+	w.logger.Info("WAL truncation completed", "new_size", copiedBytes)
 	return nil
 }
 
-// copyWALRecords copies WAL records from startLSN onwards to a new file
-func (w *WAL) copyWALRecords(oldPath string, newFile *os.File, startLSN primitives.LSN) (int64, error) {
-	reader, err := NewLogReader(oldPath)
+// copyWALRecords copies WAL records from startLSN onwards to a new file,
+// indexing them in index
+func (w *WAL) copyWALRecords(oldPath string, newFile vfs.File, startLSN primitives.LSN, index *lsnIndex) (int64, error) {
+	reader, err := w.openLogReader(oldPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create reader: %w", err)
+		return 0, newIOError(err, "failed to create reader")
 	}
 	defer reader.Close()
 
 	var totalBytes int64
 	newLSN := primitives.LSN(0)
 
+	enc := record.NewEncoder()
+	defer enc.Release()
+
 	for {
 		rec, err := reader.ReadNext()
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
-			return 0, fmt.Errorf("failed to read record: %w", err)
+			return 0, newIOError(err, "failed to read record")
 		}
 
 		// Skip records before startLSN
@@ -219,24 +246,27 @@ func (w *WAL) copyWALRecords(oldPath string, newFile *os.File, startLSN primitiv
 			continue
 		}
 
-		// Serialize record
-		data, err := record.SerializeLogRecord(rec)
-		if err != nil {
-			return 0, fmt.Errorf("failed to serialize record: %w", err)
+		// Serialize record, sealed again for its new LSN in an encrypted log
+		data := enc.Encode(rec)
+		if w.cipher != nil {
+			if data, err = sealRecord(w.cipher, data, newLSN); err != nil {
+				return 0, err
+			}
 		}
 
 		// Write to new file
 		if _, err := newFile.WriteAt(data, int64(newLSN)); err != nil {
-			return 0, fmt.Errorf("failed to write record: %w", err)
+			return 0, newIOError(err, "failed to write record")
 		}
 
+		index.add(int64(newLSN), uint32(len(data)))
 		newLSN += primitives.LSN(len(data))
 		totalBytes += int64(len(data))
 	}
 
 	// Ensure everything is written to disk
 	if err := newFile.Sync(); err != nil {
-		return 0, fmt.Errorf("failed to sync new WAL: %w", err)
+		return 0, newIOError(err, "failed to sync new WAL")
 	}
 
 	return totalBytes, nil
@@ -248,19 +278,19 @@ func (w *WAL) TruncateAfterCheckpoint(truncateConfig TruncateConfig) (primitives
 	// Step 1: Perform checkpoint
 	checkpointLSN, err := w.WriteCheckpoint()
 	if err != nil {
-		return 0, 0, fmt.Errorf("checkpoint failed: %w", err)
+		return 0, 0, newCheckpointError(err, "checkpoint failed")
 	}
 
 	// Step 2: Load the checkpoint we just wrote
 	checkpoint, err := w.GetLastCheckpoint()
 	if err != nil {
-		return checkpointLSN, 0, fmt.Errorf("failed to load checkpoint for truncation: %w", err)
+		return checkpointLSN, 0, newCheckpointError(err, "failed to load checkpoint for truncation")
 	}
 
 	// Step 3: Truncate WAL
 	bytesRemoved, err := w.TruncateWAL(checkpoint, truncateConfig)
 	if err != nil {
-		return checkpointLSN, 0, fmt.Errorf("truncation failed: %w", err)
+		return checkpointLSN, 0, newIOError(err, "truncation failed")
 	}
 
 	return checkpointLSN, bytesRemoved, nil
@@ -0,0 +1,41 @@
+package wal
+
+import (
+	"storemy/pkg/primitives"
+)
+
+// restoreTransactionIDs raises the transaction ID allocator above every ID
+// this log knows about: the high-water mark saved by the last checkpoint, and
+// the transaction of every record still in the log. Without it, IDs would
+// start over at each process start and collide with those of transactions
+// that recovery still reads from the log.
+//
+// A checkpoint that cannot be read is skipped, and the scan stops at the
+// first record that cannot be read, like the analysis phase of recovery.
+func (w *WAL) restoreTransactionIDs() {
+	var highWater int64
+	if checkpoint, err := w.GetLastCheckpoint(); err != nil {
+		w.logger.Warn("failed to load checkpoint, restoring transaction IDs from the log only", "error", err)
+	} else if checkpoint != nil {
+		highWater = checkpoint.MaxTID
+		for tid := range checkpoint.ActiveTxns {
+			highWater = max(highWater, tid)
+		}
+	}
+
+	reader, err := w.OpenLogReader()
+	if err == nil {
+		defer reader.Close()
+		for {
+			rec, err := reader.ReadNext()
+			if err != nil {
+				break
+			}
+			if rec.TID != nil {
+				highWater = max(highWater, rec.TID.ID())
+			}
+		}
+	}
+
+	primitives.RestoreTransactionIDs(highWater)
+}
@@ -0,0 +1,322 @@
+package wal
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+// IssueKind classifies an inconsistency found by Validate.
+type IssueKind uint8
+
+const (
+	// IssueUnreadable is a record that cannot be read or fails its checksum,
+	// usually the torn tail of a write cut short by a crash. The scan stops
+	// there.
+	IssueUnreadable IssueKind = iota
+
+	// IssueNonMonotonicLSN is a record whose PrevLSN or UndoNextLSN is not
+	// before its own LSN, so following it would not move back in the log.
+	IssueNonMonotonicLSN
+
+	// IssueBrokenPrevLSN is a transaction record whose PrevLSN is not the
+	// LSN of the transaction's previous record.
+	IssueBrokenPrevLSN
+
+	// IssueBrokenUndoNextLSN is a CLR whose UndoNextLSN is not a record of
+	// its own transaction.
+	IssueBrokenUndoNextLSN
+
+	// IssueUnpairedCheckpoint is a CHECKPOINT BEGIN without a CHECKPOINT END,
+	// or an END that does not refer to an open BEGIN.
+	IssueUnpairedCheckpoint
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case IssueUnreadable:
+		return "unreadable record"
+	case IssueNonMonotonicLSN:
+		return "non-monotonic LSN"
+	case IssueBrokenPrevLSN:
+		return "broken PrevLSN chain"
+	case IssueBrokenUndoNextLSN:
+		return "broken UndoNextLSN chain"
+	case IssueUnpairedCheckpoint:
+		return "unpaired checkpoint"
+	default:
+		return "unknown"
+	}
+}
+
+// ValidationIssue is one inconsistency in the log, at the offset of the
+// record it was found on.
+type ValidationIssue struct {
+	LSN     primitives.LSN
+	TxID    int64 // 0 for checkpoint records and unreadable records
+	Kind    IssueKind
+	Message string
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("LSN %d: %s: %s", i.LSN, i.Kind, i.Message)
+}
+
+// ValidateConfig configures a Validate pass.
+type ValidateConfig struct {
+	// Truncate the log at the first issue, dropping that record and every
+	// record after it. Only use it while no transaction is running, such as
+	// right after opening the WAL and before recovery.
+	TruncateAtFirstIssue bool
+}
+
+// ValidationReport is the result of a Validate pass.
+type ValidationReport struct {
+	Records      int
+	Transactions int
+	Checkpoints  int               // CHECKPOINT BEGIN records
+	EndLSN       primitives.LSN    // End of the last record read
+	Issues       []ValidationIssue // In LSN order
+	Truncated    bool              // The log was cut at TruncatedAt
+	TruncatedAt  primitives.LSN
+}
+
+// OK reports whether the log is free of issues.
+func (r *ValidationReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Validate walks the whole log and checks the links recovery relies on:
+// every transaction's PrevLSN chain, the UndoNextLSN of every CLR, that each
+// link points back in the log, that checkpoint BEGIN and END records pair
+// up, and that every FILE OP DONE completes a file operation. A transaction
+// whose first records were truncated away can only be checked from its first
+// record still in the log.
+//
+// Buffered records are flushed first. With TruncateAtFirstIssue set the log
+// is cut at the first issue, and a checkpoint taken at or after the cut is
+// removed so that recovery does not start past the end of the log.
+func (w *WAL) Validate(config ValidateConfig) (*ValidationReport, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if config.TruncateAtFirstIssue && w.readOnly {
+		return nil, newReadOnlyError("Validate")
+	}
+	if !w.readOnly {
+		if err := w.writer.flush(); err != nil {
+			return nil, newIOError(err, "failed to flush WAL before validation")
+		}
+	}
+
+	report, err := w.scanLog()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.TruncateAtFirstIssue && !report.OK() {
+		cut := report.Issues[0].LSN
+		if err := w.truncateAt(cut); err != nil {
+			return nil, newIOError(err, "failed to truncate WAL at LSN %d", cut)
+		}
+		report.Truncated = true
+		report.TruncatedAt = cut
+	}
+
+	return report, nil
+}
+
+// logValidator holds the state of a Validate scan.
+type logValidator struct {
+	report      *ValidationReport
+	lastLSN     map[int64]primitives.LSN // Last record of each transaction
+	begun       map[int64]bool           // Transactions whose BEGIN was read
+	owners      map[primitives.LSN]int64 // Transaction of each record, 0 for checkpoints
+	checkpoints map[primitives.LSN]bool  // Open CHECKPOINT BEGIN records
+	fileOps     map[primitives.LSN]int64 // FILE OP records and their transaction
+}
+
+func (w *WAL) scanLog() (*ValidationReport, error) {
+	reader, err := w.OpenLogReader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	size, err := reader.GetFileSize()
+	if err != nil {
+		return nil, newIOError(err, "failed to stat WAL")
+	}
+
+	v := &logValidator{
+		report:      &ValidationReport{},
+		lastLSN:     make(map[int64]primitives.LSN),
+		begun:       make(map[int64]bool),
+		owners:      make(map[primitives.LSN]int64),
+		checkpoints: make(map[primitives.LSN]bool),
+		fileOps:     make(map[primitives.LSN]int64),
+	}
+
+	for {
+		rec, err := reader.ReadNext()
+		if err == io.EOF {
+			if reader.offset < size {
+				v.addIssue(primitives.LSN(reader.offset), 0, IssueUnreadable,
+					"%d trailing bytes are too short for a record header", size-reader.offset)
+			}
+			break
+		}
+		if err != nil {
+			v.addIssue(primitives.LSN(reader.offset), 0, IssueUnreadable, "%v", err)
+			break
+		}
+		v.check(rec)
+	}
+
+	for lsn := range v.checkpoints {
+		v.addIssue(lsn, 0, IssueUnpairedCheckpoint, "CHECKPOINT BEGIN has no CHECKPOINT END")
+	}
+
+	v.report.EndLSN = primitives.LSN(reader.offset)
+	v.report.Transactions = len(v.lastLSN)
+	slices.SortStableFunc(v.report.Issues, func(a, b ValidationIssue) int {
+		return cmp.Compare(a.LSN, b.LSN)
+	})
+	return v.report, nil
+}
+
+func (v *logValidator) check(rec *record.LogRecord) {
+	v.report.Records++
+
+	switch rec.Type {
+	case record.CheckpointBegin:
+		v.report.Checkpoints++
+		v.owners[rec.LSN] = 0
+		v.checkpoints[rec.LSN] = true
+
+	case record.CheckpointEnd:
+		v.owners[rec.LSN] = 0
+		if !v.checkpoints[rec.PrevLSN] {
+			v.addIssue(rec.LSN, 0, IssueUnpairedCheckpoint,
+				"CHECKPOINT END refers to LSN %d, which is not an open CHECKPOINT BEGIN", rec.PrevLSN)
+			return
+		}
+		delete(v.checkpoints, rec.PrevLSN)
+
+	case record.FileOpDoneRecord:
+		// DONE is logged after the transaction ended, so it is not part of
+		// its PrevLSN chain but points at the FILE OP it completes.
+		var tid int64
+		if rec.TID != nil {
+			tid = rec.TID.ID()
+		}
+		v.owners[rec.LSN] = tid
+		if owner, ok := v.fileOps[rec.PrevLSN]; !ok || owner != tid {
+			v.addIssue(rec.LSN, tid, IssueBrokenPrevLSN,
+				"FILE OP DONE refers to LSN %d, which is not a file operation of the transaction", rec.PrevLSN)
+		}
+
+	default:
+		v.checkTransactionRecord(rec)
+	}
+}
+
+func (v *logValidator) checkTransactionRecord(rec *record.LogRecord) {
+	if rec.TID == nil {
+		v.addIssue(rec.LSN, 0, IssueBrokenPrevLSN, "transaction record has no transaction ID")
+		return
+	}
+
+	tid := rec.TID.ID()
+	last, seen := v.lastLSN[tid]
+	v.lastLSN[tid] = rec.LSN
+	v.owners[rec.LSN] = tid
+
+	switch {
+	case rec.Type == record.BeginRecord:
+		if seen {
+			v.addIssue(rec.LSN, tid, IssueBrokenPrevLSN, "second BEGIN for the transaction, whose last record is at LSN %d", last)
+		} else if rec.PrevLSN != 0 {
+			v.addIssue(rec.LSN, tid, IssueBrokenPrevLSN, "BEGIN has PrevLSN %d instead of 0", rec.PrevLSN)
+		}
+		v.begun[tid] = true
+
+	case rec.PrevLSN >= rec.LSN:
+		v.addIssue(rec.LSN, tid, IssueNonMonotonicLSN, "PrevLSN %d is not before the record", rec.PrevLSN)
+
+	case seen && rec.PrevLSN != last:
+		v.addIssue(rec.LSN, tid, IssueBrokenPrevLSN,
+			"PrevLSN %d, but the transaction's previous record is at LSN %d", rec.PrevLSN, last)
+
+	case !seen:
+		// The rest of the chain may have been truncated away, but it cannot
+		// run through another transaction's record.
+		if owner, ok := v.owners[rec.PrevLSN]; ok && owner != tid {
+			v.addIssue(rec.LSN, tid, IssueBrokenPrevLSN, "PrevLSN %d is a record of another transaction", rec.PrevLSN)
+		}
+	}
+
+	switch rec.Type {
+	case record.CLRRecord:
+		v.checkUndoNext(rec, tid)
+	case record.FileOpRecord:
+		v.fileOps[rec.LSN] = tid
+	}
+}
+
+func (v *logValidator) checkUndoNext(rec *record.LogRecord, tid int64) {
+	if rec.UndoNextLSN >= rec.LSN {
+		v.addIssue(rec.LSN, tid, IssueNonMonotonicLSN, "UndoNextLSN %d is not before the record", rec.UndoNextLSN)
+		return
+	}
+
+	owner, ok := v.owners[rec.UndoNextLSN]
+	switch {
+	case ok && owner != tid:
+		v.addIssue(rec.LSN, tid, IssueBrokenUndoNextLSN, "UndoNextLSN %d is a record of another transaction", rec.UndoNextLSN)
+	case !ok && v.begun[tid]:
+		v.addIssue(rec.LSN, tid, IssueBrokenUndoNextLSN, "UndoNextLSN %d is not the start of a record", rec.UndoNextLSN)
+	}
+}
+
+func (v *logValidator) addIssue(lsn primitives.LSN, tid int64, kind IssueKind, format string, args ...any) {
+	v.report.Issues = append(v.report.Issues, ValidationIssue{
+		LSN:     lsn,
+		TxID:    tid,
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// truncateAt cuts the log file at lsn and continues appending there. The
+// caller holds w.mutex and has flushed the writer.
+func (w *WAL) truncateAt(lsn primitives.LSN) error {
+	if err := w.file.Truncate(int64(lsn)); err != nil {
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	// The index holds boundaries past the cut; readers rebuild it
+	w.indexMu.Lock()
+	w.index = &lsnIndex{}
+	w.indexMu.Unlock()
+	w.resetWriter(w.file, lsn)
+
+	checkpoint, err := w.GetLastCheckpoint()
+	if err != nil {
+		return err
+	}
+	if checkpoint != nil && checkpoint.LSN >= lsn {
+		if err := w.fs.Remove(w.getCheckpointPath()); err != nil {
+			return newCheckpointError(err, "failed to remove checkpoint past the cut")
+		}
+	}
+
+	w.logger.Warn("truncated WAL at first inconsistency", "lsn", lsn)
+	return nil
+}
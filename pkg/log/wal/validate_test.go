@@ -0,0 +1,277 @@
+package wal
+
+import (
+	"errors"
+	"storemy/pkg/log/record"
+	"storemy/pkg/logging"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"testing"
+)
+
+func newValidateTestWAL(t *testing.T) (*WAL, *vfs.MemFS) {
+	t.Helper()
+	fsys := vfs.NewMemFS()
+	w, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+	t.Cleanup(func() { w.Close() })
+	return w, fsys
+}
+
+func TestValidate_CleanLog(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	pageID := &mockPageID{tableID: 1, pageNo: 1}
+
+	committed := primitives.NewTransactionIDFromValue(1)
+	aborted := primitives.NewTransactionIDFromValue(2)
+	for _, tid := range []*primitives.TransactionID{committed, aborted} {
+		if _, err := w.LogBegin(tid); err != nil {
+			t.Fatalf("LogBegin failed: %v", err)
+		}
+		if _, err := w.LogUpdate(tid, pageID, []byte("before"), []byte("after")); err != nil {
+			t.Fatalf("LogUpdate failed: %v", err)
+		}
+	}
+	if _, err := w.WriteCheckpoint(); err != nil {
+		t.Fatalf("WriteCheckpoint failed: %v", err)
+	}
+	if _, err := w.LogCommit(committed); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if _, err := w.LogAbort(aborted); err != nil {
+		t.Fatalf("LogAbort failed: %v", err)
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("expected a clean log, got issues: %v", report.Issues)
+	}
+	if report.Records != 8 || report.Transactions != 2 || report.Checkpoints != 1 {
+		t.Errorf("expected 8 records, 2 transactions and 1 checkpoint, got %d, %d and %d",
+			report.Records, report.Transactions, report.Checkpoints)
+	}
+	if report.EndLSN != w.CurrentLSN() {
+		t.Errorf("expected the scan to end at %d, got %d", w.CurrentLSN(), report.EndLSN)
+	}
+}
+
+func TestValidate_BrokenPrevLSN(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+
+	beginLSN, err := w.LogBegin(tid)
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogUpdate(tid, &mockPageID{tableID: 1, pageNo: 1}, []byte("a"), []byte("b")); err != nil {
+		t.Fatalf("LogUpdate failed: %v", err)
+	}
+
+	// COMMIT skipping the update, as if the update had been lost.
+	w.mutex.Lock()
+	badLSN, err := w.writeRecord(record.NewLogRecord(record.CommitRecord, tid, nil, nil, nil, beginLSN))
+	w.mutex.Unlock()
+	if err != nil {
+		t.Fatalf("writeRecord failed: %v", err)
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Issues) != 1 {
+		t.Fatalf("expected 1 issue, got %v", report.Issues)
+	}
+	issue := report.Issues[0]
+	if issue.Kind != IssueBrokenPrevLSN || issue.LSN != badLSN || issue.TxID != 1 {
+		t.Errorf("expected a broken PrevLSN at %d for transaction 1, got %v", badLSN, issue)
+	}
+}
+
+func TestValidate_ForwardLinksAndCLRs(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+	other := primitives.NewTransactionIDFromValue(2)
+	pageID := &mockPageID{tableID: 1, pageNo: 1}
+
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	otherLSN, err := w.LogBegin(other)
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogUpdate(tid, pageID, []byte("a"), []byte("b")); err != nil {
+		t.Fatalf("LogUpdate failed: %v", err)
+	}
+
+	w.mutex.Lock()
+	forward := record.NewLogRecord(record.UpdateRecord, tid, pageID, []byte("b"), []byte("c"), 1<<40)
+	forwardLSN, err := w.writeRecord(forward)
+	if err != nil {
+		w.mutex.Unlock()
+		t.Fatalf("writeRecord failed: %v", err)
+	}
+	clr := record.NewLogRecord(record.CLRRecord, tid, pageID, nil, []byte("a"), forwardLSN)
+	clr.UndoNextLSN = otherLSN
+	clrLSN, err := w.writeRecord(clr)
+	w.mutex.Unlock()
+	if err != nil {
+		t.Fatalf("writeRecord failed: %v", err)
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", report.Issues)
+	}
+	if issue := report.Issues[0]; issue.Kind != IssueNonMonotonicLSN || issue.LSN != forwardLSN {
+		t.Errorf("expected a non-monotonic LSN at %d, got %v", forwardLSN, issue)
+	}
+	if issue := report.Issues[1]; issue.Kind != IssueBrokenUndoNextLSN || issue.LSN != clrLSN {
+		t.Errorf("expected a broken UndoNextLSN at %d, got %v", clrLSN, issue)
+	}
+}
+
+func TestValidate_UnpairedCheckpoint(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+
+	beginLSN, err := w.writeCheckpointBegin()
+	if err != nil {
+		t.Fatalf("writeCheckpointBegin failed: %v", err)
+	}
+	if _, err := w.writeCheckpointEnd(beginLSN + 1); err != nil {
+		t.Fatalf("writeCheckpointEnd failed: %v", err)
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if issue.Kind != IssueUnpairedCheckpoint {
+			t.Errorf("expected an unpaired checkpoint, got %v", issue)
+		}
+	}
+	if report.Issues[0].LSN != beginLSN {
+		t.Errorf("expected the unpaired BEGIN at %d first, got %v", beginLSN, report.Issues[0])
+	}
+}
+
+func TestValidate_TornTail(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	end := w.CurrentLSN()
+	if _, err := w.file.WriteAt([]byte{0, 0}, int64(end)); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+
+	report, err := w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Kind != IssueUnreadable || report.Issues[0].LSN != end {
+		t.Fatalf("expected an unreadable record at %d, got %v", end, report.Issues)
+	}
+	if report.EndLSN != end {
+		t.Errorf("expected the scan to stop at %d, got %d", end, report.EndLSN)
+	}
+}
+
+func TestValidate_TruncateAtFirstIssue(t *testing.T) {
+	w, _ := newValidateTestWAL(t)
+	tid := primitives.NewTransactionIDFromValue(1)
+
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+
+	// A COMMIT of an unknown transaction pointing at a checkpoint record.
+	cut, err := w.writeCheckpointBegin()
+	if err != nil {
+		t.Fatalf("writeCheckpointBegin failed: %v", err)
+	}
+	w.mutex.Lock()
+	_, err = w.writeRecord(record.NewLogRecord(record.CommitRecord, primitives.NewTransactionIDFromValue(2), nil, nil, nil, cut))
+	w.mutex.Unlock()
+	if err != nil {
+		t.Fatalf("writeRecord failed: %v", err)
+	}
+	if _, err := w.WriteCheckpoint(); err != nil {
+		t.Fatalf("WriteCheckpoint failed: %v", err)
+	}
+
+	report, err := w.Validate(ValidateConfig{TruncateAtFirstIssue: true})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.Truncated || report.TruncatedAt != cut {
+		t.Fatalf("expected the log to be cut at %d, got truncated=%v at %d", cut, report.Truncated, report.TruncatedAt)
+	}
+	if w.CurrentLSN() != cut {
+		t.Errorf("expected appends to continue at %d, got %d", cut, w.CurrentLSN())
+	}
+	if checkpoint, err := w.GetLastCheckpoint(); err != nil || checkpoint != nil {
+		t.Errorf("expected the checkpoint past the cut to be removed, got %v (err %v)", checkpoint, err)
+	}
+
+	next := primitives.NewTransactionIDFromValue(3)
+	if _, err := w.LogBegin(next); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogCommit(next); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+
+	report, err = w.Validate(ValidateConfig{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.OK() || report.Records != 4 {
+		t.Errorf("expected 4 clean records after the repair, got %d records and issues %v", report.Records, report.Issues)
+	}
+}
+
+func TestValidate_ReadOnlyCannotTruncate(t *testing.T) {
+	w, fsys := newValidateTestWAL(t)
+	if _, err := w.LogBegin(primitives.NewTransactionIDFromValue(1)); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	ro, err := OpenReadOnlyWithFS(fsys, "/wal.log")
+	if err != nil {
+		t.Fatalf("OpenReadOnlyWithFS failed: %v", err)
+	}
+	defer ro.Close()
+
+	if report, err := ro.Validate(ValidateConfig{}); err != nil || !report.OK() {
+		t.Errorf("expected a clean read-only validation, got %v (err %v)", report, err)
+	}
+	if _, err := ro.Validate(ValidateConfig{TruncateAtFirstIssue: true}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package wal
+
+import (
+	"errors"
+	"storemy/pkg/clock"
+	"storemy/pkg/log/record"
+	"storemy/pkg/logging"
+	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
+	"testing"
+	"time"
+)
+
+var errInjectedIO = errors.New("injected I/O error")
+
+func TestWAL_MemFS_CrashKeepsForcedRecords(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	w, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+
+	committed := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(committed); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogCommit(committed); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+
+	// Buffered but never forced: lost in the crash.
+	if _, err := w.LogBegin(primitives.NewTransactionIDFromValue(2)); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+
+	fsys.Crash()
+
+	reader, err := NewLogReaderWithFS(fsys, "/wal.log")
+	if err != nil {
+		t.Fatalf("NewLogReaderWithFS failed: %v", err)
+	}
+	defer reader.Close()
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Type != record.BeginRecord || records[1].Type != record.CommitRecord {
+		t.Fatalf("expected BEGIN and COMMIT of the committed transaction, got %d records", len(records))
+	}
+}
+
+func TestWAL_MemFS_SyncPolicies(t *testing.T) {
+	for _, policy := range []vfs.SyncPolicy{vfs.SyncOSync, vfs.SyncFsync, vfs.SyncFdatasync} {
+		t.Run(policy.String(), func(t *testing.T) {
+			fsys := vfs.NewMemFS()
+			w, err := NewWALWithPolicy(fsys, "/wal.log", 4096, policy)
+			if err != nil {
+				t.Fatalf("NewWALWithPolicy failed: %v", err)
+			}
+
+			tid := primitives.NewTransactionIDFromValue(1)
+			if _, err := w.LogBegin(tid); err != nil {
+				t.Fatalf("LogBegin failed: %v", err)
+			}
+			if _, err := w.LogCommit(tid); err != nil {
+				t.Fatalf("LogCommit failed: %v", err)
+			}
+			if got := w.Stats().SyncPolicy; got != policy {
+				t.Errorf("Stats().SyncPolicy = %s, want %s", got, policy)
+			}
+
+			// A failed sync must fail the commit rather than acknowledge it.
+			failed := primitives.NewTransactionIDFromValue(2)
+			if _, err := w.LogBegin(failed); err != nil {
+				t.Fatalf("LogBegin failed: %v", err)
+			}
+			fsys.SetFault(vfs.FailAlways(vfs.OpSync, "wal.log", errInjectedIO))
+			if _, err := w.LogCommit(failed); err == nil {
+				t.Fatal("expected commit to fail when the WAL sync fails")
+			}
+			fsys.SetFault(nil)
+			fsys.Crash()
+
+			reader, err := NewLogReaderWithFS(fsys, "/wal.log")
+			if err != nil {
+				t.Fatalf("NewLogReaderWithFS failed: %v", err)
+			}
+			defer reader.Close()
+
+			records, err := reader.ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll failed: %v", err)
+			}
+			if len(records) != 2 || records[1].Type != record.CommitRecord {
+				t.Fatalf("expected BEGIN and COMMIT of the first transaction, got %d records", len(records))
+			}
+		})
+	}
+}
+
+func TestWAL_MemFS_WriteFaultFailsCommit(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	w, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+
+	tid := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+
+	fsys.SetFault(vfs.FailAlways(vfs.OpWrite, "wal.log", errInjectedIO))
+	if _, err := w.LogCommit(tid); err == nil {
+		t.Fatal("expected commit to fail when the WAL write fails")
+	}
+}
+
+func TestCheckpointDaemon_FakeClock(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	w, err := NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	w.SetLogger(logging.NewNopLogger())
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	daemon := NewCheckpointDaemon(w, CheckpointConfig{Interval: time.Minute, Enabled: true})
+	daemon.SetLogger(logging.NewNopLogger())
+	daemon.SetClock(fake)
+	if err := daemon.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer daemon.Stop()
+
+	// Interval ticker and size-check ticker.
+	fake.BlockUntilTickers(2)
+
+	fake.Advance(59 * time.Second)
+	if stats := daemon.GetStats(); stats.TotalCheckpoints != 0 {
+		t.Fatalf("expected no checkpoint before the interval, got %d", stats.TotalCheckpoints)
+	}
+
+	fake.Advance(time.Second)
+	deadline := time.Now().Add(5 * time.Second)
+	for daemon.GetStats().TimeBasedTriggers == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a time-based checkpoint after advancing the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := daemon.GetStats()
+	if !stats.LastCheckpointTime.Equal(fake.Now()) {
+		t.Errorf("expected checkpoint time %v from the fake clock, got %v", fake.Now(), stats.LastCheckpointTime)
+	}
+	if _, err := fsys.Stat("/wal.log.checkpoint"); err != nil {
+		t.Errorf("expected checkpoint file on the in-memory file system: %v", err)
+	}
+}
+
+func TestWAL_MemFS_NoSyncDurability(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	w, err := NewWALWithPolicy(fsys, "/wal.log", 4096, vfs.SyncFsync)
+	if err != nil {
+		t.Fatalf("NewWALWithPolicy failed: %v", err)
+	}
+	if err := w.SetDurability(DurabilityNoSync); err != nil {
+		t.Fatalf("SetDurability failed: %v", err)
+	}
+
+	// Syncs fail from here on: a nosync commit must not need one.
+	fsys.SetFault(vfs.FailAlways(vfs.OpSync, "wal.log", errInjectedIO))
+	tid := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	lsn, err := w.LogCommit(tid)
+	if err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if !w.IsDurable(lsn) {
+		t.Errorf("nosync commit at %d not written to the log file", lsn)
+	}
+
+	// Leaving nosync syncs what it wrote.
+	if err := w.SetDurability(DurabilitySync); err == nil {
+		t.Fatal("expected leaving nosync to fail when the WAL sync fails")
+	}
+	fsys.SetFault(nil)
+	if err := w.SetDurability(DurabilitySync); err != nil {
+		t.Fatalf("SetDurability failed: %v", err)
+	}
+	if got := w.Stats().Durability; got != DurabilitySync {
+		t.Errorf("Stats().Durability = %s, want sync", got)
+	}
+
+	fsys.Crash()
+
+	reader, err := NewLogReaderWithFS(fsys, "/wal.log")
+	if err != nil {
+		t.Fatalf("NewLogReaderWithFS failed: %v", err)
+	}
+	defer reader.Close()
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(records) != 2 || records[1].Type != record.CommitRecord {
+		t.Fatalf("expected the commit to survive the crash, got %d records", len(records))
+	}
+}
+
+func TestWAL_IntervalDurabilityFlushesInBackground(t *testing.T) {
+	w, err := NewWALWithFS(vfs.NewMemFS(), "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.SetSyncInterval(0); err == nil {
+		t.Error("expected a zero sync interval to be rejected")
+	}
+	if err := w.SetSyncInterval(MaxSyncInterval); err != nil {
+		t.Fatalf("SetSyncInterval failed: %v", err)
+	}
+	if err := w.SetDurability(DurabilityInterval); err != nil {
+		t.Fatalf("SetDurability failed: %v", err)
+	}
+
+	tid := primitives.NewTransactionIDFromValue(1)
+	if _, err := w.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	lsn, err := w.LogCommit(tid)
+	if err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if w.IsDurable(lsn) {
+		t.Fatalf("interval commit at %d already durable; the test needs it buffered", lsn)
+	}
+
+	// Shortening the interval restarts the flusher with it
+	if err := w.SetSyncInterval(5 * time.Millisecond); err != nil {
+		t.Fatalf("SetSyncInterval failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !w.IsDurable(lsn) {
+		if time.Now().After(deadline) {
+			t.Fatalf("commit at %d not flushed by the background flusher", lsn)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := w.Stats()
+	if stats.Durability != DurabilityInterval || stats.SyncInterval != 5*time.Millisecond {
+		t.Errorf("Stats() = %s every %s, want interval every 5ms", stats.Durability, stats.SyncInterval)
+	}
+}
@@ -1,73 +1,265 @@
 package wal
 
 import (
-	"fmt"
-	"io"
+	"errors"
 	"maps"
 	"os"
+	"storemy/pkg/encryption"
 	"storemy/pkg/log/record"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
 	"sync"
+	"sync/atomic"
 )
 
 const (
 	FirstLSN primitives.LSN = 0
 )
 
+// ErrReadOnly is returned by every operation that would append to a WAL
+// opened with OpenReadOnly.
+var ErrReadOnly = errors.New("WAL is opened in read-only mode")
+
 // WAL manages the write-ahead log
 type WAL struct {
-	file       *os.File
-	activeTxns map[*primitives.TransactionID]*record.TransactionLogInfo
-	dirtyPages map[primitives.PageID]primitives.LSN
-	mutex      sync.RWMutex
-	flushCond  *sync.Cond
-	writer     *LogWriter
+	fs             vfs.FS
+	file           vfs.File
+	activeTxns     map[*primitives.TransactionID]*record.TransactionLogInfo
+	dirtyPages     map[primitives.PageKey]primitives.LSN
+	pendingFileOps map[primitives.LSN]*primitives.TransactionID // File operations announced but not done
+	mutex          sync.RWMutex
+	flushCond      *sync.Cond
+	writer         *LogWriter
+	readOnly       bool
+	durability     Durability
+	compression    record.Compression // Compression of the page images of UPDATE records
+	syncPolicy     vfs.SyncPolicy
+	directIO       bool               // The log file is opened with vfs.ODirect, see SetDirectIO
+	cipher         *encryption.Cipher // Seals the records of an encrypted log, nil otherwise
+	replication    replication        // Standbys commits wait for, see SetReplication
+	group          *groupCommit       // Batches the log forces of concurrent commits
+	syncer         *intervalSync      // Flushes the log in the background under DurabilityInterval
+	archive        archiver           // Where the log is archived before truncation
+	subs           *subscriptions     // Consumers of the flushed records, see Subscribe
+	disk           *diskMonitor       // Free space kept for running transactions, see SetDiskReserve
+	bufferPolicy   BufferPolicy       // How the log buffer grows, see SetBufferPolicy
+	draining       atomic.Bool        // A background flush started by backpressure is running
+	drains         sync.WaitGroup     // Background flushes started by backpressure
+	index          *lsnIndex          // Record boundaries of the log file, shared with its readers
+	indexMu        sync.Mutex         // Held while the log file and its index change together
+	logger         logging.ComponentLogger
 }
 
 // NewWAL creates a new WAL instance
 func NewWAL(logPath string, bufferSize int) (*WAL, error) {
-	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_RDWR|os.O_SYNC, 0644)
+	return NewWALWithFS(vfs.OS, logPath, bufferSize)
+}
+
+// NewWALWithFS creates a WAL whose log, checkpoint and truncation files live on fsys.
+// Opening an existing log restores the transaction ID allocator above every
+// transaction ID the log and its last checkpoint hold.
+func NewWALWithFS(fsys vfs.FS, logPath string, bufferSize int) (*WAL, error) {
+	return NewWALWithPolicy(fsys, logPath, bufferSize, vfs.SyncOSync)
+}
+
+// NewWALWithPolicy creates a WAL on fsys whose writes are made durable
+// according to policy. Whatever the policy, a record is durable once the
+// flushed LSN is past it.
+func NewWALWithPolicy(fsys vfs.FS, logPath string, bufferSize int, policy vfs.SyncPolicy) (*WAL, error) {
+	return NewWALWithCipher(fsys, logPath, bufferSize, policy, nil)
+}
+
+// NewWALWithCipher creates a WAL like NewWALWithPolicy whose records are
+// encrypted with c, which also opens the encrypted records already in the
+// log. Records written without encryption stay readable. A nil c writes
+// records in the clear.
+func NewWALWithCipher(fsys vfs.FS, logPath string, bufferSize int, policy vfs.SyncPolicy, c *encryption.Cipher) (*WAL, error) {
+	file, err := fsys.OpenFile(logPath, os.O_CREATE|os.O_RDWR|policy.OpenFlag(), 0644)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open WAL file: %v", err)
+		return nil, newIOError(err, "failed to open WAL file")
 	}
 
-	pos, err := file.Seek(0, io.SeekEnd)
+	pos, err := fileSize(file)
 	if err != nil {
 		file.Close()
-		return nil, fmt.Errorf("failed to seek to end of WAL: %v", err)
+		return nil, newIOError(err, "failed to seek to end of WAL")
 	}
 
+	index := &lsnIndex{}
 	writer := NewLogWriter(file, bufferSize, primitives.LSN(pos), primitives.LSN(pos))
+	writer.sync = func() error { return policy.Sync(file) }
+	writer.cipher = c
+	writer.index = index
 
 	w := &WAL{
+		fs:         fsys,
 		file:       file,
 		writer:     writer,
+		index:      index,
+		syncPolicy: policy,
+		cipher:     c,
+		activeTxns: make(map[*primitives.TransactionID]*record.TransactionLogInfo),
+		dirtyPages: make(map[primitives.PageKey]primitives.LSN),
+		group:      newGroupCommit(),
+		syncer:     newIntervalSync(),
+		subs:       newSubscriptions(),
+		disk:       newDiskMonitor(),
+		logger:     logging.ForComponent("wal"),
+
+		pendingFileOps: make(map[primitives.LSN]*primitives.TransactionID),
+	}
+
+	w.flushCond = sync.NewCond(&w.mutex)
+	writer.onFlush = w.subs.notify
+	w.restoreTransactionIDs()
+	return w, nil
+}
+
+// OpenReadOnly opens an existing WAL file without write access.
+// The returned WAL can be scanned by the recovery manager and log readers,
+// but every append (BEGIN, data records, COMMIT, checkpoints) fails with ErrReadOnly.
+// This is used when opening a backup or standby directory that must not be modified.
+func OpenReadOnly(logPath string) (*WAL, error) {
+	return OpenReadOnlyWithFS(vfs.OS, logPath)
+}
+
+// OpenReadOnlyWithFS is OpenReadOnly for a WAL stored on fsys.
+func OpenReadOnlyWithFS(fsys vfs.FS, logPath string) (*WAL, error) {
+	return OpenReadOnlyWithCipher(fsys, logPath, nil)
+}
+
+// OpenReadOnlyWithCipher is OpenReadOnlyWithFS for a WAL whose records may be
+// encrypted with c.
+func OpenReadOnlyWithCipher(fsys vfs.FS, logPath string, c *encryption.Cipher) (*WAL, error) {
+	file, err := vfs.Open(fsys, logPath)
+	if err != nil {
+		return nil, newIOError(err, "failed to open WAL file read-only")
+	}
+
+	pos, err := fileSize(file)
+	if err != nil {
+		file.Close()
+		return nil, newIOError(err, "failed to seek to end of WAL")
+	}
+
+	w := &WAL{
+		fs:         fsys,
+		file:       file,
+		writer:     NewLogWriter(file, 0, primitives.LSN(pos), primitives.LSN(pos)),
+		index:      &lsnIndex{},
 		activeTxns: make(map[*primitives.TransactionID]*record.TransactionLogInfo),
-		dirtyPages: make(map[primitives.PageID]primitives.LSN),
+		dirtyPages: make(map[primitives.PageKey]primitives.LSN),
+		readOnly:   true,
+		cipher:     c,
+		group:      newGroupCommit(),
+		syncer:     newIntervalSync(),
+		subs:       newSubscriptions(),
+		disk:       newDiskMonitor(),
+		logger:     logging.ForComponent("wal"),
+
+		pendingFileOps: make(map[primitives.LSN]*primitives.TransactionID),
 	}
 
 	w.flushCond = sync.NewCond(&w.mutex)
+	w.restoreTransactionIDs()
 	return w, nil
 }
 
+// FS returns the file system the WAL is stored on.
+func (w *WAL) FS() vfs.FS {
+	return w.fs
+}
+
+// OpenLogReader opens a reader over the WAL file that decrypts its
+// encrypted records. The reader seeks through the WAL's index of the log,
+// which every reader opened here extends, so PrevLSN chains are followed
+// without walking the log from its start each time.
+func (w *WAL) OpenLogReader() (*LogReader, error) {
+	w.indexMu.Lock()
+	defer w.indexMu.Unlock()
+	reader, err := w.openLogReader(w.file.Name())
+	if err != nil {
+		return nil, err
+	}
+	reader.index = w.index
+	return reader, nil
+}
+
+// openLogReader opens a reader over the log file at path, on the WAL's file
+// system and with its cipher.
+func (w *WAL) openLogReader(path string) (*LogReader, error) {
+	reader, err := NewLogReaderWithFS(w.fs, path)
+	if err != nil {
+		return nil, err
+	}
+	reader.SetCipher(w.cipher)
+	return reader, nil
+}
+
+// fileSize returns the current size of file, which is where appends continue.
+func fileSize(file vfs.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// SetLogger replaces the logger used for checkpoint and truncation messages.
+func (w *WAL) SetLogger(logger logging.ComponentLogger) {
+	w.logger = logger
+}
+
+// Logger returns the logger used by this WAL.
+func (w *WAL) Logger() logging.ComponentLogger {
+	return w.logger
+}
+
+// IsReadOnly reports whether the WAL was opened with OpenReadOnly.
+func (w *WAL) IsReadOnly() bool {
+	return w.readOnly
+}
+
+// LogBegin logs the start of transaction tid. While the disk of the log is
+// below its reserve it fails with ErrDiskSpaceLow, see SetDiskReserve, and
+// while the log buffer is full it may fail with ErrWALBackpressure, see
+// SetBufferPolicy.
 func (w *WAL) LogBegin(tid *primitives.TransactionID) (primitives.LSN, error) {
+	if w.DiskSpaceLow() {
+		return 0, newDiskSpaceLowError("LogBegin")
+	}
+
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
-	lsn, err := w.logTransactionOperation(record.BeginRecord, tid, FirstLSN)
+	rec := record.NewLogRecord(record.BeginRecord, tid, nil, nil, nil, FirstLSN)
+	if err := w.admit(rec.SerializedSize(), "LogBegin"); err != nil {
+		return 0, err
+	}
+	// Entered before the BEGIN is written, so that it is accounted for
+	txnInfo := &record.TransactionLogInfo{}
+	w.activeTxns[tid] = txnInfo
+	lsn, err := w.writeRecord(rec)
 	if err != nil {
+		delete(w.activeTxns, tid)
 		return 0, err
 	}
 
-	w.activeTxns[tid] = &record.TransactionLogInfo{
-		FirstLSN: lsn,
-		LastLSN:  lsn,
-	}
+	txnInfo.FirstLSN = lsn
+	txnInfo.LastLSN = lsn
 	return lsn, nil
 }
 
-// CRITICAL: This must FORCE the log to disk before returning (durability guarantee)
-// After this returns successfully, the transaction is durable even if system crashes
+// LogCommit logs a transaction commit and returns the LSN of the commit record.
+// Under DurabilitySync (the default) it FORCES the log to disk before
+// returning, so the transaction is durable even if the system crashes;
+// concurrent commits share one force (see SetCommitWindow).
+// Under DurabilityAsync and DurabilityInterval the record may still be
+// buffered; IsDurable and WaitForDurability tell the caller when it is not.
+// Under DurabilityNoSync the record is written to the log file, which is not
+// synced. With synchronous replication configured it also waits for a quorum
+// of standbys, see SetReplication.
 func (w *WAL) LogCommit(tid *primitives.TransactionID) (primitives.LSN, error) {
 	w.mutex.Lock()
 
@@ -84,10 +276,19 @@ func (w *WAL) LogCommit(tid *primitives.TransactionID) (primitives.LSN, error) {
 	}
 
 	txnInfo.LastLSN = lsn
+	durability := w.durability
 	w.mutex.Unlock()
 
-	if err := w.Force(lsn); err != nil {
-		return 0, fmt.Errorf("failed to force commit record to disk: %v", err)
+	// Standbys only receive flushed records, so a synchronous commit is
+	// durable locally before it waits for them
+	replicated := w.Replication().Enabled()
+	if durability == DurabilitySync || durability == DurabilityNoSync || replicated {
+		if err := w.waitForCommit(lsn); err != nil {
+			return 0, newIOError(err, "failed to force commit record to disk")
+		}
+	}
+	if replicated {
+		w.waitForReplication(lsn)
 	}
 
 	w.mutex.Lock()
@@ -114,21 +315,30 @@ func (w *WAL) LogAbort(tid *primitives.TransactionID) (primitives.LSN, error) {
 
 	txnInfo.LastLSN = lsn
 	txnInfo.UndoNextLSN = txnInfo.LastLSN
+	w.dropFileOps(tid)
 	return lsn, nil
 }
 
 // Close closes the WAL gracefully
 // Flushes any remaining buffered data and closes the file
 func (w *WAL) Close() error {
+	w.closeIntervalSync()
+	w.closeDiskMonitor()
+	w.closeSubscriptions()
+	w.drains.Wait()
+
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
 	if err := w.writer.Close(); err != nil {
-		return fmt.Errorf("failed to close WAL writer: %v", err)
+		return newIOError(err, "failed to close WAL writer")
+	}
+	if err := w.writer.syncUnsynced(); err != nil {
+		return newIOError(err, "failed to sync WAL")
 	}
 
 	if err := w.file.Close(); err != nil {
-		return fmt.Errorf("failed to close WAL file: %v", err)
+		return newIOError(err, "failed to close WAL file")
 	}
 
 	return nil
@@ -154,13 +364,45 @@ func (w *WAL) LogDelete(tid *primitives.TransactionID, pageID primitives.PageID,
 	return w.logDataOperation(record.DeleteRecord, tid, pageID, beforeImage, nil)
 }
 
+// LogCLR logs a compensation record restoring a page to afterImage, after a
+// transaction undid some of its own changes without aborting. undoNextLSN is
+// the transaction's last record that was not undone, so that recovery does
+// not undo the compensated changes a second time.
+func (w *WAL) LogCLR(tid *primitives.TransactionID, pageID primitives.PageID, afterImage []byte, undoNextLSN primitives.LSN) (primitives.LSN, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	txnInfo, err := w.getTransactionInfo(tid)
+	if err != nil {
+		return 0, err
+	}
+
+	rec := record.NewLogRecord(record.CLRRecord, tid, pageID, nil, afterImage, txnInfo.LastLSN)
+	rec.UndoNextLSN = undoNextLSN
+
+	lsn, err := w.writeRecord(rec)
+	if err != nil {
+		return 0, err
+	}
+
+	txnInfo.LastLSN = lsn
+	txnInfo.UndoNextLSN = undoNextLSN
+
+	key := primitives.KeyOf(pageID)
+	if _, exists := w.dirtyPages[key]; !exists {
+		w.dirtyPages[key] = lsn
+	}
+
+	return lsn, nil
+}
+
 // GetDirtyPages returns a copy of the dirty page table
 // Used during checkpointing
-func (w *WAL) GetDirtyPages() map[primitives.PageID]primitives.LSN {
+func (w *WAL) GetDirtyPages() map[primitives.PageKey]primitives.LSN {
 	w.mutex.RLock()
 	defer w.mutex.RUnlock()
 
-	pages := make(map[primitives.PageID]primitives.LSN, len(w.dirtyPages))
+	pages := make(map[primitives.PageKey]primitives.LSN, len(w.dirtyPages))
 	maps.Copy(pages, w.dirtyPages)
 	return pages
 }
@@ -186,19 +428,44 @@ func (w *WAL) GetLastLSN(tid *primitives.TransactionID) (primitives.LSN, error)
 
 	txnInfo, exists := w.activeTxns[tid]
 	if !exists {
-		return 0, fmt.Errorf("transaction %v not found", tid)
+		return 0, newTxNotFoundError(tid)
 	}
 
 	return txnInfo.LastLSN, nil
 }
 
+// CurrentLSN returns the LSN that will be assigned to the next log record,
+// which is also the logical end of the log including buffered records.
+func (w *WAL) CurrentLSN() primitives.LSN {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.writer.CurrentLSN()
+}
+
+// FlushedLSN returns the LSN up to which log records have reached the WAL file.
+func (w *WAL) FlushedLSN() primitives.LSN {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.writer.FlushedLSN()
+}
+
 func (w *WAL) writeRecord(rec *record.LogRecord) (primitives.LSN, error) {
-	data, err := record.SerializeLogRecord(rec)
+	if w.readOnly {
+		return 0, newReadOnlyError("Append")
+	}
+
+	lsn, size, err := w.writer.WriteRecord(rec)
 	if err != nil {
 		return 0, err
 	}
 
-	return w.writer.Write(data)
+	walBytesWritten.Add(int64(size))
+	walRecordsWritten.Inc()
+	if txnInfo, ok := w.activeTxns[rec.TID]; ok {
+		txnInfo.Records++
+		txnInfo.Bytes += int64(size)
+	}
+	return lsn, nil
 }
 
 // LogAbortDuringRecovery logs an abort record during recovery without requiring
@@ -218,19 +485,26 @@ func (w *WAL) Force(lsn primitives.LSN) error {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
+	walForces.Inc()
 	return w.writer.Force(lsn)
 }
 
 func (w *WAL) getTransactionInfo(tid *primitives.TransactionID) (*record.TransactionLogInfo, error) {
 	txnInfo, exists := w.activeTxns[tid]
 	if !exists {
-		return nil, fmt.Errorf("transaction %v not found in active transactions", tid)
+		return nil, newTxNotFoundError(tid)
 	}
 	return txnInfo, nil
 }
 
 // logDataOperation is a helper for logging data operations (insert, update, delete)
 func (w *WAL) logDataOperation(recordType record.LogRecordType, tid *primitives.TransactionID, pageID primitives.PageID, beforeImage, afterImage []byte) (primitives.LSN, error) {
+	rec := record.NewLogRecord(recordType, tid, pageID, beforeImage, afterImage, 0)
+	if recordType == record.UpdateRecord {
+		// Compressed before taking the lock, which other appends wait for
+		rec.CompressImages(w.Compression())
+	}
+
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
@@ -239,17 +513,22 @@ func (w *WAL) logDataOperation(recordType record.LogRecordType, tid *primitives.
 		return FirstLSN, err
 	}
 
-	rec := record.NewLogRecord(recordType, tid, pageID, beforeImage, afterImage, txnInfo.LastLSN)
+	if err := w.admit(rec.SerializedSize(), "Log"+recordType.String()); err != nil {
+		return 0, err
+	}
 
+	rec.PrevLSN = txnInfo.LastLSN
 	lsn, err := w.writeRecord(rec)
 	if err != nil {
 		return 0, err
 	}
+	walImageBytesSaved.Add(int64(rec.ImageBytesSaved()))
 
 	txnInfo.LastLSN = lsn
 
-	if _, exists := w.dirtyPages[pageID]; !exists {
-		w.dirtyPages[pageID] = lsn
+	key := primitives.KeyOf(pageID)
+	if _, exists := w.dirtyPages[key]; !exists {
+		w.dirtyPages[key] = lsn
 	}
 
 	return lsn, nil
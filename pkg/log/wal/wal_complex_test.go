@@ -284,7 +284,7 @@ func TestMultiplePagesPerTransaction(t *testing.T) {
 	}
 
 	// Verify only one entry in dirty pages (recLSN should be first update)
-	recLSN, exists := wal.dirtyPages[pageID]
+	recLSN, exists := wal.dirtyPages[primitives.KeyOf(pageID)]
 	if !exists {
 		t.Fatal("page should be in dirty pages")
 	}
@@ -371,7 +371,7 @@ func TestGetDirtyPages(t *testing.T) {
 
 	// Verify all pages are present (using same pageID references)
 	for i, pageID := range pageIDs {
-		if _, exists := dirtyPages[pageID]; !exists {
+		if _, exists := dirtyPages[primitives.KeyOf(pageID)]; !exists {
 			t.Errorf("page %d not found in dirty pages", i)
 		}
 	}
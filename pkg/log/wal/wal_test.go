@@ -1,9 +1,12 @@
 package wal
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"storemy/pkg/log/record"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 	"testing"
 )
@@ -66,6 +69,7 @@ func createTestWAL(t *testing.T) (*WAL, string, func()) {
 		os.RemoveAll(tmpDir)
 		t.Fatalf("failed to create WAL: %v", err)
 	}
+	wal.SetLogger(logging.NewNopLogger())
 
 	cleanup := func() {
 		wal.file.Close()
@@ -156,7 +160,7 @@ func TestLogUpdate(t *testing.T) {
 	}
 
 	// Check dirty page tracking
-	recLSN, exists := wal.dirtyPages[pageID]
+	recLSN, exists := wal.dirtyPages[primitives.KeyOf(pageID)]
 	if !exists {
 		t.Fatal("page not found in dirtyPages")
 	}
@@ -262,6 +266,101 @@ func TestForceIdempotent(t *testing.T) {
 	}
 }
 
+func TestForce_RecordAtFlushedLSN(t *testing.T) {
+	wal, _, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	first, err := wal.LogBegin(primitives.NewTransactionID())
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if err := wal.Force(first); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+
+	// The next record starts exactly where the flushed part of the log ends
+	second, err := wal.LogBegin(primitives.NewTransactionID())
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if second != wal.FlushedLSN() {
+		t.Fatalf("second record at LSN %d, want the flushed LSN %d", second, wal.FlushedLSN())
+	}
+	if err := wal.Force(second); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+	if wal.FlushedLSN() <= second {
+		t.Errorf("FlushedLSN = %d after Force(%d), want past the record", wal.FlushedLSN(), second)
+	}
+}
+
+func TestForce_KeepsLaterRecordsBuffered(t *testing.T) {
+	wal, _, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	first, err := wal.LogBegin(primitives.NewTransactionID())
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	second, err := wal.LogBegin(primitives.NewTransactionID())
+	if err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+
+	if err := wal.Force(first); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+	if wal.FlushedLSN() != second {
+		t.Errorf("FlushedLSN = %d, want the end of the forced record %d", wal.FlushedLSN(), second)
+	}
+
+	if err := wal.Force(second); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+	if wal.FlushedLSN() != wal.CurrentLSN() {
+		t.Errorf("FlushedLSN = %d, want %d", wal.FlushedLSN(), wal.CurrentLSN())
+	}
+}
+
+func TestLogCommit_Durability(t *testing.T) {
+	wal, _, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	commit := func() primitives.LSN {
+		tid := primitives.NewTransactionID()
+		if _, err := wal.LogBegin(tid); err != nil {
+			t.Fatalf("LogBegin failed: %v", err)
+		}
+		lsn, err := wal.LogCommit(tid)
+		if err != nil {
+			t.Fatalf("LogCommit failed: %v", err)
+		}
+		return lsn
+	}
+
+	if lsn := commit(); !wal.IsDurable(lsn) {
+		t.Errorf("sync commit at %d not durable, flushed to %d", lsn, wal.FlushedLSN())
+	}
+
+	if err := wal.SetDurability(DurabilityAsync); err != nil {
+		t.Fatalf("SetDurability failed: %v", err)
+	}
+	lsn := commit()
+	if wal.IsDurable(lsn) {
+		t.Fatalf("async commit at %d already durable; the test needs it buffered", lsn)
+	}
+	if err := wal.WaitForDurability(lsn); err != nil {
+		t.Fatalf("WaitForDurability failed: %v", err)
+	}
+	if !wal.IsDurable(lsn) {
+		t.Errorf("commit at %d not durable after WaitForDurability", lsn)
+	}
+
+	if err := wal.WaitForDurability(wal.CurrentLSN()); err == nil {
+		t.Error("expected an error waiting for an LSN past the end of the log")
+	}
+}
+
 func TestBufferFlushing(t *testing.T) {
 	// Create WAL with small buffer to force flushing
 	tmpDir, err := os.MkdirTemp("", "wal_test_*")
@@ -369,7 +468,7 @@ func TestDirtyPageTracking(t *testing.T) {
 		t.Fatalf("LogUpdate failed: %v", err)
 	}
 
-	recLSN := wal.dirtyPages[pageID]
+	recLSN := wal.dirtyPages[primitives.KeyOf(pageID)]
 	if recLSN != lsn1 {
 		t.Errorf("expected recLSN to be %d, got %d", lsn1, recLSN)
 	}
@@ -380,7 +479,7 @@ func TestDirtyPageTracking(t *testing.T) {
 		t.Fatalf("second LogUpdate failed: %v", err)
 	}
 
-	recLSN2 := wal.dirtyPages[pageID]
+	recLSN2 := wal.dirtyPages[primitives.KeyOf(pageID)]
 	if recLSN2 != lsn1 {
 		t.Errorf("expected recLSN to remain %d, got %d", lsn1, recLSN2)
 	}
@@ -457,7 +556,7 @@ func TestLogInsert(t *testing.T) {
 	}
 
 	// Check dirty page tracking
-	recLSN, exists := wal.dirtyPages[pageID]
+	recLSN, exists := wal.dirtyPages[primitives.KeyOf(pageID)]
 	if !exists {
 		t.Fatal("page not found in dirtyPages")
 	}
@@ -509,7 +608,7 @@ func TestLogDelete(t *testing.T) {
 	}
 
 	// Check dirty page tracking
-	recLSN, exists := wal.dirtyPages[pageID]
+	recLSN, exists := wal.dirtyPages[primitives.KeyOf(pageID)]
 	if !exists {
 		t.Fatal("page not found in dirtyPages")
 	}
@@ -972,3 +1071,201 @@ func TestMultipleCommits(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenReadOnly(t *testing.T) {
+	wal, logPath, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	tid := primitives.NewTransactionID()
+	if _, err := wal.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := wal.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	wal.Close()
+
+	sizeBefore, _ := os.Stat(logPath)
+
+	roWAL, err := OpenReadOnly(logPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly failed: %v", err)
+	}
+	roWAL.SetLogger(logging.NewNopLogger())
+	defer roWAL.Close()
+
+	if !roWAL.IsReadOnly() {
+		t.Error("expected IsReadOnly to be true")
+	}
+
+	if _, err := roWAL.LogBegin(primitives.NewTransactionID()); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from LogBegin, got %v", err)
+	}
+	if _, err := roWAL.WriteCheckpoint(); err == nil {
+		t.Error("expected checkpoint to fail on a read-only WAL")
+	}
+
+	sizeAfter, _ := os.Stat(logPath)
+	if sizeAfter.Size() != sizeBefore.Size() {
+		t.Errorf("expected WAL size %d to be unchanged, got %d", sizeBefore.Size(), sizeAfter.Size())
+	}
+
+	reader, err := NewLogReader(logPath)
+	if err != nil {
+		t.Fatalf("NewLogReader failed: %v", err)
+	}
+	defer reader.Close()
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 records, got %d", len(records))
+	}
+}
+
+func TestOpenReadOnlyMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	if _, err := OpenReadOnly(filepath.Join(tmpDir, "missing.wal")); err == nil {
+		t.Error("expected error opening a missing WAL read-only")
+	}
+}
+
+func TestReopen_RestoresTransactionIDs(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "wal.log")
+	w, err := NewWAL(logPath, 4096)
+	if err != nil {
+		t.Fatalf("NewWAL failed: %v", err)
+	}
+
+	// A transaction from a previous run, with an ID the allocator has not reached
+	oldTID := primitives.NewTransactionIDFromValue(primitives.LastTransactionID() + 1000)
+	if _, err := w.LogBegin(oldTID); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := w.LogCommit(oldTID); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	w.Close()
+
+	w, err = NewWAL(logPath, 4096)
+	if err != nil {
+		t.Fatalf("reopening the WAL failed: %v", err)
+	}
+	defer w.Close()
+
+	if tid := primitives.NewTransactionID(); tid.ID() <= oldTID.ID() {
+		t.Errorf("new transaction ID %d does not exceed %d found in the log", tid.ID(), oldTID.ID())
+	}
+}
+
+func TestReopen_RestoresTransactionIDsFromCheckpoint(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "wal.log")
+
+	// The log holding the transaction was truncated; only the checkpoint
+	// remembers how far the allocator got
+	cp := record.NewCheckpointRecord(nil, nil)
+	cp.MaxTID = primitives.LastTransactionID() + 1000
+	data, err := record.SerializeCheckpoint(cp)
+	if err != nil {
+		t.Fatalf("SerializeCheckpoint failed: %v", err)
+	}
+	if err := os.WriteFile(logPath+".checkpoint", data, 0644); err != nil {
+		t.Fatalf("failed to write checkpoint: %v", err)
+	}
+
+	w, err := NewWAL(logPath, 4096)
+	if err != nil {
+		t.Fatalf("NewWAL failed: %v", err)
+	}
+	defer w.Close()
+
+	if tid := primitives.NewTransactionID(); tid.ID() <= cp.MaxTID {
+		t.Errorf("new transaction ID %d does not exceed checkpoint high-water mark %d", tid.ID(), cp.MaxTID)
+	}
+}
+
+func TestStats(t *testing.T) {
+	wal, logPath, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	tid := primitives.NewTransactionID()
+	if _, err := wal.LogBegin(tid); err != nil {
+		t.Fatalf("LogBegin failed: %v", err)
+	}
+	if _, err := wal.LogUpdate(tid, &mockPageID{tableID: 1, pageNo: 1}, []byte("before"), []byte("after")); err != nil {
+		t.Fatalf("LogUpdate failed: %v", err)
+	}
+
+	stats := wal.Stats()
+	if stats.ActiveTransactions != 1 || stats.DirtyPages != 1 {
+		t.Errorf("ActiveTransactions = %d, DirtyPages = %d, want 1 and 1", stats.ActiveTransactions, stats.DirtyPages)
+	}
+	if stats.CurrentLSN != wal.CurrentLSN() || stats.FlushedLSN != wal.FlushedLSN() {
+		t.Errorf("Stats LSNs = %d/%d, want %d/%d", stats.CurrentLSN, stats.FlushedLSN, wal.CurrentLSN(), wal.FlushedLSN())
+	}
+	if stats.BufferedBytes != int64(stats.CurrentLSN-stats.FlushedLSN) || stats.BufferedBytes == 0 {
+		t.Errorf("BufferedBytes = %d, want the unflushed records", stats.BufferedBytes)
+	}
+
+	if _, err := wal.LogCommit(tid); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+
+	stats = wal.Stats()
+	if stats.ActiveTransactions != 0 || stats.BufferedBytes != 0 {
+		t.Errorf("after commit: ActiveTransactions = %d, BufferedBytes = %d, want 0 and 0", stats.ActiveTransactions, stats.BufferedBytes)
+	}
+	info, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if stats.FileSize != info.Size() {
+		t.Errorf("FileSize = %d, want %d", stats.FileSize, info.Size())
+	}
+}
+
+func TestGetTransactionStats(t *testing.T) {
+	wal, _, cleanup := createTestWAL(t)
+	defer cleanup()
+
+	busy, idle := primitives.NewTransactionID(), primitives.NewTransactionID()
+	for _, tid := range []*primitives.TransactionID{busy, idle} {
+		if _, err := wal.LogBegin(tid); err != nil {
+			t.Fatalf("LogBegin failed: %v", err)
+		}
+	}
+	before := wal.CurrentLSN()
+	for i := range 3 {
+		if _, err := wal.LogInsert(busy, &mockPageID{tableID: 1, pageNo: primitives.PageNumber(i)}, make([]byte, 100)); err != nil {
+			t.Fatalf("LogInsert failed: %v", err)
+		}
+	}
+	inserted := int64(wal.CurrentLSN() - before)
+
+	stats, err := wal.GetTransactionStats(busy)
+	if err != nil {
+		t.Fatalf("GetTransactionStats failed: %v", err)
+	}
+	idleStats, err := wal.GetTransactionStats(idle)
+	if err != nil {
+		t.Fatalf("GetTransactionStats failed: %v", err)
+	}
+	if idleStats.Records != 1 || idleStats.Bytes == 0 {
+		t.Errorf("idle transaction: %+v, want its BEGIN only", idleStats)
+	}
+	if stats.Records != 4 || stats.Bytes != idleStats.Bytes+inserted {
+		t.Errorf("busy transaction: %+v, want 4 records of %d bytes", stats, idleStats.Bytes+inserted)
+	}
+	if stats.LastLSN <= stats.FirstLSN {
+		t.Errorf("busy transaction: LastLSN %d not after FirstLSN %d", stats.LastLSN, stats.FirstLSN)
+	}
+
+	if _, err := wal.LogCommit(busy); err != nil {
+		t.Fatalf("LogCommit failed: %v", err)
+	}
+	if _, err := wal.GetTransactionStats(busy); err == nil {
+		t.Error("expected an error for a committed transaction")
+	}
+}
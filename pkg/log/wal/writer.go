@@ -2,7 +2,10 @@ package wal
 
 import (
 	"io"
+	"storemy/pkg/encryption"
+	"storemy/pkg/log/record"
 	"storemy/pkg/primitives"
+	"time"
 )
 
 type LogWriter struct {
@@ -12,6 +15,21 @@ type LogWriter struct {
 	buffer       []byte
 	bufferOffset int
 	bufferSize   int
+	initialSize  int                // Size a grown buffer shrinks back to
+	maxSize      int                // Largest the buffer grows to, see BufferPolicy
+	lowFlushes   int                // Flushes in a row that found a grown buffer at most a quarter full
+	recordEnds   []primitives.LSN   // Where each buffered record ends, in LSN order
+	sync         func() error       // Makes written data durable, if writes alone do not
+	noSync       bool               // Skip sync, under DurabilityNoSync
+	unsynced     bool               // Data was written since the last sync
+	cipher       *encryption.Cipher // Seals every record, nil if the log is not encrypted
+	onFlush      func()             // Called whenever the flushed LSN moves, nil for none
+	index        *lsnIndex          // Boundaries of the appended records, nil if not indexed
+
+	growths    int64         // Times the buffer grew instead of being flushed
+	stalls     int64         // Appends that flushed the full buffer themselves
+	stallTime  time.Duration // Time spent in those flushes
+	rejections int64         // Appends refused under BackpressureReject
 }
 
 // NewLogWriter creates a new LogWriter with the given underlying writer and buffer size
@@ -19,6 +37,7 @@ func NewLogWriter(writer io.WriterAt, bufferSize int, current, flushed primitive
 	return &LogWriter{
 		writer:       writer,
 		bufferSize:   bufferSize,
+		initialSize:  bufferSize,
 		buffer:       make([]byte, bufferSize),
 		bufferOffset: 0,
 		currentLSN:   current,
@@ -32,7 +51,7 @@ func NewLogWriter(writer io.WriterAt, bufferSize int, current, flushed primitive
 func (w *LogWriter) Write(data []byte) (primitives.LSN, error) {
 	assignedLSN := w.currentLSN
 
-	if len(data) > w.bufferSize {
+	if len(data) > w.limit() {
 
 		if err := w.flush(); err != nil {
 			return 0, err
@@ -42,49 +61,258 @@ func (w *LogWriter) Write(data []byte) (primitives.LSN, error) {
 		if err != nil {
 			return 0, err
 		}
+		if err := w.syncWritten(); err != nil {
+			return 0, err
+		}
+		walFlushes.Inc()
 
+		w.indexRecord(assignedLSN, len(data))
 		bytesWritten := primitives.LSN(len(data))
 		w.flushedLSN += bytesWritten
 		w.currentLSN += bytesWritten
+		w.flushed()
 		return assignedLSN, nil
 	}
 
 	if w.bufferOffset+len(data) > w.bufferSize {
-		if err := w.flush(); err != nil {
+		if err := w.makeRoom(len(data)); err != nil {
 			return 0, err
 		}
 	}
 	copy(w.buffer[w.bufferOffset:], data)
 	w.bufferOffset += len(data)
 	w.currentLSN += primitives.LSN(len(data))
+	w.recordEnds = append(w.recordEnds, w.currentLSN)
+	w.indexRecord(assignedLSN, len(data))
 
 	return assignedLSN, nil
 }
 
+// WriteRecord serializes rec straight into the buffer and returns its LSN
+// and size. Unlike Write it needs no intermediate slice; a record too large
+// for the buffer is encoded with a pooled record.Encoder and written through.
+// In an encrypted log the record is sealed first (see sealRecord).
+func (w *LogWriter) WriteRecord(rec *record.LogRecord) (primitives.LSN, int, error) {
+	if w.cipher != nil {
+		enc := record.NewEncoder()
+		defer enc.Release()
+		data, err := sealRecord(w.cipher, enc.Encode(rec), w.currentLSN)
+		if err != nil {
+			return 0, 0, err
+		}
+		lsn, err := w.Write(data)
+		return lsn, len(data), err
+	}
+
+	size := rec.SerializedSize()
+	if size > w.limit() {
+		enc := record.NewEncoder()
+		defer enc.Release()
+		lsn, err := w.Write(enc.Encode(rec))
+		return lsn, size, err
+	}
+
+	if w.bufferOffset+size > w.bufferSize {
+		if err := w.makeRoom(size); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	assignedLSN := w.currentLSN
+	rec.AppendTo(w.buffer[:w.bufferOffset])
+	w.bufferOffset += size
+	w.currentLSN += primitives.LSN(size)
+	w.recordEnds = append(w.recordEnds, w.currentLSN)
+	w.indexRecord(assignedLSN, size)
+
+	return assignedLSN, size, nil
+}
+
+// indexRecord adds the record of size bytes appended at lsn to the index of
+// the log, if it has one.
+func (w *LogWriter) indexRecord(lsn primitives.LSN, size int) {
+	if w.index != nil {
+		w.index.add(int64(lsn), uint32(size))
+	}
+}
+
+// limit returns the size of the largest record the buffer can take, which
+// it may have to grow to; larger records are written through.
+func (w *LogWriter) limit() int {
+	return max(w.bufferSize, w.maxSize)
+}
+
+// makeRoom makes room for n more bytes in the buffer: it grows the buffer if
+// it may, and otherwise flushes it, which stalls the append for the write
+// and sync.
+func (w *LogWriter) makeRoom(n int) error {
+	if w.grow(w.bufferOffset + n) {
+		return nil
+	}
+
+	start := time.Now()
+	err := w.flush()
+	stall := time.Since(start)
+	w.stalls++
+	w.stallTime += stall
+	walBufferStalls.Inc()
+	walBufferStallSeconds.Observe(stall.Seconds())
+	if err != nil {
+		return err
+	}
+	// A record larger than a buffer that shrank still fits once it grows
+	if n > w.bufferSize {
+		w.grow(n)
+	}
+	return nil
+}
+
+// grow doubles the buffer until it holds needed bytes, and reports whether
+// it could without passing maxSize.
+func (w *LogWriter) grow(needed int) bool {
+	if needed > w.maxSize {
+		return false
+	}
+	size := w.bufferSize
+	for size < needed {
+		size *= 2
+	}
+	w.resize(min(size, w.maxSize))
+	w.growths++
+	walBufferGrowths.Inc()
+	return true
+}
+
+// resize replaces the buffer with one of size bytes holding the buffered
+// records.
+func (w *LogWriter) resize(size int) {
+	buffer := make([]byte, size)
+	copy(buffer, w.buffer[:w.bufferOffset])
+	w.buffer = buffer
+	w.bufferSize = size
+	w.lowFlushes = 0
+}
+
+// shrink halves a grown buffer, down to its initial size, once enough
+// flushes in a row found it at most a quarter full. used is how full the
+// buffer was before the last flush.
+func (w *LogWriter) shrink(used int) {
+	if w.bufferSize <= w.initialSize {
+		return
+	}
+	if used > w.bufferSize/4 {
+		w.lowFlushes = 0
+		return
+	}
+	w.lowFlushes++
+	if size := max(w.bufferSize/2, w.initialSize); w.lowFlushes >= shrinkAfterFlushes && w.bufferOffset <= size {
+		w.resize(size)
+	}
+}
+
+// wouldStall reports whether appending n bytes would flush the full buffer
+// in the append, since it cannot grow enough.
+func (w *LogWriter) wouldStall(n int) bool {
+	return n <= w.limit() && w.bufferOffset+n > w.limit()
+}
+
+// bufferStats returns the size and counters of the buffer.
+func (w *LogWriter) bufferStats() BufferStats {
+	return BufferStats{
+		Size:       w.bufferSize,
+		Growths:    w.growths,
+		Stalls:     w.stalls,
+		StallTime:  w.stallTime,
+		Rejections: w.rejections,
+	}
+}
+
 // Force ensures data is on disk up to the given primitives.LSN
 // This is called during commit to guarantee durability
+// An LSN is where a record starts, so the record at lsn is on disk only once
+// flushedLSN is past it. Records buffered after it stay in the buffer.
 func (w *LogWriter) Force(lsn primitives.LSN) error {
-	if w.flushedLSN >= lsn {
+	if w.flushedLSN > lsn {
 		return nil
 	}
 
+	for _, end := range w.recordEnds {
+		if end > lsn {
+			return w.flushTo(end)
+		}
+	}
 	return w.flush()
 }
 
 // flush writes the buffer to the underlying writer
 // This is an internal method, not part of the interface
 func (w *LogWriter) flush() error {
-	if w.bufferOffset == 0 {
+	return w.flushTo(w.currentLSN)
+}
+
+// flushTo writes the buffered records ending at or before end, which must be
+// a record boundary, and keeps the rest buffered.
+func (w *LogWriter) flushTo(end primitives.LSN) error {
+	n := int(end - w.flushedLSN)
+	if n == 0 {
 		return nil
 	}
 
-	_, err := w.writer.WriteAt(w.buffer[:w.bufferOffset], int64(w.flushedLSN))
+	_, err := w.writer.WriteAt(w.buffer[:n], int64(w.flushedLSN))
 	if err != nil {
 		return err
 	}
+	if err := w.syncWritten(); err != nil {
+		return err
+	}
+	walFlushes.Inc()
 
-	w.flushedLSN = w.currentLSN
-	w.bufferOffset = 0
+	used := w.bufferOffset
+	copy(w.buffer, w.buffer[n:w.bufferOffset])
+	w.bufferOffset -= n
+	w.flushedLSN = end
+	w.shrink(used)
+
+	flushed := 0
+	for flushed < len(w.recordEnds) && w.recordEnds[flushed] <= end {
+		flushed++
+	}
+	w.recordEnds = append(w.recordEnds[:0], w.recordEnds[flushed:]...)
+	w.flushed()
+	return nil
+}
+
+// flushed reports that the flushed LSN moved.
+func (w *LogWriter) flushed() {
+	if w.onFlush != nil {
+		w.onFlush()
+	}
+}
+
+// syncWritten makes the data written so far durable, so that the flushed LSN
+// can move past it. Every record in one flush shares a single sync. With
+// noSync set the data is only written, and syncUnsynced syncs it later.
+func (w *LogWriter) syncWritten() error {
+	if w.sync == nil {
+		return nil
+	}
+	if w.noSync {
+		w.unsynced = true
+		return nil
+	}
+	w.unsynced = false
+	return w.sync()
+}
+
+// syncUnsynced syncs the data written without a sync under noSync.
+func (w *LogWriter) syncUnsynced() error {
+	if !w.unsynced || w.sync == nil {
+		return nil
+	}
+	if err := w.sync(); err != nil {
+		return err
+	}
+	w.unsynced = false
 	return nil
 }
 
@@ -92,6 +320,11 @@ func (w *LogWriter) CurrentLSN() primitives.LSN {
 	return w.currentLSN
 }
 
+// FlushedLSN returns the LSN up to which the log has been written to the file.
+func (w *LogWriter) FlushedLSN() primitives.LSN {
+	return w.flushedLSN
+}
+
 func (w *LogWriter) Close() error {
 	return w.flush()
 }
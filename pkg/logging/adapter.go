@@ -0,0 +1,47 @@
+package logging
+
+import "log/slog"
+
+// ComponentLogger is the leveled, structured logger injected into long-lived
+// storage components (WAL, checkpoint daemon, recovery, catalog). Arguments
+// after the message are key-value pairs, following the log/slog convention.
+type ComponentLogger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+
+	// With returns a ComponentLogger that adds the given key-value pairs to
+	// every record.
+	With(args ...any) ComponentLogger
+}
+
+// slogLogger adapts *slog.Logger to the ComponentLogger interface.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger wraps an slog.Logger as a ComponentLogger.
+func NewSlogLogger(l *slog.Logger) ComponentLogger {
+	return &slogLogger{l: l}
+}
+
+func (s *slogLogger) Debug(msg string, args ...any) { s.l.Debug(msg, args...) }
+func (s *slogLogger) Info(msg string, args ...any)  { s.l.Info(msg, args...) }
+func (s *slogLogger) Warn(msg string, args ...any)  { s.l.Warn(msg, args...) }
+func (s *slogLogger) Error(msg string, args ...any) { s.l.Error(msg, args...) }
+
+func (s *slogLogger) With(args ...any) ComponentLogger {
+	return &slogLogger{l: s.l.With(args...)}
+}
+
+// ForComponent returns a ComponentLogger backed by the global logger with the
+// "component" field set, the default for components that are not given one.
+func ForComponent(component string) ComponentLogger {
+	return NewSlogLogger(WithComponent(component))
+}
+
+// NewNopLogger returns a ComponentLogger that discards everything.
+func NewNopLogger() ComponentLogger {
+	return NewSlogLogger(slog.New(slog.DiscardHandler))
+}
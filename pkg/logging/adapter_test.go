@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger_LevelsAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	logger := NewSlogLogger(base).With("component", "wal")
+	logger.Debug("hidden debug message")
+	logger.Info("checkpoint completed", "lsn", 42)
+	logger.Error("checkpoint failed")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden debug message") {
+		t.Error("debug message should be filtered at INFO level")
+	}
+	for _, want := range []string{"level=INFO", "msg=\"checkpoint completed\"", "lsn=42", "component=wal", "level=ERROR"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestNopLogger(t *testing.T) {
+	logger := NewNopLogger().With("component", "test")
+
+	// Must not panic or write anywhere
+	logger.Debug("debug")
+	logger.Info("info", "key", "value")
+	logger.Warn("warn")
+	logger.Error("error")
+}
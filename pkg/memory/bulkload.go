@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+)
+
+// ErrBulkLoadInProgress is returned when a transaction tries to add pages to
+// a table another transaction is bulk loading.
+var ErrBulkLoadInProgress = errors.New("table is being bulk loaded by another transaction")
+
+// BulkFile is a heap file a bulk load appends pages to.
+type BulkFile interface {
+	page.PageIO
+	GetID() primitives.FileID
+	FilePath() primitives.Filepath
+	NumPages() (primitives.PageNumber, error)
+	AllocateNewPage() (primitives.PageNumber, error)
+	Sync() error
+}
+
+// BulkLoader writes pages straight to a heap file for one transaction,
+// bypassing the buffer pool and the WAL. Only the start of the load and a
+// barrier at its end are logged:
+//
+//  1. BeginBulkLoad forces a BULK LOAD record naming the first page
+//  2. Each page is allocated at the end of the file, locked, filled by the
+//     caller and written with WritePage
+//  3. Finish syncs the file and logs a barrier with the new relation size
+//
+// Until the transaction ends no other transaction can add pages to the
+// table, so every page from the first one on belongs to the load. If the
+// transaction aborts, or crashes before its COMMIT, those pages are zeroed.
+type BulkLoader struct {
+	store *PageStore
+	ctx   TxContext
+	file  BulkFile
+	load  record.BulkLoad
+	pages int
+}
+
+// BeginBulkLoad starts an unlogged bulk load into file for ctx. The table is
+// reserved for ctx until it commits or aborts: other transactions that would
+// add pages to it fail with ErrBulkLoadInProgress.
+func (p *PageStore) BeginBulkLoad(ctx TxContext, file BulkFile) (*BulkLoader, error) {
+	if ctx.IsReadOnly() {
+		return nil, transaction.ErrReadOnlyTransaction
+	}
+	if err := ctx.EnsureBegunInWAL(p.wal); err != nil {
+		return nil, err
+	}
+	start, err := p.reserveForBulkLoad(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	load := record.BulkLoad{Path: file.FilePath(), StartPage: start}
+	lsn, err := p.wal.LogBulkLoad(ctx.ID, load)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log bulk load to WAL: %v", err)
+	}
+	ctx.AddBulkLoad(lsn, load)
+
+	return &BulkLoader{store: p, ctx: ctx, file: file, load: load}, nil
+}
+
+// NextPage allocates the next page of the load and locks it exclusively.
+func (l *BulkLoader) NextPage() (*page.PageDescriptor, error) {
+	pageNo, err := l.file.AllocateNewPage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate new page: %v", err)
+	}
+
+	pid := page.NewPageDescriptor(l.file.GetID(), pageNo)
+	if _, err := l.store.lockManager.LockPageWait(l.ctx.ID, pid, true); err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	l.ctx.RecordPageAccess(pid, transaction.ReadWrite)
+	return pid, nil
+}
+
+// WritePage writes pg, a page returned by NextPage, to the file without
+// logging it.
+func (l *BulkLoader) WritePage(pg page.Page) error {
+	if err := l.file.WritePage(pg); err != nil {
+		return fmt.Errorf("failed to write page %v: %v", pg.GetID(), err)
+	}
+
+	l.store.mutex.Lock()
+	l.store.cache.Remove(pg.GetID())
+	l.store.mutex.Unlock()
+
+	l.pages++
+	return nil
+}
+
+// Finish syncs the loaded pages and logs the barrier, returning the number
+// of pages written. The pages are durable once Finish returns; they become
+// visible to other transactions when ctx commits.
+func (l *BulkLoader) Finish() (int, error) {
+	if err := l.file.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync bulk loaded pages: %v", err)
+	}
+
+	end, err := l.file.NumPages()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of pages: %v", err)
+	}
+	l.load.EndPage = end
+	if _, err := l.store.wal.LogBulkLoadBarrier(l.ctx.ID, l.load); err != nil {
+		return 0, fmt.Errorf("failed to log bulk load barrier to WAL: %v", err)
+	}
+	return l.pages, nil
+}
+
+// Cancel zeroes the pages written so far, for a load that failed. The table
+// stays reserved until ctx ends.
+func (l *BulkLoader) Cancel() error {
+	return l.store.undoBulkLoads([]transaction.BulkLoad{{Load: l.load}})
+}
+
+// AllocatePage appends a page to file for ctx, failing with
+// ErrBulkLoadInProgress while another transaction bulk loads the table.
+// Page allocations and the start of bulk loads are serialized, so a page
+// allocated here is never mistaken for part of a load.
+func (p *PageStore) AllocatePage(ctx TxContext, file BulkFile) (primitives.PageNumber, error) {
+	p.bulkMutex.RLock()
+	defer p.bulkMutex.RUnlock()
+
+	if owner, ok := p.bulkLoads[file.GetID()]; ok && owner != ctx.ID {
+		return 0, ErrBulkLoadInProgress
+	}
+	return file.AllocateNewPage()
+}
+
+// reserveForBulkLoad reserves file for a bulk load by ctx and returns its
+// first page, the number of pages it has before the load.
+func (p *PageStore) reserveForBulkLoad(ctx TxContext, file BulkFile) (primitives.PageNumber, error) {
+	p.bulkMutex.Lock()
+	defer p.bulkMutex.Unlock()
+
+	fileID := file.GetID()
+	if owner, ok := p.bulkLoads[fileID]; ok && owner != ctx.ID {
+		return 0, ErrBulkLoadInProgress
+	}
+	start, err := file.NumPages()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of pages: %v", err)
+	}
+	p.bulkLoads[fileID] = ctx.ID
+	return start, nil
+}
+
+// endBulkLoads releases the tables tid reserved for bulk loads.
+func (p *PageStore) endBulkLoads(tid *primitives.TransactionID) {
+	p.bulkMutex.Lock()
+	defer p.bulkMutex.Unlock()
+
+	for fileID, owner := range p.bulkLoads {
+		if owner == tid {
+			delete(p.bulkLoads, fileID)
+		}
+	}
+}
+
+// undoBulkLoads zeroes the pages of loads on disk and drops any copy of them
+// from the cache. Without a barrier the pages run to the end of the file,
+// which only the loading transaction can have extended.
+func (p *PageStore) undoBulkLoads(loads []transaction.BulkLoad) error {
+	for _, b := range loads {
+		if err := b.Load.Undo(p.FS()); err != nil {
+			return fmt.Errorf("failed to undo bulk load of %s: %v", b.Load.Path, err)
+		}
+
+		fileID := b.Load.Path.Hash()
+		p.mutex.Lock()
+		for _, pid := range p.cache.GetAll() {
+			if pid.FileID() == fileID && pid.PageNo() >= b.Load.StartPage {
+				p.cache.Remove(pid)
+				delete(p.pageLSNs, primitives.KeyOf(pid))
+			}
+		}
+		bufferPoolPages.Set(int64(p.cache.Size()))
+		p.mutex.Unlock()
+	}
+	return nil
+}
@@ -14,6 +14,10 @@ import (
 type PageCache interface {
 	Get(pid primitives.PageID) (page.Page, bool)
 
+	// Peek returns a cached page like Get, without marking it as recently
+	// used.
+	Peek(pid primitives.PageID) (page.Page, bool)
+
 	Put(pid primitives.PageID, p page.Page) error
 
 	Remove(pid primitives.PageID)
@@ -23,6 +27,10 @@ type PageCache interface {
 	Clear()
 
 	GetAll() []primitives.PageID
+
+	// SetMaxSize changes how many pages the cache holds at most. Pages
+	// already cached are kept, even past a smaller size.
+	SetMaxSize(maxSize int)
 }
 
 // cacheEntry represents a cache entry containing the page ID and page data
@@ -66,6 +74,18 @@ func (c *LRUPageCache) Get(pid primitives.PageID) (page.Page, bool) {
 	return nil, false
 }
 
+// Peek retrieves a page from the cache by its page ID, leaving its place in
+// the LRU order unchanged.
+func (c *LRUPageCache) Peek(pid primitives.PageID) (page.Page, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if elem, exists := c.cache[pid.HashCode()]; exists {
+		return elem.Value.(*cacheEntry).page, true
+	}
+	return nil, false
+}
+
 // Put stores a page in the cache with the given page ID.
 // If the page already exists, it updates the existing page and marks it as recently used.
 // If the cache is at maximum capacity and the page doesn't exist, returns an error.
@@ -95,6 +115,15 @@ func (c *LRUPageCache) Put(pid primitives.PageID, p page.Page) error {
 	return nil
 }
 
+// SetMaxSize changes the maximum number of pages in the cache. Shrinking it
+// below Size keeps the pages cached but refuses new ones until enough are
+// removed.
+func (c *LRUPageCache) SetMaxSize(maxSize int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxSize = maxSize
+}
+
 // Remove removes a page from the cache by its page ID.
 // Does nothing if the page doesn't exist in the cache.
 func (c *LRUPageCache) Remove(pid primitives.PageID) {
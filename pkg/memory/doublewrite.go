@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+	"sync"
+)
+
+// DoubleWriteFile is the name of the double-write buffer in the directory of
+// a database.
+const DoubleWriteFile = "doublewrite.buf"
+
+// A crash in the middle of a page write can leave the page half old and half
+// new on disk. Redo cannot repair such a torn page, since its records
+// describe tuples, not whole pages, and neither can undo. The double-write
+// buffer keeps a copy of every page while it is written in place: a batch of
+// page images is written to the buffer and synced first, then the pages are
+// written to their files and synced, and then the buffer is emptied. A crash
+// tears either the buffer, whose checksum then fails and which is ignored
+// since no page was written in place yet, or the pages, which
+// RecoverDoubleWrite restores from the intact buffer.
+//
+// Emptying the buffer is synced too. Pages have no checksums to tell a torn
+// page from an intact one, so every page of a batch found at startup is
+// restored, and a batch left behind after its pages reached disk could put
+// back images older than pages written since without the buffer, such as
+// those of a bulk load or of a table recreated under the same name.
+//
+// Batch format:
+//
+//	[Magic:4][Count:4]{[PathLen:2][Path][PageNo:4][Page:PageSize]}...[CRC32C:4]
+var doubleWriteMagic = []byte("SMDW")
+
+var doubleWriteCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// filePathIO is a page file that knows its path, such as any file built on
+// page.BaseFile. Only its pages can be restored from the double-write
+// buffer.
+type filePathIO interface {
+	FilePath() primitives.Filepath
+}
+
+// doubleWritePage is the image of a page written in place.
+type doubleWritePage struct {
+	path   primitives.Filepath
+	pageNo primitives.PageNumber
+	data   []byte
+}
+
+// doubleWriteBuffer is the double-write buffer of a PageStore.
+type doubleWriteBuffer struct {
+	mu   sync.Mutex // Held from writing a batch until its pages are written in place
+	path string
+	file vfs.File
+}
+
+// write makes the images durable in the buffer, then calls writeInPlace,
+// which writes the pages to their files and syncs them, and then empties the
+// buffer. If writeInPlace fails, the batch is kept so that a crash before
+// the pages are written again still restores them.
+func (dw *doubleWriteBuffer) write(images []doubleWritePage, writeInPlace func() error) error {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+
+	if len(images) > 0 {
+		if _, err := dw.file.WriteAt(encodeDoubleWrite(images), 0); err != nil {
+			return fmt.Errorf("failed to write double-write buffer: %w", err)
+		}
+		if err := dw.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync double-write buffer: %w", err)
+		}
+		doubleWritePages.Add(int64(len(images)))
+	}
+
+	if err := writeInPlace(); err != nil {
+		return err
+	}
+
+	if len(images) > 0 {
+		if err := dw.file.Truncate(0); err != nil {
+			return fmt.Errorf("failed to empty double-write buffer: %w", err)
+		}
+		if err := dw.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync double-write buffer: %w", err)
+		}
+	}
+	return nil
+}
+
+func encodeDoubleWrite(images []doubleWritePage) []byte {
+	size := len(doubleWriteMagic) + 4 + 4
+	for _, img := range images {
+		size += 2 + len(img.path) + 4 + page.PageSize
+	}
+
+	buf := make([]byte, 0, size)
+	buf = append(buf, doubleWriteMagic...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(images)))
+	for _, img := range images {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(img.path)))
+		buf = append(buf, img.path...)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(img.pageNo))
+		buf = append(buf, img.data...)
+	}
+	return binary.BigEndian.AppendUint32(buf, crc32.Checksum(buf, doubleWriteCRC))
+}
+
+// decodeDoubleWrite returns the images of the batch in data, and false if
+// data holds no complete batch, such as a buffer torn while it was written.
+func decodeDoubleWrite(data []byte) ([]doubleWritePage, bool) {
+	const header = 4 + 4
+	if len(data) < header+4 || string(data[:4]) != string(doubleWriteMagic) {
+		return nil, false
+	}
+	body, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.Checksum(body, doubleWriteCRC) != sum {
+		return nil, false
+	}
+
+	count := binary.BigEndian.Uint32(body[4:header])
+	images := make([]doubleWritePage, 0, min(count, 1024))
+	rest := body[header:]
+	for range count {
+		if len(rest) < 2 {
+			return nil, false
+		}
+		pathLen := int(binary.BigEndian.Uint16(rest))
+		if len(rest) < 2+pathLen+4+page.PageSize {
+			return nil, false
+		}
+		rest = rest[2:]
+		img := doubleWritePage{path: primitives.Filepath(rest[:pathLen])}
+		rest = rest[pathLen:]
+		img.pageNo = primitives.PageNumber(binary.BigEndian.Uint32(rest))
+		img.data = rest[4 : 4+page.PageSize]
+		rest = rest[4+page.PageSize:]
+		images = append(images, img)
+	}
+	if len(rest) != 0 {
+		return nil, false
+	}
+	return images, true
+}
+
+// RecoverDoubleWrite restores the pages of a batch a crash left in the
+// double-write buffer at path, and then removes the buffer. It must run
+// when the database is opened, before its page files are read. A page whose
+// file no longer exists is skipped. It returns how many pages it restored.
+func (p *PageStore) RecoverDoubleWrite(path string) (int, error) {
+	fsys := p.FS()
+	data, err := fsys.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read double-write buffer: %w", err)
+	}
+
+	restored := 0
+	images, ok := decodeDoubleWrite(data)
+	for _, img := range images {
+		f, err := fsys.OpenFile(string(img.path), os.O_RDWR, 0644)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return restored, fmt.Errorf("failed to open %s to restore page %d: %w", img.path, img.pageNo, err)
+		}
+		_, err = f.WriteAt(img.data, int64(img.pageNo)*int64(page.PageSize))
+		if err == nil {
+			err = f.Sync()
+		}
+		f.Close()
+		if err != nil {
+			return restored, fmt.Errorf("failed to restore page %d of %s: %w", img.pageNo, img.path, err)
+		}
+		restored++
+	}
+	if ok {
+		doubleWriteRestores.Add(int64(restored))
+	}
+
+	if err := fsys.Remove(path); err != nil {
+		return restored, fmt.Errorf("failed to remove double-write buffer: %w", err)
+	}
+	return restored, nil
+}
+
+// EnableDoubleWrite protects the pages the store writes from being torn by
+// a crash with a double-write buffer at path, on the file system of the
+// page files. RecoverDoubleWrite must have run first: an existing buffer is
+// emptied. Every page flushed at commit, by FlushAllPages or by WritePage
+// is then written twice, which costs two syncs per batch of pages.
+func (p *PageStore) EnableDoubleWrite(path string) error {
+	file, err := p.FS().OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open double-write buffer: %w", err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.doubleWrite != nil {
+		p.doubleWrite.file.Close()
+	}
+	p.doubleWrite = &doubleWriteBuffer{path: path, file: file}
+	return nil
+}
+
+// DisableDoubleWrite stops writing pages through the double-write buffer and
+// removes it. Pages written afterwards are no longer protected, so it is
+// meant for shutdown, once every page has been flushed.
+func (p *PageStore) DisableDoubleWrite() error {
+	p.mutex.Lock()
+	dw := p.doubleWrite
+	p.doubleWrite = nil
+	p.mutex.Unlock()
+	if dw == nil {
+		return nil
+	}
+
+	// Wait for a batch being written in place
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if err := dw.file.Close(); err != nil {
+		return fmt.Errorf("failed to close double-write buffer: %w", err)
+	}
+	if err := p.FS().Remove(dw.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove double-write buffer: %w", err)
+	}
+	return nil
+}
+
+// doubleWriter returns the double-write buffer, nil if it is off.
+func (p *PageStore) doubleWriter() *doubleWriteBuffer {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.doubleWrite
+}
+
+// flushPagesDoubleWrite is the flush of the dirty pages among pids through
+// the double-write buffer: the pages' latches are held from taking their
+// images until they are written in place, so the buffer holds exactly what
+// reaches the files.
+func (p *PageStore) flushPagesDoubleWrite(dw *doubleWriteBuffer, pids []primitives.PageID) error {
+	unlatch := p.latches.lockAll(pids)
+	defer unlatch()
+
+	type pendingWrite struct {
+		pid    primitives.PageID
+		pageIO page.PageIO
+		page   page.Page
+		lsn    primitives.LSN
+		logged bool
+	}
+	var writes []pendingWrite
+	var images []doubleWritePage
+	for _, pid := range pids {
+		pg, exists := p.cache.Get(pid)
+		if !exists || pg.IsDirty() == nil {
+			continue
+		}
+		pageIO, err := p.getDbFileForPage(pid)
+		if err != nil {
+			return fmt.Errorf("failed to get dbFile for page %v: %v", pid, err)
+		}
+		lsn, logged, err := p.forceLog(pid)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, pendingWrite{pid: pid, pageIO: pageIO, page: pg, lsn: lsn, logged: logged})
+		if f, ok := pageIO.(filePathIO); ok {
+			images = append(images, doubleWritePage{path: f.FilePath(), pageNo: pid.PageNo(), data: pg.GetPageData()})
+		}
+	}
+
+	return dw.write(images, func() error {
+		for _, w := range writes {
+			if err := w.pageIO.WritePage(w.page); err != nil {
+				return fmt.Errorf("failed to flush page %v: failed to write page to disk: %v", w.pid, err)
+			}
+			bufferPoolPagesWritten.Inc()
+			w.page.MarkDirty(false, nil)
+
+			p.mutex.Lock()
+			p.cache.Put(w.pid, w.page)
+			if w.logged {
+				p.clearPageLSN(w.pid, w.lsn)
+			}
+			p.mutex.Unlock()
+		}
+		return p.syncFiles(pids)
+	})
+}
+
+// writePageDoubleWrite is WritePage through the double-write buffer.
+func (p *PageStore) writePageDoubleWrite(dw *doubleWriteBuffer, pageIO page.PageIO, pg page.Page) error {
+	var images []doubleWritePage
+	if f, ok := pageIO.(filePathIO); ok {
+		images = append(images, doubleWritePage{path: f.FilePath(), pageNo: pg.GetID().PageNo(), data: pg.GetPageData()})
+	}
+	return dw.write(images, func() error {
+		if err := pageIO.WritePage(pg); err != nil {
+			return fmt.Errorf("failed to write page to disk: %v", err)
+		}
+		return p.syncFile(pageIO)
+	})
+}
@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/types"
+	"storemy/pkg/vfs"
+	"testing"
+)
+
+// filePageIO writes its pages to a file, and can tear a write: write the
+// first half of the page and fail, as a crash in the middle of the write
+// would
+type filePageIO struct {
+	*mockDbFileForPageStore
+	file vfs.File
+	path primitives.Filepath
+	tear bool
+}
+
+func (f *filePageIO) FilePath() primitives.Filepath {
+	return f.path
+}
+
+func (f *filePageIO) WritePage(p page.Page) error {
+	data := p.GetPageData()
+	offset := int64(p.GetID().PageNo()) * int64(page.PageSize)
+	if f.tear {
+		f.file.WriteAt(data[:len(data)/2], offset)
+		return errors.New("crashed while writing page")
+	}
+	if _, err := f.file.WriteAt(data, offset); err != nil {
+		return err
+	}
+	return f.mockDbFileForPageStore.WritePage(p)
+}
+
+func setupDoubleWrite(t *testing.T) (*PageStore, vfs.FS, *filePageIO) {
+	t.Helper()
+	fsys := vfs.NewMemFS()
+	w, err := wal.NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+
+	file, err := fsys.OpenFile("/table.dat", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := file.WriteAt(make([]byte, page.PageSize), 0); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+
+	ps := NewPageStore(w)
+	dbFile := &filePageIO{
+		mockDbFileForPageStore: newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"}),
+		file:                   file,
+		path:                   "/table.dat",
+	}
+	ps.RegisterDbFile(1, dbFile)
+	if err := ps.EnableDoubleWrite("/" + DoubleWriteFile); err != nil {
+		t.Fatalf("EnableDoubleWrite failed: %v", err)
+	}
+	return ps, fsys, dbFile
+}
+
+// dirtyPage caches a dirty page of table 1 filled with b
+func dirtyPage(ps *PageStore, pageNo int, b byte) *mockPage {
+	pg := newMockPage(page.NewPageDescriptor(1, pgNum(pageNo)))
+	for i := range pg.data {
+		pg.data[i] = b
+	}
+	pg.MarkDirty(true, primitives.NewTransactionIDFromValue(1))
+	ps.cache.Put(pg.GetID(), pg)
+	return pg
+}
+
+func TestDoubleWrite_EncodeDecode(t *testing.T) {
+	images := []doubleWritePage{
+		{path: "/a.dat", pageNo: 0, data: bytes.Repeat([]byte{1}, page.PageSize)},
+		{path: "/dir/b.idx", pageNo: 7, data: bytes.Repeat([]byte{2}, page.PageSize)},
+	}
+	data := encodeDoubleWrite(images)
+
+	decoded, ok := decodeDoubleWrite(data)
+	if !ok || len(decoded) != 2 {
+		t.Fatalf("decodeDoubleWrite returned %d images, %v", len(decoded), ok)
+	}
+	for i, img := range decoded {
+		if img.path != images[i].path || img.pageNo != images[i].pageNo || !bytes.Equal(img.data, images[i].data) {
+			t.Errorf("image %d = %s:%d, want %s:%d", i, img.path, img.pageNo, images[i].path, images[i].pageNo)
+		}
+	}
+
+	// A batch torn while it was written is not a batch
+	if _, ok := decodeDoubleWrite(data[:len(data)-100]); ok {
+		t.Error("decodeDoubleWrite accepted a truncated batch")
+	}
+	data[20] ^= 0xFF
+	if _, ok := decodeDoubleWrite(data); ok {
+		t.Error("decodeDoubleWrite accepted a corrupt batch")
+	}
+}
+
+func TestDoubleWrite_RestoresTornPage(t *testing.T) {
+	ps, fsys, dbFile := setupDoubleWrite(t)
+	dirtyPage(ps, 0, 0xAB)
+
+	dbFile.tear = true
+	if err := ps.FlushAllPages(); err == nil {
+		t.Fatal("FlushAllPages succeeded although the page write failed")
+	}
+
+	// The page on disk is half old and half new
+	onDisk := make([]byte, page.PageSize)
+	dbFile.file.ReadAt(onDisk, 0)
+	if onDisk[0] != 0xAB || onDisk[page.PageSize-1] != 0 {
+		t.Fatal("expected a torn page on disk")
+	}
+
+	restored, err := ps.RecoverDoubleWrite("/" + DoubleWriteFile)
+	if err != nil {
+		t.Fatalf("RecoverDoubleWrite failed: %v", err)
+	}
+	if restored != 1 {
+		t.Errorf("RecoverDoubleWrite restored %d pages, want 1", restored)
+	}
+	dbFile.file.ReadAt(onDisk, 0)
+	if !bytes.Equal(onDisk, bytes.Repeat([]byte{0xAB}, page.PageSize)) {
+		t.Error("page was not restored from the double-write buffer")
+	}
+	if _, err := fsys.Stat("/" + DoubleWriteFile); !os.IsNotExist(err) {
+		t.Errorf("double-write buffer still exists after recovery: %v", err)
+	}
+}
+
+func TestDoubleWrite_EmptiedAfterWrite(t *testing.T) {
+	ps, fsys, dbFile := setupDoubleWrite(t)
+	dirtyPage(ps, 0, 0x01)
+	dirtyPage(ps, 1, 0x02)
+
+	before := doubleWritePages.Value()
+	if err := ps.handleCommit([]primitives.PageID{page.NewPageDescriptor(1, 0), page.NewPageDescriptor(1, 1)}); err != nil {
+		t.Fatalf("handleCommit failed: %v", err)
+	}
+	if n := doubleWritePages.Value() - before; n != 2 {
+		t.Errorf("%d pages double-written, want 2", n)
+	}
+
+	onDisk := make([]byte, page.PageSize)
+	dbFile.file.ReadAt(onDisk, int64(page.PageSize))
+	if onDisk[0] != 0x02 {
+		t.Error("page 1 was not written in place")
+	}
+	if info, err := fsys.Stat("/" + DoubleWriteFile); err != nil || info.Size() != 0 {
+		t.Errorf("double-write buffer not emptied after the write: %v", err)
+	}
+
+	// Nothing is left to restore
+	if err := ps.DisableDoubleWrite(); err != nil {
+		t.Fatalf("DisableDoubleWrite failed: %v", err)
+	}
+	if _, err := fsys.Stat("/" + DoubleWriteFile); !os.IsNotExist(err) {
+		t.Errorf("double-write buffer still exists after DisableDoubleWrite: %v", err)
+	}
+	if restored, err := ps.RecoverDoubleWrite("/" + DoubleWriteFile); err != nil || restored != 0 {
+		t.Errorf("RecoverDoubleWrite = %d, %v; want nothing to restore", restored, err)
+	}
+}
+
+func TestDoubleWrite_IgnoresTornBuffer(t *testing.T) {
+	ps, fsys, dbFile := setupDoubleWrite(t)
+	images := []doubleWritePage{{path: "/table.dat", pageNo: 0, data: bytes.Repeat([]byte{0xCD}, page.PageSize)}}
+	data := encodeDoubleWrite(images)
+	if err := fsys.WriteFile("/"+DoubleWriteFile, data[:len(data)/2], 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	restored, err := ps.RecoverDoubleWrite("/" + DoubleWriteFile)
+	if err != nil || restored != 0 {
+		t.Fatalf("RecoverDoubleWrite = %d, %v; want a torn buffer ignored", restored, err)
+	}
+	onDisk := make([]byte, page.PageSize)
+	dbFile.file.ReadAt(onDisk, 0)
+	if onDisk[0] != 0 {
+		t.Error("a torn batch was written to the page file")
+	}
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"fmt"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+// LogFileOp logs that ctx deletes or renames a data file. The operation is
+// deferred: it happens once ctx has committed and its COMMIT is durable, and
+// not at all if ctx aborts. Recovery finishes it if the database crashes in
+// between, so the file system always ends up matching the catalog.
+func (p *PageStore) LogFileOp(ctx TxContext, op record.FileOperation) error {
+	if err := ctx.EnsureBegunInWAL(p.wal); err != nil {
+		return err
+	}
+
+	lsn, err := p.wal.LogFileOp(ctx.ID, op)
+	if err != nil {
+		return fmt.Errorf("failed to log %s to WAL: %v", op, err)
+	}
+	ctx.AddFileOp(lsn, op)
+	return nil
+}
+
+// finishFileOps performs the file operations of ctx, which committed at
+// commitLSN. The transaction has committed whatever happens here, so an
+// operation that fails is left for recovery to finish rather than reported.
+func (p *PageStore) finishFileOps(ctx TxContext, commitLSN primitives.LSN) {
+	fileOps := ctx.FileOps()
+	if len(fileOps) == 0 {
+		return
+	}
+
+	if err := p.wal.WaitForDurability(commitLSN); err != nil {
+		p.wal.Logger().Warn("commit not durable, leaving file operations to recovery", "tx_id", ctx.ID.ID(), "error", err)
+		return
+	}
+
+	for _, f := range fileOps {
+		if f.Op.Kind == record.FileOpDelete && p.GetDbFile(f.Op.Path.Hash()) != nil {
+			// The transaction dropped the file and then created it again
+			if _, err := p.wal.LogFileOpDone(ctx.ID, f.LSN); err != nil {
+				p.wal.Logger().Warn("failed to log dropped file operation", "tx_id", ctx.ID.ID(), "lsn", f.LSN, "error", err)
+			}
+			continue
+		}
+		if err := p.wal.FinishFileOp(ctx.ID, f.LSN, f.Op); err != nil {
+			p.wal.Logger().Warn("file operation failed, leaving it to recovery", "tx_id", ctx.ID.ID(), "lsn", f.LSN, "op", f.Op.String(), "error", err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"storemy/pkg/invariant"
+	"storemy/pkg/primitives"
+	"sync"
+	"time"
+)
+
+// latchStripes is the number of latches in a latch table. Pages hash onto
+// them, so two pages contend only when they share a stripe.
+const latchStripes = 64
+
+// latchTable holds the page latches of a PageStore: short-term reader/writer
+// mutexes guarding the cache entry of a page during a single buffer pool
+// operation, where page locks are held for the whole transaction. Readers of
+// a cached page share its latch; reading a page in, logging a change to it
+// and flushing it take the latch exclusively, so each page has a single
+// writer while operations on unrelated pages proceed in parallel.
+//
+// The table is striped rather than holding a latch per page, which keeps it
+// a fixed size however many pages pass through the pool.
+//
+// Latches are taken before p.mutex, never after it, except by eviction,
+// which only tries them.
+type latchTable struct {
+	stripes [latchStripes]sync.RWMutex
+}
+
+func (lt *latchTable) latch(pid primitives.PageID) *sync.RWMutex {
+	return &lt.stripes[uint64(pid.HashCode())%latchStripes]
+}
+
+// lock acquires the latch of pid, exclusively or shared, and returns the
+// function that releases it. Waiting for the latch is counted in the latch
+// contention metrics.
+func (lt *latchTable) lock(pid primitives.PageID, exclusive bool) (unlock func()) {
+	l := lt.latch(pid)
+	if exclusive {
+		if !l.TryLock() {
+			waitForLatch(l.Lock)
+		}
+		return l.Unlock
+	}
+	if !l.TryRLock() {
+		waitForLatch(l.RLock)
+	}
+	return l.RUnlock
+}
+
+// lockAll acquires the exclusive latches of pids and returns the function
+// that releases them. Each stripe is taken once, in stripe order, so two
+// callers latching overlapping sets of pages cannot deadlock.
+func (lt *latchTable) lockAll(pids []primitives.PageID) (unlock func()) {
+	var taken [latchStripes]bool
+	for _, pid := range pids {
+		taken[uint64(pid.HashCode())%latchStripes] = true
+	}
+	for i := range lt.stripes {
+		if taken[i] && !lt.stripes[i].TryLock() {
+			waitForLatch(lt.stripes[i].Lock)
+		}
+	}
+	return func() {
+		for i := range lt.stripes {
+			if taken[i] {
+				lt.stripes[i].Unlock()
+			}
+		}
+	}
+}
+
+// tryLock acquires the exclusive latch of pid if it is free, and reports
+// whether it did.
+func (lt *latchTable) tryLock(pid primitives.PageID) (unlock func(), ok bool) {
+	l := lt.latch(pid)
+	if !l.TryLock() {
+		return nil, false
+	}
+	return l.Unlock, true
+}
+
+// assertHeld fails an invariant check, when assertions are on, if the
+// exclusive latch of pid is not held. Latches do not record their owner, so
+// this catches a caller that forgot the latch, not one relying on a latch
+// another goroutine happens to hold.
+func (lt *latchTable) assertHeld(pid primitives.PageID) {
+	if !invariant.Enabled() {
+		return
+	}
+	if l := lt.latch(pid); l.TryRLock() {
+		l.RUnlock()
+		invariant.Failf("page %v changed without its exclusive latch", pid)
+	}
+}
+
+func waitForLatch(lock func()) {
+	start := time.Now()
+	lock()
+	bufferPoolLatchWaits.Inc()
+	bufferPoolLatchWaitSeconds.Observe(time.Since(start).Seconds())
+}
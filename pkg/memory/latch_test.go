@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"path/filepath"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/invariant"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/types"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowPageIO counts the pages read through it, each read taking a while so
+// that concurrent requests overlap
+type slowPageIO struct {
+	*mockDbFileForPageStore
+	reads atomic.Int32
+}
+
+func (s *slowPageIO) ReadPage(pid *page.PageDescriptor) (page.Page, error) {
+	s.reads.Add(1)
+	time.Sleep(10 * time.Millisecond)
+	return s.mockDbFileForPageStore.ReadPage(pid)
+}
+
+// TestGetPage_ConcurrentMissesReadOnce tests that transactions missing the
+// same page at once read it from disk a single time
+func TestGetPage_ConcurrentMissesReadOnce(t *testing.T) {
+	wal, err := wal.NewWAL(filepath.Join(t.TempDir(), "test.wal"), 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	dbFile := &slowPageIO{mockDbFileForPageStore: newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"})}
+	pid := page.NewPageDescriptor(1, 0)
+
+	const readers = 8
+	var wg sync.WaitGroup
+	for range readers {
+		ctx := createTransactionContext(t, wal)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ps.GetPage(ctx, dbFile, pid, transaction.ReadOnly); err != nil {
+				t.Errorf("GetPage failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := dbFile.reads.Load(); n != 1 {
+		t.Errorf("%d concurrent misses read the page %d times, want once", readers, n)
+	}
+}
+
+func TestLatchTable_CountsContention(t *testing.T) {
+	var lt latchTable
+	pid := page.NewPageDescriptor(1, 0)
+
+	other := page.NewPageDescriptor(1, 1)
+	if lt.latch(pid) == lt.latch(other) {
+		t.Fatal("expected pages 0 and 1 on different stripes")
+	}
+
+	before := bufferPoolLatchWaits.Value()
+	unlock := lt.lock(pid, true)
+
+	// A page on another stripe is not held up by the exclusive latch
+	lt.lock(other, true)()
+
+	done := make(chan struct{})
+	go func() {
+		lt.lock(pid, false)()
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	unlock()
+	<-done
+
+	if got := bufferPoolLatchWaits.Value() - before; got != 1 {
+		t.Errorf("counted %d latch waits, want 1", got)
+	}
+}
+
+func TestLatchTable_AssertHeld(t *testing.T) {
+	var lt latchTable
+	pid := page.NewPageDescriptor(1, 0)
+
+	unlock := lt.lock(pid, true)
+	lt.assertHeld(pid)
+	unlock()
+
+	// A shared latch does not allow changing the page
+	unlock = lt.lock(pid, false)
+	defer unlock()
+	defer func() {
+		if _, ok := recover().(*invariant.Violation); !ok {
+			t.Error("expected an invariant violation for a page latched shared")
+		}
+	}()
+	lt.assertHeld(pid)
+}
@@ -0,0 +1,55 @@
+package memory
+
+import "storemy/pkg/metrics"
+
+var (
+	bufferPoolHits = metrics.NewCounter(
+		"storemy_buffer_pool_hits_total",
+		"Page requests served from the buffer pool",
+	)
+	bufferPoolMisses = metrics.NewCounter(
+		"storemy_buffer_pool_misses_total",
+		"Page requests that had to read the page from disk",
+	)
+	bufferPoolEvictions = metrics.NewCounter(
+		"storemy_buffer_pool_evictions_total",
+		"Clean pages evicted to make room in the buffer pool",
+	)
+	bufferPoolScanReads = metrics.NewCounter(
+		"storemy_buffer_pool_scan_reads_total",
+		"Pages large sequential scans read into their scan ring instead of the buffer pool",
+	)
+	bufferPoolPagesWritten = metrics.NewCounter(
+		"storemy_buffer_pool_pages_written_total",
+		"Dirty pages flushed from the buffer pool to disk",
+	)
+	bufferPoolLatchWaits = metrics.NewCounter(
+		"storemy_buffer_pool_latch_waits_total",
+		"Page latch acquisitions that had to wait for another operation on a page sharing the latch",
+	)
+	bufferPoolLatchWaitSeconds = metrics.NewHistogram(
+		"storemy_buffer_pool_latch_wait_seconds",
+		"Time spent waiting for contended page latches",
+		metrics.DefaultLatencyBuckets,
+	)
+	bufferPoolPages = metrics.NewGauge(
+		"storemy_buffer_pool_pages",
+		"Pages currently held in the buffer pool",
+	)
+	bufferPoolCapacity = metrics.NewGauge(
+		"storemy_buffer_pool_capacity_pages",
+		"Pages the buffer pool holds at most",
+	)
+	bufferPoolResizes = metrics.NewCounter(
+		"storemy_buffer_pool_resizes_total",
+		"Changes of the buffer pool capacity",
+	)
+	doubleWritePages = metrics.NewCounter(
+		"storemy_double_write_pages_total",
+		"Page images written to the double-write buffer before their pages were written in place",
+	)
+	doubleWriteRestores = metrics.NewCounter(
+		"storemy_double_write_restored_pages_total",
+		"Pages restored from the double-write buffer when a database was opened",
+	)
+)
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"fmt"
+	"storemy/pkg/primitives"
+	"strings"
+)
+
+// CachePriority is how readily the buffer pool evicts the pages of a table.
+// Eviction takes the least recently used page of the lowest priority that
+// has a clean, unlocked page, so pages of a higher priority stay cached
+// while lower priority pages can make room for new ones.
+type CachePriority uint8
+
+const (
+	// CacheNormal pages are evicted in LRU order. It is the priority of
+	// every table without a hint.
+	CacheNormal CachePriority = iota
+
+	// CacheHigh pages are evicted only when no normal page can be.
+	CacheHigh
+
+	// CachePinned pages are evicted only when no other page can be, which
+	// keeps a small hot table cached once read. A pool holding nothing but
+	// pinned pages still evicts them rather than refusing new pages, so
+	// pinning a table larger than the pool degrades to LRU among its pages.
+	CachePinned
+)
+
+func (cp CachePriority) String() string {
+	switch cp {
+	case CacheNormal:
+		return "normal"
+	case CacheHigh:
+		return "high"
+	case CachePinned:
+		return "pinned"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseCachePriority parses a cache priority name as returned by
+// CachePriority.String, ignoring case.
+func ParseCachePriority(s string) (CachePriority, error) {
+	switch strings.ToLower(s) {
+	case "normal":
+		return CacheNormal, nil
+	case "high":
+		return CacheHigh, nil
+	case "pinned":
+		return CachePinned, nil
+	default:
+		return 0, fmt.Errorf("unknown cache priority %q (expected normal, high or pinned)", s)
+	}
+}
+
+// SetCachePriority sets the eviction priority of the pages of a table or
+// index file. Pages already cached take it on at once.
+func (p *PageStore) SetCachePriority(fileID primitives.FileID, priority CachePriority) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if priority == CacheNormal {
+		delete(p.priorities, fileID)
+		return
+	}
+	p.priorities[fileID] = priority
+}
+
+// CachePriority returns the eviction priority of the pages of a file.
+func (p *PageStore) CachePriority(fileID primitives.FileID) CachePriority {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.priorities[fileID]
+}
+
+// evictionOrder returns the cached pages in the order they are considered
+// for eviction: lowest priority first, least recently used first within a
+// priority. The caller must hold p.mutex.
+func (p *PageStore) evictionOrder() []primitives.PageID {
+	pids := p.cache.GetAll()
+	if len(p.priorities) == 0 {
+		return pids
+	}
+
+	var byPriority [CachePinned + 1][]primitives.PageID
+	for _, pid := range pids {
+		prio := p.priorities[pid.FileID()]
+		byPriority[prio] = append(byPriority[prio], pid)
+	}
+
+	ordered := pids[:0]
+	for _, group := range byPriority {
+		ordered = append(ordered, group...)
+	}
+	return ordered
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"path/filepath"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/types"
+	"testing"
+)
+
+// TestCachePriority_Eviction tests that a scan of a normal table larger than
+// the pool evicts its own pages before those of pinned and high priority tables
+func TestCachePriority_Eviction(t *testing.T) {
+	wal, err := wal.NewWAL(filepath.Join(t.TempDir(), "test.wal"), 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	if _, err := ps.SetCapacity(MinPageCount); err != nil {
+		t.Fatalf("SetCapacity failed: %v", err)
+	}
+	files := map[primitives.FileID]page.PageIO{}
+	for id := primitives.FileID(1); id <= 3; id++ {
+		files[id] = newMockDbFileForPageStore(int(id), []types.Type{types.IntType}, []string{"id"})
+	}
+	ps.SetCachePriority(2, CachePinned)
+	ps.SetCachePriority(3, CacheHigh)
+
+	read := func(fileID primitives.FileID, pages int) {
+		t.Helper()
+		for i := range pages {
+			ctx := createTransactionContext(t, wal)
+			pid := page.NewPageDescriptor(fileID, primitives.PageNumber(i))
+			if _, err := ps.GetPage(ctx, files[fileID], pid, transaction.ReadOnly); err != nil {
+				t.Fatalf("GetPage failed: %v", err)
+			}
+			ps.lockManager.UnlockAllPages(ctx.ID)
+		}
+	}
+	cached := func(fileID primitives.FileID, pages int) int {
+		n := 0
+		for i := range pages {
+			if _, ok := ps.cache.Get(page.NewPageDescriptor(fileID, primitives.PageNumber(i))); ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	read(2, 4)
+	read(3, 4)
+	read(1, 3*MinPageCount)
+
+	if n := cached(2, 4); n != 4 {
+		t.Errorf("%d of 4 pinned pages cached, want all", n)
+	}
+	if n := cached(3, 4); n != 4 {
+		t.Errorf("%d of 4 high priority pages cached, want all", n)
+	}
+
+	// A pinned table filling the pool evicts the high priority pages, then
+	// its own least recently used pages rather than failing
+	read(2, 2*MinPageCount)
+	if n := cached(3, 4); n != 0 {
+		t.Errorf("%d high priority pages cached, want none", n)
+	}
+	if n := cached(2, 2*MinPageCount); n != MinPageCount {
+		t.Errorf("%d pinned pages cached, want %d", n, MinPageCount)
+	}
+
+	ps.SetCachePriority(2, CacheNormal)
+	if got := ps.CachePriority(2); got != CacheNormal {
+		t.Errorf("CachePriority = %s after reset, want normal", got)
+	}
+}
+
+func TestParseCachePriority(t *testing.T) {
+	for _, prio := range []CachePriority{CacheNormal, CacheHigh, CachePinned} {
+		got, err := ParseCachePriority(prio.String())
+		if err != nil || got != prio {
+			t.Errorf("ParseCachePriority(%q) = %s, %v", prio.String(), got, err)
+		}
+	}
+	if _, err := ParseCachePriority("sticky"); err == nil {
+		t.Error("Expected an error for an unknown priority")
+	}
+}
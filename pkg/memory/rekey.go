@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// rekeyedFile is a page file that can re-encrypt its pages with the current
+// key, such as any file built on page.BaseFile.
+type rekeyedFile interface {
+	Rekey() (int, error)
+}
+
+// RekeyFiles re-encrypts with the current key every page of the registered
+// page files that was written with an older key, and makes the rewritten
+// pages durable. It returns how many pages it rewrote. Files that are not
+// encrypted are left alone.
+//
+// Pages are rewritten as they are on disk: a page still dirty in the cache is
+// encrypted with the current key when it is flushed anyway.
+func (p *PageStore) RekeyFiles() (int, error) {
+	p.mutex.RLock()
+	files := slices.Collect(maps.Values(p.dbFiles))
+	p.mutex.RUnlock()
+
+	total := 0
+	for _, pageIO := range files {
+		f, ok := pageIO.(rekeyedFile)
+		if !ok {
+			continue
+		}
+		n, err := f.Rekey()
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("failed to rekey page file: %v", err)
+		}
+		if err := p.syncFile(pageIO); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
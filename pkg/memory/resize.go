@@ -0,0 +1,60 @@
+package memory
+
+import "fmt"
+
+// MinPageCount is the smallest capacity SetCapacity accepts, enough pages for
+// a statement to hold the pages it locks at once, such as a B-tree split.
+const MinPageCount = 16
+
+// ResizeResult describes a change of the buffer pool capacity.
+type ResizeResult struct {
+	OldCapacity int
+	NewCapacity int
+	Evicted     int // Pages evicted to fit the new capacity
+	Excess      int // Pages still above the new capacity, dirty or locked
+}
+
+// SetCapacity changes how many pages the buffer pool holds at most, while
+// the database is running. Growing takes effect at once. Shrinking evicts
+// clean, unlocked pages in eviction order (lowest cache priority first, then
+// least recently used) until the pool fits; dirty and locked pages cannot be
+// evicted under NO-STEAL, so a pool may stay above its new capacity,
+// reported as Excess, until the transactions using them finish and later
+// page reads evict them.
+//
+// Returns an error if pages is below MinPageCount.
+func (p *PageStore) SetCapacity(pages int) (ResizeResult, error) {
+	if pages < MinPageCount {
+		return ResizeResult{}, fmt.Errorf("buffer pool capacity must be at least %d pages, got %d", MinPageCount, pages)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	result := ResizeResult{OldCapacity: p.capacity, NewCapacity: pages}
+	p.capacity = pages
+	p.cache.SetMaxSize(pages)
+
+	// One pass in eviction order, skipping the pages evictPage would skip
+	for _, pid := range p.evictionOrder() {
+		if p.cache.Size() <= pages {
+			break
+		}
+		if p.tryEvict(pid) {
+			result.Evicted++
+		}
+	}
+	result.Excess = max(p.cache.Size()-pages, 0)
+
+	bufferPoolCapacity.Set(int64(pages))
+	bufferPoolPages.Set(int64(p.cache.Size()))
+	bufferPoolResizes.Inc()
+	return result, nil
+}
+
+// Capacity returns how many pages the buffer pool holds at most.
+func (p *PageStore) Capacity() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.capacity
+}
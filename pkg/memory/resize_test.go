@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"path/filepath"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/types"
+	"testing"
+)
+
+// TestSetCapacity_ShrinkAndGrow tests resizing a buffer pool holding clean,
+// locked and dirty pages
+func TestSetCapacity_ShrinkAndGrow(t *testing.T) {
+	wal, err := wal.NewWAL(filepath.Join(t.TempDir(), "test.wal"), 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	dbFile := newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"})
+
+	// 40 clean unlocked pages, then 20 locked ones of which 10 are dirty
+	for i := range 40 {
+		ctx := createTransactionContext(t, wal)
+		if _, err := ps.GetPage(ctx, dbFile, page.NewPageDescriptor(1, primitives.PageNumber(i)), transaction.ReadOnly); err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		ps.lockManager.UnlockAllPages(ctx.ID)
+	}
+	holder := createTransactionContext(t, wal)
+	for i := 40; i < 60; i++ {
+		pid := page.NewPageDescriptor(1, primitives.PageNumber(i))
+		pg, err := ps.GetPage(holder, dbFile, pid, transaction.ReadWrite)
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		if i%2 == 0 {
+			pg.MarkDirty(true, holder.ID)
+		}
+	}
+
+	if _, err := ps.SetCapacity(MinPageCount - 1); err == nil {
+		t.Error("Expected an error below MinPageCount")
+	}
+
+	res, err := ps.SetCapacity(30)
+	if err != nil {
+		t.Fatalf("SetCapacity failed: %v", err)
+	}
+	if res.OldCapacity != MaxPageCount || res.Evicted != 30 || res.Excess != 0 {
+		t.Errorf("Shrinking to 30 = %+v, want 30 evicted", res)
+	}
+	if stats := ps.Stats(); stats.Capacity != 30 || stats.CachedPages != 30 {
+		t.Errorf("Stats = %+v, want 30 of 30 pages", stats)
+	}
+
+	// The locked pages stay, above the new capacity
+	res, err = ps.SetCapacity(MinPageCount)
+	if err != nil {
+		t.Fatalf("SetCapacity failed: %v", err)
+	}
+	if res.Evicted != 10 || res.Excess != 20-MinPageCount {
+		t.Errorf("Shrinking to %d = %+v, want 10 evicted and %d excess", MinPageCount, res, 20-MinPageCount)
+	}
+
+	ctx := createTransactionContext(t, wal)
+	if _, err := ps.GetPage(ctx, dbFile, page.NewPageDescriptor(1, 60), transaction.ReadOnly); err == nil {
+		t.Error("Expected GetPage to fail with every page locked")
+	}
+
+	res, err = ps.SetCapacity(100)
+	if err != nil {
+		t.Fatalf("SetCapacity failed: %v", err)
+	}
+	if res.Evicted != 0 || ps.Capacity() != 100 {
+		t.Errorf("Growing to 100 = %+v", res)
+	}
+	if _, err := ps.GetPage(ctx, dbFile, page.NewPageDescriptor(1, 60), transaction.ReadOnly); err != nil {
+		t.Errorf("GetPage after growing failed: %v", err)
+	}
+}
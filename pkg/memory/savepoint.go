@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+)
+
+// Statement savepoints give each statement of a transaction all-or-nothing
+// semantics without aborting the transaction when it fails. BeginStatement
+// sets the savepoint; from then on GetPage keeps a copy of every page the
+// statement requests for writing, as it was before the statement's first
+// request. RollbackStatement puts those copies back in the buffer pool and
+// logs a CLR for each page it restores, whose UndoNextLSN skips the
+// statement's records, so that recovery never undoes them a second time.
+//
+// Pages stay locked by the transaction until it ends, so no other
+// transaction can have seen or changed what the statement wrote.
+
+// BeginStatement sets a savepoint for the statement about to run in ctx.
+func (p *PageStore) BeginStatement(ctx TxContext) {
+	// A transaction that has logged nothing yet has no record to go back to
+	lsn, err := p.wal.GetLastLSN(ctx.ID)
+	if err != nil {
+		lsn = 0
+	}
+	ctx.BeginStatement(lsn)
+}
+
+// EndStatement releases the savepoint of the statement running in ctx,
+// keeping its changes.
+func (p *PageStore) EndStatement(ctx TxContext) {
+	ctx.EndStatement()
+}
+
+// RollbackStatement undoes every change the statement running in ctx made
+// since BeginStatement and releases its savepoint. The transaction stays
+// active with the changes of its earlier statements. Locks the statement
+// acquired are kept until the transaction ends, as two-phase locking
+// requires.
+//
+// File operations the statement logged are dropped, and the pages of its
+// bulk loads are zeroed. Changes outside the buffer pool, such as files
+// created by DDL or in-memory catalog entries, are not undone.
+//
+// Returns an error if the statement cannot be rolled back on its own, in
+// which case the caller must abort the transaction.
+func (p *PageStore) RollbackStatement(ctx TxContext) error {
+	sp := ctx.Statement()
+	if sp == nil {
+		return fmt.Errorf("transaction %d has no statement savepoint", ctx.ID.ID())
+	}
+	defer ctx.EndStatement()
+
+	saved, complete := sp.Pages()
+	if !complete {
+		return fmt.Errorf("statement savepoint of transaction %d is missing pages", ctx.ID.ID())
+	}
+
+	undoNextLSN := sp.LSN
+	if undoNextLSN == 0 {
+		undoNextLSN = ctx.GetFirstLSN()
+	}
+	if err := p.restorePages(ctx, saved, undoNextLSN); err != nil {
+		return err
+	}
+
+	if err := p.undoBulkLoads(ctx.BulkLoadsSince(sp)); err != nil {
+		return err
+	}
+
+	for _, f := range ctx.DiscardFileOpsSince(sp) {
+		if _, err := p.wal.LogFileOpDone(ctx.ID, f.LSN); err != nil {
+			return fmt.Errorf("failed to drop %s: %v", f.Op, err)
+		}
+	}
+	return nil
+}
+
+// restorePages puts the saved images of the pages a statement changed back
+// in the cache, logging a CLR for each.
+func (p *PageStore) restorePages(ctx TxContext, saved []page.Page, undoNextLSN primitives.LSN) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, image := range saved {
+		pid := image.GetID()
+		current, exists := p.cache.Get(pid)
+		if !exists {
+			// Pages the transaction changed are never evicted before it ends
+			continue
+		}
+
+		data := image.GetPageData()
+		if bytes.Equal(current.GetPageData(), data) {
+			continue
+		}
+
+		lsn, err := p.wal.LogCLR(ctx.ID, pid, data, undoNextLSN)
+		if err != nil {
+			return fmt.Errorf("failed to log CLR for page %v: %v", pid, err)
+		}
+		p.cache.Put(pid, image)
+		p.setPageLSN(pid, lsn)
+	}
+	return nil
+}
+
+// saveForStatement saves pg to the savepoint of the statement running in ctx
+// if the statement requested it for writing.
+func saveForStatement(ctx TxContext, pg page.Page, perm transaction.Permissions) {
+	if perm != transaction.ReadWrite {
+		return
+	}
+	if sp := ctx.Statement(); sp != nil {
+		sp.SavePage(pg)
+	}
+}
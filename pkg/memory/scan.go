@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tracing"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultScanRingSize is the number of pages a large scan keeps in its
+	// ring: enough for the page it reads and the one it prefetches, with
+	// room to spare.
+	DefaultScanRingSize = 8
+
+	// largeScanDivisor makes a scan large once the table has more pages
+	// than this fraction of the buffer pool capacity.
+	largeScanDivisor = 4
+)
+
+// ScanRing is the private buffer of a large sequential scan. Pages the scan
+// reads from disk go to the ring rather than the shared buffer pool, each
+// replacing the oldest, so that scanning a big table once does not evict
+// the pages other statements keep coming back to.
+//
+// A ScanRing is safe for concurrent use by a scan and its prefetcher.
+type ScanRing struct {
+	mutex sync.Mutex
+	pages []page.Page
+	next  int // Slot the next page read goes to
+}
+
+// NewScanRing creates a ring of size pages.
+func NewScanRing(size int) *ScanRing {
+	return &ScanRing{pages: make([]page.Page, max(size, 1))}
+}
+
+// get returns the page of the ring with ID pid, if any.
+func (r *ScanRing) get(pid *page.PageDescriptor) (page.Page, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, pg := range r.pages {
+		if pg != nil && pg.GetID().Equals(pid) {
+			return pg, true
+		}
+	}
+	return nil, false
+}
+
+// put adds a page to the ring in place of the oldest.
+func (r *ScanRing) put(pg page.Page) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.pages[r.next] = pg
+	r.next = (r.next + 1) % len(r.pages)
+}
+
+// IsLargeScan reports whether a sequential scan of a table of pages pages
+// should read through a ScanRing: the table is over a quarter of the buffer
+// pool, so caching all of it would evict much of the working set.
+func (p *PageStore) IsLargeScan(pages int) bool {
+	return pages > p.Capacity()/largeScanDivisor
+}
+
+// GetScanPage retrieves a page for a large sequential scan, locking it for
+// reading like GetPage. A page already in the buffer pool is served from
+// there, without moving it in the LRU order; any other page is read into
+// ring and never enters the pool.
+//
+// The page on disk is the latest committed version: the shared lock keeps
+// writers out, and the pages of committed transactions are flushed at commit
+// while the pages ctx itself changed are dirty and so still cached.
+func (p *PageStore) GetScanPage(ctx TxContext, pageIO page.PageIO, pid *page.PageDescriptor, ring *ScanRing) (page.Page, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("transaction context cannot be nil")
+	}
+
+	waited, err := p.lockManager.LockPageWait(ctx.ID, pid, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if waited > 0 {
+		now := time.Now()
+		ctx.Trace().Record("lock.wait", now.Add(-waited), now,
+			tracing.Attr("table_id", pid.FileID()),
+			tracing.Attr("page_no", pid.PageNo()),
+			tracing.Attr("exclusive", false))
+	}
+	ctx.RecordPageAccess(pid, transaction.ReadOnly)
+
+	unlatch := p.latches.lock(pid, false)
+	pg, cached := p.cache.Peek(pid)
+	unlatch()
+	if cached {
+		bufferPoolHits.Inc()
+		return pg, nil
+	}
+	if pg, ok := ring.get(pid); ok {
+		return pg, nil
+	}
+	bufferPoolMisses.Inc()
+
+	pg, err = pageIO.ReadPage(pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page from disk: %v", err)
+	}
+	ring.put(pg)
+	bufferPoolScanReads.Inc()
+	return pg, nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"path/filepath"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/types"
+	"testing"
+)
+
+// TestGetScanPage_ReadsPastThePool tests that a scan page missing from the
+// pool is kept in the scan ring only, and that a cached one keeps its place
+func TestGetScanPage_ReadsPastThePool(t *testing.T) {
+	wal, err := wal.NewWAL(filepath.Join(t.TempDir(), "test.wal"), 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	dbFile := newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"})
+	pid := func(n int) *page.PageDescriptor { return page.NewPageDescriptor(1, primitives.PageNumber(n)) }
+
+	reader := createTransactionContext(t, wal)
+	for i := range 2 {
+		if _, err := ps.GetPage(reader, dbFile, pid(i), transaction.ReadOnly); err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+	}
+
+	ring := NewScanRing(2)
+	scanner := createTransactionContext(t, wal)
+	before := ps.Stats()
+	page3 := pid(3)
+	for _, p := range []*page.PageDescriptor{pid(0), pid(2), page3, pid(2)} {
+		if _, err := ps.GetScanPage(scanner, dbFile, p, ring); err != nil {
+			t.Fatalf("GetScanPage failed: %v", err)
+		}
+	}
+
+	stats := ps.Stats()
+	if stats.CachedPages != 2 || stats.ScanReads-before.ScanReads != 2 || stats.Hits-before.Hits != 1 {
+		t.Errorf("Stats = %+v, want 2 cached pages, 2 scan reads and 1 hit", stats)
+	}
+	if lru := ps.cache.GetAll(); !lru[0].Equals(pid(0)) {
+		t.Errorf("Page 0 moved in the LRU order by a scan, order %v", lru)
+	}
+	if !ps.lockManager.IsPageLocked(page3) {
+		t.Error("Expected the scan to lock the pages it reads")
+	}
+}
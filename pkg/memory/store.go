@@ -2,17 +2,26 @@ package memory
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"storemy/pkg/concurrency/lock"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/invariant"
 	"storemy/pkg/log/wal"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
+	"storemy/pkg/tracing"
+	"storemy/pkg/types"
+	"storemy/pkg/vfs"
 	"sync"
+	"time"
 )
 
 type TxContext = *transaction.TransactionContext
 
 const (
+	// MaxPageCount is the capacity, in pages, of a new PageStore's buffer
+	// pool; see SetCapacity.
 	MaxPageCount = 1000
 )
 
@@ -48,6 +57,7 @@ func (o OperationType) String() string {
 // providing ACID compliance through:
 //   - Page-level locking via LockManager (2PL protocol)
 //   - Write-Ahead Logging (WAL) for durability and recovery
+//   - The WAL rule: a page is written only once the log covers its pageLSN
 //   - NO-STEAL buffer policy (dirty pages never evicted before commit)
 //   - FORCE policy at commit (all dirty pages flushed to disk)
 //   - MVCC support through before-images for transaction rollback
@@ -62,20 +72,41 @@ func (o OperationType) String() string {
 // performs I/O operations and cannot manage file lifecycle (open/close).
 // File ownership belongs to CatalogManager and IndexManager.
 type PageStore struct {
-	mutex       sync.RWMutex
+	mutex       sync.RWMutex // Guards the pool as a whole: capacity, eviction, pageLSNs and dbFiles
+	latches     latchTable   // Guard the cache entries of individual pages
 	lockManager *lock.LockManager
 	cache       PageCache
+	capacity    int                                 // Pages the buffer pool holds at most, see SetCapacity
+	priorities  map[primitives.FileID]CachePriority // Files evicted other than in LRU order, see SetCachePriority
 	wal         *wal.WAL
 	dbFiles     map[primitives.FileID]page.PageIO // tableID -> PageIO mapping for I/O operations
+
+	pageLSNs  map[primitives.PageKey]primitives.LSN // LSN of the last log record describing each page
+	assertWAL bool                                  // Panic when a page is written ahead of its log records
+
+	syncPolicy vfs.SyncPolicy // How the registered page files make writes durable
+	directIO   bool           // Whether the registered page files bypass the OS page cache
+	fs         vfs.FS         // File system the page files are stored on, nil for the WAL's
+
+	doubleWrite *doubleWriteBuffer // Protects page writes from tearing, nil if off, see EnableDoubleWrite
+
+	bulkMutex sync.RWMutex                                    // Held shared while allocating heap pages, exclusively while reserving a table
+	bulkLoads map[primitives.FileID]*primitives.TransactionID // Tables reserved by a bulk load, and the loading transaction
 }
 
 // NewPageStore creates and initializes a new PageStore instance
 func NewPageStore(wal *wal.WAL) *PageStore {
+	bufferPoolCapacity.Set(MaxPageCount)
 	return &PageStore{
 		cache:       NewLRUPageCache(MaxPageCount),
+		capacity:    MaxPageCount,
 		lockManager: lock.NewLockManager(),
 		wal:         wal,
 		dbFiles:     make(map[primitives.FileID]page.PageIO),
+		pageLSNs:    make(map[primitives.PageKey]primitives.LSN),
+		priorities:  make(map[primitives.FileID]CachePriority),
+		bulkLoads:   make(map[primitives.FileID]*primitives.TransactionID),
+		assertWAL:   invariant.Enabled(),
 	}
 }
 
@@ -86,6 +117,36 @@ func (p *PageStore) RegisterDbFile(tableID primitives.FileID, pageIO page.PageIO
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	p.dbFiles[tableID] = pageIO
+	if p.syncPolicy != vfs.SyncOSync {
+		applySyncPolicy(pageIO, p.syncPolicy)
+	}
+	if p.directIO {
+		applyDirectIO(pageIO, true)
+	}
+}
+
+// SetFS sets the file system page files are opened on, such as an
+// encryption.FS that encrypts them. It must be set before any page file is
+// opened.
+func (p *PageStore) SetFS(fsys vfs.FS) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.fs = fsys
+}
+
+// FS returns the file system page files are opened on: the one given to
+// SetFS, or else the WAL's file system, or the real disk without a WAL.
+func (p *PageStore) FS() vfs.FS {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	switch {
+	case p.fs != nil:
+		return p.fs
+	case p.wal != nil:
+		return p.wal.FS()
+	default:
+		return vfs.OS
+	}
 }
 
 // GetDbFile retrieves the PageIO for a specific table ID.
@@ -105,12 +166,48 @@ func (p *PageStore) UnregisterDbFile(tableID primitives.FileID) {
 	delete(p.dbFiles, tableID)
 }
 
+// sizedFile is a page file whose size can be read from its file handle, such
+// as any file built on page.BaseFile.
+type sizedFile interface {
+	GetFile() vfs.File
+}
+
+// FileBytes returns the total size of the registered page files: the heap
+// files of the tables, the system catalog and the index files. Files that
+// are closed, or that do not expose their file handle, are not counted.
+func (p *PageStore) FileBytes() (int64, error) {
+	p.mutex.RLock()
+	files := slices.Collect(maps.Values(p.dbFiles))
+	p.mutex.RUnlock()
+
+	var total int64
+	for _, pageIO := range files {
+		f, ok := pageIO.(sizedFile)
+		if !ok {
+			continue
+		}
+		file := f.GetFile()
+		if file == nil {
+			continue
+		}
+		info, err := file.Stat()
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat page file: %v", err)
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
 // GetPage retrieves a page with specified permissions for a transaction.
 // This is the main entry point for all page access in the database, enforcing:
 //   - Lock acquisition through LockManager (shared for READ, exclusive for READ_WRITE)
-//   - Page loading from disk if not in cache
+//   - Refusing READ_WRITE access to read-only transactions
+//   - Page loading from disk if not in cache, under the page's latch rather
+//     than a pool-wide mutex, so that unrelated pages are read concurrently
 //   - LRU eviction when cache is full (respecting NO-STEAL policy)
 //   - Transaction tracking of accessed pages for commit/abort
+//   - Saving pages requested for writing to the running statement's savepoint
 //
 // Parameters:
 //   - ctx: Transaction context (must not be nil)
@@ -123,40 +220,75 @@ func (p *PageStore) GetPage(ctx TxContext, pageIO page.PageIO, pid *page.PageDes
 	}
 
 	tid := ctx.ID
-	if err := p.lockManager.LockPage(tid, pid, perm == transaction.ReadWrite); err != nil {
-		return nil, fmt.Errorf("failed to acquire lock: %v", err)
+	exclusive := perm == transaction.ReadWrite
+	if exclusive && ctx.IsReadOnly() {
+		// Refused before locking, so a reader never upgrades its shared lock
+		return nil, transaction.ErrReadOnlyTransaction
+	}
+	waited, err := p.lockManager.LockPageWait(tid, pid, exclusive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if waited > 0 {
+		now := time.Now()
+		ctx.Trace().Record("lock.wait", now.Add(-waited), now,
+			tracing.Attr("table_id", pid.FileID()),
+			tracing.Attr("page_no", pid.PageNo()),
+			tracing.Attr("exclusive", exclusive))
 	}
 
 	ctx.RecordPageAccess(pid, perm)
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
 
-	if page, exists := p.cache.Get(pid); exists {
+	unlatch := p.latches.lock(pid, false)
+	page, exists := p.cache.Get(pid)
+	unlatch()
+	if exists {
+		bufferPoolHits.Inc()
+		saveForStatement(ctx, page, perm)
 		return page, nil
 	}
 
-	if p.cache.Size() >= MaxPageCount {
-		if err := p.evictPage(); err != nil {
-			return nil, fmt.Errorf("buffer pool full, cannot evict: %v", err)
-		}
+	// Upgrade to the exclusive latch to read the page in, so that concurrent
+	// misses on it read it once; one may have done so while unlatched
+	unlatch = p.latches.lock(pid, true)
+	defer unlatch()
+	if page, exists := p.cache.Get(pid); exists {
+		bufferPoolHits.Inc()
+		saveForStatement(ctx, page, perm)
+		return page, nil
 	}
+	bufferPoolMisses.Inc()
 
-	page, err := pageIO.ReadPage(pid)
+	page, err = pageIO.ReadPage(pid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read page from disk: %v", err)
 	}
 
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// After the pool shrinks it may hold more pages than its capacity until
+	// they can be evicted
+	for p.cache.Size() >= p.capacity {
+		if err := p.evictPage(); err != nil {
+			return nil, fmt.Errorf("buffer pool full, cannot evict: %v", err)
+		}
+	}
+
 	if err := p.cache.Put(pid, page); err != nil {
 		return nil, fmt.Errorf("failed to add page to cache: %v", err)
 	}
+	bufferPoolPages.Set(int64(p.cache.Size()))
 
+	saveForStatement(ctx, page, perm)
 	return page, nil
 }
 
+// HandlePageChange logs the changes ctx made to the pages getDirtyPages
+// returns, marks the pages dirty and caches them. Each page is logged and
+// cached under its exclusive latch, so changes to different pages are
+// logged concurrently.
 func (p *PageStore) HandlePageChange(ctx TxContext, op OperationType, getDirtyPages func() ([]page.Page, error)) error {
-
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
 	if ctx == nil || ctx.ID == nil {
 		return fmt.Errorf("transaction context cannot be nil")
 	}
@@ -166,30 +298,51 @@ func (p *PageStore) HandlePageChange(ctx TxContext, op OperationType, getDirtyPa
 		return fmt.Errorf("failed to get dirty pages: %v", err)
 	}
 
+	span := ctx.Trace().StartSpan("wal.append", tracing.Attr("operation", op.String()), tracing.Attr("records", len(dirtyPages)))
+	defer span.End()
+
 	for _, pg := range dirtyPages {
-		pid := pg.GetID()
-
-		// Handle UpdateOperation specially - it needs both before and after images
-		if op == UpdateOperation {
-			var beforeImage []byte
-			if beforePage := pg.GetBeforeImage(); beforePage != nil {
-				beforeImage = beforePage.GetPageData()
-			}
-			afterImage := pg.GetPageData()
-
-			if _, err := p.wal.LogUpdate(ctx.ID, pid, beforeImage, afterImage); err != nil {
-				return fmt.Errorf("failed to log update operation: %v", err)
-			}
-		} else {
-			if err := p.logOperation(op, ctx.ID, pid, pg.GetPageData()); err != nil {
-				return fmt.Errorf("failed to log %s operation: %v", op, err)
-			}
+		if err := p.logPageChange(ctx, op, pg); err != nil {
+			return err
 		}
+	}
+	bufferPoolPages.Set(int64(p.cache.Size()))
+	return nil
+}
+
+// logPageChange logs the change of a single page for HandlePageChange.
+func (p *PageStore) logPageChange(ctx TxContext, op OperationType, pg page.Page) error {
+	pid := pg.GetID()
+	unlatch := p.latches.lock(pid, true)
+	defer unlatch()
 
-		pg.MarkDirty(true, ctx.ID)
-		p.cache.Put(pid, pg)
-		ctx.MarkPageDirty(pid)
+	var lsn primitives.LSN
+	var err error
+
+	// Handle UpdateOperation specially - it needs both before and after images
+	if op == UpdateOperation {
+		var beforeImage []byte
+		if beforePage := pg.GetBeforeImage(); beforePage != nil {
+			beforeImage = beforePage.GetPageData()
+		}
+		lsn, err = p.wal.LogUpdate(ctx.ID, pid, beforeImage, pg.GetPageData())
+		if err != nil {
+			return fmt.Errorf("failed to log update operation: %v", err)
+		}
+	} else {
+		lsn, err = p.logOperation(op, ctx.ID, pid, pg.GetPageData())
+		if err != nil {
+			return fmt.Errorf("failed to log %s operation: %v", op, err)
+		}
 	}
+
+	p.mutex.Lock()
+	p.setPageLSN(pid, lsn)
+	pg.MarkDirty(true, ctx.ID)
+	p.cache.Put(pid, pg)
+	p.mutex.Unlock()
+
+	ctx.MarkPageDirty(pid)
 	return nil
 }
 
@@ -199,39 +352,48 @@ func (p *PageStore) HandlePageChange(ctx TxContext, op OperationType, getDirtyPa
 // blocking transactions when the buffer pool is full of dirty pages.
 //
 // Eviction algorithm:
-//  1. Scan all cached pages
+//  1. Scan all cached pages, lowest cache priority first (see CachePriority)
 //  2. Skip dirty pages (modified but not committed)
 //  3. Skip locked pages (currently in use by transactions)
-//  4. Evict first clean, unlocked page found
+//  4. Skip pages whose latch is taken (an operation on them is under way)
+//  5. Evict first clean, unlocked page found
 //
 // Returns an error if no pages can be evicted (all are dirty or locked).
 // This forces transactions to commit or abort to free up buffer space.
 //
-// Called by GetPage when cache is at MaxPageCount capacity.
+// Called by GetPage when the cache is at capacity.
 //
 // Note: Caller must hold p.mutex lock.
 func (p *PageStore) evictPage() error {
-	allPages := p.cache.GetAll()
-
-	for _, pid := range allPages {
-		page, exists := p.cache.Get(pid)
-		if !exists {
-			continue
+	for _, pid := range p.evictionOrder() {
+		if p.tryEvict(pid) {
+			return nil
 		}
+	}
 
-		if page.IsDirty() != nil {
-			continue
-		}
+	return fmt.Errorf("all pages are dirty or locked, cannot evict (NO-STEAL policy)")
+}
 
-		if p.lockManager.IsPageLocked(pid) {
-			continue
-		}
+// tryEvict removes pid from the cache if it is clean, unlocked and not
+// latched, and reports whether it did. The latch is only tried: the caller
+// holds p.mutex, and may hold the latch of a page sharing the stripe.
+//
+// Note: Caller must hold p.mutex lock.
+func (p *PageStore) tryEvict(pid primitives.PageID) bool {
+	unlatch, ok := p.latches.tryLock(pid)
+	if !ok {
+		return false
+	}
+	defer unlatch()
 
-		p.cache.Remove(pid)
-		return nil
+	page, exists := p.cache.Peek(pid)
+	if !exists || page.IsDirty() != nil || p.lockManager.IsPageLocked(pid) {
+		return false
 	}
 
-	return fmt.Errorf("all pages are dirty or locked, cannot evict (NO-STEAL policy)")
+	p.cache.Remove(pid)
+	bufferPoolEvictions.Inc()
+	return true
 }
 
 // FlushAllPages writes all dirty pages to persistent storage.
@@ -258,6 +420,10 @@ func (p *PageStore) FlushAllPages() error {
 	pids = append(pids, p.cache.GetAll()...)
 	p.mutex.RUnlock()
 
+	if dw := p.doubleWriter(); dw != nil {
+		return p.flushPagesDoubleWrite(dw, pids)
+	}
+
 	for _, pid := range pids {
 		dbFile, err := p.getDbFileForPage(pid)
 		if err != nil {
@@ -267,6 +433,9 @@ func (p *PageStore) FlushAllPages() error {
 			return fmt.Errorf("failed to flush page %v: %v", pid, err)
 		}
 	}
+	if err := p.syncFiles(pids); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -278,9 +447,10 @@ func (p *PageStore) FlushAllPages() error {
 // Algorithm:
 //  1. Check if page exists in cache
 //  2. Skip if page is not dirty
-//  3. Write page data to file
-//  4. Unmark dirty flag
-//  5. Update cached copy
+//  3. Force the WAL up to the page's pageLSN (WAL rule)
+//  4. Write page data to file
+//  5. Unmark dirty flag
+//  6. Update cached copy
 //
 // Parameters:
 //   - pageIO: Page I/O interface to write the page to (must match page's table ID)
@@ -288,12 +458,12 @@ func (p *PageStore) FlushAllPages() error {
 //
 // Returns:
 //   - nil if page doesn't exist or isn't dirty (no-op)
-//   - error if disk write fails
+//   - error if forcing the WAL or the disk write fails
 func (p *PageStore) flushPage(pageIO page.PageIO, pid primitives.PageID) error {
-	p.mutex.RLock()
-	page, exists := p.cache.Get(pid)
-	p.mutex.RUnlock()
+	unlatch := p.latches.lock(pid, true)
+	defer unlatch()
 
+	page, exists := p.cache.Get(pid)
 	if !exists {
 		return nil
 	}
@@ -302,13 +472,22 @@ func (p *PageStore) flushPage(pageIO page.PageIO, pid primitives.PageID) error {
 		return nil
 	}
 
+	lsn, logged, err := p.forceLog(pid)
+	if err != nil {
+		return err
+	}
+
 	if err := pageIO.WritePage(page); err != nil {
 		return fmt.Errorf("failed to write page to disk: %v", err)
 	}
+	bufferPoolPagesWritten.Inc()
 	page.MarkDirty(false, nil)
 
 	p.mutex.Lock()
 	p.cache.Put(pid, page)
+	if logged {
+		p.clearPageLSN(pid, lsn)
+	}
 	p.mutex.Unlock()
 
 	return nil
@@ -355,27 +534,28 @@ func (p *PageStore) Close() error {
 //   - pageID: Page affected (nil for COMMIT/ABORT)
 //   - data: Page or tuple data (nil for COMMIT/ABORT)
 //
-// Returns an error if WAL write fails. This is a critical error that should
-// cause the operation to be aborted.
-func (p *PageStore) logOperation(operation OperationType, tid *primitives.TransactionID, pageID primitives.PageID, data []byte) error {
+// Returns the LSN of the new record, or an error if WAL write fails. This is
+// a critical error that should cause the operation to be aborted.
+func (p *PageStore) logOperation(operation OperationType, tid *primitives.TransactionID, pageID primitives.PageID, data []byte) (primitives.LSN, error) {
+	var lsn primitives.LSN
 	var err error
 	switch operation {
 	case InsertOperation:
-		_, err = p.wal.LogInsert(tid, pageID, data)
+		lsn, err = p.wal.LogInsert(tid, pageID, data)
 	case DeleteOperation:
-		_, err = p.wal.LogDelete(tid, pageID, data)
+		lsn, err = p.wal.LogDelete(tid, pageID, data)
 	case CommitOperation:
-		_, err = p.wal.LogCommit(tid)
+		lsn, err = p.wal.LogCommit(tid)
 	case AbortOperation:
-		_, err = p.wal.LogAbort(tid)
+		lsn, err = p.wal.LogAbort(tid)
 	default:
-		return fmt.Errorf("unknown operation: %s", operation.String())
+		return 0, fmt.Errorf("unknown operation: %s", operation.String())
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to log %s to WAL: %v", operation, err)
+		return 0, fmt.Errorf("failed to log %s to WAL: %v", operation, err)
 	}
-	return nil
+	return lsn, nil
 }
 
 // getDbFileForPage retrieves the PageIO for a given page ID.
@@ -398,6 +578,178 @@ func (p *PageStore) GetWal() *wal.WAL {
 	return p.wal
 }
 
+// BufferPoolStats is a point-in-time view of the buffer pool.
+// The counters are process-wide and shared by every PageStore.
+type BufferPoolStats struct {
+	CachedPages  int
+	Capacity     int
+	Hits         int64
+	Misses       int64
+	Evictions    int64
+	ScanReads    int64 // Misses of large scans, read past the pool (see GetScanPage)
+	PagesWritten int64
+}
+
+// Stats returns the current buffer pool occupancy and access counters.
+func (p *PageStore) Stats() BufferPoolStats {
+	p.mutex.RLock()
+	cached, capacity := p.cache.Size(), p.capacity
+	p.mutex.RUnlock()
+
+	return BufferPoolStats{
+		CachedPages:  cached,
+		Capacity:     capacity,
+		Hits:         bufferPoolHits.Value(),
+		Misses:       bufferPoolMisses.Value(),
+		Evictions:    bufferPoolEvictions.Value(),
+		ScanReads:    bufferPoolScanReads.Value(),
+		PagesWritten: bufferPoolPagesWritten.Value(),
+	}
+}
+
+// Locks returns every page lock currently held or waited for.
+func (p *PageStore) Locks() []lock.LockInfo {
+	return p.lockManager.Snapshot()
+}
+
+// LockWaitQueues returns the wait queue of every page with lock requests
+// waiting; see lock.LockManager.WaitQueues.
+func (p *PageStore) LockWaitQueues() []lock.WaitQueueInfo {
+	return p.lockManager.WaitQueues()
+}
+
+// SetLockGrantPolicy sets when queued page lock requests are granted; see
+// lock.LockManager.SetGrantPolicy.
+func (p *PageStore) SetLockGrantPolicy(policy lock.GrantPolicy) {
+	p.lockManager.SetGrantPolicy(policy)
+}
+
+// LockGrantPolicy returns when queued page lock requests are granted.
+func (p *PageStore) LockGrantPolicy() lock.GrantPolicy {
+	return p.lockManager.GrantPolicy()
+}
+
+// StartLockTrace starts recording lock events for all transactions; see
+// lock.LockManager.StartTrace.
+func (p *PageStore) StartLockTrace(capacity int) *lock.LockTrace {
+	return p.lockManager.StartTrace(capacity)
+}
+
+// StopLockTrace stops recording lock events and returns the recorded trace,
+// or nil if none was running.
+func (p *PageStore) StopLockTrace() *lock.LockTrace {
+	return p.lockManager.StopTrace()
+}
+
+// LockTrace returns the lock trace being recorded, or nil.
+func (p *PageStore) LockTrace() *lock.LockTrace {
+	return p.lockManager.Trace()
+}
+
+// LockKeyRange locks a key range of an index that ctx has scanned, so no
+// other transaction can insert a phantom into it until ctx commits or
+// aborts. Only serializable transactions take range locks; at weaker levels
+// it does nothing.
+func (p *PageStore) LockKeyRange(ctx TxContext, indexID primitives.FileID, r lock.KeyRange) error {
+	if ctx == nil {
+		return fmt.Errorf("transaction context cannot be nil")
+	}
+	if ctx.IsolationLevel() != transaction.Serializable {
+		return nil
+	}
+	return p.lockManager.LockKeyRange(ctx.ID, indexID, r)
+}
+
+// LockKeyInsert waits until ctx may insert key into an index, that is, until
+// no serializable transaction still holds a range lock covering it. Inserts
+// check at every isolation level, since it is the scanning transaction that
+// asked for the protection.
+func (p *PageStore) LockKeyInsert(ctx TxContext, indexID primitives.FileID, key types.Field) error {
+	if ctx == nil {
+		return fmt.Errorf("transaction context cannot be nil")
+	}
+	if ctx.IsReadOnly() {
+		return transaction.ErrReadOnlyTransaction
+	}
+
+	waited, err := p.lockManager.LockKeyInsert(ctx.ID, indexID, key)
+	if err != nil {
+		return fmt.Errorf("failed to acquire key range lock: %w", err)
+	}
+	if waited > 0 {
+		now := time.Now()
+		ctx.Trace().Record("lock.wait", now.Add(-waited), now,
+			tracing.Attr("index_id", indexID),
+			tracing.Attr("key", key.String()))
+	}
+	return nil
+}
+
+// LockTable takes a table-level lock on tableID for ctx, held until ctx
+// commits or aborts: shared for statements that use the table, exclusive
+// for DDL that changes or removes it. See lock.LockManager.LockTable.
+func (p *PageStore) LockTable(ctx TxContext, tableID primitives.FileID, exclusive bool) error {
+	if ctx == nil {
+		return fmt.Errorf("transaction context cannot be nil")
+	}
+	if exclusive && ctx.IsReadOnly() {
+		return transaction.ErrReadOnlyTransaction
+	}
+
+	waited, err := p.lockManager.LockTable(ctx.ID, tableID, exclusive)
+	if err != nil {
+		return fmt.Errorf("failed to acquire table lock: %w", err)
+	}
+	if waited > 0 {
+		now := time.Now()
+		ctx.Trace().Record("lock.wait", now.Add(-waited), now,
+			tracing.Attr("table_id", tableID),
+			tracing.Attr("exclusive", exclusive))
+	}
+	return nil
+}
+
+// ExportSnapshot shares the state ctx reads with other transactions and
+// returns the token naming it; see lock.LockManager.ExportSnapshot.
+func (p *PageStore) ExportSnapshot(ctx TxContext) (string, error) {
+	if ctx == nil {
+		return "", fmt.Errorf("transaction context cannot be nil")
+	}
+
+	token, waited, err := p.lockManager.ExportSnapshot(ctx.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	if waited > 0 {
+		now := time.Now()
+		ctx.Trace().Record("lock.wait", now.Add(-waited), now, tracing.Attr("snapshot", token))
+	}
+	return token, nil
+}
+
+// ImportSnapshot makes ctx read the snapshot named by token; see
+// lock.LockManager.ImportSnapshot.
+func (p *PageStore) ImportSnapshot(ctx TxContext, token string) error {
+	if ctx == nil {
+		return fmt.Errorf("transaction context cannot be nil")
+	}
+	if err := p.lockManager.ImportSnapshot(ctx.ID, token); err != nil {
+		return fmt.Errorf("failed to import snapshot: %w", err)
+	}
+	return nil
+}
+
+// TableLocks returns every table lock currently held.
+func (p *PageStore) TableLocks() []lock.TableLockInfo {
+	return p.lockManager.TableLocks()
+}
+
+// SetTableLockTimeout sets how long a table lock request waits before it
+// fails; see lock.LockManager.SetTableLockTimeout.
+func (p *PageStore) SetTableLockTimeout(d time.Duration) {
+	p.lockManager.SetTableLockTimeout(d)
+}
+
 // GetPageReadOnly retrieves a page for read-only access
 //
 // Parameters:
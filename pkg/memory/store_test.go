@@ -1,12 +1,16 @@
 package memory
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/log/record"
 	"storemy/pkg/log/wal"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/types"
+	"storemy/pkg/vfs"
 	"sync"
 	"testing"
 )
@@ -495,6 +499,123 @@ func TestCommitTransaction_NoChanges(t *testing.T) {
 	}
 }
 
+// TestCommitTransaction_FileOps tests that a logged file operation happens
+// only once the transaction commits
+func TestCommitTransaction_FileOps(t *testing.T) {
+	dir := t.TempDir()
+	wal, err := wal.NewWAL(filepath.Join(dir, "test.wal"), 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	committed := filepath.Join(dir, "committed.dat")
+	aborted := filepath.Join(dir, "aborted.dat")
+	for _, path := range []string{committed, aborted} {
+		if err := os.WriteFile(path, []byte("heap"), 0o644); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+
+	ctx := createTransactionContext(t, wal)
+	if err := ps.LogFileOp(ctx, record.FileOperation{Kind: record.FileOpDelete, Path: primitives.Filepath(committed)}); err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+	if _, err := os.Stat(committed); err != nil {
+		t.Fatalf("File deleted before commit: %v", err)
+	}
+	if err := ps.CommitTransaction(ctx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	if _, err := os.Stat(committed); !os.IsNotExist(err) {
+		t.Errorf("File not deleted after commit: %v", err)
+	}
+
+	ctx = createTransactionContext(t, wal)
+	if err := ps.LogFileOp(ctx, record.FileOperation{Kind: record.FileOpDelete, Path: primitives.Filepath(aborted)}); err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+	if err := ps.AbortTransaction(ctx); err != nil {
+		t.Fatalf("AbortTransaction failed: %v", err)
+	}
+	if _, err := os.Stat(aborted); err != nil {
+		t.Errorf("File deleted although the transaction aborted: %v", err)
+	}
+}
+
+// TestRollbackStatement tests that rolling back a statement restores only
+// the pages it changed and leaves the transaction running
+func TestRollbackStatement(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "test.wal")
+	wal, err := wal.NewWAL(walPath, 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	dbFile := newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"})
+	ps.RegisterDbFile(1, dbFile)
+	ctx := createTransactionContext(t, wal)
+	if err := ctx.EnsureBegunInWAL(wal); err != nil {
+		t.Fatalf("Failed to begin transaction in WAL: %v", err)
+	}
+
+	write := func(pageNo primitives.PageNumber, value byte) {
+		t.Helper()
+		pg, err := ps.GetPage(ctx, dbFile, page.NewPageDescriptor(1, pageNo), transaction.ReadWrite)
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		pg.(*mockPage).data[0] = value
+		pg.MarkDirty(true, ctx.ID)
+		ctx.MarkPageDirty(pg.GetID())
+	}
+	read := func(pageNo primitives.PageNumber) byte {
+		t.Helper()
+		pg, err := ps.GetPage(ctx, dbFile, page.NewPageDescriptor(1, pageNo), transaction.ReadOnly)
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		return pg.GetPageData()[0]
+	}
+
+	// The first statement succeeds
+	ps.BeginStatement(ctx)
+	write(0, 1)
+	ps.EndStatement(ctx)
+
+	// The second changes both pages, then fails
+	ps.BeginStatement(ctx)
+	write(0, 2)
+	write(1, 2)
+	write(0, 3)
+	beforeRollback := wal.CurrentLSN()
+	if err := ps.RollbackStatement(ctx); err != nil {
+		t.Fatalf("RollbackStatement failed: %v", err)
+	}
+
+	if got := read(0); got != 1 {
+		t.Errorf("Page 0 holds %d, want the first statement's 1", got)
+	}
+	if got := read(1); got != 0 {
+		t.Errorf("Page 1 holds %d, want 0", got)
+	}
+	if wal.CurrentLSN() == beforeRollback {
+		t.Error("Expected CLRs to be logged for the restored pages")
+	}
+	if ctx.Statement() != nil {
+		t.Error("Savepoint not released after rollback")
+	}
+	if err := ps.RollbackStatement(ctx); err == nil {
+		t.Error("Expected rollback without a savepoint to fail")
+	}
+	if !ctx.IsActive() {
+		t.Error("Transaction no longer active after statement rollback")
+	}
+}
+
 // TestAbortTransaction tests transaction rollback
 func TestAbortTransaction(t *testing.T) {
 	walPath := filepath.Join(t.TempDir(), "test.wal")
@@ -590,6 +711,64 @@ func TestAbortTransaction_NoBeforeImage(t *testing.T) {
 	}
 }
 
+// TestAbortTransaction_AbortNotLogged tests an abort whose ABORT record
+// cannot be written: the pages are still restored before the locks go
+func TestAbortTransaction_AbortNotLogged(t *testing.T) {
+	fsys := vfs.NewMemFS()
+	w, err := wal.NewWALWithFS(fsys, "/wal.log", 4096)
+	if err != nil {
+		t.Fatalf("NewWALWithFS failed: %v", err)
+	}
+	defer w.Close()
+
+	ps := NewPageStore(w)
+	dbFile := newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"})
+	ps.RegisterDbFile(1, dbFile)
+	ctx := createTransactionContext(t, w)
+	if err := ctx.EnsureBegunInWAL(w); err != nil {
+		t.Fatalf("Failed to begin transaction in WAL: %v", err)
+	}
+
+	pid := page.NewPageDescriptor(1, 0)
+	pg, err := ps.GetPage(ctx, dbFile, pid, transaction.ReadWrite)
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	pg.SetBeforeImage()
+	pg.(*mockPage).data[0] = 7
+	pg.MarkDirty(true, ctx.ID)
+	ctx.MarkPageDirty(pid)
+
+	// Fill the log buffer while writes to the log fail, so that the ABORT
+	// record finds no room
+	fsys.SetFault(vfs.FailAlways(vfs.OpWrite, "wal.log", errors.New("injected I/O error")))
+	for i := 0; ; i++ {
+		if i == 1000 {
+			t.Fatal("log buffer never filled")
+		}
+		if _, err := w.LogBegin(primitives.NewTransactionIDFromValue(int64(1000 + i))); err != nil {
+			break
+		}
+	}
+
+	if err := ps.AbortTransaction(ctx); err == nil {
+		t.Fatal("Expected AbortTransaction to fail when ABORT cannot be logged")
+	}
+
+	ps.mutex.RLock()
+	restored, exists := ps.cache.Get(pid)
+	ps.mutex.RUnlock()
+	if exists && (restored.GetPageData()[0] != 0 || restored.IsDirty() != nil) {
+		t.Error("Uncommitted change left in the buffer pool after the abort failed")
+	}
+	if ps.lockManager.IsPageLocked(pid) {
+		t.Error("Page still locked after its changes were undone")
+	}
+	if got := ctx.GetStatus(); got != transaction.TxAborted {
+		t.Errorf("Expected the transaction to end aborted, got %s", got)
+	}
+}
+
 // TestFlushAllPages tests flushing all dirty pages to disk
 func TestFlushAllPages(t *testing.T) {
 	walPath := filepath.Join(t.TempDir(), "test.wal")
@@ -662,6 +841,151 @@ func TestFlushAllPages_EmptyCache(t *testing.T) {
 	}
 }
 
+// TestHandlePageChange_SetsPageLSN tests that logged changes set the pageLSN
+// and that commit forces the log past it before flushing
+func TestHandlePageChange_SetsPageLSN(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "test.wal")
+	wal, err := wal.NewWAL(walPath, 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	dbFile := newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"})
+	ps.RegisterDbFile(1, dbFile)
+	ctx := createTransactionContext(t, wal)
+	if err := ctx.EnsureBegunInWAL(wal); err != nil {
+		t.Fatalf("Failed to begin transaction in WAL: %v", err)
+	}
+
+	pid := page.NewPageDescriptor(1, 0)
+	pg, err := ps.GetPage(ctx, dbFile, pid, transaction.ReadWrite)
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+
+	before := wal.CurrentLSN()
+	if err := ps.HandlePageChange(ctx, InsertOperation, func() ([]page.Page, error) {
+		return []page.Page{pg}, nil
+	}); err != nil {
+		t.Fatalf("HandlePageChange failed: %v", err)
+	}
+
+	lsn, ok := ps.PageLSN(pid)
+	if !ok || lsn != before {
+		t.Fatalf("PageLSN = %d, %v; want %d, true", lsn, ok, before)
+	}
+
+	if err := ps.CommitTransaction(ctx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	if wal.FlushedLSN() <= lsn {
+		t.Errorf("page flushed with the WAL only flushed to %d, pageLSN %d", wal.FlushedLSN(), lsn)
+	}
+	if _, ok := ps.PageLSN(pid); ok {
+		t.Error("pageLSN still tracked after the page was flushed")
+	}
+}
+
+// TestFlushPage_ForcesWAL tests that flushing a page forces the log records
+// describing it, even when they are still buffered
+func TestFlushPage_ForcesWAL(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "test.wal")
+	wal, err := wal.NewWAL(walPath, 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	dbFile := newMockDbFileForPageStore(1, []types.Type{types.IntType}, []string{"id"})
+	ps.RegisterDbFile(1, dbFile)
+	ctx := createTransactionContext(t, wal)
+	if err := ctx.EnsureBegunInWAL(wal); err != nil {
+		t.Fatalf("Failed to begin transaction in WAL: %v", err)
+	}
+
+	pid := page.NewPageDescriptor(1, 0)
+	pg, err := ps.GetPage(ctx, dbFile, pid, transaction.ReadWrite)
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+
+	lsn, err := wal.LogInsert(ctx.ID, pid, []byte("tuple"))
+	if err != nil {
+		t.Fatalf("LogInsert failed: %v", err)
+	}
+	if wal.FlushedLSN() > lsn {
+		t.Fatalf("record at %d already flushed; the test needs it buffered", lsn)
+	}
+	ps.SetPageLSN(pid, lsn)
+	pg.MarkDirty(true, ctx.ID)
+
+	if err := ps.FlushAllPages(); err != nil {
+		t.Fatalf("FlushAllPages failed: %v", err)
+	}
+	if wal.FlushedLSN() <= lsn {
+		t.Errorf("FlushedLSN = %d after flushing a page with pageLSN %d", wal.FlushedLSN(), lsn)
+	}
+
+	// A new page written around the cache is forced the same way
+	newPid := page.NewPageDescriptor(1, 1)
+	if lsn, err = wal.LogInsert(ctx.ID, newPid, []byte("tuple")); err != nil {
+		t.Fatalf("LogInsert failed: %v", err)
+	}
+	ps.SetPageLSN(newPid, lsn)
+	if err := ps.WritePage(dbFile, newMockPage(newPid)); err != nil {
+		t.Fatalf("WritePage failed: %v", err)
+	}
+	if wal.FlushedLSN() <= lsn {
+		t.Errorf("FlushedLSN = %d after writing a page with pageLSN %d", wal.FlushedLSN(), lsn)
+	}
+}
+
+// TestWALRule_Assertion tests that writing a page ahead of its log records
+// panics while assertions are on
+func TestWALRule_Assertion(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "test.wal")
+	wal, err := wal.NewWAL(walPath, 4096)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	defer wal.Close()
+
+	ps := NewPageStore(wal)
+	ctx := createTransactionContext(t, wal)
+	if err := ctx.EnsureBegunInWAL(wal); err != nil {
+		t.Fatalf("Failed to begin transaction in WAL: %v", err)
+	}
+
+	pid := page.NewPageDescriptor(1, 0)
+	lsn, err := wal.LogInsert(ctx.ID, pid, []byte("tuple"))
+	if err != nil {
+		t.Fatalf("LogInsert failed: %v", err)
+	}
+	ps.SetPageLSN(pid, lsn)
+
+	// Assertions are on by default in tests
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected a panic for a page whose log record is still buffered")
+			}
+		}()
+		ps.checkWALRule(pid, lsn)
+	}()
+
+	ps.SetWALAssertions(false)
+	ps.checkWALRule(pid, lsn)
+
+	ps.SetWALAssertions(true)
+	if err := wal.Force(lsn); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+	ps.checkWALRule(pid, lsn)
+}
+
 // TestClose tests proper shutdown
 func TestClose(t *testing.T) {
 	walPath := filepath.Join(t.TempDir(), "test.wal")
@@ -83,6 +83,20 @@ func (m *mockPage) SetBeforeImage() {
 	}
 }
 
+func (m *mockPage) Copy() page.Page {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	dataCopy := make([]byte, len(m.data))
+	copy(dataCopy, m.data)
+	return &mockPage{
+		id:        m.id,
+		data:      dataCopy,
+		dirty:     m.dirty,
+		dirtyTid:  m.dirtyTid,
+		beforeImg: m.beforeImg,
+	}
+}
+
 // mockDbFileForPageStore implements page.DbFile for PageStore testing
 type mockDbFileForPageStore struct {
 	id        int
@@ -104,8 +118,8 @@ func newMockDbFileForPageStore(id int, fieldTypes []types.Type, fieldNames []str
 }
 
 func (m *mockDbFileForPageStore) ReadPage(pid *page.PageDescriptor) (page.Page, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
 	if p, exists := m.pages[pid]; exists {
 		return p, nil
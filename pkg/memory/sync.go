@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"fmt"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+)
+
+// syncedFile is a page file whose writes may need an explicit sync to become
+// durable, such as any file built on page.BaseFile.
+type syncedFile interface {
+	Sync() error
+	SetSyncPolicy(policy vfs.SyncPolicy) error
+}
+
+// SetSyncPolicy sets how the page files registered with the store make their
+// writes durable, now and when they are registered later. Under every policy
+// a commit returns only once its pages are durable; the policies other than
+// vfs.SyncOSync write all of a commit's pages first and then sync each file
+// once.
+//
+// A file that cannot be switched keeps its current policy, which is at worst
+// slower.
+func (p *PageStore) SetSyncPolicy(policy vfs.SyncPolicy) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.syncPolicy = policy
+	for _, pageIO := range p.dbFiles {
+		applySyncPolicy(pageIO, policy)
+	}
+}
+
+// SyncPolicy returns the sync policy of the store's page files.
+func (p *PageStore) SyncPolicy() vfs.SyncPolicy {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.syncPolicy
+}
+
+func applySyncPolicy(pageIO page.PageIO, policy vfs.SyncPolicy) {
+	if f, ok := pageIO.(syncedFile); ok {
+		f.SetSyncPolicy(policy)
+	}
+}
+
+// directIOFile is a page file that can bypass the OS page cache, such as any
+// file built on page.BaseFile.
+type directIOFile interface {
+	SetDirectIO(enabled bool) error
+}
+
+// SetDirectIO sets whether the page files registered with the store, now and
+// later, read and write pages with direct I/O, so the OS page cache does not
+// hold a second copy of what the buffer pool caches (see vfs.ODirect).
+//
+// A file that cannot be switched keeps going through the page cache, as do
+// files on a file system without direct I/O.
+func (p *PageStore) SetDirectIO(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.directIO = enabled
+	for _, pageIO := range p.dbFiles {
+		applyDirectIO(pageIO, enabled)
+	}
+}
+
+// DirectIO reports whether the store's page files are set to use direct I/O.
+func (p *PageStore) DirectIO() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.directIO
+}
+
+func applyDirectIO(pageIO page.PageIO, enabled bool) {
+	if f, ok := pageIO.(directIOFile); ok {
+		f.SetDirectIO(enabled)
+	}
+}
+
+// syncFiles makes the writes to the files holding pids durable, syncing each
+// file once.
+func (p *PageStore) syncFiles(pids []primitives.PageID) error {
+	synced := make(map[primitives.FileID]bool)
+	for _, pid := range pids {
+		fileID := pid.FileID()
+		if synced[fileID] {
+			continue
+		}
+		synced[fileID] = true
+
+		pageIO, err := p.getDbFileForPage(pid)
+		if err != nil {
+			return err
+		}
+		if err := p.syncFile(pageIO); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncFile makes the writes to pageIO durable.
+func (p *PageStore) syncFile(pageIO page.PageIO) error {
+	f, ok := pageIO.(syncedFile)
+	if !ok {
+		return nil
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync page file: %v", err)
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package memory
 
 import (
+	"errors"
 	"fmt"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/primitives"
+	"storemy/pkg/tracing"
+	"strings"
 )
 
 // CommitTransaction finalizes all changes made by a transaction and makes them durable.
@@ -14,7 +17,10 @@ import (
 //  4. Release all locks held by transaction
 //
 // After commit completes:
-//   - All changes are durable (survive crash)
+//   - All changes are durable (survive crash), unless the WAL uses
+//     wal.DurabilityAsync or wal.DurabilityInterval; IsCommitDurable tells
+//     the two apart. Under wal.DurabilityNoSync they survive a crash of the
+//     process but not of the machine
 //   - Other transactions can see the changes
 //   - Transaction resources are released
 //
@@ -36,19 +42,50 @@ func (p *PageStore) CommitTransaction(ctx TxContext) error {
 //   - Database state is as if transaction never executed
 //   - Transaction resources are released
 //
+// An abort that fails part way, say because the ABORT record cannot be
+// logged, still restores the before-images of the transaction's pages and
+// undoes its bulk loads, so that its changes are neither read by others nor
+// flushed. Only then are its locks released; if that undo fails too, the
+// locks stay held. Either way the transaction stops counting as active, so
+// shutdown does not wait on it.
+//
 // Parameters:
 //   - ctx: Transaction context containing dirty pages and lock information
 func (p *PageStore) AbortTransaction(ctx TxContext) error {
-	return p.finalizeTransaction(ctx, AbortOperation)
+	err := p.finalizeTransaction(ctx, AbortOperation)
+	if err == nil || ctx == nil || ctx.ID == nil {
+		return err
+	}
+
+	if undoErr := p.undoInMemory(ctx); undoErr != nil {
+		ctx.SetStatus(transaction.TxAborting)
+		return errors.Join(err, undoErr)
+	}
+	p.endBulkLoads(ctx.ID)
+	p.lockManager.UnlockAllPages(ctx.ID)
+	ctx.SetStatus(transaction.TxAborted)
+	return err
+}
+
+// undoInMemory rolls back the bulk loads and buffered pages of ctx without
+// logging anything, for an abort that could not finish.
+func (p *PageStore) undoInMemory(ctx TxContext) error {
+	if err := p.undoBulkLoads(ctx.BulkLoads()); err != nil {
+		return err
+	}
+	return p.handleAbort(ctx.GetDirtyPages())
 }
 
 // finalizeTransaction is the unified handler for COMMIT and ABORT operations.
 // It implements the common transaction finalization protocol:
 //  1. Validate transaction context
 //  2. Check for dirty pages (no-op if read-only transaction)
-//  3. Log operation to WAL
-//  4. Execute operation-specific logic (commit or abort)
-//  5. Release all locks
+//  3. On abort, zero the pages of its bulk loads
+//  4. Log operation to WAL
+//  5. Execute operation-specific logic (commit or abort)
+//  6. On commit, perform the deferred file operations
+//  7. Release all locks and the tables reserved for bulk loads
+//  8. Mark the transaction committed or aborted
 //
 // This centralizes lock management and WAL logging for transaction termination.
 //
@@ -63,19 +100,37 @@ func (p *PageStore) finalizeTransaction(ctx *transaction.TransactionContext, ope
 	}
 
 	dirtyPageIDs := ctx.GetDirtyPages()
-	if len(dirtyPageIDs) == 0 {
+	if len(dirtyPageIDs) == 0 && len(ctx.FileOps()) == 0 && len(ctx.BulkLoads()) == 0 {
+		p.endBulkLoads(ctx.ID)
 		p.lockManager.UnlockAllPages(ctx.ID)
+		ctx.SetStatus(finalStatus(operation))
 		return nil
 	}
 
-	if err := p.logOperation(operation, ctx.ID, nil, nil); err != nil {
+	if operation == AbortOperation {
+		// Undone before the ABORT is logged, which tells recovery it need
+		// not zero the pages again
+		if err := p.undoBulkLoads(ctx.BulkLoads()); err != nil {
+			return err
+		}
+	}
+
+	trace := ctx.Trace()
+	walSpan := trace.StartSpan("wal."+strings.ToLower(operation.String()), tracing.Attr("dirty_pages", len(dirtyPageIDs)))
+	lsn, err := p.logOperation(operation, ctx.ID, nil, nil)
+	walSpan.End()
+	if err != nil {
 		return err
 	}
+	if operation == CommitOperation {
+		ctx.SetCommitLSN(lsn)
+	}
 
-	var err error
 	switch operation {
 	case CommitOperation:
+		flushSpan := trace.StartSpan("buffer.flush", tracing.Attr("pages", len(dirtyPageIDs)))
 		err = p.handleCommit(dirtyPageIDs)
+		flushSpan.End()
 
 	case AbortOperation:
 		err = p.handleAbort(dirtyPageIDs)
@@ -87,14 +142,46 @@ func (p *PageStore) finalizeTransaction(ctx *transaction.TransactionContext, ope
 	if err != nil {
 		return err
 	}
+	if operation == CommitOperation {
+		p.finishFileOps(ctx, lsn)
+	}
 
+	p.endBulkLoads(ctx.ID)
 	p.lockManager.UnlockAllPages(ctx.ID)
+	ctx.SetStatus(finalStatus(operation))
 	return nil
 }
 
+// IsCommitDurable reports whether the commit of ctx has reached the log
+// file. A transaction that changed nothing logged no commit record and is
+// trivially durable.
+func (p *PageStore) IsCommitDurable(ctx TxContext) bool {
+	lsn, ok := ctx.CommitLSN()
+	return !ok || p.wal.IsDurable(lsn)
+}
+
+// WaitForCommit returns once the commit of ctx has reached the log file,
+// forcing the log if the commit was acknowledged while still buffered.
+func (p *PageStore) WaitForCommit(ctx TxContext) error {
+	lsn, ok := ctx.CommitLSN()
+	if !ok {
+		return nil
+	}
+	return p.wal.WaitForDurability(lsn)
+}
+
+// finalStatus returns the status a transaction ends in once operation succeeds.
+func finalStatus(operation OperationType) transaction.TransactionStatus {
+	if operation == CommitOperation {
+		return transaction.TxCommitted
+	}
+	return transaction.TxAborted
+}
+
 // handleCommit executes the commit phase for dirty pages:
 //  1. Update before-images (for next transaction's rollback)
 //  2. Flush all dirty pages to disk (FORCE policy)
+//  3. Sync each file holding them once, unless every write was already durable
 //
 // This ensures durability - after commit returns, changes survive crashes.
 //
@@ -113,6 +200,13 @@ func (p *PageStore) handleCommit(dirtyPageIDs []primitives.PageID) error {
 	}
 	p.mutex.Unlock()
 
+	if dw := p.doubleWriter(); dw != nil {
+		if err := p.flushPagesDoubleWrite(dw, dirtyPageIDs); err != nil {
+			return fmt.Errorf("commit failed: %v", err)
+		}
+		return nil
+	}
+
 	for _, pid := range dirtyPageIDs {
 		dbFile, err := p.getDbFileForPage(pid)
 		if err != nil {
@@ -122,12 +216,16 @@ func (p *PageStore) handleCommit(dirtyPageIDs []primitives.PageID) error {
 			return fmt.Errorf("commit failed: unable to flush page %v: %v", pid, err)
 		}
 	}
+	if err := p.syncFiles(dirtyPageIDs); err != nil {
+		return fmt.Errorf("commit failed: %v", err)
+	}
 	return nil
 }
 
 // handleAbort executes the rollback phase for dirty pages:
 //  1. Restore before-images for all modified pages (UNDO)
 //  2. Remove pages without before-images (newly allocated)
+//  3. Forget their pageLSNs, since the restored pages match what is on disk
 //
 // This ensures atomicity - after abort returns, no trace of the transaction remains.
 //
@@ -152,6 +250,8 @@ func (p *PageStore) handleAbort(dirtyPageIDs []primitives.PageID) error {
 		} else {
 			p.cache.Remove(pid)
 		}
+		delete(p.pageLSNs, primitives.KeyOf(pid))
 	}
+	bufferPoolPages.Set(int64(p.cache.Size()))
 	return nil
 }
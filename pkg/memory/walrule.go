@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"fmt"
+	"storemy/pkg/invariant"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+)
+
+// The WAL rule: a dirty page may reach disk only after every log record
+// describing its changes has. The buffer pool tracks, for each page, the
+// pageLSN - the LSN of the last record logged for it - and forces the WAL up
+// to that LSN before writing the page.
+//
+// Records logged through HandlePageChange set the pageLSN automatically.
+// Callers that write their own log records for a page (the heap layer logs
+// tuple images through the LogManager) report them with SetPageLSN.
+//
+// Index pages are shared between transactions under latches rather than
+// page locks, so a page's pageLSN may move on while it is being written;
+// the write covers the records logged before it started.
+
+// SetPageLSN records that the log record at lsn describes a change to the
+// page pid. The pageLSN only moves forward.
+func (p *PageStore) SetPageLSN(pid primitives.PageID, lsn primitives.LSN) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.setPageLSN(pid, lsn)
+}
+
+// setPageLSN is SetPageLSN for callers holding p.mutex.
+func (p *PageStore) setPageLSN(pid primitives.PageID, lsn primitives.LSN) {
+	key := primitives.KeyOf(pid)
+	if current, ok := p.pageLSNs[key]; !ok || lsn > current {
+		p.pageLSNs[key] = lsn
+	}
+}
+
+// PageLSN returns the pageLSN of pid, and false if no log record describes
+// a change to the page that has not reached disk yet.
+func (p *PageStore) PageLSN(pid primitives.PageID) (primitives.LSN, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	lsn, ok := p.pageLSNs[primitives.KeyOf(pid)]
+	return lsn, ok
+}
+
+// SetWALAssertions turns the WAL rule assertion on or off. While on, writing
+// a page whose log records have not all reached the WAL file panics instead
+// of silently breaking recovery. It starts on when invariant assertions are
+// on, as they are in tests.
+func (p *PageStore) SetWALAssertions(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.assertWAL = enabled
+}
+
+// WritePage writes pg to disk through pageIO without going through the
+// cache, for pages changed outside the transaction's commit such as B+Tree
+// internal pages. Like a flush, it forces the WAL up to the page's pageLSN
+// first.
+func (p *PageStore) WritePage(pageIO page.PageIO, pg page.Page) error {
+	if _, _, err := p.forceLog(pg.GetID()); err != nil {
+		return err
+	}
+	if dw := p.doubleWriter(); dw != nil {
+		return p.writePageDoubleWrite(dw, pageIO, pg)
+	}
+	if err := pageIO.WritePage(pg); err != nil {
+		return fmt.Errorf("failed to write page to disk: %v", err)
+	}
+	return p.syncFile(pageIO)
+}
+
+// forceLog forces the WAL up to the current pageLSN of pid, so the page may
+// be written, and returns that pageLSN; false if the page has none.
+func (p *PageStore) forceLog(pid primitives.PageID) (primitives.LSN, bool, error) {
+	lsn, ok := p.PageLSN(pid)
+	if !ok {
+		return 0, false, nil
+	}
+	if err := p.wal.Force(lsn); err != nil {
+		return 0, false, fmt.Errorf("failed to force WAL to LSN %d before writing page %v: %v", lsn, pid, err)
+	}
+	p.checkWALRule(pid, lsn)
+	return lsn, true, nil
+}
+
+// clearPageLSN forgets the pageLSN of pid once the page is on disk, unless
+// a newer record was logged for the page while it was being written.
+// Must be called with p.mutex and the exclusive latch of pid held; without
+// the latch a record logged for the page during the write could be lost.
+func (p *PageStore) clearPageLSN(pid primitives.PageID, written primitives.LSN) {
+	p.latches.assertHeld(pid)
+	key := primitives.KeyOf(pid)
+	if lsn, ok := p.pageLSNs[key]; ok && lsn <= written {
+		delete(p.pageLSNs, key)
+	}
+}
+
+// checkWALRule panics, when assertions are on, if the record at lsn, the
+// pageLSN of pid, has not reached the WAL file. LSNs are the offsets where
+// records start, so the record is durable only once the flushed LSN is past
+// it.
+func (p *PageStore) checkWALRule(pid primitives.PageID, lsn primitives.LSN) {
+	p.mutex.RLock()
+	enabled := p.assertWAL
+	p.mutex.RUnlock()
+
+	if !enabled {
+		return
+	}
+	if flushed := p.wal.FlushedLSN(); flushed <= lsn {
+		invariant.Failf("WAL rule violated: page %v written with pageLSN %d but the WAL is flushed only to %d", pid, lsn, flushed)
+	}
+}
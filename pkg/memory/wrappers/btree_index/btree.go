@@ -23,21 +23,22 @@ type BTreePage = btree.BTreePage
 // - Internal pages contain only separator keys and child pointers for navigation
 // - Pages split when full, merge when underutilized (maintaining balance)
 // - Root page can be either leaf (small tree) or internal (large tree)
+// - Concurrent operations synchronize with latch crabbing (see treeLatches)
 //
 // Fields:
 //   - indexID: Unique identifier for this index
 //   - keyType: Type of keys stored in this index (IntType, StringType, etc.)
-//   - file: Underlying file managing page allocation and I/O
-//   - rootPageID: Page ID of the current root (nil if tree is empty)
+//   - file: Underlying file managing page allocation, I/O, page latches and the root
 //   - tx: Transaction context for concurrency control and rollback
 //   - store: PageStore for buffer pool management and page locking
+//   - latches: Latches held by the current operation (nil outside an operation)
 type BTree struct {
-	indexID    primitives.FileID
-	keyType    types.Type
-	file       *BTreeFile
-	rootPageID *page.PageDescriptor
-	tx         *transaction.TransactionContext
-	store      *memory.PageStore
+	indexID primitives.FileID
+	keyType types.Type
+	file    *BTreeFile
+	tx      *transaction.TransactionContext
+	store   *memory.PageStore
+	latches *treeLatches
 }
 
 // NewBTree creates a new B+Tree index with the specified configuration.
@@ -50,7 +51,7 @@ type BTree struct {
 // The function:
 // 1. Creates the BTree wrapper with provided parameters
 // 2. Registers the file with PageStore for flush coordination
-// 3. If pages exist, sets root to page 0 (B+Tree convention)
+// 3. If pages exist, the file reports page 0 as the root (B+Tree convention)
 //
 // Parameters:
 //   - indexID: Unique identifier for this index (should match file.GetID())
@@ -76,10 +77,6 @@ func NewBTree(indexID primitives.FileID, keyType types.Type, file *BTreeFile, tx
 	// Register the BTreeFile with the PageStore so it can flush pages properly
 	store.RegisterDbFile(primitives.FileID(indexID), file)
 
-	if file.NumPages() > 0 {
-		bt.rootPageID = page.NewPageDescriptor(bt.indexID, 0)
-	}
-
 	return bt
 }
 
@@ -109,46 +106,70 @@ func (bt *BTree) Close() error {
 	return err
 }
 
-// getRootPage retrieves or creates the root page of the B+Tree.
-// The root is always page 0 in the B+Tree file by convention.
-//
-// Behavior:
-// - If root exists: fetches it with specified permissions
-// - If tree is empty: allocates new root as leaf page (single-page tree)
-// - New root is immediately marked dirty for transaction tracking
-//
-// Parameters:
-//   - perm: Read-only or read-write access permissions
-//
-// Returns:
-//   - *BTreePage: The root page of the tree
-//   - error: Returns error if page fetch/allocation fails
-func (bt *BTree) getRootPage(perm transaction.Permissions) (*BTreePage, error) {
-	if bt.rootPageID != nil {
-		return bt.getPage(bt.rootPageID, perm)
-	}
+// withLatches returns a copy of bt for a single operation, which records the
+// page latches it takes in latches. Operations never share a latch set, so
+// the BTree itself can be used from several goroutines at once.
+func (bt *BTree) withLatches(latches *treeLatches) *BTree {
+	op := *bt
+	op.latches = latches
+	return &op
+}
 
-	root, err := bt.file.AllocatePage(bt.tx.ID, bt.keyType, true, primitives.InvalidPageNumber)
-	if err != nil {
-		return nil, fmt.Errorf("failed to allocate root page: %w", err)
-	}
+// newOperation returns a copy of bt for a single operation, with no latches
+// held yet.
+func (bt *BTree) newOperation() *BTree {
+	return bt.withLatches(newTreeLatches(bt.file.Latches()))
+}
 
-	bt.rootPageID = root.GetID()
-	if err := bt.addDirtyPage(root, memory.InsertOperation); err != nil {
-		return nil, fmt.Errorf("failed to mark root page as dirty: %w", err)
+// ensureRoot gives an empty tree its root, a leaf page.
+// The new root is immediately marked dirty for transaction tracking.
+func (bt *BTree) ensureRoot() error {
+	if _, ok := bt.file.Root(); ok {
+		return nil
 	}
 
-	return root, nil
+	_, err := bt.file.InitRoot(func() (primitives.PageNumber, error) {
+		root, err := bt.file.AllocatePage(bt.tx.ID, bt.keyType, true, primitives.InvalidPageNumber)
+		if err != nil {
+			return 0, fmt.Errorf("failed to allocate root page: %w", err)
+		}
+		if err := bt.addDirtyPage(root, memory.InsertOperation); err != nil {
+			return 0, fmt.Errorf("failed to mark root page as dirty: %w", err)
+		}
+		return root.PageNo(), nil
+	})
+	return err
 }
 
-// findLeafPage navigates from root to the leaf page that should contain the given key.
-// Implements recursive tree traversal following B+Tree invariants.
+// latchRoot fetches and latches the root page: in internalMode if it is an
+// internal page, in leafMode if the tree is a single leaf. If the root moves
+// while waiting for its latch, it starts over with the new root.
 //
-// Traversal algorithm:
-// 1. If current page is leaf: return it (base case)
-// 2. Find appropriate child pointer using key comparisons
-// 3. Recursively descend to child page
-// 4. Repeat until leaf is reached
+// Returns:
+//   - *BTreePage: The latched root, or nil if the tree is empty
+//   - error: Returns error if the page fetch fails
+func (bt *BTree) latchRoot(perm transaction.Permissions, internalMode, leafMode latchMode) (*BTreePage, error) {
+	for {
+		rootNo, ok := bt.file.Root()
+		if !ok {
+			return nil, nil
+		}
+
+		root, err := bt.latchPage(page.NewPageDescriptor(bt.indexID, rootNo), perm, internalMode, leafMode)
+		if err != nil {
+			return nil, err
+		}
+		if current, _ := bt.file.Root(); current == rootNo {
+			return root, nil
+		}
+		bt.latches.release(rootNo)
+	}
+}
+
+// findLeaf navigates from root to the leaf page that should contain the given key.
+// It crabs down the tree: each child is latched before its parent's latch is
+// released, so the operation holds at most two latches at a time and ends
+// holding only the leaf's.
 //
 // B+Tree navigation rules:
 // - In internal nodes: children[i] has keys >= children[i].Key and < children[i+1].Key
@@ -156,28 +177,74 @@ func (bt *BTree) getRootPage(perm transaction.Permissions) (*BTreePage, error) {
 // - All actual data (RIDs) resides only in leaf pages
 //
 // Parameters:
-//   - currentPage: Current page in traversal (starts with root)
 //   - key: The key being searched for
+//   - rootPerm: Lock permissions for the root page (children are read-only)
+//   - leafMode: Latch mode for the leaf; internal pages are latched shared
 //
 // Returns:
-//   - *BTreePage: The leaf page where the key belongs (may or may not contain it)
+//   - *BTreePage: The latched leaf where the key belongs, or nil for an empty tree
 //   - error: Returns error if child page read fails or structure is invalid
-func (bt *BTree) findLeafPage(currentPage *BTreePage, key types.Field) (*BTreePage, error) {
-	if currentPage.IsLeafPage() {
-		return currentPage, nil
+func (bt *BTree) findLeaf(key types.Field, rootPerm transaction.Permissions, leafMode latchMode) (*BTreePage, error) {
+	current, err := bt.latchRoot(rootPerm, sharedLatch, leafMode)
+	if err != nil || current == nil {
+		return nil, err
 	}
 
-	childPID := bt.findChildPointer(currentPage, key)
-	if childPID == nil {
-		return nil, fmt.Errorf("failed to find child pointer for key")
+	for current.IsInternalPage() {
+		childPID := bt.findChildPointer(current, key)
+		if childPID == nil {
+			return nil, fmt.Errorf("failed to find child pointer for key")
+		}
+
+		child, err := bt.latchPage(childPID, transaction.ReadOnly, sharedLatch, leafMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read child page: %w", err)
+		}
+
+		bt.latches.release(current.PageNo())
+		current = child
 	}
 
-	childPage, err := bt.getPage(childPID, transaction.ReadOnly)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read child page: %w", err)
+	return current, nil
+}
+
+// findLeafExclusive is findLeaf for changes that may split or merge pages.
+// Every page on the path is latched exclusively, and a page's ancestors stay
+// latched until a page below them is safe: one whose own change cannot
+// propagate upward. From then on only the safe page and its parent are
+// kept, the parent because a new first key in a leaf updates its separator.
+//
+// Parameters:
+//   - key: The key being inserted or deleted
+//   - safe: Reports whether the change stops at the given page
+//
+// Returns:
+//   - *BTreePage: The latched leaf, or nil for an empty tree
+//   - error: Returns error if child page read fails or structure is invalid
+func (bt *BTree) findLeafExclusive(key types.Field, safe func(*BTreePage) bool) (*BTreePage, error) {
+	current, err := bt.latchRoot(transaction.ReadWrite, exclusiveLatch, exclusiveLatch)
+	if err != nil || current == nil {
+		return nil, err
+	}
+
+	for current.IsInternalPage() {
+		childPID := bt.findChildPointer(current, key)
+		if childPID == nil {
+			return nil, fmt.Errorf("failed to find child pointer for key")
+		}
+
+		child, err := bt.latchPage(childPID, transaction.ReadOnly, exclusiveLatch, exclusiveLatch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read child page: %w", err)
+		}
+
+		if safe(child) {
+			bt.latches.releaseAllExcept(current.PageNo(), child.PageNo())
+		}
+		current = child
 	}
 
-	return bt.findLeafPage(childPage, key)
+	return current, nil
 }
 
 // findChildPointer finds the appropriate child pointer for a given key in an internal node.
@@ -271,7 +338,7 @@ func (bt *BTree) updateParentKey(child *BTreePage, newKey types.Field) error {
 	}
 
 	parID := page.NewPageDescriptor(bt.indexID, child.Parent())
-	parent, err := bt.getPage(parID, transaction.ReadWrite)
+	parent, err := bt.fetchForUpdate(parID)
 	if err != nil {
 		return fmt.Errorf("failed to read parent page: %w", err)
 	}
@@ -328,6 +395,68 @@ func (bt *BTree) getPage(pageID *page.PageDescriptor, perm transaction.Permissio
 	return btreePage, nil
 }
 
+// latchPage gets a page like getPage and latches it for the current
+// operation, in internalMode if it is an internal page and in leafMode if it
+// is a leaf, unless the operation already holds its latch. The page lock is
+// taken before the latch, so the operation never waits for a lock while
+// holding the page's latch.
+func (bt *BTree) latchPage(pageID *page.PageDescriptor, perm transaction.Permissions, internalMode, leafMode latchMode) (*BTreePage, error) {
+	p, err := bt.getPage(pageID, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	// A page's type never changes, so it can be read before latching
+	mode := internalMode
+	if p.IsLeafPage() {
+		mode = leafMode
+	}
+	if bt.latches != nil {
+		bt.latches.acquire(p.PageNo(), mode)
+	}
+	return p, nil
+}
+
+// fetchForUpdate gets a page in read-write mode and latches it exclusively
+// until the end of the operation. Pages on the operation's path are already
+// latched; other pages it changes, such as siblings, are latched here.
+func (bt *BTree) fetchForUpdate(pageID *page.PageDescriptor) (*BTreePage, error) {
+	return bt.latchPage(pageID, transaction.ReadWrite, exclusiveLatch, exclusiveLatch)
+}
+
+// updatePage applies update to a page off the operation's path, such as a
+// leaf-chain neighbor or a child whose parent pointer moves, and marks it
+// dirty. Unless the operation already holds its latch, the page is latched
+// only for the update, so the operation never keeps a latch that a reader
+// further down or right in the tree may be waiting behind.
+func (bt *BTree) updatePage(pageID *page.PageDescriptor, update func(*BTreePage)) error {
+	p, err := bt.getPage(pageID, transaction.ReadWrite)
+	if err != nil {
+		return err
+	}
+
+	if bt.latches != nil && !bt.latches.holds(p.PageNo()) {
+		bt.latches.acquire(p.PageNo(), exclusiveLatch)
+		defer bt.latches.release(p.PageNo())
+	}
+
+	update(p)
+	return bt.addDirtyPage(p, memory.UpdateOperation)
+}
+
+// allocatePage allocates a new page and latches it exclusively, so that no
+// other operation reads it before it is fully linked into the tree.
+func (bt *BTree) allocatePage(isLeaf bool, parentPage primitives.PageNumber) (*BTreePage, error) {
+	p, err := bt.file.AllocatePage(bt.tx.ID, bt.keyType, isLeaf, parentPage)
+	if err != nil {
+		return nil, err
+	}
+	if bt.latches != nil {
+		bt.latches.acquire(p.PageNo(), exclusiveLatch)
+	}
+	return p, nil
+}
+
 // addDirtyPage marks a page as modified within the current transaction.
 // Critical for transaction support - enables rollback and proper commit behavior.
 //
@@ -368,10 +497,10 @@ func (bt *BTree) addDirtyPage(p *BTreePage, op memory.OperationType) error {
 //   - direction: -1 for left sibling, +1 for right sibling
 //
 // Returns:
-//   - *BTreePage: The requested sibling page (locked for read-write)
+//   - *BTreePage: The requested sibling page (locked and latched for read-write)
 //   - error: Returns error if sibling doesn't exist or page fetch fails
 func (bt *BTree) getSiblingPage(parent *BTreePage, currIDx, direction int) (*BTreePage, error) {
 	children := parent.Children()
 	sibPageID := children[currIDx+direction].ChildPID
-	return bt.getPage(sibPageID, transaction.ReadWrite)
+	return bt.fetchForUpdate(sibPageID)
 }
@@ -429,6 +429,124 @@ func TestBTree_Stress_MassiveConcurrency(t *testing.T) {
 	}
 }
 
+// Test: Readers traverse while writers split and merge pages. Odd keys are
+// never deleted, so every search and range scan must see all of them, in
+// order and exactly once, while other goroutines insert new keys (splitting
+// leaves and internal pages) and delete the even keys (merging them).
+func TestBTree_Stress_ReadersDuringSplitsAndMerges(t *testing.T) {
+	bt, _, _, _, cleanup := setupTestBTree(t, types.IntType)
+	defer cleanup()
+
+	numInitialEntries := 3000
+	pageID := page.NewPageDescriptor(1, 0)
+	insert := func(k int) error {
+		return bt.Insert(types.NewIntField(int64(k)), tuple.NewTupleRecordID(pageID, primitives.SlotID(k)))
+	}
+
+	for i := 0; i < numInitialEntries; i++ {
+		if err := insert(i); err != nil {
+			t.Fatalf("Failed to insert initial entry %d: %v", i, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	numWriters, insertsPerWriter := 4, 1500
+
+	// Writers: append keys past the initial range, splitting the right edge
+	for w := 0; w < numWriters; w++ {
+		wg.Add(1)
+		go func(writerID int) {
+			defer wg.Done()
+			for i := 0; i < insertsPerWriter; i++ {
+				k := numInitialEntries + i*numWriters + writerID
+				if err := insert(k); err != nil {
+					t.Errorf("Writer %d: failed to insert key %d: %v", writerID, k, err)
+					return
+				}
+			}
+		}(w)
+	}
+
+	// Deleters: remove the even initial keys, underflowing and merging leaves
+	for d := 0; d < 2; d++ {
+		wg.Add(1)
+		go func(deleterID int) {
+			defer wg.Done()
+			for k := deleterID * 2; k < numInitialEntries; k += 4 {
+				rid := tuple.NewTupleRecordID(pageID, primitives.SlotID(k))
+				if err := bt.Delete(types.NewIntField(int64(k)), rid); err != nil {
+					t.Errorf("Deleter %d: failed to delete key %d: %v", deleterID, k, err)
+					return
+				}
+			}
+		}(d)
+	}
+
+	// Readers: odd keys must always be found, and range scans must return
+	// them in order with no entry missing or repeated
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func(readerID int) {
+			defer wg.Done()
+			for i := 0; i < 300; i++ {
+				start := (readerID*397 + i*131) % (numInitialEntries - 200)
+				end := start + 199
+
+				results, err := bt.RangeSearch(types.NewIntField(int64(start)), types.NewIntField(int64(end)))
+				if err != nil {
+					t.Errorf("Reader %d: range [%d, %d] failed: %v", readerID, start, end, err)
+					return
+				}
+
+				var odd []int
+				last := -1
+				for _, rid := range results {
+					k := int(rid.TupleNum)
+					if k <= last {
+						t.Errorf("Reader %d: range [%d, %d] returned key %d after %d", readerID, start, end, k, last)
+						return
+					}
+					last = k
+					if k%2 == 1 {
+						odd = append(odd, k)
+					}
+				}
+				if want := (end+1)/2 - start/2; len(odd) != want {
+					t.Errorf("Reader %d: range [%d, %d] returned %d odd keys, want %d", readerID, start, end, len(odd), want)
+					return
+				}
+
+				k := start | 1
+				found, err := bt.Search(types.NewIntField(int64(k)))
+				if err != nil || len(found) != 1 {
+					t.Errorf("Reader %d: search for key %d returned %d results, err %v", readerID, k, len(found), err)
+					return
+				}
+			}
+		}(r)
+	}
+
+	wg.Wait()
+
+	total := numInitialEntries + numWriters*insertsPerWriter
+	results, err := bt.RangeSearch(types.NewIntField(0), types.NewIntField(int64(total)))
+	if err != nil {
+		t.Fatalf("Final range search failed: %v", err)
+	}
+	if want := numInitialEntries/2 + numWriters*insertsPerWriter; len(results) != want {
+		t.Errorf("Expected %d entries after the stress run, got %d", want, len(results))
+	}
+	for i, rid := range results {
+		k := int(rid.TupleNum)
+		if k < numInitialEntries && k%2 == 0 {
+			t.Fatalf("Deleted key %d still present", k)
+		}
+		if i > 0 && int(results[i-1].TupleNum) >= k {
+			t.Fatalf("Entries out of order at %d: %d then %d", i, results[i-1].TupleNum, k)
+		}
+	}
+}
+
 // Test: Concurrent searches on string keys
 func TestBTree_Concurrent_StringKeys(t *testing.T) {
 	bt, _, _, _, cleanup := setupTestBTree(t, types.StringType)
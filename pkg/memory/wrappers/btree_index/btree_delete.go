@@ -3,7 +3,6 @@ package btreeindex
 import (
 	"fmt"
 	"slices"
-	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/memory"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/index"
@@ -72,17 +71,17 @@ func (bt *BTree) deleteFromLeaf(leaf *BTreePage, ie *index.IndexEntry) error {
 func (bt *BTree) handleUnderflow(underflowPage *BTreePage) error {
 	if underflowPage.IsRoot() && underflowPage.IsInternalPage() && underflowPage.GetNumEntries() == 0 && len(underflowPage.Children()) == 1 {
 		childPID := underflowPage.Children()[0].ChildPID
-		childPage, err := bt.getPage(childPID, transaction.ReadWrite)
+		childPage, err := bt.fetchForUpdate(childPID)
 		if err != nil {
 			return err
 		}
 		childPage.SetParent(primitives.InvalidPageNumber)
-		bt.rootPageID = childPID
+		bt.file.SetRoot(childPID.PageNo())
 		return bt.addDirtyPage(childPage, memory.UpdateOperation)
 	}
 
 	parentPageID := page.NewPageDescriptor(bt.indexID, underflowPage.Parent())
-	parent, err := bt.getPage(parentPageID, transaction.ReadWrite)
+	parent, err := bt.fetchForUpdate(parentPageID)
 	if err != nil {
 		return err
 	}
@@ -261,11 +260,9 @@ func (bt *BTree) mergePages(left, right, parent *BTreePage, childIdxToDelete int
 
 		if right.NextLeaf != primitives.InvalidPageNumber {
 			nextPageID := page.NewPageDescriptor(bt.indexID, right.NextLeaf)
-			nextPage, err := bt.getPage(nextPageID, transaction.ReadWrite)
-			if err == nil {
+			bt.updatePage(nextPageID, func(nextPage *BTreePage) {
 				nextPage.PrevLeaf = left.PageNo()
-				bt.addDirtyPage(nextPage, memory.UpdateOperation)
-			}
+			})
 
 		}
 	} else {
@@ -282,11 +279,9 @@ func (bt *BTree) mergePages(left, right, parent *BTreePage, childIdxToDelete int
 
 		// Update all children from right page to point to left page as their parent
 		for _, child := range right.Children() {
-			childPage, err := bt.getPage(child.ChildPID, transaction.ReadWrite)
-			if err == nil {
+			bt.updatePage(child.ChildPID, func(childPage *BTreePage) {
 				childPage.ParentPage = left.PageNo()
-				bt.addDirtyPage(childPage, memory.UpdateOperation)
-			}
+			})
 		}
 	}
 
@@ -2,7 +2,6 @@ package btreeindex
 
 import (
 	"fmt"
-	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/memory"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/index"
@@ -86,7 +85,7 @@ func (bt *BTree) insertAndSplitLeaf(leafPage *BTreePage, key types.Field, rid *t
 	allEntries := mergeEntryIntoSorted(leafPage.Entries, index.NewIndexEntry(key, rid))
 
 	midPoint := len(allEntries) / 2
-	leftEntries := allEntries[:midPoint]
+	leftEntries := allEntries[:midPoint:midPoint] // Capped so later inserts cannot overwrite rightEntries
 	rightEntries := allEntries[midPoint:]
 
 	rightPage, err := bt.createRightLeafSibling(leafPage, rightEntries)
@@ -121,7 +120,7 @@ func (bt *BTree) insertAndSplitLeaf(leafPage *BTreePage, key types.Field, rid *t
 //   - *BTreePage: The newly created right sibling page
 //   - error: Returns error if allocation fails or pointer updates fail
 func (bt *BTree) createRightLeafSibling(left *BTreePage, entries []*index.IndexEntry) (*BTreePage, error) {
-	right, err := bt.file.AllocatePage(bt.tx.ID, bt.keyType, true, left.ParentPage)
+	right, err := bt.allocatePage(true, left.ParentPage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate new leaf page: %w", err)
 	}
@@ -151,13 +150,9 @@ func (bt *BTree) createRightLeafSibling(left *BTreePage, entries []*index.IndexE
 //   - error: Returns error if page fetch fails or dirty marking fails
 func (bt *BTree) updateLeafPrevPointer(pageNo, newPrev primitives.PageNumber) error {
 	pageID := page.NewPageDescriptor(bt.indexID, pageNo)
-	leafPage, err := bt.getPage(pageID, transaction.ReadWrite)
-	if err != nil {
-		return err
-	}
-
-	leafPage.PrevLeaf = newPrev
-	return bt.addDirtyPage(leafPage, memory.UpdateOperation)
+	return bt.updatePage(pageID, func(leafPage *BTreePage) {
+		leafPage.PrevLeaf = newPrev
+	})
 }
 
 // mergeEntryIntoSorted inserts a new entry into a sorted slice while maintaining sort order.
@@ -215,7 +210,7 @@ func (bt *BTree) insertIntoParent(separatorKey types.Field, left, right *BTreePa
 	}
 
 	parentPageID := page.NewPageDescriptor(bt.indexID, left.ParentPage)
-	parentPage, err := bt.getPage(parentPageID, transaction.ReadWrite)
+	parentPage, err := bt.fetchForUpdate(parentPageID)
 	if err != nil {
 		return fmt.Errorf("failed to read parent page: %w", err)
 	}
@@ -268,13 +263,11 @@ func (bt *BTree) insertIntoInternal(internalPage *BTreePage, key types.Field, ch
 	bt.addDirtyPage(internalPage, memory.InsertOperation)
 
 	// Update child's parent pointer
-	childPage, err := bt.getPage(childPID, transaction.ReadWrite)
-	if err == nil {
+	bt.updatePage(childPID, func(childPage *BTreePage) {
 		childPage.ParentPage = internalPage.PageNo()
-		bt.addDirtyPage(childPage, memory.UpdateOperation)
-	}
+	})
 
-	return bt.file.WritePage(internalPage)
+	return bt.store.WritePage(bt.file, internalPage)
 }
 
 // insertAndSplitInternal handles insertion into a full internal page by splitting it.
@@ -307,7 +300,7 @@ func (bt *BTree) insertAndSplitInternal(internalPage *BTreePage, key types.Field
 	internalPage.InternalPages = left
 	bt.addDirtyPage(internalPage, memory.UpdateOperation)
 
-	rightPage, err := bt.file.AllocatePage(bt.tx.ID, bt.keyType, false, internalPage.ParentPage)
+	rightPage, err := bt.allocatePage(false, internalPage.ParentPage)
 	if err != nil {
 		return fmt.Errorf("failed to allocate new internal page: %w", err)
 	}
@@ -344,7 +337,8 @@ func (bt *BTree) updateChildrenParentPointers(children []*btree.BTreeChildPtr, p
 }
 
 // updateChildParentPointer updates a single child page's parent pointer.
-// Acquires the child page in read-write mode and marks it dirty.
+// Acquires the child page in read-write mode, latching it just for the
+// update unless it is on the operation's path, and marks it dirty.
 //
 // Parameters:
 //   - childPID: The page ID of the child to update
@@ -353,13 +347,9 @@ func (bt *BTree) updateChildrenParentPointers(children []*btree.BTreeChildPtr, p
 // Returns:
 //   - error: Returns error if page fetch fails or dirty marking fails
 func (bt *BTree) updateChildParentPointer(childPID *page.PageDescriptor, parentPageNo primitives.PageNumber) error {
-	childPage, err := bt.getPage(childPID, transaction.ReadWrite)
-	if err != nil {
-		return err
-	}
-
-	childPage.ParentPage = parentPageNo
-	return bt.addDirtyPage(childPage, memory.UpdateOperation)
+	return bt.updatePage(childPID, func(childPage *BTreePage) {
+		childPage.ParentPage = parentPageNo
+	})
 }
 
 // mergeChildPtrIntoSorted inserts a new child pointer into a sorted slice of child pointers.
@@ -414,7 +404,7 @@ func mergeChildPtrIntoSorted(children []*btree.BTreeChildPtr, key types.Field, c
 func splitInternalChildren(children []*btree.BTreeChildPtr) (left []*btree.BTreeChildPtr, middleKey types.Field, right []*btree.BTreeChildPtr) {
 	midPoint := len(children) / 2
 
-	left = children[:midPoint]
+	left = children[:midPoint:midPoint]
 	middleKey = children[midPoint].Key
 	right = children[midPoint:]
 
@@ -435,7 +425,7 @@ func splitInternalChildren(children []*btree.BTreeChildPtr) (left []*btree.BTree
 //
 // After this operation:
 // - Tree height increases by 1
-// - Root page ID is updated to the new root
+// - The file's root is updated to the new root
 // - All three pages (new root, left, right) are marked dirty
 //
 // Parameters:
@@ -446,7 +436,7 @@ func splitInternalChildren(children []*btree.BTreeChildPtr) (left []*btree.BTree
 // Returns:
 //   - error: Returns error if allocation fails or dirty marking fails
 func (bt *BTree) createNewRoot(left *BTreePage, separatorKey types.Field, right *BTreePage) error {
-	newRoot, err := bt.file.AllocatePage(bt.tx.ID, bt.keyType, false, primitives.InvalidPageNumber)
+	newRoot, err := bt.allocatePage(false, primitives.InvalidPageNumber)
 	if err != nil {
 		return fmt.Errorf("failed to allocate new root: %w", err)
 	}
@@ -459,7 +449,8 @@ func (bt *BTree) createNewRoot(left *BTreePage, separatorKey types.Field, right
 	left.ParentPage = newRoot.PageNo()
 	right.ParentPage = newRoot.PageNo()
 
-	bt.rootPageID = newRoot.GetID()
+	// The old root stays latched, so readers waiting for it see the new root
+	bt.file.SetRoot(newRoot.PageNo())
 
 	bt.addDirtyPage(left, memory.UpdateOperation)
 	bt.addDirtyPage(right, memory.UpdateOperation)
@@ -15,6 +15,7 @@ import (
 	"storemy/pkg/types"
 	"sync"
 	"testing"
+	"time"
 )
 
 func setupTestBTree(t *testing.T, keyType types.Type) (*BTree, *memory.PageStore, *transaction.TransactionContext, string, func()) {
@@ -986,3 +987,67 @@ func TestBTree_Getters(t *testing.T) {
 		t.Errorf("Expected key type IntType, got %v", bt.GetKeyType())
 	}
 }
+
+// Test: A serializable range scan blocks inserts into the range until it
+// commits, even when the index is empty and there is no page to lock
+func TestBTree_SerializableRangeScanBlocksPhantoms(t *testing.T) {
+	bt, store, _, _, cleanup := setupTestBTree(t, types.IntType)
+	defer cleanup()
+
+	scanTx := transaction.NewTransactionContext(primitives.NewTransactionID())
+	scanTx.SetIsolationLevel(transaction.Serializable)
+	reader := NewBTree(bt.indexID, types.IntType, bt.file, scanTx, store)
+
+	results, err := reader.RangeSearch(types.NewIntField(10), types.NewIntField(20))
+	if err != nil {
+		t.Fatalf("RangeSearch failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Expected an empty range, got %d results", len(results))
+	}
+
+	pageID := page.NewPageDescriptor(1, 0)
+	if err := bt.Insert(types.NewIntField(30), tuple.NewTupleRecordID(pageID, 0)); err != nil {
+		t.Fatalf("Insert outside the scanned range failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- bt.Insert(types.NewIntField(15), tuple.NewTupleRecordID(pageID, 1))
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Insert into the scanned range did not wait for the scan: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := store.CommitTransaction(scanTx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Insert after the scan committed failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Insert still blocked after the scanning transaction committed")
+	}
+}
+
+// Test: Scans below the serializable level take no range locks
+func TestBTree_RepeatableReadScanTakesNoRangeLocks(t *testing.T) {
+	bt, store, _, _, cleanup := setupTestBTree(t, types.IntType)
+	defer cleanup()
+
+	scanTx := transaction.NewTransactionContext(primitives.NewTransactionID())
+	reader := NewBTree(bt.indexID, types.IntType, bt.file, scanTx, store)
+	if _, err := reader.Search(types.NewIntField(15)); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if err := bt.Insert(types.NewIntField(15), tuple.NewTupleRecordID(page.NewPageDescriptor(1, 0), 0)); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package btreeindex
+
+import (
+	"errors"
+	"slices"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/index/btree"
+)
+
+// latchMode is the mode a page latch is held in.
+type latchMode int
+
+const (
+	sharedLatch latchMode = iota
+	exclusiveLatch
+)
+
+// errLatchConflict aborts a tree operation that could not take a latch
+// without risking a deadlock; the operation releases everything and retries.
+var errLatchConflict = errors.New("btree: latch conflict, retry")
+
+// treeLatches tracks the page latches held by one tree operation.
+//
+// Operations use latch crabbing: a page is latched before the latch of its
+// parent is released, so no operation ever sees a page halfway through a
+// change. Readers crab with shared latches and hold at most two pages at a
+// time. Writers first try optimistically, holding shared latches on
+// internal pages and an exclusive one on the leaf; if the leaf would split
+// or underflow, they restart and crab with exclusive latches, keeping the
+// ancestors that the split or merge may change and releasing them as soon as
+// a page on the way down is safe.
+type treeLatches struct {
+	table *btree.LatchTable
+	held  map[primitives.PageNumber]latchMode
+	order []primitives.PageNumber // Acquisition order, root first
+}
+
+func newTreeLatches(table *btree.LatchTable) *treeLatches {
+	return &treeLatches{table: table, held: make(map[primitives.PageNumber]latchMode)}
+}
+
+// acquire latches pageNo in mode, blocking until it is available. It does
+// nothing if the operation already holds the latch.
+func (tl *treeLatches) acquire(pageNo primitives.PageNumber, mode latchMode) {
+	if tl.holds(pageNo) {
+		return
+	}
+	latch := tl.table.Latch(pageNo)
+	if mode == exclusiveLatch {
+		latch.Lock()
+	} else {
+		latch.RLock()
+	}
+	tl.track(pageNo, mode)
+}
+
+// tryAcquire is acquire without blocking; it reports whether the latch is
+// held afterwards.
+func (tl *treeLatches) tryAcquire(pageNo primitives.PageNumber, mode latchMode) bool {
+	if tl.holds(pageNo) {
+		return true
+	}
+	latch := tl.table.Latch(pageNo)
+	var ok bool
+	if mode == exclusiveLatch {
+		ok = latch.TryLock()
+	} else {
+		ok = latch.TryRLock()
+	}
+	if ok {
+		tl.track(pageNo, mode)
+	}
+	return ok
+}
+
+func (tl *treeLatches) track(pageNo primitives.PageNumber, mode latchMode) {
+	tl.held[pageNo] = mode
+	tl.order = append(tl.order, pageNo)
+}
+
+// holds reports whether the operation holds the latch of pageNo.
+func (tl *treeLatches) holds(pageNo primitives.PageNumber) bool {
+	_, ok := tl.held[pageNo]
+	return ok
+}
+
+// release releases the latch of pageNo if the operation holds it.
+func (tl *treeLatches) release(pageNo primitives.PageNumber) {
+	mode, ok := tl.held[pageNo]
+	if !ok {
+		return
+	}
+	latch := tl.table.Latch(pageNo)
+	if mode == exclusiveLatch {
+		latch.Unlock()
+	} else {
+		latch.RUnlock()
+	}
+	delete(tl.held, pageNo)
+	tl.order = slices.DeleteFunc(tl.order, func(p primitives.PageNumber) bool { return p == pageNo })
+}
+
+// releaseAllExcept releases every latch except those of keep.
+func (tl *treeLatches) releaseAllExcept(keep ...primitives.PageNumber) {
+	for _, pageNo := range slices.Clone(tl.order) {
+		if !slices.Contains(keep, pageNo) {
+			tl.release(pageNo)
+		}
+	}
+}
+
+// releaseAll releases every latch the operation holds.
+func (tl *treeLatches) releaseAll() {
+	tl.releaseAllExcept()
+}
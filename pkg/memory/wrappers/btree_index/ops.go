@@ -1,10 +1,15 @@
 package btreeindex
 
 import (
+	"errors"
 	"fmt"
+	"runtime"
+	"slices"
+	"storemy/pkg/concurrency/lock"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/index"
+	"storemy/pkg/storage/index/btree"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
@@ -14,15 +19,19 @@ import (
 // This is the main entry point for all insert operations in the index.
 //
 // The insertion process:
-// 1. Validates key type matches index configuration
-// 2. Finds the appropriate leaf page by traversing from root
-// 3. If leaf has space: directly inserts maintaining sort order
-// 4. If leaf is full: triggers split operation that may propagate upward
+//  1. Validates key type matches index configuration
+//  2. Waits for serializable transactions that scanned a range containing
+//     key to finish, so the insert cannot create a phantom in their scan
+//  3. Optimistically descends with shared latches and latches only the leaf;
+//     if the entry fits without a split or a parent update, inserts it there
+//  4. Otherwise descends again with exclusive latches on every page the
+//     split may reach, and splits the leaf, propagating upward as needed
 //
 // Key behaviors:
 // - Duplicate (key, RID) pairs are rejected with error
 // - Empty tree gets first entry inserted in root leaf
 // - Splits may cascade up to root, potentially increasing tree height
+// - Safe for concurrent use with other inserts, deletes and searches
 //
 // Parameters:
 //   - key: The index key to insert (must match index keyType)
@@ -35,36 +44,69 @@ func (bt *BTree) Insert(key types.Field, rid *tuple.TupleRecordID) error {
 		return fmt.Errorf("key type mismatch: expected %v, got %v", bt.keyType, key.Type())
 	}
 
-	entry := index.NewIndexEntry(key, rid)
-	rootPage, err := bt.getRootPage(transaction.ReadWrite)
-	if err != nil {
+	if err := bt.store.LockKeyInsert(bt.tx, bt.indexID, key); err != nil {
+		return err
+	}
+
+	if err := bt.ensureRoot(); err != nil {
 		return fmt.Errorf("failed to get root page: %w", err)
 	}
 
-	if rootPage.GetNumEntries() == 0 && rootPage.IsLeafPage() {
-		return bt.insertIntoLeaf(rootPage, entry)
+	entry := index.NewIndexEntry(key, rid)
+	op := bt.newOperation()
+	leafPage, err := op.findLeaf(key, transaction.ReadWrite, exclusiveLatch)
+	if err == nil && insertStaysInLeaf(leafPage, key) {
+		err = op.insertIntoLeaf(leafPage, entry)
+		op.latches.releaseAll()
+		return err
+	}
+	op.latches.releaseAll()
+	if err != nil {
+		return fmt.Errorf("failed to find leaf page: %w", err)
 	}
 
-	leafPage, err := bt.findLeafPage(rootPage, key)
+	op = bt.newOperation()
+	defer op.latches.releaseAll()
+
+	leafPage, err = op.findLeafExclusive(key, func(p *BTreePage) bool { return !p.IsFull() })
 	if err != nil {
 		return fmt.Errorf("failed to find leaf page: %w", err)
 	}
 
 	if leafPage.IsFull() {
-		return bt.insertAndSplitLeaf(leafPage, key, rid)
+		return op.insertAndSplitLeaf(leafPage, key, rid)
 	}
 
-	return bt.insertIntoLeaf(leafPage, entry)
+	return op.insertIntoLeaf(leafPage, entry)
+}
+
+// insertStaysInLeaf reports whether inserting key changes only the leaf:
+// it has room, and key does not become the leaf's first key, which would
+// update the separator in its parent.
+func insertStaysInLeaf(leaf *BTreePage, key types.Field) bool {
+	if leaf.IsFull() {
+		return false
+	}
+	if leaf.IsRoot() {
+		return true
+	}
+	if len(leaf.Entries) == 0 {
+		return false
+	}
+	lt, _ := key.Compare(primitives.LessThan, leaf.Entries[0].Key)
+	return !lt
 }
 
 // Delete removes a key-value pair from the B+Tree, maintaining balance through merging.
 // Finds the appropriate leaf page and removes the specified entry if it exists.
 //
 // The deletion process:
-// 1. Validates key type matches index configuration
-// 2. Traverses tree to find leaf page containing the key
-// 3. Removes the entry from the leaf if found
-// 4. May trigger merge/redistribution if page becomes full
+//  1. Validates key type matches index configuration
+//  2. Optimistically descends with shared latches and latches only the leaf;
+//     if the leaf stays at least half full and keeps its first key, deletes there
+//  3. Otherwise descends again with exclusive latches on every page a merge
+//     or redistribution may reach, and removes the entry
+//  4. May trigger merge/redistribution if page becomes underfull
 //
 // Note: The tid parameter is unused as transaction context is stored in bt.tx.
 // This parameter exists for interface compatibility.
@@ -81,18 +123,76 @@ func (bt *BTree) Delete(key types.Field, rid *tuple.TupleRecordID) error {
 		return fmt.Errorf("key type mismatch: expected %v, got %v", bt.keyType, key.Type())
 	}
 
-	rootPage, err := bt.getRootPage(transaction.ReadWrite)
+	entry := index.NewIndexEntry(key, rid)
+	op := bt.newOperation()
+	leafPage, err := op.findLeaf(key, transaction.ReadWrite, exclusiveLatch)
+	if err == nil && leafPage == nil {
+		op.latches.releaseAll()
+		return fmt.Errorf("entry not found")
+	}
+	if err == nil && deleteStaysInLeaf(leafPage, entry) {
+		err = op.deleteFromLeaf(leafPage, entry)
+		op.latches.releaseAll()
+		return err
+	}
+	op.latches.releaseAll()
 	if err != nil {
-		return fmt.Errorf("failed to get root page: %w", err)
+		return fmt.Errorf("failed to find leaf page: %w", err)
 	}
 
-	entry := index.NewIndexEntry(key, rid)
-	leafPage, err := bt.findLeafPage(rootPage, key)
+	for {
+		err := bt.deleteExclusive(key, entry)
+		if !errors.Is(err, errLatchConflict) {
+			return err
+		}
+		runtime.Gosched()
+	}
+}
+
+// deleteStaysInLeaf reports whether deleting entry changes only the leaf:
+// the leaf stays at least half full and entry is not its first, whose
+// removal would update the separator in its parent. A missing entry changes
+// nothing.
+func deleteStaysInLeaf(leaf *BTreePage, entry *index.IndexEntry) bool {
+	idx := slices.IndexFunc(leaf.Entries, func(e *index.IndexEntry) bool {
+		return e.Equals(entry)
+	})
+	return idx == -1 || leaf.IsRoot() || (idx > 0 && leaf.HasMoreThanRequired())
+}
+
+// deleteExclusive is the pessimistic pass of Delete. If the leaf will
+// underflow, its left sibling is latched before anything changes; a range
+// scan moving right may hold that latch while waiting for the leaf's, so
+// rather than wait for it, the pass gives up with errLatchConflict and
+// Delete starts over.
+func (bt *BTree) deleteExclusive(key types.Field, entry *index.IndexEntry) error {
+	op := bt.newOperation()
+	defer op.latches.releaseAll()
+
+	leafPage, err := op.findLeafExclusive(key, (*BTreePage).HasMoreThanRequired)
 	if err != nil {
 		return fmt.Errorf("failed to find leaf page: %w", err)
 	}
+	if leafPage == nil {
+		return fmt.Errorf("entry not found")
+	}
+
+	if !leafPage.IsRoot() && !leafPage.HasMoreThanRequired() {
+		parent, err := op.fetchForUpdate(page.NewPageDescriptor(bt.indexID, leafPage.Parent()))
+		if err != nil {
+			return err
+		}
 
-	return bt.deleteFromLeaf(leafPage, entry)
+		leafID := leafPage.GetID()
+		childIdx := slices.IndexFunc(parent.Children(), func(c *btree.BTreeChildPtr) bool {
+			return c.ChildPID.Equals(leafID)
+		})
+		if childIdx > 0 && !op.latches.tryAcquire(parent.Children()[childIdx-1].ChildPID.PageNo(), exclusiveLatch) {
+			return errLatchConflict
+		}
+	}
+
+	return op.deleteFromLeaf(leafPage, entry)
 }
 
 // Search finds all tuple locations (RIDs) for a given key using exact match.
@@ -100,9 +200,10 @@ func (bt *BTree) Delete(key types.Field, rid *tuple.TupleRecordID) error {
 //
 // The search process:
 // 1. Validates key type matches index configuration
-// 2. Traverses tree from root to appropriate leaf page
-// 3. Scans leaf entries for all matches (handles duplicate keys)
-// 4. Returns empty slice if key not found (not an error)
+// 2. Locks the key against inserts if the transaction is serializable
+// 3. Traverses tree from root to appropriate leaf page
+// 4. Scans leaf entries for all matches (handles duplicate keys)
+// 5. Returns empty slice if key not found (not an error)
 //
 // B+Tree guarantees:
 // - O(log N) traversal time where N is number of keys
@@ -120,19 +221,20 @@ func (bt *BTree) Search(key types.Field) ([]*tuple.TupleRecordID, error) {
 		return nil, fmt.Errorf("key type mismatch: expected %v, got %v", bt.keyType, key.Type())
 	}
 
-	rootPage, err := bt.getRootPage(transaction.ReadOnly)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get root page: %w", err)
+	if err := bt.store.LockKeyRange(bt.tx, bt.indexID, lock.KeyRange{Low: key, High: key}); err != nil {
+		return nil, err
 	}
 
-	if rootPage.GetNumEntries() == 0 {
-		return []*tuple.TupleRecordID{}, nil
-	}
+	op := bt.newOperation()
+	defer op.latches.releaseAll()
 
-	leafPage, err := bt.findLeafPage(rootPage, key)
+	leafPage, err := op.findLeaf(key, transaction.ReadOnly, sharedLatch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find leaf page: %w", err)
 	}
+	if leafPage == nil {
+		return []*tuple.TupleRecordID{}, nil
+	}
 
 	var results []*tuple.TupleRecordID
 	for _, entry := range leafPage.Entries {
@@ -148,14 +250,15 @@ func (bt *BTree) Search(key types.Field) ([]*tuple.TupleRecordID, error) {
 //
 // The range scan process:
 // 1. Validates both keys match index type
-// 2. Finds leaf page containing startKey
-// 3. Scans forward through leaf chain collecting matching entries
-// 4. Stops when endKey is exceeded or leaf chain ends
+// 2. Locks [startKey, endKey] against inserts if the transaction is serializable
+// 3. Finds leaf page containing startKey
+// 4. Scans forward through leaf chain collecting matching entries
+// 5. Stops when endKey is exceeded or leaf chain ends
 //
 // Efficiency characteristics:
 // - O(log N) to find starting leaf
 // - O(M) to scan M matching entries across leaf pages
-// - Uses doubly-linked leaf chain (NextLeaf pointers)
+// - Uses doubly-linked leaf chain (NextLeaf pointers), crabbing latches left to right
 // - More efficient than repeated point lookups for ranges
 //
 // Note: The tid parameter is unused as transaction context is stored in bt.tx.
@@ -174,19 +277,20 @@ func (bt *BTree) RangeSearch(startKey, endKey types.Field) ([]*tuple.TupleRecord
 		return nil, fmt.Errorf("key type mismatch")
 	}
 
-	rootPage, err := bt.getRootPage(transaction.ReadOnly)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get root page: %w", err)
+	if err := bt.store.LockKeyRange(bt.tx, bt.indexID, lock.KeyRange{Low: startKey, High: endKey}); err != nil {
+		return nil, err
 	}
 
-	if rootPage.GetNumEntries() == 0 {
-		return []*tuple.TupleRecordID{}, nil
-	}
+	op := bt.newOperation()
+	defer op.latches.releaseAll()
 
-	leafPage, err := bt.findLeafPage(rootPage, startKey)
+	leafPage, err := op.findLeaf(startKey, transaction.ReadOnly, sharedLatch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find start leaf page: %w", err)
 	}
+	if leafPage == nil {
+		return []*tuple.TupleRecordID{}, nil
+	}
 
 	var results []*tuple.TupleRecordID
 
@@ -206,12 +310,16 @@ func (bt *BTree) RangeSearch(startKey, endKey types.Field) ([]*tuple.TupleRecord
 			break
 		}
 
+		// Latch the next leaf before releasing this one, so no split or
+		// merge can move entries past the scan in between
 		_, nextLeaf := leafPage.Leaves()
 		nextPageID := page.NewPageDescriptor(bt.indexID, nextLeaf)
-		leafPage, err = bt.getPage(nextPageID, transaction.ReadOnly)
+		next, err := op.latchPage(nextPageID, transaction.ReadOnly, sharedLatch, sharedLatch)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read next leaf page: %w", err)
 		}
+		op.latches.release(leafPage.PageNo())
+		leafPage = next
 	}
 
 	return results, nil
@@ -2,6 +2,7 @@ package hashindex
 
 import (
 	"fmt"
+	"storemy/pkg/concurrency/lock"
 	"storemy/pkg/memory"
 	"storemy/pkg/storage/index"
 	"storemy/pkg/storage/page"
@@ -21,6 +22,7 @@ import (
 //   - Write operation fails
 //
 // Behavior:
+//   - Waits for serializable scans covering key to finish (no phantoms)
 //   - Hashes key to determine target bucket
 //   - Traverses overflow chain if bucket is full
 //   - Creates new overflow page if needed
@@ -30,6 +32,10 @@ func (hi *HashIndex) Insert(key Field, rid RecID) error {
 		return err
 	}
 
+	if err := hi.pageStore.LockKeyInsert(hi.tx, hi.indexID, key); err != nil {
+		return err
+	}
+
 	bucketNum, err := hi.hashKey(key)
 	if err != nil {
 		return err
@@ -97,12 +103,19 @@ func (hi *HashIndex) Delete(key Field, rid RecID) error {
 //   - Slice of TupleRecordIDs pointing to matching tuples
 //   - Error if key type is invalid or page read fails
 //
+// At the serializable isolation level the key is locked against inserts by
+// other transactions until this one finishes.
+//
 // Performance: O(1) average case, O(k) where k is overflow chain length.
 func (hi *HashIndex) Search(key Field) ([]RecID, error) {
 	if err := hi.validateKeyType(key); err != nil {
 		return nil, err
 	}
 
+	if err := hi.pageStore.LockKeyRange(hi.tx, hi.indexID, lock.KeyRange{Low: key, High: key}); err != nil {
+		return nil, err
+	}
+
 	bucketPage, err := hi.getBucketPage(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bucket page: %w", err)
@@ -125,6 +138,9 @@ func (hi *HashIndex) Search(key Field) ([]RecID, error) {
 //   - Slice of TupleRecordIDs for tuples in range
 //   - Error if key types don't match or page reads fail
 //
+// At the serializable isolation level the range is locked against inserts by
+// other transactions until this one finishes.
+//
 // Performance: O(n) where n is total number of index entries.
 // Consider using B-Tree index for efficient range queries.
 func (hi *HashIndex) RangeSearch(startKey, endKey Field) ([]RecID, error) {
@@ -132,6 +148,10 @@ func (hi *HashIndex) RangeSearch(startKey, endKey Field) ([]RecID, error) {
 		return nil, fmt.Errorf("key type mismatch")
 	}
 
+	if err := hi.pageStore.LockKeyRange(hi.tx, hi.indexID, lock.KeyRange{Low: startKey, High: endKey}); err != nil {
+		return nil, err
+	}
+
 	var results []RecID
 
 	for bucketNum := 0; bucketNum < hi.numBuckets; bucketNum++ {
@@ -0,0 +1,108 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/memory"
+	"storemy/pkg/storage/heap"
+	"storemy/pkg/tuple"
+)
+
+// BulkInsert appends the tuples returned by next to dbFile until next
+// returns a nil tuple, and returns how many it inserted. The tuples go into
+// new pages written straight to the file: nothing is logged for them, and
+// they bypass the buffer pool. See memory.BulkLoader for how a bulk load is
+// made durable and undone.
+//
+// Indexes are not maintained, so BulkInsert must only be used on tables
+// without any. While ctx is active, other transactions cannot add pages to
+// dbFile.
+func (tm *TupleManager) BulkInsert(ctx *transaction.TransactionContext, dbFile *heap.HeapFile, next func() (*tuple.Tuple, error)) (int, error) {
+	if err := validateTransactionContext(ctx); err != nil {
+		return 0, err
+	}
+
+	loader, err := tm.pageProvider.BeginBulkLoad(ctx, dbFile)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := tm.bulkInsert(ctx, loader, dbFile, next)
+	if err == nil {
+		_, err = loader.Finish()
+	}
+	if err != nil {
+		tm.releaseRows(ctx, dbFile, count)
+		if cancelErr := loader.Cancel(); cancelErr != nil {
+			return 0, fmt.Errorf("%v (and failed to undo the bulk load: %v)", err, cancelErr)
+		}
+		return 0, err
+	}
+	return count, nil
+}
+
+// bulkInsert fills pages from loader with the tuples returned by next,
+// reserving each against the quota. On failure it returns how many rows it
+// reserved, for the caller to release.
+func (tm *TupleManager) bulkInsert(ctx *transaction.TransactionContext, loader *memory.BulkLoader, dbFile *heap.HeapFile, next func() (*tuple.Tuple, error)) (int, error) {
+	var current *heap.HeapPage
+	count := 0
+
+	for {
+		t, err := next()
+		if err != nil {
+			return count, err
+		}
+		if t == nil {
+			break
+		}
+		if err := tm.reserveRows(ctx, dbFile, 1); err != nil {
+			return count, err
+		}
+		count++
+
+		if current == nil || current.GetNumEmptySlots() == 0 {
+			if current, err = tm.nextBulkPage(ctx, loader, dbFile, current); err != nil {
+				return count, err
+			}
+		}
+
+		err = current.AddTuple(t)
+		if errors.Is(err, heap.ErrPageFull) {
+			if current, err = tm.nextBulkPage(ctx, loader, dbFile, current); err != nil {
+				return count, err
+			}
+			err = current.AddTuple(t)
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to add tuple %d: %v", count-1, err)
+		}
+	}
+
+	if current != nil {
+		if err := loader.WritePage(current); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// nextBulkPage writes full, unless it is nil, and returns an empty page
+// allocated by loader if the quota leaves room for it.
+func (tm *TupleManager) nextBulkPage(ctx *transaction.TransactionContext, loader *memory.BulkLoader, dbFile *heap.HeapFile, full *heap.HeapPage) (*heap.HeapPage, error) {
+	if full != nil {
+		if err := loader.WritePage(full); err != nil {
+			return nil, err
+		}
+	}
+	if err := tm.reservePage(ctx, dbFile); err != nil {
+		return nil, err
+	}
+
+	pid, err := loader.NextPage()
+	if err != nil {
+		return nil, err
+	}
+	return heap.NewEmptyHeapPage(pid, dbFile.GetTupleDesc())
+}
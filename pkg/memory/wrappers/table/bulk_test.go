@@ -0,0 +1,126 @@
+package table
+
+import (
+	"errors"
+	"storemy/pkg/memory"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/heap"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"strings"
+	"testing"
+)
+
+// tupleSource returns a BulkInsert source producing n tuples.
+func tupleSource(td *tuple.TupleDescription, n int) func() (*tuple.Tuple, error) {
+	i := 0
+	return func() (*tuple.Tuple, error) {
+		if i == n {
+			return nil, nil
+		}
+		i++
+		return createTestTuple(td, int64(i), strings.Repeat("x", 40)), nil
+	}
+}
+
+// countTuples counts the tuples stored on disk in heapFile.
+func countTuples(t *testing.T, heapFile *heap.HeapFile) int {
+	t.Helper()
+	numPages, err := heapFile.NumPages()
+	if err != nil {
+		t.Fatalf("NumPages failed: %v", err)
+	}
+
+	count := 0
+	for i := range numPages {
+		pg, err := heapFile.ReadPage(page.NewPageDescriptor(heapFile.GetID(), i))
+		if err != nil {
+			t.Fatalf("ReadPage %d failed: %v", i, err)
+		}
+		count += len(pg.(*heap.HeapPage).GetTuples())
+	}
+	return count
+}
+
+func TestBulkInsert_Commit(t *testing.T) {
+	tm, heapFile, ps, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := createTransactionContext(t)
+	startLSN := ps.GetWal().CurrentLSN()
+
+	const numTuples = 500
+	count, err := tm.BulkInsert(ctx, heapFile, tupleSource(heapFile.GetTupleDesc(), numTuples))
+	if err != nil {
+		t.Fatalf("BulkInsert failed: %v", err)
+	}
+	if count != numTuples {
+		t.Errorf("expected %d tuples inserted, got %d", numTuples, count)
+	}
+
+	if numPages, _ := heapFile.NumPages(); numPages < 2 {
+		t.Fatalf("expected the load to span several pages, got %d", numPages)
+	}
+	if err := ps.CommitTransaction(ctx); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+
+	// BEGIN, BULK LOAD, its barrier and COMMIT, but no page images
+	if logged := ps.GetWal().CurrentLSN() - startLSN; logged >= primitives.LSN(page.PageSize) {
+		t.Errorf("expected the load to log less than a page, logged %d bytes", logged)
+	}
+	if got := countTuples(t, heapFile); got != numTuples {
+		t.Errorf("expected %d tuples on disk, got %d", numTuples, got)
+	}
+}
+
+func TestBulkInsert_AbortZeroesPages(t *testing.T) {
+	tm, heapFile, ps, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := createTransactionContext(t)
+	if _, err := tm.BulkInsert(ctx, heapFile, tupleSource(heapFile.GetTupleDesc(), 300)); err != nil {
+		t.Fatalf("BulkInsert failed: %v", err)
+	}
+	if err := ps.AbortTransaction(ctx); err != nil {
+		t.Fatalf("AbortTransaction failed: %v", err)
+	}
+
+	if got := countTuples(t, heapFile); got != 0 {
+		t.Errorf("expected no tuples after abort, got %d", got)
+	}
+
+	// The table is free again once the loader has ended
+	other := createTransactionContext(t)
+	if err := tm.InsertTuple(other, heapFile, createTestTuple(heapFile.GetTupleDesc(), 1, "a")); err != nil {
+		t.Fatalf("InsertTuple after abort failed: %v", err)
+	}
+	if err := ps.CommitTransaction(other); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+}
+
+func TestBulkInsert_BlocksOtherInserters(t *testing.T) {
+	tm, heapFile, ps, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	loader := createTransactionContext(t)
+	if _, err := tm.BulkInsert(loader, heapFile, tupleSource(heapFile.GetTupleDesc(), 10)); err != nil {
+		t.Fatalf("BulkInsert failed: %v", err)
+	}
+
+	other := createTransactionContext(t)
+	_, err := tm.BulkInsert(other, heapFile, tupleSource(heapFile.GetTupleDesc(), 10))
+	if !errors.Is(err, memory.ErrBulkLoadInProgress) {
+		t.Errorf("expected ErrBulkLoadInProgress for a second loader, got %v", err)
+	}
+
+	if _, err := tm.newPage(other, heapFile); !errors.Is(err, memory.ErrBulkLoadInProgress) {
+		t.Errorf("expected ErrBulkLoadInProgress when allocating a page, got %v", err)
+	}
+
+	ps.AbortTransaction(other)
+	if err := ps.CommitTransaction(loader); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/memory"
+	"storemy/pkg/primitives"
 	"storemy/pkg/storage/heap"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
@@ -22,6 +23,7 @@ type DeleteOp struct {
 	tuples []*tuple.Tuple
 
 	executed bool
+	lsn      primitives.LSN // LSN of the last delete logged, the pageLSN of the pages it changed
 }
 
 // NewDeleteOp creates a new batch delete operation for the given transaction.
@@ -112,20 +114,23 @@ func (op *DeleteOp) Execute() error {
 	for i, t := range op.tuples {
 		modifiedPages, err := op.handleDelete(t)
 		if err != nil {
+			op.tm.releaseRows(op.ctx, op.dbFile, i)
 			return fmt.Errorf("failed to delete tuple at index %d: %v", i, err)
 		}
 
-		op.tm.markPagesAsDirty(op.ctx, modifiedPages)
+		op.tm.markPagesAsDirty(op.ctx, op.lsn, modifiedPages)
 	}
 
+	op.tm.releaseRows(op.ctx, op.dbFile, len(op.tuples))
 	return nil
 }
 
 // handleDelete executes the delete operation and logs it to WAL.
 // This helper:
 //   - Acquires exclusive lock on page containing tuple
-//   - Logs before-image to WAL for UNDO capability
+//   - Logs the tuple to WAL as before-image for UNDO capability
 //   - Performs actual tuple deletion on HeapPage
+//   - Follows a forward pointer to delete a moved tuple's copy as well
 //   - Marks page as dirty
 //
 // Parameters:
@@ -134,7 +139,7 @@ func (op *DeleteOp) Execute() error {
 //   - t: Tuple to delete (must have RecordID)
 //
 // Returns:
-//   - modifiedPages: Single-element array with the page containing deleted tuple
+//   - modifiedPages: The page containing the deleted tuple, and the page its copy was moved to if any
 //   - error: If page access, WAL logging, or deletion fails
 func (op *DeleteOp) handleDelete(t *tuple.Tuple) ([]*heap.HeapPage, error) {
 	pageID := t.RecordID.PageID
@@ -148,17 +153,55 @@ func (op *DeleteOp) handleDelete(t *tuple.Tuple) ([]*heap.HeapPage, error) {
 		return nil, fmt.Errorf("failed to get page for delete: %v", err)
 	}
 
-	if err := op.tm.logOperation(memory.DeleteOperation, op.ctx.ID, pageID, pg.GetPageData()); err != nil {
+	lsn, err := op.tm.logOperation(memory.DeleteOperation, op.ctx.ID, t)
+	if err != nil {
 		return nil, err
 	}
+	op.lsn = lsn
 
-	if heapPage, ok := pg.(*heap.HeapPage); ok {
-		if err := heapPage.DeleteTuple(t); err != nil {
-			return nil, fmt.Errorf("failed to delete tuple: %v", err)
+	heapPage, ok := pg.(*heap.HeapPage)
+	if !ok {
+		return nil, fmt.Errorf("expecting the pageType to be of heapage")
+	}
+
+	var modifiedPages []*heap.HeapPage
+	if target, ok := heapPage.Forward(t.RecordID.TupleNum); ok {
+		targetPage, err := op.deleteMoved(t, target)
+		if err != nil {
+			return nil, err
 		}
-		heapPage.MarkDirty(true, op.ctx.ID)
-		return []*heap.HeapPage{heapPage}, nil
+		modifiedPages = append(modifiedPages, targetPage)
 	}
 
-	return nil, fmt.Errorf("expecting the pageType to be of heapage")
+	if err := heapPage.DeleteTuple(t); err != nil {
+		return nil, fmt.Errorf("failed to delete tuple: %v", err)
+	}
+	heapPage.MarkDirty(true, op.ctx.ID)
+	return append(modifiedPages, heapPage), nil
+}
+
+// deleteMoved deletes the copy of t that was moved to target, leaving the
+// forward pointer in t's home slot for the caller to delete. The caller has
+// already logged the delete under t's RecordID.
+func (op *DeleteOp) deleteMoved(t *tuple.Tuple, target *tuple.TupleRecordID) (*heap.HeapPage, error) {
+	hpid, ok := target.PageID.(*page.PageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("wrong page id format")
+	}
+
+	pg, err := op.tm.pageProvider.GetPage(op.ctx, op.dbFile, hpid, transaction.ReadWrite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of moved tuple: %v", err)
+	}
+
+	targetPage, ok := pg.(*heap.HeapPage)
+	if !ok {
+		return nil, fmt.Errorf("expecting the pageType to be of heapage")
+	}
+
+	if err := targetPage.DeleteTuple(t); err != nil {
+		return nil, fmt.Errorf("failed to delete moved tuple: %v", err)
+	}
+	targetPage.MarkDirty(true, op.ctx.ID)
+	return targetPage, nil
 }
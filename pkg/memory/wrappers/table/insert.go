@@ -1,9 +1,11 @@
 package table
 
 import (
+	"errors"
 	"fmt"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/memory"
+	"storemy/pkg/primitives"
 	"storemy/pkg/storage/heap"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
@@ -24,6 +26,7 @@ type InsertOp struct {
 	// Track execution state
 	executed       bool
 	insertedTuples []*tuple.Tuple // Successfully inserted tuples (for partial rollback)
+	lsn            primitives.LSN // LSN of the last insert logged, the pageLSN of the page it changed
 }
 
 // NewInsertOp creates a new batch insert operation for the given transaction.
@@ -86,9 +89,10 @@ func (op *InsertOp) Validate() error {
 // This operation:
 //  1. Validates the operation
 //  2. Ensures transaction has logged BEGIN record
-//  3. Inserts all tuples (fail-fast on first error)
-//  4. Updates all indexes once after all insertions
-//  5. Records modification for statistics
+//  3. Reserves the rows against the quota, if one is set
+//  4. Inserts all tuples (fail-fast on first error)
+//  5. Updates all indexes once after all insertions
+//  6. Records modification for statistics
 //
 // On failure, successfully inserted tuples remain inserted (transaction rollback will undo them).
 // The operation becomes marked as executed regardless of success/failure.
@@ -103,14 +107,18 @@ func (op *InsertOp) Execute() error {
 	}
 
 	tableID := op.dbFile.GetID()
+	if err := op.tm.reserveRows(op.ctx, op.dbFile, len(op.tuples)); err != nil {
+		return err
+	}
 
 	for i, t := range op.tuples {
 		modifiedPages, err := op.handleInsert(t)
 		if err != nil {
-			return fmt.Errorf("failed to insert tuple at index %d: %v", i, err)
+			op.tm.releaseRows(op.ctx, op.dbFile, len(op.tuples)-i)
+			return fmt.Errorf("failed to insert tuple at index %d: %w", i, err)
 		}
 
-		op.tm.markPagesAsDirty(op.ctx, modifiedPages)
+		op.tm.markPagesAsDirty(op.ctx, op.lsn, modifiedPages)
 		op.insertedTuples = append(op.insertedTuples, t)
 	}
 
@@ -158,31 +166,34 @@ func (op *InsertOp) handleInsert(t *tuple.Tuple) ([]*heap.HeapPage, error) {
 //
 // The function follows this sequence:
 //  1. Atomically allocate next page number (prevents concurrent allocation races)
-//  2. Create new HeapPage with fresh page buffer
-//  3. Add tuple to the new page
-//  4. Write page to disk through heap file
-//  5. Log operation to WAL for durability
-//  6. Mark page as dirty in transaction context
+//  2. Fetch the new page through the page store with an exclusive lock
+//  3. Add tuple to the new page, allocating another if a concurrent insert filled it first
+//  4. Log the inserted tuple to WAL for durability
+//  5. Mark page as dirty in transaction context
+//
+// Like any other page the transaction changes, the new page stays in the
+// buffer pool until commit, when it is flushed after its log record.
 func (op *InsertOp) insertIntoNewPage(t *tuple.Tuple) ([]*heap.HeapPage, error) {
-	p, err := op.createNewPage()
-	if err != nil {
-		return nil, err
-	}
+	for {
+		p, err := op.tm.newPage(op.ctx, op.dbFile)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := p.AddTuple(t); err != nil {
-		return nil, fmt.Errorf("failed to add tuple to new page: %v", err)
-	}
+		if err := p.AddTuple(t); err != nil {
+			if errors.Is(err, heap.ErrPageFull) || p.GetNumEmptySlots() == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("failed to add tuple to new page: %v", err)
+		}
 
-	if err := op.dbFile.WritePage(p); err != nil {
-		return nil, fmt.Errorf("failed to write new page: %v", err)
-	}
+		if err := op.logInsert(t); err != nil {
+			return nil, err
+		}
 
-	if err := op.logInsert(p); err != nil {
-		return nil, err
+		p.MarkDirty(true, op.ctx.ID)
+		return []*heap.HeapPage{p}, nil
 	}
-
-	p.MarkDirty(true, op.ctx.ID)
-	return []*heap.HeapPage{p}, nil
 }
 
 // tryInsertIntoExistingPages attempts to insert a tuple into any existing page with free space.
@@ -193,11 +204,14 @@ func (op *InsertOp) insertIntoNewPage(t *tuple.Tuple) ([]*heap.HeapPage, error)
 //  1. Construct page ID for current page number
 //  2. Acquire exclusive lock via GetPage (ReadWrite mode)
 //  3. Check if page has empty slots (bitmap-based check)
-//  4. Log operation to WAL with before-image
-//  5. Attempt tuple insertion
+//  4. Attempt tuple insertion
+//  5. Log the inserted tuple to WAL, now that its RecordID is known
 //  6. Mark page dirty if insertion succeeds
 //  7. Return immediately on first successful insertion
 //
+// The page stays locked and in the buffer pool until the transaction ends, so
+// the log record still reaches the WAL before the page reaches disk.
+//
 // Concurrency Behavior:
 //   - Page-level locks prevent concurrent modifications to the same page
 //   - Multiple transactions can insert into different pages simultaneously
@@ -228,11 +242,10 @@ func (op *InsertOp) tryInsertIntoExistingPages(t *tuple.Tuple) ([]*heap.HeapPage
 		}
 
 		if heapPage.GetNumEmptySlots() > 0 {
-			if err := op.logInsert(heapPage); err != nil {
-				return nil, false, err
-			}
-
 			if err := heapPage.AddTuple(t); err == nil {
+				if err := op.logInsert(t); err != nil {
+					return nil, false, err
+				}
 				heapPage.MarkDirty(true, op.ctx.ID)
 				return []*heap.HeapPage{heapPage}, true, nil
 			}
@@ -242,21 +255,43 @@ func (op *InsertOp) tryInsertIntoExistingPages(t *tuple.Tuple) ([]*heap.HeapPage
 	return nil, false, nil
 }
 
-func (op *InsertOp) createNewPage() (*heap.HeapPage, error) {
-	f := op.dbFile
-	newPageNo, err := f.AllocateNewPage()
+// newPage allocates a page at the end of the heap file and fetches it
+// through the page store with an exclusive lock, like any page ctx changes.
+//
+// Other transactions see the page as soon as the file grows, so one of them
+// may lock it first; the page returned is then not necessarily empty. No
+// page is allocated while another transaction bulk loads the table, nor
+// once the database has reached its size quota.
+func (tm *TupleManager) newPage(ctx *transaction.TransactionContext, f *heap.HeapFile) (*heap.HeapPage, error) {
+	if err := tm.reservePage(ctx, f); err != nil {
+		return nil, err
+	}
+
+	newPageNo, err := tm.pageProvider.AllocatePage(ctx, f)
 	if err != nil {
-		return nil, fmt.Errorf("failed to allocate new page: %v", err)
+		return nil, fmt.Errorf("failed to allocate new page: %w", err)
 	}
 
 	newPageID := page.NewPageDescriptor(f.GetID(), newPageNo)
-	newPage, err := heap.NewEmptyHeapPage(newPageID, f.GetTupleDesc())
+	pg, err := tm.pageProvider.GetPage(ctx, f, newPageID, transaction.ReadWrite)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new page: %v", err)
+		return nil, fmt.Errorf("failed to get new page: %v", err)
+	}
+
+	newPage, ok := pg.(*heap.HeapPage)
+	if !ok {
+		return nil, fmt.Errorf("expecting the pageType to be of heapage")
 	}
-	return newPage, err
+	return newPage, nil
 }
 
-func (op *InsertOp) logInsert(page *heap.HeapPage) error {
-	return op.tm.logOperation(memory.InsertOperation, op.ctx.ID, page.GetID(), page.GetPageData())
+// logInsert logs the insertion of t, which must already have its RecordID,
+// and remembers the LSN of the record in op.lsn.
+func (op *InsertOp) logInsert(t *tuple.Tuple) error {
+	lsn, err := op.tm.logOperation(memory.InsertOperation, op.ctx.ID, t)
+	if err != nil {
+		return err
+	}
+	op.lsn = lsn
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/indexmanager"
+	"storemy/pkg/log/logmanager"
 	"storemy/pkg/log/wal"
 	"storemy/pkg/memory"
 	"storemy/pkg/primitives"
@@ -47,7 +48,7 @@ type TupleOperation interface {
 // Responsibilities:
 //   - Tuple insertion (finding free space, handling page allocation)
 //   - Tuple deletion (locating and removing tuples)
-//   - Tuple updates (implementing delete+insert semantics)
+//   - Tuple updates (in place, keeping each tuple's RecordID)
 //   - WAL logging for all tuple operations
 //   - Transaction coordination for tuple operations
 //   - Index maintenance on all DML operations
@@ -55,7 +56,9 @@ type TupleOperation interface {
 type TupleManager struct {
 	pageProvider *memory.PageStore
 	wal          *wal.WAL
+	logs         *logmanager.LogManager
 	indexManager *indexmanager.IndexManager
+	quota        Quota // Checked by inserts, nil without quotas
 }
 
 // NewTupleManager creates a new TupleManager instance
@@ -63,6 +66,7 @@ func NewTupleManager(store *memory.PageStore) *TupleManager {
 	return &TupleManager{
 		pageProvider: store,
 		wal:          store.GetWal(),
+		logs:         logmanager.NewLogManager(store.GetWal()),
 		indexManager: nil,
 	}
 }
@@ -112,26 +116,24 @@ func (tm *TupleManager) DeleteTuple(ctx *transaction.TransactionContext, dbFile
 }
 
 // UpdateTuple replaces an existing tuple with a new version within the given transaction.
-// This implements the UPDATE operation as a DELETE followed by INSERT:
-//  1. Delete old tuple (preserving before-image)
-//  2. Insert new tuple (may go to different page)
-//  3. Rollback insert if it fails (re-insert old tuple)
-//
-// This approach simplifies MVCC and recovery but may cause fragmentation over time.
-// Future optimization: In-place updates when tuple size doesn't change.
+// The new version takes the old one's slot, so newTuple gets the same RecordID
+// and indexes keep pointing at the right row:
+//  1. Store the new version in the old slot (compacting the page if needed)
+//  2. If it no longer fits on the page, move it to another page and leave a
+//     forward pointer in the old slot
+//  3. Update indexes for the changed key values
 //
 // Parameters:
 //   - ctx: Transaction context
 //   - dbFile: Heap file for the table
 //   - oldTuple: Current version to replace (must have RecordID)
-//   - newTuple: New version to insert
+//   - newTuple: New version to store
 //
 // Returns an error if:
 //   - oldTuple is nil or has no RecordID
-//   - Delete operation fails
-//   - Insert operation fails (attempts rollback by reinserting oldTuple)
+//   - Page access, WAL logging, or the page update fails
 //
-// Thread-safe: Uses page locks via Delete and Insert operations.
+// Thread-safe: Uses page locks via UpdateOp.
 func (tm *TupleManager) UpdateTuple(ctx *transaction.TransactionContext, dbFile page.DbFile, oldTuple *tuple.Tuple, newTuple *tuple.Tuple) error {
 	if oldTuple == nil {
 		return fmt.Errorf("old tuple cannot be nil")
@@ -141,13 +143,8 @@ func (tm *TupleManager) UpdateTuple(ctx *transaction.TransactionContext, dbFile
 		return fmt.Errorf("old tuple has no RecordID")
 	}
 
-	if err := tm.DeleteTuple(ctx, dbFile, oldTuple); err != nil {
-		return fmt.Errorf("failed to delete old tuple: %v", err)
-	}
-
-	if err := tm.InsertTuple(ctx, dbFile, newTuple); err != nil {
-		tm.InsertTuple(ctx, dbFile, oldTuple)
-		return fmt.Errorf("failed to insert updated tuple: %v", err)
+	if err := tm.NewUpdateOp(ctx, dbFile.(*heap.HeapFile), []*tuple.Tuple{oldTuple}, []*tuple.Tuple{newTuple}).Execute(); err != nil {
+		return fmt.Errorf("failed to update tuple: %v", err)
 	}
 
 	return nil
@@ -155,49 +152,65 @@ func (tm *TupleManager) UpdateTuple(ctx *transaction.TransactionContext, dbFile
 
 // logOperation writes an operation record to the Write-Ahead Log.
 // This enforces the WAL protocol: log records must be written before page modifications.
+// The LogManager derives the page and the tuple image from t itself.
 //
 // Record types:
-//   - INSERT: Records new tuple data with page ID
-//   - DELETE: Records before-image with page ID for UNDO
+//   - INSERT: Records the new tuple as after-image (t must have its RecordID)
+//   - DELETE: Records the tuple as before-image for UNDO
 //
 // Parameters:
 //   - operation: Type of operation to log
 //   - tid: Transaction ID performing the operation
-//   - pageID: Page affected
-//   - data: Page or tuple data
+//   - t: Tuple inserted or deleted
 //
-// Returns an error if WAL write fails. This is a critical error that should
-// cause the operation to be aborted.
-func (tm *TupleManager) logOperation(operation memory.OperationType, tid *primitives.TransactionID, pageID primitives.PageID, data []byte) error {
+// Returns the LSN of the record, which becomes the pageLSN of every page the
+// operation changes, or an error if WAL write fails. This is a critical
+// error that should cause the operation to be aborted.
+func (tm *TupleManager) logOperation(operation memory.OperationType, tid *primitives.TransactionID, t *tuple.Tuple) (primitives.LSN, error) {
+	var lsn primitives.LSN
 	var err error
 	switch operation {
 	case memory.InsertOperation:
-		_, err = tm.wal.LogInsert(tid, pageID, data)
+		lsn, err = tm.logs.LogInsert(tid, t)
 	case memory.DeleteOperation:
-		_, err = tm.wal.LogDelete(tid, pageID, data)
+		lsn, err = tm.logs.LogDelete(tid, t)
 	default:
-		return fmt.Errorf("unknown operation: %s", operation.String())
+		return 0, fmt.Errorf("unknown operation: %s", operation.String())
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to log %s to WAL: %v", operation, err)
+		return 0, fmt.Errorf("failed to log %s to WAL: %v", operation, err)
 	}
-	return nil
+	return lsn, nil
+}
+
+// logUpdate writes an UPDATE record with the old and new versions of a row
+// as before- and after-images. Returns the LSN of the record, or an error if
+// the WAL write fails.
+func (tm *TupleManager) logUpdate(tid *primitives.TransactionID, oldTuple, newTuple *tuple.Tuple) (primitives.LSN, error) {
+	lsn, err := tm.logs.LogUpdate(tid, oldTuple, newTuple)
+	if err != nil {
+		return 0, fmt.Errorf("failed to log %s to WAL: %v", memory.UpdateOperation, err)
+	}
+	return lsn, nil
 }
 
 // markPagesAsDirty updates the dirty status for all pages modified by an operation.
 // This helper:
 //  1. Marks each page as dirty with transaction ID
 //  2. Records page in transaction's write set
+//  3. Sets the page's pageLSN, so the page is not written before its log record
 //
 // This ensures proper tracking for commit/abort processing and lock management.
 //
 // Parameters:
 //   - ctx: Transaction context that modified the pages
+//   - lsn: LSN of the log record describing the modification
 //   - pages: All pages that were modified
-func (tm *TupleManager) markPagesAsDirty(ctx *transaction.TransactionContext, pages []*heap.HeapPage) {
+func (tm *TupleManager) markPagesAsDirty(ctx *transaction.TransactionContext, lsn primitives.LSN, pages []*heap.HeapPage) {
 	for _, pg := range pages {
 		pg.MarkDirty(true, ctx.ID)
 		ctx.MarkPageDirty(pg.GetID())
+		tm.pageProvider.SetPageLSN(pg.GetID(), lsn)
 	}
 }
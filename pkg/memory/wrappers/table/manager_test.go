@@ -506,6 +506,112 @@ func TestUpdateTuple_MultipleUpdates(t *testing.T) {
 	}
 }
 
+// TestUpdateTuple_KeepsRecordID tests that an update reuses the old tuple's slot
+func TestUpdateTuple_KeepsRecordID(t *testing.T) {
+	tm, heapFile, ps, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := createTransactionContext(t)
+	td := heapFile.GetTupleDesc()
+
+	oldTuple := createTestTuple(td, 1, "old")
+	other := createTestTuple(td, 2, "other")
+	if err := tm.NewInsertOp(ctx, heapFile, []*tuple.Tuple{oldTuple, other}).Execute(); err != nil {
+		t.Fatalf("InsertOp failed: %v", err)
+	}
+	rid := oldTuple.RecordID
+
+	newTuple := createTestTuple(td, 1, "new")
+	if err := tm.UpdateTuple(ctx, heapFile, oldTuple, newTuple); err != nil {
+		t.Fatalf("UpdateTuple failed: %v", err)
+	}
+
+	if !newTuple.RecordID.Equals(rid) {
+		t.Errorf("New tuple RecordID = %v, want %v", newTuple.RecordID, rid)
+	}
+	if !oldTuple.RecordID.Equals(rid) {
+		t.Errorf("Old tuple RecordID changed to %v", oldTuple.RecordID)
+	}
+
+	pg, err := ps.GetPage(ctx, heapFile, rid.PageID.(*page.PageDescriptor), transaction.ReadOnly)
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	hp := pg.(*heap.HeapPage)
+	if got, _ := hp.GetTupleAt(rid.TupleNum); got != newTuple {
+		t.Errorf("Slot %d holds %v, want the new tuple", rid.TupleNum, got)
+	}
+	if n := len(hp.GetTuples()); n != 2 {
+		t.Errorf("Expected 2 tuples on the page, got %d", n)
+	}
+
+	if err := ps.CommitTransaction(ctx); err != nil {
+		t.Errorf("Failed to commit transaction: %v", err)
+	}
+}
+
+// TestUpdateTuple_FollowsForwardPointer tests updating and deleting a tuple
+// that was moved off its home page
+func TestUpdateTuple_FollowsForwardPointer(t *testing.T) {
+	tm, heapFile, ps, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := createTransactionContext(t)
+	td := heapFile.GetTupleDesc()
+
+	oldTuple := createTestTuple(td, 1, "old")
+	if err := tm.InsertTuple(ctx, heapFile, oldTuple); err != nil {
+		t.Fatalf("InsertTuple failed: %v", err)
+	}
+	home := oldTuple.RecordID
+
+	// Move the tuple as if it had outgrown its page
+	op := tm.NewUpdateOp(ctx, heapFile, nil, nil)
+	homePage, err := op.getHeapPage(home.PageID)
+	if err != nil {
+		t.Fatalf("getHeapPage failed: %v", err)
+	}
+	moved := createTestTuple(td, 1, "moved")
+	if err := op.relocate(homePage, home, moved); err != nil {
+		t.Fatalf("relocate failed: %v", err)
+	}
+
+	target, ok := homePage.Forward(home.TupleNum)
+	if !ok || target.PageID.Equals(home.PageID) {
+		t.Fatalf("Expected a forward pointer to another page, got %v, %v", target, ok)
+	}
+
+	newTuple := createTestTuple(td, 1, "new")
+	if err := tm.UpdateTuple(ctx, heapFile, moved, newTuple); err != nil {
+		t.Fatalf("UpdateTuple failed: %v", err)
+	}
+	if !newTuple.RecordID.Equals(home) {
+		t.Errorf("New tuple RecordID = %v, want home %v", newTuple.RecordID, home)
+	}
+
+	targetPage, err := op.getHeapPage(target.PageID)
+	if err != nil {
+		t.Fatalf("getHeapPage failed: %v", err)
+	}
+	if got, _ := targetPage.GetTupleAt(target.TupleNum); got != newTuple {
+		t.Errorf("Moved slot holds %v, want the new tuple", got)
+	}
+
+	if err := tm.DeleteTuple(ctx, heapFile, newTuple); err != nil {
+		t.Fatalf("DeleteTuple failed: %v", err)
+	}
+	if _, ok := homePage.Forward(home.TupleNum); ok {
+		t.Error("Expected the forward pointer to be deleted")
+	}
+	if n := len(targetPage.GetTuples()); n != 0 {
+		t.Errorf("Expected the moved copy to be deleted, %d tuples left", n)
+	}
+
+	if err := ps.CommitTransaction(ctx); err != nil {
+		t.Errorf("Failed to commit transaction: %v", err)
+	}
+}
+
 // TestTransactionRollback_Insert tests rolling back an insert operation
 func TestTransactionRollback_Insert(t *testing.T) {
 	tm, heapFile, ps, cleanup := setupTestEnvironment(t)
@@ -0,0 +1,67 @@
+package table
+
+import (
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/storage/heap"
+)
+
+// Quota limits how much the tables may grow, such as an
+// accounting.QuotaTracker. The TupleManager reserves rows before inserting
+// them and a page before adding one to a heap file, and releases the rows it
+// deletes. What a transaction reserved or released only counts for other
+// transactions once it commits.
+type Quota interface {
+	// ReserveRows reserves n rows in f for ctx, or fails if f has no room
+	// for them.
+	ReserveRows(ctx *transaction.TransactionContext, f *heap.HeapFile, n int64) error
+
+	// ReleaseRows releases n rows of f, reserved or deleted by ctx.
+	ReleaseRows(ctx *transaction.TransactionContext, f *heap.HeapFile, n int64)
+
+	// ReservePage fails if adding a page to f would exceed the quota.
+	ReservePage(ctx *transaction.TransactionContext, f *heap.HeapFile) error
+
+	// RollbackStatement gives back what the statement running in ctx
+	// reserved and released, before its savepoint is released.
+	RollbackStatement(ctx *transaction.TransactionContext)
+}
+
+// SetQuota sets the quota inserts are checked against. Nil, the default,
+// lets the tables grow without limit.
+func (tm *TupleManager) SetQuota(q Quota) {
+	tm.quota = q
+}
+
+// reserveRows reserves n rows of f for ctx if a quota is set.
+func (tm *TupleManager) reserveRows(ctx *transaction.TransactionContext, f *heap.HeapFile, n int) error {
+	if tm.quota == nil || n == 0 {
+		return nil
+	}
+	return tm.quota.ReserveRows(ctx, f, int64(n))
+}
+
+// releaseRows releases n rows of f for ctx if a quota is set.
+func (tm *TupleManager) releaseRows(ctx *transaction.TransactionContext, f *heap.HeapFile, n int) {
+	if tm.quota == nil || n == 0 {
+		return
+	}
+	tm.quota.ReleaseRows(ctx, f, int64(n))
+}
+
+// reservePage checks a new page of f against the quota if one is set.
+func (tm *TupleManager) reservePage(ctx *transaction.TransactionContext, f *heap.HeapFile) error {
+	if tm.quota == nil {
+		return nil
+	}
+	return tm.quota.ReservePage(ctx, f)
+}
+
+// RollbackStatement gives back the rows the statement running in ctx
+// reserved and released if a quota is set. It is called when the statement
+// is rolled back, before its savepoint is released.
+func (tm *TupleManager) RollbackStatement(ctx *transaction.TransactionContext) {
+	if tm.quota == nil {
+		return
+	}
+	tm.quota.RollbackStatement(ctx)
+}
@@ -1,17 +1,21 @@
 package table
 
 import (
+	"errors"
 	"fmt"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/primitives"
 	"storemy/pkg/storage/heap"
+	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
 )
 
 // UpdateOp handles batch updates of tuples within a single transaction.
-// Updates are implemented as delete+insert pairs.
+// Each new version takes the slot of the version it replaces, so it keeps
+// the same RecordID and index entries stay valid.
 // This operation allows updating multiple tuples efficiently by:
-//   - Batching deletions and insertions
-//   - Reusing pages for new tuple versions
+//   - Updating tuples in place, compacting pages when needed
+//   - Moving tuples that outgrow their page, behind a forward pointer
 //   - Updating indexes once after all updates
 type UpdateOp struct {
 	tm        *TupleManager
@@ -22,7 +26,8 @@ type UpdateOp struct {
 
 	// Track execution state
 	executed     bool
-	updatedCount int // How many updates completed (for partial rollback tracking)
+	updatedCount int            // How many updates completed (for partial rollback tracking)
+	lsn          primitives.LSN // LSN of the update being applied, the pageLSN of every page it changes
 }
 
 // NewUpdateOp creates a new batch update operation for the given transaction.
@@ -54,6 +59,7 @@ func (tm *TupleManager) NewUpdateOp(ctx *transaction.TransactionContext, dbFile
 //   - At least one tuple pair to update
 //   - oldTuples and newTuples have matching lengths
 //   - All old tuples have valid RecordIDs
+//   - All new tuples match the table schema
 func (op *UpdateOp) Validate() error {
 	if err := validateExecuted(op.executed, "update"); err != nil {
 		return err
@@ -88,6 +94,10 @@ func (op *UpdateOp) Validate() error {
 		return err
 	}
 
+	if err := validateSchemaMatch(op.newTuples, op.dbFile); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -95,12 +105,12 @@ func (op *UpdateOp) Validate() error {
 // This operation:
 //  1. Validates the operation
 //  2. Ensures transaction has logged BEGIN record
-//  3. Creates and executes a DeleteOp for all old tuples
-//  4. Creates and executes an InsertOp for all new tuples
-//  5. Records modification for statistics
+//  3. Stores each new tuple in the slot of its old version (fail-fast on first error)
+//  4. Updates all indexes once after all updates
 //
-// Updates are implemented as delete+insert operations using DeleteOp and InsertOp.
-// If deletion succeeds but insertion fails, the old tuples remain deleted (transaction rollback will restore them).
+// Each update is logged as one UPDATE record with the old and new versions of
+// the row, however many pages storing it touches. On failure, completed
+// updates remain (transaction rollback will undo them).
 // The operation becomes marked as executed regardless of success/failure.
 func (op *UpdateOp) Execute() error {
 	if err := op.Validate(); err != nil {
@@ -113,16 +123,179 @@ func (op *UpdateOp) Execute() error {
 		return err
 	}
 
-	deleteOp := op.tm.NewDeleteOp(op.ctx, op.dbFile, op.oldTuples)
-	if err := deleteOp.Execute(); err != nil {
-		return fmt.Errorf("failed to delete old tuples: %v", err)
+	for i := range op.oldTuples {
+		if err := op.handleUpdate(op.oldTuples[i], op.newTuples[i]); err != nil {
+			return fmt.Errorf("failed to update tuple at index %d: %v", i, err)
+		}
+		op.updatedCount++
 	}
 
-	insertOp := op.tm.NewInsertOp(op.ctx, op.dbFile, op.newTuples)
-	if err := insertOp.Execute(); err != nil {
-		return fmt.Errorf("failed to insert new tuples: %v", err)
+	if op.tm.indexManager != nil {
+		tableID := op.dbFile.GetID()
+		for i := range op.oldTuples {
+			if err := op.tm.indexManager.OnUpdate(op.ctx, tableID, op.oldTuples[i], op.newTuples[i]); err != nil {
+				return fmt.Errorf("failed to update indexes on update at index %d: %v", i, err)
+			}
+		}
 	}
 
-	op.updatedCount = len(op.oldTuples)
 	return nil
 }
+
+// handleUpdate stores newTuple in the home slot of oldTuple, the slot its
+// RecordID names, leaving oldTuple untouched for index maintenance:
+//   - If the home slot holds the tuple, it is updated in place
+//   - If the home slot holds a forward pointer, the moved copy is updated
+//   - A tuple that no longer fits on its page is moved, and the home slot
+//     points to the new location
+func (op *UpdateOp) handleUpdate(oldTuple, newTuple *tuple.Tuple) error {
+	lsn, err := op.tm.logUpdate(op.ctx.ID, oldTuple, newTuple)
+	if err != nil {
+		return err
+	}
+	op.lsn = lsn
+
+	home := oldTuple.RecordID
+	homePage, err := op.getHeapPage(home.PageID)
+	if err != nil {
+		return err
+	}
+
+	if target, ok := homePage.Forward(home.TupleNum); ok {
+		return op.updateMoved(homePage, home, target, newTuple)
+	}
+
+	err = op.modify(homePage, func() error { return homePage.UpdateTuple(home.TupleNum, newTuple) })
+	if !errors.Is(err, heap.ErrPageFull) {
+		return err
+	}
+	return op.relocate(homePage, home, newTuple)
+}
+
+// updateMoved updates a tuple that was moved from its home slot to target.
+// If the new version outgrows the target page, it goes back home when it
+// fits there, and is moved again otherwise.
+func (op *UpdateOp) updateMoved(homePage *heap.HeapPage, home, target *tuple.TupleRecordID, newTuple *tuple.Tuple) error {
+	targetPage, err := op.getHeapPage(target.PageID)
+	if err != nil {
+		return err
+	}
+
+	err = op.modify(targetPage, func() error { return targetPage.UpdateTuple(target.TupleNum, newTuple) })
+	if !errors.Is(err, heap.ErrPageFull) {
+		return err
+	}
+
+	moved, err := targetPage.GetTupleAt(target.TupleNum)
+	if err != nil || moved == nil {
+		return fmt.Errorf("moved tuple missing at %v", target)
+	}
+	deleteMoved := func() error {
+		return op.modify(targetPage, func() error { return targetPage.DeleteTuple(moved) })
+	}
+
+	err = op.modify(homePage, func() error { return homePage.UpdateTuple(home.TupleNum, newTuple) })
+	if err == nil {
+		return deleteMoved()
+	}
+	if !errors.Is(err, heap.ErrPageFull) {
+		return err
+	}
+
+	if err := deleteMoved(); err != nil {
+		return err
+	}
+	return op.relocate(homePage, home, newTuple)
+}
+
+// relocate moves newTuple, which does not fit in its home slot, to another
+// page and turns the home slot into a forward pointer to it.
+func (op *UpdateOp) relocate(homePage *heap.HeapPage, home *tuple.TupleRecordID, newTuple *tuple.Tuple) error {
+	target, err := op.placeMoved(newTuple, home)
+	if err != nil {
+		return err
+	}
+	return op.modify(homePage, func() error { return homePage.SetRedirect(home.TupleNum, target) })
+}
+
+// placeMoved stores newTuple on the first page other than its home page
+// with room for it, allocating a new page if none has room, and returns
+// where it was stored.
+func (op *UpdateOp) placeMoved(newTuple *tuple.Tuple, home *tuple.TupleRecordID) (*tuple.TupleRecordID, error) {
+	numPages, err := op.dbFile.NumPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get number of pages: %v", err)
+	}
+
+	for i := range numPages {
+		if i == home.PageID.PageNo() {
+			continue
+		}
+
+		heapPage, err := op.getHeapPage(page.NewPageDescriptor(op.dbFile.GetID(), i))
+		if err != nil || heapPage.GetNumEmptySlots() == 0 {
+			continue
+		}
+
+		var target *tuple.TupleRecordID
+		err = op.modify(heapPage, func() (err error) {
+			target, err = heapPage.AddMovedTuple(newTuple, home)
+			return err
+		})
+		if err == nil {
+			return target, nil
+		}
+		if !errors.Is(err, heap.ErrPageFull) {
+			return nil, err
+		}
+	}
+
+	for {
+		p, err := op.tm.newPage(op.ctx, op.dbFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var target *tuple.TupleRecordID
+		err = op.modify(p, func() (err error) {
+			target, err = p.AddMovedTuple(newTuple, home)
+			return err
+		})
+		if err == nil {
+			return target, nil
+		}
+		if !errors.Is(err, heap.ErrPageFull) && p.GetNumEmptySlots() > 0 {
+			return nil, fmt.Errorf("failed to add tuple to new page: %v", err)
+		}
+	}
+}
+
+// modify applies change to a page and marks the page dirty. A change that
+// fails leaves the page as it was.
+func (op *UpdateOp) modify(heapPage *heap.HeapPage, change func() error) error {
+	if err := change(); err != nil {
+		return err
+	}
+
+	op.tm.markPagesAsDirty(op.ctx, op.lsn, []*heap.HeapPage{heapPage})
+	return nil
+}
+
+// getHeapPage fetches a page of the table with an exclusive lock.
+func (op *UpdateOp) getHeapPage(pageID primitives.PageID) (*heap.HeapPage, error) {
+	hpid, ok := pageID.(*page.PageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("wrong page id format")
+	}
+
+	pg, err := op.tm.pageProvider.GetPage(op.ctx, op.dbFile, hpid, transaction.ReadWrite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page for update: %v", err)
+	}
+
+	heapPage, ok := pg.(*heap.HeapPage)
+	if !ok {
+		return nil, fmt.Errorf("expecting the pageType to be of heapage")
+	}
+	return heapPage, nil
+}
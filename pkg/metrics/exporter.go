@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WriteText renders every metric in r using the Prometheus text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for _, m := range r.All() {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.Name(), escapeHelp(m.Help()))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", m.Name(), m.Type())
+
+		switch metric := m.(type) {
+		case *Counter:
+			fmt.Fprintf(bw, "%s %d\n", metric.Name(), metric.Value())
+		case *Gauge:
+			fmt.Fprintf(bw, "%s %d\n", metric.Name(), metric.Value())
+		case *Histogram:
+			bounds, cumulative := metric.Snapshot()
+			for i, bound := range bounds {
+				fmt.Fprintf(bw, "%s_bucket{le=\"%s\"} %d\n", metric.Name(), formatBound(bound), cumulative[i])
+			}
+			fmt.Fprintf(bw, "%s_sum %s\n", metric.Name(), strconv.FormatFloat(metric.Sum(), 'g', -1, 64))
+			fmt.Fprintf(bw, "%s_count %d\n", metric.Name(), metric.Count())
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler that serves the DefaultRegistry.
+func Handler() http.Handler {
+	return DefaultRegistry.Handler()
+}
+
+// Handler returns an http.Handler that serves r in the Prometheus text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// ListenAndServe starts an HTTP server on addr exposing the DefaultRegistry at /metrics.
+// It blocks like http.ListenAndServe and is normally run in its own goroutine.
+func ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	return http.ListenAndServe(addr, mux)
+}
+
+func formatBound(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
@@ -0,0 +1,275 @@
+// Package metrics provides lightweight counters, gauges and histograms that
+// subsystems update on their hot paths, and an exporter that renders them in
+// the Prometheus text exposition format.
+//
+// Metrics are registered once, usually as package-level variables:
+//
+//	var pagesRead = metrics.NewCounter("storemy_pages_read_total", "Pages read from disk")
+//
+// All metric operations are lock-free and safe for concurrent use.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// MetricType identifies the kind of metric for exposition.
+type MetricType int
+
+const (
+	CounterType MetricType = iota
+	GaugeType
+	HistogramType
+)
+
+func (t MetricType) String() string {
+	switch t {
+	case CounterType:
+		return "counter"
+	case GaugeType:
+		return "gauge"
+	case HistogramType:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// Metric is implemented by every metric that can be held in a Registry.
+type Metric interface {
+	Name() string
+	Help() string
+	Type() MetricType
+}
+
+// desc holds the identifying fields shared by all metric kinds.
+type desc struct {
+	name string
+	help string
+}
+
+func (d desc) Name() string { return d.name }
+func (d desc) Help() string { return d.help }
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	desc
+	value atomic.Int64
+}
+
+func (c *Counter) Type() MetricType { return CounterType }
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add increments the counter by n. Negative values are ignored since a
+// counter may never decrease.
+func (c *Counter) Add(n int64) {
+	if n > 0 {
+		c.value.Add(n)
+	}
+}
+
+// Value returns the current count.
+func (c *Counter) Value() int64 {
+	return c.value.Load()
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	desc
+	value atomic.Int64
+}
+
+func (g *Gauge) Type() MetricType { return GaugeType }
+
+// Set replaces the gauge value.
+func (g *Gauge) Set(v int64) {
+	g.value.Store(v)
+}
+
+// Inc increments the gauge by one.
+func (g *Gauge) Inc() {
+	g.value.Add(1)
+}
+
+// Dec decrements the gauge by one.
+func (g *Gauge) Dec() {
+	g.value.Add(-1)
+}
+
+// Add adds n (which may be negative) to the gauge.
+func (g *Gauge) Add(n int64) {
+	g.value.Add(n)
+}
+
+// Value returns the current gauge value.
+func (g *Gauge) Value() int64 {
+	return g.value.Load()
+}
+
+// DefaultLatencyBuckets are histogram upper bounds in seconds suited to query
+// and I/O latencies, from half a millisecond up to ten seconds.
+var DefaultLatencyBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram counts observations into cumulative buckets and tracks their sum.
+type Histogram struct {
+	desc
+	bounds  []float64
+	buckets []atomic.Int64 // one per bound, plus a final +Inf bucket
+	count   atomic.Int64
+	sumBits atomic.Uint64 // float64 sum stored as bits
+}
+
+func (h *Histogram) Type() MetricType { return HistogramType }
+
+// Observe records a single value.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.buckets[i].Add(1)
+	h.count.Add(1)
+
+	for {
+		old := h.sumBits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + v)
+		if h.sumBits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() int64 {
+	return h.count.Load()
+}
+
+// Sum returns the sum of all observed values.
+func (h *Histogram) Sum() float64 {
+	return math.Float64frombits(h.sumBits.Load())
+}
+
+// Snapshot returns the bucket upper bounds and their cumulative counts.
+// The final entry has an upper bound of +Inf and equals Count().
+func (h *Histogram) Snapshot() (bounds []float64, cumulative []int64) {
+	bounds = append(append([]float64(nil), h.bounds...), math.Inf(1))
+	cumulative = make([]int64, len(h.buckets))
+
+	var total int64
+	for i := range h.buckets {
+		total += h.buckets[i].Load()
+		cumulative[i] = total
+	}
+	return bounds, cumulative
+}
+
+// Registry holds a set of uniquely named metrics.
+type Registry struct {
+	metrics map[string]Metric
+	mutex   sync.RWMutex
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]Metric),
+	}
+}
+
+// DefaultRegistry is the process-wide registry used by NewCounter, NewGauge
+// and NewHistogram, and served by Handler.
+var DefaultRegistry = NewRegistry()
+
+// Register adds m to the registry. It returns an error if a metric with the
+// same name is already registered.
+func (r *Registry) Register(m Metric) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.metrics[m.Name()]; exists {
+		return fmt.Errorf("metric %s already registered", m.Name())
+	}
+	r.metrics[m.Name()] = m
+	return nil
+}
+
+// MustRegister is like Register but panics on a duplicate name. It is meant
+// for package-level metric definitions, where a duplicate is a programming error.
+func (r *Registry) MustRegister(m Metric) {
+	if err := r.Register(m); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns the metric registered under name, if any.
+func (r *Registry) Get(name string) (Metric, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	m, ok := r.metrics[name]
+	return m, ok
+}
+
+// All returns every registered metric sorted by name.
+func (r *Registry) All() []Metric {
+	r.mutex.RLock()
+	all := make([]Metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		all = append(all, m)
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// NewCounter creates a counter and registers it in r.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help}}
+	r.MustRegister(c)
+	return c
+}
+
+// NewGauge creates a gauge and registers it in r.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{desc: desc{name: name, help: help}}
+	r.MustRegister(g)
+	return g
+}
+
+// NewHistogram creates a histogram with the given bucket upper bounds and
+// registers it in r. Bounds must be sorted in increasing order; a +Inf bucket
+// is always added implicitly.
+func (r *Registry) NewHistogram(name, help string, bounds []float64) *Histogram {
+	if !sort.Float64sAreSorted(bounds) {
+		panic(fmt.Sprintf("histogram %s: bucket bounds must be sorted", name))
+	}
+	h := &Histogram{
+		desc:    desc{name: name, help: help},
+		bounds:  append([]float64(nil), bounds...),
+		buckets: make([]atomic.Int64, len(bounds)+1),
+	}
+	r.MustRegister(h)
+	return h
+}
+
+// NewCounter creates a counter in the DefaultRegistry.
+func NewCounter(name, help string) *Counter {
+	return DefaultRegistry.NewCounter(name, help)
+}
+
+// NewGauge creates a gauge in the DefaultRegistry.
+func NewGauge(name, help string) *Gauge {
+	return DefaultRegistry.NewGauge(name, help)
+}
+
+// NewHistogram creates a histogram in the DefaultRegistry.
+func NewHistogram(name, help string, bounds []float64) *Histogram {
+	return DefaultRegistry.NewHistogram(name, help, bounds)
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("test_total", "A test counter")
+
+	c.Inc()
+	c.Add(4)
+	c.Add(-10) // ignored
+
+	if got := c.Value(); got != 5 {
+		t.Errorf("expected 5, got %d", got)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("test_gauge", "A test gauge")
+
+	g.Set(10)
+	g.Inc()
+	g.Dec()
+	g.Add(-3)
+
+	if got := g.Value(); got != 7 {
+		t.Errorf("expected 7, got %d", got)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("test_seconds", "A test histogram", []float64{0.1, 1})
+
+	for _, v := range []float64{0.05, 0.1, 0.5, 2, 3} {
+		h.Observe(v)
+	}
+
+	if got := h.Count(); got != 5 {
+		t.Errorf("expected count 5, got %d", got)
+	}
+	if got := h.Sum(); got != 5.65 {
+		t.Errorf("expected sum 5.65, got %v", got)
+	}
+
+	_, cumulative := h.Snapshot()
+	want := []int64{2, 3, 5}
+	for i := range want {
+		if cumulative[i] != want[i] {
+			t.Errorf("bucket %d: expected %d, got %d", i, want[i], cumulative[i])
+		}
+	}
+}
+
+func TestHistogram_ConcurrentObserve(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("test_seconds", "A test histogram", DefaultLatencyBuckets)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				h.Observe(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if h.Count() != 8000 || h.Sum() != 8000 {
+		t.Errorf("expected count and sum 8000, got %d and %v", h.Count(), h.Sum())
+	}
+}
+
+func TestRegistry_DuplicateName(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup_total", "first")
+
+	if err := r.Register(&Counter{desc: desc{name: "dup_total"}}); err == nil {
+		t.Error("expected error registering duplicate metric name")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected NewGauge with duplicate name to panic")
+		}
+	}()
+	r.NewGauge("dup_total", "second")
+}
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("b_total", "Counter help").Add(3)
+	r.NewGauge("a_gauge", "Gauge help").Set(-2)
+	r.NewHistogram("c_seconds", "Histogram help", []float64{0.5}).Observe(0.25)
+
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	want := `# HELP a_gauge Gauge help
+# TYPE a_gauge gauge
+a_gauge -2
+# HELP b_total Counter help
+# TYPE b_total counter
+b_total 3
+# HELP c_seconds Histogram help
+# TYPE c_seconds histogram
+c_seconds_bucket{le="0.5"} 1
+c_seconds_bucket{le="+Inf"} 1
+c_seconds_sum 0.25
+c_seconds_count 1
+`
+	if sb.String() != want {
+		t.Errorf("unexpected exposition output:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("requests_total", "Requests").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rec.Code != 200 {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "requests_total 1") {
+		t.Errorf("expected counter in body, got:\n%s", rec.Body.String())
+	}
+}
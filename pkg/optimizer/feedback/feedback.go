@@ -0,0 +1,194 @@
+// Package feedback remembers how far the optimizer's row count estimates were
+// from the rows queries actually produced. EXPLAIN ANALYZE records, for the
+// predicated scan it executed, the estimate next to the real row count; the
+// cardinality estimator then scales its estimate for the same table and
+// predicates by the observed correction. Recurring predicates over skewed data,
+// where histograms and default selectivities are furthest off, thereby get
+// estimates that converge on what the data really holds.
+package feedback
+
+import (
+	"fmt"
+	"sort"
+	"storemy/pkg/plan"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is the number of distinct scans remembered by default.
+const DefaultMaxEntries = 1000
+
+// smoothing is the weight of a new observation in the correction factor. The
+// rest is the previous factor, so a single unusual run does not overturn what
+// earlier runs observed.
+const smoothing = 0.5
+
+// Entry holds the feedback observed for one table and predicate combination.
+type Entry struct {
+	Table      string
+	Predicates string  // Normalized predicates, as returned by Signature
+	Estimated  int64   // Estimate of the latest run, before correction
+	Actual     int64   // Rows the latest run produced
+	Correction float64 // Smoothed ratio of actual to estimated rows
+	Samples    int64   // Runs recorded
+	LastSeen   time.Time
+}
+
+// Store collects cardinality feedback. It is safe for concurrent use; its
+// methods are no-ops on a nil store.
+type Store struct {
+	entries    map[string]*Entry
+	maxEntries int
+	mutex      sync.Mutex
+}
+
+// NewStore creates a store remembering at most maxEntries scans. When full,
+// the least recently observed scan is evicted to make room for a new one. A
+// non-positive maxEntries uses DefaultMaxEntries.
+func NewStore(maxEntries int) *Store {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Store{
+		entries:    make(map[string]*Entry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Record adds a run of a scan over table filtered by predicates, which the
+// optimizer estimated to return estimated rows and which returned actual rows.
+// The estimate must be the uncorrected one, so that corrections do not
+// compound over repeated runs.
+func (s *Store) Record(table string, predicates []plan.PredicateInfo, estimated, actual int64) {
+	if s == nil || estimated < 0 || actual < 0 {
+		return
+	}
+
+	signature := Signature(predicates)
+	key := entryKey(table, signature)
+	ratio := float64(actual) / float64(max(estimated, 1))
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		if len(s.entries) >= s.maxEntries {
+			s.evictLocked()
+		}
+		e = &Entry{Table: strings.ToUpper(table), Predicates: signature, Correction: ratio}
+		s.entries[key] = e
+	} else {
+		e.Correction = (1-smoothing)*e.Correction + smoothing*ratio
+	}
+
+	e.Estimated = estimated
+	e.Actual = actual
+	e.Samples++
+	e.LastSeen = now
+	feedbackRecorded.Inc()
+}
+
+// Correction returns the factor by which estimates for a scan over table
+// filtered by predicates should be multiplied, and false if no run of the
+// scan has been recorded.
+func (s *Store) Correction(table string, predicates []plan.PredicateInfo) (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+
+	key := entryKey(table, Signature(predicates))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return 0, false
+	}
+	feedbackApplied.Inc()
+	return e.Correction, true
+}
+
+// evictLocked removes the entry observed least recently. The caller must hold
+// s.mutex.
+func (s *Store) evictLocked() {
+	var victimKey string
+	var victim *Entry
+	for key, e := range s.entries {
+		if victim == nil || e.LastSeen.Before(victim.LastSeen) {
+			victimKey, victim = key, e
+		}
+	}
+	if victim != nil {
+		delete(s.entries, victimKey)
+	}
+}
+
+// Snapshot returns a copy of all entries ordered by table and predicates.
+func (s *Store) Snapshot() []Entry {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, *e)
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Table != entries[j].Table {
+			return entries[i].Table < entries[j].Table
+		}
+		return entries[i].Predicates < entries[j].Predicates
+	})
+	return entries
+}
+
+// Reset discards all recorded feedback.
+func (s *Store) Reset() {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = make(map[string]*Entry)
+}
+
+// Signature returns the normalized text of a conjunction of predicates, e.g.
+// "AGE > 30 AND NAME IS NOT NULL". Predicates are sorted, so the same
+// conjunction written in another order has the same signature.
+func Signature(predicates []plan.PredicateInfo) string {
+	parts := make([]string, 0, len(predicates))
+	for _, p := range predicates {
+		parts = append(parts, predicateText(p))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " AND ")
+}
+
+// predicateText renders a single predicate for Signature.
+func predicateText(p plan.PredicateInfo) string {
+	column := strings.ToUpper(p.Column)
+	switch p.Type {
+	case plan.NullCheckPredicate:
+		if p.IsNull {
+			return column + " IS NULL"
+		}
+		return column + " IS NOT NULL"
+	case plan.InPredicate:
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(p.Values, ", "))
+	default:
+		return fmt.Sprintf("%s %s %s", column, p.Predicate, p.Value)
+	}
+}
+
+// entryKey identifies the entry of a scan over table with the given predicate
+// signature. Table names are case-insensitive.
+func entryKey(table, signature string) string {
+	return strings.ToUpper(table) + "\x00" + signature
+}
@@ -0,0 +1,75 @@
+package feedback
+
+import (
+	"math"
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"testing"
+)
+
+func statusPredicate(value string) []plan.PredicateInfo {
+	return []plan.PredicateInfo{{Column: "status", Predicate: primitives.Equals, Value: value}}
+}
+
+func TestStore_RecordAndCorrection(t *testing.T) {
+	store := NewStore(0)
+
+	if _, ok := store.Correction("orders", statusPredicate("open")); ok {
+		t.Fatal("expected no correction before any run was recorded")
+	}
+
+	store.Record("orders", statusPredicate("open"), 100, 400)
+	correction, ok := store.Correction("ORDERS", statusPredicate("open"))
+	if !ok || correction != 4 {
+		t.Fatalf("expected correction 4, got %v (found %v)", correction, ok)
+	}
+
+	// A later run moves the factor halfway towards its own ratio
+	store.Record("orders", statusPredicate("open"), 100, 200)
+	if correction, _ := store.Correction("orders", statusPredicate("open")); math.Abs(correction-3) > 1e-9 {
+		t.Errorf("expected smoothed correction 3, got %v", correction)
+	}
+
+	if _, ok := store.Correction("orders", statusPredicate("closed")); ok {
+		t.Error("feedback for one literal must not apply to another")
+	}
+}
+
+func TestStore_EvictsLeastRecentlySeen(t *testing.T) {
+	store := NewStore(2)
+	store.Record("a", statusPredicate("x"), 10, 10)
+	store.Record("b", statusPredicate("x"), 10, 10)
+	store.Record("c", statusPredicate("x"), 10, 10)
+
+	entries := store.Snapshot()
+	if len(entries) != 2 || entries[0].Table != "B" || entries[1].Table != "C" {
+		t.Errorf("expected the entry for A to be evicted, got %+v", entries)
+	}
+}
+
+func TestSignature_IgnoresPredicateOrder(t *testing.T) {
+	ageAndName := []plan.PredicateInfo{
+		{Column: "age", Predicate: primitives.GreaterThan, Value: "30"},
+		{Column: "name", Type: plan.NullCheckPredicate},
+	}
+	nameAndAge := []plan.PredicateInfo{ageAndName[1], ageAndName[0]}
+
+	want := "AGE > 30 AND NAME IS NOT NULL"
+	if got := Signature(ageAndName); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if Signature(nameAndAge) != Signature(ageAndName) {
+		t.Error("expected the same signature regardless of predicate order")
+	}
+}
+
+func TestStore_NilIsNoOp(t *testing.T) {
+	var store *Store
+	store.Record("orders", statusPredicate("open"), 1, 2)
+	if _, ok := store.Correction("orders", statusPredicate("open")); ok {
+		t.Error("nil store returned a correction")
+	}
+	if len(store.Snapshot()) != 0 {
+		t.Error("nil store returned entries")
+	}
+}
@@ -0,0 +1,14 @@
+package feedback
+
+import "storemy/pkg/metrics"
+
+var (
+	feedbackRecorded = metrics.NewCounter(
+		"storemy_cardinality_feedback_recorded_total",
+		"Scan row counts recorded by EXPLAIN ANALYZE as cardinality feedback",
+	)
+	feedbackApplied = metrics.NewCounter(
+		"storemy_cardinality_feedback_applied_total",
+		"Scan estimates corrected using recorded cardinality feedback",
+	)
+)
@@ -5,6 +5,7 @@ import (
 	"math"
 	"storemy/pkg/catalog/catalogmanager"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/optimizer/feedback"
 	"storemy/pkg/optimizer/internal/selectivity"
 	"storemy/pkg/plan"
 	"storemy/pkg/primitives"
@@ -39,8 +40,9 @@ const (
 //
 // All estimates require a transaction context for accessing catalog statistics.
 type CardinalityEstimator struct {
-	catalog *catalogmanager.CatalogManager
-	tx      *transaction.TransactionContext
+	catalog  *catalogmanager.CatalogManager
+	tx       *transaction.TransactionContext
+	feedback *feedback.Store // Optional: corrects scan estimates from observed row counts
 }
 
 // NewCardinalityEstimator creates a new cardinality estimator.
@@ -60,6 +62,12 @@ func NewCardinalityEstimator(cat *catalogmanager.CatalogManager, tx *transaction
 	}, nil
 }
 
+// SetFeedback attaches the cardinality feedback used to correct scan
+// estimates. A nil store disables the correction.
+func (ce *CardinalityEstimator) SetFeedback(store *feedback.Store) {
+	ce.feedback = store
+}
+
 // EstimatePlanCardinality estimates the output cardinality for a plan node.
 // Returns the cached cardinality if already computed, otherwise recursively
 // estimates based on the node type.
@@ -158,6 +166,7 @@ func (ce *CardinalityEstimator) getColumnDistinctCount(planNode plan.PlanNode, c
 //   - Single column GROUP BY: Uses column distinct count from statistics
 //   - Multiple column GROUP BY: Multiplies distinct counts (assumes independence)
 //   - Result capped at input cardinality (aggregation can't increase rows)
+//   - GROUPING SETS, ROLLUP, CUBE: Sums the rows of each set, one for the empty set
 //
 // Parameters:
 //   - node: Aggregate plan node to estimate
@@ -170,13 +179,24 @@ func (ce *CardinalityEstimator) estimateAggr(node *plan.AggregateNode) (Cardinal
 	}
 
 	if len(node.GroupByExprs) == 0 {
-		return 1, nil
+		return Cardinality(max(1, len(node.GroupingSets))), nil
 	}
 
 	groupCard := ce.estimateGroupByDistinctCount(node.Child, node.GroupByExprs)
-	result := Cardinality(math.Min(float64(groupCard), float64(childCard)))
+	result := Cardinality(math.Max(1.0, math.Min(float64(groupCard), float64(childCard))))
+	if node.GroupingSets == nil {
+		return result, nil
+	}
 
-	return Cardinality(math.Max(1.0, float64(result))), nil
+	var total Cardinality
+	for _, grouped := range node.GroupingSets {
+		if grouped {
+			total += result
+		} else {
+			total++
+		}
+	}
+	return max(total, MinCardinality), nil
 }
 
 // estimateGroupByDistinctCount estimates the distinct count for a GROUP BY operation.
@@ -666,6 +666,28 @@ func TestAggregateCardinality(t *testing.T) {
 
 		t.Logf("Multi-column GROUP BY: input=10000, output=%d", result)
 	})
+
+	t.Run("Grouping Sets Add The Rows Of Each Set", func(t *testing.T) {
+		child := &plan.ProjectNode{}
+		child.SetCardinality(1000)
+
+		// ROLLUP (status): the groups of status, then one total row
+		agg := &plan.AggregateNode{
+			Child:        child,
+			GroupByExprs: []string{"status"},
+			GroupingSets: []bool{true, false},
+		}
+
+		result, err := ce.estimateAggr(agg)
+		if err != nil {
+			t.Fatalf("estimateAggr error: %v", err)
+		}
+
+		expected := Cardinality(DefaultDistinctCount) + 1
+		if result != expected {
+			t.Errorf("ROLLUP cardinality: expected %d, got %d", expected, result)
+		}
+	})
 }
 
 // TestJoinCardinalityWithContainment tests improved join selectivity
@@ -744,6 +766,77 @@ func TestJoinCardinalityWithContainment(t *testing.T) {
 		}
 	})
 
+	t.Run("Outer Join Keeps Preserved Rows", func(t *testing.T) {
+		leftChild := &plan.ProjectNode{}
+		leftChild.SetCardinality(1000)
+
+		rightChild := &plan.ProjectNode{}
+		rightChild.SetCardinality(50)
+
+		emptyChild := &plan.ProjectNode{}
+		emptyChild.SetCardinality(0)
+
+		tests := []struct {
+			joinType string
+			right    plan.PlanNode
+			want     Cardinality
+		}{
+			{"left", rightChild, 1000},
+			{"right", leftChild, 1000},
+			{"full", rightChild, 1000},
+			{"left", emptyChild, 1000},
+			{"inner", emptyChild, 0},
+		}
+		for _, tt := range tests {
+			left := plan.PlanNode(leftChild)
+			if tt.joinType == "right" {
+				left = rightChild
+			}
+			join := &plan.JoinNode{LeftChild: left, RightChild: tt.right, JoinType: tt.joinType}
+
+			result, err := ce.estimateJoin(join)
+			if err != nil {
+				t.Fatalf("estimateJoin error: %v", err)
+			}
+			if result < tt.want {
+				t.Errorf("%s join: expected at least %d rows, got %d", tt.joinType, tt.want, result)
+			}
+		}
+	})
+
+	t.Run("Semi And Anti Joins Filter Left Rows", func(t *testing.T) {
+		leftChild := &plan.ProjectNode{}
+		leftChild.SetCardinality(1000)
+
+		rightChild := &plan.ProjectNode{}
+		rightChild.SetCardinality(1000000)
+
+		emptyChild := &plan.ProjectNode{}
+		emptyChild.SetCardinality(0)
+
+		tests := []struct {
+			joinType string
+			right    plan.PlanNode
+			want     Cardinality
+		}{
+			{"semi", rightChild, 500},
+			{"anti", rightChild, 500},
+			{"semi", emptyChild, 0},
+			{"anti", emptyChild, 1000},
+		}
+		for _, tt := range tests {
+			join := &plan.JoinNode{LeftChild: leftChild, RightChild: tt.right, JoinType: tt.joinType}
+
+			result, err := ce.estimateJoin(join)
+			if err != nil {
+				t.Fatalf("estimateJoin error: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("%s join: expected %d rows, got %d", tt.joinType, tt.want, result)
+			}
+		}
+	})
+
 	t.Run("Join With Extra Filters", func(t *testing.T) {
 		leftScan := &plan.ScanNode{TableID: 1}
 		leftScan.SetCardinality(1000)
@@ -1584,3 +1677,78 @@ func TestJoinCardinalityWithActualCatalog(t *testing.T) {
 		}
 	})
 }
+
+func TestJoinCardinalityWithForeignKey(t *testing.T) {
+	tcs := setupTestCatalogWithData(t)
+	defer tcs.cleanup()
+
+	tx, err := tcs.txRegistry.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer tcs.store.CommitTransaction(tx)
+
+	createTable := func(name string, columns []schema.ColumnMetadata) primitives.FileID {
+		sch, err := schema.NewSchema(0, name, columns)
+		if err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+		tableID, err := tcs.catalog.CreateTable(tx, sch)
+		if err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+		return tableID
+	}
+	usersTableID := createTable("USERS", []schema.ColumnMetadata{
+		{Name: "ID", FieldType: types.IntType, Position: 0, IsPrimary: true},
+	})
+	ordersTableID := createTable("ORDERS", []schema.ColumnMetadata{
+		{Name: "ID", FieldType: types.IntType, Position: 0, IsPrimary: true},
+		{Name: "USER_ID", FieldType: types.IntType, Position: 1},
+	})
+	if _, err := tcs.catalog.CreateForeignKeyConstraint(tx, ordersTableID, "fk_orders_user", "USER_ID", usersTableID, "ID", "RESTRICT", "RESTRICT"); err != nil {
+		t.Fatalf("failed to create foreign key: %v", err)
+	}
+
+	ce, err := NewCardinalityEstimator(tcs.catalog, tx)
+	if err != nil {
+		t.Fatalf("Failed to create cardinality estimator: %v", err)
+	}
+
+	newJoin := func(leftColumn, rightColumn string) *plan.JoinNode {
+		users := &plan.ScanNode{TableID: usersTableID}
+		users.SetCardinality(100)
+		orders := &plan.ScanNode{TableID: ordersTableID}
+		orders.SetCardinality(5000)
+		return &plan.JoinNode{
+			LeftChild:   users,
+			RightChild:  &plan.FilterNode{Child: orders},
+			LeftColumn:  leftColumn,
+			RightColumn: rightColumn,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		leftColumn  string
+		rightColumn string
+		expected    Cardinality
+	}{
+		// Every order matches its one user
+		{"Along the foreign key", "u.id", "o.user_id", 5000},
+		// Without statistics: 100 × 5000 × DefaultJoinSelectivity
+		{"Not along the foreign key", "u.id", "o.id", 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ce.estimateJoin(newJoin(tt.leftColumn, tt.rightColumn))
+			if err != nil {
+				t.Fatalf("estimateJoin error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d rows, got %d", tt.expected, result)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package cardinality
 
 import (
 	"math"
+	"storemy/pkg/catalog/catalogmanager"
 	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"strings"
 )
 
 // estimateJoin estimates output rows for a join node.
@@ -19,10 +22,15 @@ import (
 // Reasoning:
 //   - Cross product (leftRows × rightRows) is the worst-case upper bound
 //   - Join condition reduces this via joinSelectivity (typically 1/max(NDV_left, NDV_right))
+//   - A join along a declared foreign key instead matches each child row with
+//     exactly one parent row (see estimateForeignKeyJoin)
 //   - Extra filters further reduce output using correlation-corrected selectivity
 //   - Final result cannot exceed cross product size (min constraint)
 //   - Cannot produce fewer than 1 row to avoid zero estimates
 //   - Correlation correction prevents over-aggressive filtering from compound predicates
+//   - An outer join keeps every row of its preserved input, so before extra
+//     filters it produces at least that input's rows (see outerJoinMinimum)
+//   - A semi-join or anti-join only filters its left input (see estimateSemiJoin)
 //
 // Join Selectivity Estimation:
 //   - Equi-joins use distinct value counts (NDV) from both sides
@@ -49,12 +57,20 @@ func (ce *CardinalityEstimator) estimateJoin(node *plan.JoinNode) (Cardinality,
 		return 0, err
 	}
 
+	if node.JoinType == "semi" || node.JoinType == "anti" {
+		return estimateSemiJoin(node, leftCard, rightCard), nil
+	}
+
 	if leftCard == 0 || rightCard == 0 {
-		return 0, nil
+		return outerJoinMinimum(node, leftCard, rightCard), nil
 	}
 
 	baseCard := leftCard * rightCard
-	joinSelectivity := ce.estimateJoinSelectivity(node)
+	joined := float64(baseCard) * ce.estimateJoinSelectivity(node)
+	if fkRows, ok := ce.estimateForeignKeyJoin(node, leftCard, rightCard); ok {
+		joined = fkRows
+	}
+	matched := math.Max(joined, float64(outerJoinMinimum(node, leftCard, rightCard)))
 
 	filterSelectivity := 1.0
 	if len(node.ExtraFilters) > 0 {
@@ -72,13 +88,43 @@ func (ce *CardinalityEstimator) estimateJoin(node *plan.JoinNode) (Cardinality,
 		filterSelectivity = applyCorrelationCorrection(selectivities)
 	}
 
-	totalSelectivity := joinSelectivity * filterSelectivity
-	result := Cardinality(float64(baseCard) * totalSelectivity)
+	result := Cardinality(matched * filterSelectivity)
 
 	finalCard := math.Min(float64(result), float64(leftCard*rightCard))
 	return Cardinality(math.Max(1.0, finalCard)), nil
 }
 
+// outerJoinMinimum returns the fewest rows a join can produce: none for an
+// inner join, and for an outer join the rows of the input it preserves, each
+// of which appears at least once, matched or padded with NULLs. A FULL join
+// preserves both inputs, so it produces at least as many rows as the larger.
+func outerJoinMinimum(node *plan.JoinNode, leftCard, rightCard Cardinality) Cardinality {
+	switch node.JoinType {
+	case "left":
+		return leftCard
+	case "right":
+		return rightCard
+	case "full":
+		return max(leftCard, rightCard)
+	default:
+		return 0
+	}
+}
+
+// estimateSemiJoin estimates the rows of a semi-join or anti-join, which
+// outputs each left row at most once, depending on whether it has a match.
+// Without statistics on how many do, half of the left rows are assumed to;
+// against an empty right input a semi-join keeps none and an anti-join all.
+func estimateSemiJoin(node *plan.JoinNode, leftCard, rightCard Cardinality) Cardinality {
+	if leftCard == 0 || rightCard == 0 {
+		if node.JoinType == "anti" {
+			return leftCard
+		}
+		return 0
+	}
+	return Cardinality(math.Max(1.0, math.Ceil(float64(leftCard)/2)))
+}
+
 // estimateJoinSelectivity estimates the selectivity of the join condition.
 //
 // Mathematical Model:
@@ -174,3 +220,111 @@ func (ce *CardinalityEstimator) estimateEquiJoinSelectivity(node *plan.JoinNode)
 	// Use maximum as conservative estimate (lower selectivity = fewer output rows)
 	return 1.0 / maxDistinct
 }
+
+// estimateForeignKeyJoin estimates the rows of an equi-join along a foreign
+// key declared in CATALOG_CONSTRAINTS, or returns false if the join columns
+// are not the columns of one.
+//
+// Mathematical Model:
+//
+//	outputRows = childRows × min(1, parentRows / parentTableRows)
+//
+// Reasoning:
+//   - Each row of the referencing (child) table holds a key of exactly one
+//     row of the referenced (parent) table, so an unfiltered parent matches
+//     every child row once: the join produces the child side's rows
+//   - Filtering the parent side keeps the child rows whose parent survives,
+//     assumed to be the same fraction as the parent rows kept; without
+//     statistics on the parent table it is assumed unfiltered
+//   - Only single-column foreign keys whose tables are scanned on opposite
+//     sides of the join are recognised; NULL keys are ignored
+//
+// Example:
+//
+//	Orders (10,000 rows) ⋈ Customers (1,000 rows, 100 after WHERE country = 'NL')
+//	ON orders.customer_id = customers.id, with orders.customer_id → customers.id
+//	Output: 10,000 × 100/1,000 = 1,000 rows
+func (ce *CardinalityEstimator) estimateForeignKeyJoin(node *plan.JoinNode, leftCard, rightCard Cardinality) (float64, bool) {
+	if ce.catalog == nil || node.LeftColumn == "" || node.RightColumn == "" {
+		return 0, false
+	}
+
+	leftScans := collectScanNodes(node.LeftChild)
+	rightScans := collectScanNodes(node.RightChild)
+
+	if rows, ok := ce.foreignKeyJoinRows(leftScans, node.LeftColumn, leftCard, rightScans, node.RightColumn, rightCard); ok {
+		return rows, true
+	}
+	return ce.foreignKeyJoinRows(rightScans, node.RightColumn, rightCard, leftScans, node.LeftColumn, leftCard)
+}
+
+// foreignKeyJoinRows estimates the rows of a join of childColumn, on the side
+// scanning childScans, with parentColumn, on the side scanning parentScans,
+// if a table of the first side has a foreign key on childColumn referencing
+// parentColumn of a table of the second.
+func (ce *CardinalityEstimator) foreignKeyJoinRows(
+	childScans []*plan.ScanNode, childColumn string, childCard Cardinality,
+	parentScans []*plan.ScanNode, parentColumn string, parentCard Cardinality,
+) (float64, bool) {
+	for _, child := range childScans {
+		for _, parent := range parentScans {
+			if !ce.hasForeignKey(child.TableID, childColumn, parent.TableID, parentColumn) {
+				continue
+			}
+
+			kept := 1.0
+			tableStats, err := ce.catalog.GetTableStatistics(ce.tx, parent.TableID)
+			if err == nil && tableStats != nil && tableStats.Cardinality > 0 {
+				kept = math.Min(1.0, float64(parentCard)/float64(tableStats.Cardinality))
+			}
+			return float64(childCard) * kept, true
+		}
+	}
+	return 0, false
+}
+
+// hasForeignKey reports whether the table childID has an enabled foreign key
+// on childColumn alone referencing parentColumn of the table parentID.
+func (ce *CardinalityEstimator) hasForeignKey(childID primitives.FileID, childColumn string, parentID primitives.FileID, parentColumn string) bool {
+	if childID == 0 || parentID == 0 {
+		return false
+	}
+
+	foreignKeys, err := ce.catalog.GetConstraintsByType(ce.tx, childID, catalogmanager.ConstraintTypeForeignKey)
+	if err != nil {
+		return false
+	}
+
+	for _, fk := range foreignKeys {
+		if fk.IsEnabled && fk.ReferencedTableID == parentID &&
+			sameColumn(fk.ColumnNames, childColumn) && sameColumn(fk.ReferencedColumns, parentColumn) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameColumn reports whether the catalog column name matches the join
+// column, which may be qualified with its table or alias.
+func sameColumn(catalogColumn, joinColumn string) bool {
+	if i := strings.LastIndex(joinColumn, "."); i >= 0 {
+		joinColumn = joinColumn[i+1:]
+	}
+	return strings.EqualFold(strings.TrimSpace(catalogColumn), joinColumn)
+}
+
+// collectScanNodes returns the scan nodes of a plan subtree.
+func collectScanNodes(planNode plan.PlanNode) []*plan.ScanNode {
+	if planNode == nil {
+		return nil
+	}
+	if scanNode, ok := planNode.(*plan.ScanNode); ok {
+		return []*plan.ScanNode{scanNode}
+	}
+
+	var scans []*plan.ScanNode
+	for _, child := range planNode.GetChildren() {
+		scans = append(scans, collectScanNodes(child)...)
+	}
+	return scans
+}
@@ -27,7 +27,26 @@ import (
 //	Table with 10,000 rows, predicates: age > 30 (sel=0.6), city='NYC' (sel=0.1)
 //	Naive: 10000 × 0.6 × 0.1 = 600 rows
 //	With correlation correction: ~1000-1500 rows (more realistic)
+//
+// When cardinality feedback has been recorded for the same table and
+// predicates, the estimate is scaled by the observed correction factor.
 func (ce *CardinalityEstimator) estimateScan(node *plan.ScanNode) (Cardinality, error) {
+	card, err := ce.EstimateScanWithoutFeedback(node)
+	if err != nil || len(node.Predicates) == 0 {
+		return card, err
+	}
+
+	correction, ok := ce.feedback.Correction(node.TableName, node.Predicates)
+	if !ok {
+		return card, nil
+	}
+	return max(Cardinality(math.Round(float64(card)*correction)), MinCardinality), nil
+}
+
+// EstimateScanWithoutFeedback estimates output rows for a scan node from
+// statistics alone, ignoring recorded cardinality feedback. It is the estimate
+// that feedback is recorded against.
+func (ce *CardinalityEstimator) EstimateScanWithoutFeedback(node *plan.ScanNode) (Cardinality, error) {
 	tableStats, err := ce.catalog.GetTableStatistics(ce.tx, node.TableID)
 	if err != nil || tableStats == nil {
 		return DefaultTableCardinality, nil
@@ -3,10 +3,12 @@ package costmodel
 import "storemy/pkg/optimizer/internal/cardinality"
 
 const (
-	// I/O cost parameters
-	IoCostPerPage          = 1.0  // Cost of reading one page from disk
-	SequentialIoCostFactor = 0.1  // Sequential I/O is ~10x cheaper than random
-	CPUCostPerTuple        = 0.01 // Cost of processing one tuple
+	// Unit costs (default values, see Parameters)
+	DefaultSeqPageCost       = 0.1   // Sequential I/O is ~10x cheaper than random
+	DefaultRandomPageCost    = 1.0   // Cost of reading one page from disk
+	DefaultCPUTupleCost      = 0.01  // Cost of processing one tuple
+	DefaultCPUIndexTupleCost = 0.005 // Index entries are simpler than tuples
+	DefaultCPUOperatorCost   = 0.01  // Cost of evaluating one predicate
 
 	// Memory parameters (default values)
 	DefaultMemoryPages     = 1000 // 1000 pages = ~8MB with 8KB pages
@@ -21,9 +23,8 @@ const (
 	HashBuildCPUFactor = 2.0 // Building hash table is more expensive
 	HashProbeCPUFactor = 1.5 // Hash lookup overhead
 
-	// Projection/filter parameters
+	// Projection parameters
 	ProjectionCPUFactor = 0.5 // Projection is lighter than full tuple processing
-	FilterCPUFactor     = 1.0 // Filter evaluation cost multiplier
 
 	// Aggregation parameters
 	SimpleAggCPUFactor = 1.0 // No GROUP BY
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"storemy/pkg/catalog/catalogmanager"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/optimizer/feedback"
 	"storemy/pkg/optimizer/internal/cardinality"
 	"storemy/pkg/plan"
 )
@@ -24,11 +25,10 @@ type CostModel struct {
 	tx                   *transaction.TransactionContext
 
 	// Tunable cost parameters based on hardware characteristics
-	IOCostPerPage   float64 // Cost of reading one page from disk
-	CPUCostPerTuple float64 // Cost of processing one tuple
-	MemoryPages     int     // Available buffer pool pages
-	HashTableMemory int     // Memory available for hash tables (in pages)
-	SortMemory      int     // Memory available for sorting (in pages)
+	Parameters
+	MemoryPages     int // Available buffer pool pages
+	HashTableMemory int // Memory available for hash tables (in pages)
+	SortMemory      int // Memory available for sorting (in pages)
 }
 
 // NewCostModel creates a new cost model with default parameters.
@@ -44,8 +44,7 @@ func NewCostModel(cat *catalogmanager.CatalogManager, tx *transaction.Transactio
 		catalog:              cat,
 		cardinalityEstimator: cardEst,
 		tx:                   tx,
-		IOCostPerPage:        IoCostPerPage,
-		CPUCostPerTuple:      CPUCostPerTuple,
+		Parameters:           DefaultParameters(),
 		MemoryPages:          DefaultMemoryPages,
 		HashTableMemory:      DefaultHashTableMemory,
 		SortMemory:           DefaultSortMemory,
@@ -98,25 +97,50 @@ func (cm *CostModel) EstimatePlanCost(planNode plan.PlanNode) float64 {
 	return cost
 }
 
+// Parameters are the unit costs every node cost is computed from. Costs are
+// relative, so only the ratios between parameters affect which plan wins:
+// calibrating them to the hardware (e.g. a lower RandomPageCost on SSDs,
+// where random reads are almost as cheap as sequential ones) makes the
+// optimizer's choices match it better.
+type Parameters struct {
+	SeqPageCost       float64 // Cost of reading one page as part of a sequential scan
+	RandomPageCost    float64 // Cost of reading one page at a random position
+	CPUTupleCost      float64 // Cost of processing one tuple
+	CPUIndexTupleCost float64 // Cost of processing one index entry
+	CPUOperatorCost   float64 // Cost of evaluating one predicate on one tuple
+}
+
+// DefaultParameters returns the parameters a new cost model uses.
+func DefaultParameters() Parameters {
+	return Parameters{
+		SeqPageCost:       DefaultSeqPageCost,
+		RandomPageCost:    DefaultRandomPageCost,
+		CPUTupleCost:      DefaultCPUTupleCost,
+		CPUIndexTupleCost: DefaultCPUIndexTupleCost,
+		CPUOperatorCost:   DefaultCPUOperatorCost,
+	}
+}
+
 // SetCostParameters allows tuning cost model parameters for specific hardware.
 // This can be used to calibrate the cost model based on actual system performance.
-//
-// Parameters:
-// - ioCostPerPage: Relative cost of reading one page (adjust based on disk speed)
-// - cpuCostPerTuple: Relative cost of processing one tuple (adjust based on CPU speed)
-// - memoryPages: Total buffer pool size in pages
-func (cm *CostModel) SetCostParameters(
-	ioCostPerPage,
-	cpuCostPerTuple float64,
-	memoryPages int,
-) {
-	cm.IOCostPerPage = ioCostPerPage
-	cm.CPUCostPerTuple = cpuCostPerTuple
+func (cm *CostModel) SetCostParameters(params Parameters) {
+	cm.Parameters = params
+}
+
+// SetMemoryPages sets the buffer pool size in pages, half of which is
+// assumed available to hash joins and half to sorts.
+func (cm *CostModel) SetMemoryPages(memoryPages int) {
 	cm.MemoryPages = memoryPages
 	cm.HashTableMemory = memoryPages / 2 // Allocate half for hash joins
 	cm.SortMemory = memoryPages / 2      // Allocate half for sorting
 }
 
+// SetFeedback attaches the cardinality feedback the estimator uses to correct
+// scan estimates.
+func (cm *CostModel) SetFeedback(store *feedback.Store) {
+	cm.cardinalityEstimator.SetFeedback(store)
+}
+
 // GetCardinalityEstimator returns the cardinality estimator used by this cost model.
 // This allows other optimizer components to access cardinality estimation directly.
 func (cm *CostModel) GetCardinalityEstimator() *cardinality.CardinalityEstimator {
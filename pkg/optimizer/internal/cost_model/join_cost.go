@@ -50,7 +50,7 @@ func (cm *CostModel) estimateJoinCost(node *plan.JoinNode) float64 {
 	}
 
 	// Cost of materializing output tuples (CPU overhead for result construction)
-	outputCost := float64(node.Cardinality) * cm.CPUCostPerTuple
+	outputCost := float64(node.Cardinality) * cm.CPUTupleCost
 
 	return baseCost + joinMethodCost + outputCost
 }
@@ -89,8 +89,8 @@ func (cm *CostModel) estimateHashJoinCost(leftCard, rightCard int64) float64 {
 	buildSize := float64(min(leftCard, rightCard))
 	probeSize := float64(max(leftCard, rightCard))
 
-	buildCost := buildSize * cm.CPUCostPerTuple * HashBuildCPUFactor
-	probeCost := probeSize * cm.CPUCostPerTuple * HashProbeCPUFactor
+	buildCost := buildSize * cm.CPUTupleCost * HashBuildCPUFactor
+	probeCost := probeSize * cm.CPUTupleCost * HashProbeCPUFactor
 
 	// Check if hash table fits in memory
 	// Calculate pages needed for build relation
@@ -100,7 +100,7 @@ func (cm *CostModel) estimateHashJoinCost(leftCard, rightCard int64) float64 {
 		// Hash table doesn't fit: need grace hash join with partitioning
 		// Must write and re-read both relations (2× I/O per relation)
 		// Total I/O: 2 writes + 2 reads = 4× data volume
-		partitionIOCost := (buildSize + probeSize) / DefaultTuplesPerPage * cm.IOCostPerPage * 2.0
+		partitionIOCost := (buildSize + probeSize) / DefaultTuplesPerPage * cm.RandomPageCost * 2.0
 		return buildCost + probeCost + partitionIOCost
 	}
 
@@ -146,7 +146,7 @@ func (cm *CostModel) estimateSortMergeJoinCost(leftCard, rightCard int64) float6
 
 	// Merge phase: linear scan of both sorted relations
 	// Each tuple processed once for comparison
-	mergeCost := float64(leftCard+rightCard) * cm.CPUCostPerTuple
+	mergeCost := float64(leftCard+rightCard) * cm.CPUTupleCost
 
 	return leftSortCost + rightSortCost + mergeCost
 }
@@ -178,11 +178,11 @@ func (cm *CostModel) estimateSortMergeJoinCost(leftCard, rightCard int64) float6
 // Returns:
 //   - float64: Total nested loop cost (outer + inner loop processing)
 func (cm *CostModel) estimateNestedLoopJoinCost(leftCard, rightCard int64) float64 {
-	outerCost := float64(leftCard) * cm.CPUCostPerTuple
+	outerCost := float64(leftCard) * cm.CPUTupleCost
 
 	// Inner loop: for each outer tuple, process all inner tuples
 	// This is the expensive O(n*m) component
-	innerCost := float64(leftCard) * float64(rightCard) * cm.CPUCostPerTuple
+	innerCost := float64(leftCard) * float64(rightCard) * cm.CPUTupleCost
 
 	return outerCost + innerCost
 }
@@ -235,7 +235,7 @@ func (cm *CostModel) estimateSortCostInternal(cardinality int64) float64 {
 	if sortPages <= float64(cm.SortMemory) {
 		// In-memory quicksort: O(n log n) comparisons
 		// No I/O cost, purely CPU-bound
-		return nFloat * math.Log2(nFloat) * cm.CPUCostPerTuple
+		return nFloat * math.Log2(nFloat) * cm.CPUTupleCost
 	}
 
 	// External merge sort required
@@ -255,10 +255,10 @@ func (cm *CostModel) estimateSortCostInternal(cardinality int64) float64 {
 
 	// I/O cost: read and write entire dataset per pass
 	// 2× factor accounts for reading input runs and writing merged output
-	ioCost := sortPages * cm.IOCostPerPage * 2.0 * passes
+	ioCost := sortPages * cm.RandomPageCost * 2.0 * passes
 
 	// CPU cost: O(n log n) comparison cost regardless of merge strategy
-	cpuCost := nFloat * math.Log2(nFloat) * cm.CPUCostPerTuple
+	cpuCost := nFloat * math.Log2(nFloat) * cm.CPUTupleCost
 
 	return ioCost + cpuCost
 }
@@ -18,7 +18,7 @@ import (
 //   - Complex predicates (subqueries, functions) cost more
 //
 // Cost Formula:
-//   - Total = ChildCost + (InputCardinality × CPUCostPerTuple × FilterFactor × NumPredicates)
+//   - Total = ChildCost + (InputCardinality × CPUOperatorCost × NumPredicates)
 //
 // Performance Characteristics:
 //   - Pure CPU operation (no I/O beyond child)
@@ -39,8 +39,7 @@ func (cm *CostModel) estimateFilterCost(node *plan.FilterNode) float64 {
 	childCost := cm.EstimatePlanCost(node.Child)
 	childCard := node.Child.GetCardinality()
 
-	tuplesCost := float64(childCard) * cm.CPUCostPerTuple
-	filterCost := tuplesCost * FilterCPUFactor * float64(len(node.Predicates))
+	filterCost := float64(childCard) * cm.CPUOperatorCost * float64(len(node.Predicates))
 
 	return childCost + filterCost
 }
@@ -59,7 +58,7 @@ func (cm *CostModel) estimateFilterCost(node *plan.FilterNode) float64 {
 //   - Expression evaluation adds overhead (not separately modeled)
 //
 // Cost Formula:
-//   - Total = ChildCost + (InputCardinality × CPUCostPerTuple × ProjectionFactor)
+//   - Total = ChildCost + (InputCardinality × CPUTupleCost × ProjectionFactor)
 //
 // Performance Characteristics:
 //   - Lightweight CPU operation
@@ -83,7 +82,7 @@ func (cm *CostModel) estimateProjectCost(node *plan.ProjectNode) float64 {
 
 	// Projection is lighter than full tuple processing
 	// ProjectionCPUFactor accounts for column selection overhead
-	projectionCost := float64(childCard) * cm.CPUCostPerTuple * ProjectionCPUFactor
+	projectionCost := float64(childCard) * cm.CPUTupleCost * ProjectionCPUFactor
 	return childCost + projectionCost
 }
 
@@ -115,9 +114,9 @@ func (cm *CostModel) estimateProjectCost(node *plan.ProjectNode) float64 {
 //   - External agg adds 2× I/O cost (write partitions + read back)
 //
 // Cost Formula:
-//   - Simple: ChildCost + (n × CPUCostPerTuple × SimpleAggFactor)
-//   - Grouped (in-memory): ChildCost + (n × CPUCostPerTuple × GroupAggFactor)
-//   - Grouped (external): Above + (n/TuplesPerPage × IOCostPerPage × 2)
+//   - Simple: ChildCost + (n × CPUTupleCost × SimpleAggFactor)
+//   - Grouped (in-memory): ChildCost + (n × CPUTupleCost × GroupAggFactor)
+//   - Grouped (external): Above + (n/TuplesPerPage × RandomPageCost × 2)
 //
 // Best suited for:
 //   - Simple aggregation: Any cardinality (very efficient)
@@ -138,7 +137,7 @@ func (cm *CostModel) estimateAggregateCost(node *plan.AggregateNode) float64 {
 		// Simple aggregation (no GROUP BY): single-pass accumulation
 		// e.g., SELECT COUNT(*) FROM table
 		// Maintains single set of aggregate values (one accumulator per function)
-		aggCost := float64(childCard) * cm.CPUCostPerTuple * SimpleAggCPUFactor
+		aggCost := float64(childCard) * cm.CPUTupleCost * SimpleAggCPUFactor
 		return childCost + aggCost
 	}
 
@@ -148,7 +147,7 @@ func (cm *CostModel) estimateAggregateCost(node *plan.AggregateNode) float64 {
 	//   1. Hash the grouping columns
 	//   2. Probe hash table for matching group
 	//   3. Update aggregate values for that group
-	groupCost := float64(childCard) * cm.CPUCostPerTuple * GroupAggCPUFactor
+	groupCost := float64(childCard) * cm.CPUTupleCost * GroupAggCPUFactor
 
 	// Check if hash table fits in memory
 	numGroups := float64(node.Cardinality) // Output cardinality = number of groups
@@ -161,7 +160,7 @@ func (cm *CostModel) estimateAggregateCost(node *plan.AggregateNode) float64 {
 		//   - Aggregate each partition independently
 		//   - Ensures each partition fits in memory
 		// I/O cost: write all tuples + read them back = 2× data volume
-		spillCost := float64(childCard) / DefaultTuplesPerPage * cm.IOCostPerPage * 2.0
+		spillCost := float64(childCard) / DefaultTuplesPerPage * cm.RandomPageCost * 2.0
 		return childCost + groupCost + spillCost
 	}
 
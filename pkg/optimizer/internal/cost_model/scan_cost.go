@@ -15,11 +15,11 @@ import (
 //
 // Returns:
 //   - float64: Estimated cost in cost units (combines I/O and CPU costs)
-//   - Falls back to 100.0 * IOCostPerPage if statistics are unavailable
+//   - Falls back to 100.0 * RandomPageCost if statistics are unavailable
 func (cm *CostModel) estimateScanCost(node *plan.ScanNode) float64 {
 	stats, err := cm.catalog.GetTableStatistics(cm.tx, node.TableID)
 	if err != nil || stats == nil {
-		return 100.0 * cm.IOCostPerPage
+		return 100.0 * cm.RandomPageCost
 	}
 
 	switch node.AccessMethod {
@@ -37,9 +37,9 @@ func (cm *CostModel) estimateScanCost(node *plan.ScanNode) float64 {
 // estimateSeqScanCost calculates the cost of a full sequential table scan.
 //
 // Cost Model:
-//   - I/O Cost: PageCount × IOCostPerPage × SequentialIoCostFactor
+//   - I/O Cost: PageCount × SeqPageCost
 //     Sequential I/O is cheaper than random I/O due to disk prefetching
-//   - CPU Cost: Cardinality × CPUCostPerTuple (processing each tuple)
+//   - CPU Cost: Cardinality × CPUTupleCost (processing each tuple)
 //   - Cache Factor: Applied to reduce cost for recently accessed tables
 //
 // Sequential scans are efficient for:
@@ -53,13 +53,13 @@ func (cm *CostModel) estimateScanCost(node *plan.ScanNode) float64 {
 // Returns:
 //   - float64: Total estimated cost (I/O + CPU)
 func (cm *CostModel) estimateSeqScanCost(stats *systemtable.TableStatistics) float64 {
-	ioCost := float64(stats.PageCount) * cm.IOCostPerPage * SequentialIoCostFactor
+	ioCost := float64(stats.PageCount) * cm.SeqPageCost
 
 	ioCost = cm.applyCacheFactor(stats.TableID, ioCost)
 
 	cm.recordTableAccess(stats.TableID)
 
-	cpuCost := float64(stats.Cardinality) * cm.CPUCostPerTuple
+	cpuCost := float64(stats.Cardinality) * cm.CPUTupleCost
 
 	return ioCost + cpuCost
 }
@@ -72,7 +72,7 @@ func (cm *CostModel) estimateSeqScanCost(stats *systemtable.TableStatistics) flo
 //  3. Table lookups: Follow pointers to fetch actual tuples from heap pages
 //
 // Cost Components:
-//   - Index Lookup: BTreeHeight × IOCostPerPage (tree traversal)
+//   - Index Lookup: BTreeHeight × RandomPageCost (tree traversal)
 //   - Table Access: Depends on clustering factor:
 //   - ClusteringFactor = 0.0: Perfectly ordered, sequential I/O
 //   - ClusteringFactor = 1.0: Random order, many random I/Os
@@ -97,7 +97,7 @@ func (cm *CostModel) estimateIndexScanCost(node *plan.ScanNode, stats *systemtab
 	}
 
 	treeHeight := float64(indexStats.BTreeHeight)
-	indexLookupCost := treeHeight * cm.IOCostPerPage
+	indexLookupCost := treeHeight * cm.RandomPageCost
 
 	// 2. Table page access cost
 	// The clustering factor determines how many random I/Os we need
@@ -112,14 +112,14 @@ func (cm *CostModel) estimateIndexScanCost(node *plan.ScanNode, stats *systemtab
 	tuplesPerPage := calculateTuplesPerPage(stats, page.PageSize)
 	tablePages := outputRows / tuplesPerPage
 
-	tableAccessCost := tablePages * cm.IOCostPerPage *
-		(randomIOFactor + sequentialIOFactor*SequentialIoCostFactor)
+	tableAccessCost := tablePages *
+		(randomIOFactor*cm.RandomPageCost + sequentialIOFactor*cm.SeqPageCost)
 
 	tableAccessCost = cm.applyCacheFactor(stats.TableID, tableAccessCost)
 
 	cm.recordTableAccess(stats.TableID)
 
-	cpuCost := outputRows * cm.CPUCostPerTuple
+	cpuCost := outputRows * cm.CPUTupleCost
 	return indexLookupCost + tableAccessCost + cpuCost
 }
 
@@ -155,7 +155,7 @@ func (cm *CostModel) estimateIndexOnlyScanCost(node *plan.ScanNode, tableStats *
 
 	// 1. B-tree traversal cost (root to leaf)
 	treeHeight := float64(indexStats.BTreeHeight)
-	indexLookupCost := treeHeight * cm.IOCostPerPage
+	indexLookupCost := treeHeight * cm.RandomPageCost
 
 	// 2. Sequential scan of index leaf pages
 	// Index-only scan reads index pages sequentially (no table access needed)
@@ -163,10 +163,10 @@ func (cm *CostModel) estimateIndexOnlyScanCost(node *plan.ScanNode, tableStats *
 
 	// Index entries are typically smaller than full tuples
 	indexPagesScanned := outputRows / DefaultTuplesPerPage
-	indexScanCost := indexPagesScanned * cm.IOCostPerPage * SequentialIoCostFactor
+	indexScanCost := indexPagesScanned * cm.SeqPageCost
 
 	// 3. Reduced CPU cost (processing index entries is cheaper than full tuples)
-	cpuCost := outputRows * cm.CPUCostPerTuple * 0.5
+	cpuCost := outputRows * cm.CPUIndexTupleCost
 
 	return indexLookupCost + indexScanCost + cpuCost
 }
@@ -66,6 +66,15 @@ func (ppo *PredicatePushdownOptimizer) optimizeFilter(
 	node *plan.FilterNode,
 	existingPredicates []*PredicateContext,
 ) plan.PlanNode {
+	// Expression filters (e.g. involving CASE) cannot be pushed into a scan,
+	// so they stay on top of their optimized child.
+	if node.Expr != nil {
+		filtered := *node
+		filtered.Child = ppo.pushPredicates(tx, node.Child, existingPredicates)
+		filtered.Children = []plan.PlanNode{filtered.Child}
+		return &filtered
+	}
+
 	// Convert filter predicates to PredicateContext
 	newPredicates := make([]*PredicateContext, 0, len(node.Predicates))
 	for i := range node.Predicates {
@@ -105,9 +114,14 @@ func (ppo *PredicatePushdownOptimizer) optimizeJoin(
 	leftTables := ppo.getReferencedTables(node.LeftChild)
 	rightTables := ppo.getReferencedTables(node.RightChild)
 
+	// An outer join pads the columns of its null-extended side for rows
+	// without a match; filtering that side first would let the rows it
+	// removes reappear, padded, so predicates on it stay above the join
+	nullExtended := node.NullExtendedSide()
+
 	for _, predCtx := range predicates {
-		canPushLeft := ppo.canPushToChild(predCtx, leftTables)
-		canPushRight := ppo.canPushToChild(predCtx, rightTables)
+		canPushLeft := ppo.canPushToChild(predCtx, leftTables) && nullExtended != "left" && nullExtended != "either"
+		canPushRight := ppo.canPushToChild(predCtx, rightTables) && nullExtended != "right" && nullExtended != "either"
 
 		if canPushLeft && !canPushRight {
 			// Push to left child only
@@ -138,6 +152,10 @@ func (ppo *PredicatePushdownOptimizer) optimizeJoin(
 		RightColumn:   node.RightColumn,
 		JoinPredicate: node.JoinPredicate,
 		ExtraFilters:  append(node.ExtraFilters, joinPredicates...),
+		RightTable:    node.RightTable,
+		Subquery:      node.Subquery,
+		Lateral:       node.Lateral,
+		Condition:     node.Condition,
 	}
 
 	// Recompute cost and cardinality
@@ -5,6 +5,7 @@ import (
 	"storemy/pkg/catalog/catalogmanager"
 	"storemy/pkg/catalog/systemtable"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/optimizer/feedback"
 	"storemy/pkg/optimizer/internal/cardinality"
 	costmodel "storemy/pkg/optimizer/internal/cost_model"
 	"storemy/pkg/plan"
@@ -16,6 +17,8 @@ type QueryOptimizer struct {
 	costModel          *costmodel.CostModel
 	predicatePushdown  *PredicatePushdownOptimizer
 	joinOrderOptimizer *JoinOrderOptimizer
+	feedback           *feedback.Store
+	rules              *RuleRegistry
 
 	// Configuration
 	enablePredicatePushdown bool
@@ -31,6 +34,26 @@ type OptimizerConfig struct {
 	EnableBushyJoins        bool
 	EnableIndexSelection    bool
 	MaxJoinRelations        int
+
+	// Feedback corrects scan estimates with row counts observed by earlier
+	// EXPLAIN ANALYZE runs. Nil disables the correction.
+	Feedback *feedback.Store
+
+	// Rules are rewrite rules applied after the built-in ones. Nil adds none.
+	Rules *RuleRegistry
+
+	// CostParameters are the unit costs of the cost model. Nil uses
+	// DefaultCostParameters.
+	CostParameters *CostParameters
+}
+
+// CostParameters are the unit costs (page reads, tuples, predicate
+// evaluations) the cost model computes every node cost from.
+type CostParameters = costmodel.Parameters
+
+// DefaultCostParameters returns the unit costs used unless configured otherwise.
+func DefaultCostParameters() CostParameters {
+	return costmodel.DefaultParameters()
 }
 
 // DefaultOptimizerConfig returns default optimizer configuration
@@ -58,6 +81,11 @@ func NewQueryOptimizer(cat *catalogmanager.CatalogManager, config *OptimizerConf
 		return nil, fmt.Errorf("failed to create cost model: %w", err)
 	}
 
+	costModel.SetFeedback(config.Feedback)
+	if config.CostParameters != nil {
+		costModel.SetCostParameters(*config.CostParameters)
+	}
+
 	predicatePushdown := NewPredicatePushdownOptimizer(costModel)
 	joinOrderOptimizer := NewJoinOrderOptimizer(cat, costModel, config.EnableBushyJoins)
 	joinOrderOptimizer.SetMaxRelations(config.MaxJoinRelations)
@@ -67,6 +95,8 @@ func NewQueryOptimizer(cat *catalogmanager.CatalogManager, config *OptimizerConf
 		costModel:               costModel,
 		predicatePushdown:       predicatePushdown,
 		joinOrderOptimizer:      joinOrderOptimizer,
+		feedback:                config.Feedback,
+		rules:                   config.Rules,
 		enablePredicatePushdown: config.EnablePredicatePushdown,
 		enableJoinReordering:    config.EnableJoinReordering,
 		enableBushyJoins:        config.EnableBushyJoins,
@@ -74,7 +104,9 @@ func NewQueryOptimizer(cat *catalogmanager.CatalogManager, config *OptimizerConf
 	}, nil
 }
 
-// Optimize applies all optimization strategies to a query plan
+// Optimize applies all optimization strategies to a query plan: the
+// rewrite rules, in the order Rules returns them, and then a final cost
+// estimate of every node.
 func (qo *QueryOptimizer) Optimize(
 	tx *transaction.TransactionContext,
 	planNode plan.PlanNode,
@@ -84,37 +116,36 @@ func (qo *QueryOptimizer) Optimize(
 	}
 
 	optimizedPlan := planNode
-
-	// Phase 1: Logical optimization
-	// Apply predicate pushdown
-	if qo.enablePredicatePushdown {
-		optimizedPlan = qo.predicatePushdown.Optimize(tx, optimizedPlan)
-	}
-
-	// Phase 2: Join optimization
-	// If the plan contains joins, optimize join order
-	if qo.enableJoinReordering && qo.containsJoins(optimizedPlan) {
-		graph, err := qo.extractJoinGraph(tx, optimizedPlan)
-		if err == nil && graph != nil && graph.GetRelationCount() > 1 {
-			reorderedPlan, err := qo.joinOrderOptimizer.OptimizeJoinOrder(tx, graph)
-			if err == nil && reorderedPlan != nil {
-				optimizedPlan = reorderedPlan
-			}
+	for _, rule := range qo.Rules() {
+		var err error
+		optimizedPlan, err = ApplyRule(tx, rule, optimizedPlan)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	// Phase 3: Physical optimization
-	// Select access methods (index vs seq scan)
-	if qo.enableIndexSelection {
-		optimizedPlan = qo.selectAccessMethods(tx, optimizedPlan)
-	}
-
-	// Phase 4: Final cost estimation
 	qo.estimateFinalCosts(tx, optimizedPlan)
 
 	return optimizedPlan, nil
 }
 
+// Rules returns the rewrite rules Optimize applies, in order: the enabled
+// built-in rules, logical before physical, followed by the rules of the
+// configured registry.
+func (qo *QueryOptimizer) Rules() []RewriteRule {
+	var rules []RewriteRule
+	if qo.enablePredicatePushdown {
+		rules = append(rules, &predicatePushdownRule{ppo: qo.predicatePushdown})
+	}
+	if qo.enableJoinReordering {
+		rules = append(rules, &joinReorderRule{qo: qo})
+	}
+	if qo.enableIndexSelection {
+		rules = append(rules, &indexSelectionRule{qo: qo})
+	}
+	return append(rules, qo.rules.Rules()...)
+}
+
 // OptimizeJoinOrder optimizes just the join order of a query
 func (qo *QueryOptimizer) OptimizeJoinOrder(
 	tx *transaction.TransactionContext,
@@ -248,47 +279,6 @@ func (qo *QueryOptimizer) findRelations(node plan.PlanNode, graph *JoinGraph) []
 	return relations
 }
 
-// containsJoins checks if a plan tree contains any joins
-func (qo *QueryOptimizer) containsJoins(planNode plan.PlanNode) bool {
-	if planNode == nil {
-		return false
-	}
-
-	if _, ok := planNode.(*plan.JoinNode); ok {
-		return true
-	}
-
-	for _, child := range planNode.GetChildren() {
-		if qo.containsJoins(child) {
-			return true
-		}
-	}
-
-	return false
-}
-
-// selectAccessMethods chooses between index and sequential scans
-func (qo *QueryOptimizer) selectAccessMethods(
-	tx *transaction.TransactionContext,
-	planNode plan.PlanNode,
-) plan.PlanNode {
-	if planNode == nil {
-		return nil
-	}
-
-	switch n := planNode.(type) {
-	case *plan.ScanNode:
-		return qo.chooseBestAccessMethod(tx, n)
-	default:
-		// Recursively optimize children
-		children := planNode.GetChildren()
-		for i, child := range children {
-			children[i] = qo.selectAccessMethods(tx, child)
-		}
-		return planNode
-	}
-}
-
 // chooseBestAccessMethod selects the best access method for a scan
 func (qo *QueryOptimizer) chooseBestAccessMethod(
 	tx *transaction.TransactionContext,
@@ -422,6 +412,23 @@ func (qo *QueryOptimizer) explainPlanRecursive(node plan.PlanNode, indent int) s
 	return result
 }
 
+// RecordScanFeedback records that scan, a scan node of a plan returned by
+// Optimize, actually produced actual rows when the query ran. The feedback
+// store of the configuration remembers it against the uncorrected estimate,
+// so later plans with the same table and predicates are corrected. Unfiltered scans are not recorded: their
+// estimate is the table's row count, which ANALYZE keeps current.
+func (qo *QueryOptimizer) RecordScanFeedback(scan *plan.ScanNode, actual int64) {
+	if qo.feedback == nil || scan == nil || len(scan.Predicates) == 0 {
+		return
+	}
+
+	estimated, err := qo.costModel.GetCardinalityEstimator().EstimateScanWithoutFeedback(scan)
+	if err != nil {
+		return
+	}
+	qo.feedback.Record(scan.TableName, scan.Predicates, int64(estimated), actual)
+}
+
 // GetCostModel returns the cost model (for testing/tuning)
 func (qo *QueryOptimizer) GetCostModel() *costmodel.CostModel {
 	return qo.costModel
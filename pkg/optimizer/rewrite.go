@@ -0,0 +1,188 @@
+package optimizer
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/plan"
+	"strings"
+	"sync"
+)
+
+// RewriteRule is one transformation of a plan tree. The optimizer walks the
+// tree from the root and, at each node, asks the rule whether it applies;
+// Apply returns the node's replacement. The replacement's subtree belongs to
+// the rule and is not visited again by it, so a rule that rewrites a whole
+// subtree at once, like predicate pushdown, matches only at its top.
+//
+// Rules run one after another, each over the tree the previous one
+// produced: first the built-in rules (predicate pushdown, join reordering,
+// index selection) and then those registered with a RuleRegistry, in
+// registration order.
+type RewriteRule interface {
+	// Name identifies the rule; it must be unique within a registry.
+	Name() string
+
+	// Match reports whether the rule applies to node.
+	Match(node plan.PlanNode) bool
+
+	// Apply rewrites node, which Match accepted, returning node itself if
+	// there is nothing to change. An error abandons the whole optimization.
+	Apply(tx *transaction.TransactionContext, node plan.PlanNode) (plan.PlanNode, error)
+}
+
+// ApplyRule runs rule over the tree rooted at node and returns the rewritten
+// tree. Nodes above a rewritten one are copied with their new children, so
+// the tree passed in is not modified by the walk itself.
+func ApplyRule(tx *transaction.TransactionContext, rule RewriteRule, node plan.PlanNode) (plan.PlanNode, error) {
+	if node == nil {
+		return nil, nil
+	}
+	if rule.Match(node) {
+		rewritten, err := rule.Apply(tx, node)
+		if err != nil {
+			return nil, fmt.Errorf("rewrite rule %s: %w", rule.Name(), err)
+		}
+		return rewritten, nil
+	}
+
+	children := node.GetChildren()
+	newChildren := make([]plan.PlanNode, len(children))
+	changed := false
+	for i, child := range children {
+		rewritten, err := ApplyRule(tx, rule, child)
+		if err != nil {
+			return nil, err
+		}
+		newChildren[i] = rewritten
+		changed = changed || rewritten != child
+	}
+	if !changed {
+		return node, nil
+	}
+	return withChildren(node, newChildren), nil
+}
+
+// withChildren returns a copy of node with its children replaced. Node
+// types it does not know are returned unchanged.
+func withChildren(node plan.PlanNode, children []plan.PlanNode) plan.PlanNode {
+	switch n := node.(type) {
+	case *plan.FilterNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.ProjectNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.AggregateNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.SortNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.LimitNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.MaterializeNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.DistinctNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.UpdateNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.DeleteNode:
+		c := *n
+		c.Child, c.Children = children[0], children
+		return &c
+	case *plan.JoinNode:
+		c := *n
+		c.LeftChild, c.RightChild, c.Children = children[0], children[1], children
+		return &c
+	case *plan.SetOpNode:
+		c := *n
+		c.LeftChild, c.RightChild, c.Children = children[0], children[1], children
+		return &c
+	case *plan.UnionNode:
+		c := *n
+		c.LeftChild, c.RightChild, c.Children = children[0], children[1], children
+		return &c
+	case *plan.IntersectNode:
+		c := *n
+		c.LeftChild, c.RightChild, c.Children = children[0], children[1], children
+		return &c
+	case *plan.ExceptNode:
+		c := *n
+		c.LeftChild, c.RightChild, c.Children = children[0], children[1], children
+		return &c
+	default:
+		return node
+	}
+}
+
+// RuleRegistry holds the rewrite rules added to the built-in ones, in the
+// order they run. It is safe for concurrent use.
+type RuleRegistry struct {
+	mu    sync.RWMutex
+	rules []RewriteRule
+}
+
+// NewRuleRegistry creates an empty registry.
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{}
+}
+
+// Register appends rule to the registry. Returns an error if rule is nil,
+// its name is empty or a rule with the same case-insensitive name is
+// already registered.
+func (r *RuleRegistry) Register(rule RewriteRule) error {
+	if rule == nil {
+		return fmt.Errorf("rewrite rule cannot be nil")
+	}
+	name := strings.TrimSpace(rule.Name())
+	if name == "" {
+		return fmt.Errorf("rewrite rule name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.rules {
+		if strings.EqualFold(existing.Name(), name) {
+			return fmt.Errorf("rewrite rule %s is already registered", name)
+		}
+	}
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+// Unregister removes the rule with the given case-insensitive name, if any.
+func (r *RuleRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, rule := range r.rules {
+		if strings.EqualFold(rule.Name(), name) {
+			r.rules = append(r.rules[:i:i], r.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// Rules returns the registered rules in the order they run. A nil registry
+// has none.
+func (r *RuleRegistry) Rules() []RewriteRule {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]RewriteRule(nil), r.rules...)
+}
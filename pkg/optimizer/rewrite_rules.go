@@ -0,0 +1,94 @@
+package optimizer
+
+import (
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/plan"
+)
+
+// predicatePushdownRule moves filter predicates into the scans of the
+// tables they reference. It rewrites the whole subtree below the topmost
+// filter, join, scan or projection.
+type predicatePushdownRule struct {
+	ppo *PredicatePushdownOptimizer
+}
+
+func (r *predicatePushdownRule) Name() string {
+	return "predicate_pushdown"
+}
+
+func (r *predicatePushdownRule) Match(node plan.PlanNode) bool {
+	switch node.(type) {
+	case *plan.FilterNode, *plan.JoinNode, *plan.ScanNode, *plan.ProjectNode:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *predicatePushdownRule) Apply(tx *transaction.TransactionContext, node plan.PlanNode) (plan.PlanNode, error) {
+	return r.ppo.Optimize(tx, node), nil
+}
+
+// joinReorderRule replaces a tree of joins with the cheapest join order
+// found by dynamic programming. Trees it cannot turn into a join graph are
+// left as they are, as are trees with an outer join, which only commutes
+// with the joins around it in special cases, or with a subquery or CROSS
+// JOIN, whose place in the order the query fixes.
+type joinReorderRule struct {
+	qo *QueryOptimizer
+}
+
+func (r *joinReorderRule) Name() string {
+	return "join_reorder"
+}
+
+func (r *joinReorderRule) Match(node plan.PlanNode) bool {
+	_, ok := node.(*plan.JoinNode)
+	return ok && !containsFixedJoin(node)
+}
+
+// containsFixedJoin reports whether the tree rooted at node has a join that
+// cannot be reordered: an outer join, a join with a subquery or a CROSS JOIN.
+func containsFixedJoin(node plan.PlanNode) bool {
+	if join, ok := node.(*plan.JoinNode); ok && (join.IsOuter() || join.Subquery != nil || join.JoinType == "cross") {
+		return true
+	}
+	for _, child := range node.GetChildren() {
+		if child != nil && containsFixedJoin(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *joinReorderRule) Apply(tx *transaction.TransactionContext, node plan.PlanNode) (plan.PlanNode, error) {
+	graph, err := r.qo.extractJoinGraph(tx, node)
+	if err != nil || graph == nil || graph.GetRelationCount() <= 1 {
+		return node, nil
+	}
+
+	reordered, err := r.qo.joinOrderOptimizer.OptimizeJoinOrder(tx, graph)
+	if err != nil || reordered == nil {
+		return node, nil
+	}
+	return reordered, nil
+}
+
+// indexSelectionRule chooses between a sequential scan and an index scan
+// for each table by estimated cost.
+type indexSelectionRule struct {
+	qo *QueryOptimizer
+}
+
+func (r *indexSelectionRule) Name() string {
+	return "index_selection"
+}
+
+func (r *indexSelectionRule) Match(node plan.PlanNode) bool {
+	_, ok := node.(*plan.ScanNode)
+	return ok
+}
+
+func (r *indexSelectionRule) Apply(tx *transaction.TransactionContext, node plan.PlanNode) (plan.PlanNode, error) {
+	return r.qo.chooseBestAccessMethod(tx, node.(*plan.ScanNode)), nil
+}
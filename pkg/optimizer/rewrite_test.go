@@ -0,0 +1,140 @@
+package optimizer
+
+import (
+	"errors"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/plan"
+	"testing"
+)
+
+// renameScanRule rewrites every scan of a table to scan another table.
+type renameScanRule struct {
+	from, to string
+	applied  int
+}
+
+func (r *renameScanRule) Name() string { return "rename_scan" }
+
+func (r *renameScanRule) Match(node plan.PlanNode) bool {
+	scan, ok := node.(*plan.ScanNode)
+	return ok && scan.TableName == r.from
+}
+
+func (r *renameScanRule) Apply(_ *transaction.TransactionContext, node plan.PlanNode) (plan.PlanNode, error) {
+	r.applied++
+	return &plan.ScanNode{TableName: r.to}, nil
+}
+
+// wrapRule wraps the root of the plan in a Materialize node.
+type wrapRule struct{}
+
+func (wrapRule) Name() string { return "wrap" }
+
+func (wrapRule) Match(node plan.PlanNode) bool {
+	_, ok := node.(*plan.ProjectNode)
+	return ok
+}
+
+func (wrapRule) Apply(_ *transaction.TransactionContext, node plan.PlanNode) (plan.PlanNode, error) {
+	return &plan.MaterializeNode{Child: node, BasePlanNode: plan.BasePlanNode{Children: []plan.PlanNode{node}}}, nil
+}
+
+type failingRule struct{}
+
+func (failingRule) Name() string             { return "failing" }
+func (failingRule) Match(plan.PlanNode) bool { return true }
+func (failingRule) Apply(*transaction.TransactionContext, plan.PlanNode) (plan.PlanNode, error) {
+	return nil, errors.New("boom")
+}
+
+func newJoinPlan() *plan.ProjectNode {
+	join := &plan.JoinNode{
+		LeftChild:  &plan.ScanNode{TableName: "a"},
+		RightChild: &plan.ScanNode{TableName: "b"},
+	}
+	filter := &plan.FilterNode{Child: join}
+	return &plan.ProjectNode{Child: filter}
+}
+
+func TestApplyRule_ReplacesNestedNodesAndCopiesParents(t *testing.T) {
+	root := newJoinPlan()
+	rule := &renameScanRule{from: "b", to: "c"}
+
+	rewritten, err := ApplyRule(nil, rule, root)
+	if err != nil {
+		t.Fatalf("ApplyRule failed: %v", err)
+	}
+	if rule.applied != 1 {
+		t.Errorf("expected the rule to be applied once, got %d", rule.applied)
+	}
+
+	project, ok := rewritten.(*plan.ProjectNode)
+	if !ok || project == root {
+		t.Fatalf("expected a copy of the projection, got %T", rewritten)
+	}
+	join := project.Child.(*plan.FilterNode).Child.(*plan.JoinNode)
+	if got := join.RightChild.(*plan.ScanNode).TableName; got != "c" {
+		t.Errorf("expected the right scan to be rewritten to c, got %s", got)
+	}
+	if got := join.GetChildren()[1].(*plan.ScanNode).TableName; got != "c" {
+		t.Errorf("expected GetChildren to see the rewritten scan, got %s", got)
+	}
+	if join.LeftChild != root.Child.(*plan.FilterNode).Child.(*plan.JoinNode).LeftChild {
+		t.Error("expected the untouched left scan to be shared")
+	}
+	if got := root.Child.(*plan.FilterNode).Child.(*plan.JoinNode).RightChild.(*plan.ScanNode).TableName; got != "b" {
+		t.Errorf("expected the original plan to be unchanged, got %s", got)
+	}
+}
+
+func TestApplyRule_DoesNotRevisitReplacement(t *testing.T) {
+	rewritten, err := ApplyRule(nil, wrapRule{}, newJoinPlan())
+	if err != nil {
+		t.Fatalf("ApplyRule failed: %v", err)
+	}
+
+	materialize, ok := rewritten.(*plan.MaterializeNode)
+	if !ok {
+		t.Fatalf("expected a Materialize root, got %T", rewritten)
+	}
+	if _, ok := materialize.Child.(*plan.ProjectNode); !ok {
+		t.Errorf("expected the projection to be wrapped once, got %T", materialize.Child)
+	}
+}
+
+func TestApplyRule_ReportsRuleErrors(t *testing.T) {
+	if _, err := ApplyRule(nil, failingRule{}, newJoinPlan()); err == nil {
+		t.Fatal("expected the rule's error")
+	}
+}
+
+func TestRuleRegistry_RegisterAndUnregister(t *testing.T) {
+	r := NewRuleRegistry()
+	if err := r.Register(wrapRule{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register(&renameScanRule{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register(wrapRule{}); err == nil {
+		t.Error("expected an error registering a duplicate name")
+	}
+	if err := r.Register(nil); err == nil {
+		t.Error("expected an error registering a nil rule")
+	}
+
+	rules := r.Rules()
+	if len(rules) != 2 || rules[0].Name() != "wrap" || rules[1].Name() != "rename_scan" {
+		t.Fatalf("expected rules in registration order, got %v", rules)
+	}
+
+	r.Unregister("WRAP")
+	if rules := r.Rules(); len(rules) != 1 || rules[0].Name() != "rename_scan" {
+		t.Errorf("expected only rename_scan after Unregister, got %v", rules)
+	}
+
+	var none *RuleRegistry
+	if len(none.Rules()) != 0 {
+		t.Error("expected a nil registry to have no rules")
+	}
+}
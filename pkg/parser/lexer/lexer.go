@@ -1,23 +1,46 @@
 package lexer
 
 import (
+	"strconv"
 	"strings"
 	"unicode"
 )
 
 type Lexer struct {
-	input  string
-	pos    int
-	length int
+	input    string
+	original string // Input before upper-casing, used by Raw
+	pos      int
+	length   int
+
+	// Bind parameters are written either $1, $2, ... or ?, numbered in order
+	// of appearance. anonymous maps the position of each ? to its number, so
+	// a ? read again after SetPos keeps its number. A query may not mix the
+	// two styles.
+	anonymous  map[int]int
+	positional bool
 }
 
 func NewLexer(input string) *Lexer {
-	processedInput := strings.ToUpper(strings.TrimSpace(input))
+	original := strings.TrimSpace(input)
+	processedInput := strings.ToUpper(original)
 	return &Lexer{
-		input:  processedInput,
-		pos:    0,
-		length: len(processedInput),
+		input:    processedInput,
+		original: original,
+		pos:      0,
+		length:   len(processedInput),
+	}
+}
+
+// Raw returns the value of a STRING token as it was written, before the input
+// was upper-cased. Values such as file paths need their original case. If
+// upper-casing changed the length of the input, positions no longer line up
+// and the upper-cased value is returned.
+func (l *Lexer) Raw(token Token) string {
+	if token.Type != STRING || len(l.original) != len(l.input) {
+		return token.Value
 	}
+	start := token.Position + 1 // Skip the opening quote
+	return l.original[start : start+len(token.Value)]
 }
 
 func (l *Lexer) SetPos(pos int) {
@@ -60,6 +83,10 @@ func (l *Lexer) NextToken() Token {
 		return l.readOperator(start)
 	case ch == '\'' || ch == '"':
 		return l.readString(start)
+	case ch == '$':
+		return l.readPositionalParameter(start)
+	case ch == '?':
+		return l.readAnonymousParameter(start)
 	case unicode.IsDigit(rune(ch)):
 		return l.readNumber(start)
 	case unicode.IsLetter(rune(ch)) || ch == '_':
@@ -111,6 +138,42 @@ func (l *Lexer) readNumber(start int) Token {
 	return createToken(INT, value, start)
 }
 
+// readPositionalParameter reads a $N bind parameter. The PARAMETER token's
+// value is N.
+func (l *Lexer) readPositionalParameter(start int) Token {
+	l.pos++ // Skip $
+	digits := l.pos
+	for l.pos < l.length && unicode.IsDigit(rune(l.input[l.pos])) {
+		l.pos++
+	}
+
+	if l.pos == digits || len(l.anonymous) > 0 {
+		return createToken(INVALID, l.input[start:l.pos], start)
+	}
+	l.positional = true
+	return createToken(PARAMETER, l.input[digits:l.pos], start)
+}
+
+// readAnonymousParameter reads a ? bind parameter. The PARAMETER token's
+// value is the parameter's number: 1 for the first ? of the query, 2 for the
+// second, and so on.
+func (l *Lexer) readAnonymousParameter(start int) Token {
+	l.pos++
+	if l.positional {
+		return createToken(INVALID, "?", start)
+	}
+
+	if l.anonymous == nil {
+		l.anonymous = make(map[int]int)
+	}
+	n, ok := l.anonymous[start]
+	if !ok {
+		n = len(l.anonymous) + 1
+		l.anonymous[start] = n
+	}
+	return createToken(PARAMETER, strconv.Itoa(n), start)
+}
+
 func createToken(t TokenType, value string, start int) Token {
 	return Token{
 		Type:     t,
@@ -145,10 +208,24 @@ func (l *Lexer) readIdentifier(start int) Token {
 		return createToken(RIGHT, value, start)
 	case "OUTER":
 		return createToken(OUTER, value, start)
+	case "FULL":
+		return createToken(FULL, value, start)
+	case "CROSS":
+		return createToken(CROSS, value, start)
+	case "LATERAL":
+		return createToken(LATERAL, value, start)
+	case "IN":
+		return createToken(IN, value, start)
+	case "WITH":
+		return createToken(WITH, value, start)
+	case "RECURSIVE":
+		return createToken(RECURSIVE, value, start)
 	case "ON":
 		return createToken(ON, value, start)
 	case "GROUP":
 		return createToken(GROUP, value, start)
+	case "HAVING":
+		return createToken(HAVING, value, start)
 	case "BY":
 		return createToken(BY, value, start)
 	case "ORDER":
@@ -165,6 +242,9 @@ func (l *Lexer) readIdentifier(start int) Token {
 		return createToken(AND, value, start)
 	case "OR":
 		return createToken(OR, value, start)
+	case "LIKE", "ILIKE", "REGEXP":
+		// Pattern operators are parsed like the symbolic comparison operators
+		return createToken(OPERATOR, value, start)
 
 	case "CREATE":
 		return createToken(CREATE, value, start)
@@ -238,6 +318,48 @@ func (l *Lexer) readIdentifier(start int) Token {
 		return createToken(SHOW, value, start)
 	case "INDEXES":
 		return createToken(INDEXES, value, start)
+	case "PERSISTENT":
+		return createToken(PERSISTENT, value, start)
+
+	case "FOREIGN":
+		return createToken(FOREIGN, value, start)
+	case "OPTIONS":
+		return createToken(OPTIONS, value, start)
+	case "COPY":
+		return createToken(COPY, value, start)
+	case "CHECKSUM":
+		return createToken(CHECKSUM, value, start)
+	case "ALTER":
+		return createToken(ALTER, value, start)
+
+	case "TRIGGER":
+		return createToken(TRIGGER, value, start)
+	case "BEFORE":
+		return createToken(BEFORE, value, start)
+	case "AFTER":
+		return createToken(AFTER, value, start)
+	case "EXECUTE":
+		return createToken(EXECUTE, value, start)
+	case "FUNCTION":
+		return createToken(FUNCTION, value, start)
+
+	case "DATABASE":
+		return createToken(DATABASE, value, start)
+	case "USE":
+		return createToken(USE, value, start)
+
+	case "CASE":
+		return createToken(CASE, value, start)
+	case "WHEN":
+		return createToken(WHEN, value, start)
+	case "THEN":
+		return createToken(THEN, value, start)
+	case "ELSE":
+		return createToken(ELSE, value, start)
+	case "END":
+		return createToken(END, value, start)
+	case "AS":
+		return createToken(AS, value, start)
 
 	// Data type keywords
 	case "INT", "INTEGER":
@@ -104,6 +104,27 @@ func TestLexerKeywords(t *testing.T) {
 				{Type: EOF, Value: "", Position: 24},
 			},
 		},
+		{
+			input: "GROUP BY HAVING",
+			expected: []Token{
+				{Type: GROUP, Value: "GROUP", Position: 0},
+				{Type: BY, Value: "BY", Position: 6},
+				{Type: HAVING, Value: "HAVING", Position: 9},
+				{Type: EOF, Value: "", Position: 15},
+			},
+		},
+		{
+			input: "CASE WHEN THEN ELSE END AS",
+			expected: []Token{
+				{Type: CASE, Value: "CASE", Position: 0},
+				{Type: WHEN, Value: "WHEN", Position: 5},
+				{Type: THEN, Value: "THEN", Position: 10},
+				{Type: ELSE, Value: "ELSE", Position: 15},
+				{Type: END, Value: "END", Position: 20},
+				{Type: AS, Value: "AS", Position: 24},
+				{Type: EOF, Value: "", Position: 26},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -301,6 +322,15 @@ func TestLexerOperators(t *testing.T) {
 				{Type: EOF, Value: "", Position: 14},
 			},
 		},
+		{
+			input: "like ILike REGEXP",
+			expected: []Token{
+				{Type: OPERATOR, Value: "LIKE", Position: 0},
+				{Type: OPERATOR, Value: "ILIKE", Position: 5},
+				{Type: OPERATOR, Value: "REGEXP", Position: 11},
+				{Type: EOF, Value: "", Position: 17},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -485,6 +515,75 @@ func TestLexerWhitespace(t *testing.T) {
 	}
 }
 
+func TestLexerParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Token
+	}{
+		{
+			input: "$1 = $12",
+			expected: []Token{
+				{Type: PARAMETER, Value: "1", Position: 0},
+				{Type: OPERATOR, Value: "=", Position: 3},
+				{Type: PARAMETER, Value: "12", Position: 5},
+				{Type: EOF, Value: "", Position: 8},
+			},
+		},
+		{
+			input: "(?, '?', ?)",
+			expected: []Token{
+				{Type: LPAREN, Value: "(", Position: 0},
+				{Type: PARAMETER, Value: "1", Position: 1},
+				{Type: COMMA, Value: ",", Position: 2},
+				{Type: STRING, Value: "?", Position: 4},
+				{Type: COMMA, Value: ",", Position: 7},
+				{Type: PARAMETER, Value: "2", Position: 9},
+				{Type: RPAREN, Value: ")", Position: 10},
+			},
+		},
+		{
+			input: "$1 ?",
+			expected: []Token{
+				{Type: PARAMETER, Value: "1", Position: 0},
+				{Type: INVALID, Value: "?", Position: 3},
+			},
+		},
+		{
+			input: "? $1 $X",
+			expected: []Token{
+				{Type: PARAMETER, Value: "1", Position: 0},
+				{Type: INVALID, Value: "$1", Position: 2},
+				{Type: INVALID, Value: "$", Position: 5},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		lexer := NewLexer(test.input)
+		for i, expected := range test.expected {
+			token := lexer.NextToken()
+			if token.Type != expected.Type {
+				t.Errorf("Test %s, token %d: expected type %s, got %s", test.input, i, expected.Type, token.Type)
+			}
+			if token.Value != expected.Value {
+				t.Errorf("Test %s, token %d: expected value %s, got %s", test.input, i, expected.Value, token.Value)
+			}
+			if token.Position != expected.Position {
+				t.Errorf("Test %s, token %d: expected position %d, got %d", test.input, i, expected.Position, token.Position)
+			}
+		}
+	}
+
+	// A ? read again after SetPos keeps its number
+	lexer := NewLexer("? ?")
+	lexer.NextToken()
+	second := lexer.NextToken()
+	lexer.SetPos(second.Position)
+	if again := lexer.NextToken(); again.Value != "2" {
+		t.Errorf("expected second ? to stay parameter 2, got %s", again.Value)
+	}
+}
+
 func TestLexerInvalidToken(t *testing.T) {
 	input := "@#$"
 	expected := []Token{
@@ -23,8 +23,15 @@ const (
 	LEFT
 	RIGHT
 	OUTER
+	FULL
+	CROSS
+	LATERAL
+	IN
+	WITH
+	RECURSIVE
 	ON
 	GROUP
+	HAVING
 	ORDER
 	BY
 	LIMIT
@@ -61,6 +68,29 @@ const (
 
 	SHOW
 	INDEXES
+	PERSISTENT
+
+	FOREIGN
+	OPTIONS
+	COPY
+	CHECKSUM
+	ALTER
+
+	TRIGGER
+	BEFORE
+	AFTER
+	EXECUTE
+	FUNCTION
+
+	DATABASE
+	USE
+
+	CASE
+	WHEN
+	THEN
+	ELSE
+	END
+	AS
 
 	INT
 	VARCHAR
@@ -70,6 +100,7 @@ const (
 	OPERATOR
 	STRING
 	IDENTIFIER
+	PARAMETER
 
 	COMMA
 	SEMICOLON
@@ -123,10 +154,24 @@ func (t TokenType) String() string {
 		return "RIGHT"
 	case OUTER:
 		return "OUTER"
+	case FULL:
+		return "FULL"
+	case CROSS:
+		return "CROSS"
+	case LATERAL:
+		return "LATERAL"
+	case IN:
+		return "IN"
+	case WITH:
+		return "WITH"
+	case RECURSIVE:
+		return "RECURSIVE"
 	case ON:
 		return "ON"
 	case GROUP:
 		return "GROUP"
+	case HAVING:
+		return "HAVING"
 	case ORDER:
 		return "ORDER"
 	case BY:
@@ -189,6 +234,44 @@ func (t TokenType) String() string {
 		return "SHOW"
 	case INDEXES:
 		return "INDEXES"
+	case PERSISTENT:
+		return "PERSISTENT"
+	case FOREIGN:
+		return "FOREIGN"
+	case OPTIONS:
+		return "OPTIONS"
+	case COPY:
+		return "COPY"
+	case CHECKSUM:
+		return "CHECKSUM"
+	case ALTER:
+		return "ALTER"
+	case TRIGGER:
+		return "TRIGGER"
+	case BEFORE:
+		return "BEFORE"
+	case AFTER:
+		return "AFTER"
+	case EXECUTE:
+		return "EXECUTE"
+	case FUNCTION:
+		return "FUNCTION"
+	case DATABASE:
+		return "DATABASE"
+	case USE:
+		return "USE"
+	case CASE:
+		return "CASE"
+	case WHEN:
+		return "WHEN"
+	case THEN:
+		return "THEN"
+	case ELSE:
+		return "ELSE"
+	case END:
+		return "END"
+	case AS:
+		return "AS"
 	case INT:
 		return "INT"
 	case VARCHAR:
@@ -205,6 +288,8 @@ func (t TokenType) String() string {
 		return "STRING"
 	case IDENTIFIER:
 		return "IDENTIFIER"
+	case PARAMETER:
+		return "PARAMETER"
 	case COMMA:
 		return "COMMA"
 	case SEMICOLON:
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+)
+
+// parseAlterTableStatement parses an ALTER TABLE statement. Turning row
+// change auditing on or off and setting the buffer pool priority of the
+// table's pages are the changes it supports.
+// Expects one of the formats:
+//
+//	ALTER TABLE table_name {ENABLE|DISABLE} AUDIT
+//	ALTER TABLE table_name SET CACHE {PINNED|HIGH|NORMAL}
+//
+// ENABLE, DISABLE, AUDIT, CACHE and the priorities are not reserved words,
+// so they are matched as identifiers.
+func parseAlterTableStatement(l *lexer.Lexer) (statements.Statement, error) {
+	if err := expectTokenSequence(l, lexer.ALTER, lexer.TABLE); err != nil {
+		return nil, err
+	}
+
+	tableName, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected table name: %w", err)
+	}
+
+	action := l.NextToken()
+	if action.Type == lexer.SET {
+		return parseAlterTableCache(l, tableName)
+	}
+	if action.Type != lexer.IDENTIFIER || (action.Value != "ENABLE" && action.Value != "DISABLE") {
+		return nil, fmt.Errorf("expected ENABLE AUDIT, DISABLE AUDIT or SET CACHE after ALTER TABLE %s, got %s", tableName, action.Value)
+	}
+	if token := l.NextToken(); token.Type != lexer.IDENTIFIER || token.Value != "AUDIT" {
+		return nil, fmt.Errorf("expected AUDIT after %s, got %s", action.Value, token.Value)
+	}
+	if end := l.NextToken(); end.Type != lexer.EOF && end.Type != lexer.SEMICOLON {
+		return nil, fmt.Errorf("unexpected %s after AUDIT", end.Value)
+	}
+
+	stmt := statements.NewAlterTableAuditStatement(tableName, action.Value == "ENABLE")
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseAlterTableCache parses the rest of ALTER TABLE table_name SET CACHE,
+// after SET.
+func parseAlterTableCache(l *lexer.Lexer, tableName string) (*statements.AlterTableCacheStatement, error) {
+	if token := l.NextToken(); token.Type != lexer.IDENTIFIER || token.Value != "CACHE" {
+		return nil, fmt.Errorf("expected CACHE after SET, got %s", token.Value)
+	}
+	priority := l.NextToken()
+	if priority.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected PINNED, HIGH or NORMAL after CACHE, got %s", priority.Value)
+	}
+	if end := l.NextToken(); end.Type != lexer.EOF && end.Type != lexer.SEMICOLON {
+		return nil, fmt.Errorf("unexpected %s after %s", end.Value, priority.Value)
+	}
+
+	stmt := statements.NewAlterTableCacheStatement(tableName, priority.Value)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
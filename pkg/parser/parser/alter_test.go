@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"storemy/pkg/parser/statements"
+	"testing"
+)
+
+func TestParseStatement_AlterTableAudit(t *testing.T) {
+	tests := []struct {
+		sql    string
+		enable bool
+	}{
+		{"ALTER TABLE orders ENABLE AUDIT", true},
+		{"alter table orders disable audit;", false},
+	}
+
+	for _, tt := range tests {
+		stmt, err := ParseStatement(tt.sql)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.sql, err)
+		}
+		alterStmt, ok := stmt.(*statements.AlterTableAuditStatement)
+		if !ok {
+			t.Fatalf("%s: expected AlterTableAuditStatement, got %T", tt.sql, stmt)
+		}
+		if alterStmt.TableName != "ORDERS" || alterStmt.Enable != tt.enable {
+			t.Errorf("%s: got table %s, enable %v", tt.sql, alterStmt.TableName, alterStmt.Enable)
+		}
+	}
+}
+
+func TestParseStatement_AlterTableAuditErrors(t *testing.T) {
+	for _, sql := range []string{
+		"ALTER orders ENABLE AUDIT",
+		"ALTER TABLE ENABLE AUDIT",
+		"ALTER TABLE orders ENABLE",
+		"ALTER TABLE orders START AUDIT",
+		"ALTER TABLE orders ENABLE AUDIT now",
+	} {
+		if _, err := ParseStatement(sql); err == nil {
+			t.Errorf("%s: expected an error", sql)
+		}
+	}
+}
+
+func TestParseStatement_AlterTableCache(t *testing.T) {
+	for _, tt := range []struct {
+		sql      string
+		priority string
+	}{
+		{"ALTER TABLE regions SET CACHE PINNED", "PINNED"},
+		{"alter table regions set cache high;", "HIGH"},
+		{"ALTER TABLE regions SET CACHE normal", "NORMAL"},
+	} {
+		stmt, err := ParseStatement(tt.sql)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.sql, err)
+		}
+		cacheStmt, ok := stmt.(*statements.AlterTableCacheStatement)
+		if !ok {
+			t.Fatalf("%s: expected AlterTableCacheStatement, got %T", tt.sql, stmt)
+		}
+		if cacheStmt.TableName != "REGIONS" || cacheStmt.Priority != tt.priority {
+			t.Errorf("%s: got table %s, priority %s", tt.sql, cacheStmt.TableName, cacheStmt.Priority)
+		}
+	}
+
+	for _, sql := range []string{
+		"ALTER TABLE regions SET PINNED",
+		"ALTER TABLE regions SET CACHE",
+		"ALTER TABLE regions SET CACHE STICKY",
+		"ALTER TABLE regions SET CACHE PINNED now",
+	} {
+		if _, err := ParseStatement(sql); err == nil {
+			t.Errorf("%s: expected an error", sql)
+		}
+	}
+}
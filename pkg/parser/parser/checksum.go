@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+)
+
+// parseChecksumStatement parses a CHECKSUM TABLE statement.
+// Expects the format:
+//
+//	CHECKSUM TABLE table_name [, table_name ...]
+func parseChecksumStatement(l *lexer.Lexer) (*statements.ChecksumTableStatement, error) {
+	if err := expectTokenSequence(l, lexer.CHECKSUM, lexer.TABLE); err != nil {
+		return nil, err
+	}
+
+	var tableNames []string
+	for {
+		tableName, err := parseValueWithType(l, lexer.IDENTIFIER)
+		if err != nil {
+			return nil, fmt.Errorf("expected table name: %w", err)
+		}
+		tableNames = append(tableNames, tableName)
+
+		token := l.NextToken()
+		if token.Type == lexer.COMMA {
+			continue
+		}
+		if token.Type != lexer.EOF && token.Type != lexer.SEMICOLON {
+			return nil, fmt.Errorf("expected ',' or end of statement, got %s", token.Value)
+		}
+		break
+	}
+
+	stmt := statements.NewChecksumTableStatement(tableNames)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"slices"
+	"storemy/pkg/parser/statements"
+	"testing"
+)
+
+func TestParseStatement_ChecksumTable(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected []string
+	}{
+		{"CHECKSUM TABLE users", []string{"USERS"}},
+		{"CHECKSUM TABLE users, orders;", []string{"USERS", "ORDERS"}},
+	}
+
+	for _, tt := range tests {
+		stmt, err := ParseStatement(tt.sql)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.sql, err)
+		}
+		checksumStmt, ok := stmt.(*statements.ChecksumTableStatement)
+		if !ok {
+			t.Fatalf("%s: expected ChecksumTableStatement, got %T", tt.sql, stmt)
+		}
+		if !slices.Equal(checksumStmt.TableNames, tt.expected) {
+			t.Errorf("%s: expected tables %v, got %v", tt.sql, tt.expected, checksumStmt.TableNames)
+		}
+	}
+}
+
+func TestParseStatement_ChecksumTableErrors(t *testing.T) {
+	for _, sql := range []string{
+		"CHECKSUM users",
+		"CHECKSUM TABLE",
+		"CHECKSUM TABLE users,",
+		"CHECKSUM TABLE users orders",
+	} {
+		if _, err := ParseStatement(sql); err == nil {
+			t.Errorf("%s: expected error", sql)
+		}
+	}
+}
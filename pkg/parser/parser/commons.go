@@ -2,11 +2,14 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
 	"slices"
 	"storemy/pkg/parser/lexer"
 	"storemy/pkg/primitives"
 	"storemy/pkg/types"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 // expectToken validates that the given token matches the expected token type.
@@ -38,8 +41,19 @@ func parseValue(l *lexer.Lexer) (types.Field, error) {
 	}
 }
 
+// parseParameter returns the number of the bind parameter a PARAMETER token
+// stands for.
+func parseParameter(token lexer.Token) (int, error) {
+	index, err := strconv.Atoi(token.Value)
+	if err != nil || index < 1 {
+		return 0, fmt.Errorf("invalid parameter $%s: parameters are numbered from $1", token.Value)
+	}
+	return index, nil
+}
+
 // parseOperator converts a string operator into a Predicate type.
 // Supports standard comparison operators: =, >, <, >=, <=, !=, <>
+// and the pattern operators LIKE, ILIKE and REGEXP.
 func parseOperator(op string) (primitives.Predicate, error) {
 	switch op {
 	case "=":
@@ -54,11 +68,68 @@ func parseOperator(op string) (primitives.Predicate, error) {
 		return primitives.LessThanOrEqual, nil
 	case "!=", "<>":
 		return primitives.NotEqual, nil
+	case "LIKE":
+		return primitives.Like, nil
+	case "ILIKE":
+		return primitives.ILike, nil
+	case "REGEXP":
+		return primitives.Regexp, nil
 	default:
 		return primitives.Equals, fmt.Errorf("unknown operator: %s", op)
 	}
 }
 
+// parseConditionValue returns the value a condition compares against.
+// Pattern operators require a string literal. A REGEXP pattern is compiled
+// here so a bad pattern is reported as a syntax error rather than per row.
+func parseConditionValue(l *lexer.Lexer, token lexer.Token, pred primitives.Predicate) (string, error) {
+	if !pred.IsPatternMatch() {
+		return token.Value, nil
+	}
+
+	if token.Type != lexer.STRING {
+		return "", fmt.Errorf("%s requires a string pattern, got %s", pred, token.Value)
+	}
+	if pred != primitives.Regexp {
+		return token.Value, nil
+	}
+
+	pattern := regexpPattern(l.Raw(token))
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", fmt.Errorf("invalid regular expression %s: %v", token.Value, err)
+	}
+	return pattern, nil
+}
+
+// regexpPattern upper-cases a REGEXP pattern the way the lexer upper-cases
+// every other literal, so it matches the stored values. Letters after a
+// backslash (\d, \s, \b, ...) and inside flag groups such as (?i) keep their
+// case, since upper-casing them would change their meaning.
+func regexpPattern(raw string) string {
+	var sb strings.Builder
+	r := []rune(raw)
+	inFlags := false
+
+	for i := 0; i < len(r); i++ {
+		switch c := r[i]; {
+		case c == '\\' && i+1 < len(r):
+			sb.WriteRune(c)
+			i++
+			sb.WriteRune(r[i])
+		case c == '(' && i+1 < len(r) && r[i+1] == '?':
+			sb.WriteString("(?")
+			i++
+			inFlags = true
+		case inFlags:
+			sb.WriteRune(c)
+			inFlags = c != ')' && c != ':' && c != '<'
+		default:
+			sb.WriteRune(unicode.ToUpper(c))
+		}
+	}
+	return sb.String()
+}
+
 // expectTokenSequence validates that the lexer produces a sequence of tokens
 // matching the expected token types in order.
 func expectTokenSequence(l *lexer.Lexer, expectedTypes ...lexer.TokenType) error {
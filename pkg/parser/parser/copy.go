@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/datagen"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+	"strconv"
+)
+
+// parseCopyStatement parses a COPY statement.
+// Expects the format:
+//
+//	COPY table_name FROM 'path' [OPTIONS (name 'value', ...)]
+//	COPY table_name FROM GENERATE_SERIES(start, stop[, step]) [OPTIONS (seed 'n')]
+//
+// The options are those of CREATE FOREIGN TABLE. The path and option values
+// keep their original case.
+func parseCopyStatement(l *lexer.Lexer) (*statements.CopyStatement, error) {
+	if err := expectTokenSequence(l, lexer.COPY); err != nil {
+		return nil, err
+	}
+
+	tableName, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected table name: %w", err)
+	}
+
+	if err := expectTokenSequence(l, lexer.FROM); err != nil {
+		return nil, err
+	}
+
+	var stmt *statements.CopyStatement
+	if sourceToken := l.NextToken(); sourceToken.Type == lexer.IDENTIFIER && sourceToken.Value == "GENERATE_SERIES" {
+		series, err := parseGenerateSeries(l)
+		if err != nil {
+			return nil, err
+		}
+		stmt = statements.NewCopySeriesStatement(tableName, series)
+	} else {
+		if err := expectToken(sourceToken, lexer.STRING); err != nil {
+			return nil, fmt.Errorf("expected quoted file path or GENERATE_SERIES: %w", err)
+		}
+		stmt = statements.NewCopyStatement(tableName, l.Raw(sourceToken))
+	}
+
+	if token := l.NextToken(); token.Type == lexer.OPTIONS {
+		if err := expectTokenSequence(l, lexer.LPAREN); err != nil {
+			return nil, err
+		}
+
+		options, err := parseDelimitedList(l, parseForeignOption, lexer.COMMA, lexer.RPAREN)
+		if err != nil {
+			return nil, err
+		}
+		for _, opt := range options {
+			if _, dup := stmt.Options[opt.name]; dup {
+				return nil, fmt.Errorf("option %s specified more than once", opt.name)
+			}
+			stmt.Options[opt.name] = opt.value
+		}
+	} else if token.Type != lexer.EOF && token.Type != lexer.SEMICOLON {
+		return nil, fmt.Errorf("expected OPTIONS or end of statement, got %s", token.Value)
+	}
+
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseGenerateSeries parses the arguments of GENERATE_SERIES, the name
+// already consumed: (start, stop[, step]), step defaulting to 1.
+func parseGenerateSeries(l *lexer.Lexer) (datagen.Series, error) {
+	if err := expectTokenSequence(l, lexer.LPAREN); err != nil {
+		return datagen.Series{}, err
+	}
+
+	args, err := parseDelimitedList(l, func(l *lexer.Lexer) (int64, error) {
+		value, err := parseValueWithType(l, lexer.INT)
+		if err != nil {
+			return 0, fmt.Errorf("expected integer argument to GENERATE_SERIES: %w", err)
+		}
+		return strconv.ParseInt(value, 10, 64)
+	}, lexer.COMMA, lexer.RPAREN)
+	if err != nil {
+		return datagen.Series{}, err
+	}
+
+	switch len(args) {
+	case 2:
+		return datagen.Series{Start: args[0], Stop: args[1], Step: 1}, nil
+	case 3:
+		return datagen.Series{Start: args[0], Stop: args[1], Step: args[2]}, nil
+	default:
+		return datagen.Series{}, fmt.Errorf("GENERATE_SERIES takes 2 or 3 arguments, got %d", len(args))
+	}
+}
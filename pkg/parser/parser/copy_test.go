@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"storemy/pkg/parser/statements"
+	"strings"
+	"testing"
+)
+
+// COPY statement tests
+func TestParseStatement_Copy(t *testing.T) {
+	stmt, err := ParseStatement("COPY users FROM '/Data/Users.csv' OPTIONS (format 'csv', header 'true', delimiter ';')")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	copyStmt, ok := stmt.(*statements.CopyStatement)
+	if !ok {
+		t.Fatalf("expected CopyStatement, got %T", stmt)
+	}
+
+	if copyStmt.TableName != "USERS" {
+		t.Errorf("expected table name 'USERS', got %s", copyStmt.TableName)
+	}
+
+	if copyStmt.Path != "/Data/Users.csv" {
+		t.Errorf("expected path '/Data/Users.csv', got %s", copyStmt.Path)
+	}
+
+	expected := map[string]string{"FORMAT": "csv", "HEADER": "true", "DELIMITER": ";"}
+	for name, value := range expected {
+		if copyStmt.Options[name] != value {
+			t.Errorf("expected option %s = %q, got %q", name, value, copyStmt.Options[name])
+		}
+	}
+
+	stmt, err = ParseStatement("COPY users FROM 'users.csv';")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if copyStmt := stmt.(*statements.CopyStatement); len(copyStmt.Options) != 0 {
+		t.Errorf("expected no options, got %v", copyStmt.Options)
+	}
+}
+
+func TestParseStatement_CopyGenerateSeries(t *testing.T) {
+	stmt, err := ParseStatement("COPY users FROM generate_series(1, 1000, 2) OPTIONS (seed '42')")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	copyStmt := stmt.(*statements.CopyStatement)
+	if copyStmt.Series == nil {
+		t.Fatal("expected a series")
+	}
+	if s := *copyStmt.Series; s.Start != 1 || s.Stop != 1000 || s.Step != 2 {
+		t.Errorf("expected series 1..1000 step 2, got %+v", s)
+	}
+	if copyStmt.Path != "" {
+		t.Errorf("expected no path, got %q", copyStmt.Path)
+	}
+	if copyStmt.Options["SEED"] != "42" {
+		t.Errorf("expected SEED 42, got %q", copyStmt.Options["SEED"])
+	}
+	if got := copyStmt.String(); got != "COPY USERS FROM GENERATE_SERIES(1, 1000, 2) OPTIONS (SEED '42')" {
+		t.Errorf("unexpected String(): %s", got)
+	}
+
+	stmt, err = ParseStatement("COPY users FROM GENERATE_SERIES(5, 10);")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if s := *stmt.(*statements.CopyStatement).Series; s.Step != 1 {
+		t.Errorf("expected default step 1, got %d", s.Step)
+	}
+}
+
+func TestParseStatement_CopyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		errMsg string
+	}{
+		{
+			name:   "Missing FROM",
+			sql:    "COPY users 'users.csv'",
+			errMsg: "expected FROM",
+		},
+		{
+			name:   "Unquoted path",
+			sql:    "COPY users FROM users",
+			errMsg: "expected quoted file path",
+		},
+		{
+			name:   "LOCATION option",
+			sql:    "COPY users FROM 'users.csv' OPTIONS (location 'other.csv')",
+			errMsg: "LOCATION",
+		},
+		{
+			name:   "Duplicate option",
+			sql:    "COPY users FROM 'users.csv' OPTIONS (format 'csv', format 'jsonl')",
+			errMsg: "specified more than once",
+		},
+		{
+			name:   "Series with one argument",
+			sql:    "COPY users FROM GENERATE_SERIES(10)",
+			errMsg: "takes 2 or 3 arguments",
+		},
+		{
+			name:   "Series with zero step",
+			sql:    "COPY users FROM GENERATE_SERIES(1, 10, 0)",
+			errMsg: "step cannot be zero",
+		},
+		{
+			name:   "Series with file option",
+			sql:    "COPY users FROM GENERATE_SERIES(1, 10) OPTIONS (header 'true')",
+			errMsg: "cannot be used with GENERATE_SERIES",
+		},
+		{
+			name:   "Trailing garbage",
+			sql:    "COPY users FROM 'users.csv' WHERE",
+			errMsg: "expected OPTIONS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(tt.sql)
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.errMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
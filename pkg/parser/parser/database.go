@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+)
+
+// parseCreateDatabaseStatement parses a CREATE DATABASE SQL statement from the lexer tokens.
+// Syntax: CREATE DATABASE [IF NOT EXISTS] database_name
+func parseCreateDatabaseStatement(l *lexer.Lexer) (*statements.CreateDatabaseStatement, error) {
+	if err := expectTokenSequence(l, lexer.CREATE, lexer.DATABASE); err != nil {
+		return nil, err
+	}
+
+	ifNotExists, err := parseIfNotExists(l)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected database name: %w", err)
+	}
+
+	stmt := statements.NewCreateDatabaseStatement(name, ifNotExists)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseUseStatement parses a USE SQL statement from the lexer tokens.
+// Syntax: USE database_name
+func parseUseStatement(l *lexer.Lexer) (*statements.UseStatement, error) {
+	if err := expectTokenSequence(l, lexer.USE); err != nil {
+		return nil, err
+	}
+
+	name, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected database name: %w", err)
+	}
+
+	stmt := statements.NewUseStatement(name)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"storemy/pkg/parser/statements"
+	"testing"
+)
+
+// CREATE DATABASE / USE statement tests
+func TestParseStatement_CreateDatabase(t *testing.T) {
+	stmt, err := ParseStatement("CREATE DATABASE shop")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	createStmt, ok := stmt.(*statements.CreateDatabaseStatement)
+	if !ok {
+		t.Fatalf("expected CreateDatabaseStatement, got %T", stmt)
+	}
+	if createStmt.DatabaseName != "SHOP" {
+		t.Errorf("expected database name 'SHOP', got %s", createStmt.DatabaseName)
+	}
+	if createStmt.IfNotExists {
+		t.Error("expected IfNotExists to be false")
+	}
+
+	stmt, err = ParseStatement("CREATE DATABASE IF NOT EXISTS shop")
+	if err != nil {
+		t.Fatalf("unexpected error with IF NOT EXISTS: %s", err.Error())
+	}
+	if s := stmt.(*statements.CreateDatabaseStatement); !s.IfNotExists || s.DatabaseName != "SHOP" {
+		t.Errorf("expected IF NOT EXISTS SHOP, got %s", s.String())
+	}
+}
+
+func TestParseStatement_Use(t *testing.T) {
+	stmt, err := ParseStatement("USE shop")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	useStmt, ok := stmt.(*statements.UseStatement)
+	if !ok {
+		t.Fatalf("expected UseStatement, got %T", stmt)
+	}
+	if useStmt.DatabaseName != "SHOP" {
+		t.Errorf("expected database name 'SHOP', got %s", useStmt.DatabaseName)
+	}
+}
+
+func TestParseStatement_DatabaseErrors(t *testing.T) {
+	for _, sql := range []string{
+		"CREATE DATABASE",
+		"CREATE DATABASE IF EXISTS shop",
+		"USE",
+		"USE 'shop'",
+	} {
+		if _, err := ParseStatement(sql); err == nil {
+			t.Errorf("expected %q to fail", sql)
+		}
+	}
+}
@@ -36,26 +36,57 @@ func parseDeleteStatement(l *lexer.Lexer) (*statements.DeleteStatement, error) {
 
 // parseWhereCondition parses a WHERE clause condition for filtering records.
 // It expects the format: field_name operator value
-// Currently supports simple conditions with a single field, operator, and constant value.
+// Currently supports simple conditions with a single field, operator, and constant value
+// or bind parameter ($1 or ?).
+// The operator may be a comparison or one of the pattern operators LIKE, ILIKE and REGEXP.
+// Conditions involving a CASE expression (CASE ... END = value, or field = CASE ... END)
+// are returned as expression filters.
 func parseWhereCondition(l *lexer.Lexer) (*plan.FilterNode, error) {
-	fieldName, err := parseValueWithType(l, lexer.IDENTIFIER)
-	if err != nil {
+	fieldToken := l.NextToken()
+	if fieldToken.Type == lexer.CASE {
+		expr, err := parseCaseCondition(l, fieldToken, nil)
+		if err != nil {
+			return nil, err
+		}
+		return plan.NewExprFilterNode("", expr), nil
+	}
+	if err := expectToken(fieldToken, lexer.IDENTIFIER); err != nil {
 		return nil, fmt.Errorf("expected field name in WHERE: %w", err)
 	}
+	fieldName := fieldToken.Value
 
-	opValue, err := parseValueWithType(l, lexer.OPERATOR)
-	if err != nil {
+	opToken := l.NextToken()
+	if err := expectToken(opToken, lexer.OPERATOR); err != nil {
 		return nil, fmt.Errorf("expected operator in WHERE: %w", err)
 	}
 
-	pred, err := parseOperator(opValue)
+	pred, err := parseOperator(opToken.Value)
 	if err != nil {
 		return nil, err
 	}
 
-	constant, err := parseValueWithType(l, lexer.STRING, lexer.INT)
+	token := l.NextToken()
+	if token.Type == lexer.CASE {
+		expr, err := parseCaseCondition(l, fieldToken, &opToken)
+		if err != nil {
+			return nil, err
+		}
+		return plan.NewExprFilterNode("", expr), nil
+	}
+	if token.Type == lexer.PARAMETER {
+		param, err := parseParameter(token)
+		if err != nil {
+			return nil, err
+		}
+		return plan.NewParamFilterNode("", fieldName, pred, param), nil
+	}
+	if token.Type != lexer.STRING && token.Type != lexer.INT {
+		return nil, fmt.Errorf("expected value in WHERE: expected value of type %v, got %s", []lexer.TokenType{lexer.STRING, lexer.INT}, token.Value)
+	}
+
+	constant, err := parseConditionValue(l, token, pred)
 	if err != nil {
-		return nil, fmt.Errorf("expected value in WHERE: %w", err)
+		return nil, err
 	}
 
 	return plan.NewFilterNode("", fieldName, pred, constant), nil
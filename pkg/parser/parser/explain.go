@@ -11,11 +11,14 @@ import (
 //
 // Syntax:
 //
-//	EXPLAIN [ANALYZE] [FORMAT {TEXT|JSON}] <statement>
+//	EXPLAIN [ANALYZE [VERBOSE]] [FORMAT {TEXT|JSON|EDUCATIONAL}] <statement>
 //
 // Options:
 //   - ANALYZE: If specified, executes the statement and includes actual execution statistics
-//   - FORMAT: Specifies the output format (TEXT or JSON), defaults to TEXT
+//   - VERBOSE: With ANALYZE, also includes the query's trace (parse, plan, operators, waits)
+//   - FORMAT: Specifies the output format (TEXT, JSON or EDUCATIONAL), defaults to TEXT.
+//     EDUCATIONAL explains each plan step; with ANALYZE it also walks through
+//     the execution one operator call at a time
 //
 // Supported statements:
 //   - SELECT
@@ -50,15 +53,18 @@ func parseExplainStatement(l *lexer.Lexer) (statements.Statement, error) {
 	return statements.NewExplainStatement(stmt, options), nil
 }
 
-// parseExplainOptions parses the optional ANALYZE and FORMAT clauses of an EXPLAIN statement.
-// Both options are optional and can appear in any order.
+// parseExplainOptions parses the optional ANALYZE, VERBOSE and FORMAT clauses of an EXPLAIN statement.
+// All options are optional but must appear in the order shown.
 //
 // Syntax:
 //
-//	[ANALYZE] [FORMAT {TEXT|JSON}]
+//	[ANALYZE [VERBOSE]] [FORMAT {TEXT|JSON|EDUCATIONAL}]
+//
+// VERBOSE is matched as an identifier so that it stays usable as a column or table name.
 //
 // Default values:
 //   - Analyze: false
+//   - Verbose: false
 //   - Format: "TEXT"
 //
 // Parameters:
@@ -66,7 +72,7 @@ func parseExplainStatement(l *lexer.Lexer) (statements.Statement, error) {
 //
 // Returns:
 //   - statements.ExplainOptions: Structure containing the parsed options
-//   - error: Returns an error if FORMAT type is invalid (not TEXT or JSON)
+//   - error: Returns an error if FORMAT type is invalid (not TEXT, JSON or EDUCATIONAL)
 func parseExplainOptions(l *lexer.Lexer) (statements.ExplainOptions, error) {
 	options := statements.ExplainOptions{
 		Analyze: false,
@@ -78,6 +84,11 @@ func parseExplainOptions(l *lexer.Lexer) (statements.ExplainOptions, error) {
 	if token.Type == lexer.ANALYZE {
 		options.Analyze = true
 		token = l.NextToken()
+
+		if token.Type == lexer.IDENTIFIER && token.Value == "VERBOSE" {
+			options.Verbose = true
+			token = l.NextToken()
+		}
 	}
 
 	if token.Type == lexer.FORMAT {
@@ -86,10 +97,10 @@ func parseExplainOptions(l *lexer.Lexer) (statements.ExplainOptions, error) {
 
 		if formatToken.Type == lexer.TEXT {
 			formatType = "TEXT"
-		} else if formatToken.Type == lexer.IDENTIFIER && formatToken.Value == "JSON" {
-			formatType = "JSON"
+		} else if formatToken.Type == lexer.IDENTIFIER && (formatToken.Value == "JSON" || formatToken.Value == "EDUCATIONAL") {
+			formatType = formatToken.Value
 		} else {
-			return options, fmt.Errorf("invalid format type: %s (must be TEXT or JSON)", formatToken.Value)
+			return options, fmt.Errorf("invalid format type: %s (must be TEXT, JSON or EDUCATIONAL)", formatToken.Value)
 		}
 
 		options.Format = formatType
@@ -104,7 +115,7 @@ func parseExplainOptions(l *lexer.Lexer) (statements.ExplainOptions, error) {
 // This function determines the type of statement and delegates to the appropriate parser.
 //
 // Supported statement types:
-//   - SELECT: Query data from tables
+//   - SELECT: Query data from tables, with or without a WITH clause
 //   - INSERT: Insert data into tables
 //   - UPDATE: Update existing data
 //   - DELETE: Delete data from tables
@@ -125,6 +136,8 @@ func parseExplainableStatement(l *lexer.Lexer) (statements.Statement, error) {
 	switch token.Type {
 	case lexer.SELECT:
 		return parseSelectStatement(l)
+	case lexer.WITH:
+		return parseWithStatement(l)
 	case lexer.INSERT:
 		return parseInsertStatement(l)
 	case lexer.UPDATE:
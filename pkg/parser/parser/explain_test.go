@@ -201,6 +201,27 @@ func TestParseExplainAnalyzeFormatText(t *testing.T) {
 	}
 }
 
+func TestParseExplainAnalyzeFormatEducational(t *testing.T) {
+	sql := "EXPLAIN ANALYZE FORMAT EDUCATIONAL SELECT * FROM users"
+	stmt, err := ParseStatement(sql)
+	if err != nil {
+		t.Fatalf("Failed to parse EXPLAIN ANALYZE FORMAT EDUCATIONAL: %v", err)
+	}
+
+	explainStmt, ok := stmt.(*statements.ExplainStatement)
+	if !ok {
+		t.Fatalf("Expected ExplainStatement, got %T", stmt)
+	}
+
+	if !explainStmt.GetOptions().Analyze {
+		t.Error("ANALYZE should be true")
+	}
+
+	if explainStmt.GetOptions().Format != "EDUCATIONAL" {
+		t.Errorf("Expected format EDUCATIONAL, got %s", explainStmt.GetOptions().Format)
+	}
+}
+
 func TestParseExplainComplexSelect(t *testing.T) {
 	sql := "EXPLAIN ANALYZE FORMAT JSON SELECT u.id, u.name, o.total FROM users u JOIN orders o ON u.id = o.user_id WHERE u.age > 21 ORDER BY o.total DESC LIMIT 10"
 	stmt, err := ParseStatement(sql)
@@ -264,3 +285,37 @@ func TestParseExplainDefaultOptions(t *testing.T) {
 		t.Errorf("Default format should be TEXT, got %s", options.Format)
 	}
 }
+
+func TestParseExplainAnalyzeVerbose(t *testing.T) {
+	sql := "EXPLAIN ANALYZE VERBOSE FORMAT JSON SELECT * FROM users"
+	stmt, err := ParseStatement(sql)
+	if err != nil {
+		t.Fatalf("Failed to parse EXPLAIN ANALYZE VERBOSE: %v", err)
+	}
+
+	explainStmt, ok := stmt.(*statements.ExplainStatement)
+	if !ok {
+		t.Fatalf("Expected ExplainStatement, got %T", stmt)
+	}
+
+	options := explainStmt.GetOptions()
+	if !options.Analyze || !options.Verbose {
+		t.Errorf("Expected ANALYZE and VERBOSE to be set, got %+v", options)
+	}
+
+	if options.Format != "JSON" {
+		t.Errorf("Expected format JSON, got %s", options.Format)
+	}
+
+	if explainStmt.Validate() != nil {
+		t.Errorf("Expected statement to be valid, got %v", explainStmt.Validate())
+	}
+}
+
+func TestParseExplainVerboseWithoutAnalyze(t *testing.T) {
+	sql := "EXPLAIN VERBOSE SELECT * FROM users"
+	_, err := ParseStatement(sql)
+	if err == nil {
+		t.Fatal("Expected error for VERBOSE without ANALYZE, got nil")
+	}
+}
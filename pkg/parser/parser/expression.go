@@ -0,0 +1,301 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"storemy/pkg/types"
+	"strconv"
+	"strings"
+)
+
+// caseColumnName is the output column name of a CASE expression in the
+// SELECT list that has no AS alias.
+const caseColumnName = "CASE"
+
+// ParseExpression parses a standalone scalar expression, such as the
+// expression of a CHECK constraint. The whole input must be consumed.
+//
+// Example:
+//
+//	ParseExpression("CASE WHEN status = 'gold' THEN discount <= 50 ELSE discount <= 10 END")
+func ParseExpression(sql string) (plan.Expr, error) {
+	l := lexer.NewLexer(sql)
+	expr, err := parseExpression(l)
+	if err != nil {
+		return nil, err
+	}
+
+	if token := l.NextToken(); token.Type != lexer.EOF {
+		return nil, fmt.Errorf("unexpected %s after expression", token.Value)
+	}
+	return expr, nil
+}
+
+// parseExpression parses a boolean or scalar expression.
+//
+// Grammar:
+//
+//	expression = and_expr [OR and_expr]*
+//	and_expr   = comparison [AND comparison]*
+//	comparison = operand [OPERATOR operand]
+//	operand    = CASE ... END | IDENTIFIER | AGGREGATE_FUNC(IDENTIFIER | *) | STRING | INT | TRUE | FALSE | NULL | PARAMETER | ( expression )
+func parseExpression(l *lexer.Lexer) (plan.Expr, error) {
+	return parseLogical(l, lexer.OR, plan.OrOp, func(l *lexer.Lexer) (plan.Expr, error) {
+		return parseLogical(l, lexer.AND, plan.AndOp, parseComparison)
+	})
+}
+
+// parseLogical parses one or more operands separated by the keyword sep and
+// folds them left to right into LogicalExprs.
+func parseLogical(l *lexer.Lexer, sep lexer.TokenType, op plan.LogicalOp, parseOperand func(*lexer.Lexer) (plan.Expr, error)) (plan.Expr, error) {
+	left, err := parseOperand(l)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		token := l.NextToken()
+		if token.Type != sep {
+			l.SetPos(token.Position)
+			return left, nil
+		}
+
+		right, err := parseOperand(l)
+		if err != nil {
+			return nil, err
+		}
+		left = &plan.LogicalExpr{Op: op, Left: left, Right: right}
+	}
+}
+
+// parseComparison parses an operand optionally compared with a second one.
+// The right side of a pattern operator (LIKE, ILIKE, REGEXP) must be a string
+// literal, which is normalized like in a WHERE clause, or a bind parameter.
+func parseComparison(l *lexer.Lexer) (plan.Expr, error) {
+	left, err := parseOperand(l)
+	if err != nil {
+		return nil, err
+	}
+
+	opToken := l.NextToken()
+	if opToken.Type != lexer.OPERATOR {
+		l.SetPos(opToken.Position)
+		return left, nil
+	}
+
+	pred, err := parseOperator(opToken.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := parseCompareOperand(l, pred)
+	if err != nil {
+		return nil, err
+	}
+	return &plan.CompareExpr{Left: left, Op: pred, Right: right}, nil
+}
+
+// parseCompareOperand parses the right side of a comparison using pred.
+func parseCompareOperand(l *lexer.Lexer, pred primitives.Predicate) (plan.Expr, error) {
+	if !pred.IsPatternMatch() {
+		return parseOperand(l)
+	}
+
+	token := l.NextToken()
+	if token.Type == lexer.PARAMETER {
+		return parseParamExpr(token)
+	}
+	pattern, err := parseConditionValue(l, token, pred)
+	if err != nil {
+		return nil, err
+	}
+	return &plan.LiteralExpr{Value: types.NewStringField(pattern, types.StringMaxSize)}, nil
+}
+
+// parseOperand parses a single operand of a comparison.
+func parseOperand(l *lexer.Lexer) (plan.Expr, error) {
+	token := l.NextToken()
+
+	switch token.Type {
+	case lexer.CASE:
+		return parseCaseExpression(l)
+
+	case lexer.LPAREN:
+		expr, err := parseExpression(l)
+		if err != nil {
+			return nil, err
+		}
+		if err := expectTokenSequence(l, lexer.RPAREN); err != nil {
+			return nil, fmt.Errorf("expected ) to close expression: %w", err)
+		}
+		return expr, nil
+
+	case lexer.IDENTIFIER:
+		switch token.Value {
+		case "TRUE", "FALSE":
+			return &plan.LiteralExpr{Value: types.NewBoolField(token.Value == "TRUE")}, nil
+		}
+
+		next := l.NextToken()
+		if next.Type == lexer.LPAREN && token.Value == "GROUPING" {
+			call, err := parseGroupingCall(l)
+			if err != nil {
+				return nil, err
+			}
+			return call, nil
+		}
+		if next.Type == lexer.LPAREN {
+			field, err := parseAggregateArgument(l)
+			if err != nil {
+				return nil, err
+			}
+			return &plan.AggregateExpr{Func: strings.ToUpper(token.Value), Field: field}, nil
+		}
+		l.SetPos(next.Position)
+		return &plan.ColumnExpr{Name: strings.ToUpper(token.Value)}, nil
+
+	case lexer.STRING:
+		return &plan.LiteralExpr{Value: types.NewStringField(token.Value, types.StringMaxSize)}, nil
+
+	case lexer.INT:
+		value, err := strconv.ParseInt(token.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer value: %s", token.Value)
+		}
+		return &plan.LiteralExpr{Value: types.NewIntField(value)}, nil
+
+	case lexer.NULL:
+		return &plan.LiteralExpr{}, nil
+
+	case lexer.PARAMETER:
+		return parseParamExpr(token)
+
+	default:
+		return nil, fmt.Errorf("expected expression, got %s", token.Value)
+	}
+}
+
+// parseParamExpr converts a PARAMETER token into a bind parameter expression.
+func parseParamExpr(token lexer.Token) (*plan.ParamExpr, error) {
+	index, err := parseParameter(token)
+	if err != nil {
+		return nil, err
+	}
+	return &plan.ParamExpr{Index: index}, nil
+}
+
+// parseCaseExpression parses a CASE expression after its CASE keyword.
+//
+// Grammar:
+//
+//	CASE WHEN expression THEN expression [WHEN ...] [ELSE expression] END
+//	CASE operand WHEN operand THEN expression [WHEN ...] [ELSE expression] END
+//
+// The first form (searched CASE) takes the first branch whose condition is
+// true; the second (simple CASE) the first branch whose value equals the operand.
+func parseCaseExpression(l *lexer.Lexer) (*plan.CaseExpr, error) {
+	caseExpr := &plan.CaseExpr{}
+
+	token := l.NextToken()
+	if token.Type != lexer.WHEN {
+		l.SetPos(token.Position)
+		operand, err := parseOperand(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CASE operand: %w", err)
+		}
+		caseExpr.Operand = operand
+		token = l.NextToken()
+	}
+
+	for token.Type == lexer.WHEN {
+		var cond plan.Expr
+		var err error
+		if caseExpr.Operand == nil {
+			cond, err = parseExpression(l)
+		} else {
+			cond, err = parseOperand(l)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid WHEN clause: %w", err)
+		}
+
+		if err := expectTokenSequence(l, lexer.THEN); err != nil {
+			return nil, fmt.Errorf("expected THEN after WHEN condition: %w", err)
+		}
+
+		result, err := parseExpression(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid THEN result: %w", err)
+		}
+
+		caseExpr.Whens = append(caseExpr.Whens, plan.WhenClause{Cond: cond, Result: result})
+		token = l.NextToken()
+	}
+
+	if len(caseExpr.Whens) == 0 {
+		return nil, fmt.Errorf("expected WHEN in CASE expression, got %s", token.Value)
+	}
+
+	if token.Type == lexer.ELSE {
+		orElse, err := parseExpression(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ELSE result: %w", err)
+		}
+		caseExpr.Else = orElse
+		token = l.NextToken()
+	}
+
+	if err := expectToken(token, lexer.END); err != nil {
+		return nil, fmt.Errorf("expected END to close CASE expression, got %s", token.Value)
+	}
+	return caseExpr, nil
+}
+
+// parseCaseCondition parses a WHERE condition that involves a CASE expression:
+// either a condition starting with CASE (CASE ... END = 'X', or a CASE that
+// produces a boolean) or field OPERATOR CASE ... END. fieldToken is the first
+// token of the condition; for the second form opToken is the operator and the
+// CASE keyword has been consumed.
+func parseCaseCondition(l *lexer.Lexer, fieldToken lexer.Token, opToken *lexer.Token) (plan.Expr, error) {
+	if opToken == nil {
+		l.SetPos(fieldToken.Position)
+		return parseComparison(l)
+	}
+
+	pred, err := parseOperator(opToken.Value)
+	if err != nil {
+		return nil, err
+	}
+	if pred.IsPatternMatch() {
+		return nil, fmt.Errorf("%s requires a string pattern, got CASE", pred)
+	}
+
+	caseExpr, err := parseCaseExpression(l)
+	if err != nil {
+		return nil, err
+	}
+
+	column := &plan.ColumnExpr{Name: strings.ToUpper(fieldToken.Value)}
+	return &plan.CompareExpr{Left: column, Op: pred, Right: caseExpr}, nil
+}
+
+// parseSelectAlias parses the optional output name of a computed column:
+// [AS] IDENTIFIER. It returns def when no alias is given.
+func parseSelectAlias(l *lexer.Lexer, def string) (string, error) {
+	token := l.NextToken()
+	switch token.Type {
+	case lexer.AS:
+		alias, err := parseValueWithType(l, lexer.IDENTIFIER)
+		if err != nil {
+			return "", fmt.Errorf("expected alias after AS: %w", err)
+		}
+		return strings.ToUpper(alias), nil
+	case lexer.IDENTIFIER:
+		return strings.ToUpper(token.Value), nil
+	default:
+		l.SetPos(token.Position)
+		return def, nil
+	}
+}
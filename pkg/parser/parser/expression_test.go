@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/plan"
+	"strings"
+	"testing"
+)
+
+func parseSelectPlan(t *testing.T, sql string) *plan.SelectPlan {
+	t.Helper()
+	stmt, err := ParseStatement(sql)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	return stmt.(*statements.SelectStatement).Plan
+}
+
+func TestParseStatement_SelectCase(t *testing.T) {
+	p := parseSelectPlan(t, "SELECT name, CASE WHEN age < 18 THEN 'minor' WHEN age < 65 THEN 'adult' ELSE 'senior' END AS bracket FROM users")
+
+	list := p.SelectList()
+	if len(list) != 2 {
+		t.Fatalf("expected 2 select list entries, got %d", len(list))
+	}
+	if list[0].FieldName != "NAME" || list[0].Expr != nil {
+		t.Errorf("expected plain field NAME, got %s", list[0])
+	}
+
+	expected := "CASE WHEN AGE < 18 THEN 'MINOR' WHEN AGE < 65 THEN 'ADULT' ELSE 'SENIOR' END"
+	if list[1].FieldName != "BRACKET" || list[1].Expr == nil || list[1].Expr.String() != expected {
+		t.Errorf("expected %s AS BRACKET, got %s", expected, list[1])
+	}
+	if !p.HasProjectExprs() {
+		t.Error("expected HasProjectExprs to be true")
+	}
+}
+
+func TestParseStatement_SelectCaseAliases(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"SELECT CASE status WHEN 'a' THEN 1 ELSE 0 END FROM t", "CASE"},
+		{"SELECT CASE status WHEN 'a' THEN 1 ELSE 0 END is_a FROM t", "IS_A"},
+		{"SELECT CASE status WHEN 'a' THEN 1 ELSE 0 END AS is_a, id FROM t", "IS_A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			list := parseSelectPlan(t, tt.sql).SelectList()
+			caseExpr, ok := list[0].Expr.(*plan.CaseExpr)
+			if !ok || caseExpr.Operand == nil {
+				t.Fatalf("expected a simple CASE, got %v", list[0].Expr)
+			}
+			if list[0].FieldName != tt.expected {
+				t.Errorf("expected column name %s, got %s", tt.expected, list[0].FieldName)
+			}
+		})
+	}
+}
+
+func TestParseStatement_WhereCase(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			"SELECT * FROM users WHERE CASE WHEN age < 18 THEN 'minor' ELSE 'adult' END = 'adult'",
+			"Filter[CASE WHEN AGE < 18 THEN 'MINOR' ELSE 'ADULT' END = 'ADULT']",
+		},
+		{
+			"SELECT * FROM users WHERE tier = CASE WHEN age >= 65 THEN 'senior' ELSE 'standard' END",
+			"Filter[TIER = CASE WHEN AGE >= 65 THEN 'SENIOR' ELSE 'STANDARD' END]",
+		},
+		{
+			"SELECT * FROM users WHERE CASE WHEN vip = TRUE OR age > 80 THEN TRUE ELSE FALSE END",
+			"Filter[CASE WHEN (VIP = true OR AGE > 80) THEN true ELSE false END]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			filters := parseSelectPlan(t, tt.sql).Filters()
+			if len(filters) != 1 || filters[0].Expr == nil {
+				t.Fatalf("expected one expression filter, got %v", filters)
+			}
+			if got := filters[0].String(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseStatement_OrderByCase(t *testing.T) {
+	p := parseSelectPlan(t, "SELECT * FROM tickets ORDER BY CASE priority WHEN 'high' THEN 0 ELSE 1 END DESC LIMIT 5")
+
+	if p.OrderByExpr() == nil {
+		t.Fatal("expected an ORDER BY expression")
+	}
+	if p.OrderByAsc() {
+		t.Error("expected descending order")
+	}
+	if !p.HasLimit() || p.Limit() != 5 {
+		t.Error("expected LIMIT 5 after the ORDER BY expression")
+	}
+}
+
+func TestParseStatement_DeleteWhereCase(t *testing.T) {
+	stmt, err := ParseStatement("DELETE FROM users WHERE CASE WHEN age < 18 THEN 1 ELSE 0 END = 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	where := stmt.(*statements.DeleteStatement).WhereClause
+	if where == nil || where.Expr == nil {
+		t.Fatalf("expected an expression WHERE clause, got %v", where)
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"a = 1 OR b = 2 AND c = 3", "(A = 1 OR (B = 2 AND C = 3))"},
+		{"(a = 1 OR b = 2) AND c = 3", "((A = 1 OR B = 2) AND C = 3)"},
+		{"a = 1 b", ""},
+		{"CASE WHEN a = 1 THEN NULL END", "CASE WHEN A = 1 THEN NULL END"},
+		{"CASE WHEN name LIKE 'a%' THEN amount > 0 END", "CASE WHEN NAME LIKE 'A%' THEN AMOUNT > 0 END"},
+		{"CASE WHEN a = 1 THEN CASE b WHEN 2 THEN 'x' END END", "CASE WHEN A = 1 THEN CASE B WHEN 2 THEN 'X' END END"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			expr, err := ParseExpression(tt.sql)
+			if tt.expected == "" {
+				if err == nil {
+					t.Fatalf("expected error, got %s", expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if expr.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, expr)
+			}
+		})
+	}
+}
+
+func TestParseStatement_CaseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		errMsg string
+	}{
+		{"Missing END", "SELECT CASE WHEN a = 1 THEN 'x' FROM t", "expected END"},
+		{"No WHEN", "SELECT CASE ELSE 'x' END FROM t", "invalid CASE operand"},
+		{"Simple CASE without WHEN", "SELECT CASE a END FROM t", "expected WHEN"},
+		{"Missing THEN", "SELECT CASE WHEN a = 1 'x' END FROM t", "expected THEN"},
+		{"Missing alias", "SELECT CASE WHEN a = 1 THEN 'x' END AS FROM t", "expected alias after AS"},
+		{"Pattern against CASE", "SELECT * FROM t WHERE name LIKE CASE WHEN a = 1 THEN 'x' END", "LIKE requires a string pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(tt.sql)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseStatement_Parameters(t *testing.T) {
+	p := parseSelectPlan(t, "SELECT name, CASE WHEN age > ? THEN ? END AS band FROM users WHERE id = ?")
+	filter := p.Filters()[0]
+	if filter.Param != 3 || filter.Constant != "$3" {
+		t.Errorf("expected filter on parameter $3, got param %d constant %s", filter.Param, filter.Constant)
+	}
+	if got := p.SelectList()[1].Expr.String(); got != "CASE WHEN AGE > $1 THEN $2 END" {
+		t.Errorf("unexpected CASE with parameters: %s", got)
+	}
+
+	stmt, err := ParseStatement("INSERT INTO users (id, name) VALUES ($1, 'x'), (2, $2)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	insert := stmt.(*statements.InsertStatement)
+	expected := []statements.ValueParam{{Row: 0, Column: 0, Index: 1}, {Row: 1, Column: 1, Index: 2}}
+	if len(insert.Params) != len(expected) || insert.Params[0] != expected[0] || insert.Params[1] != expected[1] {
+		t.Errorf("expected INSERT params %v, got %v", expected, insert.Params)
+	}
+	if insert.String() != "INSERT INTO USERS (ID, NAME) VALUES ($1, X), (2, $2)" {
+		t.Errorf("unexpected INSERT string: %s", insert.String())
+	}
+
+	stmt, err = ParseStatement("UPDATE users SET name = $2 WHERE id = $1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	update := stmt.(*statements.UpdateStatement)
+	if update.SetClauses[0].Param != 2 || update.WhereClause.Param != 1 {
+		t.Errorf("expected SET $2 WHERE $1, got %s", update)
+	}
+
+	stmt, err = ParseStatement("DELETE FROM users WHERE name LIKE ?")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if where := stmt.(*statements.DeleteStatement).WhereClause; where.Param != 1 {
+		t.Errorf("expected DELETE filter on parameter $1, got %s", where)
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM users WHERE id = $0",
+		"SELECT * FROM users WHERE id = $1 AND age > ?",
+		"SELECT * FROM users WHERE id = $",
+	} {
+		if _, err := ParseStatement(sql); err == nil {
+			t.Errorf("expected %q to fail", sql)
+		}
+	}
+}
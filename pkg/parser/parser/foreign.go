@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+)
+
+type foreignOption struct {
+	name, value string
+}
+
+// parseCreateForeignTableStatement parses a CREATE FOREIGN TABLE statement.
+// Expects the format:
+//
+//	CREATE FOREIGN TABLE [IF NOT EXISTS] table_name (field_definitions...)
+//	OPTIONS (name 'value', ...)
+//
+// Field definitions use the CREATE TABLE syntax. Option values keep their
+// original case.
+func parseCreateForeignTableStatement(l *lexer.Lexer) (*statements.CreateForeignTableStatement, error) {
+	if err := expectTokenSequence(l, lexer.CREATE, lexer.FOREIGN, lexer.TABLE); err != nil {
+		return nil, err
+	}
+
+	ifNotExists, err := parseIfNotExists(l)
+	if err != nil {
+		return nil, err
+	}
+
+	tableName, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected table name: %w", err)
+	}
+
+	if err := expectTokenSequence(l, lexer.LPAREN); err != nil {
+		return nil, err
+	}
+
+	// Reuse the CREATE TABLE column grammar, then keep only the fields.
+	definition := statements.NewCreateStatement(tableName, ifNotExists)
+	if err := parseTableDefinition(l, definition); err != nil {
+		return nil, err
+	}
+	if definition.PrimaryKey != "" {
+		return nil, fmt.Errorf("foreign tables cannot have a PRIMARY KEY")
+	}
+
+	stmt := statements.NewCreateForeignTableStatement(tableName, ifNotExists)
+	stmt.Fields = definition.Fields
+
+	if err := expectTokenSequence(l, lexer.OPTIONS, lexer.LPAREN); err != nil {
+		return nil, err
+	}
+
+	options, err := parseDelimitedList(l, parseForeignOption, lexer.COMMA, lexer.RPAREN)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range options {
+		if _, dup := stmt.Options[opt.name]; dup {
+			return nil, fmt.Errorf("option %s specified more than once", opt.name)
+		}
+		stmt.Options[opt.name] = opt.value
+	}
+
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseForeignOption parses one "name 'value'" pair of an OPTIONS list.
+func parseForeignOption(l *lexer.Lexer) (foreignOption, error) {
+	nameToken := l.NextToken()
+	if nameToken.Type != lexer.IDENTIFIER && nameToken.Type != lexer.FORMAT {
+		return foreignOption{}, fmt.Errorf("expected option name, got %s", nameToken.Value)
+	}
+
+	valueToken := l.NextToken()
+	if err := expectToken(valueToken, lexer.STRING); err != nil {
+		return foreignOption{}, fmt.Errorf("expected quoted value for option %s: %w", nameToken.Value, err)
+	}
+
+	return foreignOption{name: nameToken.Value, value: l.Raw(valueToken)}, nil
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+// CREATE FOREIGN TABLE statement tests
+func TestParseStatement_CreateForeignTable(t *testing.T) {
+	stmt, err := ParseStatement("CREATE FOREIGN TABLE IF NOT EXISTS visits (user_id INT, page VARCHAR) " +
+		"OPTIONS (format 'csv', location '/Data/Visits.csv', header 'true', delimiter ';')")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	foreignStmt, ok := stmt.(*statements.CreateForeignTableStatement)
+	if !ok {
+		t.Fatalf("expected CreateForeignTableStatement, got %T", stmt)
+	}
+
+	if foreignStmt.TableName != "VISITS" {
+		t.Errorf("expected table name 'VISITS', got %s", foreignStmt.TableName)
+	}
+
+	if !foreignStmt.IfNotExists {
+		t.Error("expected IfNotExists to be true")
+	}
+
+	if len(foreignStmt.Fields) != 2 || foreignStmt.Fields[1].Type != types.StringType {
+		t.Errorf("unexpected fields: %+v", foreignStmt.Fields)
+	}
+
+	expected := map[string]string{
+		"FORMAT":    "csv",
+		"LOCATION":  "/Data/Visits.csv",
+		"HEADER":    "true",
+		"DELIMITER": ";",
+	}
+	for name, value := range expected {
+		if foreignStmt.Options[name] != value {
+			t.Errorf("expected option %s = %q, got %q", name, value, foreignStmt.Options[name])
+		}
+	}
+}
+
+func TestParseStatement_CreateForeignTableErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		errMsg string
+	}{
+		{
+			name:   "Missing OPTIONS",
+			sql:    "CREATE FOREIGN TABLE t (id INT)",
+			errMsg: "expected OPTIONS",
+		},
+		{
+			name:   "Missing LOCATION",
+			sql:    "CREATE FOREIGN TABLE t (id INT) OPTIONS (format 'csv')",
+			errMsg: "LOCATION",
+		},
+		{
+			name:   "Duplicate option",
+			sql:    "CREATE FOREIGN TABLE t (id INT) OPTIONS (format 'csv', location 'a.csv', format 'jsonl')",
+			errMsg: "option FORMAT specified more than once",
+		},
+		{
+			name:   "Primary key",
+			sql:    "CREATE FOREIGN TABLE t (id INT, PRIMARY KEY (id)) OPTIONS (format 'csv', location 'a.csv')",
+			errMsg: "PRIMARY KEY",
+		},
+		{
+			name:   "Unquoted value",
+			sql:    "CREATE FOREIGN TABLE t (id INT) OPTIONS (format csv, location 'a.csv')",
+			errMsg: "expected quoted value for option FORMAT",
+		},
+		{
+			name:   "Missing TABLE",
+			sql:    "CREATE FOREIGN t (id INT) OPTIONS (format 'csv', location 'a.csv')",
+			errMsg: "expected TABLE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(tt.sql)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
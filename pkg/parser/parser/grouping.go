@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/plan"
+)
+
+// parseGroupingSets parses GROUPING SETS, ROLLUP or CUBE after GROUP BY and
+// returns the grouping sets it lists, each a list of expressions. It returns
+// false, leaving the lexer where it was, for a plain GROUP BY.
+//
+// Grammar:
+//
+//	ROLLUP ( expression [, expression]* )
+//	CUBE ( expression [, expression]* )
+//	GROUPING SETS ( grouping_set [, grouping_set]* )
+//	grouping_set = () | expression | ( expression [, expression]* )
+//
+// ROLLUP (a, b) stands for GROUPING SETS ((a, b), (a), ()) and CUBE (a, b)
+// for GROUPING SETS ((a, b), (a), (b), ()).
+func parseGroupingSets(l *lexer.Lexer) ([][]plan.Expr, bool, error) {
+	start := l.NextToken()
+	next := l.NextToken()
+	if start.Type != lexer.IDENTIFIER {
+		l.SetPos(start.Position)
+		return nil, false, nil
+	}
+
+	switch {
+	case start.Value == "ROLLUP" && next.Type == lexer.LPAREN:
+		keys, err := parseGroupingList(l)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid ROLLUP: %w", err)
+		}
+		return rollupSets(keys), true, nil
+
+	case start.Value == "CUBE" && next.Type == lexer.LPAREN:
+		keys, err := parseGroupingList(l)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid CUBE: %w", err)
+		}
+		return cubeSets(keys), true, nil
+
+	case start.Value == "GROUPING" && next.Type == lexer.IDENTIFIER && next.Value == "SETS":
+		if err := expectTokenSequence(l, lexer.LPAREN); err != nil {
+			return nil, true, fmt.Errorf("expected ( after GROUPING SETS: %w", err)
+		}
+		sets, err := parseDelimitedList(l, parseGroupingSet, lexer.COMMA, lexer.RPAREN)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid GROUPING SETS: %w", err)
+		}
+		return sets, true, nil
+
+	default:
+		l.SetPos(start.Position)
+		return nil, false, nil
+	}
+}
+
+// parseGroupingSet parses one set of GROUPING SETS: (), a single expression,
+// or a parenthesized list of expressions.
+func parseGroupingSet(l *lexer.Lexer) ([]plan.Expr, error) {
+	token := l.NextToken()
+	if token.Type != lexer.LPAREN {
+		l.SetPos(token.Position)
+		key, err := parseGroupingKey(l)
+		if err != nil {
+			return nil, err
+		}
+		return []plan.Expr{key}, nil
+	}
+
+	next := l.NextToken()
+	if next.Type == lexer.RPAREN {
+		return nil, nil
+	}
+	l.SetPos(next.Position)
+	return parseGroupingList(l)
+}
+
+// parseGroupingList parses a comma-separated list of grouping keys up to and
+// including its closing parenthesis.
+func parseGroupingList(l *lexer.Lexer) ([]plan.Expr, error) {
+	return parseDelimitedList(l, parseGroupingKey, lexer.COMMA, lexer.RPAREN)
+}
+
+// parseGroupingKey parses an expression to group by.
+func parseGroupingKey(l *lexer.Lexer) (plan.Expr, error) {
+	expr, err := parseExpression(l)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GROUP BY expression: %w", err)
+	}
+	if _, ok := expr.(*plan.AggregateExpr); ok {
+		return nil, fmt.Errorf("aggregate function %s is not allowed in GROUP BY", expr)
+	}
+	return expr, nil
+}
+
+// rollupSets returns the grouping sets of ROLLUP (keys): every prefix of
+// keys, longest first, down to the empty set.
+func rollupSets(keys []plan.Expr) [][]plan.Expr {
+	sets := make([][]plan.Expr, 0, len(keys)+1)
+	for n := len(keys); n >= 0; n-- {
+		sets = append(sets, keys[:n])
+	}
+	return sets
+}
+
+// cubeSets returns the grouping sets of CUBE (keys): every subset of keys,
+// from all of them down to the empty set.
+func cubeSets(keys []plan.Expr) [][]plan.Expr {
+	n := len(keys)
+	sets := make([][]plan.Expr, 0, 1<<n)
+	for mask := 1<<n - 1; mask >= 0; mask-- {
+		var set []plan.Expr
+		for i, key := range keys {
+			if mask&(1<<(n-1-i)) != 0 {
+				set = append(set, key)
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// setGroupingSets records sets as the grouping sets of p. A query groups by
+// a single key, so every set must group by the same expression or be empty.
+func setGroupingSets(p *plan.SelectPlan, sets [][]plan.Expr) error {
+	var key plan.Expr
+	grouped := make([]bool, len(sets))
+	for i, set := range sets {
+		for _, expr := range set {
+			if key != nil && expr.String() != key.String() {
+				return fmt.Errorf("grouping sets can only group by a single key, got %s and %s", key, expr)
+			}
+			key = expr
+			grouped[i] = true
+		}
+	}
+
+	if key != nil {
+		setGroupByKey(p, key)
+	}
+	p.SetGroupingSets(grouped)
+	return nil
+}
+
+// parseGroupingCall parses the argument of GROUPING(expression) up to and
+// including its closing parenthesis. The opening parenthesis has already
+// been consumed.
+func parseGroupingCall(l *lexer.Lexer) (*plan.GroupingExpr, error) {
+	arg, err := parseExpression(l)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GROUPING argument: %w", err)
+	}
+	if err := expectTokenSequence(l, lexer.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected ) to close GROUPING: %w", err)
+	}
+	return &plan.GroupingExpr{Arg: arg}, nil
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"slices"
+	"storemy/pkg/parser/statements"
+	"strings"
+	"testing"
+)
+
+func TestParseGroupingSets(t *testing.T) {
+	tests := []struct {
+		query    string
+		groupBy  string
+		expected []bool
+	}{
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY ROLLUP (dept)", "DEPT", []bool{true, false}},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY CUBE (dept)", "DEPT", []bool{true, false}},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY GROUPING SETS ((dept), dept, ())", "DEPT", []bool{true, true, false}},
+		{"SELECT COUNT(id) FROM staff GROUP BY GROUPING SETS (())", "", []bool{false}},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY dept", "DEPT", nil},
+		// A column may be called rollup
+		{"SELECT rollup, COUNT(id) FROM staff GROUP BY rollup", "ROLLUP", nil},
+	}
+
+	for _, tt := range tests {
+		stmt, err := ParseStatement(tt.query)
+		if err != nil {
+			t.Errorf("%s: ParseStatement failed: %v", tt.query, err)
+			continue
+		}
+		p := stmt.(*statements.SelectStatement).Plan
+		if p.GroupByField() != tt.groupBy || !slices.Equal(p.GroupingSets(), tt.expected) {
+			t.Errorf("%s: expected GROUP BY %q with sets %v, got %q with %v", tt.query, tt.groupBy, tt.expected, p.GroupByField(), p.GroupingSets())
+		}
+	}
+}
+
+func TestParseGroupingCall(t *testing.T) {
+	stmt, err := ParseStatement("SELECT dept, COUNT(id), GROUPING(dept) AS total FROM staff GROUP BY ROLLUP (dept) HAVING GROUPING(dept) = 0")
+	if err != nil {
+		t.Fatalf("ParseStatement failed: %v", err)
+	}
+
+	p := stmt.(*statements.SelectStatement).Plan
+	field := p.SelectList()[2]
+	if field.FieldName != "TOTAL" || field.Expr == nil || field.Expr.String() != "GROUPING(DEPT)" {
+		t.Errorf("expected GROUPING(DEPT) AS TOTAL, got %s AS %s", field.Expr, field.FieldName)
+	}
+	if !strings.Contains(p.Having().String(), "GROUPING(DEPT)") {
+		t.Errorf("expected the HAVING condition to call GROUPING, got %s", p.Having())
+	}
+}
+
+func TestParseGroupingSetsErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY ROLLUP (dept, age)", "single key"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY CUBE (dept", "invalid CUBE"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY GROUPING SETS dept", "expected ( after GROUPING SETS"},
+		{"SELECT dept, COUNT(id) FROM staff GROUP BY GROUPING SETS ((COUNT(id)))", "not allowed in GROUP BY"},
+		{"SELECT dept, COUNT(id), GROUPING(dept FROM staff GROUP BY dept", "expected ) to close GROUPING"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseStatement(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
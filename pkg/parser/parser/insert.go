@@ -48,7 +48,15 @@ func parseInsertValues(l *lexer.Lexer, stmt *statements.InsertStatement) (*state
 			return nil, err
 		}
 
-		stmt.AddValues(values)
+		row := stmt.ValueCount()
+		fields := make([]types.Field, len(values))
+		for column, value := range values {
+			fields[column] = value.field
+			if value.param != 0 {
+				stmt.AddParam(row, column, value.param)
+			}
+		}
+		stmt.AddValues(fields)
 
 		token = l.NextToken()
 		if token.Type == lexer.COMMA {
@@ -62,8 +70,28 @@ func parseInsertValues(l *lexer.Lexer, stmt *statements.InsertStatement) (*state
 	return stmt, nil
 }
 
-func parseValueList(l *lexer.Lexer) ([]types.Field, error) {
-	return parseDelimitedList(l, parseValue, lexer.COMMA, lexer.RPAREN)
+// valueOrParam is a value of an INSERT row or UPDATE SET clause: a literal,
+// or bind parameter $param when param is not 0.
+type valueOrParam struct {
+	field types.Field
+	param int
+}
+
+func parseValueList(l *lexer.Lexer) ([]valueOrParam, error) {
+	return parseDelimitedList(l, parseValueOrParam, lexer.COMMA, lexer.RPAREN)
+}
+
+// parseValueOrParam parses a literal value or a bind parameter.
+func parseValueOrParam(l *lexer.Lexer) (valueOrParam, error) {
+	token := l.NextToken()
+	if token.Type == lexer.PARAMETER {
+		param, err := parseParameter(token)
+		return valueOrParam{param: param}, err
+	}
+
+	l.SetPos(token.Position)
+	field, err := parseValue(l)
+	return valueOrParam{field: field}, err
 }
 
 func parseFieldList(l *lexer.Lexer) ([]string, error) {
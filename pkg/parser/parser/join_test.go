@@ -2,6 +2,7 @@ package parser
 
 import (
 	"storemy/pkg/parser/statements"
+	"strings"
 	"testing"
 )
 
@@ -139,6 +140,26 @@ func TestParseSelectWithRightJoin(t *testing.T) {
 	}
 }
 
+func TestParseSelectWithFullOuterJoin(t *testing.T) {
+	for _, query := range []string{
+		"SELECT * FROM users FULL JOIN orders ON users.id = orders.user_id",
+		"SELECT * FROM users FULL OUTER JOIN orders ON users.id = orders.user_id",
+	} {
+		stmt, err := ParseStatement(query)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", query, err)
+		}
+
+		joins := stmt.(*statements.SelectStatement).Plan.Joins()
+		if len(joins) != 1 {
+			t.Fatalf("Expected 1 join, got %d", len(joins))
+		}
+		if joins[0].JoinType != "full" {
+			t.Errorf("Expected full join for %q, got %v", query, joins[0].JoinType)
+		}
+	}
+}
+
 func TestParseSelectWithMultipleJoins(t *testing.T) {
 	query := "SELECT * FROM users INNER JOIN orders ON users.id = orders.user_id LEFT JOIN products ON orders.product_id = products.id"
 	stmt, err := ParseStatement(query)
@@ -243,3 +264,162 @@ func TestParseSelectBackwardCompatibleCommaJoin(t *testing.T) {
 		t.Errorf("Expected 0 joins with comma syntax, got %d", len(joins))
 	}
 }
+
+func TestParseSelectWithCrossJoin(t *testing.T) {
+	stmt, err := ParseStatement("SELECT * FROM sizes CROSS JOIN colors c")
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+
+	joins := stmt.(*statements.SelectStatement).Plan.Joins()
+	if len(joins) != 1 {
+		t.Fatalf("Expected 1 join, got %d", len(joins))
+	}
+	if joins[0].JoinType != "cross" {
+		t.Errorf("Expected cross join, got %v", joins[0].JoinType)
+	}
+	if joins[0].RightTable.TableName != "COLORS" || joins[0].RightTable.Alias != "C" {
+		t.Errorf("Expected COLORS aliased C, got %s %s", joins[0].RightTable.TableName, joins[0].RightTable.Alias)
+	}
+}
+
+func TestParseSelectWithLateralSubquery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		joinType string
+		lateral  bool
+		alias    string
+	}{
+		{"Comma", "SELECT * FROM users u, LATERAL (SELECT * FROM orders o WHERE o.user_id = u.id LIMIT 2) recent", "cross", true, "RECENT"},
+		{"Cross", "SELECT * FROM users u CROSS JOIN LATERAL (SELECT * FROM orders o WHERE o.user_id = u.id) recent", "cross", true, "RECENT"},
+		{"Left", "SELECT * FROM users u LEFT JOIN LATERAL (SELECT * FROM orders o WHERE o.user_id = u.id) recent ON u.id = recent.user_id", "left", true, "RECENT"},
+		{"Derived", "SELECT * FROM users u JOIN (SELECT * FROM orders WHERE total > 10) big ON u.id = big.user_id", "inner", false, "BIG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+
+			joins := stmt.(*statements.SelectStatement).Plan.Joins()
+			if len(joins) != 1 {
+				t.Fatalf("Expected 1 join, got %d", len(joins))
+			}
+			join := joins[0]
+			if join.JoinType != tt.joinType || join.Lateral != tt.lateral {
+				t.Errorf("Expected %s join with lateral=%v, got %s with lateral=%v", tt.joinType, tt.lateral, join.JoinType, join.Lateral)
+			}
+			if join.Subquery == nil {
+				t.Fatal("Expected a subquery")
+			}
+			if join.RightTable.Alias != tt.alias {
+				t.Errorf("Expected subquery alias %s, got %s", tt.alias, join.RightTable.Alias)
+			}
+		})
+	}
+
+	stmt, _ := ParseStatement(tests[0].query)
+	sub := stmt.(*statements.SelectStatement).Plan.Joins()[0].Subquery
+	if filters := sub.Filters(); len(filters) != 1 || filters[0].Ref != "U.ID" {
+		t.Errorf("Expected a filter on the reference U.ID, got %v", filters)
+	}
+	if !sub.HasLimit() || sub.Limit() != 2 {
+		t.Errorf("Expected the subquery to keep its LIMIT 2")
+	}
+}
+
+func TestParseSelectWithLateralErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"SELECT * FROM users u RIGHT JOIN LATERAL (SELECT * FROM orders) o ON u.id = o.user_id", "cannot be the right side of a RIGHT JOIN"},
+		{"SELECT * FROM users u CROSS JOIN LATERAL orders", "expected ( after LATERAL"},
+		{"SELECT * FROM users u CROSS JOIN (SELECT * FROM orders)", "must have an alias"},
+		{"SELECT * FROM users u CROSS JOIN (SELECT * FROM orders UNION SELECT * FROM archive) o", "set operations are not supported"},
+		{"SELECT * FROM users CROSS orders", "JOIN"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseStatement(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
+
+func TestParseSelectWithSubqueryConditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		exists  bool
+		negated bool
+		field   string
+	}{
+		{"Exists", "SELECT * FROM users u WHERE EXISTS (SELECT * FROM orders o WHERE o.user_id = u.id)", true, false, ""},
+		{"NotExists", "SELECT * FROM users u WHERE NOT EXISTS (SELECT * FROM orders o WHERE o.user_id = u.id)", true, true, ""},
+		{"In", "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders)", false, false, "ID"},
+		{"NotIn", "SELECT * FROM users WHERE id NOT IN (SELECT user_id FROM orders)", false, true, "ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+
+			filters := stmt.(*statements.SelectStatement).Plan.SubqueryFilters()
+			if len(filters) != 1 {
+				t.Fatalf("Expected 1 subquery condition, got %d", len(filters))
+			}
+			f := filters[0]
+			if f.Exists != tt.exists || f.Negated != tt.negated || f.Field != tt.field {
+				t.Errorf("Expected exists=%v negated=%v field=%q, got %s", tt.exists, tt.negated, tt.field, f)
+			}
+			if f.Subquery == nil {
+				t.Error("Expected a subquery")
+			}
+		})
+	}
+
+	stmt, err := ParseStatement("SELECT * FROM users WHERE age > 18 AND id IN (SELECT user_id FROM orders) AND name = 'ada'")
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+	pl := stmt.(*statements.SelectStatement).Plan
+	if len(pl.Filters()) != 2 || len(pl.SubqueryFilters()) != 1 {
+		t.Errorf("Expected 2 filters and 1 subquery condition, got %d and %d", len(pl.Filters()), len(pl.SubqueryFilters()))
+	}
+}
+
+func TestParseSelectWithSubqueryConditionErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"SELECT * FROM users WHERE id IN (SELECT user_id, total FROM orders)", "exactly one column"},
+		{"SELECT * FROM users WHERE id IN (SELECT * FROM orders)", "exactly one column"},
+		{"SELECT * FROM users WHERE id IN (1, 2)", "IN requires a subquery"},
+		{"SELECT * FROM users WHERE NOT id = 1", "expected EXISTS after NOT"},
+		{"SELECT * FROM users WHERE EXISTS (SELECT * FROM orders", "expected ) after subquery"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseStatement(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
@@ -18,10 +18,22 @@ import (
 //   - DELETE: Remove rows from tables
 //   - CREATE TABLE: Define new table schemas
 //   - CREATE INDEX: Create indexes on tables
+//   - CREATE FOREIGN TABLE: Define tables backed by external CSV or JSONL files
+//   - CREATE TRIGGER: Run a registered function on INSERT, UPDATE or DELETE
+//   - CREATE DATABASE: Add a database to the engine
 //   - DROP TABLE: Remove tables
 //   - DROP INDEX: Remove indexes
+//   - DROP TRIGGER: Remove triggers
+//   - ALTER TABLE ... ENABLE/DISABLE AUDIT: Turn row change auditing on or off
+//   - ALTER TABLE ... SET CACHE: Set the buffer pool priority of a table's pages
 //   - EXPLAIN: Show query execution plan
 //   - SHOW INDEXES: Display index information
+//   - SHOW PERSISTENT: Display persistent database settings
+//   - SET PERSISTENT: Change a persistent database setting
+//   - SET TRANSACTION SNAPSHOT: Read the snapshot exported by another transaction
+//   - USE: Switch the session to another database
+//   - COPY: Load the rows of a CSV or JSONL file into a table
+//   - CHECKSUM TABLE: Compute order-independent digests of table contents
 //
 // Parameters:
 //   - sql: The SQL statement string to parse
@@ -48,8 +60,14 @@ func ParseStatement(sql string) (statements.Statement, error) {
 			return parseCreateStatement(l)
 		case lexer.INDEX:
 			return parseCreateIndexStatement(l)
+		case lexer.FOREIGN:
+			return parseCreateForeignTableStatement(l)
+		case lexer.TRIGGER:
+			return parseCreateTriggerStatement(l)
+		case lexer.DATABASE:
+			return parseCreateDatabaseStatement(l)
 		default:
-			return nil, fmt.Errorf("expected TABLE or INDEX after CREATE, got %s", secondToken.Value)
+			return nil, fmt.Errorf("expected TABLE, INDEX, FOREIGN TABLE, TRIGGER or DATABASE after CREATE, got %s", secondToken.Value)
 		}
 	case lexer.DROP:
 		secondToken := l.NextToken()
@@ -59,8 +77,10 @@ func ParseStatement(sql string) (statements.Statement, error) {
 			return parseDropStatement(l)
 		case lexer.INDEX:
 			return parseDropIndexStatement(l)
+		case lexer.TRIGGER:
+			return parseDropTriggerStatement(l)
 		default:
-			return nil, fmt.Errorf("expected TABLE or INDEX after DROP, got %s", secondToken.Value)
+			return nil, fmt.Errorf("expected TABLE, INDEX or TRIGGER after DROP, got %s", secondToken.Value)
 		}
 	case lexer.DELETE:
 		l.SetPos(0)
@@ -71,12 +91,38 @@ func ParseStatement(sql string) (statements.Statement, error) {
 	case lexer.SELECT:
 		l.SetPos(0)
 		return parseSelectStatement(l)
+	case lexer.WITH:
+		l.SetPos(0)
+		return parseWithStatement(l)
 	case lexer.EXPLAIN:
 		l.SetPos(0)
 		return parseExplainStatement(l)
 	case lexer.SHOW:
 		l.SetPos(0)
 		return parseShowStatement(l)
+	case lexer.SET:
+		secondToken := l.NextToken()
+		l.SetPos(0)
+		switch secondToken.Type {
+		case lexer.TRANSACTION:
+			return parseSetTransactionStatement(l)
+		case lexer.PERSISTENT:
+			return parseSetStatement(l)
+		default:
+			return parseSetRuntimeStatement(l)
+		}
+	case lexer.USE:
+		l.SetPos(0)
+		return parseUseStatement(l)
+	case lexer.COPY:
+		l.SetPos(0)
+		return parseCopyStatement(l)
+	case lexer.CHECKSUM:
+		l.SetPos(0)
+		return parseChecksumStatement(l)
+	case lexer.ALTER:
+		l.SetPos(0)
+		return parseAlterTableStatement(l)
 	default:
 		return nil, fmt.Errorf("unsupported statement type: %s", token.Value)
 	}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScriptStatement is one statement of a multi-statement SQL script.
+type ScriptStatement struct {
+	SQL  string // Statement text with comments removed and without the terminating semicolon
+	Line int    // 1-based line on which the statement starts
+}
+
+// SplitScript splits a SQL script into its statements.
+//
+// Statements are separated by semicolons. Semicolons inside single- or
+// double-quoted strings, where a doubled quote character is an escaped quote,
+// do not end a statement. Line comments (-- to end of line) and block
+// comments (/* */) are removed; a comment separates tokens like whitespace
+// does. Empty statements are skipped and a final statement does not need a
+// semicolon.
+//
+// Returns an error naming the line where an unterminated string or block
+// comment starts.
+func SplitScript(script string) ([]ScriptStatement, error) {
+	var (
+		result    []ScriptStatement
+		current   strings.Builder
+		line      = 1
+		startLine = 0 // Line of the first non-blank character of the statement
+	)
+
+	flush := func() {
+		if sql := strings.TrimSpace(current.String()); sql != "" {
+			result = append(result, ScriptStatement{SQL: sql, Line: startLine})
+		}
+		current.Reset()
+		startLine = 0
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			if i < len(script) {
+				line++
+			}
+			current.WriteByte(' ')
+
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			commentLine := line
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated block comment starting on line %d", commentLine)
+			}
+			comment := script[i : i+2+end+2]
+			line += strings.Count(comment, "\n")
+			i += len(comment) - 1
+			current.WriteByte(' ')
+
+		case c == '\'' || c == '"':
+			if startLine == 0 {
+				startLine = line
+			}
+			quoteLine := line
+			j := i + 1
+			for {
+				if j >= len(script) {
+					return nil, fmt.Errorf("unterminated string starting on line %d", quoteLine)
+				}
+				if script[j] == c {
+					if j+1 < len(script) && script[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			literal := script[i : j+1]
+			line += strings.Count(literal, "\n")
+			current.WriteString(literal)
+			i = j
+
+		case c == ';':
+			flush()
+
+		default:
+			if c == '\n' {
+				line++
+			} else if startLine == 0 && c != ' ' && c != '\t' && c != '\r' {
+				startLine = line
+			}
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return result, nil
+}
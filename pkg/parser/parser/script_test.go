@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []ScriptStatement
+	}{
+		{
+			name:   "Single statement without semicolon",
+			script: "SELECT * FROM users",
+			want:   []ScriptStatement{{SQL: "SELECT * FROM users", Line: 1}},
+		},
+		{
+			name:   "Multiple statements and lines",
+			script: "CREATE TABLE t (id INT);\n\nINSERT INTO t VALUES (1);\nINSERT INTO t\n  VALUES (2);",
+			want: []ScriptStatement{
+				{SQL: "CREATE TABLE t (id INT)", Line: 1},
+				{SQL: "INSERT INTO t VALUES (1)", Line: 3},
+				{SQL: "INSERT INTO t\n  VALUES (2)", Line: 4},
+			},
+		},
+		{
+			name:   "Semicolons inside strings",
+			script: "INSERT INTO t VALUES ('a;b', \"c;d\", 'it''s;');SELECT 1",
+			want: []ScriptStatement{
+				{SQL: "INSERT INTO t VALUES ('a;b', \"c;d\", 'it''s;')", Line: 1},
+				{SQL: "SELECT 1", Line: 1},
+			},
+		},
+		{
+			name:   "Comments are removed",
+			script: "-- setup; not a statement\nCREATE TABLE t (id INT); /* block;\ncomment */ DROP TABLE t -- trailing\n",
+			want: []ScriptStatement{
+				{SQL: "CREATE TABLE t (id INT)", Line: 2},
+				{SQL: "DROP TABLE t", Line: 3},
+			},
+		},
+		{
+			name:   "Empty statements are skipped",
+			script: ";;  ;\n-- only a comment\n",
+			want:   nil,
+		},
+		{
+			name:   "Multi-line string advances the line counter",
+			script: "INSERT INTO t VALUES ('line\none');\nSELECT 1;",
+			want: []ScriptStatement{
+				{SQL: "INSERT INTO t VALUES ('line\none')", Line: 1},
+				{SQL: "SELECT 1", Line: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitScript(tt.script)
+			if err != nil {
+				t.Fatalf("SplitScript() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitScript() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitScript_Unterminated(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		errMsg string
+	}{
+		{"String", "SELECT 1;\nINSERT INTO t VALUES ('oops);", "unterminated string starting on line 2"},
+		{"Block comment", "SELECT 1; /* never\nclosed", "unterminated block comment starting on line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SplitScript(tt.script)
+			if err == nil || err.Error() != tt.errMsg {
+				t.Errorf("SplitScript() error = %v, want %q", err, tt.errMsg)
+			}
+		})
+	}
+}
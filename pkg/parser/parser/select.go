@@ -6,7 +6,9 @@ import (
 	"storemy/pkg/parser/statements"
 	"storemy/pkg/plan"
 	"storemy/pkg/primitives"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type joinCondition struct {
@@ -16,12 +18,12 @@ type joinCondition struct {
 }
 
 // parseSelectStatement is the main entry point for parsing SELECT statements.
-// It orchestrates the parsing of all SELECT clauses in order: SELECT, FROM, WHERE, GROUP BY, ORDER BY.
+// It orchestrates the parsing of all SELECT clauses in order: SELECT, FROM, WHERE, GROUP BY, HAVING, ORDER BY.
 // Also handles set operations (UNION, INTERSECT, EXCEPT) which can combine multiple SELECT statements.
 //
 // Grammar:
 //
-//	SELECT [* | field_list] FROM table_list [WHERE conditions] [GROUP BY field] [ORDER BY field [ASC|DESC]]
+//	SELECT [* | field_list] FROM table_list [WHERE conditions] [GROUP BY field] [HAVING condition] [ORDER BY field [ASC|DESC]]
 //	[UNION [ALL] | INTERSECT [ALL] | EXCEPT [ALL] SELECT ...]
 //
 // Returns a SelectStatement ready for execution planning, or an error if parsing fails.
@@ -31,8 +33,10 @@ func parseSelectStatement(l *lexer.Lexer) (*statements.SelectStatement, error) {
 	parseFuncs := []func(*lexer.Lexer, *plan.SelectPlan) error{
 		parseSelect,
 		parseFrom,
+		parseAsOf,
 		parseWhere,
 		parseGroupBy,
+		parseHaving,
 		parseOrderBy,
 		parseLimit,
 	}
@@ -58,12 +62,12 @@ func parseSelectStatement(l *lexer.Lexer) (*statements.SelectStatement, error) {
 
 // parseSelect parses the SELECT clause, handling both SELECT * and explicit field lists.
 // Also handles aggregate functions like COUNT(field), SUM(field), etc.
-// Supports DISTINCT keyword: SELECT DISTINCT ...
+// Supports DISTINCT keyword: SELECT DISTINCT ... and SELECT DISTINCT ON (expr, ...) ...
 //
 // Grammar:
 //
-//	SELECT [DISTINCT] * | field [, field]*
-//	field = IDENTIFIER | AGGREGATE_FUNC(IDENTIFIER)
+//	SELECT [DISTINCT [ON (expression [, expression]*)]] * | field [, field]*
+//	field = IDENTIFIER | AGGREGATE_FUNC(IDENTIFIER) | CASE ... END [[AS] alias]
 func parseSelect(l *lexer.Lexer, p *plan.SelectPlan) error {
 	if err := expectTokenSequence(l, lexer.SELECT); err != nil {
 		return err
@@ -74,6 +78,15 @@ func parseSelect(l *lexer.Lexer, p *plan.SelectPlan) error {
 	if token.Type == lexer.DISTINCT {
 		p.SetDistinct(true)
 		token = l.NextToken() // Get next token after DISTINCT
+
+		if token.Type == lexer.ON {
+			keys, err := parseDistinctOnKeys(l)
+			if err != nil {
+				return err
+			}
+			p.SetDistinctOn(keys)
+			token = l.NextToken()
+		}
 	}
 
 	if token.Type == lexer.ASTERISK {
@@ -84,6 +97,39 @@ func parseSelect(l *lexer.Lexer, p *plan.SelectPlan) error {
 	return parseSelectFieldList(l, p, token)
 }
 
+// parseDistinctOnKeys parses the parenthesized key list of DISTINCT ON.
+// The ON keyword has already been consumed.
+//
+// Grammar:
+//
+//	( expression [, expression]* )
+func parseDistinctOnKeys(l *lexer.Lexer) ([]plan.Expr, error) {
+	if err := expectTokenSequence(l, lexer.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( after DISTINCT ON: %w", err)
+	}
+
+	var keys []plan.Expr
+	for {
+		key, err := parseExpression(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DISTINCT ON expression: %w", err)
+		}
+		if _, ok := key.(*plan.AggregateExpr); ok {
+			return nil, fmt.Errorf("aggregate function %s is not allowed in DISTINCT ON", key)
+		}
+		keys = append(keys, key)
+
+		if !consumeCommaIfPresent(l) {
+			break
+		}
+	}
+
+	if err := expectTokenSequence(l, lexer.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected ) to close DISTINCT ON: %w", err)
+	}
+	return keys, nil
+}
+
 // parseSelectFieldList processes a comma-separated list of fields in the SELECT clause.
 // Stops when it encounters the FROM keyword.
 func parseSelectFieldList(l *lexer.Lexer, p *plan.SelectPlan, firstToken lexer.Token) error {
@@ -95,12 +141,18 @@ func parseSelectFieldList(l *lexer.Lexer, p *plan.SelectPlan, firstToken lexer.T
 			break
 		}
 
-		if err := expectToken(token, lexer.IDENTIFIER); err != nil {
-			return fmt.Errorf("expected field name, got %s", token.Value)
-		}
+		if token.Type == lexer.CASE {
+			if err := parseSelectCase(l, p); err != nil {
+				return err
+			}
+		} else {
+			if err := expectToken(token, lexer.IDENTIFIER); err != nil {
+				return fmt.Errorf("expected field name, got %s", token.Value)
+			}
 
-		if err := parseSelectField(l, p, token); err != nil {
-			return err
+			if err := parseSelectField(l, p, token); err != nil {
+				return err
+			}
 		}
 
 		if !consumeCommaIfPresent(l) {
@@ -117,6 +169,9 @@ func parseSelectFieldList(l *lexer.Lexer, p *plan.SelectPlan, firstToken lexer.T
 // Distinguishes between regular fields (NAME) and aggregate functions (COUNT(ID)).
 func parseSelectField(l *lexer.Lexer, p *plan.SelectPlan, fieldToken lexer.Token) error {
 	nextToken := l.NextToken()
+	if nextToken.Type == lexer.LPAREN && fieldToken.Value == "GROUPING" {
+		return parseSelectGrouping(l, p)
+	}
 	if nextToken.Type == lexer.LPAREN {
 		return parseAggregateFunction(l, p, fieldToken)
 	}
@@ -126,6 +181,48 @@ func parseSelectField(l *lexer.Lexer, p *plan.SelectPlan, fieldToken lexer.Token
 	return nil
 }
 
+// parseSelectCase parses a CASE expression in the SELECT clause, with its
+// optional alias. The CASE keyword has already been consumed.
+//
+// Grammar:
+//
+//	CASE ... END [[AS] alias]
+func parseSelectCase(l *lexer.Lexer, p *plan.SelectPlan) error {
+	caseExpr, err := parseCaseExpression(l)
+	if err != nil {
+		return err
+	}
+
+	name, err := parseSelectAlias(l, caseColumnName)
+	if err != nil {
+		return err
+	}
+
+	p.AddProjectExpr(caseExpr, name)
+	return nil
+}
+
+// parseSelectGrouping parses a GROUPING(expression) call in the SELECT clause,
+// with its optional alias. The opening parenthesis has already been consumed.
+//
+// Grammar:
+//
+//	GROUPING ( expression ) [[AS] alias]
+func parseSelectGrouping(l *lexer.Lexer, p *plan.SelectPlan) error {
+	call, err := parseGroupingCall(l)
+	if err != nil {
+		return err
+	}
+
+	name, err := parseSelectAlias(l, call.String())
+	if err != nil {
+		return err
+	}
+
+	p.AddProjectExpr(call, name)
+	return nil
+}
+
 // parseAggregateFunction parses aggregate function calls in SELECT clause.
 //
 // Grammar:
@@ -136,8 +233,23 @@ func parseSelectField(l *lexer.Lexer, p *plan.SelectPlan, fieldToken lexer.Token
 // Example: COUNT(ID) → adds projection with field "ID" and aggregation operator "COUNT"
 // Example: COUNT(*) → adds projection with field "*" and aggregation operator "COUNT"
 func parseAggregateFunction(l *lexer.Lexer, p *plan.SelectPlan, funcToken lexer.Token) error {
-	aggOp := strings.ToUpper(funcToken.Value)
+	fieldName, err := parseAggregateArgument(l)
+	if err != nil {
+		return err
+	}
+
+	p.AddProjectField(fieldName, strings.ToUpper(funcToken.Value))
+	return nil
+}
 
+// parseAggregateArgument parses the argument of an aggregate function call up
+// to and including its closing parenthesis. The opening parenthesis has
+// already been consumed.
+//
+// Grammar:
+//
+//	IDENTIFIER | * )
+func parseAggregateArgument(l *lexer.Lexer) (string, error) {
 	fieldToken := l.NextToken()
 
 	// Handle COUNT(*) special case
@@ -148,26 +260,30 @@ func parseAggregateFunction(l *lexer.Lexer, p *plan.SelectPlan, funcToken lexer.
 	case lexer.IDENTIFIER:
 		fieldName = strings.ToUpper(fieldToken.Value)
 	default:
-		return fmt.Errorf("expected field name or * in aggregate function, got %s", fieldToken.Value)
+		return "", fmt.Errorf("expected field name or * in aggregate function, got %s", fieldToken.Value)
 	}
 
 	parenToken := l.NextToken()
 	if err := expectToken(parenToken, lexer.RPAREN); err != nil {
-		return fmt.Errorf("expected closing parenthesis in aggregate function")
+		return "", fmt.Errorf("expected closing parenthesis in aggregate function")
 	}
 
-	p.AddProjectField(fieldName, aggOp)
-	return nil
+	return fieldName, nil
 }
 
 // parseGroupBy parses the optional GROUP BY clause.
 //
 // Grammar:
 //
-//	[GROUP BY field]
+//	[GROUP BY field | expression | ROLLUP (...) | CUBE (...) | GROUPING SETS (...)]
 //
 // Example: GROUP BY DEPARTMENT
-// Currently supports grouping by a single field only.
+// Example: GROUP BY CASE WHEN AGE < 18 THEN 'minor' ELSE 'adult' END
+// Example: GROUP BY ROLLUP (DEPARTMENT)
+// Currently supports a single grouping key, which the grouping sets of
+// ROLLUP, CUBE and GROUPING SETS either group by or leave out (see
+// parseGroupingSets). A field may also name a CASE expression of the SELECT
+// list by its alias; the planner resolves it.
 func parseGroupBy(l *lexer.Lexer, p *plan.SelectPlan) error {
 	token := l.NextToken()
 	if token.Type != lexer.GROUP {
@@ -179,12 +295,53 @@ func parseGroupBy(l *lexer.Lexer, p *plan.SelectPlan) error {
 		return fmt.Errorf("expected BY after GROUP, got %s", err)
 	}
 
-	fieldToken := l.NextToken()
-	if err := expectToken(fieldToken, lexer.IDENTIFIER); err != nil {
-		return fmt.Errorf("expected field name in GROUP BY, got %s", fieldToken.Value)
+	sets, ok, err := parseGroupingSets(l)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return setGroupingSets(p, sets)
+	}
+
+	key, err := parseGroupingKey(l)
+	if err != nil {
+		return err
+	}
+	setGroupByKey(p, key)
+	return nil
+}
+
+// setGroupByKey records expr as the GROUP BY key of p.
+func setGroupByKey(p *plan.SelectPlan, expr plan.Expr) {
+	if column, ok := expr.(*plan.ColumnExpr); ok {
+		p.SetGroupBy(column.Name)
+		return
+	}
+	p.SetGroupByExpr(expr)
+}
+
+// parseHaving parses the optional HAVING clause, a condition on the groups
+// produced by GROUP BY. It may reference the grouping key, the aggregate
+// function of the SELECT list and the aliases of the SELECT list.
+//
+// Grammar:
+//
+//	[HAVING expression]
+//
+// Example: HAVING COUNT(ID) > 5
+func parseHaving(l *lexer.Lexer, p *plan.SelectPlan) error {
+	token := l.NextToken()
+	if token.Type != lexer.HAVING {
+		l.SetPos(token.Position)
+		return nil
+	}
+
+	expr, err := parseExpression(l)
+	if err != nil {
+		return fmt.Errorf("invalid HAVING condition: %w", err)
 	}
 
-	p.SetGroupBy(strings.ToUpper(fieldToken.Value))
+	p.SetHaving(expr)
 	return nil
 }
 
@@ -192,10 +349,11 @@ func parseGroupBy(l *lexer.Lexer, p *plan.SelectPlan) error {
 //
 // Grammar:
 //
-//	[ORDER BY field [ASC|DESC]]
+//	[ORDER BY field | CASE ... END [ASC|DESC]]
 //
 // Example: ORDER BY AGE DESC
-// Defaults to ascending if no direction specified.
+// Defaults to ascending if no direction specified. A CASE expression is
+// evaluated against the rows produced by the SELECT list.
 func parseOrderBy(l *lexer.Lexer, p *plan.SelectPlan) error {
 	token := l.NextToken()
 	if token.Type != lexer.ORDER {
@@ -208,7 +366,13 @@ func parseOrderBy(l *lexer.Lexer, p *plan.SelectPlan) error {
 	}
 
 	fieldToken := l.NextToken()
-	if err := expectToken(fieldToken, lexer.IDENTIFIER); err != nil {
+	var caseExpr *plan.CaseExpr
+	if fieldToken.Type == lexer.CASE {
+		var err error
+		if caseExpr, err = parseCaseExpression(l); err != nil {
+			return err
+		}
+	} else if err := expectToken(fieldToken, lexer.IDENTIFIER); err != nil {
 		return fmt.Errorf("expected field name in ORDER BY, got %s", fieldToken.Value)
 	}
 
@@ -220,6 +384,11 @@ func parseOrderBy(l *lexer.Lexer, p *plan.SelectPlan) error {
 		l.SetPos(dirToken.Position)
 	}
 
+	if caseExpr != nil {
+		p.AddOrderByExpr(caseExpr, ascending)
+		return nil
+	}
+
 	p.AddOrderBy(strings.ToUpper(fieldToken.Value), ascending)
 	return nil
 }
@@ -275,12 +444,13 @@ func parseLimit(l *lexer.Lexer, p *plan.SelectPlan) error {
 //
 // Grammar:
 //
-//	FROM table [, table]* | table [JOIN table ON condition]*
+//	FROM table [, table]* | table [JOIN join_item ON condition]*
 //
 // Supports:
 //   - Comma-separated tables (cross join): FROM users, orders
 //   - Explicit JOINs: FROM users INNER JOIN orders ON users.id = orders.user_id
-//   - LEFT/RIGHT OUTER JOINs
+//   - LEFT/RIGHT/FULL OUTER JOINs and CROSS JOINs
+//   - Subqueries, possibly LATERAL: FROM users, LATERAL (SELECT ...) o
 //
 // Tables can have aliases: FROM users u, orders o
 func parseFrom(l *lexer.Lexer, p *plan.SelectPlan) error {
@@ -296,11 +466,21 @@ func parseFrom(l *lexer.Lexer, p *plan.SelectPlan) error {
 		token := l.NextToken()
 		switch token.Type {
 		case lexer.COMMA:
-			// Legacy comma-separated tables (cross join)
-			if err := parseTable(l, p); err != nil {
+			// Legacy comma-separated tables (cross join), or a cross join
+			// with a LATERAL subquery: FROM t, LATERAL (...) x
+			next := l.NextToken()
+			l.SetPos(next.Position)
+
+			var err error
+			if next.Type == lexer.LATERAL {
+				err = parseCrossJoinItem(l, p)
+			} else {
+				err = parseTable(l, p)
+			}
+			if err != nil {
 				return err
 			}
-		case lexer.INNER, lexer.LEFT, lexer.RIGHT, lexer.JOIN:
+		case lexer.INNER, lexer.LEFT, lexer.RIGHT, lexer.FULL, lexer.CROSS, lexer.JOIN:
 			if err := parseJoin(l, p, token); err != nil {
 				return err
 			}
@@ -311,40 +491,189 @@ func parseFrom(l *lexer.Lexer, p *plan.SelectPlan) error {
 	}
 }
 
+// parseAsOf parses the optional AS OF clause that follows the FROM clause and
+// makes the query read its tables as they were at a point in the past.
+//
+// Grammar:
+//
+//	AS OF LSN integer | AS OF TIMESTAMP 'time'
+//
+// The time is RFC 3339 (2006-01-02T15:04:05Z) or 2006-01-02 15:04:05 in
+// local time. OF, LSN and TIMESTAMP are not reserved words, so they are
+// matched as identifiers.
+func parseAsOf(l *lexer.Lexer, p *plan.SelectPlan) error {
+	token := l.NextToken()
+	if token.Type != lexer.AS {
+		l.SetPos(token.Position)
+		return nil
+	}
+	if of := l.NextToken(); of.Type != lexer.IDENTIFIER || of.Value != "OF" {
+		l.SetPos(token.Position)
+		return nil
+	}
+
+	kind := l.NextToken()
+	value := l.NextToken()
+	switch {
+	case kind.Type == lexer.IDENTIFIER && kind.Value == "LSN":
+		if value.Type != lexer.INT {
+			return fmt.Errorf("expected integer after AS OF LSN, got %s", value.Value)
+		}
+		lsn, err := strconv.ParseUint(value.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid AS OF LSN value: %s", value.Value)
+		}
+		p.SetAsOf(&plan.AsOf{LSN: primitives.LSN(lsn)})
+	case kind.Type == lexer.IDENTIFIER && kind.Value == "TIMESTAMP":
+		if value.Type != lexer.STRING {
+			return fmt.Errorf("expected quoted time after AS OF TIMESTAMP, got %s", value.Value)
+		}
+		ts, err := time.Parse(time.RFC3339, value.Value)
+		if err != nil {
+			ts, err = time.ParseInLocation(time.DateTime, value.Value, time.Local)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid AS OF TIMESTAMP value: %s", value.Value)
+		}
+		p.SetAsOf(&plan.AsOf{Time: ts, ByTime: true})
+	default:
+		return fmt.Errorf("expected LSN or TIMESTAMP after AS OF, got %s", kind.Value)
+	}
+	return nil
+}
+
 // parseJoin parses a JOIN clause including the join type, table, and condition.
 //
 // Grammar:
 //
-//	[INNER|LEFT|RIGHT] [OUTER] JOIN table [alias] ON field = field
+//	[INNER|LEFT|RIGHT|FULL] [OUTER] JOIN join_item ON field = field
+//	CROSS JOIN join_item
+//	join_item = table [alias] | [LATERAL] ( SELECT ... ) alias
 //
 // Examples:
 //
 //	INNER JOIN orders ON users.id = orders.user_id
 //	LEFT OUTER JOIN departments d ON e.dept_id = d.id
 //	RIGHT JOIN products p ON o.product_id = p.id
+//	FULL OUTER JOIN shipments s ON o.id = s.order_id
+//	CROSS JOIN sizes
+//	LEFT JOIN LATERAL (SELECT * FROM orders o WHERE o.user_id = u.id) o ON u.id = o.user_id
+//
+// A LATERAL subquery is evaluated for each row to its left, so it cannot be
+// the right side of a RIGHT or FULL join, which would have to keep its rows.
 func parseJoin(l *lexer.Lexer, p *plan.SelectPlan, firstToken lexer.Token) error {
 	joinType, err := parseJoinType(l, firstToken)
 	if err != nil {
 		return err
 	}
+	if joinType == plan.CrossJoin {
+		return parseCrossJoinItem(l, p)
+	}
 
-	tableName, alias, err := parseTableWithAlias(l)
+	item, err := parseJoinItem(l)
 	if err != nil {
-		return fmt.Errorf("error parsing JOIN table: %w", err)
+		return err
+	}
+	if item.lateral && (joinType == plan.RightJoin || joinType == plan.FullJoin) {
+		return fmt.Errorf("LATERAL subquery %s cannot be the right side of a %s JOIN", item.table.Alias, joinType)
 	}
 
-	rightTable := plan.NewScanNode(tableName, alias)
 	cond, err := parseJoinCondition(l)
 	if err != nil {
 		return err
 	}
 
-	p.AddJoin(rightTable, joinType, cond.leftField, cond.rightField, cond.predicate)
+	item.addTo(p, joinType, cond)
+	return nil
+}
+
+// parseCrossJoinItem parses the right side of a CROSS JOIN, which has no
+// join condition.
+func parseCrossJoinItem(l *lexer.Lexer, p *plan.SelectPlan) error {
+	item, err := parseJoinItem(l)
+	if err != nil {
+		return err
+	}
+	item.addTo(p, plan.CrossJoin, &joinCondition{})
 	return nil
 }
 
+// joinItem is the right side of a join: a table or a subquery.
+type joinItem struct {
+	table    *plan.ScanNode   // The table, or just the alias of a subquery
+	subquery *plan.SelectPlan // nil for a table
+	lateral  bool
+}
+
+// addTo adds the join of the item to p.
+func (item *joinItem) addTo(p *plan.SelectPlan, joinType plan.JoinType, cond *joinCondition) {
+	if item.subquery == nil {
+		p.AddJoin(item.table, joinType, cond.leftField, cond.rightField, cond.predicate)
+		return
+	}
+	p.AddSubqueryJoin(item.subquery, item.table.Alias, item.lateral, joinType, cond.leftField, cond.rightField, cond.predicate)
+}
+
+// parseJoinItem parses a table with an optional alias, or a parenthesized
+// subquery, optionally LATERAL, with a required alias.
+//
+// Grammar:
+//
+//	table [alias] | [LATERAL] ( SELECT ... ) alias
+func parseJoinItem(l *lexer.Lexer) (*joinItem, error) {
+	token := l.NextToken()
+	lateral := token.Type == lexer.LATERAL
+	if lateral {
+		token = l.NextToken()
+	}
+
+	if token.Type != lexer.LPAREN {
+		if lateral {
+			return nil, fmt.Errorf("expected ( after LATERAL, got %s", token.Value)
+		}
+		l.SetPos(token.Position)
+		tableName, alias, err := parseTableWithAlias(l)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing JOIN table: %w", err)
+		}
+		return &joinItem{table: plan.NewScanNode(tableName, alias)}, nil
+	}
+
+	subquery, err := parseSubquery(l)
+	if err != nil {
+		return nil, err
+	}
+
+	aliasToken := l.NextToken()
+	if aliasToken.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("subquery in FROM must have an alias, got %s", aliasToken.Value)
+	}
+	return &joinItem{
+		table:    plan.NewScanNode(aliasToken.Value, aliasToken.Value),
+		subquery: subquery,
+		lateral:  lateral,
+	}, nil
+}
+
+// parseSubquery parses a SELECT statement and the closing parenthesis
+// around it; the opening one has already been consumed.
+func parseSubquery(l *lexer.Lexer) (*plan.SelectPlan, error) {
+	stmt, err := parseSelectStatement(l)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing subquery: %w", err)
+	}
+	if stmt.Plan.IsSetOperation() {
+		return nil, fmt.Errorf("set operations are not supported in subqueries")
+	}
+	if err := expectTokenSequence(l, lexer.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected ) after subquery: %w", err)
+	}
+	return stmt.Plan, nil
+}
+
 // parseJoinType determines the type of JOIN operation.
-// Handles INNER JOIN, LEFT [OUTER] JOIN, RIGHT [OUTER] JOIN.
+// Handles INNER JOIN, LEFT [OUTER] JOIN, RIGHT [OUTER] JOIN, FULL [OUTER] JOIN
+// and CROSS JOIN.
 // Returns the corresponding JoinType enum value.
 func parseJoinType(l *lexer.Lexer, first lexer.Token) (plan.JoinType, error) {
 	joinType := plan.InnerJoin
@@ -362,6 +691,14 @@ func parseJoinType(l *lexer.Lexer, first lexer.Token) (plan.JoinType, error) {
 		joinType = plan.RightJoin
 		err = parseOptionalOuterJoin(l)
 
+	case lexer.FULL:
+		joinType = plan.FullJoin
+		err = parseOptionalOuterJoin(l)
+
+	case lexer.CROSS:
+		joinType = plan.CrossJoin
+		err = expectTokenSequence(l, lexer.JOIN)
+
 	}
 
 	return joinType, err
@@ -396,14 +733,21 @@ func parseTable(l *lexer.Lexer, p *plan.SelectPlan) error {
 // Grammar:
 //
 //	[WHERE condition [AND condition]*]
-//	condition = field OPERATOR value
+//	condition = field OPERATOR value | field OPERATOR CASE ... END | CASE ... END [OPERATOR value]
+//	          | [NOT] EXISTS ( SELECT ... ) | field [NOT] IN ( SELECT ... )
+//	value     = STRING | INT | IDENTIFIER | PARAMETER
 //
 // Examples:
 //
 //	WHERE AGE > 18
 //	WHERE NAME = 'John' AND STATUS = 'ACTIVE'
+//	WHERE NAME LIKE 'Jo%' AND EMAIL REGEXP '@example\.com$'
+//	WHERE CASE WHEN AGE < 18 THEN 'MINOR' ELSE 'ADULT' END = 'ADULT'
+//	WHERE AGE > $1 AND NAME LIKE $2
+//	WHERE NOT EXISTS (SELECT * FROM ORDERS O WHERE O.USER_ID = U.ID)
+//	WHERE ID IN (SELECT USER_ID FROM ORDERS)
 //
-// Supports operators: =, !=, <, >, <=, >=
+// Supports operators: =, !=, <, >, <=, >=, LIKE, ILIKE, REGEXP
 func parseWhere(l *lexer.Lexer, p *plan.SelectPlan) error {
 	token := l.NextToken()
 	if token.Type != lexer.WHERE {
@@ -446,6 +790,9 @@ func parseJoinCondition(l *lexer.Lexer) (*joinCondition, error) {
 	if err != nil {
 		return nil, err
 	}
+	if predicate.IsPatternMatch() {
+		return nil, fmt.Errorf("%s is not supported in JOIN conditions", predicate)
+	}
 
 	cond := &joinCondition{
 		leftField:  strings.ToUpper(leftFieldToken.Value),
@@ -459,48 +806,147 @@ func parseJoinCondition(l *lexer.Lexer) (*joinCondition, error) {
 // Each condition follows the pattern: field OPERATOR value
 //
 // Stops when encountering a token that's not part of a condition (e.g., GROUP, ORDER, EOF).
-// All field names and values are normalized to uppercase.
+// All field names and values are normalized to uppercase, except the letters
+// of a REGEXP pattern that upper-casing would change the meaning of.
 func parseConditions(l *lexer.Lexer, p *plan.SelectPlan) error {
 	for {
 		fieldToken := l.NextToken()
-		if fieldToken.Type != lexer.IDENTIFIER {
+		switch fieldToken.Type {
+		case lexer.IDENTIFIER, lexer.CASE, lexer.EXISTS, lexer.NOT:
+		default:
 			l.SetPos(fieldToken.Position)
-			break
+			return nil
 		}
 
-		opToken := l.NextToken()
-		if err := expectToken(opToken, lexer.OPERATOR); err != nil {
-			return fmt.Errorf("expected operator, got %s", opToken.Value)
+		if err := parseCondition(l, p, fieldToken); err != nil {
+			return err
 		}
 
-		valueToken := l.NextToken()
-		var value string
+		nextToken := l.NextToken()
+		if nextToken.Type == lexer.AND {
+			continue
+		} else {
+			l.SetPos(nextToken.Position)
+			break
+		}
+	}
 
-		switch valueToken.Type {
-		case lexer.STRING, lexer.BOOLEAN, lexer.INT, lexer.IDENTIFIER:
-			value = valueToken.Value
-		default:
-			return fmt.Errorf("expected value, got %s", valueToken.Value)
+	return nil
+}
+
+// parseCondition parses a single filter condition starting at fieldToken and
+// adds it to the plan. Conditions involving a CASE expression are added as
+// expression filters.
+func parseCondition(l *lexer.Lexer, p *plan.SelectPlan, fieldToken lexer.Token) error {
+	switch fieldToken.Type {
+	case lexer.CASE:
+		expr, err := parseCaseCondition(l, fieldToken, nil)
+		if err != nil {
+			return err
 		}
+		p.AddExprFilter(expr)
+		return nil
+	case lexer.EXISTS, lexer.NOT:
+		return parseExistsCondition(l, p, fieldToken)
+	}
+
+	opToken := l.NextToken()
+	if opToken.Type == lexer.IN || opToken.Type == lexer.NOT {
+		return parseInCondition(l, p, fieldToken, opToken)
+	}
+	if err := expectToken(opToken, lexer.OPERATOR); err != nil {
+		return fmt.Errorf("expected operator, got %s", opToken.Value)
+	}
 
-		pred, err := parseOperator(opToken.Value)
+	valueToken := l.NextToken()
+	switch valueToken.Type {
+	case lexer.CASE:
+		expr, err := parseCaseCondition(l, fieldToken, &opToken)
 		if err != nil {
 			return err
 		}
+		p.AddExprFilter(expr)
+		return nil
+	case lexer.STRING, lexer.BOOLEAN, lexer.INT, lexer.IDENTIFIER, lexer.PARAMETER:
+	default:
+		return fmt.Errorf("expected value, got %s", valueToken.Value)
+	}
 
-		if err := p.AddFilter(strings.ToUpper(fieldToken.Value), pred, strings.ToUpper(value)); err != nil {
+	pred, err := parseOperator(opToken.Value)
+	if err != nil {
+		return err
+	}
+
+	if valueToken.Type == lexer.PARAMETER {
+		param, err := parseParameter(valueToken)
+		if err != nil {
 			return err
 		}
+		return p.AddParamFilter(strings.ToUpper(fieldToken.Value), pred, param)
+	}
 
-		nextToken := l.NextToken()
-		if nextToken.Type == lexer.AND {
-			continue
-		} else {
-			l.SetPos(nextToken.Position)
-			break
+	value, err := parseConditionValue(l, valueToken, pred)
+	if err != nil {
+		return err
+	}
+
+	if valueToken.Type == lexer.IDENTIFIER && strings.Contains(value, ".") && !pred.IsPatternMatch() {
+		return p.AddRefFilter(strings.ToUpper(fieldToken.Value), pred, strings.ToUpper(value))
+	}
+	return p.AddFilter(strings.ToUpper(fieldToken.Value), pred, value)
+}
+
+// parseExistsCondition parses [NOT] EXISTS ( SELECT ... ), starting at
+// first, the NOT or EXISTS keyword.
+func parseExistsCondition(l *lexer.Lexer, p *plan.SelectPlan, first lexer.Token) error {
+	negated := first.Type == lexer.NOT
+	if negated {
+		if err := expectTokenSequence(l, lexer.EXISTS); err != nil {
+			return fmt.Errorf("expected EXISTS after NOT: %w", err)
 		}
 	}
+	if err := expectTokenSequence(l, lexer.LPAREN); err != nil {
+		return fmt.Errorf("expected ( after EXISTS: %w", err)
+	}
 
+	subquery, err := parseSubquery(l)
+	if err != nil {
+		return err
+	}
+	p.AddSubqueryFilter(&plan.SubqueryFilter{Exists: true, Negated: negated, Subquery: subquery})
+	return nil
+}
+
+// parseInCondition parses field [NOT] IN ( SELECT ... ), where next is the
+// NOT or IN keyword after the field. The subquery must return one column.
+func parseInCondition(l *lexer.Lexer, p *plan.SelectPlan, fieldToken, next lexer.Token) error {
+	negated := next.Type == lexer.NOT
+	if negated {
+		if err := expectTokenSequence(l, lexer.IN); err != nil {
+			return fmt.Errorf("expected IN after NOT: %w", err)
+		}
+	}
+	if err := expectTokenSequence(l, lexer.LPAREN); err != nil {
+		return fmt.Errorf("expected ( after IN: %w", err)
+	}
+	selectToken := l.NextToken()
+	if selectToken.Type != lexer.SELECT {
+		return fmt.Errorf("IN requires a subquery, got %s", selectToken.Value)
+	}
+	l.SetPos(selectToken.Position)
+
+	subquery, err := parseSubquery(l)
+	if err != nil {
+		return err
+	}
+	if subquery.SelectAll() || len(subquery.SelectList()) != 1 {
+		return fmt.Errorf("subquery of IN must return exactly one column")
+	}
+	p.AddSubqueryFilter(&plan.SubqueryFilter{
+		Negated:  negated,
+		Field:    strings.ToUpper(fieldToken.Value),
+		Subquery: subquery,
+	})
 	return nil
 }
 
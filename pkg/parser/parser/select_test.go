@@ -4,7 +4,10 @@ import (
 	"storemy/pkg/parser/lexer"
 	"storemy/pkg/parser/statements"
 	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"strings"
 	"testing"
+	"time"
 )
 
 // SELECT statement tests
@@ -496,6 +499,106 @@ func TestParseGroupBy_WithGroupBy(t *testing.T) {
 	}
 }
 
+func TestParseGroupBy_Expression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedField string
+		isExpr        bool
+	}{
+		{"GROUP BY users.dept", "USERS.DEPT", false},
+		{"GROUP BY band", "BAND", false},
+		{"GROUP BY CASE WHEN age < 18 THEN 'minor' ELSE 'adult' END", "CASE WHEN AGE < 18 THEN 'MINOR' ELSE 'ADULT' END", true},
+		{"GROUP BY salary >= 100", "SALARY >= 100", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			selectPlan := plan.NewSelectPlan()
+			if err := parseGroupBy(NewLexer(tt.input), selectPlan); err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if selectPlan.GroupByField() != tt.expectedField {
+				t.Errorf("expected group by %s, got %s", tt.expectedField, selectPlan.GroupByField())
+			}
+			if (selectPlan.GroupByExpr() != nil) != tt.isExpr {
+				t.Errorf("expected GroupByExpr set = %v, got %v", tt.isExpr, selectPlan.GroupByExpr())
+			}
+		})
+	}
+
+	if err := parseGroupBy(NewLexer("GROUP BY COUNT(id)"), plan.NewSelectPlan()); err == nil {
+		t.Error("expected an aggregate in GROUP BY to fail")
+	}
+}
+
+func TestParseStatement_SelectWithHaving(t *testing.T) {
+	stmt, err := ParseStatement("SELECT dept, COUNT(id) FROM staff GROUP BY dept HAVING COUNT(id) > 1 AND dept <> 'ops' ORDER BY dept LIMIT 3")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	p := stmt.(*statements.SelectStatement).Plan
+	if p.GroupByField() != "DEPT" {
+		t.Errorf("expected GROUP BY DEPT, got %s", p.GroupByField())
+	}
+
+	expected := "(COUNT(ID) > 1 AND DEPT != 'OPS')"
+	if p.Having() == nil || p.Having().String() != expected {
+		t.Errorf("expected HAVING %s, got %v", expected, p.Having())
+	}
+	if !p.HasOrderBy() || p.OrderByField() != "DEPT" || !p.HasLimit() {
+		t.Error("expected ORDER BY and LIMIT after HAVING")
+	}
+
+	if _, err := ParseStatement("SELECT dept, COUNT(id) FROM staff GROUP BY dept HAVING"); err == nil {
+		t.Error("expected an empty HAVING clause to fail")
+	}
+}
+
+func TestParseStatement_SelectDistinctOn(t *testing.T) {
+	stmt, err := ParseStatement("SELECT DISTINCT ON (dept, CASE WHEN salary > 100 THEN 'high' END) name, salary FROM staff ORDER BY salary DESC")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	p := stmt.(*statements.SelectStatement).Plan
+	if !p.IsDistinct() {
+		t.Error("expected DISTINCT ON to mark the plan distinct")
+	}
+
+	keys := p.DistinctOn()
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 DISTINCT ON keys, got %d", len(keys))
+	}
+	if keys[0].String() != "DEPT" {
+		t.Errorf("expected first key DEPT, got %s", keys[0])
+	}
+	if _, ok := keys[1].(*plan.CaseExpr); !ok {
+		t.Errorf("expected second key to be a CASE expression, got %T", keys[1])
+	}
+	if len(p.SelectList()) != 2 || p.OrderByField() != "SALARY" || p.OrderByAsc() {
+		t.Error("expected select list and ORDER BY after DISTINCT ON")
+	}
+
+	plain := parseSelectPlan(t, "SELECT DISTINCT dept, name FROM staff")
+	if !plain.IsDistinct() || plain.DistinctOn() != nil {
+		t.Error("expected plain multi-column DISTINCT without keys")
+	}
+
+	invalid := []string{
+		"SELECT DISTINCT ON dept name FROM staff",
+		"SELECT DISTINCT ON () name FROM staff",
+		"SELECT DISTINCT ON (dept name FROM staff",
+		"SELECT DISTINCT ON (COUNT(id)) name FROM staff",
+	}
+	for _, sql := range invalid {
+		if _, err := ParseStatement(sql); err == nil {
+			t.Errorf("expected %q to fail", sql)
+		}
+	}
+}
+
 func TestParseOrderBy_NoOrderBy(t *testing.T) {
 	lexer := NewLexer("EOF")
 	selectPlan := plan.NewSelectPlan()
@@ -630,3 +733,117 @@ func TestParseStatement_SelectWithMultipleUnqualifiedConditions(t *testing.T) {
 		t.Errorf("expected second field name 'USERS.ID', got %s", filter2.Field)
 	}
 }
+
+func TestParseStatement_SelectWithPatternPredicates(t *testing.T) {
+	tests := []struct {
+		sql      string
+		pred     primitives.Predicate
+		constant string
+	}{
+		{"SELECT name FROM users WHERE name LIKE 'al%'", primitives.Like, "AL%"},
+		{"SELECT name FROM users WHERE name ILIKE 'Al_ce'", primitives.ILike, "AL_CE"},
+		{`SELECT name FROM users WHERE name REGEXP '^al\d+\s?$'`, primitives.Regexp, `^AL\d+\s?$`},
+		{"SELECT name FROM users WHERE name REGEXP '(?i)^al'", primitives.Regexp, "(?i)^AL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.sql)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			filter := stmt.(*statements.SelectStatement).Plan.Filters()[0]
+			if filter.Predicate != tt.pred {
+				t.Errorf("expected predicate %s, got %s", tt.pred, filter.Predicate)
+			}
+			if filter.Constant != tt.constant {
+				t.Errorf("expected constant %q, got %q", tt.constant, filter.Constant)
+			}
+		})
+	}
+}
+
+func TestParseStatement_PatternPredicateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		errMsg string
+	}{
+		{"Non-string pattern", "SELECT name FROM users WHERE name LIKE 5", "LIKE requires a string pattern"},
+		{"Invalid regexp", "SELECT name FROM users WHERE name REGEXP '(ab'", "invalid regular expression"},
+		{"Join condition", "SELECT * FROM a JOIN b ON a.name LIKE b.name", "LIKE is not supported in JOIN conditions"},
+		{"Delete with invalid regexp", "DELETE FROM users WHERE name REGEXP '[a-'", "invalid regular expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(tt.sql)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseStatement_SelectAsOf(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+		where    bool
+	}{
+		{"SELECT * FROM users AS OF LSN 4096", "LSN 4096", false},
+		{"SELECT name FROM users u AS OF LSN 12 WHERE u.id = 1", "LSN 12", true},
+		{"SELECT * FROM users AS OF TIMESTAMP '2024-05-01T10:00:00Z'", "TIMESTAMP '2024-05-01T10:00:00Z'", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.sql)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			p := stmt.(*statements.SelectStatement).Plan
+			if p.AsOf() == nil || p.AsOf().String() != tt.expected {
+				t.Errorf("expected AS OF %s, got %v", tt.expected, p.AsOf())
+			}
+			if got := len(p.Filters()) > 0; got != tt.where {
+				t.Errorf("expected WHERE %v, got %v", tt.where, got)
+			}
+		})
+	}
+
+	stmt, err := ParseStatement("SELECT * FROM users AS OF TIMESTAMP '2024-05-01 10:00:00'")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	asOf := stmt.(*statements.SelectStatement).Plan.AsOf()
+	if expected := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local); !asOf.ByTime || !asOf.Time.Equal(expected) {
+		t.Errorf("expected local time %v, got %v", expected, asOf.Time)
+	}
+}
+
+func TestParseStatement_SelectAsOfErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		errMsg string
+	}{
+		{"Missing kind", "SELECT * FROM users AS OF 10", "expected LSN or TIMESTAMP"},
+		{"Non-integer LSN", "SELECT * FROM users AS OF LSN 'abc'", "expected integer after AS OF LSN"},
+		{"Invalid time", "SELECT * FROM users AS OF TIMESTAMP 'yesterday'", "invalid AS OF TIMESTAMP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(tt.sql)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
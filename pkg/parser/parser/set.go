@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+)
+
+// parseSetStatement parses a SET PERSISTENT statement from the lexer.
+// It changes a database-wide setting stored in the superblock.
+//
+// Syntax:
+//
+//	SET PERSISTENT setting_name = value
+//
+// The value may be an integer, a quoted string (e.g. '30s' for durations),
+// or a bare word such as TRUE or FALSE.
+//
+// Parameters:
+//   - l: Lexer instance positioned at the SET token
+//
+// Returns:
+//   - statements.Statement: A SetPersistentStatement with the setting name and value
+//   - error: Returns an error if parsing fails or the statement is invalid
+func parseSetStatement(l *lexer.Lexer) (statements.Statement, error) {
+	if err := expectTokenSequence(l, lexer.SET, lexer.PERSISTENT); err != nil {
+		return nil, err
+	}
+
+	name, value, err := parseSettingAssignment(l, "SET PERSISTENT")
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := statements.NewSetPersistentStatement(name, value)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseSetRuntimeStatement parses a SET statement changing a setting of the
+// running database, which is not persisted.
+//
+// Syntax:
+//
+//	SET setting_name = value
+func parseSetRuntimeStatement(l *lexer.Lexer) (statements.Statement, error) {
+	if err := expectTokenSequence(l, lexer.SET); err != nil {
+		return nil, err
+	}
+
+	name, value, err := parseSettingAssignment(l, "SET")
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := statements.NewSetStatement(name, value)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseSettingAssignment parses the "setting_name = value" of a SET
+// statement; keyword names the statement in errors.
+func parseSettingAssignment(l *lexer.Lexer, keyword string) (name, value string, err error) {
+	name, err = parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return "", "", fmt.Errorf("expected setting name after %s: %w", keyword, err)
+	}
+
+	token := l.NextToken()
+	if token.Type != lexer.OPERATOR || token.Value != "=" {
+		return "", "", fmt.Errorf("expected = after setting name, got %s", token.Value)
+	}
+
+	value, err = parseValueWithType(l, lexer.INT, lexer.STRING, lexer.IDENTIFIER)
+	if err != nil {
+		return "", "", fmt.Errorf("expected value for setting %s: %w", name, err)
+	}
+	return name, value, nil
+}
+
+// parseSetTransactionStatement parses a SET TRANSACTION SNAPSHOT statement,
+// which imports a snapshot exported by another transaction.
+// Expects the format:
+//
+//	SET TRANSACTION SNAPSHOT 'token'
+//
+// SNAPSHOT is not a reserved word, so it is matched as an identifier.
+func parseSetTransactionStatement(l *lexer.Lexer) (*statements.SetTransactionSnapshotStatement, error) {
+	if err := expectTokenSequence(l, lexer.SET, lexer.TRANSACTION); err != nil {
+		return nil, err
+	}
+
+	token := l.NextToken()
+	if token.Type != lexer.IDENTIFIER || token.Value != "SNAPSHOT" {
+		return nil, fmt.Errorf("expected SNAPSHOT after SET TRANSACTION, got %s", token.Value)
+	}
+
+	// Tokens are upper-case hex, so upper-casing the literal keeps it valid
+	token = l.NextToken()
+	if token.Type != lexer.STRING {
+		return nil, fmt.Errorf("expected quoted snapshot token, got %s", token.Value)
+	}
+	if end := l.NextToken(); end.Type != lexer.EOF && end.Type != lexer.SEMICOLON {
+		return nil, fmt.Errorf("unexpected %s after snapshot token", end.Value)
+	}
+
+	stmt := statements.NewSetTransactionSnapshotStatement(token.Value)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"storemy/pkg/parser/statements"
+	"testing"
+)
+
+func TestParseSetPersistentStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		wantErr bool
+		setting string
+		value   string
+	}{
+		{
+			name:    "Integer value",
+			sql:     "SET PERSISTENT wal_buffer_size = 16384",
+			setting: "WAL_BUFFER_SIZE",
+			value:   "16384",
+		},
+		{
+			name:    "Quoted duration value",
+			sql:     "SET PERSISTENT checkpoint_interval = '30s'",
+			setting: "CHECKPOINT_INTERVAL",
+			value:   "30S",
+		},
+		{
+			name:    "Bare word value",
+			sql:     "SET PERSISTENT checkpoint_enabled = false",
+			setting: "CHECKPOINT_ENABLED",
+			value:   "FALSE",
+		},
+		{
+			name:    "Missing equals",
+			sql:     "SET PERSISTENT wal_buffer_size 16384",
+			wantErr: true,
+		},
+		{
+			name:    "Missing value",
+			sql:     "SET PERSISTENT wal_buffer_size =",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.sql)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			setStmt, ok := stmt.(*statements.SetPersistentStatement)
+			if !ok {
+				t.Fatalf("expected *SetPersistentStatement, got %T", stmt)
+			}
+			if setStmt.GetType() != statements.SetPersistent {
+				t.Errorf("expected type SetPersistent, got %v", setStmt.GetType())
+			}
+			if setStmt.Name != tt.setting {
+				t.Errorf("expected setting %s, got %s", tt.setting, setStmt.Name)
+			}
+			if setStmt.Value != tt.value {
+				t.Errorf("expected value %s, got %s", tt.value, setStmt.Value)
+			}
+		})
+	}
+}
+
+func TestParseSetRuntimeStatement(t *testing.T) {
+	stmt, err := ParseStatement("SET buffer_pool_size = 500")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	setStmt, ok := stmt.(*statements.SetStatement)
+	if !ok {
+		t.Fatalf("expected *SetStatement, got %T", stmt)
+	}
+	if setStmt.GetType() != statements.Set || setStmt.Name != "BUFFER_POOL_SIZE" || setStmt.Value != "500" {
+		t.Errorf("unexpected statement %s", setStmt)
+	}
+
+	for _, sql := range []string{"SET buffer_pool_size 500", "SET buffer_pool_size =", "SET = 500"} {
+		if _, err := ParseStatement(sql); err == nil {
+			t.Errorf("expected error parsing %q", sql)
+		}
+	}
+}
+
+func TestParseSetTransactionSnapshotStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		wantErr bool
+		token   string
+	}{
+		{
+			name:  "Quoted token",
+			sql:   "SET TRANSACTION SNAPSHOT '0000000A-00000001'",
+			token: "0000000A-00000001",
+		},
+		{
+			name:  "Lower case token",
+			sql:   "set transaction snapshot '0000000a-00000001';",
+			token: "0000000A-00000001",
+		},
+		{
+			name:    "Missing SNAPSHOT",
+			sql:     "SET TRANSACTION '0000000A-00000001'",
+			wantErr: true,
+		},
+		{
+			name:    "Unquoted token",
+			sql:     "SET TRANSACTION SNAPSHOT abc",
+			wantErr: true,
+		},
+		{
+			name:    "Empty token",
+			sql:     "SET TRANSACTION SNAPSHOT ''",
+			wantErr: true,
+		},
+		{
+			name:    "Trailing tokens",
+			sql:     "SET TRANSACTION SNAPSHOT 'a' 'b'",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.sql)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			snapStmt, ok := stmt.(*statements.SetTransactionSnapshotStatement)
+			if !ok {
+				t.Fatalf("expected *SetTransactionSnapshotStatement, got %T", stmt)
+			}
+			if snapStmt.Token != tt.token {
+				t.Errorf("expected token %s, got %s", tt.token, snapStmt.Token)
+			}
+		})
+	}
+}
+
+func TestParseShowStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		wantErr   bool
+		wantType  statements.StatementType
+		wantValue string // table name for SHOW INDEXES, setting name for SHOW PERSISTENT
+	}{
+		{
+			name:     "SHOW INDEXES",
+			sql:      "SHOW INDEXES",
+			wantType: statements.ShowIndexes,
+		},
+		{
+			name:      "SHOW INDEXES FROM table",
+			sql:       "SHOW INDEXES FROM users",
+			wantType:  statements.ShowIndexes,
+			wantValue: "USERS",
+		},
+		{
+			name:     "SHOW PERSISTENT",
+			sql:      "SHOW PERSISTENT",
+			wantType: statements.ShowPersistent,
+		},
+		{
+			name:      "SHOW PERSISTENT setting",
+			sql:       "SHOW PERSISTENT page_size",
+			wantType:  statements.ShowPersistent,
+			wantValue: "PAGE_SIZE",
+		},
+		{
+			name:    "SHOW unknown",
+			sql:     "SHOW TABLES",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.sql)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stmt.GetType() != tt.wantType {
+				t.Fatalf("expected type %v, got %v", tt.wantType, stmt.GetType())
+			}
+
+			switch s := stmt.(type) {
+			case *statements.ShowIndexesStatement:
+				if s.TableName != tt.wantValue {
+					t.Errorf("expected table %q, got %q", tt.wantValue, s.TableName)
+				}
+			case *statements.ShowPersistentStatement:
+				if s.Name != tt.wantValue {
+					t.Errorf("expected setting %q, got %q", tt.wantValue, s.Name)
+				}
+			default:
+				t.Errorf("unexpected statement type %T", stmt)
+			}
+		})
+	}
+}
@@ -12,21 +12,35 @@ import (
 // Syntax:
 //
 //	SHOW INDEXES [FROM table_name]
-//
-// Options:
-//   - FROM table_name: If specified, shows only indexes for the specified table
+//	SHOW PERSISTENT [setting_name]
 //
 // Parameters:
 //   - l: Lexer instance positioned at the SHOW token
 //
 // Returns:
-//   - statements.Statement: A ShowIndexesStatement containing the optional table name
+//   - statements.Statement: A ShowIndexesStatement or ShowPersistentStatement
 //   - error: Returns an error if parsing fails or the statement is invalid
 func parseShowStatement(l *lexer.Lexer) (statements.Statement, error) {
-	if err := expectTokenSequence(l, lexer.SHOW, lexer.INDEXES); err != nil {
+	if err := expectToken(l.NextToken(), lexer.SHOW); err != nil {
 		return nil, err
 	}
 
+	token := l.NextToken()
+	switch token.Type {
+	case lexer.INDEXES:
+		return parseShowIndexes(l)
+	case lexer.PERSISTENT:
+		return parseShowPersistent(l)
+	default:
+		return nil, fmt.Errorf("expected INDEXES or PERSISTENT after SHOW, got %s", token.Value)
+	}
+}
+
+// parseShowIndexes parses the remainder of SHOW INDEXES [FROM table_name].
+//
+// Options:
+//   - FROM table_name: If specified, shows only indexes for the specified table
+func parseShowIndexes(l *lexer.Lexer) (statements.Statement, error) {
 	tableName := ""
 	token := l.NextToken()
 	if token.Type == lexer.FROM {
@@ -41,3 +55,17 @@ func parseShowStatement(l *lexer.Lexer) (statements.Statement, error) {
 
 	return statements.NewShowIndexesStatement(tableName), nil
 }
+
+// parseShowPersistent parses the remainder of SHOW PERSISTENT [setting_name].
+// Without a setting name every persistent setting is listed.
+func parseShowPersistent(l *lexer.Lexer) (statements.Statement, error) {
+	name := ""
+	token := l.NextToken()
+	if token.Type == lexer.IDENTIFIER {
+		name = token.Value
+	} else {
+		l.SetPos(token.Position)
+	}
+
+	return statements.NewShowPersistentStatement(name), nil
+}
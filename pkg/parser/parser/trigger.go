@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+)
+
+// parseCreateTriggerStatement parses a CREATE TRIGGER SQL statement from the lexer tokens.
+// Syntax: CREATE TRIGGER [IF NOT EXISTS] trigger_name {BEFORE|AFTER} {INSERT|UPDATE|DELETE}
+// ON table_name EXECUTE FUNCTION function_name[()]
+func parseCreateTriggerStatement(l *lexer.Lexer) (*statements.CreateTriggerStatement, error) {
+	if err := expectTokenSequence(l, lexer.CREATE, lexer.TRIGGER); err != nil {
+		return nil, err
+	}
+
+	ifNotExists, err := parseIfNotExists(l)
+	if err != nil {
+		return nil, err
+	}
+
+	triggerName, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected trigger name: %w", err)
+	}
+
+	timing, err := parseValueWithType(l, lexer.BEFORE, lexer.AFTER)
+	if err != nil {
+		return nil, fmt.Errorf("expected BEFORE or AFTER: %w", err)
+	}
+
+	event, err := parseValueWithType(l, lexer.INSERT, lexer.UPDATE, lexer.DELETE)
+	if err != nil {
+		return nil, fmt.Errorf("expected INSERT, UPDATE or DELETE: %w", err)
+	}
+
+	if err := expectTokenSequence(l, lexer.ON); err != nil {
+		return nil, err
+	}
+
+	tableName, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected table name: %w", err)
+	}
+
+	if err := expectTokenSequence(l, lexer.EXECUTE, lexer.FUNCTION); err != nil {
+		return nil, err
+	}
+
+	functionName, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected function name: %w", err)
+	}
+
+	token := l.NextToken()
+	if token.Type == lexer.LPAREN {
+		if err := expectTokenSequence(l, lexer.RPAREN); err != nil {
+			return nil, fmt.Errorf("trigger functions take no arguments: %w", err)
+		}
+	} else {
+		l.SetPos(token.Position)
+	}
+
+	stmt := statements.NewCreateTriggerStatement(triggerName, timing, event, tableName, functionName, ifNotExists)
+	if err := stmt.Validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseDropTriggerStatement parses a DROP TRIGGER SQL statement from the lexer tokens.
+// Syntax: DROP TRIGGER [IF EXISTS] trigger_name
+func parseDropTriggerStatement(l *lexer.Lexer) (*statements.DropTriggerStatement, error) {
+	if err := expectTokenSequence(l, lexer.DROP, lexer.TRIGGER); err != nil {
+		return nil, err
+	}
+
+	ifExists, err := parseIfExists(l)
+	if err != nil {
+		return nil, err
+	}
+
+	triggerName, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected trigger name: %w", err)
+	}
+
+	return statements.NewDropTriggerStatement(triggerName, ifExists), nil
+}
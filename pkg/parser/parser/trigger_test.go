@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"storemy/pkg/parser/statements"
+	"strings"
+	"testing"
+)
+
+// CREATE TRIGGER / DROP TRIGGER statement tests
+func TestParseStatement_CreateTrigger(t *testing.T) {
+	stmt, err := ParseStatement("CREATE TRIGGER IF NOT EXISTS audit_orders AFTER INSERT ON orders EXECUTE FUNCTION audit()")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	triggerStmt, ok := stmt.(*statements.CreateTriggerStatement)
+	if !ok {
+		t.Fatalf("expected CreateTriggerStatement, got %T", stmt)
+	}
+
+	if triggerStmt.TriggerName != "AUDIT_ORDERS" {
+		t.Errorf("expected trigger name 'AUDIT_ORDERS', got %s", triggerStmt.TriggerName)
+	}
+	if triggerStmt.Timing != "AFTER" || triggerStmt.Event != "INSERT" {
+		t.Errorf("expected AFTER INSERT, got %s %s", triggerStmt.Timing, triggerStmt.Event)
+	}
+	if triggerStmt.TableName != "ORDERS" {
+		t.Errorf("expected table name 'ORDERS', got %s", triggerStmt.TableName)
+	}
+	if triggerStmt.FunctionName != "AUDIT" {
+		t.Errorf("expected function name 'AUDIT', got %s", triggerStmt.FunctionName)
+	}
+	if !triggerStmt.IfNotExists {
+		t.Error("expected IfNotExists to be true")
+	}
+
+	stmt, err = ParseStatement("CREATE TRIGGER t1 BEFORE DELETE ON orders EXECUTE FUNCTION keep")
+	if err != nil {
+		t.Fatalf("unexpected error without parentheses: %s", err.Error())
+	}
+	if s := stmt.(*statements.CreateTriggerStatement); s.Timing != "BEFORE" || s.Event != "DELETE" {
+		t.Errorf("expected BEFORE DELETE, got %s %s", s.Timing, s.Event)
+	}
+}
+
+func TestParseStatement_CreateTriggerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		errMsg string
+	}{
+		{
+			name:   "Missing timing",
+			sql:    "CREATE TRIGGER t1 INSERT ON orders EXECUTE FUNCTION audit",
+			errMsg: "expected BEFORE or AFTER",
+		},
+		{
+			name:   "Invalid event",
+			sql:    "CREATE TRIGGER t1 AFTER SELECT ON orders EXECUTE FUNCTION audit",
+			errMsg: "expected INSERT, UPDATE or DELETE",
+		},
+		{
+			name:   "Missing FUNCTION",
+			sql:    "CREATE TRIGGER t1 AFTER INSERT ON orders EXECUTE audit",
+			errMsg: "expected FUNCTION",
+		},
+		{
+			name:   "Function arguments",
+			sql:    "CREATE TRIGGER t1 AFTER INSERT ON orders EXECUTE FUNCTION audit(1)",
+			errMsg: "trigger functions take no arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(tt.sql)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseStatement_DropTrigger(t *testing.T) {
+	stmt, err := ParseStatement("DROP TRIGGER IF EXISTS audit_orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	dropStmt, ok := stmt.(*statements.DropTriggerStatement)
+	if !ok {
+		t.Fatalf("expected DropTriggerStatement, got %T", stmt)
+	}
+	if dropStmt.TriggerName != "AUDIT_ORDERS" || !dropStmt.IfExists {
+		t.Errorf("unexpected statement: %+v", dropStmt)
+	}
+}
@@ -52,7 +52,7 @@ func parseOptionalWhereClause(l *lexer.Lexer, stmt *statements.UpdateStatement)
 
 // parseSetClauses parses one or more SET clauses in an UPDATE statement.
 // Expects the format: column1=value1, column2=value2, ...
-// Each clause must be a column name followed by '=' and a value.
+// Each clause must be a column name followed by '=' and a value or bind parameter.
 func parseSetClauses(l *lexer.Lexer, stmt *statements.UpdateStatement) error {
 	for {
 		fieldName, err := parseValueWithType(l, lexer.IDENTIFIER)
@@ -69,12 +69,16 @@ func parseSetClauses(l *lexer.Lexer, stmt *statements.UpdateStatement) error {
 			return fmt.Errorf("expected '=', got %s", opValue)
 		}
 
-		value, err := parseValue(l)
+		value, err := parseValueOrParam(l)
 		if err != nil {
 			return err
 		}
 
-		stmt.AddSetClause(fieldName, value)
+		if value.param != 0 {
+			stmt.AddParamSetClause(fieldName, value.param)
+		} else {
+			stmt.AddSetClause(fieldName, value.field)
+		}
 		token := l.NextToken()
 		if token.Type == lexer.COMMA {
 			continue
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"fmt"
+	"storemy/pkg/parser/lexer"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/plan"
+)
+
+// parseWithStatement parses a SELECT statement preceded by a WITH clause,
+// which names queries the statement can read like tables.
+//
+// Syntax:
+//
+//	WITH [RECURSIVE] name [(column, ...)] AS (select) [, ...] select
+//
+// With RECURSIVE, a query that refers to its own name is recursive. It must
+// have the form anchor UNION [ALL] recursive_term, where the anchor does not
+// refer to the name and the recursive term refers to it exactly once, in its
+// FROM clause.
+//
+// Example:
+//
+//	WITH RECURSIVE reports(id) AS (
+//	    SELECT id FROM employees WHERE manager_id = 1
+//	    UNION
+//	    SELECT e.id FROM employees e JOIN reports r ON e.manager_id = r.id
+//	) SELECT * FROM reports
+//
+// Parameters:
+//   - l: Lexer instance positioned at the WITH token
+//
+// Returns:
+//   - *statements.SelectStatement: The SELECT statement, whose plan holds the CTEs
+//   - error: Returns an error if parsing fails or a recursive query is malformed
+func parseWithStatement(l *lexer.Lexer) (*statements.SelectStatement, error) {
+	if err := expectTokenSequence(l, lexer.WITH); err != nil {
+		return nil, err
+	}
+
+	recursive := false
+	if token := l.NextToken(); token.Type == lexer.RECURSIVE {
+		recursive = true
+	} else {
+		l.SetPos(token.Position)
+	}
+
+	var ctes []*plan.CTE
+	for {
+		cte, err := parseCTE(l, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range ctes {
+			if other.Name == cte.Name {
+				return nil, fmt.Errorf("WITH query name %s specified more than once", cte.Name)
+			}
+		}
+		ctes = append(ctes, cte)
+
+		if token := l.NextToken(); token.Type != lexer.COMMA {
+			l.SetPos(token.Position)
+			break
+		}
+	}
+
+	selectToken := l.NextToken()
+	if selectToken.Type != lexer.SELECT {
+		return nil, fmt.Errorf("expected SELECT after WITH clause, got %s", selectToken.Value)
+	}
+	l.SetPos(selectToken.Position)
+
+	stmt, err := parseSelectStatement(l)
+	if err != nil {
+		return nil, err
+	}
+	stmt.Plan.SetCTEs(ctes)
+	return stmt, nil
+}
+
+// parseCTE parses one query of a WITH clause: name [(column, ...)] AS (select).
+func parseCTE(l *lexer.Lexer, recursive bool) (*plan.CTE, error) {
+	name, err := parseValueWithType(l, lexer.IDENTIFIER)
+	if err != nil {
+		return nil, fmt.Errorf("expected query name in WITH clause: %w", err)
+	}
+	cte := &plan.CTE{Name: name}
+
+	token := l.NextToken()
+	if token.Type == lexer.LPAREN {
+		cte.Columns, err = parseDelimitedList(l, func(l *lexer.Lexer) (string, error) {
+			return parseValueWithType(l, lexer.IDENTIFIER)
+		}, lexer.COMMA, lexer.RPAREN)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column list of %s: %w", name, err)
+		}
+	} else {
+		l.SetPos(token.Position)
+	}
+
+	if err := expectTokenSequence(l, lexer.AS, lexer.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected AS (query) after %s: %w", name, err)
+	}
+	stmt, err := parseSelectStatement(l)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing query %s: %w", name, err)
+	}
+	if err := expectTokenSequence(l, lexer.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected ) after query %s: %w", name, err)
+	}
+	cte.Query = stmt.Plan
+
+	if recursive && tableReferences(cte.Query, name, true) > 0 {
+		cte.Recursive = true
+		if err := validateRecursiveCTE(cte); err != nil {
+			return nil, err
+		}
+	}
+	return cte, nil
+}
+
+// validateRecursiveCTE checks that a recursive CTE has the form anchor
+// UNION [ALL] recursive_term, with the recursive term reading the CTE once
+// in its FROM clause and the anchor not at all.
+func validateRecursiveCTE(cte *plan.CTE) error {
+	q := cte.Query
+	if !q.IsSetOperation() || q.SetOpType() != plan.UnionOp || q.RightPlan().IsSetOperation() {
+		return fmt.Errorf("recursive query %s must have the form anchor UNION [ALL] recursive term", cte.Name)
+	}
+	if tableReferences(cte.Anchor(), cte.Name, true) > 0 {
+		return fmt.Errorf("the anchor of recursive query %s, before UNION, cannot refer to %s", cte.Name, cte.Name)
+	}
+
+	term := cte.RecursiveTerm()
+	if tableReferences(term, cte.Name, true) != 1 || tableReferences(term, cte.Name, false) != 1 {
+		return fmt.Errorf("the recursive term of %s must refer to %s exactly once, in its FROM clause", cte.Name, cte.Name)
+	}
+	if term.HasAgg() {
+		return fmt.Errorf("the recursive term of %s cannot use aggregates", cte.Name)
+	}
+	return nil
+}
+
+// tableReferences counts the FROM items of p that read the table name,
+// including those of its subqueries if nested is true.
+func tableReferences(p *plan.SelectPlan, name string, nested bool) int {
+	if p.IsSetOperation() {
+		return tableReferences(p.LeftPlan(), name, nested) + tableReferences(p.RightPlan(), name, nested)
+	}
+
+	count := 0
+	for _, table := range p.Tables() {
+		if table.TableName == name {
+			count++
+		}
+	}
+	for _, join := range p.Joins() {
+		switch {
+		case join.Subquery != nil && nested:
+			count += tableReferences(join.Subquery, name, nested)
+		case join.Subquery == nil && join.RightTable.TableName == name:
+			count++
+		}
+	}
+	if nested {
+		for _, filter := range p.SubqueryFilters() {
+			count += tableReferences(filter.Subquery, name, nested)
+		}
+	}
+	return count
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"slices"
+	"storemy/pkg/parser/statements"
+	"strings"
+	"testing"
+)
+
+func TestParseWithStatement(t *testing.T) {
+	stmt, err := ParseStatement("WITH a AS (SELECT id FROM users), b(n) AS (SELECT id FROM a) SELECT n FROM b")
+	if err != nil {
+		t.Fatalf("ParseStatement failed: %v", err)
+	}
+	selectStmt, ok := stmt.(*statements.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *SelectStatement, got %T", stmt)
+	}
+
+	ctes := selectStmt.Plan.CTEs()
+	if len(ctes) != 2 {
+		t.Fatalf("expected 2 CTEs, got %d", len(ctes))
+	}
+	if ctes[0].Name != "A" || ctes[0].Columns != nil || ctes[0].Recursive {
+		t.Errorf("unexpected first CTE %s", ctes[0])
+	}
+	if ctes[1].Name != "B" || !slices.Equal(ctes[1].Columns, []string{"N"}) {
+		t.Errorf("unexpected second CTE %s", ctes[1])
+	}
+	if tables := selectStmt.Plan.Tables(); len(tables) != 1 || tables[0].TableName != "B" {
+		t.Errorf("expected the statement to read B, got %v", tables)
+	}
+}
+
+func TestParseWithRecursive(t *testing.T) {
+	query := "WITH RECURSIVE reports(id) AS (SELECT id FROM employees WHERE manager_id = 1 UNION ALL SELECT e.id FROM employees e JOIN reports r ON e.manager_id = r.id) SELECT * FROM reports"
+	stmt, err := ParseStatement(query)
+	if err != nil {
+		t.Fatalf("ParseStatement failed: %v", err)
+	}
+
+	cte := stmt.(*statements.SelectStatement).Plan.CTEs()[0]
+	if !cte.Recursive || !cte.Query.SetOpAll() {
+		t.Fatalf("expected a recursive UNION ALL query, got %s", cte)
+	}
+	if tables := cte.Anchor().Tables(); tables[0].TableName != "EMPLOYEES" {
+		t.Errorf("expected the anchor to read EMPLOYEES, got %s", tables[0].TableName)
+	}
+	if joins := cte.RecursiveTerm().Joins(); len(joins) != 1 || joins[0].RightTable.TableName != "REPORTS" {
+		t.Errorf("expected the recursive term to join REPORTS, got %v", joins)
+	}
+
+	// Without RECURSIVE, the name refers to a table
+	stmt, err = ParseStatement(strings.Replace(query, "RECURSIVE ", "", 1))
+	if err != nil {
+		t.Fatalf("ParseStatement failed: %v", err)
+	}
+	if stmt.(*statements.SelectStatement).Plan.CTEs()[0].Recursive {
+		t.Error("expected a query without RECURSIVE not to be recursive")
+	}
+}
+
+func TestParseWithStatementErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		errMsg string
+	}{
+		{"WITH a AS (SELECT id FROM users) DELETE FROM a", "expected SELECT after WITH clause"},
+		{"WITH a SELECT id FROM users", "expected AS (query) after A"},
+		{"WITH a AS (SELECT id FROM users SELECT id FROM a", "expected ) after query A"},
+		{"WITH a AS (SELECT id FROM users), a AS (SELECT id FROM users) SELECT id FROM a", "specified more than once"},
+		{"WITH RECURSIVE r AS (SELECT id FROM r UNION SELECT id FROM users) SELECT id FROM r", "cannot refer to R"},
+		{"WITH RECURSIVE r AS (SELECT id FROM users INTERSECT SELECT id FROM r) SELECT id FROM r", "anchor UNION [ALL] recursive term"},
+		{"WITH RECURSIVE r AS (SELECT id FROM users UNION SELECT COUNT(id) FROM r) SELECT id FROM r", "cannot use aggregates"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseStatement(tt.query)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", tt.query, tt.errMsg)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %q", tt.query, tt.errMsg, err.Error())
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package statements
+
+import "fmt"
+
+// AlterTableAuditStatement represents a SQL ALTER TABLE ... AUDIT statement
+// Format: ALTER TABLE table_name {ENABLE|DISABLE} AUDIT
+//
+// While auditing is enabled, every row the table's INSERT, UPDATE, DELETE and
+// COPY statements change adds a row to its audit table, table_name_AUDIT, in
+// the same transaction.
+type AlterTableAuditStatement struct {
+	BaseStatement
+	TableName string
+	Enable    bool
+}
+
+// NewAlterTableAuditStatement creates a new ALTER TABLE ... AUDIT statement
+func NewAlterTableAuditStatement(tableName string, enable bool) *AlterTableAuditStatement {
+	return &AlterTableAuditStatement{
+		BaseStatement: NewBaseStatement(AlterTableAudit),
+		TableName:     tableName,
+		Enable:        enable,
+	}
+}
+
+// Validate checks if the ALTER TABLE ... AUDIT statement is valid
+func (as *AlterTableAuditStatement) Validate() error {
+	if as.TableName == "" {
+		return NewValidationError(AlterTableAudit, "TableName", "table name cannot be empty")
+	}
+	return nil
+}
+
+// String returns a string representation of the ALTER TABLE ... AUDIT statement
+func (as *AlterTableAuditStatement) String() string {
+	action := "DISABLE"
+	if as.Enable {
+		action = "ENABLE"
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s AUDIT", as.TableName, action)
+}
@@ -0,0 +1,42 @@
+package statements
+
+import "fmt"
+
+// AlterTableCacheStatement represents a SQL ALTER TABLE ... SET CACHE statement
+// Format: ALTER TABLE table_name SET CACHE {PINNED|HIGH|NORMAL}
+//
+// The priority is a hint to the buffer pool: pages of a PINNED table are
+// evicted only when no other page can be, pages of a HIGH table only when no
+// NORMAL page can be. NORMAL removes the hint.
+type AlterTableCacheStatement struct {
+	BaseStatement
+	TableName string
+	Priority  string
+}
+
+// NewAlterTableCacheStatement creates a new ALTER TABLE ... SET CACHE statement
+func NewAlterTableCacheStatement(tableName, priority string) *AlterTableCacheStatement {
+	return &AlterTableCacheStatement{
+		BaseStatement: NewBaseStatement(AlterTableCache),
+		TableName:     tableName,
+		Priority:      priority,
+	}
+}
+
+// Validate checks if the ALTER TABLE ... SET CACHE statement is valid
+func (cs *AlterTableCacheStatement) Validate() error {
+	if cs.TableName == "" {
+		return NewValidationError(AlterTableCache, "TableName", "table name cannot be empty")
+	}
+	switch cs.Priority {
+	case "PINNED", "HIGH", "NORMAL":
+		return nil
+	default:
+		return NewValidationError(AlterTableCache, "Priority", "cache priority must be PINNED, HIGH or NORMAL")
+	}
+}
+
+// String returns a string representation of the ALTER TABLE ... SET CACHE statement
+func (cs *AlterTableCacheStatement) String() string {
+	return fmt.Sprintf("ALTER TABLE %s SET CACHE %s", cs.TableName, cs.Priority)
+}
@@ -0,0 +1,40 @@
+package statements
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChecksumTableStatement represents a SQL CHECKSUM TABLE statement, which
+// computes a digest of the contents of each listed table.
+// Format: CHECKSUM TABLE table_name [, table_name ...]
+type ChecksumTableStatement struct {
+	BaseStatement
+	TableNames []string
+}
+
+// NewChecksumTableStatement creates a new CHECKSUM TABLE statement
+func NewChecksumTableStatement(tableNames []string) *ChecksumTableStatement {
+	return &ChecksumTableStatement{
+		BaseStatement: NewBaseStatement(ChecksumTable),
+		TableNames:    tableNames,
+	}
+}
+
+// Validate checks if the CHECKSUM TABLE statement is valid
+func (s *ChecksumTableStatement) Validate() error {
+	if len(s.TableNames) == 0 {
+		return NewValidationError(ChecksumTable, "TableNames", "at least one table is required")
+	}
+	for _, name := range s.TableNames {
+		if name == "" {
+			return NewValidationError(ChecksumTable, "TableNames", "table name cannot be empty")
+		}
+	}
+	return nil
+}
+
+// String returns a string representation of the CHECKSUM TABLE statement
+func (s *ChecksumTableStatement) String() string {
+	return fmt.Sprintf("CHECKSUM TABLE %s", strings.Join(s.TableNames, ", "))
+}
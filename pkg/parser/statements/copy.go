@@ -0,0 +1,109 @@
+package statements
+
+import (
+	"fmt"
+	"slices"
+	"storemy/pkg/datagen"
+	"strings"
+)
+
+// CopyStatement represents a SQL COPY statement.
+// Format: COPY table_name FROM 'path' [OPTIONS (name 'value', ...)]
+//
+//	COPY table_name FROM GENERATE_SERIES(start, stop[, step]) [OPTIONS (seed 'n')]
+//
+// The rows of a CSV or JSONL file are inserted into a stored table. The
+// options are those of a foreign table, except LOCATION, which is the path;
+// FORMAT defaults to csv. The path keeps the case it was written in.
+//
+// With GENERATE_SERIES the rows are sample data, one per number of the
+// series, generated from the table's columns. SEED is the only option.
+type CopyStatement struct {
+	BaseStatement
+	TableName string
+	Path      string
+	Series    *datagen.Series // Set instead of Path when copying generated rows
+	Options   map[string]string
+}
+
+// NewCopyStatement creates a new COPY statement
+func NewCopyStatement(tableName, path string) *CopyStatement {
+	return &CopyStatement{
+		BaseStatement: NewBaseStatement(Copy),
+		TableName:     tableName,
+		Path:          path,
+		Options:       make(map[string]string),
+	}
+}
+
+// NewCopySeriesStatement creates a new COPY statement that loads the rows
+// generated for series.
+func NewCopySeriesStatement(tableName string, series datagen.Series) *CopyStatement {
+	return &CopyStatement{
+		BaseStatement: NewBaseStatement(Copy),
+		TableName:     tableName,
+		Series:        &series,
+		Options:       make(map[string]string),
+	}
+}
+
+// Validate checks the statement's structure. Option values are checked when
+// the statement is executed.
+func (s *CopyStatement) Validate() error {
+	if s.TableName == "" {
+		return NewValidationError(Copy, "TableName", "table name cannot be empty")
+	}
+
+	if s.Series != nil {
+		if err := s.Series.Validate(); err != nil {
+			return NewValidationError(Copy, "Series", err.Error())
+		}
+		for name := range s.Options {
+			if name != "SEED" {
+				return NewValidationError(Copy, "Options", fmt.Sprintf("option %s cannot be used with GENERATE_SERIES", name))
+			}
+		}
+		return nil
+	}
+
+	if strings.TrimSpace(s.Path) == "" {
+		return NewValidationError(Copy, "Path", "file path cannot be empty")
+	}
+
+	if _, ok := s.Options["LOCATION"]; ok {
+		return NewValidationError(Copy, "Options", "option LOCATION cannot be used with COPY, the file is given by FROM")
+	}
+
+	return nil
+}
+
+// String returns a string representation of the COPY statement
+func (s *CopyStatement) String() string {
+	var sb strings.Builder
+	if s.Series != nil {
+		sb.WriteString(fmt.Sprintf("COPY %s FROM %s", s.TableName, s.Series))
+	} else {
+		sb.WriteString(fmt.Sprintf("COPY %s FROM '%s'", s.TableName, s.Path))
+	}
+
+	if len(s.Options) == 0 {
+		return sb.String()
+	}
+
+	names := make([]string, 0, len(s.Options))
+	for name := range s.Options {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	sb.WriteString(" OPTIONS (")
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprintf("%s '%s'", name, s.Options[name]))
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package statements
+
+import "strings"
+
+// CreateDatabaseStatement represents a SQL CREATE DATABASE statement
+// Format: CREATE DATABASE [IF NOT EXISTS] database_name
+type CreateDatabaseStatement struct {
+	BaseStatement
+	DatabaseName string
+	IfNotExists  bool
+}
+
+// NewCreateDatabaseStatement creates a new CREATE DATABASE statement
+func NewCreateDatabaseStatement(databaseName string, ifNotExists bool) *CreateDatabaseStatement {
+	return &CreateDatabaseStatement{
+		BaseStatement: NewBaseStatement(CreateDatabase),
+		DatabaseName:  databaseName,
+		IfNotExists:   ifNotExists,
+	}
+}
+
+// Validate checks if the CREATE DATABASE statement is valid
+func (cds *CreateDatabaseStatement) Validate() error {
+	if cds.DatabaseName == "" {
+		return NewValidationError(CreateDatabase, "DatabaseName", "database name cannot be empty")
+	}
+	return nil
+}
+
+// String returns a string representation of the CREATE DATABASE statement
+func (cds *CreateDatabaseStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("CREATE DATABASE ")
+	if cds.IfNotExists {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+	sb.WriteString(cds.DatabaseName)
+	return sb.String()
+}
+
+// UseStatement represents a SQL USE statement, which switches the session to
+// another database of the engine.
+// Format: USE database_name
+type UseStatement struct {
+	BaseStatement
+	DatabaseName string
+}
+
+// NewUseStatement creates a new USE statement
+func NewUseStatement(databaseName string) *UseStatement {
+	return &UseStatement{
+		BaseStatement: NewBaseStatement(UseDatabase),
+		DatabaseName:  databaseName,
+	}
+}
+
+// Validate checks if the USE statement is valid
+func (us *UseStatement) Validate() error {
+	if us.DatabaseName == "" {
+		return NewValidationError(UseDatabase, "DatabaseName", "database name cannot be empty")
+	}
+	return nil
+}
+
+// String returns a string representation of the USE statement
+func (us *UseStatement) String() string {
+	return "USE " + us.DatabaseName
+}
@@ -3,6 +3,7 @@ package statements
 // ExplainOptions contains options for the EXPLAIN command
 type ExplainOptions struct {
 	Analyze bool   // If true, actually execute the query and show real statistics
+	Verbose bool   // If true (with Analyze), include the query's trace span tree
 	Format  string // Output format: "TEXT", "JSON", etc. (default: "TEXT")
 }
 
@@ -38,6 +39,9 @@ func (es *ExplainStatement) String() string {
 	if es.Options.Analyze {
 		result += " ANALYZE"
 	}
+	if es.Options.Verbose {
+		result += " VERBOSE"
+	}
 	if es.Options.Format != "" && es.Options.Format != "TEXT" {
 		result += " FORMAT " + es.Options.Format
 	}
@@ -50,5 +54,8 @@ func (es *ExplainStatement) Validate() error {
 	if es.Statement == nil {
 		return NewValidationError(Explain, "Statement", "EXPLAIN statement must have an underlying statement")
 	}
+	if es.Options.Verbose && !es.Options.Analyze {
+		return NewValidationError(Explain, "Options", "VERBOSE requires ANALYZE")
+	}
 	return es.Statement.Validate()
 }
@@ -0,0 +1,107 @@
+package statements
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CreateForeignTableStatement represents a SQL CREATE FOREIGN TABLE statement.
+// Format: CREATE FOREIGN TABLE [IF NOT EXISTS] table_name (field_definitions...)
+// OPTIONS (name 'value', ...)
+//
+// The table's rows are read from an external file each time it is scanned.
+// Option names are upper case; option values keep the case they were written
+// in, so that file paths survive parsing.
+type CreateForeignTableStatement struct {
+	BaseStatement
+	TableName   string
+	Fields      []FieldDefinition
+	Options     map[string]string
+	IfNotExists bool
+}
+
+// NewCreateForeignTableStatement creates a new CREATE FOREIGN TABLE statement
+func NewCreateForeignTableStatement(tableName string, ifNotExists bool) *CreateForeignTableStatement {
+	return &CreateForeignTableStatement{
+		BaseStatement: NewBaseStatement(CreateForeignTable),
+		TableName:     tableName,
+		IfNotExists:   ifNotExists,
+		Fields:        make([]FieldDefinition, 0),
+		Options:       make(map[string]string),
+	}
+}
+
+// Validate checks the statement's structure. FORMAT and LOCATION options are
+// required; their values are checked when the statement is executed.
+func (s *CreateForeignTableStatement) Validate() error {
+	if s.TableName == "" {
+		return NewValidationError(CreateForeignTable, "TableName", "table name cannot be empty")
+	}
+
+	if len(s.Fields) == 0 {
+		return NewValidationError(CreateForeignTable, "Fields", "at least one field is required")
+	}
+
+	fieldNames := make(map[string]bool)
+	for i, field := range s.Fields {
+		if field.Name == "" {
+			return NewValidationError(CreateForeignTable, fmt.Sprintf("Fields[%d].Name", i), "field name cannot be empty")
+		}
+		if fieldNames[field.Name] {
+			return NewValidationError(CreateForeignTable, fmt.Sprintf("Fields[%d].Name", i), fmt.Sprintf("duplicate field name: %s", field.Name))
+		}
+		if field.AutoIncrement {
+			return NewValidationError(CreateForeignTable, fmt.Sprintf("Fields[%d].AutoIncrement", i), "foreign tables cannot have auto-increment columns")
+		}
+		if field.DefaultValue != nil {
+			return NewValidationError(CreateForeignTable, fmt.Sprintf("Fields[%d].DefaultValue", i), "foreign tables cannot have default values")
+		}
+		fieldNames[field.Name] = true
+	}
+
+	for _, required := range []string{"FORMAT", "LOCATION"} {
+		if s.Options[required] == "" {
+			return NewValidationError(CreateForeignTable, "Options", fmt.Sprintf("option %s is required", required))
+		}
+	}
+
+	return nil
+}
+
+// String returns a string representation of the CREATE FOREIGN TABLE statement
+func (s *CreateForeignTableStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("CREATE FOREIGN TABLE ")
+
+	if s.IfNotExists {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+
+	sb.WriteString(fmt.Sprintf("%s (\n", s.TableName))
+	for i, field := range s.Fields {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s", field.Name, field.Type.String()))
+		if field.NotNull {
+			sb.WriteString(" NOT NULL")
+		}
+	}
+	sb.WriteString("\n) OPTIONS (")
+
+	names := make([]string, 0, len(s.Options))
+	for name := range s.Options {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprintf("%s '%s'", name, s.Options[name]))
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
@@ -12,6 +12,14 @@ type InsertStatement struct {
 	TableName string          // The target table name for the INSERT operation
 	Fields    []string        // Column names to insert data into
 	Values    [][]types.Field // Rows of values to be inserted, each row contains fields matching the Fields slice
+	Params    []ValueParam    // Bind parameters among the values, whose slots stay nil until bound
+}
+
+// ValueParam records that the value at Values[Row][Column] of an INSERT
+// statement is bind parameter $Index.
+type ValueParam struct {
+	Row, Column int
+	Index       int
 }
 
 // NewInsertStatement creates a new INSERT statement
@@ -34,6 +42,12 @@ func (s *InsertStatement) AddValues(values []types.Field) {
 	s.Values = append(s.Values, values)
 }
 
+// AddParam records that the value at the given row and column is bind
+// parameter $index.
+func (s *InsertStatement) AddParam(row, column, index int) {
+	s.Params = append(s.Params, ValueParam{Row: row, Column: column, Index: index})
+}
+
 // ValueCount returns the number of rows to be inserted
 func (s *InsertStatement) ValueCount() int {
 	return len(s.Values)
@@ -96,6 +110,8 @@ func (s *InsertStatement) String() string {
 			}
 			if val != nil {
 				sb.WriteString(val.String())
+			} else if param := s.paramAt(i, j); param != 0 {
+				sb.WriteString(fmt.Sprintf("$%d", param))
 			} else {
 				sb.WriteString("NULL")
 			}
@@ -105,3 +121,13 @@ func (s *InsertStatement) String() string {
 
 	return sb.String()
 }
+
+// paramAt returns the bind parameter at the given row and column, or 0.
+func (s *InsertStatement) paramAt(row, column int) int {
+	for _, p := range s.Params {
+		if p.Row == row && p.Column == column {
+			return p.Index
+		}
+	}
+	return 0
+}
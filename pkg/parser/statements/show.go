@@ -37,3 +37,99 @@ func (sis *ShowIndexesStatement) String() string {
 
 	return sb.String()
 }
+
+// ShowPersistentStatement represents a SQL SHOW PERSISTENT statement
+// Format: SHOW PERSISTENT [setting_name]
+type ShowPersistentStatement struct {
+	BaseStatement
+	Name string // Optional: if specified, show only this setting
+}
+
+// NewShowPersistentStatement creates a new SHOW PERSISTENT statement
+func NewShowPersistentStatement(name string) *ShowPersistentStatement {
+	return &ShowPersistentStatement{
+		BaseStatement: NewBaseStatement(ShowPersistent),
+		Name:          name,
+	}
+}
+
+// Validate checks if the SHOW PERSISTENT statement is valid
+func (sps *ShowPersistentStatement) Validate() error {
+	// Name is optional, so no validation needed
+	return nil
+}
+
+// String returns a string representation of the SHOW PERSISTENT statement
+func (sps *ShowPersistentStatement) String() string {
+	if sps.Name == "" {
+		return "SHOW PERSISTENT"
+	}
+	return fmt.Sprintf("SHOW PERSISTENT %s", sps.Name)
+}
+
+// SetPersistentStatement represents a SQL SET PERSISTENT statement
+// Format: SET PERSISTENT setting_name = value
+type SetPersistentStatement struct {
+	BaseStatement
+	Name  string
+	Value string
+}
+
+// NewSetPersistentStatement creates a new SET PERSISTENT statement
+func NewSetPersistentStatement(name, value string) *SetPersistentStatement {
+	return &SetPersistentStatement{
+		BaseStatement: NewBaseStatement(SetPersistent),
+		Name:          name,
+		Value:         value,
+	}
+}
+
+// Validate checks if the SET PERSISTENT statement is valid
+func (sps *SetPersistentStatement) Validate() error {
+	if sps.Name == "" {
+		return NewValidationError(SetPersistent, "Name", "setting name cannot be empty")
+	}
+	if sps.Value == "" {
+		return NewValidationError(SetPersistent, "Value", "setting value cannot be empty")
+	}
+	return nil
+}
+
+// String returns a string representation of the SET PERSISTENT statement
+func (sps *SetPersistentStatement) String() string {
+	return fmt.Sprintf("SET PERSISTENT %s = %s", sps.Name, sps.Value)
+}
+
+// SetStatement represents a SQL SET statement, which changes a setting of
+// the running database without persisting it
+// Format: SET setting_name = value
+type SetStatement struct {
+	BaseStatement
+	Name  string
+	Value string
+}
+
+// NewSetStatement creates a new SET statement
+func NewSetStatement(name, value string) *SetStatement {
+	return &SetStatement{
+		BaseStatement: NewBaseStatement(Set),
+		Name:          name,
+		Value:         value,
+	}
+}
+
+// Validate checks if the SET statement is valid
+func (ss *SetStatement) Validate() error {
+	if ss.Name == "" {
+		return NewValidationError(Set, "Name", "setting name cannot be empty")
+	}
+	if ss.Value == "" {
+		return NewValidationError(Set, "Value", "setting value cannot be empty")
+	}
+	return nil
+}
+
+// String returns a string representation of the SET statement
+func (ss *SetStatement) String() string {
+	return fmt.Sprintf("SET %s = %s", ss.Name, ss.Value)
+}
@@ -0,0 +1,33 @@
+package statements
+
+import "fmt"
+
+// SetTransactionSnapshotStatement represents a SQL SET TRANSACTION SNAPSHOT
+// statement, which makes the running transaction read the snapshot another
+// transaction exported.
+// Format: SET TRANSACTION SNAPSHOT 'token'
+type SetTransactionSnapshotStatement struct {
+	BaseStatement
+	Token string
+}
+
+// NewSetTransactionSnapshotStatement creates a new SET TRANSACTION SNAPSHOT statement
+func NewSetTransactionSnapshotStatement(token string) *SetTransactionSnapshotStatement {
+	return &SetTransactionSnapshotStatement{
+		BaseStatement: NewBaseStatement(SetTransactionSnapshot),
+		Token:         token,
+	}
+}
+
+// Validate checks if the SET TRANSACTION SNAPSHOT statement is valid
+func (s *SetTransactionSnapshotStatement) Validate() error {
+	if s.Token == "" {
+		return NewValidationError(SetTransactionSnapshot, "Token", "snapshot token cannot be empty")
+	}
+	return nil
+}
+
+// String returns a string representation of the SET TRANSACTION SNAPSHOT statement
+func (s *SetTransactionSnapshotStatement) String() string {
+	return fmt.Sprintf("SET TRANSACTION SNAPSHOT '%s'", s.Token)
+}
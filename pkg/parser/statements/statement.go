@@ -14,6 +14,19 @@ const (
 	Transaction
 	Explain
 	ShowIndexes
+	ShowPersistent
+	SetPersistent
+	CreateForeignTable
+	CreateTrigger
+	DropTrigger
+	CreateDatabase
+	UseDatabase
+	Copy
+	ChecksumTable
+	SetTransactionSnapshot
+	AlterTableAudit
+	Set
+	AlterTableCache
 )
 
 func (st StatementType) String() string {
@@ -40,19 +53,47 @@ func (st StatementType) String() string {
 		return "EXPLAIN"
 	case ShowIndexes:
 		return "SHOW INDEXES"
+	case ShowPersistent:
+		return "SHOW PERSISTENT"
+	case SetPersistent:
+		return "SET PERSISTENT"
+	case CreateForeignTable:
+		return "CREATE FOREIGN TABLE"
+	case CreateTrigger:
+		return "CREATE TRIGGER"
+	case DropTrigger:
+		return "DROP TRIGGER"
+	case CreateDatabase:
+		return "CREATE DATABASE"
+	case UseDatabase:
+		return "USE"
+	case Copy:
+		return "COPY"
+	case ChecksumTable:
+		return "CHECKSUM TABLE"
+	case SetTransactionSnapshot:
+		return "SET TRANSACTION SNAPSHOT"
+	case AlterTableAudit:
+		return "ALTER TABLE AUDIT"
+	case Set:
+		return "SET"
+	case AlterTableCache:
+		return "ALTER TABLE SET CACHE"
 	default:
 		return "UNKNOWN"
 	}
 }
 
-// IsDML returns true if the statement type is a DML operation (INSERT, UPDATE, DELETE, SELECT)
+// IsDML returns true if the statement type is a DML operation (INSERT, UPDATE, DELETE, SELECT, COPY)
 func (st StatementType) IsDML() bool {
-	return st == Select || st == Insert || st == Update || st == Delete
+	return st == Select || st == Insert || st == Update || st == Delete || st == Copy
 }
 
-// IsDDL returns true if the statement type is a DDL operation (CREATE, DROP)
+// IsDDL returns true if the statement type is a DDL operation (CREATE, DROP, ALTER)
 func (st StatementType) IsDDL() bool {
-	return st == CreateTable || st == DropTable || st == CreateIndex || st == DropIndex
+	return st == CreateTable || st == DropTable || st == CreateIndex || st == DropIndex || st == CreateForeignTable ||
+		st == CreateTrigger || st == DropTrigger || st == CreateDatabase || st == AlterTableAudit ||
+		st == AlterTableCache
 }
 
 // Statement is the interface that all SQL statements must implement
@@ -0,0 +1,114 @@
+package statements
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CreateTriggerStatement represents a SQL CREATE TRIGGER statement
+// Format: CREATE TRIGGER [IF NOT EXISTS] trigger_name {BEFORE|AFTER} {INSERT|UPDATE|DELETE}
+// ON table_name EXECUTE FUNCTION function_name[()]
+//
+// Triggers are row-level: the function runs once for every row the statement
+// inserts, updates or deletes. The function is looked up by name in the
+// database's trigger function registry when the trigger fires.
+type CreateTriggerStatement struct {
+	BaseStatement
+	TriggerName, TableName string
+	Timing                 string // BEFORE or AFTER
+	Event                  string // INSERT, UPDATE or DELETE
+	FunctionName           string
+	IfNotExists            bool
+}
+
+// NewCreateTriggerStatement creates a new CREATE TRIGGER statement
+func NewCreateTriggerStatement(triggerName, timing, event, tableName, functionName string, ifNotExists bool) *CreateTriggerStatement {
+	return &CreateTriggerStatement{
+		BaseStatement: NewBaseStatement(CreateTrigger),
+		TriggerName:   triggerName,
+		TableName:     tableName,
+		Timing:        timing,
+		Event:         event,
+		FunctionName:  functionName,
+		IfNotExists:   ifNotExists,
+	}
+}
+
+// Validate checks if the CREATE TRIGGER statement is valid
+func (cts *CreateTriggerStatement) Validate() error {
+	if cts.TriggerName == "" {
+		return NewValidationError(CreateTrigger, "TriggerName", "trigger name cannot be empty")
+	}
+
+	if cts.TableName == "" {
+		return NewValidationError(CreateTrigger, "TableName", "table name cannot be empty")
+	}
+
+	if cts.Timing != "BEFORE" && cts.Timing != "AFTER" {
+		return NewValidationError(CreateTrigger, "Timing", fmt.Sprintf("invalid trigger timing: %s (must be BEFORE or AFTER)", cts.Timing))
+	}
+
+	switch cts.Event {
+	case "INSERT", "UPDATE", "DELETE":
+	default:
+		return NewValidationError(CreateTrigger, "Event", fmt.Sprintf("invalid trigger event: %s (must be INSERT, UPDATE or DELETE)", cts.Event))
+	}
+
+	if cts.FunctionName == "" {
+		return NewValidationError(CreateTrigger, "FunctionName", "function name cannot be empty")
+	}
+
+	return nil
+}
+
+// String returns a string representation of the CREATE TRIGGER statement
+func (cts *CreateTriggerStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("CREATE TRIGGER ")
+
+	if cts.IfNotExists {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+
+	sb.WriteString(fmt.Sprintf("%s %s %s ON %s EXECUTE FUNCTION %s",
+		cts.TriggerName, cts.Timing, cts.Event, cts.TableName, cts.FunctionName))
+	return sb.String()
+}
+
+// DropTriggerStatement represents a SQL DROP TRIGGER statement
+// Format: DROP TRIGGER [IF EXISTS] trigger_name
+type DropTriggerStatement struct {
+	BaseStatement
+	TriggerName string
+	IfExists    bool
+}
+
+// NewDropTriggerStatement creates a new DROP TRIGGER statement
+func NewDropTriggerStatement(triggerName string, ifExists bool) *DropTriggerStatement {
+	return &DropTriggerStatement{
+		BaseStatement: NewBaseStatement(DropTrigger),
+		TriggerName:   triggerName,
+		IfExists:      ifExists,
+	}
+}
+
+// Validate checks if the DROP TRIGGER statement is valid
+func (dts *DropTriggerStatement) Validate() error {
+	if dts.TriggerName == "" {
+		return NewValidationError(DropTrigger, "TriggerName", "trigger name cannot be empty")
+	}
+	return nil
+}
+
+// String returns a string representation of the DROP TRIGGER statement
+func (dts *DropTriggerStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("DROP TRIGGER ")
+
+	if dts.IfExists {
+		sb.WriteString("IF EXISTS ")
+	}
+
+	sb.WriteString(dts.TriggerName)
+	return sb.String()
+}
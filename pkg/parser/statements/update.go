@@ -11,6 +11,7 @@ import (
 type SetClause struct {
 	FieldName string
 	Value     types.Field
+	Param     int // Bind parameter supplying Value (field = $1), or 0
 }
 
 // UpdateStatement represents a SQL UPDATE statement with SET clauses and optional WHERE clause
@@ -37,6 +38,15 @@ func (us *UpdateStatement) AddSetClause(fieldName string, value types.Field) {
 	})
 }
 
+// AddParamSetClause adds a SET clause assigning bind parameter $param to the
+// field. Its Value is set when the planner binds the parameter.
+func (us *UpdateStatement) AddParamSetClause(fieldName string, param int) {
+	us.SetClauses = append(us.SetClauses, SetClause{
+		FieldName: fieldName,
+		Param:     param,
+	})
+}
+
 // SetWhereClause sets the WHERE clause filter
 func (us *UpdateStatement) SetWhereClause(filter *plan.FilterNode) {
 	us.WhereClause = filter
@@ -61,7 +71,7 @@ func (us *UpdateStatement) Validate() error {
 		if clause.FieldName == "" {
 			return NewValidationError(Update, fmt.Sprintf("SetClauses[%d].FieldName", i), "field name cannot be empty")
 		}
-		if clause.Value == nil {
+		if clause.Value == nil && clause.Param == 0 {
 			return NewValidationError(Update, fmt.Sprintf("SetClauses[%d].Value", i), "value cannot be nil")
 		}
 	}
@@ -82,6 +92,10 @@ func (us *UpdateStatement) String() string {
 		if i > 0 {
 			sb.WriteString(", ")
 		}
+		if setClause.Value == nil && setClause.Param != 0 {
+			sb.WriteString(fmt.Sprintf("%s = $%d", setClause.FieldName, setClause.Param))
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("%s = %s", setClause.FieldName, setClause.Value.String()))
 	}
 
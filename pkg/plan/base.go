@@ -65,12 +65,22 @@ const (
 	StandardPredicate PredicateType = iota
 	// NullCheckPredicate represents IS NULL or IS NOT NULL
 	NullCheckPredicate
-	// LikePredicate represents LIKE pattern matching
+	// LikePredicate represents LIKE and ILIKE pattern matching
 	LikePredicate
 	// InPredicate represents IN (...) clause with multiple values
 	InPredicate
 )
 
+// PredicateTypeOf returns the predicate type the optimizer should use for a
+// single-value predicate operator: LikePredicate for LIKE and ILIKE, whose
+// selectivity depends on the pattern shape, and StandardPredicate otherwise.
+func PredicateTypeOf(pred primitives.Predicate) PredicateType {
+	if pred == primitives.Like || pred == primitives.ILike {
+		return LikePredicate
+	}
+	return StandardPredicate
+}
+
 // PredicateInfo represents a filter predicate applied in scan or filter nodes.
 // It supports different types of predicates with their specific requirements.
 type PredicateInfo struct {
@@ -90,6 +100,7 @@ const (
 	InnerJoin
 	LeftJoin
 	RightJoin
+	FullJoin
 )
 
 func (jt JoinType) String() string {
@@ -102,16 +113,21 @@ func (jt JoinType) String() string {
 		return "LEFT"
 	case RightJoin:
 		return "RIGHT"
+	case FullJoin:
+		return "FULL"
 	default:
 		return "UNKNOWN"
 	}
 }
 
 // SelectListNode represents a field or expression in the SELECT clause.
-// It can be a simple field or an aggregated field (e.g., COUNT, SUM).
+// It can be a simple field, an aggregated field (e.g., COUNT, SUM) or a
+// computed expression such as CASE, in which case Expr is set and FieldName
+// holds the output column name.
 type SelectListNode struct {
 	FieldName string
 	AggOp     string
+	Expr      Expr
 }
 
 // NewSelectListNode creates a new select list node for a field with optional aggregation.
@@ -122,7 +138,19 @@ func NewSelectListNode(fieldName, aggOp string) *SelectListNode {
 	}
 }
 
+// NewSelectExprNode creates a select list node for a computed expression whose
+// output column is called name.
+func NewSelectExprNode(expr Expr, name string) *SelectListNode {
+	return &SelectListNode{
+		FieldName: name,
+		Expr:      expr,
+	}
+}
+
 func (sln *SelectListNode) String() string {
+	if sln.Expr != nil {
+		return fmt.Sprintf("%s AS %s", sln.Expr, sln.FieldName)
+	}
 	if sln.AggOp != "" {
 		return fmt.Sprintf("%s(%s)", sln.AggOp, sln.FieldName)
 	}
@@ -137,3 +165,16 @@ const (
 	IntersectOp
 	ExceptOp
 )
+
+func (t SetOperationType) String() string {
+	switch t {
+	case UnionOp:
+		return "UNION"
+	case IntersectOp:
+		return "INTERSECT"
+	case ExceptOp:
+		return "EXCEPT"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,42 @@
+package plan
+
+import "strings"
+
+// CTE is a common table expression, a query named in a WITH clause that the
+// statement then reads like a table:
+//
+//	WITH name [(column, ...)] AS (query) SELECT ... FROM name
+//
+// A recursive CTE, defined with WITH RECURSIVE, refers to itself. Its query
+// is anchor UNION [ALL] recursive term: the anchor is evaluated once, then
+// the recursive term again and again over the rows the previous evaluation
+// produced, until an evaluation produces no rows.
+type CTE struct {
+	Name      string
+	Columns   []string // Names for the query's columns, or nil to keep its own
+	Recursive bool
+	Query     *SelectPlan
+}
+
+// Anchor returns the part of a recursive CTE's query that does not refer to
+// the CTE.
+func (c *CTE) Anchor() *SelectPlan {
+	return c.Query.LeftPlan()
+}
+
+// RecursiveTerm returns the part of a recursive CTE's query that refers to
+// the CTE.
+func (c *CTE) RecursiveTerm() *SelectPlan {
+	return c.Query.RightPlan()
+}
+
+func (c *CTE) String() string {
+	name := c.Name
+	if len(c.Columns) > 0 {
+		name += "(" + strings.Join(c.Columns, ", ") + ")"
+	}
+	if c.Recursive {
+		return "RECURSIVE " + name
+	}
+	return name
+}
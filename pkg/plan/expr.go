@@ -0,0 +1,170 @@
+package plan
+
+import (
+	"fmt"
+	"storemy/pkg/primitives"
+	"storemy/pkg/types"
+	"strings"
+)
+
+// Expr is a node of a scalar expression tree, such as a CASE expression in a
+// SELECT list, WHERE clause or ORDER BY clause. Expressions are built by the
+// parser and evaluated against tuples by the execution layer.
+type Expr interface {
+	// String returns the SQL representation of the expression.
+	String() string
+
+	exprNode()
+}
+
+// ColumnExpr references a column by name. The name may be qualified (table.column).
+type ColumnExpr struct {
+	Name string
+}
+
+// LiteralExpr is a constant value. A nil Value is the SQL NULL literal.
+type LiteralExpr struct {
+	Value types.Field
+}
+
+// CompareExpr compares two expressions with a comparison or pattern operator.
+type CompareExpr struct {
+	Left  Expr
+	Op    primitives.Predicate
+	Right Expr
+}
+
+// LogicalOp is the operator of a LogicalExpr.
+type LogicalOp int
+
+const (
+	// AndOp is true if both operands are true
+	AndOp LogicalOp = iota
+	// OrOp is true if either operand is true
+	OrOp
+)
+
+func (op LogicalOp) String() string {
+	if op == OrOp {
+		return "OR"
+	}
+	return "AND"
+}
+
+// LogicalExpr combines two boolean expressions with AND or OR.
+type LogicalExpr struct {
+	Op    LogicalOp
+	Left  Expr
+	Right Expr
+}
+
+// WhenClause is a single WHEN ... THEN ... branch of a CASE expression.
+// In a searched CASE, Cond is a boolean expression; in a simple CASE it is the
+// value compared with the CASE operand.
+type WhenClause struct {
+	Cond   Expr
+	Result Expr
+}
+
+// CaseExpr is a CASE expression in either of its two forms:
+//
+//	CASE WHEN cond THEN result [WHEN ...] [ELSE result] END             (searched)
+//	CASE operand WHEN value THEN result [WHEN ...] [ELSE result] END    (simple)
+//
+// Operand is nil for the searched form. Else is nil when the ELSE branch is
+// omitted, in which case rows matching no branch produce NULL.
+type CaseExpr struct {
+	Operand Expr
+	Whens   []WhenClause
+	Else    Expr
+}
+
+// AggregateExpr is an aggregate function call such as COUNT(*) or SUM(AMOUNT).
+// It may only appear in a HAVING clause, where it refers to the value the
+// query's aggregation computed for the current group.
+type AggregateExpr struct {
+	Func  string
+	Field string
+}
+
+// GroupingExpr is a call of GROUPING(arg), where arg is the GROUP BY key. It
+// is 1 for the rows of the empty grouping set of GROUPING SETS, ROLLUP or
+// CUBE, whose key is NULL because it was aggregated away, and 0 otherwise.
+type GroupingExpr struct {
+	Arg Expr
+}
+
+// ParamExpr is a bind parameter ($1, $2, ... or ?) of a parameterized query.
+// Index is the 1-based number of the parameter. The planner binds a value to
+// it before the query runs; until then it cannot be evaluated.
+type ParamExpr struct {
+	Index int
+	Value types.Field // Bound value; nil is NULL when Bound is set
+	Bound bool
+}
+
+// Bind sets the value of the parameter.
+func (e *ParamExpr) Bind(value types.Field) {
+	e.Value = value
+	e.Bound = true
+}
+
+func (*ColumnExpr) exprNode()    {}
+func (*LiteralExpr) exprNode()   {}
+func (*CompareExpr) exprNode()   {}
+func (*LogicalExpr) exprNode()   {}
+func (*CaseExpr) exprNode()      {}
+func (*AggregateExpr) exprNode() {}
+func (*GroupingExpr) exprNode()  {}
+func (*ParamExpr) exprNode()     {}
+
+func (e *ColumnExpr) String() string {
+	return e.Name
+}
+
+func (e *LiteralExpr) String() string {
+	switch v := e.Value.(type) {
+	case nil:
+		return "NULL"
+	case *types.StringField:
+		return fmt.Sprintf("'%s'", v.Value)
+	default:
+		return v.String()
+	}
+}
+
+func (e *CompareExpr) String() string {
+	return fmt.Sprintf("%s %s %s", e.Left, e.Op, e.Right)
+}
+
+func (e *LogicalExpr) String() string {
+	return fmt.Sprintf("(%s %s %s)", e.Left, e.Op, e.Right)
+}
+
+func (e *CaseExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	if e.Operand != nil {
+		sb.WriteString(" " + e.Operand.String())
+	}
+	for _, w := range e.Whens {
+		sb.WriteString(fmt.Sprintf(" WHEN %s THEN %s", w.Cond, w.Result))
+	}
+	if e.Else != nil {
+		sb.WriteString(" ELSE " + e.Else.String())
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+func (e *AggregateExpr) String() string {
+	return fmt.Sprintf("%s(%s)", e.Func, e.Field)
+}
+
+func (e *GroupingExpr) String() string {
+	return fmt.Sprintf("GROUPING(%s)", e.Arg)
+}
+
+func (e *ParamExpr) String() string {
+	return fmt.Sprintf("$%d", e.Index)
+}
@@ -23,6 +23,16 @@ type JoinNode struct {
 	LeftField  string    // Alias for LeftColumn (parser compatibility)
 	RightField string    // Alias for RightColumn (parser compatibility)
 	Predicate  primitives.Predicate // Alias for JoinPredicate (parser compatibility)
+
+	// Subquery is the SELECT joined in place of a table, as in
+	// JOIN (SELECT ...) alias; RightTable then only carries its alias.
+	Subquery *SelectPlan
+	// Lateral marks a LATERAL subquery, which may refer to the columns of the
+	// tables to its left and is evaluated once for each of their rows.
+	Lateral bool
+	// Condition is the [NOT] EXISTS or [NOT] IN condition a "semi" or "anti"
+	// join evaluates; its Subquery is the condition's.
+	Condition *SubqueryFilter
 }
 
 // NewJoinNode creates a new join node for parser usage.
@@ -36,6 +46,8 @@ func NewJoinNode(rightTable *ScanNode, joinType JoinType, leftField, rightField
 		joinTypeStr = "left"
 	case RightJoin:
 		joinTypeStr = "right"
+	case FullJoin:
+		joinTypeStr = "full"
 	case CrossJoin:
 		joinTypeStr = "cross"
 	}
@@ -89,6 +101,33 @@ func (j *JoinNode) GetChildren() []PlanNode {
 	return []PlanNode{j.LeftChild, j.RightChild}
 }
 
+// IsOuter reports whether the join keeps rows without a match, padding the
+// other side with NULLs: LEFT, RIGHT and FULL joins.
+func (j *JoinNode) IsOuter() bool {
+	switch j.JoinType {
+	case "left", "right", "full":
+		return true
+	default:
+		return false
+	}
+}
+
+// NullExtendedSide describes the input whose columns an outer join fills with
+// NULLs for unmatched rows: "right" for LEFT, "left" for RIGHT and "either"
+// for FULL joins, or "" for inner joins.
+func (j *JoinNode) NullExtendedSide() string {
+	switch j.JoinType {
+	case "left":
+		return "right"
+	case "right":
+		return "left"
+	case "full":
+		return "either"
+	default:
+		return ""
+	}
+}
+
 // UnionNode represents a UNION operation
 type UnionNode struct {
 	BasePlanNode
@@ -17,6 +17,20 @@ type FilterNode struct {
 	Field     string                // Field name (parser usage)
 	Predicate primitives.Predicate  // Predicate operator (parser usage)
 	Constant  string                // Constant value (parser usage)
+
+	// Expr is a boolean expression used instead of Field/Predicate/Constant
+	// when the condition is not a simple column comparison (e.g. CASE ... END = 'x').
+	Expr Expr
+
+	// Param is the number of the bind parameter the field is compared with
+	// (WHERE field = $1), or 0. Constant holds the parameter's value once the
+	// planner has bound it.
+	Param int
+
+	// Ref is the unquoted table.column the field is compared with, or "".
+	// In a LATERAL subquery it names a column of a table to the left of the
+	// subquery, whose value the planner substitutes for Constant row by row.
+	Ref string
 }
 
 // NewFilterNode creates a new simple filter node for parser usage.
@@ -32,18 +46,47 @@ func NewFilterNode(table, field string, predicate primitives.Predicate, constant
 				Column:    field,
 				Predicate: predicate,
 				Value:     constant,
-				Type:      StandardPredicate,
+				Type:      PredicateTypeOf(predicate),
 			},
 		},
 	}
 }
 
+// NewParamFilterNode creates a simple filter node comparing field with bind
+// parameter $param. Until the parameter is bound, Constant is "$param".
+func NewParamFilterNode(table, field string, predicate primitives.Predicate, param int) *FilterNode {
+	filter := NewFilterNode(table, field, predicate, fmt.Sprintf("$%d", param))
+	filter.Param = param
+	return filter
+}
+
+// BindConstant sets the constant of a filter on a bind parameter to the
+// parameter's value.
+func (f *FilterNode) BindConstant(constant string) {
+	f.Constant = constant
+	for i := range f.Predicates {
+		f.Predicates[i].Value = constant
+	}
+}
+
+// NewExprFilterNode creates a filter node that keeps rows for which the
+// boolean expression evaluates to true.
+func NewExprFilterNode(table string, expr Expr) *FilterNode {
+	return &FilterNode{
+		Table: table,
+		Expr:  expr,
+	}
+}
+
 func (f *FilterNode) GetNodeType() string {
 	return "Filter"
 }
 
 func (f *FilterNode) String() string {
 	// Simple format for parser usage (when no child is set)
+	if f.Child == nil && f.Expr != nil {
+		return fmt.Sprintf("Filter[%s]", f.Expr)
+	}
 	if f.Child == nil && f.Field != "" {
 		return fmt.Sprintf("Filter[%s.%s %s %s]", f.Table, f.Field, f.Predicate, f.Constant)
 	}
@@ -92,6 +135,7 @@ type AggregateNode struct {
 	Child        PlanNode // Input relation
 	GroupByExprs []string // GROUP BY expressions
 	AggFunctions []string // Aggregate functions (COUNT, SUM, etc.)
+	GroupingSets []bool   // GROUPING SETS, ROLLUP or CUBE: true for the sets grouping by GroupByExprs
 }
 
 func (a *AggregateNode) GetNodeType() string {
@@ -106,6 +150,25 @@ func (a *AggregateNode) String() string {
 	return sb.String()
 }
 
+// GroupByClause returns the GROUP BY clause of the aggregation without the
+// GROUP BY keywords, or "" if it does not group.
+func (a *AggregateNode) GroupByClause() string {
+	keys := strings.Join(a.GroupByExprs, ", ")
+	if a.GroupingSets == nil {
+		return keys
+	}
+
+	sets := make([]string, len(a.GroupingSets))
+	for i, grouped := range a.GroupingSets {
+		if grouped {
+			sets[i] = "(" + keys + ")"
+		} else {
+			sets[i] = "()"
+		}
+	}
+	return fmt.Sprintf("GROUPING SETS (%s)", strings.Join(sets, ", "))
+}
+
 func (a *AggregateNode) GetChildren() []PlanNode {
 	return []PlanNode{a.Child}
 }
@@ -187,6 +250,7 @@ type DistinctNode struct {
 	BasePlanNode
 	Child         PlanNode // Input relation
 	DistinctExprs []string // Columns to consider for distinctness (empty = all columns)
+	On            bool     // DISTINCT ON: keep the first row per distinct DistinctExprs
 }
 
 func (d *DistinctNode) GetNodeType() string {
@@ -195,7 +259,10 @@ func (d *DistinctNode) GetNodeType() string {
 
 func (d *DistinctNode) String() string {
 	var sb strings.Builder
-	if len(d.DistinctExprs) > 0 {
+	if d.On {
+		sb.WriteString(fmt.Sprintf("DistinctOn(keys=%d, cost=%.2f, rows=%d)\n",
+			len(d.DistinctExprs), d.Cost, d.Cardinality))
+	} else if len(d.DistinctExprs) > 0 {
 		sb.WriteString(fmt.Sprintf("Distinct(columns=%d, cost=%.2f, rows=%d)\n",
 			len(d.DistinctExprs), d.Cost, d.Cardinality))
 	} else {
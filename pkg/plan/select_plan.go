@@ -4,8 +4,24 @@ import (
 	"fmt"
 	"storemy/pkg/primitives"
 	"strings"
+	"time"
 )
 
+// AsOf is the point in the past a SELECT ... AS OF reads its tables at:
+// either a log sequence number or, when ByTime is set, a wall-clock time.
+type AsOf struct {
+	LSN    primitives.LSN
+	Time   time.Time
+	ByTime bool
+}
+
+func (a *AsOf) String() string {
+	if a.ByTime {
+		return fmt.Sprintf("TIMESTAMP '%s'", a.Time.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("LSN %d", a.LSN)
+}
+
 // SelectPlan represents the execution plan for a SELECT query.
 // It contains all the parsed components of a SELECT statement including
 // projections, filters, joins, aggregations, ordering, and DISTINCT.
@@ -13,25 +29,33 @@ import (
 type SelectPlan struct {
 	selectList []*SelectListNode
 	selectAll  bool
-	distinct   bool // true for SELECT DISTINCT
+	distinct   bool   // true for SELECT DISTINCT
+	distinctOn []Expr // keys of SELECT DISTINCT ON (...)
 
 	tables []*ScanNode
 	joins  []*JoinNode
 
-	filters []*FilterNode
+	filters         []*FilterNode
+	subqueryFilters []*SubqueryFilter
 
 	hasAgg       bool
 	aggOp        string
 	aggField     string
 	groupByField string
+	groupByExpr  Expr
+	groupingSets []bool // nil without GROUPING SETS, ROLLUP or CUBE
+	having       Expr
 
 	hasOrderBy   bool
 	orderByField string
+	orderByExpr  Expr
 	orderByAsc   bool
 
 	hasLimit      bool
 	limit, offset primitives.RowID
 
+	asOf *AsOf // nil unless the query reads a past state
+
 	// Set operation fields
 	isSetOperation bool
 	setOpType      SetOperationType
@@ -39,6 +63,8 @@ type SelectPlan struct {
 	leftPlan       *SelectPlan
 	rightPlan      *SelectPlan
 
+	ctes []*CTE // Common table expressions of the WITH clause
+
 	query string
 }
 
@@ -70,11 +96,46 @@ func (sp *SelectPlan) AddProjectField(fieldName, aggOp string) error {
 	return nil
 }
 
+// AddProjectExpr adds a computed expression to the SELECT clause, output as
+// a column called name.
+func (sp *SelectPlan) AddProjectExpr(expr Expr, name string) {
+	sp.selectList = append(sp.selectList, NewSelectExprNode(expr, name))
+}
+
+// HasProjectExprs returns true if the SELECT clause contains computed expressions.
+func (sp *SelectPlan) HasProjectExprs() bool {
+	for _, node := range sp.selectList {
+		if node.Expr != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // SetGroupBy sets the GROUP BY field for the query.
 func (sp *SelectPlan) SetGroupBy(fieldName string) {
 	sp.groupByField = fieldName
 }
 
+// SetGroupByExpr sets a computed GROUP BY expression for the query.
+func (sp *SelectPlan) SetGroupByExpr(expr Expr) {
+	sp.groupByField = expr.String()
+	sp.groupByExpr = expr
+}
+
+// SetGroupingSets sets the grouping sets of GROUP BY GROUPING SETS, ROLLUP or
+// CUBE. A query has a single GROUP BY key, so each set is true if it groups
+// by the key and false for the empty set (), which aggregates all rows into
+// one group.
+func (sp *SelectPlan) SetGroupingSets(sets []bool) {
+	sp.groupingSets = sets
+}
+
+// SetHaving sets the HAVING condition, which filters groups after aggregation.
+func (sp *SelectPlan) SetHaving(expr Expr) {
+	sp.having = expr
+}
+
 // AddOrderBy adds an ORDER BY clause to the query.
 func (sp *SelectPlan) AddOrderBy(field string, ascending bool) {
 	sp.hasOrderBy = true
@@ -82,6 +143,14 @@ func (sp *SelectPlan) AddOrderBy(field string, ascending bool) {
 	sp.orderByAsc = ascending
 }
 
+// AddOrderByExpr adds an ORDER BY clause that sorts by a computed expression.
+func (sp *SelectPlan) AddOrderByExpr(expr Expr, ascending bool) {
+	sp.hasOrderBy = true
+	sp.orderByField = expr.String()
+	sp.orderByExpr = expr
+	sp.orderByAsc = ascending
+}
+
 // AddScan adds a table scan to the FROM clause.
 func (sp *SelectPlan) AddScan(tableName string, alias string) {
 	scan := NewScanNode(tableName, alias)
@@ -121,12 +190,98 @@ func (sp *SelectPlan) AddFilter(field string, pred primitives.Predicate, constan
 	return nil
 }
 
+// AddParamFilter adds a WHERE clause filter comparing field with bind
+// parameter $param, e.g. WHERE age > $1.
+func (sp *SelectPlan) AddParamFilter(field string, pred primitives.Predicate, param int) error {
+	if err := sp.AddFilter(field, pred, fmt.Sprintf("$%d", param)); err != nil {
+		return err
+	}
+	sp.filters[len(sp.filters)-1].Param = param
+	return nil
+}
+
+// AddRefFilter adds a WHERE clause filter comparing field with the column
+// ref, given unquoted as table.column, e.g. WHERE o.customer_id = c.id. Only
+// a LATERAL subquery resolves ref to a column of an enclosing query; anywhere
+// else it is compared as written, like any unquoted value.
+func (sp *SelectPlan) AddRefFilter(field string, pred primitives.Predicate, ref string) error {
+	if err := sp.AddFilter(field, pred, ref); err != nil {
+		return err
+	}
+	sp.filters[len(sp.filters)-1].Ref = ref
+	return nil
+}
+
+// WithRefs returns a copy of the plan in which each WHERE filter on a column
+// reference compares with values[ref] instead. Filters on references missing
+// from values are left out, which does not change the plan's output columns.
+func (sp *SelectPlan) WithRefs(values map[string]string) *SelectPlan {
+	c := *sp
+	c.filters = make([]*FilterNode, 0, len(sp.filters))
+	for _, filter := range sp.filters {
+		if filter.Ref == "" {
+			c.filters = append(c.filters, filter)
+			continue
+		}
+		value, ok := values[filter.Ref]
+		if !ok {
+			continue
+		}
+		bound := NewFilterNode(filter.Table, filter.Field, filter.Predicate, value)
+		bound.Ref = filter.Ref
+		c.filters = append(c.filters, bound)
+	}
+	return &c
+}
+
+// AddExprFilter adds a WHERE clause condition given as a boolean expression.
+func (sp *SelectPlan) AddExprFilter(expr Expr) {
+	table := ""
+	if len(sp.tables) > 0 {
+		table = sp.tables[0].TableName
+		if sp.tables[0].Alias != "" {
+			table = sp.tables[0].Alias
+		}
+	}
+	sp.filters = append(sp.filters, NewExprFilterNode(table, expr))
+}
+
+// AddSubqueryFilter adds a WHERE clause condition on a subquery.
+func (sp *SelectPlan) AddSubqueryFilter(filter *SubqueryFilter) {
+	sp.subqueryFilters = append(sp.subqueryFilters, filter)
+}
+
+// SubqueryFilters returns the WHERE clause conditions on subqueries.
+func (sp *SelectPlan) SubqueryFilters() []*SubqueryFilter {
+	return sp.subqueryFilters
+}
+
+// SetCTEs sets the common table expressions defined by the query's WITH
+// clause, in the order they are defined.
+func (sp *SelectPlan) SetCTEs(ctes []*CTE) {
+	sp.ctes = ctes
+}
+
+// CTEs returns the common table expressions of the WITH clause.
+func (sp *SelectPlan) CTEs() []*CTE {
+	return sp.ctes
+}
+
 // AddJoin adds a JOIN clause to the query.
 func (sp *SelectPlan) AddJoin(rightTable *ScanNode, joinType JoinType, leftField, rightField string, predicate primitives.Predicate) {
 	join := NewJoinNode(rightTable, joinType, leftField, rightField, predicate)
 	sp.joins = append(sp.joins, join)
 }
 
+// AddSubqueryJoin adds a JOIN whose right side is the subquery sub, named
+// alias. A lateral subquery may refer to the tables to its left.
+func (sp *SelectPlan) AddSubqueryJoin(sub *SelectPlan, alias string, lateral bool, joinType JoinType, leftField, rightField string, predicate primitives.Predicate) {
+	sp.AddJoin(NewScanNode(alias, alias), joinType, leftField, rightField, predicate)
+	join := sp.joins[len(sp.joins)-1]
+	join.Subquery = sub
+	join.Lateral = lateral
+}
+
 func (sp *SelectPlan) SelectList() []*SelectListNode {
 	return sp.selectList
 }
@@ -167,6 +322,23 @@ func (sp *SelectPlan) GroupByField() string {
 	return sp.groupByField
 }
 
+// GroupByExpr returns the GROUP BY expression, or nil when grouping by a plain
+// field or not grouping at all.
+func (sp *SelectPlan) GroupByExpr() Expr {
+	return sp.groupByExpr
+}
+
+// GroupingSets returns the grouping sets of the query (see SetGroupingSets),
+// or nil for a plain GROUP BY.
+func (sp *SelectPlan) GroupingSets() []bool {
+	return sp.groupingSets
+}
+
+// Having returns the HAVING condition, or nil if the query has none.
+func (sp *SelectPlan) Having() Expr {
+	return sp.having
+}
+
 func (sp *SelectPlan) HasOrderBy() bool {
 	return sp.hasOrderBy
 }
@@ -175,6 +347,11 @@ func (sp *SelectPlan) OrderByField() string {
 	return sp.orderByField
 }
 
+// OrderByExpr returns the ORDER BY expression, or nil when sorting by a plain field.
+func (sp *SelectPlan) OrderByExpr() Expr {
+	return sp.orderByExpr
+}
+
 func (sp *SelectPlan) OrderByAsc() bool {
 	return sp.orderByAsc
 }
@@ -225,6 +402,18 @@ func (sp *SelectPlan) IsDistinct() bool {
 	return sp.distinct
 }
 
+// SetDistinctOn makes this a SELECT DISTINCT ON (keys) query, which returns
+// the first row of each group of rows with equal keys.
+func (sp *SelectPlan) SetDistinctOn(keys []Expr) {
+	sp.distinct = true
+	sp.distinctOn = keys
+}
+
+// DistinctOn returns the keys of a SELECT DISTINCT ON query, or nil.
+func (sp *SelectPlan) DistinctOn() []Expr {
+	return sp.distinctOn
+}
+
 // SetLimit sets the LIMIT clause for the query.
 func (sp *SelectPlan) SetLimit(limit, offset primitives.RowID) {
 	sp.hasLimit = true
@@ -242,6 +431,17 @@ func (sp *SelectPlan) Limit() primitives.RowID {
 	return sp.limit
 }
 
+// SetAsOf makes the query read its tables as they were at the given point.
+func (sp *SelectPlan) SetAsOf(asOf *AsOf) {
+	sp.asOf = asOf
+}
+
+// AsOf returns the point the query reads its tables at, or nil for the
+// current state.
+func (sp *SelectPlan) AsOf() *AsOf {
+	return sp.asOf
+}
+
 // Offset returns the OFFSET value.
 func (sp *SelectPlan) Offset() primitives.RowID {
 	return sp.offset
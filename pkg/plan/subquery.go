@@ -0,0 +1,86 @@
+package plan
+
+import (
+	"fmt"
+	"storemy/pkg/primitives"
+	"strings"
+)
+
+// SubqueryFilter is a WHERE condition on a subquery: [NOT] EXISTS (SELECT ...)
+// or field [NOT] IN (SELECT ...). The subquery may compare its columns with
+// those of the enclosing query, as in EXISTS (SELECT * FROM orders o WHERE
+// o.customer_id = c.id); see SelectPlan.AddRefFilter.
+type SubqueryFilter struct {
+	Exists   bool   // EXISTS rather than IN
+	Negated  bool   // NOT EXISTS or NOT IN
+	Field    string // The field IN looks up in the subquery's single column
+	Subquery *SelectPlan
+}
+
+func (f *SubqueryFilter) String() string {
+	not := ""
+	if f.Negated {
+		not = "NOT "
+	}
+	if f.Exists {
+		return fmt.Sprintf("%sEXISTS (subquery)", not)
+	}
+	return fmt.Sprintf("%s %sIN (subquery)", f.Field, not)
+}
+
+// OuterRefs returns the subquery's WHERE filters that compare its columns
+// with a column of the enclosing query, that is, with a column of a table
+// not in the subquery's own FROM clause.
+func (f *SubqueryFilter) OuterRefs() []*FilterNode {
+	inner := make(map[string]bool)
+	for _, table := range f.Subquery.Tables() {
+		inner[scanName(table)] = true
+	}
+	for _, join := range f.Subquery.Joins() {
+		inner[scanName(join.RightTable)] = true
+	}
+
+	var refs []*FilterNode
+	for _, filter := range f.Subquery.Filters() {
+		if filter.Ref == "" {
+			continue
+		}
+		table, _, _ := strings.Cut(filter.Ref, ".")
+		if !inner[table] {
+			refs = append(refs, filter)
+		}
+	}
+	return refs
+}
+
+// Decorrelatable reports whether the subquery can run once, as the right
+// side of a hash semi-join keyed on its comparisons with the enclosing
+// query, rather than once for every row of that query. This requires every
+// such comparison to be an equality, and a subquery without aggregates or
+// LIMIT, whose rows would otherwise depend on the comparisons being applied
+// first. A correlated IN must also return a plain column, which the join
+// can still find once the subquery returns all its columns.
+func (f *SubqueryFilter) Decorrelatable() bool {
+	refs := f.OuterRefs()
+	if len(refs) == 0 {
+		return true
+	}
+	for _, ref := range refs {
+		if ref.Predicate != primitives.Equals {
+			return false
+		}
+	}
+	if f.Subquery.HasAgg() || f.Subquery.HasLimit() {
+		return false
+	}
+	return f.Exists || f.Subquery.SelectList()[0].Expr == nil
+}
+
+// scanName returns the name a FROM item is referred to by: its alias, if
+// given, or its table's name.
+func scanName(s *ScanNode) string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.TableName
+}
@@ -1,21 +1,32 @@
 package planner
 
 import (
+	"encoding/json"
 	"fmt"
+	"storemy/pkg/logging"
 	"storemy/pkg/optimizer"
 	"storemy/pkg/parser/statements"
 	"storemy/pkg/plan"
 	"storemy/pkg/planner/internal/result"
+	"storemy/pkg/tracing"
 	"strings"
+	"time"
 )
 
 // ExplainPlan generates an execution plan for a query without executing it.
 // It builds the logical plan, applies optimizer transformations, and returns
 // a formatted representation of the plan tree with cost estimates.
+// With ANALYZE the statement is also executed and its actual row count and
+// execution time are reported; VERBOSE adds the query's trace span tree. The
+// rows the filtered base scan of a SELECT actually produced are recorded as
+// cardinality feedback, which corrects the estimates of later plans. FORMAT
+// EDUCATIONAL with ANALYZE lists every operator call of the execution.
 type ExplainPlan struct {
 	ctx       DbContext
 	tx        TxContext
 	statement *statements.ExplainStatement
+	optimizer *optimizer.QueryOptimizer // Set once the plan has been optimized
+	logger    logging.ComponentLogger   // Passed to the planner of the ANALYZE run
 }
 
 // NewExplainPlan creates a new EXPLAIN plan.
@@ -24,6 +35,7 @@ func NewExplainPlan(stmt *statements.ExplainStatement, ctx DbContext, tx TxConte
 		ctx:       ctx,
 		tx:        tx,
 		statement: stmt,
+		logger:    logging.ForComponent("query_planner"),
 	}
 }
 
@@ -47,6 +59,13 @@ func (p *ExplainPlan) Execute() (result.Result, error) {
 	// Format the plan for output
 	planText := p.formatPlan(optimizedPlan)
 
+	if p.statement.Options.Analyze {
+		planText, err = p.analyze(planText)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Return the explain result
 	return result.NewExplainResult(
 		planText,
@@ -55,6 +74,156 @@ func (p *ExplainPlan) Execute() (result.Result, error) {
 	), nil
 }
 
+// analyze executes the underlying statement in the current transaction and
+// appends the actual execution statistics to the formatted plan.
+func (p *ExplainPlan) analyze(planText string) (string, error) {
+	start := time.Now()
+
+	// Per-operator row counts are needed for the cardinality feedback; trace
+	// the execution in detail if the query is not traced already.
+	trace := p.tx.Trace()
+	if !trace.Detailed() {
+		trace = tracing.NewTrace("analyze", start)
+		trace.SetDetailed(true)
+		prev := p.tx.Trace()
+		p.tx.SetTrace(trace)
+		defer p.tx.SetTrace(prev)
+	}
+
+	// The educational format walks through the execution step by step
+	var steps []tracing.StepEvent
+	educational := p.statement.Options.Format == "EDUCATIONAL"
+	if educational {
+		prevObserver := trace.StepObserver()
+		trace.SetStepObserver(func(e tracing.StepEvent) {
+			steps = append(steps, e)
+			if prevObserver != nil {
+				prevObserver(e)
+			}
+		})
+		defer trace.SetStepObserver(prevObserver)
+	}
+
+	inner := NewQueryPlanner(p.ctx)
+	inner.SetLogger(p.logger)
+	innerPlan, err := inner.Plan(p.statement.Statement, p.tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to plan statement for ANALYZE: %w", err)
+	}
+
+	res, err := innerPlan.Execute()
+	if err != nil {
+		return "", fmt.Errorf("failed to execute statement for ANALYZE: %w", err)
+	}
+	elapsed := time.Since(start)
+	rows := actualRows(res)
+	p.recordFeedback(trace)
+
+	if p.statement.Options.Format == "JSON" {
+		return p.formatAnalyzeJSON(planText, rows, elapsed, trace)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(planText)
+	sb.WriteString(fmt.Sprintf("Actual Rows: %d\n", rows))
+	sb.WriteString(fmt.Sprintf("Execution Time: %.3fms\n", float64(elapsed.Microseconds())/1000))
+	if p.statement.Options.Verbose {
+		sb.WriteString("\nTrace:\n")
+		sb.WriteString(trace.Format())
+	}
+	if educational {
+		sb.WriteString(formatStepThrough(steps))
+	}
+	return sb.String(), nil
+}
+
+// formatAnalyzeJSON wraps the JSON plan together with the execution statistics.
+func (p *ExplainPlan) formatAnalyzeJSON(planText string, rows int, elapsed time.Duration, trace *tracing.Trace) (string, error) {
+	out := struct {
+		Plan            json.RawMessage   `json:"plan"`
+		ActualRows      int               `json:"actualRows"`
+		ExecutionTimeMs float64           `json:"executionTimeMs"`
+		Trace           *tracing.SpanTree `json:"trace,omitempty"`
+	}{
+		ActualRows:      rows,
+		ExecutionTimeMs: float64(elapsed.Microseconds()) / 1000,
+	}
+
+	if json.Valid([]byte(planText)) {
+		out.Plan = json.RawMessage(planText)
+	} else {
+		quoted, _ := json.Marshal(planText)
+		out.Plan = quoted
+	}
+
+	if p.statement.Options.Verbose {
+		tree := trace.Tree()
+		out.Trace = &tree
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode EXPLAIN ANALYZE output: %w", err)
+	}
+	return string(data), nil
+}
+
+// recordFeedback records the rows produced by the base table scan of an
+// analyzed SELECT, read from its span in trace, against the optimizer's
+// estimate for the scan. Only scans with simple predicates are recorded, and
+// not under a LIMIT, which stops the scan before it has read everything.
+func (p *ExplainPlan) recordFeedback(trace *tracing.Trace) {
+	stmt, ok := p.statement.Statement.(*statements.SelectStatement)
+	if !ok || p.optimizer == nil {
+		return
+	}
+	selectPlan := stmt.Plan
+	if selectPlan.IsSetOperation() || selectPlan.HasLimit() || len(selectPlan.Tables()) == 0 {
+		return
+	}
+
+	node, err := p.buildScanNode(selectPlan.Tables()[0], selectPlan.Filters())
+	if err != nil {
+		return
+	}
+	scan, ok := node.(*plan.ScanNode)
+	if !ok || len(scan.Predicates) == 0 {
+		return
+	}
+
+	if rows, ok := spanRows(trace.Tree(), "Scan "+scan.TableName); ok {
+		p.optimizer.RecordScanFeedback(scan, rows)
+	}
+}
+
+// spanRows returns the row count recorded on the first span named name in a
+// depth-first walk of tree. The base table scan of a query is the leftmost
+// input of its operator tree, so it is found before the scans of joined tables.
+func spanRows(tree tracing.SpanTree, name string) (int64, bool) {
+	if tree.Name == name {
+		rows, ok := tree.Attributes[tracing.RowsAttr].(int64)
+		return rows, ok
+	}
+	for _, child := range tree.Children {
+		if rows, ok := spanRows(child, name); ok {
+			return rows, true
+		}
+	}
+	return 0, false
+}
+
+// actualRows returns the number of rows produced or affected by a statement.
+func actualRows(res result.Result) int {
+	switch r := res.(type) {
+	case *result.SelectQueryResult:
+		return len(r.Tuples)
+	case *result.DMLResult:
+		return r.RowsAffected
+	default:
+		return 0
+	}
+}
+
 // buildLogicalPlan constructs the logical plan tree for the underlying statement.
 // It converts the parsed statement into a PlanNode tree that can be optimized.
 func (p *ExplainPlan) buildLogicalPlan() (plan.PlanNode, error) {
@@ -113,23 +282,51 @@ func (p *ExplainPlan) buildSelectPlan(stmt *statements.SelectStatement) (plan.Pl
 		}
 	}
 
+	// Apply [NOT] EXISTS and [NOT] IN conditions as semi-joins and anti-joins
+	for _, filter := range selectPlan.SubqueryFilters() {
+		currentNode, err = p.buildSemiJoinNode(currentNode, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply aggregation if present
 	if selectPlan.HasAgg() {
 		currentNode = p.buildAggregateNode(currentNode, selectPlan)
 	}
 
+	// Apply HAVING as a filter on the aggregated groups
+	if selectPlan.Having() != nil {
+		currentNode = &plan.FilterNode{
+			BasePlanNode: plan.BasePlanNode{
+				Children: []plan.PlanNode{currentNode},
+			},
+			Child: currentNode,
+			Expr:  selectPlan.Having(),
+		}
+	}
+
+	// Apply DISTINCT ON, after sorting by ORDER BY, before the projection
+	distinctOn := len(selectPlan.DistinctOn()) > 0
+	if distinctOn {
+		if selectPlan.HasOrderBy() {
+			currentNode = p.buildSortNode(currentNode, selectPlan.OrderByField(), selectPlan.OrderByAsc())
+		}
+		currentNode = p.buildDistinctNode(currentNode, selectPlan)
+	}
+
 	// Apply projection if not SELECT *
 	if !selectPlan.SelectAll() {
 		currentNode = p.buildProjectNode(currentNode, selectPlan.SelectList())
 	}
 
 	// Apply DISTINCT if specified
-	if selectPlan.IsDistinct() && !selectPlan.HasAgg() {
-		currentNode = p.buildDistinctNode(currentNode)
+	if selectPlan.IsDistinct() && !selectPlan.HasAgg() && !distinctOn {
+		currentNode = p.buildDistinctNode(currentNode, selectPlan)
 	}
 
 	// Apply ORDER BY if present
-	if selectPlan.HasOrderBy() {
+	if selectPlan.HasOrderBy() && !distinctOn {
 		currentNode = p.buildSortNode(currentNode, selectPlan.OrderByField(), selectPlan.OrderByAsc())
 	}
 
@@ -151,6 +348,19 @@ func (p *ExplainPlan) buildScanNode(table *plan.ScanNode, filters []*plan.Filter
 		Predicates:   make([]plan.PredicateInfo, 0),
 	}
 
+	// Expression conditions (e.g. involving CASE) are not scan predicates:
+	// they are evaluated by a filter on top of the scan.
+	if len(filters) > 0 && filters[0] != nil && filters[0].Expr != nil {
+		return &plan.FilterNode{
+			BasePlanNode: plan.BasePlanNode{
+				Children: []plan.PlanNode{scanNode},
+			},
+			Child: scanNode,
+			Table: filters[0].Table,
+			Expr:  filters[0].Expr,
+		}, nil
+	}
+
 	// Add filter predicates from the first filter (if any)
 	if len(filters) > 0 && filters[0] != nil {
 		filter := filters[0]
@@ -158,7 +368,7 @@ func (p *ExplainPlan) buildScanNode(table *plan.ScanNode, filters []*plan.Filter
 			Column:    filter.Field,
 			Predicate: filter.Predicate,
 			Value:     filter.Constant,
-			Type:      plan.StandardPredicate,
+			Type:      plan.PredicateTypeOf(filter.Predicate),
 		})
 	}
 
@@ -170,14 +380,21 @@ func (p *ExplainPlan) buildJoinNodes(leftNode plan.PlanNode, joins []*plan.JoinN
 	currentNode := leftNode
 
 	for _, joinSpec := range joins {
-		// Build scan for right table
-		rightNode := &plan.ScanNode{
+		// Build scan for right table, or the plan of a subquery
+		var rightNode plan.PlanNode = &plan.ScanNode{
 			BasePlanNode: plan.BasePlanNode{},
 			TableName:    joinSpec.RightTable.TableName,
 			Alias:        joinSpec.RightTable.Alias,
 			AccessMethod: "seqscan",
 			Predicates:   make([]plan.PredicateInfo, 0),
 		}
+		if joinSpec.Subquery != nil {
+			var err error
+			rightNode, err = p.buildSelectPlan(statements.NewSelectStatement(joinSpec.Subquery))
+			if err != nil {
+				return nil, err
+			}
+		}
 
 		// Create join node
 		joinNode := &plan.JoinNode{
@@ -189,6 +406,12 @@ func (p *ExplainPlan) buildJoinNodes(leftNode plan.PlanNode, joins []*plan.JoinN
 			JoinType:    joinSpec.JoinType,
 			LeftColumn:  joinSpec.LeftField,
 			RightColumn: joinSpec.RightField,
+			RightTable:  joinSpec.RightTable,
+			Subquery:    joinSpec.Subquery,
+			Lateral:     joinSpec.Lateral,
+		}
+		if joinSpec.Lateral || joinSpec.JoinType == "cross" {
+			joinNode.JoinMethod = "nested-loop"
 		}
 
 		currentNode = joinNode
@@ -197,6 +420,37 @@ func (p *ExplainPlan) buildJoinNodes(leftNode plan.PlanNode, joins []*plan.JoinN
 	return currentNode, nil
 }
 
+// buildSemiJoinNode creates the semi-join or anti-join that evaluates a
+// subquery condition on the rows of leftNode: a hash join, or a nested loop
+// running the subquery for each row if it cannot be decorrelated.
+func (p *ExplainPlan) buildSemiJoinNode(leftNode plan.PlanNode, filter *plan.SubqueryFilter) (plan.PlanNode, error) {
+	rightNode, err := p.buildSelectPlan(statements.NewSelectStatement(filter.Subquery))
+	if err != nil {
+		return nil, err
+	}
+
+	joinType := "semi"
+	if filter.Negated {
+		joinType = "anti"
+	}
+	method := "hash"
+	if !filter.Decorrelatable() {
+		method = "nested-loop"
+	}
+	return &plan.JoinNode{
+		BasePlanNode: plan.BasePlanNode{
+			Children: []plan.PlanNode{leftNode, rightNode},
+		},
+		LeftChild:  leftNode,
+		RightChild: rightNode,
+		JoinType:   joinType,
+		JoinMethod: method,
+		LeftColumn: filter.Field,
+		Subquery:   filter.Subquery,
+		Condition:  filter,
+	}, nil
+}
+
 // buildAggregateNode creates an aggregation node.
 func (p *ExplainPlan) buildAggregateNode(child plan.PlanNode, selectPlan *plan.SelectPlan) plan.PlanNode {
 	groupBy := make([]string, 0)
@@ -215,6 +469,7 @@ func (p *ExplainPlan) buildAggregateNode(child plan.PlanNode, selectPlan *plan.S
 		Child:        child,
 		GroupByExprs: groupBy,
 		AggFunctions: aggFunctions,
+		GroupingSets: selectPlan.GroupingSets(),
 	}
 }
 
@@ -234,13 +489,27 @@ func (p *ExplainPlan) buildProjectNode(child plan.PlanNode, selectList []*plan.S
 	}
 }
 
-// buildDistinctNode creates a distinct node.
-func (p *ExplainPlan) buildDistinctNode(child plan.PlanNode) plan.PlanNode {
+// buildDistinctNode creates a distinct node. Plain DISTINCT compares the
+// columns of the SELECT list (all columns for SELECT *), DISTINCT ON its keys.
+func (p *ExplainPlan) buildDistinctNode(child plan.PlanNode, selectPlan *plan.SelectPlan) plan.PlanNode {
+	exprs := make([]string, 0)
+	if keys := selectPlan.DistinctOn(); len(keys) > 0 {
+		for _, key := range keys {
+			exprs = append(exprs, key.String())
+		}
+	} else if !selectPlan.SelectAll() {
+		for _, field := range selectPlan.SelectList() {
+			exprs = append(exprs, field.FieldName)
+		}
+	}
+
 	return &plan.DistinctNode{
 		BasePlanNode: plan.BasePlanNode{
 			Children: []plan.PlanNode{child},
 		},
-		Child: child,
+		Child:         child,
+		DistinctExprs: exprs,
+		On:            len(selectPlan.DistinctOn()) > 0,
 	}
 }
 
@@ -337,7 +606,7 @@ func (p *ExplainPlan) buildUpdatePlan(stmt *statements.UpdateStatement) (plan.Pl
 			Column:    stmt.WhereClause.Field,
 			Predicate: stmt.WhereClause.Predicate,
 			Value:     stmt.WhereClause.Constant,
-			Type:      plan.StandardPredicate,
+			Type:      plan.PredicateTypeOf(stmt.WhereClause.Predicate),
 		})
 	}
 
@@ -368,7 +637,7 @@ func (p *ExplainPlan) buildDeletePlan(stmt *statements.DeleteStatement) (plan.Pl
 			Column:    stmt.WhereClause.Field,
 			Predicate: stmt.WhereClause.Predicate,
 			Value:     stmt.WhereClause.Constant,
-			Type:      plan.StandardPredicate,
+			Type:      plan.PredicateTypeOf(stmt.WhereClause.Predicate),
 		})
 	}
 
@@ -394,14 +663,19 @@ func (p *ExplainPlan) buildDDLPlan(operation, objectName string) plan.PlanNode {
 // optimizePlan applies the query optimizer to the logical plan.
 func (p *ExplainPlan) optimizePlan(planNode plan.PlanNode) (plan.PlanNode, error) {
 	// Get optimizer from context
-	optimizerInstance, err := optimizer.NewQueryOptimizer(
-		p.ctx.CatalogManager(),
-		optimizer.DefaultOptimizerConfig(),
-	)
+	config := optimizer.DefaultOptimizerConfig()
+	config.Feedback = p.ctx.CardinalityFeedback()
+	config.Rules = p.ctx.RewriteRules()
+	if store := p.ctx.Settings(); store != nil {
+		costs := store.Settings().CostParameters()
+		config.CostParameters = &costs
+	}
+	optimizerInstance, err := optimizer.NewQueryOptimizer(p.ctx.CatalogManager(), config)
 	if err != nil {
 		// If optimizer creation fails, return unoptimized plan
 		return planNode, nil
 	}
+	p.optimizer = optimizerInstance
 
 	// Apply optimization
 	optimizedPlan, err := optimizerInstance.Optimize(p.tx, planNode)
@@ -504,10 +778,40 @@ func (p *ExplainPlan) formatNodeDetails(node plan.PlanNode) string {
 		if joinTypeStr == "" {
 			joinTypeStr = "inner"
 		}
-		return fmt.Sprintf("%s JOIN on %s = %s %s",
-			joinTypeStr, n.LeftColumn, n.RightColumn, baseInfo)
+		details := joinTypeStr + " JOIN"
+		if n.IsOuter() {
+			details = joinTypeStr + " outer JOIN"
+		}
+		if n.Condition != nil {
+			method := "hash"
+			if n.JoinMethod == "nested-loop" {
+				method = "nested loop"
+			}
+			details += fmt.Sprintf(" for %s using %s", n.Condition, method)
+			return fmt.Sprintf("%s %s", details, baseInfo)
+		}
+		if n.Subquery != nil {
+			subquery := "subquery " + n.RightTable.Alias
+			if n.Lateral {
+				subquery = "LATERAL " + subquery
+			}
+			details += fmt.Sprintf(" [%s]", subquery)
+		}
+		if n.JoinType != "cross" {
+			details += fmt.Sprintf(" on %s = %s", n.LeftColumn, n.RightColumn)
+		}
+		if n.IsOuter() {
+			details += fmt.Sprintf(" [NULL-extends %s]", n.NullExtendedSide())
+		}
+		if n.JoinMethod == "nested-loop" {
+			details += " using nested loop"
+		}
+		return fmt.Sprintf("%s %s", details, baseInfo)
 
 	case *plan.FilterNode:
+		if n.Expr != nil {
+			return fmt.Sprintf("Filter on %s %s", n.Expr, baseInfo)
+		}
 		return fmt.Sprintf("Filter (%d predicates) %s",
 			len(n.Predicates), baseInfo)
 
@@ -517,8 +821,8 @@ func (p *ExplainPlan) formatNodeDetails(node plan.PlanNode) string {
 
 	case *plan.AggregateNode:
 		aggInfo := fmt.Sprintf("Aggregate [%s]", strings.Join(n.AggFunctions, ", "))
-		if len(n.GroupByExprs) > 0 {
-			aggInfo += fmt.Sprintf(" GROUP BY %s", strings.Join(n.GroupByExprs, ", "))
+		if clause := n.GroupByClause(); clause != "" {
+			aggInfo += " GROUP BY " + clause
 		}
 		return fmt.Sprintf("%s %s", aggInfo, baseInfo)
 
@@ -531,6 +835,12 @@ func (p *ExplainPlan) formatNodeDetails(node plan.PlanNode) string {
 			n.Limit, n.Offset, baseInfo)
 
 	case *plan.DistinctNode:
+		if n.On {
+			return fmt.Sprintf("Distinct ON (%s) %s", strings.Join(n.DistinctExprs, ", "), baseInfo)
+		}
+		if len(n.DistinctExprs) > 0 {
+			return fmt.Sprintf("Distinct on %s %s", strings.Join(n.DistinctExprs, ", "), baseInfo)
+		}
 		return fmt.Sprintf("Distinct %s", baseInfo)
 
 	case *plan.SetOpNode:
@@ -3,6 +3,7 @@ package planner
 import (
 	"fmt"
 	"storemy/pkg/plan"
+	"storemy/pkg/tracing"
 	"strings"
 )
 
@@ -89,6 +90,12 @@ func (p *ExplainPlan) getNodeExplanation(node plan.PlanNode) string {
 		return "💡 Reading all rows from table (full table scan)"
 
 	case *plan.JoinNode:
+		if n.Condition != nil {
+			return fmt.Sprintf("💡 Keeping rows for which %s holds, running the subquery once and looking rows up in a hash table of its results", n.Condition)
+		}
+		if n.IsOuter() {
+			return fmt.Sprintf("💡 Combining data from two tables using %s method, keeping unmatched rows and filling the %s side with NULLs", n.JoinMethod, n.NullExtendedSide())
+		}
 		return fmt.Sprintf("💡 Combining data from two tables using %s method", n.JoinMethod)
 
 	case *plan.FilterNode:
@@ -271,6 +278,7 @@ func (p *ExplainPlan) getEducationalResources(node plan.PlanNode) string {
 
 	sb.WriteString("\n  📚 To learn more about query optimization:\n")
 	sb.WriteString("     - Use EXPLAIN ANALYZE to see actual execution statistics\n")
+	sb.WriteString("     - Use EXPLAIN ANALYZE FORMAT EDUCATIONAL to step through the execution\n")
 	sb.WriteString("     - Compare costs between different query formulations\n")
 	sb.WriteString("     - Monitor for sequential scans on large tables\n")
 	sb.WriteString("     - Create indexes for frequently filtered/joined columns\n")
@@ -294,6 +302,12 @@ func (p *ExplainPlan) collectConcepts(node plan.PlanNode, concepts map[string]st
 
 	case *plan.JoinNode:
 		concepts["JOIN"] = "Combining rows from two or more tables based on a related column"
+		if n.IsOuter() {
+			concepts["OUTER JOIN"] = "Keeping rows without a match on the other side, whose columns are filled with NULLs"
+		}
+		if n.Condition != nil {
+			concepts["SEMI/ANTI JOIN"] = "Keeping each row once if it has a match (EXISTS, IN) or only if it has none (NOT EXISTS, NOT IN)"
+		}
 
 	case *plan.AggregateNode:
 		concepts["Aggregation"] = "Computing summary values (COUNT, SUM, AVG, etc.)"
@@ -317,6 +331,41 @@ func (p *ExplainPlan) collectConcepts(node plan.PlanNode, concepts map[string]st
 	}
 }
 
+// maxEducationalSteps caps the steps listed by formatStepThrough: every row
+// pulled through every operator is a step, so large results would drown the
+// interesting part, how the operators hand rows to each other.
+const maxEducationalSteps = 200
+
+// formatStepThrough lists the operator calls made while a query executed, in
+// the order the executor made them, indented by the operator's place in the
+// plan tree.
+func formatStepThrough(steps []tracing.StepEvent) string {
+	var sb strings.Builder
+
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("═", 80))
+	sb.WriteString("\n")
+	sb.WriteString("👣 STEP-THROUGH EXECUTION\n")
+	sb.WriteString(strings.Repeat("─", 80))
+	sb.WriteString("\n")
+	sb.WriteString("  Each line is one call into an operator. The top operator asks its input\n")
+	sb.WriteString("  for a row, which asks its own input, and so on down to the table scans:\n")
+	sb.WriteString("  rows are pulled up through the plan one at a time.\n\n")
+
+	if len(steps) == 0 {
+		sb.WriteString("  (the statement ran no plan operators)\n")
+		return sb.String()
+	}
+
+	for _, step := range steps[:min(len(steps), maxEducationalSteps)] {
+		sb.WriteString(fmt.Sprintf("  %4d  %s%s\n", step.Seq, strings.Repeat("  ", step.Depth), step))
+	}
+	if len(steps) > maxEducationalSteps {
+		sb.WriteString(fmt.Sprintf("\n  ... %d more steps (showing the first %d)\n", len(steps)-maxEducationalSteps, maxEducationalSteps))
+	}
+	return sb.String()
+}
+
 // countNodes counts the total number of nodes in the subtree
 func (p *ExplainPlan) countNodes(node plan.PlanNode) int {
 	if node == nil {
@@ -0,0 +1,115 @@
+package ddl
+
+import (
+	"fmt"
+	"storemy/pkg/audit"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/result"
+	"storemy/pkg/primitives"
+)
+
+// AlterTableAuditPlan represents the execution plan for
+// ALTER TABLE ... ENABLE AUDIT and ALTER TABLE ... DISABLE AUDIT.
+//
+// Enabling creates the audit table <table>_AUDIT, or reuses an existing table
+// with the audit columns, and records the pair in CATALOG_AUDITED_TABLES; from
+// then on the DML executor writes one audit row per changed row. Disabling
+// removes the catalog record and keeps the audit table and its rows.
+//
+// Example:
+//
+//	ALTER TABLE orders ENABLE AUDIT;
+//	SELECT AUDIT_OP, OLD_ROW, NEW_ROW FROM ORDERS_AUDIT;
+type AlterTableAuditPlan struct {
+	Statement *statements.AlterTableAuditStatement
+	ctx       DbContext
+	tx        TxContext
+}
+
+// NewAlterTableAuditPlan creates a new ALTER TABLE ... AUDIT plan instance.
+func NewAlterTableAuditPlan(stmt *statements.AlterTableAuditStatement, ctx DbContext, tx TxContext) *AlterTableAuditPlan {
+	return &AlterTableAuditPlan{
+		Statement: stmt,
+		ctx:       ctx,
+		tx:        tx,
+	}
+}
+
+// Execute enables or disables auditing within the current transaction.
+//
+// Execution steps:
+//  1. Validates the table is a stored table and locks it exclusively
+//  2. Validates the table is not an audit table itself
+//  3. Enables or disables auditing in the catalog
+func (p *AlterTableAuditPlan) Execute() (result.Result, error) {
+	cm := p.ctx.CatalogManager()
+	tableName := p.Statement.TableName
+
+	if !cm.TableExists(p.tx, tableName) {
+		return nil, catalogerror.NewTableNotFound("table %s does not exist", tableName)
+	}
+	tableID, err := cm.LockTable(p.tx, tableName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	auditing, err := cm.GetAuditedBy(p.tx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if auditing != nil {
+		return nil, fmt.Errorf("table %s is an audit table", tableName)
+	}
+
+	if !p.Statement.Enable {
+		if err := cm.DisableAudit(p.tx, tableID); err != nil {
+			return nil, fmt.Errorf("failed to disable audit: %w", err)
+		}
+		return result.NewDDLResult(true, fmt.Sprintf("Audit disabled on %s", tableName)), nil
+	}
+
+	auditTableID, err := p.auditTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	md := catalogmanager.AuditMetadata{TableID: tableID, AuditTableID: auditTableID}
+	if err := cm.EnableAudit(p.tx, md); err != nil {
+		return nil, fmt.Errorf("failed to enable audit: %w", err)
+	}
+	return result.NewDDLResult(true, fmt.Sprintf("Audit enabled on %s, changes are recorded in %s", tableName, audit.TableName(tableName))), nil
+}
+
+// auditTable returns the ID of the audit table of tableName, creating it if
+// it does not exist. An existing table is reused only if it has the audit
+// columns.
+func (p *AlterTableAuditPlan) auditTable(tableName string) (primitives.FileID, error) {
+	cm := p.ctx.CatalogManager()
+	name := audit.TableName(tableName)
+
+	if !cm.TableExists(p.tx, name) {
+		sch, err := audit.Schema(tableName)
+		if err != nil {
+			return 0, err
+		}
+		id, err := cm.CreateTable(p.tx, sch)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create audit table %s: %w", name, err)
+		}
+		return id, nil
+	}
+
+	id, err := cm.LockTable(p.tx, name, true)
+	if err != nil {
+		return 0, err
+	}
+	sch, err := cm.GetTableSchema(p.tx, id)
+	if err != nil {
+		return 0, err
+	}
+	if !audit.Matches(sch.TupleDesc) {
+		return 0, fmt.Errorf("table %s exists and is not an audit table", name)
+	}
+	return id, nil
+}
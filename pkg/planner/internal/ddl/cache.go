@@ -0,0 +1,58 @@
+package ddl
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/memory"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/result"
+)
+
+// AlterTableCachePlan represents the execution plan for
+// ALTER TABLE ... SET CACHE {PINNED|HIGH|NORMAL}.
+//
+// It records the priority in CATALOG_CACHE_HINTS, where the database finds it
+// again on restart, and hands it to the buffer pool at once. The priority is
+// a hint: pages of a pinned or high priority table are evicted after those of
+// normal tables, but still when nothing else can be.
+//
+// Example:
+//
+//	ALTER TABLE regions SET CACHE PINNED;
+type AlterTableCachePlan struct {
+	Statement *statements.AlterTableCacheStatement
+	ctx       DbContext
+	tx        TxContext
+}
+
+// NewAlterTableCachePlan creates a new ALTER TABLE ... SET CACHE plan instance.
+func NewAlterTableCachePlan(stmt *statements.AlterTableCacheStatement, ctx DbContext, tx TxContext) *AlterTableCachePlan {
+	return &AlterTableCachePlan{
+		Statement: stmt,
+		ctx:       ctx,
+		tx:        tx,
+	}
+}
+
+// Execute sets the cache priority of the table within the current transaction.
+func (p *AlterTableCachePlan) Execute() (result.Result, error) {
+	cm := p.ctx.CatalogManager()
+	tableName := p.Statement.TableName
+
+	priority, err := memory.ParseCachePriority(p.Statement.Priority)
+	if err != nil {
+		return nil, err
+	}
+	if !cm.TableExists(p.tx, tableName) {
+		return nil, catalogerror.NewTableNotFound("table %s does not exist", tableName)
+	}
+	tableID, err := cm.LockTable(p.tx, tableName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cm.SetCacheHint(p.tx, tableID, priority); err != nil {
+		return nil, fmt.Errorf("failed to set cache priority: %w", err)
+	}
+	return result.NewDDLResult(true, fmt.Sprintf("Cache priority of %s set to %s", tableName, priority)), nil
+}
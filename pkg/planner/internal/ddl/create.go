@@ -2,6 +2,7 @@ package ddl
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/parser/statements"
@@ -75,12 +76,16 @@ func (p *CreateTablePlan) Execute() (result.Result, error) {
 func (p *CreateTablePlan) checkTableExists() (*result.DDLResult, error) {
 	cm := p.ctx.CatalogManager()
 	tableName := p.Statement.TableName
-	if cm.TableExists(p.TxContext, tableName) {
+	foreignTable, err := cm.GetForeignTable(p.TxContext, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if cm.TableExists(p.TxContext, tableName) || foreignTable != nil {
 		if p.Statement.IfNotExists {
 			msg := fmt.Sprintf("Table %s already exists (IF NOT EXISTS)", tableName)
 			return result.NewDDLResult(true, msg), nil
 		}
-		return nil, fmt.Errorf("table %s already exists", tableName)
+		return nil, catalogerror.NewTableExists("table %s already exists", tableName)
 	}
 	return nil, nil
 }
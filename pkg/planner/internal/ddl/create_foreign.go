@@ -0,0 +1,76 @@
+package ddl
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/foreign"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/result"
+)
+
+// CreateForeignTablePlan represents the execution plan for CREATE FOREIGN TABLE.
+// It validates the definition and records it in CATALOG_FOREIGN_TABLES; no
+// heap file is created and the external file is not read until the table is
+// scanned.
+//
+// Example:
+//
+//	CREATE FOREIGN TABLE visits (user_id INT, page STRING)
+//	OPTIONS (format 'csv', location '/data/visits.csv', header 'true');
+type CreateForeignTablePlan struct {
+	Statement *statements.CreateForeignTableStatement
+	ctx       DbContext
+	tx        TxContext
+}
+
+// NewCreateForeignTablePlan creates a new CREATE FOREIGN TABLE plan instance.
+func NewCreateForeignTablePlan(stmt *statements.CreateForeignTableStatement, ctx DbContext, tx TxContext) *CreateForeignTablePlan {
+	return &CreateForeignTablePlan{
+		Statement: stmt,
+		ctx:       ctx,
+		tx:        tx,
+	}
+}
+
+// Execute performs the CREATE FOREIGN TABLE operation within the current transaction.
+//
+// Execution steps:
+//  1. Validates no stored table, foreign table or system view has the name
+//     (respects IF NOT EXISTS clause)
+//  2. Validates the columns and options
+//  3. Records the definition in the catalog
+func (p *CreateForeignTablePlan) Execute() (result.Result, error) {
+	cm := p.ctx.CatalogManager()
+	tableName := p.Statement.TableName
+
+	existing, err := cm.GetForeignTable(p.tx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil || cm.TableExists(p.tx, tableName) {
+		if p.Statement.IfNotExists {
+			return result.NewDDLResult(true, fmt.Sprintf("Table %s already exists (IF NOT EXISTS)", tableName)), nil
+		}
+		return nil, catalogerror.NewTableExists("table %s already exists", tableName)
+	}
+	if _, ok := p.ctx.SystemViews().Lookup(tableName); ok {
+		return nil, fmt.Errorf("%s is the name of a system view", tableName)
+	}
+
+	columns := make([]foreign.Column, len(p.Statement.Fields))
+	for i, field := range p.Statement.Fields {
+		columns[i] = foreign.Column{Name: field.Name, Type: field.Type}
+	}
+
+	table, err := foreign.NewTable(tableName, columns, p.Statement.Options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid foreign table %s: %w", tableName, err)
+	}
+
+	if err := cm.AddForeignTable(p.tx, table.Metadata()); err != nil {
+		return nil, fmt.Errorf("failed to create foreign table: %w", err)
+	}
+
+	return result.NewDDLResult(true, fmt.Sprintf("Foreign table %s created over %s file %s",
+		tableName, table.Format, table.Location)), nil
+}
@@ -255,7 +255,7 @@ func TestCreateTablePlan_Execute_Error_TableAlreadyExists(t *testing.T) {
 	}
 
 	expectedError := "table users already exists"
-	if err.Error() != expectedError {
+	if !strings.Contains(err.Error(), expectedError) {
 		t.Errorf("Expected error %q, got %q", expectedError, err.Error())
 	}
 
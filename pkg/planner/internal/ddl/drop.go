@@ -2,6 +2,7 @@ package ddl
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/catalogmanager"
 	"storemy/pkg/parser/statements"
 	"storemy/pkg/planner/internal/result"
@@ -16,9 +17,12 @@ import (
 // Execution flow:
 //  1. Validates table exists (respects IF EXISTS clause)
 //  2. Drops all associated indexes (including auto-created primary key indexes)
-//  3. Removes table metadata from CATALOG_TABLES
+//  3. Removes table metadata from CATALOG_TABLES, along with the table's triggers
+//     and audit record; an audit table in use cannot be dropped
 //  4. CatalogManager handles heap file deletion and buffer pool eviction
 //
+// Dropping a foreign table only removes its definition from CATALOG_FOREIGN_TABLES.
+//
 // Example:
 //
 //	DROP TABLE users;                    -- Fails if not exists
@@ -54,15 +58,34 @@ func (p *DropTablePlan) Execute() (result.Result, error) {
 	tableName := p.Statement.TableName
 
 	if !cm.TableExists(p.tx, tableName) {
+		foreignTable, err := cm.GetForeignTable(p.tx, tableName)
+		if err != nil {
+			return nil, err
+		}
+		if foreignTable != nil {
+			return p.dropForeign()
+		}
 		if p.Statement.IfExists {
 			return result.NewDDLResult(true, fmt.Sprintf("Table %s does not exist (IF EXISTS)", tableName)), nil
 		}
-		return nil, fmt.Errorf("table %s does not exist", tableName)
+		return nil, catalogerror.NewTableNotFound("table %s does not exist", tableName)
 	}
 
-	tableID, err := cm.GetTableID(p.tx, tableName)
+	tableID, err := cm.LockTable(p.tx, tableName, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get table ID: %w", err)
+		return nil, err
+	}
+
+	auditing, err := cm.GetAuditedBy(p.tx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if auditing != nil {
+		audited, err := cm.GetTableMetadataByID(p.tx, auditing.TableID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("table %s is the audit table of %s; disable its audit first", tableName, audited.TableName)
 	}
 
 	if err := p.drop(tableID); err != nil {
@@ -72,6 +95,15 @@ func (p *DropTablePlan) Execute() (result.Result, error) {
 	return result.NewDDLResult(true, fmt.Sprintf("Table %s dropped successfully", tableName)), nil
 }
 
+// dropForeign removes a foreign table's definition. Its external file is left
+// in place.
+func (p *DropTablePlan) dropForeign() (result.Result, error) {
+	if err := p.ctx.CatalogManager().DropForeignTable(p.tx, p.Statement.TableName); err != nil {
+		return nil, fmt.Errorf("failed to drop foreign table: %w", err)
+	}
+	return result.NewDDLResult(true, fmt.Sprintf("Foreign table %s dropped successfully", p.Statement.TableName)), nil
+}
+
 func (p *DropTablePlan) drop(tableID primitives.FileID) error {
 	cm := p.ctx.CatalogManager()
 
@@ -10,6 +10,7 @@ import (
 	"storemy/pkg/registry"
 	"storemy/pkg/storage/index"
 	"storemy/pkg/types"
+	"strings"
 	"testing"
 )
 
@@ -347,7 +348,7 @@ func TestDropTablePlan_Execute_Error_TableDoesNotExist(t *testing.T) {
 	}
 
 	expectedError := "table nonexistent_table does not exist"
-	if err.Error() != expectedError {
+	if !strings.Contains(err.Error(), expectedError) {
 		t.Errorf("Expected error %q, got %q", expectedError, err.Error())
 	}
 }
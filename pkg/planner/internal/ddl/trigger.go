@@ -0,0 +1,125 @@
+package ddl
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/catalogerror"
+	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/result"
+)
+
+// CreateTriggerPlan represents the execution plan for CREATE TRIGGER.
+// It records the trigger in CATALOG_TRIGGERS; from then on the DML executor
+// runs the named function for every row of the table the event affects.
+//
+// Example:
+//
+//	CREATE TRIGGER audit_orders AFTER INSERT ON orders EXECUTE FUNCTION audit;
+type CreateTriggerPlan struct {
+	Statement *statements.CreateTriggerStatement
+	ctx       DbContext
+	tx        TxContext
+}
+
+// NewCreateTriggerPlan creates a new CREATE TRIGGER plan instance.
+func NewCreateTriggerPlan(stmt *statements.CreateTriggerStatement, ctx DbContext, tx TxContext) *CreateTriggerPlan {
+	return &CreateTriggerPlan{
+		Statement: stmt,
+		ctx:       ctx,
+		tx:        tx,
+	}
+}
+
+// Execute performs the CREATE TRIGGER operation within the current transaction.
+//
+// Execution steps:
+//  1. Validates no trigger has the name (respects IF NOT EXISTS clause)
+//  2. Validates the table is a stored table
+//  3. Validates the function is registered
+//  4. Records the trigger in the catalog
+func (p *CreateTriggerPlan) Execute() (result.Result, error) {
+	cm := p.ctx.CatalogManager()
+	stmt := p.Statement
+
+	existing, err := cm.GetTrigger(p.tx, stmt.TriggerName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if stmt.IfNotExists {
+			return result.NewDDLResult(true, fmt.Sprintf("Trigger %s already exists (IF NOT EXISTS)", stmt.TriggerName)), nil
+		}
+		return nil, catalogerror.NewObjectExists("trigger %s already exists", stmt.TriggerName)
+	}
+
+	if !cm.TableExists(p.tx, stmt.TableName) {
+		return nil, catalogerror.NewTableNotFound("table %s does not exist", stmt.TableName)
+	}
+	tableID, err := cm.LockTable(p.tx, stmt.TableName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.ctx.Triggers().Lookup(stmt.FunctionName); !ok {
+		return nil, fmt.Errorf("trigger function %s is not registered", stmt.FunctionName)
+	}
+
+	md := catalogmanager.TriggerMetadata{
+		TriggerName:  stmt.TriggerName,
+		TableID:      tableID,
+		Timing:       stmt.Timing,
+		Event:        stmt.Event,
+		FunctionName: stmt.FunctionName,
+	}
+	if err := cm.AddTrigger(p.tx, md); err != nil {
+		return nil, fmt.Errorf("failed to create trigger: %w", err)
+	}
+
+	return result.NewDDLResult(true, fmt.Sprintf("Trigger %s created on %s", stmt.TriggerName, stmt.TableName)), nil
+}
+
+// DropTriggerPlan represents the execution plan for DROP TRIGGER.
+//
+// Example:
+//
+//	DROP TRIGGER IF EXISTS audit_orders;
+type DropTriggerPlan struct {
+	Statement *statements.DropTriggerStatement
+	ctx       DbContext
+	tx        TxContext
+}
+
+// NewDropTriggerPlan creates a new DROP TRIGGER plan instance.
+func NewDropTriggerPlan(stmt *statements.DropTriggerStatement, ctx DbContext, tx TxContext) *DropTriggerPlan {
+	return &DropTriggerPlan{
+		Statement: stmt,
+		ctx:       ctx,
+		tx:        tx,
+	}
+}
+
+// Execute removes the trigger from the catalog within the current transaction.
+// The registered function is left in the registry.
+func (p *DropTriggerPlan) Execute() (result.Result, error) {
+	cm := p.ctx.CatalogManager()
+	name := p.Statement.TriggerName
+
+	existing, err := cm.GetTrigger(p.tx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		if p.Statement.IfExists {
+			return result.NewDDLResult(true, fmt.Sprintf("Trigger %s does not exist (IF EXISTS)", name)), nil
+		}
+		return nil, catalogerror.NewObjectNotFound("trigger %s does not exist", name)
+	}
+	if err := cm.LockTableByID(p.tx, existing.TableID, true); err != nil {
+		return nil, err
+	}
+
+	if err := cm.DropTrigger(p.tx, name); err != nil {
+		return nil, fmt.Errorf("failed to drop trigger: %w", err)
+	}
+	return result.NewDDLResult(true, fmt.Sprintf("Trigger %s dropped successfully", name)), nil
+}
@@ -0,0 +1,274 @@
+package dml
+
+import (
+	"fmt"
+	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/datagen"
+	"storemy/pkg/foreign"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/metadata"
+	"storemy/pkg/planner/internal/result"
+	"storemy/pkg/primitives"
+	"storemy/pkg/registry"
+	"storemy/pkg/storage/heap"
+	"storemy/pkg/trigger"
+	"storemy/pkg/tuple"
+	"strconv"
+)
+
+// CopyPlan represents an execution plan for a COPY statement. It reads the
+// rows of a CSV or JSONL file with the foreign table readers, or generates
+// sample rows for GENERATE_SERIES, and inserts them into a stored table.
+//
+// Loading into an empty, unaudited table with no indexes, triggers, PRIMARY
+// KEY or UNIQUE constraints is a bulk load: the rows are written to new pages
+// without logging them, and only the start and end of the load reach the
+// WAL. Any other table gets one logged insert per row, as with INSERT.
+//
+// Example:
+//
+//	COPY users FROM '/data/users.csv' OPTIONS (header 'true');
+//	COPY users FROM GENERATE_SERIES(1, 1000000) OPTIONS (seed '42');
+type CopyPlan struct {
+	statement *statements.CopyStatement
+	ctx       *registry.DatabaseContext
+	tx        *transaction.TransactionContext
+}
+
+// NewCopyPlan creates a new CopyPlan instance.
+func NewCopyPlan(stmt *statements.CopyStatement, tx *transaction.TransactionContext, ctx *registry.DatabaseContext) *CopyPlan {
+	return &CopyPlan{
+		statement: stmt,
+		ctx:       ctx,
+		tx:        tx,
+	}
+}
+
+// Execute performs the COPY operation.
+//
+// The execution flow:
+//  1. Resolves table metadata from the catalog
+//  2. Opens the file, reading it with the table's columns
+//  3. Bulk loads the rows if the table allows it, inserts them one by one otherwise
+//  4. Returns the number of rows copied, each validated against the table's constraints
+func (p *CopyPlan) Execute() (result.Result, error) {
+	md, err := metadata.ResolveTableMetadata(p.statement.TableName, p.tx, p.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := p.ctx.CatalogManager()
+	autoIncInfo, err := cm.GetAutoIncrementColumn(p.tx, md.TableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auto-increment info: %v", err)
+	}
+	if autoIncInfo != nil {
+		return nil, fmt.Errorf("cannot COPY into table %s: it has auto-increment column %s", p.statement.TableName, autoIncInfo.ColumnName)
+	}
+
+	reader, err := p.openSource(md.TupleDesc)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	dbFile, err := cm.GetTableFile(md.TableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table file: %v", err)
+	}
+	heapFile, ok := dbFile.(*heap.HeapFile)
+	if !ok {
+		return nil, fmt.Errorf("table %s is not stored in a heap file", p.statement.TableName)
+	}
+
+	tableMeta, err := cm.GetTableMetadataByID(p.tx, md.TableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table metadata: %v", err)
+	}
+	schema, err := cm.GetTableSchema(p.tx, md.TableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table schema: %v", err)
+	}
+
+	indexSearcher := p.ctx.IndexManager().NewIndexSearcher(p.ctx.IndexManager())
+	validator := cm.GetConstraintValidator(indexSearcher)
+
+	validate := func(t *tuple.Tuple) error {
+		return validator.ValidateInsert(p.tx, md.TableID, tableMeta.TableName, t, schema)
+	}
+
+	// next returns the next row of the file with the table's schema, or nil
+	// at the end of the file
+	next := func() (*tuple.Tuple, error) {
+		row, err := reader.Next()
+		if err != nil || row == nil {
+			return nil, err
+		}
+
+		newTuple := tuple.NewTuple(md.TupleDesc)
+		var i primitives.ColumnID
+		for i = 0; i < md.TupleDesc.NumFields(); i++ {
+			field, err := row.GetField(i)
+			if err != nil {
+				return nil, err
+			}
+			if err := newTuple.SetField(i, field); err != nil {
+				return nil, fmt.Errorf("failed to set field: %v", err)
+			}
+		}
+		return newTuple, nil
+	}
+
+	triggers, err := loadRowTriggers(p.ctx, p.tx, md.TableID, tableMeta.TableName, trigger.Insert)
+	if err != nil {
+		return nil, err
+	}
+
+	bulk, err := p.canBulkLoad(md.TableID, heapFile, triggers)
+	if err != nil {
+		return nil, err
+	}
+
+	var copiedCount int
+	if bulk {
+		copiedCount, err = p.ctx.TupleManager().BulkInsert(p.tx, heapFile, func() (*tuple.Tuple, error) {
+			newTuple, err := next()
+			if err != nil || newTuple == nil {
+				return nil, err
+			}
+			return newTuple, validate(newTuple)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to bulk load table %s: %w", p.statement.TableName, err)
+		}
+	} else {
+		copiedCount, err = p.insertRows(heapFile, next, validate, triggers)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.ctx.RecordModifications(md.TableID, p.statement.TableName, copiedCount)
+	triggers.recordAuditRows()
+
+	return &result.DMLResult{
+		RowsAffected: copiedCount,
+		Message:      fmt.Sprintf("%d row(s) copied", copiedCount),
+	}, nil
+}
+
+// openSource opens the file to copy as a foreign table with the columns of
+// td, or the generator of the series' rows. Relative paths are resolved
+// against the data directory.
+func (p *CopyPlan) openSource(td *tuple.TupleDescription) (foreign.Reader, error) {
+	if series := p.statement.Series; series != nil {
+		seed := int64(datagen.DefaultSeed)
+		if value, ok := p.statement.Options["SEED"]; ok {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid COPY source: SEED must be an integer, got %q", value)
+			}
+			seed = parsed
+		}
+
+		gen, err := datagen.New(td, *series, seed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid COPY source: %w", err)
+		}
+		return gen.Reader(*series), nil
+	}
+
+	columns := make([]foreign.Column, td.NumFields())
+	for i := range columns {
+		name, _ := td.GetFieldName(primitives.ColumnID(i))
+		columns[i] = foreign.Column{Name: name, Type: td.Types[i]}
+	}
+
+	options := map[string]string{foreign.OptionFormat: string(foreign.FormatCSV)}
+	for name, value := range p.statement.Options {
+		options[name] = value
+	}
+	options[foreign.OptionLocation] = p.statement.Path
+
+	source, err := foreign.NewTable(p.statement.TableName, columns, options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid COPY source: %w", err)
+	}
+	return source.Open(p.ctx.DataDir())
+}
+
+// canBulkLoad reports whether the rows can be bulk loaded into the table.
+// Only empty tables qualify, since undoing a load zeroes every page it
+// added; nor can the table have indexes, triggers or auditing, which a bulk
+// load does not maintain, or PRIMARY KEY or UNIQUE constraints, which could not see
+// the rows loaded before.
+func (p *CopyPlan) canBulkLoad(tableID primitives.FileID, heapFile *heap.HeapFile, triggers *rowTriggers) (bool, error) {
+	if triggers != nil {
+		return false, nil
+	}
+
+	numPages, err := heapFile.NumPages()
+	if err != nil {
+		return false, fmt.Errorf("failed to get number of pages: %v", err)
+	}
+	if numPages != 0 {
+		return false, nil
+	}
+
+	cm := p.ctx.CatalogManager()
+	indexes, err := cm.NewIndexOps(p.tx).GetIndexesByTable(tableID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get indexes: %v", err)
+	}
+	if len(indexes) != 0 {
+		return false, nil
+	}
+
+	constraints, err := cm.GetConstraintsForTable(p.tx, tableID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get constraints: %v", err)
+	}
+	for _, c := range constraints {
+		if c.ConstraintType == catalogmanager.ConstraintTypePrimaryKey || c.ConstraintType == catalogmanager.ConstraintTypeUnique {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// insertRows inserts the rows returned by next one by one, firing the
+// table's INSERT triggers and validating each row once its BEFORE triggers
+// have run.
+func (p *CopyPlan) insertRows(heapFile *heap.HeapFile, next func() (*tuple.Tuple, error), validate func(*tuple.Tuple) error, triggers *rowTriggers) (int, error) {
+	insertedCount := 0
+	for {
+		newTuple, err := next()
+		if err != nil {
+			return 0, err
+		}
+		if newTuple == nil {
+			return insertedCount, nil
+		}
+
+		skip, err := triggers.before(nil, newTuple)
+		if err != nil {
+			return 0, err
+		}
+		if skip {
+			continue
+		}
+
+		if err := validate(newTuple); err != nil {
+			return 0, err
+		}
+
+		if err := p.ctx.TupleManager().InsertTuple(p.tx, heapFile, newTuple); err != nil {
+			return 0, fmt.Errorf("failed to insert tuple: %w", err)
+		}
+
+		if err := triggers.after(nil, newTuple); err != nil {
+			return 0, err
+		}
+		insertedCount++
+	}
+}
@@ -0,0 +1,177 @@
+package dml
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"storemy/pkg/config"
+	"storemy/pkg/execution/setops"
+	"storemy/pkg/iterator"
+	"storemy/pkg/plan"
+	"storemy/pkg/planner/internal/metadata"
+	"storemy/pkg/planner/internal/scan"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// cteTable holds the rows of a query of the WITH clause, which the FROM items
+// naming it read instead of a table.
+type cteTable struct {
+	desc *tuple.TupleDescription
+	rows []*tuple.Tuple
+}
+
+// scanCTE returns a scan over the rows of the WITH query name, filtered by
+// whereClause if it is not nil, or false if the query has no WITH query of
+// that name. A WITH query hides a table of the same name.
+func (p *SelectPlan) scanCTE(name string, whereClause *plan.FilterNode) (iterator.DbIterator, bool, error) {
+	table, ok := p.ctes[name]
+	if !ok {
+		return nil, false, nil
+	}
+	scanOp, err := scan.BuildRowScan(table.desc, table.rows, whereClause)
+	return scanOp, true, err
+}
+
+// withCTE returns a copy of p in which name reads table.
+func (p *SelectPlan) withCTE(name string, table *cteTable) *SelectPlan {
+	child := *p
+	child.ctes = maps.Clone(p.ctes)
+	if child.ctes == nil {
+		child.ctes = make(map[string]*cteTable)
+	}
+	child.ctes[name] = table
+	return &child
+}
+
+// evaluateCTEs evaluates the queries of the WITH clause, in order, so that
+// each can read those before it and the statement can read all of them.
+// The subqueries of the statement inherit them (see createPlanIter).
+func (p *SelectPlan) evaluateCTEs() error {
+	for _, cte := range p.statement.Plan.CTEs() {
+		table, err := p.evaluateCTE(cte)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate WITH query %s: %w", cte.Name, err)
+		}
+		p.ctes = p.withCTE(cte.Name, table).ctes
+	}
+	return nil
+}
+
+// evaluateCTE runs a query of the WITH clause to completion and returns its
+// rows, under the column names the WITH clause gives it.
+func (p *SelectPlan) evaluateCTE(cte *plan.CTE) (*cteTable, error) {
+	var op iterator.DbIterator
+	var err error
+	if cte.Recursive {
+		op, err = p.buildRecursiveUnion(cte)
+	} else {
+		op, err = p.createQueryIter(cte.Query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := metadata.MaterializeTuples(op, p.tx.MemoryTracker())
+	if errors.Is(err, setops.ErrRecursionLimit) {
+		return nil, fmt.Errorf("%w (raise max_recursive_iterations if the query does terminate)", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := cteTupleDesc(cte, op.GetTupleDesc())
+	if err != nil {
+		return nil, err
+	}
+	rows, err = relabelTuples(rows, desc)
+	if err != nil {
+		return nil, err
+	}
+	return &cteTable{desc: desc, rows: rows}, nil
+}
+
+// buildRecursiveUnion builds the operator evaluating a recursive query: its
+// recursive term is rebuilt for every iteration with the query's name bound
+// to the rows of the previous one.
+func (p *SelectPlan) buildRecursiveUnion(cte *plan.CTE) (iterator.DbIterator, error) {
+	anchor, err := p.createQueryIter(cte.Anchor())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build anchor: %w", err)
+	}
+	desc, err := cteTupleDesc(cte, anchor.GetTupleDesc())
+	if err != nil {
+		return nil, err
+	}
+
+	step := func(working []*tuple.Tuple) (iterator.DbIterator, error) {
+		rows, err := relabelTuples(working, desc)
+		if err != nil {
+			return nil, err
+		}
+		child := p.withCTE(cte.Name, &cteTable{desc: desc, rows: rows})
+		return child.createPlanIter(cte.RecursiveTerm())
+	}
+
+	op, err := setops.NewRecursiveUnion(anchor, step, cte.Query.SetOpAll(), p.maxRecursiveIterations())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recursive union operator: %w", err)
+	}
+	op.SetMemoryTracker(p.tx.MemoryTracker())
+	return p.traceOperator("Recursive Union "+cte.Name, op, anchor), nil
+}
+
+// maxRecursiveIterations returns the max_recursive_iterations setting.
+func (p *SelectPlan) maxRecursiveIterations() int64 {
+	if store := p.ctx.Settings(); store != nil {
+		return store.Settings().MaxRecursiveIterations
+	}
+	return config.DefaultMaxRecursiveIterations
+}
+
+// cteTupleDesc returns the schema of a WITH query whose rows have schema td:
+// its columns are named by the WITH clause, or else after the query's own.
+func cteTupleDesc(cte *plan.CTE, td *tuple.TupleDescription) (*tuple.TupleDescription, error) {
+	n := td.NumFields()
+	if len(cte.Columns) > 0 && len(cte.Columns) != int(n) {
+		return nil, fmt.Errorf("WITH query %s has %d columns but %d names were given", cte.Name, n, len(cte.Columns))
+	}
+
+	fieldTypes := make([]types.Type, n)
+	names := make([]string, n)
+	for i := range n {
+		fieldTypes[i] = td.Types[i]
+		if len(cte.Columns) > 0 {
+			names[i] = cte.Columns[i]
+			continue
+		}
+		name, err := td.GetFieldName(i)
+		if err != nil {
+			return nil, err
+		}
+		names[i] = extractFieldName(name)
+	}
+	return tuple.NewTupleDesc(fieldTypes, names)
+}
+
+// relabelTuples returns rows under the schema desc, which has the same types.
+func relabelTuples(rows []*tuple.Tuple, desc *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+	relabeled := make([]*tuple.Tuple, 0, len(rows))
+	for _, row := range rows {
+		t := tuple.NewTuple(desc)
+		for i := range desc.NumFields() {
+			field, err := row.GetField(i)
+			if err != nil {
+				return nil, err
+			}
+			if field == nil {
+				continue // NULL
+			}
+			if err := t.SetField(i, field); err != nil {
+				return nil, err
+			}
+		}
+		relabeled = append(relabeled, t)
+	}
+	return relabeled, nil
+}
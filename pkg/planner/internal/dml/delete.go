@@ -9,6 +9,7 @@ import (
 	"storemy/pkg/planner/internal/scan"
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
+	"storemy/pkg/trigger"
 	"storemy/pkg/tuple"
 )
 
@@ -17,7 +18,8 @@ import (
 //
 // The execution follows a two-phase approach:
 // 1. Query Phase: Identifies all tuples matching the WHERE clause (or all tuples if no WHERE clause)
-// 2. Delete Phase: Removes the identified tuples from the table
+// 2. Delete Phase: Removes the identified tuples from the table, firing BEFORE and
+// AFTER DELETE triggers around each row and auditing it if the table is audited
 //
 // This approach ensures consistency by determining the full set of tuples to delete
 // before performing any modifications.
@@ -54,7 +56,7 @@ func NewDeletePlan(
 // 4. Delete the collected tuples from the table
 //
 // Returns:
-//   - DMLResult containing the number of rows deleted
+//   - DMLResult containing the number of rows deleted, excluding rows skipped by a BEFORE trigger
 //   - error if any step fails (table not found, invalid WHERE clause, deletion failure, etc.)
 func (p *DeletePlan) Execute() (result.Result, error) {
 	tableID, err := metadata.ResolveTableID(p.statement.TableName, p.tx, p.ctx)
@@ -67,19 +69,20 @@ func (p *DeletePlan) Execute() (result.Result, error) {
 		return nil, err
 	}
 
-	tuplesToDelete, err := metadata.CollectAllTuples(query)
+	tuplesToDelete, err := metadata.MaterializeTuples(query, p.tx.MemoryTracker())
 	if err != nil {
 		return nil, err
 	}
 
-	err = p.deleteTuples(tuplesToDelete, tableID)
+	deleted, err := p.deleteTuples(tuplesToDelete, tableID)
 	if err != nil {
 		return nil, err
 	}
+	p.ctx.RecordModifications(tableID, p.statement.TableName, deleted)
 
 	return &result.DMLResult{
-		RowsAffected: len(tuplesToDelete),
-		Message:      fmt.Sprintf("%d row(s) deleted", len(tuplesToDelete)),
+		RowsAffected: deleted,
+		Message:      fmt.Sprintf("%d row(s) deleted", deleted),
 	}, nil
 }
 
@@ -91,19 +94,40 @@ func (p *DeletePlan) Execute() (result.Result, error) {
 //   - tableID: ID of the table from which to delete tuples
 //
 // Returns:
+//   - the number of tuples deleted
 //   - error if tuple deletion fails, including the index of the failed tuple
-func (p *DeletePlan) deleteTuples(ts []*tuple.Tuple, tableID primitives.FileID) error {
+func (p *DeletePlan) deleteTuples(ts []*tuple.Tuple, tableID primitives.FileID) (int, error) {
 	ctm := p.ctx.CatalogManager()
 	tm := p.ctx.TupleManager()
 	dbFile, err := ctm.GetTableFile(tableID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	triggers, err := loadRowTriggers(p.ctx, p.tx, tableID, p.statement.TableName, trigger.Delete)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
 	for i, t := range ts {
+		skip, err := triggers.before(t, nil)
+		if err != nil {
+			return 0, err
+		}
+		if skip {
+			continue
+		}
+
 		if err := tm.DeleteTuple(p.tx, dbFile, t); err != nil {
-			return fmt.Errorf("failed to delete tuple %d: %v", i+1, err)
+			return 0, fmt.Errorf("failed to delete tuple %d: %v", i+1, err)
+		}
+
+		if err := triggers.after(t, nil); err != nil {
+			return 0, err
 		}
+		deleted++
 	}
-	return nil
+	triggers.recordAuditRows()
+	return deleted, nil
 }
@@ -11,6 +11,7 @@ import (
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
 	"storemy/pkg/types"
+	"strings"
 	"testing"
 )
 
@@ -272,7 +273,7 @@ func TestDeletePlan_Execute_Error_TableNotFound(t *testing.T) {
 	}
 
 	expectedError := "table nonexistent_table not found"
-	if err.Error() != expectedError {
+	if !strings.Contains(err.Error(), expectedError) {
 		t.Errorf("Expected error %q, got %q", expectedError, err.Error())
 	}
 }
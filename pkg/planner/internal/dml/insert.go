@@ -10,6 +10,7 @@ import (
 	"storemy/pkg/planner/internal/result"
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
+	"storemy/pkg/trigger"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
 )
@@ -25,6 +26,8 @@ type columnIndexMapping = []primitives.ColumnID
 //   - Partial column inserts: INSERT INTO table (col1, col2) VALUES (...)
 //   - Auto-increment columns: Automatically generates values for auto-increment fields
 //   - Batch inserts: Multiple value sets in a single INSERT statement
+//   - Triggers: BEFORE and AFTER INSERT triggers fire for every row
+//   - Auditing: each row is recorded in the audit table of an audited table
 //
 // Example usage:
 //
@@ -96,6 +99,7 @@ func (p *InsertPlan) Execute() (result.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	p.ctx.RecordModifications(md.TableID, p.statement.TableName, insertedCount)
 
 	return &result.DMLResult{
 		RowsAffected: insertedCount,
@@ -148,8 +152,10 @@ func (p *InsertPlan) buildColumnIndexMapping(td *tuple.TupleDescription) (column
 //  1. Validates that the number of values matches expectations
 //  2. Creates a tuple with proper field placement
 //  3. Handles auto-increment column generation
-//  4. Inserts the tuple through the tuple manager
-//  5. Updates the auto-increment counter if applicable
+//  4. Fires BEFORE INSERT triggers, which may change or skip the row
+//  5. Validates constraints and inserts the tuple through the tuple manager
+//  6. Updates the auto-increment counter if applicable
+//  7. Fires AFTER INSERT triggers and writes the audit row
 //
 // Parameters:
 //   - tableID: The unique identifier of the target table
@@ -183,6 +189,11 @@ func (p *InsertPlan) insertTuples(tableID primitives.FileID, tupleDesc *tuple.Tu
 	indexSearcher := p.ctx.IndexManager().NewIndexSearcher(p.ctx.IndexManager())
 	validator := cm.GetConstraintValidator(indexSearcher)
 
+	triggers, err := loadRowTriggers(p.ctx, p.tx, tableID, tableMeta.TableName, trigger.Insert)
+	if err != nil {
+		return 0, err
+	}
+
 	insertedCount := 0
 	for _, values := range p.statement.Values {
 		if err := validateValueCount(values, tupleDesc, fieldMapping, autoIncInfo); err != nil {
@@ -194,13 +205,21 @@ func (p *InsertPlan) insertTuples(tableID primitives.FileID, tupleDesc *tuple.Tu
 			return 0, err
 		}
 
+		skip, err := triggers.before(nil, newTuple)
+		if err != nil {
+			return 0, err
+		}
+		if skip {
+			continue
+		}
+
 		// Validate constraints before insertion
 		if err := validator.ValidateInsert(p.tx, tableID, tableMeta.TableName, newTuple, schema); err != nil {
 			return 0, err
 		}
 
 		if err := p.ctx.TupleManager().InsertTuple(p.tx, dbFile, newTuple); err != nil {
-			return 0, fmt.Errorf("failed to insert tuple: %v", err)
+			return 0, fmt.Errorf("failed to insert tuple: %w", err)
 		}
 
 		// Update auto-increment counter if column is auto-incremented
@@ -212,8 +231,13 @@ func (p *InsertPlan) insertTuples(tableID primitives.FileID, tupleDesc *tuple.Tu
 			autoIncInfo.NextValue = newValue
 		}
 
+		if err := triggers.after(nil, newTuple); err != nil {
+			return 0, err
+		}
+
 		insertedCount++
 	}
+	triggers.recordAuditRows()
 
 	return insertedCount, nil
 }
@@ -11,6 +11,7 @@ import (
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
 	"storemy/pkg/types"
+	"strings"
 	"testing"
 )
 
@@ -243,7 +244,7 @@ func TestInsertPlan_Execute_Error_TableNotFound(t *testing.T) {
 	}
 
 	expectedError := "table nonexistent_table not found"
-	if err.Error() != expectedError {
+	if !strings.Contains(err.Error(), expectedError) {
 		t.Errorf("Expected error %q, got %q", expectedError, err.Error())
 	}
 }
@@ -0,0 +1,166 @@
+package dml
+
+import (
+	"fmt"
+	"slices"
+	"storemy/pkg/execution/join"
+	"storemy/pkg/iterator"
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"strings"
+)
+
+// outerRef is a reference from a LATERAL subquery to a column of the tables
+// to its left.
+type outerRef struct {
+	ref    string              // The reference as written, table.column
+	column primitives.ColumnID // The column's position in the left input
+}
+
+// usesNestedLoop reports whether a join is executed by a nested loop that
+// rebuilds its right side for every left row: CROSS JOINs, which have no
+// condition to hash or sort on, and LATERAL subqueries, whose result depends
+// on the left row.
+func usesNestedLoop(joinNode *plan.JoinNode) bool {
+	return joinNode.Lateral || joinNode.JoinType == "cross"
+}
+
+// buildNestedLoopJoin joins left, the result of the FROM items before the
+// i-th join, with the right side of that join, rebuilt for each left row.
+// The references of a LATERAL subquery are bound to the left row's values;
+// a NULL value matches nothing, so the subquery then returns no rows.
+func (p *SelectPlan) buildNestedLoopJoin(joinNode *plan.JoinNode, i int, left iterator.DbIterator) (iterator.DbIterator, error) {
+	var right join.RightInput
+	var rightDesc *tuple.TupleDescription
+
+	if sub := joinNode.Subquery; sub != nil {
+		refs, err := p.resolveOuterRefs(joinNode, i, left.GetTupleDesc())
+		if err != nil {
+			return nil, err
+		}
+
+		// The references' filters do not change the output columns
+		unbound, err := p.createPlanIter(sub.WithRefs(nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build subquery %s: %w", joinNode.RightTable.Alias, err)
+		}
+		rightDesc = unbound.GetTupleDesc()
+
+		right = func(row *tuple.Tuple) (iterator.DbIterator, error) {
+			values := make(map[string]string, len(refs))
+			for _, r := range refs {
+				field, err := row.GetField(r.column)
+				if err != nil {
+					return nil, err
+				}
+				if field == nil {
+					return nil, nil
+				}
+				values[r.ref] = field.String()
+			}
+			return p.createPlanIter(sub.WithRefs(values))
+		}
+	} else {
+		scanOp, err := p.buildJoinRightSide(joinNode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build right side of join: %w", err)
+		}
+		rightDesc = scanOp.GetTupleDesc()
+
+		right = func(*tuple.Tuple) (iterator.DbIterator, error) {
+			return p.buildJoinRightSide(joinNode)
+		}
+	}
+
+	joinOp, err := join.NewLateralJoin(left, rightDesc, right)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create join operator: %w", err)
+	}
+	if err := joinOp.SetJoinType(executionJoinType(joinNode)); err != nil {
+		return nil, err
+	}
+
+	if joinNode.JoinType != "cross" {
+		li, err := findFieldIndex(joinNode.LeftField, left.GetTupleDesc())
+		if err != nil {
+			return nil, fmt.Errorf("failed to build join predicate: %w", err)
+		}
+		ri, err := findFieldIndex(joinNode.RightField, rightDesc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build join predicate: %w", err)
+		}
+		if err := joinOp.SetCondition(li, ri, joinNode.Predicate); err != nil {
+			return nil, err
+		}
+	}
+
+	return joinOp, nil
+}
+
+// buildSubquery builds the right side of the i-th join, a subquery that is
+// not LATERAL, once; left is the result of the FROM items before it.
+func (p *SelectPlan) buildSubquery(joinNode *plan.JoinNode, i int, left iterator.DbIterator) (iterator.DbIterator, error) {
+	if _, err := p.resolveOuterRefs(joinNode, i, left.GetTupleDesc()); err != nil {
+		return nil, err
+	}
+	return p.createPlanIter(joinNode.Subquery)
+}
+
+// resolveOuterRefs validates the column references in the WHERE clause of
+// the subquery of the i-th join and returns those naming a table to its
+// left, resolved against left, the description of that table's rows. Only a
+// LATERAL subquery may refer to such tables; a reference to a table neither
+// in the subquery nor to its left is an error.
+func (p *SelectPlan) resolveOuterRefs(joinNode *plan.JoinNode, i int, left *tuple.TupleDescription) ([]outerRef, error) {
+	sub := joinNode.Subquery
+	name := joinNode.RightTable.Alias
+	inner := fromAliases(sub, len(sub.Joins()))
+	outer := fromAliases(p.statement.Plan, i)
+
+	var refs []outerRef
+	for _, filter := range sub.Filters() {
+		if filter.Ref == "" {
+			continue
+		}
+
+		table, column, _ := strings.Cut(filter.Ref, ".")
+		if slices.Contains(inner, table) {
+			continue
+		}
+		if !slices.Contains(outer, table) {
+			return nil, fmt.Errorf("subquery %s refers to %s, but there is no table %s in or to the left of it", name, filter.Ref, table)
+		}
+		if !joinNode.Lateral {
+			return nil, fmt.Errorf("subquery %s refers to %s, a table to its left, which requires LATERAL", name, table)
+		}
+
+		idx, err := findFieldIndex(column, left)
+		if err != nil {
+			return nil, fmt.Errorf("subquery %s refers to unknown column %s: %w", name, filter.Ref, err)
+		}
+		refs = append(refs, outerRef{ref: filter.Ref, column: idx})
+	}
+	return refs, nil
+}
+
+// fromAliases returns the names, aliases where given, of the tables listed in
+// pl's FROM clause and of the right sides of its first n joins.
+func fromAliases(pl *plan.SelectPlan, n int) []string {
+	var aliases []string
+	for _, table := range pl.Tables() {
+		aliases = append(aliases, scanAlias(table))
+	}
+	for _, joinNode := range pl.Joins()[:n] {
+		aliases = append(aliases, scanAlias(joinNode.RightTable))
+	}
+	return aliases
+}
+
+// scanAlias returns the name a FROM item is referred to by.
+func scanAlias(table *plan.ScanNode) string {
+	if table.Alias != "" {
+		return table.Alias
+	}
+	return table.TableName
+}
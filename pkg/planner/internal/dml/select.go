@@ -5,7 +5,9 @@ import (
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/execution/aggregation"
 	"storemy/pkg/execution/join"
+	"storemy/pkg/execution/membudget"
 	"storemy/pkg/execution/query"
+	"storemy/pkg/execution/scanner"
 	"storemy/pkg/execution/setops"
 	"storemy/pkg/iterator"
 	"storemy/pkg/parser/statements"
@@ -15,8 +17,10 @@ import (
 	"storemy/pkg/planner/internal/scan"
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
+	"storemy/pkg/tracing"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
+	"strings"
 )
 
 // SelectPlan orchestrates the execution of a SELECT statement.
@@ -26,6 +30,7 @@ type SelectPlan struct {
 	ctx       *registry.DatabaseContext
 	tx        *transaction.TransactionContext
 	statement *statements.SelectStatement
+	ctes      map[string]*cteTable // Rows of the WITH queries, by name
 }
 
 // NewSelectPlan creates a new SELECT query execution plan.
@@ -45,12 +50,14 @@ func NewSelectPlan(stmt *statements.SelectStatement, tx *transaction.Transaction
 //  3. Otherwise: Build base scan with WHERE filter
 //  4. Apply JOINs (if any)
 //  5. Apply aggregation/GROUP BY (if any)
-//  6. Apply projection/SELECT list (if no aggregation)
-//  7. Apply DISTINCT (if specified and no aggregation)
-//  8. Apply ORDER BY (if specified)
-//  9. Apply LIMIT/OFFSET (if specified)
+//  6. Apply HAVING (if any)
+//  7. Apply DISTINCT ON with its ORDER BY (if specified)
+//  8. Apply projection/SELECT list (if no aggregation)
+//  9. Apply DISTINCT (if specified and no aggregation)
 //
-// 10. Materialize all results via collectAllTuples()
+// 10. Apply ORDER BY (if specified)
+// 11. Apply LIMIT/OFFSET (if specified)
+// 12. Materialize all results via collectAllTuples()
 func (p *SelectPlan) Execute() (result.Result, error) {
 	if p.statement.Plan.IsSetOperation() {
 		return p.executeSetOperation()
@@ -60,8 +67,9 @@ func (p *SelectPlan) Execute() (result.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	tracing.AttachOperator(p.tx.Trace(), iter)
 
-	results, err := metadata.CollectAllTuples(iter)
+	results, err := metadata.MaterializeTuples(iter, p.tx.MemoryTracker())
 	if err != nil {
 		return nil, err
 	}
@@ -77,57 +85,120 @@ func (p *SelectPlan) Execute() (result.Result, error) {
 //
 // Execution flow (same as Execute but returns iterator instead of materialized results):
 //  1. Build base scan with WHERE filter
-//  2. Apply JOINs (if any)
+//  2. Apply JOINs (if any), then the WHERE filter if an outer join
+//     prevents filtering the base scan, then [NOT] EXISTS and [NOT] IN
+//     conditions as semi-joins and anti-joins
 //  3. Apply aggregation/GROUP BY (if any)
-//  4. Apply projection/SELECT list (if no aggregation)
-//  5. Apply DISTINCT (if specified and no aggregation)
-//  6. Apply ORDER BY (if specified)
-//  7. Apply LIMIT/OFFSET (if specified)
+//  4. Apply HAVING (if any)
+//  5. Apply DISTINCT ON with its ORDER BY (if specified)
+//  6. Apply projection/SELECT list (if no aggregation)
+//  7. Apply DISTINCT (if specified and no aggregation)
+//  8. Apply ORDER BY (if specified)
+//  9. Apply LIMIT/OFFSET (if specified)
 //
 // Returns iterator.DbIterator ready to produce tuples on demand.
 func (p *SelectPlan) ExecuteIterator() (iterator.DbIterator, error) {
+	if err := p.evaluateCTEs(); err != nil {
+		return nil, err
+	}
+
 	currentOp, err := p.buildScanOperator()
 	if err != nil {
 		return nil, err
 	}
+	p.pushDownProjection(currentOp)
+	currentOp = p.traceOperator("Scan "+p.statement.Plan.Tables()[0].TableName, currentOp)
 
 	currentOp, err = p.applyJoinsIfNeeded(currentOp)
 	if err != nil {
 		return nil, err
 	}
 
+	input := currentOp
+	if p.filterAfterJoins() {
+		currentOp, err = scan.BuildFilter(currentOp, p.whereFilter())
+		if err != nil {
+			return nil, err
+		}
+		currentOp = p.traceStage("Filter", input, currentOp)
+	}
+
+	currentOp, err = p.applySubqueryFiltersIfNeeded(currentOp)
+	if err != nil {
+		return nil, err
+	}
+
+	input = currentOp
 	currentOp, err = p.applyAggregationIfNeeded(currentOp)
 	if err != nil {
 		return nil, err
 	}
+	currentOp = p.traceStage("Aggregate", input, currentOp)
+
+	input = currentOp
+	currentOp, err = p.applyHavingIfNeeded(currentOp)
+	if err != nil {
+		return nil, err
+	}
+	currentOp = p.traceStage("Having", input, currentOp)
+
+	input = currentOp
+	currentOp, err = p.applyDistinctOnIfNeeded(currentOp)
+	if err != nil {
+		return nil, err
+	}
+	currentOp = p.traceStage("DistinctOn", input, currentOp)
 
 	if !p.statement.Plan.HasAgg() {
+		input = currentOp
 		currentOp, err = p.applyProjectionIfNeeded(currentOp)
 		if err != nil {
 			return nil, err
 		}
+		currentOp = p.traceStage("Project", input, currentOp)
 	}
 
-	if p.statement.Plan.IsDistinct() && !p.statement.Plan.HasAgg() {
+	if p.statement.Plan.IsDistinct() && !p.statement.Plan.HasAgg() && p.statement.Plan.DistinctOn() == nil {
+		input = currentOp
 		currentOp, err = p.applyDistinctIfNeeded(currentOp)
 		if err != nil {
 			return nil, err
 		}
+		currentOp = p.traceStage("Distinct", input, currentOp)
 	}
 
+	input = currentOp
 	currentOp, err = p.applySortIfNeeded(currentOp)
 	if err != nil {
 		return nil, err
 	}
+	currentOp = p.traceStage("Sort", input, currentOp)
 
+	input = currentOp
 	currentOp, err = p.applyLimitIfNeeded(currentOp)
 	if err != nil {
 		return nil, err
 	}
+	currentOp = p.traceStage("Limit", input, currentOp)
 
 	return currentOp, nil
 }
 
+// traceOperator records op as a span in the transaction's trace when the
+// query is traced in detail; spans of traced inputs become its children.
+func (p *SelectPlan) traceOperator(name string, op iterator.DbIterator, inputs ...iterator.DbIterator) iterator.DbIterator {
+	return tracing.TraceOperator(p.tx.Trace(), name, op, inputs...)
+}
+
+// traceStage traces the operator added by an optional pipeline stage.
+// Stages that were skipped return their input unchanged and are not traced.
+func (p *SelectPlan) traceStage(name string, input, output iterator.DbIterator) iterator.DbIterator {
+	if output == input {
+		return output
+	}
+	return p.traceOperator(name, output, input)
+}
+
 // buildScanOperator creates the base scan operator with optional WHERE filter.
 // This is the foundation of the query execution tree - all other operators build on this.
 //
@@ -137,6 +208,9 @@ func (p *SelectPlan) ExecuteIterator() (iterator.DbIterator, error) {
 //  3. Create SeqScan operator (acquires page locks via LockManager)
 //  4. Wrap in Filter operator if WHERE clause exists
 //
+// System views (SYS_*) are scanned from live engine state instead of a heap file,
+// and foreign tables are parsed from their external file.
+//
 // Returns iterator.DbIterator ready to produce tuples from base table.
 // Errors if table doesn't exist or scan creation fails.
 func (p *SelectPlan) buildScanOperator() (iterator.DbIterator, error) {
@@ -146,18 +220,34 @@ func (p *SelectPlan) buildScanOperator() (iterator.DbIterator, error) {
 	}
 
 	firstTable := tables[0]
-	metadata, err := metadata.ResolveTableMetadata(firstTable.TableName, p.tx, p.ctx)
+
+	filter := p.whereFilter()
+	if p.filterAfterJoins() {
+		filter = nil
+	}
+
+	if scanOp, ok, err := p.scanCTE(firstTable.TableName, filter); ok {
+		return scanOp, err
+	}
+
+	if view, ok := p.ctx.SystemViews().Lookup(firstTable.TableName); ok {
+		return scan.BuildViewScan(view, filter)
+	}
+
+	ft, err := metadata.ResolveForeignTable(firstTable.TableName, p.tx, p.ctx)
 	if err != nil {
 		return nil, err
 	}
+	if ft != nil {
+		return scan.BuildForeignScan(ft, p.ctx.DataDir(), filter)
+	}
 
-	var filter *plan.FilterNode
-	filters := p.statement.Plan.Filters()
-	if len(filters) > 0 {
-		filter = filters[0]
+	metadata, err := metadata.ResolveTableMetadata(firstTable.TableName, p.tx, p.ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	scanOp, err := scan.BuildScanWithFilter(p.tx, metadata.TableID, filter, p.ctx)
+	scanOp, err := p.buildTableScan(metadata.TableID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create table scan: %w", err)
 	}
@@ -165,6 +255,75 @@ func (p *SelectPlan) buildScanOperator() (iterator.DbIterator, error) {
 	return scanOp, nil
 }
 
+// buildTableScan scans a base table, as it was at the AS OF point if the
+// query has one. System views, foreign tables and WITH queries are always
+// read as they are now.
+func (p *SelectPlan) buildTableScan(tableID primitives.FileID, filter *plan.FilterNode) (iterator.DbIterator, error) {
+	if asOf := p.statement.Plan.AsOf(); asOf != nil {
+		return scan.BuildHistoryScan(p.tx, tableID, asOf, filter, p.ctx)
+	}
+	return scan.BuildScanWithFilter(p.tx, tableID, filter, p.ctx)
+}
+
+// whereFilter returns the WHERE condition, or nil if there is none.
+func (p *SelectPlan) whereFilter() *plan.FilterNode {
+	if filters := p.statement.Plan.Filters(); len(filters) > 0 {
+		return filters[0]
+	}
+	return nil
+}
+
+// filterAfterJoins reports whether the WHERE condition must be applied to
+// the joined rows rather than the scan of the first table. A RIGHT or FULL
+// join pads first-table columns with NULLs for unmatched rows, so filtering
+// the first table beforehand would bring the rows it removes back, padded.
+// A LEFT join is filtered afterwards too, since its WHERE may test the
+// columns it pads.
+func (p *SelectPlan) filterAfterJoins() bool {
+	if p.whereFilter() == nil {
+		return false
+	}
+	for _, joinNode := range p.statement.Plan.Joins() {
+		if executionJoinType(joinNode) != join.InnerJoin {
+			return true
+		}
+	}
+	return false
+}
+
+// pushDownProjection tells a sequential scan of the first table which of its
+// columns the SELECT list projects, so that a scan decoding rows from their
+// serialized form decodes just those (see scanner.SequentialScan.SetColumns).
+// It only does so when the projection sits right above the scan: joins,
+// [NOT] EXISTS and IN conditions, aggregation and DISTINCT ON read columns
+// of their own, and a WHERE condition the scan does not evaluate itself is
+// a Filter operator between them. Computed SELECT expressions keep every
+// column too.
+func (p *SelectPlan) pushDownProjection(scanOp iterator.DbIterator) {
+	seqScan, ok := scanOp.(*scanner.SequentialScan)
+	pl := p.statement.Plan
+	if !ok || pl.SelectAll() || pl.HasAgg() || len(pl.Joins()) > 0 || len(pl.SubqueryFilters()) > 0 || len(pl.DistinctOn()) > 0 {
+		return
+	}
+
+	fields := pl.SelectList()
+	if len(fields) == 0 {
+		return
+	}
+	columns := make([]primitives.ColumnID, 0, len(fields))
+	for _, field := range fields {
+		if field.Expr != nil {
+			return
+		}
+		idx, err := findFieldIndex(field.FieldName, seqScan.GetTupleDesc())
+		if err != nil {
+			return
+		}
+		columns = append(columns, idx)
+	}
+	seqScan.SetColumns(columns...)
+}
+
 // applyProjectionIfNeeded applies the SELECT clause projection if not SELECT *.
 // Skipped if query has aggregation (aggregation defines output schema instead).
 func (p *SelectPlan) applyProjectionIfNeeded(input iterator.DbIterator) (iterator.DbIterator, error) {
@@ -188,6 +347,12 @@ func (p *SelectPlan) applyProjectionIfNeeded(input iterator.DbIterator) (iterato
 //  2. Extract field type from input schema
 //  3. Create Project operator with field indices and types
 func buildProjection(input iterator.DbIterator, selectFields []*plan.SelectListNode) (iterator.DbIterator, error) {
+	for _, field := range selectFields {
+		if field.Expr != nil {
+			return buildExpressionProjection(input, selectFields)
+		}
+	}
+
 	fieldIndices := make([]primitives.ColumnID, 0, len(selectFields))
 	fieldTypes := make([]types.Type, 0, len(selectFields))
 	tupleDesc := input.GetTupleDesc()
@@ -211,6 +376,37 @@ func buildProjection(input iterator.DbIterator, selectFields []*plan.SelectListN
 	return pr, nil
 }
 
+// buildExpressionProjection constructs a Project operator for a SELECT list
+// that contains computed expressions such as CASE. Plain fields are projected
+// as column references, so every output field is computed the same way.
+func buildExpressionProjection(input iterator.DbIterator, selectFields []*plan.SelectListNode) (iterator.DbIterator, error) {
+	tupleDesc := input.GetTupleDesc()
+	exprs := make([]*query.Expression, 0, len(selectFields))
+	names := make([]string, 0, len(selectFields))
+
+	for _, field := range selectFields {
+		var expr plan.Expr = &plan.ColumnExpr{Name: field.FieldName}
+		if field.Expr != nil {
+			expr = field.Expr
+		}
+
+		bound, err := query.NewExpression(expr, tupleDesc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid select expression %s: %w", expr, err)
+		}
+
+		exprs = append(exprs, bound)
+		names = append(names, extractFieldName(field.FieldName))
+	}
+
+	pr, err := query.NewExpressionProject(exprs, names, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create projection: %v", err)
+	}
+
+	return pr, nil
+}
+
 // applyJoinsIfNeeded applies all JOIN operations to the input operator.
 // Builds a left-deep join tree where each join becomes the left input to the next join.
 //
@@ -222,6 +418,9 @@ func buildProjection(input iterator.DbIterator, selectFields []*plan.SelectListN
 //     c. Create JoinOperator wrapping left and right
 //     d. Current operator becomes this join (for next iteration)
 //
+// CROSS JOINs and LATERAL subqueries are nested loops instead, which build
+// their right side again for every left row (see buildNestedLoopJoin).
+//
 // Example query flow:
 //
 //	FROM users u JOIN orders o ON u.id = o.user_id JOIN products p ON o.product_id = p.id
@@ -233,11 +432,29 @@ func (p *SelectPlan) applyJoinsIfNeeded(input iterator.DbIterator) (iterator.DbI
 	}
 
 	currentOp := input
-	for _, joinNode := range joins {
-		rightOp, err := p.buildJoinRightSide(joinNode)
+	for i, joinNode := range joins {
+		if usesNestedLoop(joinNode) {
+			joinOp, err := p.buildNestedLoopJoin(joinNode, i, currentOp)
+			if err != nil {
+				return nil, err
+			}
+			currentOp = p.traceOperator("Nested Loop Join", joinOp, currentOp)
+			continue
+		}
+
+		var rightOp iterator.DbIterator
+		var err error
+		rightName := "Scan " + joinNode.RightTable.TableName
+		if joinNode.Subquery != nil {
+			rightOp, err = p.buildSubquery(joinNode, i, currentOp)
+			rightName = "Subquery " + joinNode.RightTable.Alias
+		} else {
+			rightOp, err = p.buildJoinRightSide(joinNode)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to build right side of join: %w", err)
 		}
+		rightOp = p.traceOperator(rightName, rightOp)
 
 		li, ri, predOp, err := p.buildJoinPredicateFields(joinNode, currentOp, rightOp)
 		if err != nil {
@@ -248,23 +465,55 @@ func (p *SelectPlan) applyJoinsIfNeeded(input iterator.DbIterator) (iterator.DbI
 		if err != nil {
 			return nil, fmt.Errorf("failed to create join operator: %w", err)
 		}
+		joinOp.SetMemoryTracker(p.tx.MemoryTracker())
+		joinOp.SetJoinType(executionJoinType(joinNode))
 
-		currentOp = joinOp
+		currentOp = p.traceOperator("Join", joinOp, currentOp, rightOp)
 	}
 
 	return currentOp, nil
 }
 
+// executionJoinType maps the parsed join type to the join operator's.
+func executionJoinType(joinNode *plan.JoinNode) join.JoinType {
+	switch joinNode.JoinType {
+	case "left":
+		return join.LeftOuterJoin
+	case "right":
+		return join.RightOuterJoin
+	case "full":
+		return join.FullOuterJoin
+	default:
+		return join.InnerJoin
+	}
+}
+
 // buildJoinRightSide creates a scan operator for the right side of a JOIN.
 // Each join's right side is a fresh scan of a table (no filter optimization currently).
 func (p *SelectPlan) buildJoinRightSide(joinNode *plan.JoinNode) (iterator.DbIterator, error) {
 	table := joinNode.RightTable
+	if scanOp, ok, err := p.scanCTE(table.TableName, nil); ok {
+		return scanOp, err
+	}
+
+	if view, ok := p.ctx.SystemViews().Lookup(table.TableName); ok {
+		return scan.BuildViewScan(view, nil)
+	}
+
+	ft, err := metadata.ResolveForeignTable(table.TableName, p.tx, p.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ft != nil {
+		return scan.BuildForeignScan(ft, p.ctx.DataDir(), nil)
+	}
+
 	md, err := metadata.ResolveTableMetadata(table.TableName, p.tx, p.ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	scanOp, err := scan.BuildScanWithFilter(p.tx, md.TableID, nil, p.ctx)
+	scanOp, err := p.buildTableScan(md.TableID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scan for table %s: %w", table.TableName, err)
 	}
@@ -302,9 +551,11 @@ func (p *SelectPlan) buildJoinPredicateFields(node *plan.JoinNode, l, r iterator
 //
 // Aggregation process:
 //  1. Identify aggregation field from SELECT clause (e.g., COUNT(ID))
-//  2. Identify GROUP BY field if present
-//  3. Create AggregateOperator that:
-//     - Groups tuples by GROUP BY field (or single group if no GROUP BY)
+//  2. Identify GROUP BY key if present
+//  3. If the key is a computed expression, project the aggregation field and
+//     the computed key so the aggregate can group by it
+//  4. Create AggregateOperator that:
+//     - Groups tuples by GROUP BY key (or single group if no GROUP BY)
 //     - Applies aggregate function to each group
 //     - Outputs one tuple per group
 //
@@ -319,17 +570,30 @@ func (p *SelectPlan) applyAggregationIfNeeded(input iterator.DbIterator) (iterat
 		return input, nil
 	}
 
+	groupKey := p.groupByKey(input.GetTupleDesc())
+	if err := p.checkProjectExprsGrouped(groupKey); err != nil {
+		return nil, err
+	}
+
 	aggFieldIndex, err := p.parseAggregationIndex(input.GetTupleDesc())
 	if err != nil {
 		return nil, err
 	}
 
 	groupIndex := aggregation.NoGrouping
-	if pl.GroupByField() != "" {
-		groupIndex, err = findFieldIndex(pl.GroupByField(), input.GetTupleDesc())
+	switch key := groupKey.(type) {
+	case nil:
+	case *plan.ColumnExpr:
+		groupIndex, err = findFieldIndex(key.Name, input.GetTupleDesc())
 		if err != nil {
 			return nil, fmt.Errorf("group by field %s not found: %w", p.statement.Plan.GroupByField(), err)
 		}
+	default:
+		input, err = buildGroupKeyProjection(input, aggFieldIndex, groupKey)
+		if err != nil {
+			return nil, err
+		}
+		aggFieldIndex, groupIndex = 0, 1
 	}
 
 	aggOp, err := aggregation.ParseAggregateOp(pl.AggOp())
@@ -337,12 +601,308 @@ func (p *SelectPlan) applyAggregationIfNeeded(input iterator.DbIterator) (iterat
 		return nil, err
 	}
 
-	aggOperator, err := aggregation.NewAggregateOperator(input, aggFieldIndex, groupIndex, aggOp)
+	sets, withGrouping := pl.GroupingSets(), hasGroupingCall(pl)
+	if sets == nil && !withGrouping {
+		aggOperator, err := aggregation.NewAggregateOperator(input, aggFieldIndex, groupIndex, aggOp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create aggregate operator: %w", err)
+		}
+		aggOperator.SetMemoryTracker(p.tx.MemoryTracker())
+		return aggOperator, nil
+	}
+
+	// A plain GROUP BY is a single grouping set
+	if sets == nil {
+		sets = []bool{groupKey != nil}
+	}
+	setsOperator, err := aggregation.NewGroupingSetsOperator(input, aggFieldIndex, groupIndex, aggOp, sets, withGrouping)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create aggregate operator: %w", err)
+		return nil, fmt.Errorf("failed to create grouping sets operator: %w", err)
+	}
+	setsOperator.SetMemoryTracker(p.tx.MemoryTracker())
+	return setsOperator, nil
+}
+
+// hasGroupingCall reports whether the SELECT list calls GROUPING, which adds
+// a GROUPING column to the aggregate output.
+func hasGroupingCall(pl *plan.SelectPlan) bool {
+	for _, field := range pl.SelectList() {
+		if _, ok := field.Expr.(*plan.GroupingExpr); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// groupByKey returns the GROUP BY key of the query as an expression, or nil if
+// the query is not grouped. A GROUP BY name is a column of td if one exists
+// and otherwise the alias of a computed expression in the SELECT list.
+func (p *SelectPlan) groupByKey(td *tuple.TupleDescription) plan.Expr {
+	pl := p.statement.Plan
+	if pl.GroupByExpr() != nil {
+		return pl.GroupByExpr()
+	}
+
+	if pl.GroupByField() == "" {
+		return nil
+	}
+	return resolveSelectAlias(pl, &plan.ColumnExpr{Name: pl.GroupByField()}, td)
+}
+
+// resolveSelectAlias returns the SELECT list expression that expr names by
+// its alias, for clauses evaluated before the projection. A column of td
+// takes precedence over an alias of the same name; any other expr is
+// returned unchanged.
+func resolveSelectAlias(pl *plan.SelectPlan, expr plan.Expr, td *tuple.TupleDescription) plan.Expr {
+	column, ok := expr.(*plan.ColumnExpr)
+	if !ok {
+		return expr
+	}
+
+	if _, err := findFieldIndex(column.Name, td); err != nil {
+		if aliased := selectListExpr(pl, column.Name); aliased != nil {
+			return aliased
+		}
 	}
+	return expr
+}
 
-	return aggOperator, nil
+// selectListExpr returns the computed SELECT list expression aliased as name,
+// or nil if there is none.
+func selectListExpr(pl *plan.SelectPlan, name string) plan.Expr {
+	for _, field := range pl.SelectList() {
+		if field.Expr != nil && field.FieldName == name {
+			return field.Expr
+		}
+	}
+	return nil
+}
+
+// checkProjectExprsGrouped verifies that every computed expression in the
+// SELECT list of an aggregate query is the GROUP BY key, since only the key
+// and the aggregate have a single value per group, or a GROUPING call on it.
+func (p *SelectPlan) checkProjectExprsGrouped(groupKey plan.Expr) error {
+	r := &havingResolver{plan: p.statement.Plan, groupKey: groupKey}
+	for _, field := range p.statement.Plan.SelectList() {
+		if field.Expr == nil {
+			continue
+		}
+		if grouping, ok := field.Expr.(*plan.GroupingExpr); ok {
+			if !r.refersToGroupKey(grouping.Arg) {
+				return fmt.Errorf("argument of %s must be the GROUP BY key", grouping)
+			}
+			continue
+		}
+		if groupKey == nil || field.Expr.String() != groupKey.String() {
+			return fmt.Errorf("expression %s must appear in GROUP BY when combined with aggregate functions", field.Expr)
+		}
+	}
+	return nil
+}
+
+// buildGroupKeyProjection projects the aggregation field and the computed
+// GROUP BY key, in that order, so that an AggregateOperator can group by an
+// expression the input does not contain.
+func buildGroupKeyProjection(input iterator.DbIterator, aggFieldIndex primitives.ColumnID, groupKey plan.Expr) (iterator.DbIterator, error) {
+	tupleDesc := input.GetTupleDesc()
+
+	key, err := query.NewExpression(groupKey, tupleDesc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group by expression %s: %w", groupKey, err)
+	}
+
+	aggName, err := tupleDesc.GetFieldName(aggFieldIndex)
+	if err != nil {
+		return nil, err
+	}
+	aggField, err := query.NewExpression(&plan.ColumnExpr{Name: aggName}, tupleDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := query.NewExpressionProject([]*query.Expression{aggField, key}, []string{aggName, groupKey.String()}, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group key projection: %v", err)
+	}
+
+	return pr, nil
+}
+
+// applyHavingIfNeeded filters the groups produced by aggregation with the
+// HAVING condition. Only executes if the query has a HAVING clause.
+//
+// The condition is resolved against the aggregate output: references to the
+// GROUP BY key (by name, alias or expression) and to the aggregate function of
+// the SELECT list become references to the corresponding output columns.
+//
+// Example: SELECT dept, COUNT(id) FROM employees GROUP BY dept HAVING COUNT(id) > 5
+//
+// Returns Filter operator wrapping input, or input unchanged if no HAVING.
+func (p *SelectPlan) applyHavingIfNeeded(input iterator.DbIterator) (iterator.DbIterator, error) {
+	pl := p.statement.Plan
+	if pl.Having() == nil {
+		return input, nil
+	}
+	if !pl.HasAgg() {
+		return nil, fmt.Errorf("HAVING requires an aggregate function in the SELECT list")
+	}
+
+	td := input.GetTupleDesc()
+	r := &havingResolver{
+		plan:     pl,
+		groupKey: p.groupByKey(td),
+	}
+	aggIndex := 0
+	if r.groupKey != nil {
+		r.groupColumn = td.FieldNames[0]
+		aggIndex = 1
+	}
+	r.aggColumn = td.FieldNames[aggIndex]
+	if hasGroupingCall(pl) {
+		r.groupingColumn = td.FieldNames[aggIndex+1]
+	}
+
+	cond, err := r.resolve(pl.Having())
+	if err != nil {
+		return nil, fmt.Errorf("invalid HAVING condition %s: %w", pl.Having(), err)
+	}
+
+	bound, err := query.NewExpression(cond, td)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HAVING condition %s: %w", pl.Having(), err)
+	}
+
+	filter, err := query.NewExpressionFilter(bound, input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HAVING condition %s: %w", pl.Having(), err)
+	}
+
+	return filter, nil
+}
+
+// havingResolver rewrites a HAVING condition in terms of the columns of the
+// aggregate output.
+type havingResolver struct {
+	plan                   *plan.SelectPlan
+	groupKey               plan.Expr
+	groupColumn, aggColumn string
+	groupingColumn         string // Output column of GROUPING, if the SELECT list calls it
+}
+
+func (r *havingResolver) resolve(expr plan.Expr) (plan.Expr, error) {
+	if r.isGroupKey(expr) {
+		return &plan.ColumnExpr{Name: r.groupColumn}, nil
+	}
+
+	switch e := expr.(type) {
+	case *plan.LiteralExpr:
+		return e, nil
+
+	case *plan.ColumnExpr:
+		if r.refersToGroupKey(e) {
+			return &plan.ColumnExpr{Name: r.groupColumn}, nil
+		}
+		return nil, fmt.Errorf("column %s must appear in GROUP BY or be used in an aggregate function", e.Name)
+
+	case *plan.GroupingExpr:
+		if r.groupingColumn == "" || !r.refersToGroupKey(e.Arg) {
+			return nil, fmt.Errorf("%s must be a GROUPING call of the SELECT list", e)
+		}
+		return &plan.ColumnExpr{Name: r.groupingColumn}, nil
+
+	case *plan.AggregateExpr:
+		if e.Func != strings.ToUpper(r.plan.AggOp()) || extractFieldName(e.Field) != extractFieldName(r.plan.AggField()) {
+			return nil, fmt.Errorf("aggregate %s must be the aggregate function of the SELECT list", e)
+		}
+		return &plan.ColumnExpr{Name: r.aggColumn}, nil
+
+	case *plan.CompareExpr:
+		left, err := r.resolve(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := r.resolve(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &plan.CompareExpr{Left: left, Op: e.Op, Right: right}, nil
+
+	case *plan.LogicalExpr:
+		left, err := r.resolve(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := r.resolve(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &plan.LogicalExpr{Op: e.Op, Left: left, Right: right}, nil
+
+	case *plan.CaseExpr:
+		return r.resolveCase(e)
+
+	default:
+		return nil, fmt.Errorf("unsupported expression %s", expr)
+	}
+}
+
+func (r *havingResolver) resolveCase(e *plan.CaseExpr) (plan.Expr, error) {
+	resolved := &plan.CaseExpr{Whens: make([]plan.WhenClause, 0, len(e.Whens))}
+
+	var err error
+	if e.Operand != nil {
+		if resolved.Operand, err = r.resolve(e.Operand); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, w := range e.Whens {
+		cond, err := r.resolve(w.Cond)
+		if err != nil {
+			return nil, err
+		}
+		result, err := r.resolve(w.Result)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Whens = append(resolved.Whens, plan.WhenClause{Cond: cond, Result: result})
+	}
+
+	if e.Else != nil {
+		if resolved.Else, err = r.resolve(e.Else); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// refersToGroupKey reports whether expr is the GROUP BY key or the alias of
+// a SELECT list expression that is.
+func (r *havingResolver) refersToGroupKey(expr plan.Expr) bool {
+	if r.isGroupKey(expr) {
+		return true
+	}
+	column, ok := expr.(*plan.ColumnExpr)
+	if !ok {
+		return false
+	}
+	aliased := selectListExpr(r.plan, column.Name)
+	return aliased != nil && r.isGroupKey(aliased)
+}
+
+// isGroupKey reports whether expr is the GROUP BY key. Column references
+// match regardless of their table qualifier.
+func (r *havingResolver) isGroupKey(expr plan.Expr) bool {
+	if r.groupKey == nil {
+		return false
+	}
+
+	column, ok := expr.(*plan.ColumnExpr)
+	keyColumn, keyIsColumn := r.groupKey.(*plan.ColumnExpr)
+	if ok && keyIsColumn {
+		return extractFieldName(column.Name) == extractFieldName(keyColumn.Name)
+	}
+	return expr.String() == r.groupKey.String()
 }
 
 func (p *SelectPlan) parseAggregationIndex(td *tuple.TupleDescription) (primitives.ColumnID, error) {
@@ -365,6 +925,62 @@ func (p *SelectPlan) parseAggregationIndex(td *tuple.TupleDescription) (primitiv
 	return aggFieldIndex, nil
 }
 
+// applyDistinctOnIfNeeded applies SELECT DISTINCT ON (keys) to the input operator.
+// Only executes if the query specifies DISTINCT ON.
+//
+// DISTINCT ON process:
+//  1. Sort the input by the ORDER BY key, if any (the sort is stable)
+//  2. Keep the first tuple of each group with equal keys (hash-based)
+//
+// Both steps run before the projection, so the keys and the ORDER BY key can
+// reference any input column as well as the aliases of the SELECT list.
+//
+// Example: SELECT DISTINCT ON (dept) dept, name FROM employees ORDER BY salary DESC
+//
+//	→ Returns the best-paid employee of each department
+//
+// Returns DistinctOn operator wrapping input, or input unchanged if no DISTINCT ON.
+func (p *SelectPlan) applyDistinctOnIfNeeded(input iterator.DbIterator) (iterator.DbIterator, error) {
+	pl := p.statement.Plan
+	if len(pl.DistinctOn()) == 0 {
+		return input, nil
+	}
+	if pl.HasAgg() {
+		return nil, fmt.Errorf("DISTINCT ON cannot be combined with aggregate functions")
+	}
+
+	td := input.GetTupleDesc()
+	if pl.HasOrderBy() {
+		orderKey := pl.OrderByExpr()
+		if orderKey == nil {
+			orderKey = &plan.ColumnExpr{Name: pl.OrderByField()}
+		}
+
+		var err error
+		input, err = p.buildExpressionSort(input, resolveSelectAlias(pl, orderKey, td), pl.OrderByAsc())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]*query.Expression, 0, len(pl.DistinctOn()))
+	for _, key := range pl.DistinctOn() {
+		bound, err := query.NewExpression(resolveSelectAlias(pl, key, td), td)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DISTINCT ON expression %s: %w", key, err)
+		}
+		keys = append(keys, bound)
+	}
+
+	dnt, err := query.NewDistinctOn(keys, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create distinct on operator: %w", err)
+	}
+	dnt.SetMemoryTracker(p.tx.MemoryTracker())
+
+	return dnt, nil
+}
+
 // applyDistinctIfNeeded applies DISTINCT deduplication to the input operator.
 // Only executes if the query specifies SELECT DISTINCT.
 //
@@ -378,6 +994,7 @@ func (p *SelectPlan) applyDistinctIfNeeded(input iterator.DbIterator) (iterator.
 	if err != nil {
 		return nil, fmt.Errorf("failed to create distinct operator: %w", err)
 	}
+	dnt.SetMemoryTracker(p.tx.MemoryTracker())
 
 	return dnt, nil
 }
@@ -397,10 +1014,15 @@ func (p *SelectPlan) applyDistinctIfNeeded(input iterator.DbIterator) (iterator.
 // Returns Sort operator wrapping input, or input unchanged if no ORDER BY.
 func (p *SelectPlan) applySortIfNeeded(input iterator.DbIterator) (iterator.DbIterator, error) {
 	plan := p.statement.Plan
-	if !plan.HasOrderBy() {
+	// DISTINCT ON already sorted its input to pick the first row per group.
+	if !plan.HasOrderBy() || plan.DistinctOn() != nil {
 		return input, nil
 	}
 
+	if plan.OrderByExpr() != nil {
+		return p.buildExpressionSort(input, plan.OrderByExpr(), plan.OrderByAsc())
+	}
+
 	fieldIdx, err := findFieldIndex(plan.OrderByField(), input.GetTupleDesc())
 	if err != nil {
 		return nil, fmt.Errorf("order by field %s not found: %w", plan.OrderByField(), err)
@@ -410,6 +1032,25 @@ func (p *SelectPlan) applySortIfNeeded(input iterator.DbIterator) (iterator.DbIt
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sort operator: %w", err)
 	}
+	sortOp.SetMemoryTracker(p.tx.MemoryTracker())
+
+	return sortOp, nil
+}
+
+// buildExpressionSort creates a Sort operator that orders tuples by an
+// ORDER BY expression. The expression is bound to the input schema, so it can
+// reference the columns and aliases produced by the SELECT list.
+func (p *SelectPlan) buildExpressionSort(input iterator.DbIterator, expr plan.Expr, ascending bool) (iterator.DbIterator, error) {
+	bound, err := query.NewExpression(expr, input.GetTupleDesc())
+	if err != nil {
+		return nil, fmt.Errorf("invalid order by expression %s: %w", expr, err)
+	}
+
+	sortOp, err := query.NewSortByExpression(input, bound, ascending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sort operator: %w", err)
+	}
+	sortOp.SetMemoryTracker(p.tx.MemoryTracker())
 
 	return sortOp, nil
 }
@@ -453,6 +1094,28 @@ func (p *SelectPlan) applyLimitIfNeeded(input iterator.DbIterator) (iterator.DbI
 //
 // This approach avoids the wasteful Iterator → Array → Iterator → Array conversion.
 func (p *SelectPlan) executeSetOperation() (result.Result, error) {
+	setOp, err := p.setOperationIterator()
+	if err != nil {
+		return nil, err
+	}
+	tracing.AttachOperator(p.tx.Trace(), setOp)
+
+	results, err := metadata.MaterializeTuples(setOp, p.tx.MemoryTracker())
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.SelectQueryResult{
+		TupleDesc: setOp.GetTupleDesc(),
+		Tuples:    results,
+	}, nil
+}
+
+// setOperationIterator builds the operator tree of a set operation.
+func (p *SelectPlan) setOperationIterator() (iterator.DbIterator, error) {
+	if err := p.evaluateCTEs(); err != nil {
+		return nil, err
+	}
 	pl := p.statement.Plan
 
 	leftIter, err := p.createPlanIter(pl.LeftPlan())
@@ -469,21 +1132,15 @@ func (p *SelectPlan) executeSetOperation() (result.Result, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create set operation iterator: %v", err)
 	}
-
-	results, err := metadata.CollectAllTuples(setOp)
-	if err != nil {
-		return nil, err
-	}
-
-	return &result.SelectQueryResult{
-		TupleDesc: setOp.GetTupleDesc(),
-		Tuples:    results,
-	}, nil
+	return p.traceOperator(pl.SetOpType().String(), setOp, leftIter, rightIter), nil
 }
 
+// createPlanIter builds the operator tree of pl, a subquery of p's statement,
+// which can read the same WITH queries.
 func (p *SelectPlan) createPlanIter(pl *plan.SelectPlan) (iterator.DbIterator, error) {
 	stmt := statements.NewSelectStatement(pl)
 	plan := NewSelectPlan(stmt, p.tx, p.ctx)
+	plan.ctes = p.ctes
 
 	iter, err := plan.ExecuteIterator()
 	if err != nil {
@@ -493,8 +1150,22 @@ func (p *SelectPlan) createPlanIter(pl *plan.SelectPlan) (iterator.DbIterator, e
 	return iter, nil
 }
 
+// createQueryIter is createPlanIter for queries that may be set operations,
+// such as the queries of the WITH clause.
+func (p *SelectPlan) createQueryIter(pl *plan.SelectPlan) (iterator.DbIterator, error) {
+	if !pl.IsSetOperation() {
+		return p.createPlanIter(pl)
+	}
+	sub := NewSelectPlan(statements.NewSelectStatement(pl), p.tx, p.ctx)
+	sub.ctes = p.ctes
+	return sub.setOperationIterator()
+}
+
 func (p *SelectPlan) createSetOp(l, r iterator.DbIterator) (iterator.DbIterator, error) {
-	var setOp iterator.DbIterator
+	var setOp interface {
+		iterator.DbIterator
+		SetMemoryTracker(t *membudget.Tracker)
+	}
 	var err error
 
 	isAll := p.statement.Plan.SetOpAll()
@@ -518,5 +1189,6 @@ func (p *SelectPlan) createSetOp(l, r iterator.DbIterator) (iterator.DbIterator,
 		return nil, fmt.Errorf("unsupported set operation type")
 	}
 
+	setOp.SetMemoryTracker(p.tx.MemoryTracker())
 	return setOp, nil
 }
@@ -2,7 +2,9 @@ package dml
 
 import (
 	"os"
+	"slices"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/execution/scanner"
 	"storemy/pkg/parser/statements"
 	"storemy/pkg/plan"
 	"storemy/pkg/planner/internal/ddl"
@@ -11,6 +13,7 @@ import (
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
 	"storemy/pkg/types"
+	"strings"
 	"testing"
 )
 
@@ -214,6 +217,57 @@ func TestSelectPlan_Execute_WithProjection(t *testing.T) {
 	}
 }
 
+func TestSelectPlan_PushDownProjection(t *testing.T) {
+	ctx, tx, cleanup := setupSelectTestWithData(t)
+	defer cleanup()
+
+	tests := []struct {
+		name    string
+		build   func(sp *plan.SelectPlan)
+		columns []primitives.ColumnID
+	}{
+		{
+			name: "plain columns",
+			build: func(sp *plan.SelectPlan) {
+				sp.AddProjectField("email", "")
+				sp.AddProjectField("name", "")
+			},
+			columns: []primitives.ColumnID{2, 1},
+		},
+		{
+			name:  "select all",
+			build: func(sp *plan.SelectPlan) { sp.SetSelectAll(true) },
+		},
+		{
+			name:  "aggregate",
+			build: func(sp *plan.SelectPlan) { sp.AddProjectField("age", "SUM") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selectPlan := plan.NewSelectPlan()
+			selectPlan.AddScan("users", "users")
+			tt.build(selectPlan)
+			planInstance := NewSelectPlan(statements.NewSelectStatement(selectPlan), tx, ctx)
+
+			scanOp, err := planInstance.buildScanOperator()
+			if err != nil {
+				t.Fatalf("buildScanOperator failed: %v", err)
+			}
+			planInstance.pushDownProjection(scanOp)
+
+			seqScan, ok := scanOp.(*scanner.SequentialScan)
+			if !ok {
+				t.Fatalf("expected a sequential scan, got %T", scanOp)
+			}
+			if got := seqScan.Columns(); !slices.Equal(got, tt.columns) {
+				t.Errorf("scan columns = %v, want %v", got, tt.columns)
+			}
+		})
+	}
+}
+
 func TestSelectPlan_Execute_WithFilter(t *testing.T) {
 	ctx, tx, cleanup := setupSelectTestWithData(t)
 	defer cleanup() // Always clean up resources
@@ -326,7 +380,7 @@ func TestSelectPlan_Execute_Error_TableNotFound(t *testing.T) {
 	}
 
 	expectedError := "table nonexistent_table not found"
-	if err.Error() != expectedError {
+	if !strings.Contains(err.Error(), expectedError) {
 		t.Errorf("Expected error %q, got %q", expectedError, err.Error())
 	}
 }
@@ -0,0 +1,219 @@
+package dml
+
+import (
+	"fmt"
+	"slices"
+	"storemy/pkg/execution/join"
+	"storemy/pkg/iterator"
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"strings"
+)
+
+// applySubqueryFiltersIfNeeded applies the WHERE conditions on subqueries,
+// [NOT] EXISTS and [NOT] IN, to the joined rows. Each becomes a hash semi-join
+// or anti-join with its subquery, which runs once however many rows it is
+// checked for (see buildSemiJoin), unless the subquery cannot be decorrelated;
+// it then runs once per row instead (see buildNestedSemiJoin).
+func (p *SelectPlan) applySubqueryFiltersIfNeeded(input iterator.DbIterator) (iterator.DbIterator, error) {
+	currentOp := input
+	for _, filter := range p.statement.Plan.SubqueryFilters() {
+		refs, err := p.resolveSubqueryRefs(filter, currentOp.GetTupleDesc())
+		if err != nil {
+			return nil, err
+		}
+
+		name := "Semi Join"
+		if filter.Negated {
+			name = "Anti Join"
+		}
+
+		if !filter.Decorrelatable() {
+			joinOp, err := p.buildNestedSemiJoin(filter, refs, currentOp)
+			if err != nil {
+				return nil, err
+			}
+			currentOp = p.traceOperator("Nested "+name, joinOp, currentOp)
+			continue
+		}
+
+		joinOp, rightOp, err := p.buildSemiJoin(filter, refs, currentOp)
+		if err != nil {
+			return nil, err
+		}
+		currentOp = p.traceOperator(name, joinOp, currentOp, rightOp)
+	}
+	return currentOp, nil
+}
+
+// resolveSubqueryRefs validates the column references in the WHERE clause of
+// filter's subquery and returns those naming a table of the enclosing query,
+// resolved against left, the description of its joined rows. A reference to
+// a table neither in the subquery nor in the enclosing query is an error.
+func (p *SelectPlan) resolveSubqueryRefs(filter *plan.SubqueryFilter, left *tuple.TupleDescription) ([]outerRef, error) {
+	outer := fromAliases(p.statement.Plan, len(p.statement.Plan.Joins()))
+
+	var refs []outerRef
+	for _, f := range filter.OuterRefs() {
+		table, column, _ := strings.Cut(f.Ref, ".")
+		if !slices.Contains(outer, table) {
+			return nil, fmt.Errorf("subquery in %s refers to %s, but there is no table %s in the query", filter, f.Ref, table)
+		}
+
+		idx, err := findFieldIndex(column, left)
+		if err != nil {
+			return nil, fmt.Errorf("subquery in %s refers to unknown column %s: %w", filter, f.Ref, err)
+		}
+		refs = append(refs, outerRef{ref: f.Ref, column: idx})
+	}
+	return refs, nil
+}
+
+// semiJoinKind maps a subquery condition to the semi-join that evaluates it.
+// NOT IN needs the null-aware anti-join: x NOT IN (..., NULL) is never true.
+func semiJoinKind(filter *plan.SubqueryFilter) join.SemiJoinKind {
+	switch {
+	case !filter.Negated:
+		return join.Semi
+	case filter.Exists:
+		return join.Anti
+	default:
+		return join.NullAwareAnti
+	}
+}
+
+// buildSemiJoin builds the hash semi-join evaluating filter on the rows of
+// left, returning it and its right side, the subquery. refs are the
+// subquery's references to columns of left.
+//
+// A correlated subquery, whose WHERE clause compares its columns with those
+// of the enclosing query, is decorrelated: the comparisons are removed from
+// it and become keys of the join instead, so
+//
+//	EXISTS (SELECT * FROM orders o WHERE o.customer_id = c.id)
+//
+// runs SELECT * FROM orders once and keeps the rows whose c.id is among its
+// customer_id values. filter must be Decorrelatable.
+func (p *SelectPlan) buildSemiJoin(filter *plan.SubqueryFilter, refs []outerRef, left iterator.DbIterator) (iterator.DbIterator, iterator.DbIterator, error) {
+	sub := filter.Subquery
+	leftDesc := left.GetTupleDesc()
+
+	values := refsAsWritten(sub)
+	var leftKeys []primitives.ColumnID
+	for _, r := range refs {
+		delete(values, r.ref)
+		leftKeys = append(leftKeys, r.column)
+	}
+
+	correlated := len(refs) > 0
+	decorrelated := sub.WithRefs(values)
+	if correlated {
+		// The compared columns must survive the subquery's projection
+		decorrelated.SetSelectAll(true)
+	}
+	rightOp, err := p.createPlanIter(decorrelated)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build subquery in %s: %w", filter, err)
+	}
+	rightOp = p.traceOperator("Subquery", rightOp)
+	rightDesc := rightOp.GetTupleDesc()
+
+	var rightKeys []primitives.ColumnID
+	if !filter.Exists {
+		leftIdx, err := findFieldIndex(filter.Field, leftDesc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unknown column %s in %s: %w", filter.Field, filter, err)
+		}
+
+		rightIdx := primitives.ColumnID(0)
+		if correlated {
+			if rightIdx, err = findFieldIndex(sub.SelectList()[0].FieldName, rightDesc); err != nil {
+				return nil, nil, fmt.Errorf("subquery in %s must return a column of its tables: %w", filter, err)
+			}
+		} else if rightDesc.NumFields() != 1 {
+			return nil, nil, fmt.Errorf("subquery in %s must return exactly one column", filter)
+		}
+
+		// The IN column comes first, as the null-aware anti-join expects
+		leftKeys = append([]primitives.ColumnID{leftIdx}, leftKeys...)
+		rightKeys = append(rightKeys, rightIdx)
+	}
+	for _, f := range filter.OuterRefs() {
+		idx, err := findFieldIndex(f.Field, rightDesc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("subquery in %s compares unknown column %s: %w", filter, f.Field, err)
+		}
+		rightKeys = append(rightKeys, idx)
+	}
+
+	joinOp, err := join.NewHashSemiJoin(left, rightOp, leftKeys, rightKeys, semiJoinKind(filter))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create semi-join operator: %w", err)
+	}
+	joinOp.SetMemoryTracker(p.tx.MemoryTracker())
+	return joinOp, rightOp, nil
+}
+
+// buildNestedSemiJoin builds the join evaluating filter on the rows of left
+// when its subquery cannot be decorrelated, as in
+//
+//	EXISTS (SELECT * FROM orders o WHERE o.total > c.credit_limit)
+//
+// The subquery is then rebuilt and run for each row, with refs, its
+// references to columns of left, bound to the row's values; a NULL value
+// matches nothing, so the subquery then returns no rows.
+func (p *SelectPlan) buildNestedSemiJoin(filter *plan.SubqueryFilter, refs []outerRef, left iterator.DbIterator) (iterator.DbIterator, error) {
+	sub := filter.Subquery
+
+	var key *primitives.ColumnID
+	if !filter.Exists {
+		idx, err := findFieldIndex(filter.Field, left.GetTupleDesc())
+		if err != nil {
+			return nil, fmt.Errorf("unknown column %s in %s: %w", filter.Field, filter, err)
+		}
+		key = &idx
+
+		// The references' filters do not change the output columns
+		unbound, err := p.createPlanIter(sub.WithRefs(nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build subquery in %s: %w", filter, err)
+		}
+		if unbound.GetTupleDesc().NumFields() != 1 {
+			return nil, fmt.Errorf("subquery in %s must return exactly one column", filter)
+		}
+	}
+
+	right := func(row *tuple.Tuple) (iterator.DbIterator, error) {
+		values := refsAsWritten(sub)
+		for _, r := range refs {
+			field, err := row.GetField(r.column)
+			if err != nil {
+				return nil, err
+			}
+			if field == nil {
+				return nil, nil
+			}
+			values[r.ref] = field.String()
+		}
+		return p.createPlanIter(sub.WithRefs(values))
+	}
+
+	joinOp, err := join.NewNestedSemiJoin(left, right, key, semiJoinKind(filter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create semi-join operator: %w", err)
+	}
+	return joinOp, nil
+}
+
+// refsAsWritten maps each column reference in the WHERE clause of sub to
+// itself, so that WithRefs keeps comparing with it as written.
+func refsAsWritten(sub *plan.SelectPlan) map[string]string {
+	values := make(map[string]string)
+	for _, f := range sub.Filters() {
+		if f.Ref != "" {
+			values[f.Ref] = f.Ref
+		}
+	}
+	return values
+}
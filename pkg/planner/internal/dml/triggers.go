@@ -0,0 +1,139 @@
+package dml
+
+import (
+	"fmt"
+	"storemy/pkg/audit"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/primitives"
+	"storemy/pkg/registry"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/trigger"
+	"storemy/pkg/tuple"
+	"time"
+)
+
+// rowTriggers fires the triggers of one table for one event and writes its
+// audit rows if the table is audited. The definitions are read from the
+// catalog once per statement; a nil *rowTriggers does nothing, so tables
+// without triggers or auditing pay only for those lookups.
+type rowTriggers struct {
+	ctx      *registry.DatabaseContext
+	registry *trigger.Registry
+	tx       *transaction.TransactionContext
+	table    string
+	event    trigger.Event
+	defs     []trigger.Definition
+	audit    *rowAudit
+}
+
+// rowAudit is the audit table a table's changed rows are recorded in.
+type rowAudit struct {
+	id   primitives.FileID
+	name string
+	file page.DbFile
+	td   *tuple.TupleDescription
+	rows int // Audit rows written by the statement
+}
+
+// loadRowTriggers returns the triggers defined on a table for event together
+// with its audit table, or nil if there are neither.
+func loadRowTriggers(ctx *registry.DatabaseContext, tx *transaction.TransactionContext, tableID primitives.FileID, tableName string, event trigger.Event) (*rowTriggers, error) {
+	mds, err := ctx.CatalogManager().GetTriggersForTable(tx, tableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load triggers: %w", err)
+	}
+
+	var defs []trigger.Definition
+	for _, md := range mds {
+		if trigger.Event(md.Event) != event {
+			continue
+		}
+		defs = append(defs, trigger.Definition{
+			Name:     md.TriggerName,
+			Timing:   trigger.Timing(md.Timing),
+			Event:    event,
+			Function: md.FunctionName,
+		})
+	}
+
+	ra, err := loadRowAudit(ctx, tx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) == 0 && ra == nil {
+		return nil, nil
+	}
+
+	return &rowTriggers{
+		ctx:      ctx,
+		registry: ctx.Triggers(),
+		tx:       tx,
+		table:    tableName,
+		event:    event,
+		defs:     defs,
+		audit:    ra,
+	}, nil
+}
+
+// loadRowAudit returns the audit table of a table, or nil if it is not audited.
+func loadRowAudit(ctx *registry.DatabaseContext, tx *transaction.TransactionContext, tableID primitives.FileID) (*rowAudit, error) {
+	cm := ctx.CatalogManager()
+	md, err := cm.GetAudit(tx, tableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit: %w", err)
+	}
+	if md == nil {
+		return nil, nil
+	}
+
+	file, err := cm.GetTableFile(md.AuditTableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit table file: %w", err)
+	}
+	name, err := cm.GetTableName(tx, md.AuditTableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit table name: %w", err)
+	}
+	return &rowAudit{id: md.AuditTableID, name: name, file: file, td: file.GetTupleDesc()}, nil
+}
+
+// before fires the BEFORE triggers for one row. They may change newRow in place.
+// skip reports that a trigger asked for the row to be left alone.
+func (rt *rowTriggers) before(oldRow, newRow *tuple.Tuple) (skip bool, err error) {
+	if rt == nil {
+		return false, nil
+	}
+	return rt.registry.Fire(rt.tx, rt.table, rt.defs, trigger.Before, rt.event, oldRow, newRow)
+}
+
+// after fires the AFTER triggers for one row, then writes its audit row.
+func (rt *rowTriggers) after(oldRow, newRow *tuple.Tuple) error {
+	if rt == nil {
+		return nil
+	}
+	if _, err := rt.registry.Fire(rt.tx, rt.table, rt.defs, trigger.After, rt.event, oldRow, newRow); err != nil {
+		return err
+	}
+	if rt.audit == nil {
+		return nil
+	}
+
+	row, err := audit.NewRow(rt.audit.td, rt.tx.ID, time.Now(), string(rt.event), oldRow, newRow)
+	if err != nil {
+		return fmt.Errorf("failed to build audit row: %w", err)
+	}
+	if err := rt.ctx.TupleManager().InsertTuple(rt.tx, rt.audit.file, row); err != nil {
+		return fmt.Errorf("failed to write audit row: %w", err)
+	}
+	rt.audit.rows++
+	return nil
+}
+
+// recordAuditRows reports the audit rows the statement wrote as
+// modifications of the audit table, once the statement changed all its rows.
+func (rt *rowTriggers) recordAuditRows() {
+	if rt == nil || rt.audit == nil {
+		return
+	}
+	rt.ctx.RecordModifications(rt.audit.id, rt.audit.name, rt.audit.rows)
+}
@@ -9,6 +9,7 @@ import (
 	"storemy/pkg/planner/internal/scan"
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
+	"storemy/pkg/trigger"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
 )
@@ -50,9 +51,11 @@ func NewUpdatePlan(statement *statements.UpdateStatement, tx *transaction.Transa
 // Execute performs the UPDATE operation in three phases:
 // 1. Validate table exists and build field index→value update map
 // 2. Scan table to collect all tuples matching WHERE clause
-// 3. Apply updates using storage layer's DELETE+INSERT mechanism
+// 3. Apply updates using storage layer's DELETE+INSERT mechanism, firing
+// BEFORE and AFTER UPDATE triggers around each row
 //
-// Returns a DMLResult with the count of modified rows.
+// Returns a DMLResult with the count of modified rows, which excludes rows
+// skipped by a BEFORE trigger.
 // All operations are atomic within the transaction - failures trigger rollback.
 func (p *UpdatePlan) Execute() (result.Result, error) {
 	md, err := metadata.ResolveTableMetadata(p.statement.TableName, p.tx, p.ctx)
@@ -70,19 +73,20 @@ func (p *UpdatePlan) Execute() (result.Result, error) {
 		return nil, err
 	}
 
-	tuplesToUpdate, err := metadata.CollectAllTuples(queryPlan)
+	tuplesToUpdate, err := metadata.MaterializeTuples(queryPlan, p.tx.MemoryTracker())
 	if err != nil {
 		return nil, err
 	}
 
-	err = p.updateTuples(tuplesToUpdate, updateMap, md.TableID)
+	updated, err := p.updateTuples(tuplesToUpdate, updateMap, md.TableID)
 	if err != nil {
 		return nil, err
 	}
+	p.ctx.RecordModifications(md.TableID, p.statement.TableName, updated)
 
 	return &result.DMLResult{
-		RowsAffected: len(tuplesToUpdate),
-		Message:      fmt.Sprintf("%d row(s) updated", len(tuplesToUpdate)),
+		RowsAffected: updated,
+		Message:      fmt.Sprintf("%d row(s) updated", updated),
 	}, nil
 }
 
@@ -111,7 +115,9 @@ func (p *UpdatePlan) buildUpdateMap(tupleDesc *tuple.TupleDescription) (updatedC
 //
 // UPDATE is implemented as DELETE + INSERT at the storage layer:
 // - For each tuple, a new tuple is created with updated field values
+// - BEFORE UPDATE triggers may change the new tuple or skip the row
 // - The old tuple is deleted and the new tuple is inserted
+// - AFTER UPDATE triggers fire once the row is written, followed by its audit row
 // - The tuple manager ensures atomicity within the transaction
 //
 // Parameters:
@@ -120,47 +126,68 @@ func (p *UpdatePlan) buildUpdateMap(tupleDesc *tuple.TupleDescription) (updatedC
 // - tableID: the identifier of the table being updated.
 //
 // Returns:
+// - int: the number of rows updated.
 // - error: non-nil if any tuple update fails (e.g., constraint violation, I/O error).
-func (p *UpdatePlan) updateTuples(tuples []*tuple.Tuple, updateMap updatedColMap, tableID primitives.FileID) error {
+func (p *UpdatePlan) updateTuples(tuples []*tuple.Tuple, updateMap updatedColMap, tableID primitives.FileID) (int, error) {
 	tupleMgr := p.ctx.TupleManager()
 	ctm := p.ctx.CatalogManager()
 
 	file, err := ctm.GetTableFile(tableID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Get table metadata for constraint validation
 	tableMeta, err := ctm.GetTableMetadataByID(p.tx, tableID)
 	if err != nil {
-		return fmt.Errorf("failed to get table metadata: %v", err)
+		return 0, fmt.Errorf("failed to get table metadata: %v", err)
 	}
 
 	// Get table schema for constraint validation
 	schema, err := ctm.GetTableSchema(p.tx, tableID)
 	if err != nil {
-		return fmt.Errorf("failed to get table schema: %v", err)
+		return 0, fmt.Errorf("failed to get table schema: %v", err)
 	}
 
 	// Create index searcher for UNIQUE constraint validation
 	indexSearcher := p.ctx.IndexManager().NewIndexSearcher(p.ctx.IndexManager())
 	validator := ctm.GetConstraintValidator(indexSearcher)
 
+	triggers, err := loadRowTriggers(p.ctx, p.tx, tableID, tableMeta.TableName, trigger.Update)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
 	for _, old := range tuples {
 		newTup, err := old.WithUpdatedFields(updateMap)
 		if err != nil {
-			return err
+			return 0, err
+		}
+
+		skip, err := triggers.before(old, newTup)
+		if err != nil {
+			return 0, err
+		}
+		if skip {
+			continue
 		}
 
 		// Validate constraints before update
 		if err := validator.ValidateUpdate(p.tx, tableID, tableMeta.TableName, old, newTup, schema); err != nil {
-			return err
+			return 0, err
 		}
 
 		if err := tupleMgr.UpdateTuple(p.tx, file, old, newTup); err != nil {
-			return fmt.Errorf("failed to update tuple: %v", err)
+			return 0, fmt.Errorf("failed to update tuple: %v", err)
+		}
+
+		if err := triggers.after(old, newTup); err != nil {
+			return 0, err
 		}
+		updated++
 	}
+	triggers.recordAuditRows()
 
-	return nil
+	return updated, nil
 }
@@ -12,6 +12,7 @@ import (
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
 	"storemy/pkg/types"
+	"strings"
 	"testing"
 )
 
@@ -377,7 +378,7 @@ func TestUpdatePlan_Execute_Error_TableNotFound(t *testing.T) {
 	}
 
 	expectedError := "table nonexistent_table not found"
-	if err.Error() != expectedError {
+	if !strings.Contains(err.Error(), expectedError) {
 		t.Errorf("Expected error %q, got %q", expectedError, err.Error())
 	}
 }
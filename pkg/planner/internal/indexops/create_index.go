@@ -2,7 +2,9 @@ package indexops
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
 	"storemy/pkg/parser/statements"
 	"storemy/pkg/planner/internal/result"
 	"storemy/pkg/registry"
@@ -41,6 +43,7 @@ func NewCreateIndexPlan(
 //
 // Steps:
 //  1. Validates index creation (table exists, column exists, index name unique)
+//     and locks the table exclusively
 //  2. Handles IF NOT EXISTS clause
 //  3. Creates physical index file
 //  4. Registers index in catalog (CATALOG_INDEXES)
@@ -56,7 +59,7 @@ func (p *CreateIndexPlan) Execute() (result.Result, error) {
 	validation, err := catalogOps.ValidateIndexCreation(indexName, tableName, colName)
 	if err != nil {
 		// Handle IF NOT EXISTS for already-existing index
-		if p.Statement.IfNotExists && fmt.Sprintf("%v", err) == fmt.Sprintf("index %s already exists", indexName) {
+		if p.Statement.IfNotExists && dberror.Code(err) == catalogerror.ErrCodeObjectExists {
 			return &result.DDLResult{
 				Success: true,
 				Message: fmt.Sprintf("Index %s already exists (IF NOT EXISTS)", indexName),
@@ -64,6 +67,9 @@ func (p *CreateIndexPlan) Execute() (result.Result, error) {
 		}
 		return nil, err
 	}
+	if err := p.ctx.CatalogManager().LockTableByID(p.tx, validation.TableID, true); err != nil {
+		return nil, err
+	}
 
 	// Step 2: Create physical index file
 	filePath := GenerateIndexFilePath(p.ctx, tableName, indexName)
@@ -58,6 +58,7 @@ func NewDropIndexPlan(
 //
 // Steps:
 //  1. Validates index deletion (index exists, table ownership if specified)
+//     and locks the index's table exclusively
 //  2. Handles IF EXISTS clause
 //  3. Removes index entry from CATALOG_INDEXES table
 //  4. Deletes physical index file from disk
@@ -72,7 +73,7 @@ func (p *DropIndexPlan) Execute() (result.Result, error) {
 	catalogOps := p.ctx.CatalogManager().NewIndexOps(p.tx)
 
 	// Step 1: Validate via catalog (single consolidated call)
-	_, err := catalogOps.ValidateIndexDeletion(idxName, tableName)
+	metadata, err := catalogOps.ValidateIndexDeletion(idxName, tableName)
 	if err != nil {
 		// Handle IF EXISTS for non-existent index
 		if p.Statement.IfExists {
@@ -80,6 +81,9 @@ func (p *DropIndexPlan) Execute() (result.Result, error) {
 		}
 		return nil, err
 	}
+	if err := p.ctx.CatalogManager().LockTableByID(p.tx, metadata.TableID, true); err != nil {
+		return nil, err
+	}
 
 	// Step 2: Delete index from system (catalog + physical file)
 	indexOpsCoordinator := NewIndexOps(p.tx, p.ctx.CatalogManager(), p.ctx.IndexManager())
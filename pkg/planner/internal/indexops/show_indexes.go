@@ -2,6 +2,7 @@ package indexops
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
 	"storemy/pkg/concurrency/transaction"
@@ -92,7 +93,7 @@ func (p *ShowIndexesPlan) getIndexesForTable() ([]*systemtable.IndexMetadata, er
 
 	tableID, err := cm.GetTableID(p.tx, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("table %s does not exist", tableName)
+		return nil, catalogerror.NewTableNotFound("table %s does not exist", tableName)
 	}
 
 	indexes, err := cm.NewIndexOps(p.tx).GetIndexesByTable(tableID)
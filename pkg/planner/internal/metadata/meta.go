@@ -2,7 +2,10 @@ package metadata
 
 import (
 	"fmt"
+	"storemy/pkg/catalog/catalogerror"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/execution/membudget"
+	"storemy/pkg/foreign"
 	"storemy/pkg/iterator"
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
@@ -16,11 +19,19 @@ type TableMetadata struct {
 
 // resolveTableMetadata retrieves table ID and schema in a single operation.
 // This is the primary table lookup method used by all planner components.
+// The table is locked shared for tx, so DDL cannot drop or rename it while
+// the statement runs.
 func ResolveTableMetadata(tableName string, tx *transaction.TransactionContext, ctx *registry.DatabaseContext) (*TableMetadata, error) {
 	catalogMgr := ctx.CatalogManager()
-	tableID, err := catalogMgr.GetTableID(tx, tableName)
+	tableID, err := catalogMgr.LockTable(tx, tableName, false)
 	if err != nil {
-		return nil, fmt.Errorf("table %s not found", tableName)
+		if catalogMgr.TableExists(tx, tableName) {
+			return nil, err
+		}
+		if ft, _ := catalogMgr.GetForeignTable(tx, tableName); ft != nil {
+			return nil, fmt.Errorf("table %s is a foreign table and can only be read with SELECT", tableName)
+		}
+		return nil, catalogerror.NewTableNotFound("table %s not found", tableName)
 	}
 
 	sch, err := catalogMgr.GetTableSchema(tx, tableID)
@@ -34,6 +45,27 @@ func ResolveTableMetadata(tableName string, tx *transaction.TransactionContext,
 	}, nil
 }
 
+// ResolveForeignTable returns the definition of a foreign table, or nil if
+// tableName is a stored table or does not exist. Stored tables are checked
+// first so that ordinary queries do not scan the foreign table catalog.
+func ResolveForeignTable(tableName string, tx *transaction.TransactionContext, ctx *registry.DatabaseContext) (*foreign.Table, error) {
+	catalogMgr := ctx.CatalogManager()
+	if catalogMgr.TableExists(tx, tableName) {
+		return nil, nil
+	}
+
+	md, err := catalogMgr.GetForeignTable(tx, tableName)
+	if err != nil || md == nil {
+		return nil, err
+	}
+
+	ft, err := foreign.FromMetadata(md)
+	if err != nil {
+		return nil, fmt.Errorf("invalid definition of foreign table %s: %v", tableName, err)
+	}
+	return ft, nil
+}
+
 // resolveTableID converts a table name to its internal numeric identifier.
 // Convenience wrapper around resolveTableMetadata when only the ID is needed.
 func ResolveTableID(tableName string, tx *transaction.TransactionContext, ctx *registry.DatabaseContext) (primitives.FileID, error) {
@@ -48,11 +80,22 @@ func ResolveTableID(tableName string, tx *transaction.TransactionContext, ctx *r
 // collectAllTuples executes an iterator and materializes all results into memory.
 // This is used by operators that require full result sets (e.g., ORDER BY, aggregation).
 func CollectAllTuples(it iterator.DbIterator) ([]*tuple.Tuple, error) {
+	return MaterializeTuples(it, nil)
+}
+
+// MaterializeTuples is CollectAllTuples for a query whose memory is accounted
+// by mem: each result tuple is reserved until the query finishes, and
+// materialization fails with membudget.ErrOutOfMemoryBudget once the
+// query's budget is exhausted. A nil mem accounts nothing.
+func MaterializeTuples(it iterator.DbIterator, mem *membudget.Tracker) ([]*tuple.Tuple, error) {
 	if err := it.Open(); err != nil {
-		return nil, fmt.Errorf("failed to open iterator: %v", err)
+		return nil, fmt.Errorf("failed to open iterator: %w", err)
 	}
 	defer it.Close()
 	return iterator.Map(it, func(t *tuple.Tuple) (*tuple.Tuple, error) {
+		if err := mem.Reserve(membudget.TupleSize(t)); err != nil {
+			return nil, fmt.Errorf("cannot materialize query result: %w", err)
+		}
 		return t, nil
 	})
 }
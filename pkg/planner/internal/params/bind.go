@@ -0,0 +1,370 @@
+package params
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/plan"
+	"storemy/pkg/planner/internal/metadata"
+	"storemy/pkg/primitives"
+	"storemy/pkg/registry"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+)
+
+// Bind binds args to the bind parameters of a parsed statement: args[0] to $1
+// (or the first ?), args[1] to $2, and so on. The statement is modified in
+// place, so a statement is bound once, right after it is parsed.
+//
+// The type of each parameter is inferred from where it is used:
+//   - compared with a column (WHERE age > $1): the column's type
+//   - inserted into or assigned to a column (VALUES ($1), SET age = $1): the column's type
+//   - compared with another expression: that expression's type
+//   - right side of LIKE, ILIKE or REGEXP: STRING
+//   - elsewhere: the type of the Go value (see types.TypeOfValue)
+//
+// Each argument is then converted to that type with types.FieldFromValue, so
+// a string argument for an INT column must hold an integer. Argument values
+// are never parsed as SQL, which is what makes parameterized queries safe
+// from SQL injection.
+//
+// It is an error for the statement to use a parameter that has no argument,
+// or for there to be more arguments than parameters.
+func Bind(stmt statements.Statement, args []any, tx *transaction.TransactionContext, ctx *registry.DatabaseContext) error {
+	b := &binder{args: args, tx: tx, ctx: ctx}
+	if err := b.bindStatement(stmt); err != nil {
+		return err
+	}
+
+	if len(args) > b.count {
+		return fmt.Errorf("got %d arguments, but the statement has %d parameters", len(args), b.count)
+	}
+	return nil
+}
+
+// binder walks a statement and binds the values of its parameters.
+type binder struct {
+	args  []any
+	count int // Highest parameter number seen
+	tx    *transaction.TransactionContext
+	ctx   *registry.DatabaseContext
+
+	// scope holds the schemas of the tables the statement reads or writes,
+	// used to infer parameter types from column references.
+	scope []*tuple.TupleDescription
+}
+
+func (b *binder) bindStatement(stmt statements.Statement) error {
+	switch s := stmt.(type) {
+	case *statements.SelectStatement:
+		return b.bindSelect(s.Plan)
+	case *statements.InsertStatement:
+		return b.bindInsert(s)
+	case *statements.UpdateStatement:
+		return b.bindUpdate(s)
+	case *statements.DeleteStatement:
+		b.scope = b.tableScope(s.TableName)
+		return b.bindFilter(s.WhereClause)
+	case *statements.ExplainStatement:
+		return b.bindStatement(s.Statement)
+	default:
+		return nil
+	}
+}
+
+func (b *binder) bindSelect(p *plan.SelectPlan) error {
+	if p.IsSetOperation() {
+		if err := b.bindSelect(p.LeftPlan()); err != nil {
+			return err
+		}
+		return b.bindSelect(p.RightPlan())
+	}
+
+	var names []string
+	for _, table := range p.Tables() {
+		names = append(names, table.TableName)
+	}
+	for _, join := range p.Joins() {
+		names = append(names, join.RightTable.TableName)
+	}
+	b.scope = b.tableScope(names...)
+
+	for _, filter := range p.Filters() {
+		if err := b.bindFilter(filter); err != nil {
+			return err
+		}
+	}
+
+	exprs := []plan.Expr{p.GroupByExpr(), p.Having(), p.OrderByExpr()}
+	for _, node := range p.SelectList() {
+		exprs = append(exprs, node.Expr)
+	}
+	exprs = append(exprs, p.DistinctOn()...)
+	for _, expr := range exprs {
+		if err := b.bindExpr(expr, types.InvalidType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *binder) bindInsert(s *statements.InsertStatement) error {
+	b.scope = b.tableScope(s.TableName)
+
+	for _, p := range s.Params {
+		typ := types.InvalidType
+		switch {
+		case len(s.Fields) > 0:
+			if p.Column < len(s.Fields) {
+				typ = b.columnType(s.Fields[p.Column])
+			}
+		case len(b.scope) > 0:
+			if t, err := b.scope[0].TypeAtIndex(primitives.ColumnID(p.Column)); err == nil {
+				typ = t
+			}
+		}
+
+		value, err := b.value(p.Index, typ)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			return fmt.Errorf("parameter $%d: cannot insert NULL", p.Index)
+		}
+		s.Values[p.Row][p.Column] = value
+	}
+	return nil
+}
+
+func (b *binder) bindUpdate(s *statements.UpdateStatement) error {
+	b.scope = b.tableScope(s.TableName)
+
+	for i := range s.SetClauses {
+		clause := &s.SetClauses[i]
+		if clause.Param == 0 {
+			continue
+		}
+
+		value, err := b.value(clause.Param, b.columnType(clause.FieldName))
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			return fmt.Errorf("parameter $%d: cannot set %s to NULL", clause.Param, clause.FieldName)
+		}
+		clause.Value = value
+	}
+
+	return b.bindFilter(s.WhereClause)
+}
+
+// bindFilter binds the parameters of a WHERE condition. A simple condition
+// on a parameter gets the parameter's value as its constant; as a constant
+// cannot be NULL, a NULL value turns it into the equivalent expression
+// filter, which matches no rows.
+func (b *binder) bindFilter(filter *plan.FilterNode) error {
+	if filter == nil {
+		return nil
+	}
+	if filter.Expr != nil {
+		return b.bindExpr(filter.Expr, types.BoolType)
+	}
+	if filter.Param == 0 {
+		return nil
+	}
+
+	typ := b.columnType(filter.Field)
+	if filter.Predicate.IsPatternMatch() {
+		typ = types.StringType
+	}
+
+	value, err := b.value(filter.Param, typ)
+	if err != nil {
+		return err
+	}
+
+	if value == nil {
+		column := &plan.ColumnExpr{Name: filter.Field}
+		filter.Expr = &plan.CompareExpr{Left: column, Op: filter.Predicate, Right: &plan.LiteralExpr{}}
+		return nil
+	}
+	filter.BindConstant(value.String())
+	return nil
+}
+
+// bindExpr binds the parameters of expr. want is the type expr is expected
+// to have, or InvalidType if it is unknown.
+func (b *binder) bindExpr(expr plan.Expr, want types.Type) error {
+	switch e := expr.(type) {
+	case *plan.ParamExpr:
+		value, err := b.value(e.Index, want)
+		if err != nil {
+			return err
+		}
+		e.Bind(value)
+		return nil
+
+	case *plan.CompareExpr:
+		leftType, rightType := b.exprType(e.Left), b.exprType(e.Right)
+		if e.Op.IsPatternMatch() {
+			leftType, rightType = types.StringType, types.StringType
+		}
+		if err := b.bindExpr(e.Left, rightType); err != nil {
+			return err
+		}
+		return b.bindExpr(e.Right, leftType)
+
+	case *plan.LogicalExpr:
+		if err := b.bindExpr(e.Left, types.BoolType); err != nil {
+			return err
+		}
+		return b.bindExpr(e.Right, types.BoolType)
+
+	case *plan.CaseExpr:
+		return b.bindCase(e, want)
+
+	default:
+		return nil
+	}
+}
+
+// bindCase binds the parameters of a CASE expression. In a simple CASE the
+// operand and WHEN values share a type; the results share the type of the
+// first result whose type is known.
+func (b *binder) bindCase(e *plan.CaseExpr, want types.Type) error {
+	condType := types.BoolType
+	if e.Operand != nil {
+		condType = b.exprType(e.Operand)
+		for _, w := range e.Whens {
+			if condType != types.InvalidType {
+				break
+			}
+			condType = b.exprType(w.Cond)
+		}
+		if err := b.bindExpr(e.Operand, condType); err != nil {
+			return err
+		}
+	}
+
+	resultType := want
+	for _, w := range e.Whens {
+		if resultType != types.InvalidType {
+			break
+		}
+		resultType = b.exprType(w.Result)
+	}
+	if resultType == types.InvalidType && e.Else != nil {
+		resultType = b.exprType(e.Else)
+	}
+
+	for _, w := range e.Whens {
+		if err := b.bindExpr(w.Cond, condType); err != nil {
+			return err
+		}
+		if err := b.bindExpr(w.Result, resultType); err != nil {
+			return err
+		}
+	}
+	if e.Else != nil {
+		return b.bindExpr(e.Else, resultType)
+	}
+	return nil
+}
+
+// exprType returns the type of expr as far as it is known before execution,
+// or InvalidType.
+func (b *binder) exprType(expr plan.Expr) types.Type {
+	switch e := expr.(type) {
+	case *plan.ColumnExpr:
+		return b.columnType(e.Name)
+	case *plan.LiteralExpr:
+		if e.Value != nil {
+			return e.Value.Type()
+		}
+	case *plan.ParamExpr:
+		if e.Bound && e.Value != nil {
+			return e.Value.Type()
+		}
+	case *plan.CompareExpr, *plan.LogicalExpr:
+		return types.BoolType
+	case *plan.GroupingExpr:
+		return types.IntType
+	case *plan.CaseExpr:
+		for _, w := range e.Whens {
+			if t := b.exprType(w.Result); t != types.InvalidType {
+				return t
+			}
+		}
+		if e.Else != nil {
+			return b.exprType(e.Else)
+		}
+	}
+	return types.InvalidType
+}
+
+// value converts the argument of parameter $index to typ, or to the type of
+// the Go value if typ is InvalidType.
+func (b *binder) value(index int, typ types.Type) (types.Field, error) {
+	b.count = max(b.count, index)
+	if index > len(b.args) {
+		return nil, fmt.Errorf("no value supplied for parameter $%d", index)
+	}
+
+	arg := b.args[index-1]
+	if arg == nil {
+		return nil, nil
+	}
+
+	if typ == types.InvalidType {
+		t, err := types.TypeOfValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("parameter $%d: %v", index, err)
+		}
+		typ = t
+	}
+
+	field, err := types.FieldFromValue(arg, typ)
+	if err != nil {
+		return nil, fmt.Errorf("parameter $%d: %v", index, err)
+	}
+	return field, nil
+}
+
+// columnType returns the type of the named column in the binder's scope, or
+// InvalidType if no table in scope has it.
+func (b *binder) columnType(name string) types.Type {
+	if dot := strings.LastIndex(name, "."); dot != -1 {
+		name = name[dot+1:]
+	}
+
+	for _, td := range b.scope {
+		if idx, err := td.FindFieldIndex(name); err == nil {
+			if t, err := td.TypeAtIndex(idx); err == nil {
+				return t
+			}
+		}
+	}
+	return types.InvalidType
+}
+
+// tableScope resolves the schemas of the named tables, which may be system
+// views, foreign tables or stored tables. Tables that do not exist are
+// skipped; planning reports them.
+func (b *binder) tableScope(names ...string) []*tuple.TupleDescription {
+	var scope []*tuple.TupleDescription
+	for _, name := range names {
+		if view, ok := b.ctx.SystemViews().Lookup(name); ok {
+			scope = append(scope, view.TupleDesc)
+			continue
+		}
+		if ft, err := metadata.ResolveForeignTable(name, b.tx, b.ctx); err == nil && ft != nil {
+			scope = append(scope, ft.TupleDesc)
+			continue
+		}
+		if md, err := metadata.ResolveTableMetadata(name, b.tx, b.ctx); err == nil {
+			scope = append(scope, md.TupleDesc)
+		}
+	}
+	return scope
+}
@@ -0,0 +1,231 @@
+package scan
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/config"
+	"storemy/pkg/iterator"
+	"storemy/pkg/log/logmanager"
+	"storemy/pkg/log/record"
+	"storemy/pkg/plan"
+	"storemy/pkg/primitives"
+	"storemy/pkg/registry"
+	"storemy/pkg/tuple"
+	"time"
+)
+
+// BuildHistoryScan builds an iterator over the rows a table held at the point
+// asOf names, for SELECT ... AS OF.
+//
+// The engine keeps no old row versions, so the past state is rebuilt from the
+// write-ahead log: the table's current rows are read with a sequential scan,
+// then every change made by a transaction that committed after the point is
+// undone, newest first, using the tuple images of its log records. Changes of
+// transactions that aborted were already undone in place and are skipped.
+//
+// A point older than the time_travel_retention setting is rejected, as is
+// any point when the setting is 0. So is a point before the table's file was
+// renamed, deleted or bulk loaded, since those changes are not logged row by
+// row. A point before the table was created reads it empty. Index scans are
+// never used; a non-nil whereClause is applied to the rebuilt rows.
+//
+// Parameters:
+// - tx: the transaction context used to read the current rows.
+// - tableID: the identifier of the table to scan.
+// - asOf: the point in the past to read the table at.
+// - whereClause: optional filter node describing a simple WHERE predicate; may be nil.
+// - ctx: database context providing catalog, page store and settings access.
+//
+// Returns:
+// - iterator.DbIterator: an iterator that produces the table's past rows (possibly filtered).
+// - error: non-nil if the point is out of range or the log cannot be read.
+func BuildHistoryScan(tx *transaction.TransactionContext, tableID primitives.FileID, asOf *plan.AsOf, whereClause *plan.FilterNode, ctx *registry.DatabaseContext) (iterator.DbIterator, error) {
+	retention := config.DefaultTimeTravelRetention
+	if store := ctx.Settings(); store != nil {
+		retention = store.Settings().TimeTravelRetention
+	}
+	if retention <= 0 {
+		return nil, fmt.Errorf("AS OF queries are disabled (time_travel_retention is 0)")
+	}
+
+	heapFile, err := getHeapFileForTable(ctx, tableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table file: %v", err)
+	}
+	td := heapFile.GetTupleDesc()
+
+	// Read the current rows first: their page locks keep writers from
+	// changing them until tx ends, so no change to them is missing from
+	// the log read afterwards
+	current, err := readCurrentRows(tx, tableID, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := readTableHistory(tableID, asOf, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if oldest := time.Now().Add(-retention); h.at.Before(oldest) {
+		return nil, fmt.Errorf("AS OF %s is older than the time travel retention of %v", asOf, retention)
+	}
+	if h.rewrittenLSN != 0 {
+		return nil, fmt.Errorf("AS OF %s is before table %d was replaced or bulk loaded at LSN %d", asOf, tableID, h.rewrittenLSN)
+	}
+
+	rows, err := undoTableChanges(current, h.changes, td)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild table %d AS OF %s: %v", tableID, asOf, err)
+	}
+	return BuildRowScan(td, rows, whereClause)
+}
+
+// historySlot identifies the slot a row lives in. Every row of a scan is in
+// the same file, so the page number and slot are enough.
+type historySlot struct {
+	page primitives.PageNumber
+	slot primitives.SlotID
+}
+
+// tableHistory is what readTableHistory learns from the log about one table.
+type tableHistory struct {
+	changes      []*record.LogRecord // Row changes to undo, in LSN order
+	at           time.Time           // Time of the point: the TIMESTAMP, or the time of the last record at the LSN
+	rewrittenLSN primitives.LSN      // Last committed file change or bulk load after the point, 0 if none
+}
+
+// readCurrentRows returns the rows tableID holds now, by the slot they live in.
+func readCurrentRows(tx *transaction.TransactionContext, tableID primitives.FileID, ctx *registry.DatabaseContext) (map[historySlot]*tuple.Tuple, error) {
+	scanOp, err := BuildScanWithFilter(tx, tableID, nil, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanOp.Open(); err != nil {
+		return nil, fmt.Errorf("failed to open table scan: %v", err)
+	}
+	defer scanOp.Close()
+
+	rows := make(map[historySlot]*tuple.Tuple)
+	_, err = iterator.Map(scanOp, func(t *tuple.Tuple) (*tuple.Tuple, error) {
+		if t.RecordID == nil {
+			return nil, fmt.Errorf("row of table %d has no RecordID", tableID)
+		}
+		rows[historySlot{t.RecordID.PageID.PageNo(), t.RecordID.TupleNum}] = t
+		return t, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table %d: %v", tableID, err)
+	}
+	return rows, nil
+}
+
+// readTableHistory reads the log and collects the row changes of tableID
+// to undo to get back to asOf: every change of a transaction that committed
+// after the point, including those it logged before it. A record is at or
+// before an LSN point when its LSN is, and at or before a TIMESTAMP point
+// when it was written no later; record times are only kept to the second.
+func readTableHistory(tableID primitives.FileID, asOf *plan.AsOf, ctx *registry.DatabaseContext) (*tableHistory, error) {
+	w := ctx.PageStore().GetWal()
+	if w == nil {
+		return nil, fmt.Errorf("AS OF queries need a write-ahead log")
+	}
+
+	before := func(rec *record.LogRecord) bool {
+		if asOf.ByTime {
+			return !rec.Timestamp.After(asOf.Time)
+		}
+		return rec.LSN <= asOf.LSN
+	}
+
+	h := &tableHistory{at: asOf.Time}
+	committedAfter := make(map[int64]bool)
+	var changes, rewrites []*record.LogRecord
+	err := w.ReadHistory(func(rec *record.LogRecord) error {
+		switch rec.Type {
+		case record.InsertRecord, record.DeleteRecord, record.UpdateRecord:
+			// Kept whatever their LSN: a change logged before the point is
+			// undone too if its transaction committed after it. Records
+			// only keep the low 32 bits of the file ID.
+			if rec.TID != nil && rec.PageID != nil && uint32(rec.PageID.FileID()) == uint32(tableID) {
+				changes = append(changes, rec)
+			}
+		}
+		if !asOf.ByTime && (before(rec) || h.at.IsZero()) {
+			h.at = rec.Timestamp
+		}
+		if before(rec) {
+			return nil
+		}
+
+		switch rec.Type {
+		case record.CommitRecord:
+			if rec.TID == nil {
+				return nil
+			}
+			committedAfter[rec.TID.ID()] = true
+		case record.FileOpRecord:
+			if rec.FileOp.Path.Hash() == tableID || rec.FileOp.NewPath.Hash() == tableID {
+				rewrites = append(rewrites, rec)
+			}
+		case record.BulkLoadRecord:
+			if rec.BulkLoad.Path.Hash() == tableID {
+				rewrites = append(rewrites, rec)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if h.at.IsZero() {
+		h.at = time.Now() // The log is empty, so nothing changed since
+	}
+	for _, rec := range rewrites {
+		if rec.TID != nil && committedAfter[rec.TID.ID()] {
+			h.rewrittenLSN = rec.LSN
+		}
+	}
+	h.changes = slices.DeleteFunc(changes, func(rec *record.LogRecord) bool {
+		return !committedAfter[rec.TID.ID()]
+	})
+	return h, nil
+}
+
+// undoTableChanges applies the undo of changes to rows, newest first, and
+// returns the resulting rows in page and slot order.
+func undoTableChanges(rows map[historySlot]*tuple.Tuple, changes []*record.LogRecord, td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+	for _, rec := range slices.Backward(changes) {
+		image := rec.BeforeImage
+		if rec.Type == record.InsertRecord {
+			image = rec.AfterImage
+		}
+		t, err := logmanager.DecodeTupleImage(rec.PageID, image, td)
+		if err != nil {
+			return nil, fmt.Errorf("LSN %d: %v", rec.LSN, err)
+		}
+
+		slot := historySlot{rec.PageID.PageNo(), t.RecordID.TupleNum}
+		if rec.Type == record.InsertRecord {
+			delete(rows, slot)
+		} else {
+			rows[slot] = t
+		}
+	}
+
+	slots := make([]historySlot, 0, len(rows))
+	for slot := range rows {
+		slots = append(slots, slot)
+	}
+	slices.SortFunc(slots, func(a, b historySlot) int {
+		return cmp.Or(cmp.Compare(a.page, b.page), cmp.Compare(a.slot, b.slot))
+	})
+
+	result := make([]*tuple.Tuple, len(slots))
+	for i, slot := range slots {
+		result[i] = rows[slot]
+	}
+	return result, nil
+}
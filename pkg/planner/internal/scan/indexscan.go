@@ -11,6 +11,7 @@ import (
 	"storemy/pkg/plan"
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
+	"storemy/pkg/storage/index"
 	"storemy/pkg/types"
 	"strings"
 )
@@ -32,7 +33,7 @@ type IndexScannerBuilder struct {
 // Index selection logic:
 //  1. Get all indexes for the table
 //  2. Find an index on the filtered column
-//  3. Check if the predicate is index-friendly (=, <, >, <=, >=)
+//  3. Check if the predicate is index-friendly (=, <, >, <=, >=, or LIKE with a literal prefix)
 //  4. Create appropriate IndexScan operator (equality or range)
 func (b *IndexScannerBuilder) TryBuildIndexScan(filter *plan.FilterNode) (iterator.DbIterator, bool, error) {
 	indexCol, err := b.getIndexColumn(filter)
@@ -61,10 +62,46 @@ func (b *IndexScannerBuilder) TryBuildIndexScan(filter *plan.FilterNode) (iterat
 	case primitives.GreaterThan, primitives.LessThan,
 		primitives.GreaterThanOrEqual, primitives.LessThanOrEqual:
 		return b.buildIndexRangeScan(*indexCfg, *pred)
+
+	case primitives.Like:
+		return b.buildIndexPrefixScan(*indexCfg, *pred)
 	}
 	return nil, false, nil
 }
 
+// buildIndexPrefixScan creates an IndexScan operator for LIKE patterns with a
+// literal prefix, e.g. LIKE 'abc%'. Every match lies in [prefix, successor),
+// so only that range of the index is scanned; the full pattern is applied as
+// a post-filter. Patterns starting with a wildcard and non-BTree indexes are
+// not converted and fall back to a sequential scan.
+func (b *IndexScannerBuilder) buildIndexPrefixScan(cfg scanner.IndexScanConfig, pred query.Predicate) (iterator.DbIterator, bool, error) {
+	if cfg.Index.GetIndexType() != index.BTreeIndex {
+		return nil, false, nil
+	}
+
+	prefix := types.LikePrefix(pred.Value().String())
+	if prefix == "" {
+		return nil, false, nil
+	}
+
+	start := types.NewStringField(prefix, types.StringMaxSize)
+	end := types.GetMaxValueFor(types.StringType)
+	if bound, ok := types.PrefixUpperBound(prefix); ok {
+		end = types.NewStringField(bound, types.StringMaxSize)
+	}
+
+	indexScan, err := scanner.NewIndexRangeScan(cfg, start, end)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create prefix scan: %w", err)
+	}
+
+	filterOp, err := query.NewFilter(&pred, indexScan)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create pattern filter: %w", err)
+	}
+	return filterOp, true, nil
+}
+
 // buildIndexEqualityScan creates an IndexScan operator for equality predicates (=).
 // Works with both Hash and BTree indexes.
 func (b *IndexScannerBuilder) buildIndexEqualityScan(cfg scanner.IndexScanConfig, pred query.Predicate) (iterator.DbIterator, bool, error) {
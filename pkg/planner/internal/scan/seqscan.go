@@ -5,11 +5,13 @@ import (
 	"storemy/pkg/concurrency/transaction"
 	"storemy/pkg/execution/query"
 	"storemy/pkg/execution/scanner"
+	"storemy/pkg/foreign"
 	"storemy/pkg/iterator"
 	"storemy/pkg/plan"
 	"storemy/pkg/primitives"
 	"storemy/pkg/registry"
 	"storemy/pkg/storage/heap"
+	"storemy/pkg/sysview"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
 	"strings"
@@ -19,10 +21,12 @@ import (
 //
 // Behavior:
 //   - If a non-nil whereClause is provided, the function attempts to construct an index-backed scan
-//     (via tryBuildIndexScan). If an index scan can be used, it is returned.
+//     (via tryBuildIndexScan). If an index scan can be used, it is returned. Expression
+//     conditions (e.g. involving CASE) never use an index.
 //   - If index scan construction fails or no applicable index is available, the function falls back
-//     to creating a sequential table scan and, if a whereClause was provided, wraps that scan with
-//     a Filter operator that evaluates the predicate derived from the whereClause.
+//     to creating a sequential table scan. A simple field-constant whereClause is pushed down into
+//     the scan, which skips the rows failing it as it reads them; an expression condition wraps the
+//     scan with a Filter operator instead.
 //
 // Parameters:
 // - tx: the transaction context used for the scan.
@@ -39,7 +43,7 @@ func BuildScanWithFilter(tx *transaction.TransactionContext, tableID primitives.
 		return nil, fmt.Errorf("failed to get table file: %v", err)
 	}
 
-	if whereClause != nil {
+	if whereClause != nil && whereClause.Expr == nil {
 		idxBuilder := IndexScannerBuilder{tx: tx, ctx: ctx, tableID: tableID}
 		indexOp, usedIndex, err := idxBuilder.TryBuildIndexScan(whereClause)
 		if err != nil {
@@ -54,6 +58,112 @@ func BuildScanWithFilter(tx *transaction.TransactionContext, tableID primitives.
 		return nil, fmt.Errorf("failed to create table scan: %v", err)
 	}
 
+	if whereClause == nil {
+		return scanOp, nil
+	}
+	if whereClause.Expr == nil {
+		return pushDownFilter(scanOp, whereClause)
+	}
+
+	return createFilter(scanOp, whereClause)
+}
+
+// pushDownFilter makes a sequential scan evaluate a simple WHERE predicate
+// itself rather than through a Filter operator above it.
+//
+// Parameters:
+// - scanOp: the sequential scan to filter.
+// - whereClause: the filter node describing a simple WHERE predicate.
+//
+// Returns:
+// - iterator.DbIterator: scanOp, now returning only the rows passing the predicate.
+// - error: non-nil if predicate construction fails.
+func pushDownFilter(scanOp *scanner.SequentialScan, whereClause *plan.FilterNode) (iterator.DbIterator, error) {
+	predicate, err := buildPredicateFromFilterNode(whereClause, scanOp.GetTupleDesc())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build WHERE predicate: %v", err)
+	}
+
+	scanOp.SetFilters(heap.ScanFilter{
+		Field: predicate.FieldIndex(),
+		Op:    predicate.Operation(),
+		Value: predicate.Value(),
+	})
+	return scanOp, nil
+}
+
+// BuildViewScan builds an iterator over the rows of a system view.
+// System views have no indexes, so a non-nil whereClause is always applied
+// with a Filter operator on top of the view scan.
+//
+// Parameters:
+// - view: the system view to scan.
+// - whereClause: optional filter node describing a simple WHERE predicate; may be nil.
+//
+// Returns:
+// - iterator.DbIterator: an iterator that produces the view's rows (possibly filtered).
+// - error: non-nil on failure to prepare the scan or predicate.
+func BuildViewScan(view *sysview.View, whereClause *plan.FilterNode) (iterator.DbIterator, error) {
+	scanOp, err := scanner.NewViewScan(view.TupleDesc, view.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scan for view %s: %v", view.Name, err)
+	}
+
+	if whereClause == nil {
+		return scanOp, nil
+	}
+
+	return createFilter(scanOp, whereClause)
+}
+
+// BuildForeignScan builds an iterator that parses the rows of a foreign table
+// from its file as they are read. Foreign tables have no indexes, so a non-nil
+// whereClause is always applied with a Filter operator on top of the scan.
+//
+// Parameters:
+// - table: the foreign table to scan.
+// - dataDir: the directory relative file locations are resolved against.
+// - whereClause: optional filter node describing a simple WHERE predicate; may be nil.
+//
+// Returns:
+// - iterator.DbIterator: an iterator that produces the file's rows (possibly filtered).
+// - error: non-nil on failure to prepare the scan or predicate.
+func BuildForeignScan(table *foreign.Table, dataDir string, whereClause *plan.FilterNode) (iterator.DbIterator, error) {
+	open := func() (scanner.RowStream, error) {
+		return table.Open(dataDir)
+	}
+
+	scanOp, err := scanner.NewForeignScan(table.TupleDesc, open)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scan for foreign table %s: %v", table.Name, err)
+	}
+
+	if whereClause == nil {
+		return scanOp, nil
+	}
+
+	return createFilter(scanOp, whereClause)
+}
+
+// BuildRowScan builds an iterator over rows already in memory, such as the
+// result of a WITH query, which all conform to td.
+//
+// Parameters:
+// - td: the schema of the rows.
+// - rows: the rows to scan.
+// - whereClause: optional filter node describing a simple WHERE predicate; may be nil.
+//
+// Returns:
+// - iterator.DbIterator: an iterator that produces the rows (possibly filtered).
+// - error: non-nil on failure to prepare the scan or predicate.
+func BuildRowScan(td *tuple.TupleDescription, rows []*tuple.Tuple, whereClause *plan.FilterNode) (iterator.DbIterator, error) {
+	scanOp, err := scanner.NewViewScan(td, func() ([]*tuple.Tuple, error) {
+		return rows, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scan over rows: %v", err)
+	}
+
 	if whereClause == nil {
 		return scanOp, nil
 	}
@@ -67,6 +177,7 @@ func BuildScanWithFilter(tx *transaction.TransactionContext, tableID primitives.
 //   - Resolves dotted field names by taking the last segment (e.g. "table.col" -> "col").
 //   - Looks up the field index and type in the provided TupleDescription.
 //   - Converts the filter constant to a Field value with the appropriate type.
+//   - Rejects pattern operators (LIKE, ILIKE, REGEXP) on non-string columns.
 //   - Constructs and returns a query.Predicate that compares the field at the resolved index
 //     using the operator specified in the FilterNode.
 //
@@ -89,6 +200,10 @@ func buildPredicateFromFilterNode(filter *plan.FilterNode, td *tuple.TupleDescri
 	}
 
 	fieldType, _ := td.TypeAtIndex(fieldIndex)
+	if filter.Predicate.IsPatternMatch() && fieldType != types.StringType {
+		return nil, fmt.Errorf("%s requires a string column, %s is %s", filter.Predicate, fieldName, fieldType)
+	}
+
 	constantField, err := types.CreateFieldFromConstant(fieldType, filter.Constant)
 	if err != nil {
 		return nil, err
@@ -124,6 +239,12 @@ func getHeapFileForTable(ctx *registry.DatabaseContext, tableID primitives.FileI
 	return heapFile, nil
 }
 
+// BuildFilter applies whereClause to the rows of input, an operator other
+// than a table scan, such as the join of several tables.
+func BuildFilter(input iterator.DbIterator, whereClause *plan.FilterNode) (iterator.DbIterator, error) {
+	return createFilter(input, whereClause)
+}
+
 // createFilter wraps a scan iterator with a Filter operator based on the provided WHERE clause.
 //
 // Parameters:
@@ -134,6 +255,10 @@ func getHeapFileForTable(ctx *registry.DatabaseContext, tableID primitives.FileI
 // - iterator.DbIterator: a filter iterator that wraps the scan and applies the predicate.
 // - error: non-nil if predicate construction or filter creation fails.
 func createFilter(scanOp iterator.DbIterator, whereClause *plan.FilterNode) (iterator.DbIterator, error) {
+	if whereClause.Expr != nil {
+		return createExpressionFilter(scanOp, whereClause.Expr)
+	}
+
 	predicate, err := buildPredicateFromFilterNode(whereClause, scanOp.GetTupleDesc())
 	if err != nil {
 		return nil, fmt.Errorf("failed to build WHERE predicate: %v", err)
@@ -146,3 +271,27 @@ func createFilter(scanOp iterator.DbIterator, whereClause *plan.FilterNode) (ite
 
 	return filterOp, nil
 }
+
+// createExpressionFilter wraps a scan iterator with a Filter operator that
+// evaluates a boolean expression, such as a condition involving CASE.
+//
+// Parameters:
+// - scanOp: the underlying scan iterator that produces tuples to be filtered.
+// - expr: the boolean WHERE expression.
+//
+// Returns:
+// - iterator.DbIterator: a filter iterator that keeps the tuples for which expr is true.
+// - error: non-nil if the expression cannot be bound to the scan's schema or is not boolean.
+func createExpressionFilter(scanOp iterator.DbIterator, expr plan.Expr) (iterator.DbIterator, error) {
+	bound, err := query.NewExpression(expr, scanOp.GetTupleDesc())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build WHERE expression: %v", err)
+	}
+
+	filterOp, err := query.NewExpressionFilter(bound, scanOp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create filter: %v", err)
+	}
+
+	return filterOp, nil
+}
@@ -0,0 +1,136 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/config"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/result"
+	"storemy/pkg/registry"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+var errNoSettings = errors.New("persistent settings are not available for this database")
+
+// ShowPersistentPlan represents the execution plan for SHOW PERSISTENT statement.
+// It reads the settings held in the database superblock and returns them as a
+// result set.
+//
+// Example:
+//
+//	SHOW PERSISTENT;                      -- Show all settings
+//	SHOW PERSISTENT checkpoint_interval;  -- Show a single setting
+type ShowPersistentPlan struct {
+	Statement *statements.ShowPersistentStatement // Parsed SHOW PERSISTENT statement
+	ctx       *registry.DatabaseContext           // Database context for settings access
+}
+
+// NewShowPersistentPlan creates a new SHOW PERSISTENT plan instance.
+func NewShowPersistentPlan(stmt *statements.ShowPersistentStatement, ctx *registry.DatabaseContext) *ShowPersistentPlan {
+	return &ShowPersistentPlan{
+		Statement: stmt,
+		ctx:       ctx,
+	}
+}
+
+// Execute returns the requested settings as a SelectQueryResult.
+func (p *ShowPersistentPlan) Execute() (result.Result, error) {
+	store := p.ctx.Settings()
+	if store == nil {
+		return nil, errNoSettings
+	}
+
+	var settings []config.Setting
+	if p.Statement.Name != "" {
+		s, err := store.Get(p.Statement.Name)
+		if err != nil {
+			return nil, err
+		}
+		settings = []config.Setting{s}
+	} else {
+		settings = store.All()
+	}
+
+	td := createSettingsSchema().TupleDesc
+	tuples := make([]*tuple.Tuple, 0, len(settings))
+	for _, s := range settings {
+		tuples = append(tuples, tuple.NewBuilder(td).
+			AddString(s.Name).
+			AddString(s.Value).
+			AddString(describeApplies(s)).
+			AddString(s.Description).
+			MustBuild())
+	}
+
+	return &result.SelectQueryResult{
+		TupleDesc: td,
+		Tuples:    tuples,
+	}, nil
+}
+
+// SetPersistentPlan represents the execution plan for SET PERSISTENT statement.
+// The new value is validated and written to the superblock atomically before
+// the statement returns.
+//
+// Example:
+//
+//	SET PERSISTENT checkpoint_interval = '5m';
+type SetPersistentPlan struct {
+	Statement *statements.SetPersistentStatement // Parsed SET PERSISTENT statement
+	ctx       *registry.DatabaseContext          // Database context for settings access
+}
+
+// NewSetPersistentPlan creates a new SET PERSISTENT plan instance.
+func NewSetPersistentPlan(stmt *statements.SetPersistentStatement, ctx *registry.DatabaseContext) *SetPersistentPlan {
+	return &SetPersistentPlan{
+		Statement: stmt,
+		ctx:       ctx,
+	}
+}
+
+// Execute persists the new setting value and reports it in a DDLResult.
+func (p *SetPersistentPlan) Execute() (result.Result, error) {
+	store := p.ctx.Settings()
+	if store == nil {
+		return nil, errNoSettings
+	}
+
+	s, err := store.Set(p.Statement.Name, p.Statement.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := fmt.Sprintf("Setting %s set to %s", s.Name, s.Value)
+	if s.RequiresRestart {
+		msg += " (takes effect after restart)"
+	}
+	return &result.DDLResult{
+		Success: true,
+		Message: msg,
+	}, nil
+}
+
+func createSettingsSchema() *schema.Schema {
+	sch, _ := schema.NewSchemaBuilder(systemtable.InvalidTableID, "show_persistent_result").
+		AddColumn("name", types.StringType).
+		AddColumn("value", types.StringType).
+		AddColumn("applies", types.StringType).
+		AddColumn("description", types.StringType).
+		Build()
+	return sch
+}
+
+// describeApplies reports when a change to the setting takes effect.
+func describeApplies(s config.Setting) string {
+	switch {
+	case s.ReadOnly:
+		return "fixed"
+	case s.RequiresRestart:
+		return "restart"
+	default:
+		return "immediate"
+	}
+}
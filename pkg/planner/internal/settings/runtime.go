@@ -0,0 +1,114 @@
+package settings
+
+import (
+	"fmt"
+	"storemy/pkg/config"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/result"
+	"storemy/pkg/registry"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runtimeSetting applies a new value to the running database and returns a
+// message describing the change.
+type runtimeSetting func(ctx *registry.DatabaseContext, value string) (string, error)
+
+// runtimeSettings are the settings SET changes on the running database. Unlike
+// the settings of SET PERSISTENT, they are not stored in the superblock and go
+// back to their defaults when the database is reopened.
+var runtimeSettings = map[string]runtimeSetting{
+	"buffer_pool_size":  setBufferPoolSize,
+	"wal_durability":    setWALDurability,
+	"wal_sync_interval": setWALSyncInterval,
+}
+
+// SetPlan represents the execution plan for SET statement. The new value
+// takes effect at once and lasts until the database is closed.
+//
+// Example:
+//
+//	SET buffer_pool_size = 5000;
+//	SET wal_durability = nosync;
+type SetPlan struct {
+	Statement *statements.SetStatement  // Parsed SET statement
+	ctx       *registry.DatabaseContext // Database context the setting applies to
+}
+
+// NewSetPlan creates a new SET plan instance.
+func NewSetPlan(stmt *statements.SetStatement, ctx *registry.DatabaseContext) *SetPlan {
+	return &SetPlan{
+		Statement: stmt,
+		ctx:       ctx,
+	}
+}
+
+// Execute applies the new setting value and reports it in a DDLResult.
+func (p *SetPlan) Execute() (result.Result, error) {
+	name := strings.ToLower(p.Statement.Name)
+	apply, ok := runtimeSettings[name]
+	if !ok {
+		err := config.NewUnknownSettingError(p.Statement.Name)
+		err.Hint = "SET changes buffer_pool_size, wal_durability and wal_sync_interval; use SET PERSISTENT for the settings listed by SHOW PERSISTENT"
+		return nil, err
+	}
+
+	msg, err := apply(p.ctx, p.Statement.Value)
+	if err != nil {
+		return nil, config.NewInvalidSettingError(name, p.Statement.Value, err)
+	}
+	return &result.DDLResult{
+		Success: true,
+		Message: msg,
+	}, nil
+}
+
+// setBufferPoolSize resizes the buffer pool to a number of pages.
+func setBufferPoolSize(ctx *registry.DatabaseContext, value string) (string, error) {
+	pages, err := strconv.Atoi(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid integer value: %s", value)
+	}
+
+	res, err := ctx.PageStore().SetCapacity(pages)
+	if err != nil {
+		return "", err
+	}
+
+	msg := fmt.Sprintf("Setting buffer_pool_size set to %d pages", res.NewCapacity)
+	if res.Evicted > 0 {
+		msg += fmt.Sprintf(" (%d pages evicted)", res.Evicted)
+	}
+	if res.Excess > 0 {
+		msg += fmt.Sprintf(" (%d dirty or locked pages above the limit are evicted once released)", res.Excess)
+	}
+	return msg, nil
+}
+
+// setWALDurability switches the durability level of the WAL, e.g. to nosync
+// for a bulk load and back to sync afterwards.
+func setWALDurability(ctx *registry.DatabaseContext, value string) (string, error) {
+	d, err := wal.ParseDurability(value)
+	if err != nil {
+		return "", err
+	}
+	if err := ctx.WAL().SetDurability(d); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Setting wal_durability set to %s", d), nil
+}
+
+// setWALSyncInterval changes how often the WAL is flushed under the interval
+// durability level.
+func setWALSyncInterval(ctx *registry.DatabaseContext, value string) (string, error) {
+	interval, err := time.ParseDuration(strings.ToLower(value))
+	if err != nil {
+		return "", fmt.Errorf("invalid duration value: %s", value)
+	}
+	if err := ctx.WAL().SetSyncInterval(interval); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Setting wal_sync_interval set to %s", interval), nil
+}
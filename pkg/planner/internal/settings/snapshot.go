@@ -0,0 +1,43 @@
+package settings
+
+import (
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/parser/statements"
+	"storemy/pkg/planner/internal/result"
+	"storemy/pkg/registry"
+)
+
+// SetTransactionSnapshotPlan represents the execution plan for SET
+// TRANSACTION SNAPSHOT. It makes the transaction read the snapshot another
+// transaction exported, so parallel readers such as dump workers each see
+// the same consistent state of the database.
+//
+// Example:
+//
+//	SET TRANSACTION SNAPSHOT '0000000A-00000001';
+type SetTransactionSnapshotPlan struct {
+	Statement *statements.SetTransactionSnapshotStatement // Parsed SET TRANSACTION SNAPSHOT statement
+	ctx       *registry.DatabaseContext                   // Database context for lock access
+	tx        *transaction.TransactionContext             // Transaction importing the snapshot
+}
+
+// NewSetTransactionSnapshotPlan creates a new SET TRANSACTION SNAPSHOT plan instance.
+func NewSetTransactionSnapshotPlan(stmt *statements.SetTransactionSnapshotStatement, tx *transaction.TransactionContext, ctx *registry.DatabaseContext) *SetTransactionSnapshotPlan {
+	return &SetTransactionSnapshotPlan{
+		Statement: stmt,
+		ctx:       ctx,
+		tx:        tx,
+	}
+}
+
+// Execute imports the snapshot and reports it in a DDLResult.
+func (p *SetTransactionSnapshotPlan) Execute() (result.Result, error) {
+	if err := p.ctx.PageStore().ImportSnapshot(p.tx, p.Statement.Token); err != nil {
+		return nil, err
+	}
+	return &result.DDLResult{
+		Success: true,
+		Message: fmt.Sprintf("Transaction reads snapshot %s", p.Statement.Token),
+	}, nil
+}
@@ -7,7 +7,9 @@ import (
 	"storemy/pkg/planner/internal/ddl"
 	"storemy/pkg/planner/internal/dml"
 	"storemy/pkg/planner/internal/indexops"
+	"storemy/pkg/planner/internal/params"
 	"storemy/pkg/planner/internal/result"
+	"storemy/pkg/planner/internal/settings"
 )
 
 // Plan represents an executable query plan that can be executed to produce a result.
@@ -18,19 +20,36 @@ type Plan interface {
 // QueryPlanner is responsible for converting parsed SQL statements into executable plans.
 // It uses the database context to access schema information and transaction context for execution.
 type QueryPlanner struct {
-	ctx DbContext
+	ctx    DbContext
+	logger logging.ComponentLogger
 }
 
 // NewQueryPlanner creates a new QueryPlanner instance with the provided database context.
 func NewQueryPlanner(ctx DbContext) *QueryPlanner {
 	return &QueryPlanner{
-		ctx: ctx,
+		ctx:    ctx,
+		logger: logging.ForComponent("query_planner"),
 	}
 }
 
+// SetLogger replaces the logger the planner reports planned statements to.
+func (qp *QueryPlanner) SetLogger(logger logging.ComponentLogger) {
+	qp.logger = logger
+}
+
+// Bind binds args to the bind parameters ($1, $2, ... or ?) of a parsed
+// statement, inferring each parameter's type from the column it is compared
+// with or assigned to. It must be called before Plan, also when args is
+// empty, so a statement with unbound parameters is rejected.
+func (qp *QueryPlanner) Bind(stmt statements.Statement, args []any, tx TxContext) error {
+	return params.Bind(stmt, args, tx, qp.ctx)
+}
+
 // Plan converts a parsed SQL statement into an executable plan.
-// It supports DDL operations (CREATE TABLE, DROP TABLE, CREATE INDEX, DROP INDEX),
-// DML operations (INSERT, DELETE, SELECT, UPDATE), and utility operations (SHOW INDEXES).
+// It supports DDL operations (CREATE TABLE, CREATE FOREIGN TABLE, DROP TABLE, CREATE INDEX, DROP INDEX,
+// CREATE TRIGGER, DROP TRIGGER),
+// DML operations (INSERT, DELETE, SELECT, UPDATE, COPY), and utility operations
+// (SHOW INDEXES, SHOW PERSISTENT, SET PERSISTENT, CHECKSUM TABLE).
 //
 // Parameters:
 //   - stmt: The parsed SQL statement to plan
@@ -40,7 +59,7 @@ func NewQueryPlanner(ctx DbContext) *QueryPlanner {
 //   - Plan: An executable plan for the statement
 //   - error: An error if the statement type is unsupported
 func (qp *QueryPlanner) Plan(stmt statements.Statement, tx TxContext) (Plan, error) {
-	log := logging.WithTx(int(tx.ID.ID())).With("component", "query_planner")
+	log := qp.logger.With("tx_id", int(tx.ID.ID()))
 
 	var stmtType string
 	switch s := stmt.(type) {
@@ -48,6 +67,10 @@ func (qp *QueryPlanner) Plan(stmt statements.Statement, tx TxContext) (Plan, err
 		stmtType = "CREATE_TABLE"
 		log.Info("planning query", "statement_type", stmtType, "table", s.TableName)
 		return ddl.NewCreateTablePlan(s, qp.ctx, tx), nil
+	case *statements.CreateForeignTableStatement:
+		stmtType = "CREATE_FOREIGN_TABLE"
+		log.Info("planning query", "statement_type", stmtType, "table", s.TableName)
+		return ddl.NewCreateForeignTablePlan(s, qp.ctx, tx), nil
 	case *statements.DropStatement:
 		stmtType = "DROP_TABLE"
 		log.Info("planning query", "statement_type", stmtType, "table", s.TableName)
@@ -60,10 +83,30 @@ func (qp *QueryPlanner) Plan(stmt statements.Statement, tx TxContext) (Plan, err
 		stmtType = "DROP_INDEX"
 		log.Info("planning query", "statement_type", stmtType, "index", s.IndexName)
 		return indexops.NewDropIndexPlan(s, qp.ctx, tx), nil
+	case *statements.CreateTriggerStatement:
+		stmtType = "CREATE_TRIGGER"
+		log.Info("planning query", "statement_type", stmtType, "trigger", s.TriggerName, "table", s.TableName)
+		return ddl.NewCreateTriggerPlan(s, qp.ctx, tx), nil
+	case *statements.DropTriggerStatement:
+		stmtType = "DROP_TRIGGER"
+		log.Info("planning query", "statement_type", stmtType, "trigger", s.TriggerName)
+		return ddl.NewDropTriggerPlan(s, qp.ctx, tx), nil
+	case *statements.AlterTableAuditStatement:
+		stmtType = "ALTER_TABLE_AUDIT"
+		log.Info("planning query", "statement_type", stmtType, "table", s.TableName, "enable", s.Enable)
+		return ddl.NewAlterTableAuditPlan(s, qp.ctx, tx), nil
+	case *statements.AlterTableCacheStatement:
+		stmtType = "ALTER_TABLE_CACHE"
+		log.Info("planning query", "statement_type", stmtType, "table", s.TableName, "priority", s.Priority)
+		return ddl.NewAlterTableCachePlan(s, qp.ctx, tx), nil
 	case *statements.InsertStatement:
 		stmtType = "INSERT"
 		log.Info("planning query", "statement_type", stmtType, "table", s.TableName, "num_rows", len(s.Values))
 		return dml.NewInsertPlan(s, tx, qp.ctx), nil
+	case *statements.CopyStatement:
+		stmtType = "COPY"
+		log.Info("planning query", "statement_type", stmtType, "table", s.TableName, "path", s.Path)
+		return dml.NewCopyPlan(s, tx, qp.ctx), nil
 	case *statements.DeleteStatement:
 		stmtType = "DELETE"
 		log.Info("planning query", "statement_type", stmtType, "table", s.TableName)
@@ -80,10 +123,32 @@ func (qp *QueryPlanner) Plan(stmt statements.Statement, tx TxContext) (Plan, err
 		stmtType = "SHOW_INDEXES"
 		log.Info("planning query", "statement_type", stmtType, "table", s.TableName)
 		return indexops.NewShowIndexesPlan(s, qp.ctx, tx), nil
+	case *statements.ShowPersistentStatement:
+		stmtType = "SHOW_PERSISTENT"
+		log.Info("planning query", "statement_type", stmtType, "setting", s.Name)
+		return settings.NewShowPersistentPlan(s, qp.ctx), nil
+	case *statements.SetPersistentStatement:
+		stmtType = "SET_PERSISTENT"
+		log.Info("planning query", "statement_type", stmtType, "setting", s.Name)
+		return settings.NewSetPersistentPlan(s, qp.ctx), nil
+	case *statements.SetStatement:
+		stmtType = "SET"
+		log.Info("planning query", "statement_type", stmtType, "setting", s.Name)
+		return settings.NewSetPlan(s, qp.ctx), nil
+	case *statements.SetTransactionSnapshotStatement:
+		stmtType = "SET_TRANSACTION_SNAPSHOT"
+		log.Info("planning query", "statement_type", stmtType, "snapshot", s.Token)
+		return settings.NewSetTransactionSnapshotPlan(s, tx, qp.ctx), nil
+	case *statements.ChecksumTableStatement:
+		stmtType = "CHECKSUM_TABLE"
+		log.Info("planning query", "statement_type", stmtType, "tables", s.TableNames)
+		return dml.NewChecksumTablePlan(s, tx, qp.ctx), nil
 	case *statements.ExplainStatement:
 		stmtType = "EXPLAIN"
 		log.Info("planning query", "statement_type", stmtType)
-		return NewExplainPlan(s, qp.ctx, tx), nil
+		plan := NewExplainPlan(s, qp.ctx, tx)
+		plan.logger = qp.logger
+		return plan, nil
 	default:
 		log.Error("unsupported statement type", "type", fmt.Sprintf("%T", stmt))
 		return nil, fmt.Errorf("unsupported statement type: %T", stmt)
@@ -28,6 +28,12 @@ const (
 
 	// Like represents the LIKE operator for pattern matching
 	Like
+
+	// ILike represents the ILIKE operator, a case-insensitive LIKE
+	ILike
+
+	// Regexp represents the REGEXP operator for regular expression matching
+	Regexp
 )
 
 // String returns the SQL string representation of the predicate operator.
@@ -58,7 +64,19 @@ func (p Predicate) String() string {
 	case Like:
 		return "LIKE"
 
+	case ILike:
+		return "ILIKE"
+
+	case Regexp:
+		return "REGEXP"
+
 	default:
 		return "UNKNOWN"
 	}
 }
+
+// IsPatternMatch reports whether the predicate matches a string against a
+// pattern (LIKE, ILIKE or REGEXP) rather than comparing two values.
+func (p Predicate) IsPatternMatch() bool {
+	return p == Like || p == ILike || p == Regexp
+}
@@ -21,3 +21,16 @@ type PageID interface {
 	// HashCode returns a hash code for this page ID
 	HashCode() HashCode
 }
+
+// PageKey identifies a page by value: its file and page number. Unlike a
+// PageID it is comparable, so it can key a map, and unlike a HashCode two
+// distinct pages never share one.
+type PageKey struct {
+	FileID FileID
+	PageNo PageNumber
+}
+
+// KeyOf returns the PageKey of pid.
+func KeyOf(pid PageID) PageKey {
+	return PageKey{FileID: pid.FileID(), PageNo: pid.PageNo()}
+}
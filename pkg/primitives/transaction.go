@@ -17,6 +17,26 @@ func NewTransactionID() *TransactionID {
 	}
 }
 
+// LastTransactionID returns the highest transaction ID allocated so far, or 0
+// if none has been.
+func LastTransactionID() int64 {
+	return atomic.LoadInt64(&transactionCounter)
+}
+
+// RestoreTransactionIDs makes every transaction ID allocated from now on
+// greater than highWater. It is called on startup with the highest ID found in
+// the log, so IDs keep growing across restarts instead of colliding with those
+// of transactions whose records are still there. The allocator never moves
+// backwards: a highWater below the last allocated ID has no effect.
+func RestoreTransactionIDs(highWater int64) {
+	for {
+		last := atomic.LoadInt64(&transactionCounter)
+		if last >= highWater || atomic.CompareAndSwapInt64(&transactionCounter, last, highWater) {
+			return
+		}
+	}
+}
+
 // NewTransactionIDFromValue creates a TransactionID with a specific ID value.
 // This is primarily used for deserialization purposes.
 func NewTransactionIDFromValue(id int64) *TransactionID {
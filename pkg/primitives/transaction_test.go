@@ -0,0 +1,18 @@
+package primitives
+
+import "testing"
+
+func TestRestoreTransactionIDs(t *testing.T) {
+	highWater := LastTransactionID() + 100
+	RestoreTransactionIDs(highWater)
+
+	if tid := NewTransactionID(); tid.ID() != highWater+1 {
+		t.Errorf("expected ID %d after restoring to %d, got %d", highWater+1, highWater, tid.ID())
+	}
+
+	// A lower high-water mark never moves the allocator backwards
+	RestoreTransactionIDs(1)
+	if tid := NewTransactionID(); tid.ID() != highWater+2 {
+		t.Errorf("expected ID %d, got %d", highWater+2, tid.ID())
+	}
+}
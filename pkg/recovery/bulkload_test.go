@@ -0,0 +1,73 @@
+package recovery
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"testing"
+)
+
+func TestRecover_UndoesUncommittedBulkLoads(t *testing.T) {
+	testWAL, walPath := createTestWAL(t)
+	defer testWAL.Close()
+
+	dir := filepath.Dir(walPath)
+	path := func(name string) primitives.Filepath { return primitives.Filepath(filepath.Join(dir, name)) }
+	loaded := bytes.Repeat([]byte{0xAB}, 4*page.PageSize)
+	for _, name := range []string{"a.dat", "b.dat"} {
+		if err := os.WriteFile(string(path(name)), loaded, 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	// tid1 loaded pages 1-2 of a.dat and committed.
+	tid1 := primitives.NewTransactionIDFromValue(1)
+	testWAL.LogBegin(tid1)
+	load := record.BulkLoad{Path: path("a.dat"), StartPage: 1}
+	if _, err := testWAL.LogBulkLoad(tid1, load); err != nil {
+		t.Fatalf("LogBulkLoad failed: %v", err)
+	}
+	load.EndPage = 3
+	if _, err := testWAL.LogBulkLoadBarrier(tid1, load); err != nil {
+		t.Fatalf("LogBulkLoadBarrier failed: %v", err)
+	}
+	testWAL.LogCommit(tid1)
+
+	// tid2 crashed while loading b.dat from page 2.
+	tid2 := primitives.NewTransactionIDFromValue(2)
+	testWAL.LogBegin(tid2)
+	if _, err := testWAL.LogBulkLoad(tid2, record.BulkLoad{Path: path("b.dat"), StartPage: 2}); err != nil {
+		t.Fatalf("LogBulkLoad failed: %v", err)
+	}
+
+	rm := newTestRecoveryManager(testWAL, walPath)
+	if err := rm.Recover(); err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(string(path("a.dat"))); !bytes.Equal(data, loaded) {
+		t.Error("expected the committed load of a.dat to be kept")
+	}
+	data, _ := os.ReadFile(string(path("b.dat")))
+	if !bytes.Equal(data[:2*page.PageSize], loaded[:2*page.PageSize]) {
+		t.Error("expected the pages of b.dat before the load to be kept")
+	}
+	if !bytes.Equal(data[2*page.PageSize:], make([]byte, 2*page.PageSize)) {
+		t.Error("expected the pages of the uncommitted load of b.dat to be zeroed")
+	}
+	if stats := rm.GetStats(); stats.BulkLoadsUndone != 1 {
+		t.Errorf("expected 1 bulk load undone, got %d", stats.BulkLoadsUndone)
+	}
+
+	// tid2 is now aborted, so a second recovery has nothing to undo.
+	rm = newTestRecoveryManager(testWAL, walPath)
+	if err := rm.Recover(); err != nil {
+		t.Fatalf("second Recover failed: %v", err)
+	}
+	if stats := rm.GetStats(); stats.BulkLoadsUndone != 0 {
+		t.Errorf("expected no bulk load to undo, got %d", stats.BulkLoadsUndone)
+	}
+}
@@ -0,0 +1,35 @@
+package recovery
+
+import (
+	"fmt"
+	dberror "storemy/pkg/error"
+)
+
+// Error codes of the DBErrors returned by recovery
+const (
+	// ErrCodeRecoveryFailed indicates a phase of recovery could not complete
+	ErrCodeRecoveryFailed = "RECOVERY_FAILED"
+
+	// ErrCodeRecoveryCorrupt indicates the log holds a record recovery
+	// cannot make sense of
+	ErrCodeRecoveryCorrupt = "RECOVERY_CORRUPT"
+)
+
+// newRecoveryError creates a DBError for a step of recovery that failed
+// because of cause.
+func newRecoveryError(cause error, format string, args ...any) *dberror.DBError {
+	err := dberror.New(dberror.ErrCategorySystem, ErrCodeRecoveryFailed, fmt.Sprintf(format, args...))
+	err.Cause = cause
+	err.Component = "RecoveryManager"
+	err.Hint = "Fix the cause and open the database again; recovery resumes from the last checkpoint"
+	return err
+}
+
+// newCorruptLogError creates a DBError for a log record recovery cannot
+// process.
+func newCorruptLogError(format string, args ...any) *dberror.DBError {
+	err := dberror.New(dberror.ErrCategoryData, ErrCodeRecoveryCorrupt, fmt.Sprintf(format, args...))
+	err.Component = "RecoveryManager"
+	err.Hint = "The log may be damaged; validate it and restore from backup if it cannot be repaired"
+	return err
+}
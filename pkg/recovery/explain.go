@@ -0,0 +1,308 @@
+package recovery
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+// RecordExplanation says what recovery would do with one WAL record and why.
+type RecordExplanation struct {
+	LSN    primitives.LSN
+	Type   record.LogRecordType
+	TxID   int64             // 0 for checkpoint records
+	PageID primitives.PageID // Nil for records that do not touch a page
+	Redone bool              // Replayed by the redo phase
+	Undone bool              // Rolled back by the undo phase, with a CLR
+	Reason string
+}
+
+// Outcome summarizes the record's fate: KEPT, REDONE, UNDONE, or
+// REDONE+UNDONE for a loser's change whose history is first repeated.
+func (e RecordExplanation) Outcome() string {
+	switch {
+	case e.Redone && e.Undone:
+		return "REDONE+UNDONE"
+	case e.Redone:
+		return "REDONE"
+	case e.Undone:
+		return "UNDONE"
+	default:
+		return "KEPT"
+	}
+}
+
+// TransactionExplanation is a transaction as the analysis phase sees it.
+type TransactionExplanation struct {
+	TxID     int64
+	Status   TransactionStatus
+	LastLSN  primitives.LSN
+	UndoLSNs []primitives.LSN // Records the undo phase rolls back, in order
+}
+
+// Loser reports whether the transaction was still active at the crash, so
+// the undo phase rolls it back.
+func (t TransactionExplanation) Loser() bool {
+	return t.Status == TxnActive
+}
+
+// Explanation is an annotated dry run of ARIES recovery over a WAL: what the
+// analysis phase learns, and which records redo and undo would touch.
+type Explanation struct {
+	CheckpointLSN primitives.LSN // 0 if the log has no checkpoint
+	RecordsRead   int
+	DirtyPages    int
+	RedoStartLSN  primitives.LSN // Smallest LSN in the dirty page table
+	Transactions  []TransactionExplanation
+	Records       []RecordExplanation
+}
+
+// Explain runs recovery as a dry run and narrates it record by record. The
+// analysis phase runs as in Recover, so the dirty page and transaction
+// tables are left as GetDirtyPageTable and GetTransactionTable report them,
+// but redo and undo only decide what they would do: no page is changed and
+// no CLR or ABORT record is written, so Explain is safe on a live or
+// read-only WAL. It is a teaching aid for how ARIES repeats history and
+// then rolls back losers.
+func (rm *RecoveryManager) Explain() (*Explanation, error) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	stats := rm.stats
+	defer func() { rm.stats = stats }()
+
+	if err := rm.analysisPhase(); err != nil {
+		return nil, newRecoveryError(err, "analysis phase failed")
+	}
+
+	exp := &Explanation{DirtyPages: len(rm.dirtyPageTable)}
+	if checkpoint, err := rm.wal.GetLastCheckpoint(); err == nil && checkpoint != nil {
+		exp.CheckpointLSN = checkpoint.LSN
+	}
+	for _, lsn := range rm.dirtyPageTable {
+		if exp.RedoStartLSN == 0 || lsn < exp.RedoStartLSN {
+			exp.RedoStartLSN = lsn
+		}
+	}
+
+	reader, err := rm.openLogReader()
+	if err != nil {
+		return nil, newRecoveryError(err, "failed to create WAL reader")
+	}
+	defer reader.Close()
+
+	var records []*record.LogRecord
+	recordMap := make(map[primitives.LSN]*record.LogRecord)
+	for {
+		rec, err := reader.ReadNext()
+		if err != nil {
+			break
+		}
+		records = append(records, rec)
+		recordMap[rec.LSN] = rec
+	}
+	exp.RecordsRead = len(records)
+
+	lookup := func(lsn primitives.LSN) (*record.LogRecord, bool, error) {
+		rec, ok := recordMap[lsn]
+		return rec, ok, nil
+	}
+	undone := make(map[primitives.LSN]bool)
+	for _, txnInfo := range rm.transactionTable {
+		txn := TransactionExplanation{TxID: txnInfo.TID.ID(), Status: txnInfo.Status, LastLSN: txnInfo.LastLSN}
+		if txn.Loser() {
+			chain, err := undoChain(txnInfo, lookup)
+			if err != nil {
+				return nil, err
+			}
+			for _, rec := range chain {
+				txn.UndoLSNs = append(txn.UndoLSNs, rec.LSN)
+				undone[rec.LSN] = true
+			}
+		}
+		exp.Transactions = append(exp.Transactions, txn)
+	}
+	slices.SortFunc(exp.Transactions, func(a, b TransactionExplanation) int {
+		return cmp.Compare(a.TxID, b.TxID)
+	})
+
+	for _, rec := range records {
+		exp.Records = append(exp.Records, rm.explainRecord(rec, exp.CheckpointLSN, undone[rec.LSN]))
+	}
+	return exp, nil
+}
+
+// explainRecord decides the fate of one record given the analysis results.
+func (rm *RecoveryManager) explainRecord(rec *record.LogRecord, checkpointLSN primitives.LSN, undone bool) RecordExplanation {
+	e := RecordExplanation{LSN: rec.LSN, Type: rec.Type, PageID: rec.PageID, Undone: undone}
+	if rec.TID != nil {
+		e.TxID = rec.TID.ID()
+	}
+
+	var status TransactionStatus
+	if txnInfo, ok := rm.transactionTable[e.TxID]; ok {
+		status = txnInfo.Status
+	}
+
+	switch rec.Type {
+	case record.CheckpointBegin, record.CheckpointEnd:
+		e.Reason = "checkpoint: analysis starts from the last one"
+		return e
+	case record.BeginRecord:
+		e.Reason = fmt.Sprintf("T%d starts", e.TxID)
+		return e
+	case record.CommitRecord:
+		e.Reason = fmt.Sprintf("T%d committed: a winner, its changes must survive", e.TxID)
+		return e
+	case record.AbortRecord:
+		e.Reason = fmt.Sprintf("T%d aborted: its rollback was finished before the crash", e.TxID)
+		return e
+	}
+
+	if rec.Type == record.BulkLoadRecord || rec.Type == record.BulkLoadBarrierRecord {
+		e.Reason = "redo: bulk loaded pages are not logged, the file was synced before the barrier"
+		switch {
+		case undone:
+			e.Reason += fmt.Sprintf("; undo: T%d never committed, so its loaded pages are zeroed", e.TxID)
+		case status == TxnCommitted && rec.Type == record.BulkLoadBarrierRecord:
+			e.Reason += fmt.Sprintf("; undo: T%d committed, so the table is consistent up to page %d", e.TxID, rec.BulkLoad.EndPage)
+		case status == TxnCommitted:
+			e.Reason += fmt.Sprintf("; undo: T%d committed, so the loaded pages are kept", e.TxID)
+		case status == TxnAborted:
+			e.Reason += fmt.Sprintf("; undo: T%d already zeroed its loaded pages", e.TxID)
+		}
+		return e
+	}
+
+	if rec.LSN < checkpointLSN && rec.Type != record.CLRRecord {
+		e.Reason = "before the checkpoint: "
+	}
+
+	var redoReason string
+	e.Redone, redoReason = rm.redoDecision(rec)
+	e.Reason += "redo: " + redoReason
+
+	switch {
+	case rec.Type == record.CLRRecord:
+		e.Reason += "; undo: a CLR records an undo already done and is never undone"
+	case undone:
+		e.Reason += fmt.Sprintf("; undo: T%d never committed, so the change is rolled back and a CLR written", e.TxID)
+	case status == TxnCommitted:
+		e.Reason += fmt.Sprintf("; undo: T%d committed, so the change is kept", e.TxID)
+	case status == TxnAborted:
+		e.Reason += fmt.Sprintf("; undo: T%d already rolled back", e.TxID)
+	}
+	return e
+}
+
+// String renders the explanation as a narrative of the three phases, with
+// one line per WAL record:
+//
+//	Analysis: read 4 records, no checkpoint; 1 dirty page, redo starts at LSN 0
+//	  T1 committed (winner)
+//	  T2 active at crash (loser): undo rolls back LSN 120
+//	LSN   TX  RECORD  PAGE  OUTCOME        WHY
+//	0     T1  BEGIN   -     KEPT           T1 starts
+//	...
+func (exp *Explanation) String() string {
+	var sb strings.Builder
+
+	checkpoint := "no checkpoint"
+	if exp.CheckpointLSN != 0 {
+		checkpoint = fmt.Sprintf("checkpoint at LSN %d", exp.CheckpointLSN)
+	}
+	sb.WriteString(fmt.Sprintf("Analysis: read %d records, %s; %d dirty page(s)", exp.RecordsRead, checkpoint, exp.DirtyPages))
+	if exp.DirtyPages > 0 {
+		sb.WriteString(fmt.Sprintf(", redo starts at LSN %d", exp.RedoStartLSN))
+	}
+	sb.WriteString("\n")
+
+	for _, txn := range exp.Transactions {
+		switch txn.Status {
+		case TxnCommitted:
+			sb.WriteString(fmt.Sprintf("  T%d committed (winner)\n", txn.TxID))
+		case TxnAborted:
+			sb.WriteString(fmt.Sprintf("  T%d aborted before the crash\n", txn.TxID))
+		default:
+			lsns := make([]string, len(txn.UndoLSNs))
+			for i, lsn := range txn.UndoLSNs {
+				lsns[i] = fmt.Sprintf("%d", lsn)
+			}
+			undo := "nothing to roll back"
+			if len(lsns) > 0 {
+				undo = "undo rolls back LSN " + strings.Join(lsns, ", ")
+			}
+			sb.WriteString(fmt.Sprintf("  T%d active at crash (loser): %s, then logs ABORT\n", txn.TxID, undo))
+		}
+	}
+
+	if len(exp.Records) == 0 {
+		sb.WriteString("The WAL is empty: nothing to recover\n")
+		return sb.String()
+	}
+
+	rows := [][]string{{"LSN", "TX", "RECORD", "PAGE", "OUTCOME", "WHY"}}
+	for _, e := range exp.Records {
+		tx, page := "-", "-"
+		if e.TxID != 0 {
+			tx = fmt.Sprintf("T%d", e.TxID)
+		}
+		if e.PageID != nil {
+			page = fmt.Sprintf("%d:%d", e.PageID.FileID(), e.PageID.PageNo())
+		}
+		rows = append(rows, []string{fmt.Sprintf("%d", e.LSN), tx, recordTypeName(e.Type), page, e.Outcome(), e.Reason})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for _, row := range rows {
+		for i, cell := range row[:len(row)-1] {
+			sb.WriteString(fmt.Sprintf("%-*s  ", widths[i], cell))
+		}
+		sb.WriteString(row[len(row)-1])
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// recordTypeName returns the short name of a log record type.
+func recordTypeName(t record.LogRecordType) string {
+	switch t {
+	case record.BeginRecord:
+		return "BEGIN"
+	case record.CommitRecord:
+		return "COMMIT"
+	case record.AbortRecord:
+		return "ABORT"
+	case record.UpdateRecord:
+		return "UPDATE"
+	case record.InsertRecord:
+		return "INSERT"
+	case record.DeleteRecord:
+		return "DELETE"
+	case record.CheckpointBegin:
+		return "CKPT_BEGIN"
+	case record.CheckpointEnd:
+		return "CKPT_END"
+	case record.CLRRecord:
+		return "CLR"
+	case record.FileOpRecord:
+		return "FILE_OP"
+	case record.FileOpDoneRecord:
+		return "FILE_OP_DONE"
+	case record.BulkLoadRecord:
+		return "BULK_LOAD"
+	case record.BulkLoadBarrierRecord:
+		return "BULK_LOAD_BARRIER"
+	default:
+		return fmt.Sprintf("TYPE(%d)", t)
+	}
+}
@@ -0,0 +1,105 @@
+package recovery
+
+import (
+	"strings"
+	"testing"
+
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+func TestExplain_WinnerAndLoser(t *testing.T) {
+	testWAL, walPath := createTestWAL(t)
+	defer testWAL.Close()
+
+	winner := primitives.NewTransactionID()
+	loser := primitives.NewTransactionID()
+
+	testWAL.LogBegin(winner)
+	testWAL.LogUpdate(winner, newMockPageID(1), []byte("old"), []byte("new"))
+	testWAL.LogCommit(winner)
+	testWAL.LogBegin(loser)
+	insertLSN, _ := testWAL.LogInsert(loser, newMockPageID(2), []byte("row"))
+	updateLSN, _ := testWAL.LogUpdate(loser, newMockPageID(1), []byte("new"), []byte("newer"))
+
+	rm := newTestRecoveryManager(testWAL, walPath)
+	exp, err := rm.Explain()
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+
+	if exp.RecordsRead != 6 || len(exp.Records) != 6 {
+		t.Fatalf("Expected 6 records, got %d (%d explained)", exp.RecordsRead, len(exp.Records))
+	}
+
+	var got []string
+	for _, e := range exp.Records {
+		got = append(got, recordTypeName(e.Type)+" "+e.Outcome())
+	}
+	want := []string{"BEGIN KEPT", "UPDATE REDONE", "COMMIT KEPT", "BEGIN KEPT", "INSERT REDONE+UNDONE", "UPDATE REDONE+UNDONE"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected outcomes %v, got %v", want, got)
+	}
+
+	if len(exp.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got %d", len(exp.Transactions))
+	}
+	for _, txn := range exp.Transactions {
+		if txn.TxID == winner.ID() && txn.Loser() {
+			t.Error("Committed transaction reported as a loser")
+		}
+		if txn.TxID == loser.ID() {
+			if !txn.Loser() {
+				t.Error("Uncommitted transaction not reported as a loser")
+			}
+			if len(txn.UndoLSNs) != 2 || txn.UndoLSNs[0] != updateLSN || txn.UndoLSNs[1] != insertLSN {
+				t.Errorf("Expected undo of LSN %d then %d, got %v", updateLSN, insertLSN, txn.UndoLSNs)
+			}
+		}
+	}
+
+	// A dry run must not touch the log or the statistics
+	if rm.stats != (RecoveryStats{}) {
+		t.Errorf("Explain changed the recovery statistics: %+v", rm.stats)
+	}
+	if needed, _ := rm.IsRecoveryNeeded(); !needed {
+		t.Error("Explain logged an ABORT for the loser")
+	}
+
+	narrative := exp.String()
+	for _, s := range []string{"no checkpoint", "(winner)", "(loser)", "never committed"} {
+		if !strings.Contains(narrative, s) {
+			t.Errorf("Expected narrative to mention %q:\n%s", s, narrative)
+		}
+	}
+}
+
+func TestExplain_EmptyWAL(t *testing.T) {
+	testWAL, walPath := createTestWAL(t)
+	defer testWAL.Close()
+
+	exp, err := newTestRecoveryManager(testWAL, walPath).Explain()
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if len(exp.Records) != 0 || !strings.Contains(exp.String(), "nothing to recover") {
+		t.Errorf("Expected an empty explanation, got:\n%s", exp.String())
+	}
+}
+
+func TestExplainRecord_NotRedone(t *testing.T) {
+	rm := newTestRecoveryManager(nil, "")
+	tid := primitives.NewTransactionID()
+	rm.transactionTable[tid.ID()] = &TransactionInfo{TID: tid, Status: TxnCommitted}
+	rm.dirtyPageTable[primitives.KeyOf(newMockPageID(1))] = 10
+
+	rec := &record.LogRecord{LSN: 5, Type: record.UpdateRecord, TID: tid, PageID: newMockPageID(1)}
+	if e := rm.explainRecord(rec, 0, false); e.Redone || !strings.Contains(e.Reason, "clean until LSN 10") {
+		t.Errorf("Expected a change before the page's first dirtying LSN to be skipped, got %+v", e)
+	}
+
+	rec = &record.LogRecord{LSN: 20, Type: record.DeleteRecord, TID: tid, PageID: newMockPageID(1)}
+	if e := rm.explainRecord(rec, 0, false); e.Outcome() != "KEPT" || !strings.Contains(e.Reason, "committed") {
+		t.Errorf("Expected a committed delete to be kept, got %+v", e)
+	}
+}
@@ -0,0 +1,90 @@
+package recovery
+
+import (
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+)
+
+// pendingFileOp is a FILE OP record without a matching DONE record.
+type pendingFileOp struct {
+	lsn primitives.LSN
+	tid *primitives.TransactionID
+	op  record.FileOperation
+}
+
+// fileOpPhase resolves the file operations that were announced but never
+// marked done. A transaction performs its file operations only after its
+// COMMIT is durable, so those of a committed transaction are finished now,
+// and those of any other transaction never touched the disk and are
+// dropped. Each is then closed with a DONE record.
+//
+// A file operation may be pending long before the last checkpoint, so the
+// whole log is scanned rather than the part analysis reads.
+func (rm *RecoveryManager) fileOpPhase() error {
+	pending, committed, err := rm.scanFileOps()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	rm.logger.Info("finishing file operations", "pending", len(pending))
+
+	for _, p := range pending {
+		if committed[p.tid.ID()] {
+			if err := rm.wal.FinishFileOp(p.tid, p.lsn, p.op); err != nil {
+				return newRecoveryError(err, "failed to finish %s logged at LSN %d", p.op, p.lsn)
+			}
+			rm.logger.Info("finished file operation", "lsn", p.lsn, "tx_id", p.tid.ID(), "op", p.op.String())
+			rm.stats.FileOpsFinished++
+			continue
+		}
+
+		if _, err := rm.wal.LogFileOpDone(p.tid, p.lsn); err != nil {
+			return newRecoveryError(err, "failed to drop %s logged at LSN %d", p.op, p.lsn)
+		}
+		rm.logger.Debug("dropped file operation of uncommitted transaction", "lsn", p.lsn, "tx_id", p.tid.ID())
+		rm.stats.FileOpsDropped++
+	}
+
+	return nil
+}
+
+// scanFileOps reads the whole log and returns, in log order, the FILE OP
+// records that have no DONE record, along with the transactions that
+// committed.
+func (rm *RecoveryManager) scanFileOps() ([]pendingFileOp, map[int64]bool, error) {
+	reader, err := rm.openLogReader()
+	if err != nil {
+		return nil, nil, newRecoveryError(err, "failed to create WAL reader")
+	}
+	defer reader.Close()
+
+	var ops []pendingFileOp
+	done := make(map[primitives.LSN]bool)
+	committed := make(map[int64]bool)
+	for {
+		rec, err := reader.ReadNext()
+		if err != nil {
+			break
+		}
+		switch {
+		case rec.TID == nil:
+		case rec.Type == record.FileOpRecord:
+			ops = append(ops, pendingFileOp{lsn: rec.LSN, tid: rec.TID, op: rec.FileOp})
+		case rec.Type == record.FileOpDoneRecord:
+			done[rec.PrevLSN] = true
+		case rec.Type == record.CommitRecord:
+			committed[rec.TID.ID()] = true
+		}
+	}
+
+	pending := ops[:0]
+	for _, op := range ops {
+		if !done[op.lsn] {
+			pending = append(pending, op)
+		}
+	}
+	return pending, committed, nil
+}
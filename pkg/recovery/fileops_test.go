@@ -0,0 +1,69 @@
+package recovery
+
+import (
+	"os"
+	"path/filepath"
+	"storemy/pkg/log/record"
+	"storemy/pkg/primitives"
+	"testing"
+)
+
+func TestRecover_FinishesCommittedFileOps(t *testing.T) {
+	testWAL, walPath := createTestWAL(t)
+	defer testWAL.Close()
+
+	dir := filepath.Dir(walPath)
+	path := func(name string) primitives.Filepath { return primitives.Filepath(filepath.Join(dir, name)) }
+	for _, name := range []string{"a.dat", "b.dat", "c.dat"} {
+		if err := os.WriteFile(string(path(name)), []byte(name), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	// tid1 committed but crashed before performing its file operations.
+	tid1 := primitives.NewTransactionIDFromValue(1)
+	testWAL.LogBegin(tid1)
+	if _, err := testWAL.LogFileOp(tid1, record.FileOperation{Kind: record.FileOpDelete, Path: path("a.dat")}); err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+	if _, err := testWAL.LogFileOp(tid1, record.FileOperation{Kind: record.FileOpRename, Path: path("c.dat"), NewPath: path("d.dat")}); err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+	testWAL.LogCommit(tid1)
+
+	// tid2 never committed, so its file must be kept.
+	tid2 := primitives.NewTransactionIDFromValue(2)
+	testWAL.LogBegin(tid2)
+	if _, err := testWAL.LogFileOp(tid2, record.FileOperation{Kind: record.FileOpDelete, Path: path("b.dat")}); err != nil {
+		t.Fatalf("LogFileOp failed: %v", err)
+	}
+
+	rm := newTestRecoveryManager(testWAL, walPath)
+	if err := rm.Recover(); err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+
+	if _, err := os.Stat(string(path("a.dat"))); !os.IsNotExist(err) {
+		t.Errorf("expected a.dat to be deleted, got %v", err)
+	}
+	if _, err := os.Stat(string(path("d.dat"))); err != nil {
+		t.Errorf("expected c.dat to be renamed to d.dat, got %v", err)
+	}
+	if _, err := os.Stat(string(path("b.dat"))); err != nil {
+		t.Errorf("expected b.dat of the uncommitted transaction to be kept, got %v", err)
+	}
+
+	stats := rm.GetStats()
+	if stats.FileOpsFinished != 2 || stats.FileOpsDropped != 1 {
+		t.Errorf("expected 2 finished and 1 dropped file operations, got %d and %d", stats.FileOpsFinished, stats.FileOpsDropped)
+	}
+
+	// Every operation now has a DONE record, so a second recovery has nothing to do.
+	rm = newTestRecoveryManager(testWAL, walPath)
+	if err := rm.Recover(); err != nil {
+		t.Fatalf("second Recover failed: %v", err)
+	}
+	if stats := rm.GetStats(); stats.FileOpsFinished != 0 || stats.FileOpsDropped != 0 {
+		t.Errorf("expected no pending file operations, got %d finished and %d dropped", stats.FileOpsFinished, stats.FileOpsDropped)
+	}
+}
@@ -40,7 +40,7 @@ func TestCrashRecovery_SimpleCommit(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		// Check if recovery is needed
 		needed, err := rm.IsRecoveryNeeded()
@@ -100,7 +100,7 @@ func TestCrashRecovery_UncommittedTransaction(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		// Check if recovery is needed
 		needed, err := rm.IsRecoveryNeeded()
@@ -193,7 +193,7 @@ func TestCrashRecovery_MultipleTransactions(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		// Check if recovery is needed
 		needed, err := rm.IsRecoveryNeeded()
@@ -271,7 +271,7 @@ func TestCrashRecovery_LongRunningTransaction(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		err = rm.Recover()
 		if err != nil {
@@ -331,7 +331,7 @@ func TestCrashRecovery_InterleavedTransactions(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		err = rm.Recover()
 		if err != nil {
@@ -380,7 +380,7 @@ func TestCrashRecovery_EmptyWAL(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		needed, err := rm.IsRecoveryNeeded()
 		if err != nil {
@@ -430,7 +430,7 @@ func TestCrashRecovery_RepeatedRecovery(t *testing.T) {
 			t.Fatalf("Failed to reopen WAL: %v", err)
 		}
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		err = rm.Recover()
 		if err != nil {
@@ -511,7 +511,7 @@ func TestCrashRecovery_CorruptedWAL(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		// Recovery should handle the truncated WAL gracefully
 		// It may fail or succeed depending on where truncation occurred
@@ -550,7 +550,7 @@ func TestCrashRecovery_SequentialCrashes(t *testing.T) {
 			t.Fatalf("Failed to reopen WAL: %v", err)
 		}
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 		err = rm.Recover()
 		if err != nil {
 			t.Fatalf("First recovery failed: %v", err)
@@ -582,7 +582,7 @@ func TestCrashRecovery_SequentialCrashes(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 		err = rm.Recover()
 		if err != nil {
 			t.Fatalf("Second recovery failed: %v", err)
@@ -631,7 +631,7 @@ func TestCrashRecovery_MixedPageAccess(t *testing.T) {
 		}
 		defer testWAL.Close()
 
-		rm := NewRecoveryManager(testWAL, walPath, nil)
+		rm := newTestRecoveryManager(testWAL, walPath)
 
 		err = rm.Recover()
 		if err != nil {
@@ -646,7 +646,7 @@ func TestCrashRecovery_MixedPageAccess(t *testing.T) {
 
 		// Page should be in dirty page table
 		dirtyPages := rm.GetDirtyPageTable()
-		if _, exists := dirtyPages[newMockPageID(100).HashCode()]; !exists {
+		if _, exists := dirtyPages[primitives.KeyOf(newMockPageID(100))]; !exists {
 			t.Error("Page 100 should be in dirty page table")
 		}
 	}
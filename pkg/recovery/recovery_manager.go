@@ -1,28 +1,38 @@
 package recovery
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 
+	"storemy/pkg/invariant"
 	"storemy/pkg/log/record"
 	"storemy/pkg/log/wal"
+	"storemy/pkg/logging"
 	"storemy/pkg/memory"
 	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
 )
 
 // RecoveryManager implements ARIES-style crash recovery with three phases:
 // 1. Analysis - scan WAL to identify uncommitted transactions and dirty pages
 // 2. Redo - replay all operations to restore database state
 // 3. Undo - rollback uncommitted transactions
+// after which the deferred file operations of committed transactions are
+// finished.
 type RecoveryManager struct {
 	wal       *wal.WAL
 	walPath   string
 	pageStore *memory.PageStore
 	mutex     sync.RWMutex
+	logger    logging.ComponentLogger
+
+	stopAtCorrupt bool // Treat the first corrupt record as the end of the log
 
 	// Analysis phase results
-	dirtyPageTable   map[primitives.HashCode]primitives.LSN // pageID.HashCode() -> first LSN that dirtied it
-	transactionTable map[int64]*TransactionInfo              // tidID -> transaction info
+	dirtyPageTable   map[primitives.PageKey]primitives.LSN // page -> first LSN that dirtied it
+	transactionTable map[int64]*TransactionInfo            // tidID -> transaction info
 
 	// Recovery statistics
 	stats RecoveryStats
@@ -48,12 +58,15 @@ const (
 
 // RecoveryStats tracks recovery phase statistics
 type RecoveryStats struct {
-	LogRecordsScanned    int
-	RedoOperations       int
-	UndoOperations       int
+	LogRecordsScanned     int
+	RedoOperations        int
+	UndoOperations        int
 	TransactionsRecovered int
-	TransactionsUndone   int
-	DirtyPagesFound      int
+	TransactionsUndone    int
+	DirtyPagesFound       int
+	FileOpsFinished       int
+	FileOpsDropped        int
+	BulkLoadsUndone       int
 }
 
 // NewRecoveryManager creates a new recovery manager instance
@@ -62,10 +75,49 @@ func NewRecoveryManager(wal *wal.WAL, walPath string, pageStore *memory.PageStor
 		wal:              wal,
 		walPath:          walPath,
 		pageStore:        pageStore,
-		dirtyPageTable:   make(map[primitives.HashCode]primitives.LSN),
+		dirtyPageTable:   make(map[primitives.PageKey]primitives.LSN),
 		transactionTable: make(map[int64]*TransactionInfo),
 		stats:            RecoveryStats{},
+		logger:           logging.ForComponent("recovery"),
+	}
+}
+
+// openLogReader opens a reader over the WAL file, on the same file system as
+// the WAL and decrypting its encrypted records.
+func (rm *RecoveryManager) openLogReader() (*wal.LogReader, error) {
+	if rm.wal == nil {
+		return wal.NewLogReader(rm.walPath)
+	}
+	return rm.wal.OpenLogReader()
+}
+
+// pageFS returns the file system the page files are stored on.
+func (rm *RecoveryManager) pageFS() vfs.FS {
+	if rm.pageStore != nil {
+		return rm.pageStore.FS()
 	}
+	return rm.wal.FS()
+}
+
+// SetLogger replaces the logger used to report recovery progress.
+func (rm *RecoveryManager) SetLogger(logger logging.ComponentLogger) {
+	rm.logger = logger
+}
+
+// SetStopAtCorruptRecord chooses what recovery does on reaching a record
+// that is torn or fails its checksum. By default recovery fails with an error
+// wrapping wal.ErrCorruptRecord, so that a damaged log is not silently cut
+// short. With stop set, replay ends at the first invalid record as if the
+// log ended there, which is what a log whose last write was cut short by a
+// crash needs; the records after it are ignored.
+func (rm *RecoveryManager) SetStopAtCorruptRecord(stop bool) {
+	rm.stopAtCorrupt = stop
+}
+
+// endOfLog reports whether err, returned by ReadNext, ends the scan of the
+// log rather than failing recovery.
+func (rm *RecoveryManager) endOfLog(err error) bool {
+	return err == io.EOF || (rm.stopAtCorrupt && errors.Is(err, wal.ErrCorruptRecord))
 }
 
 // Recover performs the full ARIES recovery algorithm
@@ -74,59 +126,100 @@ func (rm *RecoveryManager) Recover() error {
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 
-	fmt.Println("Starting ARIES recovery...")
+	rm.logger.Info("starting ARIES recovery")
 
 	// Phase 1: Analysis
 	if err := rm.analysisPhase(); err != nil {
-		return fmt.Errorf("analysis phase failed: %w", err)
+		return newRecoveryError(err, "analysis phase failed")
 	}
 
 	// Phase 2: Redo
 	if err := rm.redoPhase(); err != nil {
-		return fmt.Errorf("redo phase failed: %w", err)
+		return newRecoveryError(err, "redo phase failed")
 	}
 
 	// Phase 3: Undo
 	if err := rm.undoPhase(); err != nil {
-		return fmt.Errorf("undo phase failed: %w", err)
+		return newRecoveryError(err, "undo phase failed")
+	}
+
+	if err := rm.fileOpPhase(); err != nil {
+		return newRecoveryError(err, "file operation phase failed")
+	}
+
+	rm.logger.Info("recovery completed", rm.statsAttrs()...)
+	return nil
+}
+
+// RecoverRedoOnly runs the Analysis and Redo phases but skips Undo.
+// Undo writes CLRs and ABORT records, so it cannot run against a read-only WAL;
+// instead, uncommitted transactions are left in the transaction table and can be
+// inspected via GetUncommittedTransactions. Used when opening a database read-only.
+func (rm *RecoveryManager) RecoverRedoOnly() error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	rm.logger.Info("starting ARIES recovery", "mode", "redo-only")
+
+	if err := rm.analysisPhase(); err != nil {
+		return newRecoveryError(err, "analysis phase failed")
+	}
+
+	if err := rm.redoPhase(); err != nil {
+		return newRecoveryError(err, "redo phase failed")
 	}
 
-	fmt.Printf("Recovery completed successfully. Stats: %+v\n", rm.stats)
+	rm.logger.Info("redo-only recovery completed", rm.statsAttrs()...)
 	return nil
 }
 
+// statsAttrs flattens the recovery statistics into logger key-value pairs.
+func (rm *RecoveryManager) statsAttrs() []any {
+	return []any{
+		"records_scanned", rm.stats.LogRecordsScanned,
+		"redo_operations", rm.stats.RedoOperations,
+		"undo_operations", rm.stats.UndoOperations,
+		"transactions_recovered", rm.stats.TransactionsRecovered,
+		"transactions_undone", rm.stats.TransactionsUndone,
+		"dirty_pages", rm.stats.DirtyPagesFound,
+		"file_ops_finished", rm.stats.FileOpsFinished,
+		"file_ops_dropped", rm.stats.FileOpsDropped,
+		"bulk_loads_undone", rm.stats.BulkLoadsUndone,
+	}
+}
+
 // analysisPhase scans the WAL to:
 // 1. Load the last checkpoint (if exists) to initialize state
 // 2. Build the dirty page table (which pages were modified)
 // 3. Build the transaction table (which transactions were active)
 // 4. Identify uncommitted transactions that need to be undone
 func (rm *RecoveryManager) analysisPhase() error {
-	fmt.Println("Phase 1: Analysis - scanning WAL...")
+	rm.logger.Info("analysis phase: scanning WAL")
 
 	// Force flush WAL to ensure all records are on disk before reading
 	// Get the current LSN by checking the writer's current LSN
 	if err := rm.wal.Force(primitives.LSN(^uint64(0))); err != nil {
-		return fmt.Errorf("failed to flush WAL before analysis: %w", err)
+		return newRecoveryError(err, "failed to flush WAL before analysis")
 	}
 
 	// Reset internal state
-	rm.dirtyPageTable = make(map[primitives.HashCode]primitives.LSN)
+	rm.dirtyPageTable = make(map[primitives.PageKey]primitives.LSN)
 	rm.transactionTable = make(map[int64]*TransactionInfo)
 
 	// Try to load the last checkpoint
 	startLSN := primitives.LSN(0)
 	checkpoint, err := rm.wal.GetLastCheckpoint()
 	if err != nil {
-		fmt.Printf("Warning: failed to load checkpoint: %v\n", err)
+		rm.logger.Warn("failed to load checkpoint, scanning from beginning", "error", err)
 		// Continue with recovery from beginning
 	} else if checkpoint != nil {
 		// Initialize state from checkpoint
-		fmt.Printf("Found checkpoint at LSN %d: %d active transactions, %d dirty pages\n",
-			checkpoint.LSN, len(checkpoint.ActiveTxns), len(checkpoint.DirtyPages))
+		rm.logger.Info("found checkpoint",
+			"lsn", checkpoint.LSN, "active_txns", len(checkpoint.ActiveTxns), "dirty_pages", len(checkpoint.DirtyPages))
 
 		// Load dirty page table from checkpoint
-		for pageHash, lsn := range checkpoint.DirtyPages {
-			rm.dirtyPageTable[pageHash] = lsn
+		for key, lsn := range checkpoint.DirtyPages {
+			rm.dirtyPageTable[key] = lsn
 		}
 
 		// Load transaction table from checkpoint
@@ -142,35 +235,49 @@ func (rm *RecoveryManager) analysisPhase() error {
 
 		// Start scanning from checkpoint LSN
 		startLSN = checkpoint.LSN
-		fmt.Printf("Starting analysis from checkpoint LSN %d\n", startLSN)
+
+		// A legacy checkpoint names its dirty pages only by hash, so they are
+		// rebuilt from the log, starting where the earliest of them became
+		// dirty. The next checkpoint is written in the current format.
+		if len(checkpoint.LegacyDirtyPages) > 0 {
+			if minLSN, ok := checkpoint.MinDirtyLSN(); ok && minLSN < startLSN {
+				startLSN = minLSN
+			}
+			rm.logger.Warn("checkpoint uses the legacy dirty page format, rebuilding dirty pages from the log",
+				"legacy_dirty_pages", len(checkpoint.LegacyDirtyPages), "start_lsn", startLSN)
+		}
+		rm.logger.Debug("starting analysis from checkpoint", "lsn", startLSN)
 	} else {
-		fmt.Println("No checkpoint found, starting analysis from beginning")
+		rm.logger.Debug("no checkpoint found, starting analysis from beginning")
 	}
 
-	reader, err := wal.NewLogReader(rm.walPath)
+	reader, err := rm.openLogReader()
 	if err != nil {
-		return fmt.Errorf("failed to create WAL reader: %w", err)
+		return newRecoveryError(err, "failed to create WAL reader")
 	}
 	defer reader.Close()
 
 	// Scan WAL from startLSN (either checkpoint LSN or 0)
+	if err := reader.Seek(startLSN); err != nil {
+		return newRecoveryError(err, "failed to read WAL")
+	}
 	for {
 		logRecord, err := reader.ReadNext()
 		if err != nil {
-			// End of log reached
+			if !rm.endOfLog(err) {
+				return newRecoveryError(err, "failed to read WAL")
+			}
+			if err != io.EOF {
+				rm.logger.Warn("stopping replay at corrupt WAL record", "error", err)
+			}
 			break
 		}
 
-		// Skip records before our start LSN
-		if logRecord.LSN < startLSN {
-			continue
-		}
-
 		rm.stats.LogRecordsScanned++
 
 		// Process record based on type
 		if err := rm.processAnalysisRecord(logRecord); err != nil {
-			return fmt.Errorf("failed to process record at LSN %d: %w", logRecord.LSN, err)
+			return newRecoveryError(err, "failed to process record at LSN %d", logRecord.LSN)
 		}
 	}
 
@@ -185,8 +292,8 @@ func (rm *RecoveryManager) analysisPhase() error {
 		}
 	}
 
-	fmt.Printf("Analysis complete: %d transactions, %d dirty pages, %d uncommitted\n",
-		rm.stats.TransactionsRecovered, rm.stats.DirtyPagesFound, rm.stats.TransactionsUndone)
+	rm.logger.Info("analysis phase complete",
+		"transactions", rm.stats.TransactionsRecovered, "dirty_pages", rm.stats.DirtyPagesFound, "uncommitted", rm.stats.TransactionsUndone)
 
 	return nil
 }
@@ -202,7 +309,7 @@ func (rm *RecoveryManager) processAnalysisRecord(rec *record.LogRecord) error {
 
 	// All other record types require a transaction ID
 	if rec.TID == nil {
-		return fmt.Errorf("log record at LSN %d has no transaction ID", rec.LSN)
+		return newCorruptLogError("log record at LSN %d has no transaction ID", rec.LSN)
 	}
 
 	tid := rec.TID
@@ -265,9 +372,25 @@ func (rm *RecoveryManager) processAnalysisRecord(rec *record.LogRecord) error {
 		}
 
 		// Add to dirty page table if not already present
-		pageHash := rec.PageID.HashCode()
-		if _, exists := rm.dirtyPageTable[pageHash]; !exists {
-			rm.dirtyPageTable[pageHash] = rec.LSN
+		key := primitives.KeyOf(rec.PageID)
+		if _, exists := rm.dirtyPageTable[key]; !exists {
+			rm.dirtyPageTable[key] = rec.LSN
+		}
+
+	case record.FileOpRecord, record.BulkLoadRecord, record.BulkLoadBarrierRecord:
+		// Deferred file operation or unlogged bulk load - joins the
+		// transaction's chain but adds no page to the dirty page table;
+		// fileOpPhase and the undo phase decide its fate
+		if txnInfo, exists := rm.transactionTable[tidID]; exists {
+			txnInfo.LastLSN = rec.LSN
+		} else {
+			rm.transactionTable[tidID] = &TransactionInfo{
+				TID:         tid,
+				Status:      TxnActive,
+				FirstLSN:    rec.LSN,
+				LastLSN:     rec.LSN,
+				UndoNextLSN: rec.PrevLSN,
+			}
 		}
 
 	case record.CLRRecord:
@@ -278,9 +401,9 @@ func (rm *RecoveryManager) processAnalysisRecord(rec *record.LogRecord) error {
 		}
 
 		// CLR also dirties pages
-		pageHash := rec.PageID.HashCode()
-		if _, exists := rm.dirtyPageTable[pageHash]; !exists {
-			rm.dirtyPageTable[pageHash] = rec.LSN
+		key := primitives.KeyOf(rec.PageID)
+		if _, exists := rm.dirtyPageTable[key]; !exists {
+			rm.dirtyPageTable[key] = rec.LSN
 		}
 	}
 
@@ -290,10 +413,10 @@ func (rm *RecoveryManager) processAnalysisRecord(rec *record.LogRecord) error {
 // redoPhase replays all operations from the WAL to restore the database state
 // This ensures all committed transactions are reflected on disk
 func (rm *RecoveryManager) redoPhase() error {
-	fmt.Println("Phase 2: Redo - replaying operations...")
+	rm.logger.Info("redo phase: replaying operations")
 
 	if len(rm.dirtyPageTable) == 0 {
-		fmt.Println("No dirty pages found, skipping redo phase")
+		rm.logger.Debug("no dirty pages found, skipping redo phase")
 		return nil
 	}
 
@@ -305,55 +428,73 @@ func (rm *RecoveryManager) redoPhase() error {
 		}
 	}
 
-	reader, err := wal.NewLogReader(rm.walPath)
+	reader, err := rm.openLogReader()
 	if err != nil {
-		return fmt.Errorf("failed to create WAL reader: %w", err)
+		return newRecoveryError(err, "failed to create WAL reader")
 	}
 	defer reader.Close()
 
 	// Scan from the earliest dirty page LSN
+	if err := reader.Seek(minLSN); err != nil {
+		return newRecoveryError(err, "failed to read WAL")
+	}
 	for {
 		logRecord, err := reader.ReadNext()
 		if err != nil {
-			// End of log reached
+			if !rm.endOfLog(err) {
+				return newRecoveryError(err, "failed to read WAL")
+			}
 			break
 		}
 
-		// Skip records before minimum LSN
-		if logRecord.LSN < minLSN {
-			continue
-		}
-
 		// Redo the operation if needed
 		if err := rm.redoRecord(logRecord); err != nil {
-			return fmt.Errorf("failed to redo record at LSN %d: %w", logRecord.LSN, err)
+			return newRecoveryError(err, "failed to redo record at LSN %d", logRecord.LSN)
 		}
 	}
 
-	fmt.Printf("Redo complete: %d operations replayed\n", rm.stats.RedoOperations)
+	rm.logger.Info("redo phase complete", "operations", rm.stats.RedoOperations)
 	return nil
 }
 
 // redoRecord replays a single log record
 func (rm *RecoveryManager) redoRecord(rec *record.LogRecord) error {
+	if redo, _ := rm.redoDecision(rec); !redo {
+		return nil
+	}
+
+	// Apply the after-image to the page
+	if err := rm.applyRedo(rec); err != nil {
+		return err
+	}
+	rm.stats.RedoOperations++
+	return nil
+}
+
+// redoDecision reports whether the redo phase replays rec, and why.
+func (rm *RecoveryManager) redoDecision(rec *record.LogRecord) (bool, string) {
 	// Only redo data modification records
 	switch rec.Type {
 	case record.UpdateRecord, record.InsertRecord, record.CLRRecord:
-		// Check if this page is in the dirty page table
-		pageHash := rec.PageID.HashCode()
-		if firstLSN, isDirty := rm.dirtyPageTable[pageHash]; isDirty {
-			// Only redo if this record dirtied the page or came after
-			if rec.LSN >= firstLSN {
-				// Apply the after-image to the page
-				if err := rm.applyRedo(rec); err != nil {
-					return err
-				}
-				rm.stats.RedoOperations++
-			}
-		}
+	case record.DeleteRecord:
+		return false, "delete records are not replayed by redo"
+	case record.BulkLoadRecord, record.BulkLoadBarrierRecord:
+		return false, "bulk loaded pages are not logged, the file was synced before the barrier"
+	default:
+		return false, "not a page change"
 	}
 
-	return nil
+	// Check if this page is in the dirty page table
+	firstLSN, isDirty := rm.dirtyPageTable[primitives.KeyOf(rec.PageID)]
+	if !isDirty {
+		return false, "page is not in the dirty page table, so the change is already on disk"
+	}
+
+	// Only redo if this record dirtied the page or came after
+	if rec.LSN < firstLSN {
+		return false, fmt.Sprintf("page was clean until LSN %d, so this earlier change is already on disk", firstLSN)
+	}
+	return true, fmt.Sprintf("page is dirty since LSN %d, so history is repeated", firstLSN)
 }
 
 // applyRedo applies the after-image of a log record to a page
@@ -376,7 +517,7 @@ func (rm *RecoveryManager) applyRedo(rec *record.LogRecord) error {
 // undoPhase rolls back all uncommitted transactions
 // This ensures atomicity - no partial transactions remain
 func (rm *RecoveryManager) undoPhase() error {
-	fmt.Println("Phase 3: Undo - rolling back uncommitted transactions...")
+	rm.logger.Info("undo phase: rolling back uncommitted transactions")
 
 	// Collect all uncommitted transactions
 	var uncommittedTxns []*TransactionInfo
@@ -387,109 +528,148 @@ func (rm *RecoveryManager) undoPhase() error {
 	}
 
 	if len(uncommittedTxns) == 0 {
-		fmt.Println("No uncommitted transactions found, skipping undo phase")
+		rm.logger.Debug("no uncommitted transactions found, skipping undo phase")
 		return nil
 	}
 
-	fmt.Printf("Found %d uncommitted transactions to rollback\n", len(uncommittedTxns))
+	rm.logger.Info("rolling back uncommitted transactions", "count", len(uncommittedTxns))
 
 	// For each uncommitted transaction, follow the undo chain backwards
 	for _, txnInfo := range uncommittedTxns {
 		if err := rm.undoTransaction(txnInfo); err != nil {
-			return fmt.Errorf("failed to undo transaction %v: %w", txnInfo.TID, err)
+			return newRecoveryError(err, "failed to undo transaction %v", txnInfo.TID)
 		}
 	}
 
-	fmt.Printf("Undo complete: %d operations undone\n", rm.stats.UndoOperations)
+	rm.logger.Info("undo phase complete", "operations", rm.stats.UndoOperations)
 	return nil
 }
 
 // undoTransaction rolls back a single uncommitted transaction
 func (rm *RecoveryManager) undoTransaction(txnInfo *TransactionInfo) error {
-	fmt.Printf("Undoing transaction %v (LastLSN=%d)\n", txnInfo.TID, txnInfo.LastLSN)
+	rm.logger.Debug("undoing transaction", "tx_id", txnInfo.TID.ID(), "last_lsn", txnInfo.LastLSN)
 
-	reader, err := wal.NewLogReader(rm.walPath)
+	reader, err := rm.openLogReader()
 	if err != nil {
-		return fmt.Errorf("failed to create WAL reader: %w", err)
+		return newRecoveryError(err, "failed to create WAL reader")
 	}
 	defer reader.Close()
 
-	// Build a map of all log records for quick lookup
-	recordMap := make(map[primitives.LSN]*record.LogRecord)
-	for {
+	// Read the records of the chain by seeking to each of them. A reader of
+	// the WAL seeks through the WAL's index of the log, which the analysis
+	// pass already extended to its end, so each seek reads a few headers
+	readAt := func(lsn primitives.LSN) (*record.LogRecord, bool, error) {
+		if err := reader.Seek(lsn); err != nil {
+			return nil, false, newRecoveryError(err, "failed to seek to LSN %d", lsn)
+		}
 		rec, err := reader.ReadNext()
 		if err != nil {
-			break
+			return nil, false, newRecoveryError(err, "failed to read the record at LSN %d", lsn)
+		}
+		return rec, rec.LSN == lsn, nil
+	}
+
+	chain, err := undoChain(txnInfo, readAt)
+	if err != nil {
+		return err
+	}
+	for _, rec := range chain {
+		switch rec.Type {
+		case record.UpdateRecord, record.DeleteRecord:
+			// Undo this operation
+			if err := rm.undoRecord(rec); err != nil {
+				return newRecoveryError(err, "failed to undo record at LSN %d", rec.LSN)
+			}
+			rm.stats.UndoOperations++
+
+			// Write CLR (Compensation Log Record) to prevent re-undo
+			clr := &record.LogRecord{
+				Type:        record.CLRRecord,
+				TID:         rec.TID,
+				PageID:      rec.PageID,
+				AfterImage:  rec.BeforeImage, // CLR applies the before-image
+				UndoNextLSN: rec.PrevLSN,     // Continue undo chain
+			}
+
+			if err := rm.writeCLR(clr); err != nil {
+				return newRecoveryError(err, "failed to write CLR")
+			}
+
+		case record.BulkLoadRecord:
+			// The pages were never logged, so they are zeroed in place.
+			// Zeroing is idempotent and needs no CLR.
+			if err := rec.BulkLoad.Undo(rm.pageFS()); err != nil {
+				return newRecoveryError(err, "failed to undo bulk load at LSN %d", rec.LSN)
+			}
+			rm.logger.Info("undid bulk load", "lsn", rec.LSN, "tx_id", rec.TID.ID(), "load", rec.BulkLoad.String())
+			rm.stats.UndoOperations++
+			rm.stats.BulkLoadsUndone++
+
+		case record.InsertRecord:
+			// For inserts, we need to delete the tuple
+			// This is equivalent to applying a delete operation
+			if err := rm.undoInsert(rec); err != nil {
+				return newRecoveryError(err, "failed to undo insert at LSN %d", rec.LSN)
+			}
+			rm.stats.UndoOperations++
+
+			// Write CLR
+			clr := &record.LogRecord{
+				Type:        record.CLRRecord,
+				TID:         rec.TID,
+				PageID:      rec.PageID,
+				UndoNextLSN: rec.PrevLSN,
+			}
+
+			if err := rm.writeCLR(clr); err != nil {
+				return newRecoveryError(err, "failed to write CLR")
+			}
 		}
-		recordMap[rec.LSN] = rec
 	}
 
+	// Mark transaction as aborted in WAL during recovery
+	// We use LogAbortDuringRecovery because the transaction is not in the active transactions table
+	if _, err := rm.wal.LogAbortDuringRecovery(txnInfo.TID, txnInfo.LastLSN); err != nil {
+		return newRecoveryError(err, "failed to log abort")
+	}
+
+	return nil
+}
+
+// undoChain returns the data modification records of a loser transaction in
+// the order the undo phase rolls them back: following PrevLSN backwards from
+// its last record to the start of the transaction. lookup returns the record
+// at an LSN, false if no record starts there, or an error if the log cannot
+// be read. A chain pointing at an LSN with no record is corrupt, since
+// stopping there would leave the changes before it in place.
+func undoChain(txnInfo *TransactionInfo, lookup func(primitives.LSN) (*record.LogRecord, bool, error)) ([]*record.LogRecord, error) {
+	var chain []*record.LogRecord
+
 	// Follow the undo chain backwards from LastLSN
 	currentLSN := txnInfo.LastLSN
-
 	for currentLSN != 0 {
-		rec, exists := recordMap[currentLSN]
+		rec, exists, err := lookup(currentLSN)
+		if err != nil {
+			return nil, err
+		}
 		if !exists {
-			// Reached the beginning of the transaction
-			break
+			return nil, newCorruptLogError("undo chain of transaction %d points at LSN %d, where no record starts", txnInfo.TID.ID(), currentLSN)
 		}
 
-		// Only undo data modification records
+		// Only undo data modification records and bulk loads
 		if rec.TID.Equals(txnInfo.TID) {
 			switch rec.Type {
-			case record.UpdateRecord, record.DeleteRecord:
-				// Undo this operation
-				if err := rm.undoRecord(rec); err != nil {
-					return fmt.Errorf("failed to undo record at LSN %d: %w", currentLSN, err)
-				}
-				rm.stats.UndoOperations++
-
-				// Write CLR (Compensation Log Record) to prevent re-undo
-				clr := &record.LogRecord{
-					Type:        record.CLRRecord,
-					TID:         rec.TID,
-					PageID:      rec.PageID,
-					AfterImage:  rec.BeforeImage, // CLR applies the before-image
-					UndoNextLSN: rec.PrevLSN,     // Continue undo chain
-				}
-
-				if err := rm.writeCLR(clr); err != nil {
-					return fmt.Errorf("failed to write CLR: %w", err)
-				}
-
-			case record.InsertRecord:
-				// For inserts, we need to delete the tuple
-				// This is equivalent to applying a delete operation
-				if err := rm.undoInsert(rec); err != nil {
-					return fmt.Errorf("failed to undo insert at LSN %d: %w", currentLSN, err)
-				}
-				rm.stats.UndoOperations++
-
-				// Write CLR
-				clr := &record.LogRecord{
-					Type:        record.CLRRecord,
-					TID:         rec.TID,
-					PageID:      rec.PageID,
-					UndoNextLSN: rec.PrevLSN,
-				}
-
-				if err := rm.writeCLR(clr); err != nil {
-					return fmt.Errorf("failed to write CLR: %w", err)
-				}
+			case record.UpdateRecord, record.DeleteRecord, record.InsertRecord, record.BulkLoadRecord:
+				chain = append(chain, rec)
 			}
 		}
 
-		// Follow the undo chain via PrevLSN
+		// Follow the undo chain via PrevLSN, which only moves backwards; a
+		// record pointing at itself or forward would loop the undo phase
+		invariant.Check(rec.PrevLSN < currentLSN, "undo chain of transaction %v: record at LSN %d has PrevLSN %d", txnInfo.TID, currentLSN, rec.PrevLSN)
 		currentLSN = rec.PrevLSN
 	}
-
-	// Mark transaction as aborted in WAL during recovery
-	// We use LogAbortDuringRecovery because the transaction is not in the active transactions table
-	if _, err := rm.wal.LogAbortDuringRecovery(txnInfo.TID, txnInfo.LastLSN); err != nil {
-		return fmt.Errorf("failed to log abort: %w", err)
-	}
-
-	return nil
+	return chain, nil
 }
 
 // undoRecord undoes a single update or delete operation
@@ -539,11 +719,11 @@ func (rm *RecoveryManager) ResetStats() {
 }
 
 // GetDirtyPageTable returns a copy of the dirty page table
-func (rm *RecoveryManager) GetDirtyPageTable() map[primitives.HashCode]primitives.LSN {
+func (rm *RecoveryManager) GetDirtyPageTable() map[primitives.PageKey]primitives.LSN {
 	rm.mutex.RLock()
 	defer rm.mutex.RUnlock()
 
-	result := make(map[primitives.HashCode]primitives.LSN)
+	result := make(map[primitives.PageKey]primitives.LSN)
 	for k, v := range rm.dirtyPageTable {
 		result[k] = v
 	}
@@ -587,12 +767,12 @@ func (rm *RecoveryManager) GetUncommittedTransactions() []*primitives.Transactio
 func (rm *RecoveryManager) IsRecoveryNeeded() (bool, error) {
 	// Force flush WAL to ensure all records are on disk before reading
 	if err := rm.wal.Force(primitives.LSN(^uint64(0))); err != nil {
-		return false, fmt.Errorf("failed to flush WAL before checking: %w", err)
+		return false, newRecoveryError(err, "failed to flush WAL before checking")
 	}
 
-	reader, err := wal.NewLogReader(rm.walPath)
+	reader, err := rm.openLogReader()
 	if err != nil {
-		return false, fmt.Errorf("failed to create WAL reader: %w", err)
+		return false, newRecoveryError(err, "failed to create WAL reader")
 	}
 	defer reader.Close()
 
@@ -1,12 +1,17 @@
 package recovery
 
 import (
+	"errors"
 	"hash/fnv"
+	"os"
 	"path/filepath"
 	"testing"
 
+	dberror "storemy/pkg/error"
+	"storemy/pkg/invariant"
 	"storemy/pkg/log/record"
 	"storemy/pkg/log/wal"
+	"storemy/pkg/logging"
 	"storemy/pkg/primitives"
 )
 
@@ -71,10 +76,19 @@ func createTestWAL(t *testing.T) (*wal.WAL, string) {
 	if err != nil {
 		t.Fatalf("Failed to create test WAL: %v", err)
 	}
+	testWAL.SetLogger(logging.NewNopLogger())
 
 	return testWAL, walPath
 }
 
+// newTestRecoveryManager creates a recovery manager over testWAL that
+// discards its progress log.
+func newTestRecoveryManager(testWAL *wal.WAL, walPath string) *RecoveryManager {
+	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm.SetLogger(logging.NewNopLogger())
+	return rm
+}
+
 func TestNewRecoveryManager(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
@@ -102,7 +116,7 @@ func TestAnalysisPhase_EmptyWAL(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	err := rm.analysisPhase()
 	if err != nil {
@@ -142,7 +156,7 @@ func TestAnalysisPhase_SingleCommittedTransaction(t *testing.T) {
 	}
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err = rm.analysisPhase()
 	if err != nil {
 		t.Fatalf("Analysis phase failed: %v", err)
@@ -192,7 +206,7 @@ func TestAnalysisPhase_UncommittedTransaction(t *testing.T) {
 	testWAL.LogUpdate(tid, newMockPageID(1), []byte("old"), []byte("new"))
 	// No commit - simulates crash
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err := rm.analysisPhase()
 	if err != nil {
 		t.Fatalf("Analysis phase failed: %v", err)
@@ -240,7 +254,7 @@ func TestAnalysisPhase_MultipleTransactions(t *testing.T) {
 	testWAL.LogUpdate(tid3, newMockPageID(3), []byte("old3"), []byte("new3"))
 	testWAL.LogAbort(tid3)
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err := rm.analysisPhase()
 	if err != nil {
 		t.Fatalf("Analysis phase failed: %v", err)
@@ -292,7 +306,7 @@ func TestAnalysisPhase_MultipleUpdatesToSamePage(t *testing.T) {
 
 	testWAL.LogCommit(tid)
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err := rm.analysisPhase()
 	if err != nil {
 		t.Fatalf("Analysis phase failed: %v", err)
@@ -304,7 +318,7 @@ func TestAnalysisPhase_MultipleUpdatesToSamePage(t *testing.T) {
 	}
 
 	// The LSN should be from the FIRST update
-	if lsn, exists := rm.dirtyPageTable[pageID.HashCode()]; !exists {
+	if lsn, exists := rm.dirtyPageTable[primitives.KeyOf(pageID)]; !exists {
 		t.Error("Page 1 not in dirty page table")
 	} else if lsn != firstUpdateLSN {
 		t.Errorf("Expected first update LSN %d, got %d", firstUpdateLSN, lsn)
@@ -315,7 +329,7 @@ func TestProcessAnalysisRecord_BeginRecord(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	tid := primitives.NewTransactionID()
 	rec := &record.LogRecord{
@@ -348,7 +362,7 @@ func TestProcessAnalysisRecord_UpdateRecord(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	tid := primitives.NewTransactionID()
 	pageID := newMockPageID(42)
@@ -378,7 +392,7 @@ func TestProcessAnalysisRecord_UpdateRecord(t *testing.T) {
 	}
 
 	// Check dirty page table
-	lsn, exists := rm.dirtyPageTable[pageID.HashCode()]
+	lsn, exists := rm.dirtyPageTable[primitives.KeyOf(pageID)]
 	if !exists {
 		t.Fatal("Page not added to dirty page table")
 	}
@@ -398,10 +412,10 @@ func TestRedoPhase_NoDirtyPages(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Empty dirty page table
-	rm.dirtyPageTable = make(map[primitives.HashCode]primitives.LSN)
+	rm.dirtyPageTable = make(map[primitives.PageKey]primitives.LSN)
 
 	err := rm.redoPhase()
 	if err != nil {
@@ -425,7 +439,7 @@ func TestRedoPhase_WithDirtyPages(t *testing.T) {
 	updateLSN, _ := testWAL.LogUpdate(tid, pageID, []byte("old"), []byte("new"))
 	testWAL.LogCommit(tid)
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Run analysis first
 	err := rm.analysisPhase()
@@ -445,7 +459,7 @@ func TestRedoPhase_WithDirtyPages(t *testing.T) {
 	}
 
 	// Verify the update LSN is in dirty page table
-	if lsn, exists := rm.dirtyPageTable[pageID.HashCode()]; !exists {
+	if lsn, exists := rm.dirtyPageTable[primitives.KeyOf(pageID)]; !exists {
 		t.Error("Page should be in dirty page table")
 	} else if lsn != updateLSN {
 		t.Errorf("Expected LSN=%d, got %d", updateLSN, lsn)
@@ -463,7 +477,7 @@ func TestUndoPhase_NoUncommittedTransactions(t *testing.T) {
 	testWAL.LogUpdate(tid, newMockPageID(1), []byte("old"), []byte("new"))
 	testWAL.LogCommit(tid)
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Run analysis first
 	err := rm.analysisPhase()
@@ -494,7 +508,7 @@ func TestUndoPhase_UncommittedTransaction(t *testing.T) {
 	testWAL.LogUpdate(tid, newMockPageID(1), []byte("old"), []byte("new"))
 	// No commit - crash!
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Run analysis first
 	err := rm.analysisPhase()
@@ -523,7 +537,7 @@ func TestGetStats(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Set some statistics
 	rm.stats.LogRecordsScanned = 10
@@ -549,7 +563,7 @@ func TestResetStats(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Set some statistics
 	rm.stats.LogRecordsScanned = 10
@@ -570,13 +584,13 @@ func TestGetDirtyPageTable(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Add some dirty pages
 	page1 := newMockPageID(1)
 	page2 := newMockPageID(2)
-	rm.dirtyPageTable[page1.HashCode()] = 100
-	rm.dirtyPageTable[page2.HashCode()] = 200
+	rm.dirtyPageTable[primitives.KeyOf(page1)] = 100
+	rm.dirtyPageTable[primitives.KeyOf(page2)] = 200
 
 	table := rm.GetDirtyPageTable()
 
@@ -584,15 +598,15 @@ func TestGetDirtyPageTable(t *testing.T) {
 		t.Errorf("Expected 2 dirty pages, got %d", len(table))
 	}
 
-	if table[page1.HashCode()] != 100 {
+	if table[primitives.KeyOf(page1)] != 100 {
 		t.Error("Dirty page LSN incorrect")
 	}
 
 	// Verify it's a copy (modifying returned table shouldn't affect original)
 	page3 := newMockPageID(3)
-	table[page3.HashCode()] = 300
+	table[primitives.KeyOf(page3)] = 300
 
-	if _, exists := rm.dirtyPageTable[page3.HashCode()]; exists {
+	if _, exists := rm.dirtyPageTable[primitives.KeyOf(page3)]; exists {
 		t.Error("Modifying returned table affected original")
 	}
 }
@@ -601,7 +615,7 @@ func TestGetUncommittedTransactions(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	tid1 := primitives.NewTransactionID()
 	tid2 := primitives.NewTransactionID()
@@ -638,7 +652,7 @@ func TestIsRecoveryNeeded_EmptyWAL(t *testing.T) {
 	testWAL, walPath := createTestWAL(t)
 	defer testWAL.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	needed, err := rm.IsRecoveryNeeded()
 	if err != nil {
@@ -661,7 +675,7 @@ func TestIsRecoveryNeeded_CommittedTransaction(t *testing.T) {
 	testWAL.LogUpdate(tid, newMockPageID(1), []byte("old"), []byte("new"))
 	testWAL.LogCommit(tid)
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	needed, err := rm.IsRecoveryNeeded()
 	if err != nil {
@@ -684,7 +698,7 @@ func TestIsRecoveryNeeded_UncommittedTransaction(t *testing.T) {
 	testWAL.LogUpdate(tid, newMockPageID(1), []byte("old"), []byte("new"))
 	// No commit - simulates crash
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	needed, err := rm.IsRecoveryNeeded()
 	if err != nil {
@@ -725,7 +739,7 @@ func TestFullRecoveryScenario(t *testing.T) {
 	testWAL.LogUpdate(tid3, newMockPageID(4), []byte("old3"), []byte("new3"))
 	testWAL.LogAbort(tid3)
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Check if recovery is needed
 	needed, err := rm.IsRecoveryNeeded()
@@ -799,7 +813,7 @@ func TestConcurrentAnalysis(t *testing.T) {
 		// Odd transactions left uncommitted
 	}
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 
 	// Run analysis
 	err := rm.analysisPhase()
@@ -838,14 +852,14 @@ func TestAnalysisPhase_CLRRecords(t *testing.T) {
 	reader, _ := wal.NewLogReader(walPath)
 	defer reader.Close()
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err := rm.analysisPhase()
 	if err != nil {
 		t.Fatalf("Analysis phase failed: %v", err)
 	}
 
 	// CLR should still mark the page as dirty
-	if _, exists := rm.dirtyPageTable[newMockPageID(1).HashCode()]; !exists {
+	if _, exists := rm.dirtyPageTable[primitives.KeyOf(newMockPageID(1))]; !exists {
 		t.Error("Page should be in dirty page table after CLR")
 	}
 
@@ -902,7 +916,7 @@ func TestRecoveryWithCheckpoint(t *testing.T) {
 	// Phase 3: Perform recovery
 	t.Log("Phase 3: Performing recovery")
 
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err = rm.Recover()
 	if err != nil {
 		t.Fatalf("Recovery failed: %v", err)
@@ -960,7 +974,7 @@ func TestRecoveryWithoutCheckpoint(t *testing.T) {
 	// tid2 not committed
 
 	// Perform recovery without checkpoint
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err := rm.Recover()
 	if err != nil {
 		t.Fatalf("Recovery without checkpoint failed: %v", err)
@@ -1009,7 +1023,7 @@ func TestCheckpointWithActiveTransactions(t *testing.T) {
 	}
 
 	// Perform recovery
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err = rm.Recover()
 	if err != nil {
 		t.Fatalf("Recovery with checkpoint failed: %v", err)
@@ -1060,7 +1074,7 @@ func TestCheckpointAndTruncation(t *testing.T) {
 	}
 
 	// Verify recovery works after checkpoint
-	rm := NewRecoveryManager(testWAL, walPath, nil)
+	rm := newTestRecoveryManager(testWAL, walPath)
 	err = rm.Recover()
 	if err != nil {
 		t.Fatalf("Recovery after checkpoint failed: %v", err)
@@ -1074,3 +1088,113 @@ func TestCheckpointAndTruncation(t *testing.T) {
 
 	t.Log("Checkpoint and truncation test passed")
 }
+
+func TestAnalysisPhase_CorruptRecord(t *testing.T) {
+	testWAL, walPath := createTestWAL(t)
+
+	committed := primitives.NewTransactionID()
+	testWAL.LogBegin(committed)
+	testWAL.LogUpdate(committed, newMockPageID(1), []byte("old"), []byte("new"))
+	testWAL.LogCommit(committed)
+
+	loser := primitives.NewTransactionID()
+	testWAL.LogBegin(loser)
+	corruptLSN, err := testWAL.LogUpdate(loser, newMockPageID(2), []byte("old"), []byte("new"))
+	if err != nil {
+		t.Fatalf("LogUpdate failed: %v", err)
+	}
+	testWAL.Close()
+
+	// Flip a bit of the loser's update, leaving its size intact
+	file, err := os.OpenFile(walPath, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open WAL file: %v", err)
+	}
+	b := make([]byte, 1)
+	offset := int64(corruptLSN) + record.RecordSize + record.TypeSize
+	if _, err := file.ReadAt(b, offset); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	b[0] ^= 0x01
+	if _, err := file.WriteAt(b, offset); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+	file.Close()
+
+	testWAL, err = wal.NewWAL(walPath, 4096)
+	if err != nil {
+		t.Fatalf("Failed to reopen WAL: %v", err)
+	}
+	defer testWAL.Close()
+
+	rm := newTestRecoveryManager(testWAL, walPath)
+	if err := rm.analysisPhase(); !errors.Is(err, wal.ErrCorruptRecord) {
+		t.Fatalf("Expected analysis to fail with ErrCorruptRecord, got %v", err)
+	}
+
+	rm = newTestRecoveryManager(testWAL, walPath)
+	rm.SetStopAtCorruptRecord(true)
+	if err := rm.analysisPhase(); err != nil {
+		t.Fatalf("Analysis phase failed: %v", err)
+	}
+	if rm.stats.LogRecordsScanned != 4 {
+		t.Errorf("Expected replay to stop after 4 records, scanned %d", rm.stats.LogRecordsScanned)
+	}
+	if info := rm.transactionTable[loser.ID()]; info == nil || info.LastLSN >= corruptLSN {
+		t.Errorf("Expected the loser to end before the corrupt record, got %+v", info)
+	}
+	if len(rm.dirtyPageTable) != 1 {
+		t.Errorf("Expected only the committed page to be dirty, got %d pages", len(rm.dirtyPageTable))
+	}
+}
+
+func TestUndoChain_AssertsPrevLSNMovesBackwards(t *testing.T) {
+	tid := primitives.NewTransactionIDFromValue(1)
+	records := map[primitives.LSN]*record.LogRecord{
+		100: {LSN: 100, Type: record.InsertRecord, TID: tid, PrevLSN: 200},
+		200: {LSN: 200, Type: record.InsertRecord, TID: tid, PrevLSN: 100},
+	}
+	lookup := func(lsn primitives.LSN) (*record.LogRecord, bool, error) {
+		rec, ok := records[lsn]
+		return rec, ok, nil
+	}
+
+	defer func() {
+		if _, ok := recover().(*invariant.Violation); !ok {
+			t.Error("expected an invariant violation for a looping undo chain")
+		}
+	}()
+	undoChain(&TransactionInfo{TID: tid, LastLSN: 200}, lookup)
+}
+
+func TestUndoChain_FailsOnReadError(t *testing.T) {
+	tid := primitives.NewTransactionIDFromValue(1)
+	readErr := errors.New("read failed")
+	lookup := func(lsn primitives.LSN) (*record.LogRecord, bool, error) {
+		if lsn == 100 {
+			return nil, false, readErr
+		}
+		return &record.LogRecord{LSN: lsn, Type: record.InsertRecord, TID: tid, PrevLSN: 100}, true, nil
+	}
+
+	if _, err := undoChain(&TransactionInfo{TID: tid, LastLSN: 200}, lookup); !errors.Is(err, readErr) {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
+
+func TestUndoChain_FailsOnMissingRecord(t *testing.T) {
+	tid := primitives.NewTransactionIDFromValue(1)
+	records := map[primitives.LSN]*record.LogRecord{
+		200: {LSN: 200, Type: record.InsertRecord, TID: tid, PrevLSN: 100},
+	}
+	lookup := func(lsn primitives.LSN) (*record.LogRecord, bool, error) {
+		rec, ok := records[lsn]
+		return rec, ok, nil
+	}
+
+	_, err := undoChain(&TransactionInfo{TID: tid, LastLSN: 200}, lookup)
+	var dbErr *dberror.DBError
+	if !errors.As(err, &dbErr) || dbErr.Code != ErrCodeRecoveryCorrupt {
+		t.Errorf("expected a %s error for a broken undo chain, got %v", ErrCodeRecoveryCorrupt, err)
+	}
+}
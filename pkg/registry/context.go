@@ -5,11 +5,16 @@ import (
 	"storemy/pkg/catalog/schema"
 	"storemy/pkg/catalog/systemtable"
 	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/config"
 	"storemy/pkg/indexmanager"
 	"storemy/pkg/log/wal"
 	"storemy/pkg/memory"
 	"storemy/pkg/memory/wrappers/table"
+	"storemy/pkg/optimizer"
+	"storemy/pkg/optimizer/feedback"
 	"storemy/pkg/primitives"
+	"storemy/pkg/sysview"
+	"storemy/pkg/trigger"
 )
 
 // DatabaseContext holds all shared components that are needed across the database system.
@@ -21,9 +26,22 @@ type DatabaseContext struct {
 	txRegistry   *transaction.TransactionRegistry
 	tupleManager *table.TupleManager
 	wal          *wal.WAL
+	settings     *config.Store
+	systemViews  *sysview.Registry
+	triggers     *trigger.Registry
+	feedback     *feedback.Store
+	rewriteRules *optimizer.RuleRegistry
+	modRecorders []ModificationRecorder
 	dataDir      string
 }
 
+// ModificationRecorder is told how many rows each INSERT, UPDATE and DELETE
+// changed, so statistics can be refreshed once enough of a table has changed
+// and cached query results over the table can be discarded.
+type ModificationRecorder interface {
+	RecordModifications(tableID primitives.FileID, tableName string, count int)
+}
+
 // catalogAdapter adapts catalogmanager.CatalogManager to indexmanager.CatalogReader
 type catalogAdapter struct {
 	cm *catalogmanager.CatalogManager
@@ -50,13 +68,24 @@ func NewDatabaseContext(
 
 	tupleManager.SetIndexManager(indexMgr)
 
+	txRegistry := transaction.NewTransactionRegistry(wal)
+	systemViews := sysview.NewRegistry()
+	if err := sysview.RegisterEngineViews(systemViews, txRegistry, pageStore, wal); err != nil {
+		// The built-in views are static; failing to build them is a programming error.
+		panic(err)
+	}
+
 	return &DatabaseContext{
 		pageStore:    pageStore,
 		catalogMgr:   catalogMgr,
 		indexManager: indexMgr,
-		txRegistry:   transaction.NewTransactionRegistry(wal),
+		txRegistry:   txRegistry,
 		tupleManager: tupleManager,
 		wal:          wal,
+		systemViews:  systemViews,
+		triggers:     trigger.NewRegistry(),
+		feedback:     feedback.NewStore(feedback.DefaultMaxEntries),
+		rewriteRules: optimizer.NewRuleRegistry(),
 		dataDir:      dataDir,
 	}
 }
@@ -88,3 +117,48 @@ func (ctx *DatabaseContext) TupleManager() *table.TupleManager {
 func (ctx *DatabaseContext) IndexManager() *indexmanager.IndexManager {
 	return ctx.indexManager
 }
+
+// Settings returns the persistent settings store, or nil if none was attached.
+func (ctx *DatabaseContext) Settings() *config.Store {
+	return ctx.settings
+}
+
+// SetSettings attaches the persistent settings store loaded from the superblock.
+func (ctx *DatabaseContext) SetSettings(settings *config.Store) {
+	ctx.settings = settings
+}
+
+// SystemViews returns the registry of read-only system views.
+func (ctx *DatabaseContext) SystemViews() *sysview.Registry {
+	return ctx.systemViews
+}
+
+// Triggers returns the registry of trigger functions.
+func (ctx *DatabaseContext) Triggers() *trigger.Registry {
+	return ctx.triggers
+}
+
+// CardinalityFeedback returns the row counts observed by EXPLAIN ANALYZE,
+// which the optimizer uses to correct its estimates.
+func (ctx *DatabaseContext) CardinalityFeedback() *feedback.Store {
+	return ctx.feedback
+}
+
+// RewriteRules returns the rewrite rules the optimizer applies after its
+// built-in ones.
+func (ctx *DatabaseContext) RewriteRules() *optimizer.RuleRegistry {
+	return ctx.rewriteRules
+}
+
+// AddModificationRecorder attaches a recorder notified by RecordModifications.
+func (ctx *DatabaseContext) AddModificationRecorder(recorder ModificationRecorder) {
+	ctx.modRecorders = append(ctx.modRecorders, recorder)
+}
+
+// RecordModifications reports count changed rows of a table to the attached
+// recorders. It does nothing if no recorder is attached.
+func (ctx *DatabaseContext) RecordModifications(tableID primitives.FileID, tableName string, count int) {
+	for _, recorder := range ctx.modRecorders {
+		recorder.RecordModifications(tableID, tableName, count)
+	}
+}
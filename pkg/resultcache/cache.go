@@ -0,0 +1,236 @@
+// Package resultcache caches the results of read-only queries so repeated
+// queries over tables that have not changed skip planning and execution,
+// which pays off for read-heavy workloads such as dashboards.
+//
+// Every write to a table bumps a global version counter and records it as the
+// table's version. A result is stored together with the version current when
+// its query started: if any of the query's tables was written since, the
+// result may be stale and is not stored. Writes also evict the cached results
+// over the written table right away, so a lookup never has to check versions.
+package resultcache
+
+import (
+	"container/list"
+	"storemy/pkg/primitives"
+	"strings"
+	"sync"
+)
+
+// Config limits the size of a cache. A zero MaxEntries disables caching; a
+// zero MaxBytes places no limit on the total result size.
+type Config struct {
+	MaxEntries int
+	MaxBytes   int64
+}
+
+// Enabled reports whether the configuration caches anything.
+func (c Config) Enabled() bool {
+	return c.MaxEntries > 0
+}
+
+// Stats reports how well the cache is doing.
+type Stats struct {
+	Entries       int
+	Bytes         int64
+	Hits          int64
+	Misses        int64
+	Stores        int64
+	Evictions     int64 // Entries dropped to respect the size limits
+	Invalidations int64 // Entries dropped because one of their tables was written
+}
+
+// HitRatio returns the fraction of lookups answered from the cache.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// entry is a cached result together with the tables it was computed from.
+type entry struct {
+	key    string
+	tables []string
+	value  any
+	size   int64
+}
+
+// Cache is an LRU cache of query results. It is safe for concurrent use.
+type Cache struct {
+	config Config
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element // Elements hold *entry, most recently used first
+	lru     *list.List
+	byTable map[string]map[string]struct{} // Table name -> keys of the entries reading it
+
+	version       uint64            // Bumped by every write
+	tableVersions map[string]uint64 // Version of the last write to each table
+	flushVersion  uint64            // Version of the last InvalidateAll
+
+	stats Stats
+}
+
+// New creates a cache with the given limits.
+func New(config Config) *Cache {
+	return &Cache{
+		config:        config,
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+		byTable:       make(map[string]map[string]struct{}),
+		tableVersions: make(map[string]uint64),
+	}
+}
+
+// Enabled reports whether the cache stores anything.
+func (c *Cache) Enabled() bool {
+	return c.config.Enabled()
+}
+
+// Config returns the limits the cache was created with.
+func (c *Cache) Config() Config {
+	return c.config
+}
+
+// Version returns the current write version. Callers take it before running
+// a query and pass it to Put with the query's result.
+func (c *Cache) Version() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.version
+}
+
+// Get returns the cached result for key.
+func (c *Cache) Get(key string) (any, bool) {
+	if !c.Enabled() {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+	return elem.Value.(*entry).value, true
+}
+
+// Put caches value, of size bytes, as the result of key computed from tables
+// by a query that started at version. It returns false if the result was not
+// stored: because a table was written after the query started, or because
+// the result alone exceeds MaxBytes.
+func (c *Cache) Put(key string, tables []string, version uint64, value any, size int64) bool {
+	if !c.Enabled() {
+		return false
+	}
+	if c.config.MaxBytes > 0 && size > c.config.MaxBytes {
+		return false
+	}
+
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = strings.ToUpper(table)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.flushVersion > version {
+		return false
+	}
+	for _, table := range names {
+		if c.tableVersions[table] > version {
+			return false
+		}
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeLocked(elem)
+	}
+
+	e := &entry{key: key, tables: names, value: value, size: size}
+	c.entries[key] = c.lru.PushFront(e)
+	c.stats.Bytes += size
+	c.stats.Stores++
+	for _, table := range names {
+		keys, ok := c.byTable[table]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.byTable[table] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for len(c.entries) > c.config.MaxEntries || (c.config.MaxBytes > 0 && c.stats.Bytes > c.config.MaxBytes) {
+		c.removeLocked(c.lru.Back())
+		c.stats.Evictions++
+	}
+	return true
+}
+
+// Invalidate records a write to table and drops the results that read it.
+func (c *Cache) Invalidate(table string) {
+	table = strings.ToUpper(table)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.version++
+	c.tableVersions[table] = c.version
+	for key := range c.byTable[table] {
+		c.removeLocked(c.entries[key])
+		c.stats.Invalidations++
+	}
+}
+
+// RecordModifications invalidates the results over a table that count rows
+// of were just inserted, updated or deleted. It lets the cache observe writes
+// as a registry.ModificationRecorder.
+func (c *Cache) RecordModifications(_ primitives.FileID, tableName string, count int) {
+	if count > 0 {
+		c.Invalidate(tableName)
+	}
+}
+
+// InvalidateAll drops every cached result, e.g. after a schema change.
+func (c *Cache) InvalidateAll() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.version++
+	c.flushVersion = c.version
+	c.stats.Invalidations += int64(len(c.entries))
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.byTable = make(map[string]map[string]struct{})
+	c.stats.Bytes = 0
+}
+
+// Stats returns a snapshot of the cache statistics.
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+// removeLocked drops the entry held by elem. The caller must hold c.mutex.
+func (c *Cache) removeLocked(elem *list.Element) {
+	e := elem.Value.(*entry)
+	c.lru.Remove(elem)
+	delete(c.entries, e.key)
+	c.stats.Bytes -= e.size
+	for _, table := range e.tables {
+		keys := c.byTable[table]
+		delete(keys, e.key)
+		if len(keys) == 0 {
+			delete(c.byTable, table)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package resultcache
+
+import "testing"
+
+func TestCache_GetPut(t *testing.T) {
+	c := New(Config{MaxEntries: 10})
+
+	if _, ok := c.Get("q"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	if !c.Put("q", []string{"users"}, c.Version(), "result", 6) {
+		t.Fatal("expected Put to store the result")
+	}
+
+	value, ok := c.Get("q")
+	if !ok || value != "result" {
+		t.Fatalf("expected a hit with the stored result, got %v, %v", value, ok)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 || stats.Bytes != 6 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.HitRatio() != 0.5 {
+		t.Errorf("expected hit ratio 0.5, got %v", stats.HitRatio())
+	}
+}
+
+func TestCache_Disabled(t *testing.T) {
+	c := New(Config{})
+	if c.Put("q", nil, c.Version(), "result", 1) {
+		t.Error("expected a disabled cache not to store results")
+	}
+	if _, ok := c.Get("q"); ok {
+		t.Error("expected a disabled cache to miss")
+	}
+}
+
+func TestCache_InvalidateOnWrite(t *testing.T) {
+	c := New(Config{MaxEntries: 10})
+	c.Put("users", []string{"users"}, c.Version(), 1, 1)
+	c.Put("orders", []string{"orders"}, c.Version(), 2, 1)
+	c.Put("join", []string{"users", "orders"}, c.Version(), 3, 1)
+
+	c.RecordModifications(0, "USERS", 1)
+
+	for _, key := range []string{"users", "join"} {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("expected %s to be invalidated by a write to USERS", key)
+		}
+	}
+	if _, ok := c.Get("orders"); !ok {
+		t.Error("expected orders to survive a write to USERS")
+	}
+	if got := c.Stats().Invalidations; got != 2 {
+		t.Errorf("expected 2 invalidations, got %d", got)
+	}
+
+	c.RecordModifications(0, "orders", 0)
+	if _, ok := c.Get("orders"); !ok {
+		t.Error("expected a write of zero rows not to invalidate anything")
+	}
+}
+
+func TestCache_RejectsResultsOlderThanWrite(t *testing.T) {
+	c := New(Config{MaxEntries: 10})
+
+	version := c.Version()
+	c.Invalidate("users") // Written while the query ran
+	if c.Put("q", []string{"users"}, version, 1, 1) {
+		t.Error("expected a result computed before a write to its table to be rejected")
+	}
+	if !c.Put("other", []string{"orders"}, version, 1, 1) {
+		t.Error("expected a result over an unwritten table to be stored")
+	}
+
+	version = c.Version()
+	c.InvalidateAll()
+	if c.Put("q", []string{"orders"}, version, 1, 1) {
+		t.Error("expected a result computed before InvalidateAll to be rejected")
+	}
+	if c.Stats().Entries != 0 {
+		t.Errorf("expected InvalidateAll to empty the cache, got %d entries", c.Stats().Entries)
+	}
+}
+
+func TestCache_SizeLimits(t *testing.T) {
+	c := New(Config{MaxEntries: 2, MaxBytes: 100})
+
+	c.Put("a", nil, c.Version(), 1, 10)
+	c.Put("b", nil, c.Version(), 2, 10)
+	c.Get("a") // b is now the least recently used
+	c.Put("c", nil, c.Version(), 3, 10)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a recently used entry to be kept")
+	}
+
+	if c.Put("huge", nil, c.Version(), 4, 101) {
+		t.Error("expected a result larger than MaxBytes to be rejected")
+	}
+
+	c.Put("d", nil, c.Version(), 5, 85)
+	stats := c.Stats()
+	if stats.Bytes > 100 {
+		t.Errorf("expected at most 100 bytes cached, got %d", stats.Bytes)
+	}
+	if stats.Evictions != 2 {
+		t.Errorf("expected 2 evictions, got %d", stats.Evictions)
+	}
+}
@@ -0,0 +1,181 @@
+package stmtstats
+
+import (
+	"hash/fnv"
+	"slices"
+	"storemy/pkg/parser/lexer"
+	"strings"
+	"unicode"
+)
+
+// Placeholder replaces every literal in a normalized query.
+const Placeholder = "?"
+
+// Normalize rewrites query into the canonical form used to group statements:
+//
+//   - string, numeric and boolean literals are replaced with ?
+//   - keywords and identifiers are upper-cased (as the lexer does)
+//   - whitespace is collapsed and trailing semicolons are dropped
+//   - repeated identical parenthesised groups, such as the rows of a
+//     multi-row INSERT, are collapsed into a single group
+//
+// For example, both "select * from t where id = 1" and
+// "SELECT *  FROM t WHERE id=42;" normalize to "SELECT * FROM T WHERE ID = ?".
+func Normalize(query string) string {
+	l := lexer.NewLexer(query)
+
+	var words []string
+	prev := lexer.Token{Type: lexer.EOF}
+	for {
+		tok := l.NextToken()
+		if tok.Type == lexer.EOF {
+			break
+		}
+
+		switch {
+		case isLiteral(tok):
+			if isSign(prev) && expectsOperand(words) {
+				// "-5" lexes as INVALID("-") INT("5"); fold the sign into the literal.
+				words[len(words)-1] = Placeholder
+			} else if !isDecimalContinuation(prev, words) {
+				words = append(words, Placeholder)
+			}
+		case tok.Type == lexer.INVALID && tok.Value == "." && len(words) > 0 && words[len(words)-1] == Placeholder:
+			// "1.5" lexes as INT("1") INVALID(".") INT("5"); the fraction is absorbed below.
+		case tok.Type == lexer.SEMICOLON:
+		default:
+			words = append(words, tokenText(tok))
+		}
+		prev = tok
+	}
+
+	words = collapseRepeatedGroups(words)
+	return join(words)
+}
+
+// Fingerprint returns a stable 64-bit identifier for a normalized query.
+func Fingerprint(normalized string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return h.Sum64()
+}
+
+func isLiteral(tok lexer.Token) bool {
+	switch tok.Type {
+	case lexer.STRING:
+		return true
+	case lexer.INT:
+		// INT is also the column type keyword; numeric literals start with a digit.
+		return tok.Value != "" && unicode.IsDigit(rune(tok.Value[0]))
+	case lexer.IDENTIFIER:
+		return tok.Value == "TRUE" || tok.Value == "FALSE"
+	default:
+		return false
+	}
+}
+
+func isSign(tok lexer.Token) bool {
+	return tok.Type == lexer.INVALID && (tok.Value == "-" || tok.Value == "+")
+}
+
+// expectsOperand reports whether the sign at the end of words is unary: it
+// starts the query or follows an operator, comma, opening parenthesis or a
+// keyword that introduces a value.
+func expectsOperand(words []string) bool {
+	if len(words) < 2 {
+		return true
+	}
+	before := words[len(words)-2]
+	if isKeywordBeforeValue(before) {
+		return true
+	}
+	return before != Placeholder && before != ")" && !isIdentifierLike(before)
+}
+
+func isIdentifierLike(word string) bool {
+	for _, r := range word {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+			return false
+		}
+	}
+	return word != ""
+}
+
+func isKeywordBeforeValue(word string) bool {
+	switch word {
+	case "VALUES", "LIMIT", "OFFSET", "AND", "OR", "NOT", "SET", "DEFAULT":
+		return true
+	default:
+		return false
+	}
+}
+
+// isDecimalContinuation reports whether a numeric literal is the fractional
+// part of the literal before it ("1" "." "5").
+func isDecimalContinuation(prev lexer.Token, words []string) bool {
+	return prev.Type == lexer.INVALID && prev.Value == "." && len(words) > 0 && words[len(words)-1] == Placeholder
+}
+
+func tokenText(tok lexer.Token) string {
+	if tok.Type == lexer.STRING {
+		return Placeholder
+	}
+	return tok.Value
+}
+
+// collapseRepeatedGroups removes parenthesised groups that repeat the group
+// immediately before them: "(?, ?), (?, ?)" becomes "(?, ?)".
+func collapseRepeatedGroups(words []string) []string {
+	out := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		out = append(out, words[i])
+		if words[i] != ")" {
+			continue
+		}
+
+		start := matchingParen(out, len(out)-1)
+		if start < 0 {
+			continue
+		}
+		group := out[start:]
+
+		for i+1 < len(words) && words[i+1] == "," && hasPrefix(words[i+2:], group) {
+			i += 1 + len(group)
+		}
+	}
+	return out
+}
+
+// matchingParen returns the index of the "(" matching the ")" at end, or -1.
+func matchingParen(words []string, end int) int {
+	depth := 0
+	for i := end; i >= 0; i-- {
+		switch words[i] {
+		case ")":
+			depth++
+		case "(":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func hasPrefix(words, prefix []string) bool {
+	return len(words) >= len(prefix) && slices.Equal(words[:len(prefix)], prefix)
+}
+
+// join concatenates words with single spaces, without spaces inside
+// parentheses or before commas.
+func join(words []string) string {
+	var sb strings.Builder
+	for i, w := range words {
+		if i > 0 && w != "," && w != ")" && words[i-1] != "(" {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(w)
+	}
+	return sb.String()
+}
@@ -0,0 +1,129 @@
+// Package stmtstats aggregates execution statistics per statement
+// fingerprint. Queries that differ only in their literal values share a
+// fingerprint, so the collector shows which kinds of statements the database
+// spends its time on, similar to PostgreSQL's pg_stat_statements.
+package stmtstats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is the number of distinct fingerprints tracked by default.
+const DefaultMaxEntries = 1000
+
+// Entry holds the statistics aggregated for one fingerprint.
+type Entry struct {
+	QueryID   uint64 // Fingerprint of Query
+	Query     string // Normalized query text
+	Calls     int64
+	TotalTime time.Duration
+	MaxTime   time.Duration
+	Rows      int64 // Rows returned or affected, summed over all calls
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// MeanTime returns the average execution time per call.
+func (e Entry) MeanTime() time.Duration {
+	if e.Calls == 0 {
+		return 0
+	}
+	return e.TotalTime / time.Duration(e.Calls)
+}
+
+// Collector accumulates statement statistics. It is safe for concurrent use.
+type Collector struct {
+	entries    map[uint64]*Entry
+	maxEntries int
+	resetAt    time.Time
+	mutex      sync.Mutex
+}
+
+// NewCollector creates a collector tracking at most maxEntries fingerprints.
+// When full, the least frequently called fingerprint is evicted to make room
+// for a new one. A non-positive maxEntries uses DefaultMaxEntries.
+func NewCollector(maxEntries int) *Collector {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Collector{
+		entries:    make(map[uint64]*Entry),
+		maxEntries: maxEntries,
+		resetAt:    time.Now(),
+	}
+}
+
+// Record adds one execution of query that took elapsed and produced rows rows.
+func (c *Collector) Record(query string, elapsed time.Duration, rows int64) {
+	normalized := Normalize(query)
+	id := Fingerprint(normalized)
+	now := time.Now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		if len(c.entries) >= c.maxEntries {
+			c.evictLocked()
+		}
+		e = &Entry{QueryID: id, Query: normalized, FirstSeen: now}
+		c.entries[id] = e
+	}
+
+	e.Calls++
+	e.TotalTime += elapsed
+	e.MaxTime = max(e.MaxTime, elapsed)
+	e.Rows += rows
+	e.LastSeen = now
+}
+
+// evictLocked removes the entry with the fewest calls, breaking ties by the
+// oldest last use. The caller must hold c.mutex.
+func (c *Collector) evictLocked() {
+	var victim *Entry
+	for _, e := range c.entries {
+		if victim == nil || e.Calls < victim.Calls ||
+			(e.Calls == victim.Calls && e.LastSeen.Before(victim.LastSeen)) {
+			victim = e
+		}
+	}
+	if victim != nil {
+		delete(c.entries, victim.QueryID)
+	}
+}
+
+// Snapshot returns a copy of all entries ordered by total time, highest first.
+func (c *Collector) Snapshot() []Entry {
+	c.mutex.Lock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, *e)
+	}
+	c.mutex.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].TotalTime != entries[j].TotalTime {
+			return entries[i].TotalTime > entries[j].TotalTime
+		}
+		return entries[i].QueryID < entries[j].QueryID
+	})
+	return entries
+}
+
+// Reset discards all collected statistics.
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[uint64]*Entry)
+	c.resetAt = time.Now()
+}
+
+// ResetAt returns when statistics were last reset, or when the collector was created.
+func (c *Collector) ResetAt() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.resetAt
+}
@@ -0,0 +1,98 @@
+package stmtstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"integer literal", "select * from users where id = 42", "SELECT * FROM USERS WHERE ID = ?"},
+		{"string literal", "SELECT name FROM users WHERE name = 'alice'", "SELECT NAME FROM USERS WHERE NAME = ?"},
+		{"whitespace and semicolon", "SELECT  *\n FROM users   WHERE id=1;", "SELECT * FROM USERS WHERE ID = ?"},
+		{"negative number", "SELECT * FROM t WHERE x > -5", "SELECT * FROM T WHERE X > ?"},
+		{"decimal number", "UPDATE t SET price = 1.25 WHERE id = 3", "UPDATE T SET PRICE = ? WHERE ID = ?"},
+		{"boolean literal", "SELECT * FROM t WHERE active = true", "SELECT * FROM T WHERE ACTIVE = ?"},
+		{"limit and offset", "SELECT * FROM t LIMIT 10 OFFSET 20", "SELECT * FROM T LIMIT ? OFFSET ?"},
+		{"multi-row insert", "INSERT INTO t VALUES (1, 'a'), (2, 'b'), (3, 'c')", "INSERT INTO T VALUES (?, ?)"},
+		{"column type keyword kept", "CREATE TABLE t (id INT, name VARCHAR)", "CREATE TABLE T (ID INT, NAME VARCHAR)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.query); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFingerprint_SameForDifferentLiterals(t *testing.T) {
+	a := Fingerprint(Normalize("SELECT * FROM users WHERE id = 1"))
+	b := Fingerprint(Normalize("select * from USERS where id = 99"))
+	c := Fingerprint(Normalize("SELECT * FROM users WHERE age = 1"))
+
+	if a != b {
+		t.Error("expected queries differing only in literals to share a fingerprint")
+	}
+	if a == c {
+		t.Error("expected queries on different columns to have different fingerprints")
+	}
+}
+
+func TestCollector_Aggregates(t *testing.T) {
+	c := NewCollector(0)
+	c.Record("SELECT * FROM t WHERE id = 1", 10*time.Millisecond, 1)
+	c.Record("SELECT * FROM t WHERE id = 2", 30*time.Millisecond, 0)
+	c.Record("DELETE FROM t", time.Millisecond, 5)
+
+	entries := c.Snapshot()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 fingerprints, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.Query != "SELECT * FROM T WHERE ID = ?" {
+		t.Fatalf("expected slowest statement first, got %q", e.Query)
+	}
+	if e.Calls != 2 || e.Rows != 1 {
+		t.Errorf("expected 2 calls and 1 row, got %d calls and %d rows", e.Calls, e.Rows)
+	}
+	if e.TotalTime != 40*time.Millisecond || e.MaxTime != 30*time.Millisecond || e.MeanTime() != 20*time.Millisecond {
+		t.Errorf("unexpected timings: total=%v max=%v mean=%v", e.TotalTime, e.MaxTime, e.MeanTime())
+	}
+}
+
+func TestCollector_EvictsLeastCalled(t *testing.T) {
+	c := NewCollector(2)
+	c.Record("SELECT * FROM a", time.Millisecond, 0)
+	c.Record("SELECT * FROM a", time.Millisecond, 0)
+	c.Record("SELECT * FROM b", time.Millisecond, 0)
+	c.Record("SELECT * FROM c", time.Millisecond, 0)
+
+	queries := make(map[string]bool)
+	for _, e := range c.Snapshot() {
+		queries[e.Query] = true
+	}
+	if len(queries) != 2 || !queries["SELECT * FROM A"] || !queries["SELECT * FROM C"] {
+		t.Errorf("expected B to be evicted, got %v", queries)
+	}
+}
+
+func TestCollector_Reset(t *testing.T) {
+	c := NewCollector(0)
+	before := c.ResetAt()
+	c.Record("SELECT * FROM t", time.Millisecond, 0)
+
+	c.Reset()
+	if len(c.Snapshot()) != 0 {
+		t.Error("expected no entries after Reset")
+	}
+	if c.ResetAt().Before(before) {
+		t.Error("expected reset time to advance")
+	}
+}
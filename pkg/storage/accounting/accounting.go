@@ -0,0 +1,139 @@
+// Package accounting reports the storage used by each table of a database:
+// the size of its heap file and indexes, and how the heap pages divide into
+// live tuples, dead space left by deletes and free space.
+//
+// Like fsck, it reads heap pages from disk rather than through the buffer
+// pool, so the report covers committed data; changes of statements still
+// running are not counted. Dead tuples are estimated from the dead space,
+// since a deleted tuple leaves no trace but the bytes it occupied.
+//
+// A QuotaTracker uses the same accounting to keep a database within a Quota,
+// refusing inserts that would take it past its size or a table past its row
+// limit.
+package accounting
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/heap"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/vfs"
+)
+
+// TableUsage is the storage used by one table.
+type TableUsage struct {
+	Table string
+
+	HeapBytes  int64 // Size of the heap file
+	HeapPages  int64
+	LiveTuples int64
+	LiveBytes  int64 // Bytes of the live tuples
+	DeadTuples int64 // Estimated from DeadBytes
+	DeadBytes  int64 // Space of deleted tuples not yet reclaimed by compaction
+	FreeBytes  int64 // Space of the heap pages available for new tuples
+
+	Indexes    int
+	IndexBytes int64 // Size of the table's index files, overflow pages included
+}
+
+// TotalBytes is the size of the table's files.
+func (u TableUsage) TotalBytes() int64 {
+	return u.HeapBytes + u.IndexBytes
+}
+
+// Collect returns the storage used by every user table of the catalog,
+// largest first. Index files are looked up on fsys.
+func Collect(cm *catalogmanager.CatalogManager, fsys vfs.FS, tx *transaction.TransactionContext) ([]TableUsage, error) {
+	tables, err := cm.GetAllTables(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table catalog: %w", err)
+	}
+	indexes, err := cm.NewIndexOps(tx).GetAllIndexes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index catalog: %w", err)
+	}
+
+	byTable := make(map[primitives.FileID][]*systemtable.IndexMetadata)
+	for _, im := range indexes {
+		byTable[im.TableID] = append(byTable[im.TableID], im)
+	}
+
+	usages := make([]TableUsage, 0, len(tables))
+	for _, tm := range tables {
+		u, err := collectTable(cm, fsys, tm, byTable[tm.TableID])
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", tm.TableName, err)
+		}
+		usages = append(usages, u)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].TotalBytes() > usages[j].TotalBytes()
+	})
+	return usages, nil
+}
+
+// collectTable measures one table and its indexes.
+func collectTable(cm *catalogmanager.CatalogManager, fsys vfs.FS, tm *systemtable.TableMetadata, indexes []*systemtable.IndexMetadata) (TableUsage, error) {
+	u := TableUsage{Table: tm.TableName, Indexes: len(indexes)}
+
+	file, err := cm.GetTableFile(tm.TableID)
+	if err != nil {
+		return u, fmt.Errorf("table is not loaded: %w", err)
+	}
+	hf, ok := file.(*heap.HeapFile)
+	if !ok {
+		return u, fmt.Errorf("table file is %T, not a heap file", file)
+	}
+	if err := collectHeap(&u, hf); err != nil {
+		return u, err
+	}
+
+	for _, im := range indexes {
+		info, err := fsys.Stat(string(im.FilePath))
+		if os.IsNotExist(err) {
+			continue // fsck reports the missing file
+		}
+		if err != nil {
+			return u, fmt.Errorf("cannot stat index %s: %w", im.IndexName, err)
+		}
+		u.IndexBytes += info.Size()
+	}
+	return u, nil
+}
+
+// collectHeap adds up the usage of every page of hf.
+func collectHeap(u *TableUsage, hf *heap.HeapFile) error {
+	info, err := hf.GetFile().Stat()
+	if err != nil {
+		return fmt.Errorf("cannot stat heap file: %w", err)
+	}
+	u.HeapBytes = info.Size()
+	numPages := primitives.PageNumber(info.Size() / int64(page.PageSize))
+	u.HeapPages = int64(numPages)
+
+	td := hf.GetTupleDesc()
+	for pageNo := primitives.PageNumber(0); pageNo < numPages; pageNo++ {
+		data, err := hf.ReadPageData(pageNo)
+		if err != nil {
+			return fmt.Errorf("cannot read page %d: %w", pageNo, err)
+		}
+		hp, err := heap.NewHeapPage(page.NewPageDescriptor(hf.GetID(), pageNo), data, td)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", pageNo, err)
+		}
+
+		pu := hp.Usage()
+		u.LiveTuples += int64(pu.LiveTuples)
+		u.LiveBytes += int64(pu.LiveBytes)
+		u.DeadTuples += int64(pu.DeadTuples)
+		u.DeadBytes += int64(pu.DeadBytes)
+		u.FreeBytes += int64(pu.FreeBytes)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package accounting
+
+import "storemy/pkg/metrics"
+
+var (
+	sizeQuotaRefusals = metrics.NewCounter(
+		"storemy_size_quota_refusals_total",
+		"Inserts refused because the database reached its size quota",
+	)
+	rowQuotaRefusals = metrics.NewCounter(
+		"storemy_row_quota_refusals_total",
+		"Inserts refused because a table reached its row quota",
+	)
+)
@@ -0,0 +1,269 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/catalog/catalogmanager"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/memory"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/heap"
+	"storemy/pkg/storage/page"
+	"sync"
+)
+
+// ErrQuotaExceeded is returned (wrapped in a *QuotaError) when an insert
+// would take a database or table past its quota.
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// Kinds of quota, reported by QuotaError.
+const (
+	SizeQuota = "size"
+	RowQuota  = "rows"
+)
+
+// QuotaError describes an insert that was refused. It matches
+// ErrQuotaExceeded with errors.Is.
+type QuotaError struct {
+	Kind  string // SizeQuota or RowQuota
+	Table string // Table the insert was into
+	Used  int64  // Bytes of the database, or rows of the table
+	Limit int64  // Quota of Kind
+}
+
+func (e *QuotaError) Error() string {
+	if e.Kind == SizeQuota {
+		return fmt.Sprintf("%s: database size quota of %d bytes reached (%d bytes in use, inserting into %s)",
+			ErrQuotaExceeded, e.Limit, e.Used, e.Table)
+	}
+	return fmt.Sprintf("%s: table %s has reached its quota of %d rows (%d rows in use)",
+		ErrQuotaExceeded, e.Table, e.Limit, e.Used)
+}
+
+// Is reports whether target is ErrQuotaExceeded.
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// Quota limits how much a database may grow. Zero limits are unlimited.
+type Quota struct {
+	// MaxBytes limits the total size of the page files of the database:
+	// tables, indexes and the system catalog. An insert that needs a new
+	// heap page once the files reach it fails.
+	MaxBytes int64
+
+	// MaxTableRows limits the rows of each user table.
+	MaxTableRows int64
+}
+
+// Enabled reports whether q limits anything.
+func (q Quota) Enabled() bool {
+	return q.MaxBytes > 0 || q.MaxTableRows > 0
+}
+
+// QuotaTracker enforces a Quota on the inserts of a database, as the
+// table.Quota of its TupleManager. System tables are never refused, but
+// their files count towards MaxBytes.
+//
+// The rows of a table are counted from disk the first time it is checked,
+// and then kept up to date with the rows transactions reserve and release.
+// A transaction's own changes count for it at once, but only the rows other
+// transactions add count against it until they commit; rows they delete are
+// only freed by their commit, and everything is forgotten if they abort.
+// The rows a statement reserved or released are recorded on its savepoint
+// too, and given back when the statement is rolled back, so rows whose
+// statements were rolled back never keep an insert out. A table about to be
+// refused is recounted first.
+//
+// The size is read from the page files when a page is added, so inserts
+// running together may take the database a few pages past MaxBytes.
+type QuotaTracker struct {
+	quota Quota
+	cm    *catalogmanager.CatalogManager
+	store *memory.PageStore
+
+	mutex   sync.Mutex
+	tables  map[primitives.FileID]*tableRows
+	pending map[*transaction.TransactionContext]map[primitives.FileID]int64 // Rows reserved (positive) or released (negative) by running transactions
+}
+
+// tableRows is the committed row count of a table.
+type tableRows struct {
+	file *heap.HeapFile // File the rows were counted in; a table recreated under the same ID is recounted
+	rows int64
+}
+
+// NewQuotaTracker creates a tracker enforcing quota on the tables of cm,
+// whose page files are registered with store.
+func NewQuotaTracker(quota Quota, cm *catalogmanager.CatalogManager, store *memory.PageStore) *QuotaTracker {
+	return &QuotaTracker{
+		quota:   quota,
+		cm:      cm,
+		store:   store,
+		tables:  make(map[primitives.FileID]*tableRows),
+		pending: make(map[*transaction.TransactionContext]map[primitives.FileID]int64),
+	}
+}
+
+// Quota returns the quota the tracker enforces.
+func (q *QuotaTracker) Quota() Quota {
+	return q.quota
+}
+
+// ReserveRows reserves n rows of f for ctx. It fails with a *QuotaError if
+// the table would exceed MaxTableRows.
+func (q *QuotaTracker) ReserveRows(ctx *transaction.TransactionContext, f *heap.HeapFile, n int64) error {
+	if q.quota.MaxTableRows <= 0 || q.isSystemTable(f.GetID()) {
+		return nil
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.settle()
+
+	t, err := q.table(f, false)
+	if err != nil {
+		return err
+	}
+	used := q.rowsUsed(ctx, f.GetID(), t)
+	if used+n > q.quota.MaxTableRows {
+		if t, err = q.table(f, true); err != nil {
+			return err
+		}
+		used = q.rowsUsed(ctx, f.GetID(), t)
+	}
+	if used+n > q.quota.MaxTableRows {
+		rowQuotaRefusals.Inc()
+		return &QuotaError{Kind: RowQuota, Table: q.tableName(ctx, f), Used: used, Limit: q.quota.MaxTableRows}
+	}
+
+	q.addPending(ctx, f.GetID(), n)
+	return nil
+}
+
+// ReleaseRows releases n rows of f, reserved or deleted by ctx.
+func (q *QuotaTracker) ReleaseRows(ctx *transaction.TransactionContext, f *heap.HeapFile, n int64) {
+	if q.quota.MaxTableRows <= 0 || q.isSystemTable(f.GetID()) {
+		return
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.addPending(ctx, f.GetID(), -n)
+}
+
+// RollbackStatement gives back the rows the statement running in ctx
+// reserved and released, as its changes are about to be undone. It must be
+// called before the statement's savepoint is released.
+func (q *QuotaTracker) RollbackStatement(ctx *transaction.TransactionContext) {
+	sp := ctx.Statement()
+	if sp == nil {
+		return
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	deltas, ok := q.pending[ctx]
+	if !ok {
+		return
+	}
+	for id, n := range sp.Rows() {
+		deltas[id] -= n
+	}
+}
+
+// ReservePage fails with a *QuotaError if adding a page to f would take the
+// database past MaxBytes.
+func (q *QuotaTracker) ReservePage(ctx *transaction.TransactionContext, f *heap.HeapFile) error {
+	if q.quota.MaxBytes <= 0 || q.isSystemTable(f.GetID()) {
+		return nil
+	}
+
+	used, err := q.store.FileBytes()
+	if err != nil {
+		return fmt.Errorf("failed to measure the database: %w", err)
+	}
+	if used+int64(page.PageSize) > q.quota.MaxBytes {
+		sizeQuotaRefusals.Inc()
+		return &QuotaError{Kind: SizeQuota, Table: q.tableName(ctx, f), Used: used, Limit: q.quota.MaxBytes}
+	}
+	return nil
+}
+
+// settle folds the rows of the transactions that committed since the last
+// call into the row counts, and forgets those of the transactions that
+// aborted. The caller holds q.mutex.
+func (q *QuotaTracker) settle() {
+	for ctx, deltas := range q.pending {
+		switch ctx.GetStatus() {
+		case transaction.TxCommitted:
+			for id, n := range deltas {
+				if t, ok := q.tables[id]; ok {
+					t.rows += n
+				}
+			}
+			delete(q.pending, ctx)
+		case transaction.TxAborted:
+			delete(q.pending, ctx)
+		}
+	}
+}
+
+// table returns the row count of f, counting its rows on disk if they were
+// not counted yet, were counted in another file, or recount is set. The
+// caller holds q.mutex.
+func (q *QuotaTracker) table(f *heap.HeapFile, recount bool) (*tableRows, error) {
+	t, ok := q.tables[f.GetID()]
+	if ok && t.file == f && !recount {
+		return t, nil
+	}
+
+	var u TableUsage
+	if err := collectHeap(&u, f); err != nil {
+		return nil, fmt.Errorf("failed to count the rows of the table: %w", err)
+	}
+	t = &tableRows{file: f, rows: u.LiveTuples}
+	q.tables[f.GetID()] = t
+	return t, nil
+}
+
+// rowsUsed returns the rows of table id that count against ctx: the
+// committed ones, those ctx reserved or released, and those other
+// transactions reserved. The caller holds q.mutex.
+func (q *QuotaTracker) rowsUsed(ctx *transaction.TransactionContext, id primitives.FileID, t *tableRows) int64 {
+	used := t.rows
+	for other, deltas := range q.pending {
+		if n := deltas[id]; other == ctx || n > 0 {
+			used += n
+		}
+	}
+	return used
+}
+
+// addPending adds n rows of table id to those ctx reserved, and to those of
+// the statement it is running. The caller holds q.mutex.
+func (q *QuotaTracker) addPending(ctx *transaction.TransactionContext, id primitives.FileID, n int64) {
+	deltas, ok := q.pending[ctx]
+	if !ok {
+		deltas = make(map[primitives.FileID]int64)
+		q.pending[ctx] = deltas
+	}
+	deltas[id] += n
+	if sp := ctx.Statement(); sp != nil {
+		sp.AddRows(id, n)
+	}
+}
+
+// isSystemTable reports whether id is a table of the system catalog.
+func (q *QuotaTracker) isSystemTable(id primitives.FileID) bool {
+	_, err := q.cm.SystemTabs.GetSysTable(id)
+	return err == nil
+}
+
+// tableName returns the name of the table of f, for error messages.
+func (q *QuotaTracker) tableName(ctx *transaction.TransactionContext, f *heap.HeapFile) string {
+	if name, err := q.cm.GetTableName(ctx, f.GetID()); err == nil {
+		return name
+	}
+	return fmt.Sprintf("%d", f.GetID())
+}
@@ -0,0 +1,75 @@
+package heap
+
+import (
+	"fmt"
+	dberror "storemy/pkg/error"
+)
+
+// Error codes of the DBErrors returned by heap files and pages
+const (
+	// ErrCodeIO indicates reading or writing a heap file failed
+	ErrCodeIO = "HEAP_IO_ERROR"
+
+	// ErrCodePageCorrupt indicates a heap page failed its checks
+	ErrCodePageCorrupt = "HEAP_PAGE_CORRUPT"
+
+	// ErrCodePageFull indicates a page has no room for a tuple
+	ErrCodePageFull = "HEAP_PAGE_FULL"
+
+	// ErrCodeTupleInvalid indicates a tuple does not fit the page it is
+	// stored on
+	ErrCodeTupleInvalid = "HEAP_TUPLE_INVALID"
+
+	// ErrCodeTupleNotFound indicates a record ID that points at no tuple
+	ErrCodeTupleNotFound = "HEAP_TUPLE_NOT_FOUND"
+
+	// ErrCodeInvalidState indicates a heap file, page or iterator was used
+	// in a state that does not allow it
+	ErrCodeInvalidState = "HEAP_INVALID_STATE"
+)
+
+// newHeapError creates a DBError of the heap whose message is formatted from
+// format and args, caused by cause if it is not nil.
+func newHeapError(category dberror.ErrorCategory, code string, cause error, format string, args ...any) *dberror.DBError {
+	err := dberror.New(category, code, fmt.Sprintf(format, args...))
+	err.Cause = cause
+	err.Component = "HeapFile"
+	return err
+}
+
+// newIOError creates a DBError for a heap file that could not be read or
+// written.
+func newIOError(cause error, format string, args ...any) *dberror.DBError {
+	return newHeapError(dberror.ErrCategorySystem, ErrCodeIO, cause, format, args...)
+}
+
+// newCorruptPageError creates a DBError for a page whose contents are not
+// valid.
+func newCorruptPageError(cause error, format string, args ...any) *dberror.DBError {
+	err := newHeapError(dberror.ErrCategoryData, ErrCodePageCorrupt, cause, format, args...)
+	err.Hint = "The page may have been damaged; restore the table from backup if it cannot be repaired"
+	return err
+}
+
+// newPageFullError creates a DBError for a page with no room for a tuple.
+func newPageFullError(cause error, format string, args ...any) *dberror.DBError {
+	return newHeapError(dberror.ErrCategoryTransient, ErrCodePageFull, cause, format, args...)
+}
+
+// newInvalidTupleError creates a DBError for a tuple that does not fit its
+// page.
+func newInvalidTupleError(cause error, format string, args ...any) *dberror.DBError {
+	return newHeapError(dberror.ErrCategoryUser, ErrCodeTupleInvalid, cause, format, args...)
+}
+
+// newTupleNotFoundError creates a DBError for a record ID that points at no
+// tuple.
+func newTupleNotFoundError(cause error, format string, args ...any) *dberror.DBError {
+	return newHeapError(dberror.ErrCategoryUser, ErrCodeTupleNotFound, cause, format, args...)
+}
+
+// newStateError creates a DBError for a heap file, page or iterator used in
+// a state that does not allow it.
+func newStateError(cause error, format string, args ...any) *dberror.DBError {
+	return newHeapError(dberror.ErrCategorySystem, ErrCodeInvalidState, cause, format, args...)
+}
@@ -1,11 +1,11 @@
 package heap
 
 import (
-	"fmt"
 	"io"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
+	"storemy/pkg/vfs"
 )
 
 // HeapFile represents a collection of pages stored in a single OS file on disk.
@@ -32,7 +32,14 @@ type HeapFile struct {
 //   - *HeapFile: The initialized heap file
 //   - error: If the filename is empty or file cannot be opened
 func NewHeapFile(filename primitives.Filepath, td *tuple.TupleDescription) (*HeapFile, error) {
-	baseFile, err := page.NewBaseFile(filename)
+	return NewHeapFileWithFS(vfs.OS, filename, td)
+}
+
+// NewHeapFileWithFS creates a HeapFile backed by a file stored on fsys.
+// Tests use it with an in-memory file system to control durability and
+// inject I/O faults.
+func NewHeapFileWithFS(fsys vfs.FS, filename primitives.Filepath, td *tuple.TupleDescription) (*HeapFile, error) {
+	baseFile, err := page.NewBaseFileWithFS(fsys, filename)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +84,7 @@ func (hf *HeapFile) ReadPage(pageID *page.PageDescriptor) (page.Page, error) {
 		if err == io.EOF {
 			return NewHeapPage(heapPageID, make([]byte, page.PageSize), hf.tupleDesc)
 		}
-		return nil, fmt.Errorf("failed to read page data: %w", err)
+		return nil, newIOError(err, "failed to read page data")
 	}
 
 	return NewHeapPage(heapPageID, pageData, hf.tupleDesc)
@@ -93,20 +100,20 @@ func (hf *HeapFile) ReadPage(pageID *page.PageDescriptor) (page.Page, error) {
 //   - error: If validation fails
 func (hf *HeapFile) validateAndConvertPageID(pageID primitives.PageID) (*page.PageDescriptor, error) {
 	if pageID == nil {
-		return nil, fmt.Errorf("page ID cannot be nil")
+		return nil, newStateError(nil, "page ID cannot be nil")
 	}
 
 	heapPageID, ok := pageID.(*page.PageDescriptor)
 	if !ok {
-		return nil, fmt.Errorf("invalid page ID type for HeapFile")
+		return nil, newStateError(nil, "invalid page ID type for HeapFile")
 	}
 
 	if heapPageID == nil {
-		return nil, fmt.Errorf("page descriptor cannot be nil")
+		return nil, newStateError(nil, "page descriptor cannot be nil")
 	}
 
 	if heapPageID.FileID() != hf.GetID() {
-		return nil, fmt.Errorf("page ID table mismatch")
+		return nil, newStateError(nil, "page ID table mismatch")
 	}
 
 	return heapPageID, nil
@@ -122,7 +129,7 @@ func (hf *HeapFile) validateAndConvertPageID(pageID primitives.PageID) (*page.Pa
 //   - error: If page is nil, file is closed, or I/O fails
 func (hf *HeapFile) WritePage(p page.Page) error {
 	if p == nil {
-		return fmt.Errorf("page cannot be nil")
+		return newStateError(nil, "page cannot be nil")
 	}
 
 	return hf.WritePageData(p.GetID().PageNo(), p.GetPageData())
@@ -2,10 +2,12 @@ package heap
 
 import (
 	"fmt"
+	"slices"
 	"storemy/pkg/iterator"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
+	"storemy/pkg/types"
 )
 
 // HeapFileIterator provides iteration over all tuples in a HeapFile across all pages.
@@ -16,6 +18,16 @@ type HeapFileIterator struct {
 	currentPageNo   primitives.PageNumber
 	currentPageIter *HeapPageIterator
 	isOpen          bool
+
+	// Raw page decoding, used in tuple reuse mode, to intern strings, to
+	// filter and to decode some columns only. See ReuseTuple, InternStrings,
+	// SetFilters and SetColumns.
+	reuse   *tuple.Tuple
+	dict    *types.StringDict
+	raw     *rawPageCursor // Cursor over the current page, nil past the last page
+	cursor  rawPageCursor
+	buf     []byte       // Data of the current page
+	pending *tuple.Tuple // Tuple decoded by HasNext and not returned by Next yet
 }
 
 // NewHeapFileIterator creates a new iterator for iterating over all tuples in a heap file.
@@ -35,6 +47,67 @@ func NewHeapFileIterator(heapFile *HeapFile, tid *primitives.TransactionID) *Hea
 	}
 }
 
+// ReuseTuple makes the iterator decode every tuple into t and return t from
+// Next, instead of returning a new tuple per row: pages are decoded straight
+// from their serialized form, with no HeapPage or per-row tuple, field or
+// record ID allocations once t is filled. The caller owns t and must copy
+// whatever it keeps before the next call to HasNext or Next, which
+// overwrite it. t must be described by the file's tuple description. It
+// must be called before Open; a nil t turns the mode off.
+func (it *HeapFileIterator) ReuseTuple(t *tuple.Tuple) {
+	it.reuse = t
+	it.raw = nil
+	it.pending = nil
+}
+
+// InternStrings makes the iterator intern the string values it decodes in
+// dict, so that rows repeating a value share one string (see
+// types.StringDict). Like tuple reuse mode, with which it can be combined,
+// it decodes pages straight from their serialized form. It must be called
+// before Open; a nil dict turns interning off.
+func (it *HeapFileIterator) InternStrings(dict *types.StringDict) {
+	it.dict = dict
+	it.raw = nil
+	it.pending = nil
+}
+
+// SetFilters makes the iterator return only the rows passing every filter.
+// Rows are filtered as pages are decoded from their serialized form, like
+// in tuple reuse mode, with which it can be combined: the filtered fields
+// of a row are decoded first, and the rest only if the row passes. It must
+// be called before Open; no filters turn filtering off.
+func (it *HeapFileIterator) SetFilters(filters []ScanFilter) {
+	it.cursor.filter = nil
+	if len(filters) > 0 {
+		it.cursor.filter = newRawFilter(filters)
+	}
+	it.raw = nil
+	it.pending = nil
+}
+
+// SetColumns makes the iterator decode only the given fields of each row,
+// leaving the others nil, for a reader such as a projection that uses just
+// those. Fields are decoded from their fixed offsets in the serialized row,
+// so the bytes of the others are skipped rather than decoded. Like tuple
+// reuse mode, with which it can be combined, it decodes pages straight from
+// their serialized form; filtered fields are decoded for the filters
+// whether they are listed or not. It must be called before Open; no
+// columns decode every field.
+func (it *HeapFileIterator) SetColumns(columns []primitives.ColumnID) {
+	it.cursor.columns = nil
+	if len(columns) > 0 {
+		it.cursor.columns = slices.Compact(slices.Sorted(slices.Values(columns)))
+	}
+	it.raw = nil
+	it.pending = nil
+}
+
+// rawMode reports whether pages are decoded from their serialized form
+// rather than read as HeapPages.
+func (it *HeapFileIterator) rawMode() bool {
+	return it.reuse != nil || it.dict != nil || it.cursor.filter != nil || it.cursor.columns != nil
+}
+
 // Open prepares the iterator for use by initializing the first page iterator.
 // This method must be called before any other iterator operations.
 //
@@ -42,7 +115,7 @@ func NewHeapFileIterator(heapFile *HeapFile, tid *primitives.TransactionID) *Hea
 //   - error: If the iterator cannot be initialized or the first page cannot be read
 func (it *HeapFileIterator) Open() error {
 	if it.heapFile == nil {
-		return fmt.Errorf("heap file is nil")
+		return newStateError(nil, "heap file is nil")
 	}
 
 	it.currentPageNo = 0
@@ -57,7 +130,7 @@ func (it *HeapFileIterator) loadPageIterator(pageNo primitives.PageNumber) error
 	// Check if page exists
 	numPages, err := it.heapFile.NumPages()
 	if err != nil {
-		return fmt.Errorf("failed to get number of pages: %w", err)
+		return newIOError(err, "failed to get number of pages")
 	}
 
 	// If we've gone past the last page, we're done
@@ -66,23 +139,27 @@ func (it *HeapFileIterator) loadPageIterator(pageNo primitives.PageNumber) error
 		return nil
 	}
 
-	// Read the page
 	pageID := page.NewPageDescriptor(it.heapFile.GetID(), pageNo)
+	if it.rawMode() {
+		return it.loadRawPage(pageID)
+	}
+
+	// Read the page
 	pg, err := it.heapFile.ReadPage(pageID)
 	if err != nil {
-		return fmt.Errorf("failed to read page %d: %w", pageNo, err)
+		return newIOError(err, "failed to read page %d", pageNo)
 	}
 
 	// Cast to HeapPage
 	heapPage, ok := pg.(*HeapPage)
 	if !ok {
-		return fmt.Errorf("page is not a HeapPage")
+		return newStateError(nil, "page is not a HeapPage")
 	}
 
 	// Create a new page iterator
 	it.currentPageIter = NewHeapPageIterator(heapPage)
 	if err := it.currentPageIter.Open(); err != nil {
-		return fmt.Errorf("failed to open page iterator: %w", err)
+		return newStateError(err, "failed to open page iterator")
 	}
 
 	return nil
@@ -95,7 +172,11 @@ func (it *HeapFileIterator) loadPageIterator(pageNo primitives.PageNumber) error
 //   - error: If an error occurs while checking for more tuples
 func (it *HeapFileIterator) HasNext() (bool, error) {
 	if !it.isOpen {
-		return false, fmt.Errorf("iterator is not open")
+		return false, newStateError(nil, "iterator is not open")
+	}
+
+	if it.rawMode() {
+		return it.hasNextRaw()
 	}
 
 	// If no current page iterator, we're at the end
@@ -122,7 +203,7 @@ func (it *HeapFileIterator) HasNext() (bool, error) {
 func (it *HeapFileIterator) advanceToNextPage() (bool, error) {
 	numPages, err := it.heapFile.NumPages()
 	if err != nil {
-		return false, fmt.Errorf("failed to get number of pages: %w", err)
+		return false, newIOError(err, "failed to get number of pages")
 	}
 
 	// Try loading successive pages until we find one with tuples or reach the end
@@ -160,7 +241,7 @@ func (it *HeapFileIterator) advanceToNextPage() (bool, error) {
 //   - error: If there are no more tuples or an error occurs
 func (it *HeapFileIterator) Next() (*tuple.Tuple, error) {
 	if !it.isOpen {
-		return nil, fmt.Errorf("iterator is not open")
+		return nil, newStateError(nil, "iterator is not open")
 	}
 
 	// Check if there are more tuples
@@ -169,12 +250,18 @@ func (it *HeapFileIterator) Next() (*tuple.Tuple, error) {
 		return nil, err
 	}
 	if !hasNext {
-		return nil, fmt.Errorf("no more tuples")
+		return nil, newStateError(nil, "no more tuples")
+	}
+
+	if it.rawMode() {
+		t := it.pending
+		it.pending = nil
+		return t, nil
 	}
 
 	// Get the next tuple from the current page iterator
 	if it.currentPageIter == nil {
-		return nil, fmt.Errorf("no current page iterator")
+		return nil, newStateError(nil, "no current page iterator")
 	}
 
 	return it.currentPageIter.Next()
@@ -186,7 +273,7 @@ func (it *HeapFileIterator) Next() (*tuple.Tuple, error) {
 //   - error: If the rewind operation fails
 func (it *HeapFileIterator) Rewind() error {
 	if !it.isOpen {
-		return fmt.Errorf("iterator is not open")
+		return newStateError(nil, "iterator is not open")
 	}
 
 	// Close the current page iterator if it exists
@@ -195,6 +282,8 @@ func (it *HeapFileIterator) Rewind() error {
 	}
 
 	// Reset to the beginning
+	it.raw = nil
+	it.pending = nil
 	it.currentPageNo = 0
 	return it.loadPageIterator(it.currentPageNo)
 }
@@ -211,9 +300,61 @@ func (it *HeapFileIterator) Close() error {
 		it.currentPageIter = nil
 	}
 
+	it.raw = nil
+	it.pending = nil
 	it.isOpen = false
 	return nil
 }
 
+// loadRawPage reads the serialized data of a page for raw decoding, into
+// the same buffer for every page.
+func (it *HeapFileIterator) loadRawPage(pageID *page.PageDescriptor) error {
+	if it.buf == nil {
+		it.buf = make([]byte, page.PageSize)
+	}
+	if err := it.heapFile.ReadPageDataInto(pageID.PageNo(), it.buf); err != nil {
+		return newIOError(err, "failed to read page %d", pageID.PageNo())
+	}
+	schema := tuple.Schemas.Get(pageID.FileID(), it.heapFile.GetTupleDesc())
+	it.cursor.reset(pageID, it.buf, schema)
+	it.raw = &it.cursor
+	return nil
+}
+
+// hasNextRaw decodes the next tuple, into the reused tuple if there is one,
+// moving on to the following pages when the current one has no more.
+func (it *HeapFileIterator) hasNextRaw() (bool, error) {
+	if it.pending != nil {
+		return true, nil
+	}
+
+	for it.raw != nil {
+		t := it.reuse
+		if t == nil {
+			t = tuple.NewTuple(it.heapFile.GetTupleDesc())
+		}
+		found, err := it.raw.next(t, it.dict)
+		if err != nil {
+			return false, fmt.Errorf("page %d: %w", it.currentPageNo, err)
+		}
+		if found {
+			if it.reuse == nil {
+				// The cursor's record ID changes with the next tuple
+				rid := *t.RecordID
+				t.RecordID = &rid
+			}
+			it.pending = t
+			return true, nil
+		}
+
+		it.raw = nil
+		it.currentPageNo++
+		if err := it.loadPageIterator(it.currentPageNo); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
 // Compile-time check to ensure HeapFileIterator implements iterator.DbFileIterator
 var _ iterator.DbFileIterator = (*HeapFileIterator)(nil)
@@ -1,12 +1,14 @@
 package heap
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
+	"storemy/pkg/vfs"
 	"testing"
 )
 
@@ -703,3 +705,97 @@ func TestHeapFile_ValidatePageID(t *testing.T) {
 		})
 	}
 }
+
+func TestHeapFile_MemFS_SyncFaultAndCrash(t *testing.T) {
+	td := createTestTupleDesc()
+	fsys := vfs.NewMemFS()
+
+	hf, err := NewHeapFileWithFS(fsys, "/table.dat", td)
+	if err != nil {
+		t.Fatalf("NewHeapFileWithFS failed: %v", err)
+	}
+
+	pageNo, err := hf.AllocateNewPage()
+	if err != nil {
+		t.Fatalf("AllocateNewPage failed: %v", err)
+	}
+
+	durable := make([]byte, page.PageSize)
+	durable[0] = 1
+	if err := hf.WritePageData(pageNo, durable); err != nil {
+		t.Fatalf("WritePageData failed: %v", err)
+	}
+
+	fsys.SetFault(vfs.FailAlways(vfs.OpSync, "table.dat", errors.New("injected fsync failure")))
+	lost := make([]byte, page.PageSize)
+	lost[0] = 2
+	if err := hf.WritePageData(pageNo, lost); err == nil {
+		t.Fatal("expected WritePageData to report the fsync failure")
+	}
+
+	fsys.SetFault(nil)
+	fsys.Crash()
+
+	reopened, err := NewHeapFileWithFS(fsys, "/table.dat", td)
+	if err != nil {
+		t.Fatalf("reopening heap file failed: %v", err)
+	}
+	defer reopened.Close()
+
+	data, err := reopened.ReadPageData(pageNo)
+	if err != nil {
+		t.Fatalf("ReadPageData failed: %v", err)
+	}
+	if data[0] != 1 {
+		t.Errorf("expected the last synced page to survive the crash, got first byte %d", data[0])
+	}
+}
+
+func TestHeapFile_MemFS_FsyncPolicyDefersSync(t *testing.T) {
+	td := createTestTupleDesc()
+	fsys := vfs.NewMemFS()
+
+	hf, err := NewHeapFileWithFS(fsys, "/table.dat", td)
+	if err != nil {
+		t.Fatalf("NewHeapFileWithFS failed: %v", err)
+	}
+	if err := hf.SetSyncPolicy(vfs.SyncFsync); err != nil {
+		t.Fatalf("SetSyncPolicy failed: %v", err)
+	}
+
+	pageNo, err := hf.AllocateNewPage()
+	if err != nil {
+		t.Fatalf("AllocateNewPage failed: %v", err)
+	}
+	synced := make([]byte, page.PageSize)
+	synced[0] = 1
+	if err := hf.WritePageData(pageNo, synced); err != nil {
+		t.Fatalf("WritePageData failed: %v", err)
+	}
+	if err := hf.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Written but not synced: lost in the crash.
+	unsynced := make([]byte, page.PageSize)
+	unsynced[0] = 2
+	if err := hf.WritePageData(pageNo, unsynced); err != nil {
+		t.Fatalf("WritePageData failed: %v", err)
+	}
+
+	fsys.Crash()
+
+	reopened, err := NewHeapFileWithFS(fsys, "/table.dat", td)
+	if err != nil {
+		t.Fatalf("reopening heap file failed: %v", err)
+	}
+	defer reopened.Close()
+
+	data, err := reopened.ReadPageData(pageNo)
+	if err != nil {
+		t.Fatalf("ReadPageData failed: %v", err)
+	}
+	if data[0] != 1 {
+		t.Errorf("expected the page as of the last Sync to survive the crash, got first byte %d", data[0])
+	}
+}
@@ -3,12 +3,11 @@ package heap
 import (
 	"bytes"
 	"encoding/binary"
-	"fmt"
-	"io"
+	"errors"
+	"storemy/pkg/invariant"
 	"storemy/pkg/primitives"
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
-	"storemy/pkg/types"
 	"sync"
 )
 
@@ -22,6 +21,7 @@ const (
 // SlotPointer represents a pointer to a tuple within a page (PostgreSQL-style)
 // Each slot has an offset (where tuple starts) and length (how long it is)
 // If offset is 0, the slot is considered empty/deleted
+// The high bits of the offset flag forward pointers and moved tuples (see redirect.go)
 type SlotPointer struct {
 	Offset primitives.SlotID // Offset from start of page (0 = empty slot)
 	Length uint16            // Length of tuple data in bytes
@@ -43,10 +43,12 @@ type SlotPointer struct {
 type HeapPage struct {
 	pageID       *page.PageDescriptor
 	tupleDesc    *tuple.TupleDescription
-	tuples       []*tuple.Tuple    // In-memory tuple cache (indexed by slot number)
-	slotPointers []SlotPointer     // Pointer array (offset, length) for each slot
-	numSlots     primitives.SlotID // Maximum number of slots
-	freeSpacePtr uint16            // Points to start of free space
+	schema       *tuple.CompiledSchema  // Layout of tupleDesc, shared by the file's pages
+	tuples       []*tuple.Tuple         // In-memory tuple cache (indexed by slot number)
+	slotPointers []SlotPointer          // Pointer array (offset, length) for each slot
+	links        []*tuple.TupleRecordID // Forward pointer target or moved tuple's home, per slot
+	numSlots     primitives.SlotID      // Maximum number of slots
+	freeSpacePtr uint16                 // Points to start of free space
 	dirtier      *primitives.TransactionID
 	oldData      []byte // Before-image for rollback
 	mutex        sync.RWMutex
@@ -72,18 +74,20 @@ func NewEmptyHeapPage(pid *page.PageDescriptor, td *tuple.TupleDescription) (*He
 // the slot pointer array and tuple array from the provided data.
 func NewHeapPage(pid *page.PageDescriptor, data []byte, td *tuple.TupleDescription) (*HeapPage, error) {
 	if len(data) != page.PageSize {
-		return nil, fmt.Errorf("invalid page data size: expected %d, got %d", page.PageSize, len(data))
+		return nil, newCorruptPageError(nil, "invalid page data size: expected %d, got %d", page.PageSize, len(data))
 	}
 
 	hp := &HeapPage{
 		pageID:    pid,
 		tupleDesc: td,
+		schema:    tuple.Schemas.Get(pid.FileID(), td),
 		oldData:   make([]byte, page.PageSize),
 	}
 
 	hp.numSlots = hp.getNumTuples()
 	hp.slotPointers = make([]SlotPointer, hp.numSlots)
 	hp.tuples = make([]*tuple.Tuple, hp.numSlots)
+	hp.links = make([]*tuple.TupleRecordID, hp.numSlots)
 	hp.freeSpacePtr = uint16(hp.getHeaderSize())
 
 	if err := hp.parsePageData(data); err != nil {
@@ -137,6 +141,10 @@ func (hp *HeapPage) MarkDirty(dirty bool, tid *primitives.TransactionID) {
 // Layout:
 //
 //	[SlotPointer0][SlotPointer1]...[SlotPointerN][FreeSpace][...TupleData...]
+//
+// With invariant assertions on, the result is checked with VerifyPageData,
+// so a page whose slots went out of bounds fails where it is written rather
+// than when it is next read.
 func (hp *HeapPage) GetPageData() []byte {
 	hp.mutex.RLock()
 	defer hp.mutex.RUnlock()
@@ -150,22 +158,21 @@ func (hp *HeapPage) GetPageData() []byte {
 	}
 
 	for i := primitives.SlotID(0); i < hp.numSlots; i++ {
-		if hp.slotPointers[i].Offset == 0 || hp.tuples[i] == nil {
+		if hp.slotPointers[i].Offset == 0 {
 			continue // Empty slot
 		}
-
-		tupleOffset := hp.slotPointers[i].Offset
-		buffer := bytes.NewBuffer(pageData[tupleOffset:tupleOffset])
-
-		for j := primitives.ColumnID(0); j < hp.tupleDesc.NumFields(); j++ {
-			field, err := hp.tuples[i].GetField(j)
-			if err != nil {
-				continue
-			}
-			field.Serialize(buffer)
+		data := hp.encodeSlot(i)
+		if invariant.Enabled() && len(data) != int(hp.slotPointers[i].Length) {
+			invariant.Failf("heap page %v: slot %d holds %d bytes but its pointer says %d", hp.pageID, i, len(data), hp.slotPointers[i].Length)
 		}
+		copy(pageData[hp.slotPointers[i].offset():], data)
 	}
 
+	if invariant.Enabled() {
+		if errs := VerifyPageData(pageData, hp.tupleDesc); len(errs) > 0 {
+			invariant.Failf("heap page %v serialized inconsistently: %v", hp.pageID, errors.Join(errs...))
+		}
+	}
 	return pageData
 }
 
@@ -189,9 +196,25 @@ func (hp *HeapPage) SetBeforeImage() {
 	hp.oldData = hp.GetPageData()
 }
 
+// Copy returns an independent copy of this page, including its before-image
+// and the transaction that dirtied it.
+func (hp *HeapPage) Copy() page.Page {
+	cp, err := NewHeapPage(hp.pageID, hp.GetPageData(), hp.tupleDesc)
+	if err != nil {
+		return nil
+	}
+
+	hp.mutex.RLock()
+	defer hp.mutex.RUnlock()
+	copy(cp.oldData, hp.oldData)
+	cp.dirtier = hp.dirtier
+	return cp
+}
+
 // AddTuple inserts a tuple into the first available empty slot on this page.
 // The tuple's RecordID is set to identify its location on this page.
 // Tuples are allocated from the end of the page, growing backward toward the pointer array.
+// If deletions left the free space fragmented, the page is compacted first.
 //
 // Thread-safe: Uses write lock for concurrent access.
 //
@@ -204,35 +227,27 @@ func (hp *HeapPage) SetBeforeImage() {
 // Errors:
 //   - Schema mismatch between tuple and page
 //   - No empty slots available
-//   - Not enough contiguous free space
+//   - Not enough free space, even after compaction (ErrPageFull)
 func (hp *HeapPage) AddTuple(t *tuple.Tuple) error {
 	hp.mutex.Lock()
 	defer hp.mutex.Unlock()
 
 	if !t.TupleDesc.Equals(hp.tupleDesc) {
-		return fmt.Errorf("tuple schema does not match page schema")
+		return newInvalidTupleError(nil, "tuple schema does not match page schema")
 	}
 
 	slotIndex, err := hp.findFirstEmptySlot()
 	if err != nil {
-		return fmt.Errorf("no empty slot available: %w", err)
+		return newPageFullError(err, "no empty slot available")
 	}
 
-	tupleSize := hp.tupleDesc.GetSize()
+	tupleSize := hp.schema.Size()
 	if tupleSize > MaxTupleSize {
-		return fmt.Errorf("tuple size %d exceeds maximum %d", tupleSize, MaxTupleSize)
+		return newInvalidTupleError(nil, "tuple size %d exceeds maximum %d", tupleSize, MaxTupleSize)
 	}
 
-	if !hp.hasSpaceForTuple(uint16(tupleSize)) {
-		return fmt.Errorf("no space left on this page")
-	}
-
-	newTupleOffset := hp.freeSpacePtr
-	hp.freeSpacePtr += uint16(tupleSize)
-
-	hp.slotPointers[slotIndex] = SlotPointer{
-		Offset: primitives.SlotID(newTupleOffset),
-		Length: uint16(tupleSize),
+	if err := hp.place(slotIndex, 0, uint16(tupleSize)); err != nil {
+		return err
 	}
 
 	hp.tuples[slotIndex] = t
@@ -243,33 +258,38 @@ func (hp *HeapPage) AddTuple(t *tuple.Tuple) error {
 // DeleteTuple removes a tuple from this page by invalidating its slot pointer.
 // The tuple's RecordID is set to nil after successful deletion.
 // Note: This does not reclaim space immediately. Call Compact() to defragment the page.
+//
+// A tuple moved here from another page is found by its home record ID. Its
+// RecordID is kept, since the forward pointer in its home slot still has to
+// be deleted; deleting a home slot removes the forward pointer it holds.
 func (hp *HeapPage) DeleteTuple(t *tuple.Tuple) error {
 	hp.mutex.Lock()
 	defer hp.mutex.Unlock()
 
 	recordID := t.RecordID
 	if recordID == nil {
-		return fmt.Errorf("tuple has no record ID")
+		return newInvalidTupleError(nil, "tuple has no record ID")
 	}
 
-	if !recordID.PageID.Equals(hp.pageID) {
-		return fmt.Errorf("tuple is not on this page")
-	}
-
-	slotIndex := recordID.TupleNum
-
-	if !hp.isSlotUsed(slotIndex) {
-		return fmt.Errorf("tuple slot %d is already empty", slotIndex)
+	slotIndex, err := hp.findSlot(recordID)
+	if err != nil {
+		return err
 	}
 
+	moved := hp.slotPointers[slotIndex].isMoved()
 	hp.slotPointers[slotIndex] = SlotPointer{Offset: 0, Length: 0}
 	hp.tuples[slotIndex] = nil
-	t.RecordID = nil
+	hp.links[slotIndex] = nil
+	if !moved {
+		t.RecordID = nil
+	}
 	return nil
 }
 
 // GetTuples returns all non-empty tuples stored on this page.
-// Empty slots (marked as unused in header) are excluded from the result.
+// Empty slots (marked as unused in header) and forward pointers are excluded
+// from the result; a tuple moved here from another page is included, with
+// the RecordID of its home slot.
 func (hp *HeapPage) GetTuples() []*tuple.Tuple {
 	hp.mutex.RLock()
 	defer hp.mutex.RUnlock()
@@ -284,13 +304,14 @@ func (hp *HeapPage) GetTuples() []*tuple.Tuple {
 	return tuples
 }
 
-// GetTupleAt returns the tuple at the specified slot index, or nil if the slot is empty.
+// GetTupleAt returns the tuple at the specified slot index, or nil if the slot is empty
+// or holds a forward pointer (see Forward).
 func (hp *HeapPage) GetTupleAt(idx primitives.SlotID) (*tuple.Tuple, error) {
 	hp.mutex.RLock()
 	defer hp.mutex.RUnlock()
 
 	if idx >= hp.numSlots {
-		return nil, fmt.Errorf("slot index %d out of bounds", idx)
+		return nil, newTupleNotFoundError(nil, "slot index %d out of bounds", idx)
 	}
 
 	return hp.tuples[idx], nil
@@ -305,7 +326,7 @@ func (hp *HeapPage) GetTupleAt(idx primitives.SlotID) (*tuple.Tuple, error) {
 // Returns:
 //   - int: Maximum number of tuple slots for this page's schema
 func (hp *HeapPage) getNumTuples() primitives.SlotID {
-	tupleSize := hp.tupleDesc.GetSize()
+	tupleSize := hp.schema.Size()
 	return primitives.SlotID(page.PageSize) / primitives.SlotID(tupleSize+SlotPointerSize)
 }
 
@@ -329,14 +350,14 @@ func (hp *HeapPage) parsePageData(data []byte) error {
 	for i := primitives.SlotID(0); i < hp.numSlots; i++ {
 		offset := int(i) * SlotPointerSize
 		if offset+SlotPointerSize > len(data) {
-			return fmt.Errorf("invalid page data: insufficient data for slot pointers")
+			return newCorruptPageError(nil, "invalid page data: insufficient data for slot pointers")
 		}
 
 		hp.slotPointers[i].Offset = primitives.SlotID(binary.LittleEndian.Uint16(data[offset:]))
 		hp.slotPointers[i].Length = binary.LittleEndian.Uint16(data[offset+2:])
 
 		if hp.slotPointers[i].Offset != 0 {
-			endOffset := uint16(hp.slotPointers[i].Offset) + hp.slotPointers[i].Length
+			endOffset := hp.slotPointers[i].offset() + hp.slotPointers[i].Length
 			if endOffset > maxOffset {
 				maxOffset = endOffset
 			}
@@ -350,23 +371,38 @@ func (hp *HeapPage) parsePageData(data []byte) error {
 			continue // Empty slot
 		}
 
-		tupleOffset := hp.slotPointers[i].Offset
-		tupleLength := hp.slotPointers[i].Length
+		sp := hp.slotPointers[i]
+		tupleOffset := sp.offset()
+		tupleLength := sp.Length
 
-		if int(uint16(tupleOffset)+tupleLength) > len(data) {
-			return fmt.Errorf("invalid tuple at slot %d: offset %d + length %d exceeds page size",
+		if int(tupleOffset+tupleLength) > len(data) {
+			return newCorruptPageError(nil, "invalid tuple at slot %d: offset %d + length %d exceeds page size",
 				i, tupleOffset, tupleLength)
 		}
 
-		tupleData := data[tupleOffset : uint16(tupleOffset)+tupleLength]
-		reader := bytes.NewReader(tupleData)
+		tupleData := data[tupleOffset : tupleOffset+tupleLength]
 
-		t, err := readTuple(reader, hp.tupleDesc)
+		if sp.flags() != 0 {
+			link, err := readRecordID(bytes.NewReader(tupleData), hp.pageID.FileID())
+			if err != nil {
+				return newCorruptPageError(err, "failed to read record ID at slot %d", i)
+			}
+			hp.links[i] = link
+			if sp.isRedirect() {
+				continue // Forward pointer, no tuple here
+			}
+			tupleData = tupleData[recordIDSize:]
+		}
+
+		t, err := hp.schema.Decode(tupleData)
 		if err != nil {
-			return fmt.Errorf("failed to read tuple at slot %d: %v", i, err)
+			return newCorruptPageError(err, "failed to read tuple at slot %d", i)
 		}
 
 		t.RecordID = tuple.NewTupleRecordID(hp.pageID, i)
+		if sp.isMoved() {
+			t.RecordID = hp.links[i]
+		}
 		hp.tuples[i] = t
 	}
 
@@ -402,7 +438,7 @@ func (hp *HeapPage) findFirstEmptySlot() (primitives.SlotID, error) {
 			return i, nil
 		}
 	}
-	return 0, fmt.Errorf("no slot found")
+	return 0, newPageFullError(nil, "no slot found")
 }
 
 // hasSpaceForTuple checks if there is enough contiguous free space for a tuple of the given size.
@@ -411,37 +447,6 @@ func (hp *HeapPage) hasSpaceForTuple(tupleSize uint16) bool {
 	return uint32(hp.freeSpacePtr)+uint32(tupleSize) <= uint32(page.PageSize)
 }
 
-// readTuple deserializes a single tuple from a byte stream.
-// Used during page parsing to reconstruct tuples from disk.
-//
-// Parameters:
-//   - reader: Byte stream positioned at start of tuple data
-//   - td: Schema descriptor for the tuple
-//
-// Returns:
-//   - *tuple.Tuple: Parsed tuple with all fields populated
-//   - error: If field parsing fails or reader encounters error
-func readTuple(reader io.Reader, td *tuple.TupleDescription) (*tuple.Tuple, error) {
-	t := tuple.NewTuple(td)
-
-	for j := primitives.ColumnID(0); j < td.NumFields(); j++ {
-		fieldType, err := td.TypeAtIndex(j)
-		if err != nil {
-			return nil, err
-		}
-
-		field, err := types.ParseField(reader, fieldType)
-		if err != nil {
-			return nil, err
-		}
-
-		if err := t.SetField(j, field); err != nil {
-			return nil, err
-		}
-	}
-	return t, nil
-}
-
 // Compact defragments the page by moving all tuples together to eliminate gaps.
 // This reclaims space left by deleted tuples, making it available for new insertions.
 //
@@ -457,32 +462,29 @@ func readTuple(reader io.Reader, td *tuple.TupleDescription) (*tuple.Tuple, erro
 func (hp *HeapPage) Compact() int {
 	hp.mutex.Lock()
 	defer hp.mutex.Unlock()
+	return hp.compact()
+}
 
+// compact is the internal implementation of Compact.
+// Must be called with the write lock held.
+func (hp *HeapPage) compact() int {
 	// Serialize all tuples to a temporary buffer
 	type tupleData struct {
 		slotIndex primitives.SlotID
+		flags     uint16
 		data      []byte
 	}
 
 	var activeTuples []tupleData
 	for i := primitives.SlotID(0); i < hp.numSlots; i++ {
-		if hp.slotPointers[i].Offset == 0 || hp.tuples[i] == nil {
+		if hp.slotPointers[i].Offset == 0 {
 			continue // Skip empty slots
 		}
 
-		// Serialize tuple
-		buffer := &bytes.Buffer{}
-		for j := primitives.ColumnID(0); j < hp.tupleDesc.NumFields(); j++ {
-			field, err := hp.tuples[i].GetField(j)
-			if err != nil {
-				continue
-			}
-			field.Serialize(buffer)
-		}
-
 		activeTuples = append(activeTuples, tupleData{
 			slotIndex: i,
-			data:      buffer.Bytes(),
+			flags:     hp.slotPointers[i].flags(),
+			data:      hp.encodeSlot(i),
 		})
 	}
 
@@ -498,7 +500,7 @@ func (hp *HeapPage) Compact() int {
 
 		// Update slot pointer to new location
 		hp.slotPointers[td.slotIndex] = SlotPointer{
-			Offset: primitives.SlotID(hp.freeSpacePtr),
+			Offset: primitives.SlotID(hp.freeSpacePtr | td.flags),
 			Length: tupleSize,
 		}
 
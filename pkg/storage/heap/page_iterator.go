@@ -1,7 +1,6 @@
 package heap
 
 import (
-	"fmt"
 	"storemy/pkg/tuple"
 )
 
@@ -41,7 +40,7 @@ func (it *HeapPageIterator) Next() (*tuple.Tuple, error) {
 	}
 
 	if !hasNext {
-		return nil, fmt.Errorf("no more tuples")
+		return nil, newStateError(nil, "no more tuples")
 	}
 
 	it.currentIndex++
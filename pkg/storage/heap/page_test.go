@@ -549,6 +549,49 @@ func TestHeapPage_AddTuple_FillPage(t *testing.T) {
 	}
 }
 
+func TestHeapPage_AddTuple_CompactsAfterDeletes(t *testing.T) {
+	pageID := page.NewPageDescriptor(1, 2)
+	td := mustCreateTupleDesc()
+
+	hp, err := NewEmptyHeapPage(pageID, td)
+	if err != nil {
+		t.Fatalf("Failed to create HeapPage: %v", err)
+	}
+
+	tuples := make([]*tuple.Tuple, hp.numSlots)
+	for i := range tuples {
+		tuples[i] = createTestTuple(td, int64(i), "User")
+		if err := hp.AddTuple(tuples[i]); err != nil {
+			t.Fatalf("Failed to add tuple %d: %v", i, err)
+		}
+	}
+
+	// Free the first slot: the free space left behind is not contiguous with
+	// the free space at the end of the page
+	if err := hp.DeleteTuple(tuples[0]); err != nil {
+		t.Fatalf("DeleteTuple failed: %v", err)
+	}
+
+	replacement := createTestTuple(td, 100, "Replacement")
+	if err := hp.AddTuple(replacement); err != nil {
+		t.Fatalf("AddTuple after delete failed: %v", err)
+	}
+	if replacement.RecordID.TupleNum != 0 {
+		t.Errorf("Expected replacement in slot 0, got slot %d", replacement.RecordID.TupleNum)
+	}
+
+	reloaded, err := NewHeapPage(pageID, hp.GetPageData(), td)
+	if err != nil {
+		t.Fatalf("Failed to reload page: %v", err)
+	}
+	for i, want := range append([]*tuple.Tuple{replacement}, tuples[1:]...) {
+		got, _ := reloaded.GetTupleAt(primitives.SlotID(i))
+		if got == nil || got.String() != want.String() {
+			t.Errorf("Slot %d after compaction = %v, want %v", i, got, want)
+		}
+	}
+}
+
 func TestHeapPage_DeleteTuple_AlreadyEmpty(t *testing.T) {
 	pageID := page.NewPageDescriptor(1, 2)
 	td := mustCreateTupleDesc()
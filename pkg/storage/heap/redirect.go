@@ -0,0 +1,267 @@
+package heap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+)
+
+// Slot flags live in the two high bits of a slot pointer's offset, which a
+// real offset never reaches since a page is only page.PageSize bytes long.
+//
+// A tuple that no longer fits on its page after an update is moved to
+// another page, and its home slot becomes a forward pointer (redirect) to the
+// new location. The moved copy remembers its home, so the tuple keeps the
+// record ID of its home slot wherever it lives and indexes never need to
+// change.
+const (
+	slotRedirect = 0x8000 // Slot holds a forward pointer instead of a tuple
+	slotMoved    = 0x4000 // Slot holds a tuple whose home slot is on another page
+	slotFlags    = slotRedirect | slotMoved
+
+	// recordIDSize is the size of an encoded forward pointer or home record
+	// ID: the page number (8 bytes) and slot (2 bytes) within the same file.
+	recordIDSize = 10
+)
+
+// ErrPageFull is returned when a page has no room for a tuple, even after
+// compaction.
+var ErrPageFull = errors.New("no space left on this page")
+
+// offset returns the position of the slot's data, without the flags.
+func (sp SlotPointer) offset() uint16 {
+	return uint16(sp.Offset) &^ slotFlags
+}
+
+// flags returns the slot flags.
+func (sp SlotPointer) flags() uint16 {
+	return uint16(sp.Offset) & slotFlags
+}
+
+func (sp SlotPointer) isRedirect() bool {
+	return sp.Offset&slotRedirect != 0
+}
+
+func (sp SlotPointer) isMoved() bool {
+	return sp.Offset&slotMoved != 0
+}
+
+// Forward returns where the tuple whose home is slot idx now lives, and false
+// if the slot does not hold a forward pointer.
+func (hp *HeapPage) Forward(idx primitives.SlotID) (*tuple.TupleRecordID, bool) {
+	hp.mutex.RLock()
+	defer hp.mutex.RUnlock()
+
+	if idx >= hp.numSlots || !hp.slotPointers[idx].isRedirect() {
+		return nil, false
+	}
+	return hp.links[idx], true
+}
+
+// UpdateTuple replaces the contents of slot idx with t, so the tuple keeps
+// its record ID. If t does not fit where the old contents were, the page is
+// compacted to make room.
+//
+// A moved tuple stays moved and keeps its home. A forward pointer is replaced
+// by t itself, bringing a moved tuple back home; the caller must delete the
+// moved copy.
+//
+// Returns ErrPageFull, leaving the page unchanged, if t does not fit on the
+// page even after compaction.
+func (hp *HeapPage) UpdateTuple(idx primitives.SlotID, t *tuple.Tuple) error {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	if !t.TupleDesc.Equals(hp.tupleDesc) {
+		return newInvalidTupleError(nil, "tuple schema does not match page schema")
+	}
+	if !hp.isSlotUsed(idx) {
+		return newTupleNotFoundError(nil, "tuple slot %d is empty", idx)
+	}
+
+	old := hp.slotPointers[idx]
+	oldTuple, oldLink := hp.tuples[idx], hp.links[idx]
+
+	flags := old.flags() & slotMoved
+	size := uint16(len(hp.encodeTuple(t)))
+	if flags != 0 {
+		size += recordIDSize
+	} else {
+		hp.links[idx] = nil
+	}
+
+	if size <= old.Length {
+		hp.slotPointers[idx] = SlotPointer{Offset: primitives.SlotID(old.offset() | flags), Length: size}
+	} else {
+		hp.slotPointers[idx] = SlotPointer{}
+		hp.tuples[idx] = nil
+		if err := hp.place(idx, flags, size); err != nil {
+			// The old contents fit before compaction, so they still fit now
+			hp.tuples[idx], hp.links[idx] = oldTuple, oldLink
+			hp.place(idx, old.flags(), old.Length)
+			return err
+		}
+	}
+
+	hp.tuples[idx] = t
+	if flags != 0 {
+		t.RecordID = hp.links[idx]
+	} else {
+		t.RecordID = tuple.NewTupleRecordID(hp.pageID, idx)
+	}
+	return nil
+}
+
+// AddMovedTuple stores t, whose home slot home on another page of the same
+// file has no room for it, in the first empty slot of this page. The tuple's
+// RecordID is set to home; the returned record ID is where the copy lives,
+// for the home slot to point to with SetRedirect.
+func (hp *HeapPage) AddMovedTuple(t *tuple.Tuple, home *tuple.TupleRecordID) (*tuple.TupleRecordID, error) {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	if !t.TupleDesc.Equals(hp.tupleDesc) {
+		return nil, newInvalidTupleError(nil, "tuple schema does not match page schema")
+	}
+	if home == nil {
+		return nil, newStateError(nil, "moved tuple needs a home record ID")
+	}
+	if home.PageID.FileID() != hp.pageID.FileID() || home.PageID.Equals(hp.pageID) {
+		return nil, newStateError(nil, "home %v must be on another page of the same file", home)
+	}
+
+	slotIndex, err := hp.findFirstEmptySlot()
+	if err != nil {
+		return nil, newPageFullError(err, "no empty slot available")
+	}
+
+	hp.links[slotIndex] = home
+	if err := hp.place(slotIndex, slotMoved, uint16(hp.schema.Size())+recordIDSize); err != nil {
+		hp.links[slotIndex] = nil
+		return nil, err
+	}
+
+	hp.tuples[slotIndex] = t
+	t.RecordID = home
+	return tuple.NewTupleRecordID(hp.pageID, slotIndex), nil
+}
+
+// SetRedirect turns slot idx into a forward pointer to target, the location
+// of the slot's tuple on another page of the same file. The tuple stored in
+// the slot, if any, is dropped. A moved tuple cannot be redirected: point its
+// home slot at the new location instead.
+func (hp *HeapPage) SetRedirect(idx primitives.SlotID, target *tuple.TupleRecordID) error {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	if target == nil || target.PageID.FileID() != hp.pageID.FileID() || target.PageID.Equals(hp.pageID) {
+		return newStateError(nil, "redirect target %v must be on another page of the same file", target)
+	}
+	if !hp.isSlotUsed(idx) {
+		return newTupleNotFoundError(nil, "tuple slot %d is empty", idx)
+	}
+
+	old := hp.slotPointers[idx]
+	if old.isMoved() {
+		return newStateError(nil, "tuple slot %d holds a moved tuple", idx)
+	}
+	oldTuple, oldLink := hp.tuples[idx], hp.links[idx]
+
+	hp.tuples[idx] = nil
+	hp.links[idx] = target
+	if recordIDSize <= old.Length {
+		hp.slotPointers[idx] = SlotPointer{Offset: primitives.SlotID(old.offset() | slotRedirect), Length: recordIDSize}
+		return nil
+	}
+
+	hp.slotPointers[idx] = SlotPointer{}
+	if err := hp.place(idx, slotRedirect, recordIDSize); err != nil {
+		hp.tuples[idx], hp.links[idx] = oldTuple, oldLink
+		hp.place(idx, old.flags(), old.Length)
+		return err
+	}
+	return nil
+}
+
+// place allocates size bytes of free space for slot idx and points the slot
+// at them with the given flags, compacting the page first if the contiguous
+// free space is too small. The slot must be empty while place runs.
+// Must be called with the write lock held.
+func (hp *HeapPage) place(idx primitives.SlotID, flags uint16, size uint16) error {
+	if !hp.hasSpaceForTuple(size) {
+		hp.compact()
+	}
+	if !hp.hasSpaceForTuple(size) {
+		return ErrPageFull
+	}
+
+	hp.slotPointers[idx] = SlotPointer{
+		Offset: primitives.SlotID(hp.freeSpacePtr | flags),
+		Length: size,
+	}
+	hp.freeSpacePtr += size
+	return nil
+}
+
+// findSlot returns the slot holding the tuple with record ID rid: its home
+// slot if the home is on this page, otherwise the slot of a copy moved here.
+// Must be called with the lock held.
+func (hp *HeapPage) findSlot(rid *tuple.TupleRecordID) (primitives.SlotID, error) {
+	if rid.PageID.Equals(hp.pageID) {
+		if !hp.isSlotUsed(rid.TupleNum) {
+			return 0, newTupleNotFoundError(nil, "tuple slot %d is already empty", rid.TupleNum)
+		}
+		return rid.TupleNum, nil
+	}
+
+	for i := primitives.SlotID(0); i < hp.numSlots; i++ {
+		if hp.slotPointers[i].isMoved() && hp.links[i].Equals(rid) {
+			return i, nil
+		}
+	}
+	return 0, newTupleNotFoundError(nil, "tuple is not on this page")
+}
+
+// encodeSlot serializes the contents of slot idx as they are stored on disk:
+// the forward pointer or home record ID, if any, followed by the tuple.
+// Must be called with the lock held.
+func (hp *HeapPage) encodeSlot(idx primitives.SlotID) []byte {
+	sp := hp.slotPointers[idx]
+	buffer := &bytes.Buffer{}
+	if sp.flags() != 0 {
+		writeRecordID(buffer, hp.links[idx])
+	}
+	if !sp.isRedirect() && hp.tuples[idx] != nil {
+		buffer.Write(hp.encodeTuple(hp.tuples[idx]))
+	}
+	return buffer.Bytes()
+}
+
+// encodeTuple serializes the fields of t, each at its offset in the page's
+// tuple layout.
+func (hp *HeapPage) encodeTuple(t *tuple.Tuple) []byte {
+	return hp.schema.Encode(t)
+}
+
+// writeRecordID encodes a record ID within the page's own file.
+func writeRecordID(w io.Writer, rid *tuple.TupleRecordID) {
+	var buf [recordIDSize]byte
+	binary.LittleEndian.PutUint64(buf[0:], uint64(rid.PageID.PageNo()))
+	binary.LittleEndian.PutUint16(buf[8:], uint16(rid.TupleNum))
+	w.Write(buf[:])
+}
+
+// readRecordID decodes a record ID written by writeRecordID, in file fileID.
+func readRecordID(r io.Reader, fileID primitives.FileID) (*tuple.TupleRecordID, error) {
+	var buf [recordIDSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, err
+	}
+	pageNo := primitives.PageNumber(binary.LittleEndian.Uint64(buf[0:]))
+	slot := primitives.SlotID(binary.LittleEndian.Uint16(buf[8:]))
+	return tuple.NewTupleRecordID(page.NewPageDescriptor(fileID, pageNo), slot), nil
+}
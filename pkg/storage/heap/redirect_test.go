@@ -0,0 +1,185 @@
+package heap
+
+import (
+	"errors"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+func TestHeapPage_UpdateTuple_KeepsSlot(t *testing.T) {
+	td := mustCreateTupleDesc()
+	hp, err := NewEmptyHeapPage(page.NewPageDescriptor(1, 0), td)
+	if err != nil {
+		t.Fatalf("Failed to create HeapPage: %v", err)
+	}
+
+	for i := range 3 {
+		if err := hp.AddTuple(createTestTuple(td, int64(i), "old")); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+	}
+
+	updated := createTestTuple(td, 1, "new")
+	if err := hp.UpdateTuple(1, updated); err != nil {
+		t.Fatalf("UpdateTuple failed: %v", err)
+	}
+	if updated.RecordID == nil || updated.RecordID.TupleNum != 1 {
+		t.Fatalf("Updated tuple RecordID = %v, want slot 1", updated.RecordID)
+	}
+
+	reloaded, err := NewHeapPage(hp.GetID(), hp.GetPageData(), td)
+	if err != nil {
+		t.Fatalf("Failed to reload page: %v", err)
+	}
+	got, _ := reloaded.GetTupleAt(1)
+	if got == nil || got.String() != updated.String() {
+		t.Errorf("Slot 1 = %v, want %v", got, updated)
+	}
+	if n := len(reloaded.GetTuples()); n != 3 {
+		t.Errorf("Expected 3 tuples, got %d", n)
+	}
+
+	if err := hp.UpdateTuple(5, updated); err == nil {
+		t.Error("Expected error updating an empty slot")
+	}
+}
+
+func TestHeapPage_ForwardPointer(t *testing.T) {
+	td := mustCreateTupleDesc()
+	homePage, _ := NewEmptyHeapPage(page.NewPageDescriptor(1, 0), td)
+	targetPage, _ := NewEmptyHeapPage(page.NewPageDescriptor(1, 1), td)
+
+	original := createTestTuple(td, 7, "original")
+	if err := homePage.AddTuple(original); err != nil {
+		t.Fatalf("AddTuple failed: %v", err)
+	}
+	home := original.RecordID
+
+	moved := createTestTuple(td, 7, "moved")
+	target, err := targetPage.AddMovedTuple(moved, home)
+	if err != nil {
+		t.Fatalf("AddMovedTuple failed: %v", err)
+	}
+	if !moved.RecordID.Equals(home) {
+		t.Errorf("Moved tuple RecordID = %v, want home %v", moved.RecordID, home)
+	}
+	if err := homePage.SetRedirect(home.TupleNum, target); err != nil {
+		t.Fatalf("SetRedirect failed: %v", err)
+	}
+
+	// Both pages survive a round trip through their serialized form
+	for _, hp := range []*HeapPage{homePage, targetPage} {
+		if errs := VerifyPageData(hp.GetPageData(), td); len(errs) > 0 {
+			t.Fatalf("VerifyPageData(page %d) = %v", hp.GetID().PageNo(), errs)
+		}
+	}
+	homePage, _ = NewHeapPage(homePage.GetID(), homePage.GetPageData(), td)
+	targetPage, _ = NewHeapPage(targetPage.GetID(), targetPage.GetPageData(), td)
+
+	got, ok := homePage.Forward(home.TupleNum)
+	if !ok || !got.Equals(target) {
+		t.Fatalf("Forward(%d) = %v, %v; want %v", home.TupleNum, got, ok, target)
+	}
+	if tup, _ := homePage.GetTupleAt(home.TupleNum); tup != nil {
+		t.Errorf("Expected no tuple in a forward pointer slot, got %v", tup)
+	}
+	if n := len(homePage.GetTuples()); n != 0 {
+		t.Errorf("Expected home page scan to skip the forward pointer, got %d tuples", n)
+	}
+
+	tuples := targetPage.GetTuples()
+	if len(tuples) != 1 || !tuples[0].RecordID.Equals(home) {
+		t.Fatalf("Expected the moved tuple with its home RecordID, got %v", tuples)
+	}
+
+	// Updating the moved copy keeps it moved
+	again := createTestTuple(td, 7, "again")
+	if err := targetPage.UpdateTuple(target.TupleNum, again); err != nil {
+		t.Fatalf("UpdateTuple on moved tuple failed: %v", err)
+	}
+	if !again.RecordID.Equals(home) {
+		t.Errorf("Updated moved tuple RecordID = %v, want home %v", again.RecordID, home)
+	}
+
+	// Deleting the copy keeps the RecordID for deleting the forward pointer
+	if err := targetPage.DeleteTuple(again); err != nil {
+		t.Fatalf("DeleteTuple on moved tuple failed: %v", err)
+	}
+	if again.RecordID == nil {
+		t.Fatal("Expected RecordID to be kept after deleting the moved copy")
+	}
+	if err := homePage.DeleteTuple(again); err != nil {
+		t.Fatalf("DeleteTuple on forward pointer failed: %v", err)
+	}
+	if _, ok := homePage.Forward(home.TupleNum); ok {
+		t.Error("Expected forward pointer to be gone")
+	}
+	if homePage.GetNumEmptySlots() != homePage.numSlots || targetPage.GetNumEmptySlots() != targetPage.numSlots {
+		t.Error("Expected both pages to be empty")
+	}
+}
+
+func TestHeapPage_ForwardPointer_Errors(t *testing.T) {
+	td := mustCreateTupleDesc()
+	hp, _ := NewEmptyHeapPage(page.NewPageDescriptor(1, 0), td)
+	tup := createTestTuple(td, 1, "a")
+	if err := hp.AddTuple(tup); err != nil {
+		t.Fatalf("AddTuple failed: %v", err)
+	}
+
+	if err := hp.SetRedirect(0, tuple.NewTupleRecordID(hp.GetID(), 1)); err == nil {
+		t.Error("Expected error redirecting to the same page")
+	}
+	if err := hp.SetRedirect(0, tuple.NewTupleRecordID(page.NewPageDescriptor(2, 1), 0)); err == nil {
+		t.Error("Expected error redirecting to another file")
+	}
+	if _, err := hp.AddMovedTuple(createTestTuple(td, 2, "b"), tup.RecordID); err == nil {
+		t.Error("Expected error moving a tuple to its own home page")
+	}
+}
+
+func TestHeapPage_SetRedirect_PageFull(t *testing.T) {
+	// A single int column is smaller than a forward pointer, so a full page
+	// runs out of space before every slot can be redirected
+	td, err := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"id"})
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	hp, _ := NewEmptyHeapPage(page.NewPageDescriptor(1, 0), td)
+
+	var redirected int
+	for i := primitives.SlotID(0); i < hp.numSlots; i++ {
+		tup := tuple.NewTuple(td)
+		tup.SetField(0, types.NewIntField(int64(i)))
+		if err := hp.AddTuple(tup); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+	}
+	for i := primitives.SlotID(0); i < hp.numSlots; i++ {
+		if err := hp.SetRedirect(i, tuple.NewTupleRecordID(page.NewPageDescriptor(1, 1), i)); err != nil {
+			if !errors.Is(err, ErrPageFull) {
+				t.Fatalf("SetRedirect(%d) failed: %v", i, err)
+			}
+			break
+		}
+		redirected++
+	}
+	if redirected == 0 || redirected == int(hp.numSlots) {
+		t.Fatalf("Expected the page to run out of space part way, redirected %d of %d", redirected, hp.numSlots)
+	}
+
+	before := hp.GetPageData()
+	last := primitives.SlotID(redirected)
+	if err := hp.SetRedirect(last, tuple.NewTupleRecordID(page.NewPageDescriptor(1, 1), last)); !errors.Is(err, ErrPageFull) {
+		t.Fatalf("Expected ErrPageFull, got %v", err)
+	}
+	if string(hp.GetPageData()) != string(before) {
+		t.Error("Expected a failed redirect to leave the page unchanged")
+	}
+	if errs := VerifyPageData(hp.GetPageData(), td); len(errs) > 0 {
+		t.Errorf("VerifyPageData = %v", errs)
+	}
+}
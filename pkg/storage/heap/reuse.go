@@ -0,0 +1,98 @@
+package heap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// rawPageCursor walks the tuples of serialized page data, decoding each into
+// a caller-owned tuple instead of building a HeapPage with a tuple per slot.
+// It is what a HeapFileIterator in tuple reuse mode, interning strings,
+// filtering or decoding some columns only reads pages with.
+type rawPageCursor struct {
+	pid      *page.PageDescriptor
+	data     []byte
+	schema   *tuple.CompiledSchema
+	numSlots primitives.SlotID
+	slot     primitives.SlotID     // Next slot to look at
+	rid      tuple.TupleRecordID   // Record ID of the tuple last decoded
+	filter   *rawFilter            // Skips the tuples failing the scan filters, nil if none
+	columns  []primitives.ColumnID // Fields to decode, sorted, nil for all of them
+}
+
+// reset points the cursor at the first slot of the page data.
+func (c *rawPageCursor) reset(pid *page.PageDescriptor, data []byte, schema *tuple.CompiledSchema) {
+	*c = rawPageCursor{
+		pid:      pid,
+		data:     data,
+		schema:   schema,
+		numSlots: primitives.SlotID(page.PageSize) / primitives.SlotID(schema.Size()+SlotPointerSize),
+		filter:   c.filter,
+		columns:  c.columns,
+	}
+}
+
+// next decodes the next tuple of the page into t, interning its strings in
+// dict unless it is nil, and reports whether there was one. Only the
+// columns of the cursor are decoded if it has any. Empty slots,
+// forward pointers and tuples failing the filter are skipped, the latter
+// before any field but the filtered ones is decoded, and a tuple moved
+// here from another page gets the record ID of its home slot, like
+// HeapPage.GetTuples. t.RecordID points into the cursor and changes with
+// the next call.
+func (c *rawPageCursor) next(t *tuple.Tuple, dict *types.StringDict) (bool, error) {
+	for ; c.slot < c.numSlots; c.slot++ {
+		pos := int(c.slot) * SlotPointerSize
+		sp := SlotPointer{
+			Offset: primitives.SlotID(binary.LittleEndian.Uint16(c.data[pos:])),
+			Length: binary.LittleEndian.Uint16(c.data[pos+2:]),
+		}
+		if sp.Offset == 0 || sp.isRedirect() {
+			continue
+		}
+
+		start, end := int(sp.offset()), int(sp.offset())+int(sp.Length)
+		if end > len(c.data) {
+			return false, newCorruptPageError(nil, "invalid tuple at slot %d: offset %d + length %d exceeds page size", c.slot, start, sp.Length)
+		}
+		tupleData := c.data[start:end]
+
+		c.rid = tuple.TupleRecordID{PageID: c.pid, TupleNum: c.slot}
+		t.RecordID = &c.rid
+		if sp.isMoved() {
+			home, err := readRecordID(bytes.NewReader(tupleData), c.pid.FileID())
+			if err != nil {
+				return false, newCorruptPageError(err, "failed to read record ID at slot %d", c.slot)
+			}
+			t.RecordID = home
+			tupleData = tupleData[recordIDSize:]
+		}
+
+		if c.filter != nil {
+			ok, err := c.filter.matches(c.schema, tupleData)
+			if err != nil {
+				return false, newInvalidTupleError(err, "failed to filter tuple at slot %d", c.slot)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		var err error
+		if c.columns != nil {
+			err = c.schema.DecodeColumnsInto(t, tupleData, c.columns, dict)
+		} else {
+			err = c.schema.DecodeInto(t, tupleData, dict)
+		}
+		if err != nil {
+			return false, newCorruptPageError(err, "failed to read tuple at slot %d", c.slot)
+		}
+		c.slot++
+		return true, nil
+	}
+	return false, nil
+}
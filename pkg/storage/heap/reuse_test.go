@@ -0,0 +1,282 @@
+package heap
+
+import (
+	"slices"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+	"unsafe"
+)
+
+// scanAll reads every tuple of hf, rendered with its record ID so the scans
+// of both modes can be compared.
+func scanAll(t *testing.T, hf *HeapFile, reuse *tuple.Tuple, dict *types.StringDict) []string {
+	t.Helper()
+	it := NewHeapFileIterator(hf, primitives.NewTransactionID())
+	if reuse != nil {
+		it.ReuseTuple(reuse)
+	}
+	it.InternStrings(dict)
+	if err := it.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer it.Close()
+
+	var rows []string
+	for {
+		hasNext, err := it.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			return rows
+		}
+		tup, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if reuse != nil && tup != reuse {
+			t.Fatal("Expected Next to return the reused tuple")
+		}
+		rows = append(rows, tup.RecordID.String()+" "+tup.String())
+	}
+}
+
+func TestHeapFileIterator_ReuseTuple(t *testing.T) {
+	td := mustCreateTupleDesc()
+	filePath, _ := createTempFile(t, "reuse.dat")
+	hf, err := NewHeapFile(filePath, td)
+	if err != nil {
+		t.Fatalf("NewHeapFile failed: %v", err)
+	}
+	defer hf.Close()
+
+	home, _ := NewEmptyHeapPage(page.NewPageDescriptor(hf.GetID(), 0), td)
+	empty, _ := NewEmptyHeapPage(page.NewPageDescriptor(hf.GetID(), 1), td)
+	target, _ := NewEmptyHeapPage(page.NewPageDescriptor(hf.GetID(), 2), td)
+
+	var added []*tuple.Tuple
+	for i, name := range []string{"alice", "bob", "carol", "dave"} {
+		tup := createTestTuple(td, int64(i), name)
+		if err := home.AddTuple(tup); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+		added = append(added, tup)
+	}
+	if err := home.DeleteTuple(added[1]); err != nil {
+		t.Fatalf("DeleteTuple failed: %v", err)
+	}
+
+	// Move carol to the last page, leaving a forward pointer behind
+	moved := createTestTuple(td, 2, "carol moved")
+	rid, err := target.AddMovedTuple(moved, added[2].RecordID)
+	if err != nil {
+		t.Fatalf("AddMovedTuple failed: %v", err)
+	}
+	if err := home.SetRedirect(added[2].RecordID.TupleNum, rid); err != nil {
+		t.Fatalf("SetRedirect failed: %v", err)
+	}
+	if err := target.AddTuple(createTestTuple(td, 4, "erin")); err != nil {
+		t.Fatalf("AddTuple failed: %v", err)
+	}
+
+	for _, hp := range []*HeapPage{home, empty, target} {
+		if err := hf.WritePage(hp); err != nil {
+			t.Fatalf("WritePage failed: %v", err)
+		}
+	}
+
+	want := scanAll(t, hf, nil, nil)
+	if len(want) != 4 {
+		t.Fatalf("Expected 4 tuples, got %d: %v", len(want), want)
+	}
+	modes := map[string]func() []string{
+		"reuse":        func() []string { return scanAll(t, hf, tuple.NewTuple(td), nil) },
+		"intern":       func() []string { return scanAll(t, hf, nil, types.NewStringDict(0)) },
+		"reuse+intern": func() []string { return scanAll(t, hf, tuple.NewTuple(td), types.NewStringDict(0)) },
+	}
+	for name, scan := range modes {
+		got := scan()
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d tuples, want %d: %v", name, len(got), len(want), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: tuple %d = %q, want %q", name, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestHeapFileIterator_InternStrings(t *testing.T) {
+	td := mustCreateTupleDesc()
+	filePath, _ := createTempFile(t, "intern.dat")
+	hf, err := NewHeapFile(filePath, td)
+	if err != nil {
+		t.Fatalf("NewHeapFile failed: %v", err)
+	}
+	defer hf.Close()
+
+	hp, _ := NewEmptyHeapPage(page.NewPageDescriptor(hf.GetID(), 0), td)
+	for i := range 6 {
+		if err := hp.AddTuple(createTestTuple(td, int64(i), []string{"red", "green"}[i%2])); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+	}
+	if err := hf.WritePage(hp); err != nil {
+		t.Fatalf("WritePage failed: %v", err)
+	}
+
+	dict := types.NewStringDict(0)
+	it := NewHeapFileIterator(hf, primitives.NewTransactionID())
+	it.InternStrings(dict)
+	if err := it.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer it.Close()
+
+	var tuples []*tuple.Tuple
+	for {
+		hasNext, err := it.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			break
+		}
+		tup, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		tuples = append(tuples, tup)
+	}
+
+	if len(tuples) != 6 || dict.Len() != 2 {
+		t.Fatalf("Expected 6 tuples and 2 interned values, got %d and %d", len(tuples), dict.Len())
+	}
+	name := func(i int) string {
+		f, _ := tuples[i].GetField(1)
+		return f.(*types.StringField).Value
+	}
+	if name(0) != "red" || unsafe.StringData(name(0)) != unsafe.StringData(name(4)) {
+		t.Error("Expected rows with the same value to share one string")
+	}
+	if tuples[0] == tuples[1] || tuples[0].RecordID.Equals(tuples[1].RecordID) {
+		t.Error("Expected a tuple and record ID of its own per row")
+	}
+}
+
+func TestHeapFileIterator_SetFilters(t *testing.T) {
+	td := mustCreateTupleDesc()
+	filePath, _ := createTempFile(t, "filter.dat")
+	hf, err := NewHeapFile(filePath, td)
+	if err != nil {
+		t.Fatalf("NewHeapFile failed: %v", err)
+	}
+	defer hf.Close()
+
+	for pageNo := range 3 {
+		hp, _ := NewEmptyHeapPage(page.NewPageDescriptor(hf.GetID(), primitives.PageNumber(pageNo)), td)
+		for i := range 10 {
+			if err := hp.AddTuple(createTestTuple(td, int64(pageNo*10+i), []string{"red", "green"}[i%2])); err != nil {
+				t.Fatalf("AddTuple failed: %v", err)
+			}
+		}
+		if err := hf.WritePage(hp); err != nil {
+			t.Fatalf("WritePage failed: %v", err)
+		}
+	}
+
+	filters := []ScanFilter{
+		{Field: 0, Op: primitives.GreaterThanOrEqual, Value: types.NewIntField(5)},
+		{Field: 1, Op: primitives.Equals, Value: types.NewStringField("green", types.StringMaxSize)},
+	}
+	for _, reuse := range []*tuple.Tuple{nil, tuple.NewTuple(td)} {
+		it := NewHeapFileIterator(hf, primitives.NewTransactionID())
+		it.ReuseTuple(reuse)
+		it.SetFilters(filters)
+		if err := it.Open(); err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+
+		var ids []int64
+		for {
+			hasNext, err := it.HasNext()
+			if err != nil {
+				t.Fatalf("HasNext failed: %v", err)
+			}
+			if !hasNext {
+				break
+			}
+			tup, err := it.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if ok, _ := MatchAll(filters, tup); !ok {
+				t.Errorf("row %s fails the filters", tup)
+			}
+			f, _ := tup.GetField(0)
+			ids = append(ids, f.(*types.IntField).Value)
+		}
+		it.Close()
+
+		// Odd ids are green; 1 and 3 are below 5
+		if len(ids) != 13 || ids[0] != 5 || ids[12] != 29 {
+			t.Errorf("reuse=%v: got ids %v, want the 13 odd ids from 5 to 29", reuse != nil, ids)
+		}
+	}
+}
+
+func TestHeapFileIterator_SetColumns(t *testing.T) {
+	td := mustCreateTupleDesc()
+	filePath, _ := createTempFile(t, "columns.dat")
+	hf, err := NewHeapFile(filePath, td)
+	if err != nil {
+		t.Fatalf("NewHeapFile failed: %v", err)
+	}
+	defer hf.Close()
+
+	hp, _ := NewEmptyHeapPage(page.NewPageDescriptor(hf.GetID(), 0), td)
+	for i := range 10 {
+		if err := hp.AddTuple(createTestTuple(td, int64(i), []string{"red", "green"}[i%2])); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+	}
+	if err := hf.WritePage(hp); err != nil {
+		t.Fatalf("WritePage failed: %v", err)
+	}
+
+	// The filter still sees the name, which the rows leave out
+	it := NewHeapFileIterator(hf, primitives.NewTransactionID())
+	it.SetColumns([]primitives.ColumnID{0})
+	it.SetFilters([]ScanFilter{{Field: 1, Op: primitives.Equals, Value: types.NewStringField("green", types.StringMaxSize)}})
+	if err := it.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer it.Close()
+
+	var ids []int64
+	for {
+		hasNext, err := it.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			break
+		}
+		tup, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if name, _ := tup.GetField(1); name != nil {
+			t.Errorf("name %v decoded, only the id was asked for", name)
+		}
+		f, _ := tup.GetField(0)
+		ids = append(ids, f.(*types.IntField).Value)
+	}
+	if !slices.Equal(ids, []int64{1, 3, 5, 7, 9}) {
+		t.Errorf("got ids %v, want the odd ids", ids)
+	}
+}
@@ -0,0 +1,108 @@
+package heap
+
+import (
+	"fmt"
+	"path/filepath"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+// setupScanBenchmark writes numPages full pages of (id, value, description)
+// tuples to a new heap file.
+func setupScanBenchmark(b *testing.B, numPages int) *HeapFile {
+	td, err := tuple.NewTupleDesc(
+		[]types.Type{types.IntType, types.IntType, types.StringType},
+		[]string{"id", "value", "description"},
+	)
+	if err != nil {
+		b.Fatalf("Failed to create tuple description: %v", err)
+	}
+
+	hf, err := NewHeapFile(primitives.Filepath(filepath.Join(b.TempDir(), "scan.dat")), td)
+	if err != nil {
+		b.Fatalf("Failed to create heap file: %v", err)
+	}
+
+	id := int64(0)
+	for pageNo := range numPages {
+		hp, err := NewEmptyHeapPage(page.NewPageDescriptor(hf.GetID(), primitives.PageNumber(pageNo)), td)
+		if err != nil {
+			b.Fatalf("Failed to create page: %v", err)
+		}
+		for hp.GetNumEmptySlots() > 0 {
+			t := tuple.NewTuple(td)
+			t.SetField(0, types.NewIntField(id))
+			t.SetField(1, types.NewIntField(id*100))
+			t.SetField(2, types.NewStringField(fmt.Sprintf("description_%d", id), types.StringMaxSize))
+			if err := hp.AddTuple(t); err != nil {
+				break
+			}
+			id++
+		}
+		if err := hf.WritePage(hp); err != nil {
+			b.Fatalf("Failed to write page: %v", err)
+		}
+	}
+	return hf
+}
+
+// BenchmarkHeapFileScan reads every page of a heap file from disk and decodes
+// its tuples, the work a sequential scan does on a cold buffer pool. The
+// reuse variant decodes every tuple into one caller-owned tuple.
+func BenchmarkHeapFileScan(b *testing.B) {
+	for _, numPages := range []int{10, 100} {
+		for _, reuse := range []bool{false, true} {
+			b.Run(fmt.Sprintf("pages_%d/reuse_%t", numPages, reuse), func(b *testing.B) {
+				hf := setupScanBenchmark(b, numPages)
+				defer hf.Close()
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					it := NewHeapFileIterator(hf, primitives.NewTransactionID())
+					if reuse {
+						it.ReuseTuple(tuple.NewTuple(hf.GetTupleDesc()))
+					}
+					if err := it.Open(); err != nil {
+						b.Fatalf("Failed to open iterator: %v", err)
+					}
+					for {
+						hasNext, err := it.HasNext()
+						if err != nil {
+							b.Fatalf("HasNext failed: %v", err)
+						}
+						if !hasNext {
+							break
+						}
+						if _, err := it.Next(); err != nil {
+							b.Fatalf("Next failed: %v", err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkNewHeapPage parses a full page.
+func BenchmarkNewHeapPage(b *testing.B) {
+	hf := setupScanBenchmark(b, 1)
+	defer hf.Close()
+
+	pid := page.NewPageDescriptor(hf.GetID(), 0)
+	pg, err := hf.ReadPage(pid)
+	if err != nil {
+		b.Fatalf("Failed to read page: %v", err)
+	}
+	data := pg.GetPageData()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewHeapPage(pid, data, hf.GetTupleDesc()); err != nil {
+			b.Fatalf("NewHeapPage failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package heap
+
+import (
+	"fmt"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+)
+
+// ScanFilter compares one field of a row with a constant. A heap scan given
+// filters evaluates them itself and skips the rows failing any of them, so
+// the operators above it never see those rows, and a scan decoding pages
+// from their serialized form decodes only the filtered fields of a row
+// until it passes.
+type ScanFilter struct {
+	Field primitives.ColumnID  // Index of the compared field
+	Op    primitives.Predicate // Comparison of the field with Value
+	Value types.Field          // Constant the field is compared with
+}
+
+// Matches reports whether field passes the filter. A missing field never
+// does.
+func (f ScanFilter) Matches(field types.Field) (bool, error) {
+	if field == nil {
+		return false, nil
+	}
+	return field.Compare(f.Op, f.Value)
+}
+
+// MatchesTuple reports whether t passes the filter.
+func (f ScanFilter) MatchesTuple(t *tuple.Tuple) (bool, error) {
+	field, err := t.GetField(f.Field)
+	if err != nil {
+		return false, err
+	}
+	return f.Matches(field)
+}
+
+func (f ScanFilter) String() string {
+	return fmt.Sprintf("field[%d] %s %s", f.Field, f.Op, f.Value)
+}
+
+// MatchAll reports whether t passes every filter.
+func MatchAll(filters []ScanFilter, t *tuple.Tuple) (bool, error) {
+	for _, f := range filters {
+		ok, err := f.MatchesTuple(t)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// rawFilter evaluates scan filters on serialized tuples, decoding each
+// filtered field into a field of its own that is reused from row to row.
+type rawFilter struct {
+	filters []ScanFilter
+	scratch []types.Field
+}
+
+func newRawFilter(filters []ScanFilter) *rawFilter {
+	return &rawFilter{filters: filters, scratch: make([]types.Field, len(filters))}
+}
+
+// matches reports whether the serialized tuple data laid out by schema
+// passes every filter.
+func (rf *rawFilter) matches(schema *tuple.CompiledSchema, data []byte) (bool, error) {
+	for i, f := range rf.filters {
+		if int(f.Field) >= len(schema.Desc.Types) {
+			return false, newInvalidTupleError(nil, "filter on field %d, tuple has %d", f.Field, len(schema.Desc.Types))
+		}
+		fieldType := schema.Desc.Types[f.Field]
+		off := schema.Offset(f.Field)
+		end := off + fieldType.Size()
+		if uint32(len(data)) < end {
+			return false, newInvalidTupleError(nil, "field %d needs bytes up to %d, got %d", f.Field, end, len(data))
+		}
+
+		field, err := types.DecodeFieldInto(rf.scratch[i], data[off:end], fieldType, nil)
+		if err != nil {
+			return false, newInvalidTupleError(err, "field %d", f.Field)
+		}
+		rf.scratch[i] = field
+
+		ok, err := f.Matches(field)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,46 @@
+package heap
+
+import (
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+)
+
+// PageUsage describes how the bytes of a heap page are used.
+type PageUsage struct {
+	LiveTuples      int // Tuples stored on the page, including tuples moved here
+	LiveBytes       int // Bytes of the stored tuples
+	ForwardPointers int // Slots pointing to a tuple moved to another page
+	DeadTuples      int // Estimated tuples deleted or moved away whose bytes are not reclaimed yet
+	DeadBytes       int // Bytes left behind by deleted or moved tuples, reclaimed by compaction
+	FreeBytes       int // Contiguous bytes available for new tuples
+}
+
+// Usage reports how the page's bytes are used. The slot pointer array is
+// neither live, dead nor free.
+func (hp *HeapPage) Usage() PageUsage {
+	hp.mutex.RLock()
+	defer hp.mutex.RUnlock()
+
+	var u PageUsage
+	used := 0
+	for i := primitives.SlotID(0); i < hp.numSlots; i++ {
+		sp := hp.slotPointers[i]
+		if sp.Offset == 0 {
+			continue
+		}
+		used += int(sp.Length)
+		if sp.isRedirect() {
+			u.ForwardPointers++
+			continue
+		}
+		u.LiveTuples++
+		u.LiveBytes += int(sp.Length)
+	}
+
+	u.DeadBytes = max(int(hp.freeSpacePtr)-int(hp.getHeaderSize())-used, 0)
+	if size := hp.schema.Size(); size > 0 {
+		u.DeadTuples = u.DeadBytes / int(size)
+	}
+	u.FreeBytes = page.PageSize - int(hp.freeSpacePtr)
+	return u
+}
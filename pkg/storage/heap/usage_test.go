@@ -0,0 +1,48 @@
+package heap
+
+import (
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"testing"
+)
+
+func TestHeapPage_Usage(t *testing.T) {
+	td := mustCreateTupleDesc()
+	hp, err := NewEmptyHeapPage(page.NewPageDescriptor(1, 0), td)
+	if err != nil {
+		t.Fatalf("Failed to create HeapPage: %v", err)
+	}
+
+	empty := hp.Usage()
+	if empty.LiveTuples != 0 || empty.DeadBytes != 0 || empty.FreeBytes != page.PageSize-int(hp.getHeaderSize()) {
+		t.Errorf("empty page usage = %+v", empty)
+	}
+
+	tuples := make([]*tuple.Tuple, 3)
+	for i := range tuples {
+		tuples[i] = createTestTuple(td, int64(i), "User")
+		if err := hp.AddTuple(tuples[i]); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+	}
+	if err := hp.DeleteTuple(tuples[0]); err != nil {
+		t.Fatalf("DeleteTuple failed: %v", err)
+	}
+
+	size := int(hp.schema.Size())
+	u := hp.Usage()
+	if u.LiveTuples != 2 || u.LiveBytes != 2*size {
+		t.Errorf("live = %d tuples, %d bytes, expected 2 and %d", u.LiveTuples, u.LiveBytes, 2*size)
+	}
+	if u.DeadTuples != 1 || u.DeadBytes != size {
+		t.Errorf("dead = %d tuples, %d bytes, expected 1 and %d", u.DeadTuples, u.DeadBytes, size)
+	}
+	if total := int(hp.getHeaderSize()) + u.LiveBytes + u.DeadBytes + u.FreeBytes; total != page.PageSize {
+		t.Errorf("usage adds up to %d bytes, expected %d", total, page.PageSize)
+	}
+
+	hp.Compact()
+	if u := hp.Usage(); u.DeadBytes != 0 || u.LiveTuples != 2 {
+		t.Errorf("usage after compaction = %+v", u)
+	}
+}
@@ -0,0 +1,85 @@
+package heap
+
+import (
+	"encoding/binary"
+	"slices"
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+)
+
+// VerifyPageData checks the structure of serialized heap page data without
+// building a HeapPage. Unlike NewHeapPage, which stops at the first problem,
+// it reports every inconsistency it finds:
+//   - the data is not exactly one page long
+//   - a used slot points into the slot pointer array or past the end of the page
+//   - the tuple regions of two used slots overlap
+//   - a slot is flagged as both a forward pointer and a moved tuple
+//   - a forward pointer or moved tuple is too short for its record ID
+//   - a tuple cannot be decoded with the page's tuple description
+//
+// An empty result means the page can be loaded safely.
+func VerifyPageData(data []byte, td *tuple.TupleDescription) []error {
+	if len(data) != page.PageSize {
+		return []error{newCorruptPageError(nil, "invalid page data size: expected %d, got %d", page.PageSize, len(data))}
+	}
+
+	hp := &HeapPage{tupleDesc: td, schema: tuple.Compile(td)}
+	numSlots := hp.getNumTuples()
+	headerSize := int(hp.getHeaderSize())
+
+	type region struct {
+		slot       primitives.SlotID
+		start, end int
+	}
+
+	var errs []error
+	var regions []region
+	for i := primitives.SlotID(0); i < numSlots; i++ {
+		pos := int(i) * SlotPointerSize
+		sp := SlotPointer{
+			Offset: primitives.SlotID(binary.LittleEndian.Uint16(data[pos:])),
+			Length: binary.LittleEndian.Uint16(data[pos+2:]),
+		}
+		if sp.Offset == 0 {
+			continue
+		}
+		offset, length := int(sp.offset()), int(sp.Length)
+
+		switch {
+		case offset < headerSize:
+			errs = append(errs, newCorruptPageError(nil, "slot %d: offset %d lies inside the slot pointer array (%d bytes)", i, offset, headerSize))
+			continue
+		case offset+length > page.PageSize:
+			errs = append(errs, newCorruptPageError(nil, "slot %d: offset %d + length %d exceeds page size", i, offset, length))
+			continue
+		}
+
+		tupleData := data[offset : offset+length]
+		switch {
+		case sp.isRedirect() && sp.isMoved():
+			errs = append(errs, newCorruptPageError(nil, "slot %d: flagged as both a forward pointer and a moved tuple", i))
+		case sp.flags() != 0 && length < recordIDSize:
+			errs = append(errs, newCorruptPageError(nil, "slot %d: length %d is too short for a record ID", i, length))
+		case sp.isRedirect():
+		default:
+			if sp.isMoved() {
+				tupleData = tupleData[recordIDSize:]
+			}
+			if _, err := hp.schema.Decode(tupleData); err != nil {
+				errs = append(errs, newCorruptPageError(err, "slot %d: failed to decode tuple", i))
+			}
+		}
+		regions = append(regions, region{slot: i, start: offset, end: offset + length})
+	}
+
+	slices.SortFunc(regions, func(a, b region) int { return a.start - b.start })
+	for i := 1; i < len(regions); i++ {
+		prev, cur := regions[i-1], regions[i]
+		if cur.start < prev.end {
+			errs = append(errs, newCorruptPageError(nil, "slots %d and %d overlap at bytes [%d, %d)", prev.slot, cur.slot, cur.start, min(prev.end, cur.end)))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,102 @@
+package heap
+
+import (
+	"encoding/binary"
+	"storemy/pkg/invariant"
+	"storemy/pkg/storage/page"
+	"testing"
+)
+
+func buildVerifyTestPage(t *testing.T) []byte {
+	t.Helper()
+	td := mustCreateTupleDesc()
+
+	hp, err := NewEmptyHeapPage(page.NewPageDescriptor(1, 0), td)
+	if err != nil {
+		t.Fatalf("NewEmptyHeapPage failed: %v", err)
+	}
+	for i := int64(0); i < 3; i++ {
+		if err := hp.AddTuple(createTestTupleForFile(td, i, "row")); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+	}
+	return hp.GetPageData()
+}
+
+func TestVerifyPageData_ValidPage(t *testing.T) {
+	data := buildVerifyTestPage(t)
+	if errs := VerifyPageData(data, mustCreateTupleDesc()); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	empty := make([]byte, page.PageSize)
+	if errs := VerifyPageData(empty, mustCreateTupleDesc()); len(errs) != 0 {
+		t.Errorf("expected no errors for an empty page, got %v", errs)
+	}
+}
+
+func TestVerifyPageData_Corruption(t *testing.T) {
+	td := mustCreateTupleDesc()
+
+	tests := []struct {
+		name    string
+		corrupt func(data []byte)
+	}{
+		{
+			name: "offset inside slot array",
+			corrupt: func(data []byte) {
+				binary.LittleEndian.PutUint16(data[0:], 2)
+			},
+		},
+		{
+			name: "tuple past end of page",
+			corrupt: func(data []byte) {
+				binary.LittleEndian.PutUint16(data[2:], page.PageSize)
+			},
+		},
+		{
+			name: "overlapping slots",
+			corrupt: func(data []byte) {
+				copy(data[SlotPointerSize:2*SlotPointerSize], data[0:SlotPointerSize])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildVerifyTestPage(t)
+			tt.corrupt(data)
+
+			if errs := VerifyPageData(data, td); len(errs) == 0 {
+				t.Error("expected corruption to be reported")
+			}
+		})
+	}
+
+	if errs := VerifyPageData(make([]byte, 10), td); len(errs) != 1 {
+		t.Errorf("expected a single size error, got %v", errs)
+	}
+}
+
+func TestGetPageData_AssertsLayout(t *testing.T) {
+	td := mustCreateTupleDesc()
+	hp, err := NewEmptyHeapPage(page.NewPageDescriptor(1, 0), td)
+	if err != nil {
+		t.Fatalf("NewEmptyHeapPage failed: %v", err)
+	}
+	for i := int64(0); i < 2; i++ {
+		if err := hp.AddTuple(createTestTupleForFile(td, i, "row")); err != nil {
+			t.Fatalf("AddTuple failed: %v", err)
+		}
+	}
+
+	// Point the second slot back over the first
+	hp.slotPointers[1].Offset = hp.slotPointers[0].Offset
+
+	defer func() {
+		if _, ok := recover().(*invariant.Violation); !ok {
+			t.Error("expected an invariant violation for overlapping slots")
+		}
+	}()
+	hp.GetPageData()
+}
@@ -6,6 +6,7 @@ import (
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
+	"storemy/pkg/vfs"
 	"sync"
 )
 
@@ -16,11 +17,21 @@ type BTreeFile struct {
 	keyType  types.Type
 	numPages primitives.PageNumber
 	mutex    sync.RWMutex
+
+	latches   LatchTable
+	root      primitives.PageNumber
+	hasRoot   bool
+	rootMutex sync.RWMutex
 }
 
 // NewBTreeFile creates or opens a B+Tree index file
 func NewBTreeFile(filename primitives.Filepath, keyType types.Type) (*BTreeFile, error) {
-	baseFile, err := page.NewBaseFile(filename)
+	return NewBTreeFileWithFS(vfs.OS, filename, keyType)
+}
+
+// NewBTreeFileWithFS creates or opens a B+Tree index file stored on fsys.
+func NewBTreeFileWithFS(fsys vfs.FS, filename primitives.Filepath, keyType types.Type) (*BTreeFile, error) {
+	baseFile, err := page.NewBaseFileWithFS(fsys, filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create base file: %w", err)
 	}
@@ -35,6 +46,7 @@ func NewBTreeFile(filename primitives.Filepath, keyType types.Type) (*BTreeFile,
 		indexID:  0, // Will be set by BTree
 		keyType:  keyType,
 		numPages: numPages,
+		hasRoot:  numPages > 0, // At page 0 by convention
 	}
 
 	return bf, nil
@@ -230,6 +230,18 @@ func (p *BTreePage) SetBeforeImage() {
 	p.beforeImage = p.GetPageData()
 }
 
+// Copy returns an independent copy of this page, including its before-image
+func (p *BTreePage) Copy() page.Page {
+	cp, err := DeserializeBTreePage(p.GetPageData(), p.pageID)
+	if err != nil {
+		return nil
+	}
+	cp.isDirty = p.isDirty
+	cp.dirtyTxn = p.dirtyTxn
+	cp.beforeImage = slices.Clone(p.beforeImage)
+	return cp
+}
+
 func (p *BTreePage) SetParent(id primitives.PageNumber) {
 	p.ParentPage = id
 }
@@ -0,0 +1,79 @@
+package btree
+
+import (
+	"storemy/pkg/primitives"
+	"sync"
+)
+
+// LatchTable hands out the page latches of a B+Tree file.
+//
+// Latches are short-term reader/writer mutexes protecting the in-memory
+// contents of a page while one tree operation reads or changes it. Unlike
+// the lock manager's page locks they belong to an operation rather than a
+// transaction, are released as soon as the operation no longer needs the
+// page, and are never seen by deadlock detection: callers must take them in
+// an order that cannot deadlock (top-down, and left to right along the
+// leaf chain).
+type LatchTable struct {
+	mutex   sync.Mutex
+	latches map[primitives.PageNumber]*sync.RWMutex
+}
+
+// Latch returns the latch of a page, creating it on first use.
+func (lt *LatchTable) Latch(pageNo primitives.PageNumber) *sync.RWMutex {
+	lt.mutex.Lock()
+	defer lt.mutex.Unlock()
+
+	if lt.latches == nil {
+		lt.latches = make(map[primitives.PageNumber]*sync.RWMutex)
+	}
+	latch, ok := lt.latches[pageNo]
+	if !ok {
+		latch = &sync.RWMutex{}
+		lt.latches[pageNo] = latch
+	}
+	return latch
+}
+
+// Latches returns the latch table shared by every operation on this file.
+func (bf *BTreeFile) Latches() *LatchTable {
+	return &bf.latches
+}
+
+// Root returns the page number of the root, and false if the tree has none
+// yet. A file opened with pages has its root at page 0.
+func (bf *BTreeFile) Root() (primitives.PageNumber, bool) {
+	bf.rootMutex.RLock()
+	defer bf.rootMutex.RUnlock()
+	return bf.root, bf.hasRoot
+}
+
+// SetRoot records pageNo as the root after the tree grew or shrank a level.
+// The caller must hold the exclusive latch of the old root, so that readers
+// that latched it before the change can notice it by calling Root again.
+func (bf *BTreeFile) SetRoot(pageNo primitives.PageNumber) {
+	bf.rootMutex.Lock()
+	defer bf.rootMutex.Unlock()
+	bf.root = pageNo
+	bf.hasRoot = true
+}
+
+// InitRoot gives an empty tree its first root, the page allocated by
+// allocate, and returns the root. If the tree already has a root, allocate
+// is not called; concurrent callers agree on a single root.
+func (bf *BTreeFile) InitRoot(allocate func() (primitives.PageNumber, error)) (primitives.PageNumber, error) {
+	bf.rootMutex.Lock()
+	defer bf.rootMutex.Unlock()
+
+	if bf.hasRoot {
+		return bf.root, nil
+	}
+
+	root, err := allocate()
+	if err != nil {
+		return 0, err
+	}
+	bf.root = root
+	bf.hasRoot = true
+	return root, nil
+}
@@ -7,6 +7,7 @@ import (
 	"storemy/pkg/storage/page"
 	"storemy/pkg/tuple"
 	"storemy/pkg/types"
+	"storemy/pkg/vfs"
 	"sync"
 )
 
@@ -51,6 +52,11 @@ type HashFile struct {
 //   - *HashFile: The opened or created hash file
 //   - error: Error if file operations fail or invalid parameters provided
 func NewHashFile(filePath primitives.Filepath, keyType types.Type, numBuckets BucketNumber) (*HashFile, error) {
+	return NewHashFileWithFS(vfs.OS, filePath, keyType, numBuckets)
+}
+
+// NewHashFileWithFS creates or opens a hash index file stored on fsys.
+func NewHashFileWithFS(fsys vfs.FS, filePath primitives.Filepath, keyType types.Type, numBuckets BucketNumber) (*HashFile, error) {
 	if filePath == "" {
 		return nil, fmt.Errorf("filePath cannot be empty")
 	}
@@ -59,7 +65,7 @@ func NewHashFile(filePath primitives.Filepath, keyType types.Type, numBuckets Bu
 		numBuckets = DefaultBuckets
 	}
 
-	baseFile, err := page.NewBaseFile(filePath)
+	baseFile, err := page.NewBaseFileWithFS(fsys, filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create base file: %w", err)
 	}
@@ -173,6 +173,19 @@ func (hp *HashPage) SetBeforeImage() {
 	hp.beforeImage = hp.GetPageData()
 }
 
+// Copy returns an independent copy of this page, including its before-image.
+// Implements page.Page interface.
+func (hp *HashPage) Copy() page.Page {
+	cp, err := DeserializeHashPage(hp.GetPageData(), hp.pageID)
+	if err != nil {
+		return nil
+	}
+	cp.isDirty = hp.isDirty
+	cp.dirtyTxn = hp.dirtyTxn
+	cp.beforeImage = slices.Clone(hp.beforeImage)
+	return cp
+}
+
 // IsFull returns true if the page cannot accept more entries.
 // Pages that are full require overflow pages for additional entries.
 func (hp *HashPage) IsFull() bool {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"storemy/pkg/primitives"
+	"storemy/pkg/vfs"
 	"sync"
 )
 
@@ -22,10 +23,14 @@ import (
 //
 // Thread-safety: All public methods use read/write locks to ensure safe concurrent access.
 type BaseFile struct {
-	file     *os.File            // The underlying OS file handle for I/O operations
-	fileID   primitives.FileID   // Unique identifier generated from the file path hash
-	mutex    sync.RWMutex        // Read-write mutex for thread-safe operations
-	filePath primitives.Filepath // Absolute path to the database file
+	file       vfs.File            // The underlying file handle for I/O operations
+	fileID     primitives.FileID   // Unique identifier generated from the file path hash
+	mutex      sync.RWMutex        // Read-write mutex for thread-safe operations
+	filePath   primitives.Filepath // Absolute path to the database file
+	fsys       vfs.FS              // File system holding the file, to reopen it
+	syncPolicy vfs.SyncPolicy      // How writes are made durable
+	directIO   bool                // Opened with vfs.ODirect, bypassing the OS page cache
+	unsynced   bool                // Writes since the last Sync may not be durable
 }
 
 // NewBaseFile creates a new base file handler.
@@ -41,11 +46,24 @@ type BaseFile struct {
 //   - *BaseFile: A pointer to the initialized BaseFile structure
 //   - error: An error if the filename is empty or file opening fails
 func NewBaseFile(filePath primitives.Filepath) (*BaseFile, error) {
+	return NewBaseFileWithFS(vfs.OS, filePath)
+}
+
+// NewBaseFileWithFS creates a base file handler for a file stored on fsys.
+//
+// Parameters:
+//   - fsys: The file system holding the file (vfs.OS for the real disk)
+//   - filePath: The path to the database file to open
+//
+// Returns:
+//   - *BaseFile: A pointer to the initialized BaseFile structure
+//   - error: An error if the filename is empty or file opening fails
+func NewBaseFileWithFS(fsys vfs.FS, filePath primitives.Filepath) (*BaseFile, error) {
 	if filePath == "" {
 		return nil, fmt.Errorf("filePath cannot be empty")
 	}
 
-	file, err := openFile(filePath)
+	file, err := openFile(fsys, filePath, vfs.SyncOSync, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -54,6 +72,7 @@ func NewBaseFile(filePath primitives.Filepath) (*BaseFile, error) {
 		file:     file,
 		fileID:   filePath.Hash(),
 		filePath: filePath,
+		fsys:     fsys,
 	}, nil
 }
 
@@ -68,17 +87,17 @@ func (bf *BaseFile) GetID() primitives.FileID {
 	return bf.fileID
 }
 
-// GetFile returns the underlying os.File handle.
+// GetFile returns the underlying file handle.
 //
 // This method is primarily intended for use by subclasses that need
 // direct access to the file handle for specialized operations.
 //
 // Returns:
-//   - *os.File: The underlying OS file handle
+//   - vfs.File: The underlying file handle
 //
 // Note: Direct manipulation of the file handle should be done carefully
 // to avoid breaking the thread-safety guarantees provided by BaseFile.
-func (bf *BaseFile) GetFile() *os.File {
+func (bf *BaseFile) GetFile() vfs.File {
 	return bf.file
 }
 
@@ -141,6 +160,23 @@ func (bf *BaseFile) ReadPageData(pageNo primitives.PageNumber) ([]byte, error) {
 	return pageData, err
 }
 
+// ReadPageDataInto is ReadPageData reading into buf, which must be PageSize
+// bytes, so that a caller reading many pages can reuse one buffer.
+func (bf *BaseFile) ReadPageDataInto(pageNo primitives.PageNumber, buf []byte) error {
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+
+	if bf.file == nil {
+		return fmt.Errorf("file is closed")
+	}
+	if len(buf) != PageSize {
+		return fmt.Errorf("invalid page buffer size: expected %d, got %d", PageSize, len(buf))
+	}
+
+	_, err := bf.file.ReadAt(buf, int64(pageNo)*int64(PageSize))
+	return err
+}
+
 // WritePageData writes raw page data to disk at the specified page number.
 //
 // This method writes exactly PageSize bytes to the file at the offset
@@ -156,8 +192,9 @@ func (bf *BaseFile) ReadPageData(pageNo primitives.PageNumber) ([]byte, error) {
 //
 // Thread-safety: Uses write lock to ensure exclusive access during write.
 //
-// Note: This method calls Sync() to ensure durability, which may impact
-// performance for high-frequency writes. Consider batching writes when possible.
+// Note: Under the default vfs.SyncOSync policy the write is durable when this
+// method returns. Under the other policies it is durable only after the next
+// call to Sync, which lets a batch of page writes share one sync.
 //
 // Example:
 //
@@ -184,14 +221,150 @@ func (bf *BaseFile) WritePageData(pageNo primitives.PageNumber, pageData []byte)
 	if _, err := bf.file.WriteAt(pageData, offset); err != nil {
 		return fmt.Errorf("failed to write page data: %w", err)
 	}
+	bf.unsynced = bf.syncPolicy != vfs.SyncOSync
+
+	return nil
+}
+
+// Sync makes every page written so far durable. It does nothing under the
+// vfs.SyncOSync policy, where each write is durable on its own, or when
+// nothing was written since the last Sync.
+//
+// Returns:
+//   - error: An error if the file is closed or the sync fails
+//
+// Thread-safety: Uses write lock, so pages written before Sync is called are
+// covered by it.
+func (bf *BaseFile) Sync() error {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
 
-	if err := bf.file.Sync(); err != nil {
+	if bf.file == nil {
+		return fmt.Errorf("file is closed")
+	}
+	if !bf.unsynced {
+		return nil
+	}
+	if err := bf.syncPolicy.Sync(bf.file); err != nil {
 		return fmt.Errorf("failed to sync file: %w", err)
 	}
+	bf.unsynced = false
+	return nil
+}
 
+// SetSyncPolicy changes how writes to the file are made durable. Pending
+// writes are synced first, and the file is reopened if the policy needs
+// different open flags.
+//
+// Parameters:
+//   - policy: The sync policy for later writes
+//
+// Returns:
+//   - error: An error if the file is closed, or syncing or reopening it fails
+func (bf *BaseFile) SetSyncPolicy(policy vfs.SyncPolicy) error {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	if bf.file == nil {
+		return fmt.Errorf("file is closed")
+	}
+	if policy == bf.syncPolicy {
+		return nil
+	}
+	if bf.unsynced {
+		if err := bf.syncPolicy.Sync(bf.file); err != nil {
+			return fmt.Errorf("failed to sync file: %w", err)
+		}
+		bf.unsynced = false
+	}
+
+	if policy.OpenFlag() != bf.syncPolicy.OpenFlag() {
+		file, err := openFile(bf.fsys, bf.filePath, policy, bf.directIO)
+		if err != nil {
+			return err
+		}
+		bf.file.Close()
+		bf.file = file
+	}
+	bf.syncPolicy = policy
+	return nil
+}
+
+// SetDirectIO reopens the file with or without direct I/O (see vfs.ODirect),
+// which reads and writes pages without going through the OS page cache, so
+// they are not cached both there and in the buffer pool. Pending writes are
+// synced first. Where the file system does not support direct I/O the file
+// keeps using the page cache.
+//
+// Parameters:
+//   - enabled: Whether later reads and writes bypass the page cache
+//
+// Returns:
+//   - error: An error if the file is closed, or syncing or reopening it fails
+func (bf *BaseFile) SetDirectIO(enabled bool) error {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	if bf.file == nil {
+		return fmt.Errorf("file is closed")
+	}
+	if enabled == bf.directIO {
+		return nil
+	}
+	if bf.unsynced {
+		if err := bf.syncPolicy.Sync(bf.file); err != nil {
+			return fmt.Errorf("failed to sync file: %w", err)
+		}
+		bf.unsynced = false
+	}
+
+	file, err := openFile(bf.fsys, bf.filePath, bf.syncPolicy, enabled)
+	if err != nil {
+		return err
+	}
+	bf.file.Close()
+	bf.file = file
+	bf.directIO = enabled
 	return nil
 }
 
+// rekeyer is a file that can re-encrypt its contents with the current key,
+// such as an encryption.File.
+type rekeyer interface {
+	Rekey() (int, error)
+}
+
+// Rekey re-encrypts with the current key the pages of an encrypted file that
+// were written with an older key, and returns how many it rewrote. A file
+// that is not encrypted has nothing to rewrite.
+//
+// Returns:
+//   - int: The number of pages rewritten
+//   - error: An error if the file is closed or a page cannot be rewritten
+//
+// Thread-safety: Uses write lock, so no page is written while it is rekeyed.
+func (bf *BaseFile) Rekey() (int, error) {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	if bf.file == nil {
+		return 0, fmt.Errorf("file is closed")
+	}
+	r, ok := bf.file.(rekeyer)
+	if !ok {
+		return 0, nil
+	}
+
+	n, err := r.Rekey()
+	if n > 0 {
+		bf.unsynced = bf.syncPolicy != vfs.SyncOSync
+	}
+	if err != nil {
+		return n, fmt.Errorf("failed to rekey file: %w", err)
+	}
+	return n, nil
+}
+
 // Close closes the underlying file handle.
 //
 // This method should be called when the BaseFile is no longer needed
@@ -273,11 +446,7 @@ func (bf *BaseFile) AllocateNewPage() (primitives.PageNumber, error) {
 	if _, err := bf.file.WriteAt(zeroPage, offset); err != nil {
 		return 0, fmt.Errorf("failed to reserve page space: %w", err)
 	}
-
-	// Sync to ensure the size change is visible to other processes
-	if err := bf.file.Sync(); err != nil {
-		return 0, fmt.Errorf("failed to sync file after page allocation: %w", err)
-	}
+	bf.unsynced = bf.syncPolicy != vfs.SyncOSync
 
 	return primitives.PageNumber(allocatedPageNo), nil
 }
@@ -290,8 +459,12 @@ func (bf *BaseFile) FilePath() primitives.Filepath {
 	return bf.filePath
 }
 
-func openFile(filename primitives.Filepath) (*os.File, error) {
-	file, err := os.OpenFile(string(filename), os.O_RDWR|os.O_CREATE, 0644)
+func openFile(fsys vfs.FS, filename primitives.Filepath, policy vfs.SyncPolicy, direct bool) (vfs.File, error) {
+	flag := os.O_RDWR | os.O_CREATE | policy.OpenFlag()
+	if direct {
+		flag |= vfs.ODirect
+	}
+	file, err := fsys.OpenFile(string(filename), flag, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %v", filename, err)
 	}
@@ -32,4 +32,9 @@ type Page interface {
 	// SetBeforeImage copies current content to the before image
 	// Called when a transaction that wrote this page commits
 	SetBeforeImage()
+
+	// Copy returns an independent copy of this page with the same content,
+	// dirty state and before image, or nil if the page cannot be copied
+	// Used to roll back a single statement of a transaction
+	Copy() Page
 }
@@ -0,0 +1,69 @@
+package sysview
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Session describes a statement currently executing against the database.
+// The engine has no long-lived connections, so each executing statement is
+// reported as its own session.
+type Session struct {
+	ID        int64
+	TxID      int64
+	Query     string
+	StartedAt time.Time
+}
+
+// SessionTracker records the sessions that are currently executing.
+type SessionTracker struct {
+	sessions map[int64]Session
+	nextID   int64
+	mutex    sync.Mutex
+}
+
+// NewSessionTracker creates an empty session tracker.
+func NewSessionTracker() *SessionTracker {
+	return &SessionTracker{
+		sessions: make(map[int64]Session),
+	}
+}
+
+// Start registers a new session running query in transaction txID and
+// returns its session ID, which must be passed to End when it finishes.
+func (st *SessionTracker) Start(query string, txID int64) int64 {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.nextID++
+	st.sessions[st.nextID] = Session{
+		ID:        st.nextID,
+		TxID:      txID,
+		Query:     query,
+		StartedAt: time.Now(),
+	}
+	return st.nextID
+}
+
+// End removes a finished session.
+func (st *SessionTracker) End(id int64) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	delete(st.sessions, id)
+}
+
+// Snapshot returns the current sessions ordered by session ID.
+func (st *SessionTracker) Snapshot() []Session {
+	st.mutex.Lock()
+	sessions := make([]Session, 0, len(st.sessions))
+	for _, s := range st.sessions {
+		sessions = append(sessions, s)
+	}
+	st.mutex.Unlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
@@ -0,0 +1,116 @@
+// Package sysview implements read-only system views: virtual tables whose rows
+// are computed from live engine state every time they are scanned. They let
+// operators inspect transactions, locks, the buffer pool, the WAL and the
+// checkpoint daemon with ordinary SELECT statements:
+//
+//	SELECT * FROM sys_locks WHERE granted = false;
+//
+// View names and column names are upper case, matching how the lexer
+// normalizes identifiers.
+package sysview
+
+import (
+	"fmt"
+	"sort"
+	"storemy/pkg/catalog/schema"
+	"storemy/pkg/catalog/systemtable"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"sync"
+)
+
+// RowsFunc computes the current rows of a view. Each tuple must conform to
+// the view's TupleDesc.
+type RowsFunc func(td *tuple.TupleDescription) ([]*tuple.Tuple, error)
+
+// View is a named virtual table backed by a RowsFunc.
+type View struct {
+	Name        string
+	Description string
+	TupleDesc   *tuple.TupleDescription
+	rows        RowsFunc
+}
+
+// Column describes one column of a view.
+type Column struct {
+	Name string
+	Type types.Type
+}
+
+// NewView creates a view with the given columns. The name is upper-cased.
+func NewView(name, description string, columns []Column, rows RowsFunc) (*View, error) {
+	name = strings.ToUpper(name)
+
+	builder := schema.NewSchemaBuilder(systemtable.InvalidTableID, name)
+	for _, col := range columns {
+		builder.AddColumn(col.Name, col.Type)
+	}
+
+	sch, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema for view %s: %v", name, err)
+	}
+
+	return &View{
+		Name:        name,
+		Description: description,
+		TupleDesc:   sch.TupleDesc,
+		rows:        rows,
+	}, nil
+}
+
+// Rows returns a fresh snapshot of the view's rows.
+func (v *View) Rows() ([]*tuple.Tuple, error) {
+	return v.rows(v.TupleDesc)
+}
+
+// Registry holds the system views known to a database.
+type Registry struct {
+	views map[string]*View
+	mutex sync.RWMutex
+}
+
+// NewRegistry creates an empty view registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		views: make(map[string]*View),
+	}
+}
+
+// Register adds v to the registry. It returns an error if a view with the
+// same name is already registered.
+func (r *Registry) Register(v *View) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.views[v.Name]; exists {
+		return fmt.Errorf("system view %s already registered", v.Name)
+	}
+	r.views[v.Name] = v
+	return nil
+}
+
+// Lookup finds a view by case-insensitive name.
+func (r *Registry) Lookup(name string) (*View, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	v, ok := r.views[strings.ToUpper(name)]
+	return v, ok
+}
+
+// All returns every registered view sorted by name.
+func (r *Registry) All() []*View {
+	r.mutex.RLock()
+	all := make([]*View, 0, len(r.views))
+	for _, v := range r.views {
+		all = append(all, v)
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
@@ -0,0 +1,69 @@
+package sysview
+
+import (
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"testing"
+)
+
+func newCounterView(t *testing.T, name string, rows *int) *View {
+	t.Helper()
+	v, err := NewView(name, "test view", []Column{{"N", types.IntType}}, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		*rows++
+		out := make([]*tuple.Tuple, 0, *rows)
+		for i := 0; i < *rows; i++ {
+			out = append(out, tuple.NewBuilder(td).AddInt(int64(i)).MustBuild())
+		}
+		return out, nil
+	})
+	if err != nil {
+		t.Fatalf("NewView failed: %v", err)
+	}
+	return v
+}
+
+func TestRegistry_LookupIsCaseInsensitive(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	if err := r.Register(newCounterView(t, "sys_test", &calls)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if _, ok := r.Lookup("SYS_TEST"); !ok {
+		t.Error("expected upper-case lookup to succeed")
+	}
+	if _, ok := r.Lookup("Sys_Test"); !ok {
+		t.Error("expected mixed-case lookup to succeed")
+	}
+	if _, ok := r.Lookup("sys_missing"); ok {
+		t.Error("expected lookup of unknown view to fail")
+	}
+}
+
+func TestRegistry_DuplicateRejected(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	if err := r.Register(newCounterView(t, "SYS_TEST", &calls)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register(newCounterView(t, "sys_test", &calls)); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+}
+
+func TestSessionTracker(t *testing.T) {
+	st := NewSessionTracker()
+	a := st.Start("SELECT 1", 10)
+	b := st.Start("SELECT 2", 11)
+
+	sessions := st.Snapshot()
+	if len(sessions) != 2 || sessions[0].ID != a || sessions[1].ID != b {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+
+	st.End(a)
+	sessions = st.Snapshot()
+	if len(sessions) != 1 || sessions[0].TxID != 11 {
+		t.Errorf("expected only session %d to remain, got %+v", b, sessions)
+	}
+}
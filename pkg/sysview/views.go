@@ -0,0 +1,521 @@
+package sysview
+
+import (
+	"sort"
+	"storemy/pkg/catalog"
+	"storemy/pkg/concurrency/transaction"
+	"storemy/pkg/log/wal"
+	"storemy/pkg/memory"
+	"storemy/pkg/optimizer/feedback"
+	"storemy/pkg/resultcache"
+	"storemy/pkg/stmtstats"
+	"storemy/pkg/storage/accounting"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"time"
+)
+
+// Names of the built-in system views.
+const (
+	SessionsView     = "SYS_SESSIONS"
+	TransactionsView = "SYS_TRANSACTIONS"
+	LocksView        = "SYS_LOCKS"
+	LockQueuesView   = "SYS_LOCK_QUEUES"
+	BufferPoolView   = "SYS_BUFFER_POOL"
+	WALView          = "SYS_WAL"
+	ReplicationView  = "SYS_REPLICATION"
+	CheckpointerView = "SYS_CHECKPOINTER"
+	StatementsView   = "SYS_STATEMENTS"
+	AutoAnalyzeView  = "SYS_AUTO_ANALYZE"
+	ResultCacheView  = "SYS_RESULT_CACHE"
+	FeedbackView     = "SYS_CARDINALITY_FEEDBACK"
+	TableStorageView = "SYS_TABLE_STORAGE"
+)
+
+// RegisterEngineViews registers the views over the core storage components:
+// SYS_TRANSACTIONS, SYS_LOCKS, SYS_LOCK_QUEUES, SYS_BUFFER_POOL and SYS_WAL.
+func RegisterEngineViews(r *Registry, txRegistry *transaction.TransactionRegistry, store *memory.PageStore, w *wal.WAL) error {
+	builders := []func() (*View, error){
+		func() (*View, error) { return NewTransactionsView(txRegistry) },
+		func() (*View, error) { return NewLocksView(store) },
+		func() (*View, error) { return NewLockQueuesView(store) },
+		func() (*View, error) { return NewBufferPoolView(store, w) },
+		func() (*View, error) { return NewWALView(w) },
+		func() (*View, error) { return NewReplicationView(w) },
+	}
+
+	for _, build := range builders {
+		v, err := build()
+		if err != nil {
+			return err
+		}
+		if err := r.Register(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewSessionsView creates SYS_SESSIONS, one row per statement currently
+// executing, including the SELECT that reads the view.
+func NewSessionsView(tracker *SessionTracker) (*View, error) {
+	columns := []Column{
+		{"SESSION_ID", types.IntType},
+		{"TX_ID", types.IntType},
+		{"QUERY", types.StringType},
+		{"STARTED_AT", types.IntType},
+		{"DURATION_MS", types.IntType},
+	}
+
+	return NewView(SessionsView, "Statements currently executing", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		sessions := tracker.Snapshot()
+		rows := make([]*tuple.Tuple, 0, len(sessions))
+		for _, s := range sessions {
+			rows = append(rows, tuple.NewBuilder(td).
+				AddInt(s.ID).
+				AddInt(s.TxID).
+				AddString(s.Query).
+				AddTimestamp(s.StartedAt).
+				AddInt(time.Since(s.StartedAt).Milliseconds()).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewTransactionsView creates SYS_TRANSACTIONS, one row per active transaction.
+func NewTransactionsView(txRegistry *transaction.TransactionRegistry) (*View, error) {
+	columns := []Column{
+		{"TX_ID", types.IntType},
+		{"STATUS", types.StringType},
+		{"STARTED_AT", types.IntType},
+		{"DURATION_MS", types.IntType},
+		{"FIRST_LSN", types.Uint64Type},
+		{"LAST_LSN", types.Uint64Type},
+		{"LOCKED_PAGES", types.IntType},
+		{"DIRTY_PAGES", types.IntType},
+		{"WAITING_FOR", types.IntType},
+		{"TUPLES_READ", types.IntType},
+		{"TUPLES_WRITTEN", types.IntType},
+		{"TUPLES_DELETED", types.IntType},
+	}
+
+	return NewView(TransactionsView, "Active transactions", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		active := txRegistry.GetActive()
+		sort.Slice(active, func(i, j int) bool {
+			return active[i].ID.ID() < active[j].ID.ID()
+		})
+
+		rows := make([]*tuple.Tuple, 0, len(active))
+		for _, tx := range active {
+			stats := tx.GetStatistics()
+			rows = append(rows, tuple.NewBuilder(td).
+				AddInt(tx.ID.ID()).
+				AddString(tx.GetStatus().String()).
+				AddTimestamp(tx.StartTime()).
+				AddInt(tx.Duration().Milliseconds()).
+				AddUint64(uint64(tx.GetFirstLSN())).
+				AddUint64(uint64(tx.GetLastLSN())).
+				AddInt(int64(stats.LockedPages)).
+				AddInt(int64(stats.DirtyPages)).
+				AddInt(int64(len(tx.GetWaitingFor()))).
+				AddInt(int64(stats.TuplesRead)).
+				AddInt(int64(stats.TuplesWritten)).
+				AddInt(int64(stats.TuplesDeleted)).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewLocksView creates SYS_LOCKS, one row per page lock held or waited for.
+// Rows with GRANTED = false are lock waits; WAIT_MS reports how long the
+// transaction has been waiting and is zero for granted locks.
+func NewLocksView(store *memory.PageStore) (*View, error) {
+	columns := []Column{
+		{"TX_ID", types.IntType},
+		{"TABLE_ID", types.Uint64Type},
+		{"PAGE_NO", types.Uint64Type},
+		{"MODE", types.StringType},
+		{"GRANTED", types.BoolType},
+		{"SINCE", types.IntType},
+		{"WAIT_MS", types.IntType},
+	}
+
+	return NewView(LocksView, "Page locks held and waited for", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		locks := store.Locks()
+		rows := make([]*tuple.Tuple, 0, len(locks))
+		for _, l := range locks {
+			var waitMs int64
+			if !l.Granted {
+				waitMs = time.Since(l.Since).Milliseconds()
+			}
+			rows = append(rows, tuple.NewBuilder(td).
+				AddInt(l.TID.ID()).
+				AddUint64(uint64(l.PageID.FileID())).
+				AddUint64(uint64(l.PageID.PageNo())).
+				AddString(l.LockType.String()).
+				AddBool(l.Granted).
+				AddTimestamp(l.Since).
+				AddInt(waitMs).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewLockQueuesView creates SYS_LOCK_QUEUES, one row per page with lock
+// requests waiting, longest queue first. POLICY is the lock grant policy
+// deciding the order the requests are granted in.
+func NewLockQueuesView(store *memory.PageStore) (*View, error) {
+	columns := []Column{
+		{"TABLE_ID", types.Uint64Type},
+		{"PAGE_NO", types.Uint64Type},
+		{"HOLDERS", types.IntType},
+		{"WAITING", types.IntType},
+		{"WAITING_SHARED", types.IntType},
+		{"WAITING_EXCLUSIVE", types.IntType},
+		{"OLDEST_WAIT_MS", types.IntType},
+		{"POLICY", types.StringType},
+	}
+
+	return NewView(LockQueuesView, "Page lock wait queues", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		queues := store.LockWaitQueues()
+		policy := store.LockGrantPolicy().String()
+		rows := make([]*tuple.Tuple, 0, len(queues))
+		for _, q := range queues {
+			rows = append(rows, tuple.NewBuilder(td).
+				AddUint64(uint64(q.PageID.FileID())).
+				AddUint64(uint64(q.PageID.PageNo())).
+				AddInt(int64(q.Holders)).
+				AddInt(int64(q.Waiting())).
+				AddInt(int64(q.WaitingShared)).
+				AddInt(int64(q.WaitingExclusive)).
+				AddInt(time.Since(q.OldestRequest).Milliseconds()).
+				AddString(policy).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewBufferPoolView creates SYS_BUFFER_POOL, a single row describing buffer
+// pool occupancy and access counters. DIRTY_PAGES comes from the WAL's dirty
+// page table.
+func NewBufferPoolView(store *memory.PageStore, w *wal.WAL) (*View, error) {
+	columns := []Column{
+		{"CACHED_PAGES", types.IntType},
+		{"CAPACITY", types.IntType},
+		{"DIRTY_PAGES", types.IntType},
+		{"HITS", types.IntType},
+		{"MISSES", types.IntType},
+		{"EVICTIONS", types.IntType},
+		{"PAGES_WRITTEN", types.IntType},
+	}
+
+	return NewView(BufferPoolView, "Buffer pool occupancy and hit counters", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		stats := store.Stats()
+		return []*tuple.Tuple{tuple.NewBuilder(td).
+			AddInt(int64(stats.CachedPages)).
+			AddInt(int64(stats.Capacity)).
+			AddInt(int64(w.Stats().DirtyPages)).
+			AddInt(stats.Hits).
+			AddInt(stats.Misses).
+			AddInt(stats.Evictions).
+			AddInt(stats.PagesWritten).
+			MustBuild()}, nil
+	})
+}
+
+// NewWALView creates SYS_WAL, a single row with the current log position.
+func NewWALView(w *wal.WAL) (*View, error) {
+	columns := []Column{
+		{"CURRENT_LSN", types.Uint64Type},
+		{"FLUSHED_LSN", types.Uint64Type},
+		{"FILE_SIZE", types.IntType},
+		{"LAST_CHECKPOINT_LSN", types.Uint64Type},
+		{"ACTIVE_TRANSACTIONS", types.IntType},
+		{"DIRTY_PAGES", types.IntType},
+		{"READ_ONLY", types.BoolType},
+	}
+
+	return NewView(WALView, "Write-ahead log position", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		stats := w.Stats()
+		return []*tuple.Tuple{tuple.NewBuilder(td).
+			AddUint64(uint64(stats.CurrentLSN)).
+			AddUint64(uint64(stats.FlushedLSN)).
+			AddInt(stats.FileSize).
+			AddUint64(uint64(stats.LastCheckpointLSN)).
+			AddInt(int64(stats.ActiveTransactions)).
+			AddInt(int64(stats.DirtyPages)).
+			AddBool(stats.ReadOnly).
+			MustBuild()}, nil
+	})
+}
+
+// NewReplicationView creates SYS_REPLICATION, one row per standby counting
+// toward the synchronous replication quorum. LAG_BYTES is measured at the
+// configured acknowledgment level, LAST_ACK is 0 for a standby that never
+// acknowledged, and DEMOTED is true while commits stop waiting because the
+// quorum timed out.
+func NewReplicationView(w *wal.WAL) (*View, error) {
+	columns := []Column{
+		{"STANDBY", types.StringType},
+		{"RECEIVED_LSN", types.Uint64Type},
+		{"APPLIED_LSN", types.Uint64Type},
+		{"LAG_BYTES", types.IntType},
+		{"LAST_ACK", types.IntType},
+		{"QUORUM", types.IntType},
+		{"LEVEL", types.StringType},
+		{"DEMOTED", types.BoolType},
+	}
+
+	return NewView(ReplicationView, "Synchronous replication standbys", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		stats := w.ReplicationStats()
+		rows := make([]*tuple.Tuple, 0, len(stats.Standbys))
+		for _, s := range stats.Standbys {
+			var lastAck int64
+			if !s.LastAck.IsZero() {
+				lastAck = s.LastAck.Unix()
+			}
+			rows = append(rows, tuple.NewBuilder(td).
+				AddString(s.Name).
+				AddUint64(uint64(s.ReceivedLSN)).
+				AddUint64(uint64(s.AppliedLSN)).
+				AddInt(s.LagBytes).
+				AddInt(lastAck).
+				AddInt(int64(stats.Config.Quorum)).
+				AddString(stats.Config.Level.String()).
+				AddBool(stats.Demoted).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewCheckpointerView creates SYS_CHECKPOINTER, a single row with the
+// checkpoint daemon's configuration and statistics.
+func NewCheckpointerView(daemon *wal.CheckpointDaemon) (*View, error) {
+	columns := []Column{
+		{"RUNNING", types.BoolType},
+		{"INTERVAL", types.StringType},
+		{"MAX_WAL_SIZE", types.IntType},
+		{"TOTAL_CHECKPOINTS", types.IntType},
+		{"TIME_TRIGGERED", types.IntType},
+		{"SIZE_TRIGGERED", types.IntType},
+		{"MANUAL_TRIGGERED", types.IntType},
+		{"FAILED", types.IntType},
+		{"LAST_CHECKPOINT_AT", types.IntType},
+		{"LAST_CHECKPOINT_LSN", types.Uint64Type},
+		{"LAST_DURATION_MS", types.IntType},
+	}
+
+	return NewView(CheckpointerView, "Checkpoint daemon statistics", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		cfg := daemon.GetConfig()
+		stats := daemon.GetStats()
+
+		var lastAt int64
+		if !stats.LastCheckpointTime.IsZero() {
+			lastAt = stats.LastCheckpointTime.Unix()
+		}
+
+		return []*tuple.Tuple{tuple.NewBuilder(td).
+			AddBool(daemon.IsRunning()).
+			AddString(cfg.Interval.String()).
+			AddInt(cfg.MaxWALSize).
+			AddInt(stats.TotalCheckpoints).
+			AddInt(stats.TimeBasedTriggers).
+			AddInt(stats.SizeBasedTriggers).
+			AddInt(stats.ManualTriggers).
+			AddInt(stats.FailedCheckpoints).
+			AddInt(lastAt).
+			AddUint64(uint64(stats.LastCheckpointLSN)).
+			AddInt(stats.LastCheckpointDuration.Milliseconds()).
+			MustBuild()}, nil
+	})
+}
+
+// NewStatementsView creates SYS_STATEMENTS, one row per statement fingerprint
+// with its aggregated execution statistics, slowest in total first.
+func NewStatementsView(collector *stmtstats.Collector) (*View, error) {
+	columns := []Column{
+		{"QUERY_ID", types.Uint64Type},
+		{"QUERY", types.StringType},
+		{"CALLS", types.IntType},
+		{"TOTAL_MS", types.FloatType},
+		{"MEAN_MS", types.FloatType},
+		{"MAX_MS", types.FloatType},
+		{"ROWS", types.IntType},
+		{"LAST_SEEN", types.IntType},
+	}
+
+	return NewView(StatementsView, "Execution statistics per statement fingerprint", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		entries := collector.Snapshot()
+		rows := make([]*tuple.Tuple, 0, len(entries))
+		for _, e := range entries {
+			rows = append(rows, tuple.NewBuilder(td).
+				AddUint64(e.QueryID).
+				AddString(e.Query).
+				AddInt(e.Calls).
+				AddFloat(durationMs(e.TotalTime)).
+				AddFloat(durationMs(e.MeanTime())).
+				AddFloat(durationMs(e.MaxTime)).
+				AddInt(e.Rows).
+				AddTimestamp(e.LastSeen).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewResultCacheView creates SYS_RESULT_CACHE, a single row with the size
+// limits, contents and hit statistics of the query result cache.
+func NewResultCacheView(cache *resultcache.Cache) (*View, error) {
+	columns := []Column{
+		{"ENABLED", types.BoolType},
+		{"MAX_ENTRIES", types.IntType},
+		{"MAX_BYTES", types.IntType},
+		{"ENTRIES", types.IntType},
+		{"BYTES", types.IntType},
+		{"HITS", types.IntType},
+		{"MISSES", types.IntType},
+		{"HIT_RATIO", types.FloatType},
+		{"STORES", types.IntType},
+		{"EVICTIONS", types.IntType},
+		{"INVALIDATIONS", types.IntType},
+	}
+
+	return NewView(ResultCacheView, "Query result cache statistics", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		config := cache.Config()
+		stats := cache.Stats()
+		return []*tuple.Tuple{tuple.NewBuilder(td).
+			AddBool(config.Enabled()).
+			AddInt(int64(config.MaxEntries)).
+			AddInt(config.MaxBytes).
+			AddInt(int64(stats.Entries)).
+			AddInt(stats.Bytes).
+			AddInt(stats.Hits).
+			AddInt(stats.Misses).
+			AddFloat(stats.HitRatio()).
+			AddInt(stats.Stores).
+			AddInt(stats.Evictions).
+			AddInt(stats.Invalidations).
+			MustBuild()}, nil
+	})
+}
+
+// NewFeedbackView creates SYS_CARDINALITY_FEEDBACK, one row per table and
+// predicate combination whose actual row count EXPLAIN ANALYZE has recorded,
+// with the correction the optimizer applies to its estimate.
+func NewFeedbackView(store *feedback.Store) (*View, error) {
+	columns := []Column{
+		{"TABLE_NAME", types.StringType},
+		{"PREDICATES", types.StringType},
+		{"ESTIMATED_ROWS", types.IntType},
+		{"ACTUAL_ROWS", types.IntType},
+		{"CORRECTION", types.FloatType},
+		{"SAMPLES", types.IntType},
+		{"LAST_SEEN", types.IntType},
+	}
+
+	return NewView(FeedbackView, "Cardinality feedback recorded by EXPLAIN ANALYZE", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		entries := store.Snapshot()
+		rows := make([]*tuple.Tuple, 0, len(entries))
+		for _, e := range entries {
+			rows = append(rows, tuple.NewBuilder(td).
+				AddString(e.Table).
+				AddString(e.Predicates).
+				AddInt(e.Estimated).
+				AddInt(e.Actual).
+				AddFloat(e.Correction).
+				AddInt(e.Samples).
+				AddTimestamp(e.LastSeen).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewTableStorageView creates SYS_TABLE_STORAGE, one row per table with the
+// storage it uses, largest first. report collects the rows each time the
+// view is read (see package accounting); DEAD_TUPLES is an estimate.
+func NewTableStorageView(report func() ([]accounting.TableUsage, error)) (*View, error) {
+	columns := []Column{
+		{"TABLE_NAME", types.StringType},
+		{"TOTAL_BYTES", types.IntType},
+		{"HEAP_BYTES", types.IntType},
+		{"HEAP_PAGES", types.IntType},
+		{"LIVE_TUPLES", types.IntType},
+		{"DEAD_TUPLES", types.IntType},
+		{"LIVE_BYTES", types.IntType},
+		{"DEAD_BYTES", types.IntType},
+		{"FREE_BYTES", types.IntType},
+		{"INDEXES", types.IntType},
+		{"INDEX_BYTES", types.IntType},
+	}
+
+	return NewView(TableStorageView, "Storage used by each table", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		usages, err := report()
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]*tuple.Tuple, 0, len(usages))
+		for _, u := range usages {
+			rows = append(rows, tuple.NewBuilder(td).
+				AddString(u.Table).
+				AddInt(u.TotalBytes()).
+				AddInt(u.HeapBytes).
+				AddInt(u.HeapPages).
+				AddInt(u.LiveTuples).
+				AddInt(u.DeadTuples).
+				AddInt(u.LiveBytes).
+				AddInt(u.DeadBytes).
+				AddInt(u.FreeBytes).
+				AddInt(int64(u.Indexes)).
+				AddInt(u.IndexBytes).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+// NewAutoAnalyzeView creates SYS_AUTO_ANALYZE, one row per table modified or
+// analyzed since startup with its modification counter and the threshold at
+// which the background updater re-analyzes it.
+func NewAutoAnalyzeView(sm *catalog.StatisticsManager) (*View, error) {
+	columns := []Column{
+		{"TABLE_ID", types.Uint64Type},
+		{"TABLE_NAME", types.StringType},
+		{"MODIFICATIONS", types.IntType},
+		{"THRESHOLD", types.IntType},
+		{"LAST_ANALYZED_ROWS", types.Uint64Type},
+		{"LAST_ANALYZE", types.IntType},
+		{"AUTO_ANALYZE_COUNT", types.IntType},
+	}
+
+	return NewView(AutoAnalyzeView, "Modification counters driving automatic statistics updates", columns, func(td *tuple.TupleDescription) ([]*tuple.Tuple, error) {
+		activity := sm.Activity()
+		rows := make([]*tuple.Tuple, 0, len(activity))
+		for _, a := range activity {
+			var lastAnalyze int64
+			if !a.LastAnalyze.IsZero() {
+				lastAnalyze = a.LastAnalyze.Unix()
+			}
+
+			rows = append(rows, tuple.NewBuilder(td).
+				AddUint64(uint64(a.TableID)).
+				AddString(a.TableName).
+				AddInt(int64(a.Modifications)).
+				AddInt(int64(a.Threshold)).
+				AddUint64(a.LastAnalyzedRows).
+				AddInt(lastAnalyze).
+				AddInt(a.AutoAnalyzeCount).
+				MustBuild())
+		}
+		return rows, nil
+	})
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
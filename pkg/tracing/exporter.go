@@ -0,0 +1,157 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// SpanData is an immutable snapshot of a finished span. Its fields mirror the
+// OpenTelemetry span model (trace ID, span ID, parent span ID, name, start and
+// end time, attributes), so an Exporter can forward it to an OpenTelemetry
+// SDK or collector with a field-by-field conversion.
+type SpanData struct {
+	TraceID      TraceID
+	SpanID       SpanID
+	ParentSpanID SpanID // Zero for the root span
+	Name         string
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   []Attribute
+}
+
+// Exporter receives the spans of every completed trace. The method set follows
+// the OpenTelemetry SpanExporter interface.
+type Exporter interface {
+	// ExportSpans exports a batch of spans. It is called once per trace, with
+	// parents listed before their children.
+	ExportSpans(ctx context.Context, spans []SpanData) error
+
+	// Shutdown flushes pending spans and releases resources. ExportSpans is
+	// not called after Shutdown.
+	Shutdown(ctx context.Context) error
+}
+
+// Spans returns a snapshot of every span in the trace, parents first.
+// Spans that have not ended are reported as ending now.
+func (t *Trace) Spans() []SpanData {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	var spans []SpanData
+	var walk func(s *Span)
+	walk = func(s *Span) {
+		data := SpanData{
+			TraceID:    t.id,
+			SpanID:     s.id,
+			Name:       s.name,
+			StartTime:  s.start,
+			EndTime:    s.end,
+			Attributes: append([]Attribute(nil), s.attrs...),
+		}
+		if s.parent != nil {
+			data.ParentSpanID = s.parent.id
+		}
+		if data.EndTime.IsZero() {
+			data.EndTime = now
+		}
+		if data.StartTime.IsZero() {
+			data.StartTime = data.EndTime
+		}
+		spans = append(spans, data)
+
+		for _, c := range s.children {
+			walk(c)
+		}
+	}
+	walk(t.root)
+	return spans
+}
+
+// JSONExporter writes each span as a single-line JSON object.
+type JSONExporter struct {
+	w      io.Writer
+	mutex  sync.Mutex
+	closed bool
+}
+
+// NewJSONExporter creates an exporter that writes spans to w.
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{w: w}
+}
+
+type jsonSpan struct {
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Name         string         `json:"name"`
+	StartTime    time.Time      `json:"start_time"`
+	EndTime      time.Time      `json:"end_time"`
+	DurationUs   int64          `json:"duration_us"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+}
+
+// ExportSpans writes spans as JSON lines.
+func (e *JSONExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return nil
+	}
+
+	enc := json.NewEncoder(e.w)
+	for _, s := range spans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		js := jsonSpan{
+			TraceID:    s.TraceID.String(),
+			SpanID:     s.SpanID.String(),
+			Name:       s.Name,
+			StartTime:  s.StartTime,
+			EndTime:    s.EndTime,
+			DurationUs: s.EndTime.Sub(s.StartTime).Microseconds(),
+			Attributes: attributeMap(s.Attributes),
+		}
+		if s.ParentSpanID.IsValid() {
+			js.ParentSpanID = s.ParentSpanID.String()
+		}
+		if err := enc.Encode(js); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown stops the exporter; later exports are dropped.
+func (e *JSONExporter) Shutdown(ctx context.Context) error {
+	e.mutex.Lock()
+	e.closed = true
+	e.mutex.Unlock()
+	return nil
+}
+
+func attributeMap(attrs []Attribute) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		if d, ok := a.Value.(time.Duration); ok {
+			m[a.Key] = d.String()
+			continue
+		}
+		m[a.Key] = a.Value
+	}
+	return m
+}
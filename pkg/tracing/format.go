@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format renders the span tree as indented text, one span per line:
+//
+//	query 1.204ms
+//	  -> parse 0.031ms
+//	  -> plan 0.012ms
+//	  -> execute 1.102ms
+//	    -> Limit 0.903ms rows=10 active_time=0.871ms
+func (t *Trace) Format() string {
+	if t == nil {
+		return ""
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var sb strings.Builder
+	formatSpan(&sb, t.root, 0)
+	return sb.String()
+}
+
+func formatSpan(sb *strings.Builder, s *Span, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	if depth > 0 {
+		sb.WriteString("-> ")
+	}
+	sb.WriteString(s.name)
+	sb.WriteString(" ")
+	sb.WriteString(formatDuration(s.duration()))
+	if s.end.IsZero() && !s.start.IsZero() {
+		sb.WriteString(" (running)")
+	}
+	for _, a := range s.attrs {
+		sb.WriteString(fmt.Sprintf(" %s=%s", a.Key, formatValue(a.Value)))
+	}
+	sb.WriteString("\n")
+
+	for _, c := range s.children {
+		formatSpan(sb, c, depth+1)
+	}
+}
+
+// SpanTree is a JSON-friendly view of a span and its descendants.
+type SpanTree struct {
+	Name       string         `json:"name"`
+	DurationMs float64        `json:"duration_ms"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Children   []SpanTree     `json:"children,omitempty"`
+}
+
+// Tree returns the span tree rooted at the trace's root span.
+func (t *Trace) Tree() SpanTree {
+	if t == nil {
+		return SpanTree{}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return buildTree(t.root)
+}
+
+func buildTree(s *Span) SpanTree {
+	node := SpanTree{
+		Name:       s.name,
+		DurationMs: durationMs(s.duration()),
+		Attributes: attributeMap(s.attrs),
+	}
+	for _, c := range s.children {
+		node.Children = append(node.Children, buildTree(c))
+	}
+	return node
+}
+
+func formatValue(v any) string {
+	if d, ok := v.(time.Duration); ok {
+		return formatDuration(d)
+	}
+	return fmt.Sprint(v)
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", durationMs(d))
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"storemy/pkg/iterator"
+	"storemy/pkg/tuple"
+	"time"
+)
+
+// Attribute keys recorded on operator spans.
+const (
+	RowsAttr       = "rows"        // Tuples returned by the operator
+	ActiveTimeAttr = "active_time" // Time spent inside the operator, including its inputs
+)
+
+// TracedIterator wraps an execution operator and records its work as a span.
+// The span starts when the operator is first opened and ends when it is
+// closed; because operators are pulled one tuple at a time, the time actually
+// spent inside the operator (and its inputs) is reported separately as the
+// active_time attribute, along with the number of rows it produced.
+type TracedIterator struct {
+	iterator.DbIterator
+	trace  *Trace
+	span   *Span
+	rows   int64
+	active time.Duration
+	steps  stepper
+}
+
+// TraceOperator wraps op so that its execution is recorded as a span named
+// name, if t records per-operator spans. Spans of traced inputs become
+// children of the new span, so the spans mirror the operator tree. When t is
+// nil or not detailed, op is returned unchanged.
+func TraceOperator(t *Trace, name string, op iterator.DbIterator, inputs ...iterator.DbIterator) iterator.DbIterator {
+	if !t.Detailed() {
+		return op
+	}
+
+	observer := t.StepObserver()
+	span := t.newDetachedSpan(name)
+
+	t.mutex.Lock()
+	span.operator = true
+	for _, in := range inputs {
+		if traced, ok := in.(*TracedIterator); ok && traced.span.parent == nil {
+			span.addChild(traced.span)
+		}
+	}
+	leaf := len(span.children) == 0
+	t.mutex.Unlock()
+
+	return &TracedIterator{DbIterator: op, trace: t, span: span, steps: stepper{observer: observer, pages: leaf, page: -1}}
+}
+
+// AttachOperator places the span tree of a traced operator under the
+// trace's current span. It is called once with the root of the operator tree.
+func AttachOperator(t *Trace, op iterator.DbIterator) {
+	traced, ok := op.(*TracedIterator)
+	if !ok || traced.trace != t {
+		return
+	}
+	t.attach(traced.span)
+}
+
+// Span returns the span recording this operator.
+func (ti *TracedIterator) Span() *Span {
+	return ti.span
+}
+
+func (ti *TracedIterator) Open() error {
+	ti.trace.mutex.Lock()
+	if ti.span.start.IsZero() {
+		ti.span.start = time.Now()
+	}
+	ti.trace.mutex.Unlock()
+
+	defer ti.measure()()
+	if err := ti.DbIterator.Open(); err != nil {
+		return err
+	}
+	ti.stepOpen(StepOpen)
+	return nil
+}
+
+func (ti *TracedIterator) HasNext() (bool, error) {
+	defer ti.measure()()
+	hasNext, err := ti.DbIterator.HasNext()
+	if err == nil && !hasNext {
+		ti.stepDone()
+	}
+	return hasNext, err
+}
+
+func (ti *TracedIterator) Next() (*tuple.Tuple, error) {
+	defer ti.measure()()
+	t, err := ti.DbIterator.Next()
+	if err == nil && t != nil {
+		ti.rows++
+		ti.stepRow(t)
+	}
+	return t, err
+}
+
+func (ti *TracedIterator) Rewind() error {
+	defer ti.measure()()
+	if err := ti.DbIterator.Rewind(); err != nil {
+		return err
+	}
+	ti.stepOpen(StepRewind)
+	return nil
+}
+
+// Close closes the operator and ends its span.
+func (ti *TracedIterator) Close() error {
+	err := ti.DbIterator.Close()
+	ti.stepClose()
+
+	ti.span.SetAttributes(Attr(RowsAttr, ti.rows), Attr(ActiveTimeAttr, ti.active))
+	ti.span.End()
+	return err
+}
+
+// measure makes the operator's span current for the duration of a call, so
+// that lock and WAL waits nest under it, and adds the call's duration to the
+// operator's active time. Use as: defer ti.measure()().
+func (ti *TracedIterator) measure() func() {
+	start := time.Now()
+	prev := ti.trace.enter(ti.span)
+	return func() {
+		ti.trace.exit(prev)
+		ti.active += time.Since(start)
+	}
+}
@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"fmt"
+	"storemy/pkg/tuple"
+)
+
+// StepKind is the kind of a StepEvent.
+type StepKind int
+
+const (
+	StepOpen   StepKind = iota // The operator was opened
+	StepRow                    // The operator returned a row
+	StepPage                   // A scan moved past a page it returned rows from
+	StepDone                   // The operator reported that it has no more rows
+	StepRewind                 // The operator was rewound to its first row
+	StepClose                  // The operator was closed
+)
+
+func (k StepKind) String() string {
+	switch k {
+	case StepOpen:
+		return "open"
+	case StepRow:
+		return "row"
+	case StepPage:
+		return "page"
+	case StepDone:
+		return "done"
+	case StepRewind:
+		return "rewind"
+	case StepClose:
+		return "close"
+	default:
+		return fmt.Sprintf("StepKind(%d)", int(k))
+	}
+}
+
+// StepEvent is one step of a query's execution, observed one operator pull
+// at a time. Events are emitted in execution order, so a consumer sees how
+// each Next call on the root operator pulls rows up through the tree.
+type StepEvent struct {
+	Seq      int64  // Position of the event in the query, starting at 1
+	Operator string // Operator name, e.g. "Scan USERS" or "Sort"
+	Depth    int    // Nesting below the root operator, which is 0
+	Kind     StepKind
+
+	// Rows is the number of rows the operator has returned so far; for
+	// StepPage, the rows it returned from that page.
+	Rows int64
+
+	// Page is the page the row came from, for StepRow and StepPage events of
+	// operators returning stored rows, and -1 otherwise.
+	Page int64
+
+	// Tuple is the row returned, for StepRow events.
+	Tuple *tuple.Tuple
+}
+
+// String annotates the event for display, e.g.
+// "Scan USERS read page 3, returned 12 rows".
+func (e StepEvent) String() string {
+	switch e.Kind {
+	case StepOpen:
+		return fmt.Sprintf("%s opened", e.Operator)
+	case StepRow:
+		if e.Page >= 0 {
+			return fmt.Sprintf("%s returned row %d from page %d", e.Operator, e.Rows, e.Page)
+		}
+		return fmt.Sprintf("%s returned row %d", e.Operator, e.Rows)
+	case StepPage:
+		return fmt.Sprintf("%s read page %d, returned %d %s", e.Operator, e.Page, e.Rows, plural(e.Rows, "row", "rows"))
+	case StepDone:
+		return fmt.Sprintf("%s finished after %d %s", e.Operator, e.Rows, plural(e.Rows, "row", "rows"))
+	case StepRewind:
+		return fmt.Sprintf("%s rewound", e.Operator)
+	case StepClose:
+		return fmt.Sprintf("%s closed", e.Operator)
+	default:
+		return fmt.Sprintf("%s %s", e.Operator, e.Kind)
+	}
+}
+
+func plural(n int64, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// StepObserver receives the step events of a query. It is called
+// synchronously on the goroutine executing the query, so it can pace the
+// execution, e.g. to let a user watch it live, by not returning immediately.
+type StepObserver func(StepEvent)
+
+// SetStepObserver makes the trace report every operator call to observer.
+// Only operators traced after the call are observed, so it must be set
+// before the query is planned, on a detailed trace. Nil stops reporting.
+func (t *Trace) SetStepObserver(observer StepObserver) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	t.observer = observer
+	t.mutex.Unlock()
+}
+
+// StepObserver returns the observer set with SetStepObserver, or nil.
+func (t *Trace) StepObserver() StepObserver {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.observer
+}
+
+// stepper emits the step events of one traced operator.
+type stepper struct {
+	observer StepObserver
+	pages    bool  // Report pages; only for leaves, as other operators pass their input rows on
+	done     bool  // StepDone was emitted since the last open or rewind
+	page     int64 // Page of the last stored row returned, or -1
+	pageRows int64 // Rows returned from page
+}
+
+// emit reports an event of the operator traced by ti.
+func (ti *TracedIterator) emit(kind StepKind, rows, page int64, t *tuple.Tuple) {
+	ti.trace.mutex.Lock()
+	ti.trace.steps++
+	seq := ti.trace.steps
+	depth := 0
+	for p := ti.span.parent; p != nil && p.operator; p = p.parent {
+		depth++
+	}
+	ti.trace.mutex.Unlock()
+
+	ti.steps.observer(StepEvent{
+		Seq:      seq,
+		Operator: ti.span.name,
+		Depth:    depth,
+		Kind:     kind,
+		Rows:     rows,
+		Page:     page,
+		Tuple:    t,
+	})
+}
+
+// stepOpen reports that the operator was opened (StepOpen) or rewound
+// (StepRewind) and starts over with no page being read.
+func (ti *TracedIterator) stepOpen(kind StepKind) {
+	if ti.steps.observer == nil {
+		return
+	}
+	ti.steps.done = false
+	ti.steps.page, ti.steps.pageRows = -1, 0
+	ti.emit(kind, ti.rows, -1, nil)
+}
+
+// stepRow reports a returned row, preceded by a StepPage event if it is the
+// first row from a new page.
+func (ti *TracedIterator) stepRow(t *tuple.Tuple) {
+	if ti.steps.observer == nil {
+		return
+	}
+
+	page := int64(-1)
+	if ti.steps.pages && t.RecordID != nil && t.RecordID.PageID != nil {
+		page = int64(t.RecordID.PageID.PageNo())
+	}
+	if page != ti.steps.page {
+		ti.stepPage()
+		ti.steps.page = page
+	}
+	if page >= 0 {
+		ti.steps.pageRows++
+	}
+	ti.emit(StepRow, ti.rows, page, t)
+}
+
+// stepPage reports the rows returned from the current page, if any.
+func (ti *TracedIterator) stepPage() {
+	if ti.steps.page >= 0 && ti.steps.pageRows > 0 {
+		ti.emit(StepPage, ti.steps.pageRows, ti.steps.page, nil)
+	}
+	ti.steps.page, ti.steps.pageRows = -1, 0
+}
+
+// stepDone reports, once, that the operator is exhausted.
+func (ti *TracedIterator) stepDone() {
+	if ti.steps.observer == nil || ti.steps.done {
+		return
+	}
+	ti.steps.done = true
+	ti.stepPage()
+	ti.emit(StepDone, ti.rows, -1, nil)
+}
+
+// stepClose reports that the operator was closed.
+func (ti *TracedIterator) stepClose() {
+	if ti.steps.observer == nil {
+		return
+	}
+	ti.stepPage()
+	ti.emit(StepClose, ti.rows, -1, nil)
+}
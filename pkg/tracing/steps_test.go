@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"storemy/pkg/primitives"
+	"storemy/pkg/storage/page"
+	"storemy/pkg/tuple"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStepObserver_ReportsOperatorPulls(t *testing.T) {
+	tr := NewTrace("query", time.Now())
+	tr.SetDetailed(true)
+
+	var events []StepEvent
+	tr.SetStepObserver(func(e StepEvent) { events = append(events, e) })
+
+	// Three stored rows: two on page 0 and one on page 1
+	source := newSliceIterator(t, 3)
+	for i, pageNo := range []primitives.PageNumber{0, 0, 1} {
+		pid := page.NewPageDescriptor(1, pageNo)
+		source.tuples[i].RecordID = tuple.NewTupleRecordID(pid, primitives.SlotID(i))
+	}
+
+	scan := TraceOperator(tr, "Scan T", source)
+	limit := TraceOperator(tr, "Limit", scan, scan)
+	AttachOperator(tr, limit)
+	drain(t, limit)
+
+	var lines []string
+	for i, e := range events {
+		if e.Seq != int64(i+1) {
+			t.Errorf("event %d has sequence number %d", i, e.Seq)
+		}
+		lines = append(lines, strings.Repeat("  ", e.Depth)+e.String())
+	}
+
+	want := []string{
+		"  Scan T opened",
+		"Limit opened",
+		"  Scan T returned row 1 from page 0",
+		"Limit returned row 1",
+		"  Scan T returned row 2 from page 0",
+		"Limit returned row 2",
+		"  Scan T read page 0, returned 2 rows",
+		"  Scan T returned row 3 from page 1",
+		"Limit returned row 3",
+		"  Scan T read page 1, returned 1 row",
+		"  Scan T finished after 3 rows",
+		"Limit finished after 3 rows",
+		"  Scan T closed",
+		"Limit closed",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected steps:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestStepObserver_NotSetEmitsNothing(t *testing.T) {
+	tr := NewTrace("query", time.Now())
+	tr.SetDetailed(true)
+
+	scan := TraceOperator(tr, "Scan", newSliceIterator(t, 2))
+	tr.SetStepObserver(func(StepEvent) { t.Error("operator traced before the observer was set reported a step") })
+	drain(t, scan)
+}
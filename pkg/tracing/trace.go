@@ -0,0 +1,282 @@
+// Package tracing records a tree of timed spans for a single query: parsing,
+// planning, each execution operator, and the lock and WAL waits it incurred.
+//
+// A Trace is attached to the transaction running the query. Code on the
+// execution path looks it up and opens spans under whatever span is current:
+//
+//	span := tx.Trace().StartSpan("wal.commit")
+//	defer span.End()
+//
+// Every method is safe to call on a nil *Trace or *Span and does nothing, so
+// untraced queries pay only for a nil check.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace. Its size matches OpenTelemetry trace IDs.
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return fmt.Sprintf("%x", id[:])
+}
+
+// SpanID identifies a span within a trace. Its size matches OpenTelemetry span IDs.
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return fmt.Sprintf("%x", id[:])
+}
+
+// IsValid reports whether the ID is non-zero. The root span has an invalid parent ID.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// Attribute is a key-value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr creates an Attribute.
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Trace is the span tree recorded for one query.
+type Trace struct {
+	id       TraceID
+	root     *Span
+	current  *Span
+	detailed bool
+	nextID   uint64
+	observer StepObserver // Receives operator step events; nil if not stepping
+	steps    int64        // Step events emitted
+	mutex    sync.Mutex
+}
+
+// NewTrace starts a trace whose root span is named name and began at start.
+// The start time may lie in the past so that work done before the decision to
+// trace (such as parsing) can still be recorded with Record.
+func NewTrace(name string, start time.Time) *Trace {
+	t := &Trace{}
+	_, _ = rand.Read(t.id[:])
+
+	t.root = t.newSpan(name, nil)
+	t.root.start = start
+	t.current = t.root
+	return t
+}
+
+// ID returns the trace ID.
+func (t *Trace) ID() TraceID {
+	if t == nil {
+		return TraceID{}
+	}
+	return t.id
+}
+
+// Root returns the root span.
+func (t *Trace) Root() *Span {
+	if t == nil {
+		return nil
+	}
+	return t.root
+}
+
+// Detailed reports whether per-operator spans should be recorded.
+func (t *Trace) Detailed() bool {
+	if t == nil {
+		return false
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.detailed
+}
+
+// SetDetailed enables or disables per-operator spans.
+func (t *Trace) SetDetailed(detailed bool) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	t.detailed = detailed
+	t.mutex.Unlock()
+}
+
+// StartSpan opens a child of the current span and makes it current until End
+// is called.
+func (t *Trace) StartSpan(name string, attrs ...Attribute) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	span := t.newSpan(name, attrs)
+	span.start = time.Now()
+	t.current.addChild(span)
+	t.current = span
+	return span
+}
+
+// Record adds an already completed span as a child of the current span.
+func (t *Trace) Record(name string, start, end time.Time, attrs ...Attribute) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	span := t.newSpan(name, attrs)
+	span.start = start
+	span.end = end
+	t.current.addChild(span)
+}
+
+// newDetachedSpan creates a span that is not yet part of the tree.
+func (t *Trace) newDetachedSpan(name string) *Span {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.newSpan(name, nil)
+}
+
+// attach adds span as a child of the current span.
+func (t *Trace) attach(span *Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.current.addChild(span)
+}
+
+// enter makes span current and returns the previously current span.
+func (t *Trace) enter(span *Span) *Span {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	prev := t.current
+	t.current = span
+	return prev
+}
+
+// exit restores the span returned by enter.
+func (t *Trace) exit(prev *Span) {
+	t.mutex.Lock()
+	t.current = prev
+	t.mutex.Unlock()
+}
+
+// newSpan allocates a span with a fresh ID. The caller must hold t.mutex
+// unless the trace is still being constructed.
+func (t *Trace) newSpan(name string, attrs []Attribute) *Span {
+	t.nextID++
+	span := &Span{trace: t, name: name, attrs: attrs}
+	binary.BigEndian.PutUint64(span.id[:], t.nextID)
+	return span
+}
+
+// Span is a timed unit of work within a trace.
+type Span struct {
+	trace    *Trace
+	parent   *Span
+	id       SpanID
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    []Attribute
+	children []*Span
+	operator bool // Records an execution operator
+}
+
+// End marks the span finished. If the span is current, its parent becomes current.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	t := s.trace
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if s.end.IsZero() {
+		s.end = time.Now()
+	}
+	if t.current == s && s.parent != nil {
+		t.current = s.parent
+	}
+}
+
+// SetAttributes adds attributes to the span, replacing any with the same key.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.trace.mutex.Lock()
+	defer s.trace.mutex.Unlock()
+
+	for _, a := range attrs {
+		replaced := false
+		for i := range s.attrs {
+			if s.attrs[i].Key == a.Key {
+				s.attrs[i].Value = a.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.attrs = append(s.attrs, a)
+		}
+	}
+}
+
+// Name returns the span name.
+func (s *Span) Name() string {
+	if s == nil {
+		return ""
+	}
+	return s.name
+}
+
+// Duration returns the span's duration, or the time elapsed so far if it has not ended.
+func (s *Span) Duration() time.Duration {
+	if s == nil {
+		return 0
+	}
+
+	s.trace.mutex.Lock()
+	defer s.trace.mutex.Unlock()
+	return s.duration()
+}
+
+func (s *Span) duration() time.Duration {
+	if s.start.IsZero() {
+		return 0
+	}
+	if s.end.IsZero() {
+		return time.Since(s.start)
+	}
+	return s.end.Sub(s.start)
+}
+
+// Children returns the span's direct children.
+func (s *Span) Children() []*Span {
+	if s == nil {
+		return nil
+	}
+
+	s.trace.mutex.Lock()
+	defer s.trace.mutex.Unlock()
+	return append([]*Span(nil), s.children...)
+}
+
+func (s *Span) addChild(child *Span) {
+	child.parent = s
+	s.children = append(s.children, child)
+}
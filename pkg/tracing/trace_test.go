@@ -0,0 +1,228 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sliceIterator returns a fixed set of tuples.
+type sliceIterator struct {
+	td     *tuple.TupleDescription
+	tuples []*tuple.Tuple
+	pos    int
+}
+
+func newSliceIterator(t *testing.T, n int) *sliceIterator {
+	t.Helper()
+	td, err := tuple.NewTupleDesc([]types.Type{types.IntType}, []string{"N"})
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	it := &sliceIterator{td: td}
+	for i := 0; i < n; i++ {
+		it.tuples = append(it.tuples, tuple.NewBuilder(td).AddInt(int64(i)).MustBuild())
+	}
+	return it
+}
+
+func (it *sliceIterator) Open() error                           { return nil }
+func (it *sliceIterator) HasNext() (bool, error)                { return it.pos < len(it.tuples), nil }
+func (it *sliceIterator) Rewind() error                         { it.pos = 0; return nil }
+func (it *sliceIterator) Close() error                          { return nil }
+func (it *sliceIterator) GetTupleDesc() *tuple.TupleDescription { return it.td }
+func (it *sliceIterator) Next() (*tuple.Tuple, error) {
+	t := it.tuples[it.pos]
+	it.pos++
+	return t, nil
+}
+
+func drain(t *testing.T, it interface {
+	Open() error
+	HasNext() (bool, error)
+	Next() (*tuple.Tuple, error)
+	Close() error
+}) {
+	t.Helper()
+	if err := it.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for {
+		hasNext, err := it.HasNext()
+		if err != nil {
+			t.Fatalf("HasNext failed: %v", err)
+		}
+		if !hasNext {
+			break
+		}
+		if _, err := it.Next(); err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+	}
+	if err := it.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func attrValue(s *Span, key string) any {
+	for _, a := range s.attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+func TestNilTraceIsNoop(t *testing.T) {
+	var tr *Trace
+	span := tr.StartSpan("ignored")
+	span.SetAttributes(Attr("k", 1))
+	span.End()
+	tr.Record("ignored", time.Now(), time.Now())
+
+	if tr.Detailed() || tr.Root() != nil || tr.Spans() != nil || tr.Format() != "" {
+		t.Error("expected nil trace to record nothing")
+	}
+
+	it := newSliceIterator(t, 1)
+	if TraceOperator(tr, "Scan", it) != it {
+		t.Error("expected operator to be returned unchanged without a trace")
+	}
+}
+
+func TestStartSpan_Nesting(t *testing.T) {
+	tr := NewTrace("query", time.Now())
+
+	outer := tr.StartSpan("execute")
+	inner := tr.StartSpan("wal.commit")
+	inner.End()
+	tr.Record("lock.wait", time.Now().Add(-time.Millisecond), time.Now())
+	outer.End()
+	tr.StartSpan("commit").End()
+
+	root := tr.Root()
+	children := root.Children()
+	if len(children) != 2 || children[0].Name() != "execute" || children[1].Name() != "commit" {
+		t.Fatalf("unexpected root children: %v", children)
+	}
+
+	grandchildren := children[0].Children()
+	if len(grandchildren) != 2 || grandchildren[0].Name() != "wal.commit" || grandchildren[1].Name() != "lock.wait" {
+		t.Fatalf("unexpected execute children: %v", grandchildren)
+	}
+	if grandchildren[1].Duration() < time.Millisecond {
+		t.Errorf("expected recorded span to keep its duration, got %v", grandchildren[1].Duration())
+	}
+}
+
+func TestTraceOperator_BuildsOperatorTree(t *testing.T) {
+	tr := NewTrace("query", time.Now())
+	tr.SetDetailed(true)
+
+	scan := TraceOperator(tr, "Scan", newSliceIterator(t, 3))
+	limit := TraceOperator(tr, "Limit", scan, scan)
+
+	execute := tr.StartSpan("execute")
+	AttachOperator(tr, limit)
+	drain(t, limit)
+	execute.End()
+
+	ops := execute.Children()
+	if len(ops) != 1 || ops[0].Name() != "Limit" {
+		t.Fatalf("expected Limit under execute, got %v", ops)
+	}
+	inputs := ops[0].Children()
+	if len(inputs) != 1 || inputs[0].Name() != "Scan" {
+		t.Fatalf("expected Scan under Limit, got %v", inputs)
+	}
+	if rows := attrValue(inputs[0], RowsAttr); rows != int64(3) {
+		t.Errorf("expected scan to report 3 rows, got %v", rows)
+	}
+	if _, ok := attrValue(ops[0], ActiveTimeAttr).(time.Duration); !ok {
+		t.Error("expected active_time attribute on operator span")
+	}
+}
+
+func TestTraceOperator_WaitsNestUnderOperator(t *testing.T) {
+	tr := NewTrace("query", time.Now())
+	tr.SetDetailed(true)
+
+	scan := TraceOperator(tr, "Scan", &waitingIterator{sliceIterator: newSliceIterator(t, 1), trace: tr})
+	AttachOperator(tr, scan)
+	drain(t, scan)
+
+	waits := scan.(*TracedIterator).Span().Children()
+	if len(waits) == 0 || waits[0].Name() != "lock.wait" {
+		t.Fatalf("expected lock wait under the scan span, got %v", waits)
+	}
+}
+
+// waitingIterator records a lock wait every time it produces a tuple.
+type waitingIterator struct {
+	*sliceIterator
+	trace *Trace
+}
+
+func (it *waitingIterator) Next() (*tuple.Tuple, error) {
+	now := time.Now()
+	it.trace.Record("lock.wait", now, now)
+	return it.sliceIterator.Next()
+}
+
+func TestJSONExporter(t *testing.T) {
+	tr := NewTrace("query", time.Now())
+	tr.StartSpan("parse", Attr("statement_type", "SELECT")).End()
+	tr.Root().End()
+
+	var buf bytes.Buffer
+	exporter := NewJSONExporter(&buf)
+	if err := exporter.ExportSpans(context.Background(), tr.Spans()); err != nil {
+		t.Fatalf("ExportSpans failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %d:\n%s", len(lines), buf.String())
+	}
+
+	var root, child map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &root); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &child); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := root["parent_span_id"]; ok {
+		t.Error("root span must not have a parent")
+	}
+	if child["parent_span_id"] != root["span_id"] || child["trace_id"] != root["trace_id"] {
+		t.Errorf("child span not linked to root: %v", child)
+	}
+
+	exporter.Shutdown(context.Background())
+	buf.Reset()
+	exporter.ExportSpans(context.Background(), tr.Spans())
+	if buf.Len() != 0 {
+		t.Error("expected no output after Shutdown")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tr := NewTrace("query", time.Now())
+	tr.StartSpan("plan").End()
+	running := tr.StartSpan("execute")
+	running.SetAttributes(Attr(RowsAttr, 5))
+
+	out := tr.Format()
+	if !strings.Contains(out, "  -> plan ") {
+		t.Errorf("expected indented plan span, got:\n%s", out)
+	}
+	if !strings.Contains(out, "-> execute") || !strings.Contains(out, "(running) rows=5") {
+		t.Errorf("expected running execute span with attributes, got:\n%s", out)
+	}
+}
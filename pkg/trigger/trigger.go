@@ -0,0 +1,294 @@
+// Package trigger runs registered Go functions when rows of a table are
+// inserted, updated or deleted.
+//
+// Trigger definitions live in the CATALOG_TRIGGERS system table and name a
+// function by string; the functions themselves are registered at runtime in a
+// Registry. The DML executor fires the triggers of a table once per affected
+// row, inside the statement's transaction:
+//
+//   - BEFORE triggers run before constraints are validated and the row is
+//     written. They may change the new row with SetNew, skip the row by
+//     returning ErrSkipRow, or abort the statement by returning any other error.
+//   - AFTER triggers run once the row has been written. Returning an error
+//     aborts the statement.
+//
+// Statements run by a trigger through Context.Exec share the transaction, so
+// their changes are logged to the WAL with the triggering statement's and are
+// rolled back with it.
+package trigger
+
+import (
+	"errors"
+	"fmt"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"sync"
+)
+
+// Timing says whether a trigger runs before or after the row is written.
+type Timing string
+
+const (
+	Before Timing = "BEFORE"
+	After  Timing = "AFTER"
+)
+
+// Event is the kind of row change that fires a trigger.
+type Event string
+
+const (
+	Insert Event = "INSERT"
+	Update Event = "UPDATE"
+	Delete Event = "DELETE"
+)
+
+// MaxDepth bounds how deeply triggers may fire other triggers through
+// Context.Exec, so that a trigger that modifies its own table fails instead
+// of recursing forever.
+const MaxDepth = 16
+
+// ErrSkipRow may be returned by a BEFORE trigger to leave the current row
+// unchanged without failing the statement. Later triggers do not run for the
+// row and it is not counted as affected.
+var ErrSkipRow = errors.New("skip row")
+
+// Func is a trigger function. It receives the row being changed and may run
+// further statements in the same transaction through ctx.Exec.
+type Func func(ctx *Context) error
+
+// Executor runs a SQL statement inside an existing transaction. The database
+// installs one so that trigger functions can call Context.Exec.
+type Executor func(tx *transaction.TransactionContext, sql string) error
+
+// Definition is a trigger as stored in the catalog.
+type Definition struct {
+	Name     string
+	Timing   Timing
+	Event    Event
+	Function string
+}
+
+// Context describes the row change a trigger function is running for.
+type Context struct {
+	Trigger string // Trigger name
+	Table   string // Table the row belongs to
+	Timing  Timing
+	Event   Event
+
+	// Old is the row before the change; nil for INSERT.
+	Old *tuple.Tuple
+	// New is the row after the change; nil for DELETE.
+	New *tuple.Tuple
+
+	Tx *transaction.TransactionContext
+
+	registry *Registry
+}
+
+// OldValue returns a column of the old row.
+func (c *Context) OldValue(column string) (types.Field, error) {
+	return rowValue(c.Old, "OLD", c.Event, column)
+}
+
+// NewValue returns a column of the new row.
+func (c *Context) NewValue(column string) (types.Field, error) {
+	return rowValue(c.New, "NEW", c.Event, column)
+}
+
+// SetNew replaces a column of the new row. Only BEFORE INSERT and BEFORE
+// UPDATE triggers may change the row; the changed row is what constraints are
+// validated against and what is written.
+func (c *Context) SetNew(column string, value types.Field) error {
+	if c.Timing != Before || c.New == nil {
+		return fmt.Errorf("trigger %s: only BEFORE INSERT and BEFORE UPDATE triggers can modify NEW", c.Trigger)
+	}
+	idx, err := c.New.TupleDesc.FindFieldIndex(strings.ToUpper(column))
+	if err != nil {
+		return err
+	}
+	return c.New.SetField(idx, value)
+}
+
+// Exec runs a SQL statement in the transaction of the triggering statement.
+// Triggers on the tables it changes fire as usual.
+func (c *Context) Exec(sql string) error {
+	c.registry.mu.RLock()
+	exec := c.registry.exec
+	c.registry.mu.RUnlock()
+
+	if exec == nil {
+		return fmt.Errorf("trigger %s: no statement executor is installed", c.Trigger)
+	}
+	return exec(c.Tx, sql)
+}
+
+func rowValue(row *tuple.Tuple, which string, event Event, column string) (types.Field, error) {
+	if row == nil {
+		return nil, fmt.Errorf("%s is not available in %s triggers", which, event)
+	}
+	idx, err := row.TupleDesc.FindFieldIndex(strings.ToUpper(column))
+	if err != nil {
+		return nil, err
+	}
+	return row.GetField(idx)
+}
+
+// Registry maps function names to trigger functions and fires triggers.
+// It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	funcs map[string]Func
+	exec  Executor
+	depth map[int64]int // Nesting depth of Fire per transaction
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		funcs: make(map[string]Func),
+		depth: make(map[int64]int),
+	}
+}
+
+// Register adds a trigger function under a case-insensitive name.
+// Returns an error if the name is empty, fn is nil or the name is taken.
+func (r *Registry) Register(name string, fn Func) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("trigger function name cannot be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("trigger function %s cannot be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.funcs[name]; exists {
+		return fmt.Errorf("trigger function %s is already registered", name)
+	}
+	r.funcs[name] = fn
+	return nil
+}
+
+// Unregister removes a trigger function. Triggers that name it fail when they
+// fire until a function with that name is registered again.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.funcs, strings.ToUpper(name))
+}
+
+// Lookup returns the function registered under name.
+func (r *Registry) Lookup(name string) (Func, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn, ok := r.funcs[strings.ToUpper(name)]
+	return fn, ok
+}
+
+// SetExecutor installs the function used by Context.Exec.
+func (r *Registry) SetExecutor(exec Executor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exec = exec
+}
+
+// Fire runs, in order, each definition matching timing and event for one row.
+// Changes made with SetNew are applied to newRow in place. skip reports that a
+// BEFORE trigger returned ErrSkipRow, in which case the row must be left alone.
+//
+// A failing trigger stops the remaining ones and its error is returned as a
+// TRIGGER_FAILED DBError, unless it already is a DBError, whose code is kept.
+func (r *Registry) Fire(tx *transaction.TransactionContext, table string, defs []Definition, timing Timing, event Event, oldRow, newRow *tuple.Tuple) (skip bool, err error) {
+	var matching []Definition
+	for _, d := range defs {
+		if d.Timing == timing && d.Event == event {
+			matching = append(matching, d)
+		}
+	}
+	if len(matching) == 0 {
+		return false, nil
+	}
+
+	if err := r.enter(tx); err != nil {
+		return false, err
+	}
+	defer r.exit(tx)
+
+	for _, d := range matching {
+		fn, ok := r.Lookup(d.Function)
+		if !ok {
+			err := dberror.New(dberror.ErrCategoryUser, "TRIGGER_FUNCTION_NOT_FOUND",
+				fmt.Sprintf("trigger %s on %s calls function %s, which is not registered", d.Name, table, d.Function))
+			err.Operation = "Fire"
+			err.Component = "TriggerRegistry"
+			err.Hint = "Register the function with Database.RegisterTriggerFunc or drop the trigger"
+			return false, err
+		}
+
+		ctx := &Context{
+			Trigger:  d.Name,
+			Table:    table,
+			Timing:   timing,
+			Event:    event,
+			Old:      oldRow,
+			New:      newRow,
+			Tx:       tx,
+			registry: r,
+		}
+		if err := fn(ctx); err != nil {
+			if timing == Before && errors.Is(err, ErrSkipRow) {
+				return true, nil
+			}
+			return false, triggerError(d, table, err)
+		}
+	}
+	return false, nil
+}
+
+// enter records one more level of trigger nesting for tx.
+func (r *Registry) enter(tx *transaction.TransactionContext) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := tx.ID.ID()
+	if r.depth[id] >= MaxDepth {
+		err := dberror.New(dberror.ErrCategoryUser, "TRIGGER_DEPTH_EXCEEDED",
+			fmt.Sprintf("triggers nested more than %d levels deep", MaxDepth))
+		err.Operation = "Fire"
+		err.Component = "TriggerRegistry"
+		err.Hint = "Check for triggers that modify the table that fires them"
+		return err
+	}
+	r.depth[id]++
+	return nil
+}
+
+// exit undoes enter.
+func (r *Registry) exit(tx *transaction.TransactionContext) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := tx.ID.ID()
+	if r.depth[id]--; r.depth[id] <= 0 {
+		delete(r.depth, id)
+	}
+}
+
+func triggerError(d Definition, table string, err error) error {
+	var dbErr *dberror.DBError
+	if errors.As(err, &dbErr) {
+		return err
+	}
+
+	wrapped := dberror.New(dberror.ErrCategoryUser, "TRIGGER_FAILED",
+		fmt.Sprintf("trigger %s on %s failed: %v", d.Name, table, err))
+	wrapped.Cause = err
+	wrapped.Operation = "Fire"
+	wrapped.Component = "TriggerRegistry"
+	return wrapped
+}
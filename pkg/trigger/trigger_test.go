@@ -0,0 +1,207 @@
+package trigger
+
+import (
+	"errors"
+	"slices"
+	"storemy/pkg/concurrency/transaction"
+	dberror "storemy/pkg/error"
+	"storemy/pkg/primitives"
+	"storemy/pkg/tuple"
+	"storemy/pkg/types"
+	"strings"
+	"testing"
+)
+
+func testRow(t *testing.T, id int64, name string) *tuple.Tuple {
+	t.Helper()
+	td, err := tuple.NewTupleDesc([]types.Type{types.IntType, types.StringType}, []string{"ID", "NAME"})
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	return tuple.NewBuilder(td).AddInt(id).AddString(name).MustBuild()
+}
+
+func testTx() *transaction.TransactionContext {
+	return transaction.NewTransactionContext(primitives.NewTransactionID())
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	noop := func(*Context) error { return nil }
+
+	if err := r.Register("audit", noop); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, ok := r.Lookup("AUDIT"); !ok {
+		t.Error("expected lookup to be case-insensitive")
+	}
+	if err := r.Register("Audit", noop); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+	if err := r.Register(" ", noop); err == nil {
+		t.Error("expected empty name to fail")
+	}
+	if err := r.Register("other", nil); err == nil {
+		t.Error("expected nil function to fail")
+	}
+
+	r.Unregister("audit")
+	if _, ok := r.Lookup("audit"); ok {
+		t.Error("expected function to be unregistered")
+	}
+}
+
+func TestRegistry_FireOrderAndMatching(t *testing.T) {
+	r := NewRegistry()
+	var calls []string
+	record := func(ctx *Context) error {
+		calls = append(calls, ctx.Trigger)
+		return nil
+	}
+	if err := r.Register("record", record); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	defs := []Definition{
+		{Name: "A", Timing: Before, Event: Insert, Function: "record"},
+		{Name: "B", Timing: After, Event: Insert, Function: "record"},
+		{Name: "C", Timing: Before, Event: Insert, Function: "record"},
+		{Name: "D", Timing: Before, Event: Delete, Function: "record"},
+	}
+
+	skip, err := r.Fire(testTx(), "T", defs, Before, Insert, nil, testRow(t, 1, "a"))
+	if err != nil || skip {
+		t.Fatalf("Fire = (%v, %v), want (false, nil)", skip, err)
+	}
+	if !slices.Equal(calls, []string{"A", "C"}) {
+		t.Errorf("calls = %v, want [A C]", calls)
+	}
+}
+
+func TestRegistry_FireBeforeCanModifyAndSkip(t *testing.T) {
+	r := NewRegistry()
+	r.Register("upper", func(ctx *Context) error {
+		name, err := ctx.NewValue("name")
+		if err != nil {
+			return err
+		}
+		return ctx.SetNew("name", types.NewStringField(strings.ToUpper(name.String()), types.StringMaxSize))
+	})
+	r.Register("skip_negative", func(ctx *Context) error {
+		id, err := ctx.NewValue("id")
+		if err != nil {
+			return err
+		}
+		if id.(*types.IntField).Value < 0 {
+			return ErrSkipRow
+		}
+		return nil
+	})
+
+	defs := []Definition{
+		{Name: "A_SKIP", Timing: Before, Event: Insert, Function: "skip_negative"},
+		{Name: "B_UPPER", Timing: Before, Event: Insert, Function: "upper"},
+	}
+
+	row := testRow(t, 1, "alice")
+	if skip, err := r.Fire(testTx(), "T", defs, Before, Insert, nil, row); err != nil || skip {
+		t.Fatalf("Fire = (%v, %v), want (false, nil)", skip, err)
+	}
+	if name, _ := row.GetField(1); name.String() != "ALICE" {
+		t.Errorf("expected NEW to be modified in place, got %s", name)
+	}
+
+	if skip, err := r.Fire(testTx(), "T", defs, Before, Insert, nil, testRow(t, -1, "bob")); err != nil || !skip {
+		t.Errorf("Fire = (%v, %v), want (true, nil)", skip, err)
+	}
+}
+
+func TestRegistry_FireAfterCannotModify(t *testing.T) {
+	r := NewRegistry()
+	r.Register("modify", func(ctx *Context) error {
+		return ctx.SetNew("name", types.NewStringField("x", types.StringMaxSize))
+	})
+	r.Register("old", func(ctx *Context) error {
+		_, err := ctx.OldValue("id")
+		return err
+	})
+
+	defs := []Definition{{Name: "M", Timing: After, Event: Insert, Function: "modify"}}
+	_, err := r.Fire(testTx(), "T", defs, After, Insert, nil, testRow(t, 1, "a"))
+	if err == nil || !strings.Contains(err.Error(), "only BEFORE") {
+		t.Errorf("expected SetNew in an AFTER trigger to fail, got %v", err)
+	}
+
+	defs = []Definition{{Name: "O", Timing: Before, Event: Insert, Function: "old"}}
+	_, err = r.Fire(testTx(), "T", defs, Before, Insert, nil, testRow(t, 1, "a"))
+	if err == nil || !strings.Contains(err.Error(), "OLD is not available in INSERT triggers") {
+		t.Errorf("expected OLD to be unavailable on INSERT, got %v", err)
+	}
+}
+
+func TestRegistry_FireErrors(t *testing.T) {
+	r := NewRegistry()
+	cause := errors.New("balance too low")
+	r.Register("fail", func(*Context) error { return cause })
+	r.Register("fail_db", func(*Context) error {
+		return dberror.New(dberror.ErrCategoryUser, "CUSTOM", "custom failure")
+	})
+
+	tests := []struct {
+		name     string
+		function string
+		code     string
+	}{
+		{"Plain error", "fail", "TRIGGER_FAILED"},
+		{"DBError keeps its code", "fail_db", "CUSTOM"},
+		{"Missing function", "missing", "TRIGGER_FUNCTION_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs := []Definition{{Name: "CHECK_BALANCE", Timing: Before, Event: Update, Function: tt.function}}
+			_, err := r.Fire(testTx(), "ACCOUNTS", defs, Before, Update, testRow(t, 1, "a"), testRow(t, 1, "b"))
+
+			var dbErr *dberror.DBError
+			if !errors.As(err, &dbErr) || dbErr.Code != tt.code {
+				t.Fatalf("expected %s error, got %v", tt.code, err)
+			}
+		})
+	}
+
+	defs := []Definition{{Name: "CHECK_BALANCE", Timing: Before, Event: Update, Function: "fail"}}
+	_, err := r.Fire(testTx(), "ACCOUNTS", defs, Before, Update, nil, testRow(t, 1, "b"))
+	if !errors.Is(err, cause) || !strings.Contains(err.Error(), "trigger CHECK_BALANCE on ACCOUNTS failed") {
+		t.Errorf("expected wrapped cause naming the trigger, got %v", err)
+	}
+}
+
+func TestRegistry_ExecDepthLimit(t *testing.T) {
+	r := NewRegistry()
+	defs := []Definition{{Name: "LOOP", Timing: After, Event: Insert, Function: "loop"}}
+
+	calls := 0
+	r.SetExecutor(func(tx *transaction.TransactionContext, sql string) error {
+		// Stands in for an INSERT into the same table, which fires LOOP again.
+		_, err := r.Fire(tx, "T", defs, After, Insert, nil, testRow(t, 1, "a"))
+		return err
+	})
+	r.Register("loop", func(ctx *Context) error {
+		calls++
+		return ctx.Exec("INSERT INTO t VALUES (1, 'a')")
+	})
+
+	tx := testTx()
+	_, err := r.Fire(tx, "T", defs, After, Insert, nil, testRow(t, 1, "a"))
+
+	var dbErr *dberror.DBError
+	if !errors.As(err, &dbErr) || dbErr.Code != "TRIGGER_DEPTH_EXCEEDED" {
+		t.Fatalf("expected TRIGGER_DEPTH_EXCEEDED, got %v", err)
+	}
+	if calls != MaxDepth {
+		t.Errorf("expected %d nested calls, got %d", MaxDepth, calls)
+	}
+	if len(r.depth) != 0 {
+		t.Errorf("expected depth to be reset after Fire returns, got %v", r.depth)
+	}
+}
@@ -0,0 +1,245 @@
+package tuple
+
+import (
+	"fmt"
+	"slices"
+	"storemy/pkg/primitives"
+	"storemy/pkg/types"
+	"sync"
+)
+
+// CompiledSchema is the storage layout of a TupleDescription, worked out once
+// instead of for every tuple: the byte offset of each field in a serialized
+// tuple and the size of the whole tuple. Fields are serialized back to back
+// at fixed sizes, so a field can be decoded straight from its offset.
+//
+// A CompiledSchema is immutable and safe for concurrent use.
+type CompiledSchema struct {
+	Desc    *TupleDescription // Schema the layout was compiled from
+	Version uint64            // Raised each time the table's schema is recompiled
+	offsets []uint32
+	size    uint32
+}
+
+// Compile works out the storage layout of td.
+//
+// Parameters:
+//   - td: the tuple description to compile
+//
+// Returns:
+//   - *CompiledSchema: the layout of tuples described by td, at version 1
+func Compile(td *TupleDescription) *CompiledSchema {
+	cs := &CompiledSchema{
+		Desc:    td,
+		Version: 1,
+		offsets: make([]uint32, len(td.Types)),
+	}
+	for i, fieldType := range td.Types {
+		cs.offsets[i] = cs.size
+		cs.size += fieldType.Size()
+	}
+	return cs
+}
+
+// Size returns the size in bytes of a serialized tuple.
+func (cs *CompiledSchema) Size() uint32 {
+	return cs.size
+}
+
+// Offset returns the byte offset of field i in a serialized tuple.
+func (cs *CompiledSchema) Offset(i primitives.ColumnID) uint32 {
+	return cs.offsets[i]
+}
+
+// Matches reports whether tuples described by td have this layout and field
+// names, so that the compiled schema can stand in for td.
+func (cs *CompiledSchema) Matches(td *TupleDescription) bool {
+	if td == cs.Desc {
+		return true
+	}
+	return td != nil && slices.Equal(td.Types, cs.Desc.Types) && slices.Equal(td.FieldNames, cs.Desc.FieldNames)
+}
+
+// Decode builds a tuple from its serialized form. data must hold at least
+// Size() bytes; anything after the tuple is ignored.
+//
+// Returns:
+//   - *Tuple: the decoded tuple, described by cs.Desc
+//   - error: if data is too short or a field cannot be decoded
+func (cs *CompiledSchema) Decode(data []byte) (*Tuple, error) {
+	if uint32(len(data)) < cs.size {
+		return nil, fmt.Errorf("tuple needs %d bytes, got %d", cs.size, len(data))
+	}
+
+	fields := make([]types.Field, len(cs.offsets))
+	for i, fieldType := range cs.Desc.Types {
+		field, err := types.DecodeField(data[cs.offsets[i]:cs.offsets[i]+fieldType.Size()], fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", i, err)
+		}
+		fields[i] = field
+	}
+	return &Tuple{TupleDesc: cs.Desc, fields: fields}, nil
+}
+
+// DecodeInto decodes a serialized tuple into t, overwriting its fields and
+// reusing the field values it already holds (see types.DecodeFieldInto), so
+// that decoding many tuples into one allocates nothing once t is filled.
+// Fields t does not hold yet are allocated, so a new tuple can be decoded
+// too. Strings are interned in dict unless it is nil. t must be described
+// by cs.Desc; its RecordID is left unchanged. On error t is partly
+// overwritten.
+func (cs *CompiledSchema) DecodeInto(t *Tuple, data []byte, dict *types.StringDict) error {
+	if uint32(len(data)) < cs.size {
+		return fmt.Errorf("tuple needs %d bytes, got %d", cs.size, len(data))
+	}
+	if len(t.fields) != len(cs.offsets) {
+		return fmt.Errorf("tuple has %d fields, schema has %d", len(t.fields), len(cs.offsets))
+	}
+
+	for i, fieldType := range cs.Desc.Types {
+		field, err := types.DecodeFieldInto(t.fields[i], data[cs.offsets[i]:cs.offsets[i]+fieldType.Size()], fieldType, dict)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", i, err)
+		}
+		t.fields[i] = field
+	}
+	t.TupleDesc = cs.Desc
+	return nil
+}
+
+// DecodeColumnsInto is DecodeInto for the fields listed in cols alone, which
+// are decoded straight from their offsets while the bytes of the others are
+// skipped. The fields not listed are set to nil, so a reader that only uses
+// some columns, such as a projection, pays for decoding just those. cols
+// must be sorted in increasing order without duplicates.
+func (cs *CompiledSchema) DecodeColumnsInto(t *Tuple, data []byte, cols []primitives.ColumnID, dict *types.StringDict) error {
+	if uint32(len(data)) < cs.size {
+		return fmt.Errorf("tuple needs %d bytes, got %d", cs.size, len(data))
+	}
+	if len(t.fields) != len(cs.offsets) {
+		return fmt.Errorf("tuple has %d fields, schema has %d", len(t.fields), len(cs.offsets))
+	}
+	if len(cols) > 0 && int(cols[len(cols)-1]) >= len(cs.offsets) {
+		return fmt.Errorf("field index %d out of bounds [0, %d)", cols[len(cols)-1], len(cs.offsets))
+	}
+
+	next := 0
+	for i, fieldType := range cs.Desc.Types {
+		if next == len(cols) || int(cols[next]) != i {
+			t.fields[i] = nil
+			continue
+		}
+		next++
+		field, err := types.DecodeFieldInto(t.fields[i], data[cs.offsets[i]:cs.offsets[i]+fieldType.Size()], fieldType, dict)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", i, err)
+		}
+		t.fields[i] = field
+	}
+	t.TupleDesc = cs.Desc
+	return nil
+}
+
+// DecodeField decodes field i of a serialized tuple without decoding the
+// others.
+func (cs *CompiledSchema) DecodeField(data []byte, i primitives.ColumnID) (types.Field, error) {
+	if int(i) >= len(cs.offsets) {
+		return nil, fmt.Errorf("field index %d out of bounds [0, %d)", i, len(cs.offsets))
+	}
+	fieldType := cs.Desc.Types[i]
+	end := cs.offsets[i] + fieldType.Size()
+	if uint32(len(data)) < end {
+		return nil, fmt.Errorf("field %d needs bytes up to %d, got %d", i, end, len(data))
+	}
+	return types.DecodeField(data[cs.offsets[i]:end], fieldType)
+}
+
+// Encode serializes t into Size() bytes, each field at its offset. Fields
+// that are not set are left zeroed.
+func (cs *CompiledSchema) Encode(t *Tuple) []byte {
+	data := make([]byte, cs.size)
+	for i, field := range t.fields {
+		if field == nil || i >= len(cs.offsets) {
+			continue
+		}
+		off := cs.offsets[i]
+		field.Serialize(&sliceWriter{buf: data[off : off+cs.Desc.Types[i].Size()]})
+	}
+	return data
+}
+
+// sliceWriter writes into a preallocated slice, dropping what does not fit.
+type sliceWriter struct {
+	buf []byte
+}
+
+func (w *sliceWriter) Write(p []byte) (int, error) {
+	n := copy(w.buf, p)
+	w.buf = w.buf[n:]
+	return len(p), nil
+}
+
+// SchemaCache holds the compiled schema of each table, keyed by table ID, so
+// that heap scans and the executor work out a table's layout once rather than
+// per tuple. When the tuple description of a table changes, its entry is
+// recompiled with a higher version; readers holding the old entry keep a
+// consistent, if stale, view.
+type SchemaCache struct {
+	mutex   sync.RWMutex
+	schemas map[primitives.FileID]*CompiledSchema
+	// Last version handed out per table, kept across Invalidate so that
+	// versions never repeat
+	versions map[primitives.FileID]uint64
+}
+
+// Schemas is the process-wide schema cache used by heap files.
+var Schemas = NewSchemaCache()
+
+// NewSchemaCache creates an empty schema cache.
+func NewSchemaCache() *SchemaCache {
+	return &SchemaCache{
+		schemas:  make(map[primitives.FileID]*CompiledSchema),
+		versions: make(map[primitives.FileID]uint64),
+	}
+}
+
+// Get returns the compiled schema of table tableID, whose tuples are
+// described by td. The cached entry is returned if it matches td; otherwise
+// td is compiled and replaces it at the next version.
+func (c *SchemaCache) Get(tableID primitives.FileID, td *TupleDescription) *CompiledSchema {
+	c.mutex.RLock()
+	cs, ok := c.schemas[tableID]
+	c.mutex.RUnlock()
+	if ok && cs.Matches(td) {
+		return cs
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cs, ok := c.schemas[tableID]; ok && cs.Matches(td) {
+		return cs
+	}
+	cs = Compile(td)
+	c.versions[tableID]++
+	cs.Version = c.versions[tableID]
+	c.schemas[tableID] = cs
+	return cs
+}
+
+// Lookup returns the cached compiled schema of table tableID, if any.
+func (c *SchemaCache) Lookup(tableID primitives.FileID) (*CompiledSchema, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	cs, ok := c.schemas[tableID]
+	return cs, ok
+}
+
+// Invalidate drops the compiled schema of table tableID, after the table was
+// dropped or its schema altered. The next Get compiles it at a new version.
+func (c *SchemaCache) Invalidate(tableID primitives.FileID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.schemas, tableID)
+}
@@ -0,0 +1,171 @@
+package tuple
+
+import (
+	"storemy/pkg/primitives"
+	"storemy/pkg/types"
+	"testing"
+)
+
+func mustCompiledDesc(t *testing.T, names ...string) *TupleDescription {
+	td, err := NewTupleDesc([]types.Type{types.IntType, types.StringType, types.BoolType}, names)
+	if err != nil {
+		t.Fatalf("NewTupleDesc failed: %v", err)
+	}
+	return td
+}
+
+func TestCompile_Layout(t *testing.T) {
+	cs := Compile(mustCompiledDesc(t, "id", "name", "active"))
+
+	wantOffsets := []uint32{0, 8, 8 + types.StringType.Size()}
+	for i, want := range wantOffsets {
+		if got := cs.Offset(primitives.ColumnID(i)); got != want {
+			t.Errorf("Offset(%d) = %d, want %d", i, got, want)
+		}
+	}
+	if cs.Size() != cs.Desc.GetSize() {
+		t.Errorf("Size() = %d, want %d", cs.Size(), cs.Desc.GetSize())
+	}
+}
+
+func TestCompiledSchema_EncodeDecode(t *testing.T) {
+	td := mustCompiledDesc(t, "id", "name", "active")
+	cs := Compile(td)
+
+	original, err := NewBuilder(td).AddInt(7).AddString("alice").AddBool(true).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data := cs.Encode(original)
+	if uint32(len(data)) != cs.Size() {
+		t.Fatalf("Encode produced %d bytes, want %d", len(data), cs.Size())
+	}
+
+	decoded, err := cs.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if decoded.TupleDesc != td {
+		t.Error("decoded tuple should be described by the compiled description")
+	}
+	for i := primitives.ColumnID(0); i < td.NumFields(); i++ {
+		want, _ := original.GetField(i)
+		got, _ := decoded.GetField(i)
+		if !got.Equals(want) {
+			t.Errorf("field %d = %v, want %v", i, got, want)
+		}
+	}
+
+	name, err := cs.DecodeField(data, 1)
+	if err != nil || name.String() != "alice" {
+		t.Errorf("DecodeField(1) = %v, %v; want alice", name, err)
+	}
+
+	if _, err := cs.Decode(data[:cs.Size()-1]); err == nil {
+		t.Error("expected an error decoding a truncated tuple")
+	}
+	if _, err := cs.DecodeField(data, 3); err == nil {
+		t.Error("expected an error decoding a field out of bounds")
+	}
+}
+
+func TestCompiledSchema_DecodeIntoReusesFields(t *testing.T) {
+	td := mustCompiledDesc(t, "id", "name", "active")
+	cs := Compile(td)
+
+	first := cs.Encode(NewBuilder(td).AddInt(1).AddString("alice").AddBool(true).MustBuild())
+	second := cs.Encode(NewBuilder(td).AddInt(2).AddString("bob").AddBool(false).MustBuild())
+
+	reused := NewTuple(td)
+	if err := cs.DecodeInto(reused, first, nil); err != nil {
+		t.Fatalf("DecodeInto failed: %v", err)
+	}
+	id, _ := reused.GetField(0)
+
+	if err := cs.DecodeInto(reused, second, nil); err != nil {
+		t.Fatalf("DecodeInto failed: %v", err)
+	}
+	if got := reused.String(); got != "2\tbob\tfalse\n" {
+		t.Errorf("decoded %q", got)
+	}
+	if again, _ := reused.GetField(0); again != id {
+		t.Error("expected the field value to be reused")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := cs.DecodeInto(reused, second, nil); err != nil {
+			t.Fatalf("DecodeInto failed: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("DecodeInto allocated %.0f times decoding the same tuple", allocs)
+	}
+
+	if err := cs.DecodeInto(reused, second[:cs.Size()-1], nil); err == nil {
+		t.Error("expected an error decoding a truncated tuple")
+	}
+}
+
+func TestCompiledSchema_DecodeColumnsInto(t *testing.T) {
+	td := mustCompiledDesc(t, "id", "name", "active")
+	cs := Compile(td)
+	data := cs.Encode(NewBuilder(td).AddInt(7).AddString("alice").AddBool(true).MustBuild())
+
+	// A full tuple left in the reused tuple loses the columns not asked for
+	reused := NewTuple(td)
+	if err := cs.DecodeInto(reused, data, nil); err != nil {
+		t.Fatalf("DecodeInto failed: %v", err)
+	}
+	if err := cs.DecodeColumnsInto(reused, data, []primitives.ColumnID{0, 2}, nil); err != nil {
+		t.Fatalf("DecodeColumnsInto failed: %v", err)
+	}
+	if id, _ := reused.GetField(0); id == nil || id.String() != "7" {
+		t.Errorf("field 0 = %v, want 7", id)
+	}
+	if name, _ := reused.GetField(1); name != nil {
+		t.Errorf("field 1 = %v, want nil", name)
+	}
+	if active, _ := reused.GetField(2); active == nil || active.String() != "true" {
+		t.Errorf("field 2 = %v, want true", active)
+	}
+
+	if err := cs.DecodeColumnsInto(reused, data, []primitives.ColumnID{3}, nil); err == nil {
+		t.Error("expected an error decoding a field out of bounds")
+	}
+}
+
+func TestSchemaCache_Versions(t *testing.T) {
+	cache := NewSchemaCache()
+	td := mustCompiledDesc(t, "id", "name", "active")
+
+	first := cache.Get(1, td)
+	if first.Version != 1 {
+		t.Errorf("first version = %d, want 1", first.Version)
+	}
+
+	// An equal description reuses the compiled schema
+	if cs := cache.Get(1, mustCompiledDesc(t, "id", "name", "active")); cs != first {
+		t.Error("an equal tuple description should reuse the cached schema")
+	}
+
+	// A changed description is recompiled at the next version
+	renamed := cache.Get(1, mustCompiledDesc(t, "id", "full_name", "active"))
+	if renamed == first || renamed.Version != 2 {
+		t.Errorf("changed schema: got version %d (same entry: %v), want a new entry at version 2", renamed.Version, renamed == first)
+	}
+
+	// Versions keep growing after an invalidation
+	cache.Invalidate(1)
+	if _, ok := cache.Lookup(1); ok {
+		t.Error("Lookup found an invalidated schema")
+	}
+	if cs := cache.Get(1, td); cs.Version != 3 {
+		t.Errorf("version after Invalidate = %d, want 3", cs.Version)
+	}
+
+	// Tables are versioned independently
+	if cs := cache.Get(2, td); cs.Version != 1 {
+		t.Errorf("version of another table = %d, want 1", cs.Version)
+	}
+}
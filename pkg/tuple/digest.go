@@ -0,0 +1,80 @@
+package tuple
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// Digest computes a stable checksum of a multiset of tuples. Each tuple is
+// encoded canonically, field by field with its type and a NULL marker, and
+// the encodings are hashed with SHA-256 in sorted order, so the checksum
+// depends only on the rows and their multiplicities: not on the order they
+// were added in, which differs with page layout after deletes, vacuums or
+// recovery.
+//
+// The encodings are kept until Sum, so the caller should account for the
+// sizes Add returns.
+type Digest struct {
+	rows [][]byte
+}
+
+// NewDigest creates an empty digest.
+func NewDigest() *Digest {
+	return &Digest{}
+}
+
+// Add adds t to the digest and returns the size of its encoding in bytes.
+func (d *Digest) Add(t *Tuple) (int, error) {
+	row, err := canonicalEncoding(t)
+	if err != nil {
+		return 0, err
+	}
+	d.rows = append(d.rows, row)
+	return len(row), nil
+}
+
+// Rows returns the number of tuples added.
+func (d *Digest) Rows() int {
+	return len(d.rows)
+}
+
+// Sum returns the checksum of the tuples added so far as a hex string.
+func (d *Digest) Sum() string {
+	slices.SortFunc(d.rows, bytes.Compare)
+
+	h := sha256.New()
+	var length [4]byte
+	for _, row := range d.rows {
+		binary.BigEndian.PutUint32(length[:], uint32(len(row)))
+		h.Write(length[:])
+		h.Write(row)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalEncoding encodes t's fields in order: a 0 byte for NULL, or a 1
+// byte, the field's type and its serialized value.
+func canonicalEncoding(t *Tuple) ([]byte, error) {
+	var buf bytes.Buffer
+	for i := range t.NumFields() {
+		field, err := t.GetField(i)
+		if err != nil {
+			return nil, err
+		}
+		if field == nil {
+			buf.WriteByte(0)
+			continue
+		}
+
+		buf.WriteByte(1)
+		buf.WriteByte(byte(field.Type()))
+		if err := field.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("failed to encode field %d: %w", i, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
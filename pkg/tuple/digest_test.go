@@ -0,0 +1,62 @@
+package tuple
+
+import (
+	"storemy/pkg/types"
+	"testing"
+)
+
+// digestOf returns the checksum of rows of (id, name), where a nil name is
+// NULL.
+func digestOf(t *testing.T, rows ...[2]any) string {
+	t.Helper()
+	td := mustCreateTupleDesc([]types.Type{types.IntType, types.StringType}, []string{"id", "name"})
+
+	d := NewDigest()
+	for _, row := range rows {
+		tup := NewTuple(td)
+		tup.SetField(0, types.NewIntField(int64(row[0].(int))))
+		if row[1] != nil {
+			tup.SetField(1, types.NewStringField(row[1].(string), types.StringMaxSize))
+		}
+		if _, err := d.Add(tup); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if d.Rows() != len(rows) {
+		t.Errorf("expected %d rows, got %d", len(rows), d.Rows())
+	}
+	return d.Sum()
+}
+
+func TestDigest_OrderIndependent(t *testing.T) {
+	a := digestOf(t, [2]any{1, "ada"}, [2]any{2, "alan"}, [2]any{2, "alan"})
+	b := digestOf(t, [2]any{2, "alan"}, [2]any{1, "ada"}, [2]any{2, "alan"})
+	if a != b {
+		t.Errorf("expected the same checksum in any order, got %s and %s", a, b)
+	}
+}
+
+func TestDigest_DistinguishesContents(t *testing.T) {
+	base := digestOf(t, [2]any{1, "ada"}, [2]any{2, "alan"})
+
+	tests := []struct {
+		name string
+		sum  string
+	}{
+		{"Missing row", digestOf(t, [2]any{1, "ada"})},
+		{"Duplicate row", digestOf(t, [2]any{1, "ada"}, [2]any{2, "alan"}, [2]any{2, "alan"})},
+		{"Changed value", digestOf(t, [2]any{1, "ada"}, [2]any{2, "grace"})},
+		{"NULL value", digestOf(t, [2]any{1, "ada"}, [2]any{2, nil})},
+		{"Empty string", digestOf(t, [2]any{1, "ada"}, [2]any{2, ""})},
+	}
+
+	for _, tt := range tests {
+		if tt.sum == base {
+			t.Errorf("%s: expected a different checksum", tt.name)
+		}
+	}
+
+	if empty := digestOf(t); empty == base || empty != digestOf(t) {
+		t.Error("expected an empty digest to be stable and differ from a non-empty one")
+	}
+}
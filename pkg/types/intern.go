@@ -0,0 +1,54 @@
+package types
+
+// DefaultStringDictLimit is the number of distinct values a StringDict
+// created for a scan holds before it stops growing.
+const DefaultStringDictLimit = 4096
+
+// StringDict interns the string values decoded by a scan, so that rows
+// repeating a value share one string instead of each holding a copy. On a
+// low-cardinality column this saves an allocation per row for the value and
+// lets whatever keeps the values, such as the groups of an aggregate, hold
+// one copy per distinct value.
+//
+// The dictionary holds at most limit distinct values. Once it is full, new
+// values are no longer added and are decoded into strings of their own, so
+// a high-cardinality column costs one map lookup per value and at most
+// limit retained strings.
+//
+// A StringDict is meant for a single scan and is not safe for concurrent use.
+type StringDict struct {
+	values map[string]string
+	limit  int
+}
+
+// NewStringDict creates an empty dictionary holding at most limit values.
+// A limit of zero or less means DefaultStringDictLimit.
+func NewStringDict(limit int) *StringDict {
+	if limit <= 0 {
+		limit = DefaultStringDictLimit
+	}
+	return &StringDict{values: make(map[string]string), limit: limit}
+}
+
+// Intern returns b as a string, the one held by the dictionary if b was
+// seen before. Looking up a value already held allocates nothing.
+func (d *StringDict) Intern(b []byte) string {
+	if s, ok := d.values[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	if len(d.values) < d.limit {
+		d.values[s] = s
+	}
+	return s
+}
+
+// Len returns the number of distinct values held.
+func (d *StringDict) Len() int {
+	return len(d.values)
+}
+
+// Full reports whether the dictionary has stopped adding values.
+func (d *StringDict) Full() bool {
+	return len(d.values) >= d.limit
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+)
+
+func TestStringDict_Intern(t *testing.T) {
+	dict := NewStringDict(2)
+
+	first := dict.Intern([]byte("red"))
+	again := dict.Intern([]byte("red"))
+	if first != "red" || unsafe.StringData(first) != unsafe.StringData(again) {
+		t.Error("Expected repeated values to share one string")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		dict.Intern([]byte("red"))
+	})
+	if allocs != 0 {
+		t.Errorf("Intern allocated %.0f times for a value already held", allocs)
+	}
+
+	dict.Intern([]byte("green"))
+	if !dict.Full() {
+		t.Fatal("Expected the dictionary to be full")
+	}
+	blue := dict.Intern([]byte("blue"))
+	if blue != "blue" || dict.Len() != 2 {
+		t.Errorf("Expected blue to be decoded but not added, got %q with %d values", blue, dict.Len())
+	}
+}
+
+func TestDecodeFieldInto_Interned(t *testing.T) {
+	dict := NewStringDict(0)
+	var buf bytes.Buffer
+	if err := NewStringField("red", StringMaxSize).Serialize(&buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	first, err := DecodeFieldInto(nil, data, StringType, dict)
+	if err != nil {
+		t.Fatalf("DecodeFieldInto failed: %v", err)
+	}
+	second, err := DecodeFieldInto(nil, data, StringType, dict)
+	if err != nil {
+		t.Fatalf("DecodeFieldInto failed: %v", err)
+	}
+	a, b := first.(*StringField).Value, second.(*StringField).Value
+	if a != "red" || unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("Expected both fields to hold the interned value")
+	}
+
+	reused := NewStringField("green", StringMaxSize)
+	if _, err := DecodeFieldInto(reused, data, StringType, dict); err != nil {
+		t.Fatalf("DecodeFieldInto failed: %v", err)
+	}
+	if unsafe.StringData(reused.Value) != unsafe.StringData(a) {
+		t.Error("Expected the reused field to hold the interned value")
+	}
+}
@@ -943,3 +943,44 @@ func TestParseField_EmptyReader_Uint64Field(t *testing.T) {
 		t.Errorf("Expected EOF error, got: %v", err)
 	}
 }
+
+func TestDecodeField_RoundTrip(t *testing.T) {
+	fields := []Field{
+		NewIntField(-42),
+		NewInt32Field(-7),
+		NewInt64Field(1 << 40),
+		NewUint32Field(7),
+		NewUint64Field(1 << 63),
+		NewFloat64Field(3.25),
+		NewBoolField(true),
+		NewStringField("hello", StringMaxSize),
+		NewStringField("", StringMaxSize),
+	}
+
+	for _, original := range fields {
+		var buf bytes.Buffer
+		if err := original.Serialize(&buf); err != nil {
+			t.Fatalf("Failed to serialize %v: %v", original, err)
+		}
+
+		decoded, err := DecodeField(buf.Bytes(), original.Type())
+		if err != nil {
+			t.Fatalf("DecodeField(%v) failed: %v", original.Type(), err)
+		}
+		if !decoded.Equals(original) {
+			t.Errorf("DecodeField(%v) = %v, want %v", original.Type(), decoded, original)
+		}
+	}
+}
+
+func TestDecodeField_InvalidData(t *testing.T) {
+	if _, err := DecodeField(make([]byte, 4), IntType); err == nil {
+		t.Error("expected an error for a short int field")
+	}
+
+	data := make([]byte, StringType.Size())
+	binary.BigEndian.PutUint32(data, StringMaxSize+1)
+	if _, err := DecodeField(data, StringType); err == nil {
+		t.Error("expected an error for a string longer than StringMaxSize")
+	}
+}
@@ -0,0 +1,156 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// likeEscape escapes the next pattern character so that % and _ can be matched literally.
+const likeEscape = '\\'
+
+// maxCachedRegexps bounds the compiled REGEXP patterns kept by MatchRegexp.
+const maxCachedRegexps = 128
+
+var regexpCache = struct {
+	sync.Mutex
+	patterns map[string]*regexp.Regexp
+}{patterns: make(map[string]*regexp.Regexp)}
+
+// MatchLike reports whether value matches a SQL LIKE pattern.
+// In the pattern, % matches any sequence of characters (including none),
+// _ matches exactly one character and a backslash makes the character after
+// it match literally. The whole value must match.
+//
+// Examples:
+//
+//	MatchLike("ALICE", "AL%")   // true
+//	MatchLike("ALICE", "_LICE") // true
+//	MatchLike("50%", "50\\%")   // true
+func MatchLike(value, pattern string) bool {
+	v, p := []rune(value), []rune(pattern)
+	vi, pi := 0, 0
+	starP, starV := -1, 0
+
+	for vi < len(v) {
+		if pi < len(p) {
+			switch c := p[pi]; {
+			case c == '%':
+				starP, starV = pi, vi
+				pi++
+				continue
+			case c == '_':
+				vi++
+				pi++
+				continue
+			case c == likeEscape && pi+1 < len(p):
+				if p[pi+1] == v[vi] {
+					vi++
+					pi += 2
+					continue
+				}
+			case c == v[vi]:
+				vi++
+				pi++
+				continue
+			}
+		}
+
+		// Mismatch: let the most recent % absorb one more character.
+		if starP < 0 {
+			return false
+		}
+		starV++
+		vi, pi = starV, starP+1
+	}
+
+	for pi < len(p) && p[pi] == '%' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// MatchILike is the case-insensitive form of MatchLike.
+func MatchILike(value, pattern string) bool {
+	return MatchLike(strings.ToLower(value), strings.ToLower(pattern))
+}
+
+// MatchRegexp reports whether value contains a match of the regular
+// expression pattern (RE2 syntax). Compiled patterns are cached, since a
+// predicate evaluates the same pattern against every row.
+func MatchRegexp(value, pattern string) (bool, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
+
+// compileRegexp returns the compiled form of pattern, compiling and caching it
+// on first use. The cache is cleared once it holds maxCachedRegexps patterns.
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpCache.Lock()
+	defer regexpCache.Unlock()
+
+	if re, ok := regexpCache.patterns[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(regexpCache.patterns) >= maxCachedRegexps {
+		clear(regexpCache.patterns)
+	}
+	regexpCache.patterns[pattern] = re
+	return re, nil
+}
+
+// LikePrefix returns the literal text every value matching the LIKE pattern
+// starts with: the characters before the first unescaped wildcard, with
+// escapes removed. It returns "" if the pattern starts with a wildcard.
+//
+// Examples:
+//
+//	LikePrefix("AB%")    // "AB"
+//	LikePrefix("A_C%")   // "A"
+//	LikePrefix("50\\%%") // "50%"
+//	LikePrefix("%AB")    // ""
+func LikePrefix(pattern string) string {
+	var prefix strings.Builder
+	p := []rune(pattern)
+
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case c == '%' || c == '_':
+			return prefix.String()
+		case c == likeEscape && i+1 < len(p):
+			i++
+			prefix.WriteRune(p[i])
+		default:
+			prefix.WriteRune(c)
+		}
+	}
+	return prefix.String()
+}
+
+// PrefixUpperBound returns the smallest string greater than every string that
+// starts with prefix, for use as the end key of a range scan. ok is false when
+// no such string exists (the prefix is empty or consists only of 0xFF bytes),
+// in which case the scan has no upper bound.
+//
+// Example:
+//
+//	PrefixUpperBound("AB") // "AC", true
+func PrefixUpperBound(prefix string) (string, bool) {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xFF {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package types
+
+import "testing"
+
+func TestMatchLike(t *testing.T) {
+	tests := []struct {
+		value    string
+		pattern  string
+		expected bool
+	}{
+		{"ALICE", "ALICE", true},
+		{"ALICE", "AL%", true},
+		{"ALICE", "%CE", true},
+		{"ALICE", "%LI%", true},
+		{"ALICE", "_LICE", true},
+		{"ALICE", "A_I%", true},
+		{"ALICE", "%", true},
+		{"", "%", true},
+		{"", "", true},
+		{"ALICE", "AL", false},
+		{"ALICE", "ALICE_", false},
+		{"ALICE", "%X%", false},
+		{"AXBXC", "%X_", true},
+		{"AXBXC", "A%B%C", true},
+		{"50%", "50\\%", true},
+		{"500", "50\\%", false},
+		{"A_B", "A\\_B", true},
+		{"AXB", "A\\_B", false},
+		{"ÄBC", "_BC", true},
+		{"alice", "AL%", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchLike(tt.value, tt.pattern); got != tt.expected {
+			t.Errorf("MatchLike(%q, %q) = %v, expected %v", tt.value, tt.pattern, got, tt.expected)
+		}
+	}
+
+	if !MatchILike("alice", "AL%") {
+		t.Error("expected ILIKE to ignore case")
+	}
+}
+
+func TestMatchRegexp(t *testing.T) {
+	ok, err := MatchRegexp("ORDER-1042", `^ORDER-\d+$`)
+	if err != nil || !ok {
+		t.Errorf("MatchRegexp = (%v, %v), expected (true, nil)", ok, err)
+	}
+
+	ok, err = MatchRegexp("ORDER-X", `^ORDER-\d+$`)
+	if err != nil || ok {
+		t.Errorf("MatchRegexp = (%v, %v), expected (false, nil)", ok, err)
+	}
+
+	if _, err := MatchRegexp("ORDER", "("); err == nil {
+		t.Error("expected an invalid pattern to fail")
+	}
+}
+
+func TestLikePrefix(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"AB%", "AB"},
+		{"ABC", "ABC"},
+		{"A_C%", "A"},
+		{"%AB", ""},
+		{"_AB", ""},
+		{"50\\%%", "50%"},
+	}
+
+	for _, tt := range tests {
+		if got := LikePrefix(tt.pattern); got != tt.expected {
+			t.Errorf("LikePrefix(%q) = %q, expected %q", tt.pattern, got, tt.expected)
+		}
+	}
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	if bound, ok := PrefixUpperBound("AB"); !ok || bound != "AC" {
+		t.Errorf("PrefixUpperBound(AB) = (%q, %v), expected (AC, true)", bound, ok)
+	}
+	if bound, ok := PrefixUpperBound("A\xff"); !ok || bound != "B" {
+		t.Errorf("PrefixUpperBound(A\\xff) = (%q, %v), expected (B, true)", bound, ok)
+	}
+	if _, ok := PrefixUpperBound(""); ok {
+		t.Error("expected no upper bound for an empty prefix")
+	}
+}
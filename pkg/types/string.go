@@ -43,12 +43,13 @@ func NewStringField(value string, maxSize int) *StringField {
 // using the specified predicate. String comparisons are performed lexicographically.
 //
 // Parameters:
-//   - op: The comparison predicate to apply (supports all standard predicates plus Like)
+//   - op: The comparison predicate to apply (supports all standard predicates plus
+//     LIKE, ILIKE and REGEXP, where other holds the pattern)
 //   - other: The other Field to compare against (must be a *StringField)
 //
 // Returns:
 //   - bool: The result of the comparison operation
-//   - error: An error if the other field is not a StringField or if comparison fails
+//   - error: An error if a REGEXP pattern does not compile
 func (s *StringField) Compare(op primitives.Predicate, other Field) (bool, error) {
 	otherStringField, ok := other.(*StringField)
 	if !ok {
@@ -77,7 +78,14 @@ func (s *StringField) Compare(op primitives.Predicate, other Field) (bool, error
 		return cmp != 0, nil
 
 	case primitives.Like:
-		return strings.Contains(s.Value, otherStringField.Value), nil
+		return MatchLike(s.Value, otherStringField.Value), nil
+
+	case primitives.ILike:
+		return MatchILike(s.Value, otherStringField.Value), nil
+
+	case primitives.Regexp:
+		return MatchRegexp(s.Value, otherStringField.Value)
+
 	default:
 		return false, nil
 	}
@@ -144,7 +144,10 @@ func TestStringField_Compare(t *testing.T) {
 		{primitives.GreaterThanOrEqual, field2, false},
 		{primitives.NotEqual, field2, true},
 		{primitives.NotEqual, field3, false},
-		{primitives.Like, NewStringField("app", 10), true},
+		{primitives.Like, NewStringField("app%", 10), true},
+		{primitives.Like, NewStringField("app", 10), false},
+		{primitives.ILike, NewStringField("APP%", 10), true},
+		{primitives.Regexp, NewStringField("^ap+l", 10), true},
 		{primitives.Like, field2, false},
 		{primitives.Equals, intField, false},
 	}
@@ -13,8 +13,13 @@ func CreateFieldFromConstant(t Type, constant string) (Field, error) {
 		fmt.Sscanf(constant, "%d", &intVal)
 		return NewIntField(intVal), nil
 
+	case Uint64Type:
+		var uintVal uint64
+		fmt.Sscanf(constant, "%d", &uintVal)
+		return NewUint64Field(uintVal), nil
+
 	case BoolType:
-		boolVal := constant == "true"
+		boolVal := strings.EqualFold(constant, "true")
 		return NewBoolField(boolVal), nil
 
 	case FloatType:
@@ -0,0 +1,223 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TypeOfValue returns the Type a Go value maps to when nothing else
+// determines its type, such as a bind parameter that is not compared with or
+// assigned to a column. Integers map to IntType, floats to FloatType, bools
+// to BoolType and strings to StringType; a Field keeps its own type.
+func TypeOfValue(v any) (Type, error) {
+	switch v := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return IntType, nil
+	case float32, float64:
+		return FloatType, nil
+	case bool:
+		return BoolType, nil
+	case string, []byte:
+		return StringType, nil
+	case Field:
+		return v.Type(), nil
+	default:
+		return InvalidType, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// FieldFromValue converts a Go value, such as a bind parameter of a
+// parameterized query, to a Field of type t. A nil value converts to a nil
+// Field (NULL).
+//
+// Conversions never lose information: a number must fit the target type, a
+// float converts to an integer type only if it is integral, and a string
+// converts to a numeric or boolean type only if it parses as one. Unlike
+// SQL literals, string values are not upper-cased.
+func FieldFromValue(v any, t Type) (Field, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if f, ok := v.(Field); ok {
+		if f.Type() == t {
+			return f, nil
+		}
+		v = fieldValue(f)
+	}
+
+	switch t {
+	case IntType, Int32Type, Int64Type, Uint32Type, Uint64Type:
+		return integerField(v, t)
+	case FloatType:
+		return floatField(v)
+	case BoolType:
+		return boolField(v)
+	case StringType:
+		return stringField(v)
+	default:
+		return nil, fmt.Errorf("unsupported field type: %v", t)
+	}
+}
+
+// fieldValue returns the Go value held by f.
+func fieldValue(f Field) any {
+	switch f := f.(type) {
+	case *IntField:
+		return f.Value
+	case *Int32Field:
+		return f.Value
+	case *Int64Field:
+		return f.Value
+	case *Uint32Field:
+		return f.Value
+	case *Uint64Field:
+		return f.Value
+	case *Float64Field:
+		return f.Value
+	case *BoolField:
+		return f.Value
+	case *StringField:
+		return f.Value
+	default:
+		return f.String()
+	}
+}
+
+// integerField converts v to an integer field of type t, checking its range.
+func integerField(v any, t Type) (Field, error) {
+	var n int64
+	var u uint64
+	unsigned := false
+
+	switch v := v.(type) {
+	case int:
+		n = int64(v)
+	case int8:
+		n = int64(v)
+	case int16:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint:
+		u, unsigned = uint64(v), true
+	case uint8:
+		u, unsigned = uint64(v), true
+	case uint16:
+		u, unsigned = uint64(v), true
+	case uint32:
+		u, unsigned = uint64(v), true
+	case uint64:
+		u, unsigned = v, true
+	case float32:
+		return integerField(float64(v), t)
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return nil, fmt.Errorf("cannot convert %v to %s", v, t)
+		}
+		n = int64(v)
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			parsedUnsigned, uerr := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+			if uerr != nil {
+				return nil, fmt.Errorf("cannot convert %q to %s", v, t)
+			}
+			u, unsigned = parsedUnsigned, true
+		}
+		n = parsed
+	default:
+		return nil, fmt.Errorf("cannot convert %T to %s", v, t)
+	}
+
+	if unsigned {
+		if u > math.MaxInt64 {
+			if t != Uint64Type {
+				return nil, fmt.Errorf("value %d out of range for %s", u, t)
+			}
+			return NewUint64Field(u), nil
+		}
+		n = int64(u)
+	}
+
+	switch t {
+	case Int32Type:
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("value %d out of range for %s", n, t)
+		}
+		return NewInt32Field(int32(n)), nil
+	case Uint32Type:
+		if n < 0 || n > math.MaxUint32 {
+			return nil, fmt.Errorf("value %d out of range for %s", n, t)
+		}
+		return NewUint32Field(uint32(n)), nil
+	case Uint64Type:
+		if n < 0 {
+			return nil, fmt.Errorf("value %d out of range for %s", n, t)
+		}
+		return NewUint64Field(uint64(n)), nil
+	case Int64Type:
+		return NewInt64Field(n), nil
+	default:
+		return NewIntField(n), nil
+	}
+}
+
+// floatField converts v to a FloatType field.
+func floatField(v any) (Field, error) {
+	switch v := v.(type) {
+	case float32:
+		return NewFloat64Field(float64(v)), nil
+	case float64:
+		return NewFloat64Field(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to %s", v, FloatType)
+		}
+		return NewFloat64Field(f), nil
+	}
+
+	n, err := integerField(v, IntType)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %T to %s", v, FloatType)
+	}
+	return NewFloat64Field(float64(n.(*IntField).Value)), nil
+}
+
+// boolField converts v to a BoolType field.
+func boolField(v any) (Field, error) {
+	switch v := v.(type) {
+	case bool:
+		return NewBoolField(v), nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to %s", v, BoolType)
+		}
+		return NewBoolField(b), nil
+	default:
+		return nil, fmt.Errorf("cannot convert %T to %s", v, BoolType)
+	}
+}
+
+// stringField converts v to a StringType field.
+func stringField(v any) (Field, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, fmt.Errorf("cannot convert %T to %s", v, StringType)
+	}
+
+	if len(s) > StringMaxSize {
+		return nil, fmt.Errorf("string of %d bytes exceeds the maximum of %d", len(s), StringMaxSize)
+	}
+	return NewStringField(s, StringMaxSize), nil
+}
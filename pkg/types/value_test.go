@@ -0,0 +1,101 @@
+package types
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTypeOfValue(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected Type
+	}{
+		{42, IntType},
+		{uint8(7), IntType},
+		{int64(-1), IntType},
+		{3.5, FloatType},
+		{float32(1), FloatType},
+		{true, BoolType},
+		{"alice", StringType},
+		{[]byte("bob"), StringType},
+		{NewFloat64Field(1.5), FloatType},
+	}
+
+	for _, tt := range tests {
+		got, err := TypeOfValue(tt.value)
+		if err != nil {
+			t.Errorf("TypeOfValue(%v) failed: %v", tt.value, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("TypeOfValue(%v) = %s, expected %s", tt.value, got, tt.expected)
+		}
+	}
+
+	if _, err := TypeOfValue(struct{}{}); err == nil {
+		t.Error("expected an error for an unsupported value type")
+	}
+}
+
+func TestFieldFromValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		typ      Type
+		expected Field
+	}{
+		{"int", 42, IntType, NewIntField(42)},
+		{"int from string", " -7 ", IntType, NewIntField(-7)},
+		{"int from integral float", 3.0, IntType, NewIntField(3)},
+		{"int32", int64(100), Int32Type, NewInt32Field(100)},
+		{"uint64 above int64", uint64(math.MaxUint64), Uint64Type, NewUint64Field(math.MaxUint64)},
+		{"float from int", 2, FloatType, NewFloat64Field(2)},
+		{"float from string", "2.5", FloatType, NewFloat64Field(2.5)},
+		{"bool", true, BoolType, NewBoolField(true)},
+		{"bool from string", "false", BoolType, NewBoolField(false)},
+		{"string keeps case", "Alice", StringType, NewStringField("Alice", StringMaxSize)},
+		{"bytes", []byte("x"), StringType, NewStringField("x", StringMaxSize)},
+		{"field", NewIntField(5), FloatType, NewFloat64Field(5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FieldFromValue(tt.value, tt.typ)
+			if err != nil {
+				t.Fatalf("FieldFromValue(%v, %s) failed: %v", tt.value, tt.typ, err)
+			}
+			if !got.Equals(tt.expected) {
+				t.Errorf("FieldFromValue(%v, %s) = %v, expected %v", tt.value, tt.typ, got, tt.expected)
+			}
+		})
+	}
+
+	if got, err := FieldFromValue(nil, IntType); got != nil || err != nil {
+		t.Errorf("expected nil to convert to NULL, got %v, %v", got, err)
+	}
+}
+
+func TestFieldFromValue_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		typ   Type
+	}{
+		{"non-numeric string", "1; DROP TABLE users", IntType},
+		{"fractional float", 1.5, IntType},
+		{"int32 overflow", int64(math.MaxInt32) + 1, Int32Type},
+		{"negative unsigned", -1, Uint32Type},
+		{"uint64 into int", uint64(math.MaxUint64), IntType},
+		{"bool from int", 1, BoolType},
+		{"string from int", 1, StringType},
+		{"string too long", strings.Repeat("x", StringMaxSize+1), StringType},
+		{"unsupported go type", struct{}{}, IntType},
+	}
+
+	for _, tt := range tests {
+		if _, err := FieldFromValue(tt.value, tt.typ); err == nil {
+			t.Errorf("%s: expected FieldFromValue(%v, %s) to fail", tt.name, tt.value, tt.typ)
+		}
+	}
+}
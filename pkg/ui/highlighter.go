@@ -12,7 +12,7 @@ var (
 		"ON", "AS", "INSERT", "INTO", "VALUES", "UPDATE", "SET", "DELETE",
 		"CREATE", "TABLE", "DROP", "ALTER", "ADD", "COLUMN", "PRIMARY", "KEY",
 		"FOREIGN", "REFERENCES", "INDEX", "UNIQUE", "NOT", "NULL", "DEFAULT",
-		"AND", "OR", "IN", "EXISTS", "BETWEEN", "LIKE", "LIMIT", "OFFSET",
+		"AND", "OR", "IN", "EXISTS", "BETWEEN", "LIKE", "ILIKE", "REGEXP", "LIMIT", "OFFSET",
 		"ORDER", "BY", "GROUP", "HAVING", "DISTINCT", "ALL", "UNION", "EXCEPT",
 		"INTERSECT", "CASE", "WHEN", "THEN", "ELSE", "END", "BEGIN", "COMMIT",
 		"ROLLBACK", "TRANSACTION", "IF", "INT", "VARCHAR", "TEXT", "FLOAT",
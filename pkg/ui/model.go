@@ -2,7 +2,9 @@ package ui
 
 import (
 	"fmt"
+	"os"
 	"storemy/pkg/database"
+	"storemy/pkg/tracing"
 	"strings"
 	"time"
 
@@ -18,7 +20,7 @@ import (
 
 // Model represents the application state
 type Model struct {
-	database    *database.Database
+	database    *database.Session
 	queryEditor textarea.Model
 	resultView  viewport.Model
 	resultTable table.Model
@@ -35,11 +37,13 @@ type Model struct {
 
 	lastQueryTime time.Duration
 	keys          keyMap
+
+	steps []string // Execution steps of the last \step query, oldest first
 }
 
-func NewModel(db *database.Database) Model {
+func NewModel(session *database.Session) Model {
 	ta := textarea.New()
-	ta.Placeholder = "Enter your SQL query here..."
+	ta.Placeholder = "Enter your SQL query here, \\i <file> [stop|continue|rollback] to run a script, \\step <query> to watch it execute, \\locktrace on|show|off to follow locks, or \\recovery to see what crash recovery would do..."
 	ta.CharLimit = 5000
 	ta.ShowLineNumbers = true
 	ta.SetHeight(6)
@@ -79,7 +83,7 @@ func NewModel(db *database.Database) Model {
 	sp.Style = lipgloss.NewStyle().Foreground(primaryColor)
 
 	return Model{
-		database:     db,
+		database:     session,
 		queryEditor:  ta,
 		resultView:   vp,
 		resultTable:  t,
@@ -118,6 +122,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keys.Execute):
 			query := m.queryEditor.Value()
+			if strings.HasPrefix(strings.TrimSpace(query), `\step`) {
+				m.executing = true
+				m.steps = nil
+				return m, m.executeStepByStep(strings.TrimSpace(query))
+			}
+			if strings.HasPrefix(strings.TrimSpace(query), `\locktrace`) {
+				m.steps = nil
+				return m, m.lockTrace(strings.TrimSpace(query))
+			}
+			if strings.TrimSpace(query) == `\dt+` {
+				m.steps = nil
+				return m, m.tableSizes(strings.TrimSpace(query))
+			}
+			if strings.TrimSpace(query) == `\recovery` {
+				m.steps = nil
+				return m, m.explainRecovery(strings.TrimSpace(query))
+			}
+			if strings.HasPrefix(strings.TrimSpace(query), `\i`) {
+				m.executing = true
+				return m, m.executeScriptFile(strings.TrimSpace(query))
+			}
 			if strings.TrimSpace(query) != "" {
 				m.executing = true
 				return m, m.executeQuery(query)
@@ -127,6 +152,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.queryEditor.SetValue("")
 			m.lastResult = database.QueryResult{}
 			m.lastError = nil
+			m.steps = nil
 
 		case key.Matches(msg, m.keys.ShowTables):
 			m.executing = true
@@ -150,6 +176,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateResultDisplay()
 		}
 
+	case stepMsg:
+		m.steps = append(m.steps, msg.line)
+		return m, waitForMsg(msg.next)
+
 	case spinner.TickMsg:
 		if m.executing {
 			var cmd tea.Cmd
@@ -185,6 +215,10 @@ func (m Model) View() string {
 	editorSection := m.renderQueryEditor()
 	sections = append(sections, editorSection)
 
+	if len(m.steps) > 0 {
+		sections = append(sections, m.renderSteps())
+	}
+
 	// Results or executing spinner
 	if m.executing {
 		sections = append(sections, m.renderExecuting())
@@ -283,6 +317,21 @@ func (m Model) renderExecuting() string {
 		Render(content)
 }
 
+// renderSteps shows the latest execution steps of a \step query.
+func (m Model) renderSteps() string {
+	header := lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true).
+		Render(fmt.Sprintf("👣 Execution steps (%d)", len(m.steps)))
+
+	visible := m.steps[max(0, len(m.steps)-maxVisibleSteps):]
+	body := lipgloss.NewStyle().
+		Foreground(textSecondary).
+		Render(strings.Join(visible, "\n"))
+
+	return fmt.Sprintf("%s\n%s", header, body)
+}
+
 func (m Model) renderError() string {
 	icon := errorStyle.Render(" ⚠ ERROR ")
 	message := lipgloss.NewStyle().
@@ -432,6 +481,191 @@ func (m Model) executeQuery(query string) tea.Cmd {
 	}
 }
 
+// maxVisibleSteps is the number of latest steps shown while a \step query
+// runs and after it has finished.
+const maxVisibleSteps = 12
+
+// stepDelay paces a \step query so that its execution can be followed.
+const stepDelay = 40 * time.Millisecond
+
+// stepMsg carries one execution step of a \step query; next delivers the
+// following step, or the query's result once it has finished.
+type stepMsg struct {
+	line string
+	next <-chan tea.Msg
+}
+
+// waitForMsg waits for the next message of a running \step query.
+func waitForMsg(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// executeStepByStep runs the \step meta-command: \step <query>. The query
+// executes in the background and each operator call is shown as it happens,
+// e.g. "Scan USERS read page 3, returned 12 rows", followed by the result.
+func (m Model) executeStepByStep(command string) tea.Cmd {
+	query := strings.TrimSpace(strings.TrimPrefix(command, `\step`))
+	if query == "" {
+		return func() tea.Msg {
+			return queryResultMsg{query: command, err: fmt.Errorf(`usage: \step <query>`)}
+		}
+	}
+
+	ch := make(chan tea.Msg)
+	go func() {
+		start := time.Now()
+		result, err := m.database.ExecuteQueryStepByStep(query, func(e tracing.StepEvent) {
+			line := fmt.Sprintf("%4d  %s%s", e.Seq, strings.Repeat("  ", e.Depth), e)
+			ch <- stepMsg{line: line, next: ch}
+			time.Sleep(stepDelay)
+		})
+		ch <- queryResultMsg{query: command, result: result, err: err, duration: time.Since(start)}
+	}()
+	return waitForMsg(ch)
+}
+
+// lockTrace runs the \locktrace meta-command: \locktrace on|show|off. While
+// on, the lock manager records which transaction acquires, waits for and
+// releases which page locks; show and off display the recording as a
+// timeline with one column per transaction, and off also stops it.
+func (m Model) lockTrace(command string) tea.Cmd {
+	return func() tea.Msg {
+		msg := queryResultMsg{query: command, result: database.QueryResult{Success: true}}
+		db := m.database.Database()
+
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(command, `\locktrace`))) {
+		case "on":
+			db.StartLockTrace(0)
+			msg.result.Message = `Lock tracing on: run transactions, then \locktrace show to see their locks`
+		case "show", "":
+			trace := db.LockTrace()
+			if trace == nil {
+				msg.err = fmt.Errorf(`lock tracing is off; start it with \locktrace on`)
+				return msg
+			}
+			msg.result.Message = "Lock timeline\n" + db.LockTimeline(trace)
+		case "off":
+			trace := db.StopLockTrace()
+			if trace == nil {
+				msg.err = fmt.Errorf("lock tracing is not on")
+				return msg
+			}
+			msg.result.Message = "Lock tracing off\n" + db.LockTimeline(trace)
+		default:
+			msg.err = fmt.Errorf(`usage: \locktrace on|show|off`)
+		}
+		return msg
+	}
+}
+
+// explainRecovery runs the \recovery meta-command, showing what ARIES
+// recovery would do with every WAL record if the database crashed now.
+func (m Model) explainRecovery(command string) tea.Cmd {
+	return func() tea.Msg {
+		exp, err := m.database.Database().ExplainRecovery()
+		if err != nil {
+			return queryResultMsg{query: command, err: err}
+		}
+		return queryResultMsg{query: command, result: database.QueryResult{
+			Success: true,
+			Message: "Recovery dry run\n" + exp.String(),
+		}}
+	}
+}
+
+// tableSizes runs the \dt+ meta-command, listing every table with the
+// storage it uses, largest first (see SYS_TABLE_STORAGE).
+func (m Model) tableSizes(command string) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		usages, err := m.database.Database().StorageReport()
+		if err != nil {
+			return queryResultMsg{query: command, err: err}
+		}
+
+		rows := make([][]string, 0, len(usages))
+		for _, u := range usages {
+			rows = append(rows, []string{
+				u.Table,
+				formatBytes(u.TotalBytes()),
+				formatBytes(u.HeapBytes),
+				fmt.Sprintf("%d", u.LiveTuples),
+				fmt.Sprintf("%d", u.DeadTuples),
+				formatBytes(u.FreeBytes),
+				fmt.Sprintf("%d", u.Indexes),
+				formatBytes(u.IndexBytes),
+			})
+		}
+		return queryResultMsg{
+			query: command,
+			result: database.QueryResult{
+				Success: true,
+				Columns: []string{"Table", "Total", "Heap", "Live Tuples", "Dead Tuples", "Free", "Indexes", "Index Size"},
+				Rows:    rows,
+				Message: fmt.Sprintf("%d tables", len(rows)),
+			},
+			duration: time.Since(start),
+		}
+	}
+}
+
+// formatBytes formats a size with the largest binary unit it reaches.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// executeScriptFile runs the \i meta-command: \i <file> [stop|continue|rollback].
+// The script's per-statement summary is shown as the result table; only a
+// missing file or an unreadable script is reported as an error.
+func (m Model) executeScriptFile(command string) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		msg := queryResultMsg{query: command}
+
+		args := strings.Fields(strings.TrimPrefix(command, `\i`))
+		if len(args) == 0 || len(args) > 2 {
+			msg.err = fmt.Errorf(`usage: \i <file> [stop|continue|rollback]`)
+			return msg
+		}
+
+		policy := database.ScriptStopOnError
+		if len(args) == 2 {
+			p, err := database.ParseScriptErrorPolicy(args[1])
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			policy = p
+		}
+
+		content, err := os.ReadFile(args[0])
+		if err != nil {
+			msg.err = fmt.Errorf("cannot read script: %w", err)
+			return msg
+		}
+
+		result, err := m.database.ExecuteScript(string(content), policy)
+		msg.duration = time.Since(start)
+		if result == nil {
+			msg.err = err
+			return msg
+		}
+		msg.result = result.QueryResult()
+		return msg
+	}
+}
+
 // showStatistics displays database statistics
 func (m Model) showStatistics() tea.Cmd {
 	return func() tea.Msg {
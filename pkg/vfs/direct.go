@@ -0,0 +1,163 @@
+package vfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// DirectAlignment is the alignment of the offsets, lengths and memory
+// buffers of direct I/O. It covers the logical block size of common disks.
+const DirectAlignment = 4096
+
+// ODirect asks OpenFile to bypass the operating system's page cache where
+// the platform and the file system support it, so that pages the buffer
+// pool already caches are not cached twice. It is O_DIRECT on Linux and 0
+// elsewhere.
+//
+// A file opened with ODirect behaves like any other: unaligned reads and
+// writes go through aligned buffers, reading and rewriting the blocks they
+// only partly cover. Where direct I/O is not available, such as on tmpfs or
+// a MemFS, the file is opened without it; IsDirect tells the two apart.
+const ODirect = oDirect
+
+// IsDirect reports whether f was opened with direct I/O.
+func IsDirect(f File) bool {
+	_, ok := f.(*directFile)
+	return ok
+}
+
+// openDirect opens name with direct I/O, falling back to a regular open if
+// the file system rejects it.
+func openDirect(name string, flag int, perm fs.FileMode) (File, error) {
+	f, err := os.OpenFile(name, flag|oDirect, perm)
+	if err == nil {
+		return newDirectFile(f)
+	}
+	if !errors.Is(err, syscall.EINVAL) {
+		return nil, err
+	}
+	f, err = os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// directFile adapts a file opened with direct I/O, whose reads and writes
+// must be aligned, to arbitrary offsets and lengths.
+type directFile struct {
+	File
+	mu   sync.Mutex // Serializes writes, which may rewrite shared blocks
+	size int64      // Size of the file, which aligned writes may overshoot
+}
+
+func newDirectFile(f File) (*directFile, error) {
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &directFile{File: f, size: info.Size()}, nil
+}
+
+// ReadAt reads the aligned blocks covering p and copies p out of them.
+func (d *directFile) ReadAt(p []byte, off int64) (int, error) {
+	start, end := alignDown(off), alignUp(off+int64(len(p)))
+	buf := alignedBuffer(int(end - start))
+
+	n, err := d.File.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	got := max(0, min(len(p), n-int(off-start)))
+	copy(p, buf[off-start:])
+	if got < len(p) {
+		return got, io.EOF
+	}
+	return got, nil
+}
+
+// WriteAt writes p through the aligned blocks covering it, reading back the
+// parts of the first and last block outside p first. Blocks written past
+// the end of the file are cut back to it.
+func (d *directFile) WriteAt(p []byte, off int64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	end := off + int64(len(p))
+	alignedStart, alignedEnd := alignDown(off), alignUp(end)
+	buf := alignedBuffer(int(alignedEnd - alignedStart))
+
+	headRead := alignedStart != off && alignedStart < d.size
+	if headRead {
+		if err := d.readBlock(buf[:DirectAlignment], alignedStart); err != nil {
+			return 0, err
+		}
+	}
+	tail := alignedEnd - DirectAlignment
+	if alignedEnd != end && tail < d.size && !(headRead && tail == alignedStart) {
+		if err := d.readBlock(buf[len(buf)-DirectAlignment:], tail); err != nil {
+			return 0, err
+		}
+	}
+	copy(buf[off-alignedStart:], p)
+
+	if _, err := d.File.WriteAt(buf, alignedStart); err != nil {
+		return 0, err
+	}
+	if end > d.size {
+		if alignedEnd > end {
+			if err := d.File.Truncate(end); err != nil {
+				return 0, err
+			}
+		}
+		d.size = end
+	}
+	return len(p), nil
+}
+
+// readBlock reads the block at off into block, zero-filling past the end of
+// the file.
+func (d *directFile) readBlock(block []byte, off int64) error {
+	n, err := d.File.ReadAt(block, off)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	clear(block[n:])
+	return nil
+}
+
+func (d *directFile) Truncate(size int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.File.Truncate(size); err != nil {
+		return err
+	}
+	d.size = size
+	return nil
+}
+
+func alignDown(off int64) int64 {
+	return off &^ (DirectAlignment - 1)
+}
+
+func alignUp(off int64) int64 {
+	return alignDown(off + DirectAlignment - 1)
+}
+
+// alignedBuffer returns a zeroed buffer of size bytes whose memory starts on
+// a DirectAlignment boundary, as direct I/O requires.
+func alignedBuffer(size int) []byte {
+	buf := make([]byte, size+DirectAlignment)
+	shift := 0
+	if rem := int(uintptr(unsafe.Pointer(&buf[0])) & (DirectAlignment - 1)); rem != 0 {
+		shift = DirectAlignment - rem
+	}
+	return buf[shift : shift+size : shift+size]
+}
@@ -0,0 +1,5 @@
+package vfs
+
+import "syscall"
+
+const oDirect = syscall.O_DIRECT
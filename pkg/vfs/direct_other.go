@@ -0,0 +1,7 @@
+//go:build !linux
+
+package vfs
+
+// oDirect is 0 where the platform has no O_DIRECT, so files are opened
+// through the page cache.
+const oDirect = 0
@@ -0,0 +1,93 @@
+package vfs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDirectFile_UnalignedWrites writes records straddling block boundaries,
+// as the WAL does, and reads them back at arbitrary offsets
+func TestDirectFile_UnalignedWrites(t *testing.T) {
+	f, err := OS.OpenFile(filepath.Join(t.TempDir(), "wal.log"), os.O_RDWR|os.O_CREATE|ODirect, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	// Without direct I/O here, the aligned buffering still runs over a
+	// regular file
+	d, ok := f.(*directFile)
+	if !ok {
+		if d, err = newDirectFile(f); err != nil {
+			t.Fatalf("newDirectFile failed: %v", err)
+		}
+	}
+	defer d.Close()
+
+	var want []byte
+	for i, size := range []int{100, 4000, 10, DirectAlignment, 3 * DirectAlignment / 2, 1} {
+		record := bytes.Repeat([]byte{byte(i + 1)}, size)
+		if _, err := d.WriteAt(record, int64(len(want))); err != nil {
+			t.Fatalf("WriteAt failed: %v", err)
+		}
+		want = append(want, record...)
+	}
+
+	info, err := d.Stat()
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size() != int64(len(want)) {
+		t.Errorf("file size %d, want %d", info.Size(), len(want))
+	}
+
+	// Overwrite the middle of a block
+	copy(want[4090:4110], bytes.Repeat([]byte{0xff}, 20))
+	if _, err := d.WriteAt(want[4090:4110], 4090); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+
+	got := make([]byte, len(want))
+	if n, err := d.ReadAt(got, 0); err != nil || n != len(want) {
+		t.Fatalf("ReadAt = %d, %v", n, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("read back different data than was written")
+	}
+
+	tail := make([]byte, 50)
+	n, err := d.ReadAt(tail, int64(len(want)-20))
+	if err != io.EOF || n != 20 || !bytes.Equal(tail[:n], want[len(want)-20:]) {
+		t.Errorf("ReadAt past the end = %d, %v", n, err)
+	}
+}
+
+func TestOpenFile_DirectFallsBack(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.db")
+	f, err := OS.OpenFile(name, os.O_RDWR|os.O_CREATE|ODirect, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer f.Close()
+
+	// Whether or not the file system took O_DIRECT, the file works
+	page := bytes.Repeat([]byte{7}, DirectAlignment)
+	if _, err := f.WriteAt(page, DirectAlignment); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+	got := make([]byte, DirectAlignment)
+	if _, err := f.ReadAt(got, DirectAlignment); err != nil || !bytes.Equal(got, page) {
+		t.Errorf("ReadAt = %v, data equal %v", err, bytes.Equal(got, page))
+	}
+	t.Logf("direct I/O: %v", IsDirect(f))
+
+	m := NewMemFS()
+	mf, err := m.OpenFile("/data.db", os.O_RDWR|os.O_CREATE|ODirect, 0644)
+	if err != nil {
+		t.Fatalf("MemFS OpenFile failed: %v", err)
+	}
+	if IsDirect(mf) {
+		t.Error("expected a MemFS file not to use direct I/O")
+	}
+}
@@ -0,0 +1,70 @@
+package vfs
+
+import (
+	"strings"
+	"sync"
+)
+
+// Op identifies a file system operation for fault injection.
+type Op int
+
+const (
+	OpOpen Op = iota
+	OpRead
+	OpWrite
+	OpSync
+	OpRename
+	OpRemove
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpOpen:
+		return "open"
+	case OpRead:
+		return "read"
+	case OpWrite:
+		return "write"
+	case OpSync:
+		return "sync"
+	case OpRename:
+		return "rename"
+	case OpRemove:
+		return "remove"
+	default:
+		return "unknown"
+	}
+}
+
+// FaultFunc decides whether an operation on the named file fails. A non-nil
+// return value is returned to the caller and the operation has no effect.
+type FaultFunc func(op Op, name string) error
+
+// FailNth fails the nth (1-based) occurrence of op on files whose path
+// contains substr, and only that occurrence.
+func FailNth(op Op, substr string, n int, err error) FaultFunc {
+	var mutex sync.Mutex
+	count := 0
+	return func(o Op, name string) error {
+		if o != op || !strings.Contains(name, substr) {
+			return nil
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		count++
+		if count == n {
+			return err
+		}
+		return nil
+	}
+}
+
+// FailAlways fails every occurrence of op on files whose path contains substr.
+func FailAlways(op Op, substr string, err error) FaultFunc {
+	return func(o Op, name string) error {
+		if o == op && strings.Contains(name, substr) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,415 @@
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory file system for deterministic tests.
+//
+// Each file keeps two copies of its contents: the data visible to readers and
+// the data that has been made durable by Sync (or by writing through a handle
+// opened with O_SYNC). Crash discards everything that was not synced,
+// simulating a power failure. Creating, renaming and removing files take
+// effect durably and immediately.
+//
+// Faults can be injected with SetFault to make individual opens, reads,
+// writes, syncs, renames or removes fail.
+type MemFS struct {
+	files   map[string]*memNode
+	dirs    map[string]bool
+	handles map[*memFile]struct{}
+	fault   FaultFunc
+	now     func() time.Time
+	mutex   sync.Mutex
+}
+
+type memNode struct {
+	data    []byte
+	durable []byte
+	modTime time.Time
+}
+
+// NewMemFS creates an empty in-memory file system containing only the root directory.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		files:   make(map[string]*memNode),
+		dirs:    map[string]bool{"/": true, ".": true},
+		handles: make(map[*memFile]struct{}),
+		now:     time.Now,
+	}
+}
+
+// SetFault installs f to decide which operations fail. Nil removes it.
+func (m *MemFS) SetFault(f FaultFunc) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.fault = f
+}
+
+// SetTimeSource sets the function used for file modification times, such as
+// the Now method of a fake clock.
+func (m *MemFS) SetTimeSource(now func() time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.now = now
+}
+
+// Crash simulates a power failure: every file reverts to its last synced
+// contents and all open handles are closed.
+func (m *MemFS) Crash() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, n := range m.files {
+		n.data = slices.Clone(n.durable)
+	}
+	for h := range m.handles {
+		h.closed = true
+	}
+	clear(m.handles)
+}
+
+// checkFault returns the injected error for op on name, if any. The caller
+// must hold m.mutex.
+func (m *MemFS) checkFault(op Op, name string) error {
+	if m.fault == nil {
+		return nil
+	}
+	if err := m.fault(op, name); err != nil {
+		return &fs.PathError{Op: op.String(), Path: name, Err: err}
+	}
+	return nil
+}
+
+func (m *MemFS) parentExists(name string) bool {
+	return m.dirs[filepath.Dir(name)]
+}
+
+func (m *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	name = filepath.Clean(name)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.checkFault(OpOpen, name); err != nil {
+		return nil, err
+	}
+	if m.dirs[name] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	n, exists := m.files[name]
+	switch {
+	case exists && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case !exists && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case !exists:
+		if !m.parentExists(name) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		n = &memNode{modTime: m.now()}
+		m.files[name] = n
+	}
+
+	access := flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR)
+	if flag&os.O_TRUNC != 0 && access != os.O_RDONLY {
+		n.data = nil
+		n.modTime = m.now()
+	}
+
+	h := &memFile{
+		fs:       m,
+		node:     n,
+		name:     name,
+		readable: access != os.O_WRONLY,
+		writable: access != os.O_RDONLY,
+		sync:     flag&os.O_SYNC != 0,
+	}
+	m.handles[h] = struct{}{}
+	return h, nil
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	f, err := Open(m, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, info.Size())
+	if _, err := f.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+// WriteFile writes data to name, creating or truncating it. Like
+// os.WriteFile it does not sync the file.
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := m.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.checkFault(OpRename, oldpath); err != nil {
+		return err
+	}
+	n, ok := m.files[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if !m.parentExists(newpath) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+
+	delete(m.files, oldpath)
+	m.files[newpath] = n
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	name = filepath.Clean(name)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.checkFault(OpRemove, name); err != nil {
+		return err
+	}
+	if _, ok := m.files[name]; ok {
+		delete(m.files, name)
+		return nil
+	}
+	if m.dirs[name] {
+		for path := range m.files {
+			if filepath.Dir(path) == name {
+				return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrExist}
+			}
+		}
+		delete(m.dirs, name)
+		return nil
+	}
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	name = filepath.Clean(name)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if n, ok := m.files[name]; ok {
+		return n.info(name), nil
+	}
+	if m.dirs[name] {
+		return memFileInfo{name: filepath.Base(name), mode: fs.ModeDir | 0755}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *MemFS) MkdirAll(path string, perm fs.FileMode) error {
+	path = filepath.Clean(path)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for dir := path; !m.dirs[dir]; dir = filepath.Dir(dir) {
+		if _, ok := m.files[dir]; ok {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+		}
+		m.dirs[dir] = true
+	}
+	return nil
+}
+
+func (n *memNode) info(name string) memFileInfo {
+	return memFileInfo{
+		name:    filepath.Base(name),
+		size:    int64(len(n.data)),
+		mode:    0644,
+		modTime: n.modTime,
+	}
+}
+
+// memFile is an open handle on a MemFS file.
+type memFile struct {
+	fs       *MemFS
+	node     *memNode
+	name     string
+	readable bool
+	writable bool
+	sync     bool
+	closed   bool
+}
+
+func (f *memFile) Name() string {
+	return f.name
+}
+
+// checkOpen returns an error if the handle is closed. The caller must hold f.fs.mutex.
+func (f *memFile) checkOpen(op string) error {
+	if f.closed {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+	return nil
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if err := f.checkOpen("read"); err != nil {
+		return 0, err
+	}
+	if !f.readable {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrPermission}
+	}
+	if err := f.fs.checkFault(OpRead, f.name); err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if off >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, f.node.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (int, error) {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if err := f.checkOpen("write"); err != nil {
+		return 0, err
+	}
+	if !f.writable {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrPermission}
+	}
+	if err := f.fs.checkFault(OpWrite, f.name); err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	end := off + int64(len(p))
+	if end > int64(len(f.node.data)) {
+		f.node.data = append(f.node.data, make([]byte, end-int64(len(f.node.data)))...)
+	}
+	copy(f.node.data[off:], p)
+	f.node.modTime = f.fs.now()
+
+	if f.sync {
+		if err := f.syncLocked(); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+func (f *memFile) Sync() error {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if err := f.checkOpen("sync"); err != nil {
+		return err
+	}
+	return f.syncLocked()
+}
+
+// syncLocked makes the file's current contents durable. The caller must hold f.fs.mutex.
+func (f *memFile) syncLocked() error {
+	if err := f.fs.checkFault(OpSync, f.name); err != nil {
+		return err
+	}
+	f.node.durable = slices.Clone(f.node.data)
+	return nil
+}
+
+func (f *memFile) Truncate(size int64) error {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if err := f.checkOpen("truncate"); err != nil {
+		return err
+	}
+	if !f.writable || size < 0 {
+		return &fs.PathError{Op: "truncate", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if err := f.fs.checkFault(OpWrite, f.name); err != nil {
+		return err
+	}
+
+	if size <= int64(len(f.node.data)) {
+		f.node.data = f.node.data[:size:size]
+	} else {
+		f.node.data = append(f.node.data, make([]byte, size-int64(len(f.node.data)))...)
+	}
+	f.node.modTime = f.fs.now()
+	return nil
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if err := f.checkOpen("stat"); err != nil {
+		return nil, err
+	}
+	return f.node.info(f.name), nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if err := f.checkOpen("close"); err != nil {
+		return err
+	}
+	f.closed = true
+	delete(f.fs.handles, f)
+	return nil
+}
+
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return fi.size }
+func (fi memFileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi memFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi memFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi memFileInfo) Sys() any           { return nil }
@@ -0,0 +1,135 @@
+package vfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+var errInjected = errors.New("injected I/O error")
+
+func TestMemFS_ReadWrite(t *testing.T) {
+	m := NewMemFS()
+	f, err := m.OpenFile("/data.db", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteAt([]byte("world"), 6); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+	if _, err := f.WriteAt([]byte("hello"), 0); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+
+	buf := make([]byte, 11)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	if string(buf) != "hello\x00world" {
+		t.Errorf("unexpected contents %q", buf)
+	}
+
+	if n, err := f.ReadAt(make([]byte, 4), 9); n != 2 || err != io.EOF {
+		t.Errorf("expected short read with io.EOF, got n=%d err=%v", n, err)
+	}
+
+	info, err := m.Stat("/data.db")
+	if err != nil || info.Size() != 11 {
+		t.Errorf("expected size 11, got %v (err %v)", info, err)
+	}
+}
+
+func TestMemFS_NotExist(t *testing.T) {
+	m := NewMemFS()
+	if _, err := m.OpenFile("/missing", os.O_RDONLY, 0); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+	if _, err := m.OpenFile("/nodir/file", os.O_RDWR|os.O_CREATE, 0644); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error for missing parent, got %v", err)
+	}
+
+	if err := m.MkdirAll("/a/b", 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if _, err := m.OpenFile("/a/b/file", os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		t.Errorf("expected create under MkdirAll directory to succeed, got %v", err)
+	}
+}
+
+func TestMemFS_CrashKeepsOnlySyncedData(t *testing.T) {
+	m := NewMemFS()
+	f, _ := m.OpenFile("/data.db", os.O_RDWR|os.O_CREATE, 0644)
+	f.WriteAt([]byte("synced"), 0)
+	if err := f.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	f.WriteAt([]byte("-lost"), 6)
+
+	sf, _ := m.OpenFile("/log", os.O_RDWR|os.O_CREATE|os.O_SYNC, 0644)
+	sf.WriteAt([]byte("durable"), 0)
+
+	m.Crash()
+
+	if _, err := f.ReadAt(make([]byte, 1), 0); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected handles to be closed by Crash, got %v", err)
+	}
+
+	data, err := m.ReadFile("/data.db")
+	if err != nil || string(data) != "synced" {
+		t.Errorf("expected only synced data to survive, got %q (err %v)", data, err)
+	}
+	data, err = m.ReadFile("/log")
+	if err != nil || string(data) != "durable" {
+		t.Errorf("expected O_SYNC writes to survive, got %q (err %v)", data, err)
+	}
+}
+
+func TestMemFS_RenameAndRemove(t *testing.T) {
+	m := NewMemFS()
+	if err := m.WriteFile("/a.tmp", []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := m.Rename("/a.tmp", "/a"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if _, err := m.Stat("/a.tmp"); !os.IsNotExist(err) {
+		t.Error("expected old name to be gone after rename")
+	}
+	if err := m.Remove("/a"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := m.Stat("/a"); !os.IsNotExist(err) {
+		t.Error("expected file to be gone after remove")
+	}
+}
+
+func TestMemFS_FaultInjection(t *testing.T) {
+	m := NewMemFS()
+	f, _ := m.OpenFile("/wal.log", os.O_RDWR|os.O_CREATE, 0644)
+
+	m.SetFault(FailNth(OpWrite, "wal", 2, errInjected))
+	if _, err := f.WriteAt([]byte("one"), 0); err != nil {
+		t.Fatalf("first write should succeed: %v", err)
+	}
+	if _, err := f.WriteAt([]byte("two"), 3); !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected error on second write, got %v", err)
+	}
+	if _, err := f.WriteAt([]byte("three"), 3); err != nil {
+		t.Fatalf("third write should succeed: %v", err)
+	}
+
+	m.SetFault(FailAlways(OpSync, "wal", errInjected))
+	if err := f.Sync(); !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected sync error, got %v", err)
+	}
+
+	m.SetFault(nil)
+	m.Crash()
+	data, _ := m.ReadFile("/wal.log")
+	if len(data) != 0 {
+		t.Errorf("expected nothing durable after failed sync, got %q", data)
+	}
+}
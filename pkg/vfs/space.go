@@ -0,0 +1,13 @@
+package vfs
+
+import "errors"
+
+// ErrFreeSpaceUnsupported is returned by FreeSpace where the platform cannot
+// report the free space of a file system.
+var ErrFreeSpaceUnsupported = errors.New("free space is not reported on this platform")
+
+// FreeSpace returns the bytes an unprivileged process can still write to
+// the file system holding path, which must exist on disk.
+func FreeSpace(path string) (int64, error) {
+	return freeSpace(path)
+}
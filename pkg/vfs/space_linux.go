@@ -0,0 +1,15 @@
+package vfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// freeSpace reads the blocks available to unprivileged users with statfs.
+func freeSpace(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	return int64(st.Bavail) * st.Bsize, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package vfs
+
+// freeSpace is not available where the platform has no statfs.
+func freeSpace(path string) (int64, error) {
+	return 0, ErrFreeSpaceUnsupported
+}
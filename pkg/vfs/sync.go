@@ -0,0 +1,76 @@
+package vfs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SyncPolicy is how writes to the WAL and the page files are made durable.
+type SyncPolicy uint8
+
+const (
+	// SyncOSync opens files with O_SYNC, so every write returns only once it
+	// is on disk. It is the simplest and slowest policy.
+	SyncOSync SyncPolicy = iota
+
+	// SyncFsync lets writes land in the OS cache and makes a batch of them
+	// durable with one explicit fsync: the WAL syncs once per buffer flush,
+	// the page files once per commit.
+	SyncFsync
+
+	// SyncFdatasync is SyncFsync using fdatasync, which skips flushing file
+	// metadata such as the modification time. It falls back to fsync where
+	// fdatasync is not available.
+	SyncFdatasync
+)
+
+func (p SyncPolicy) String() string {
+	switch p {
+	case SyncOSync:
+		return "osync"
+	case SyncFsync:
+		return "fsync"
+	case SyncFdatasync:
+		return "fdatasync"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSyncPolicy parses a sync policy name as returned by SyncPolicy.String,
+// ignoring case.
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch strings.ToLower(s) {
+	case "osync":
+		return SyncOSync, nil
+	case "fsync":
+		return SyncFsync, nil
+	case "fdatasync":
+		return SyncFdatasync, nil
+	default:
+		return 0, fmt.Errorf("unknown sync policy %q (expected osync, fsync or fdatasync)", s)
+	}
+}
+
+// OpenFlag returns the flag to add to os.O_RDWR and friends when opening a
+// file written under this policy.
+func (p SyncPolicy) OpenFlag() int {
+	if p == SyncOSync {
+		return os.O_SYNC
+	}
+	return 0
+}
+
+// Sync makes every write to f so far durable. Files opened under SyncOSync
+// have nothing left to sync.
+func (p SyncPolicy) Sync(f File) error {
+	switch p {
+	case SyncOSync:
+		return nil
+	case SyncFdatasync:
+		return fdatasync(f)
+	default:
+		return f.Sync()
+	}
+}
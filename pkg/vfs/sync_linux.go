@@ -0,0 +1,19 @@
+package vfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// fdatasync flushes the data of f, but not metadata that is not needed to
+// read it back, to disk. Files not backed by the OS are synced with Sync.
+func fdatasync(f File) error {
+	osFile, ok := f.(*os.File)
+	if !ok {
+		return f.Sync()
+	}
+	if err := syscall.Fdatasync(int(osFile.Fd())); err != nil {
+		return &os.PathError{Op: "fdatasync", Path: osFile.Name(), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package vfs
+
+// fdatasync falls back to Sync where the platform has no fdatasync.
+func fdatasync(f File) error {
+	return f.Sync()
+}
@@ -0,0 +1,64 @@
+package vfs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseSyncPolicy(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncOSync, SyncFsync, SyncFdatasync} {
+		parsed, err := ParseSyncPolicy(policy.String())
+		if err != nil || parsed != policy {
+			t.Errorf("ParseSyncPolicy(%q) = %v, %v; want %v", policy.String(), parsed, err, policy)
+		}
+	}
+	if got, err := ParseSyncPolicy("FDATASYNC"); err != nil || got != SyncFdatasync {
+		t.Errorf("ParseSyncPolicy should ignore case, got %v, %v", got, err)
+	}
+	if _, err := ParseSyncPolicy("sometimes"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+func TestSyncPolicy_MemFS(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncOSync, SyncFsync, SyncFdatasync} {
+		t.Run(policy.String(), func(t *testing.T) {
+			m := NewMemFS()
+			f, err := m.OpenFile("/data.db", os.O_RDWR|os.O_CREATE|policy.OpenFlag(), 0644)
+			if err != nil {
+				t.Fatalf("OpenFile failed: %v", err)
+			}
+			if _, err := f.WriteAt([]byte("abc"), 0); err != nil {
+				t.Fatalf("WriteAt failed: %v", err)
+			}
+			if err := policy.Sync(f); err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+			m.Crash()
+
+			data, err := m.ReadFile("/data.db")
+			if err != nil {
+				t.Fatalf("ReadFile failed: %v", err)
+			}
+			if string(data) != "abc" {
+				t.Errorf("expected the write to survive the crash, got %q", data)
+			}
+		})
+	}
+}
+
+func TestSyncPolicy_OSFile(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncOSync, SyncFsync, SyncFdatasync} {
+		f, err := OS.OpenFile(t.TempDir()+"/data.db", os.O_RDWR|os.O_CREATE|policy.OpenFlag(), 0644)
+		if err != nil {
+			t.Fatalf("OpenFile failed: %v", err)
+		}
+		if _, err := f.WriteAt([]byte("abc"), 0); err != nil {
+			t.Fatalf("WriteAt failed: %v", err)
+		}
+		if err := policy.Sync(f); err != nil {
+			t.Errorf("%s: Sync failed: %v", policy, err)
+		}
+		f.Close()
+	}
+}
@@ -0,0 +1,87 @@
+// Package vfs abstracts the file system operations used by the WAL and the
+// page files, so that tests can run against an in-memory file system with
+// crash simulation and injected I/O faults instead of the real disk.
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// File is an open file. *os.File satisfies this interface.
+type File interface {
+	io.ReaderAt
+	io.WriterAt
+	io.Closer
+
+	// Name returns the path the file was opened with.
+	Name() string
+
+	// Stat returns the file's metadata, including its current size.
+	Stat() (fs.FileInfo, error)
+
+	// Sync makes all previous writes to the file durable.
+	Sync() error
+
+	// Truncate changes the size of the file.
+	Truncate(size int64) error
+}
+
+// FS is the set of file system operations used by the storage layer. The
+// method signatures follow the os package, and errors for missing files
+// satisfy os.IsNotExist.
+type FS interface {
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+	Stat(name string) (fs.FileInfo, error)
+	MkdirAll(path string, perm fs.FileMode) error
+}
+
+// OS is the file system backed by the os package.
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	if ODirect != 0 && flag&ODirect != 0 {
+		return openDirect(name, flag&^ODirect, perm)
+	}
+	f, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (osFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// Open opens name for reading.
+func Open(fsys FS, name string) (File, error) {
+	return fsys.OpenFile(name, os.O_RDONLY, 0)
+}